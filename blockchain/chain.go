@@ -4,18 +4,20 @@ import (
 	"encoding/binary"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/store"
 )
 
 const maxDistance = 2000
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "blockchain"})
+var logger = util.GetLoggerForModule("blockchain")
 
 // Chain represents the blockchain and also is the interface to underlying store.
 type Chain struct {
@@ -25,6 +27,8 @@ type Chain struct {
 	root    common.Hash
 
 	mu *sync.RWMutex
+
+	searchIndexEnabled bool
 }
 
 // NewChain creates a new Chain instance.
@@ -274,6 +278,18 @@ func (ch *Chain) FinalizePreviousBlocks(hash common.Hash) error {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
 
+	// The blocks are finalized from the newest to the oldest, but the search index requires the
+	// blocks to be added in the order of the height
+	finalized := []*core.ExtendedBlock{}
+	defer func() {
+		if !ch.searchIndexEnabled {
+			return
+		}
+		for i := len(finalized) - 1; i >= 0; i-- {
+			ch.addBlockToSearchIndex(finalized[i])
+		}
+	}()
+
 	status := core.BlockStatusDirectlyFinalized
 	for !hash.IsEmpty() {
 		block, err := ch.findBlock(hash)
@@ -293,6 +309,7 @@ func (ch *Chain) FinalizePreviousBlocks(hash common.Hash) error {
 		// Force update TX index on block finalization so that the index doesn't point to
 		// duplicate TX in fork.
 		ch.AddTxsToIndex(block, true)
+		finalized = append(finalized, block)
 
 		hash = block.Parent
 	}
@@ -314,6 +331,17 @@ func (ch *Chain) SaveBlock(block *core.ExtendedBlock) error {
 	return ch.saveBlock(block)
 }
 
+// healthCheckKey is the record written and deleted by CheckWritable.
+var healthCheckKey = common.Bytes("/healthcheck")
+
+// CheckWritable checks that the underlying store accepts writes by writing and deleting a record.
+func (ch *Chain) CheckWritable() error {
+	if err := ch.store.Put(healthCheckKey, uint64(time.Now().Unix())); err != nil {
+		return err
+	}
+	return ch.store.Delete(healthCheckKey)
+}
+
 // FindBlock tries to retrieve a block by hash.
 func (ch *Chain) FindBlock(hash common.Hash) (*core.ExtendedBlock, error) {
 	ch.mu.RLock()
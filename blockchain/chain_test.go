@@ -167,3 +167,13 @@ func TestBlockIndex(t *testing.T) {
 	assert.Equal(core.GetTestBlock("a2").Hash(), blocks[0].Hash())
 	assert.Equal(core.GetTestBlock("b2").Hash(), blocks[1].Hash())
 }
+
+func TestCheckWritable(t *testing.T) {
+	assert := assert.New(t)
+
+	chain := CreateTestChain()
+	assert.Nil(chain.CheckWritable())
+
+	var value uint64
+	assert.NotNil(chain.store.Get(healthCheckKey, &value))
+}
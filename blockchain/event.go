@@ -0,0 +1,43 @@
+package blockchain
+
+import (
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// blockEventsKey constructs the DB key for the events emitted by the given block.
+func blockEventsKey(blockHash common.Hash) common.Bytes {
+	return append(common.Bytes("be/"), blockHash[:]...)
+}
+
+// BlockEventsEntry holds the ledger events emitted during the execution of a block.
+type BlockEventsEntry struct {
+	BlockHash common.Hash
+	Events    []*types.Event
+}
+
+// AddBlockEvents adds the events emitted during the execution of the given block.
+func (ch *Chain) AddBlockEvents(blockHash common.Hash, events []*types.Event) {
+	blockEventsEntry := BlockEventsEntry{
+		BlockHash: blockHash,
+		Events:    events,
+	}
+	err := ch.store.Put(blockEventsKey(blockHash), blockEventsEntry)
+	if err != nil {
+		logger.Panic(err)
+	}
+}
+
+// FindBlockEvents looks up the events emitted during the execution of the given block.
+func (ch *Chain) FindBlockEvents(blockHash common.Hash) ([]*types.Event, bool) {
+	blockEventsEntry := &BlockEventsEntry{}
+	err := ch.store.Get(blockEventsKey(blockHash), blockEventsEntry)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	return blockEventsEntry.Events, true
+}
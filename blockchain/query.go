@@ -0,0 +1,257 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+//
+// The search queries are conjunctions of conditions on the attributes of the indexed items, e.g.
+//
+//     type=SendTx AND recipient=0x2e833968e5bb786ae419c4d13189fb081cc43bab AND height>1000000
+//
+// Each condition compares an attribute key with a value using one of the operators below. The
+// values can be quoted with single quotes, which is required if they contain spaces. The ordering
+// operators compare the values as integers, and the other operators compare them as strings,
+// case-insensitively.
+//
+
+// QueryOperator is the comparison operator of a query condition.
+type QueryOperator string
+
+const (
+	QueryOpEqual        QueryOperator = "="
+	QueryOpNotEqual     QueryOperator = "!="
+	QueryOpLess         QueryOperator = "<"
+	QueryOpLessEqual    QueryOperator = "<="
+	QueryOpGreater      QueryOperator = ">"
+	QueryOpGreaterEqual QueryOperator = ">="
+	QueryOpContains     QueryOperator = "CONTAINS"
+	QueryOpExists       QueryOperator = "EXISTS"
+)
+
+// QueryCondition is a single condition of a search query.
+type QueryCondition struct {
+	Key   string
+	Op    QueryOperator
+	Value string
+}
+
+// Query is a parsed search query.
+type Query struct {
+	Conditions []QueryCondition
+}
+
+// ParseQuery parses the given search query.
+func ParseQuery(s string) (*Query, error) {
+	tokens, err := tokenizeQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("Empty query")
+	}
+
+	q := &Query{}
+	for i := 0; i < len(tokens); {
+		if i > 0 {
+			if !strings.EqualFold(tokens[i], "AND") {
+				return nil, fmt.Errorf("Expected AND, got %v", tokens[i])
+			}
+			i++
+		}
+		if i+1 < len(tokens) && strings.EqualFold(tokens[i+1], string(QueryOpExists)) {
+			q.Conditions = append(q.Conditions, QueryCondition{Key: normalizeQueryKey(tokens[i]), Op: QueryOpExists})
+			i += 2
+			continue
+		}
+		if i+2 >= len(tokens) {
+			return nil, fmt.Errorf("Incomplete condition at the end of the query")
+		}
+		key, op, value := tokens[i], QueryOperator(strings.ToUpper(tokens[i+1])), tokens[i+2]
+		switch op {
+		case QueryOpEqual, QueryOpNotEqual, QueryOpContains:
+		case QueryOpLess, QueryOpLessEqual, QueryOpGreater, QueryOpGreaterEqual:
+			if _, ok := new(big.Int).SetString(value, 0); !ok {
+				return nil, fmt.Errorf("The value of %v %v must be an integer, got %v", key, op, value)
+			}
+		default:
+			return nil, fmt.Errorf("Invalid operator: %v", tokens[i+1])
+		}
+		q.Conditions = append(q.Conditions, QueryCondition{Key: normalizeQueryKey(key), Op: op, Value: value})
+		i += 3
+	}
+	return q, nil
+}
+
+// tokenizeQuery splits the query into the keys, operators, values and the AND keywords.
+func tokenizeQuery(s string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("Unterminated quote at position %v", i)
+			}
+			tokens = append(tokens, s[i+1:i+1+end])
+			i += end + 2
+		case c == '=' || c == '<' || c == '>' || c == '!':
+			j := i + 1
+			if j < len(s) && s[j] == '=' {
+				j++
+			}
+			if s[i:j] == "!" {
+				return nil, fmt.Errorf("Invalid operator at position %v", i)
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n'=<>!", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// normalizeQueryKey maps the aliases to the canonical attribute keys.
+func normalizeQueryKey(key string) string {
+	key = strings.ToLower(key)
+	switch key {
+	case "tx.height", "block.height":
+		return SearchKeyHeight
+	case "tx.type":
+		return SearchKeyType
+	}
+	return key
+}
+
+// Matches checks if all the conditions hold for the given attribute values. A condition on a
+// multi-valued attribute, e.g. the recipients of a SendTx, holds if it holds for any of the values.
+func (q *Query) Matches(values func(key string) []string) bool {
+	for _, cond := range q.Conditions {
+		if !cond.matchesAny(values(cond.Key)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cond QueryCondition) matchesAny(values []string) bool {
+	if cond.Op == QueryOpExists {
+		return len(values) > 0
+	}
+	if cond.Op == QueryOpNotEqual {
+		for _, value := range values {
+			if strings.EqualFold(value, cond.Value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, value := range values {
+		if cond.matches(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cond QueryCondition) matches(value string) bool {
+	switch cond.Op {
+	case QueryOpEqual:
+		return strings.EqualFold(value, cond.Value)
+	case QueryOpContains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(cond.Value))
+	}
+
+	x, ok := new(big.Int).SetString(value, 0)
+	if !ok {
+		return false
+	}
+	y, _ := new(big.Int).SetString(cond.Value, 0)
+	cmp := x.Cmp(y)
+	switch cond.Op {
+	case QueryOpLess:
+		return cmp < 0
+	case QueryOpLessEqual:
+		return cmp <= 0
+	case QueryOpGreater:
+		return cmp > 0
+	case QueryOpGreaterEqual:
+		return cmp >= 0
+	}
+	return false
+}
+
+// HeightRange returns the range of the block heights allowed by the conditions on the height,
+// both ends included. The second return value is false if no height satisfies the conditions.
+func (q *Query) HeightRange() (min uint64, max uint64, ok bool) {
+	min, max = 0, ^uint64(0)
+	for _, cond := range q.Conditions {
+		if cond.Key != SearchKeyHeight {
+			continue
+		}
+		v, valid := new(big.Int).SetString(cond.Value, 0)
+		if !valid {
+			if cond.Op == QueryOpEqual {
+				return 0, 0, false
+			}
+			continue
+		}
+		if v.Sign() < 0 {
+			v.SetUint64(0)
+			if cond.Op == QueryOpLess || cond.Op == QueryOpLessEqual || cond.Op == QueryOpEqual {
+				return 0, 0, false
+			}
+		}
+		if !v.IsUint64() {
+			if cond.Op == QueryOpGreater || cond.Op == QueryOpGreaterEqual || cond.Op == QueryOpEqual {
+				return 0, 0, false
+			}
+			continue
+		}
+		h := v.Uint64()
+		switch cond.Op {
+		case QueryOpEqual:
+			min, max = maxUint64(min, h), minUint64(max, h)
+		case QueryOpGreater:
+			if h == ^uint64(0) {
+				return 0, 0, false
+			}
+			min = maxUint64(min, h+1)
+		case QueryOpGreaterEqual:
+			min = maxUint64(min, h)
+		case QueryOpLess:
+			if h == 0 {
+				return 0, 0, false
+			}
+			max = minUint64(max, h-1)
+		case QueryOpLessEqual:
+			max = minUint64(max, h)
+		}
+	}
+	return min, max, min <= max
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxUint64(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
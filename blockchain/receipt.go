@@ -0,0 +1,35 @@
+package blockchain
+
+import (
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// canonicalTxReceiptKey constructs the DB key for the canonical receipt of the given transaction hash.
+func canonicalTxReceiptKey(hash common.Hash) common.Bytes {
+	return append(common.Bytes("ctxr/"), hash[:]...)
+}
+
+// AddCanonicalTxReceipts adds the canonical receipts of the transactions in a block.
+func (ch *Chain) AddCanonicalTxReceipts(receipts []*types.TxReceipt) {
+	for _, receipt := range receipts {
+		err := ch.store.Put(canonicalTxReceiptKey(receipt.TxHash), *receipt)
+		if err != nil {
+			logger.Panic(err)
+		}
+	}
+}
+
+// FindCanonicalTxReceiptByHash looks up the canonical receipt of the given transaction hash.
+func (ch *Chain) FindCanonicalTxReceiptByHash(hash common.Hash) (*types.TxReceipt, bool) {
+	receipt := &types.TxReceipt{}
+	err := ch.store.Get(canonicalTxReceiptKey(hash), receipt)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	return receipt, true
+}
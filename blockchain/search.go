@@ -0,0 +1,477 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+//
+// The search index covers the transactions and the ledger events of the finalized blocks. Each
+// indexed item is stored with its attributes, and each term, i.e. an attribute key/value pair
+// like "type=sendtx", maps to the posting list of the items that carry the term. The posting lists
+// are stored in chunks of fixed size, in the order of the block height, so that they can be
+// appended cheaply and range-scanned by height. The "*" term lists all the items.
+//
+
+const (
+	SearchItemTx    = "tx"
+	SearchItemEvent = "event"
+
+	SearchKeyHeight = "height"
+	SearchKeyType   = "type"
+	SearchKeyKind   = "kind"
+	SearchKeyTxHash = "tx.hash"
+
+	searchAllTerm   = "*"
+	searchChunkSize = 256
+
+	// SearchMaxScan is the max number of postings scanned by a single search. A search that hits
+	// the limit returns a cursor to resume the scan from.
+	SearchMaxScan = 10000
+)
+
+func searchIndexMetaKey() common.Bytes {
+	return common.Bytes("si/m")
+}
+
+func searchItemKey(id uint64) common.Bytes {
+	return append(common.Bytes("si/i/"), uint64ToBytes(id)...)
+}
+
+func searchTermKey(term string) common.Bytes {
+	return append(common.Bytes("si/t/"), term...)
+}
+
+func searchChunkKey(term string, chunk uint64) common.Bytes {
+	key := append(common.Bytes("si/c/"), term...)
+	key = append(key, '/')
+	return append(key, uint64ToBytes(chunk)...)
+}
+
+func uint64ToBytes(v uint64) common.Bytes {
+	b := make(common.Bytes, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// searchIndexMeta tracks the progress of the search index.
+type searchIndexMeta struct {
+	LastItemID uint64
+	LastHeight uint64
+	Started    bool
+}
+
+// searchTermHead records the number of postings of a term.
+type searchTermHead struct {
+	Count uint64
+}
+
+type searchPosting struct {
+	ItemID uint64
+	Height uint64
+}
+
+type searchChunk struct {
+	Postings []searchPosting
+}
+
+// SearchItem is a transaction or a ledger event in the search index.
+type SearchItem struct {
+	ID         uint64
+	Kind       string // SearchItemTx or SearchItemEvent
+	Type       string // the tx type, e.g. SendTx, or the event type
+	BlockHash  common.Hash
+	Height     uint64
+	Index      uint64      // the position of the tx in the block, or of the event among the block events
+	TxHash     common.Hash // empty for the events
+	Attributes []types.EventAttribute
+}
+
+// Values returns the values of the given attribute key.
+func (item *SearchItem) Values(key string) []string {
+	switch key {
+	case SearchKeyHeight:
+		return []string{strconv.FormatUint(item.Height, 10)}
+	case SearchKeyKind:
+		return []string{item.Kind}
+	case SearchKeyType:
+		return []string{item.Type}
+	}
+	values := []string{}
+	for _, attr := range item.Attributes {
+		if attr.Key == key {
+			values = append(values, attr.Value)
+		}
+	}
+	return values
+}
+
+// terms returns the terms under which the item is indexed.
+func (item *SearchItem) terms() []string {
+	seen := map[string]bool{searchAllTerm: true}
+	terms := []string{searchAllTerm}
+	add := func(key, value string) {
+		term := searchTerm(key, value)
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	add(SearchKeyKind, item.Kind)
+	add(SearchKeyType, item.Type)
+	for _, attr := range item.Attributes {
+		add(attr.Key, attr.Value)
+	}
+	return terms
+}
+
+func searchTerm(key, value string) string {
+	return strings.ToLower(key) + "=" + strings.ToLower(value)
+}
+
+// EnableSearchIndex turns on the indexing of the blocks finalized from now on.
+func (ch *Chain) EnableSearchIndex() {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.searchIndexEnabled = true
+}
+
+// SearchIndexEnabled returns whether the search index is enabled.
+func (ch *Chain) SearchIndexEnabled() bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+	return ch.searchIndexEnabled
+}
+
+// SearchIndexRange returns the heights of the first and the last blocks in the search index, the
+// first height is the height of the oldest indexed item. It returns false if nothing is indexed.
+func (ch *Chain) SearchIndexRange() (first uint64, last uint64, ok bool) {
+	meta := &searchIndexMeta{}
+	if err := ch.store.Get(searchIndexMetaKey(), meta); err != nil || !meta.Started || meta.LastItemID == 0 {
+		return 0, 0, false
+	}
+	item := &SearchItem{}
+	if err := ch.store.Get(searchItemKey(1), item); err != nil {
+		return 0, 0, false
+	}
+	return item.Height, meta.LastHeight, true
+}
+
+// AddBlockToSearchIndex indexes a finalized block, e.g. to backfill the search index. The blocks
+// must be indexed in the order of the height, the blocks already indexed are skipped.
+func (ch *Chain) AddBlockToSearchIndex(block *core.ExtendedBlock) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.addBlockToSearchIndex(block)
+}
+
+// addBlockToSearchIndex indexes the transactions and the events of a finalized block. The blocks
+// must be indexed in the order of the height.
+func (ch *Chain) addBlockToSearchIndex(block *core.ExtendedBlock) {
+	meta := &searchIndexMeta{}
+	if err := ch.store.Get(searchIndexMetaKey(), meta); err != nil && err != store.ErrKeyNotFound {
+		logger.Panic(err)
+	}
+	if meta.Started && block.Height <= meta.LastHeight {
+		return // already indexed
+	}
+
+	items := []*SearchItem{}
+	for idx, raw := range block.Txs {
+		tx, err := types.TxFromBytes(raw)
+		if err != nil {
+			logger.Warnf("Failed to decode tx %v of block %v for the search index: %v", idx, block.Hash().Hex(), err)
+			continue
+		}
+		txHash := crypto.Keccak256Hash(raw)
+		receipt, _ := ch.FindTxReceiptByHash(txHash)
+		items = append(items, &SearchItem{
+			Kind:       SearchItemTx,
+			Type:       reflect.TypeOf(tx).Elem().Name(),
+			BlockHash:  block.Hash(),
+			Height:     block.Height,
+			Index:      uint64(idx),
+			TxHash:     txHash,
+			Attributes: txSearchAttributes(tx, txHash, receipt),
+		})
+	}
+	events, _ := ch.FindBlockEvents(block.Hash())
+	for idx, event := range events {
+		items = append(items, &SearchItem{
+			Kind:       SearchItemEvent,
+			Type:       event.Type,
+			BlockHash:  block.Hash(),
+			Height:     block.Height,
+			Index:      uint64(idx),
+			Attributes: event.Attributes,
+		})
+	}
+
+	// The items are stored before the postings, so that the searches running concurrently never
+	// see a posting of a missing item
+	postings := make(map[string][]searchPosting)
+	for _, item := range items {
+		meta.LastItemID++
+		item.ID = meta.LastItemID
+		if err := ch.store.Put(searchItemKey(item.ID), *item); err != nil {
+			logger.Panic(err)
+		}
+		for _, term := range item.terms() {
+			postings[term] = append(postings[term], searchPosting{ItemID: item.ID, Height: item.Height})
+		}
+	}
+	for term, ps := range postings {
+		ch.appendSearchPostings(term, ps)
+	}
+
+	meta.LastHeight = block.Height
+	meta.Started = true
+	if err := ch.store.Put(searchIndexMetaKey(), *meta); err != nil {
+		logger.Panic(err)
+	}
+}
+
+func (ch *Chain) appendSearchPostings(term string, postings []searchPosting) {
+	head := ch.loadSearchTermHead(term)
+	for len(postings) > 0 {
+		chunkIdx := head.Count / searchChunkSize
+		chunk := ch.loadSearchChunk(term, chunkIdx)
+		n := searchChunkSize - len(chunk.Postings)
+		if n > len(postings) {
+			n = len(postings)
+		}
+		chunk.Postings = append(chunk.Postings, postings[:n]...)
+		if err := ch.store.Put(searchChunkKey(term, chunkIdx), *chunk); err != nil {
+			logger.Panic(err)
+		}
+		head.Count += uint64(n)
+		postings = postings[n:]
+	}
+	if err := ch.store.Put(searchTermKey(term), *head); err != nil {
+		logger.Panic(err)
+	}
+}
+
+func (ch *Chain) loadSearchTermHead(term string) *searchTermHead {
+	head := &searchTermHead{}
+	if err := ch.store.Get(searchTermKey(term), head); err != nil && err != store.ErrKeyNotFound {
+		logger.Panic(err)
+	}
+	return head
+}
+
+func (ch *Chain) loadSearchChunk(term string, chunkIdx uint64) *searchChunk {
+	chunk := &searchChunk{}
+	if err := ch.store.Get(searchChunkKey(term, chunkIdx), chunk); err != nil && err != store.ErrKeyNotFound {
+		logger.Panic(err)
+	}
+	return chunk
+}
+
+// Search returns up to limit indexed items that match the query, in the order of the block height,
+// starting after the item with the cursor ID. The returned cursor resumes the search, and is zero
+// if there are no more items to scan.
+func (ch *Chain) Search(q *Query, cursor uint64, limit int) ([]*SearchItem, uint64) {
+	items := []*SearchItem{}
+	minHeight, maxHeight, ok := q.HeightRange()
+	if !ok || limit <= 0 {
+		return items, 0
+	}
+
+	// Scan the shortest posting list among the equality conditions. The posting lists are append
+	// only, so they can be read without holding the chain lock.
+	term := searchAllTerm
+	head := ch.loadSearchTermHead(term)
+	for _, cond := range q.Conditions {
+		if cond.Op != QueryOpEqual || cond.Key == SearchKeyHeight {
+			continue
+		}
+		t := searchTerm(cond.Key, cond.Value)
+		if h := ch.loadSearchTermHead(t); h.Count < head.Count {
+			term, head = t, h
+		}
+	}
+	if head.Count == 0 {
+		return items, 0
+	}
+
+	numChunks := int((head.Count + searchChunkSize - 1) / searchChunkSize)
+	after := func(p searchPosting) bool {
+		return p.ItemID > cursor && p.Height >= minHeight
+	}
+	chunkIdx := sort.Search(numChunks, func(i int) bool {
+		postings := ch.loadSearchChunk(term, uint64(i)).Postings
+		return len(postings) > 0 && after(postings[len(postings)-1])
+	})
+
+	scanned := 0
+	for ; chunkIdx < numChunks; chunkIdx++ {
+		postings := ch.loadSearchChunk(term, uint64(chunkIdx)).Postings
+		for _, p := range postings {
+			if !after(p) {
+				continue
+			}
+			if p.Height > maxHeight {
+				return items, 0
+			}
+			if scanned >= SearchMaxScan {
+				return items, cursor
+			}
+			scanned++
+			cursor = p.ItemID
+
+			item := &SearchItem{}
+			if err := ch.store.Get(searchItemKey(p.ItemID), item); err != nil {
+				logger.Errorf("Failed to load search item %v: %v", p.ItemID, err)
+				continue
+			}
+			if !q.Matches(item.Values) {
+				continue
+			}
+			items = append(items, item)
+			if len(items) >= limit {
+				return items, cursor
+			}
+		}
+	}
+	return items, 0
+}
+
+// txSearchAttributes extracts the attributes of a transaction for the search index.
+func txSearchAttributes(tx types.Tx, txHash common.Hash, receipt *TxReceiptEntry) []types.EventAttribute {
+	attrs := []types.EventAttribute{{Key: SearchKeyTxHash, Value: txHash.Hex()}}
+	add := func(key string, value string) {
+		attrs = append(attrs, types.EventAttribute{Key: key, Value: value})
+	}
+	sender := func(addr common.Address) {
+		add("sender", addr.Hex())
+		add("account", addr.Hex())
+	}
+	recipient := func(addr common.Address) {
+		add("recipient", addr.Hex())
+		add("account", addr.Hex())
+	}
+
+	switch tx := tx.(type) {
+	case *types.CoinbaseTx:
+		sender(tx.Proposer.Address)
+		for _, output := range tx.Outputs {
+			recipient(output.Address)
+		}
+	case *types.SlashTx:
+		sender(tx.Proposer.Address)
+		add("slashed", tx.SlashedAddress.Hex())
+		add("account", tx.SlashedAddress.Hex())
+	case *types.SendTx:
+		for _, input := range tx.Inputs {
+			sender(input.Address)
+		}
+		for _, output := range tx.Outputs {
+			recipient(output.Address)
+		}
+	case *types.MultiSigSendTx:
+		sender(tx.Input.Address)
+		for _, output := range tx.Outputs {
+			recipient(output.Address)
+		}
+	case *types.BatchSendTx:
+		sender(tx.Input.Address)
+		for _, output := range tx.Outputs {
+			recipient(output.Address)
+		}
+	case *types.ReserveFundTx:
+		sender(tx.Source.Address)
+		for _, resourceID := range tx.ResourceIDs {
+			add("resource_id", resourceID)
+		}
+	case *types.ReserveFundTxV2:
+		sender(tx.Source.Address)
+		for _, resourceID := range tx.ResourceIDs {
+			add("resource_id", resourceID)
+		}
+	case *types.ReleaseFundTx:
+		sender(tx.Source.Address)
+	case *types.ServicePaymentTx:
+		sender(tx.Source.Address)
+		recipient(tx.Target.Address)
+		add("resource_id", tx.ResourceID)
+	case *types.SplitRuleTx:
+		sender(tx.Initiator.Address)
+		add("resource_id", tx.ResourceID)
+	case *types.SplitRuleRenewalTx:
+		sender(tx.Initiator.Address)
+		add("resource_id", tx.ResourceID)
+	case *types.SmartContractTx:
+		sender(tx.From.Address)
+		recipient(tx.To.Address)
+	case *types.SmartContractTxV2:
+		sender(tx.From.Address)
+		recipient(tx.To.Address)
+	case *types.DepositStakeTx:
+		sender(tx.Source.Address)
+		recipient(tx.Holder.Address)
+		add("purpose", strconv.Itoa(int(tx.Purpose)))
+	case *types.DepositStakeTxV2:
+		sender(tx.Source.Address)
+		recipient(tx.Holder.Address)
+		add("purpose", strconv.Itoa(int(tx.Purpose)))
+	case *types.WithdrawStakeTx:
+		sender(tx.Source.Address)
+		recipient(tx.Holder.Address)
+		add("purpose", strconv.Itoa(int(tx.Purpose)))
+	case *types.StakeRewardDistributionTx:
+		sender(tx.Holder.Address)
+		recipient(tx.Beneficiary.Address)
+	case *types.VestingTransferTx:
+		sender(tx.Source.Address)
+		recipient(tx.Beneficiary)
+	case *types.VestingClaimTx:
+		sender(tx.Beneficiary.Address)
+		add("fund_id", tx.FundID.Hex())
+	case *types.TokenRegistryTx:
+		sender(tx.Registrar.Address)
+		add("namespace", tx.Namespace)
+		add("symbol", tx.Symbol)
+		add("contract", tx.ContractAddress.Hex())
+	case *types.ValidatorKeyRotationTx:
+		sender(tx.Validator.Address)
+		add("signing_address", tx.SigningAddress.Hex())
+	case *types.IBCTx:
+		sender(tx.Relayer.Address)
+		add("ibc_msg_type", strconv.Itoa(int(tx.MsgType)))
+	case *types.BandwidthProofTx:
+		sender(tx.Pool.Address)
+		recipient(tx.EdgeNode.Address)
+		add("resource_id", tx.ResourceID)
+	case *types.GovProposalTx:
+		sender(tx.Proposer.Address)
+		add("gov_proposal_type", tx.ProposalType.String())
+	case *types.GovVoteTx:
+		sender(tx.Voter.Address)
+		add("gov_proposal_id", strconv.FormatUint(tx.ProposalID, 10))
+	}
+
+	if receipt != nil {
+		if receipt.ContractAddress != (common.Address{}) {
+			add("contract", receipt.ContractAddress.Hex())
+		}
+		for _, log := range receipt.Logs {
+			add("log.address", log.Address.Hex())
+		}
+		if receipt.EvmErr != "" {
+			add("status", "failed")
+		} else {
+			add("status", "success")
+		}
+	}
+	return attrs
+}
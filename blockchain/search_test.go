@@ -0,0 +1,134 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestParseQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	q, err := ParseQuery("type=SendTx AND recipient = '0xabc' and tx.height>1000000")
+	require.Nil(t, err)
+	assert.Equal([]QueryCondition{
+		{Key: "type", Op: QueryOpEqual, Value: "SendTx"},
+		{Key: "recipient", Op: QueryOpEqual, Value: "0xabc"},
+		{Key: "height", Op: QueryOpGreater, Value: "1000000"},
+	}, q.Conditions)
+
+	q, err = ParseQuery("resource_id CONTAINS 'movie 1' AND purpose EXISTS AND height<=20")
+	require.Nil(t, err)
+	assert.Equal([]QueryCondition{
+		{Key: "resource_id", Op: QueryOpContains, Value: "movie 1"},
+		{Key: "purpose", Op: QueryOpExists},
+		{Key: "height", Op: QueryOpLessEqual, Value: "20"},
+	}, q.Conditions)
+
+	for _, s := range []string{"", "type", "type=", "type=SendTx AND", "type=SendTx OR height>1", "height>abc", "type ! SendTx", "type='SendTx"} {
+		_, err = ParseQuery(s)
+		assert.NotNil(err, s)
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	assert := assert.New(t)
+
+	values := map[string][]string{
+		"type":      {"SendTx"},
+		"height":    {"1500"},
+		"recipient": {"0xAAA", "0xBBB"},
+	}
+	matches := func(s string) bool {
+		q, err := ParseQuery(s)
+		require.Nil(t, err)
+		return q.Matches(func(key string) []string { return values[key] })
+	}
+
+	assert.True(matches("type=sendtx AND recipient=0xbbb"))
+	assert.True(matches("height>=1500 AND height<1501"))
+	assert.True(matches("recipient CONTAINS aa AND type EXISTS"))
+	assert.True(matches("recipient!=0xccc"))
+	assert.False(matches("recipient!=0xaaa"))
+	assert.False(matches("type=SendTx AND height>1500"))
+	assert.False(matches("sender EXISTS"))
+
+	q, err := ParseQuery("height>10 AND height<=20 AND type=SendTx")
+	require.Nil(t, err)
+	min, max, ok := q.HeightRange()
+	assert.True(ok)
+	assert.Equal(uint64(11), min)
+	assert.Equal(uint64(20), max)
+
+	q, err = ParseQuery("height>20 AND height<10")
+	require.Nil(t, err)
+	_, _, ok = q.HeightRange()
+	assert.False(ok)
+}
+
+func TestSearchIndex(t *testing.T) {
+	assert := assert.New(t)
+	core.ResetTestBlocks()
+
+	chain := CreateTestChainByBlocks([]string{
+		"a1", "a0",
+		"a2", "a1",
+		"a3", "a2",
+	})
+	chain.EnableSearchIndex()
+	_, _, ok := chain.SearchIndexRange()
+	assert.False(ok)
+
+	expired := func(resourceID string) *types.Event {
+		return &types.Event{
+			Type:       "SplitRuleExpired",
+			Attributes: []types.EventAttribute{{Key: "resource_id", Value: resourceID}},
+		}
+	}
+	chain.AddBlockEvents(core.CreateTestBlock("a1", "a0").Hash(), []*types.Event{expired("r1"), expired("r2")})
+	chain.AddBlockEvents(core.CreateTestBlock("a3", "a2").Hash(), []*types.Event{expired("r1")})
+	require.Nil(t, chain.FinalizePreviousBlocks(core.CreateTestBlock("a3", "a2").Hash()))
+
+	first, last, ok := chain.SearchIndexRange()
+	assert.True(ok)
+	assert.Equal(uint64(1), first)
+	assert.Equal(uint64(3), last)
+
+	search := func(s string, cursor uint64, limit int) ([]*SearchItem, uint64) {
+		q, err := ParseQuery(s)
+		require.Nil(t, err)
+		return chain.Search(q, cursor, limit)
+	}
+
+	items, next := search("type=SplitRuleExpired", 0, 10)
+	assert.Equal(3, len(items))
+	assert.Equal(uint64(0), next)
+
+	items, _ = search("resource_id=R1", 0, 10)
+	require.Equal(t, 2, len(items))
+	assert.Equal(uint64(1), items[0].Height)
+	assert.Equal(uint64(3), items[1].Height)
+
+	items, _ = search("resource_id=r1 AND height>1", 0, 10)
+	require.Equal(t, 1, len(items))
+	assert.Equal(uint64(3), items[0].Height)
+
+	// Paginate
+	items, next = search("kind=event", 0, 2)
+	assert.Equal(2, len(items))
+	assert.NotEqual(uint64(0), next)
+	items, next = search("kind=event", next, 2)
+	require.Equal(t, 1, len(items))
+	assert.Equal("r1", items[0].Values("resource_id")[0])
+	assert.Equal(uint64(0), next)
+
+	// Finalizing the same blocks again doesn't index them twice
+	a3, err := chain.FindBlock(core.CreateTestBlock("a3", "a2").Hash())
+	require.Nil(t, err)
+	chain.addBlockToSearchIndex(a3)
+	items, _ = search("type EXISTS", 0, 10)
+	assert.Equal(3, len(items))
+}
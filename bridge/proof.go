@@ -0,0 +1,240 @@
+// Package bridge builds and verifies the proof bundles which allow external chains to trustlessly
+// verify the transactions and events of Theta. A bundle contains the header of a block, the commit
+// certificates which finalize the block, and the Merkle proofs of the selected transactions and
+// their receipts against the transaction and receipt root hashes of the block header. Given the
+// validator set of the block, e.g. tracked by the light client of the external chain, the bundle
+// can be verified without trusting the node which produced it.
+package bridge
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store/database"
+	"github.com/thetatoken/theta/store/trie"
+)
+
+//
+// ------- MerkleProof ------- //
+//
+
+// MerkleProof is the list of the encoded trie nodes on the path from the root to an item. The nodes
+// are looked up by their hashes, so the proof does not need to carry the keys.
+type MerkleProof []common.Bytes
+
+var _ database.Putter = (*MerkleProof)(nil)
+
+// Put implements the database.Putter interface, so that the proof can be filled by trie.Prove()
+func (mp *MerkleProof) Put(key []byte, value []byte) error {
+	*mp = append(*mp, common.CopyBytes(value))
+	return nil
+}
+
+var _ trie.DatabaseReader = (*MerkleProof)(nil)
+
+// Get returns the trie node with the given hash
+func (mp MerkleProof) Get(key []byte) ([]byte, error) {
+	for _, node := range mp {
+		if bytes.Equal(crypto.Keccak256(node), key) {
+			return node, nil
+		}
+	}
+	return nil, errors.Errorf("Proof node %v not found", hex.EncodeToString(key))
+}
+
+// Has returns whether the proof contains the trie node with the given hash
+func (mp MerkleProof) Has(key []byte) (bool, error) {
+	_, err := mp.Get(key)
+	return err == nil, nil
+}
+
+// itemKey returns the trie key of the item at the index, as in core.CalculateRootHash()
+func itemKey(index uint64) common.Bytes {
+	key, _ := rlp.EncodeToBytes(uint(index))
+	return key
+}
+
+// ProveItem builds the Merkle proof of the item at the index against the root hash calculated by
+// core.CalculateRootHash(), e.g. the TxHash or the ReceiptHash of a block.
+func ProveItem(items []common.Bytes, index uint64) (MerkleProof, error) {
+	if index >= uint64(len(items)) {
+		return nil, errors.Errorf("Item index %v out of range, there are %v items", index, len(items))
+	}
+	tr := new(trie.Trie)
+	for i, item := range items {
+		tr.Update(itemKey(uint64(i)), item)
+	}
+	proof := MerkleProof{}
+	if err := tr.Prove(itemKey(index), 0, &proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// VerifyItem checks that the item is at the index of the trie with the given root hash
+func VerifyItem(rootHash common.Hash, index uint64, item common.Bytes, proof MerkleProof) error {
+	value, _, err := trie.VerifyProof(rootHash, itemKey(index), proof)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return errors.Errorf("Item %v is not in the trie", index)
+	}
+	if !bytes.Equal(value, item) {
+		return errors.Errorf("Item %v does not match the proof", index)
+	}
+	return nil
+}
+
+//
+// ------- ProofBundle ------- //
+//
+
+// TxProof proves that a transaction is included in the block
+type TxProof struct {
+	Index uint64
+	Tx    common.Bytes // the raw transaction
+	Proof MerkleProof
+}
+
+// ReceiptProof proves the receipt of a transaction in the block, along with the events it emitted
+type ReceiptProof struct {
+	Index   uint64
+	Receipt common.Bytes // the encoded types.TxReceipt
+	Proof   MerkleProof
+}
+
+// DecodeReceipt decodes the receipt. The receipt should only be trusted once the bundle is verified.
+func (rp *ReceiptProof) DecodeReceipt() (*types.TxReceipt, error) {
+	receipt := &types.TxReceipt{}
+	if err := types.FromBytes(rp.Receipt, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// ProofBundle proves that the block is finalized, and that the transactions and receipts are
+// committed by the block. Following the finalization rule of the consensus, a block is finalized
+// if its child block is committed, and links to the block by both its Parent and its HCC.
+type ProofBundle struct {
+	Header      *core.BlockHeader
+	ChildHeader *core.BlockHeader      // carries the commit certificate of the block in its HCC
+	ChildCC     core.CommitCertificate // the commit certificate of the child block
+	Txs         []TxProof
+	Receipts    []ReceiptProof
+}
+
+// NewProofBundle creates the bundle proving the transactions of the block at the given indices.
+// The receipts of the block transactions, in the block order, are proven along if given; they are
+// only committed by the blocks from common.HeightEnableTxReceiptRoot on.
+func NewProofBundle(block *core.Block, childHeader *core.BlockHeader, childCC core.CommitCertificate,
+	receipts []*types.TxReceipt, indices []uint64) (*ProofBundle, error) {
+	pb := &ProofBundle{
+		Header:      block.BlockHeader,
+		ChildHeader: childHeader,
+		ChildCC:     childCC,
+		Txs:         []TxProof{},
+		Receipts:    []ReceiptProof{},
+	}
+
+	for _, index := range indices {
+		proof, err := ProveItem(block.Txs, index)
+		if err != nil {
+			return nil, err
+		}
+		pb.Txs = append(pb.Txs, TxProof{
+			Index: index,
+			Tx:    block.Txs[index],
+			Proof: proof,
+		})
+	}
+
+	if len(receipts) == 0 {
+		return pb, nil
+	}
+	if block.Height < common.HeightEnableTxReceiptRoot {
+		return nil, errors.Errorf("Receipts are not committed by the blocks below height %v", common.HeightEnableTxReceiptRoot)
+	}
+	receiptBytes := []common.Bytes{}
+	for _, receipt := range receipts {
+		raw, err := types.ToBytes(receipt)
+		if err != nil {
+			return nil, err
+		}
+		receiptBytes = append(receiptBytes, raw)
+	}
+	for _, index := range indices {
+		proof, err := ProveItem(receiptBytes, index)
+		if err != nil {
+			return nil, err
+		}
+		pb.Receipts = append(pb.Receipts, ReceiptProof{
+			Index:   index,
+			Receipt: receiptBytes[index],
+			Proof:   proof,
+		})
+	}
+	return pb, nil
+}
+
+// Verify checks that the block is finalized by the given validator set, and that the transactions
+// and receipts of the bundle are committed by the block. The validator set must come from a trusted
+// source, since the bundle is only as trustworthy as the validator set it is verified against.
+func (pb *ProofBundle) Verify(chainID string, validators *core.ValidatorSet) error {
+	if pb.Header == nil || pb.ChildHeader == nil {
+		return errors.New("Block header is missing")
+	}
+	if pb.Header.ChainID != chainID || pb.ChildHeader.ChainID != chainID {
+		return errors.Errorf("ChainID mismatch, expected %v", chainID)
+	}
+
+	blockHash := pb.Header.Hash()
+	if pb.ChildHeader.Parent != blockHash || pb.ChildHeader.HCC.BlockHash != blockHash {
+		return errors.Errorf("Child block does not link to block %v", blockHash.Hex())
+	}
+	if pb.ChildHeader.Height != pb.Header.Height+1 {
+		return errors.Errorf("Child block height %v does not follow block height %v", pb.ChildHeader.Height, pb.Header.Height)
+	}
+	if !pb.ChildHeader.HCC.IsValid(validators) {
+		return errors.Errorf("Invalid commit certificate for block %v", blockHash.Hex())
+	}
+	childHash := pb.ChildHeader.Hash()
+	if pb.ChildCC.BlockHash != childHash || !pb.ChildCC.IsValid(validators) {
+		return errors.Errorf("Invalid commit certificate for child block %v", childHash.Hex())
+	}
+
+	for _, tp := range pb.Txs {
+		if err := VerifyItem(pb.Header.TxHash, tp.Index, tp.Tx, tp.Proof); err != nil {
+			return errors.Wrapf(err, "Invalid proof for transaction %v", tp.Index)
+		}
+	}
+	if len(pb.Receipts) > 0 && pb.Header.Height < common.HeightEnableTxReceiptRoot {
+		return errors.Errorf("Receipts are not committed by the blocks below height %v", common.HeightEnableTxReceiptRoot)
+	}
+	for _, rp := range pb.Receipts {
+		if err := VerifyItem(pb.Header.ReceiptHash, rp.Index, rp.Receipt, rp.Proof); err != nil {
+			return errors.Wrapf(err, "Invalid proof for receipt %v", rp.Index)
+		}
+	}
+	return nil
+}
+
+// Encode serializes the bundle
+func (pb *ProofBundle) Encode() (common.Bytes, error) {
+	return rlp.EncodeToBytes(pb)
+}
+
+// DecodeProofBundle deserializes the bundle
+func DecodeProofBundle(raw common.Bytes) (*ProofBundle, error) {
+	pb := &ProofBundle{}
+	if err := rlp.DecodeBytes(raw, pb); err != nil {
+		return nil, err
+	}
+	return pb, nil
+}
@@ -0,0 +1,142 @@
+package bridge
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestProveItem(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, numItems := range []int{1, 2, 17, 300} {
+		items := []common.Bytes{}
+		for i := 0; i < numItems; i++ {
+			items = append(items, common.Bytes{byte(i), byte(i >> 8), 0xff})
+		}
+		root := core.CalculateRootHash(items)
+
+		for _, index := range []int{0, numItems / 2, numItems - 1} {
+			proof, err := ProveItem(items, uint64(index))
+			assert.Nil(err)
+			assert.Nil(VerifyItem(root, uint64(index), items[index], proof))
+			assert.NotNil(VerifyItem(root, uint64(index), common.Bytes{0x01}, proof))
+			assert.NotNil(VerifyItem(common.Hash{}, uint64(index), items[index], proof))
+		}
+	}
+
+	_, err := ProveItem([]common.Bytes{{0x01}}, 1)
+	assert.NotNil(err)
+}
+
+func TestProofBundle(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID := "testchain"
+	privs, validators := createTestValidators(4)
+
+	txs := []common.Bytes{}
+	receipts := []*types.TxReceipt{}
+	receiptBytes := []common.Bytes{}
+	for i := 0; i < 5; i++ {
+		tx := common.Bytes{byte(i), 0x01, 0x02}
+		receipt := &types.TxReceipt{
+			TxHash: crypto.Keccak256Hash(tx),
+			Logs: []*types.Log{{
+				Address: common.HexToAddress("0x1234"),
+				Topics:  []common.Hash{common.HexToHash("0xabcd")},
+				Data:    []byte{byte(i)},
+			}},
+		}
+		raw, err := types.ToBytes(receipt)
+		require.Nil(err)
+		txs = append(txs, tx)
+		receipts = append(receipts, receipt)
+		receiptBytes = append(receiptBytes, raw)
+	}
+
+	block := core.NewBlock()
+	block.ChainID = chainID
+	block.Height = common.HeightEnableTxReceiptRoot
+	block.Parent = common.HexToHash("0x01")
+	block.HCC.BlockHash = block.Parent
+	block.Timestamp = big.NewInt(1)
+	block.AddTxs(txs)
+	block.ReceiptHash = core.CalculateRootHash(receiptBytes)
+
+	child := core.NewBlock()
+	child.ChainID = chainID
+	child.Height = block.Height + 1
+	child.Parent = block.Hash()
+	child.HCC = core.CommitCertificate{Votes: signVotes(privs, block.Hash()), BlockHash: block.Hash()}
+	child.Timestamp = big.NewInt(2)
+	childCC := core.CommitCertificate{Votes: signVotes(privs, child.Hash()), BlockHash: child.Hash()}
+
+	pb, err := NewProofBundle(block, child.BlockHeader, childCC, receipts, []uint64{1, 4})
+	require.Nil(err)
+	assert.Nil(pb.Verify(chainID, validators))
+
+	raw, err := pb.Encode()
+	require.Nil(err)
+	decoded, err := DecodeProofBundle(raw)
+	require.Nil(err)
+	assert.Nil(decoded.Verify(chainID, validators))
+	assert.Equal(txs[4], decoded.Txs[1].Tx)
+	receipt, err := decoded.Receipts[1].DecodeReceipt()
+	require.Nil(err)
+	assert.Equal(receipts[4].TxHash, receipt.TxHash)
+	assert.Equal([]byte{4}, receipt.Logs[0].Data)
+
+	// Wrong chain
+	assert.NotNil(pb.Verify("otherchain", validators))
+
+	// Not finalized by the validators
+	otherPrivs, otherValidators := createTestValidators(4)
+	assert.NotNil(pb.Verify(chainID, otherValidators))
+	forged, _ := DecodeProofBundle(raw)
+	forged.ChildCC = core.CommitCertificate{Votes: signVotes(otherPrivs, child.Hash()), BlockHash: child.Hash()}
+	assert.NotNil(forged.Verify(chainID, validators))
+
+	// Tampered transaction
+	forged, _ = DecodeProofBundle(raw)
+	forged.Txs[0].Tx = common.Bytes{0xff}
+	assert.NotNil(forged.Verify(chainID, validators))
+
+	// Tampered receipt
+	forged, _ = DecodeProofBundle(raw)
+	forged.Receipts[0].Index = 2
+	assert.NotNil(forged.Verify(chainID, validators))
+
+	// Receipts are not committed before the receipt root is enabled
+	block.Height = common.HeightEnableTxReceiptRoot - 1
+	_, err = NewProofBundle(block, child.BlockHeader, childCC, receipts, []uint64{1})
+	assert.NotNil(err)
+}
+
+func createTestValidators(num int) ([]*crypto.PrivateKey, *core.ValidatorSet) {
+	privs := []*crypto.PrivateKey{}
+	validators := core.NewValidatorSet()
+	for i := 0; i < num; i++ {
+		priv, _, _ := crypto.GenerateKeyPair()
+		privs = append(privs, priv)
+		validators.AddValidator(core.NewValidator(priv.PublicKey().Address().Hex(), new(big.Int).SetUint64(1e18)))
+	}
+	return privs, validators
+}
+
+func signVotes(privs []*crypto.PrivateKey, blockHash common.Hash) *core.VoteSet {
+	votes := core.NewVoteSet()
+	for _, priv := range privs {
+		vote := core.Vote{ID: priv.PublicKey().Address(), Block: blockHash}
+		vote.Sign(priv)
+		votes.AddVote(vote)
+	}
+	return votes
+}
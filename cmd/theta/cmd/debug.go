@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/diagnostic"
+	"github.com/thetatoken/theta/rpc"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+var (
+	debugEndpointFlag string
+	debugOutputFlag   string
+)
+
+// debugCmd groups the commands which help troubleshooting a node
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Troubleshoot Theta node.",
+}
+
+// debugDumpCmd represents the debug dump command
+var debugDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Write a diagnostic bundle of the running node.",
+	Long: `Write a diagnostic bundle of the running node to a file, to be attached to a support ticket.
+The bundle holds the stack traces of all the goroutines, the recent logs, the state of the chain
+and the config with the secrets redacted. It is the same bundle the node writes to the "crash"
+folder of its config folder when it crashes.
+
+The bundle is requested from the admin RPC listener of the node, which requires the admin
+namespace to be enabled. If the node cannot be reached, only the config is dumped.`,
+	Example: `theta debug dump --config=./node --output=./bundle.txt`,
+	Run:     runDebugDump,
+}
+
+func init() {
+	RootCmd.AddCommand(debugCmd)
+	debugCmd.AddCommand(debugDumpCmd)
+
+	debugDumpCmd.Flags().StringVar(&debugEndpointFlag, "endpoint", "", "the RPC endpoint of the node (default is the admin listener in the config)")
+	debugDumpCmd.Flags().StringVar(&debugOutputFlag, "output", "", "the file of the bundle (default is theta-diagnostic-<time>.txt)")
+}
+
+func runDebugDump(cmd *cobra.Command, args []string) {
+	endpoint := debugEndpointFlag
+	if endpoint == "" {
+		endpoint = adminEndpoint()
+	}
+	output := debugOutputFlag
+	if output == "" {
+		output = fmt.Sprintf("theta-diagnostic-%v.txt", time.Now().UTC().Format("20060102-150405"))
+	}
+
+	bundle, err := requestDiagnosticBundle(endpoint)
+	if err != nil {
+		log.Warnf("Failed to get the diagnostic bundle from %v: %v", endpoint, err)
+		file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			log.Fatalf("Failed to create %v: %v", output, err)
+		}
+		defer file.Close()
+		if err := diagnostic.WriteBundle(file, fmt.Sprintf("node unreachable at %v: %v", endpoint, err)); err != nil {
+			log.Fatalf("Failed to write the diagnostic bundle: %v", err)
+		}
+		fmt.Printf("The node is unreachable, the bundle of the local config is written to %v\n", output)
+		return
+	}
+
+	if err := ioutil.WriteFile(output, []byte(bundle), 0600); err != nil {
+		log.Fatalf("Failed to write the diagnostic bundle: %v", err)
+	}
+	fmt.Printf("Diagnostic bundle written to %v\n", output)
+}
+
+// adminEndpoint returns the endpoint of the admin listener, or of the main listener if the admin
+// listener is disabled.
+func adminEndpoint() string {
+	port := viper.GetString(common.CfgRPCAdminPort)
+	if port == "" {
+		return fmt.Sprintf("http://127.0.0.1:%v/rpc", viper.GetString(common.CfgRPCPort))
+	}
+	return fmt.Sprintf("http://%v:%v/rpc", viper.GetString(common.CfgRPCAdminAddress), port)
+}
+
+func requestDiagnosticBundle(endpoint string) (string, error) {
+	client := rpcc.NewRPCClient(endpoint)
+	res, err := client.Call("admin.GetDiagnosticBundle", rpc.GetDiagnosticBundleArgs{})
+	if err != nil {
+		return "", err
+	}
+	if res.Error != nil {
+		return "", res.Error
+	}
+	result := rpc.GetDiagnosticBundleResult{}
+	if err := res.GetObject(&result); err != nil {
+		return "", err
+	}
+	return result.Bundle, nil
+}
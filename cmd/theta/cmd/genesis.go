@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/snapshot"
+)
+
+var (
+	genesisSpecFlag   string
+	genesisFileFlag   string
+	genesisOutputFlag string
+	genesisHashFlag   string
+)
+
+// genesisCmd groups the commands of the genesis snapshots of private networks
+var genesisCmd = &cobra.Command{
+	Use:   "genesis",
+	Short: "Generate, validate and inspect genesis snapshots.",
+	Long: `Generate, validate and inspect the genesis snapshots of private networks. A genesis snapshot is
+generated from a JSON spec of the chain ID, the timestamp of the genesis block, the initial
+balances and the initial validators, e.g.
+
+{
+  "chain_id": "privatenet",
+  "timestamp": 1600000000,
+  "accounts": [
+    {"address": "0x2E833968E5bB786Ae419c4d13189fB081Cc43bab", "theta_wei": "20000000000000000000000000", "tfuel_wei": "100000000000000000000000000"}
+  ],
+  "validators": [
+    {"address": "0x2E833968E5bB786Ae419c4d13189fB081Cc43bab", "stake": "10000000000000000000000000"}
+  ],
+  "upgrades": [
+    {"name": "theta3", "height": "1"}
+  ]
+}
+
+The stake of a validator is taken from the balance of its "stake_source", or of the validator
+itself if not set. The optional "upgrades" set the activation heights of the protocol upgrades,
+which otherwise activate at their mainnet heights. The same spec always generates the same
+genesis block hash.`,
+}
+
+var genesisGenerateCmd = &cobra.Command{
+	Use:     "generate",
+	Short:   "Generate a genesis snapshot from a JSON spec.",
+	Example: `theta genesis generate --spec=./genesis.json --output=./genesis`,
+	Run:     runGenesisGenerate,
+}
+
+var genesisValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a genesis snapshot.",
+	Long: `Validate a genesis snapshot: its state must match the state hash of the genesis block. With
+--hash, the genesis block hash must match the given hash. With --spec, the snapshot must be the
+one generated from the spec.`,
+	Example: `theta genesis validate --file=./genesis --spec=./genesis.json`,
+	Run:     runGenesisValidate,
+}
+
+var genesisInspectCmd = &cobra.Command{
+	Use:     "inspect",
+	Short:   "Print a summary of a genesis snapshot.",
+	Example: `theta genesis inspect --file=./genesis`,
+	Run:     runGenesisInspect,
+}
+
+func init() {
+	RootCmd.AddCommand(genesisCmd)
+	genesisCmd.AddCommand(genesisGenerateCmd)
+	genesisCmd.AddCommand(genesisValidateCmd)
+	genesisCmd.AddCommand(genesisInspectCmd)
+
+	genesisGenerateCmd.Flags().StringVar(&genesisSpecFlag, "spec", "", "the JSON spec of the genesis snapshot")
+	genesisGenerateCmd.Flags().StringVar(&genesisOutputFlag, "output", "genesis", "the genesis snapshot file to write")
+
+	genesisValidateCmd.Flags().StringVar(&genesisFileFlag, "file", "genesis", "the genesis snapshot file")
+	genesisValidateCmd.Flags().StringVar(&genesisSpecFlag, "spec", "", "the JSON spec the snapshot is expected to be generated from")
+	genesisValidateCmd.Flags().StringVar(&genesisHashFlag, "hash", "", "the expected genesis block hash")
+
+	genesisInspectCmd.Flags().StringVar(&genesisFileFlag, "file", "genesis", "the genesis snapshot file")
+}
+
+func runGenesisGenerate(cmd *cobra.Command, args []string) {
+	if genesisSpecFlag == "" {
+		log.Fatalf("The genesis spec is required")
+	}
+	spec, err := snapshot.LoadGenesisSpec(genesisSpecFlag)
+	if err != nil {
+		log.Fatalf("Invalid genesis spec %v: %v", genesisSpecFlag, err)
+	}
+	if _, err := os.Stat(genesisOutputFlag); !os.IsNotExist(err) {
+		log.Fatalf("%v already exists", genesisOutputFlag)
+	}
+
+	sv, metadata, err := spec.Generate()
+	if err != nil {
+		log.Fatalf("Failed to generate the genesis snapshot: %v", err)
+	}
+	if err := snapshot.WriteGenesisSnapshot(sv, metadata, genesisOutputFlag); err != nil {
+		log.Fatalf("Failed to write the genesis snapshot: %v", err)
+	}
+
+	fmt.Printf("Genesis snapshot written to %v\n", genesisOutputFlag)
+	fmt.Printf("Chain ID: %v, genesis block hash: %v\n", spec.ChainID, metadata.TailTrio.Second.Header.Hash().Hex())
+	fmt.Println("The nodes of the network are configured with the hash as genesis.hash in config.yaml.")
+}
+
+func runGenesisValidate(cmd *cobra.Command, args []string) {
+	summary, err := snapshot.ReadGenesisSnapshot(genesisFileFlag)
+	if err != nil {
+		log.Fatalf("Invalid genesis snapshot %v: %v", genesisFileFlag, err)
+	}
+
+	if genesisHashFlag != "" && summary.BlockHash != common.HexToHash(genesisHashFlag) {
+		log.Fatalf("Genesis block hash mismatch, expected: %v, actual: %v", genesisHashFlag, summary.BlockHash.Hex())
+	}
+	if genesisSpecFlag != "" {
+		spec, err := snapshot.LoadGenesisSpec(genesisSpecFlag)
+		if err != nil {
+			log.Fatalf("Invalid genesis spec %v: %v", genesisSpecFlag, err)
+		}
+		_, metadata, err := spec.Generate()
+		if err != nil {
+			log.Fatalf("Failed to generate the genesis snapshot of the spec: %v", err)
+		}
+		if expected := metadata.TailTrio.Second.Header.Hash(); summary.BlockHash != expected {
+			log.Fatalf("The snapshot is not generated from the spec, genesis block hash expected: %v, actual: %v",
+				expected.Hex(), summary.BlockHash.Hex())
+		}
+	}
+
+	fmt.Printf("Genesis snapshot %v is valid, chain ID: %v, genesis block hash: %v\n",
+		genesisFileFlag, summary.ChainID, summary.BlockHash.Hex())
+}
+
+func runGenesisInspect(cmd *cobra.Command, args []string) {
+	summary, err := snapshot.ReadGenesisSnapshot(genesisFileFlag)
+	if err != nil {
+		log.Fatalf("Invalid genesis snapshot %v: %v", genesisFileFlag, err)
+	}
+
+	fmt.Printf("Chain ID:    %v\n", summary.ChainID)
+	fmt.Printf("Block hash:  %v\n", summary.BlockHash.Hex())
+	fmt.Printf("State hash:  %v\n", summary.StateHash.Hex())
+	fmt.Printf("Timestamp:   %v (%v)\n", summary.Timestamp, time.Unix(summary.Timestamp, 0).UTC().Format(time.RFC3339))
+	fmt.Printf("Total Theta: %v\n", formatWei(summary.TotalTheta))
+	fmt.Printf("Total TFuel: %v\n", formatWei(summary.TotalTFuel))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "\nValidators (%v):\n", len(summary.Validators))
+	fmt.Fprintln(w, "  ADDRESS\tSTAKE (THETA)")
+	for _, validator := range summary.Validators {
+		fmt.Fprintf(w, "  %v\t%v\n", validator.Address.Hex(), formatWei(validator.Stake))
+	}
+	fmt.Fprintf(w, "\nAccounts (%v):\n", len(summary.Accounts))
+	fmt.Fprintln(w, "  ADDRESS\tTHETA\tTFUEL")
+	for _, account := range summary.Accounts {
+		fmt.Fprintf(w, "  %v\t%v\t%v\n", account.Address.Hex(), formatWei(account.ThetaWei), formatWei(account.TFuelWei))
+	}
+	if len(summary.Upgrades) > 0 {
+		fmt.Fprintf(w, "\nUpgrades (%v):\n", len(summary.Upgrades))
+		fmt.Fprintln(w, "  NAME\tHEIGHT")
+		for _, upgrade := range summary.Upgrades {
+			fmt.Fprintf(w, "  %v\t%v\n", upgrade.Name, upgrade.Height)
+		}
+	}
+	w.Flush()
+}
+
+// formatWei formats an amount in Wei as a decimal number of tokens.
+func formatWei(wei *big.Int) string {
+	amount := new(big.Rat).SetFrac(wei, big.NewInt(1e18)).FloatString(18)
+	return strings.TrimSuffix(strings.TrimRight(amount, "0"), ".")
+}
@@ -1,24 +1,68 @@
 package cmd
 
 import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
 	"os"
 	"path"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/snapshot"
+	"github.com/thetatoken/theta/wallet/softwallet/hd"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+)
+
+var (
+	initMnemonicFlag  bool
+	initGenKeyFlag    bool
+	initValidatorFlag bool
+	initGenesisFlag   string
+	initTestnetFlag   int
+	initChainIDFlag   string
+)
+
+// The ports of the first node of a local testnet, the other nodes use the following ports
+const (
+	testnetP2PBasePort = 12000
+	testnetRPCBasePort = 16888
 )
 
 // initCmd represents the init command
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize Theta node configuration.",
-	Long:  ``,
-	Run:   runInit,
+	Long: `Initialize Theta node configuration. With --mnemonic, the node identity key is derived from
+a seed phrase, the master secret of the node, from which the validator signing keys are derived
+as well along independent derivation paths.
+
+With --testnet N, the config folder is populated with the complete configuration of a local
+network of N validator nodes under node1 ... nodeN, sharing a newly generated genesis snapshot.
+Each node is started with "theta start --config=<config>/nodeX".`,
+	Example: `theta init --config=./node --gen_key --genesis=./genesis
+theta init --config=./localnet --testnet=4 --password=qwertyuiop`,
+	Run: runInit,
 }
 
 func init() {
 	RootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().BoolVar(&initMnemonicFlag, "mnemonic", false, "derive the node identity key from a seed phrase")
+	initCmd.Flags().BoolVar(&initGenKeyFlag, "gen_key", false, "generate the node key instead of on the first start")
+	initCmd.Flags().BoolVar(&initValidatorFlag, "validator", false, "also generate a validator signing key")
+	initCmd.Flags().StringVar(&initGenesisFlag, "genesis", "", "the genesis snapshot of a private network to place in the config folder")
+	initCmd.Flags().IntVar(&initTestnetFlag, "testnet", 0, "generate the configuration of a local network with the given number of nodes")
+	initCmd.Flags().StringVar(&initChainIDFlag, "chain_id", "privatenet", "the chain ID of the local network generated with --testnet")
 }
 
 func runInit(cmd *cobra.Command, args []string) {
@@ -30,7 +74,248 @@ func runInit(cmd *cobra.Command, args []string) {
 		log.WithFields(log.Fields{"err": err, "path": cfgPath}).Fatal("Failed to create config folder")
 	}
 
-	if err := common.WriteInitialConfig(path.Join(cfgPath, "config.yaml")); err != nil {
+	if initTestnetFlag > 0 {
+		if err := initTestnet(initTestnetFlag); err != nil {
+			log.WithFields(log.Fields{"err": err}).Fatal("Failed to generate the testnet configuration")
+		}
+		return
+	}
+
+	config := common.InitialConfig
+	if initGenesisFlag != "" {
+		genesisHash, err := placeGenesis(initGenesisFlag, path.Join(cfgPath, "snapshot"))
+		if err != nil {
+			log.WithFields(log.Fields{"err": err, "genesis": initGenesisFlag}).Fatal("Failed to place the genesis snapshot")
+		}
+		config += fmt.Sprintf("genesis:\n  hash: \"%v\"\n", genesisHash.Hex())
+	}
+	if err := common.WriteFileAtomic(path.Join(cfgPath, "config.yaml"), []byte(config), 0600); err != nil {
 		log.WithFields(log.Fields{"err": err, "path": cfgPath}).Fatal("Failed to write config")
 	}
+
+	if initMnemonicFlag {
+		if err := initKeyFromMnemonic(); err != nil {
+			log.WithFields(log.Fields{"err": err}).Fatal("Failed to derive the node key")
+		}
+	} else if initGenKeyFlag || initValidatorFlag {
+		if err := initRandomKeys(); err != nil {
+			log.WithFields(log.Fields{"err": err}).Fatal("Failed to generate the node key")
+		}
+	}
+}
+
+// initKeyFromMnemonic derives the node identity key from the seed phrase and stores it in the
+// key store of the node
+func initKeyFromMnemonic() error {
+	mnemonic, err := utils.GetPassword("Please enter the seed phrase of the node: ")
+	if err != nil {
+		return fmt.Errorf("Failed to get seed phrase: %v", err)
+	}
+	seed, err := hd.NewSeed(mnemonic, "")
+	if err != nil {
+		return err
+	}
+	nodeKey, err := hd.DeriveNodeIdentityKey(seed)
+	if err != nil {
+		return err
+	}
+	validatorKey, err := hd.DeriveValidatorKey(seed, 0)
+	if err != nil {
+		return err
+	}
+
+	password, err := getNewPassword()
+	if err != nil {
+		return err
+	}
+	if err := storeKey(nodeKeyPath(), nodeKey, password); err != nil {
+		return err
+	}
+	if initValidatorFlag {
+		if err := storeKey(validatorKeyPath(), validatorKey, password); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Node identity key: %v, derivation path: %v\n", nodeKey.PublicKey().Address().Hex(),
+		wtypes.NodeIdentityDerivationPath)
+	fmt.Printf("Validator key:     %v, derivation path: %v\n", validatorKey.PublicKey().Address().Hex(),
+		wtypes.ValidatorDerivationPath(0))
+	fmt.Println("")
+	fmt.Println("The validator keys can be recovered from the same seed phrase with \"thetacli key recover --path\",")
+	fmt.Println("incrementing the last component of the derivation path for each key rotation.")
+	return nil
+}
+
+// initRandomKeys generates the node key, and the validator signing key if requested.
+func initRandomKeys() error {
+	password, err := getNewPassword()
+	if err != nil {
+		return err
+	}
+
+	nodeKey, _, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return err
+	}
+	if err := storeKey(nodeKeyPath(), nodeKey, password); err != nil {
+		return err
+	}
+	fmt.Printf("Node key:      %v\n", nodeKey.PublicKey().Address().Hex())
+
+	if initValidatorFlag {
+		validatorKey, _, err := crypto.GenerateKeyPair()
+		if err != nil {
+			return err
+		}
+		if err := storeKey(validatorKeyPath(), validatorKey, password); err != nil {
+			return err
+		}
+		fmt.Printf("Validator key: %v\n", validatorKey.PublicKey().Address().Hex())
+	}
+	return nil
+}
+
+// initTestnet generates the keys, the config files and the genesis snapshot of a local network
+// where all the nodes are validators and connect to each other.
+func initTestnet(numNodes int) error {
+	password, err := getNewPassword()
+	if err != nil {
+		return err
+	}
+
+	// The validators deposit their own stakes
+	stakeAmount := new(big.Int).Mul(big.NewInt(5), core.MinValidatorStakeDeposit)
+	thetaBalance := new(big.Int).Mul(big.NewInt(2), stakeAmount)
+	balances := make(map[common.Address]types.Coins)
+	stakes := []snapshot.GenesisStakeDeposit{}
+	keys := []*crypto.PrivateKey{}
+	for i := 0; i < numNodes; i++ {
+		key, _, err := crypto.GenerateKeyPair()
+		if err != nil {
+			return err
+		}
+		address := key.PublicKey().Address()
+		balances[address] = types.Coins{
+			ThetaWei: thetaBalance,
+			TFuelWei: new(big.Int).Mul(big.NewInt(5), thetaBalance),
+		}
+		stakes = append(stakes, snapshot.GenesisStakeDeposit{Source: address, Holder: address, Amount: stakeAmount})
+		keys = append(keys, key)
+	}
+
+	sv, metadata, err := snapshot.GenerateGenesisSnapshot(initChainIDFlag, nil, balances, stakes, nil, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	genesisPath := path.Join(cfgPath, "genesis")
+	if err := snapshot.WriteGenesisSnapshot(sv, metadata, genesisPath); err != nil {
+		return err
+	}
+	genesisHash := metadata.TailTrio.Second.Header.Hash()
+
+	for i, key := range keys {
+		nodePath := path.Join(cfgPath, fmt.Sprintf("node%v", i+1))
+		if err := os.Mkdir(nodePath, 0700); err != nil {
+			return err
+		}
+		if err := storeKey(path.Join(nodePath, "key"), key, password); err != nil {
+			return err
+		}
+		if _, err := placeGenesis(genesisPath, path.Join(nodePath, "snapshot")); err != nil {
+			return err
+		}
+		config := testnetNodeConfig(i, numNodes, genesisHash)
+		if err := common.WriteFileAtomic(path.Join(nodePath, "config.yaml"), []byte(config), 0600); err != nil {
+			return err
+		}
+		fmt.Printf("node%v: validator %v, P2P port %v, RPC port %v\n", i+1, key.PublicKey().Address().Hex(),
+			testnetP2PBasePort+i, testnetRPCBasePort+i)
+	}
+
+	fmt.Println("")
+	fmt.Printf("Chain ID: %v, genesis block hash: %v\n", initChainIDFlag, genesisHash.Hex())
+	return nil
+}
+
+// testnetNodeConfig returns the config file of the i-th node of a local testnet, which uses the
+// other nodes as its seeds.
+func testnetNodeConfig(i int, numNodes int, genesisHash common.Hash) string {
+	seeds := []string{}
+	for j := 0; j < numNodes; j++ {
+		if j != i {
+			seeds = append(seeds, fmt.Sprintf("127.0.0.1:%v", testnetP2PBasePort+j))
+		}
+	}
+	return fmt.Sprintf(`# Theta configuration
+genesis:
+  hash: "%v"
+p2p:
+  port: %v
+  seeds: %v
+rpc:
+  enabled: true
+  port: %v
+`, genesisHash.Hex(), testnetP2PBasePort+i, strings.Join(seeds, ","), testnetRPCBasePort+i)
+}
+
+// placeGenesis copies the genesis snapshot to the snapshot path of a node, and returns the hash of
+// the genesis block the node needs to be configured with.
+func placeGenesis(genesisPath string, snapshotFilePath string) (common.Hash, error) {
+	header, err := snapshot.ReadGenesisBlockHeader(genesisPath)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	content, err := ioutil.ReadFile(genesisPath)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := ioutil.WriteFile(snapshotFilePath, content, 0600); err != nil {
+		return common.Hash{}, err
+	}
+	return header.Hash(), nil
+}
+
+func storeKey(keysDir string, privKey *crypto.PrivateKey, password string) error {
+	keystore, err := ks.NewKeystoreEncrypted(keysDir, ks.StandardScryptN, ks.StandardScryptP)
+	if err != nil {
+		return err
+	}
+	return keystore.StoreKey(ks.NewKey(privKey), password)
+}
+
+func nodeKeyPath() string {
+	keyPath := viper.GetString(common.CfgKeyPath)
+	if keyPath == "" {
+		keyPath = cfgPath
+	}
+	return path.Join(keyPath, "key")
+}
+
+// validatorKeyPath returns the key store of the validator signing keys, kept apart from the node key.
+func validatorKeyPath() string {
+	keyPath := viper.GetString(common.CfgKeyPath)
+	if keyPath == "" {
+		keyPath = cfgPath
+	}
+	return path.Join(keyPath, "validator_key")
+}
+
+// getNewPassword returns the password set with --password, or asks the user to choose one.
+func getNewPassword() (string, error) {
+	if len(nodePassword) != 0 {
+		return nodePassword, nil
+	}
+	password, err := utils.GetPassword("Please choose your password for the Theta Node: ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to get password: %v", err)
+	}
+	confirmation, err := utils.GetPassword("Please enter your password again: ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to get password: %v", err)
+	}
+	if password != confirmation {
+		return "", fmt.Errorf("Passwords do not match")
+	}
+	return password, nil
 }
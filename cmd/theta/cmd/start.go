@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"path"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -17,14 +19,20 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/diagnostic"
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/common/tracing"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/hsm"
+	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/node"
 	msg "github.com/thetatoken/theta/p2p/messenger"
 	msgl "github.com/thetatoken/theta/p2pl/messenger"
 	"github.com/thetatoken/theta/rlp"
 	"github.com/thetatoken/theta/snapshot"
+	"github.com/thetatoken/theta/store/database"
 	"github.com/thetatoken/theta/store/database/backend"
 	"github.com/thetatoken/theta/store/rollingdb"
 	"github.com/thetatoken/theta/version"
@@ -40,6 +48,11 @@ var startCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(startCmd)
+
+	startCmd.Flags().Bool("read_only", false, "only serve RPC queries from checkpoints of the data directory of another node")
+	viper.BindPFlag(common.CfgStorageReadOnly, startCmd.Flags().Lookup("read_only"))
+	startCmd.Flags().Bool("archive", false, "retain the full history of the chain, requires a data directory holding the history since the genesis")
+	viper.BindPFlag(common.CfgStorageArchive, startCmd.Flags().Lookup("archive"))
 }
 
 func runStart(cmd *cobra.Command, args []string) {
@@ -47,11 +60,52 @@ func runStart(cmd *cobra.Command, args []string) {
 	var network *msgl.Messenger
 	var err error
 
-	privKey, err := loadOrCreateKey()
+	// A panic of the main goroutine or of the main loops leaves a diagnostic bundle in the config folder
+	diagnostic.SetCrashDir(path.Join(cfgPath, "crash"))
+	defer diagnostic.Recover()
+
+	readOnly := viper.GetBool(common.CfgStorageReadOnly)
+
+	if viper.GetBool(common.CfgStorageArchive) {
+		// Neither prune the state, nor cut the rolling layers which are compacted by the pruning
+		viper.Set(common.CfgStorageStatePruningEnabled, false)
+		viper.Set(common.CfgStorageRollingEnabled, false)
+		viper.Set(common.CfgStorageSearchIndexEnabled, true)
+		log.Infof("Running in archive mode, state pruning is disabled")
+	}
+
+	// The timers and histograms only collect data if metrics are enabled
+	if viper.GetBool(common.CfgRPCPrometheusEnabled) || viper.GetString(common.CfgMetricsPrometheusAddress) != "" {
+		metrics.Enabled = true
+	}
+
+	var privKey *crypto.PrivateKey
+	if readOnly {
+		// A read-only node never signs anything, an ephemeral key is sufficient
+		privKey, _, err = crypto.GenerateKeyPair()
+	} else {
+		privKey, err = loadOrCreateKey()
+	}
 	if err != nil {
 		log.Fatalf("Failed to load or create key: %v", err)
 	}
 
+	if viper.GetBool(common.CfgTracingEnabled) {
+		exporter := tracing.NewExporter(viper.GetString(common.CfgTracingOTLPEndpoint), "theta", privKey.PublicKey().Address().Hex())
+		tracing.Init(exporter, viper.GetFloat64(common.CfgTracingSampleRatio))
+		defer tracing.Shutdown()
+	}
+
+	var hsmSigner *hsm.Signer
+	if !readOnly && viper.GetBool(common.CfgHSMEnabled) {
+		hsmSigner, err = newHSMSigner()
+		if err != nil {
+			log.Fatalf("Failed to initialize the HSM signer: %v", err)
+		}
+		defer hsmSigner.Close()
+		log.Infof("Using HSM key %v, address: %v", viper.GetString(common.CfgHSMKeyLabel), hsmSigner.PublicKey().Address())
+	}
+
 	// Open database
 	dbPath := viper.GetString(common.CfgDataPath)
 	if dbPath == "" {
@@ -60,17 +114,34 @@ func runStart(cmd *cobra.Command, args []string) {
 
 	mainDBPath := path.Join(dbPath, "db", "main")
 	refDBPath := path.Join(dbPath, "db", "ref")
-	db, err := backend.NewLDBDatabase(mainDBPath, refDBPath,
-		viper.GetInt(common.CfgStorageLevelDBCacheSize),
-		viper.GetInt(common.CfgStorageLevelDBHandles))
-
-	rdb := rollingdb.NewRollingDB(dbPath, db)
+	var db database.Database
+	rollingPath := dbPath
+	if readOnly {
+		// The node writing to the data directory holds the file locks of the databases, serve
+		// from checkpoints of them instead
+		var cdb *backend.CheckpointDatabase
+		cdb, err = backend.NewCheckpointDatabase(path.Join(dbPath, "db"), path.Join(cfgPath, "checkpoint"),
+			viper.GetInt(common.CfgStorageLevelDBCacheSize),
+			viper.GetInt(common.CfgStorageLevelDBHandles))
+		if err == nil {
+			db = cdb
+			rollingPath = cdb.Path()
+		}
+	} else {
+		db, err = backend.NewLDBDatabase(mainDBPath, refDBPath,
+			viper.GetInt(common.CfgStorageLevelDBCacheSize),
+			viper.GetInt(common.CfgStorageLevelDBHandles))
+	}
 
 	if err != nil {
 		log.Fatalf("Failed to connect to the db. main: %v, ref: %v, err: %v",
 			mainDBPath, refDBPath, err)
 	}
 
+	state.SetCacheSize(viper.GetInt(common.CfgStorageStateCacheSize) * 1024 * 1024)
+
+	rdb := rollingdb.NewRollingDB(rollingPath, db)
+
 	// load snapshot
 	if len(snapshotPath) == 0 {
 		snapshotPath = path.Join(cfgPath, "snapshot")
@@ -93,7 +164,13 @@ func runStart(cmd *cobra.Command, args []string) {
 			}
 		}
 	}
-	if skipLoadSnapshot && !viper.GetBool(common.CfgForceValidateSnapshot) {
+	if readOnly {
+		// The snapshot must have been validated by the node that owns the data directory
+		if !skipLoadSnapshot {
+			log.Fatalf("No validated snapshot found in %v, read-only mode requires an initialized data directory", dbPath)
+		}
+		snapshotBlockHeader = dbSnapshotHeader
+	} else if skipLoadSnapshot && !viper.GetBool(common.CfgForceValidateSnapshot) {
 		log.Println("Skip validating snapshot")
 	} else {
 		snapshotBlockHeader, err = snapshot.ValidateSnapshot(snapshotPath, chainImportDirPath, chainCorrectionPath)
@@ -123,13 +200,15 @@ func runStart(cmd *cobra.Command, args []string) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	p2pOpt := common.P2POptEnum(viper.GetInt(common.CfgP2POpt))
-	if p2pOpt != common.P2POptOld {
+	if readOnly {
+		log.Println("Running in read-only mode, P2P networking is disabled")
+	} else if p2pOpt != common.P2POptOld {
 		port := viper.GetInt(common.CfgP2PLPort)
 		peerSeeds := strings.FieldsFunc(viper.GetString(common.CfgLibP2PSeeds), f)
 		seedPeerOnly := viper.GetBool(common.CfgP2PSeedPeerOnly)
 		network = newMessenger(privKey, peerSeeds, port, seedPeerOnly, ctx)
 	}
-	if p2pOpt != common.P2POptLibp2p {
+	if !readOnly && p2pOpt != common.P2POptLibp2p {
 		portOld := viper.GetInt(common.CfgP2PPort)
 		peerSeedsOld := strings.FieldsFunc(viper.GetString(common.CfgP2PSeeds), f)
 		networkOld = newMessengerOld(privKey, peerSeedsOld, portOld, ctx)
@@ -137,6 +216,7 @@ func runStart(cmd *cobra.Command, args []string) {
 
 	params := &node.Params{
 		ChainID:             root.ChainID,
+		DataPath:            dbPath,
 		PrivateKey:          privKey,
 		Root:                root,
 		NetworkOld:          networkOld,
@@ -146,23 +226,69 @@ func runStart(cmd *cobra.Command, args []string) {
 		SnapshotPath:        snapshotPath,
 		ChainImportDirPath:  chainImportDirPath,
 		ChainCorrectionPath: chainCorrectionPath,
+		ReadOnly:            readOnly,
+	}
+	if viper.GetBool(common.CfgMempoolJournalEnabled) {
+		params.MempoolJournalPath = path.Join(dbPath, "mempool", "journal")
+	}
+	if hsmSigner != nil {
+		params.Signer = hsmSigner
+		interval := time.Duration(viper.GetInt(common.CfgHSMHealthCheckInterval)) * time.Second
+		if interval > 0 {
+			go hsmSigner.MonitorHealth(ctx, interval)
+		}
 	}
 
 	n := node.NewNode(params)
+	diagnostic.SetStatusProvider(n.DiagnosticStatus)
 
-	c := make(chan os.Signal)
-	signal.Notify(c, os.Interrupt)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	done := make(chan struct{})
 	go func() {
 		<-c
 		signal.Stop(c)
+
+		timeout := time.Duration(viper.GetInt(common.CfgNodeShutdownTimeoutSecs)) * time.Second
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), timeout)
+		defer shutdownCancel()
+		if err := n.Shutdown(shutdownCtx); err != nil {
+			// A component may still be writing, closing the databases could corrupt them
+			log.Errorf("Failed to shut down gracefully: %v", err)
+			os.Exit(1)
+		}
 		cancel()
-		network.Stop()
-		// Wait at most 5 seconds before forcefully shutting down.
-		<-time.After(time.Duration(5) * time.Second)
+		rdb.Close()
+		db.Close()
 		close(done)
 	}()
 
+	if n.RPC != nil {
+		// The admin.Shutdown RPC method takes the same path as the interrupt signal
+		n.RPC.SetShutdownHandler(func() {
+			select {
+			case c <- os.Interrupt:
+			default:
+			}
+		})
+		n.RPC.SetReloadHandler(n.ReloadConfig)
+		n.RPC.SetProfileDir(path.Join(cfgPath, "profiles"))
+	}
+
+	// Reload the config file on SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			applied, restartRequired, err := n.ReloadConfig()
+			if err != nil {
+				log.Errorf("Failed to reload the config: %v", err)
+				continue
+			}
+			log.Infof("Config reloaded, applied: %v, restart required: %v", applied, restartRequired)
+		}
+	}()
+
 	n.Start(ctx)
 
 	if viper.GetBool(common.CfgProfEnabled) {
@@ -175,9 +301,13 @@ func runStart(cmd *cobra.Command, args []string) {
 		go memoryCleanupRoutine()
 	}
 
+	// The node may also stop on its own, in which case it is shut down the same way
 	go func() {
 		n.Wait()
-		close(done)
+		select {
+		case c <- os.Interrupt:
+		default:
+		}
 	}()
 
 	<-done
@@ -281,6 +411,23 @@ func loadOrCreateKey() (*crypto.PrivateKey, error) {
 	return nodePrivKey, nil
 }
 
+func newHSMSigner() (*hsm.Signer, error) {
+	pin := os.Getenv("THETA_HSM_PIN")
+	if pinFile := viper.GetString(common.CfgHSMPinFile); pinFile != "" {
+		content, err := ioutil.ReadFile(pinFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read the HSM PIN file: %v", err)
+		}
+		pin = strings.TrimSpace(string(content))
+	}
+	return hsm.NewSigner(hsm.Config{
+		Library:    viper.GetString(common.CfgHSMLibrary),
+		TokenLabel: viper.GetString(common.CfgHSMTokenLabel),
+		KeyLabel:   viper.GetString(common.CfgHSMKeyLabel),
+		PIN:        pin,
+	})
+}
+
 func newMessenger(privKey *crypto.PrivateKey, seedPeerNetAddresses []string, port int, seedPeerOnly bool, ctx context.Context) *msgl.Messenger {
 	log.WithFields(log.Fields{
 		"pubKey":  fmt.Sprintf("%v", privKey.PublicKey().ToBytes()),
@@ -0,0 +1,44 @@
+package addressbook
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+)
+
+// addCmd adds a name to the address book
+var addCmd = &cobra.Command{
+	Use:     "add <name> <address>",
+	Short:   "Add a name to the address book",
+	Long:    `Add a name to the address book. An existing name is only replaced with --force.`,
+	Example: "thetacli addressbook add exchange-hot 26d813157F7503a9057FB2DB6Eb2f83a35c4FdD7",
+	Args:    cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.ToLower(args[0])
+		if !utils.IsAddressBookName(name) {
+			utils.Error("Invalid name %v, expected a letter followed by at most 31 letters, digits, '_', '.' or '-'\n", args[0])
+		}
+		if !common.IsHexAddress(args[1]) {
+			utils.Error("Invalid address %v\n", args[1])
+		}
+		address := common.HexToAddress(args[1])
+
+		book := loadAddressBook(cmd)
+		if existing, ok := book.Entries[name]; ok && existing != address && !forceFlag {
+			utils.Error("Name %v is already used for %v, replace it with --force\n", name, existing.Hex())
+		}
+		if others := book.NamesOf(address); len(others) > 0 {
+			utils.Info("Address %v is also named %v\n", address.Hex(), strings.Join(others, ", "))
+		}
+		book.Entries[name] = address
+		saveAddressBook(book)
+
+		utils.PrintResult(utils.AddressBookEntry{Name: name, Address: address}, "Added %v: %v\n", name, address.Hex())
+	},
+}
+
+func init() {
+	addCmd.Flags().BoolVar(&forceFlag, "force", false, "Replace the address of an existing name")
+}
@@ -0,0 +1,38 @@
+package addressbook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+)
+
+// exportCmd exports the address book unencrypted, to be imported by the import command
+var exportCmd = &cobra.Command{
+	Use:     "export [file]",
+	Short:   "Export the address book",
+	Long:    `Export the address book as an unencrypted JSON file, or to stdout if no file is given.`,
+	Example: "thetacli addressbook export names.json",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entries := loadAddressBook(cmd).List()
+		content, err := json.MarshalIndent(entries, "", "    ")
+		if err != nil {
+			utils.Error("Failed to encode the address book: %v\n", err)
+		}
+		if len(args) == 0 {
+			fmt.Println(string(content))
+			return
+		}
+		if err := ioutil.WriteFile(args[0], content, 0600); err != nil {
+			utils.Error("Failed to write %v: %v\n", args[0], err)
+		}
+		result := struct {
+			Exported int    `json:"exported"`
+			File     string `json:"file"`
+		}{len(entries), args[0]}
+		utils.PrintResult(result, "Exported %v names to %v\n", len(entries), args[0])
+	},
+}
@@ -0,0 +1,66 @@
+package addressbook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+)
+
+// importCmd imports the names exported by the export command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import names into the address book",
+	Long: `Import names from a JSON file in the format of the export command. Nothing is imported if any name
+is already used for a different address, unless --overwrite is set.`,
+	Example: "thetacli addressbook import names.json",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			utils.Error("Failed to read %v: %v\n", args[0], err)
+		}
+		entries := []utils.AddressBookEntry{}
+		if err := json.Unmarshal(content, &entries); err != nil {
+			utils.Error("Failed to parse %v: %v\n", args[0], err)
+		}
+
+		book := loadAddressBook(cmd)
+		imported := make(map[string]utils.AddressBookEntry)
+		conflicts := []string{}
+		for _, entry := range entries {
+			name := strings.ToLower(entry.Name)
+			if !utils.IsAddressBookName(name) {
+				utils.Error("Invalid name %v in %v\n", entry.Name, args[0])
+			}
+			// The file itself may also use a name for different addresses
+			if other, ok := imported[name]; ok && other.Address != entry.Address {
+				utils.Error("Name %v is used for both %v and %v in %v\n", name, other.Address.Hex(), entry.Address.Hex(), args[0])
+			}
+			if existing, ok := book.Entries[name]; ok && existing != entry.Address {
+				conflicts = append(conflicts, name)
+			}
+			imported[name] = utils.AddressBookEntry{Name: name, Address: entry.Address}
+		}
+		if len(conflicts) > 0 && !overwriteFlag {
+			utils.Error("Names already used for different addresses: %v, replace them with --overwrite\n", strings.Join(conflicts, ", "))
+		}
+
+		for name, entry := range imported {
+			book.Entries[name] = entry.Address
+		}
+		saveAddressBook(book)
+
+		result := struct {
+			Imported    int      `json:"imported"`
+			Overwritten []string `json:"overwritten"`
+		}{len(imported), conflicts}
+		utils.PrintResult(result, "Imported %v names, overwritten: %v\n", len(imported), conflicts)
+	},
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&overwriteFlag, "overwrite", false, "Replace the addresses of the existing names")
+}
@@ -0,0 +1,28 @@
+package addressbook
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+)
+
+// listCmd lists the names in the address book
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the names in the address book",
+	Example: "thetacli addressbook list",
+	Run: func(cmd *cobra.Command, args []string) {
+		entries := loadAddressBook(cmd).List()
+
+		if utils.IsJSONOutput() {
+			utils.PrintJSON(struct {
+				Entries []utils.AddressBookEntry `json:"entries"`
+			}{entries})
+			return
+		}
+		for _, entry := range entries {
+			fmt.Printf("%-32v %v\n", entry.Name, entry.Address.Hex())
+		}
+	},
+}
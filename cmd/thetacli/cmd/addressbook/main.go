@@ -0,0 +1,44 @@
+package addressbook
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+)
+
+// Flags for the address book commands
+var (
+	forceFlag     bool
+	overwriteFlag bool
+)
+
+// AddressBookCmd represents the address book command
+var AddressBookCmd = &cobra.Command{
+	Use:   "addressbook",
+	Short: "Manage the address book",
+	Long: `Manage the encrypted address book, which maps names to addresses so that accounts can be referred
+to by name in the address flags of the other commands, e.g. --from=alice --to=exchange-hot. The address book
+password is read from the THETACLI_ADDRESS_BOOK_PASSWORD environment variable if set.`,
+}
+
+func init() {
+	AddressBookCmd.AddCommand(addCmd)
+	AddressBookCmd.AddCommand(listCmd)
+	AddressBookCmd.AddCommand(removeCmd)
+	AddressBookCmd.AddCommand(importCmd)
+	AddressBookCmd.AddCommand(exportCmd)
+}
+
+func loadAddressBook(cmd *cobra.Command) *utils.AddressBook {
+	cfgPath := cmd.Flag("config").Value.String()
+	book, err := utils.LoadAddressBook(cfgPath)
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeWalletError, "%v\n", err)
+	}
+	return book
+}
+
+func saveAddressBook(book *utils.AddressBook) {
+	if err := book.Save(); err != nil {
+		utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to save the address book: %v\n", err)
+	}
+}
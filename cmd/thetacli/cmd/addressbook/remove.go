@@ -0,0 +1,28 @@
+package addressbook
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+)
+
+// removeCmd removes a name from the address book
+var removeCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Short:   "Remove a name from the address book",
+	Example: "thetacli addressbook remove exchange-hot",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.ToLower(args[0])
+		book := loadAddressBook(cmd)
+		address, ok := book.Entries[name]
+		if !ok {
+			utils.Error("Name %v is not in the address book\n", args[0])
+		}
+		delete(book.Entries, name)
+		saveAddressBook(book)
+
+		utils.PrintResult(utils.AddressBookEntry{Name: name, Address: address}, "Removed %v: %v\n", name, address.Hex())
+	},
+}
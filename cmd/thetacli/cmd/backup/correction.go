@@ -9,8 +9,6 @@ import (
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 var (
@@ -29,14 +27,14 @@ var chainCorrectionCmd = &cobra.Command{
 }
 
 func doChainCorrectionCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	res, err := client.Call("theta.BackupChainCorrection", rpc.BackupChainCorrectionArgs{SnapshotHeight: heightFlag, EndBlockHash: common.HexToHash(hashFlag), Config: configFlag, ExclusionTxs: exclusionTxsFlag})
 	if err != nil {
-		utils.Error("Failed to get backup chain call details: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get backup chain call details: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to get backup chain res details: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get backup chain res details: %v\n", res.Error)
 	}
 	json, err := json.MarshalIndent(res.Result, "", "    ")
 	if err != nil {
@@ -8,8 +8,6 @@ import (
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // snapshotCmd represents the snapshot backup command.
@@ -24,14 +22,14 @@ var snapshotCmd = &cobra.Command{
 }
 
 func doSnapshotCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	res, err := client.Call("theta.BackupSnapshot", rpc.BackupSnapshotArgs{Config: configFlag, Height: heightFlag, Version: versionFlag})
 	if err != nil {
-		utils.Error("Failed to get backup snapshot call details: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get backup snapshot call details: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to get backup snapshot res details: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get backup snapshot res details: %v\n", res.Error)
 	}
 	json, err := json.MarshalIndent(res.Result, "", "    ")
 	if err != nil {
@@ -0,0 +1,101 @@
+package call
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/cobra"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// estimateGasPriceFlag defaults to empty, in which case the node uses the minimum gas price
+var estimateGasPriceFlag string
+
+// estimateGasCmd represents the estimate_gas command, which simulates the smart contract transaction against
+// the latest finalized state and returns the minimal gas limit required, along with the expected fee.
+var estimateGasCmd = &cobra.Command{
+	Use:   "estimate_gas",
+	Short: "Estimate the gas limit and fee of a smart contract transaction",
+	Example: `
+	[Estimate the gas needed to call an API of a smart contract]
+	thetacli call estimate_gas --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=0x7ad6cea2bc3162e30a3c98d84f821b3233c22647 --data=f8a8fd6d
+	`,
+	Run: doEstimateGasCmd,
+}
+
+func doEstimateGasCmd(cmd *cobra.Command, args []string) {
+	from := types.TxInput{
+		Address: common.HexToAddress(fromFlag),
+		Coins: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: new(big.Int).SetUint64(valueFlag),
+		},
+		Sequence: seqFlag,
+	}
+
+	to := types.TxOutput{
+		Address: common.HexToAddress(toFlag),
+	}
+
+	gasPrice := big.NewInt(0) // let the node fill in the minimum gas price
+	if len(estimateGasPriceFlag) != 0 {
+		var ok bool
+		gasPrice, ok = types.ParseCoinAmount(estimateGasPriceFlag)
+		if !ok {
+			utils.Error("Failed to parse gas price")
+		}
+	}
+
+	data, err := hex.DecodeString(dataFlag)
+	if err != nil {
+		utils.Error("Failed to decode data: %v, err: %v\n", dataFlag, err)
+	}
+
+	sctx := &types.SmartContractTx{
+		From:     from,
+		To:       to,
+		GasPrice: gasPrice,
+		Data:     data,
+	}
+
+	sctxBytes, err := types.TxToBytes(sctx)
+	if err != nil {
+		utils.Error("Failed to encode smart contract transaction: %v\n", sctx)
+	}
+
+	rpcCallArgs := rpc.EstimateGasArgs{
+		SctxBytes: hex.EncodeToString(sctxBytes),
+	}
+
+	client := utils.NewRPCClient()
+
+	res, err := client.Call("theta.EstimateGas", rpcCallArgs)
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to estimate gas: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to estimate gas: %v\n", res.Error)
+	}
+	json, err := json.MarshalIndent(res.Result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
+	}
+	fmt.Println(string(json))
+}
+
+func init() {
+	estimateGasCmd.Flags().StringVar(&fromFlag, "from", "", "The caller address")
+	estimateGasCmd.Flags().StringVar(&toFlag, "to", "", "The smart contract address, leave empty to deploy a contract")
+	estimateGasCmd.Flags().Uint64Var(&valueFlag, "value", 0, "Value to be transferred")
+	estimateGasCmd.Flags().StringVar(&estimateGasPriceFlag, "gas_price", "", "The gas price, default to the minimum gas price")
+	estimateGasCmd.Flags().StringVar(&dataFlag, "data", "", "The data for the smart contract")
+	estimateGasCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+
+	estimateGasCmd.MarkFlagRequired("from")
+}
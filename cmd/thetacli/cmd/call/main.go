@@ -2,6 +2,7 @@ package call
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 )
 
 // Common flags used in Call sub commands.
@@ -21,8 +22,17 @@ var (
 var CallCmd = &cobra.Command{
 	Use:   "call",
 	Short: "Call smart contract APIs",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		cfgPath := cmd.Flag("config").Value.String()
+		for _, flag := range []*string{&fromFlag, &toFlag} {
+			*flag = utils.ResolveAddress(cfgPath, *flag)
+		}
+		utils.ApplyChainID(cmd)
+	},
 }
 
 func init() {
 	CallCmd.AddCommand(smartContractCmd)
+	CallCmd.AddCommand(estimateGasCmd)
+	CallCmd.AddCommand(txCmd)
 }
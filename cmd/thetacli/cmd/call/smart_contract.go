@@ -7,8 +7,6 @@ import (
 	"math/big"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
@@ -77,21 +75,21 @@ func doSmartContractCmd(cmd *cobra.Command, args []string) {
 		utils.Error("Failed to encode smart contract transaction: %v\n", sctx)
 	}
 	if verboseFlag {
-		fmt.Printf("Encoded Tx: %x\n\n", sctxBytes)
+		utils.Info("Encoded Tx: %x\n\n", sctxBytes)
 	}
 
 	rpcCallArgs := rpc.CallSmartContractArgs{
 		SctxBytes: hex.EncodeToString(sctxBytes),
 	}
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	res, err := client.Call("theta.CallSmartContract", rpcCallArgs)
 	if err != nil {
-		utils.Error("Failed to call smart contract: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to call smart contract: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to execute smart contract: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to execute smart contract: %v\n", res.Error)
 	}
 	json, err := json.MarshalIndent(res.Result, "", "    ")
 	if err != nil {
@@ -0,0 +1,66 @@
+package call
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// Flags used in the tx command
+var (
+	txBytesFlag   string
+	heightFlag    uint64
+	stateRootFlag string
+)
+
+// txCmd represents the tx command, which executes a signed transaction of any type against the
+// ledger state without committing it, and prints the would-be result
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Dry run a transaction of any type",
+	Example: `
+	[Dry run a signed transaction against the last finalized state]
+	thetacli call tx --tx_bytes=0x02f8a4c78085e8d4a51000f86ff86d942e833968e5bb786ae419c4d13189fb081cc43babd3888ac7230489e800008901158e46f1e875100015b841
+
+	[Dry run a signed transaction against the state of the block at the given height]
+	thetacli call tx --tx_bytes=0x02f8a4c78085e8d4a51000f86ff86d942e833968e5bb786ae419c4d13189fb081cc43babd3888ac7230489e800008901158e46f1e875100015b841 --height=1024
+	`,
+	Run: doTxCmd,
+}
+
+func doTxCmd(cmd *cobra.Command, args []string) {
+	rpcCallArgs := rpc.CallTxArgs{
+		TxBytes:   strings.TrimPrefix(txBytesFlag, "0x"),
+		Height:    common.JSONUint64(heightFlag),
+		StateRoot: stateRootFlag,
+	}
+
+	client := utils.NewRPCClient()
+
+	res, err := client.Call("theta.CallTx", rpcCallArgs)
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to dry run the transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to dry run the transaction: %v\n", res.Error)
+	}
+	json, err := json.MarshalIndent(res.Result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
+	}
+	fmt.Println(string(json))
+}
+
+func init() {
+	txCmd.Flags().StringVar(&txBytesFlag, "tx_bytes", "", "The hex encoded signed transaction")
+	txCmd.Flags().Uint64Var(&heightFlag, "height", 0, "Height of the block to execute on top of, default to the last finalized block")
+	txCmd.Flags().StringVar(&stateRootFlag, "state_root", "", "State root of the block, required if there are multiple blocks at the height")
+
+	txCmd.MarkFlagRequired("tx_bytes")
+}
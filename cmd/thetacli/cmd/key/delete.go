@@ -24,41 +24,44 @@ var deleteCmd = &cobra.Command{
 		address := common.HexToAddress(args[0])
 
 		cfgPath := cmd.Flag("config").Value.String()
-		wallet, err := wallet.OpenWallet(cfgPath, wtypes.WalletTypeSoft, true)
+		wallet, err := wallet.OpenWalletWithKeysDir(utils.KeysDirPath(cfgPath), wtypes.WalletTypeSoft, true)
 		if err != nil {
-			utils.Error("Failed to open wallet: %v\n", err)
+			utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to open wallet: %v\n", err)
 		}
 
 		prompt := fmt.Sprintf("Please enter the password: ")
-		password, err := utils.GetPassword(prompt)
+		password, err := utils.GetWalletPassword(address, prompt)
 		if err != nil {
 			utils.Error("Failed to get password: %v\n", err)
 		}
 
-		fmt.Println("Are you sure to delete the key? Please enter 'no' to stop or 'yes' to proceed: ")
-		confirmation, err := utils.GetConfirmation()
-		if err != nil {
-			utils.Error("Failed to get confirmation: %v\n", err)
-		}
-		if strings.ToLower(confirmation) != "yes" {
-			return
-		}
+		// The confirmation is skipped in the JSON output mode, which is not interactive
+		if !utils.IsJSONOutput() {
+			fmt.Println("Are you sure to delete the key? Please enter 'no' to stop or 'yes' to proceed: ")
+			confirmation, err := utils.GetConfirmation()
+			if err != nil {
+				utils.Error("Failed to get confirmation: %v\n", err)
+			}
+			if strings.ToLower(confirmation) != "yes" {
+				return
+			}
 
-		prompt = fmt.Sprintf("Please enter the password again to proceed: ")
-		password2, err := utils.GetPassword(prompt)
-		if err != nil {
-			utils.Error("Failed to get password: %v\n", err)
-		}
+			prompt = fmt.Sprintf("Please enter the password again to proceed: ")
+			password2, err := utils.GetPassword(prompt)
+			if err != nil {
+				utils.Error("Failed to get password: %v\n", err)
+			}
 
-		if password != password2 {
-			utils.Error("Passwords do not match, abort\n")
+			if password != password2 {
+				utils.Error("Passwords do not match, abort\n")
+			}
 		}
 
 		err = wallet.Delete(address, password)
 		if err != nil {
-			utils.Error("Failed to delete key for address %v: %v\n", address.Hex(), err)
+			utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to delete key for address %v: %v\n", address.Hex(), err)
 		}
 
-		fmt.Printf("Key for address %v has been deleted\n", address.Hex())
+		utils.PrintResult(keyResult{Address: address.Hex()}, "Key for address %v has been deleted\n", address.Hex())
 	},
 }
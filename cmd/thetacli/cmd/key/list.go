@@ -17,9 +17,9 @@ var listCmd = &cobra.Command{
 	Example: "thetacli key list",
 	Run: func(cmd *cobra.Command, args []string) {
 		cfgPath := cmd.Flag("config").Value.String()
-		wallet, err := wallet.OpenWallet(cfgPath, wtypes.WalletTypeSoft, true)
+		wallet, err := wallet.OpenWalletWithKeysDir(utils.KeysDirPath(cfgPath), wtypes.WalletTypeSoft, true)
 		if err != nil {
-			utils.Error("Failed to open wallet: %v\n", err)
+			utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to open wallet: %v\n", err)
 		}
 
 		keyAddresses, err := wallet.List()
@@ -27,6 +27,16 @@ var listCmd = &cobra.Command{
 			utils.Error("Failed to list keys: %v\n", err)
 		}
 
+		if utils.IsJSONOutput() {
+			result := struct {
+				Addresses []string `json:"addresses"`
+			}{[]string{}}
+			for _, keyAddress := range keyAddresses {
+				result.Addresses = append(result.Addresses, keyAddress.Hex())
+			}
+			utils.PrintJSON(result)
+			return
+		}
 		for _, keyAddress := range keyAddresses {
 			fmt.Printf("%s\n", keyAddress.Hex())
 		}
@@ -2,8 +2,29 @@ package key
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/wallet"
+	"github.com/thetatoken/theta/wallet/softwallet"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
 )
 
+// Flags for the key commands
+var (
+	mnemonicFlag          bool
+	wordsFlag             int
+	pathFlag              string
+	passphraseFlag        bool
+	kdfFlag               string
+	allowWeakPasswordFlag bool
+)
+
+// keyResult is the result of the key commands in the JSON output mode
+type keyResult struct {
+	Address  string `json:"address"`
+	Path     string `json:"path,omitempty"`
+	Mnemonic string `json:"mnemonic,omitempty"`
+}
+
 // KeyCmd represents the key command
 var KeyCmd = &cobra.Command{
 	Use:   "key",
@@ -13,7 +34,48 @@ var KeyCmd = &cobra.Command{
 
 func init() {
 	KeyCmd.AddCommand(newCmd)
+	KeyCmd.AddCommand(recoverCmd)
 	KeyCmd.AddCommand(listCmd)
 	KeyCmd.AddCommand(deleteCmd)
 	KeyCmd.AddCommand(passwordCmd)
+	KeyCmd.AddCommand(migrateCmd)
+	KeyCmd.AddCommand(signMessageCmd)
+	KeyCmd.AddCommand(verifyMessageCmd)
+	KeyCmd.AddCommand(signTypedDataCmd)
+	KeyCmd.AddCommand(verifyTypedDataCmd)
+}
+
+// openWallet opens the soft wallet, which encrypts the keys with the KDF selected by --kdf
+func openWallet(cmd *cobra.Command) *softwallet.SoftWallet {
+	kdfParams, err := ks.KDFParamsByName(kdfFlag)
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+	cfgPath := cmd.Flag("config").Value.String()
+	wallet, err := wallet.OpenSoftWalletWithKDF(utils.KeysDirPath(cfgPath), kdfParams)
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to open wallet: %v\n", err)
+	}
+	return wallet
+}
+
+// getNewPassword prompts for the password of a new key, which is rejected if it's too weak
+// unless --allow_weak_password is set
+func getNewPassword(prompt string) string {
+	password, err := utils.GetPassword(prompt)
+	if err != nil {
+		utils.Error("Failed to get password: %v\n", err)
+	}
+	if !allowWeakPasswordFlag {
+		if err := utils.CheckPasswordStrength(password); err != nil {
+			utils.Error("%v, or use --allow_weak_password\n", err)
+		}
+	}
+	return password
+}
+
+// addNewPasswordFlags adds the flags of the commands encrypting a key with a new password
+func addNewPasswordFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&kdfFlag, "kdf", ks.KDFArgon2id, "Key derivation function of the key encryption (argon2id|scrypt), scrypt for compatibility with the older wallets")
+	cmd.Flags().BoolVar(&allowWeakPasswordFlag, "allow_weak_password", false, "Accept a password that fails the strength check")
 }
@@ -0,0 +1,75 @@
+package key
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
+)
+
+// migrateCmd re-encrypts the keys with the KDF selected by --kdf
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [address...]",
+	Short: "Re-encrypt keys with a stronger key derivation function",
+	Long: `Re-encrypt keys with the key derivation function selected by --kdf, all the keys by default.
+The password of each key is asked for, and stays the same. The keys already encrypted with the
+selected KDF are skipped.`,
+	Example: "thetacli key migrate 1d8E1191E0a97C1aDa4940B79188D3B1f6f5C695 --kdf=argon2id",
+	Run: func(cmd *cobra.Command, args []string) {
+		wallet := openWallet(cmd)
+		kdfParams, _ := ks.KDFParamsByName(kdfFlag)
+
+		addresses := []common.Address{}
+		for _, arg := range args {
+			addresses = append(addresses, common.HexToAddress(arg))
+		}
+		if len(addresses) == 0 {
+			var err error
+			if addresses, err = wallet.List(); err != nil {
+				utils.Error("Failed to list keys: %v\n", err)
+			}
+		}
+
+		type migration struct {
+			Address string `json:"address"`
+			From    string `json:"from"`
+			To      string `json:"to"`
+		}
+		result := struct {
+			Migrated []migration `json:"migrated"`
+			Skipped  []string    `json:"skipped"`
+		}{[]migration{}, []string{}}
+
+		for _, address := range addresses {
+			params, err := wallet.KeyKDFParams(address)
+			if err != nil {
+				utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to read key %v: %v\n", address.Hex(), err)
+			}
+			if params == kdfParams {
+				utils.Info("Key %v is already encrypted with %v\n", address.Hex(), params)
+				result.Skipped = append(result.Skipped, address.Hex())
+				continue
+			}
+
+			password, err := utils.GetWalletPassword(address, fmt.Sprintf("Please enter the password of %v: ", address.Hex()))
+			if err != nil {
+				utils.Error("Failed to get password: %v\n", err)
+			}
+			if err := wallet.MigrateKey(address, password); err != nil {
+				utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to migrate key %v: %v\n", address.Hex(), err)
+			}
+			utils.Info("Key %v migrated from %v to %v\n", address.Hex(), params, kdfParams)
+			result.Migrated = append(result.Migrated, migration{address.Hex(), params.String(), kdfParams.String()})
+		}
+
+		if utils.IsJSONOutput() {
+			utils.PrintJSON(result)
+		}
+	},
+}
+
+func init() {
+	migrateCmd.Flags().StringVar(&kdfFlag, "kdf", ks.KDFArgon2id, "Key derivation function to encrypt the keys with (argon2id|scrypt)")
+}
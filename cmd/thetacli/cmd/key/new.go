@@ -1,38 +1,72 @@
 package key
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
-	"github.com/thetatoken/theta/wallet"
+	"github.com/thetatoken/theta/wallet/softwallet/hd"
 	wtypes "github.com/thetatoken/theta/wallet/types"
 )
 
 // newCmd generates a new key
 var newCmd = &cobra.Command{
-	Use:     "new",
-	Short:   "Generates a new private key",
-	Long:    `Generates a new private key.`,
-	Example: "thetacli key new",
+	Use:   "new",
+	Short: "Generates a new private key",
+	Long: `Generates a new private key. With --mnemonic, the key is derived from a newly generated
+seed phrase, which can recover the key with "thetacli key recover".`,
+	Example: "thetacli key new --mnemonic --path \"m/44'/500'/0'/0/0\"",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfgPath := cmd.Flag("config").Value.String()
-		wallet, err := wallet.OpenWallet(cfgPath, wtypes.WalletTypeSoft, true)
-		if err != nil {
-			utils.Error("Failed to open wallet: %v\n", err)
+		wallet := openWallet(cmd)
+
+		if !mnemonicFlag {
+			password := getNewPassword("Please enter password: ")
+
+			address, err := wallet.NewKey(password)
+			if err != nil {
+				utils.Error("Failed to generate new key: %v\n", err)
+			}
+
+			utils.PrintResult(keyResult{Address: address.Hex()}, "Successfully created key: %v\n", address.Hex())
+			return
 		}
 
-		prompt := fmt.Sprintf("Please enter password: ")
-		password, err := utils.GetPassword(prompt)
+		path, err := wtypes.ParseDerivationPath(pathFlag)
 		if err != nil {
-			utils.Error("Failed to get password: %v\n", err)
+			utils.Error("%v\n", err)
 		}
+		mnemonic, err := hd.NewMnemonic(wordsFlag)
+		if err != nil {
+			utils.Error("Failed to generate mnemonic: %v\n", err)
+		}
+		passphrase := getMnemonicPassphrase()
+		password := getNewPassword("Please enter password: ")
 
-		address, err := wallet.NewKey(password)
+		address, err := wallet.NewKeyFromMnemonic(mnemonic, passphrase, path, password)
 		if err != nil {
 			utils.Error("Failed to generate new key: %v\n", err)
 		}
 
-		fmt.Printf("Successfully created key: %v\n", address.Hex())
+		result := keyResult{Address: address.Hex(), Path: path.String(), Mnemonic: mnemonic}
+		utils.PrintResult(result, "Successfully created key: %v\nDerivation path: %v\n\nSeed phrase:\n\n%v\n\n"+
+			"Write down the seed phrase and keep it safe. Anyone with the seed phrase can recover the key.\n", address.Hex(), path, mnemonic)
 	},
 }
+
+// getMnemonicPassphrase prompts for the optional BIP39 passphrase if requested by --passphrase
+func getMnemonicPassphrase() string {
+	if !passphraseFlag {
+		return ""
+	}
+	passphrase, err := utils.GetPassword("Please enter the seed phrase passphrase: ")
+	if err != nil {
+		utils.Error("Failed to get passphrase: %v\n", err)
+	}
+	return passphrase
+}
+
+func init() {
+	newCmd.Flags().BoolVar(&mnemonicFlag, "mnemonic", false, "Derive the key from a newly generated seed phrase")
+	newCmd.Flags().IntVar(&wordsFlag, "words", hd.MnemonicWords24, "Number of words of the seed phrase, 12 or 24")
+	newCmd.Flags().StringVar(&pathFlag, "path", wtypes.DefaultHDDerivationPath.String(), "Derivation path of the key")
+	newCmd.Flags().BoolVar(&passphraseFlag, "passphrase", false, "Protect the seed phrase with an additional passphrase")
+	addNewPasswordFlags(newCmd)
+}
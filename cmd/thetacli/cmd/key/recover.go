@@ -1,17 +1,49 @@
 package key
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/wallet/softwallet/hd"
+	wtypes "github.com/thetatoken/theta/wallet/types"
 )
 
 // recoverCmd recovers the key from the given seed phrase
 var recoverCmd = &cobra.Command{
 	Use:   "recover",
 	Short: "Recover a key from seed phrase",
-	Long:  `Recover a key from seed phrase.`,
+	Long: `Recover a key from seed phrase. The keys at other derivation paths can be recovered
+from the same seed phrase with --path.`,
+	Example: "thetacli key recover --path \"m/44'/500'/0'/0/1\"",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("key recover not supported yet")
+		path, err := wtypes.ParseDerivationPath(pathFlag)
+		if err != nil {
+			utils.Error("%v\n", err)
+		}
+
+		wallet := openWallet(cmd)
+
+		mnemonic, err := utils.GetPassword("Please enter the seed phrase: ")
+		if err != nil {
+			utils.Error("Failed to get seed phrase: %v\n", err)
+		}
+		if err := hd.ValidateMnemonic(mnemonic); err != nil {
+			utils.Error("%v\n", err)
+		}
+		passphrase := getMnemonicPassphrase()
+		password := getNewPassword("Please enter password: ")
+
+		address, err := wallet.NewKeyFromMnemonic(mnemonic, passphrase, path, password)
+		if err != nil {
+			utils.Error("Failed to recover key: %v\n", err)
+		}
+
+		result := keyResult{Address: address.Hex(), Path: path.String()}
+		utils.PrintResult(result, "Successfully recovered key: %v\n", address.Hex())
 	},
 }
+
+func init() {
+	recoverCmd.Flags().StringVar(&pathFlag, "path", wtypes.DefaultHDDerivationPath.String(), "Derivation path of the key")
+	recoverCmd.Flags().BoolVar(&passphraseFlag, "passphrase", false, "The seed phrase is protected by an additional passphrase")
+	addNewPasswordFlags(recoverCmd)
+}
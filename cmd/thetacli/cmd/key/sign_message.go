@@ -0,0 +1,158 @@
+package key
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/hexutil"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/eip712"
+	"github.com/thetatoken/theta/wallet"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+)
+
+// The off-chain signatures are made the way of the Ethereum wallets, so that they can be verified by
+// the same tooling and by the smart contracts with ecrecover. The signature is the 65 bytes
+// R || S || V, where V is 27 or 28.
+
+// Flags for the message signing commands
+var hexMessageFlag bool
+
+// signMessageCmd signs a message with a key, as personal_sign of the Ethereum wallets does
+var signMessageCmd = &cobra.Command{
+	Use:   "sign-message <address> <message>",
+	Short: "Sign a message with a key",
+	Long: `Sign a message with a key, prefixed with "\x19Ethereum Signed Message:\n" and the message length as
+defined by EIP-191, which makes the signature compatible with personal_sign of the Ethereum wallets. With
+--hex, the message is given as hex, e.g. a challenge of a login flow.`,
+	Example: `thetacli key sign-message 2E833968E5bB786Ae419c4d13189fB081Cc43bab "Sign in to example.com, nonce 8213"`,
+	Args:    cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		address := common.HexToAddress(utils.ResolveAddress(cmd.Flag("config").Value.String(), args[0]))
+		message := parseMessage(args[1])
+		signingBytes := eip712.PersonalMessage(message)
+		sig := signOffChain(cmd, address, signingBytes)
+
+		result := offChainSignature{
+			Address:   address.Hex(),
+			Hash:      crypto.Keccak256Hash(signingBytes).Hex(),
+			Signature: sig,
+		}
+		utils.PrintResult(result, "%v\n", sig)
+	},
+}
+
+// verifyMessageCmd verifies a signature of a message made by sign-message or personal_sign
+var verifyMessageCmd = &cobra.Command{
+	Use:     "verify-message <address> <message> <signature>",
+	Short:   "Verify a signature of a message",
+	Long:    `Verify a signature of a message made by sign-message, or by personal_sign of an Ethereum wallet.`,
+	Example: `thetacli key verify-message 2E833968E5bB786Ae419c4d13189fB081Cc43bab "Sign in to example.com, nonce 8213" 0x4355...1c`,
+	Args:    cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		address := common.HexToAddress(utils.ResolveAddress(cmd.Flag("config").Value.String(), args[0]))
+		message := parseMessage(args[1])
+		verifyOffChain(address, eip712.PersonalMessage(message), args[2])
+	},
+}
+
+// offChainSignature is the result of the signing commands in the JSON output mode
+type offChainSignature struct {
+	Address   string `json:"address"`
+	Hash      string `json:"hash"` // the hash signed, i.e. the digest recovered with ecrecover
+	Signature string `json:"signature"`
+}
+
+// offChainVerification is the result of the verification commands in the JSON output mode
+type offChainVerification struct {
+	Address   string `json:"address"`
+	Hash      string `json:"hash"`
+	Recovered string `json:"recovered,omitempty"`
+	Valid     bool   `json:"valid"`
+}
+
+// parseMessage returns the bytes of the message argument, which is hex with --hex
+func parseMessage(arg string) []byte {
+	if !hexMessageFlag {
+		return []byte(arg)
+	}
+	message, err := hexutil.Decode(arg)
+	if err != nil {
+		utils.Error("Invalid hex message: %v\n", err)
+	}
+	return message
+}
+
+// signOffChain signs the bytes with the key of the address, and returns the signature as hex with
+// the V of the Ethereum signatures
+func signOffChain(cmd *cobra.Command, address common.Address, signingBytes []byte) string {
+	cfgPath := cmd.Flag("config").Value.String()
+	wallet, err := wallet.OpenWalletWithKeysDir(utils.KeysDirPath(cfgPath), wtypes.WalletTypeSoft, true)
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to open wallet: %v\n", err)
+	}
+	prompt := fmt.Sprintf("Please enter the password of %v: ", address.Hex())
+	password, err := utils.GetWalletPassword(address, prompt)
+	if err != nil {
+		utils.Error("Failed to get password: %v\n", err)
+	}
+	if err := wallet.Unlock(address, password, nil); err != nil {
+		utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to unlock key %v: %v\n", address.Hex(), err)
+	}
+	defer wallet.Lock(address)
+
+	sig, err := wallet.Sign(address, signingBytes)
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeWalletError, "Failed to sign: %v\n", err)
+	}
+	sigBytes := append([]byte{}, sig.ToBytes()...)
+	if len(sigBytes) != crypto.SignatureLength {
+		utils.ErrorWithCode(utils.ExitCodeWalletError, "Invalid signature length %v\n", len(sigBytes))
+	}
+	sigBytes[crypto.SignatureLength-1] += 27
+	return hexutil.Encode(sigBytes)
+}
+
+// verifyOffChain recovers the signer of the bytes from the signature, whose V is either 27 or 28
+// as in the Ethereum signatures or the recovery id, and exits with an error code unless it's the
+// expected address
+func verifyOffChain(address common.Address, signingBytes []byte, signature string) {
+	sigBytes, err := hexutil.Decode(strings.TrimSpace(signature))
+	if err != nil || len(sigBytes) != crypto.SignatureLength {
+		utils.Error("Invalid signature, expected %v bytes of hex\n", crypto.SignatureLength)
+	}
+	if v := sigBytes[crypto.SignatureLength-1]; v == 27 || v == 28 {
+		sigBytes[crypto.SignatureLength-1] = v - 27
+	}
+
+	result := offChainVerification{
+		Address: address.Hex(),
+		Hash:    crypto.Keccak256Hash(signingBytes).Hex(),
+	}
+	sig, _ := crypto.SignatureFromBytes(sigBytes)
+	if recovered, err := sig.RecoverSignerAddress(signingBytes); err == nil {
+		result.Recovered = recovered.Hex()
+		result.Valid = recovered == address
+	}
+
+	switch {
+	case result.Valid:
+		utils.PrintResult(result, "Valid signature of %v\n", address.Hex())
+	case result.Recovered != "":
+		utils.PrintResult(result, "INVALID signature, signed by %v\n", result.Recovered)
+	default:
+		utils.PrintResult(result, "INVALID signature\n")
+	}
+	if !result.Valid {
+		os.Exit(utils.ExitCodeError)
+	}
+}
+
+func init() {
+	signMessageCmd.Flags().BoolVar(&hexMessageFlag, "hex", false, "The message is given as 0x prefixed hex")
+	verifyMessageCmd.Flags().BoolVar(&hexMessageFlag, "hex", false, "The message is given as 0x prefixed hex")
+}
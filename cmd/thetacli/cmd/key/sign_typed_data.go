@@ -0,0 +1,65 @@
+package key
+
+import (
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/eip712"
+)
+
+// signTypedDataCmd signs EIP-712 typed structured data with a key, as eth_signTypedData_v4 of the
+// Ethereum wallets does
+var signTypedDataCmd = &cobra.Command{
+	Use:   "sign-typed-data <address> <file>",
+	Short: "Sign EIP-712 typed data with a key",
+	Long: `Sign EIP-712 typed structured data with a key, e.g. an airdrop claim verified by a smart contract. The
+file holds the typed data in the JSON format of eth_signTypedData_v4, with the types, the primary type, the
+domain and the message. The signature is compatible with eth_signTypedData_v4 of the Ethereum wallets.`,
+	Example: `thetacli key sign-typed-data 2E833968E5bB786Ae419c4d13189fB081Cc43bab claim.json`,
+	Args:    cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		address := common.HexToAddress(utils.ResolveAddress(cmd.Flag("config").Value.String(), args[0]))
+		signingBytes := readTypedData(args[1])
+		sig := signOffChain(cmd, address, signingBytes)
+
+		result := offChainSignature{
+			Address:   address.Hex(),
+			Hash:      crypto.Keccak256Hash(signingBytes).Hex(),
+			Signature: sig,
+		}
+		utils.PrintResult(result, "%v\n", sig)
+	},
+}
+
+// verifyTypedDataCmd verifies a signature of EIP-712 typed data
+var verifyTypedDataCmd = &cobra.Command{
+	Use:     "verify-typed-data <address> <file> <signature>",
+	Short:   "Verify a signature of EIP-712 typed data",
+	Long:    `Verify a signature of EIP-712 typed data made by sign-typed-data, or by eth_signTypedData_v4 of an Ethereum wallet.`,
+	Example: `thetacli key verify-typed-data 2E833968E5bB786Ae419c4d13189fB081Cc43bab claim.json 0x4355...1c`,
+	Args:    cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		address := common.HexToAddress(utils.ResolveAddress(cmd.Flag("config").Value.String(), args[0]))
+		verifyOffChain(address, readTypedData(args[1]), args[2])
+	},
+}
+
+// readTypedData reads the typed data from the file, and returns the bytes to sign
+func readTypedData(filePath string) []byte {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		utils.Error("Failed to read %v: %v\n", filePath, err)
+	}
+	td, err := eip712.ParseTypedData(content)
+	if err != nil {
+		utils.Error("Failed to parse %v: %v\n", filePath, err)
+	}
+	signingBytes, err := td.SigningBytes()
+	if err != nil {
+		utils.Error("Failed to encode the typed data: %v\n", err)
+	}
+	return signingBytes
+}
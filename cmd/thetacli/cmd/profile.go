@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+)
+
+// profileCmd lists the profiles of the networks in the config
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "List the network profiles in the config",
+	Long: `List the network profiles in the config, with the chain ID, the RPC endpoints and the keys directory of
+each. The profile is selected with --profile, or with the profile setting of the config.`,
+	Example: "thetacli profile --profile=testnet",
+	Args:    cobra.NoArgs,
+	Run:     runProfile,
+}
+
+type profileEntry struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	utils.Profile
+}
+
+func runProfile(cmd *cobra.Command, args []string) {
+	profiles, err := utils.Profiles()
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+	entries := []profileEntry{}
+	for _, name := range utils.ProfileNames() {
+		entries = append(entries, profileEntry{name, name == utils.ActiveProfile(), profiles[name]})
+	}
+	if utils.IsJSONOutput() {
+		utils.PrintJSON(entries)
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No profiles in the config\n")
+		return
+	}
+	for _, entry := range entries {
+		marker := " "
+		if entry.Active {
+			marker = "*"
+		}
+		endpoints := entry.RemoteRPCEndpoints
+		if len(endpoints) == 0 && entry.RemoteRPCEndpoint != "" {
+			endpoints = []string{entry.RemoteRPCEndpoint}
+		}
+		fmt.Printf("%v %v\n", marker, entry.Name)
+		fmt.Printf("    Chain ID:      %v\n", orNone(entry.ChainID))
+		fmt.Printf("    RPC endpoints: %v\n", orNone(strings.Join(endpoints, ", ")))
+		fmt.Printf("    Keys:          %v\n", orNone(entry.KeysDir))
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -9,8 +9,6 @@ import (
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // accountCmd represents the account command.
@@ -25,17 +23,17 @@ var accountCmd = &cobra.Command{
 }
 
 func doAccountCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	res, err := client.Call("theta.GetAccount", rpc.GetAccountArgs{
 		Address: addressFlag,
 		Height:  common.JSONUint64(heightFlag),
 		Preview: previewFlag})
 	if err != nil {
-		utils.Error("Failed to get account details: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get account details: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to get account details: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get account details: %v\n", res.Error)
 	}
 	json, err := json.MarshalIndent(res.Result, "", "    ")
 	if err != nil {
@@ -10,9 +10,7 @@ import (
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/ybbus/jsonrpc"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // blockCmd represents the block command.
@@ -26,7 +24,7 @@ var blockCmd = &cobra.Command{
 	Long:    `Get block details.`,
 	Example: `thetacli query block --height=300`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+		client := utils.NewRPCClient()
 
 		var res *jsonrpc.RPCResponse
 		var err error
@@ -49,10 +47,10 @@ var blockCmd = &cobra.Command{
 		}
 
 		if err != nil {
-			utils.Error("Failed to get block(s) details: %v\n", err)
+			utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get block(s) details: %v\n", err)
 		}
 		if res.Error != nil {
-			utils.Error("Failed to retrieve block(s) details: %v\n", res.Error)
+			utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to retrieve block(s) details: %v\n", res.Error)
 		}
 		json, err := json.MarshalIndent(res.Result, "", "    ")
 		if err != nil {
@@ -5,12 +5,9 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // eenpCmd represents the eenp command.
@@ -24,15 +21,15 @@ var eenpCmd = &cobra.Command{
 }
 
 func doEenpCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	height := heightFlag
 	res, err := client.Call("theta.GetEenpByHeight", rpc.GetEenpByHeightArgs{Height: common.JSONUint64(height)})
 	if err != nil {
-		utils.Error("Failed to get elite edge node pool: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get elite edge node pool: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to get elite edge node pool: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get elite edge node pool: %v\n", res.Error)
 	}
 	json, err := json.MarshalIndent(res.Result, "", "    ")
 	if err != nil {
@@ -5,12 +5,9 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // gcpCmd represents the gcp command.
@@ -24,15 +21,15 @@ var gcpCmd = &cobra.Command{
 }
 
 func doGcpCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	height := heightFlag
 	res, err := client.Call("theta.GetGcpByHeight", rpc.GetGcpByHeightArgs{Height: common.JSONUint64(height)})
 	if err != nil {
-		utils.Error("Failed to get guardian candidate pool: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get guardian candidate pool: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to get guardian candidate pool: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get guardian candidate pool: %v\n", res.Error)
 	}
 	json, err := json.MarshalIndent(res.Result, "", "    ")
 	if err != nil {
@@ -0,0 +1,89 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// govProposalsCmd represents the governance proposals command.
+// Example:
+//		thetacli query gov_proposals --status=voting
+//		thetacli query gov_proposals --id=1
+var govProposalsCmd = &cobra.Command{
+	Use:   "gov_proposals",
+	Short: "Get the governance proposals, or a single proposal along with its param changes",
+	Example: `thetacli query gov_proposals --status=voting
+thetacli query gov_proposals --id=1`,
+	Run: doGovProposalsCmd,
+}
+
+func doGovProposalsCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient()
+	var res *rpcc.RPCResponse
+	var err error
+	if cmd.Flags().Changed("id") {
+		res, err = client.Call("theta.GetGovProposal", rpc.GetGovProposalArgs{
+			ProposalID: common.JSONUint64(proposalIDFlag),
+		})
+	} else {
+		res, err = client.Call("theta.GetGovProposals", rpc.GetGovProposalsArgs{
+			Status: statusFlag,
+		})
+	}
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get governance proposals: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get governance proposals: %v\n", res.Error)
+	}
+	json, err := json.MarshalIndent(res.Result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
+	}
+	fmt.Println(string(json))
+}
+
+// govVotesCmd represents the governance votes command.
+// Example:
+//		thetacli query gov_votes --id=1
+var govVotesCmd = &cobra.Command{
+	Use:     "gov_votes",
+	Short:   "Get the votes on a governance proposal",
+	Example: `thetacli query gov_votes --id=1`,
+	Run:     doGovVotesCmd,
+}
+
+func doGovVotesCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient()
+	res, err := client.Call("theta.GetGovVotes", rpc.GetGovVotesArgs{
+		ProposalID: common.JSONUint64(proposalIDFlag),
+		Voter:      addressFlag,
+	})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get governance votes: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get governance votes: %v\n", res.Error)
+	}
+	json, err := json.MarshalIndent(res.Result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
+	}
+	fmt.Println(string(json))
+}
+
+func init() {
+	govProposalsCmd.Flags().Uint64Var(&proposalIDFlag, "id", 0, "ID of the proposal")
+	govProposalsCmd.Flags().StringVar(&statusFlag, "status", "", "Status of the proposals (voting|passed|rejected|failed), all the proposals are returned if empty")
+
+	govVotesCmd.Flags().Uint64Var(&proposalIDFlag, "id", 0, "ID of the proposal")
+	govVotesCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the voter, the votes of all the voters are returned if empty")
+	govVotesCmd.MarkFlagRequired("id")
+}
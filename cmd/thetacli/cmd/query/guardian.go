@@ -8,8 +8,6 @@ import (
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // guardianCmd retreves guardian related information from Theta server.
@@ -32,14 +30,14 @@ type GuardianResult struct {
 }
 
 func doGuardianCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	res, err := client.Call("theta.GetGuardianInfo", rpc.GetGuardianInfoArgs{})
 	if err != nil {
-		utils.Error("Failed to get guardian info: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get guardian info: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to get guardian info: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get guardian info: %v\n", res.Error)
 	}
 	result := res.Result.(map[string]interface{})
 	address, ok := result["Address"].(string)
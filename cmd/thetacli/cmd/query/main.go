@@ -2,6 +2,7 @@ package query
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 )
 
 var (
@@ -15,12 +16,25 @@ var (
 	endFlag              uint64
 	skipEdgeNodeFlag     bool
 	includeEthTxHashFlag bool
+	resourceIDsFlag      []string
+	namespaceFlag        string
+	holderFlag           string
+	sourceFlag           string
+	proposalIDFlag       uint64
+	statusFlag           string
 )
 
 // QueryCmd represents the query command
 var QueryCmd = &cobra.Command{
 	Use:   "query",
 	Short: "Query entities stored in blockchain",
+	Long:  `Query entities stored in blockchain. The addresses can also be given as names in the address book.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		cfgPath := cmd.Flag("config").Value.String()
+		for _, flag := range []*string{&addressFlag, &holderFlag, &sourceFlag} {
+			*flag = utils.ResolveAddress(cfgPath, *flag)
+		}
+	},
 }
 
 func init() {
@@ -30,11 +44,19 @@ func init() {
 	QueryCmd.AddCommand(blockCmd)
 	QueryCmd.AddCommand(txCmd)
 	QueryCmd.AddCommand(splitRuleCmd)
+	QueryCmd.AddCommand(activeSplitRulesCmd)
+	QueryCmd.AddCommand(reservesCmd)
 	QueryCmd.AddCommand(vcpCmd)
 	QueryCmd.AddCommand(gcpCmd)
 	QueryCmd.AddCommand(eenpCmd)
 	QueryCmd.AddCommand(srdrsCmd)
 	QueryCmd.AddCommand(stakeReturnsCmd)
+	QueryCmd.AddCommand(stakesCmd)
+	QueryCmd.AddCommand(validatorsCmd)
 	QueryCmd.AddCommand(peersCmd)
 	QueryCmd.AddCommand(versionCmd)
+	QueryCmd.AddCommand(vestingCmd)
+	QueryCmd.AddCommand(tokensCmd)
+	QueryCmd.AddCommand(govProposalsCmd)
+	QueryCmd.AddCommand(govVotesCmd)
 }
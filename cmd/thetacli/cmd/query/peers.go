@@ -8,8 +8,6 @@ import (
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // peersCmd represents the peers command.
@@ -21,16 +19,16 @@ var peersCmd = &cobra.Command{
 	Long:    `Get currently connected peers.`,
 	Example: `thetacli query peers`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+		client := utils.NewRPCClient()
 
 		res, err := client.Call("theta.GetPeers", rpc.GetPeersArgs{
 			SkipEdgeNode: skipEdgeNodeFlag,
 		})
 		if err != nil {
-			utils.Error("Failed to get peers: %v\n", err)
+			utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get peers: %v\n", err)
 		}
 		if res.Error != nil {
-			utils.Error("Failed to retrieve peers: %v\n", res.Error)
+			utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to retrieve peers: %v\n", res.Error)
 		}
 		json, err := json.MarshalIndent(res.Result, "", "    ")
 		if err != nil {
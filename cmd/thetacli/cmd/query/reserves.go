@@ -0,0 +1,140 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// Status of the reserved funds. A reserved fund can pay for the services until its end height, and
+// can be released once the freeze period after the end height is over.
+const (
+	reserveStatusActive     = "active"
+	reserveStatusFrozen     = "frozen"
+	reserveStatusReleasable = "releasable"
+)
+
+// reservesCmd represents the reserves command.
+// Example:
+//		thetacli query reserves --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab
+var reservesCmd = &cobra.Command{
+	Use:   "reserves",
+	Short: "Get the reserved funds of an account",
+	Long: `Get the funds reserved by an account for the service payments, with their reserve sequences, collateral,
+remaining funds, end heights and the heights from which they can be released.`,
+	Example: `thetacli query reserves --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab`,
+	Run:     doReservesCmd,
+}
+
+// reserveSummary is a reserved fund as seen at the queried height
+type reserveSummary struct {
+	ReserveSequence common.JSONUint64 `json:"reserve_sequence"`
+	Status          string            `json:"status"`
+	ResourceIDs     []string          `json:"resource_ids"`
+	Collateral      types.Coins       `json:"collateral"`
+	InitialFund     types.Coins       `json:"initial_fund"`
+	UsedFund        types.Coins       `json:"used_fund"`
+	RemainingFund   types.Coins       `json:"remaining_fund"`
+	EndBlockHeight  common.JSONUint64 `json:"end_block_height"`
+	ReleaseHeight   common.JSONUint64 `json:"release_height"`
+	Payments        int               `json:"payments"`
+}
+
+func doReservesCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient()
+
+	height := heightFlag
+	if height == 0 {
+		height = queryFinalizedHeight(client)
+	}
+	res, err := client.Call("theta.GetAccount", rpc.GetAccountArgs{
+		Address: addressFlag,
+		Height:  common.JSONUint64(heightFlag)})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get account details: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get account details: %v\n", res.Error)
+	}
+	account := &types.Account{}
+	if err := res.GetObject(account); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+
+	reserves := []reserveSummary{}
+	for _, fund := range account.ReservedFunds {
+		remaining := fund.InitialFund.Minus(fund.UsedFund)
+		if !remaining.IsNonnegative() {
+			remaining = types.NewCoins(0, 0)
+		}
+		releaseHeight := fund.EndBlockHeight + types.ReservedFundFreezePeriodDuration
+		status := reserveStatusActive
+		if height >= releaseHeight {
+			status = reserveStatusReleasable
+		} else if height > fund.EndBlockHeight {
+			status = reserveStatusFrozen
+		}
+		reserves = append(reserves, reserveSummary{
+			ReserveSequence: common.JSONUint64(fund.ReserveSequence),
+			Status:          status,
+			ResourceIDs:     fund.ResourceIDs,
+			Collateral:      fund.Collateral.NoNil(),
+			InitialFund:     fund.InitialFund.NoNil(),
+			UsedFund:        fund.UsedFund.NoNil(),
+			RemainingFund:   remaining,
+			EndBlockHeight:  common.JSONUint64(fund.EndBlockHeight),
+			ReleaseHeight:   common.JSONUint64(releaseHeight),
+			Payments:        len(fund.TransferRecords),
+		})
+	}
+
+	if utils.IsJSONOutput() {
+		utils.PrintJSON(struct {
+			Address  string            `json:"address"`
+			Height   common.JSONUint64 `json:"height"`
+			Reserves []reserveSummary  `json:"reserves"`
+		}{addressFlag, common.JSONUint64(height), reserves})
+		return
+	}
+	if len(reserves) == 0 {
+		fmt.Printf("No reserved funds of %v at height %v\n", addressFlag, height)
+		return
+	}
+	fmt.Printf("Reserved funds of %v at height %v:\n", addressFlag, height)
+	for _, r := range reserves {
+		fmt.Printf("\nReserve sequence %v: %v, ends at height %v, releasable from height %v\n",
+			r.ReserveSequence, r.Status, r.EndBlockHeight, r.ReleaseHeight)
+		fmt.Printf("  Resources:  %v\n", strings.Join(r.ResourceIDs, ", "))
+		fmt.Printf("  Collateral: %v\n", utils.FormatCoins(r.Collateral))
+		fmt.Printf("  Fund:       %v, %v used\n", utils.FormatCoins(r.InitialFund), utils.FormatCoins(r.UsedFund))
+		fmt.Printf("  Remaining:  %v\n", utils.FormatCoins(r.RemainingFund))
+		fmt.Printf("  Payments:   %v\n", r.Payments)
+	}
+}
+
+// queryFinalizedHeight returns the height of the latest finalized block
+func queryFinalizedHeight(client utils.RPCClient) uint64 {
+	res, err := client.Call("theta.GetStatus", rpc.GetStatusArgs{})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get blockchain status: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get blockchain status: %v\n", res.Error)
+	}
+	status := &rpc.GetStatusResult{}
+	if err := res.GetObject(status); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	return uint64(status.LatestFinalizedBlockHeight)
+}
+
+func init() {
+	reservesCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the account")
+	reservesCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the block, the latest finalized block by default")
+	reservesCmd.MarkFlagRequired("address")
+}
@@ -5,11 +5,10 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // splitRuleCmd represents the split_rule command.
@@ -17,21 +16,83 @@ import (
 //		thetacli query split_rule --resource_id=vid2dz369du0mkwcrb9
 var splitRuleCmd = &cobra.Command{
 	Use:     "split_rule",
+	Aliases: []string{"splitrule"},
 	Short:   "Get split rule status",
+	Long: `Get the split rule of a resource, with the percentages of the service payments going to each address.
+The remainder of each payment goes to the receiver of the payment.`,
 	Example: `thetacli query split_rule --resource_id=vid2dz369du0mkwcrb9`,
 	Run:     doSplitRuleCmd,
 }
 
+// activeSplitRulesCmd represents the active_split_rules command.
+// Example:
+//		thetacli query active_split_rules --resource_ids=vid2dz369du0mkwcrb9,vidivt6ebd4ycy4jk8t
+var activeSplitRulesCmd = &cobra.Command{
+	Use:     "active_split_rules",
+	Short:   "Get the active split rules of the given resource IDs",
+	Example: `thetacli query active_split_rules --resource_ids=vid2dz369du0mkwcrb9,vidivt6ebd4ycy4jk8t`,
+	Run:     doActiveSplitRulesCmd,
+}
+
 func doSplitRuleCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	resourceID := resourceIDFlag
-	res, err := client.Call("theta.GetSplitRule", rpc.GetSplitRuleArgs{ResourceID: resourceID})
+	res, err := client.Call("theta.GetSplitRule", rpc.GetSplitRuleArgs{ResourceID: resourceID, Height: common.JSONUint64(heightFlag)})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get split rule details: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get split rule details: %v\n", res.Error)
+	}
+	if utils.IsJSONOutput() {
+		utils.PrintJSON(res.Result)
+		return
+	}
+	if res.Result == nil {
+		fmt.Printf("No split rule for %v\n", resourceID)
+		return
+	}
+	splitRule := &types.SplitRule{}
+	if err := res.GetObject(splitRule); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	height := heightFlag
+	if height == 0 {
+		height = queryFinalizedHeight(client)
+	}
+	printSplitRule(splitRule, height)
+}
+
+// printSplitRule prints the split percentages of the rule. The remainder of each service payment
+// goes to the receiver of the payment.
+func printSplitRule(splitRule *types.SplitRule, height uint64) {
+	status := fmt.Sprintf("active until height %v", splitRule.EndBlockHeight)
+	if height > splitRule.EndBlockHeight {
+		status = fmt.Sprintf("expired at height %v", splitRule.EndBlockHeight)
+	}
+	fmt.Printf("Split rule of %v, %v\n", splitRule.ResourceID, status)
+	fmt.Printf("  Initiator: %v\n", splitRule.InitiatorAddress.Hex())
+
+	remainder := 100
+	for _, split := range splitRule.Splits {
+		fmt.Printf("  %v %3v%%\n", split.Address.Hex(), split.Percentage)
+		remainder -= int(split.Percentage)
+	}
+	if remainder > 0 {
+		fmt.Printf("  %-42v %3v%%\n", "Payment receiver", remainder)
+	}
+}
+
+func doActiveSplitRulesCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient()
+
+	res, err := client.Call("theta.GetActiveSplitRules", rpc.GetActiveSplitRulesArgs{ResourceIDs: resourceIDsFlag})
 	if err != nil {
-		utils.Error("Failed to get split rule details: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get active split rules: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to get split rule details: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get active split rules: %v\n", res.Error)
 	}
 	json, err := json.MarshalIndent(res.Result, "", "    ")
 	if err != nil {
@@ -42,5 +103,9 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 
 func init() {
 	splitRuleCmd.Flags().StringVar(&resourceIDFlag, "resource_id", "", "Resource ID of the contract")
+	splitRuleCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the block, the latest state if zero")
 	splitRuleCmd.MarkFlagRequired("resource_id")
+
+	activeSplitRulesCmd.Flags().StringSliceVar(&resourceIDsFlag, "resource_ids", []string{}, "Resource IDs of the contracts")
+	activeSplitRulesCmd.MarkFlagRequired("resource_ids")
 }
@@ -5,12 +5,9 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // srdrsCmd represents the eenp command.
@@ -24,17 +21,17 @@ var srdrsCmd = &cobra.Command{
 }
 
 func doSrdrsCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 	height := heightFlag
 	res, err := client.Call("theta.GetStakeRewardDistributionByHeight", rpc.GetStakeRewardDistributionRuleSetByHeightArgs{
 		Height:  common.JSONUint64(height),
 		Address: addressFlag,
 	})
 	if err != nil {
-		utils.Error("Failed to get stake reward distribution rule set: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get stake reward distribution rule set: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to get stake reward distribution rule set: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get stake reward distribution rule set: %v\n", res.Error)
 	}
 	json, err := json.MarshalIndent(res.Result, "", "    ")
 	if err != nil {
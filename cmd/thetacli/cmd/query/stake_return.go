@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/rpc"
@@ -24,12 +23,11 @@ var stakeReturnsCmd = &cobra.Command{
 }
 
 func doStakeReturnsCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	purpose := purposeFlag
 	if purpose != 2 {
-		fmt.Println("Only support querying stake return for elite edge nodes (purpose=2) for now")
-		return
+		utils.Error("Only support querying stake return for elite edge nodes (purpose=2) for now\n")
 	}
 
 	height := heightFlag
@@ -41,10 +39,10 @@ func doStakeReturnsCmd(cmd *cobra.Command, args []string) {
 		res, err = client.Call("theta.GetEliteEdgeNodeStakeReturnsByHeight", rpc.GetEliteEdgeNodeStakeReturnsByHeightArgs{Height: common.JSONUint64(height)})
 	}
 	if err != nil {
-		utils.Error("Failed to get stake returns: %v\n", err)
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get stake returns: %v\n", err)
 	}
 	if res.Error != nil {
-		utils.Error("Failed to get stake returns: %v\n", res.Error)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get stake returns: %v\n", res.Error)
 	}
 	json, err := json.MarshalIndent(res.Result, "", "    ")
 	if err != nil {
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// stakesCmd represents the stakes command.
+// Example:
+//		thetacli query stakes --holder=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --purpose=0
+//   * List the withdrawn stakes of a source that are not yet returned, along with their return heights
+//		thetacli query stakes --source=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab
+var stakesCmd = &cobra.Command{
+	Use:   "stakes",
+	Short: "Get the stakes of a stake holder, or the pending withdrawals of a source",
+	Long: `Get the stakes deposited to a validator candidate, guardian or elite edge node with --holder, or the
+withdrawn stakes of a source that are not yet returned with --source.`,
+	Example: `thetacli query stakes --holder=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --purpose=0`,
+	Run:     doStakesCmd,
+}
+
+// validatorsCmd represents the validators command.
+// Example:
+//		thetacli query validators --height=10
+var validatorsCmd = &cobra.Command{
+	Use:     "validators",
+	Short:   "Get the validator set",
+	Long:    `Get the validator set in effect at the given height, or at the latest finalized block by default.`,
+	Example: `thetacli query validators --height=10`,
+	Run:     doValidatorsCmd,
+}
+
+func doStakesCmd(cmd *cobra.Command, args []string) {
+	if (len(holderFlag) == 0) == (len(sourceFlag) == 0) {
+		utils.Error("Exactly one of --holder and --source must be specified\n")
+	}
+
+	client := utils.NewRPCClient()
+
+	var res *rpcc.RPCResponse
+	var err error
+	if len(holderFlag) != 0 {
+		res, err = client.Call("theta.GetStakeHolder", rpc.GetStakeHolderArgs{
+			Holder:  holderFlag,
+			Purpose: purposeFlag,
+			Height:  common.JSONUint64(heightFlag)})
+	} else {
+		res, err = client.Call("theta.GetPendingStakeWithdrawals", rpc.GetPendingStakeWithdrawalsArgs{
+			Source: sourceFlag,
+			Height: common.JSONUint64(heightFlag)})
+	}
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get stakes: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get stakes: %v\n", res.Error)
+	}
+	json, err := json.MarshalIndent(res.Result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
+	}
+	fmt.Println(string(json))
+}
+
+func doValidatorsCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient()
+
+	res, err := client.Call("theta.GetValidatorSet", rpc.GetValidatorSetArgs{Height: common.JSONUint64(heightFlag)})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get validator set: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get validator set: %v\n", res.Error)
+	}
+	json, err := json.MarshalIndent(res.Result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
+	}
+	fmt.Println(string(json))
+}
+
+func init() {
+	stakesCmd.Flags().StringVar(&holderFlag, "holder", "", "Address of the stake holder")
+	stakesCmd.Flags().StringVar(&sourceFlag, "source", "", "Address of the stake source")
+	stakesCmd.Flags().Uint8Var(&purposeFlag, "purpose", uint8(0), "Purpose of the stakes of the holder, validator_node=0, guardian_node=1, elite_edge_node=2")
+	stakesCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the block, the latest finalized block by default")
+
+	validatorsCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the block, the latest finalized block by default")
+}
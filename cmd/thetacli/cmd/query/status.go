@@ -8,8 +8,6 @@ import (
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // statusCmd represents the account command.
@@ -21,14 +19,14 @@ var statusCmd = &cobra.Command{
 	Long:    `Get blockchain status.`,
 	Example: `thetacli query status`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+		client := utils.NewRPCClient()
 
 		res, err := client.Call("theta.GetStatus", rpc.GetStatusArgs{})
 		if err != nil {
-			utils.Error("Failed to get blockchain status: %v\n", err)
+			utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get blockchain status: %v\n", err)
 		}
 		if res.Error != nil {
-			utils.Error("Failed to retrieve blockchain status: %v\n", res.Error)
+			utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to retrieve blockchain status: %v\n", res.Error)
 		}
 		json, err := json.MarshalIndent(res.Result, "", "    ")
 		if err != nil {
@@ -0,0 +1,42 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// tokensCmd represents the tokens command.
+// Example:
+//		thetacli query tokens --namespace=theta
+var tokensCmd = &cobra.Command{
+	Use:     "tokens",
+	Short:   "Get the registered tokens",
+	Example: `thetacli query tokens --namespace=theta`,
+	Run:     doTokensCmd,
+}
+
+func doTokensCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient()
+	res, err := client.Call("theta.GetRegisteredTokens", rpc.GetRegisteredTokensArgs{
+		Namespace: namespaceFlag,
+	})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get registered tokens: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get registered tokens: %v\n", res.Error)
+	}
+	json, err := json.MarshalIndent(res.Result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
+	}
+	fmt.Println(string(json))
+}
+
+func init() {
+	tokensCmd.Flags().StringVar(&namespaceFlag, "namespace", "", "Namespace of the tokens, all the registered tokens are returned if empty")
+}
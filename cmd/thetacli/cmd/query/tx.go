@@ -8,8 +8,6 @@ import (
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // txCmd represents the query tx command.
@@ -22,16 +20,16 @@ var txCmd = &cobra.Command{
 	Long:    `Get transaction details.`,
 	Example: `thetacli query tx --hash=0x2fe41732b40ca852e9c36f52b278dde78f0fe34f28f9c94083112aa6a0624b8c`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+		client := utils.NewRPCClient()
 		res, err := client.Call("theta.GetTransaction", rpc.GetTransactionArgs{
 			Hash: hashFlag,
 		})
 
 		if err != nil {
-			utils.Error("Failed to get transaction details: %v\n", err)
+			utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get transaction details: %v\n", err)
 		}
 		if res.Error != nil {
-			utils.Error("Failed to retrieve transaction details: %v\n", res.Error)
+			utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to retrieve transaction details: %v\n", res.Error)
 		}
 		json, err := json.MarshalIndent(res.Result, "", "    ")
 		if err != nil {
@@ -5,11 +5,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // versionCmd represents the version command.
@@ -20,14 +17,14 @@ var versionCmd = &cobra.Command{
 	Short:   "Get the Theta version",
 	Example: `thetacli query version`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+		client := utils.NewRPCClient()
 
 		res, err := client.Call("theta.GetVersion", rpc.GetVersionArgs{})
 		if err != nil {
-			utils.Error("Failed to get version: %v\n", err)
+			utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get version: %v\n", err)
 		}
 		if res.Error != nil {
-			utils.Error("Failed to get version: %v\n", res.Error)
+			utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get version: %v\n", res.Error)
 		}
 		json, err := json.MarshalIndent(res.Result, "", "    ")
 		if err != nil {
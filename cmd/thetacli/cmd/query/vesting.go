@@ -0,0 +1,46 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// vestingCmd represents the vesting command.
+// Example:
+//		thetacli query vesting --address=9F1233798E905E173560071255140b4A8aBd3Ec6
+var vestingCmd = &cobra.Command{
+	Use:     "vesting",
+	Short:   "Get the vesting funds of a beneficiary",
+	Example: `thetacli query vesting --address=9F1233798E905E173560071255140b4A8aBd3Ec6`,
+	Run:     doVestingCmd,
+}
+
+func doVestingCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient()
+	res, err := client.Call("theta.GetVestingFunds", rpc.GetVestingFundsArgs{
+		Address: addressFlag,
+		Height:  common.JSONUint64(heightFlag),
+	})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get vesting funds: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get vesting funds: %v\n", res.Error)
+	}
+	json, err := json.MarshalIndent(res.Result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
+	}
+	fmt.Println(string(json))
+}
+
+func init() {
+	vestingCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the beneficiary")
+	vestingCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the block, the latest finalized block if zero")
+	vestingCmd.MarkFlagRequired("address")
+}
@@ -6,6 +6,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/addressbook"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/backup"
 
 	homedir "github.com/mitchellh/go-homedir"
@@ -16,9 +17,17 @@ import (
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/key"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/query"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/tx"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/watch"
 )
 
 var cfgPath string
+var outputFlag string
+var nodeFlag []string
+var passwordFileFlag string
+var passwordFDFlag int
+var keychainFlag bool
+var profileFlag string
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
@@ -31,8 +40,7 @@ var RootCmd = &cobra.Command{
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		utils.Error("%v\n", err)
 	}
 }
 
@@ -40,18 +48,38 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	RootCmd.PersistentFlags().StringVar(&cfgPath, "config", getDefaultConfigPath(), fmt.Sprintf("config path (default is %s)", getDefaultConfigPath()))
+	RootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Profile of the network in the config, e.g. --profile=testnet")
+	RootCmd.PersistentFlags().StringSliceVar(&nodeFlag, "node", []string{}, "RPC endpoints of the node for this invocation, overriding the configured ones, e.g. --node=http://localhost:16888/rpc")
+	RootCmd.PersistentFlags().StringVar(&passwordFileFlag, "password_file", "", "File holding the wallet password on its first line, accessible by the owner only")
+	RootCmd.PersistentFlags().IntVar(&passwordFDFlag, "password_fd", -1, "File descriptor to read the wallet password from, e.g. --password_fd=3 3<<<\"$PASSWORD\"")
+	RootCmd.PersistentFlags().BoolVar(&keychainFlag, "keychain", false, "Read the wallet passwords from the OS keychain")
+	RootCmd.PersistentFlags().StringVar(&outputFlag, "output", utils.OutputText, "Output format (text|json), json for machine-readable output without interactive prompts")
 
 	RootCmd.AddCommand(daemon.DaemonCmd)
 	RootCmd.AddCommand(key.KeyCmd)
+	RootCmd.AddCommand(addressbook.AddressBookCmd)
 	RootCmd.AddCommand(tx.TxCmd)
 	RootCmd.AddCommand(query.QueryCmd)
 	RootCmd.AddCommand(call.CallCmd)
+	RootCmd.AddCommand(tx.ContractCmd)
 	RootCmd.AddCommand(backup.BackupCmd)
+	RootCmd.AddCommand(watch.WatchCmd)
 	RootCmd.AddCommand(versionCmd)
+	RootCmd.AddCommand(profileCmd)
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	if err := utils.SetOutputFormat(outputFlag); err != nil {
+		utils.Error("%v\n", err)
+	}
+
+	utils.SetPasswordSource(utils.PasswordSource{
+		File:     passwordFileFlag,
+		FD:       passwordFDFlag,
+		Keychain: keychainFlag,
+	})
+
 	viper.AddConfigPath(cfgPath)
 
 	// Search config (without extension).
@@ -62,7 +90,18 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		utils.Info("Using config file: %v\n", viper.ConfigFileUsed())
+	}
+
+	if err := utils.ApplyProfile(profileFlag); err != nil {
+		utils.Error("%v\n", err)
+	}
+	if utils.ActiveProfile() != "" {
+		utils.Info("Using profile: %v\n", utils.ActiveProfile())
+	}
+
+	if len(nodeFlag) > 0 {
+		viper.Set(utils.CfgRemoteRPCEndpoints, nodeFlag)
 	}
 }
 
@@ -0,0 +1,327 @@
+package tx
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// batchSendCmd represents the batch send command. Each output is specified as <address>:<theta>:<tfuel>,
+// or as a row of a CSV or JSON file for payouts and airdrops with many recipients.
+// Example:
+//		thetacli tx batch_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --outputs=9F1233798E905E173560071255140b4A8aBd3Ec6:10:9,0d2fD67d573c8ecB4161510fc00754d64B401F86:0:25 --seq=1
+//   * Read the outputs from a CSV file with the address,theta,tfuel columns, or a JSON file with an array of
+//     {"address","theta","tfuel"} objects
+//		thetacli tx batch_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --file=payouts.csv
+//   * Send a series of transactions with consecutive sequences, one for each output, instead of a single transaction
+//		thetacli tx batch_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --file=payouts.csv --series --async
+var batchSendCmd = &cobra.Command{
+	Use:     "batch_send",
+	Aliases: []string{"send-batch"},
+	Short:   "Send tokens to multiple addresses in one transaction, or in a series of transactions",
+	Example: `thetacli tx batch_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --outputs=9F1233798E905E173560071255140b4A8aBd3Ec6:10:9,0d2fD67d573c8ecB4161510fc00754d64B401F86:0:25 --seq=1
+thetacli tx batch_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --file=payouts.csv --series`,
+	Run: doBatchSendCmd,
+}
+
+// batchSendOutput is an output of the batch send command, in the format of the rows of a JSON file
+type batchSendOutput struct {
+	Address string `json:"address"`
+	Theta   string `json:"theta"`
+	TFuel   string `json:"tfuel"`
+}
+
+// batchSendResult is the result of a transaction sent by the batch send command in the series mode
+type batchSendResult struct {
+	Address string `json:"address"`
+	Hash    string `json:"hash,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func parseBatchSendOutputs(cfgPath string) []types.TxOutput {
+	if len(fileFlag) != 0 {
+		if len(outputsFlag) != 0 {
+			utils.Error("The --outputs flag cannot be combined with the --file flag\n")
+		}
+		return readBatchSendFile(cfgPath, fileFlag)
+	}
+
+	outputs := []types.TxOutput{}
+	for _, outputStr := range outputsFlag {
+		fields := strings.Split(outputStr, ":")
+		if len(fields) != 3 {
+			utils.Error("Invalid output %v, expected <address>:<theta>:<tfuel>\n", outputStr)
+		}
+		outputs = append(outputs, parseBatchSendOutput(cfgPath, outputStr, batchSendOutput{fields[0], fields[1], fields[2]}))
+	}
+	return outputs
+}
+
+// parseBatchSendOutput parses an output, whose address can also be a name in the address book
+func parseBatchSendOutput(cfgPath string, name string, output batchSendOutput) types.TxOutput {
+	address := utils.ResolveAddress(cfgPath, strings.TrimSpace(output.Address))
+	if !common.IsHexAddress(address) {
+		utils.Error("Invalid address of output %v\n", name)
+	}
+	// Empty amounts are allowed in the files, e.g. for the outputs sending only TFuel
+	theta, tfuel := big.NewInt(0), big.NewInt(0)
+	var ok bool
+	if amount := strings.TrimSpace(output.Theta); len(amount) != 0 {
+		if theta, ok = types.ParseCoinAmountOf(amount, types.DenomTheta); !ok {
+			utils.Error("Failed to parse theta amount of output %v\n", name)
+		}
+	}
+	if amount := strings.TrimSpace(output.TFuel); len(amount) != 0 {
+		if tfuel, ok = types.ParseCoinAmountOf(amount, types.DenomTFuel); !ok {
+			utils.Error("Failed to parse tfuel amount of output %v\n", name)
+		}
+	}
+	return types.TxOutput{
+		Address: common.HexToAddress(address),
+		Coins: types.Coins{
+			ThetaWei: theta,
+			TFuelWei: tfuel,
+		},
+	}
+}
+
+// readBatchSendFile reads the outputs from a JSON file if it has the .json extension, otherwise
+// from a CSV file with the address,theta,tfuel columns and an optional header row
+func readBatchSendFile(cfgPath string, filePath string) []types.TxOutput {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		utils.Error("Failed to read %v: %v\n", filePath, err)
+	}
+
+	rows := []batchSendOutput{}
+	if strings.ToLower(filepath.Ext(filePath)) == ".json" {
+		if err := json.Unmarshal(content, &rows); err != nil {
+			utils.Error("Failed to parse %v: %v\n", filePath, err)
+		}
+	} else {
+		reader := csv.NewReader(strings.NewReader(string(content)))
+		reader.Comment = '#'
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			utils.Error("Failed to parse %v: %v\n", filePath, err)
+		}
+		for i, record := range records {
+			if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+				continue // header row
+			}
+			if len(record) != 3 {
+				utils.Error("Invalid row %v of %v, expected address,theta,tfuel\n", i+1, filePath)
+			}
+			rows = append(rows, batchSendOutput{record[0], record[1], record[2]})
+		}
+	}
+
+	outputs := []types.TxOutput{}
+	for i, row := range rows {
+		outputs = append(outputs, parseBatchSendOutput(cfgPath, fmt.Sprintf("#%v (%v)", i+1, row.Address), row))
+	}
+	return outputs
+}
+
+func doBatchSendCmd(cmd *cobra.Command, args []string) {
+	walletType := getWalletType(cmd)
+	if walletType == wtypes.WalletTypeSoft && len(fromFlag) == 0 {
+		utils.Error("The from address cannot be empty") // we don't need to specify the "from address" for hardware wallets
+		return
+	}
+
+	outputs := parseBatchSendOutputs(cmd.Flag("config").Value.String())
+	if len(outputs) == 0 {
+		utils.Error("The outputs cannot be empty")
+		return
+	}
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	if seriesFlag {
+		sendSeries(cmd, wallet, fromAddress, outputs)
+		return
+	}
+
+	fee := getFee(types.TxBatchSend, uint64(len(outputs)))
+
+	outTotal := types.NewCoins(0, 0)
+	for _, output := range outputs {
+		outTotal = outTotal.Plus(output.Coins)
+	}
+	batchSendTx := &types.BatchSendTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Input: types.TxInput{
+			Address: fromAddress,
+			Coins: types.Coins{
+				TFuelWei: new(big.Int).Add(outTotal.TFuelWei, fee),
+				ThetaWei: outTotal.ThetaWei,
+			},
+			Sequence: getSequence(cmd, fromAddress),
+		},
+		Outputs: outputs,
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(batchSendTx, chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	batchSendTx.SetSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(batchSendTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	broadcastSignedTx(hex.EncodeToString(raw))
+}
+
+// sendSeries signs a send transaction for each output with consecutive sequences, and then broadcasts
+// them in order. As the later transactions can't be executed without the earlier ones, the
+// broadcasting stops at the first failure, and the remaining transactions are reported as not sent.
+func sendSeries(cmd *cobra.Command, wallet wtypes.Wallet, fromAddress common.Address, outputs []types.TxOutput) {
+	fee := getFee(types.TxSend, 2)
+	sequence := getSequence(cmd, fromAddress)
+
+	// The series is confirmed as a whole instead of each transaction
+	total := types.NewCoins(0, 0)
+	for _, output := range outputs {
+		total = total.Plus(output.Coins)
+	}
+	confirmTx([]string{
+		fmt.Sprintf("Type:     series of %v SendTx", len(outputs)),
+		fmt.Sprintf("Chain ID: %v", chainIDFlag),
+		fmt.Sprintf("From:     %v", fromAddress.Hex()),
+		fmt.Sprintf("Sequence: %v to %v", sequence, sequence+uint64(len(outputs))-1),
+		fmt.Sprintf("Total:    %v", utils.FormatCoins(total)),
+		fmt.Sprintf("Fee:      %v each", utils.FormatCoinAmount(fee, types.DenomTFuel)),
+	})
+
+	signedTxs := []string{}
+	for i, output := range outputs {
+		sendTx := &types.SendTx{
+			Fee: types.Coins{
+				ThetaWei: new(big.Int).SetUint64(0),
+				TFuelWei: fee,
+			},
+			Inputs: []types.TxInput{{
+				Address: fromAddress,
+				Coins: types.Coins{
+					TFuelWei: new(big.Int).Add(output.Coins.TFuelWei, fee),
+					ThetaWei: output.Coins.ThetaWei,
+				},
+				Sequence: sequence + uint64(i),
+			}},
+			Outputs: []types.TxOutput{output},
+		}
+		sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(sendTx, chainIDFlag))
+		if err != nil {
+			utils.Error("Failed to sign transaction #%v: %v\n", i+1, err)
+		}
+		sendTx.SetSignature(fromAddress, sig)
+
+		raw, err := types.TxToBytes(sendTx)
+		if err != nil {
+			utils.Error("Failed to encode transaction #%v: %v\n", i+1, err)
+		}
+		signedTxs = append(signedTxs, hex.EncodeToString(raw))
+	}
+
+	client := utils.NewRPCClient()
+	results := []batchSendResult{}
+	failed := false
+	for i, signedTx := range signedTxs {
+		result := batchSendResult{Address: outputs[i].Address.Hex()}
+		if failed {
+			result.Error = "not sent"
+			results = append(results, result)
+			continue
+		}
+
+		hash, err := broadcastSeriesTx(client, signedTx)
+		if err != nil {
+			failed = true
+			result.Error = err.Error()
+			fmt.Fprintf(os.Stderr, "[%v/%v] Failed to send to %v: %v\n", i+1, len(signedTxs), result.Address, err)
+		} else {
+			result.Hash = hash
+			fmt.Fprintf(os.Stderr, "[%v/%v] Sent to %v: %v\n", i+1, len(signedTxs), result.Address, hash)
+			// Only the sequences of the broadcasted transactions are recorded
+			if assignedSequence != nil {
+				assignedSequence.sequence = sequence + uint64(i)
+				recordPendingSequence()
+			}
+		}
+		results = append(results, result)
+	}
+
+	formatted, err := json.MarshalIndent(results, "", "    ")
+	if err != nil {
+		utils.Error("Failed to encode the result: %v\n", err)
+	}
+	utils.PrintResult(struct {
+		Transactions []batchSendResult `json:"transactions"`
+	}{results}, "%s\n", formatted)
+	if failed {
+		os.Exit(utils.ExitCodeServerError)
+	}
+}
+
+func broadcastSeriesTx(client utils.RPCClient, signedTx string) (string, error) {
+	var res *rpcc.RPCResponse
+	var err error
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	}
+	if err != nil {
+		return "", err
+	}
+	if res.Error != nil {
+		return "", fmt.Errorf("Server returned error: %v", res.Error)
+	}
+	result := &rpc.BroadcastRawTransactionResult{}
+	if err := res.GetObject(result); err != nil {
+		return "", fmt.Errorf("Failed to parse server response: %v", err)
+	}
+	return result.TxHash, nil
+}
+
+func init() {
+	batchSendCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	batchSendCmd.Flags().StringVar(&fromFlag, "from", "", "Address to send from")
+	batchSendCmd.Flags().StringSliceVar(&outputsFlag, "outputs", []string{}, "Outputs, each in the format of <address>:<theta>:<tfuel>")
+	batchSendCmd.Flags().StringVar(&fileFlag, "file", "", "CSV or JSON file to read the outputs from, instead of --outputs")
+	batchSendCmd.Flags().BoolVar(&seriesFlag, "series", false, "Send a transaction for each output with consecutive sequences, instead of a single transaction")
+	batchSendCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	batchSendCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	batchSendCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	batchSendCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
+	batchSendCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	batchSendCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	batchSendCmd.MarkFlagRequired("chain")
+}
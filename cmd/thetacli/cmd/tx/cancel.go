@@ -0,0 +1,84 @@
+package tx
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/ledger/types"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+)
+
+// cancelCmd represents the cancel command. It sends a zero-value self-send with the sequence of
+// the pending transaction to be cancelled. The fee needs to be at least 10% higher than the fee of
+// the pending transaction for the mempool to accept the replacement.
+// Example:
+//		thetacli tx cancel --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --seq=7 --fee=0.5
+var cancelCmd = &cobra.Command{
+	Use:     "cancel",
+	Short:   "Cancel a pending transaction",
+	Example: `thetacli tx cancel --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --seq=7 --fee=0.5`,
+	Run:     doCancelCmd,
+}
+
+func doCancelCmd(cmd *cobra.Command, args []string) {
+	walletType := getWalletType(cmd)
+	if walletType == wtypes.WalletTypeSoft && len(fromFlag) == 0 {
+		utils.Error("The from address cannot be empty") // we don't need to specify the "from address" for hardware wallets
+		return
+	}
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	fee := parseAmount("fee", feeFlag, types.DenomTFuel)
+	cancelTx := &types.SendTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Inputs: []types.TxInput{{
+			Address: fromAddress,
+			Coins: types.Coins{
+				TFuelWei: fee,
+				ThetaWei: new(big.Int).SetUint64(0),
+			},
+			Sequence: uint64(seqFlag),
+		}},
+		Outputs: []types.TxOutput{{
+			Address: fromAddress,
+			Coins:   types.NewCoins(0, 0),
+		}},
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(cancelTx, chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	cancelTx.SetSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(cancelTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	broadcastSignedTx(hex.EncodeToString(raw))
+}
+
+func init() {
+	cancelCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	cancelCmd.Flags().StringVar(&fromFlag, "from", "", "Address of the pending transaction")
+	cancelCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	cancelCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the pending transaction")
+	cancelCmd.Flags().StringVar(&feeFlag, "fee", "", "Fee, needs to be at least 10% higher than that of the pending transaction")
+	cancelCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
+	cancelCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	cancelCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	cancelCmd.MarkFlagRequired("chain")
+	cancelCmd.MarkFlagRequired("seq")
+	cancelCmd.MarkFlagRequired("fee")
+}
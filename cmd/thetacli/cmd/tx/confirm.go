@@ -0,0 +1,138 @@
+package tx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+)
+
+// Before a transaction is signed, its decoded summary is displayed and the user is asked to confirm
+// it, so that a typo in the flags doesn't irreversibly send the funds. The confirmation is skipped
+// with the --yes flag, which is required in the JSON output mode.
+
+// confirmed is set once the user confirmed the transactions to be signed by the command, e.g.
+// the whole series of the batch send command
+var confirmed bool
+
+// confirmWallet asks the user to confirm the transaction before it is signed by the wallet
+type confirmWallet struct {
+	wtypes.Wallet
+}
+
+func (w *confirmWallet) Sign(address common.Address, signBytes common.Bytes) (*crypto.Signature, error) {
+	if !confirmed {
+		chainID, tx, err := types.TxFromSignBytes(signBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode transaction: %v", err)
+		}
+		confirmTx(describeTx(chainID, address, tx))
+	}
+	return w.Wallet.Sign(address, signBytes)
+}
+
+// confirmTx displays the summary of the transactions and exits unless the user confirms them
+func confirmTx(summary []string) {
+	if yesFlag {
+		return
+	}
+	if utils.IsJSONOutput() {
+		utils.Error("The transaction needs to be confirmed with --yes in the JSON output mode\n")
+	}
+
+	fmt.Println(strings.Join(summary, "\n"))
+	fmt.Println("Please enter 'yes' to sign the transaction, or anything else to abort: ")
+	confirmation, err := utils.GetConfirmation()
+	if err != nil {
+		utils.Error("Failed to get confirmation: %v\n", err)
+	}
+	if strings.ToLower(confirmation) != "yes" {
+		utils.Error("Aborted\n")
+	}
+	confirmed = true
+}
+
+// describeTx returns the human-readable summary of the transaction to be signed by the signer
+func describeTx(chainID string, signer common.Address, tx types.Tx) []string {
+	summary := []string{
+		fmt.Sprintf("Type:     %v", strings.TrimPrefix(fmt.Sprintf("%T", tx), "*types.")),
+		fmt.Sprintf("Chain ID: %v", chainID),
+		fmt.Sprintf("Signer:   %v", signer.Hex()),
+	}
+	input := func(in types.TxInput) {
+		summary = append(summary,
+			fmt.Sprintf("From:     %v", in.Address.Hex()),
+			fmt.Sprintf("Sequence: %v", in.Sequence))
+	}
+	output := func(label string, out types.TxOutput) {
+		summary = append(summary, fmt.Sprintf("%-9v %v, %v", label+":", out.Address.Hex(), utils.FormatCoins(out.Coins)))
+	}
+	fee := func(fee types.Coins) {
+		summary = append(summary, fmt.Sprintf("Fee:      %v", utils.FormatCoins(fee)))
+	}
+
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		for _, in := range tx.Inputs {
+			input(in)
+		}
+		for _, out := range tx.Outputs {
+			output("To", out)
+		}
+		fee(tx.Fee)
+		for _, payer := range tx.FeePayer {
+			summary = append(summary, fmt.Sprintf("Fee payer: %v", payer.Address.Hex()))
+		}
+	case *types.BatchSendTx:
+		input(tx.Input)
+		for _, out := range tx.Outputs {
+			output("To", out)
+		}
+		fee(tx.Fee)
+	case *types.MultiSigSendTx:
+		input(tx.Input)
+		for _, out := range tx.Outputs {
+			output("To", out)
+		}
+		fee(tx.Fee)
+	case *types.SmartContractTx:
+		input(tx.From)
+		summary = append(summary,
+			fmt.Sprintf("Contract: %v", tx.To.Address.Hex()),
+			fmt.Sprintf("Value:    %v", utils.FormatCoins(tx.From.Coins)),
+			fmt.Sprintf("Gas:      %v at %v", tx.GasLimit, utils.FormatCoinAmount(tx.GasPrice, types.DenomTFuel)))
+	case *types.DepositStakeTxV2:
+		input(tx.Source)
+		summary = append(summary,
+			fmt.Sprintf("Holder:   %v, purpose %v", tx.Holder.Address.Hex(), tx.Purpose),
+			fmt.Sprintf("Stake:    %v", utils.FormatCoins(tx.Source.Coins)))
+		fee(tx.Fee)
+	case *types.WithdrawStakeTx:
+		input(tx.Source)
+		summary = append(summary, fmt.Sprintf("Holder:   %v, purpose %v", tx.Holder.Address.Hex(), tx.Purpose))
+		fee(tx.Fee)
+	case *types.VestingTransferTx:
+		input(tx.Source)
+		summary = append(summary,
+			fmt.Sprintf("To:       %v, %v", tx.Beneficiary.Hex(), utils.FormatCoins(types.Coins{
+				ThetaWei: tx.Source.Coins.ThetaWei,
+				TFuelWei: new(big.Int).Sub(tx.Source.Coins.TFuelWei, tx.Fee.TFuelWei),
+			})),
+			fmt.Sprintf("Unlock:   height %v, time %v", tx.UnlockHeight, tx.UnlockTime))
+		fee(tx.Fee)
+	default:
+		// The other transactions move no funds other than the fee, so their fields are listed as is
+		content, err := json.MarshalIndent(tx, "", "    ")
+		if err != nil {
+			utils.Error("Failed to encode transaction: %v\n", err)
+		}
+		summary = append(summary, string(content))
+	}
+	return summary
+}
@@ -0,0 +1,451 @@
+package tx
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm/abi"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// The contract commands encode the constructor and method calls of the EVM smart contracts with
+// their ABI, given as the JSON generated by the Solidity compiler, or as a Truffle or Hardhat
+// artifact which also contains the bytecode. The arguments are given as strings, see
+// abi.ParseValue, and the return values and the events emitted by the transaction are decoded.
+
+// ContractCmd groups the commands to deploy, call and query smart contracts. It shares the
+// signing workflow of the tx sub commands.
+var ContractCmd = &cobra.Command{
+	Use:   "contract",
+	Short: "Deploy, call and query smart contracts with their ABI",
+	Long: `Deploy, call and query smart contracts with their ABI. The arguments are encoded according to
+the ABI, with the integers in decimal or 0x prefixed hex, the bytes in hex and the arrays and tuples
+as JSON arrays. The addresses can also be given as names in the address book.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		resolveAddressFlags(cmd)
+		utils.ApplyChainID(cmd)
+	},
+}
+
+// contractDeployCmd deploys a smart contract with the constructor arguments
+// Example:
+//		thetacli contract deploy 1000000 --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --abi=Token.json --bytecode=Token.bin
+var contractDeployCmd = &cobra.Command{
+	Use:     "deploy [constructor args...]",
+	Short:   "Deploy a smart contract",
+	Example: `thetacli contract deploy 1000000 --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --abi=Token.json --bytecode=Token.bin`,
+	Run:     doContractDeployCmd,
+}
+
+// contractCallCmd calls a method of a smart contract with a transaction, which modifies the state
+// Example:
+//		thetacli contract call transfer 9F1233798E905E173560071255140b4A8aBd3Ec6 100 --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --contract=0x7ad6cea2bc3162e30a3c98d84f821b3233c22647 --abi=Token.json
+var contractCallCmd = &cobra.Command{
+	Use:   "call <method> [args...]",
+	Short: "Call a method of a smart contract with a transaction",
+	Long: `Call a method of a smart contract with a transaction. Once the transaction is included in a block,
+the return values and the events emitted by the call are decoded. An overloaded method is
+selected by its signature, e.g. "safeTransferFrom(address,address,uint256)".`,
+	Example: `thetacli contract call transfer 9F1233798E905E173560071255140b4A8aBd3Ec6 100 --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --contract=0x7ad6cea2bc3162e30a3c98d84f821b3233c22647 --abi=Token.json`,
+	Args:    cobra.MinimumNArgs(1),
+	Run:     doContractCallCmd,
+}
+
+// contractQueryCmd calls a method of a smart contract without a transaction, e.g. to read its state
+// Example:
+//		thetacli contract query balanceOf 9F1233798E905E173560071255140b4A8aBd3Ec6 --contract=0x7ad6cea2bc3162e30a3c98d84f821b3233c22647 --abi=Token.json
+var contractQueryCmd = &cobra.Command{
+	Use:   "query <method> [args...]",
+	Short: "Call a method of a smart contract without a transaction",
+	Long: `Call a method of a smart contract without a transaction, and decode its return values. The call
+does NOT modify the state, so it's mostly used with the view methods, or as a dry run of the others.`,
+	Example: `thetacli contract query balanceOf 9F1233798E905E173560071255140b4A8aBd3Ec6 --contract=0x7ad6cea2bc3162e30a3c98d84f821b3233c22647 --abi=Token.json`,
+	Args:    cobra.MinimumNArgs(1),
+	Run:     doContractQueryCmd,
+}
+
+// contractTxResult is the result of the deploy and call commands
+type contractTxResult struct {
+	Hash            string                 `json:"hash"`
+	Block           *core.BlockHeader      `json:"block,omitempty"`
+	ContractAddress *common.Address        `json:"contract_address,omitempty"`
+	GasUsed         *common.JSONUint64     `json:"gas_used,omitempty"`
+	Outputs         map[string]interface{} `json:"outputs,omitempty"`
+	Events          []contractEvent        `json:"events,omitempty"`
+}
+
+// contractEvent is an event emitted by a transaction. The logs that don't match any event of the
+// ABI are given with their raw topics and data.
+type contractEvent struct {
+	Address common.Address         `json:"address"`
+	Event   string                 `json:"event,omitempty"`
+	Args    map[string]interface{} `json:"args,omitempty"`
+	Topics  []common.Hash          `json:"topics,omitempty"`
+	Data    string                 `json:"data,omitempty"`
+}
+
+// contractQueryResult is the result of the query command
+type contractQueryResult struct {
+	Outputs map[string]interface{} `json:"outputs"`
+	GasUsed common.JSONUint64      `json:"gas_used"`
+}
+
+func doContractDeployCmd(cmd *cobra.Command, args []string) {
+	contractABI, bytecode := loadContractArtifact(abiFlag != "")
+	if bytecodeFlag != "" {
+		bytecode = readBytecode(bytecodeFlag)
+	}
+	if len(bytecode) == 0 {
+		utils.Error("The bytecode of the contract is required, specify it with --bytecode\n")
+	}
+
+	values, err := contractABI.Constructor.Inputs.ParseValues(args)
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+	encoded, err := contractABI.Constructor.Inputs.Pack(values...)
+	if err != nil {
+		utils.Error("Failed to encode the constructor arguments: %v\n", err)
+	}
+
+	sendContractTx(cmd, contractABI, nil, common.Address{}, append(bytecode, encoded...))
+}
+
+func doContractCallCmd(cmd *cobra.Command, args []string) {
+	contractABI, _ := loadContractArtifact(true)
+	method, data := packContractCall(contractABI, args)
+	if method.IsReadOnly() {
+		fmt.Fprintf(os.Stderr, "Method %v doesn't modify the state, it can be called without a transaction with \"contract query\"\n", method.Sig())
+	}
+
+	sendContractTx(cmd, contractABI, method, common.HexToAddress(contractFlag), data)
+}
+
+func doContractQueryCmd(cmd *cobra.Command, args []string) {
+	contractABI, _ := loadContractArtifact(true)
+	method, data := packContractCall(contractABI, args)
+
+	gasLimit := types.MaximumTxGasLimit
+	if cmd.Flags().Changed("gas_limit") {
+		gasLimit = gasLimitFlag
+	}
+	result := callSmartContract(common.HexToAddress(contractFlag), data, gasLimit)
+	ret, err := hex.DecodeString(result.VmReturn)
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	if result.VmError != "" {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to execute smart contract: %v\n", describeVMError(result.VmError, ret))
+	}
+
+	outputs, err := method.Outputs.Unpack(ret)
+	if err != nil {
+		utils.Error("Failed to decode the return values: %v\n", err)
+	}
+	queryResult := &contractQueryResult{
+		Outputs: abi.FormatValues(method.Outputs, outputs),
+		GasUsed: result.GasUsed,
+	}
+	formatted, err := json.MarshalIndent(queryResult, "", "    ")
+	if err != nil {
+		utils.Error("Failed to encode the result: %v\n", err)
+	}
+	utils.PrintResult(queryResult, "%s\n", formatted)
+}
+
+// loadContractArtifact reads the ABI given by the --abi flag, and the bytecode if the file is a
+// Truffle or Hardhat artifact. Without the flag, an empty ABI is returned unless it's required.
+func loadContractArtifact(required bool) (*abi.ABI, []byte) {
+	if abiFlag == "" {
+		if required {
+			utils.Error("The ABI of the contract is required, specify it with --abi\n")
+		}
+		return &abi.ABI{Constructor: &abi.Method{}}, nil
+	}
+	content, err := ioutil.ReadFile(abiFlag)
+	if err != nil {
+		utils.Error("Failed to read %v: %v\n", abiFlag, err)
+	}
+
+	var bytecode []byte
+	artifact := struct {
+		ABI      json.RawMessage `json:"abi"`
+		Bytecode string          `json:"bytecode"`
+	}{}
+	if err := json.Unmarshal(content, &artifact); err == nil && len(artifact.ABI) != 0 {
+		content = artifact.ABI
+		if artifact.Bytecode != "" {
+			bytecode = readBytecode(artifact.Bytecode)
+		}
+	}
+
+	contractABI, err := abi.JSON(content)
+	if err != nil {
+		utils.Error("Failed to parse the ABI in %v: %v\n", abiFlag, err)
+	}
+	return contractABI, bytecode
+}
+
+// readBytecode decodes the hex encoded bytecode, or reads it from the file with the path
+func readBytecode(bytecodeOrPath string) []byte {
+	encoded := bytecodeOrPath
+	if content, err := ioutil.ReadFile(bytecodeOrPath); err == nil {
+		encoded = string(content)
+	}
+	encoded = strings.TrimPrefix(strings.TrimSpace(encoded), "0x")
+	bytecode, err := hex.DecodeString(encoded)
+	if err != nil {
+		utils.Error("Failed to decode the bytecode: %v\n", err)
+	}
+	return bytecode
+}
+
+// packContractCall encodes the call of the method with the arguments, given as the method name or
+// signature followed by its arguments
+func packContractCall(contractABI *abi.ABI, args []string) (*abi.Method, []byte) {
+	if contractFlag == "" {
+		utils.Error("The contract address is required, specify it with --contract\n")
+	}
+	method, err := contractABI.Method(args[0])
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+	values, err := method.Inputs.ParseValues(args[1:])
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+	data, err := method.Pack(values...)
+	if err != nil {
+		utils.Error("Failed to encode the arguments: %v\n", err)
+	}
+	return method, data
+}
+
+// sendContractTx signs and broadcasts the transaction deploying a contract, or calling the method
+// of the contract at the address. Unless broadcasted asynchronously, the receipt of the
+// transaction is then decoded with the ABI.
+func sendContractTx(cmd *cobra.Command, contractABI *abi.ABI, method *abi.Method, to common.Address, data []byte) {
+	// The ABI encoding only applies to the EVM contracts
+	runtimeFlag = "evm"
+	if !cmd.Flags().Changed("gas_limit") {
+		gasLimitFlag = estimateContractGas(to, data)
+	}
+
+	signedTx, sponsored := signSmartContractTx(cmd, to, data)
+	if sponsored {
+		printEncodedTx(signedTx, "Transaction signed by the caller, to be co-signed by the fee payer with the \"tx sponsor\" command:\n%s\n", signedTx)
+		recordPendingSequence()
+		return
+	}
+
+	broadcasted := broadcastRawTx(signedTx)
+	result := &contractTxResult{
+		Hash:  broadcasted.TxHash,
+		Block: broadcasted.Block,
+	}
+	if !asyncFlag {
+		receipt := queryContractReceipt(broadcasted.TxHash)
+		if receipt.EvmErr != "" {
+			utils.ErrorWithCode(utils.ExitCodeServerError, "Transaction %v failed: %v\n", broadcasted.TxHash, describeVMError(receipt.EvmErr, receipt.EvmRet))
+		}
+		gasUsed := common.JSONUint64(receipt.GasUsed)
+		result.GasUsed = &gasUsed
+		if method == nil {
+			result.ContractAddress = &receipt.ContractAddress
+		} else {
+			outputs, err := method.Outputs.Unpack(receipt.EvmRet)
+			if err != nil {
+				utils.Error("Failed to decode the return values: %v\n", err)
+			}
+			result.Outputs = abi.FormatValues(method.Outputs, outputs)
+		}
+		result.Events = decodeContractEvents(contractABI, receipt.Logs)
+	}
+
+	formatted, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to encode the result: %v\n", err)
+	}
+	utils.PrintResult(result, "Successfully broadcasted transaction:\n%s\n", formatted)
+}
+
+// estimateContractGas returns the gas limit of the transaction, with a margin over the gas used by
+// a dry run, as the state may change before the transaction is included in a block
+func estimateContractGas(to common.Address, data []byte) uint64 {
+	result := callSmartContract(to, data, types.MaximumTxGasLimit)
+	if result.VmError != "" {
+		ret, _ := hex.DecodeString(result.VmReturn)
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Dry run of the transaction failed: %v\n", describeVMError(result.VmError, ret))
+	}
+	gasLimit := uint64(result.GasUsed) * 6 / 5
+	if gasLimit > types.MaximumTxGasLimit {
+		gasLimit = types.MaximumTxGasLimit
+	}
+	fmt.Fprintf(os.Stderr, "Using the estimated gas limit %v\n", gasLimit)
+	return gasLimit
+}
+
+// callSmartContract executes the call of the contract at the address, or the deployment of a
+// contract, by the --from address without a transaction
+func callSmartContract(to common.Address, data []byte, gasLimit uint64) *rpc.CallSmartContractResult {
+	value, ok := types.ParseCoinAmountOf(valueFlag, types.DenomTFuel)
+	if !ok {
+		utils.Error("Failed to parse value")
+	}
+	gasPrice, ok := types.ParseCoinAmountOf(gasPriceFlag, types.DenomTFuel)
+	if !ok {
+		utils.Error("Failed to parse gas price")
+	}
+
+	sctx := &types.SmartContractTx{
+		From: types.TxInput{
+			Address: common.HexToAddress(fromFlag),
+			Coins: types.Coins{
+				ThetaWei: new(big.Int).SetUint64(0),
+				TFuelWei: value,
+			},
+		},
+		To:       types.TxOutput{Address: to},
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+		Data:     data,
+	}
+	sctxBytes, err := types.TxToBytes(sctx)
+	if err != nil {
+		utils.Error("Failed to encode smart contract transaction: %v\n", err)
+	}
+
+	client := utils.NewRPCClient()
+	res, err := client.Call("theta.CallSmartContract", rpc.CallSmartContractArgs{SctxBytes: hex.EncodeToString(sctxBytes)})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to call smart contract, the gas limit can be specified with --gas_limit: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to execute smart contract: %v\n", res.Error)
+	}
+	result := &rpc.CallSmartContractResult{}
+	if err := res.GetObject(result); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	return result
+}
+
+// queryContractReceipt waits for the receipt of the transaction, which is available once the
+// transaction is included in a block
+func queryContractReceipt(hash string) *blockchain.TxReceiptEntry {
+	client := utils.NewRPCClient()
+	deadline := time.Now().Add(timeoutFlag)
+	for {
+		res, err := client.Call("theta.GetTransaction", rpc.GetTransactionArgs{Hash: hash})
+		if err != nil {
+			utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get transaction receipt: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get transaction receipt: %v\n", res.Error)
+		}
+		result := struct {
+			Receipt *blockchain.TxReceiptEntry `json:"receipt"`
+		}{}
+		if err := res.GetObject(&result); err != nil {
+			utils.Error("Failed to parse server response: %v\n", err)
+		}
+		if result.Receipt != nil {
+			return result.Receipt
+		}
+
+		if time.Now().After(deadline) {
+			utils.ErrorWithCode(utils.ExitCodeTimeout, "Timed out waiting for the receipt of transaction %v, check with \"tx wait\"\n", hash)
+		}
+		time.Sleep(waitPollInterval)
+	}
+}
+
+// decodeContractEvents decodes the logs emitted by a transaction into the events of the ABI
+func decodeContractEvents(contractABI *abi.ABI, logs []*types.Log) []contractEvent {
+	events := []contractEvent{}
+	for _, log := range logs {
+		event := contractEvent{Address: log.Address}
+		if len(log.Topics) != 0 {
+			if e, ok := contractABI.EventByID(log.Topics[0]); ok {
+				if values, err := e.UnpackLog(log.Topics, log.Data); err == nil {
+					event.Event = e.Sig()
+					event.Args = abi.FormatValues(e.Inputs, values)
+					events = append(events, event)
+					continue
+				}
+			}
+		}
+		event.Topics = log.Topics
+		event.Data = "0x" + hex.EncodeToString(log.Data)
+		events = append(events, event)
+	}
+	return events
+}
+
+// describeVMError appends the revert reason in the return data to the VM error, if any
+func describeVMError(vmErr string, ret []byte) string {
+	if reason, ok := abi.UnpackRevertReason(ret); ok {
+		return fmt.Sprintf("%v: %v", vmErr, reason)
+	}
+	return vmErr
+}
+
+// addContractTxFlags adds the flags of the commands sending a contract transaction
+func addContractTxFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	cmd.Flags().StringVar(&fromFlag, "from", "", "The caller address")
+	cmd.Flags().StringVar(&abiFlag, "abi", "", "File of the contract ABI, or of the Truffle or Hardhat artifact")
+	cmd.Flags().StringVar(&valueFlag, "value", "0", "Value to be transferred")
+	cmd.Flags().StringVar(&gasPriceFlag, "gas_price", fmt.Sprintf("%dwei", types.MinimumGasPriceJune2021), "The gas price")
+	cmd.Flags().Uint64Var(&gasLimitFlag, "gas_limit", 0, "The gas limit, estimated with a dry run by default")
+	cmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	cmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	cmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	cmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	cmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	cmd.Flags().StringVar(&feePayerFlag, "fee_payer", "", "Address of the fee payer, which pays the gas fee on behalf of the caller")
+	cmd.Flags().Uint64Var(&feePayerSeqFlag, "fee_payer_seq", 0, "Sequence number of the fee payer")
+	cmd.Flags().Uint64Var(&notAfterHeightFlag, "not_after_height", 0, "Block height after which the transaction expires, 0 for no expiry")
+	cmd.Flags().DurationVar(&timeoutFlag, "timeout", 60*time.Second, "Maximum time to wait for the receipt of the transaction")
+
+	cmd.MarkFlagRequired("chain")
+	cmd.MarkFlagRequired("from")
+}
+
+func init() {
+	addContractTxFlags(contractDeployCmd)
+	contractDeployCmd.Flags().StringVar(&bytecodeFlag, "bytecode", "", "The contract bytecode in hex, or the file containing it, read from the artifact by default")
+
+	addContractTxFlags(contractCallCmd)
+	contractCallCmd.Flags().StringVar(&contractFlag, "contract", "", "The smart contract address")
+	contractCallCmd.MarkFlagRequired("abi")
+	contractCallCmd.MarkFlagRequired("contract")
+
+	contractQueryCmd.Flags().StringVar(&fromFlag, "from", "", "The caller address")
+	contractQueryCmd.Flags().StringVar(&contractFlag, "contract", "", "The smart contract address")
+	contractQueryCmd.Flags().StringVar(&abiFlag, "abi", "", "File of the contract ABI, or of the Truffle or Hardhat artifact")
+	contractQueryCmd.Flags().StringVar(&valueFlag, "value", "0", "Value to be transferred")
+	contractQueryCmd.Flags().StringVar(&gasPriceFlag, "gas_price", fmt.Sprintf("%dwei", types.MinimumGasPriceJune2021), "The gas price")
+	contractQueryCmd.Flags().Uint64Var(&gasLimitFlag, "gas_limit", 0, fmt.Sprintf("The gas limit, %v by default", types.MaximumTxGasLimit))
+	contractQueryCmd.MarkFlagRequired("abi")
+	contractQueryCmd.MarkFlagRequired("contract")
+
+	ContractCmd.AddCommand(contractDeployCmd)
+	ContractCmd.AddCommand(contractCallCmd)
+	ContractCmd.AddCommand(contractQueryCmd)
+
+	ContractCmd.PersistentFlags().BoolVar(&yesFlag, "yes", false, "Sign the transaction without asking for confirmation")
+	ContractCmd.PersistentFlags().BoolVar(&ledgerFlag, "ledger", false, "Sign with the Ledger device, same as --wallet=nano, the key is selected by --path")
+}
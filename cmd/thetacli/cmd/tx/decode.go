@@ -0,0 +1,294 @@
+package tx
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// decodeCmd decodes a raw transaction, and verifies its signatures.
+// Example:
+//		thetacli tx decode 0xf88c80f887c78085e8d4a51000f842f840...
+//		thetacli tx decode signed.json
+var decodeCmd = &cobra.Command{
+	Use:   "decode <hex|file>",
+	Short: "Decode a raw transaction and verify its signatures",
+	Long: `Decode a raw transaction, given as hex or as a file holding the hex or a transaction written by the sign
+command, and verify its signatures. The signatures are verified against the chain ID given by --chain, recorded
+in the file, or reported by the node, in this order. The signer recovered from each signature is shown, so that a
+signature by the wrong key or for the wrong chain can be told apart from a corrupted one.`,
+	Example: `thetacli tx decode signed.json --chain=privatenet`,
+	Args:    cobra.ExactArgs(1),
+	Run:     doDecodeCmd,
+}
+
+// decodedTx is the result of the decode command
+type decodedTx struct {
+	Type       string             `json:"type"`
+	Hash       common.Hash        `json:"hash"`
+	Size       int                `json:"size"`
+	ChainID    string             `json:"chain_id,omitempty"`
+	Tx         types.Tx           `json:"tx"`
+	Signatures []decodedSignature `json:"signatures"`
+}
+
+// decodedSignature is a signature of the transaction with the signer it is expected from
+type decodedSignature struct {
+	Signer    string          `json:"signer"` // the role of the signer, e.g. source or inputs[1]
+	Address   common.Address  `json:"address"`
+	Present   bool            `json:"present"`
+	Recovered *common.Address `json:"recovered,omitempty"`
+	Valid     bool            `json:"valid"`
+	Note      string          `json:"note,omitempty"`
+}
+
+// txSigner is a signature the transaction carries, or should carry
+type txSigner struct {
+	role      string
+	address   common.Address
+	sig       *crypto.Signature
+	signBytes func(chainID string) []byte
+}
+
+func doDecodeCmd(cmd *cobra.Command, args []string) {
+	raw, chainID := readRawTx(args[0])
+	tx, err := types.TxFromBytes(raw)
+	if err != nil {
+		utils.Error("Failed to decode transaction: %v\n", err)
+	}
+	if chainIDFlag != "" {
+		chainID = chainIDFlag
+	}
+	if chainID == "" {
+		chainID = queryChainID()
+	}
+
+	result := &decodedTx{
+		Type:       strings.TrimPrefix(fmt.Sprintf("%T", tx), "*types."),
+		Hash:       crypto.Keccak256Hash(raw),
+		Size:       len(raw),
+		ChainID:    chainID,
+		Tx:         tx,
+		Signatures: []decodedSignature{},
+	}
+	_, isEthCompatible := tx.(*types.SmartContractTx)
+	for _, signer := range getTxSigners(tx) {
+		sig := verifyTxSignature(signer, chainID)
+		if isEthCompatible && signer.role == "from" && sig.Present && !sig.Valid {
+			sig.Note = "not a native signature, possibly an Ethereum transaction signature"
+		}
+		result.Signatures = append(result.Signatures, sig)
+	}
+
+	if utils.IsJSONOutput() {
+		utils.PrintJSON(result)
+		return
+	}
+	txJSON, err := json.MarshalIndent(tx, "", "    ")
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	fmt.Printf("Type:     %v\n", result.Type)
+	fmt.Printf("Hash:     %v\n", result.Hash.Hex())
+	fmt.Printf("Size:     %v bytes\n", result.Size)
+	if chainID != "" {
+		fmt.Printf("Chain ID: %v\n", chainID)
+	}
+	fmt.Printf("%v\n", string(txJSON))
+	for _, sig := range result.Signatures {
+		status := "valid"
+		switch {
+		case !sig.Present:
+			status = "missing"
+		case !sig.Valid && sig.Recovered != nil:
+			status = fmt.Sprintf("INVALID, signed by %v", sig.Recovered.Hex())
+		case !sig.Valid:
+			status = "INVALID"
+		}
+		if sig.Note != "" {
+			status += " (" + sig.Note + ")"
+		}
+		fmt.Printf("Signature of %v %v: %v\n", sig.Signer, sig.Address.Hex(), status)
+	}
+}
+
+// readRawTx reads the raw transaction from the argument, which is either the hex itself or a file
+// holding the hex or an offline transaction. The chain ID is returned for an offline transaction.
+func readRawTx(arg string) (common.Bytes, string) {
+	content := arg
+	chainID := ""
+	if data, err := ioutil.ReadFile(arg); err == nil {
+		content = strings.TrimSpace(string(data))
+		otx := &offlineTx{}
+		if strings.HasPrefix(content, "{") {
+			if err := json.Unmarshal(data, otx); err != nil {
+				utils.Error("Failed to parse %v: %v\n", arg, err)
+			}
+			content, chainID = otx.Raw, otx.ChainID
+		}
+	} else if !os.IsNotExist(err) {
+		utils.Error("Failed to read %v: %v\n", arg, err)
+	}
+
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(content, "0x"), "0X"))
+	if err != nil {
+		utils.Error("Failed to decode transaction hex: %v\n", err)
+	}
+	return raw, chainID
+}
+
+// queryChainID returns the chain ID of the node, or an empty string if the node is unreachable
+func queryChainID() string {
+	client := utils.NewRPCClient()
+	res, err := client.Call("theta.GetStatus", rpc.GetStatusArgs{})
+	if err != nil || res.Error != nil {
+		fmt.Fprintf(os.Stderr, "Failed to query the chain ID of the node, specify it with --chain to verify the signatures\n")
+		return ""
+	}
+	status := &rpc.GetStatusResult{}
+	if err := res.GetObject(status); err != nil {
+		return ""
+	}
+	return status.ChainID
+}
+
+// getTxSigners returns the signers of the transaction, along with the bytes each of them signs
+func getTxSigners(tx types.Tx) []txSigner {
+	signers := []txSigner{}
+	input := func(role string, in types.TxInput) {
+		signers = append(signers, txSigner{role, in.Address, in.Signature, tx.SignBytes})
+	}
+
+	switch tx := tx.(type) {
+	case *types.CoinbaseTx:
+		input("proposer", tx.Proposer)
+	case *types.SlashTx:
+		input("proposer", tx.Proposer)
+	case *types.SendTx:
+		for i, in := range tx.Inputs {
+			input(fmt.Sprintf("inputs[%v]", i), in)
+		}
+		for _, in := range tx.FeePayer {
+			input("fee_payer", in)
+		}
+	case *types.ReserveFundTx:
+		input("source", tx.Source)
+	case *types.ReserveFundTxV2:
+		input("source", tx.Source)
+	case *types.ReleaseFundTx:
+		input("source", tx.Source)
+	case *types.ServicePaymentTx:
+		signers = append(signers,
+			txSigner{"source", tx.Source.Address, tx.Source.Signature, tx.SourceSignBytes},
+			txSigner{"target", tx.Target.Address, tx.Target.Signature, tx.TargetSignBytes})
+	case *types.SplitRuleTx:
+		input("initiator", tx.Initiator)
+	case *types.SplitRuleRenewalTx:
+		input("initiator", tx.Initiator)
+	case *types.SmartContractTx:
+		input("from", tx.From)
+		for _, in := range tx.FeePayer {
+			input("fee_payer", in)
+		}
+	case *types.SmartContractTxV2:
+		input("from", tx.From)
+		for _, in := range tx.FeePayer {
+			input("fee_payer", in)
+		}
+	case *types.DepositStakeTx:
+		input("source", tx.Source)
+	case *types.DepositStakeTxV2:
+		input("source", tx.Source)
+	case *types.WithdrawStakeTx:
+		input("source", tx.Source)
+	case *types.StakeRewardDistributionTx:
+		input("holder", tx.Holder)
+	case *types.MultiSigSendTx:
+		for i, signer := range tx.MultiSig.Signers {
+			var sig *crypto.Signature
+			if i < len(tx.Signatures) {
+				sig = tx.Signatures[i]
+			}
+			signers = append(signers, txSigner{fmt.Sprintf("signers[%v]", i), signer, sig, tx.SignBytes})
+		}
+	case *types.VestingTransferTx:
+		input("source", tx.Source)
+	case *types.VestingClaimTx:
+		input("beneficiary", tx.Beneficiary)
+	case *types.BatchSendTx:
+		input("input", tx.Input)
+	case *types.TokenRegistryTx:
+		input("registrar", tx.Registrar)
+	case *types.ValidatorKeyRotationTx:
+		input("validator", tx.Validator)
+		signers = append(signers, txSigner{"signing_key", tx.SigningAddress, tx.SigningKeySignature, tx.SignBytes})
+	case *types.IBCTx:
+		input("relayer", tx.Relayer)
+	case *types.BandwidthProofTx:
+		input("edge_node", tx.EdgeNode)
+		signers = append(signers, txSigner{"pool", tx.Pool.Address, tx.Pool.Signature, tx.PoolSignBytes})
+	case *types.GovProposalTx:
+		input("proposer", tx.Proposer)
+	case *types.GovVoteTx:
+		input("voter", tx.Voter)
+	}
+	return signers
+}
+
+// verifyTxSignature verifies the signature against the sign bytes of the chain, also with the v2
+// Ethereum tx wrapper accepted by the ledger since HeightTxWrapperExtension, and with the v3 wrapper
+// required since HeightEnableChainIDReplayProtection, over the numeric chain ID reported by the node
+func verifyTxSignature(signer txSigner, chainID string) decodedSignature {
+	result := decodedSignature{
+		Signer:  signer.role,
+		Address: signer.address,
+		Present: signer.sig != nil && !signer.sig.IsEmpty(),
+	}
+	if !result.Present {
+		return result
+	}
+	if chainID == "" {
+		result.Note = "not verified without the chain ID"
+		return result
+	}
+
+	signBytes := signer.signBytes(chainID)
+	if recovered, err := signer.sig.RecoverSignerAddress(signBytes); err == nil {
+		result.Recovered = &recovered
+		result.Valid = recovered == signer.address
+	}
+	if !result.Valid {
+		signBytesV2 := types.ChangeEthereumTxWrapper(signBytes, 2)
+		if signer.sig.Verify(signBytesV2, signer.address) {
+			result.Recovered = &signer.address
+			result.Valid = true
+			result.Note = "v2 tx wrapper"
+		}
+	}
+	if !result.Valid {
+		signBytesV3 := types.ReplayProtectedSignBytes(signBytes, utils.NumericChainID(chainID))
+		if signer.sig.Verify(signBytesV3, signer.address) {
+			result.Recovered = &signer.address
+			result.Valid = true
+			result.Note = "v3 tx wrapper, replay protected"
+		}
+	}
+	if !result.Valid && result.Recovered != nil {
+		result.Note = "signed by another key, or for another chain"
+	}
+	return result
+}
+
+func init() {
+	decodeCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID to verify the signatures against")
+}
@@ -2,7 +2,6 @@ package tx
 
 import (
 	"encoding/hex"
-	"fmt"
 	"math/big"
 	"strings"
 
@@ -11,14 +10,10 @@ import (
 	"github.com/thetatoken/theta/crypto/bls"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/ledger/types"
-	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // depositStakeCmd represents the deposit stake command
@@ -38,27 +33,17 @@ func doDepositStakeCmd(cmd *cobra.Command, args []string) {
 	}
 	defer wallet.Lock(sourceAddress)
 
-	fee, ok := types.ParseCoinAmount(feeFlag)
-	if !ok {
-		utils.Error("Failed to parse fee")
-	}
-	stake, ok := types.ParseCoinAmount(stakeInThetaFlag)
-	if !ok {
-		utils.Error("Failed to parse stake")
-	}
-	if stake.Cmp(core.Zero) < 0 {
-		utils.Error("Invalid input: stake must be positive\n")
-	}
+	fee := getFee(types.TxDepositStakeV2, 0)
 
 	var thetaStake *big.Int
 	var tfuelStake *big.Int
 
 	if purposeFlag == core.StakeForValidator || purposeFlag == core.StakeForGuardian {
-		thetaStake = stake
+		thetaStake = parseAmount("stake", stakeInThetaFlag, types.DenomTheta)
 		tfuelStake = new(big.Int).SetUint64(0)
 	} else { // purposeFlag == core.StakeForEliteEdgeNode
 		thetaStake = new(big.Int).SetUint64(0)
-		tfuelStake = stake
+		tfuelStake = parseAmount("stake", stakeInThetaFlag, types.DenomTFuel)
 	}
 
 	source := types.TxInput{
@@ -67,7 +52,7 @@ func doDepositStakeCmd(cmd *cobra.Command, args []string) {
 			ThetaWei: thetaStake,
 			TFuelWei: tfuelStake,
 		},
-		Sequence: uint64(seqFlag),
+		Sequence: getSequence(cmd, sourceAddress),
 	}
 
 	depositStakeTx := &types.DepositStakeTxV2{
@@ -155,7 +140,7 @@ func doDepositStakeCmd(cmd *cobra.Command, args []string) {
 		Address: holderAddress,
 	}
 
-	sig, err := wallet.Sign(sourceAddress, depositStakeTx.SignBytes(chainIDFlag))
+	sig, err := wallet.Sign(sourceAddress, utils.TxSignBytes(depositStakeTx, chainIDFlag))
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
@@ -167,39 +152,29 @@ func doDepositStakeCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	broadcastSignedTx(signedTx)
+}
 
-	var res *rpcc.RPCResponse
-	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	}
-	if err != nil {
-		utils.Error("Failed to broadcast transaction: %v\n", err)
-	}
-	if res.Error != nil {
-		utils.Error("Server returned error: %v\n", res.Error)
-	}
-	fmt.Printf("Successfully broadcasted transaction.\n")
+// addDepositStakeFlags registers the flags of the deposit stake commands
+func addDepositStakeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	cmd.Flags().StringVar(&sourceFlag, "source", "", "Source of the stake")
+	cmd.Flags().StringVar(&holderFlag, "holder", "", "Holder of the stake")
+	cmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	cmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	cmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	cmd.Flags().StringVar(&stakeInThetaFlag, "stake", "5000000", "Theta amount to stake")
+	cmd.Flags().Uint8Var(&purposeFlag, "purpose", 0, "Purpose of staking")
+	cmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	cmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	cmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	cmd.MarkFlagRequired("chain")
+	cmd.MarkFlagRequired("source")
+	cmd.MarkFlagRequired("holder")
+	cmd.MarkFlagRequired("stake")
 }
 
 func init() {
-	depositStakeCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
-	depositStakeCmd.Flags().StringVar(&sourceFlag, "source", "", "Source of the stake")
-	depositStakeCmd.Flags().StringVar(&holderFlag, "holder", "", "Holder of the stake")
-	depositStakeCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
-	depositStakeCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWeiJune2021), "Fee")
-	depositStakeCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
-	depositStakeCmd.Flags().StringVar(&stakeInThetaFlag, "stake", "5000000", "Theta amount to stake")
-	depositStakeCmd.Flags().Uint8Var(&purposeFlag, "purpose", 0, "Purpose of staking")
-	depositStakeCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
-	depositStakeCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
-	depositStakeCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
-
-	depositStakeCmd.MarkFlagRequired("chain")
-	depositStakeCmd.MarkFlagRequired("source")
-	depositStakeCmd.MarkFlagRequired("holder")
-	depositStakeCmd.MarkFlagRequired("seq")
-	depositStakeCmd.MarkFlagRequired("stake")
+	addDepositStakeFlags(depositStakeCmd)
 }
@@ -0,0 +1,59 @@
+package tx
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// The fee of a transaction is optional for the tx sub commands. Without the --fee flag, the minimum
+// fee of the transaction is calculated from the fee schedule queried from the node. The amounts can
+// be specified with denominations, e.g. --fee=0.3tfuel or --fee=300000000000000000wei.
+
+const feeFlagUsage = "Fee, e.g. 0.3tfuel, defaults to the minimum fee in the fee schedule of the node"
+
+// getFee returns the fee given by the --fee flag, or the minimum fee of the transaction with the
+// given type and size (as defined by types.TxFeeRule) in the fee schedule of the node.
+func getFee(txType types.TxType, size uint64) *big.Int {
+	if len(feeFlag) != 0 {
+		fee, ok := types.ParseCoinAmountOf(feeFlag, types.DenomTFuel)
+		if !ok {
+			utils.Error("Failed to parse fee: %v\n", feeFlag)
+		}
+		return fee
+	}
+
+	fee := queryFeeSchedule().MinimumFee(txType, size)
+	fmt.Fprintf(os.Stderr, "Using the minimum fee %v\n", utils.FormatCoinAmount(fee, types.DenomTFuel))
+	return fee
+}
+
+// queryFeeSchedule returns the fee schedule of the node, or the default fee schedule if the node
+// is unreachable, e.g. when building a transaction offline
+func queryFeeSchedule() *types.FeeSchedule {
+	client := utils.NewRPCClient()
+	res, err := client.Call("theta.GetFeeSchedule", rpc.GetFeeScheduleArgs{})
+	if err == nil && res.Error == nil {
+		result := &rpc.GetFeeScheduleResult{}
+		if err = res.GetObject(result); err == nil && result.FeeSchedule != nil {
+			return result.FeeSchedule
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Failed to query the fee schedule of the node, falling back to the default fee schedule\n")
+	return types.DefaultFeeSchedule()
+}
+
+// parseAmount parses the amount of the given coin (types.DenomTheta or types.DenomTFuel) given by
+// a flag, e.g. 12.5theta or 12.5
+func parseAmount(flagName string, amount string, denom string) *big.Int {
+	ret, ok := types.ParseCoinAmountOf(amount, denom)
+	if !ok {
+		utils.Error("Failed to parse %v amount: %v\n", flagName, amount)
+	}
+	return ret
+}
@@ -0,0 +1,200 @@
+package tx
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// govProposeCmd represents the governance proposal command. Each param change replaces a chain
+// param with the value read from a JSON file, e.g. the fee schedule returned by theta.GetFeeSchedule.
+// Example:
+//		thetacli tx gov_propose --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --type=text --title="Lower the fees" --description="..." --deposit=10
+//		thetacli tx gov_propose --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --type=param_change --title="Lower the fees" --deposit=10 --param_change=fee_schedule:fees.json
+var govProposeCmd = &cobra.Command{
+	Use:   "gov_propose",
+	Short: "Submit a governance proposal",
+	Example: `thetacli tx gov_propose --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --type=text --title="Lower the fees" --description="..." --deposit=10
+thetacli tx gov_propose --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --type=param_change --title="Lower the fees" --deposit=10 --param_change=fee_schedule:fees.json`,
+	Run: doGovProposeCmd,
+}
+
+func doGovProposeCmd(cmd *cobra.Command, args []string) {
+	proposalType, err := types.ParseGovProposalType(proposalTypeFlag)
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+	paramChanges := []types.GovParamChange{}
+	for _, pcStr := range paramChangesFlag {
+		paramChanges = append(paramChanges, parseGovParamChange(pcStr))
+	}
+	deposit := parseAmount("deposit", depositFlag, types.DenomTFuel)
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	fee := getFee(types.TxGovProposal, 0)
+
+	govProposalTx := &types.GovProposalTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Proposer: types.TxInput{
+			Address: fromAddress,
+			Coins: types.Coins{
+				ThetaWei: new(big.Int).SetUint64(0),
+				TFuelWei: deposit,
+			},
+			Sequence: getSequence(cmd, fromAddress),
+		},
+		ProposalType:   proposalType,
+		Title:          titleFlag,
+		Description:    descriptionFlag,
+		ParamChanges:   paramChanges,
+		NotAfterHeight: notAfterHeightFlag,
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(govProposalTx, chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	govProposalTx.SetSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(govProposalTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	broadcastSignedTx(hex.EncodeToString(raw))
+}
+
+// parseGovParamChange parses a param change given as <param>:<json file>
+func parseGovParamChange(pcStr string) types.GovParamChange {
+	parts := strings.SplitN(pcStr, ":", 2)
+	if len(parts) != 2 {
+		utils.Error("Param change needs to be given as <param>:<json file>: %v\n", pcStr)
+	}
+	param, file := parts[0], parts[1]
+
+	var value interface{}
+	switch param {
+	case types.GovParamFeeSchedule:
+		value = &types.FeeSchedule{}
+	case types.GovParamUpgradeSchedule:
+		value = &types.UpgradeSchedule{}
+	default:
+		utils.Error("Unknown chain param: %v\n", param)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		utils.Error("Failed to read %v: %v\n", file, err)
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		utils.Error("Failed to parse %v: %v\n", file, err)
+	}
+	pc, err := types.NewGovParamChange(param, value)
+	if err != nil {
+		utils.Error("Invalid %v: %v\n", param, err)
+	}
+	return pc
+}
+
+// govVoteCmd represents the governance vote command. The vote can be changed until the end of the
+// voting period.
+// Example:
+//		thetacli tx gov_vote --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --proposal_id=1 --option=yes
+var govVoteCmd = &cobra.Command{
+	Use:     "gov_vote",
+	Short:   "Vote on a governance proposal with the staked Theta",
+	Example: `thetacli tx gov_vote --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --proposal_id=1 --option=yes`,
+	Run:     doGovVoteCmd,
+}
+
+func doGovVoteCmd(cmd *cobra.Command, args []string) {
+	option, err := types.ParseGovVoteOption(voteOptionFlag)
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	fee := getFee(types.TxGovVote, 0)
+
+	govVoteTx := &types.GovVoteTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Voter: types.TxInput{
+			Address:  fromAddress,
+			Sequence: getSequence(cmd, fromAddress),
+		},
+		ProposalID:     proposalIDFlag,
+		Option:         option,
+		NotAfterHeight: notAfterHeightFlag,
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(govVoteTx, chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	govVoteTx.SetSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(govVoteTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	broadcastSignedTx(hex.EncodeToString(raw))
+}
+
+func init() {
+	govProposeCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	govProposeCmd.Flags().StringVar(&fromFlag, "from", "", "Proposer's address")
+	govProposeCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	govProposeCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	govProposeCmd.Flags().StringVar(&proposalTypeFlag, "type", "text", "Proposal type (text|param_change)")
+	govProposeCmd.Flags().StringVar(&titleFlag, "title", "", "Title of the proposal")
+	govProposeCmd.Flags().StringVar(&descriptionFlag, "description", "", "Description of the proposal")
+	govProposeCmd.Flags().StringVar(&depositFlag, "deposit", "10", "Deposit in TFuel, refunded if the vote reaches the quorum")
+	govProposeCmd.Flags().StringSliceVar(&paramChangesFlag, "param_change", []string{}, "Param change as <param>:<json file>, the params are fee_schedule and upgrade_schedule")
+	govProposeCmd.Flags().Uint64Var(&notAfterHeightFlag, "not_after_height", 0, "Block height after which the transaction expires, 0 for no expiry")
+	govProposeCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	govProposeCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	govProposeCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	govProposeCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	govProposeCmd.MarkFlagRequired("chain")
+	govProposeCmd.MarkFlagRequired("from")
+	govProposeCmd.MarkFlagRequired("title")
+
+	govVoteCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	govVoteCmd.Flags().StringVar(&fromFlag, "from", "", "Voter's address")
+	govVoteCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	govVoteCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	govVoteCmd.Flags().Uint64Var(&proposalIDFlag, "proposal_id", 0, "ID of the proposal")
+	govVoteCmd.Flags().StringVar(&voteOptionFlag, "option", "", "Vote option (yes|no|abstain)")
+	govVoteCmd.Flags().Uint64Var(&notAfterHeightFlag, "not_after_height", 0, "Block height after which the transaction expires, 0 for no expiry")
+	govVoteCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	govVoteCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	govVoteCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	govVoteCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	govVoteCmd.MarkFlagRequired("chain")
+	govVoteCmd.MarkFlagRequired("from")
+	govVoteCmd.MarkFlagRequired("proposal_id")
+	govVoteCmd.MarkFlagRequired("option")
+}
@@ -1,7 +1,10 @@
 package tx
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 )
 
 // Common flags used in Tx sub commands.
@@ -13,6 +16,7 @@ var (
 	seqFlag                      uint64
 	thetaAmountFlag              string
 	tfuelAmountFlag              string
+	amountFlag                   string
 	gasAmountFlag                uint64
 	feeFlag                      string
 	resourceIDsFlag              []string
@@ -28,6 +32,7 @@ var (
 	gasLimitFlag                 uint64
 	dataFlag                     string
 	walletFlag                   string
+	ledgerFlag                   bool
 	stakeInThetaFlag             string
 	purposeFlag                  uint8
 	sourceFlag                   string
@@ -36,13 +41,68 @@ var (
 	beneficiaryFlag              string
 	splitBasisPointFlag          uint64
 	passwordFlag                 string
+	thresholdFlag                uint
+	signersFlag                  []string
+	txFlag                       string
+	txsFlag                      []string
+	broadcastFlag                bool
+	unlockHeightFlag             uint64
+	unlockTimeFlag               uint64
+	vestingEndHeightFlag         uint64
+	fundIDFlag                   string
+	outputsFlag                  []string
+	disputeWindowFlag            uint64
+	namespaceFlag                string
+	symbolFlag                   string
+	tokenNameFlag                string
+	decimalsFlag                 uint8
+	contractFlag                 string
+	feePayerFlag                 string
+	feePayerSeqFlag              uint64
+	notAfterHeightFlag           uint64
+	runtimeFlag                  string
+	offlineFlag                  bool
+	outFlag                      string
+	timeoutFlag                  time.Duration
+	fileFlag                     string
+	seriesFlag                   bool
+	yesFlag                      bool
+	abiFlag                      string
+	bytecodeFlag                 string
+	signingAddressFlag           string
+	activationEpochFlag          uint64
+	proposalTypeFlag             string
+	titleFlag                    string
+	descriptionFlag              string
+	depositFlag                  string
+	paramChangesFlag             []string
+	proposalIDFlag               uint64
+	voteOptionFlag               string
 )
 
 // TxCmd represents the Tx command
 var TxCmd = &cobra.Command{
 	Use:   "tx",
 	Short: "Manage transactions",
-	Long:  `Manage transactions.`,
+	Long:  `Manage transactions. The addresses can also be given as names in the address book. The chain ID defaults to the one of the selected profile.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		resolveAddressFlags(cmd)
+		utils.ApplyChainID(cmd)
+	},
+}
+
+// resolveAddressFlags replaces the address book names in the address flags with their addresses.
+// The outputs of the batch send command are resolved when they are parsed.
+func resolveAddressFlags(cmd *cobra.Command) {
+	cfgPath := cmd.Flag("config").Value.String()
+	for _, flag := range []*string{&fromFlag, &toFlag, &sourceFlag, &holderFlag, &beneficiaryFlag, &feePayerFlag, &contractFlag, &signingAddressFlag} {
+		*flag = utils.ResolveAddress(cfgPath, *flag)
+	}
+	for _, flag := range []*[]string{&signersFlag, &addressesFlag} {
+		for i := range *flag {
+			(*flag)[i] = utils.ResolveAddress(cfgPath, (*flag)[i])
+		}
+	}
 }
 
 func init() {
@@ -53,5 +113,26 @@ func init() {
 	TxCmd.AddCommand(smartContractCmd)
 	TxCmd.AddCommand(depositStakeCmd)
 	TxCmd.AddCommand(withdrawStakeCmd)
+	TxCmd.AddCommand(stakeCmd)
 	TxCmd.AddCommand(stakeRewardDistributionCmd)
+	TxCmd.AddCommand(multiSigCmd)
+	TxCmd.AddCommand(vestingTransferCmd)
+	TxCmd.AddCommand(vestingClaimCmd)
+	TxCmd.AddCommand(cancelCmd)
+	TxCmd.AddCommand(batchSendCmd)
+	TxCmd.AddCommand(splitRuleRenewCmd)
+	TxCmd.AddCommand(tokenRegisterCmd)
+	TxCmd.AddCommand(validatorKeyRotateCmd)
+	TxCmd.AddCommand(govProposeCmd)
+	TxCmd.AddCommand(govVoteCmd)
+	TxCmd.AddCommand(sponsorCmd)
+	TxCmd.AddCommand(buildCmd)
+	TxCmd.AddCommand(signCmd)
+	TxCmd.AddCommand(broadcastCmd)
+	TxCmd.AddCommand(statusCmd)
+	TxCmd.AddCommand(waitCmd)
+	TxCmd.AddCommand(decodeCmd)
+
+	TxCmd.PersistentFlags().BoolVar(&yesFlag, "yes", false, "Sign the transaction without asking for confirmation")
+	TxCmd.PersistentFlags().BoolVar(&ledgerFlag, "ledger", false, "Sign with the Ledger device, same as --wallet=nano, the key is selected by --path")
 }
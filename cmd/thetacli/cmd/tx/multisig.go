@@ -0,0 +1,210 @@
+package tx
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// multiSigCmd represents the multisig command. A multisig transaction is created once,
+// signed separately by each of the signers, and then assembled and broadcasted.
+// Example:
+//		thetacli tx multisig address --threshold=2 --signers=2E833968E5bB786Ae419c4d13189fB081Cc43bab,9F1233798E905E173560071255140b4A8aBd3Ec6,0d2fD67d573c8ecB4161510fc00754d64B401F86
+//		thetacli tx multisig create --threshold=2 --signers=2E833968E5bB786Ae419c4d13189fB081Cc43bab,9F1233798E905E173560071255140b4A8aBd3Ec6,0d2fD67d573c8ecB4161510fc00754d64B401F86 --to=70f587259738cB626A1720Af7038B8DcDb6a42a0 --theta=10 --tfuel=9 --seq=1
+//		thetacli tx multisig sign --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --tx=<unsigned tx>
+//		thetacli tx multisig assemble --txs=<tx signed by signer 1>,<tx signed by signer 2> --broadcast
+var multiSigCmd = &cobra.Command{
+	Use:   "multisig",
+	Short: "Manage multisig account transactions",
+}
+
+var multiSigAddressCmd = &cobra.Command{
+	Use:     "address",
+	Short:   "Get the address of a multisig account",
+	Example: `thetacli tx multisig address --threshold=2 --signers=2E833968E5bB786Ae419c4d13189fB081Cc43bab,9F1233798E905E173560071255140b4A8aBd3Ec6,0d2fD67d573c8ecB4161510fc00754d64B401F86`,
+	Run:     doMultiSigAddressCmd,
+}
+
+var multiSigCreateCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Create an unsigned transaction sending tokens from a multisig account",
+	Example: `thetacli tx multisig create --threshold=2 --signers=2E833968E5bB786Ae419c4d13189fB081Cc43bab,9F1233798E905E173560071255140b4A8aBd3Ec6,0d2fD67d573c8ecB4161510fc00754d64B401F86 --to=70f587259738cB626A1720Af7038B8DcDb6a42a0 --theta=10 --tfuel=9 --seq=1`,
+	Run:     doMultiSigCreateCmd,
+}
+
+var multiSigSignCmd = &cobra.Command{
+	Use:     "sign",
+	Short:   "Sign a multisig transaction as one of the signers",
+	Example: `thetacli tx multisig sign --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --tx=<unsigned tx>`,
+	Run:     doMultiSigSignCmd,
+}
+
+var multiSigAssembleCmd = &cobra.Command{
+	Use:     "assemble",
+	Short:   "Assemble the partially signed copies of a multisig transaction",
+	Example: `thetacli tx multisig assemble --txs=<tx signed by signer 1>,<tx signed by signer 2> --broadcast`,
+	Run:     doMultiSigAssembleCmd,
+}
+
+func parseMultiSigInfo() *types.MultiSigInfo {
+	signers := []common.Address{}
+	for _, signer := range signersFlag {
+		signers = append(signers, common.HexToAddress(signer))
+	}
+	multiSig := types.NewMultiSigInfo(thresholdFlag, signers)
+	if res := multiSig.ValidateBasic(); res.IsError() {
+		utils.Error("Invalid multisig account: %v\n", res.Message)
+	}
+	return multiSig
+}
+
+func decodeMultiSigTx(txStr string) *types.MultiSigSendTx {
+	raw, err := hex.DecodeString(txStr)
+	if err != nil {
+		utils.Error("Failed to decode transaction: %v\n", err)
+	}
+	tx, err := types.TxFromBytes(raw)
+	if err != nil {
+		utils.Error("Failed to decode transaction: %v\n", err)
+	}
+	multiSigTx, ok := tx.(*types.MultiSigSendTx)
+	if !ok {
+		utils.Error("Not a multisig transaction\n")
+	}
+	return multiSigTx
+}
+
+func encodeMultiSigTx(tx *types.MultiSigSendTx) string {
+	raw, err := types.TxToBytes(tx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	return hex.EncodeToString(raw)
+}
+
+func doMultiSigAddressCmd(cmd *cobra.Command, args []string) {
+	multiSig := parseMultiSigInfo()
+	result := struct {
+		Address string `json:"address"`
+	}{multiSig.Address().Hex()}
+	utils.PrintResult(result, "%s\n", multiSig.Address().Hex())
+}
+
+func doMultiSigCreateCmd(cmd *cobra.Command, args []string) {
+	multiSig := parseMultiSigInfo()
+
+	theta := parseAmount("theta", thetaAmountFlag, types.DenomTheta)
+	tfuel := parseAmount("tfuel", tfuelAmountFlag, types.DenomTFuel)
+	fee := getFee(types.TxMultiSigSend, 2) // the input and the output accounts
+
+	multiSigTx := &types.MultiSigSendTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		MultiSig: *multiSig,
+		Input: types.TxInput{
+			Address: multiSig.Address(),
+			Coins: types.Coins{
+				TFuelWei: new(big.Int).Add(tfuel, fee),
+				ThetaWei: theta,
+			},
+			Sequence: getSequence(cmd, multiSig.Address()),
+		},
+		Outputs: []types.TxOutput{{
+			Address: common.HexToAddress(toFlag),
+			Coins: types.Coins{
+				TFuelWei: tfuel,
+				ThetaWei: theta,
+			},
+		}},
+	}
+
+	encodedTx := encodeMultiSigTx(multiSigTx)
+	printEncodedTx(encodedTx, "%s\n", encodedTx)
+}
+
+func doMultiSigSignCmd(cmd *cobra.Command, args []string) {
+	multiSigTx := decodeMultiSigTx(txFlag)
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	if multiSigTx.MultiSig.SignerIndex(fromAddress) < 0 {
+		utils.Error("Address %v is not a signer of the multisig account\n", fromAddress.Hex())
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(multiSigTx, chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	multiSigTx.SetSignature(fromAddress, sig)
+
+	encodedTx := encodeMultiSigTx(multiSigTx)
+	printEncodedTx(encodedTx, "%s\n", encodedTx)
+}
+
+func doMultiSigAssembleCmd(cmd *cobra.Command, args []string) {
+	if len(txsFlag) == 0 {
+		utils.Error("No transaction to assemble")
+	}
+
+	multiSigTx := decodeMultiSigTx(txsFlag[0])
+	for _, txStr := range txsFlag[1:] {
+		if !multiSigTx.MergeSignatures(decodeMultiSigTx(txStr)) {
+			utils.Error("Transactions are not from the same multisig account\n")
+		}
+	}
+	signedTx := encodeMultiSigTx(multiSigTx)
+
+	if !broadcastFlag {
+		printEncodedTx(signedTx, "%s\n", signedTx)
+		return
+	}
+
+	broadcastSignedTx(signedTx)
+}
+
+func init() {
+	multiSigAddressCmd.Flags().UintVar(&thresholdFlag, "threshold", 1, "Number of signatures required")
+	multiSigAddressCmd.Flags().StringSliceVar(&signersFlag, "signers", []string{}, "Signer addresses")
+	multiSigAddressCmd.MarkFlagRequired("threshold")
+	multiSigAddressCmd.MarkFlagRequired("signers")
+
+	multiSigCreateCmd.Flags().UintVar(&thresholdFlag, "threshold", 1, "Number of signatures required")
+	multiSigCreateCmd.Flags().StringSliceVar(&signersFlag, "signers", []string{}, "Signer addresses")
+	multiSigCreateCmd.Flags().StringVar(&toFlag, "to", "", "Address to send to")
+	multiSigCreateCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	multiSigCreateCmd.Flags().StringVar(&thetaAmountFlag, "theta", "0", "Theta amount")
+	multiSigCreateCmd.Flags().StringVar(&tfuelAmountFlag, "tfuel", "0", "TFuel amount")
+	multiSigCreateCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	multiSigCreateCmd.MarkFlagRequired("threshold")
+	multiSigCreateCmd.MarkFlagRequired("signers")
+	multiSigCreateCmd.MarkFlagRequired("to")
+
+	multiSigSignCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	multiSigSignCmd.Flags().StringVar(&fromFlag, "from", "", "Signer address")
+	multiSigSignCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	multiSigSignCmd.Flags().StringVar(&txFlag, "tx", "", "Hex encoded multisig transaction")
+	multiSigSignCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
+	multiSigSignCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	multiSigSignCmd.MarkFlagRequired("chain")
+	multiSigSignCmd.MarkFlagRequired("tx")
+
+	multiSigAssembleCmd.Flags().StringSliceVar(&txsFlag, "txs", []string{}, "Hex encoded multisig transactions, each signed by some of the signers")
+	multiSigAssembleCmd.Flags().BoolVar(&broadcastFlag, "broadcast", false, "Broadcast the assembled transaction")
+	multiSigAssembleCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	multiSigAssembleCmd.MarkFlagRequired("txs")
+
+	multiSigCmd.AddCommand(multiSigAddressCmd)
+	multiSigCmd.AddCommand(multiSigCreateCmd)
+	multiSigCmd.AddCommand(multiSigSignCmd)
+	multiSigCmd.AddCommand(multiSigAssembleCmd)
+}
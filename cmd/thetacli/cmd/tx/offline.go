@@ -0,0 +1,230 @@
+package tx
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+)
+
+// The offline signing workflow splits a transaction command into three steps, so that the keys
+// can stay on a machine that never touches the network:
+//		thetacli tx build send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --theta=10 --seq=1 > unsigned.json
+//		thetacli tx sign unsigned.json --offline --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --out=signed.json
+//		thetacli tx broadcast signed.json
+
+// buildCmd runs a tx sub command without signing and broadcasting the transaction. The
+// unsigned transaction is printed instead.
+var buildCmd = &cobra.Command{
+	Use:                "build <command> [flags]",
+	Short:              "Build an unsigned transaction with any of the tx sub commands",
+	Example:            `thetacli tx build send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --theta=10 --seq=1 > unsigned.json`,
+	DisableFlagParsing: true,
+	Run:                doBuildCmd,
+}
+
+// signCmd signs an unsigned transaction, and broadcasts it unless --offline is set.
+var signCmd = &cobra.Command{
+	Use:     "sign <unsigned-file>",
+	Short:   "Sign a transaction built by the build command",
+	Example: `thetacli tx sign unsigned.json --offline --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --out=signed.json`,
+	Args:    cobra.ExactArgs(1),
+	Run:     doSignCmd,
+}
+
+// broadcastCmd broadcasts a transaction signed by the sign command.
+var broadcastCmd = &cobra.Command{
+	Use:     "broadcast <signed-file>",
+	Short:   "Broadcast a transaction signed by the sign command",
+	Example: `thetacli tx broadcast signed.json`,
+	Args:    cobra.ExactArgs(1),
+	Run:     doBroadcastCmd,
+}
+
+// offlineTx is the file format of the transactions passed between the offline workflow steps.
+type offlineTx struct {
+	ChainID string          `json:"chain_id"`
+	Signer  common.Address  `json:"signer"`
+	Type    string          `json:"type"`
+	Signed  bool            `json:"signed"`
+	Tx      json.RawMessage `json:"tx"`  // for review only
+	Raw     string          `json:"raw"` // the hex encoded transaction
+}
+
+func newOfflineTx(chainID string, signer common.Address, tx types.Tx, signed bool) *offlineTx {
+	raw, err := types.TxToBytes(tx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	txJSON, err := json.Marshal(tx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	return &offlineTx{
+		ChainID: chainID,
+		Signer:  signer,
+		Type:    strings.TrimPrefix(fmt.Sprintf("%T", tx), "*types."),
+		Signed:  signed,
+		Tx:      txJSON,
+		Raw:     hex.EncodeToString(raw),
+	}
+}
+
+func readOfflineTx(filePath string) (*offlineTx, types.Tx) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		utils.Error("Failed to read %v: %v\n", filePath, err)
+	}
+	otx := &offlineTx{}
+	if err := json.Unmarshal(content, otx); err != nil {
+		utils.Error("Failed to parse %v: %v\n", filePath, err)
+	}
+	raw, err := hex.DecodeString(otx.Raw)
+	if err != nil {
+		utils.Error("Failed to decode transaction: %v\n", err)
+	}
+	tx, err := types.TxFromBytes(raw)
+	if err != nil {
+		utils.Error("Failed to decode transaction: %v\n", err)
+	}
+	return otx, tx
+}
+
+func writeOfflineTx(otx *offlineTx, filePath string) {
+	content, err := json.MarshalIndent(otx, "", "    ")
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	if filePath == "" {
+		fmt.Println(string(content))
+		return
+	}
+	if err := ioutil.WriteFile(filePath, content, 0600); err != nil {
+		utils.Error("Failed to write %v: %v\n", filePath, err)
+	}
+}
+
+// ------------------------------ Build -----------------------------------
+
+// buildMode is set by the build command, in which the sub commands are given a buildWallet
+var buildMode bool
+
+// buildWallet stands in for the wallet of the signer in the build mode. Instead of signing the
+// transaction, it prints the unsigned transaction and exits, before the sub command would
+// broadcast it. The other wallet methods are not used by the sub commands.
+type buildWallet struct {
+	wtypes.Wallet
+	address common.Address
+}
+
+func (w *buildWallet) Lock(address common.Address) error {
+	return nil
+}
+
+func (w *buildWallet) IsUnlocked(address common.Address) bool {
+	return true
+}
+
+func (w *buildWallet) Sign(address common.Address, signBytes common.Bytes) (*crypto.Signature, error) {
+	chainID, tx, err := types.TxFromSignBytes(signBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode transaction: %v", err)
+	}
+	writeOfflineTx(newOfflineTx(chainID, address, tx, false), "")
+	os.Exit(0)
+	return nil, nil
+}
+
+func doBuildCmd(cmd *cobra.Command, args []string) {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		cmd.Help()
+		return
+	}
+	sub, _, err := cmd.Parent().Find(args[:1])
+	if err != nil || sub == cmd.Parent() || sub == cmd || sub == signCmd || sub == broadcastCmd || sub == decodeCmd {
+		utils.Error("Unknown transaction command %v\n", args[0])
+	}
+	// The co-signing commands would drop the signatures already on the transaction
+	if sub == sponsorCmd || sub == multiSigCmd {
+		utils.Error("The %v command can't be used with build\n", args[0])
+	}
+
+	buildMode = true
+	root := cmd.Root()
+	root.SetArgs(append([]string{cmd.Parent().Name()}, args...))
+	if err := root.Execute(); err != nil {
+		os.Exit(1)
+	}
+	// The sub command exits when the transaction is built
+	utils.Error("The %v command did not build a transaction\n", args[0])
+}
+
+// ------------------------------ Sign -----------------------------------
+
+func doSignCmd(cmd *cobra.Command, args []string) {
+	otx, tx := readOfflineTx(args[0])
+	if otx.Signed {
+		utils.Error("The transaction is already signed\n")
+	}
+	signable, ok := tx.(interface {
+		SetSignature(addr common.Address, sig *crypto.Signature) bool
+	})
+	if !ok {
+		utils.Error("Transactions of type %v can't be signed\n", otx.Type)
+	}
+	if fromFlag == "" && getWalletType(cmd) == wtypes.WalletTypeSoft {
+		fromFlag = otx.Signer.Hex()
+	}
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(tx, otx.ChainID))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	if !signable.SetSignature(fromAddress, sig) {
+		utils.Error("Address %v is not a signer of the transaction\n", fromAddress.Hex())
+	}
+
+	signed := newOfflineTx(otx.ChainID, fromAddress, tx, true)
+	if offlineFlag {
+		writeOfflineTx(signed, outFlag)
+		return
+	}
+	broadcastSignedTx(signed.Raw)
+}
+
+// ------------------------------ Broadcast -----------------------------------
+
+func doBroadcastCmd(cmd *cobra.Command, args []string) {
+	otx, _ := readOfflineTx(args[0])
+	if !otx.Signed {
+		utils.Error("The transaction is not signed yet\n")
+	}
+	broadcastSignedTx(otx.Raw)
+}
+
+func init() {
+	signCmd.Flags().StringVar(&fromFlag, "from", "", "Signer address, the signer recorded by the build command by default")
+	signCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	signCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
+	signCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	signCmd.Flags().BoolVar(&offlineFlag, "offline", false, "Write the signed transaction instead of broadcasting it")
+	signCmd.Flags().StringVar(&outFlag, "out", "", "File to write the signed transaction to, stdout by default")
+	signCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+
+	broadcastCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+}
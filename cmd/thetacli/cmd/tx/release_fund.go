@@ -2,16 +2,11 @@ package tx
 
 import (
 	"encoding/hex"
-	"fmt"
 	"math/big"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/ledger/types"
-	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // releaseFundCmd represents the release fund command
@@ -25,7 +20,7 @@ var releaseFundCmd = &cobra.Command{
 }
 
 func doReleaseFundCmd(cmd *cobra.Command, args []string) {
-	wallet, fromAddress, err := walletUnlock(cmd, fromFlag, passwordFlag)
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
 	if err != nil {
 		return
 	}
@@ -33,23 +28,20 @@ func doReleaseFundCmd(cmd *cobra.Command, args []string) {
 
 	input := types.TxInput{
 		Address:  fromAddress,
-		Sequence: uint64(seqFlag),
+		Sequence: getSequence(cmd, fromAddress),
 	}
 
-	tfuel, ok := types.ParseCoinAmount(feeFlag)
-	if !ok {
-		utils.Error("Failed to parse tfuel amount")
-	}
+	fee := getFee(types.TxReleaseFund, 0)
 	releaseFundTx := &types.ReleaseFundTx{
 		Fee: types.Coins{
 			ThetaWei: new(big.Int).SetUint64(0),
-			TFuelWei: tfuel,
+			TFuelWei: fee,
 		},
 		Source:          input,
 		ReserveSequence: reserveSeqFlag,
 	}
 
-	sig, err := wallet.Sign(fromAddress, releaseFundTx.SignBytes(chainIDFlag))
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(releaseFundTx, chainIDFlag))
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
@@ -61,36 +53,22 @@ func doReleaseFundCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
-
-	var res *rpcc.RPCResponse
-	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	}
-	if err != nil {
-		utils.Error("Failed to broadcast transaction: %v\n", err)
-	}
-	if res.Error != nil {
-		utils.Error("Server returned error: %v\n", res.Error)
-	}
-	fmt.Printf("Successfully broadcasted transaction.\n")
+	broadcastSignedTx(signedTx)
 }
 
 func init() {
 	releaseFundCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
 	releaseFundCmd.Flags().StringVar(&fromFlag, "from", "", "Reserve owner's address")
-	releaseFundCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
-	releaseFundCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWeiJune2021), "Fee")
+	releaseFundCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	releaseFundCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
 	releaseFundCmd.Flags().Uint64Var(&reserveSeqFlag, "reserve_seq", 1000, "Reserve sequence")
 	releaseFundCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	releaseFundCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
 	releaseFundCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
 	releaseFundCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
 
 	releaseFundCmd.MarkFlagRequired("chain")
 	releaseFundCmd.MarkFlagRequired("from")
-	releaseFundCmd.MarkFlagRequired("seq")
 	releaseFundCmd.MarkFlagRequired("reserve_seq")
 	releaseFundCmd.MarkFlagRequired("resource_id")
 
@@ -2,21 +2,17 @@ package tx
 
 import (
 	"encoding/hex"
-	"fmt"
 	"math/big"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/ledger/types"
-	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // reserveFundCmd represents the reserve fund command
 // Example:
 //		thetacli tx reserve --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --fund=900 --collateral=1203 --seq=6 --duration=1002 --resource_ids=die_another_day,hello
+//		thetacli tx reserve --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --fund=900 --collateral=1203 --seq=6 --duration=1002 --resource_ids=die_another_day,hello --dispute_window=100
 var reserveFundCmd = &cobra.Command{
 	Use:     "reserve",
 	Short:   "Reserve fund for an off-chain micropayment",
@@ -25,31 +21,27 @@ var reserveFundCmd = &cobra.Command{
 }
 
 func doReserveFundCmd(cmd *cobra.Command, args []string) {
-	wallet, fromAddress, err := walletUnlock(cmd, fromFlag, passwordFlag)
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
 	if err != nil {
 		return
 	}
 	defer wallet.Lock(fromAddress)
 
-	fee, ok := types.ParseCoinAmount(feeFlag)
-	if !ok {
-		utils.Error("Failed to parse fee")
-	}
-	fund, ok := types.ParseCoinAmount(reserveFundInTFuelFlag)
-	if !ok {
-		utils.Error("Failed to parse fund")
-	}
-	col, ok := types.ParseCoinAmount(reserveCollateralInTFuelFlag)
-	if !ok {
-		utils.Error("Failed to parse collateral")
+	// The dispute window is only supported by ReserveFundTxV2
+	txType := types.TxReserveFund
+	if disputeWindowFlag > 0 {
+		txType = types.TxReserveFundV2
 	}
+	feeAmount := getFee(txType, 0)
+	fund := parseAmount("fund", reserveFundInTFuelFlag, types.DenomTFuel)
+	col := parseAmount("collateral", reserveCollateralInTFuelFlag, types.DenomTFuel)
 	input := types.TxInput{
 		Address: fromAddress,
 		Coins: types.Coins{
 			ThetaWei: new(big.Int).SetUint64(0),
 			TFuelWei: fund,
 		},
-		Sequence: uint64(seqFlag),
+		Sequence: getSequence(cmd, fromAddress),
 	}
 	resourceIDs := []string{}
 	for _, id := range resourceIDsFlag {
@@ -63,22 +55,41 @@ func doReserveFundCmd(cmd *cobra.Command, args []string) {
 		utils.Error("Invalid input: collateral must be positive\n")
 	}
 
-	reserveFundTx := &types.ReserveFundTx{
-		Fee: types.Coins{
-			ThetaWei: new(big.Int).SetUint64(0),
-			TFuelWei: fee,
-		},
-		Source:      input,
-		ResourceIDs: resourceIDs,
-		Collateral:  collateral,
-		Duration:    durationFlag,
+	fee := types.Coins{
+		ThetaWei: new(big.Int).SetUint64(0),
+		TFuelWei: feeAmount,
+	}
+
+	var reserveFundTx types.Tx
+	if txType == types.TxReserveFundV2 {
+		reserveFundTx = &types.ReserveFundTxV2{
+			Fee:           fee,
+			Source:        input,
+			ResourceIDs:   resourceIDs,
+			Collateral:    collateral,
+			Duration:      durationFlag,
+			DisputeWindow: disputeWindowFlag,
+		}
+	} else {
+		reserveFundTx = &types.ReserveFundTx{
+			Fee:         fee,
+			Source:      input,
+			ResourceIDs: resourceIDs,
+			Collateral:  collateral,
+			Duration:    durationFlag,
+		}
 	}
 
-	sig, err := wallet.Sign(fromAddress, reserveFundTx.SignBytes(chainIDFlag))
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(reserveFundTx, chainIDFlag))
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
-	reserveFundTx.SetSignature(fromAddress, sig)
+	switch tx := reserveFundTx.(type) {
+	case *types.ReserveFundTxV2:
+		tx.SetSignature(fromAddress, sig)
+	case *types.ReserveFundTx:
+		tx.SetSignature(fromAddress, sig)
+	}
 
 	raw, err := types.TxToBytes(reserveFundTx)
 	if err != nil {
@@ -86,39 +97,26 @@ func doReserveFundCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
-
-	var res *rpcc.RPCResponse
-	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	}
-	if err != nil {
-		utils.Error("Failed to broadcast transaction: %v\n", err)
-	}
-	if res.Error != nil {
-		utils.Error("Server returned error: %v\n", res.Error)
-	}
-	fmt.Printf("Successfully broadcasted transaction.\n")
+	broadcastSignedTx(signedTx)
 }
 
 func init() {
 	reserveFundCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
 	reserveFundCmd.Flags().StringVar(&fromFlag, "from", "", "Address to send from")
-	reserveFundCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	reserveFundCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
 	reserveFundCmd.Flags().StringVar(&reserveFundInTFuelFlag, "fund", "0", "TFuel amount to reserve")
 	reserveFundCmd.Flags().StringVar(&reserveCollateralInTFuelFlag, "collateral", "0", "TFuel amount as collateral")
-	reserveFundCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWeiJune2021), "Fee")
+	reserveFundCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
 	reserveFundCmd.Flags().Uint64Var(&durationFlag, "duration", 1000, "Reserve duration")
 	reserveFundCmd.Flags().StringSliceVar(&resourceIDsFlag, "resource_ids", []string{}, "Reserouce IDs")
+	reserveFundCmd.Flags().Uint64Var(&disputeWindowFlag, "dispute_window", 0, "Number of blocks before a service payment is settled, 0 to settle immediately")
 	reserveFundCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	reserveFundCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
 	reserveFundCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
 	reserveFundCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
 
 	reserveFundCmd.MarkFlagRequired("chain")
 	reserveFundCmd.MarkFlagRequired("from")
-	reserveFundCmd.MarkFlagRequired("seq")
 	reserveFundCmd.MarkFlagRequired("duration")
 	reserveFundCmd.MarkFlagRequired("resource_id")
 }
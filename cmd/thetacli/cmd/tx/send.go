@@ -2,20 +2,13 @@ package tx
 
 import (
 	"encoding/hex"
-	"encoding/json"
-	"fmt"
 	"math/big"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/ledger/types"
-	"github.com/thetatoken/theta/rpc"
 	wtypes "github.com/thetatoken/theta/wallet/types"
-
-	"github.com/ybbus/jsonrpc"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // sendCmd represents the send command
@@ -23,6 +16,10 @@ import (
 //		thetacli tx send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --theta=10 --tfuel=9 --seq=1
 //		thetacli tx send --chain="privatenet" --path "m/44'/60'/0'/0/0" --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --theta=10 --tfuel=9 --seq=1 --wallet=trezor
 //		thetacli tx send --chain="privatenet" --path "m/44'/60'/0'/0" --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --theta=10 --tfuel=9 --seq=1 --wallet=nano
+//   * Send with the amount in denominations and an explicit fee, instead of the minimum fee queried from the node
+//		thetacli tx send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --amount=12.5theta,0.3tfuel --fee=0.3tfuel
+//   * Send with the fee paid by a fee payer, which then co-signs the tx with the "tx sponsor" command
+//		thetacli tx send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --theta=10 --seq=1 --fee_payer=0d2fD67d573c8ecB4161510fc00754d64B401F86 --fee_payer_seq=3
 var sendCmd = &cobra.Command{
 	Use:     "send",
 	Short:   "Send tokens",
@@ -52,25 +49,38 @@ func doSendCmd(cmd *cobra.Command, args []string) {
 	}
 	defer wallet.Lock(fromAddress)
 
-	theta, ok := types.ParseCoinAmount(thetaAmountFlag)
-	if !ok {
-		utils.Error("Failed to parse theta amount")
+	var theta, tfuel *big.Int
+	if len(amountFlag) != 0 {
+		if cmd.Flags().Changed("theta") || cmd.Flags().Changed("tfuel") {
+			utils.Error("The --amount flag cannot be combined with the --theta and --tfuel flags\n")
+		}
+		amount, ok := types.ParseCoins(amountFlag)
+		if !ok {
+			utils.Error("Failed to parse amount: %v, expected e.g. 12.5theta or 12.5theta,0.3tfuel\n", amountFlag)
+		}
+		theta, tfuel = amount.ThetaWei, amount.TFuelWei
+	} else {
+		theta = parseAmount("theta", thetaAmountFlag, types.DenomTheta)
+		tfuel = parseAmount("tfuel", tfuelAmountFlag, types.DenomTFuel)
 	}
-	tfuel, ok := types.ParseCoinAmount(tfuelAmountFlag)
-	if !ok {
-		utils.Error("Failed to parse tfuel amount")
+	// The fee of a sponsored tx is paid by the fee payer
+	sponsored := len(feePayerFlag) != 0
+	numAccountsAffected := uint64(2)
+	if sponsored {
+		numAccountsAffected++
 	}
-	fee, ok := types.ParseCoinAmount(feeFlag)
-	if !ok {
-		utils.Error("Failed to parse fee")
+	fee := getFee(types.TxSend, numAccountsAffected)
+	inputTFuel := new(big.Int).Add(tfuel, fee)
+	if sponsored {
+		inputTFuel = tfuel
 	}
 	inputs := []types.TxInput{{
 		Address: fromAddress,
 		Coins: types.Coins{
-			TFuelWei: new(big.Int).Add(tfuel, fee),
+			TFuelWei: inputTFuel,
 			ThetaWei: theta,
 		},
-		Sequence: uint64(seqFlag),
+		Sequence: getSequence(cmd, fromAddress),
 	}}
 	outputs := []types.TxOutput{{
 		Address: common.HexToAddress(toFlag),
@@ -84,11 +94,15 @@ func doSendCmd(cmd *cobra.Command, args []string) {
 			ThetaWei: new(big.Int).SetUint64(0),
 			TFuelWei: fee,
 		},
-		Inputs:  inputs,
-		Outputs: outputs,
+		Inputs:         inputs,
+		Outputs:        outputs,
+		NotAfterHeight: notAfterHeightFlag,
+	}
+	if sponsored {
+		sendTx.FeePayer = []types.TxInput{newFeePayerInput(fee)}
 	}
 
-	sig, err := wallet.Sign(fromAddress, sendTx.SignBytes(chainIDFlag))
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(sendTx, chainIDFlag))
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
@@ -100,31 +114,13 @@ func doSendCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
-
-	var res *jsonrpc.RPCResponse
-	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	if sponsored {
+		printEncodedTx(signedTx, "Transaction signed by the sender, to be co-signed by the fee payer with the \"tx sponsor\" command:\n%s\n", signedTx)
+		recordPendingSequence()
+		return
 	}
 
-	if err != nil {
-		utils.Error("Failed to broadcast transaction: %v\n", err)
-	}
-	if res.Error != nil {
-		utils.Error("Server returned error: %v\n", res.Error)
-	}
-	result := &rpc.BroadcastRawTransactionResult{}
-	err = res.GetObject(result)
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n", err)
-	}
-	formatted, err := json.MarshalIndent(result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n", err)
-	}
-	fmt.Printf("Successfully broadcasted transaction:\n%s\n", formatted)
+	broadcastSignedTx(signedTx)
 }
 
 func init() {
@@ -132,16 +128,19 @@ func init() {
 	sendCmd.Flags().StringVar(&fromFlag, "from", "", "Address to send from")
 	sendCmd.Flags().StringVar(&toFlag, "to", "", "Address to send to")
 	sendCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
-	sendCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	sendCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
 	sendCmd.Flags().StringVar(&thetaAmountFlag, "theta", "0", "Theta amount")
 	sendCmd.Flags().StringVar(&tfuelAmountFlag, "tfuel", "0", "TFuel amount")
-	sendCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWeiJune2021), "Fee")
+	sendCmd.Flags().StringVar(&amountFlag, "amount", "", "Amount with denominations, e.g. 12.5theta or 12.5theta,0.3tfuel, instead of --theta and --tfuel")
+	sendCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
 	sendCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
 	sendCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
 	sendCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	sendCmd.Flags().StringVar(&feePayerFlag, "fee_payer", "", "Address of the fee payer, which pays the fee on behalf of the sender")
+	sendCmd.Flags().Uint64Var(&feePayerSeqFlag, "fee_payer_seq", 0, "Sequence number of the fee payer")
+	sendCmd.Flags().Uint64Var(&notAfterHeightFlag, "not_after_height", 0, "Block height after which the transaction expires, 0 for no expiry")
 
 	sendCmd.MarkFlagRequired("chain")
 	//sendCmd.MarkFlagRequired("from")
 	sendCmd.MarkFlagRequired("to")
-	sendCmd.MarkFlagRequired("seq")
 }
@@ -0,0 +1,116 @@
+package tx
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// The sequence of a transaction is optional for the tx sub commands. Without the --seq flag, the
+// next sequence of the signer is queried from the screened view of the node, which accounts for
+// the transactions accepted by its mempool. The sequences of the transactions broadcasted by
+// thetacli are also recorded locally for a while, so that transactions sent in quick succession,
+// e.g. asynchronously or through different nodes, don't reuse the same sequence.
+
+const (
+	pendingSequencesFile = "pending_sequences.json"
+	pendingSequenceTTL   = 5 * time.Minute // how long a locally recorded sequence is trusted
+)
+
+type pendingSequence struct {
+	Sequence  uint64 `json:"sequence"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// assignedSequence is the sequence assigned by getSequence, recorded once the transaction is
+// broadcasted
+var assignedSequence *struct {
+	address  common.Address
+	sequence uint64
+	cfgPath  string
+}
+
+// getSequence returns the sequence of the next transaction of the address, given by the --seq
+// flag or queried from the node.
+func getSequence(cmd *cobra.Command, address common.Address) uint64 {
+	if cmd.Flags().Changed("seq") {
+		return seqFlag
+	}
+
+	client := utils.NewRPCClient()
+	res, err := client.Call("theta.GetAccount", rpc.GetAccountArgs{Address: address.Hex(), Preview: true})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to query the sequence of %v, specify it with --seq: %v\n", address.Hex(), err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to query the sequence of %v, specify it with --seq: %v\n", address.Hex(), res.Error)
+	}
+	account := struct {
+		Sequence common.JSONUint64 `json:"sequence"`
+	}{}
+	if err := res.GetObject(&account); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+
+	cfgPath := cmd.Flag("config").Value.String()
+	sequence := uint64(account.Sequence) + 1
+	pending, ok := loadPendingSequences(cfgPath)[strings.ToLower(address.Hex())]
+	if ok && time.Since(time.Unix(pending.Timestamp, 0)) < pendingSequenceTTL && pending.Sequence >= sequence {
+		sequence = pending.Sequence + 1
+	}
+
+	assignedSequence = &struct {
+		address  common.Address
+		sequence uint64
+		cfgPath  string
+	}{address, sequence, cfgPath}
+	return sequence
+}
+
+// recordPendingSequence records the sequence assigned by getSequence after the transaction is
+// broadcasted.
+func recordPendingSequence() {
+	if assignedSequence == nil {
+		return
+	}
+	cfgPath := assignedSequence.cfgPath
+	pendings := loadPendingSequences(cfgPath)
+	now := time.Now()
+	for address, pending := range pendings {
+		if now.Sub(time.Unix(pending.Timestamp, 0)) >= pendingSequenceTTL {
+			delete(pendings, address)
+		}
+	}
+	pendings[strings.ToLower(assignedSequence.address.Hex())] = pendingSequence{
+		Sequence:  assignedSequence.sequence,
+		Timestamp: now.Unix(),
+	}
+
+	content, err := json.MarshalIndent(pendings, "", "    ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(cfgPath, 0700); err != nil {
+		return
+	}
+	// Failing to record the sequence only affects the transactions sent in quick succession
+	ioutil.WriteFile(path.Join(cfgPath, pendingSequencesFile), content, 0600)
+}
+
+func loadPendingSequences(cfgPath string) map[string]pendingSequence {
+	pendings := make(map[string]pendingSequence)
+	content, err := ioutil.ReadFile(path.Join(cfgPath, pendingSequencesFile))
+	if err != nil {
+		return pendings
+	}
+	json.Unmarshal(content, &pendings)
+	return pendings
+}
@@ -2,19 +2,14 @@ package tx
 
 import (
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/ledger/types"
-	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // smartContractCmd represents the smart_contract command. It will submit a smart contract transaction
@@ -24,6 +19,10 @@ import (
 //		thetacli tx smart_contract --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --value=1680 --gas_price=3 --gas_limit=50000 --data=600a600c600039600a6000f3600360135360016013f3 --seq=1
 //   * Call an API of a smart contract
 //		thetacli tx smart_contract --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=0x7ad6cea2bc3162e30a3c98d84f821b3233c22647 --gas_price=3 --gas_limit=50000 --seq=2
+//   * Call an API of a smart contract with the gas fee paid by a fee payer, which then co-signs the tx with the "tx sponsor" command
+//		thetacli tx smart_contract --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=0x7ad6cea2bc3162e30a3c98d84f821b3233c22647 --gas_price=3 --gas_limit=50000 --seq=2 --fee_payer=0d2fD67d573c8ecB4161510fc00754d64B401F86 --fee_payer_seq=3
+//   * Deploy a WASM smart contract, the data is the WASM module
+//		thetacli tx smart_contract --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --gas_price=3 --gas_limit=500000 --data=0061736d01000000... --seq=3 --runtime=wasm
 
 var smartContractCmd = &cobra.Command{
 	Use:   "smart_contract",
@@ -39,13 +38,32 @@ var smartContractCmd = &cobra.Command{
 }
 
 func doSmartContractCmd(cmd *cobra.Command, args []string) {
-	wallet, fromAddress, err := walletUnlock(cmd, fromFlag, passwordFlag)
+	data, err := hex.DecodeString(dataFlag)
 	if err != nil {
+		utils.Error("Failed to decode data: %v, err: %v\n", dataFlag, err)
+	}
+
+	signedTx, sponsored := signSmartContractTx(cmd, common.HexToAddress(toFlag), data)
+	if sponsored {
+		printEncodedTx(signedTx, "Transaction signed by the caller, to be co-signed by the fee payer with the \"tx sponsor\" command:\n%s\n", signedTx)
+		recordPendingSequence()
 		return
 	}
+
+	broadcastSignedTx(signedTx)
+}
+
+// signSmartContractTx signs the smart contract transaction calling the contract at the address, or
+// deploying a contract if the address is empty, with the data and the other flags of the command.
+// It returns the hex encoded transaction and whether it's to be co-signed by a fee payer.
+func signSmartContractTx(cmd *cobra.Command, toAddress common.Address, data []byte) (string, bool) {
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeWalletError, "%v\n", err)
+	}
 	defer wallet.Lock(fromAddress)
 
-	value, ok := types.ParseCoinAmount(valueFlag)
+	value, ok := types.ParseCoinAmountOf(valueFlag, types.DenomTFuel)
 	if !ok {
 		utils.Error("Failed to parse value")
 	}
@@ -56,67 +74,66 @@ func doSmartContractCmd(cmd *cobra.Command, args []string) {
 			ThetaWei: new(big.Int).SetUint64(0),
 			TFuelWei: value,
 		},
-		Sequence: seqFlag,
+		Sequence: getSequence(cmd, fromAddress),
 	}
 
 	to := types.TxOutput{
-		Address: common.HexToAddress(toFlag),
+		Address: toAddress,
 	}
 
-	gasPrice, ok := types.ParseCoinAmount(gasPriceFlag)
+	gasPrice, ok := types.ParseCoinAmountOf(gasPriceFlag, types.DenomTFuel)
 	if !ok {
 		utils.Error("Failed to parse gas price")
 	}
 
-	data, err := hex.DecodeString(dataFlag)
-	if err != nil {
-		return
-	}
-
 	smartContractTx := &types.SmartContractTx{
-		From:     from,
-		To:       to,
-		GasLimit: gasLimitFlag,
-		GasPrice: gasPrice,
-		Data:     data,
+		From:           from,
+		To:             to,
+		GasLimit:       gasLimitFlag,
+		GasPrice:       gasPrice,
+		Data:           data,
+		NotAfterHeight: notAfterHeightFlag,
 	}
 
-	sig, err := wallet.Sign(fromAddress, smartContractTx.SignBytes(chainIDFlag))
-	if err != nil {
-		utils.Error("Failed to sign transaction: %v\n", err)
+	// The gas fee of a sponsored tx is paid by the fee payer, up to the fee limit
+	sponsored := len(feePayerFlag) != 0
+	if sponsored {
+		feeLimit := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimitFlag))
+		smartContractTx.FeePayer = []types.TxInput{newFeePayerInput(feeLimit)}
 	}
-	smartContractTx.SetSignature(fromAddress, sig)
 
-	raw, err := types.TxToBytes(smartContractTx)
-	if err != nil {
-		utils.Error("Failed to encode transaction: %v\n", err)
+	var tx types.Tx = smartContractTx
+	setSignature := smartContractTx.SetSignature
+	switch runtimeFlag {
+	case "evm":
+	case "wasm":
+		// WASM contracts are deployed and called with the SmartContractTxV2, which specifies the runtime
+		smartContractTxV2 := &types.SmartContractTxV2{
+			From:           smartContractTx.From,
+			To:             smartContractTx.To,
+			GasLimit:       smartContractTx.GasLimit,
+			GasPrice:       smartContractTx.GasPrice,
+			Data:           smartContractTx.Data,
+			Runtime:        types.ContractRuntimeWASM,
+			FeePayer:       smartContractTx.FeePayer,
+			NotAfterHeight: smartContractTx.NotAfterHeight,
+		}
+		tx, setSignature = smartContractTxV2, smartContractTxV2.SetSignature
+	default:
+		utils.Error("Invalid runtime: %v\n", runtimeFlag)
 	}
-	signedTx := hex.EncodeToString(raw)
-
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
-	var res *rpcc.RPCResponse
-	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	}
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(tx, chainIDFlag))
 	if err != nil {
-		utils.Error("Failed to broadcast transaction: %v\n", err)
-	}
-	if res.Error != nil {
-		utils.Error("Server returned error: %v\n", res.Error)
-	}
-	result := &rpc.BroadcastRawTransactionResult{}
-	err = res.GetObject(result)
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n", err)
+		utils.Error("Failed to sign transaction: %v\n", err)
 	}
-	formatted, err := json.MarshalIndent(result, "", "    ")
+	setSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(tx)
 	if err != nil {
-		utils.Error("Failed to parse server response: %v\n", err)
+		utils.Error("Failed to encode transaction: %v\n", err)
 	}
-	fmt.Printf("Successfully broadcasted transaction:\n%s\n", formatted)
+	return hex.EncodeToString(raw), sponsored
 }
 
 func init() {
@@ -127,14 +144,18 @@ func init() {
 	smartContractCmd.Flags().StringVar(&gasPriceFlag, "gas_price", fmt.Sprintf("%dwei", types.MinimumGasPriceJune2021), "The gas price")
 	smartContractCmd.Flags().Uint64Var(&gasLimitFlag, "gas_limit", 0, "The gas limit")
 	smartContractCmd.Flags().StringVar(&dataFlag, "data", "", "The data for the smart contract")
-	smartContractCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	smartContractCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
 	smartContractCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	smartContractCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
 	smartContractCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
 	smartContractCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	smartContractCmd.Flags().StringVar(&feePayerFlag, "fee_payer", "", "Address of the fee payer, which pays the gas fee on behalf of the caller")
+	smartContractCmd.Flags().Uint64Var(&feePayerSeqFlag, "fee_payer_seq", 0, "Sequence number of the fee payer")
+	smartContractCmd.Flags().Uint64Var(&notAfterHeightFlag, "not_after_height", 0, "Block height after which the transaction expires, 0 for no expiry")
+	smartContractCmd.Flags().StringVar(&runtimeFlag, "runtime", "evm", "Runtime of the smart contract (evm|wasm)")
 
 	smartContractCmd.MarkFlagRequired("chain")
 	smartContractCmd.MarkFlagRequired("from")
 	smartContractCmd.MarkFlagRequired("gas_price")
 	smartContractCmd.MarkFlagRequired("gas_limit")
-	smartContractCmd.MarkFlagRequired("seq")
 }
@@ -2,18 +2,13 @@ package tx
 
 import (
 	"encoding/hex"
-	"fmt"
 	"math/big"
 	"strconv"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/ledger/types"
-	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // splitRuleCmd represents the split rule command
@@ -26,8 +21,18 @@ var splitRuleCmd = &cobra.Command{
 	Run:     doSplitRuleCmd,
 }
 
+// splitRuleRenewCmd represents the split rule renewal command
+// Example:
+//		thetacli tx split_rule_renew --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --seq=9 --resource_id=die_another_day --duration=1000
+var splitRuleRenewCmd = &cobra.Command{
+	Use:     "split_rule_renew",
+	Short:   "Extend an existing split rule",
+	Example: `thetacli tx split_rule_renew --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --seq=9 --resource_id=die_another_day --duration=1000`,
+	Run:     doSplitRuleRenewCmd,
+}
+
 func doSplitRuleCmd(cmd *cobra.Command, args []string) {
-	wallet, fromAddress, err := walletUnlock(cmd, fromFlag, passwordFlag)
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
 	if err != nil {
 		return
 	}
@@ -35,12 +40,11 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 
 	input := types.TxInput{
 		Address:  fromAddress,
-		Sequence: uint64(seqFlag),
+		Sequence: getSequence(cmd, fromAddress),
 	}
 
 	if len(addressesFlag) != len(percentagesFlag) {
-		fmt.Println("Should have the same number of addresses and percentages")
-		return
+		utils.Error("Should have the same number of addresses and percentages\n")
 	}
 	var splits []types.Split
 	for idx, addressStr := range addressesFlag {
@@ -48,14 +52,12 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 
 		address, err := hex.DecodeString(addressStr)
 		if err != nil {
-			fmt.Println("The address must be a hex string")
-			return
+			utils.Error("The address must be a hex string\n")
 		}
 
 		percentage, err := strconv.ParseUint(percentageStr, 10, 32)
 		if err != nil {
-			fmt.Println(err)
-			return
+			utils.Error("%v\n", err)
 		}
 
 		split := types.Split{
@@ -65,10 +67,7 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 		splits = append(splits, split)
 	}
 
-	fee, ok := types.ParseCoinAmount(feeFlag)
-	if !ok {
-		utils.Error("Failed to parse fee")
-	}
+	fee := getFee(types.TxSplitRule, 0)
 
 	splitRuleTx := &types.SplitRuleTx{
 		Fee: types.Coins{
@@ -81,7 +80,7 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 		Splits:     splits,
 	}
 
-	sig, err := wallet.Sign(fromAddress, splitRuleTx.SignBytes(chainIDFlag))
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(splitRuleTx, chainIDFlag))
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
@@ -93,41 +92,77 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	broadcastSignedTx(signedTx)
+}
 
-	var res *rpcc.RPCResponse
-	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+func doSplitRuleRenewCmd(cmd *cobra.Command, args []string) {
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
 	}
+	defer wallet.Lock(fromAddress)
+
+	fee := getFee(types.TxSplitRuleRenewal, 0)
+
+	splitRuleRenewalTx := &types.SplitRuleRenewalTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		ResourceID: resourceIDFlag,
+		Initiator: types.TxInput{
+			Address:  fromAddress,
+			Sequence: getSequence(cmd, fromAddress),
+		},
+		Duration: durationFlag,
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(splitRuleRenewalTx, chainIDFlag))
 	if err != nil {
-		utils.Error("Failed to broadcast transaction: %v\n", err)
+		utils.Error("Failed to sign transaction: %v\n", err)
 	}
-	if res.Error != nil {
-		utils.Error("Server returned error: %v\n", res.Error)
+	splitRuleRenewalTx.SetSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(splitRuleRenewalTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
 	}
-	fmt.Printf("Successfully broadcasted transaction.\n")
+	broadcastSignedTx(hex.EncodeToString(raw))
 }
 
 func init() {
 	splitRuleCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
 	splitRuleCmd.Flags().StringVar(&fromFlag, "from", "", "Initiator's address")
-	splitRuleCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
-	splitRuleCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWeiJune2021), "Fee")
+	splitRuleCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	splitRuleCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
 	splitRuleCmd.Flags().StringVar(&resourceIDFlag, "resource_id", "", "The resourceID of interest")
 	splitRuleCmd.Flags().StringSliceVar(&addressesFlag, "addresses", []string{}, "List of addresses participating in the split")
 	splitRuleCmd.Flags().StringSliceVar(&percentagesFlag, "percentages", []string{}, "List of integers (between 0 and 100) representing of percentage of split")
 	splitRuleCmd.Flags().Uint64Var(&durationFlag, "duration", 1000, "Reserve duration")
 	splitRuleCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	splitRuleCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
 	splitRuleCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
 	splitRuleCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
 
 	splitRuleCmd.MarkFlagRequired("chain")
 	splitRuleCmd.MarkFlagRequired("from")
-	splitRuleCmd.MarkFlagRequired("seq")
 	splitRuleCmd.MarkFlagRequired("addresses")
 	splitRuleCmd.MarkFlagRequired("percentages")
 	splitRuleCmd.MarkFlagRequired("resource_id")
 	splitRuleCmd.MarkFlagRequired("duration")
+
+	splitRuleRenewCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	splitRuleRenewCmd.Flags().StringVar(&fromFlag, "from", "", "Initiator's address")
+	splitRuleRenewCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	splitRuleRenewCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	splitRuleRenewCmd.Flags().StringVar(&resourceIDFlag, "resource_id", "", "The resourceID of the split rule")
+	splitRuleRenewCmd.Flags().Uint64Var(&durationFlag, "duration", 1000, "Number of blocks to extend the split rule by")
+	splitRuleRenewCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	splitRuleRenewCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	splitRuleRenewCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	splitRuleRenewCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	splitRuleRenewCmd.MarkFlagRequired("chain")
+	splitRuleRenewCmd.MarkFlagRequired("from")
+	splitRuleRenewCmd.MarkFlagRequired("resource_id")
 }
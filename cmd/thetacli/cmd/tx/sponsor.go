@@ -0,0 +1,108 @@
+package tx
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// sponsorCmd represents the sponsor command. A sponsored transaction is created and signed by
+// the sender with the --fee_payer flag, and then co-signed and broadcasted by the fee payer.
+// Example:
+//		thetacli tx send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --theta=10 --seq=1 --fee_payer=0d2fD67d573c8ecB4161510fc00754d64B401F86 --fee_payer_seq=3
+//		thetacli tx sponsor --chain="privatenet" --from=0d2fD67d573c8ecB4161510fc00754d64B401F86 --tx=<tx signed by the sender> --broadcast
+var sponsorCmd = &cobra.Command{
+	Use:     "sponsor",
+	Short:   "Co-sign a transaction as its fee payer",
+	Example: `thetacli tx sponsor --chain="privatenet" --from=0d2fD67d573c8ecB4161510fc00754d64B401F86 --tx=<tx signed by the sender> --broadcast`,
+	Run:     doSponsorCmd,
+}
+
+func newFeePayerInput(maxFee *big.Int) types.TxInput {
+	return types.TxInput{
+		Address: common.HexToAddress(feePayerFlag),
+		Coins: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: maxFee,
+		},
+		Sequence: feePayerSeqFlag,
+	}
+}
+
+func doSponsorCmd(cmd *cobra.Command, args []string) {
+	raw, err := hex.DecodeString(txFlag)
+	if err != nil {
+		utils.Error("Failed to decode transaction: %v\n", err)
+	}
+	tx, err := types.TxFromBytes(raw)
+	if err != nil {
+		utils.Error("Failed to decode transaction: %v\n", err)
+	}
+
+	var feePayers []types.TxInput
+	var setSignature func(addr common.Address, sig *crypto.Signature) bool
+	switch sponsoredTx := tx.(type) {
+	case *types.SendTx:
+		feePayers = sponsoredTx.FeePayer
+		setSignature = sponsoredTx.SetSignature
+	case *types.SmartContractTx:
+		feePayers = sponsoredTx.FeePayer
+		setSignature = sponsoredTx.SetSignature
+	case *types.SmartContractTxV2:
+		feePayers = sponsoredTx.FeePayer
+		setSignature = sponsoredTx.SetSignature
+	default:
+		utils.Error("Only send and smart contract transactions can be sponsored\n")
+	}
+	if len(feePayers) != 1 {
+		utils.Error("The transaction does not specify a fee payer\n")
+	}
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	if feePayers[0].Address != fromAddress {
+		utils.Error("Address %v is not the fee payer of the transaction\n", fromAddress.Hex())
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(tx, chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	setSignature(fromAddress, sig)
+
+	raw, err = types.TxToBytes(tx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	if !broadcastFlag {
+		printEncodedTx(signedTx, "%s\n", signedTx)
+		return
+	}
+
+	broadcastSignedTx(signedTx)
+}
+
+func init() {
+	sponsorCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	sponsorCmd.Flags().StringVar(&fromFlag, "from", "", "Fee payer address")
+	sponsorCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	sponsorCmd.Flags().StringVar(&txFlag, "tx", "", "Hex encoded transaction signed by the sender")
+	sponsorCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
+	sponsorCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	sponsorCmd.Flags().BoolVar(&broadcastFlag, "broadcast", false, "Broadcast the co-signed transaction")
+	sponsorCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+
+	sponsorCmd.MarkFlagRequired("chain")
+	sponsorCmd.MarkFlagRequired("tx")
+}
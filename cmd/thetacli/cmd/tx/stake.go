@@ -0,0 +1,76 @@
+package tx
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// stakeCmd groups the commands of the staking lifecycle. The stakes are queried with the
+// "query stakes" and "query validators" commands.
+var stakeCmd = &cobra.Command{
+	Use:   "stake",
+	Short: "Deposit and withdraw the stakes of validators, guardians and elite edge nodes",
+}
+
+// stakeDepositCmd represents the stake deposit command, same as the deposit command
+// Example:
+//		thetacli tx stake deposit --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=2E833968E5bB786Ae419c4d13189fB081Cc43bab --stake=6000000 --purpose=0
+var stakeDepositCmd = &cobra.Command{
+	Use:     "deposit",
+	Short:   "Deposit stake to a validator or guardian",
+	Example: `thetacli tx stake deposit --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=2E833968E5bB786Ae419c4d13189fB081Cc43bab --stake=6000000 --purpose=0`,
+	Run:     doDepositStakeCmd,
+}
+
+// stakeWithdrawCmd represents the stake withdraw command. Unlike the withdraw command, it also
+// prints the height at which the stake will be returned to the source.
+// Example:
+//		thetacli tx stake withdraw --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=2E833968E5bB786Ae419c4d13189fB081Cc43bab --purpose=0
+var stakeWithdrawCmd = &cobra.Command{
+	Use:     "withdraw",
+	Short:   "Withdraw stake from a validator or guardian",
+	Example: `thetacli tx stake withdraw --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=2E833968E5bB786Ae419c4d13189fB081Cc43bab --purpose=0`,
+	Run:     doStakeWithdrawCmd,
+}
+
+func doStakeWithdrawCmd(cmd *cobra.Command, args []string) {
+	sourceAddress := withdrawStake(cmd)
+
+	if asyncFlag {
+		fmt.Fprintf(os.Stderr, "The stake will be returned %v blocks after the transaction is included in a block\n", core.ReturnLockingPeriod)
+		return
+	}
+
+	client := utils.NewRPCClient()
+	res, err := client.Call("theta.GetPendingStakeWithdrawals", rpc.GetPendingStakeWithdrawalsArgs{Source: sourceAddress.Hex()})
+	if err == nil && res.Error == nil {
+		result := &rpc.GetPendingStakeWithdrawalsResult{}
+		if err = res.GetObject(result); err == nil {
+			holderAddress := common.HexToAddress(holderFlag)
+			for _, withdrawal := range result.Withdrawals {
+				if withdrawal.Holder == holderAddress && withdrawal.Purpose == purposeFlag {
+					fmt.Fprintf(os.Stderr, "The stake will be returned at height %v, in %v blocks\n",
+						uint64(withdrawal.ReturnHeight), uint64(withdrawal.BlocksRemaining))
+					return
+				}
+			}
+		}
+	}
+	// The withdrawal is only visible to the node once the block including it is finalized
+	fmt.Fprintf(os.Stderr, "The stake will be returned %v blocks after the transaction is included in a block, check with \"query stakes --source=%v\"\n",
+		core.ReturnLockingPeriod, sourceAddress.Hex())
+}
+
+func init() {
+	addDepositStakeFlags(stakeDepositCmd)
+	addWithdrawStakeFlags(stakeWithdrawCmd)
+
+	stakeCmd.AddCommand(stakeDepositCmd)
+	stakeCmd.AddCommand(stakeWithdrawCmd)
+}
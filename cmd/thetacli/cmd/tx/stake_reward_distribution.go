@@ -2,17 +2,12 @@ package tx
 
 import (
 	"encoding/hex"
-	"fmt"
 	"math/big"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/ledger/types"
-	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // stakeRewardDistributionCmd represents the stake reward distribution command
@@ -32,14 +27,11 @@ func doStakeRewardDistributionCmd(cmd *cobra.Command, args []string) {
 	}
 	defer wallet.Lock(holderAddress)
 
-	fee, ok := types.ParseCoinAmount(feeFlag)
-	if !ok {
-		utils.Error("Failed to parse fee")
-	}
+	fee := getFee(types.TxStakeRewardDistribution, 0)
 
 	holder := types.TxInput{
 		Address:  holderAddress,
-		Sequence: uint64(seqFlag),
+		Sequence: getSequence(cmd, holderAddress),
 	}
 	beneficiary := types.TxOutput{
 		Address: common.HexToAddress(beneficiaryFlag),
@@ -56,7 +48,7 @@ func doStakeRewardDistributionCmd(cmd *cobra.Command, args []string) {
 		//Purpose:         purposeFlag,
 	}
 
-	sig, err := wallet.Sign(holderAddress, stakeRewardDistributionTx.SignBytes(chainIDFlag))
+	sig, err := wallet.Sign(holderAddress, utils.TxSignBytes(stakeRewardDistributionTx, chainIDFlag))
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
@@ -68,29 +60,15 @@ func doStakeRewardDistributionCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
-
-	var res *rpcc.RPCResponse
-	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	}
-	if err != nil {
-		utils.Error("Failed to broadcast transaction: %v\n", err)
-	}
-	if res.Error != nil {
-		utils.Error("Server returned error: %v\n", res.Error)
-	}
-	fmt.Printf("Successfully broadcasted transaction.\n")
+	broadcastSignedTx(signedTx)
 }
 
 func init() {
 	stakeRewardDistributionCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
 	stakeRewardDistributionCmd.Flags().StringVar(&holderFlag, "holder", "", "Holder of the stake")
 	stakeRewardDistributionCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
-	stakeRewardDistributionCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWei), "Fee")
-	stakeRewardDistributionCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	stakeRewardDistributionCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	stakeRewardDistributionCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
 	stakeRewardDistributionCmd.Flags().StringVar(&beneficiaryFlag, "beneficiary", "", "Address of the beneficiary")
 	stakeRewardDistributionCmd.Flags().Uint64Var(&splitBasisPointFlag, "split_basis_point", 0, "fraction of the reward split in terms of basis point (1/10000). 100 basis point = 100/10000 = 1.00%")
 	//stakeRewardDistributionCmd.Flags().Uint8Var(&purposeFlag, "purpose", 0, "Purpose of staking")
@@ -100,5 +78,4 @@ func init() {
 
 	stakeRewardDistributionCmd.MarkFlagRequired("chain")
 	stakeRewardDistributionCmd.MarkFlagRequired("holder")
-	stakeRewardDistributionCmd.MarkFlagRequired("seq")
 }
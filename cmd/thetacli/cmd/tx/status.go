@@ -0,0 +1,94 @@
+package tx
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// statusCmd prints the status of a transaction, and its block and receipt once included in a block
+// Example:
+//		thetacli tx status 0x2fe41732b40ca852e9c36f52b278dde78f0fe34f28f9c94083112aa6a0624b8c
+var statusCmd = &cobra.Command{
+	Use:     "status <hash>",
+	Short:   "Print the status of a transaction",
+	Long:    `Print the status of a transaction, which is one of finalized, committed, pending, abandoned or not_found.`,
+	Example: `thetacli tx status 0x2fe41732b40ca852e9c36f52b278dde78f0fe34f28f9c94083112aa6a0624b8c`,
+	Args:    cobra.ExactArgs(1),
+	Run:     doStatusCmd,
+}
+
+// waitCmd waits until a transaction is finalized, and prints its block and receipt
+// Example:
+//		thetacli tx wait 0x2fe41732b40ca852e9c36f52b278dde78f0fe34f28f9c94083112aa6a0624b8c --timeout=60s
+var waitCmd = &cobra.Command{
+	Use:   "wait <hash>",
+	Short: "Wait until a transaction is finalized",
+	Long: `Wait until a transaction is finalized, and print its block and receipt. Exits with an error if
+the transaction is abandoned by the mempool, or is not finalized before the timeout.`,
+	Example: `thetacli tx wait 0x2fe41732b40ca852e9c36f52b278dde78f0fe34f28f9c94083112aa6a0624b8c --timeout=60s`,
+	Args:    cobra.ExactArgs(1),
+	Run:     doWaitCmd,
+}
+
+// waitPollInterval is the interval between the status queries of the wait command
+const waitPollInterval = time.Second
+
+// txStatusResult is the part of the theta.GetTransactionByHash result used by the wait command
+type txStatusResult struct {
+	Status        string            `json:"status"`
+	BlockHeight   common.JSONUint64 `json:"block_height"`
+	Confirmations common.JSONUint64 `json:"confirmations"`
+}
+
+func queryTxStatus(client utils.RPCClient, hash string) (interface{}, *txStatusResult) {
+	res, err := client.Call("theta.GetTransactionByHash", rpc.GetTransactionByHashArgs{Hash: hash})
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to get transaction status: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to get transaction status: %v\n", res.Error)
+	}
+	status := &txStatusResult{}
+	if err := res.GetObject(status); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	return res.Result, status
+}
+
+func doStatusCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient()
+	result, _ := queryTxStatus(client, args[0])
+	utils.PrintJSON(result)
+}
+
+func doWaitCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient()
+	hash := args[0]
+
+	// A transaction just broadcasted to another node may not be found for a while, so
+	// not_found is waited out like pending and committed
+	deadline := time.Now().Add(timeoutFlag)
+	for {
+		result, status := queryTxStatus(client, hash)
+		switch status.Status {
+		case rpc.TxStatusFinalized:
+			utils.PrintJSON(result)
+			return
+		case rpc.TxStatusAbandoned:
+			utils.ErrorWithCode(utils.ExitCodeServerError, "Transaction %v was abandoned by the mempool\n", hash)
+		}
+
+		if timeoutFlag > 0 && time.Now().After(deadline) {
+			utils.ErrorWithCode(utils.ExitCodeTimeout, "Timed out waiting for transaction %v, last status: %v\n", hash, status.Status)
+		}
+		time.Sleep(waitPollInterval)
+	}
+}
+
+func init() {
+	waitCmd.Flags().DurationVar(&timeoutFlag, "timeout", 60*time.Second, "Maximum time to wait for, 0 to wait indefinitely")
+}
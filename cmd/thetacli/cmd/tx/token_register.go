@@ -0,0 +1,89 @@
+package tx
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// tokenRegisterCmd represents the token register command
+// Example:
+//		thetacli tx token_register --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --seq=10 --namespace=theta --symbol=TDROP --name="Theta Drop" --decimals=18 --contract=0x1336739b05c7ab8a526d40dcc0d04a826b5f8b03
+var tokenRegisterCmd = &cobra.Command{
+	Use:     "token_register",
+	Short:   "Register the metadata of a token contract",
+	Example: `thetacli tx token_register --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --seq=10 --namespace=theta --symbol=TDROP --name="Theta Drop" --decimals=18 --contract=0x1336739b05c7ab8a526d40dcc0d04a826b5f8b03`,
+	Run:     doTokenRegisterCmd,
+}
+
+func doTokenRegisterCmd(cmd *cobra.Command, args []string) {
+	if err := types.ValidateTokenNamespace(namespaceFlag); err != nil {
+		utils.Error("%v\n", err)
+	}
+	if err := types.ValidateTokenSymbol(symbolFlag); err != nil {
+		utils.Error("%v\n", err)
+	}
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	fee := getFee(types.TxTokenRegistry, 0)
+
+	tokenRegistryTx := &types.TokenRegistryTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Registrar: types.TxInput{
+			Address:  fromAddress,
+			Sequence: getSequence(cmd, fromAddress),
+		},
+		Namespace:       namespaceFlag,
+		Symbol:          symbolFlag,
+		Name:            tokenNameFlag,
+		Decimals:        decimalsFlag,
+		ContractAddress: common.HexToAddress(contractFlag),
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(tokenRegistryTx, chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	tokenRegistryTx.SetSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(tokenRegistryTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	broadcastSignedTx(hex.EncodeToString(raw))
+}
+
+func init() {
+	tokenRegisterCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	tokenRegisterCmd.Flags().StringVar(&fromFlag, "from", "", "Registrar's address")
+	tokenRegisterCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	tokenRegisterCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	tokenRegisterCmd.Flags().StringVar(&namespaceFlag, "namespace", "", "Namespace to register the token under")
+	tokenRegisterCmd.Flags().StringVar(&symbolFlag, "symbol", "", "Symbol of the token")
+	tokenRegisterCmd.Flags().StringVar(&tokenNameFlag, "name", "", "Display name of the token")
+	tokenRegisterCmd.Flags().Uint8Var(&decimalsFlag, "decimals", 18, "Number of decimals of the token")
+	tokenRegisterCmd.Flags().StringVar(&contractFlag, "contract", "", "Address of the token contract")
+	tokenRegisterCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	tokenRegisterCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	tokenRegisterCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	tokenRegisterCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	tokenRegisterCmd.MarkFlagRequired("chain")
+	tokenRegisterCmd.MarkFlagRequired("from")
+	tokenRegisterCmd.MarkFlagRequired("namespace")
+	tokenRegisterCmd.MarkFlagRequired("symbol")
+	tokenRegisterCmd.MarkFlagRequired("name")
+	tokenRegisterCmd.MarkFlagRequired("contract")
+}
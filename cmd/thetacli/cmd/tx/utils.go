@@ -18,51 +18,56 @@ import (
 
 const HARDENED_FLAG = 1 << 31
 
-func walletUnlock(cmd *cobra.Command, addressStr string, password string) (wtypes.Wallet, common.Address, error) {
-	return walletUnlockWithPath(cmd, addressStr, "", password)
-}
-
 func walletUnlockWithPath(cmd *cobra.Command, addressStr string, path string, password string) (wtypes.Wallet, common.Address, error) {
 	var wallet wtypes.Wallet
 	var address common.Address
 	var err error
+	if buildMode {
+		if addressStr == "" {
+			utils.Error("The signer address is required to build the transaction\n")
+		}
+		address = common.HexToAddress(addressStr)
+		return &buildWallet{address: address}, address, nil
+	}
 	walletType := getWalletType(cmd)
 	if walletType == wtypes.WalletTypeSoft {
 		cfgPath := cmd.Flag("config").Value.String()
 		wallet, address, err = SoftWalletUnlock(cfgPath, addressStr, password)
 	} else {
-		derivationPath, err := parseDerivationPath(path, walletType)
-		if err != nil {
-			return nil, common.Address{}, err
+		var derivationPath types.DerivationPath
+		derivationPath, err = parseDerivationPath(path, walletType)
+		if err == nil {
+			wallet, address, err = ColdWalletUnlock(walletType, derivationPath)
+		}
+		if err == nil && addressStr != "" && address != common.HexToAddress(addressStr) {
+			wallet.Lock(address)
+			err = fmt.Errorf("Wallet address %v doesn't match the address %v, check the derivation path", address.Hex(), addressStr)
 		}
-		wallet, address, err = ColdWalletUnlock(walletType, derivationPath)
 	}
-	return wallet, address, err
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeWalletError, "%v\n", err)
+	}
+	return &confirmWallet{Wallet: wallet}, address, nil
 }
 
 func ColdWalletUnlock(walletType wtypes.WalletType, derivationPath types.DerivationPath) (wtypes.Wallet, common.Address, error) {
 	wallet, err := wallet.OpenWallet("", walletType, true)
 	if err != nil {
-		fmt.Printf("Failed to open wallet: %v\n", err)
-		return nil, common.Address{}, err
+		return nil, common.Address{}, fmt.Errorf("Failed to open wallet: %v", err)
 	}
 
 	err = wallet.Unlock(common.Address{}, "", derivationPath)
 	if err != nil {
-		fmt.Printf("Failed to unlock wallet: %v\n", err)
-		return nil, common.Address{}, err
+		return nil, common.Address{}, fmt.Errorf("Failed to unlock wallet: %v", err)
 	}
 
 	addresses, err := wallet.List()
 	if err != nil {
-		fmt.Printf("Failed to list wallet addresses: %v\n", err)
-		return nil, common.Address{}, err
+		return nil, common.Address{}, fmt.Errorf("Failed to list wallet addresses: %v", err)
 	}
 
 	if len(addresses) == 0 {
-		errMsg := fmt.Sprintf("No address detected in the wallet\n")
-		fmt.Printf(errMsg)
-		return nil, common.Address{}, fmt.Errorf(errMsg)
+		return nil, common.Address{}, fmt.Errorf("No address detected in the wallet")
 	}
 	address := addresses[0]
 
@@ -72,32 +77,32 @@ func ColdWalletUnlock(walletType wtypes.WalletType, derivationPath types.Derivat
 }
 
 func SoftWalletUnlock(cfgPath, addressStr string, password string) (wtypes.Wallet, common.Address, error) {
-	wallet, err := wallet.OpenWallet(cfgPath, wtypes.WalletTypeSoft, true)
+	wallet, err := wallet.OpenWalletWithKeysDir(utils.KeysDirPath(cfgPath), wtypes.WalletTypeSoft, true)
 	if err != nil {
-		fmt.Printf("Failed to open wallet: %v\n", err)
-		return nil, common.Address{}, err
+		return nil, common.Address{}, fmt.Errorf("Failed to open wallet: %v", err)
 	}
 
+	address := common.HexToAddress(addressStr)
 	if password == "" || len(password) == 0 {
 		prompt := fmt.Sprintf("Please enter password: ")
-		password, err = utils.GetPassword(prompt)
+		password, err = utils.GetWalletPassword(address, prompt)
 		if err != nil {
-			fmt.Printf("Failed to get password: %v\n", err)
-			return nil, common.Address{}, err
+			return nil, common.Address{}, fmt.Errorf("Failed to get password: %v", err)
 		}
 	}
 
-	address := common.HexToAddress(addressStr)
 	err = wallet.Unlock(address, password, nil)
 	if err != nil {
-		fmt.Printf("Failed to unlock address %v: %v\n", address.Hex(), err)
-		return nil, common.Address{}, err
+		return nil, common.Address{}, fmt.Errorf("Failed to unlock address %v: %v", address.Hex(), err)
 	}
 
 	return wallet, address, nil
 }
 
 func getWalletType(cmd *cobra.Command) (walletType wtypes.WalletType) {
+	if ledgerFlag {
+		return wtypes.WalletTypeColdNano
+	}
 	walletTypeStr := cmd.Flag("wallet").Value.String()
 	if walletTypeStr == "nano" {
 		walletType = wtypes.WalletTypeColdNano
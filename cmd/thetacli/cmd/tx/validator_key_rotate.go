@@ -0,0 +1,94 @@
+package tx
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// validatorKeyRotateCmd represents the validator key rotate command. The transaction is signed by
+// both the validator and the new signing key, which needs to be in the soft wallet, e.g. recovered
+// with "thetacli key recover --path" from the seed phrase of the node.
+// Example:
+//		thetacli tx validator_key_rotate --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --signing_address=0d2fD67d573c8ecB4161510fc00754d64B401F86 --activation_epoch=120000
+var validatorKeyRotateCmd = &cobra.Command{
+	Use:     "validator_key_rotate",
+	Short:   "Announce a new validator signing key which activates at a future epoch",
+	Example: `thetacli tx validator_key_rotate --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --signing_address=0d2fD67d573c8ecB4161510fc00754d64B401F86 --activation_epoch=120000`,
+	Run:     doValidatorKeyRotateCmd,
+}
+
+func doValidatorKeyRotateCmd(cmd *cobra.Command, args []string) {
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	fee := getFee(types.TxValidatorKeyRotation, 0)
+	signingAddress := common.HexToAddress(signingAddressFlag)
+
+	validatorKeyRotationTx := &types.ValidatorKeyRotationTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Validator: types.TxInput{
+			Address:  fromAddress,
+			Sequence: getSequence(cmd, fromAddress),
+		},
+		SigningAddress:  signingAddress,
+		ActivationEpoch: activationEpochFlag,
+	}
+	signBytes := utils.TxSignBytes(validatorKeyRotationTx, chainIDFlag)
+
+	sig, err := wallet.Sign(fromAddress, signBytes)
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	validatorKeyRotationTx.SetSignature(fromAddress, sig)
+
+	// The signing key signature can be added later by the sign command when building the transaction
+	if !buildMode {
+		cfgPath := cmd.Flag("config").Value.String()
+		signingWallet, _, err := SoftWalletUnlock(cfgPath, signingAddress.Hex(), "")
+		if err != nil {
+			utils.ErrorWithCode(utils.ExitCodeWalletError, "%v\n", err)
+		}
+		defer signingWallet.Lock(signingAddress)
+
+		signingKeySig, err := signingWallet.Sign(signingAddress, signBytes)
+		if err != nil {
+			utils.Error("Failed to sign transaction with the signing key: %v\n", err)
+		}
+		validatorKeyRotationTx.SetSignature(signingAddress, signingKeySig)
+	}
+
+	raw, err := types.TxToBytes(validatorKeyRotationTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	broadcastSignedTx(hex.EncodeToString(raw))
+}
+
+func init() {
+	validatorKeyRotateCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	validatorKeyRotateCmd.Flags().StringVar(&fromFlag, "from", "", "Validator's address")
+	validatorKeyRotateCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	validatorKeyRotateCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	validatorKeyRotateCmd.Flags().StringVar(&signingAddressFlag, "signing_address", "", "Address of the new signing key")
+	validatorKeyRotateCmd.Flags().Uint64Var(&activationEpochFlag, "activation_epoch", 0, "Epoch from which the new signing key is used")
+	validatorKeyRotateCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	validatorKeyRotateCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	validatorKeyRotateCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	validatorKeyRotateCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	validatorKeyRotateCmd.MarkFlagRequired("chain")
+	validatorKeyRotateCmd.MarkFlagRequired("from")
+	validatorKeyRotateCmd.MarkFlagRequired("signing_address")
+	validatorKeyRotateCmd.MarkFlagRequired("activation_epoch")
+}
@@ -0,0 +1,194 @@
+package tx
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// vestingTransferCmd represents the vesting transfer command
+// Example:
+//		thetacli tx vesting_transfer --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --theta=1000 --unlock_height=20000 --vesting_end_height=120000 --seq=1
+var vestingTransferCmd = &cobra.Command{
+	Use:     "vesting_transfer",
+	Short:   "Lock tokens for a beneficiary until a block height or time, with optional linear vesting",
+	Example: `thetacli tx vesting_transfer --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=9F1233798E905E173560071255140b4A8aBd3Ec6 --theta=1000 --unlock_height=20000 --vesting_end_height=120000 --seq=1`,
+	Run:     doVestingTransferCmd,
+}
+
+// vestingClaimCmd represents the vesting claim command
+// Example:
+//		thetacli tx vesting_claim --chain="privatenet" --from=9F1233798E905E173560071255140b4A8aBd3Ec6 --fund_id=0x2fe41732b40ca852e9c36f52b278dde78f0fe34f28f9c94083112aa6a0624b8c --seq=1
+var vestingClaimCmd = &cobra.Command{
+	Use:     "vesting_claim",
+	Short:   "Claim the vested tokens of a vesting fund",
+	Example: `thetacli tx vesting_claim --chain="privatenet" --from=9F1233798E905E173560071255140b4A8aBd3Ec6 --fund_id=0x2fe41732b40ca852e9c36f52b278dde78f0fe34f28f9c94083112aa6a0624b8c --seq=1`,
+	Run:     doVestingClaimCmd,
+}
+
+func doVestingTransferCmd(cmd *cobra.Command, args []string) {
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	theta := parseAmount("theta", thetaAmountFlag, types.DenomTheta)
+	tfuel := parseAmount("tfuel", tfuelAmountFlag, types.DenomTFuel)
+	fee := getFee(types.TxVestingTransfer, 0)
+
+	vestingTransferTx := &types.VestingTransferTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Source: types.TxInput{
+			Address: fromAddress,
+			Coins: types.Coins{
+				TFuelWei: new(big.Int).Add(tfuel, fee),
+				ThetaWei: theta,
+			},
+			Sequence: getSequence(cmd, fromAddress),
+		},
+		Beneficiary:      common.HexToAddress(toFlag),
+		UnlockHeight:     unlockHeightFlag,
+		UnlockTime:       unlockTimeFlag,
+		VestingEndHeight: vestingEndHeightFlag,
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(vestingTransferTx, chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	vestingTransferTx.SetSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(vestingTransferTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	broadcastSignedTx(hex.EncodeToString(raw))
+}
+
+func doVestingClaimCmd(cmd *cobra.Command, args []string) {
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	fee := getFee(types.TxVestingClaim, 0)
+	feeCoins := types.Coins{
+		ThetaWei: new(big.Int).SetUint64(0),
+		TFuelWei: fee,
+	}
+
+	vestingClaimTx := &types.VestingClaimTx{
+		Fee: feeCoins,
+		Beneficiary: types.TxInput{
+			Address:  fromAddress,
+			Coins:    feeCoins,
+			Sequence: getSequence(cmd, fromAddress),
+		},
+		FundID: common.HexToHash(fundIDFlag),
+	}
+
+	sig, err := wallet.Sign(fromAddress, utils.TxSignBytes(vestingClaimTx, chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	vestingClaimTx.SetSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(vestingClaimTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	broadcastSignedTx(hex.EncodeToString(raw))
+}
+
+// broadcastSignedTx broadcasts the signed transaction and prints the result, which includes the
+// transaction hash, and the block if the transaction was broadcasted synchronously
+func broadcastSignedTx(signedTx string) {
+	result := broadcastRawTx(signedTx)
+
+	formatted, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	utils.PrintResult(result, "Successfully broadcasted transaction:\n%s\n", formatted)
+}
+
+// broadcastRawTx broadcasts the hex encoded transaction without printing the result
+func broadcastRawTx(signedTx string) *rpc.BroadcastRawTransactionResult {
+	client := utils.NewRPCClient()
+
+	var res *rpcc.RPCResponse
+	var err error
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	}
+	if err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.ErrorWithCode(utils.ExitCodeServerError, "Server returned error: %v\n", res.Error)
+	}
+	result := &rpc.BroadcastRawTransactionResult{}
+	err = res.GetObject(result)
+	if err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	recordPendingSequence()
+	return result
+}
+
+// printEncodedTx prints a hex encoded transaction that is not broadcasted yet, e.g. to be signed
+// by the other signers
+func printEncodedTx(encodedTx string, format string, args ...interface{}) {
+	result := struct {
+		Tx string `json:"tx"`
+	}{encodedTx}
+	utils.PrintResult(result, format, args...)
+}
+
+func init() {
+	vestingTransferCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	vestingTransferCmd.Flags().StringVar(&fromFlag, "from", "", "Address to send from")
+	vestingTransferCmd.Flags().StringVar(&toFlag, "to", "", "Beneficiary address")
+	vestingTransferCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	vestingTransferCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	vestingTransferCmd.Flags().StringVar(&thetaAmountFlag, "theta", "0", "Theta amount")
+	vestingTransferCmd.Flags().StringVar(&tfuelAmountFlag, "tfuel", "0", "TFuel amount")
+	vestingTransferCmd.Flags().Uint64Var(&unlockHeightFlag, "unlock_height", 0, "Block height before which the tokens cannot be claimed")
+	vestingTransferCmd.Flags().Uint64Var(&unlockTimeFlag, "unlock_time", 0, "Unix timestamp before which the tokens cannot be claimed")
+	vestingTransferCmd.Flags().Uint64Var(&vestingEndHeightFlag, "vesting_end_height", 0, "Block height at which all the tokens are vested, 0 means no linear vesting")
+	vestingTransferCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	vestingTransferCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
+	vestingTransferCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	vestingTransferCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	vestingTransferCmd.MarkFlagRequired("chain")
+	vestingTransferCmd.MarkFlagRequired("to")
+
+	vestingClaimCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	vestingClaimCmd.Flags().StringVar(&fromFlag, "from", "", "Beneficiary address")
+	vestingClaimCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	vestingClaimCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	vestingClaimCmd.Flags().StringVar(&fundIDFlag, "fund_id", "", "ID of the vesting fund")
+	vestingClaimCmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	vestingClaimCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
+	vestingClaimCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	vestingClaimCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	vestingClaimCmd.MarkFlagRequired("chain")
+	vestingClaimCmd.MarkFlagRequired("fund_id")
+}
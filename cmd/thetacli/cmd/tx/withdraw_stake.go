@@ -2,17 +2,12 @@ package tx
 
 import (
 	"encoding/hex"
-	"fmt"
 	"math/big"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/ledger/types"
-	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // withdrawStakeCmd represents the withdraw stake command
@@ -26,20 +21,22 @@ var withdrawStakeCmd = &cobra.Command{
 }
 
 func doWithdrawStakeCmd(cmd *cobra.Command, args []string) {
+	withdrawStake(cmd)
+}
+
+// withdrawStake signs and broadcasts the withdraw stake transaction, and returns the source address
+func withdrawStake(cmd *cobra.Command) common.Address {
 	wallet, sourceAddress, err := walletUnlockWithPath(cmd, sourceFlag, pathFlag, passwordFlag)
 	if err != nil {
-		return
+		return sourceAddress
 	}
 	defer wallet.Lock(sourceAddress)
 
-	fee, ok := types.ParseCoinAmount(feeFlag)
-	if !ok {
-		utils.Error("Failed to parse fee")
-	}
+	fee := getFee(types.TxWithdrawStake, 0)
 
 	source := types.TxInput{
 		Address:  sourceAddress,
-		Sequence: uint64(seqFlag),
+		Sequence: getSequence(cmd, sourceAddress),
 	}
 	holder := types.TxOutput{
 		Address: common.HexToAddress(holderFlag),
@@ -55,7 +52,7 @@ func doWithdrawStakeCmd(cmd *cobra.Command, args []string) {
 		Purpose: purposeFlag,
 	}
 
-	sig, err := wallet.Sign(sourceAddress, withdrawStakeTx.SignBytes(chainIDFlag))
+	sig, err := wallet.Sign(sourceAddress, utils.TxSignBytes(withdrawStakeTx, chainIDFlag))
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
@@ -67,37 +64,28 @@ func doWithdrawStakeCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	broadcastSignedTx(signedTx)
+	return sourceAddress
+}
 
-	var res *rpcc.RPCResponse
-	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
-	}
-	if err != nil {
-		utils.Error("Failed to broadcast transaction: %v\n", err)
-	}
-	if res.Error != nil {
-		utils.Error("Server returned error: %v\n", res.Error)
-	}
-	fmt.Printf("Successfully broadcasted transaction.\n")
+// addWithdrawStakeFlags registers the flags of the withdraw stake commands
+func addWithdrawStakeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	cmd.Flags().StringVar(&sourceFlag, "source", "", "Source of the stake")
+	cmd.Flags().StringVar(&holderFlag, "holder", "", "Holder of the stake")
+	cmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	cmd.Flags().StringVar(&feeFlag, "fee", "", feeFlagUsage)
+	cmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, queried from the node by default")
+	cmd.Flags().Uint8Var(&purposeFlag, "purpose", 0, "Purpose of staking")
+	cmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	cmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	cmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	cmd.MarkFlagRequired("chain")
+	cmd.MarkFlagRequired("source")
+	cmd.MarkFlagRequired("holder")
 }
 
 func init() {
-	withdrawStakeCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
-	withdrawStakeCmd.Flags().StringVar(&sourceFlag, "source", "", "Source of the stake")
-	withdrawStakeCmd.Flags().StringVar(&holderFlag, "holder", "", "Holder of the stake")
-	withdrawStakeCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
-	withdrawStakeCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWeiJune2021), "Fee")
-	withdrawStakeCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
-	withdrawStakeCmd.Flags().Uint8Var(&purposeFlag, "purpose", 0, "Purpose of staking")
-	withdrawStakeCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
-	withdrawStakeCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
-	withdrawStakeCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
-
-	withdrawStakeCmd.MarkFlagRequired("chain")
-	withdrawStakeCmd.MarkFlagRequired("source")
-	withdrawStakeCmd.MarkFlagRequired("holder")
-	withdrawStakeCmd.MarkFlagRequired("seq")
+	addWithdrawStakeFlags(withdrawStakeCmd)
 }
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
+)
+
+// The address book maps names to addresses, so that accounts can be referred to by name in the
+// address flags of the commands, e.g. --from=alice --to=exchange-hot. It is stored encrypted
+// with a password, which is read from the THETACLI_ADDRESS_BOOK_PASSWORD environment variable or
+// prompted for.
+
+const (
+	addressBookFile        = "address_book.json"
+	addressBookPasswordEnv = "THETACLI_ADDRESS_BOOK_PASSWORD"
+)
+
+// The names start with a letter and are shorter than the hex addresses, so that a name can never
+// be mistaken for an address
+var addressBookNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]{0,31}$`)
+
+// AddressBook maps the lower case names to the addresses
+type AddressBook struct {
+	Entries map[string]common.Address
+
+	cfgPath  string
+	password string
+}
+
+// AddressBookEntry is an entry of the address book, also the format of the exported address books
+type AddressBookEntry struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+}
+
+// loadedAddressBook caches the address book, so that the password is asked for only once
+var loadedAddressBook *AddressBook
+
+// IsAddressBookName returns whether the string is a valid address book name
+func IsAddressBookName(name string) bool {
+	return addressBookNameRegexp.MatchString(name)
+}
+
+// AddressBookExists returns whether the address book has been created under the config path
+func AddressBookExists(cfgPath string) bool {
+	_, err := os.Stat(path.Join(cfgPath, addressBookFile))
+	return err == nil
+}
+
+// LoadAddressBook decrypts the address book under the config path, or returns an empty address
+// book if it doesn't exist yet. The password of a new address book is asked for when it is saved.
+func LoadAddressBook(cfgPath string) (*AddressBook, error) {
+	if loadedAddressBook != nil && loadedAddressBook.cfgPath == cfgPath {
+		return loadedAddressBook, nil
+	}
+
+	book := &AddressBook{
+		Entries: make(map[string]common.Address),
+		cfgPath: cfgPath,
+	}
+	content, err := ioutil.ReadFile(path.Join(cfgPath, addressBookFile))
+	if os.IsNotExist(err) {
+		return book, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the address book: %v", err)
+	}
+
+	book.password, err = getAddressBookPassword("Please enter the address book password: ")
+	if err != nil {
+		return nil, err
+	}
+	plain, err := ks.DecryptData(content, book.password)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decrypt the address book: %v", err)
+	}
+	entries := []AddressBookEntry{}
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return nil, fmt.Errorf("Failed to parse the address book: %v", err)
+	}
+	for _, entry := range entries {
+		book.Entries[strings.ToLower(entry.Name)] = entry.Address
+	}
+
+	loadedAddressBook = book
+	return book, nil
+}
+
+// Save encrypts and writes the address book
+func (book *AddressBook) Save() error {
+	if book.password == "" {
+		password, err := getAddressBookPassword("Please choose a password for the new address book: ")
+		if err != nil {
+			return err
+		}
+		if password == "" {
+			return fmt.Errorf("The address book password cannot be empty")
+		}
+		book.password = password
+	}
+
+	plain, err := json.Marshal(book.List())
+	if err != nil {
+		return err
+	}
+	content, err := ks.EncryptData(plain, book.password, ks.LightScryptN, ks.LightScryptP)
+	if err != nil {
+		return fmt.Errorf("Failed to encrypt the address book: %v", err)
+	}
+	if err := os.MkdirAll(book.cfgPath, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(book.cfgPath, addressBookFile), content, 0600)
+}
+
+// List returns the entries sorted by name
+func (book *AddressBook) List() []AddressBookEntry {
+	entries := []AddressBookEntry{}
+	for name, address := range book.Entries {
+		entries = append(entries, AddressBookEntry{Name: name, Address: address})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// NamesOf returns the names of the address
+func (book *AddressBook) NamesOf(address common.Address) []string {
+	names := []string{}
+	for _, entry := range book.List() {
+		if entry.Address == address {
+			names = append(names, entry.Name)
+		}
+	}
+	return names
+}
+
+// ResolveAddress returns the address of the name in the address book. Any other value, e.g. a hex
+// address or a guardian summary, is returned as is.
+func ResolveAddress(cfgPath string, nameOrAddress string) string {
+	if !IsAddressBookName(nameOrAddress) {
+		return nameOrAddress
+	}
+	if !AddressBookExists(cfgPath) {
+		Error("Unknown account name %v, add it with \"thetacli addressbook add\"\n", nameOrAddress)
+	}
+	book, err := LoadAddressBook(cfgPath)
+	if err != nil {
+		ErrorWithCode(ExitCodeWalletError, "%v\n", err)
+	}
+	address, ok := book.Entries[strings.ToLower(nameOrAddress)]
+	if !ok {
+		Error("Unknown account name %v, add it with \"thetacli addressbook add\"\n", nameOrAddress)
+	}
+	return address.Hex()
+}
+
+func getAddressBookPassword(prompt string) (string, error) {
+	if password, ok := os.LookupEnv(addressBookPasswordEnv); ok {
+		return password, nil
+	}
+	password, err := GetPassword(prompt)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get the address book password: %v", err)
+	}
+	return password, nil
+}
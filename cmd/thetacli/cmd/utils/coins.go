@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// FormatCoins formats the coins in units of Theta and TFuel, e.g. 10 Theta, 0.3 TFuel
+func FormatCoins(coins types.Coins) string {
+	coins = coins.NoNil()
+	if coins.ThetaWei.Sign() == 0 {
+		return FormatCoinAmount(coins.TFuelWei, types.DenomTFuel)
+	}
+	if coins.TFuelWei.Sign() == 0 {
+		return FormatCoinAmount(coins.ThetaWei, types.DenomTheta)
+	}
+	return FormatCoinAmount(coins.ThetaWei, types.DenomTheta) + ", " + FormatCoinAmount(coins.TFuelWei, types.DenomTFuel)
+}
+
+// FormatCoinAmount formats the amount in wei in units of the coin, e.g. 0.3 TFuel
+func FormatCoinAmount(amount *big.Int, denom string) string {
+	weiPerCoin := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	s := new(big.Rat).SetFrac(amount, weiPerCoin).FloatString(18)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	return fmt.Sprintf("%v %v", s, denom)
+}
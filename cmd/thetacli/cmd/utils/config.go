@@ -3,11 +3,17 @@ package utils
 import "github.com/spf13/viper"
 
 const (
-	CfgRemoteRPCEndpoint = "remoteRPCEndpoint"
-	CfgDebug             = "debug"
+	CfgRemoteRPCEndpoint  = "remoteRPCEndpoint"
+	CfgRemoteRPCEndpoints = "remoteRPCEndpoints" // takes precedence over remoteRPCEndpoint, see rpc.go
+	CfgRPCMaxLagBlocks    = "rpcMaxLagBlocks"
+	CfgRPCCrossCheck      = "rpcCrossCheck"
+	CfgDebug              = "debug"
 )
 
 func init() {
 	viper.SetDefault(CfgRemoteRPCEndpoint, "http://localhost:16888/rpc")
+	viper.SetDefault(CfgRemoteRPCEndpoints, []string{})
+	viper.SetDefault(CfgRPCMaxLagBlocks, 0)
+	viper.SetDefault(CfgRPCCrossCheck, false)
 	viper.SetDefault(CfgDebug, false)
 }
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Output formats of the commands, selected with the global --output flag
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Exit codes of the commands
+const (
+	ExitCodeError       = 1 // invalid input or other errors
+	ExitCodeWalletError = 2 // failed to open or unlock the wallet
+	ExitCodeRPCError    = 3 // failed to reach the node
+	ExitCodeServerError = 4 // the node returned an error, e.g. rejected the transaction
+	ExitCodeTimeout     = 5 // timed out waiting for the node, e.g. for a transaction to be finalized
+)
+
+var outputFormat = OutputText
+
+// SetOutputFormat sets the output format of the commands
+func SetOutputFormat(format string) error {
+	format = strings.ToLower(format)
+	if format != OutputText && format != OutputJSON {
+		return fmt.Errorf("Invalid output format %v, expected text or json", format)
+	}
+	outputFormat = format
+	return nil
+}
+
+// IsJSONOutput returns whether the commands emit JSON. In the JSON output mode the interactive
+// prompts are suppressed, and the secrets are read from the flags or line by line from stdin.
+func IsJSONOutput() bool {
+	return outputFormat == OutputJSON
+}
+
+// PrintResult prints the result of a command, encoded as JSON in the JSON output mode, otherwise
+// formatted with the given text format
+func PrintResult(result interface{}, format string, args ...interface{}) {
+	if IsJSONOutput() {
+		PrintJSON(result)
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// PrintJSON prints the result of a command as indented JSON regardless of the output format
+func PrintJSON(result interface{}) {
+	content, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		Error("Failed to encode the result: %v\n", err)
+	}
+	fmt.Println(string(content))
+}
+
+// Info prints a message for the interactive user, which is suppressed in the JSON output mode
+func Info(format string, args ...interface{}) {
+	if IsJSONOutput() {
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// ErrorWithCode prints the error message, as a JSON object in the JSON output mode, and exits
+// with the given code
+func ErrorWithCode(code int, msg string, args ...interface{}) {
+	if IsJSONOutput() {
+		PrintJSON(struct {
+			Error string `json:"error"`
+			Code  int    `json:"code"`
+		}{strings.TrimSpace(fmt.Sprintf(msg, args...)), code})
+		os.Exit(code)
+	}
+	fmt.Printf(msg, args...)
+	os.Exit(code)
+}
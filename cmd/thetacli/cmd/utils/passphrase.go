@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+)
+
+// The password of a wallet key is read from the first available of:
+//   - the --password flag of the command
+//   - the file given by the global --password_file flag
+//   - the file descriptor given by the global --password_fd flag, e.g. --password_fd=3 3<<<"$PASSWORD"
+//   - the THETACLI_WALLET_PASSWORD environment variable
+//   - the OS keychain, only with the global --keychain flag or useKeychain in the config
+//   - the prompt, which never echoes
+// The non-interactive sources allow unattended signing, e.g. by a signing service. The keychain
+// holds the passwords as generic passwords of the service "thetacli" with the key address as the
+// account, stored with
+//		security add-generic-password -s thetacli -a 0x2E833968E5bB786Ae419c4d13189fB081Cc43bab -w
+// on macOS, or with
+//		secret-tool store --label="thetacli" service thetacli account 0x2E833968E5bB786Ae419c4d13189fB081Cc43bab
+// on Linux.
+
+const (
+	CfgUseKeychain = "useKeychain"
+
+	walletPasswordEnv = "THETACLI_WALLET_PASSWORD"
+	keychainService   = "thetacli"
+)
+
+// PasswordSource holds the global flags selecting the non-interactive password sources
+type PasswordSource struct {
+	File     string
+	FD       int // negative if not set
+	Keychain bool
+}
+
+var passwordSource = PasswordSource{FD: -1}
+
+// passwordFromFD caches the password read from the file descriptor, which can only be read once
+var passwordFromFD *string
+
+func init() {
+	viper.SetDefault(CfgUseKeychain, false)
+}
+
+// SetPasswordSource sets the non-interactive sources of the wallet passwords
+func SetPasswordSource(source PasswordSource) {
+	passwordSource = source
+}
+
+// GetWalletPassword returns the password of the key of the address from the non-interactive
+// sources, or prompts for it
+func GetWalletPassword(address common.Address, prompt string) (string, error) {
+	if passwordSource.File != "" {
+		return readPasswordFile(passwordSource.File)
+	}
+	if passwordSource.FD >= 0 {
+		return readPasswordFD(passwordSource.FD)
+	}
+	if password, ok := os.LookupEnv(walletPasswordEnv); ok {
+		return password, nil
+	}
+	if passwordSource.Keychain || viper.GetBool(CfgUseKeychain) {
+		return readKeychainPassword(address)
+	}
+	return GetPassword(prompt)
+}
+
+// readPasswordFile reads the first line of the file, which must not be accessible by the other users
+func readPasswordFile(filePath string) (string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read the password file: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("The password file %v is accessible by other users, restrict it with chmod 600", filePath)
+	}
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read the password file: %v", err)
+	}
+	return firstLine(content), nil
+}
+
+func readPasswordFD(fd int) (string, error) {
+	if passwordFromFD != nil {
+		return *passwordFromFD, nil
+	}
+	file := os.NewFile(uintptr(fd), fmt.Sprintf("fd%v", fd))
+	if file == nil {
+		return "", fmt.Errorf("Invalid password file descriptor %v", fd)
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("Failed to read the password from file descriptor %v: %v", fd, err)
+	}
+	password := firstLine([]byte(line))
+	passwordFromFD = &password
+	return password, nil
+}
+
+// readKeychainPassword looks up the password of the address in the OS keychain with the command
+// line tool of the platform, so that no keychain library is linked
+func readKeychainPassword(address common.Address) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keychainService, "-a", address.Hex(), "-w")
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", keychainService, "account", address.Hex())
+	default:
+		return "", fmt.Errorf("The OS keychain is not supported on %v", runtime.GOOS)
+	}
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Failed to read the password of %v from the keychain: %v %v", address.Hex(), err, strings.TrimSpace(stderr.String()))
+	}
+	return firstLine(out), nil
+}
+
+func firstLine(content []byte) string {
+	line := string(content)
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimRight(line, "\r")
+}
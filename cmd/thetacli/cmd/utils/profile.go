@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// The profiles hold the settings of the networks in the config, e.g.
+//		profile: mainnet
+//		profiles:
+//		  mainnet:
+//		    chainID: mainnet
+//		    remoteRPCEndpoint: http://localhost:16888/rpc
+//		  testnet:
+//		    chainID: testnet
+//		    remoteRPCEndpoints:
+//		      - https://testnet-node-1.example.com/rpc
+//		      - https://testnet-node-2.example.com/rpc
+//		    keysDir: ~/.thetacli/testnet_keys
+// The profile given by the global --profile flag, or else by the profile setting, overrides the
+// top level settings. The chain ID of the profile is the default of the --chain flags, and a
+// different chain ID given with --chain is rejected, so that a transaction can't be signed for
+// another network by mistake.
+
+const (
+	CfgProfile  = "profile"
+	CfgProfiles = "profiles"
+	CfgChainID  = "chainID"
+	CfgKeysDir  = "keysDir"
+)
+
+// Profile is the settings of a network
+type Profile struct {
+	ChainID            string   `mapstructure:"chainID" json:"chain_id,omitempty"`
+	RemoteRPCEndpoint  string   `mapstructure:"remoteRPCEndpoint" json:"remote_rpc_endpoint,omitempty"`
+	RemoteRPCEndpoints []string `mapstructure:"remoteRPCEndpoints" json:"remote_rpc_endpoints,omitempty"`
+	KeysDir            string   `mapstructure:"keysDir" json:"keys_dir,omitempty"`
+}
+
+// activeProfile is the name of the profile applied to the config, if any
+var activeProfile string
+
+func init() {
+	viper.SetDefault(CfgProfile, "")
+	viper.SetDefault(CfgChainID, "")
+	viper.SetDefault(CfgKeysDir, "")
+}
+
+// Profiles returns the profiles in the config by name
+func Profiles() (map[string]Profile, error) {
+	profiles := make(map[string]Profile)
+	if err := viper.UnmarshalKey(CfgProfiles, &profiles); err != nil {
+		return nil, fmt.Errorf("Invalid profiles in the config: %v", err)
+	}
+	return profiles, nil
+}
+
+// ProfileNames returns the sorted names of the profiles in the config
+func ProfileNames() []string {
+	names := []string{}
+	for name := range viper.GetStringMap(CfgProfiles) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfile returns the name of the profile applied to the config, or an empty string
+func ActiveProfile() string {
+	return activeProfile
+}
+
+// ApplyProfile overrides the top level settings with the settings of the profile, which is the
+// profile setting of the config if the name is empty
+func ApplyProfile(name string) error {
+	if name == "" {
+		name = viper.GetString(CfgProfile)
+	}
+	if name == "" {
+		return nil
+	}
+
+	// The keys of the config are case insensitive
+	profiles, err := Profiles()
+	if err != nil {
+		return err
+	}
+	profile, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("Unknown profile %v, the config has the profiles %v", name, strings.Join(ProfileNames(), ", "))
+	}
+
+	if profile.ChainID != "" {
+		viper.Set(CfgChainID, profile.ChainID)
+	}
+	// The endpoints of the other networks at the top level must not be used as fallbacks
+	if len(profile.RemoteRPCEndpoints) > 0 {
+		viper.Set(CfgRemoteRPCEndpoints, profile.RemoteRPCEndpoints)
+	} else if profile.RemoteRPCEndpoint != "" {
+		viper.Set(CfgRemoteRPCEndpoints, []string{profile.RemoteRPCEndpoint})
+	}
+	if profile.RemoteRPCEndpoint != "" {
+		viper.Set(CfgRemoteRPCEndpoint, profile.RemoteRPCEndpoint)
+	}
+	if profile.KeysDir != "" {
+		viper.Set(CfgKeysDir, profile.KeysDir)
+	}
+	activeProfile = strings.ToLower(name)
+	return nil
+}
+
+// ApplyChainID sets the --chain flag of the command to the configured chain ID if it's not given,
+// and rejects a different chain ID
+func ApplyChainID(cmd *cobra.Command) {
+	flag := cmd.Flags().Lookup("chain")
+	chainID := viper.GetString(CfgChainID)
+	if flag == nil || chainID == "" {
+		return
+	}
+	if !flag.Changed {
+		cmd.Flags().Set("chain", chainID)
+		return
+	}
+	if flag.Value.String() != chainID {
+		source := "the config"
+		if activeProfile != "" {
+			source = fmt.Sprintf("profile %v", activeProfile)
+		}
+		Error("The chain ID %v differs from the chain ID %v of %v, select the profile of the network with --profile\n",
+			flag.Value.String(), chainID, source)
+	}
+}
+
+// KeysDirPath returns the directory of the key files, which is the keys directory under the config
+// path unless the config sets another
+func KeysDirPath(cfgPath string) string {
+	keysDir := viper.GetString(CfgKeysDir)
+	if keysDir == "" {
+		return path.Join(cfgPath, "keys")
+	}
+	if expanded, err := homedir.Expand(keysDir); err == nil {
+		return expanded
+	}
+	return keysDir
+}
@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// The commands reach the node through the RPC endpoints in the config, e.g.
+//		remoteRPCEndpoints:
+//		  - http://node-1:16888/rpc
+//		  - http://node-2:16888/rpc
+// The first reachable endpoint is used, the others are fallbacks. With rpcMaxLagBlocks set, the
+// endpoints whose latest finalized block lags behind the best endpoint by more than that are only
+// used when all the others are unreachable. With rpcCrossCheck, the results of the queries are
+// compared across all the endpoints. The global --node flag overrides the configured endpoints.
+
+const (
+	rpcDialTimeout   = 5 * time.Second
+	rpcStatusTimeout = 5 * time.Second
+)
+
+// RPCClient calls the RPC methods of the node
+type RPCClient interface {
+	Call(method string, params ...interface{}) (*rpcc.RPCResponse, error)
+}
+
+// RPCEndpoints returns the configured RPC endpoints, the primary one first
+func RPCEndpoints() []string {
+	endpoints := []string{}
+	for _, endpoint := range viper.GetStringSlice(CfgRemoteRPCEndpoints) {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if len(endpoints) == 0 {
+		endpoints = []string{viper.GetString(CfgRemoteRPCEndpoint)}
+	}
+	return endpoints
+}
+
+// NewRPCClient returns a client of the configured RPC endpoints, which fails over to the next
+// endpoint when one is unreachable
+func NewRPCClient() RPCClient {
+	endpoints := RPCEndpoints()
+	if len(endpoints) == 1 {
+		return newEndpointClient(endpoints[0], nil)
+	}
+	c := &failoverClient{
+		endpoints: endpoints,
+		clients:   make([]*rpcc.RPCClient, len(endpoints)),
+	}
+	for i, endpoint := range endpoints {
+		c.clients[i] = newEndpointClient(endpoint, nil)
+	}
+	return c
+}
+
+func newEndpointClient(endpoint string, httpClient *http.Client) *rpcc.RPCClient {
+	if httpClient == nil {
+		// Only the connection attempts time out, broadcasting a transaction may block until it is
+		// included in a block
+		httpClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy:       http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{Timeout: rpcDialTimeout}).DialContext,
+			},
+		}
+	}
+	client := rpcc.NewRPCClient(endpoint)
+	client.SetHTTPClient(httpClient)
+	return client
+}
+
+type failoverClient struct {
+	endpoints []string
+	clients   []*rpcc.RPCClient
+
+	once  sync.Once
+	order []int // indexes of the endpoints in the order they are tried
+}
+
+func (c *failoverClient) Call(method string, params ...interface{}) (*rpcc.RPCResponse, error) {
+	c.once.Do(c.orderEndpoints)
+
+	if viper.GetBool(CfgRPCCrossCheck) && isQueryMethod(method) {
+		return c.crossCheckedCall(method, params...)
+	}
+
+	var lastErr error
+	for i, idx := range c.order {
+		res, err := c.clients[idx].Call(method, params...)
+		if err == nil {
+			// Stick to the working endpoint for the following calls
+			if i > 0 {
+				c.order = append([]int{idx}, append(c.order[:i:i], c.order[i+1:]...)...)
+			}
+			return res, nil
+		}
+		if i < len(c.order)-1 {
+			fmt.Fprintf(os.Stderr, "RPC endpoint %v is unreachable: %v, trying %v\n", c.endpoints[idx], err, c.endpoints[c.order[i+1]])
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// crossCheckedCall calls the method on all the endpoints, and warns if the reachable endpoints
+// return different results. The result of the first reachable endpoint is returned.
+func (c *failoverClient) crossCheckedCall(method string, params ...interface{}) (*rpcc.RPCResponse, error) {
+	var first *rpcc.RPCResponse
+	var firstEndpoint string
+	var firstContent []byte
+	var lastErr error
+	for _, idx := range c.order {
+		res, err := c.clients[idx].Call(method, params...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "RPC endpoint %v is unreachable: %v\n", c.endpoints[idx], err)
+			lastErr = err
+			continue
+		}
+		content, err := json.Marshal(struct {
+			Result interface{}
+			Error  *rpcc.RPCError
+		}{res.Result, res.Error})
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first, firstEndpoint, firstContent = res, c.endpoints[idx], content
+			continue
+		}
+		if string(content) != string(firstContent) {
+			fmt.Fprintf(os.Stderr, "Warning: RPC endpoints %v and %v returned different results for %v\n", firstEndpoint, c.endpoints[idx], method)
+		}
+	}
+	if first == nil {
+		return nil, lastErr
+	}
+	return first, nil
+}
+
+// orderEndpoints moves the endpoints lagging behind the best endpoint by more than the configured
+// number of blocks to the end, along with the endpoints that fail to report their status
+func (c *failoverClient) orderEndpoints() {
+	c.order = make([]int, len(c.endpoints))
+	for i := range c.order {
+		c.order[i] = i
+	}
+	maxLag := viper.GetInt64(CfgRPCMaxLagBlocks)
+	if maxLag <= 0 {
+		return
+	}
+
+	heights := make([]int64, len(c.endpoints))
+	wg := &sync.WaitGroup{}
+	for i, endpoint := range c.endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			heights[i] = queryFinalizedHeight(endpoint)
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	best := int64(-1)
+	for _, height := range heights {
+		if height > best {
+			best = height
+		}
+	}
+	if best < 0 {
+		return // none reports its status, leave it to the failover
+	}
+
+	upToDate, lagging := []int{}, []int{}
+	for i, height := range heights {
+		if height < 0 || best-height > maxLag {
+			if height >= 0 {
+				fmt.Fprintf(os.Stderr, "RPC endpoint %v lags %v blocks behind, using it as a last resort\n", c.endpoints[i], best-height)
+			}
+			lagging = append(lagging, i)
+			continue
+		}
+		upToDate = append(upToDate, i)
+	}
+	c.order = append(upToDate, lagging...)
+}
+
+// endpointStatus is the part of the result of theta.GetStatus needed to rank the endpoints. The
+// rpc package cannot be imported here since it depends on this package.
+type endpointStatus struct {
+	LatestFinalizedBlockHeight common.JSONUint64 `json:"latest_finalized_block_height"`
+}
+
+// queryFinalizedHeight returns the latest finalized height reported by the endpoint, or -1 if the
+// endpoint fails to report it in time
+func queryFinalizedHeight(endpoint string) int64 {
+	client := newEndpointClient(endpoint, &http.Client{Timeout: rpcStatusTimeout})
+	res, err := client.Call("theta.GetStatus", struct{}{})
+	if err != nil || res.Error != nil {
+		return -1
+	}
+	status := &endpointStatus{}
+	if err := res.GetObject(status); err != nil {
+		return -1
+	}
+	return int64(status.LatestFinalizedBlockHeight)
+}
+
+// isQueryMethod returns whether the RPC method only reads the state of the node
+func isQueryMethod(method string) bool {
+	return strings.HasPrefix(method, "theta.Get")
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// Once the chain ID replay protection is active on the network, the transactions are signed over
+// the sign bytes committing to the numeric chain ID, i.e. in the v3 Ethereum tx wrapper. Whether
+// it is active, along with the numeric chain ID, is queried from the node once per command. Without
+// the node, e.g. when building a transaction offline, the transactions are signed over the legacy
+// sign bytes.
+
+// chainParams is the part of the result of theta.GetChainID needed to sign the transactions. The
+// rpc package cannot be imported here since it depends on this package.
+type chainParams struct {
+	ChainID                string          `json:"chain_id"`
+	NumericChainID         *common.JSONBig `json:"numeric_chain_id"`
+	ReplayProtectionActive bool            `json:"replay_protection_active"`
+}
+
+var (
+	chainParamsOnce sync.Once
+	nodeChainParams *chainParams // nil if the node cannot be reached
+)
+
+// TxSignBytes returns the bytes of the transaction to be signed for the chain
+func TxSignBytes(tx types.Tx, chainID string) common.Bytes {
+	signBytes := tx.SignBytes(chainID)
+	if params := queryChainParams(chainID); params != nil && params.ReplayProtectionActive {
+		return types.ReplayProtectedSignBytes(signBytes, NumericChainID(chainID))
+	}
+	return signBytes
+}
+
+// NumericChainID returns the numeric chain ID the node reports for the chain, or nil if the node
+// cannot be reached or is on another chain, in which case the numeric chain ID is derived from the
+// chain ID, see types.ReplayProtectedSignBytes.
+func NumericChainID(chainID string) *big.Int {
+	params := queryChainParams(chainID)
+	if params == nil || params.ChainID != chainID || params.NumericChainID == nil {
+		return nil
+	}
+	return params.NumericChainID.ToInt()
+}
+
+func queryChainParams(chainID string) *chainParams {
+	chainParamsOnce.Do(func() {
+		client := NewRPCClient()
+		res, err := client.Call("theta.GetChainID", struct{}{})
+		if err == nil && res.Error == nil {
+			params := &chainParams{}
+			if err = res.GetObject(params); err == nil {
+				if params.ChainID != chainID {
+					fmt.Fprintf(os.Stderr, "Warning: signing for chain %v, but the node is on chain %v\n", chainID, params.ChainID)
+				}
+				nodeChainParams = params
+				return
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Failed to query the chain ID of the node, signing without the replay protection\n")
+	})
+	return nodeChainParams
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"unicode"
 
 	"github.com/bgentry/speakeasy"
 	isatty "github.com/mattn/go-isatty"
@@ -12,10 +13,15 @@ import (
 
 var buf *bufio.Reader
 
+// GetPassword reads a secret, without echoing it on a terminal. In the JSON output mode the prompt
+// goes to stderr, and without a terminal the secret is read line by line from stdin.
 func GetPassword(prompt string) (password string, err error) {
-	if inputIsTty() {
+	switch {
+	case inputIsTty() && IsJSONOutput():
+		password, err = speakeasy.FAsk(os.Stderr, prompt)
+	case inputIsTty():
 		password, err = speakeasy.Ask(prompt)
-	} else {
+	default:
 		password, err = stdinLine()
 	}
 	return
@@ -42,6 +48,49 @@ func stdinLine() (string, error) {
 }
 
 func Error(msg string, args ...interface{}) {
-	fmt.Printf(msg, args...)
-	os.Exit(1)
+	ErrorWithCode(ExitCodeError, msg, args...)
+}
+
+// MinPasswordLength is the minimum length of the passwords of the new keys
+const MinPasswordLength = 8
+
+// commonPasswords are rejected regardless of their length and character classes
+var commonPasswords = map[string]bool{
+	"password": true, "password1": true, "password123": true, "passw0rd": true, "p@ssw0rd": true,
+	"12345678": true, "123456789": true, "1234567890": true, "qwertyuiop": true, "qwerty123": true,
+	"iloveyou": true, "letmein1": true, "11111111": true, "00000000": true, "abcd1234": true,
+	"theta123": true, "thetatoken": true, "tfuel123": true,
+}
+
+// CheckPasswordStrength returns an error if the password is too weak to protect a key. The
+// passwords shorter than 16 characters need at least 3 of lowercase and uppercase letters,
+// digits and symbols.
+func CheckPasswordStrength(password string) error {
+	if len([]rune(password)) < MinPasswordLength {
+		return fmt.Errorf("The password must have at least %v characters", MinPasswordLength)
+	}
+	if commonPasswords[strings.ToLower(password)] {
+		return fmt.Errorf("The password is too common")
+	}
+	if strings.Count(password, password[:1]) == len(password) {
+		return fmt.Errorf("The password must not repeat a single character")
+	}
+
+	var lower, upper, digit, symbol int
+	for _, c := range password {
+		switch {
+		case unicode.IsLower(c):
+			lower = 1
+		case unicode.IsUpper(c):
+			upper = 1
+		case unicode.IsDigit(c):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	if len([]rune(password)) < 16 && lower+upper+digit+symbol < 3 {
+		return fmt.Errorf("The password must have at least 16 characters, or 3 of lowercase and uppercase letters, digits and symbols")
+	}
+	return nil
 }
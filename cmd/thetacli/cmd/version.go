@@ -1,9 +1,8 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/version"
 )
 
@@ -19,5 +18,10 @@ func init() {
 }
 
 func runVersion(cmd *cobra.Command, args []string) {
-	fmt.Printf("Version %v %s\nBuilt at %s\n", version.Version, version.GitHash, version.Timestamp)
+	result := struct {
+		Version   string `json:"version"`
+		GitHash   string `json:"git_hash"`
+		Timestamp string `json:"timestamp"`
+	}{version.Version, version.GitHash, version.Timestamp}
+	utils.PrintResult(result, "Version %v %s\nBuilt at %s\n", version.Version, version.GitHash, version.Timestamp)
 }
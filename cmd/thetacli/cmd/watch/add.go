@@ -0,0 +1,28 @@
+package watch
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+)
+
+// addCmd adds a watch-only address to the watch list
+var addCmd = &cobra.Command{
+	Use:     "add <address>",
+	Short:   "Add a watch-only address to the watch list",
+	Long:    `Add a watch-only address to the watch list, or update its label if it is already watched. No key is needed.`,
+	Example: "thetacli watch add 26d813157F7503a9057FB2DB6Eb2f83a35c4FdD7 --label=treasury",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entry := WatchListEntry{Address: parseAddress(cmd, args[0]), Label: labelFlag}
+
+		list := loadWatchList(cmd)
+		list.Put(entry)
+		saveWatchList(list)
+
+		utils.PrintResult(entry, "Watching %v\n", entry.Address.Hex())
+	},
+}
+
+func init() {
+	addCmd.Flags().StringVar(&labelFlag, "label", "", "Label of the address shown by the watch command")
+}
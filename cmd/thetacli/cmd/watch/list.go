@@ -0,0 +1,54 @@
+package watch
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// listCmd lists the watch-only addresses, optionally with their balances
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the watch-only addresses",
+	Example: "thetacli watch list --balances",
+	Run: func(cmd *cobra.Command, args []string) {
+		entries := loadWatchList(cmd).Entries
+
+		type listEntry struct {
+			WatchListEntry
+			Balance *types.Coins `json:"balance,omitempty"`
+		}
+		result := []listEntry{}
+		for _, entry := range entries {
+			item := listEntry{WatchListEntry: entry}
+			if balancesFlag {
+				balance, err := queryBalance(entry.Address)
+				if err != nil {
+					utils.ErrorWithCode(utils.ExitCodeRPCError, "Failed to query the balance of %v: %v\n", entry.Address.Hex(), err)
+				}
+				item.Balance = &balance
+			}
+			result = append(result, item)
+		}
+
+		if utils.IsJSONOutput() {
+			utils.PrintJSON(struct {
+				Entries []listEntry `json:"entries"`
+			}{result})
+			return
+		}
+		for _, item := range result {
+			if item.Balance == nil {
+				fmt.Printf("%v %v\n", item.Address.Hex(), item.Label)
+				continue
+			}
+			fmt.Printf("%v %-24v %v\n", item.Address.Hex(), item.Label, utils.FormatCoins(*item.Balance))
+		}
+	},
+}
+
+func init() {
+	listCmd.Flags().BoolVar(&balancesFlag, "balances", false, "Query the balances of the addresses")
+}
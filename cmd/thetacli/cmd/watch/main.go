@@ -0,0 +1,63 @@
+package watch
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+)
+
+// Flags for the watch commands
+var (
+	labelFlag      string
+	balancesFlag   bool
+	wsEndpointFlag string
+)
+
+// WatchCmd streams the balance changes and the transactions of the watch-only addresses.
+// Example:
+//		thetacli watch add 26d813157F7503a9057FB2DB6Eb2f83a35c4FdD7 --label=treasury
+//		thetacli watch
+var WatchCmd = &cobra.Command{
+	Use:   "watch [address...]",
+	Short: "Monitor watch-only addresses",
+	Long: `Monitor watch-only addresses, i.e. accounts whose keys are not in the wallet, e.g. treasury accounts.
+Without a sub command, the balance changes and the pending and confirmed transactions of the given addresses,
+or of the addresses in the watch list, are streamed from the subscription endpoint of the node until interrupted.
+In the JSON output mode each event is printed as a JSON object on its own line.`,
+	Example: `thetacli watch
+thetacli watch 26d813157F7503a9057FB2DB6Eb2f83a35c4FdD7 treasury-cold`,
+	Run: doWatchCmd,
+}
+
+func init() {
+	WatchCmd.Flags().StringVar(&wsEndpointFlag, "ws_endpoint", "", "Subscription endpoint of the node, derived from the RPC endpoints by default")
+
+	WatchCmd.AddCommand(addCmd)
+	WatchCmd.AddCommand(removeCmd)
+	WatchCmd.AddCommand(listCmd)
+}
+
+func loadWatchList(cmd *cobra.Command) *WatchList {
+	cfgPath := cmd.Flag("config").Value.String()
+	list, err := LoadWatchList(cfgPath)
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+	return list
+}
+
+func saveWatchList(list *WatchList) {
+	if err := list.Save(); err != nil {
+		utils.Error("Failed to save the watch list: %v\n", err)
+	}
+}
+
+// parseAddress parses an address argument, which can also be a name in the address book
+func parseAddress(cmd *cobra.Command, arg string) common.Address {
+	cfgPath := cmd.Flag("config").Value.String()
+	resolved := utils.ResolveAddress(cfgPath, arg)
+	if !common.IsHexAddress(resolved) {
+		utils.Error("Invalid address %v\n", arg)
+	}
+	return common.HexToAddress(resolved)
+}
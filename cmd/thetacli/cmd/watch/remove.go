@@ -0,0 +1,27 @@
+package watch
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+)
+
+// removeCmd removes a watch-only address from the watch list
+var removeCmd = &cobra.Command{
+	Use:     "remove <address>",
+	Short:   "Remove a watch-only address from the watch list",
+	Example: "thetacli watch remove 26d813157F7503a9057FB2DB6Eb2f83a35c4FdD7",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		address := parseAddress(cmd, args[0])
+
+		list := loadWatchList(cmd)
+		entry, ok := list.Find(address)
+		if !ok {
+			utils.Error("Address %v is not in the watch list\n", address.Hex())
+		}
+		list.Remove(address)
+		saveWatchList(list)
+
+		utils.PrintResult(entry, "Removed %v\n", address.Hex())
+	},
+}
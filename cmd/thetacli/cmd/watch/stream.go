@@ -0,0 +1,329 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+	"golang.org/x/net/websocket"
+)
+
+// The watch command holds a connection to the subscription endpoint of the node. The pending
+// transactions of the watched addresses are reported as they enter the mempool of the node, and
+// confirmed once a finalized block includes them. The balances are queried again on each finalized
+// block, so that the changes made by any transaction, e.g. the returned stakes, are reported too.
+
+const (
+	watchReadTimeout    = 90 * time.Second // three heartbeats of the node by default
+	watchReconnectDelay = 5 * time.Second
+	pendingTxExpiry     = 30 * time.Minute
+)
+
+// Events reported by the watch command
+const (
+	eventBalance     = "balance"
+	eventPendingTx   = "pending_tx"
+	eventConfirmedTx = "confirmed_tx"
+)
+
+// watchEvent is printed as a JSON object on its own line in the JSON output mode
+type watchEvent struct {
+	Event     string            `json:"event"`
+	Height    common.JSONUint64 `json:"height,omitempty"`
+	Address   *common.Address   `json:"address,omitempty"` // the watched address, if known
+	Label     string            `json:"label,omitempty"`
+	TxHash    *common.Hash      `json:"hash,omitempty"`
+	From      *common.Address   `json:"from,omitempty"`
+	Direction string            `json:"direction,omitempty"` // incoming or outgoing
+	Change    *types.Coins      `json:"change,omitempty"`
+	Balance   *types.Coins      `json:"balance,omitempty"`
+}
+
+// subscriptionMessage is any of the responses, notifications and heartbeats of the subscription
+// endpoint
+type subscriptionMessage struct {
+	ID        json.RawMessage   `json:"id"`
+	Result    json.RawMessage   `json:"result"`
+	Error     string            `json:"error"`
+	Topic     string            `json:"topic"`
+	Heartbeat common.JSONUint64 `json:"heartbeat"`
+}
+
+type pendingTx struct {
+	event  watchEvent
+	seenAt time.Time
+}
+
+type watcher struct {
+	entries  []WatchListEntry
+	balances map[common.Address]types.Coins
+	pending  map[common.Hash]*pendingTx
+}
+
+func doWatchCmd(cmd *cobra.Command, args []string) {
+	list := loadWatchList(cmd)
+	entries := list.Entries
+	if len(args) > 0 {
+		entries = []WatchListEntry{}
+		for _, arg := range args {
+			address := parseAddress(cmd, arg)
+			entry, ok := list.Find(address)
+			if !ok {
+				entry = WatchListEntry{Address: address}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		utils.Error("No addresses to watch, add them with \"thetacli watch add\"\n")
+	}
+
+	endpoints := []string{wsEndpointFlag}
+	if wsEndpointFlag == "" {
+		endpoints = []string{}
+		for _, rpcEndpoint := range utils.RPCEndpoints() {
+			endpoint, err := subscriptionEndpoint(rpcEndpoint)
+			if err != nil {
+				utils.Error("%v\n", err)
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	w := &watcher{
+		entries:  entries,
+		balances: make(map[common.Address]types.Coins),
+		pending:  make(map[common.Hash]*pendingTx),
+	}
+	if err := w.refreshBalances(0); err != nil {
+		utils.ErrorWithCode(utils.ExitCodeRPCError, "%v\n", err)
+	}
+	// Reconnect to the next endpoint, if any, when the connection is lost
+	for i := 0; ; i = (i + 1) % len(endpoints) {
+		err := w.stream(endpoints[i])
+		fmt.Fprintf(os.Stderr, "Lost the connection to %v: %v, reconnecting in %v\n", endpoints[i], err, watchReconnectDelay)
+		time.Sleep(watchReconnectDelay)
+	}
+}
+
+// subscriptionEndpoint derives the URL of the subscription endpoint from the RPC endpoint, e.g.
+// ws://localhost:16888/ws/subscribe from http://localhost:16888/rpc
+func subscriptionEndpoint(rpcEndpoint string) (string, error) {
+	u, err := url.Parse(rpcEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("Invalid remote RPC endpoint %v: %v", rpcEndpoint, err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("Can't derive the subscription endpoint from %v, specify it with --ws_endpoint", rpcEndpoint)
+	}
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/rpc") + "/ws/subscribe"
+	return u.String(), nil
+}
+
+// stream subscribes to the finalized blocks and the pending transactions of the watched addresses,
+// and reports the events until the connection is lost
+func (w *watcher) stream(endpoint string) error {
+	ws, err := websocket.Dial(endpoint, "", "http://localhost/")
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	filter, err := json.Marshal(rpc.PendingTxFilter{Addresses: w.addresses()})
+	if err != nil {
+		return err
+	}
+	requests := []rpc.SubscriptionRequest{
+		{ID: json.RawMessage("1"), Method: "subscribe", Topic: rpc.SubscriptionTopicNewBlocks},
+		{ID: json.RawMessage("2"), Method: "subscribe", Topic: rpc.SubscriptionTopicPendingTxs, Filter: filter},
+	}
+	for _, req := range requests {
+		if err := websocket.JSON.Send(ws, req); err != nil {
+			return err
+		}
+	}
+
+	for {
+		ws.SetReadDeadline(time.Now().Add(watchReadTimeout))
+		msg := &subscriptionMessage{}
+		if err := websocket.JSON.Receive(ws, msg); err != nil {
+			return err
+		}
+
+		switch {
+		case len(msg.ID) > 0:
+			if msg.Error != "" {
+				utils.ErrorWithCode(utils.ExitCodeServerError, "Failed to subscribe: %v\n", msg.Error)
+			}
+		case msg.Error != "":
+			return fmt.Errorf("%v", msg.Error)
+		case msg.Topic == rpc.SubscriptionTopicNewBlocks:
+			block := &rpc.BlockEntry{}
+			if err := json.Unmarshal(msg.Result, block); err != nil {
+				return fmt.Errorf("Failed to parse block: %v", err)
+			}
+			w.handleBlock(block)
+		case msg.Topic == rpc.SubscriptionTopicPendingTxs:
+			entry := &rpc.PendingTxEntry{}
+			if err := json.Unmarshal(msg.Result, entry); err != nil {
+				return fmt.Errorf("Failed to parse pending transaction: %v", err)
+			}
+			w.handlePendingTx(entry)
+		}
+	}
+}
+
+func (w *watcher) addresses() []common.Address {
+	addresses := []common.Address{}
+	for _, entry := range w.entries {
+		addresses = append(addresses, entry.Address)
+	}
+	return addresses
+}
+
+func (w *watcher) find(address common.Address) (WatchListEntry, bool) {
+	for _, entry := range w.entries {
+		if entry.Address == address {
+			return entry, true
+		}
+	}
+	return WatchListEntry{}, false
+}
+
+// handlePendingTx reports a pending transaction sent from or to a watched address. The entry only
+// carries the sender, so the recipient of an incoming transaction shows in the balance changes.
+func (w *watcher) handlePendingTx(entry *rpc.PendingTxEntry) {
+	if _, ok := w.pending[entry.TxHash]; ok {
+		return
+	}
+	hash, from := entry.TxHash, entry.Address
+	event := watchEvent{Event: eventPendingTx, TxHash: &hash, From: &from, Direction: "incoming"}
+	description := fmt.Sprintf("from %v", from.Hex())
+	if sender, ok := w.find(from); ok {
+		event.Address = &from
+		event.Label = sender.Label
+		event.Direction = "outgoing"
+		description = fmt.Sprintf("from %v", sender.Name())
+	}
+	w.pending[hash] = &pendingTx{event: event, seenAt: time.Now()}
+
+	printEvent(&event, "Pending %v tx %v %v\n", event.Direction, hash.Hex(), description)
+}
+
+func (w *watcher) handleBlock(block *rpc.BlockEntry) {
+	for _, hash := range block.TxHashes {
+		tx, ok := w.pending[hash]
+		if !ok {
+			continue
+		}
+		delete(w.pending, hash)
+		event := tx.event
+		event.Event = eventConfirmedTx
+		event.Height = block.Height
+		printEvent(&event, "[%v] Confirmed %v tx %v\n", block.Height, event.Direction, hash.Hex())
+	}
+	for hash, tx := range w.pending {
+		if time.Since(tx.seenAt) > pendingTxExpiry {
+			delete(w.pending, hash)
+		}
+	}
+
+	if err := w.refreshBalances(block.Height); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+}
+
+// refreshBalances queries the balances of the watched addresses and reports the changes. All the
+// balances are reported on the first query.
+func (w *watcher) refreshBalances(height common.JSONUint64) error {
+	for _, entry := range w.entries {
+		balance, err := queryBalance(entry.Address)
+		if err != nil {
+			return fmt.Errorf("Failed to query the balance of %v: %v", entry.Address.Hex(), err)
+		}
+		previous, known := w.balances[entry.Address]
+		if known && previous.IsEqual(balance) {
+			continue
+		}
+		w.balances[entry.Address] = balance
+
+		address := entry.Address
+		event := watchEvent{Event: eventBalance, Height: height, Address: &address, Label: entry.Label, Balance: &balance}
+		if !known {
+			printEvent(&event, "%v: %v\n", entry.Name(), utils.FormatCoins(balance))
+			continue
+		}
+		change := balance.Minus(previous)
+		event.Change = &change
+		printEvent(&event, "[%v] %v: %v, balance %v\n", height, entry.Name(), formatChange(change), utils.FormatCoins(balance))
+	}
+	return nil
+}
+
+// queryBalance returns the balance of the address
+func queryBalance(address common.Address) (types.Coins, error) {
+	client := utils.NewRPCClient()
+	res, err := client.Call("theta.GetAccount", rpc.GetAccountArgs{Address: address.Hex()})
+	if err != nil {
+		return types.Coins{}, err
+	}
+	if res.Error != nil {
+		// The account doesn't exist until it receives any coins
+		if res.Error.Code == rpc.ErrCodeNotFound {
+			return types.NewCoins(0, 0), nil
+		}
+		return types.Coins{}, res.Error
+	}
+	account := struct {
+		Balance types.Coins `json:"coins"`
+	}{}
+	if err := res.GetObject(&account); err != nil {
+		return types.Coins{}, fmt.Errorf("Failed to parse server response: %v", err)
+	}
+	return account.Balance.NoNil(), nil
+}
+
+// formatChange formats the signed amounts of a balance change, e.g. +10 Theta, -0.3 TFuel
+func formatChange(change types.Coins) string {
+	parts := []string{}
+	for _, c := range []struct {
+		amount *big.Int
+		denom  string
+	}{{change.ThetaWei, types.DenomTheta}, {change.TFuelWei, types.DenomTFuel}} {
+		switch c.amount.Sign() {
+		case 1:
+			parts = append(parts, "+"+utils.FormatCoinAmount(c.amount, c.denom))
+		case -1:
+			parts = append(parts, utils.FormatCoinAmount(c.amount, c.denom))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printEvent prints the event as a single line of JSON in the JSON output mode, otherwise
+// formatted with the given text format
+func printEvent(event *watchEvent, format string, args ...interface{}) {
+	if !utils.IsJSONOutput() {
+		fmt.Printf(format, args...)
+		return
+	}
+	content, err := json.Marshal(event)
+	if err != nil {
+		utils.Error("Failed to encode the event: %v\n", err)
+	}
+	fmt.Println(string(content))
+}
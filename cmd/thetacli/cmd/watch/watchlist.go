@@ -0,0 +1,102 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// The watch list holds the watch-only addresses, i.e. the accounts monitored by the watch command
+// whose keys are not in the wallet, e.g. the treasury accounts. It only contains public data, so
+// unlike the address book it is stored unencrypted.
+
+const watchListFile = "watch_list.json"
+
+// WatchListEntry is a watch-only address with an optional label
+type WatchListEntry struct {
+	Address common.Address `json:"address"`
+	Label   string         `json:"label,omitempty"`
+}
+
+// Name returns the label of the address, or the address itself if it is not labelled
+func (entry WatchListEntry) Name() string {
+	if entry.Label == "" {
+		return entry.Address.Hex()
+	}
+	return entry.Label
+}
+
+// WatchList is the list of watch-only addresses under the config path
+type WatchList struct {
+	Entries []WatchListEntry
+
+	cfgPath string
+}
+
+// LoadWatchList reads the watch list under the config path, or returns an empty watch list if it
+// doesn't exist yet
+func LoadWatchList(cfgPath string) (*WatchList, error) {
+	list := &WatchList{
+		Entries: []WatchListEntry{},
+		cfgPath: cfgPath,
+	}
+	content, err := ioutil.ReadFile(path.Join(cfgPath, watchListFile))
+	if os.IsNotExist(err) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the watch list: %v", err)
+	}
+	if err := json.Unmarshal(content, &list.Entries); err != nil {
+		return nil, fmt.Errorf("Failed to parse the watch list: %v", err)
+	}
+	return list, nil
+}
+
+// Save writes the watch list
+func (list *WatchList) Save() error {
+	content, err := json.MarshalIndent(list.Entries, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(list.cfgPath, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(list.cfgPath, watchListFile), content, 0600)
+}
+
+// Find returns the entry of the address
+func (list *WatchList) Find(address common.Address) (WatchListEntry, bool) {
+	for _, entry := range list.Entries {
+		if entry.Address == address {
+			return entry, true
+		}
+	}
+	return WatchListEntry{}, false
+}
+
+// Put adds the address to the watch list, or updates its label if it is already watched
+func (list *WatchList) Put(entry WatchListEntry) {
+	for i := range list.Entries {
+		if list.Entries[i].Address == entry.Address {
+			list.Entries[i].Label = entry.Label
+			return
+		}
+	}
+	list.Entries = append(list.Entries, entry)
+}
+
+// Remove removes the address from the watch list, it returns false if the address is not watched
+func (list *WatchList) Remove(address common.Address) bool {
+	for i, entry := range list.Entries {
+		if entry.Address == address {
+			list.Entries = append(list.Entries[:i], list.Entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
@@ -47,7 +48,7 @@ type ThetaCliRPCServer struct {
 
 // NewThetaCliRPCServer creates a new instance of ThetaRPCServer.
 func NewThetaCliRPCServer(cfgPath, port string) (*ThetaCliRPCServer, error) {
-	wallet, err := wl.OpenWallet(cfgPath, wt.WalletTypeSoft, true)
+	wallet, err := wl.OpenWalletWithKeysDir(utils.KeysDirPath(cfgPath), wt.WalletTypeSoft, true)
 	if err != nil {
 		fmt.Printf("Failed to open wallet: %v\n", err)
 		return nil, err
@@ -6,9 +6,6 @@ import (
 	"math/big"
 	"strconv"
 
-	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
-
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/core"
@@ -89,7 +86,7 @@ func (t *ThetaCliRPCService) Send(args *SendArgs, result *SendResult) (err error
 		Outputs: outputs,
 	}
 
-	signBytes := sendTx.SignBytes(args.ChainID)
+	signBytes := utils.TxSignBytes(sendTx, args.ChainID)
 	sig, err := t.wallet.Sign(from, signBytes)
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
@@ -102,7 +99,7 @@ func (t *ThetaCliRPCService) Send(args *SendArgs, result *SendResult) (err error
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient()
 
 	rpcMethod := "theta.BroadcastRawTransaction"
 	if args.Async {
@@ -14,8 +14,26 @@ const (
 	// CfgKeyPath defines custom key path
 	CfgKeyPath = "key.path"
 
+	// CfgHSMEnabled sets whether the validator signs with a key held by an HSM through PKCS#11.
+	// The key file under key.path remains the identity of the node on the P2P network.
+	CfgHSMEnabled = "hsm.enabled"
+	// CfgHSMLibrary is the path of the PKCS#11 module of the HSM
+	CfgHSMLibrary = "hsm.library"
+	// CfgHSMTokenLabel is the label of the HSM token holding the key
+	CfgHSMTokenLabel = "hsm.tokenLabel"
+	// CfgHSMKeyLabel is the label of the validator key pair on the token
+	CfgHSMKeyLabel = "hsm.keyLabel"
+	// CfgHSMPinFile is the file containing the PIN of the token. The PIN is read from the
+	// THETA_HSM_PIN environment variable if not set.
+	CfgHSMPinFile = "hsm.pinFile"
+	// CfgHSMHealthCheckInterval sets the interval (in seconds) of the HSM health checks
+	CfgHSMHealthCheckInterval = "hsm.healthCheckInterval"
+
 	// CfgNodeType indicates the type of the node, e.g. blockchain node/edge node
 	CfgNodeType = "node.type"
+	// CfgNodeShutdownTimeoutSecs sets the deadline (in seconds) of the graceful shutdown, the node exits
+	// without closing the databases if the block being processed is not done by then.
+	CfgNodeShutdownTimeoutSecs = "node.shutdownTimeoutSecs"
 	// CfgForceValidateSnapshot defines wether validation of snapshot can be skipped
 	CfgForceValidateSnapshot = "snapshot.force_validate"
 
@@ -34,6 +52,9 @@ const (
 	CfgConsensusEdgeNodeVoteQueueSize = "consensus.edgeNodeVoteQueueSize"
 	// CfgConsensusPassThroughGuardianVote defines the how guardian vote is handled.
 	CfgConsensusPassThroughGuardianVote = "consensus.passThroughGuardianVote"
+	// CfgConsensusValidatorAddress defines the validator address the node votes and proposes for, in case the
+	// node signs with a rotated signing key. Defaults to the address of the signing key.
+	CfgConsensusValidatorAddress = "consensus.validatorAddress"
 
 	// CfgStorageRollingEnabled indicates whether rolling is enabled
 	CfgStorageRollingEnabled = "storage.stateRollingEnabled"
@@ -49,8 +70,19 @@ const (
 	CfgStorageLevelDBCacheSize = "storage.levelDBCacheSize"
 	// CfgStorageLevelDBHandles indicates Level DB handle count
 	CfgStorageLevelDBHandles = "storage.levelDBHandles"
+	// CfgStorageStateCacheSize indicates the memory budget of the trie node and account caches of the state in MB, 0 to disable the caches
+	CfgStorageStateCacheSize = "storage.stateCacheSize"
 	// CfgStorageRollingInterval is the block interval that we start new db layer
 	CfgStorageRollingInterval = "storage.rollingInterval"
+	// CfgStorageReadOnly indicates whether the node serves queries from checkpoints of the data directory of another node
+	CfgStorageReadOnly = "storage.readOnly"
+	// CfgStorageReadOnlyRefreshInterval is the interval in seconds at which a read-only node takes a new checkpoint of the data directory
+	CfgStorageReadOnlyRefreshInterval = "storage.readOnlyRefreshInterval"
+	// CfgStorageSearchIndexEnabled indicates whether the transactions and events of the finalized blocks are indexed for search
+	CfgStorageSearchIndexEnabled = "storage.searchIndexEnabled"
+	// CfgStorageArchive indicates whether the node retains the full history of the chain, i.e. it never prunes
+	// the state and indexes all the blocks for search. It requires a data directory holding the history since the genesis.
+	CfgStorageArchive = "storage.archive"
 
 	// CfgSyncMessageQueueSize defines the capacity of Sync Manager message queue.
 	CfgSyncMessageQueueSize = "sync.messageQueueSize"
@@ -107,10 +139,43 @@ const (
 	CfgP2PNatMapping = "p2p.natMapping"
 	// CfgP2PMaxConnections specifies the number of max connections a node can accept
 	CfgP2PMaxConnections = "p2p.maxConnections"
+	// CfgP2PSessionKeyRotationInterval sets the interval (in seconds) to rotate the session keys of
+	// the encrypted peer connections, 0 disables the rotation
+	CfgP2PSessionKeyRotationInterval = "p2p.sessionKeyRotationInterval"
 
 	// CfgSyncInboundResponseWhitelist filters inbound messages based on peer ID.
 	CfgSyncInboundResponseWhitelist = "sync.inboundResponseWhitelist"
 
+	// CfgMempoolMaxNumTxs sets the maximum number of pending transactions in the mempool. Once
+	// the mempool is full, the transactions with the lowest fee per byte are evicted.
+	CfgMempoolMaxNumTxs = "mempool.maxNumTxs"
+	// CfgMempoolMaxNumTxsPerAccount sets the maximum number of pending transactions of a single account.
+	CfgMempoolMaxNumTxsPerAccount = "mempool.maxNumTxsPerAccount"
+	// CfgMempoolMaxNumFutureTxs sets the maximum number of transactions held in the mempool
+	// because their sequences are ahead of the next expected sequences of their accounts. Once
+	// it is reached, the transactions of the account holding the most of them are evicted first.
+	CfgMempoolMaxNumFutureTxs = "mempool.maxNumFutureTxs"
+	// CfgMempoolMaxTxSize sets the maximum size (in bytes) of an encoded transaction accepted by the mempool.
+	CfgMempoolMaxTxSize = "mempool.maxTxSize"
+	// CfgMempoolMaxTxsPerPeerPerSecond sets the maximum number of transactions a peer can gossip per second,
+	// the excess transactions are dropped without being screened.
+	CfgMempoolMaxTxsPerPeerPerSecond = "mempool.maxTxsPerPeerPerSecond"
+	// CfgMempoolTxLifetimeSecs sets the time (in seconds) after which a pending transaction expires and
+	// is dropped from the mempool.
+	CfgMempoolTxLifetimeSecs = "mempool.txLifetimeSecs"
+	// CfgMempoolRebroadcastIntervalSecs sets the interval (in seconds) to rebroadcast the pending transactions
+	// submitted through the local RPC, including to the newly connected peers. Zero disables the rebroadcast.
+	CfgMempoolRebroadcastIntervalSecs = "mempool.rebroadcastIntervalSecs"
+	// CfgMempoolPriorityLocalTxs sets whether the transactions submitted through the local RPC can be marked
+	// as priority transactions, which are never evicted from the mempool by the transactions paying higher fees.
+	CfgMempoolPriorityLocalTxs = "mempool.priorityLocalTxs"
+	// CfgMempoolMaxNumPriorityTxs sets the maximum number of priority transactions admitted in excess of
+	// mempool.maxNumTxs when the mempool is full.
+	CfgMempoolMaxNumPriorityTxs = "mempool.maxNumPriorityTxs"
+	// CfgMempoolJournalEnabled sets whether to persist the pending transactions, so that they
+	// can be reloaded after the node restarts.
+	CfgMempoolJournalEnabled = "mempool.journalEnabled"
+
 	// CfgRPCEnabled sets whether to run RPC service.
 	CfgRPCEnabled = "rpc.enabled"
 	// CfgRPCAddress sets the binding address of RPC service.
@@ -121,9 +186,79 @@ const (
 	CfgRPCMaxConnections = "rpc.maxConnections"
 	// CfgRPCTimeoutSecs set a timeout for RPC.
 	CfgRPCTimeoutSecs = "rpc.timeoutSecs"
+	// CfgRPCMaxBatchSize limits the number of requests in a JSON-RPC batch, non-positive means no limit.
+	CfgRPCMaxBatchSize = "rpc.maxBatchSize"
+	// CfgRPCValidateParams sets whether to validate the params of the JSON-RPC calls against the RPC schema served at /openapi.json.
+	CfgRPCValidateParams = "rpc.validateParams"
+	// CfgRPCEthEnabled sets whether to serve the Ethereum JSON-RPC methods (eth_*) at the /eth endpoint.
+	CfgRPCEthEnabled = "rpc.ethEnabled"
+	// CfgRPCWSMaxSubscriptionsPerConn limits the number of subscriptions a WebSocket connection can hold.
+	CfgRPCWSMaxSubscriptionsPerConn = "rpc.wsMaxSubscriptionsPerConn"
+	// CfgRPCWSHeartbeatIntervalSecs sets the interval of the heartbeats sent to the WebSocket subscribers.
+	CfgRPCWSHeartbeatIntervalSecs = "rpc.wsHeartbeatIntervalSecs"
+	// CfgRPCGrpcEnabled sets whether to run the gRPC service alongside the JSON-RPC service.
+	CfgRPCGrpcEnabled = "rpc.grpcEnabled"
+	// CfgRPCGrpcPort sets the port of the gRPC service.
+	CfgRPCGrpcPort = "rpc.grpcPort"
+	// CfgRPCAuthEnabled sets whether to authenticate the RPC callers and restrict the methods they can call.
+	CfgRPCAuthEnabled = "rpc.authEnabled"
+	// CfgRPCAuthAPIKeys lists the API keys with the methods they can call, in the format of "<key>=<pattern>,<pattern>".
+	CfgRPCAuthAPIKeys = "rpc.authAPIKeys"
+	// CfgRPCAuthJWTSecret sets the secret to verify the HS256 JWTs, JWT authentication is disabled if empty.
+	CfgRPCAuthJWTSecret = "rpc.authJWTSecret"
+	// CfgRPCAuthPublicMethods lists the methods that can be called without credentials.
+	CfgRPCAuthPublicMethods = "rpc.authPublicMethods"
+	// CfgRPCAdminAddress sets the binding address of the admin RPC listener.
+	CfgRPCAdminAddress = "rpc.adminAddress"
+	// CfgRPCAdminPort sets the port of the admin RPC listener, which is disabled if empty.
+	CfgRPCAdminPort = "rpc.adminPort"
+	// CfgRPCAdminMethods lists the methods only served by the admin RPC listener when it is enabled.
+	CfgRPCAdminMethods = "rpc.adminMethods"
+	// CfgRPCAdminNamespaceEnabled sets whether to serve the node management methods (admin.*) to the admin listener and the authenticated callers.
+	CfgRPCAdminNamespaceEnabled = "rpc.adminNamespaceEnabled"
+	// CfgRPCRateLimitEnabled sets whether to limit the request rate and concurrent requests of each RPC client.
+	CfgRPCRateLimitEnabled = "rpc.rateLimitEnabled"
+	// CfgRPCRateLimitRequestsPerSec sets the sustained requests per second allowed for each RPC client.
+	CfgRPCRateLimitRequestsPerSec = "rpc.rateLimitRequestsPerSec"
+	// CfgRPCRateLimitBurst sets the number of requests an RPC client can send at once above the sustained rate.
+	CfgRPCRateLimitBurst = "rpc.rateLimitBurst"
+	// CfgRPCRateLimitMaxConcurrent limits the concurrent requests of each RPC client, non-positive means no limit.
+	CfgRPCRateLimitMaxConcurrent = "rpc.rateLimitMaxConcurrent"
+	// CfgRPCPrometheusEnabled sets whether to serve the metrics in the Prometheus format at /metrics.
+	CfgRPCPrometheusEnabled = "rpc.prometheusEnabled"
+	// CfgRPCPprofEnabled sets whether to serve the runtime profiles at /debug/pprof/ on the admin listener.
+	CfgRPCPprofEnabled = "rpc.pprofEnabled"
+	// CfgRPCPprofMaxDurationSecs caps the duration of the CPU profiles, traces and the profiles captured by admin.CaptureProfile.
+	CfgRPCPprofMaxDurationSecs = "rpc.pprofMaxDurationSecs"
+	// CfgRPCPprofBlockProfileRate sets the runtime block profile rate (in nanoseconds) while pprof is enabled, zero samples only during admin.CaptureProfile.
+	CfgRPCPprofBlockProfileRate = "rpc.pprofBlockProfileRate"
+	// CfgRPCPprofMutexProfileFraction sets the runtime mutex profile fraction while pprof is enabled, zero samples only during admin.CaptureProfile.
+	CfgRPCPprofMutexProfileFraction = "rpc.pprofMutexProfileFraction"
+	// CfgRPCHealthMaxBlocksBehind sets how many blocks the node can lag behind the network and still be reported ready at /readyz.
+	CfgRPCHealthMaxBlocksBehind = "rpc.healthMaxBlocksBehind"
+	// CfgRPCHealthMinPeers sets the number of peers the node needs to be reported ready at /readyz.
+	CfgRPCHealthMinPeers = "rpc.healthMinPeers"
+	// CfgRPCGraphQLEnabled sets whether to serve the GraphQL queries of the chain data at the /graphql endpoint.
+	CfgRPCGraphQLEnabled = "rpc.graphqlEnabled"
+	// CfgRPCCacheSizeMB sets the memory budget of the cache of the RPC results about the finalized data, zero disables the cache.
+	CfgRPCCacheSizeMB = "rpc.cacheSizeMB"
+	// CfgRPCCacheTTLSecs sets how long an RPC result stays in the cache, zero means no expiry.
+	CfgRPCCacheTTLSecs = "rpc.cacheTTLSecs"
+	// CfgRPCTLSCertFile sets the certificate file for serving RPC over HTTPS, which is enabled when both the certificate and key files are set.
+	CfgRPCTLSCertFile = "rpc.tlsCertFile"
+	// CfgRPCTLSKeyFile sets the private key file for serving RPC over HTTPS.
+	CfgRPCTLSKeyFile = "rpc.tlsKeyFile"
+	// CfgRPCCORSAllowedOrigins lists the origins allowed to make cross-origin RPC requests, "*" allows any origin.
+	CfgRPCCORSAllowedOrigins = "rpc.corsAllowedOrigins"
+	// CfgRPCTrustedProxies lists the IPs or CIDRs of the reverse proxies whose X-Forwarded-For headers are trusted.
+	CfgRPCTrustedProxies = "rpc.trustedProxies"
+	// CfgRPCMaxRequestBodyBytes limits the size of an RPC request body, non-positive means no limit.
+	CfgRPCMaxRequestBodyBytes = "rpc.maxRequestBodyBytes"
 
 	// CfgLogLevels sets the log level.
 	CfgLogLevels = "log.levels"
+	// CfgLogFormat sets the format of the log entries, either "text" or "json".
+	CfgLogFormat = "log.format"
 	// CfgLogPrintSelfID determines whether to print node's ID in log (Useful in simulation when
 	// there are more than one node running).
 	CfgLogPrintSelfID = "log.printSelfID"
@@ -133,6 +268,62 @@ const (
 
 	// Graphite Server to collet metrics
 	CfgMetricsServer = "metrics.server"
+	// CfgMetricsPrometheusAddress sets the address of the listener serving the metrics of all modules
+	// in the Prometheus format at /metrics, which is disabled if empty.
+	CfgMetricsPrometheusAddress = "metrics.prometheusAddress"
+
+	// CfgTracingEnabled sets whether to export the spans of the block processing pipeline to an OpenTelemetry collector.
+	CfgTracingEnabled = "tracing.enabled"
+	// CfgTracingOTLPEndpoint sets the OTLP/HTTP endpoint of the collector.
+	CfgTracingOTLPEndpoint = "tracing.otlpEndpoint"
+	// CfgTracingSampleRatio sets the ratio of the blocks and transactions traced, between 0 and 1.
+	CfgTracingSampleRatio = "tracing.sampleRatio"
+
+	// CfgStateDiffEnabled sets whether to export the state diff of each committed block for the indexers.
+	CfgStateDiffEnabled = "stateDiff.enabled"
+	// CfgStateDiffSink sets the sink of the state diffs (file|webhook|kafka).
+	CfgStateDiffSink = "stateDiff.sink"
+	// CfgStateDiffFilePath sets the file the state diffs are appended to as JSON lines, "statediff.jsonl" in the data directory if empty.
+	CfgStateDiffFilePath = "stateDiff.filePath"
+	// CfgStateDiffWebhookURL sets the URL the state diffs are posted to.
+	CfgStateDiffWebhookURL = "stateDiff.webhookURL"
+	// CfgStateDiffKafkaRESTProxy sets the URL of the Kafka REST proxy the state diffs are produced through.
+	CfgStateDiffKafkaRESTProxy = "stateDiff.kafkaRESTProxy"
+	// CfgStateDiffKafkaTopic sets the Kafka topic of the state diffs.
+	CfgStateDiffKafkaTopic = "stateDiff.kafkaTopic"
+	// CfgStateDiffHTTPHeaders sets the extra headers of the webhook and Kafka requests, as "<name>: <value>".
+	CfgStateDiffHTTPHeaders = "stateDiff.httpHeaders"
+
+	// CfgExecutionParallelTxWorkers sets the number of workers executing the transactions of a block in parallel, 0 or 1 to execute them in sequence.
+	CfgExecutionParallelTxWorkers = "execution.parallelTxWorkers"
+	// CfgExecutionBlockResultCacheSize sets the number of recently applied blocks whose results are kept to apply them again on the same parent state without execution, e.g. after a fork switch, 0 to disable.
+	CfgExecutionBlockResultCacheSize = "execution.blockResultCacheSize"
+
+	// CfgWebhookEnabled sets whether to post the node events to the webhook endpoints.
+	CfgWebhookEnabled = "webhook.enabled"
+	// CfgWebhookEndpoints lists the webhook endpoints in the format of "<event>,<event>=<url>", or "*=<url>" for all the events.
+	CfgWebhookEndpoints = "webhook.endpoints"
+	// CfgWebhookSecret sets the secret to sign the webhook requests with HMAC-SHA256, the requests are not signed if empty.
+	CfgWebhookSecret = "webhook.secret"
+	// CfgWebhookMaxRetries sets the max number of retries of a failed webhook delivery.
+	CfgWebhookMaxRetries = "webhook.maxRetries"
+	// CfgWebhookWatchAddresses lists the addresses to notify the funds received by.
+	CfgWebhookWatchAddresses = "webhook.watchAddresses"
+	// CfgWebhookWatchValidators lists the validators to notify the missed proposals of, the address of the node if empty.
+	CfgWebhookWatchValidators = "webhook.watchValidators"
+	// CfgWebhookMissedProposals sets the number of consecutive proposals a validator misses before it is notified.
+	CfgWebhookMissedProposals = "webhook.missedProposals"
+	// CfgWebhookMaxBlocksBehind sets the number of blocks the node can be behind the network before it is notified.
+	CfgWebhookMaxBlocksBehind = "webhook.maxBlocksBehind"
+
+	// CfgResourceCheckIntervalSecs sets the interval (in seconds) of the disk, file descriptor and memory checks.
+	CfgResourceCheckIntervalSecs = "resource.checkIntervalSecs"
+	// CfgResourceMinFreeDiskMB sets the free space of the data disk below which the non-essential work is paused.
+	CfgResourceMinFreeDiskMB = "resource.minFreeDiskMB"
+	// CfgResourceMaxOpenFilesRatio sets the ratio of the file descriptor limit above which the non-essential work is paused.
+	CfgResourceMaxOpenFilesRatio = "resource.maxOpenFilesRatio"
+	// CfgResourceMaxMemoryMB sets the memory obtained from the OS above which the non-essential work is paused, 0 means no limit.
+	CfgResourceMaxMemoryMB = "resource.maxMemoryMB"
 
 	// CfgProfEnabled to enable profiling
 	CfgProfEnabled = "prof.enabled"
@@ -158,6 +349,7 @@ p2p:
 
 func init() {
 	viper.SetDefault(CfgNodeType, 1) // 1: blockchain node, 2: edge node
+	viper.SetDefault(CfgNodeShutdownTimeoutSecs, 30)
 	viper.SetDefault(CfgForceValidateSnapshot, false)
 
 	viper.SetDefault(CfgConsensusMaxEpochLength, 20)
@@ -165,6 +357,7 @@ func init() {
 	viper.SetDefault(CfgConsensusMessageQueueSize, 512)
 	viper.SetDefault(CfgConsensusEdgeNodeVoteQueueSize, 100000)
 	viper.SetDefault(CfgConsensusPassThroughGuardianVote, false)
+	viper.SetDefault(CfgConsensusValidatorAddress, "")
 
 	viper.SetDefault(CfgSyncMessageQueueSize, 512)
 	viper.SetDefault(CfgSyncDownloadByHash, false)
@@ -177,7 +370,23 @@ func init() {
 	viper.SetDefault(CfgStorageStatePruningSkipCheckpoints, true)
 	viper.SetDefault(CfgStorageLevelDBCacheSize, 256)
 	viper.SetDefault(CfgStorageLevelDBHandles, 16)
+	viper.SetDefault(CfgStorageStateCacheSize, 256)
 	viper.SetDefault(CfgStorageRollingInterval, 14400) // approximately 1 days by default
+	viper.SetDefault(CfgStorageReadOnly, false)
+	viper.SetDefault(CfgStorageReadOnlyRefreshInterval, 10)
+	viper.SetDefault(CfgStorageSearchIndexEnabled, false)
+	viper.SetDefault(CfgStorageArchive, false)
+
+	viper.SetDefault(CfgMempoolMaxNumTxs, 25600)
+	viper.SetDefault(CfgMempoolMaxNumTxsPerAccount, 64)
+	viper.SetDefault(CfgMempoolMaxNumFutureTxs, 1024)
+	viper.SetDefault(CfgMempoolMaxTxSize, 128*1024)
+	viper.SetDefault(CfgMempoolMaxTxsPerPeerPerSecond, 500)
+	viper.SetDefault(CfgMempoolTxLifetimeSecs, 60)
+	viper.SetDefault(CfgMempoolRebroadcastIntervalSecs, 15)
+	viper.SetDefault(CfgMempoolPriorityLocalTxs, false)
+	viper.SetDefault(CfgMempoolMaxNumPriorityTxs, 256)
+	viper.SetDefault(CfgMempoolJournalEnabled, true)
 
 	viper.SetDefault(CfgRPCEnabled, false)
 	viper.SetDefault(CfgP2PMessageQueueSize, 512)
@@ -200,18 +409,92 @@ func init() {
 	viper.SetDefault(CfgP2PConnectionFIFO, false)
 	viper.SetDefault(CfgP2PNatMapping, false)
 	viper.SetDefault(CfgP2PMaxConnections, 2048)
+	viper.SetDefault(CfgP2PSessionKeyRotationInterval, 1800)
+
+	viper.SetDefault(CfgHSMEnabled, false)
+	viper.SetDefault(CfgHSMKeyLabel, "theta-validator")
+	viper.SetDefault(CfgHSMHealthCheckInterval, 60)
 
 	viper.SetDefault(CfgRPCAddress, "0.0.0.0")
 	viper.SetDefault(CfgRPCPort, "16888")
 	viper.SetDefault(CfgRPCMaxConnections, 200)
 	viper.SetDefault(CfgRPCTimeoutSecs, 60)
+	viper.SetDefault(CfgRPCMaxBatchSize, 100)
+	viper.SetDefault(CfgRPCValidateParams, true)
+	viper.SetDefault(CfgRPCEthEnabled, false)
+	viper.SetDefault(CfgRPCWSMaxSubscriptionsPerConn, 16)
+	viper.SetDefault(CfgRPCWSHeartbeatIntervalSecs, 30)
+	viper.SetDefault(CfgRPCGrpcEnabled, false)
+	viper.SetDefault(CfgRPCGrpcPort, "16890")
+	viper.SetDefault(CfgRPCAuthEnabled, false)
+	viper.SetDefault(CfgRPCAuthAPIKeys, []string{})
+	viper.SetDefault(CfgRPCAuthJWTSecret, "")
+	viper.SetDefault(CfgRPCAuthPublicMethods, []string{
+		"theta.Get*", "theta.List*", "theta.CallSmartContract", "theta.EstimateGas",
+		"web3_*", "net_*", "eth_get*", "eth_call", "eth_estimateGas", "eth_chainId",
+		"eth_blockNumber", "eth_gasPrice", "eth_syncing", "eth_accounts", "graphql",
+	})
+	viper.SetDefault(CfgRPCAdminAddress, "127.0.0.1")
+	viper.SetDefault(CfgRPCAdminPort, "")
+	viper.SetDefault(CfgRPCAdminMethods, []string{"theta.Backup*", "admin.*"})
+	viper.SetDefault(CfgRPCAdminNamespaceEnabled, false)
+	viper.SetDefault(CfgRPCRateLimitEnabled, false)
+	viper.SetDefault(CfgRPCRateLimitRequestsPerSec, 50)
+	viper.SetDefault(CfgRPCRateLimitBurst, 100)
+	viper.SetDefault(CfgRPCRateLimitMaxConcurrent, 16)
+	viper.SetDefault(CfgRPCPrometheusEnabled, false)
+	viper.SetDefault(CfgRPCPprofEnabled, false)
+	viper.SetDefault(CfgRPCPprofMaxDurationSecs, 30)
+	viper.SetDefault(CfgRPCPprofBlockProfileRate, 0)
+	viper.SetDefault(CfgRPCPprofMutexProfileFraction, 0)
+	viper.SetDefault(CfgRPCHealthMaxBlocksBehind, 10)
+	viper.SetDefault(CfgRPCHealthMinPeers, 1)
+	viper.SetDefault(CfgRPCGraphQLEnabled, false)
+	viper.SetDefault(CfgRPCCacheSizeMB, 64)
+	viper.SetDefault(CfgRPCCacheTTLSecs, 3600)
+	viper.SetDefault(CfgRPCTLSCertFile, "")
+	viper.SetDefault(CfgRPCTLSKeyFile, "")
+	viper.SetDefault(CfgRPCCORSAllowedOrigins, []string{"*"})
+	viper.SetDefault(CfgRPCTrustedProxies, []string{})
+	viper.SetDefault(CfgRPCMaxRequestBodyBytes, 8*1024*1024)
 
 	viper.SetDefault(CfgLogLevels, "*:debug")
+	viper.SetDefault(CfgLogFormat, "text")
 	viper.SetDefault(CfgLogPrintSelfID, false)
 
 	viper.SetDefault(CfgGuardianRoundLength, 30)
 
 	viper.SetDefault(CfgMetricsServer, "guardian-metrics.thetatoken.org")
+	viper.SetDefault(CfgMetricsPrometheusAddress, "")
+
+	viper.SetDefault(CfgTracingEnabled, false)
+	viper.SetDefault(CfgTracingOTLPEndpoint, "http://127.0.0.1:4318")
+	viper.SetDefault(CfgTracingSampleRatio, 1.0)
+
+	viper.SetDefault(CfgStateDiffEnabled, false)
+	viper.SetDefault(CfgStateDiffSink, "file")
+	viper.SetDefault(CfgStateDiffFilePath, "")
+	viper.SetDefault(CfgStateDiffWebhookURL, "")
+	viper.SetDefault(CfgStateDiffKafkaRESTProxy, "http://127.0.0.1:8082")
+	viper.SetDefault(CfgStateDiffKafkaTopic, "theta-state-diffs")
+	viper.SetDefault(CfgStateDiffHTTPHeaders, []string{})
+
+	viper.SetDefault(CfgExecutionParallelTxWorkers, 0)
+	viper.SetDefault(CfgExecutionBlockResultCacheSize, 64)
+
+	viper.SetDefault(CfgWebhookEnabled, false)
+	viper.SetDefault(CfgWebhookEndpoints, []string{})
+	viper.SetDefault(CfgWebhookSecret, "")
+	viper.SetDefault(CfgWebhookMaxRetries, 5)
+	viper.SetDefault(CfgWebhookWatchAddresses, []string{})
+	viper.SetDefault(CfgWebhookWatchValidators, []string{})
+	viper.SetDefault(CfgWebhookMissedProposals, 3)
+	viper.SetDefault(CfgWebhookMaxBlocksBehind, 100)
+
+	viper.SetDefault(CfgResourceCheckIntervalSecs, 10)
+	viper.SetDefault(CfgResourceMinFreeDiskMB, 5120)
+	viper.SetDefault(CfgResourceMaxOpenFilesRatio, 0.9)
+	viper.SetDefault(CfgResourceMaxMemoryMB, 0)
 
 	viper.SetDefault(CfgProfEnabled, false)
 	viper.SetDefault(CfgForceGCEnabled, true)
@@ -0,0 +1,185 @@
+// Package diagnostic produces the diagnostic bundles attached to the support tickets. A bundle
+// holds the stack traces of all the goroutines, the recent log lines, the state of the chain and
+// the config with the secrets redacted. It is written to a file when the node crashes, or on
+// demand with "theta debug dump".
+package diagnostic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common/util"
+	"github.com/thetatoken/theta/version"
+)
+
+var logger = util.GetLoggerForModule("diagnostic")
+
+// redactedKeys are the fragments of the config keys whose values are replaced in the bundles
+var redactedKeys = []string{"password", "secret", "apikey", "mnemonic", "privatekey"}
+
+const redacted = "<redacted>"
+
+var (
+	mu             sync.RWMutex
+	statusProvider func() interface{}
+	crashDir       string
+)
+
+// SetStatusProvider sets the function which returns the state of the chain included in the
+// bundles, e.g. the last finalized block.
+func SetStatusProvider(provider func() interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	statusProvider = provider
+}
+
+// SetCrashDir sets the directory of the bundles written on crash. The crashes are not recorded
+// until it is set.
+func SetCrashDir(dir string) {
+	mu.Lock()
+	defer mu.Unlock()
+	crashDir = dir
+}
+
+// Recover writes a bundle if the goroutine panics, and then panics again so that the node still
+// crashes. It must be deferred directly, e.g. "defer diagnostic.Recover()".
+func Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	mu.RLock()
+	dir := crashDir
+	mu.RUnlock()
+	if dir != "" {
+		if filePath, err := WriteBundleFile(dir, fmt.Sprintf("panic: %v", r)); err != nil {
+			logger.Errorf("Failed to write the crash bundle: %v", err)
+		} else {
+			logger.Errorf("Crash bundle written to %v", filePath)
+		}
+	}
+	panic(r)
+}
+
+// WriteBundleFile writes a bundle to a new file in the directory, and returns its path.
+func WriteBundleFile(dir string, reason string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	filePath := path.Join(dir, fmt.Sprintf("theta-diagnostic-%v.txt", time.Now().UTC().Format("20060102-150405")))
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := WriteBundle(file, reason); err != nil {
+		return "", err
+	}
+	return filePath, file.Sync()
+}
+
+// WriteBundle writes a bundle to w. The reason tells why it is produced, e.g. the panic value.
+func WriteBundle(w io.Writer, reason string) error {
+	b := &bundleWriter{w: w}
+	b.section("Theta diagnostic bundle")
+	b.printf("Reason:     %v\n", reason)
+	b.printf("Time:       %v\n", time.Now().UTC().Format(time.RFC3339))
+	b.printf("Version:    %v %v, built at %v\n", version.Version, version.GitHash, version.Timestamp)
+	b.printf("Go:         %v %v/%v\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	b.printf("PID:        %v\n", os.Getpid())
+	b.printf("Goroutines: %v\n", runtime.NumGoroutine())
+
+	b.section("Chain")
+	mu.RLock()
+	provider := statusProvider
+	mu.RUnlock()
+	if provider == nil {
+		b.printf("unavailable\n")
+	} else {
+		b.json(provider())
+	}
+
+	b.section("Config")
+	b.json(redactConfig(viper.AllSettings()))
+
+	b.section("Recent logs")
+	for _, line := range util.RecentLogs() {
+		b.printf("%v\n", line)
+	}
+
+	b.section("Goroutines")
+	b.printf("%s\n", goroutineStacks())
+	return b.err
+}
+
+// bundleWriter keeps the first error, so that the sections need not check each write.
+type bundleWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (b *bundleWriter) printf(format string, a ...interface{}) {
+	if b.err == nil {
+		_, b.err = fmt.Fprintf(b.w, format, a...)
+	}
+}
+
+func (b *bundleWriter) section(title string) {
+	b.printf("\n===== %v =====\n\n", title)
+}
+
+func (b *bundleWriter) json(value interface{}) {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		b.printf("Failed to encode: %v\n", err)
+		return
+	}
+	b.printf("%s\n", content)
+}
+
+// goroutineStacks returns the stack traces of all the goroutines.
+func goroutineStacks() []byte {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 64<<20 {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// redactConfig returns a copy of the settings with the values of the secrets replaced.
+func redactConfig(settings map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		if nested, ok := value.(map[string]interface{}); ok {
+			result[key] = redactConfig(nested)
+		} else if isSecret(key) {
+			result[key] = redacted
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range redactedKeys {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
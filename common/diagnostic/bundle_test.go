@@ -0,0 +1,76 @@
+package diagnostic
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	settings := map[string]interface{}{
+		"p2p": map[string]interface{}{"port": 12000},
+		"rpc": map[string]interface{}{
+			"port":          "16888",
+			"authapikeys":   []string{"key1=theta.*"},
+			"authjwtsecret": "jwt",
+		},
+		"wallet": map[string]interface{}{"password": "qwertyuiop"},
+	}
+	result := redactConfig(settings)
+
+	assert.Equal(map[string]interface{}{"port": 12000}, result["p2p"])
+	assert.Equal(map[string]interface{}{
+		"port":          "16888",
+		"authapikeys":   redacted,
+		"authjwtsecret": redacted,
+	}, result["rpc"])
+	assert.Equal(map[string]interface{}{"password": redacted}, result["wallet"])
+
+	// The settings are not modified
+	assert.Equal("jwt", settings["rpc"].(map[string]interface{})["authjwtsecret"])
+}
+
+func TestWriteBundle(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	SetStatusProvider(func() interface{} {
+		return map[string]uint64{"last_finalized_height": 42}
+	})
+	defer SetStatusProvider(nil)
+
+	var buf bytes.Buffer
+	require.Nil(WriteBundle(&buf, "test"))
+	content := buf.String()
+
+	assert.True(strings.Contains(content, "Reason:     test"))
+	assert.True(strings.Contains(content, `"last_finalized_height": 42`))
+	assert.True(strings.Contains(content, "===== Goroutines ====="))
+	assert.True(strings.Contains(content, "TestWriteBundle"))
+}
+
+func TestRecover(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "diagnostic")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	SetCrashDir(dir)
+	defer SetCrashDir("")
+
+	assert.PanicsWithValue("boom", func() {
+		defer Recover()
+		panic("boom")
+	})
+	files, err := filepath.Glob(filepath.Join(dir, "theta-diagnostic-*.txt"))
+	assert.Nil(err)
+	assert.Equal(1, len(files))
+}
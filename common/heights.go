@@ -30,6 +30,76 @@ const HeightTxWrapperExtension uint64 = 12749952
 // HeightSupportThetaTokenInSmartContract specifies the block height to support Theta in smart contracts
 const HeightSupportThetaTokenInSmartContract uint64 = 13123789 // approximate time: 5pm Dec 4, 2021 PT
 
+// HeightEnableMultiSigTx specifies the minimal block height to enable the multisig account transactions
+const HeightEnableMultiSigTx uint64 = 14500000
+
+// HeightEnableVestingTx specifies the minimal block height to enable the time-locked and vesting transfers
+const HeightEnableVestingTx uint64 = 14500000
+
+// HeightEnableBatchSendTx specifies the minimal block height to enable the batch send transaction
+const HeightEnableBatchSendTx uint64 = 14500000
+
+// HeightEnableSplitRuleRenewal specifies the minimal block height to enable the split rule renewal transaction,
+// and the removal of the expired split rules at the end of each block
+const HeightEnableSplitRuleRenewal uint64 = 14500000
+
+// HeightEnableTxCancellation specifies the minimal block height to allow the zero-value self-send which cancels a pending transaction
+const HeightEnableTxCancellation uint64 = 14500000
+
+// HeightEnableServicePaymentDispute specifies the minimal block height to enable the ReserveFundTxV2 transaction,
+// which allows reserving fund with a dispute window for the service payment settlement
+const HeightEnableServicePaymentDispute uint64 = 14500000
+
+// HeightEnableTokenRegistryTx specifies the minimal block height to enable the token registry transaction
+const HeightEnableTokenRegistryTx uint64 = 14500000
+
+// HeightEnableValidatorKeyRotationTx specifies the minimal block height to enable the validator key rotation transaction
+const HeightEnableValidatorKeyRotationTx uint64 = 14500000
+
+// HeightEnableIBCTx specifies the minimal block height to enable the IBC transaction
+const HeightEnableIBCTx uint64 = 14500000
+
+// HeightEnableBandwidthProofTx specifies the minimal block height to enable the bandwidth proof transaction
+const HeightEnableBandwidthProofTx uint64 = 14500000
+
+// HeightEnableGovernance specifies the minimal block height to enable the governance proposals and votes
+const HeightEnableGovernance uint64 = 14500000
+
+// HeightEnableEd25519Signature specifies the minimal block height to accept the Ed25519 signatures of the transactions
+const HeightEnableEd25519Signature uint64 = 14500000
+
+// HeightEnableCanonicalSignature specifies the minimal block height to reject the non-canonical transaction signatures,
+// i.e. the secp256k1 signatures with high S values or not in the 65 byte [R || S || V] encoding, which would otherwise
+// allow a third party to change the transaction hash without invalidating the signature
+const HeightEnableCanonicalSignature uint64 = 14500000
+
+// HeightEnableTxReceiptRoot specifies the minimal block height to commit the transaction receipts
+// under the receipt root hash of the block header
+const HeightEnableTxReceiptRoot uint64 = 14500000
+
+// HeightEnableSponsoredFee specifies the minimal block height to allow a fee payer to pay the
+// transaction fee on behalf of the transaction sender
+const HeightEnableSponsoredFee uint64 = 14500000
+
+// HeightEnableTxExpiry specifies the minimal block height to allow transactions with an expiry height
+const HeightEnableTxExpiry uint64 = 14500000
+
+// HeightEnableFeeSchedule specifies the minimal block height to charge the minimum transaction fees
+// specified by the fee schedule stored in the ledger state
+const HeightEnableFeeSchedule uint64 = 14500000
+
+// HeightEnableWasmRuntime specifies the minimal block height to deploy and call WASM smart contracts
+const HeightEnableWasmRuntime uint64 = 14500000
+
+// HeightEnableNativePrecompiles specifies the minimal block height to enable the precompiled contracts
+// for the native ledger operations, e.g. querying the validator set and reserving funds
+const HeightEnableNativePrecompiles uint64 = 14500000
+
+// HeightEnableChainIDReplayProtection specifies the minimal block height to require the transaction signatures
+// to commit to the numeric chain ID of the network, i.e. to be over the sign bytes in the v3 Ethereum tx wrapper,
+// so that they cannot be replayed on another network
+const HeightEnableChainIDReplayProtection uint64 = 14500000
+
 // CheckpointInterval defines the interval between checkpoints.
 const CheckpointInterval = int64(100)
 
@@ -0,0 +1,83 @@
+// Package prometheus exposes the metrics of a go-metrics registry in the Prometheus text
+// exposition format, so that they can be scraped without the Prometheus client library.
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+const contentType = "text/plain; version=0.0.4"
+
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// Handler returns an HTTP handler which serves the metrics of the registry.
+func Handler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(Export(reg))
+	})
+}
+
+// Export renders the metrics of the registry in the Prometheus text format, sorted by name.
+func Export(reg metrics.Registry) []byte {
+	names := []string{}
+	reg.Each(func(name string, i interface{}) {
+		names = append(names, name)
+	})
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	for _, name := range names {
+		pname := mutateName(name)
+		switch m := reg.Get(name).(type) {
+		case metrics.Counter:
+			writeValue(buf, pname, "counter", float64(m.Count()))
+		case metrics.Gauge:
+			writeValue(buf, pname, "gauge", float64(m.Value()))
+		case metrics.GaugeFloat64:
+			writeValue(buf, pname, "gauge", m.Value())
+		case metrics.Meter:
+			writeValue(buf, pname, "counter", float64(m.Snapshot().Count()))
+		case metrics.Histogram:
+			s := m.Snapshot()
+			writeSummary(buf, pname, s.Count(), float64(s.Sum()), s.Percentiles(quantiles))
+		case metrics.Timer:
+			s := m.Snapshot()
+			writeSummary(buf, pname, s.Count(), float64(s.Sum()), s.Percentiles(quantiles))
+		}
+	}
+	return buf.Bytes()
+}
+
+func writeValue(buf *bytes.Buffer, name string, typ string, value float64) {
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, typ)
+	fmt.Fprintf(buf, "%s %v\n", name, value)
+}
+
+func writeSummary(buf *bytes.Buffer, name string, count int64, sum float64, ps []float64) {
+	fmt.Fprintf(buf, "# TYPE %s summary\n", name)
+	for i, q := range quantiles {
+		fmt.Fprintf(buf, "%s{quantile=\"%v\"} %v\n", name, q, ps[i])
+	}
+	fmt.Fprintf(buf, "%s_sum %v\n", name, sum)
+	fmt.Fprintf(buf, "%s_count %v\n", name, count)
+}
+
+// mutateName converts a go-metrics name (e.g. "rpc/ratelimit/rejected") into a valid Prometheus
+// metric name (e.g. "rpc_ratelimit_rejected").
+func mutateName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,31 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+func TestExport(t *testing.T) {
+	reg := metrics.NewRegistry()
+	counter := &metrics.StandardCounter{}
+	counter.Inc(3)
+	reg.Register("rpc/ratelimit/rejected", counter)
+	gauge := &metrics.StandardGauge{}
+	gauge.Update(7)
+	reg.Register("rpc/inflight", gauge)
+
+	want := "# TYPE rpc_inflight gauge\n" +
+		"rpc_inflight 7\n" +
+		"# TYPE rpc_ratelimit_rejected counter\n" +
+		"rpc_ratelimit_rejected 3\n"
+	if got := string(Export(reg)); got != want {
+		t.Errorf("\nwant: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestMutateName(t *testing.T) {
+	if got := mutateName("chain/db-compact.time"); got != "chain_db_compact_time" {
+		t.Errorf("unexpected name %v", got)
+	}
+}
@@ -313,6 +313,12 @@ func (r *PrefixedRegistry) UnregisterAll() {
 
 var DefaultRegistry Registry = NewRegistry()
 
+// ModuleRegistry returns the registry a module registers its metrics into. The metrics are
+// stored in the DefaultRegistry with the module name as prefix, e.g. "consensus/epoch".
+func ModuleRegistry(module string) Registry {
+	return NewPrefixedChildRegistry(DefaultRegistry, module+"/")
+}
+
 // Call the given function for each registered metric.
 func Each(f func(string, interface{})) {
 	DefaultRegistry.Each(f)
@@ -154,6 +154,16 @@ func TestPrefixedChildRegistryGetOrRegister(t *testing.T) {
 	}
 }
 
+func TestModuleRegistry(t *testing.T) {
+	defer DefaultRegistry.Unregister("module/foo")
+
+	_ = ModuleRegistry("module").GetOrRegister("foo", NewCounter())
+
+	if DefaultRegistry.Get("module/foo") == nil {
+		t.Fatal("module/foo not registered into the DefaultRegistry")
+	}
+}
+
 func TestPrefixedRegistryGetOrRegister(t *testing.T) {
 	r := NewPrefixedRegistry("prefix.")
 
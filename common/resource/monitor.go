@@ -0,0 +1,177 @@
+// Package resource monitors the free disk space, the open file descriptors and the memory of the
+// node, so that the non-essential work such as snapshot exports and sync prefetching can be paused
+// when a resource runs low, rather than failing with opaque database errors once it runs out.
+package resource
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/common/util"
+)
+
+var logger = util.GetLoggerForModule("resource")
+
+// Usage is the latest reading of the resources. The values which cannot be read on the platform
+// are zero.
+type Usage struct {
+	FreeDiskBytes uint64 `json:"free_disk_bytes"`
+	OpenFiles     uint64 `json:"open_files"`
+	MaxOpenFiles  uint64 `json:"max_open_files"`
+	MemoryBytes   uint64 `json:"memory_bytes"`
+}
+
+// Limits are the thresholds of the resources, a zero limit disables the check.
+type Limits struct {
+	MinFreeDiskBytes  uint64
+	MaxOpenFilesRatio float64
+	MaxMemoryBytes    uint64
+}
+
+// Status is the usage along with the alerts of the resources beyond their limits.
+type Status struct {
+	Usage
+	Alerts []string `json:"alerts"`
+}
+
+// Monitor checks the resources periodically. A nil Monitor is valid and never reports pressure.
+type Monitor struct {
+	dataPath string
+	interval time.Duration
+	limits   Limits
+
+	mu     sync.RWMutex
+	status Status
+
+	freeDisk  metrics.Gauge
+	openFiles metrics.Gauge
+	memory    metrics.Gauge
+	alerts    metrics.Gauge
+}
+
+// NewMonitor creates the monitor of the resources with the limits from the config. The free disk
+// space is checked on the file system of dataPath.
+func NewMonitor(dataPath string) *Monitor {
+	reg := metrics.ModuleRegistry("resource")
+	gauge := func(name string) metrics.Gauge {
+		return reg.GetOrRegister(name, &metrics.StandardGauge{}).(metrics.Gauge)
+	}
+
+	interval := time.Duration(viper.GetInt(common.CfgResourceCheckIntervalSecs)) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &Monitor{
+		dataPath: dataPath,
+		interval: interval,
+		limits: Limits{
+			MinFreeDiskBytes:  uint64(viper.GetInt64(common.CfgResourceMinFreeDiskMB)) * 1024 * 1024,
+			MaxOpenFilesRatio: viper.GetFloat64(common.CfgResourceMaxOpenFilesRatio),
+			MaxMemoryBytes:    uint64(viper.GetInt64(common.CfgResourceMaxMemoryMB)) * 1024 * 1024,
+		},
+		status: Status{Alerts: []string{}},
+
+		freeDisk:  gauge("free_disk_bytes"),
+		openFiles: gauge("open_files"),
+		memory:    gauge("memory_bytes"),
+		alerts:    gauge("alerts"),
+	}
+}
+
+// Start checks the resources until ctx is done.
+func (m *Monitor) Start(ctx context.Context) {
+	m.check()
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.check()
+			}
+		}
+	}()
+}
+
+// Status returns the result of the latest check.
+func (m *Monitor) Status() Status {
+	if m == nil {
+		return Status{Alerts: []string{}}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// UnderPressure returns whether a resource is beyond its limit, in which case the non-essential
+// work should be paused.
+func (m *Monitor) UnderPressure() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.status.Alerts) > 0
+}
+
+func (m *Monitor) check() {
+	usage := Usage{}
+	limits := m.limits
+
+	// The checks of the resources which cannot be read are skipped
+	var err error
+	if usage.FreeDiskBytes, err = freeDiskBytes(m.dataPath); err != nil {
+		limits.MinFreeDiskBytes = 0
+	}
+	if usage.OpenFiles, usage.MaxOpenFiles, err = openFiles(); err != nil {
+		limits.MaxOpenFilesRatio = 0
+	}
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	usage.MemoryBytes = memStats.Sys
+
+	alerts := checkLimits(usage, limits)
+
+	m.freeDisk.Update(int64(usage.FreeDiskBytes))
+	m.openFiles.Update(int64(usage.OpenFiles))
+	m.memory.Update(int64(usage.MemoryBytes))
+	m.alerts.Update(int64(len(alerts)))
+
+	m.mu.Lock()
+	previous := m.status.Alerts
+	m.status = Status{Usage: usage, Alerts: alerts}
+	m.mu.Unlock()
+
+	if len(alerts) > 0 && len(previous) == 0 {
+		logger.Warnf("Pausing the non-essential work, resources low: %v", alerts)
+	} else if len(alerts) == 0 && len(previous) > 0 {
+		logger.Infof("Resources back within limits, resuming the non-essential work")
+	}
+}
+
+// checkLimits returns the alerts of the resources beyond their limits.
+func checkLimits(usage Usage, limits Limits) []string {
+	alerts := []string{}
+	if limits.MinFreeDiskBytes > 0 && usage.FreeDiskBytes < limits.MinFreeDiskBytes {
+		alerts = append(alerts, fmt.Sprintf("free disk space %v MB below %v MB",
+			usage.FreeDiskBytes/1024/1024, limits.MinFreeDiskBytes/1024/1024))
+	}
+	if limits.MaxOpenFilesRatio > 0 && usage.MaxOpenFiles > 0 &&
+		float64(usage.OpenFiles) > limits.MaxOpenFilesRatio*float64(usage.MaxOpenFiles) {
+		alerts = append(alerts, fmt.Sprintf("%v open files out of the limit of %v",
+			usage.OpenFiles, usage.MaxOpenFiles))
+	}
+	if limits.MaxMemoryBytes > 0 && usage.MemoryBytes > limits.MaxMemoryBytes {
+		alerts = append(alerts, fmt.Sprintf("memory usage %v MB above %v MB",
+			usage.MemoryBytes/1024/1024, limits.MaxMemoryBytes/1024/1024))
+	}
+	return alerts
+}
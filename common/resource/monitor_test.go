@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	limits := Limits{
+		MinFreeDiskBytes:  1024 * 1024 * 1024,
+		MaxOpenFilesRatio: 0.9,
+		MaxMemoryBytes:    4 * 1024 * 1024 * 1024,
+	}
+	usage := Usage{
+		FreeDiskBytes: 10 * 1024 * 1024 * 1024,
+		OpenFiles:     100,
+		MaxOpenFiles:  1024,
+		MemoryBytes:   1024 * 1024 * 1024,
+	}
+	assert.Equal([]string{}, checkLimits(usage, limits))
+
+	usage.FreeDiskBytes = 512 * 1024 * 1024
+	usage.OpenFiles = 1000
+	usage.MemoryBytes = 5 * 1024 * 1024 * 1024
+	alerts := checkLimits(usage, limits)
+	assert.Equal(3, len(alerts))
+	assert.Equal("free disk space 512 MB below 1024 MB", alerts[0])
+	assert.Equal("1000 open files out of the limit of 1024", alerts[1])
+	assert.Equal("memory usage 5120 MB above 4096 MB", alerts[2])
+
+	// The checks with zero limits are disabled
+	assert.Equal([]string{}, checkLimits(usage, Limits{}))
+}
+
+func TestNilMonitor(t *testing.T) {
+	assert := assert.New(t)
+
+	var m *Monitor
+	assert.False(m.UnderPressure())
+	assert.Equal([]string{}, m.Status().Alerts)
+}
+
+func TestUsage(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMonitor(".")
+	m.check()
+	assert.True(m.Status().MemoryBytes > 0)
+}
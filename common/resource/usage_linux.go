@@ -0,0 +1,36 @@
+// +build linux
+
+package resource
+
+import (
+	"os"
+	"syscall"
+)
+
+// freeDiskBytes returns the space available to the node on the file system of the path.
+func freeDiskBytes(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
+
+// openFiles returns the number of file descriptors open by the process and their limit.
+func openFiles() (uint64, uint64, error) {
+	dir, err := os.Open("/proc/self/fd")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return 0, 0, err
+	}
+	return uint64(len(names)), uint64(limit.Cur), nil
+}
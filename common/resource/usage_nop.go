@@ -0,0 +1,15 @@
+// +build !linux
+
+package resource
+
+import "errors"
+
+// freeDiskBytes returns the space available to the node on the file system of the path.
+func freeDiskBytes(path string) (uint64, error) {
+	return 0, errors.New("Not implemented")
+}
+
+// openFiles returns the number of file descriptors open by the process and their limit.
+func openFiles() (uint64, uint64, error) {
+	return 0, 0, errors.New("Not implemented")
+}
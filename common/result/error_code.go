@@ -13,6 +13,9 @@ const (
 	CodeEmptyPubKeyWithSequence1 ErrorCode = 100004
 	CodeUnauthorizedTx           ErrorCode = 100005
 	CodeInvalidFee               ErrorCode = 100006
+	CodeInvalidFeePayer          ErrorCode = 100007
+	CodeTxExpired                ErrorCode = 100008
+	CodeNonCanonicalSignature    ErrorCode = 100009
 
 	// ReserveFund Errors
 	CodeReserveFundCheckFailed   ErrorCode = 101001
@@ -41,4 +44,33 @@ const (
 	CodeInsufficientStake       ErrorCode = 106003
 	CodeNotEnoughBalanceToStake ErrorCode = 106004
 	CodeStakeExceedsCap         ErrorCode = 106005
+
+	// TokenRegistry Errors
+	CodeUnauthorizedToUpdateTokenNamespace ErrorCode = 107001
+	CodeInvalidTokenMetadata               ErrorCode = 107002
+
+	// ValidatorKeyRotation Errors
+	CodeNotAValidatorCandidate    ErrorCode = 108001
+	CodeInvalidKeyActivationEpoch ErrorCode = 108002
+
+	// IBC Errors
+	CodeInvalidIBCMsg    ErrorCode = 109001
+	CodeIBCStateNotFound ErrorCode = 109002
+	CodeIBCInvalidState  ErrorCode = 109003
+	CodeIBCInvalidProof  ErrorCode = 109004
+	CodeIBCUnauthorized  ErrorCode = 109005
+	CodeIBCPacketTimeout ErrorCode = 109006
+
+	// BandwidthProof Errors
+	CodeInvalidBandwidthProof     ErrorCode = 110001
+	CodeBandwidthProofReplayed    ErrorCode = 110002
+	CodeBandwidthRewardExceedsCap ErrorCode = 110003
+	CodeChargeReservedFundFailed  ErrorCode = 110004
+
+	// Governance Errors
+	CodeInvalidGovProposal     ErrorCode = 111001
+	CodeGovProposalNotFound    ErrorCode = 111002
+	CodeGovVotingClosed        ErrorCode = 111003
+	CodeInvalidGovVote         ErrorCode = 111004
+	CodeInsufficientGovDeposit ErrorCode = 111005
 )
@@ -0,0 +1,221 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	exportQueueSize = 4096
+	exportBatchSize = 512
+	exportInterval  = 5 * time.Second
+	exportTimeout   = 10 * time.Second
+
+	// The OTLP status code and span kind of the exported spans
+	statusCodeError  = 2
+	spanKindInternal = 1
+)
+
+// Exporter sends the finished spans in batches to an OpenTelemetry collector with the OTLP/HTTP
+// JSON encoding. The spans are dropped if the collector cannot keep up.
+type Exporter struct {
+	url        string
+	attributes []otlpKeyValue
+	client     *http.Client
+
+	queue chan *Span
+	quit  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewExporter creates an exporter which posts the spans to the "/v1/traces" path of the
+// collector endpoint, e.g. "http://127.0.0.1:4318". The service name and instance ID identify
+// the node in the traces.
+func NewExporter(endpoint string, serviceName string, instanceID string) *Exporter {
+	exp := &Exporter{
+		url: strings.TrimRight(endpoint, "/") + "/v1/traces",
+		attributes: []otlpKeyValue{
+			newKeyValue("service.name", serviceName),
+			newKeyValue("service.instance.id", instanceID),
+		},
+		client: &http.Client{Timeout: exportTimeout},
+		queue:  make(chan *Span, exportQueueSize),
+		quit:   make(chan struct{}),
+	}
+
+	exp.wg.Add(1)
+	go exp.mainLoop()
+
+	return exp
+}
+
+// Stop exports the queued spans and stops the exporter.
+func (exp *Exporter) Stop() {
+	close(exp.quit)
+	exp.wg.Wait()
+}
+
+func (exp *Exporter) add(span *Span) {
+	select {
+	case exp.queue <- span:
+	default:
+	}
+}
+
+func (exp *Exporter) mainLoop() {
+	defer exp.wg.Done()
+
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := []*Span{}
+	for {
+		select {
+		case span := <-exp.queue:
+			batch = append(batch, span)
+			if len(batch) < exportBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-exp.quit:
+			for len(exp.queue) > 0 {
+				batch = append(batch, <-exp.queue)
+			}
+			exp.export(batch)
+			return
+		}
+		exp.export(batch)
+		batch = []*Span{}
+	}
+}
+
+func (exp *Exporter) export(spans []*Span) {
+	if len(spans) == 0 {
+		return
+	}
+	body, err := json.Marshal(exp.encode(spans))
+	if err != nil {
+		log.Errorf("Failed to encode the trace spans: %v", err)
+		return
+	}
+	resp, err := exp.client.Post(exp.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Warnf("Failed to export %v trace spans to %v: %v", len(spans), exp.url, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Warnf("Failed to export %v trace spans to %v: %v", len(spans), exp.url, resp.Status)
+	}
+}
+
+// ------------------------------ OTLP JSON encoding ------------------------------
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+func (exp *Exporter) encode(spans []*Span) *otlpRequest {
+	ospans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		span.mu.Lock()
+		ospan := otlpSpan{
+			TraceID:           hex.EncodeToString(span.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.SpanID[:]),
+			Name:              span.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+		}
+		if span.ParentID != (SpanID{}) {
+			ospan.ParentSpanID = hex.EncodeToString(span.ParentID[:])
+		}
+		for key, value := range span.Attributes {
+			ospan.Attributes = append(ospan.Attributes, newKeyValue(key, value))
+		}
+		if span.Err != "" {
+			ospan.Status = &otlpStatus{Code: statusCodeError, Message: span.Err}
+		}
+		span.mu.Unlock()
+		ospans = append(ospans, ospan)
+	}
+
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource:   otlpResource{Attributes: exp.attributes},
+			ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "theta"}, Spans: ospans}},
+		}},
+	}
+}
+
+// newKeyValue encodes an attribute, the 64-bit integers are encoded as strings as required by
+// the OTLP JSON encoding.
+func newKeyValue(key string, value interface{}) otlpKeyValue {
+	var v map[string]interface{}
+	switch value := value.(type) {
+	case bool:
+		v = map[string]interface{}{"boolValue": value}
+	case int:
+		v = map[string]interface{}{"intValue": strconv.FormatInt(int64(value), 10)}
+	case int64:
+		v = map[string]interface{}{"intValue": strconv.FormatInt(value, 10)}
+	case uint64:
+		v = map[string]interface{}{"intValue": strconv.FormatUint(value, 10)}
+	case float64:
+		v = map[string]interface{}{"doubleValue": value}
+	case string:
+		v = map[string]interface{}{"stringValue": value}
+	default:
+		v = map[string]interface{}{"stringValue": fmt.Sprintf("%v", value)}
+	}
+	return otlpKeyValue{Key: key, Value: v}
+}
@@ -0,0 +1,195 @@
+// Package tracing records the spans of the block processing pipeline, from the receipt of a
+// block to the commit of its state, and exports them to an OpenTelemetry collector with the
+// OTLP/HTTP protocol.
+//
+// The stages of the pipeline run in different goroutines which do not share a context. The
+// spans of a block are therefore put into the trace derived from the block hash, so that they
+// are grouped together without passing the context along the pipeline.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+)
+
+type TraceID [16]byte
+type SpanID [8]byte
+
+// Span is a timed operation of a trace. A nil Span is valid and records nothing, which is what
+// the Start functions return when tracing is disabled or the trace is not sampled.
+type Span struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	ParentID   SpanID // zero for the root spans
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]interface{}
+	Err        string
+
+	mu       sync.Mutex
+	exporter *Exporter
+}
+
+type spanContextKey struct{}
+
+var (
+	mu       sync.RWMutex
+	exporter *Exporter
+	ratio    float64
+)
+
+// Init enables tracing, the spans are sampled with the given ratio and exported by exp.
+func Init(exp *Exporter, sampleRatio float64) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	exporter = exp
+	ratio = sampleRatio
+}
+
+// Shutdown disables tracing and flushes the spans not exported yet.
+func Shutdown() {
+	mu.Lock()
+	exp := exporter
+	exporter = nil
+	mu.Unlock()
+
+	if exp != nil {
+		exp.Stop()
+	}
+}
+
+// Enabled returns whether tracing is enabled.
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return exporter != nil
+}
+
+// StartSpan starts a span which is the child of the span of ctx, or the root of a new trace.
+// The returned context carries the new span.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	if parent := SpanFromContext(ctx); parent != nil {
+		return startSpan(ctx, parent.exporter, parent.TraceID, parent.SpanID, name)
+	}
+
+	exp, sampleRatio := current()
+	if exp == nil {
+		return ctx, nil
+	}
+	var traceID TraceID
+	rand.Read(traceID[:])
+	if !sampled(traceID, sampleRatio) {
+		return ctx, nil
+	}
+	return startSpan(ctx, exp, traceID, SpanID{}, name)
+}
+
+// StartBlockSpan starts a span of the processing of the given block. It is the child of the span
+// of ctx if any, otherwise it is put into the trace of the block.
+func StartBlockSpan(ctx context.Context, name string, blockHash common.Hash) (context.Context, *Span) {
+	if SpanFromContext(ctx) != nil {
+		ctx, span := StartSpan(ctx, name)
+		span.SetAttribute("block.hash", blockHash.Hex())
+		return ctx, span
+	}
+
+	exp, sampleRatio := current()
+	if exp == nil {
+		return ctx, nil
+	}
+	traceID := BlockTraceID(blockHash)
+	if !sampled(traceID, sampleRatio) {
+		return ctx, nil
+	}
+	ctx, span := startSpan(ctx, exp, traceID, SpanID{}, name)
+	span.SetAttribute("block.hash", blockHash.Hex())
+	return ctx, span
+}
+
+// BlockTraceID returns the ID of the trace of the given block.
+func BlockTraceID(blockHash common.Hash) TraceID {
+	var traceID TraceID
+	copy(traceID[:], blockHash[:len(traceID)])
+	return traceID
+}
+
+// SpanFromContext returns the span carried by ctx, or nil if there is none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// SetAttribute sets an attribute of the span. The values are exported as strings unless they
+// are booleans, integers or floats.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes[key] = value
+}
+
+// SetError marks the span as failed with the given error, a nil error is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Err = err.Error()
+}
+
+// Finish ends the span and queues it for export, calling it again has no effect.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	finished := !s.End.IsZero()
+	if !finished {
+		s.End = time.Now()
+	}
+	s.mu.Unlock()
+	if !finished {
+		s.exporter.add(s)
+	}
+}
+
+func current() (*Exporter, float64) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return exporter, ratio
+}
+
+func startSpan(ctx context.Context, exp *Exporter, traceID TraceID, parentID SpanID, name string) (context.Context, *Span) {
+	span := &Span{
+		TraceID:    traceID,
+		ParentID:   parentID,
+		Name:       name,
+		Start:      time.Now(),
+		Attributes: make(map[string]interface{}),
+		exporter:   exp,
+	}
+	rand.Read(span.SpanID[:])
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// sampled decides from the trace ID whether a trace is sampled, so that all the spans of a block
+// trace are sampled consistently across the goroutines.
+func sampled(traceID TraceID, sampleRatio float64) bool {
+	if sampleRatio >= 1 {
+		return true
+	}
+	if sampleRatio <= 0 {
+		return false
+	}
+	return float64(binary.BigEndian.Uint64(traceID[8:])) < sampleRatio*(1<<64)
+}
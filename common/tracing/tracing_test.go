@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestTracingDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, span := StartBlockSpan(context.Background(), "sync.receive_block", common.HexToHash("0x01"))
+	assert.Nil(span)
+	assert.Nil(SpanFromContext(ctx))
+
+	// A nil span records nothing
+	span.SetAttribute("height", 1)
+	span.SetError(errors.New("failed"))
+	span.Finish()
+}
+
+func TestBlockSpans(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var mu sync.Mutex
+	received := []*otlpRequest{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/v1/traces", r.URL.Path)
+		req := &otlpRequest{}
+		assert.Nil(json.NewDecoder(r.Body).Decode(req))
+		mu.Lock()
+		received = append(received, req)
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	Init(NewExporter(collector.URL, "theta", "node1"), 1)
+	require.True(Enabled())
+
+	blockHash := common.HexToHash("0x0102030405060708091011121314151617181920212223242526272829303132")
+	_, syncSpan := StartBlockSpan(context.Background(), "sync.receive_block", blockHash)
+	syncSpan.Finish()
+
+	ctx, applySpan := StartBlockSpan(context.Background(), "ledger.apply_block", blockHash)
+	applySpan.SetAttribute("height", uint64(100))
+	_, commitSpan := StartSpan(ctx, "state.commit")
+	commitSpan.SetError(errors.New("state root mismatch"))
+	commitSpan.Finish()
+	commitSpan.Finish()
+	applySpan.Finish()
+
+	// The spans of the block share the trace derived from the block hash
+	assert.Equal(BlockTraceID(blockHash), syncSpan.TraceID)
+	assert.Equal(BlockTraceID(blockHash), applySpan.TraceID)
+	assert.Equal(BlockTraceID(blockHash), commitSpan.TraceID)
+	assert.Equal(applySpan.SpanID, commitSpan.ParentID)
+	assert.Equal(SpanID{}, applySpan.ParentID)
+
+	Shutdown()
+	assert.False(Enabled())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(1, len(received))
+	rs := received[0].ResourceSpans
+	require.Equal(1, len(rs))
+	assert.Equal("service.name", rs[0].Resource.Attributes[0].Key)
+	assert.Equal("theta", rs[0].Resource.Attributes[0].Value["stringValue"])
+	spans := rs[0].ScopeSpans[0].Spans
+	require.Equal(3, len(spans))
+
+	assert.Equal("sync.receive_block", spans[0].Name)
+	assert.Equal(hex.EncodeToString(blockHash[:16]), spans[0].TraceID)
+	assert.Equal("", spans[0].ParentSpanID)
+	assert.Nil(spans[0].Status)
+
+	assert.Equal("state.commit", spans[1].Name)
+	assert.Equal(hex.EncodeToString(applySpan.SpanID[:]), spans[1].ParentSpanID)
+	require.NotNil(spans[1].Status)
+	assert.Equal(statusCodeError, spans[1].Status.Code)
+	assert.Equal("state root mismatch", spans[1].Status.Message)
+
+	assert.Equal("ledger.apply_block", spans[2].Name)
+	attributes := map[string]map[string]interface{}{}
+	for _, kv := range spans[2].Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	assert.Equal("100", attributes["height"]["intValue"])
+	assert.Equal(blockHash.Hex(), attributes["block.hash"]["stringValue"])
+}
+
+func TestSampling(t *testing.T) {
+	assert := assert.New(t)
+
+	traceID := TraceID{}
+	assert.True(sampled(traceID, 1))
+	assert.False(sampled(traceID, 0))
+	assert.True(sampled(traceID, 0.5))
+
+	traceID[8] = 0xc0
+	assert.False(sampled(traceID, 0.5))
+	assert.True(sampled(traceID, 0.8))
+}
@@ -3,6 +3,8 @@ package util
 import (
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -11,6 +13,12 @@ import (
 
 var logLevels map[string]string
 
+// moduleLoggers tracks the loggers created for each module, so that their levels can be changed at runtime
+var (
+	moduleLoggers   = make(map[string][]*log.Logger)
+	moduleLoggersMu sync.Mutex
+)
+
 const (
 	panicLevel = "panic"
 	fatalLevel = "fatal"
@@ -21,8 +29,14 @@ const (
 )
 const defaultLevel = warnLevel
 
+// InitLog applies the log config to the global logger, and to the module loggers created so far
+// such as the package level ones.
 func InitLog() {
+	moduleLoggersMu.Lock()
+	defer moduleLoggersMu.Unlock()
+
 	logLevels = parseLogLevelConfig(viper.GetString(common.CfgLogLevels))
+	log.SetFormatter(newLogFormatter())
 	log.Infof("Log settings: %v, %v", logLevels, viper.GetString(common.CfgLogLevels))
 	if logLevels["*"] == panicLevel {
 		log.SetLevel(log.PanicLevel)
@@ -37,16 +51,79 @@ func InitLog() {
 	} else {
 		log.SetLevel(log.DebugLevel)
 	}
+
+	for module, loggers := range moduleLoggers {
+		for _, logger := range loggers {
+			logger.Formatter = newLogFormatter()
+			setModuleLevel(logger, module)
+		}
+	}
+}
+
+// newLogFormatter creates the formatter of the format set by the config, either "text" or "json".
+func newLogFormatter() log.Formatter {
+	if viper.GetString(common.CfgLogFormat) == "json" {
+		return &JSONFormatter{JSONFormatter: log.JSONFormatter{TimestampFormat: time.RFC3339Nano}}
+	}
+
+	customFormatter := new(TextFormatter)
+	customFormatter.TimestampFormat = "2006-01-02 15:04:05"
+	customFormatter.FullTimestamp = true
+	customFormatter.ForceFormatting = true
+	return customFormatter
+}
+
+// JSONFormatter formats the entries as JSON objects, one per line, with the module of the
+// logger in the "module" field.
+type JSONFormatter struct {
+	log.JSONFormatter
+}
+
+func (f *JSONFormatter) Format(entry *log.Entry) ([]byte, error) {
+	module, ok := entry.Data["prefix"]
+	if !ok {
+		return f.JSONFormatter.Format(entry)
+	}
+
+	data := make(log.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		data[key] = value
+	}
+	delete(data, "prefix")
+	data["module"] = module
+
+	e := *entry
+	e.Data = data
+	return f.JSONFormatter.Format(&e)
+}
+
+// ReloadLog re-applies the log config at runtime, e.g. after the config file is reloaded. Unlike
+// InitLog it returns an error rather than panicking if the log levels cannot be parsed, and then
+// leaves the current levels unchanged.
+func ReloadLog() error {
+	if _, err := parseLogLevels(viper.GetString(common.CfgLogLevels)); err != nil {
+		return err
+	}
+	InitLog()
+	return nil
 }
 
 func parseLogLevelConfig(config string) map[string]string {
+	levels, err := parseLogLevels(config)
+	if err != nil {
+		panic(err.Error())
+	}
+	return levels
+}
+
+func parseLogLevels(config string) (map[string]string, error) {
 	levels := make(map[string]string)
 
 	moduleAndLevels := strings.Split(config, ",")
 	for _, moduleAndLevel := range moduleAndLevels {
 		tokens := strings.Split(moduleAndLevel, ":")
 		if len(tokens) != 2 {
-			panic(fmt.Sprintf("Failed to parse module log level: \"%v\"", moduleAndLevel))
+			return nil, fmt.Errorf("Failed to parse module log level: \"%v\"", moduleAndLevel)
 		}
 		levels[strings.TrimSpace(tokens[0])] = strings.TrimSpace(tokens[1])
 	}
@@ -54,20 +131,29 @@ func parseLogLevelConfig(config string) map[string]string {
 	if _, ok := levels["*"]; !ok {
 		levels["*"] = defaultLevel
 	}
-	return levels
+	return levels, nil
 }
 
 // GetLoggerForModule returns the logger for given module.
 func GetLoggerForModule(module string) *log.Entry {
-	customFormatter := new(TextFormatter)
-	customFormatter.TimestampFormat = "2006-01-02 15:04:05"
+	customFormatter := newLogFormatter()
 	log.SetFormatter(customFormatter)
-	customFormatter.FullTimestamp = true
-	customFormatter.ForceFormatting = true
 
 	logger := log.New()
 	logger.Formatter = customFormatter
+	logger.AddHook(recentLogs)
+
+	moduleLoggersMu.Lock()
+	setModuleLevel(logger, module)
+	moduleLoggers[module] = append(moduleLoggers[module], logger)
+	moduleLoggersMu.Unlock()
 
+	return logger.WithFields(log.Fields{"prefix": module})
+}
+
+// setModuleLevel sets the level of the logger to the level of its module. The loggers created
+// before the config is loaded keep the default level until InitLog is called.
+func setModuleLevel(logger *log.Logger, module string) {
 	level, ok := logLevels[module]
 	if !ok {
 		level = logLevels["*"]
@@ -86,6 +172,65 @@ func GetLoggerForModule(module string) *log.Entry {
 	} else if level == debugLevel {
 		logger.SetLevel(log.DebugLevel)
 	}
+}
 
-	return logger.WithFields(log.Fields{"prefix": module})
+// GetLogLevels returns the log level of each module with a logger or a configured level, "*" is
+// the level of the modules not listed.
+func GetLogLevels() map[string]string {
+	moduleLoggersMu.Lock()
+	defer moduleLoggersMu.Unlock()
+
+	levels := make(map[string]string)
+	for module, level := range logLevels {
+		levels[module] = level
+	}
+	if _, ok := levels["*"]; !ok {
+		levels["*"] = defaultLevel
+	}
+	for module := range moduleLoggers {
+		if _, ok := levels[module]; !ok {
+			levels[module] = levels["*"]
+		}
+	}
+	return levels
+}
+
+// SetLogLevel changes the log level of the given module at runtime. Setting the level of "*"
+// changes the level of the modules without their own levels, along with the global logger.
+func SetLogLevel(module string, level string) error {
+	level = strings.ToLower(strings.TrimSpace(level))
+	switch level {
+	case panicLevel, fatalLevel, errorLevel, warnLevel, infoLevel, debugLevel:
+	default:
+		return fmt.Errorf("Invalid log level: %v", level)
+	}
+	lvl, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	moduleLoggersMu.Lock()
+	defer moduleLoggersMu.Unlock()
+
+	if logLevels == nil {
+		logLevels = map[string]string{"*": defaultLevel}
+	}
+	logLevels[module] = level
+	if module != "*" {
+		for _, logger := range moduleLoggers[module] {
+			logger.SetLevel(lvl)
+		}
+		return nil
+	}
+
+	log.SetLevel(lvl)
+	for m, loggers := range moduleLoggers {
+		if _, ok := logLevels[m]; ok {
+			continue
+		}
+		for _, logger := range loggers {
+			logger.SetLevel(lvl)
+		}
+	}
+	return nil
 }
@@ -3,10 +3,14 @@
 package util
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
 )
 
 func TestParseLogLevelConfig(t *testing.T) {
@@ -35,3 +39,97 @@ func TestGetLoggerForModule(t *testing.T) {
 	assert.Equal(log.InfoLevel, GetLoggerForModule("consensus").Logger.Level)
 	assert.Equal(log.ErrorLevel, GetLoggerForModule("sync").Logger.Level)
 }
+
+func TestSetLogLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	logLevels = parseLogLevelConfig("*:error,p2p:debug")
+	p2pLogger := GetLoggerForModule("p2p")
+	syncLogger := GetLoggerForModule("sync")
+
+	assert.Nil(SetLogLevel("sync", "info"))
+	assert.Equal(log.InfoLevel, syncLogger.Logger.Level)
+	assert.Equal(log.DebugLevel, p2pLogger.Logger.Level)
+	assert.Equal("info", GetLogLevels()["sync"])
+
+	// The modules with their own levels are not affected by "*"
+	consensusLogger := GetLoggerForModule("consensus")
+	assert.Nil(SetLogLevel("*", "warn"))
+	assert.Equal(log.WarnLevel, consensusLogger.Logger.Level)
+	assert.Equal(log.InfoLevel, syncLogger.Logger.Level)
+	assert.Equal(log.DebugLevel, p2pLogger.Logger.Level)
+
+	assert.NotNil(SetLogLevel("p2p", "verbose"))
+	assert.Equal(log.DebugLevel, p2pLogger.Logger.Level)
+}
+
+func TestInitLogAppliesToExistingLoggers(t *testing.T) {
+	assert := assert.New(t)
+
+	// Package level loggers are created before the config is loaded
+	logLevels = nil
+	ledgerLogger := GetLoggerForModule("ledger")
+
+	viper.Set(common.CfgLogLevels, "*:error,ledger:debug")
+	defer viper.Set(common.CfgLogLevels, "")
+	InitLog()
+
+	assert.Equal(log.DebugLevel, ledgerLogger.Logger.Level)
+	assert.Equal("debug", GetLogLevels()["ledger"])
+}
+
+func TestReloadLog(t *testing.T) {
+	assert := assert.New(t)
+
+	logLevels = nil
+	syncLogger := GetLoggerForModule("netsync")
+
+	viper.Set(common.CfgLogLevels, "*:error,netsync:info")
+	defer viper.Set(common.CfgLogLevels, "")
+	assert.Nil(ReloadLog())
+	assert.Equal(log.InfoLevel, syncLogger.Logger.Level)
+
+	// An invalid config is rejected and the levels are unchanged
+	viper.Set(common.CfgLogLevels, "*:error,netsync")
+	assert.NotNil(ReloadLog())
+	assert.Equal(log.InfoLevel, syncLogger.Logger.Level)
+}
+
+func TestJSONFormatter(t *testing.T) {
+	assert := assert.New(t)
+
+	viper.Set(common.CfgLogFormat, "json")
+	defer viper.Set(common.CfgLogFormat, "text")
+
+	logger := GetLoggerForModule("mempool")
+	buf := &bytes.Buffer{}
+	logger.Logger.Out = buf
+	logger.Logger.SetLevel(log.InfoLevel)
+	logger.WithFields(log.Fields{"height": 10}).Info("Block finalized")
+
+	entry := map[string]interface{}{}
+	assert.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal("mempool", entry["module"])
+	assert.Equal("Block finalized", entry["msg"])
+	assert.Equal("info", entry["level"])
+	assert.Equal(float64(10), entry["height"])
+	assert.Nil(entry["prefix"])
+}
+
+func TestLogTail(t *testing.T) {
+	assert := assert.New(t)
+
+	tail := newLogTail(3)
+	assert.Equal([]string{}, tail.recent())
+
+	logger := log.New()
+	logger.Formatter = &log.TextFormatter{DisableTimestamp: true}
+	logger.AddHook(tail)
+	logger.Warn("first")
+	logger.Warn("second")
+	assert.Equal([]string{"level=warning msg=first", "level=warning msg=second"}, tail.recent())
+
+	logger.Warn("third")
+	logger.Warn("fourth")
+	assert.Equal([]string{"level=warning msg=second", "level=warning msg=third", "level=warning msg=fourth"}, tail.recent())
+}
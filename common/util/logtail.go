@@ -0,0 +1,64 @@
+package util
+
+import (
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// logTailSize is the number of the most recent log lines kept for the diagnostic bundles
+const logTailSize = 1000
+
+// logTail is a hook of all the loggers which keeps the most recent log lines in a ring buffer.
+type logTail struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+var recentLogs = newLogTail(logTailSize)
+
+func init() {
+	log.AddHook(recentLogs)
+}
+
+func newLogTail(size int) *logTail {
+	return &logTail{lines: make([]string, size)}
+}
+
+func (t *logTail) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (t *logTail) Fire(entry *log.Entry) error {
+	line, err := entry.String()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lines[t.next] = strings.TrimRight(line, "\n")
+	t.next = (t.next + 1) % len(t.lines)
+	if t.next == 0 {
+		t.full = true
+	}
+	return nil
+}
+
+func (t *logTail) recent() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.full {
+		return append([]string{}, t.lines[:t.next]...)
+	}
+	return append(append([]string{}, t.lines[t.next:]...), t.lines[:t.next]...)
+}
+
+// RecentLogs returns the most recent log lines of all the loggers, the oldest first.
+func RecentLogs() []string {
+	return recentLogs.recent()
+}
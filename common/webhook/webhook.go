@@ -0,0 +1,228 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//
+// The webhook dispatcher POSTs the node events as JSON to the endpoints configured by the
+// operator. Each endpoint is served by its own goroutine, so a slow or unreachable endpoint
+// does not delay the others, and receives the events in order. A failed delivery is retried
+// with an exponential backoff, and dropped once the retries are exhausted.
+//
+// If a secret is set, each request is signed with HMAC-SHA256 over "<timestamp>.<body>", where
+// the timestamp is the X-Theta-Timestamp header. The signature is sent in the X-Theta-Signature
+// header as "sha256=<hex>", and the receivers should reject the requests with a stale timestamp.
+//
+
+const (
+	endpointQueueSize = 1024
+	requestTimeout    = 10 * time.Second
+	minRetryBackoff   = 1 * time.Second
+	maxRetryBackoff   = 60 * time.Second
+
+	HeaderEvent     = "X-Theta-Event"
+	HeaderDelivery  = "X-Theta-Delivery"
+	HeaderTimestamp = "X-Theta-Timestamp"
+	HeaderSignature = "X-Theta-Signature"
+)
+
+// Event is the payload posted to the endpoints
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Timestamp int64       `json:"timestamp"` // unix time in seconds
+	Data      interface{} `json:"data"`
+}
+
+// Endpoint is a URL along with the types of the events it subscribes to
+type Endpoint struct {
+	URL    string
+	Events map[string]bool // nil for all the events
+}
+
+// ParseEndpoint parses an endpoint in the format of "<event>,<event>=<url>", or "*=<url>" to
+// subscribe to all the events.
+func ParseEndpoint(entry string) (Endpoint, error) {
+	parts := strings.SplitN(entry, "=", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return Endpoint{}, fmt.Errorf("Invalid webhook endpoint, expected <events>=<url>: %v", entry)
+	}
+	if !strings.HasPrefix(parts[1], "http://") && !strings.HasPrefix(parts[1], "https://") {
+		return Endpoint{}, fmt.Errorf("Invalid webhook URL: %v", parts[1])
+	}
+	endpoint := Endpoint{URL: parts[1]}
+	if parts[0] != "*" {
+		endpoint.Events = make(map[string]bool)
+		for _, event := range strings.Split(parts[0], ",") {
+			endpoint.Events[strings.TrimSpace(event)] = true
+		}
+	}
+	return endpoint, nil
+}
+
+// Sign returns the signature of the body sent at the given timestamp
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers the events to the endpoints
+type Dispatcher struct {
+	workers []*worker
+	wg      sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher, a delivery is attempted up to maxRetries+1 times
+func NewDispatcher(endpoints []Endpoint, secret string, maxRetries int) *Dispatcher {
+	d := &Dispatcher{}
+	client := &http.Client{Timeout: requestTimeout}
+	for _, endpoint := range endpoints {
+		w := &worker{
+			endpoint:   endpoint,
+			secret:     []byte(secret),
+			maxRetries: maxRetries,
+			client:     client,
+			queue:      make(chan *Event, endpointQueueSize),
+			quit:       make(chan struct{}),
+		}
+		d.workers = append(d.workers, w)
+		d.wg.Add(1)
+		go w.mainLoop(&d.wg)
+	}
+	return d
+}
+
+// Notify queues an event of the given type for the endpoints subscribing to it
+func (d *Dispatcher) Notify(eventType string, data interface{}) {
+	event := &Event{
+		ID:        newEventID(),
+		Type:      eventType,
+		Timestamp: time.Now().Unix(),
+		Data:      data,
+	}
+	for _, w := range d.workers {
+		if w.endpoint.Events != nil && !w.endpoint.Events[eventType] {
+			continue
+		}
+		select {
+		case w.queue <- event:
+		default:
+			log.Warnf("Webhook queue of %v is full, dropped the %v event", w.endpoint.URL, eventType)
+		}
+	}
+}
+
+// Stop delivers the queued events without retrying, and stops the dispatcher.
+func (d *Dispatcher) Stop() {
+	for _, w := range d.workers {
+		close(w.quit)
+	}
+	d.wg.Wait()
+}
+
+func newEventID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+type worker struct {
+	endpoint   Endpoint
+	secret     []byte
+	maxRetries int
+	client     *http.Client
+
+	queue chan *Event
+	quit  chan struct{}
+}
+
+func (w *worker) mainLoop(wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		select {
+		case event := <-w.queue:
+			w.deliver(event)
+		case <-w.quit:
+			for len(w.queue) > 0 {
+				w.deliver(<-w.queue)
+			}
+			return
+		}
+	}
+}
+
+func (w *worker) deliver(event *Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("Failed to encode the %v webhook event: %v", event.Type, err)
+		return
+	}
+
+	backoff := minRetryBackoff
+	for attempt := 0; ; attempt++ {
+		retriable, err := w.post(event, body)
+		if err == nil {
+			return
+		}
+		if !retriable || attempt >= w.maxRetries {
+			log.Warnf("Failed to deliver the %v webhook event %v to %v: %v", event.Type, event.ID, w.endpoint.URL, err)
+			return
+		}
+		select {
+		case <-time.After(backoff):
+		case <-w.quit:
+			log.Warnf("Failed to deliver the %v webhook event %v to %v before shutdown: %v", event.Type, event.ID, w.endpoint.URL, err)
+			return
+		}
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// post sends the event, and returns whether the failure, if any, is worth retrying, i.e. a network
+// error, a server error or a rate limit.
+func (w *worker) post(event *Event, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if len(w.secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(w.secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retriable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retriable, fmt.Errorf("%v", resp.Status)
+}
@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	endpoint, err := ParseEndpoint("*=https://example.com/hook?token=a=b")
+	assert.Nil(err)
+	assert.Equal("https://example.com/hook?token=a=b", endpoint.URL)
+	assert.Nil(endpoint.Events)
+
+	endpoint, err = ParseEndpoint("block_finalized, funds_received=http://127.0.0.1:8080")
+	assert.Nil(err)
+	assert.Equal(map[string]bool{"block_finalized": true, "funds_received": true}, endpoint.Events)
+
+	_, err = ParseEndpoint("https://example.com/hook")
+	assert.NotNil(err)
+	_, err = ParseEndpoint("*=example.com")
+	assert.NotNil(err)
+}
+
+func TestDispatcher(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	secret := "s3cret"
+	mu := &sync.Mutex{}
+	attempts := 0
+	received := []*Event{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(Sign([]byte(secret), r.Header.Get(HeaderTimestamp), body), r.Header.Get(HeaderSignature))
+
+		// The first attempt fails, and is retried
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		event := &Event{}
+		assert.Nil(json.Unmarshal(body, event))
+		assert.Equal(event.Type, r.Header.Get(HeaderEvent))
+		assert.Equal(event.ID, r.Header.Get(HeaderDelivery))
+		received = append(received, event)
+	}))
+	defer server.Close()
+
+	endpoint, err := ParseEndpoint("block_finalized=" + server.URL)
+	require.Nil(err)
+	d := NewDispatcher([]Endpoint{endpoint}, secret, 3)
+	d.Notify("block_finalized", map[string]uint64{"height": 1})
+	d.Notify("node_fell_behind", map[string]uint64{"height": 1}) // not subscribed
+	d.Notify("block_finalized", map[string]uint64{"height": 2})
+
+	// Wait for the retry before stopping, the queued events are not retried on shutdown
+	numReceived := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received)
+	}
+	for i := 0; i < 50 && numReceived() < 2; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	d.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(3, attempts)
+	require.Equal(2, len(received))
+	assert.Equal(float64(1), received[0].Data.(map[string]interface{})["height"])
+	assert.Equal(float64(2), received[1].Data.(map[string]interface{})["height"])
+}
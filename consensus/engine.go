@@ -15,7 +15,10 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/diagnostic"
+	"github.com/thetatoken/theta/common/metrics"
 	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/common/tracing"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
@@ -24,7 +27,7 @@ import (
 	"github.com/thetatoken/theta/store"
 )
 
-var logger = log.WithFields(log.Fields{"prefix": "consensus"})
+var logger = util.GetLoggerForModule("consensus")
 
 var _ core.ConsensusEngine = (*ConsensusEngine)(nil)
 
@@ -32,7 +35,8 @@ var _ core.ConsensusEngine = (*ConsensusEngine)(nil)
 type ConsensusEngine struct {
 	logger *log.Entry
 
-	privateKey *crypto.PrivateKey
+	signer           crypto.Signer
+	validatorAddress common.Address
 
 	chain            *blockchain.Chain
 	dispatcher       *dispatcher.Dispatcher
@@ -59,16 +63,23 @@ type ConsensusEngine struct {
 	voteTimerReady bool
 	blockProcessed bool
 
+	// Metrics
+	epochStart    time.Time
+	epochDuration metrics.Timer
+	epochTimeouts metrics.Counter
+
 	state *State
 }
 
-// NewConsensusEngine creates a instance of ConsensusEngine.
-func NewConsensusEngine(privateKey *crypto.PrivateKey, db store.Store, chain *blockchain.Chain, dispatcher *dispatcher.Dispatcher, validatorManager core.ValidatorManager) *ConsensusEngine {
+// NewConsensusEngine creates a instance of ConsensusEngine. The signer is usually the private key
+// of the validator, or a hardware security module holding it.
+func NewConsensusEngine(signer crypto.Signer, db store.Store, chain *blockchain.Chain, dispatcher *dispatcher.Dispatcher, validatorManager core.ValidatorManager) *ConsensusEngine {
 	e := &ConsensusEngine{
 		chain:      chain,
 		dispatcher: dispatcher,
 
-		privateKey: privateKey,
+		signer:           signer,
+		validatorAddress: signer.PublicKey().Address(),
 
 		incoming:        make(chan interface{}, viper.GetInt(common.CfgConsensusMessageQueueSize)),
 		finalizedBlocks: make(chan *core.Block, viper.GetInt(common.CfgConsensusMessageQueueSize)),
@@ -87,7 +98,15 @@ func NewConsensusEngine(privateKey *crypto.PrivateKey, db store.Store, chain *bl
 	logger = util.GetLoggerForModule("consensus")
 	e.logger = logger
 
-	blsKey, err := bls.GenKey(strings.NewReader(common.Bytes2Hex(privateKey.PublicKey().ToBytes())))
+	if validatorAddress := viper.GetString(common.CfgConsensusValidatorAddress); validatorAddress != "" {
+		e.validatorAddress = common.HexToAddress(validatorAddress)
+	}
+
+	reg := metrics.ModuleRegistry("consensus")
+	e.epochDuration = metrics.GetOrRegisterTimer("epoch_duration", reg)
+	e.epochTimeouts = reg.GetOrRegister("epoch_timeouts", &metrics.StandardCounter{}).(metrics.Counter)
+
+	blsKey, err := bls.GenKey(strings.NewReader(common.Bytes2Hex(signer.PublicKey().ToBytes())))
 	if err != nil {
 		e.logger.Panic(err)
 	}
@@ -108,14 +127,20 @@ func (e *ConsensusEngine) GetLedger() core.Ledger {
 	return e.ledger
 }
 
-// ID returns the identifier of current node.
+// ID returns the identifier of current node, i.e. the validator address it votes and proposes for.
 func (e *ConsensusEngine) ID() string {
-	return e.privateKey.PublicKey().Address().Hex()
+	return e.validatorAddress.Hex()
 }
 
-// PrivateKey returns the private key
+// PrivateKey returns the private key, or nil if the signer doesn't expose the key
 func (e *ConsensusEngine) PrivateKey() *crypto.PrivateKey {
-	return e.privateKey
+	privateKey, _ := e.signer.(*crypto.PrivateKey)
+	return privateKey
+}
+
+// Signer returns the signer of the votes and blocks
+func (e *ConsensusEngine) Signer() crypto.Signer {
+	return e.signer
 }
 
 // Chain return a pointer to the underlying chain store.
@@ -258,6 +283,7 @@ func (e *ConsensusEngine) Wait() {
 
 func (e *ConsensusEngine) mainLoop() {
 	defer e.wg.Done()
+	defer diagnostic.Recover()
 
 	for {
 		e.enterEpoch()
@@ -280,6 +306,7 @@ func (e *ConsensusEngine) mainLoop() {
 				}
 			case <-e.epochTimer.C:
 				e.logger.WithFields(log.Fields{"e.epoch": e.GetEpoch()}).Debug("Epoch timeout. Repeating epoch")
+				e.epochTimeouts.Inc(1)
 				e.vote()
 				break Epoch
 			case <-e.guardianTimer.C:
@@ -307,6 +334,11 @@ func (e *ConsensusEngine) mainLoop() {
 func (e *ConsensusEngine) enterEpoch() {
 	logger.Debugf("Enter epoch %v", e.GetEpoch())
 
+	if !e.epochStart.IsZero() {
+		e.epochDuration.UpdateSince(e.epochStart)
+	}
+	e.epochStart = time.Now()
+
 	// Reset timers.
 	if e.epochTimer != nil {
 		e.epochTimer.Stop()
@@ -451,7 +483,8 @@ func (e *ConsensusEngine) validateBlock(block *core.Block, parent *core.Extended
 	}
 	if !hccBlock.Status.IsFinalized() {
 		hccValidators := e.validatorManager.GetValidatorSet(block.HCC.BlockHash)
-		if !block.HCC.IsValid(hccValidators) {
+		resolver := e.signingAddressResolver(block.HCC.BlockHash, hccBlock.Height)
+		if !block.HCC.IsValidWithSigningAddresses(hccValidators, resolver) {
 			e.logger.WithFields(log.Fields{
 				"parent":    block.Parent.Hex(),
 				"block":     block.Hash().Hex(),
@@ -506,6 +539,16 @@ func (e *ConsensusEngine) validateBlock(block *core.Block, parent *core.Extended
 		return result.Error("Invalid proposer")
 	}
 
+	signingAddress := e.getSigningAddress(block.Parent, block.Height, block.Proposer, block.Epoch)
+	if res := block.ValidateSignature(signingAddress); res.IsError() {
+		e.logger.WithFields(log.Fields{
+			"block.Epoch":    block.Epoch,
+			"block.proposer": block.Proposer.Hex(),
+			"signingAddress": signingAddress.Hex(),
+		}).Warn("Invalid proposer signature")
+		return result.Error("Invalid proposer signature")
+	}
+
 	// Validate Guardian Votes.
 	// We allow checkpoint blocs to have nil guardian votes.
 	if block.GuardianVotes != nil && block.Height >= common.HeightEnableTheta2 && common.IsCheckPointHeight(block.Height) {
@@ -712,6 +755,10 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 	start := time.Now()
 
 	block := eb.Block
+	ctx, span := tracing.StartBlockSpan(context.Background(), "consensus.handle_block", block.Hash())
+	span.SetAttribute("block.height", block.Height)
+	defer span.Finish()
+
 	if !eb.Status.IsPending() {
 		// Before consensus engine can process the first one, sync layer might send duplicate blocks.
 		e.logger.WithFields(log.Fields{
@@ -732,10 +779,14 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 	}
 
 	start1 := time.Now()
-	if e.validateBlock(block, parent).IsError() {
+	_, validateSpan := tracing.StartSpan(ctx, "consensus.validate_block")
+	res := e.validateBlock(block, parent)
+	validateSpan.Finish()
+	if res.IsError() {
 		e.logger.WithFields(log.Fields{
 			"block.Hash": block.Hash().Hex(),
 		}).Warn("Block is invalid")
+		span.SetError(fmt.Errorf("invalid block: %v", res.Message))
 		e.chain.MarkBlockInvalid(block.Hash())
 		return
 	}
@@ -820,7 +871,7 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 }
 
 func (e *ConsensusEngine) shouldVote(block common.Hash) bool {
-	return e.shouldVoteByID(e.privateKey.PublicKey().Address(), block)
+	return e.shouldVoteByID(e.validatorAddress, block)
 }
 
 func (e *ConsensusEngine) shouldVoteByID(id common.Address, block common.Hash) bool {
@@ -897,15 +948,16 @@ func (e *ConsensusEngine) createVote(block *core.Block) core.Vote {
 	vote := core.Vote{
 		Block:  block.Hash(),
 		Height: block.Height,
-		ID:     e.privateKey.PublicKey().Address(),
+		ID:     e.validatorAddress,
 		Epoch:  e.GetEpoch(),
 	}
-	vote.Sign(e.privateKey)
+	vote.Sign(e.signer)
 	return vote
 }
 
 func (e *ConsensusEngine) validateVote(vote core.Vote) bool {
-	if res := vote.Validate(); res.IsError() {
+	signingAddress := e.getSigningAddress(vote.Block, vote.Height, vote.ID, vote.Epoch)
+	if res := vote.ValidateWithSigningAddress(signingAddress); res.IsError() {
 		e.logger.WithFields(log.Fields{
 			"err": res.String(),
 		}).Warn("Ignoring invalid vote")
@@ -914,6 +966,36 @@ func (e *ConsensusEngine) validateVote(vote core.Vote) bool {
 	return true
 }
 
+// getSigningAddress returns the address of the key the validator signs with in the given epoch, which
+// differs from the validator address once the validator has rotated its signing key.
+func (e *ConsensusEngine) getSigningAddress(blockHash common.Hash, height uint64, validator common.Address, epoch uint64) common.Address {
+	if e.ledger == nil || height < common.HeightEnableValidatorKeyRotationTx {
+		return validator
+	}
+	signingAddress, err := e.ledger.GetValidatorSigningAddress(blockHash, validator, epoch)
+	if err != nil {
+		// The block might not have been processed yet, fall back to the last finalized state.
+		lfb := e.state.GetLastFinalizedBlock()
+		signingAddress, err = e.ledger.GetValidatorSigningAddress(lfb.Hash(), validator, epoch)
+	}
+	if err != nil {
+		e.logger.WithFields(log.Fields{
+			"block":     blockHash.Hex(),
+			"validator": validator.Hex(),
+			"err":       err,
+		}).Warn("Failed to get validator signing address")
+		return validator
+	}
+	return signingAddress
+}
+
+// signingAddressResolver resolves the signing addresses of the votes for the given block.
+func (e *ConsensusEngine) signingAddressResolver(blockHash common.Hash, height uint64) core.SigningAddressResolver {
+	return func(validator common.Address, epoch uint64) common.Address {
+		return e.getSigningAddress(blockHash, height, validator, epoch)
+	}
+}
+
 func (e *ConsensusEngine) handleVote(vote core.Vote) (endEpoch bool) {
 	// Validate vote.
 	if !e.validateVote(vote) {
@@ -1107,6 +1189,25 @@ func (e *ConsensusEngine) GetLastFinalizedBlock() *core.ExtendedBlock {
 	return e.state.GetLastFinalizedBlock()
 }
 
+// GetNetworkHeight returns the height finalized by the network as seen from the votes of the
+// current epoch, or zero if no votes have been received.
+func (e *ConsensusEngine) GetNetworkHeight() uint64 {
+	epochVotes, err := e.state.GetEpochVotes()
+	if err != nil || epochVotes == nil {
+		return 0
+	}
+	maxVoteHeight := uint64(0)
+	for _, v := range epochVotes.Votes() {
+		if v.Height > maxVoteHeight {
+			maxVoteHeight = v.Height
+		}
+	}
+	if maxVoteHeight == 0 {
+		return 0
+	}
+	return maxVoteHeight - 1 // the finalized height is at most maxVoteHeight-1
+}
+
 func (e *ConsensusEngine) processCCBlock(ccBlock *core.ExtendedBlock) {
 	if ccBlock.Height <= e.state.GetHighestCCBlock().Height {
 		return
@@ -1143,6 +1244,10 @@ func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
 
 	e.logger.WithFields(log.Fields{"block.Hash": block.Hash().Hex(), "block.Height": block.Height}).Info("Finalizing block")
 
+	_, span := tracing.StartBlockSpan(context.Background(), "consensus.finalize_block", block.Hash())
+	span.SetAttribute("block.height", block.Height)
+	defer span.Finish()
+
 	e.state.SetLastFinalizedBlock(block)
 	e.ledger.FinalizeState(block.Height, block.StateHash)
 
@@ -1254,7 +1359,7 @@ func (e *ConsensusEngine) createProposal(shouldIncludeValidatorUpdateTxs bool) (
 	block.Epoch = e.GetEpoch()
 	block.Parent = tip.Hash()
 	block.Height = tip.Height + 1
-	block.Proposer = e.privateKey.PublicKey().Address()
+	block.Proposer = e.validatorAddress
 	block.Timestamp = big.NewInt(time.Now().Unix())
 	block.HCC.BlockHash = e.state.GetHighestCCBlock().Hash()
 	hccValidators := e.validatorManager.GetValidatorSet(block.HCC.BlockHash)
@@ -1278,9 +1383,12 @@ func (e *ConsensusEngine) createProposal(shouldIncludeValidatorUpdateTxs bool) (
 	}
 	block.AddTxs(txs)
 	block.StateHash = newRoot
+	if receiptHash, ok := result.Info["receiptHash"]; ok {
+		block.ReceiptHash = receiptHash.(common.Hash)
+	}
 
 	// Sign block.
-	sig, err := e.privateKey.Sign(block.SignBytes())
+	sig, err := e.signer.Sign(block.SignBytes())
 	if err != nil {
 		e.logger.WithFields(log.Fields{"error": err}).Panic("Failed to sign vote")
 	}
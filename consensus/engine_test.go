@@ -96,6 +96,7 @@ func TestSingleBlockValidation(t *testing.T) {
 	_, err := chain.AddBlock(invalidBlock)
 	require.Nil(err)
 	invalidBlock.Height = chain.Root().Height + 1
+	invalidBlock.Signature, _ = privKey.Sign(invalidBlock.SignBytes())
 	res = ce.validateBlock(invalidBlock, chain.Root())
 	require.True(res.IsOK(), "Should be valid")
 	invalidBlock.Height = 0
@@ -114,6 +114,15 @@ func (s *State) Load() (err error) {
 	return
 }
 
+// Reload loads the state committed to the database again, e.g. after a read-only node switched
+// to a new checkpoint of the data directory of another node.
+func (s *State) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Load()
+}
+
 func (s *State) GetEpoch() uint64 {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -0,0 +1,112 @@
+package consensus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"sort"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/vrf"
+)
+
+//
+// -------------------------------- VRF based selection ----------------------------------
+//
+// Each validator evaluates the VRF on the block hash and the epoch with its key, and the proposer
+// or the committee of the epoch is selected by the outputs. The outputs can't be predicted before
+// the block is known, nor chosen by the validators, and anyone can verify them with the proofs.
+// A validator withholding its output only drops out of the selection. The score of a validator is
+// its output divided by its stake, the lowest scores being selected, which favours the validators
+// with more stake.
+//
+
+// ValidatorVRFOutput is the VRF proof of a validator for an epoch
+type ValidatorVRFOutput struct {
+	PublicKey *crypto.PublicKey
+	Proof     common.Bytes
+}
+
+// VRFInput returns the input of the VRF of the validators for the epoch following the block
+func VRFInput(blockHash common.Hash, epoch uint64) []byte {
+	input := make([]byte, 0, len("theta_vrf")+common.HashLength+8)
+	input = append(input, "theta_vrf"...)
+	input = append(input, blockHash[:]...)
+	var epochBytes [8]byte
+	binary.BigEndian.PutUint64(epochBytes[:], epoch)
+	return append(input, epochBytes[:]...)
+}
+
+// ProveValidatorVRF evaluates the VRF of the validator for the epoch following the block
+func ProveValidatorVRF(privKey *crypto.PrivateKey, blockHash common.Hash, epoch uint64) (*ValidatorVRFOutput, error) {
+	_, proof, err := vrf.Prove(privKey, VRFInput(blockHash, epoch))
+	if err != nil {
+		return nil, err
+	}
+	return &ValidatorVRFOutput{PublicKey: privKey.PublicKey(), Proof: proof}, nil
+}
+
+// Verify verifies the proof, and returns the address of the validator and its VRF output
+func (o *ValidatorVRFOutput) Verify(blockHash common.Hash, epoch uint64) (common.Address, []byte, error) {
+	beta, err := vrf.Verify(o.PublicKey, VRFInput(blockHash, epoch), o.Proof)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	return o.PublicKey.Address(), beta, nil
+}
+
+type vrfScore struct {
+	validator core.Validator
+	score     *big.Int
+}
+
+// SelectCommitteeByVRF selects up to the given number of validators with the lowest scores. The
+// outputs with invalid proofs, or of the accounts not in the validator set, are ignored.
+func SelectCommitteeByVRF(valSet *core.ValidatorSet, blockHash common.Hash, epoch uint64,
+	outputs []*ValidatorVRFOutput, size int) []core.Validator {
+	scores := []vrfScore{}
+	seen := make(map[common.Address]bool)
+	for _, output := range outputs {
+		address, beta, err := output.Verify(blockHash, epoch)
+		if err != nil || seen[address] {
+			continue
+		}
+		validator, err := valSet.GetValidator(address)
+		if err != nil || validator.Stake == nil || validator.Stake.Sign() <= 0 {
+			continue
+		}
+		seen[address] = true
+		score := new(big.Int).SetBytes(beta)
+		score.Lsh(score, 64) // keep the precision for the large stakes
+		score.Div(score, validator.Stake)
+		scores = append(scores, vrfScore{validator, score})
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if c := scores[i].score.Cmp(scores[j].score); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(scores[i].validator.Address[:], scores[j].validator.Address[:]) < 0
+	})
+	if len(scores) > size {
+		scores = scores[:size]
+	}
+	committee := make([]core.Validator, len(scores))
+	for i, s := range scores {
+		committee[i] = s.validator
+	}
+	return committee
+}
+
+// SelectProposerByVRF selects the validator with the lowest score as the proposer. It returns
+// false if none of the outputs is valid.
+func SelectProposerByVRF(valSet *core.ValidatorSet, blockHash common.Hash, epoch uint64,
+	outputs []*ValidatorVRFOutput) (core.Validator, bool) {
+	committee := SelectCommitteeByVRF(valSet, blockHash, epoch, outputs, 1)
+	if len(committee) == 0 {
+		return core.Validator{}, false
+	}
+	return committee[0], true
+}
@@ -0,0 +1,67 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+func TestVRFSelection(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	blockHash := common.BytesToHash([]byte("block"))
+	epoch := uint64(7)
+
+	valSet := core.NewValidatorSet()
+	outputs := []*ValidatorVRFOutput{}
+	for i := 0; i < 5; i++ {
+		privKey, pubKey, err := crypto.GenerateKeyPair()
+		require.Nil(err)
+		valSet.AddValidator(core.Validator{Address: pubKey.Address(), Stake: big.NewInt(int64(1000 * (i + 1)))})
+		output, err := ProveValidatorVRF(privKey, blockHash, epoch)
+		require.Nil(err)
+		outputs = append(outputs, output)
+	}
+
+	committee := SelectCommitteeByVRF(valSet, blockHash, epoch, outputs, 3)
+	assert.Equal(3, len(committee))
+	proposer, ok := SelectProposerByVRF(valSet, blockHash, epoch, outputs)
+	assert.True(ok)
+	assert.Equal(committee[0], proposer)
+
+	// The selection doesn't depend on the order of the outputs
+	reversed := make([]*ValidatorVRFOutput, len(outputs))
+	for i, output := range outputs {
+		reversed[len(outputs)-1-i] = output
+	}
+	assert.Equal(committee, SelectCommitteeByVRF(valSet, blockHash, epoch, reversed, 3))
+
+	// Outputs of other epochs, duplicates and non-validators are ignored
+	outsiderKey, _, _ := crypto.GenerateKeyPair()
+	outsider, err := ProveValidatorVRF(outsiderKey, blockHash, epoch)
+	require.Nil(err)
+	stale, err := ProveValidatorVRF(outsiderKey, blockHash, epoch-1)
+	require.Nil(err)
+	polluted := append([]*ValidatorVRFOutput{outsider, stale}, outputs...)
+	polluted = append(polluted, outputs...)
+	assert.Equal(committee, SelectCommitteeByVRF(valSet, blockHash, epoch, polluted, 3))
+	assert.Equal(5, len(SelectCommitteeByVRF(valSet, blockHash, epoch, polluted, 10)))
+
+	// A validator withholding its output only removes itself
+	var withheld []*ValidatorVRFOutput
+	for _, output := range outputs {
+		if output.PublicKey.Address() != proposer.Address {
+			withheld = append(withheld, output)
+		}
+	}
+	assert.Equal(committee[1:], SelectCommitteeByVRF(valSet, blockHash, epoch, withheld, 2))
+
+	_, ok = SelectProposerByVRF(valSet, blockHash, epoch+1, outputs)
+	assert.False(ok)
+}
@@ -378,7 +378,21 @@ func (h *BlockHeader) Validate(chainID string) result.Result {
 	if h.Signature == nil || h.Signature.IsEmpty() {
 		return result.Error("Block is not signed")
 	}
-	if !h.Signature.Verify(h.SignBytes(), h.Proposer) {
+	if h.Height >= common.HeightEnableValidatorKeyRotationTx {
+		// The proposer may sign with a rotated key, which only the ledger state tells. The consensus
+		// engine checks the signer against the state, see ValidateSignature.
+		if _, err := h.Signature.RecoverSignerAddress(h.SignBytes()); err != nil {
+			return result.Error("Signature verification failed")
+		}
+		return result.OK
+	}
+	return h.ValidateSignature(h.Proposer)
+}
+
+// ValidateSignature checks that the block is signed by the key of the given signing address, which is
+// the proposer address unless the proposer has rotated its signing key.
+func (h *BlockHeader) ValidateSignature(signingAddress common.Address) result.Result {
+	if h.Signature == nil || !h.Signature.Verify(h.SignBytes(), signingAddress) {
 		return result.Error("Signature verification failed")
 	}
 	return result.OK
@@ -20,6 +20,7 @@ const (
 //
 type TxInfo struct {
 	EffectiveGasPrice *big.Int
+	Fee               *big.Int // TFuelWei fee the transaction is ranked by in the mempool
 	Address           common.Address
 	Sequence          uint64
 }
@@ -30,7 +31,12 @@ type TxInfo struct {
 type Ledger interface {
 	GetCurrentBlock() *Block
 	ScreenTxUnsafe(rawTx common.Bytes) result.Result
+	ResetScreenedAccountUnsafe(address common.Address)
 	ScreenTx(rawTx common.Bytes) (priority *TxInfo, res result.Result)
+	ScreenTxReplacement(rawTx common.Bytes, precedingRawTxs []common.Bytes) (priority *TxInfo, res result.Result)
+	GetTxInfo(rawTx common.Bytes) (*TxInfo, result.Result)
+	PreverifyTxSignatures(rawTxs []common.Bytes)
+	GetAccountSequence(address common.Address) uint64
 	ProposeBlockTxs(block *Block, shouldIncludeValidatorUpdateTxs bool) (stateRootHash common.Hash, blockRawTxs []common.Bytes, res result.Result)
 	ApplyBlockTxs(block *Block) result.Result
 	ApplyBlockTxsForChainCorrection(block *Block) (common.Hash, result.Result)
@@ -40,5 +46,6 @@ type Ledger interface {
 	GetFinalizedValidatorCandidatePool(blockHash common.Hash, isNext bool) (*ValidatorCandidatePool, error)
 	GetGuardianCandidatePool(blockHash common.Hash) (*GuardianCandidatePool, error)
 	GetEliteEdgeNodePoolOfLastCheckpoint(blockHash common.Hash) (EliteEdgeNodePool, error)
+	GetValidatorSigningAddress(blockHash common.Hash, validator common.Address, epoch uint64) (common.Address, error)
 	PruneState(endHeight uint64) error
 }
@@ -46,8 +46,18 @@ func (cc CommitCertificate) String() string {
 	return fmt.Sprintf("CC{BlockHash: %v, Votes: %v}", cc.BlockHash.Hex(), cc.Votes)
 }
 
+// SigningAddressResolver returns the address of the key the validator signs with at the epoch, which
+// differs from the validator address once the validator has rotated its signing key
+type SigningAddressResolver func(validator common.Address, epoch uint64) common.Address
+
 // IsValid checks if a CommitCertificate is valid.
 func (cc CommitCertificate) IsValid(validators *ValidatorSet) bool {
+	return cc.IsValidWithSigningAddresses(validators, nil)
+}
+
+// IsValidWithSigningAddresses checks if a CommitCertificate is valid, with the votes signed by the keys
+// resolved by signingAddress, or by the keys of the voter addresses if signingAddress is nil.
+func (cc CommitCertificate) IsValidWithSigningAddresses(validators *ValidatorSet, signingAddress SigningAddressResolver) bool {
 	if cc.Votes == nil || cc.Votes.IsEmpty() {
 		return false
 	}
@@ -62,7 +72,11 @@ func (cc CommitCertificate) IsValid(validators *ValidatorSet) bool {
 		if vote.Block != cc.BlockHash {
 			return false
 		}
-		if vote.Validate().IsError() {
+		signer := vote.ID
+		if signingAddress != nil {
+			signer = signingAddress(vote.ID, vote.Epoch)
+		}
+		if vote.ValidateWithSigningAddress(signer).IsError() {
 			return false
 		}
 	}
@@ -93,8 +107,8 @@ func (v Vote) SignBytes() common.Bytes {
 	return raw
 }
 
-// Sign signs the vote using given private key.
-func (v *Vote) Sign(priv *crypto.PrivateKey) {
+// Sign signs the vote using given signer.
+func (v *Vote) Sign(priv crypto.Signer) {
 	sig, err := priv.Sign(v.SignBytes())
 	if err != nil {
 		// Should not happen.
@@ -110,6 +124,12 @@ func (v *Vote) SetSignature(sig *crypto.Signature) {
 
 // Validate checks the vote is legitimate.
 func (v Vote) Validate() result.Result {
+	return v.ValidateWithSigningAddress(v.ID)
+}
+
+// ValidateWithSigningAddress checks the vote, which needs to be signed by the key of the given signing
+// address instead of the voter address, see SigningAddressResolver.
+func (v Vote) ValidateWithSigningAddress(signingAddress common.Address) result.Result {
 	if v.Block.IsEmpty() {
 		return result.Error("Block is not specified")
 	}
@@ -119,7 +139,7 @@ func (v Vote) Validate() result.Result {
 	if v.Signature == nil || v.Signature.IsEmpty() {
 		return result.Error("Vote is not signed")
 	}
-	if !v.Signature.Verify(v.SignBytes(), v.ID) {
+	if !v.Signature.Verify(v.SignBytes(), signingAddress) {
 		return result.Error("Signature verification failed")
 	}
 	return result.OK
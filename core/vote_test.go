@@ -259,3 +259,51 @@ func TestCommitCertificate(t *testing.T) {
 	cc = CommitCertificate{Votes: invalidVoteSet, BlockHash: blockHash}
 	assert.False(cc.IsValid(vs))
 }
+
+func TestCommitCertificateWithRotatedKey(t *testing.T) {
+	assert := assert.New(t)
+
+	stake := new(big.Int).Mul(new(big.Int).SetUint64(100000000), new(big.Int).SetUint64(1e18))
+	priv1, _, _ := crypto.GenerateKeyPair()
+	va1Addr := priv1.PublicKey().Address()
+	priv2, _, _ := crypto.GenerateKeyPair()
+	va2Addr := priv2.PublicKey().Address()
+	rotatedPriv2, _, _ := crypto.GenerateKeyPair()
+	rotatedAddr2 := rotatedPriv2.PublicKey().Address()
+
+	vs := NewValidatorSet()
+	vs.AddValidator(NewValidator(va1Addr.Hex(), stake))
+	vs.AddValidator(NewValidator(va2Addr.Hex(), stake))
+
+	// The second validator votes with its rotated key
+	blockHash := common.HexToHash("a1")
+	vote1 := Vote{ID: va1Addr, Block: blockHash, Height: 1, Epoch: 5}
+	vote1.Sign(priv1)
+	vote2 := Vote{ID: va2Addr, Block: blockHash, Height: 1, Epoch: 5}
+	vote2.Sign(rotatedPriv2)
+	voteSet := NewVoteSet()
+	voteSet.AddVote(vote1)
+	voteSet.AddVote(vote2)
+	cc := CommitCertificate{Votes: voteSet, BlockHash: blockHash}
+
+	assert.True(vote2.Validate().IsError())
+	assert.False(cc.IsValid(vs))
+
+	resolver := func(validator common.Address, epoch uint64) common.Address {
+		if validator == va2Addr && epoch >= 5 {
+			return rotatedAddr2
+		}
+		return validator
+	}
+	assert.True(vote2.ValidateWithSigningAddress(rotatedAddr2).IsOK())
+	assert.True(cc.IsValidWithSigningAddresses(vs, resolver))
+
+	// The rotated key is not active yet in earlier epochs
+	vote2 = Vote{ID: va2Addr, Block: blockHash, Height: 1, Epoch: 4}
+	vote2.Sign(rotatedPriv2)
+	voteSet = NewVoteSet()
+	voteSet.AddVote(vote1)
+	voteSet.AddVote(vote2)
+	cc = CommitCertificate{Votes: voteSet, BlockHash: blockHash}
+	assert.False(cc.IsValidWithSigningAddresses(vs, resolver))
+}
@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"runtime"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/thetatoken/theta/common"
+)
+
+//
+// ------------------- Batch Signature Verification ------------------- //
+//
+// The secp256k1 signatures are verified by recovering the public key of the signer, which can't be
+// amortized across signatures the way the Schnorr or BLS signatures can. A batch is instead verified
+// in parallel on all the CPUs, and the valid signatures are remembered, so that verifying them again,
+// e.g. while executing the transactions of a block one after another, is a cache lookup. The key of
+// the cache covers the message, the signature and the address, so a hit always means the same check
+// succeeded before.
+//
+
+const verifiedSignatureCacheSize = 1 << 16
+
+var verifiedSignatures *lru.Cache
+
+func init() {
+	var err error
+	verifiedSignatures, err = lru.New(verifiedSignatureCacheSize)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func verifiedSignatureKey(msg common.Bytes, sig *Signature, addr common.Address) common.Hash {
+	return keccak256Hash(keccak256(msg), sig.ToBytes(), addr[:])
+}
+
+// isVerifiedSignature returns whether the signature was verified against the message and address
+func isVerifiedSignature(msg common.Bytes, sig *Signature, addr common.Address) bool {
+	return verifiedSignatures.Contains(verifiedSignatureKey(msg, sig, addr))
+}
+
+func rememberVerifiedSignature(msg common.Bytes, sig *Signature, addr common.Address) {
+	verifiedSignatures.Add(verifiedSignatureKey(msg, sig, addr), struct{}{})
+}
+
+type batchItem struct {
+	sig  *Signature
+	addr common.Address
+	msgs []common.Bytes
+}
+
+// BatchVerifier verifies a batch of signatures in parallel
+type BatchVerifier struct {
+	items []batchItem
+}
+
+// NewBatchVerifier creates an empty batch
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{}
+}
+
+// Add adds a signature expected from the given address to the batch. The signature is valid if it
+// signs any of the messages, e.g. the sign bytes of a transaction in either of the wrappers accepted.
+func (bv *BatchVerifier) Add(sig *Signature, addr common.Address, msgs ...common.Bytes) {
+	bv.items = append(bv.items, batchItem{sig: sig, addr: addr, msgs: msgs})
+}
+
+// Len returns the number of signatures in the batch
+func (bv *BatchVerifier) Len() int {
+	return len(bv.items)
+}
+
+// Verify verifies the signatures of the batch in parallel, and returns whether each of them is
+// valid, in the order they were added
+func (bv *BatchVerifier) Verify() []bool {
+	results := make([]bool, len(bv.items))
+	numWorkers := runtime.NumCPU()
+	if numWorkers > len(bv.items) {
+		numWorkers = len(bv.items)
+	}
+
+	indexes := make(chan int, len(bv.items))
+	for i := range bv.items {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg := &sync.WaitGroup{}
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = bv.items[i].verify()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// VerifyAll returns whether all the signatures of the batch are valid
+func (bv *BatchVerifier) VerifyAll() bool {
+	for _, valid := range bv.Verify() {
+		if !valid {
+			return false
+		}
+	}
+	return true
+}
+
+func (item batchItem) verify() bool {
+	for _, msg := range item.msgs {
+		if item.sig.Verify(msg, item.addr) {
+			return true
+		}
+	}
+	return false
+}
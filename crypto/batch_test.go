@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestBatchVerifier(t *testing.T) {
+	assert := assert.New(t)
+
+	batch := NewBatchVerifier()
+	expected := []bool{}
+	for i := 0; i < 20; i++ {
+		privKey, pubKey, err := GenerateKeyPair()
+		assert.Nil(err)
+		msg := common.Bytes(fmt.Sprintf("message %v", i))
+		sig, err := privKey.Sign(msg)
+		assert.Nil(err)
+
+		switch i % 4 {
+		case 0: // valid
+			batch.Add(sig, pubKey.Address(), msg)
+			expected = append(expected, true)
+		case 1: // signed by another key
+			_, otherPubKey, _ := GenerateKeyPair()
+			batch.Add(sig, otherPubKey.Address(), msg)
+			expected = append(expected, false)
+		case 2: // signed another message
+			batch.Add(sig, pubKey.Address(), common.Bytes("another message"))
+			expected = append(expected, false)
+		case 3: // signed the alternative message
+			batch.Add(sig, pubKey.Address(), common.Bytes("another message"), msg)
+			expected = append(expected, true)
+		}
+	}
+	assert.Equal(20, batch.Len())
+	assert.Equal(expected, batch.Verify())
+	assert.False(batch.VerifyAll())
+
+	empty := NewBatchVerifier()
+	assert.Equal([]bool{}, empty.Verify())
+	assert.True(empty.VerifyAll())
+}
+
+func TestVerifiedSignatureCache(t *testing.T) {
+	assert := assert.New(t)
+
+	privKey, pubKey, err := GenerateKeyPair()
+	assert.Nil(err)
+	msg := common.Bytes("hello")
+	sig, err := privKey.Sign(msg)
+	assert.Nil(err)
+
+	assert.False(isVerifiedSignature(msg, sig, pubKey.Address()))
+	batch := NewBatchVerifier()
+	batch.Add(sig, pubKey.Address(), msg)
+	assert.True(batch.VerifyAll())
+	assert.True(isVerifiedSignature(msg, sig, pubKey.Address()))
+	assert.True(sig.Verify(msg, pubKey.Address()))
+
+	// The cache only remembers the exact message, signature and address verified
+	_, otherPubKey, _ := GenerateKeyPair()
+	assert.False(sig.Verify(msg, otherPubKey.Address()))
+	assert.False(sig.Verify(common.Bytes("hello!"), pubKey.Address()))
+	assert.False(isVerifiedSignature(msg, sig, otherPubKey.Address()))
+}
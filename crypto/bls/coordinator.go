@@ -0,0 +1,151 @@
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CoSigner makes partial signatures with a key share, typically on a machine of its own
+type CoSigner interface {
+	Index() int
+	SignShare(message []byte) (*PartialSignature, error)
+}
+
+// LocalCoSigner is a co-signer holding its key share in memory
+type LocalCoSigner struct {
+	share *KeyShare
+}
+
+var _ CoSigner = (*LocalCoSigner)(nil)
+
+// NewLocalCoSigner creates a co-signer with the key share.
+func NewLocalCoSigner(share *KeyShare) *LocalCoSigner {
+	return &LocalCoSigner{share: share}
+}
+
+// Index returns the index of the key share.
+func (s *LocalCoSigner) Index() int {
+	return s.share.Index
+}
+
+// SignShare makes a partial signature of the message.
+func (s *LocalCoSigner) SignShare(message []byte) (*PartialSignature, error) {
+	return s.share.Sign(message), nil
+}
+
+// Coordinator collects the partial signatures of the co-signers, and recovers the signature once
+// the threshold of them are valid. The co-signers that fail, time out or return invalid partial
+// signatures are left out, so the signing survives the loss or compromise of fewer co-signers
+// than the number of shares above the threshold.
+type Coordinator struct {
+	publicKey       *PublicKey
+	sharePublicKeys map[int]*PublicKey
+	threshold       int
+	cosigners       []CoSigner
+	timeout         time.Duration
+}
+
+// NewCoordinator creates a coordinator of the co-signers. The public keys of the shares are
+// indexed from 1, as returned by SplitSecretKey.
+func NewCoordinator(publicKey *PublicKey, sharePublicKeys []*PublicKey, threshold int,
+	cosigners []CoSigner, timeout time.Duration) (*Coordinator, error) {
+	if threshold < 1 || threshold > len(sharePublicKeys) {
+		return nil, fmt.Errorf("invalid threshold %v of %v shares", threshold, len(sharePublicKeys))
+	}
+	if len(cosigners) < threshold {
+		return nil, fmt.Errorf("%v co-signers can't reach the threshold %v", len(cosigners), threshold)
+	}
+
+	c := &Coordinator{
+		publicKey:       publicKey,
+		sharePublicKeys: make(map[int]*PublicKey),
+		threshold:       threshold,
+		cosigners:       cosigners,
+		timeout:         timeout,
+	}
+	for i, pub := range sharePublicKeys {
+		c.sharePublicKeys[i+1] = pub
+	}
+	seen := make(map[int]bool)
+	for _, cosigner := range cosigners {
+		if _, ok := c.sharePublicKeys[cosigner.Index()]; !ok {
+			return nil, fmt.Errorf("unknown key share %v", cosigner.Index())
+		}
+		if seen[cosigner.Index()] {
+			return nil, fmt.Errorf("duplicate co-signer of key share %v", cosigner.Index())
+		}
+		seen[cosigner.Index()] = true
+	}
+	return c, nil
+}
+
+type signShareResult struct {
+	index   int
+	partial *PartialSignature
+	err     error
+}
+
+// Sign requests the partial signatures of the message from all the co-signers, and returns the
+// signature recovered from the first threshold of valid ones.
+func (c *Coordinator) Sign(message []byte) (*Signature, error) {
+	// Buffered, so that the co-signers answering after the threshold is reached don't block
+	results := make(chan signShareResult, len(c.cosigners))
+	for _, cosigner := range c.cosigners {
+		go func(cosigner CoSigner) {
+			partial, err := cosigner.SignShare(message)
+			results <- signShareResult{cosigner.Index(), partial, err}
+		}(cosigner)
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	partials := []*PartialSignature{}
+	failures := []string{}
+collect:
+	for pending := len(c.cosigners); pending > 0; pending-- {
+		select {
+		case res := <-results:
+			if err := c.checkPartial(res, message); err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			partials = append(partials, res.partial)
+			if len(partials) < c.threshold {
+				continue
+			}
+			sig, err := RecoverSignature(partials)
+			if err != nil {
+				return nil, err
+			}
+			if !sig.Verify(message, c.publicKey) {
+				return nil, errors.New("the recovered signature is invalid, the key shares don't match the public key")
+			}
+			return sig, nil
+		case <-timer.C:
+			failures = append(failures, fmt.Sprintf("%v co-signers timed out", pending))
+			break collect
+		}
+	}
+	return nil, fmt.Errorf("got %v of the %v partial signatures required: %v",
+		len(partials), c.threshold, strings.Join(failures, "; "))
+}
+
+// checkPartial verifies the partial signature against the public key of the key share
+func (c *Coordinator) checkPartial(res signShareResult, message []byte) error {
+	if res.err != nil {
+		return fmt.Errorf("co-signer %v failed: %v", res.index, res.err)
+	}
+	if res.partial == nil || res.partial.Signature.IsEmpty() {
+		return fmt.Errorf("co-signer %v returned no signature", res.index)
+	}
+	if res.partial.Index != res.index {
+		return fmt.Errorf("co-signer %v signed with key share %v", res.index, res.partial.Index)
+	}
+	if !res.partial.Signature.Verify(message, c.sharePublicKeys[res.index]) {
+		return fmt.Errorf("co-signer %v returned an invalid signature", res.index)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	bh "github.com/herumi/bls-eth-go-binary/bls"
+)
+
+// ------------- Threshold signatures --------------
+//
+// A secret key is split into n key shares with Shamir's secret sharing over a random polynomial of
+// degree t-1, so that any t of the partial signatures made with the shares recover the signature
+// of the secret key, while t-1 shares reveal nothing about it. The shares are indexed from 1, the
+// value of the polynomial at 0 being the secret key itself. The shares are dealt by the holder of
+// the secret key, which should be erased afterwards. Only the BLS keys can be shared this way, the
+// secp256k1 keys signing the blocks and the votes of the validators have no threshold scheme yet.
+
+// KeyShare is a share of a secret key
+type KeyShare struct {
+	Index     int
+	SecretKey *SecretKey
+}
+
+// PartialSignature is a signature made with a key share
+type PartialSignature struct {
+	Index     int
+	Signature *Signature
+}
+
+// SplitSecretKey splits the secret key into the given number of key shares, any threshold of
+// which can sign for the key. It returns the key shares along with their public keys, which verify
+// the partial signatures.
+func SplitSecretKey(sk *SecretKey, threshold, total int) ([]*KeyShare, []*PublicKey, error) {
+	if threshold < 1 || threshold > total {
+		return nil, nil, fmt.Errorf("invalid threshold %v of %v shares", threshold, total)
+	}
+
+	// The coefficients of the polynomial are drawn from the random source of the library
+	genkeyLock.Lock()
+	msk := sk.f.GetMasterSecretKey(threshold)
+	genkeyLock.Unlock()
+
+	shares := make([]*KeyShare, total)
+	publicKeys := make([]*PublicKey, total)
+	for i := 0; i < total; i++ {
+		index := i + 1
+		id, err := shareID(index)
+		if err != nil {
+			return nil, nil, err
+		}
+		shareKey := &bh.SecretKey{}
+		if err := shareKey.Set(msk, id); err != nil {
+			return nil, nil, err
+		}
+		shares[i] = &KeyShare{Index: index, SecretKey: &SecretKey{f: shareKey}}
+		publicKeys[i] = &PublicKey{p: shareKey.GetPublicKey()}
+	}
+	return shares, publicKeys, nil
+}
+
+// Sign makes a partial signature of the message with the key share.
+func (ks *KeyShare) Sign(message []byte) *PartialSignature {
+	return &PartialSignature{Index: ks.Index, Signature: ks.SecretKey.Sign(message)}
+}
+
+// PublicKey returns the public key of the key share.
+func (ks *KeyShare) PublicKey() *PublicKey {
+	return ks.SecretKey.PublicKey()
+}
+
+// RecoverSignature recovers the signature of the secret key from the partial signatures, which must
+// be made with at least the threshold of distinct key shares. The partial signatures are not
+// verified, see Coordinator.
+func RecoverSignature(partials []*PartialSignature) (*Signature, error) {
+	if len(partials) == 0 {
+		return nil, errors.New("no partial signatures")
+	}
+	sigs := make([]bh.Sign, len(partials))
+	ids := make([]bh.ID, len(partials))
+	seen := make(map[int]bool)
+	for i, partial := range partials {
+		if partial.Signature.IsEmpty() {
+			return nil, fmt.Errorf("empty partial signature of share %v", partial.Index)
+		}
+		if seen[partial.Index] {
+			return nil, fmt.Errorf("duplicate partial signature of share %v", partial.Index)
+		}
+		seen[partial.Index] = true
+		id, err := shareID(partial.Index)
+		if err != nil {
+			return nil, err
+		}
+		sigs[i] = *partial.Signature.s
+		ids[i] = *id
+	}
+
+	sig := &bh.Sign{}
+	if err := sig.Recover(sigs, ids); err != nil {
+		return nil, err
+	}
+	return &Signature{s: sig}, nil
+}
+
+func shareID(index int) (*bh.ID, error) {
+	if index < 1 {
+		return nil, fmt.Errorf("invalid key share index %v", index)
+	}
+	id := &bh.ID{}
+	if err := id.SetDecString(strconv.Itoa(index)); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
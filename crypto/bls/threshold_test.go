@@ -0,0 +1,118 @@
+package bls
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestThresholdSignature(t *testing.T) {
+	sk, _ := RandKey()
+	shares, sharePubs, err := SplitSecretKey(sk, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 || len(sharePubs) != 5 {
+		t.Fatal("wrong number of shares")
+	}
+
+	msg := []byte("hello")
+	expected := sk.Sign(msg)
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		partials := []*PartialSignature{}
+		for _, i := range subset {
+			partial := shares[i].Sign(msg)
+			if !partial.Signature.Verify(msg, sharePubs[i]) {
+				t.Fatalf("partial signature of share %v did not verify", shares[i].Index)
+			}
+			partials = append(partials, partial)
+		}
+		sig, err := RecoverSignature(partials)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sig.Equals(expected) || !sig.Verify(msg, sk.PublicKey()) {
+			t.Fatalf("wrong signature recovered from shares %v", subset)
+		}
+	}
+
+	// Fewer shares than the threshold recover another signature
+	sig, err := RecoverSignature([]*PartialSignature{shares[0].Sign(msg), shares[1].Sign(msg)})
+	if err == nil && sig.Verify(msg, sk.PublicKey()) {
+		t.Fatal("signature recovered below the threshold")
+	}
+
+	if _, err := RecoverSignature([]*PartialSignature{shares[0].Sign(msg), shares[0].Sign(msg)}); err == nil {
+		t.Fatal("duplicate partial signatures accepted")
+	}
+	if _, _, err := SplitSecretKey(sk, 4, 3); err == nil {
+		t.Fatal("threshold above the number of shares accepted")
+	}
+}
+
+type failingCoSigner struct {
+	index int
+	err   error
+	delay time.Duration
+	sig   *Signature
+}
+
+func (s *failingCoSigner) Index() int {
+	return s.index
+}
+
+func (s *failingCoSigner) SignShare(message []byte) (*PartialSignature, error) {
+	time.Sleep(s.delay)
+	return &PartialSignature{Index: s.index, Signature: s.sig}, s.err
+}
+
+func TestCoordinator(t *testing.T) {
+	sk, _ := RandKey()
+	other, _ := RandKey()
+	shares, sharePubs, err := SplitSecretKey(sk, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("block")
+
+	// A co-signer is down, another signs with a wrong key, the others reach the threshold
+	cosigners := []CoSigner{
+		NewLocalCoSigner(shares[0]),
+		&failingCoSigner{index: 2, err: errors.New("connection refused")},
+		&failingCoSigner{index: 3, sig: other.Sign(msg)},
+		NewLocalCoSigner(shares[3]),
+		NewLocalCoSigner(shares[4]),
+	}
+	c, err := NewCoordinator(sk.PublicKey(), sharePubs, 3, cosigners, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := c.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.Verify(msg, sk.PublicKey()) {
+		t.Fatal("coordinated signature did not verify")
+	}
+
+	// Only two co-signers answer in time
+	cosigners = []CoSigner{
+		NewLocalCoSigner(shares[0]),
+		NewLocalCoSigner(shares[1]),
+		&failingCoSigner{index: 3, delay: time.Second, sig: shares[2].Sign(msg).Signature},
+	}
+	c, err = NewCoordinator(sk.PublicKey(), sharePubs, 3, cosigners, 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Sign(msg); err == nil {
+		t.Fatal("signed below the threshold")
+	}
+
+	if _, err := NewCoordinator(sk.PublicKey(), sharePubs, 3, cosigners[:2], time.Second); err == nil {
+		t.Fatal("coordinator created with fewer co-signers than the threshold")
+	}
+	if _, err := NewCoordinator(sk.PublicKey(), sharePubs, 3, []CoSigner{cosigners[0], cosigners[0], cosigners[1]}, time.Second); err == nil {
+		t.Fatal("coordinator created with duplicate co-signers")
+	}
+}
@@ -225,8 +225,29 @@ func (sig *Signature) IsEmpty() bool {
 	return len(sig.data) == 0
 }
 
-// RecoverSignerAddress recovers the address of the signer for the given message
+// IsCanonical returns whether the signature is in the canonical form. A secp256k1 signature needs
+// to be encoded in exactly 65 bytes as [R || S || V], with S in the lower half of the curve order and
+// V either 0 or 1, since (R, N-S) is an equally valid signature of the same message. The Ed25519
+// signatures are unique by construction, the verification rejects non-canonical S values.
+func (sig *Signature) IsCanonical() bool {
+	if sig.Scheme() == SignatureSchemeEd25519 {
+		return true
+	}
+	if len(sig.data) != SignatureLength {
+		return false
+	}
+	r := new(big.Int).SetBytes(sig.data[:32])
+	s := new(big.Int).SetBytes(sig.data[32:64])
+	return validateSignatureValues(sig.data[64], r, s, true)
+}
+
+// RecoverSignerAddress recovers the address of the signer for the given message. The Ed25519
+// signatures are verified against the public key they carry instead.
 func (sig *Signature) RecoverSignerAddress(msg common.Bytes) (common.Address, error) {
+	if sig.Scheme() == SignatureSchemeEd25519 {
+		return sig.recoverEd25519SignerAddress(msg)
+	}
+
 	msgHash := keccak256(msg)
 	recoveredUncompressedPubKey, err := ecrecover(msgHash, sig.ToBytes())
 	if err != nil {
@@ -242,11 +263,15 @@ func (sig *Signature) RecoverSignerAddress(msg common.Bytes) (common.Address, er
 	return address, nil
 }
 
-// Verify verifies the signature with given raw message and address.
+// Verify verifies the signature with given raw message and address. The signatures verified
+// before, e.g. by a BatchVerifier, are not recovered again.
 func (sig *Signature) Verify(msg common.Bytes, addr common.Address) bool {
 	if sig == nil || sig.IsEmpty() {
 		return false
 	}
+	if isVerifiedSignature(msg, sig, addr) {
+		return true
+	}
 	recoveredAddress, err := sig.RecoverSignerAddress(msg)
 	if err != nil {
 		return false
@@ -254,6 +279,7 @@ func (sig *Signature) Verify(msg common.Bytes, addr common.Address) bool {
 	if recoveredAddress != addr {
 		return false
 	}
+	rememberVerifiedSignature(msg, sig, addr)
 	return true
 }
 
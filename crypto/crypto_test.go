@@ -266,3 +266,31 @@ func TestSignatureVerification2(t *testing.T) {
 	assert.False(pubKeyA.VerifySignature(msg2, sig2B))
 	assert.False(pubKeyB.VerifySignature(msg2, sig2A))
 }
+
+func TestCanonicalSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	privKey, pubKey, err := GenerateKeyPair()
+	assert.Nil(err)
+	msg := common.Bytes("Hello world!")
+	sig, err := privKey.Sign(msg)
+	assert.Nil(err)
+	assert.True(sig.IsCanonical())
+
+	// (R, N-S) with the flipped recovery ID is a valid signature of the same message, but not canonical
+	data := common.CopyBytes(sig.ToBytes())
+	s := new(big.Int).SetBytes(data[32:64])
+	copy(data[32:64], common.LeftPadBytes(new(big.Int).Sub(secp256k1N, s).Bytes(), 32))
+	data[64] ^= 1
+	malleated, _ := SignatureFromBytes(data)
+	assert.True(malleated.Verify(msg, pubKey.Address()))
+	assert.False(malleated.IsCanonical())
+
+	// Non-compact encodings
+	padded, _ := SignatureFromBytes(append(common.CopyBytes(sig.ToBytes()), 0))
+	assert.False(padded.IsCanonical())
+	data = common.CopyBytes(sig.ToBytes())
+	data[64] += 27
+	legacyV, _ := SignatureFromBytes(data)
+	assert.False(legacyV.IsCanonical())
+}
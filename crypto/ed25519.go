@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"io"
+
+	"github.com/thetatoken/theta/common"
+)
+
+//
+// ----------------------- Ed25519 Signature APIs ----------------------- //
+//
+// Besides the secp256k1 ECDSA signatures, the transaction inputs can be signed with Ed25519 keys,
+// which are cheaper to verify. The public key of an Ed25519 signer can't be recovered from the
+// signature, so the signature carries the public key, prefixed with the scheme identifier:
+//
+//   scheme (1 byte) || public key (32 bytes) || signature (64 bytes)
+//
+// The 65 byte secp256k1 signatures keep their encoding. The address of an Ed25519 key is derived
+// from the Keccak256 hash of the public key the same way as the address of a secp256k1 key.
+//
+
+// SignatureScheme identifies the signature scheme of a Signature
+type SignatureScheme byte
+
+const (
+	SignatureSchemeSecp256k1 SignatureScheme = 0x00
+	SignatureSchemeEd25519   SignatureScheme = 0x01
+)
+
+const ed25519SignatureLength = 1 + ed25519.PublicKeySize + ed25519.SignatureSize
+
+var errInvalidEd25519Key = errors.New("invalid Ed25519 key")
+
+// Scheme returns the signature scheme of the signature
+func (sig *Signature) Scheme() SignatureScheme {
+	if len(sig.data) == ed25519SignatureLength && sig.data[0] == byte(SignatureSchemeEd25519) {
+		return SignatureSchemeEd25519
+	}
+	return SignatureSchemeSecp256k1
+}
+
+// recoverEd25519SignerAddress verifies the Ed25519 signature against the public key it carries,
+// and returns the address of the public key
+func (sig *Signature) recoverEd25519SignerAddress(msg common.Bytes) (common.Address, error) {
+	pubKey := ed25519.PublicKey(sig.data[1 : 1+ed25519.PublicKeySize])
+	if !ed25519.Verify(pubKey, msg, sig.data[1+ed25519.PublicKeySize:]) {
+		return common.Address{}, errInvalidSignature
+	}
+	return (&Ed25519PublicKey{pubKey: pubKey}).Address(), nil
+}
+
+//
+// Ed25519PrivateKey represents an Ed25519 private key
+//
+type Ed25519PrivateKey struct {
+	privKey ed25519.PrivateKey
+}
+
+// GenerateEd25519Key generates a random Ed25519 private key
+func GenerateEd25519Key(rand io.Reader) (*Ed25519PrivateKey, error) {
+	_, privKey, err := ed25519.GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &Ed25519PrivateKey{privKey: privKey}, nil
+}
+
+// Ed25519PrivateKeyFromSeed derives the Ed25519 private key from the 32 byte seed
+func Ed25519PrivateKeyFromSeed(seed common.Bytes) (*Ed25519PrivateKey, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, errInvalidEd25519Key
+	}
+	return &Ed25519PrivateKey{privKey: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// Seed returns the 32 byte seed of the private key
+func (sk *Ed25519PrivateKey) Seed() common.Bytes {
+	return sk.privKey.Seed()
+}
+
+// PublicKey returns the public key corresponding to the private key
+func (sk *Ed25519PrivateKey) PublicKey() *Ed25519PublicKey {
+	return &Ed25519PublicKey{pubKey: sk.privKey.Public().(ed25519.PublicKey)}
+}
+
+// Sign signs the message with the private key. Unlike the secp256k1 signatures, the message is
+// signed as is, Ed25519 hashes it internally.
+func (sk *Ed25519PrivateKey) Sign(msg common.Bytes) (*Signature, error) {
+	data := make([]byte, 0, ed25519SignatureLength)
+	data = append(data, byte(SignatureSchemeEd25519))
+	data = append(data, sk.privKey.Public().(ed25519.PublicKey)...)
+	data = append(data, ed25519.Sign(sk.privKey, msg)...)
+	return &Signature{data: data}, nil
+}
+
+//
+// Ed25519PublicKey represents an Ed25519 public key
+//
+type Ed25519PublicKey struct {
+	pubKey ed25519.PublicKey
+}
+
+// Ed25519PublicKeyFromBytes converts the given bytes to an Ed25519 public key
+func Ed25519PublicKeyFromBytes(pkBytes common.Bytes) (*Ed25519PublicKey, error) {
+	if len(pkBytes) != ed25519.PublicKeySize {
+		return nil, errInvalidEd25519Key
+	}
+	return &Ed25519PublicKey{pubKey: ed25519.PublicKey(common.CopyBytes(pkBytes))}, nil
+}
+
+// ToBytes returns the bytes representation of the public key
+func (pk *Ed25519PublicKey) ToBytes() common.Bytes {
+	return common.Bytes(pk.pubKey)
+}
+
+// Address returns the address corresponding to the public key
+func (pk *Ed25519PublicKey) Address() common.Address {
+	return common.BytesToAddress(keccak256(pk.pubKey)[12:])
+}
+
+// Verify verifies the signature with given raw message
+func (pk *Ed25519PublicKey) Verify(msg common.Bytes, sig *Signature) bool {
+	if sig == nil || sig.Scheme() != SignatureSchemeEd25519 {
+		return false
+	}
+	address, err := sig.recoverEd25519SignerAddress(msg)
+	return err == nil && address == pk.Address()
+}
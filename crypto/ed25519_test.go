@@ -0,0 +1,62 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestEd25519Signature(t *testing.T) {
+	assert := assert.New(t)
+
+	privKey, err := GenerateEd25519Key(rand.Reader)
+	assert.Nil(err)
+	pubKey := privKey.PublicKey()
+	msg := common.Bytes("Hello world!")
+
+	sig, err := privKey.Sign(msg)
+	assert.Nil(err)
+	assert.Equal(SignatureSchemeEd25519, sig.Scheme())
+	assert.Equal(ed25519SignatureLength, len(sig.ToBytes()))
+	assert.True(pubKey.Verify(msg, sig))
+	assert.True(sig.Verify(msg, pubKey.Address()))
+
+	address, err := sig.RecoverSignerAddress(msg)
+	assert.Nil(err)
+	assert.Equal(pubKey.Address(), address)
+
+	// Wrong message, address or public key
+	assert.False(sig.Verify(common.Bytes("Hello world?"), pubKey.Address()))
+	otherKey, err := GenerateEd25519Key(rand.Reader)
+	assert.Nil(err)
+	assert.False(sig.Verify(msg, otherKey.PublicKey().Address()))
+	assert.False(otherKey.PublicKey().Verify(msg, sig))
+
+	// The public key carried by the signature can't be swapped
+	forged := common.CopyBytes(sig.ToBytes())
+	copy(forged[1:33], otherKey.PublicKey().ToBytes())
+	forgedSig, _ := SignatureFromBytes(forged)
+	assert.False(forgedSig.Verify(msg, otherKey.PublicKey().Address()))
+
+	// Round trip of the seed
+	restored, err := Ed25519PrivateKeyFromSeed(privKey.Seed())
+	assert.Nil(err)
+	assert.Equal(pubKey.Address(), restored.PublicKey().Address())
+}
+
+func TestSignatureScheme(t *testing.T) {
+	assert := assert.New(t)
+
+	privKey, _, err := GenerateKeyPair()
+	assert.Nil(err)
+	sig, err := privKey.Sign(common.Bytes("Hello world!"))
+	assert.Nil(err)
+	assert.Equal(SignatureSchemeSecp256k1, sig.Scheme())
+
+	// Malformed Ed25519 signatures are treated as secp256k1 signatures, which fail to recover
+	malformed, _ := SignatureFromBytes(append([]byte{byte(SignatureSchemeEd25519)}, make([]byte, 64)...))
+	assert.Equal(SignatureSchemeSecp256k1, malformed.Scheme())
+	assert.False(malformed.Verify(common.Bytes("Hello world!"), common.Address{}))
+}
@@ -0,0 +1,323 @@
+// Package eip712 implements the hashing of the EIP-191 personal messages and the EIP-712 typed
+// structured data, so that the signatures made with the Theta keys can be verified by the
+// Ethereum tooling and the smart contracts, e.g. with ecrecover.
+package eip712
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/hexutil"
+	"github.com/thetatoken/theta/crypto"
+)
+
+// DomainType is the name of the type of the domain of the typed data
+const DomainType = "EIP712Domain"
+
+// Field is a member of a struct type
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Types maps the names of the struct types to their members
+type Types map[string][]Field
+
+// TypedData is the structured data to sign, in the format of eth_signTypedData_v4
+type TypedData struct {
+	Types       Types                  `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      map[string]interface{} `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+var (
+	arrayTypeRegexp = regexp.MustCompile(`^(.+)\[(\d*)\]$`)
+	intTypeRegexp   = regexp.MustCompile(`^(u?)int(\d*)$`)
+	bytesTypeRegexp = regexp.MustCompile(`^bytes(\d+)$`)
+)
+
+// ParseTypedData parses the JSON encoded typed data. The numbers are kept as they are written,
+// so that the large integers don't lose precision.
+func ParseTypedData(data []byte) (*TypedData, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	td := &TypedData{}
+	if err := decoder.Decode(td); err != nil {
+		return nil, fmt.Errorf("invalid typed data: %v", err)
+	}
+	if _, ok := td.Types[DomainType]; !ok {
+		return nil, fmt.Errorf("invalid typed data: missing the %v type", DomainType)
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return nil, fmt.Errorf("invalid typed data: unknown primary type %q", td.PrimaryType)
+	}
+	return td, nil
+}
+
+// PersonalMessage returns the bytes signed for the message by personal_sign, as defined by
+// EIP-191. The signature is made over the keccak256 hash of these bytes.
+func PersonalMessage(msg []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(msg))
+	return append([]byte(prefix), msg...)
+}
+
+// SigningBytes returns the bytes signed for the typed data, i.e.
+// "\x19\x01" || domainSeparator || hashStruct(message). The signature is made over the keccak256
+// hash of these bytes.
+func (td *TypedData) SigningBytes() ([]byte, error) {
+	domainSeparator, err := td.HashStruct(DomainType, td.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash the domain: %v", err)
+	}
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash the message: %v", err)
+	}
+	signingBytes := []byte{0x19, 0x01}
+	signingBytes = append(signingBytes, domainSeparator[:]...)
+	return append(signingBytes, messageHash[:]...), nil
+}
+
+// DomainSeparator returns the hash of the domain
+func (td *TypedData) DomainSeparator() (common.Hash, error) {
+	return td.HashStruct(DomainType, td.Domain)
+}
+
+// HashStruct returns the hash of the struct of the given type
+func (td *TypedData) HashStruct(typeName string, data map[string]interface{}) (common.Hash, error) {
+	encoded, err := td.encodeData(typeName, data, 1)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// TypeHash returns the hash of the encoded type
+func (td *TypedData) TypeHash(typeName string) common.Hash {
+	return crypto.Keccak256Hash([]byte(td.EncodeType(typeName)))
+}
+
+// EncodeType returns the encoding of the type, e.g. Mail(Person from,Person to,string contents)
+// followed by the encodings of the referenced struct types sorted by name,
+// e.g. Person(string name,address wallet)
+func (td *TypedData) EncodeType(typeName string) string {
+	deps := td.dependencies(typeName, map[string]bool{})
+	sort.Strings(deps)
+
+	buf := &strings.Builder{}
+	for _, dep := range append([]string{typeName}, deps...) {
+		members := []string{}
+		for _, field := range td.Types[dep] {
+			members = append(members, field.Type+" "+field.Name)
+		}
+		buf.WriteString(dep + "(" + strings.Join(members, ",") + ")")
+	}
+	return buf.String()
+}
+
+// dependencies returns the struct types referenced by the type, directly or not
+func (td *TypedData) dependencies(typeName string, found map[string]bool) []string {
+	deps := []string{}
+	for _, field := range td.Types[typeName] {
+		dep := baseType(field.Type)
+		if _, isStruct := td.Types[dep]; !isStruct || found[dep] || dep == typeName {
+			continue
+		}
+		found[dep] = true
+		deps = append(deps, dep)
+		deps = append(deps, td.dependencies(dep, found)...)
+	}
+	return deps
+}
+
+func (td *TypedData) encodeData(typeName string, data map[string]interface{}, depth int) ([]byte, error) {
+	if depth > 32 {
+		return nil, fmt.Errorf("struct %v is nested too deep", typeName)
+	}
+	fields, ok := td.Types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", typeName)
+	}
+	if len(data) > len(fields) {
+		return nil, fmt.Errorf("struct %v has more values than members", typeName)
+	}
+
+	typeHash := td.TypeHash(typeName)
+	buf := bytes.NewBuffer(typeHash[:])
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing value of %v.%v", typeName, field.Name)
+		}
+		encoded, err := td.encodeValue(field.Type, value, depth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %v.%v: %v", typeName, field.Name, err)
+		}
+		buf.Write(encoded)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeValue returns the 32 byte encoding of the value of the given type
+func (td *TypedData) encodeValue(typeName string, value interface{}, depth int) ([]byte, error) {
+	if match := arrayTypeRegexp.FindStringSubmatch(typeName); match != nil {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array, got %v", value)
+		}
+		if match[2] != "" {
+			if length, _ := strconv.Atoi(match[2]); length != len(items) {
+				return nil, fmt.Errorf("expected %v items, got %v", length, len(items))
+			}
+		}
+		buf := &bytes.Buffer{}
+		for _, item := range items {
+			encoded, err := td.encodeValue(match[1], item, depth)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(encoded)
+		}
+		return crypto.Keccak256(buf.Bytes()), nil
+	}
+
+	if _, isStruct := td.Types[typeName]; isStruct {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a %v struct, got %v", typeName, value)
+		}
+		encoded, err := td.encodeData(typeName, data, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(encoded), nil
+	}
+
+	switch typeName {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %v", value)
+		}
+		return crypto.Keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+	case "address":
+		s, ok := value.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("expected an address, got %v", value)
+		}
+		return common.LeftPadBytes(common.HexToAddress(s).Bytes(), 32), nil
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a bool, got %v", value)
+		}
+		if b {
+			return common.LeftPadBytes([]byte{1}, 32), nil
+		}
+		return make([]byte, 32), nil
+	}
+
+	if match := bytesTypeRegexp.FindStringSubmatch(typeName); match != nil {
+		length, _ := strconv.Atoi(match[1])
+		if length < 1 || length > 32 {
+			return nil, fmt.Errorf("invalid type %v", typeName)
+		}
+		b, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != length {
+			return nil, fmt.Errorf("expected %v bytes, got %v", length, len(b))
+		}
+		return common.RightPadBytes(b, 32), nil
+	}
+
+	if match := intTypeRegexp.FindStringSubmatch(typeName); match != nil {
+		bits := 256
+		if match[2] != "" {
+			bits, _ = strconv.Atoi(match[2])
+		}
+		if bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("invalid type %v", typeName)
+		}
+		return encodeInteger(value, bits, match[1] == "u")
+	}
+
+	return nil, fmt.Errorf("unknown type %q", typeName)
+}
+
+// encodeInteger returns the 32 byte two's complement encoding of the integer, given as a JSON
+// number, or as a decimal or hex string
+func encodeInteger(value interface{}, bits int, unsigned bool) ([]byte, error) {
+	var s string
+	switch v := value.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, fmt.Errorf("expected an integer, got %v", value)
+	}
+
+	n, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		n, ok = n.SetString(s[2:], 16)
+	} else {
+		n, ok = n.SetString(s, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("expected an integer, got %v", value)
+	}
+
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	if !unsigned {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+		return nil, fmt.Errorf("%v overflows %v bits", n, bits)
+	}
+	if n.Sign() < 0 {
+		n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return common.LeftPadBytes(n.Bytes(), 32), nil
+}
+
+func parseBytes(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected hex bytes, got %v", value)
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("expected hex bytes, got %v: %v", s, err)
+	}
+	return b, nil
+}
+
+// baseType returns the type of the items of an array type, e.g. Person for Person[][2]
+func baseType(typeName string) string {
+	for {
+		match := arrayTypeRegexp.FindStringSubmatch(typeName)
+		if match == nil {
+			return typeName
+		}
+		typeName = match[1]
+	}
+}
@@ -0,0 +1,135 @@
+package eip712
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+// mailTypedData is the example of the EIP-712 specification
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestTypedDataHash(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	td, err := ParseTypedData([]byte(mailTypedData))
+	require.Nil(err)
+
+	assert.Equal("Mail(Person from,Person to,string contents)Person(string name,address wallet)", td.EncodeType("Mail"))
+	assert.Equal("0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2", td.TypeHash("Mail").Hex())
+
+	domainSeparator, err := td.DomainSeparator()
+	require.Nil(err)
+	assert.Equal("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", domainSeparator.Hex())
+
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	require.Nil(err)
+	assert.Equal("0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e", messageHash.Hex())
+
+	signingBytes, err := td.SigningBytes()
+	require.Nil(err)
+	assert.Equal("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", crypto.Keccak256Hash(signingBytes).Hex())
+}
+
+func TestTypedDataSignature(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	td, err := ParseTypedData([]byte(mailTypedData))
+	require.Nil(err)
+	signingBytes, err := td.SigningBytes()
+	require.Nil(err)
+
+	privKey, err := crypto.PrivateKeyFromBytes(crypto.Keccak256([]byte("cow")))
+	require.Nil(err)
+	assert.Equal(common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"), privKey.PublicKey().Address())
+
+	sig, err := privKey.Sign(signingBytes)
+	require.Nil(err)
+	sigBytes := sig.ToBytes()
+	assert.Equal(common.FromHex("0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d"), []byte(sigBytes[:32]))
+	assert.Equal(common.FromHex("0x07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"), []byte(sigBytes[32:64]))
+	assert.Equal(byte(1), sigBytes[64]) // v = 28
+	assert.True(sig.Verify(signingBytes, privKey.PublicKey().Address()))
+}
+
+func TestTypedDataValues(t *testing.T) {
+	assert := assert.New(t)
+
+	td := &TypedData{Types: Types{
+		DomainType: {},
+		"Claim": {
+			{Name: "amounts", Type: "uint96[]"},
+			{Name: "delta", Type: "int8"},
+			{Name: "id", Type: "bytes4"},
+		},
+	}}
+	encoded, err := td.encodeValue("int8", "-1", 1)
+	assert.Nil(err)
+	assert.Equal(common.FromHex("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"), encoded)
+
+	_, err = td.encodeValue("int8", "128", 1)
+	assert.NotNil(err)
+	_, err = td.encodeValue("uint8", "-1", 1)
+	assert.NotNil(err)
+	_, err = td.encodeValue("bytes4", "0x0102", 1)
+	assert.NotNil(err)
+	_, err = td.encodeValue("address", "0x1234", 1)
+	assert.NotNil(err)
+
+	_, err = td.HashStruct("Claim", map[string]interface{}{
+		"amounts": []interface{}{"1000000000000000000000", "0x10"},
+		"delta":   "-5",
+	})
+	assert.NotNil(err) // missing id
+
+	_, err = td.HashStruct("Claim", map[string]interface{}{
+		"amounts": []interface{}{"1000000000000000000000", "0x10"},
+		"delta":   "-5",
+		"id":      "0x01020304",
+	})
+	assert.Nil(err)
+}
+
+func TestPersonalMessage(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]byte("\x19Ethereum Signed Message:\n5hello"), PersonalMessage([]byte("hello")))
+	// The hash of personal_sign("hello"), as computed by web3.eth.accounts.hashMessage
+	assert.Equal("0x50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750",
+		crypto.Keccak256Hash(PersonalMessage([]byte("hello"))).Hex())
+}
@@ -0,0 +1,339 @@
+// Package hsm implements a crypto.Signer backed by a hardware security module, accessed through
+// PKCS#11. The secp256k1 key pair is generated and kept on the HSM, and only the ECDSA signing is
+// delegated to it: the message hashing, the signature normalization to low S values and the
+// recovery ID are done locally, so the signatures are identical in format to the ones of the keys
+// stored in files.
+package hsm
+
+import (
+	"bytes"
+	"context"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/pkcs11"
+	log "github.com/sirupsen/logrus"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "hsm"})
+
+// secp256k1OID is the DER encoding of the secp256k1 curve OID 1.3.132.0.10, the expected
+// CKA_EC_PARAMS of the key
+var secp256k1OID = []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}
+
+// The session states, defined in pkcs11t.h but not exported by the pkcs11 package
+const (
+	ckSRoPublicSession = 0
+	ckSRoUserFunctions = 1
+	ckSRwUserFunctions = 3
+)
+
+var (
+	ErrTokenNotFound = errors.New("HSM token not found")
+	ErrKeyNotFound   = errors.New("HSM key not found")
+)
+
+// Config specifies the PKCS#11 module, token and key used for signing
+type Config struct {
+	Library    string // path to the PKCS#11 module of the HSM vendor
+	TokenLabel string
+	KeyLabel   string // CKA_LABEL of the private and public key objects
+	PIN        string
+}
+
+// module is the subset of the PKCS#11 API used by the signer, implemented by pkcs11.Ctx
+type module interface {
+	Initialize() error
+	Finalize() error
+	Destroy()
+	GetSlotList(tokenPresent bool) ([]uint, error)
+	GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error)
+	OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error)
+	CloseSession(sh pkcs11.SessionHandle) error
+	GetSessionInfo(sh pkcs11.SessionHandle) (pkcs11.SessionInfo, error)
+	Login(sh pkcs11.SessionHandle, userType uint, pin string) error
+	FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error
+	FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error)
+	FindObjectsFinal(sh pkcs11.SessionHandle) error
+	GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle, a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error)
+	SignInit(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, o pkcs11.ObjectHandle) error
+	Sign(sh pkcs11.SessionHandle, message []byte) ([]byte, error)
+}
+
+// Signer signs with a secp256k1 key held by an HSM. It is safe for concurrent use, the requests
+// to the HSM are serialized on a single session.
+type Signer struct {
+	mu sync.Mutex
+
+	ctx    module
+	config Config
+	slotID uint
+
+	session    pkcs11.SessionHandle
+	hasSession bool
+	keyHandle  pkcs11.ObjectHandle
+	pubKey     *crypto.PublicKey
+}
+
+var _ crypto.Signer = (*Signer)(nil)
+
+// NewSigner loads the PKCS#11 module, logs in to the token and looks up the key
+func NewSigner(config Config) (*Signer, error) {
+	ctx := pkcs11.New(config.Library)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load the PKCS#11 module %v", config.Library)
+	}
+	signer, err := newSigner(ctx, config)
+	if err != nil {
+		ctx.Destroy()
+		return nil, err
+	}
+	return signer, nil
+}
+
+func newSigner(ctx module, config Config) (*Signer, error) {
+	if err := ctx.Initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize the PKCS#11 module: %v", err)
+	}
+	slotID, err := findSlot(ctx, config.TokenLabel)
+	if err != nil {
+		ctx.Finalize()
+		return nil, err
+	}
+	signer := &Signer{
+		ctx:    ctx,
+		config: config,
+		slotID: slotID,
+	}
+	if err := signer.openSession(); err != nil {
+		ctx.Finalize()
+		return nil, err
+	}
+	pubKey, err := signer.loadPublicKey()
+	if err != nil {
+		signer.Close()
+		return nil, err
+	}
+	signer.pubKey = pubKey
+	return signer, nil
+}
+
+// PublicKey returns the public key of the HSM key
+func (s *Signer) PublicKey() *crypto.PublicKey {
+	return s.pubKey
+}
+
+// Sign signs the Keccak256 hash of the message with the HSM key
+func (s *Signer) Sign(msg common.Bytes) (*crypto.Signature, error) {
+	msgHash := crypto.Keccak256(msg)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs, err := s.sign(msgHash)
+	if err != nil {
+		// The session may have been closed by the HSM, retry once with a new session
+		logger.Warnf("Failed to sign with the HSM, reopening the session: %v", err)
+		if err := s.reopenSession(); err != nil {
+			return nil, err
+		}
+		if rs, err = s.sign(msgHash); err != nil {
+			return nil, err
+		}
+	}
+	if len(rs) != 64 {
+		return nil, fmt.Errorf("unexpected HSM signature length: %v", len(rs))
+	}
+	return crypto.SignatureFromRS(msgHash, rs[:32], rs[32:], s.pubKey)
+}
+
+func (s *Signer) sign(msgHash []byte) ([]byte, error) {
+	if !s.hasSession {
+		return nil, errors.New("no HSM session")
+	}
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}
+	if err := s.ctx.SignInit(s.session, mechanism, s.keyHandle); err != nil {
+		return nil, err
+	}
+	return s.ctx.Sign(s.session, msgHash)
+}
+
+// HealthCheck checks that the session is logged in and the key is still available on the token,
+// reopening the session if needed
+func (s *Signer) HealthCheck() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.hasSession {
+		info, err := s.ctx.GetSessionInfo(s.session)
+		if err == nil && isLoggedIn(info) {
+			_, err = s.findObject(pkcs11.CKO_PRIVATE_KEY)
+			return err
+		}
+		logger.Warnf("HSM session lost, reopening: %v", err)
+	}
+	return s.reopenSession()
+}
+
+// MonitorHealth runs the health check periodically until the context is done
+func (s *Signer) MonitorHealth(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.HealthCheck(); err != nil {
+				logger.Errorf("HSM health check failed: %v", err)
+			}
+		}
+	}
+}
+
+// Close closes the session and unloads the PKCS#11 module
+func (s *Signer) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeSession()
+	s.ctx.Finalize()
+	s.ctx.Destroy()
+}
+
+func (s *Signer) openSession() error {
+	session, err := s.ctx.OpenSession(s.slotID, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return fmt.Errorf("failed to open the HSM session: %v", err)
+	}
+	err = s.ctx.Login(session, pkcs11.CKU_USER, s.config.PIN)
+	if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		s.ctx.CloseSession(session)
+		return fmt.Errorf("failed to log in to the HSM: %v", err)
+	}
+	s.session, s.hasSession = session, true
+
+	keyHandle, err := s.findObject(pkcs11.CKO_PRIVATE_KEY)
+	if err != nil {
+		s.closeSession()
+		return err
+	}
+	s.keyHandle = keyHandle
+	return nil
+}
+
+func (s *Signer) reopenSession() error {
+	s.closeSession()
+	// The token may have been moved to another slot
+	slotID, err := findSlot(s.ctx, s.config.TokenLabel)
+	if err != nil {
+		return err
+	}
+	s.slotID = slotID
+	if err := s.openSession(); err != nil {
+		return err
+	}
+	// The token may have been replaced with one holding another key
+	pubKey, err := s.loadPublicKey()
+	if err == nil && !bytes.Equal(pubKey.ToBytes(), s.pubKey.ToBytes()) {
+		err = fmt.Errorf("HSM key %v changed", s.config.KeyLabel)
+	}
+	if err != nil {
+		s.closeSession()
+		return err
+	}
+	return nil
+}
+
+func (s *Signer) closeSession() {
+	if s.hasSession {
+		s.ctx.CloseSession(s.session)
+		s.hasSession = false
+	}
+}
+
+func (s *Signer) findObject(class uint) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.config.KeyLabel),
+	}
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, err
+	}
+	objects, _, err := s.ctx.FindObjects(s.session, 2)
+	s.ctx.FindObjectsFinal(s.session)
+	if err != nil {
+		return 0, err
+	}
+	if len(objects) == 0 {
+		return 0, ErrKeyNotFound
+	}
+	if len(objects) > 1 {
+		return 0, fmt.Errorf("multiple HSM keys with label %v", s.config.KeyLabel)
+	}
+	return objects[0], nil
+}
+
+func (s *Signer) loadPublicKey() (*crypto.PublicKey, error) {
+	object, err := s.findObject(pkcs11.CKO_PUBLIC_KEY)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := s.ctx.GetAttributeValue(s.session, object, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var params, point []byte
+	for _, attr := range attrs {
+		switch attr.Type {
+		case pkcs11.CKA_EC_PARAMS:
+			params = attr.Value
+		case pkcs11.CKA_EC_POINT:
+			point = attr.Value
+		}
+	}
+	if !bytes.Equal(params, secp256k1OID) {
+		return nil, fmt.Errorf("HSM key %v is not a secp256k1 key", s.config.KeyLabel)
+	}
+	return crypto.PublicKeyFromBytes(decodeECPoint(point))
+}
+
+// decodeECPoint unwraps the DER octet string of CKA_EC_POINT, some modules return the raw point
+func decodeECPoint(point []byte) []byte {
+	var raw []byte
+	if rest, err := asn1.Unmarshal(point, &raw); err == nil && len(rest) == 0 {
+		return raw
+	}
+	return point
+}
+
+func findSlot(ctx module, tokenLabel string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(info.Label) == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, ErrTokenNotFound
+}
+
+func isLoggedIn(info pkcs11.SessionInfo) bool {
+	return info.State == ckSRoUserFunctions || info.State == ckSRwUserFunctions
+}
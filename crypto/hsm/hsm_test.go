@@ -0,0 +1,219 @@
+package hsm
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+func TestSigner(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	module := newFakeModule(t, "validator")
+	module.pin = "1234"
+	signer, err := newSigner(module, Config{TokenLabel: "theta", KeyLabel: "validator", PIN: "1234"})
+	require.Nil(err)
+	assert.Equal(module.pubKey.Address(), signer.PublicKey().Address())
+
+	for i := 0; i < 20; i++ {
+		msg := common.Bytes("message " + string(rune('a'+i)))
+		sig, err := signer.Sign(msg)
+		require.Nil(err)
+		assert.True(sig.Verify(msg, module.pubKey.Address()))
+		// The signatures are normalized to low S values
+		assert.Equal(byte(0), sig.ToBytes()[32]&0x80)
+	}
+	assert.Nil(signer.HealthCheck())
+
+	// The signer recovers from lost sessions
+	module.closeAllSessions()
+	sig, err := signer.Sign(common.Bytes("hello"))
+	require.Nil(err)
+	assert.True(sig.Verify(common.Bytes("hello"), module.pubKey.Address()))
+	module.closeAllSessions()
+	assert.Nil(signer.HealthCheck())
+
+	// A different key under the same label is not used
+	other := newFakeModule(t, "validator")
+	module.privKey, module.pubKey = other.privKey, other.pubKey
+	module.closeAllSessions()
+	assert.NotNil(signer.HealthCheck())
+	_, err = signer.Sign(common.Bytes("hello"))
+	assert.NotNil(err)
+
+	signer.Close()
+	assert.True(module.finalized)
+}
+
+func TestSignerErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := newSigner(newFakeModule(t, "validator"), Config{TokenLabel: "other", KeyLabel: "validator"})
+	assert.Equal(ErrTokenNotFound, err)
+
+	_, err = newSigner(newFakeModule(t, "validator"), Config{TokenLabel: "theta", KeyLabel: "other"})
+	assert.Equal(ErrKeyNotFound, err)
+
+	module := newFakeModule(t, "validator")
+	module.pin = "1234"
+	_, err = newSigner(module, Config{TokenLabel: "theta", KeyLabel: "validator", PIN: "0000"})
+	assert.NotNil(err)
+}
+
+// fakeModule emulates a PKCS#11 module with a single token holding one secp256k1 key pair
+type fakeModule struct {
+	t *testing.T
+
+	label     string
+	pin       string
+	privKey   *crypto.PrivateKey
+	pubKey    *crypto.PublicKey
+	sessions  map[pkcs11.SessionHandle]bool
+	next      pkcs11.SessionHandle
+	found     []pkcs11.ObjectHandle
+	finalized bool
+}
+
+const (
+	fakePrivKeyHandle = pkcs11.ObjectHandle(1)
+	fakePubKeyHandle  = pkcs11.ObjectHandle(2)
+)
+
+var errSessionHandleInvalid = pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID)
+
+func newFakeModule(t *testing.T, label string) *fakeModule {
+	privKey, pubKey, err := crypto.GenerateKeyPair()
+	require.Nil(t, err)
+	return &fakeModule{
+		t:        t,
+		label:    label,
+		privKey:  privKey,
+		pubKey:   pubKey,
+		sessions: make(map[pkcs11.SessionHandle]bool),
+	}
+}
+
+func (m *fakeModule) closeAllSessions() {
+	m.sessions = make(map[pkcs11.SessionHandle]bool)
+}
+
+func (m *fakeModule) Initialize() error { return nil }
+func (m *fakeModule) Finalize() error   { m.finalized = true; return nil }
+func (m *fakeModule) Destroy()          {}
+
+func (m *fakeModule) GetSlotList(tokenPresent bool) ([]uint, error) {
+	return []uint{7}, nil
+}
+
+func (m *fakeModule) GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error) {
+	return pkcs11.TokenInfo{Label: "theta"}, nil
+}
+
+func (m *fakeModule) OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error) {
+	m.next++
+	m.sessions[m.next] = false
+	return m.next, nil
+}
+
+func (m *fakeModule) CloseSession(sh pkcs11.SessionHandle) error {
+	delete(m.sessions, sh)
+	return nil
+}
+
+func (m *fakeModule) GetSessionInfo(sh pkcs11.SessionHandle) (pkcs11.SessionInfo, error) {
+	loggedIn, ok := m.sessions[sh]
+	if !ok {
+		return pkcs11.SessionInfo{}, errSessionHandleInvalid
+	}
+	if loggedIn {
+		return pkcs11.SessionInfo{State: ckSRoUserFunctions}, nil
+	}
+	return pkcs11.SessionInfo{State: ckSRoPublicSession}, nil
+}
+
+func (m *fakeModule) Login(sh pkcs11.SessionHandle, userType uint, pin string) error {
+	if _, ok := m.sessions[sh]; !ok {
+		return errSessionHandleInvalid
+	}
+	if pin != m.pin {
+		return pkcs11.Error(pkcs11.CKR_PIN_INCORRECT)
+	}
+	m.sessions[sh] = true
+	return nil
+}
+
+func (m *fakeModule) FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error {
+	if !m.sessions[sh] {
+		return errSessionHandleInvalid
+	}
+	m.found = nil
+	for _, handle := range []pkcs11.ObjectHandle{fakePrivKeyHandle, fakePubKeyHandle} {
+		class := pkcs11.NewAttribute(pkcs11.CKA_CLASS, uint(pkcs11.CKO_PRIVATE_KEY))
+		if handle == fakePubKeyHandle {
+			class = pkcs11.NewAttribute(pkcs11.CKA_CLASS, uint(pkcs11.CKO_PUBLIC_KEY))
+		}
+		matches := true
+		for _, attr := range temp {
+			switch attr.Type {
+			case pkcs11.CKA_CLASS:
+				matches = matches && bytes.Equal(attr.Value, class.Value)
+			case pkcs11.CKA_LABEL:
+				matches = matches && string(attr.Value) == m.label
+			}
+		}
+		if matches {
+			m.found = append(m.found, handle)
+		}
+	}
+	return nil
+}
+
+func (m *fakeModule) FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error) {
+	return m.found, false, nil
+}
+
+func (m *fakeModule) FindObjectsFinal(sh pkcs11.SessionHandle) error { return nil }
+
+func (m *fakeModule) GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle,
+	a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error) {
+	if !m.sessions[sh] || o != fakePubKeyHandle {
+		return nil, errSessionHandleInvalid
+	}
+	point, err := asn1.Marshal(m.pubKey.ToBytes())
+	require.Nil(m.t, err)
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, secp256k1OID),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, point),
+	}, nil
+}
+
+func (m *fakeModule) SignInit(sh pkcs11.SessionHandle, mech []*pkcs11.Mechanism, o pkcs11.ObjectHandle) error {
+	if !m.sessions[sh] {
+		return errSessionHandleInvalid
+	}
+	if o != fakePrivKeyHandle || len(mech) != 1 || mech[0].Mechanism != pkcs11.CKM_ECDSA {
+		return errors.New("unexpected sign request")
+	}
+	return nil
+}
+
+func (m *fakeModule) Sign(sh pkcs11.SessionHandle, message []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, crypto.PrivKeyToECDSA(m.privKey), message)
+	if err != nil {
+		return nil, err
+	}
+	rs := make([]byte, 64)
+	copy(rs[32-len(r.Bytes()):32], r.Bytes())
+	copy(rs[64-len(s.Bytes()):], s.Bytes())
+	return rs, nil
+}
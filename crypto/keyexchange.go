@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/math"
+	"golang.org/x/crypto/hkdf"
+)
+
+//
+// -------------------------- Key Exchange APIs -------------------------- //
+//
+
+var errEphemeralKeyUsed = errors.New("ephemeral key already used")
+
+//
+// EphemeralKey is a single use secp256k1 key pair for the ECDH key exchanges. The private key is
+// erased once the shared secret is computed, so the secrets derived from the exchange can't be
+// recovered later from the long term keys of the parties, which provides forward secrecy.
+//
+type EphemeralKey struct {
+	privKey *ecdsa.PrivateKey
+}
+
+// GenerateEphemeralKey generates a new ephemeral key pair
+func GenerateEphemeralKey() (*EphemeralKey, error) {
+	privKey, err := ecdsa.GenerateKey(s256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &EphemeralKey{privKey: privKey}, nil
+}
+
+// PublicKeyBytes returns the uncompressed public key to send to the remote party
+func (ek *EphemeralKey) PublicKeyBytes() common.Bytes {
+	if ek.privKey == nil {
+		return nil
+	}
+	return fromECDSAPub(&ek.privKey.PublicKey)
+}
+
+// SharedSecret computes the ECDH shared secret with the public key of the remote party, and
+// erases the private key
+func (ek *EphemeralKey) SharedSecret(remotePubKey common.Bytes) ([]byte, error) {
+	if ek.privKey == nil {
+		return nil, errEphemeralKeyUsed
+	}
+	pub, err := unmarshalPubkey(remotePubKey)
+	if err != nil {
+		return nil, err
+	}
+	seckey := math.PaddedBigBytes(ek.privKey.D, 32)
+	defer zeroBytes(seckey)
+	ek.Destroy()
+
+	x, _ := s256().ScalarMult(pub.X, pub.Y, seckey)
+	if x == nil || x.Sign() == 0 {
+		return nil, errInvalidPubkey
+	}
+	return math.PaddedBigBytes(x, 32), nil
+}
+
+// Destroy erases the private key
+func (ek *EphemeralKey) Destroy() {
+	if ek.privKey == nil {
+		return
+	}
+	ek.privKey.D.SetInt64(0)
+	ek.privKey = nil
+}
+
+// DeriveKeys expands the secret into the given number of bytes of key material with HKDF-SHA256.
+// Different info strings give independent keys from the same secret.
+func DeriveKeys(secret, salt []byte, info string, length int) ([]byte, error) {
+	keys := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
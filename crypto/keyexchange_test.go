@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEphemeralKeyExchange(t *testing.T) {
+	assert := assert.New(t)
+
+	alice, err := GenerateEphemeralKey()
+	assert.Nil(err)
+	bob, err := GenerateEphemeralKey()
+	assert.Nil(err)
+	alicePub, bobPub := alice.PublicKeyBytes(), bob.PublicKeyBytes()
+
+	aliceSecret, err := alice.SharedSecret(bobPub)
+	assert.Nil(err)
+	bobSecret, err := bob.SharedSecret(alicePub)
+	assert.Nil(err)
+	assert.Equal(32, len(aliceSecret))
+	assert.Equal(aliceSecret, bobSecret)
+
+	// The keys are single use
+	_, err = alice.SharedSecret(bobPub)
+	assert.Equal(errEphemeralKeyUsed, err)
+	assert.Nil(alice.PublicKeyBytes())
+
+	carol, err := GenerateEphemeralKey()
+	assert.Nil(err)
+	_, err = carol.SharedSecret(alicePub[:64])
+	assert.NotNil(err)
+}
+
+func TestDeriveKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	secret := []byte("secret")
+	keys1, err := DeriveKeys(secret, []byte("salt"), "info", 64)
+	assert.Nil(err)
+	assert.Equal(64, len(keys1))
+	keys2, err := DeriveKeys(secret, []byte("salt"), "info", 64)
+	assert.Nil(err)
+	assert.Equal(keys1, keys2)
+
+	keys3, err := DeriveKeys(secret, []byte("salt"), "other info", 64)
+	assert.Nil(err)
+	assert.NotEqual(keys1, keys3)
+	keys4, err := DeriveKeys(secret, []byte("other salt"), "info", 64)
+	assert.Nil(err)
+	assert.NotEqual(keys1, keys4)
+}
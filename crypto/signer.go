@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/math"
+)
+
+var errInvalidSignature = errors.New("invalid signature")
+
+//
+// Signer signs messages with a secp256k1 key, which may be held in memory, or by an external
+// device such as a hardware security module. The signatures are in the same format as the
+// signatures of PrivateKey.Sign.
+//
+type Signer interface {
+	PublicKey() *PublicKey
+	Sign(msg common.Bytes) (*Signature, error)
+}
+
+var _ Signer = (*PrivateKey)(nil)
+
+// SignatureFromRS returns the signature of the message hash with the given R and S values signed by
+// the public key. S is normalized to the lower half of the curve order, and the recovery ID is
+// searched for, since the external signers usually only return R and S.
+func SignatureFromRS(msgHash []byte, r, s []byte, pubKey *PublicKey) (*Signature, error) {
+	if len(r) > 32 || len(s) > 32 {
+		return nil, errInvalidSignature
+	}
+	sVal := new(big.Int).SetBytes(s)
+	if sVal.Cmp(secp256k1halfN) > 0 {
+		sVal.Sub(secp256k1N, sVal)
+	}
+
+	sigBytes := make([]byte, 65)
+	copy(sigBytes[32-len(r):32], r)
+	copy(sigBytes[32:64], math.PaddedBigBytes(sVal, 32))
+	expected := fromECDSAPub(pubKey.pubKey)
+	for v := byte(0); v < 2; v++ {
+		sigBytes[64] = v
+		recovered, err := ecrecover(msgHash, sigBytes)
+		if err == nil && bytes.Equal(recovered, expected) {
+			return &Signature{data: sigBytes}, nil
+		}
+	}
+	return nil, errInvalidSignature
+}
@@ -0,0 +1,258 @@
+// Package vrf implements the verifiable random function ECVRF over secp256k1 with SHA-256 and the
+// try-and-increment hash to curve, following the construction of RFC 9381. The keys are the
+// secp256k1 keys of the accounts and the validators, so a validator proves its VRF outputs with the
+// key it signs the blocks with. The nonce is derived from the secret key and the hashed input
+// rather than with RFC 6979, which changes the proofs but not their verification.
+package vrf
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/secp256k1"
+)
+
+const (
+	suite = 0xFE // ECVRF-SECP256K1-SHA256-TAI
+
+	ptLen = 33 // compressed point
+	cLen  = 16 // challenge
+	qLen  = 32 // scalar
+
+	// ProofLength is the length of the encoded proof Gamma || c || s
+	ProofLength = ptLen + cLen + qLen
+	// OutputLength is the length of the VRF output
+	OutputLength = sha256.Size
+)
+
+var (
+	ErrInvalidProof = errors.New("invalid VRF proof")
+	ErrInvalidKey   = errors.New("invalid VRF key")
+)
+
+var curve = secp256k1.S256()
+
+type point struct {
+	x, y *big.Int
+}
+
+// GenerateKey generates a new key pair for the VRF
+func GenerateKey() (*crypto.PrivateKey, *crypto.PublicKey, error) {
+	return crypto.GenerateKeyPair()
+}
+
+// Prove returns the VRF output of the input with the secret key, along with the proof of the output
+func Prove(privKey *crypto.PrivateKey, alpha []byte) (beta []byte, proof []byte, err error) {
+	x := new(big.Int).Set(privKey.D())
+	if x.Sign() <= 0 || x.Cmp(curve.N) >= 0 {
+		return nil, nil, ErrInvalidKey
+	}
+	Y := scalarBaseMult(x)
+	H, err := hashToCurve(Y, alpha)
+	if err != nil {
+		return nil, nil, err
+	}
+	hString := encodePoint(H)
+
+	gamma := scalarMult(H, x)
+	k := nonce(x, hString)
+	c := hashPoints(H, gamma, scalarBaseMult(k), scalarMult(H, k))
+	s := new(big.Int).Mul(c, x)
+	s.Add(s, k)
+	s.Mod(s, curve.N)
+
+	proof = make([]byte, 0, ProofLength)
+	proof = append(proof, encodePoint(gamma)...)
+	proof = append(proof, padBytes(c.Bytes(), cLen)...)
+	proof = append(proof, padBytes(s.Bytes(), qLen)...)
+	return proofToHash(gamma), proof, nil
+}
+
+// Verify verifies the proof of the VRF output of the input with the public key, and returns the
+// output
+func Verify(pubKey *crypto.PublicKey, alpha []byte, proof []byte) (beta []byte, err error) {
+	Y, err := decodePublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	gamma, c, s, err := decodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	H, err := hashToCurve(Y, alpha)
+	if err != nil {
+		return nil, err
+	}
+
+	// U = s*B - c*Y, V = s*H - c*Gamma
+	U, ok := sub(scalarBaseMult(s), scalarMult(Y, c))
+	if !ok {
+		return nil, ErrInvalidProof
+	}
+	V, ok := sub(scalarMult(H, s), scalarMult(gamma, c))
+	if !ok {
+		return nil, ErrInvalidProof
+	}
+	if hashPoints(H, gamma, U, V).Cmp(c) != 0 {
+		return nil, ErrInvalidProof
+	}
+	return proofToHash(gamma), nil
+}
+
+// ProofToHash returns the VRF output of the proof, without verifying the proof
+func ProofToHash(proof []byte) ([]byte, error) {
+	gamma, _, _, err := decodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	return proofToHash(gamma), nil
+}
+
+// hashToCurve hashes the input to a point with the try-and-increment method
+func hashToCurve(Y point, alpha []byte) (point, error) {
+	pkString := encodePoint(Y)
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha256.New()
+		h.Write([]byte{suite, 0x01})
+		h.Write(pkString)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+		if H, ok := decodePoint(append([]byte{0x02}, h.Sum(nil)...)); ok {
+			return H, nil
+		}
+	}
+	return point{}, errors.New("failed to hash the VRF input to the curve")
+}
+
+// hashPoints returns the challenge of the points
+func hashPoints(points ...point) *big.Int {
+	h := sha256.New()
+	h.Write([]byte{suite, 0x02})
+	for _, p := range points {
+		h.Write(encodePoint(p))
+	}
+	h.Write([]byte{0x00})
+	return new(big.Int).SetBytes(h.Sum(nil)[:cLen])
+}
+
+func proofToHash(gamma point) []byte {
+	// The cofactor of secp256k1 is 1
+	h := sha256.New()
+	h.Write([]byte{suite, 0x03})
+	h.Write(encodePoint(gamma))
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
+
+// nonce derives the nonce deterministically from the secret key and the hashed input
+func nonce(x *big.Int, hString []byte) *big.Int {
+	for ctr := byte(0); ; ctr++ {
+		h := sha256.New()
+		h.Write(padBytes(x.Bytes(), qLen))
+		h.Write(hString)
+		h.Write([]byte{ctr})
+		k := new(big.Int).SetBytes(h.Sum(nil))
+		if k.Sign() > 0 && k.Cmp(curve.N) < 0 {
+			return k
+		}
+	}
+}
+
+func decodeProof(proof []byte) (gamma point, c, s *big.Int, err error) {
+	if len(proof) != ProofLength {
+		return point{}, nil, nil, ErrInvalidProof
+	}
+	gamma, ok := decodePoint(proof[:ptLen])
+	if !ok {
+		return point{}, nil, nil, ErrInvalidProof
+	}
+	c = new(big.Int).SetBytes(proof[ptLen : ptLen+cLen])
+	s = new(big.Int).SetBytes(proof[ptLen+cLen:])
+	if s.Cmp(curve.N) >= 0 {
+		return point{}, nil, nil, ErrInvalidProof
+	}
+	return gamma, c, s, nil
+}
+
+func decodePublicKey(pubKey *crypto.PublicKey) (point, error) {
+	if pubKey == nil || pubKey.IsEmpty() {
+		return point{}, ErrInvalidKey
+	}
+	x, y := curve.Unmarshal(pubKey.ToBytes())
+	if x == nil || !curve.IsOnCurve(x, y) {
+		return point{}, ErrInvalidKey
+	}
+	return point{x, y}, nil
+}
+
+// encodePoint returns the compressed encoding of the point
+func encodePoint(p point) []byte {
+	b := make([]byte, ptLen)
+	b[0] = 0x02 | byte(p.y.Bit(0))
+	copy(b[1:], padBytes(p.x.Bytes(), ptLen-1))
+	return b
+}
+
+// decodePoint decodes a compressed point, solving y^2 = x^3 + 7
+func decodePoint(b []byte) (point, bool) {
+	if len(b) != ptLen || (b[0] != 0x02 && b[0] != 0x03) {
+		return point{}, false
+	}
+	x := new(big.Int).SetBytes(b[1:])
+	if x.Cmp(curve.P) >= 0 {
+		return point{}, false
+	}
+	y2 := new(big.Int).Exp(x, big.NewInt(3), curve.P)
+	y2.Add(y2, curve.B)
+	y2.Mod(y2, curve.P)
+	// P = 3 mod 4, so the square root is y2^((P+1)/4)
+	exp := new(big.Int).Add(curve.P, big.NewInt(1))
+	exp.Rsh(exp, 2)
+	y := new(big.Int).Exp(y2, exp, curve.P)
+	if new(big.Int).Exp(y, big.NewInt(2), curve.P).Cmp(y2) != 0 {
+		return point{}, false
+	}
+	if y.Bit(0) != uint(b[0]&1) {
+		y.Sub(curve.P, y)
+	}
+	return point{x, y}, true
+}
+
+func scalarBaseMult(k *big.Int) point {
+	x, y := curve.ScalarBaseMult(padBytes(new(big.Int).Mod(k, curve.N).Bytes(), qLen))
+	return point{x, y}
+}
+
+func scalarMult(p point, k *big.Int) point {
+	x, y := curve.ScalarMult(p.x, p.y, padBytes(new(big.Int).Mod(k, curve.N).Bytes(), qLen))
+	return point{x, y}
+}
+
+// sub returns p - q, or false if either is the point at infinity or the difference is
+func sub(p, q point) (point, bool) {
+	if p.x == nil || q.x == nil {
+		return point{}, false
+	}
+	negY := new(big.Int).Sub(curve.P, q.y)
+	negY.Mod(negY, curve.P)
+	if p.x.Cmp(q.x) == 0 {
+		if p.y.Cmp(negY) != 0 {
+			return point{}, false // p = q, the difference is the point at infinity
+		}
+		x, y := curve.Double(p.x, p.y) // p = -q
+		return point{x, y}, true
+	}
+	x, y := curve.Add(p.x, p.y, q.x, negY)
+	return point{x, y}, true
+}
+
+func padBytes(b []byte, length int) []byte {
+	if len(b) >= length {
+		return b
+	}
+	padded := make([]byte, length)
+	copy(padded[length-len(b):], b)
+	return padded
+}
@@ -0,0 +1,72 @@
+package vrf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveVerify(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	privKey, pubKey, err := GenerateKey()
+	require.Nil(err)
+
+	alpha := []byte("epoch 42")
+	beta, proof, err := Prove(privKey, alpha)
+	require.Nil(err)
+	assert.Equal(OutputLength, len(beta))
+	assert.Equal(ProofLength, len(proof))
+
+	verified, err := Verify(pubKey, alpha, proof)
+	assert.Nil(err)
+	assert.Equal(beta, verified)
+
+	hash, err := ProofToHash(proof)
+	assert.Nil(err)
+	assert.Equal(beta, hash)
+
+	// The output is unique for the key and input
+	beta2, proof2, err := Prove(privKey, alpha)
+	require.Nil(err)
+	assert.Equal(beta, beta2)
+	assert.Equal(proof, proof2)
+
+	beta3, _, err := Prove(privKey, []byte("epoch 43"))
+	require.Nil(err)
+	assert.NotEqual(beta, beta3)
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	privKey, pubKey, err := GenerateKey()
+	require.Nil(err)
+	_, otherPubKey, err := GenerateKey()
+	require.Nil(err)
+
+	alpha := []byte("epoch 42")
+	_, proof, err := Prove(privKey, alpha)
+	require.Nil(err)
+
+	_, err = Verify(otherPubKey, alpha, proof)
+	assert.Equal(ErrInvalidProof, err)
+
+	_, err = Verify(pubKey, []byte("epoch 43"), proof)
+	assert.Equal(ErrInvalidProof, err)
+
+	for _, i := range []int{1, ptLen, ptLen + cLen, ProofLength - 1} {
+		tampered := append([]byte{}, proof...)
+		tampered[i] ^= 0x01
+		_, err = Verify(pubKey, alpha, tampered)
+		assert.NotNil(err, "tampered byte %v", i)
+	}
+
+	_, err = Verify(pubKey, alpha, proof[:ProofLength-1])
+	assert.Equal(ErrInvalidProof, err)
+	_, err = Verify(nil, alpha, proof)
+	assert.Equal(ErrInvalidKey, err)
+}
@@ -2,20 +2,21 @@ package dispatcher
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/spf13/viper"
 
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/p2p"
 	p2ptypes "github.com/thetatoken/theta/p2p/types"
 	"github.com/thetatoken/theta/p2pl"
-
-	log "github.com/sirupsen/logrus"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "dispatcher"})
+var logger = util.GetLoggerForModule("dispatcher")
 
 //
 // Dispatcher dispatches messages to approporiate destinations
@@ -60,8 +61,14 @@ func (dp *Dispatcher) Start(ctx context.Context) error {
 	return err
 }
 
-// Stop is called when the dispatcher stops
+// Stop is called when the dispatcher stops, the networks disconnect from their peers
 func (dp *Dispatcher) Stop() {
+	if !reflect.ValueOf(dp.p2pnet).IsNil() {
+		dp.p2pnet.Stop()
+	}
+	if !reflect.ValueOf(dp.p2plnet).IsNil() {
+		dp.p2plnet.Stop()
+	}
 	dp.cancel()
 }
 
@@ -180,6 +187,32 @@ func (dp *Dispatcher) PeerExists(peerID string) bool {
 	return false
 }
 
+// ConnectToPeer connects to the peer at the given address. A multiaddress, e.g. "/ip4/<ip>/tcp/<port>/p2p/<id>",
+// is connected with the libp2p network, and an "<ip>:<port>" address with the other network.
+func (dp *Dispatcher) ConnectToPeer(address string) error {
+	if strings.HasPrefix(address, "/") && !reflect.ValueOf(dp.p2plnet).IsNil() {
+		return dp.p2plnet.ConnectToPeer(address)
+	}
+	if !reflect.ValueOf(dp.p2pnet).IsNil() {
+		return dp.p2pnet.ConnectToPeer(address)
+	}
+	if !reflect.ValueOf(dp.p2plnet).IsNil() {
+		return dp.p2plnet.ConnectToPeer(address)
+	}
+	return errors.New("No network to connect to the peer")
+}
+
+// DisconnectPeer disconnects from the given peer
+func (dp *Dispatcher) DisconnectPeer(peerID string) error {
+	if !reflect.ValueOf(dp.p2pnet).IsNil() {
+		return dp.p2pnet.DisconnectPeer(peerID)
+	}
+	if !reflect.ValueOf(dp.p2plnet).IsNil() {
+		return dp.p2plnet.DisconnectPeer(peerID)
+	}
+	return errors.New("No network to disconnect the peer from")
+}
+
 // send delivers message directly to a list of peers.
 func (dp *Dispatcher) send(peerIDs []string, channelID common.ChannelIDEnum, content interface{}) {
 	messageOld := p2ptypes.Message{
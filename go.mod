@@ -6,10 +6,11 @@ require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/dgraph-io/badger v1.6.0-rc1
 	github.com/fd/go-nat v1.0.0
-	github.com/golang/protobuf v1.3.1
+	github.com/golang/protobuf v1.3.2
 	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db
 	github.com/gorilla/context v1.1.1 // indirect
 	github.com/gorilla/mux v1.6.2
+	github.com/graph-gophers/graphql-go v0.0.0-20191115155744-f33e81362277
 	github.com/hashicorp/golang-lru v0.5.1
 	github.com/herumi/bls-eth-go-binary v0.0.0-20200107021104-147ed25f233e
 	github.com/huin/goupnp v1.0.0
@@ -32,6 +33,7 @@ require (
 	github.com/libp2p/go-nat v0.0.3
 	github.com/libp2p/go-stream-muxer v0.1.0
 	github.com/mattn/go-isatty v0.0.12
+	github.com/miekg/pkcs11 v1.0.3
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/mongodb/mongo-go-driver v0.0.17
 	github.com/multiformats/go-multiaddr v0.0.4
@@ -53,6 +55,7 @@ require (
 	github.com/thetatoken/theta/common v0.0.0
 	github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2 v0.0.0
 	github.com/tidwall/pretty v1.0.0 // indirect
+	github.com/tyler-smith/go-bip39 v1.0.2
 	github.com/wedeploy/gosocketio v0.0.7-beta
 	github.com/xdg/scram v0.0.0-20180814205039-7eeb5667e42c // indirect
 	github.com/xdg/stringprep v0.0.0-20180714160509-73f8eece6fdc // indirect
@@ -63,6 +66,7 @@ require (
 	golang.org/x/net v0.0.0-20191021144547-ec77196f6094
 	golang.org/x/sys v0.0.0-20200223170610-d5e6a3e2c0ae
 	golang.org/x/xerrors v0.0.0-20191011141410-1b5146add898 // indirect
+	google.golang.org/grpc v1.24.0
 	gopkg.in/karalabe/cookiejar.v2 v2.0.0-20150724131613-8dcd6a7f4951
 	gopkg.in/mgo.v2 v2.0.0-20180705113604-9856a29383ce
 )
@@ -0,0 +1,106 @@
+package ledger
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+//
+// Block execution results
+//
+// A block can be applied more than once on the same parent state, e.g. when the consensus engine
+// switches back to a fork, or a block processed earlier is applied again as a hardcoded block. The
+// state after a block is committed to the database the first time it is applied, so the ledger
+// keeps the results of the recently applied blocks keyed by the block hash and the parent state
+// root, and moves to the committed state without executing the block again.
+//
+// The cache does not cut the cost of the blocks applied only once, which is the common case:
+// finalizing a block does not execute it again, and the execution of the block transactions by the
+// proposer in ProposeBlockTxs, on the checked view, is not reused when the proposer applies the block.
+//
+
+type blockResultKey struct {
+	blockHash       common.Hash
+	parentStateRoot common.Hash
+}
+
+// blockResult is the outcome of a block applied by the ledger, besides the committed state
+type blockResult struct {
+	hasValidatorUpdate bool
+	events             []*types.Event
+	receipts           []*types.TxReceipt
+	stateDiffs         []st.StateDiff
+	stateDiffTracked   bool // false if the state diffs were not exported when the block was applied
+}
+
+func newBlockResultCache(size int) *lru.Cache {
+	if size <= 0 {
+		return nil
+	}
+	cache, err := lru.New(size)
+	if err != nil {
+		logger.Panicf("Failed to create the block result cache: %v", err)
+	}
+	return cache
+}
+
+// cacheBlockResult records the result of a block applied on the given parent state
+func (ledger *Ledger) cacheBlockResult(block *core.Block, parentStateRoot common.Hash, res *blockResult) {
+	if ledger.blockResults == nil {
+		return
+	}
+	ledger.blockResults.Add(blockResultKey{blockHash: block.Hash(), parentStateRoot: parentStateRoot}, res)
+}
+
+// applyCachedBlockResult moves the ledger state to the state after the block, if the block has
+// been applied on the same parent state before. It returns false if the block needs to be executed.
+func (ledger *Ledger) applyCachedBlockResult(block *core.Block, parentBlock *core.Block, parentStateRoot common.Hash) (result.Result, bool) {
+	if ledger.blockResults == nil {
+		return result.OK, false
+	}
+	key := blockResultKey{blockHash: block.Hash(), parentStateRoot: parentStateRoot}
+	value, ok := ledger.blockResults.Get(key)
+	if !ok {
+		return result.OK, false
+	}
+	cached := value.(*blockResult)
+	if ledger.stateDiffs != nil && !cached.stateDiffTracked {
+		// The block needs to be executed to track its state diffs for the exporter
+		return result.OK, false
+	}
+
+	if res := ledger.state.ResetState(block); res.IsError() {
+		// The state is no longer available, e.g. pruned
+		logger.Debugf("Failed to apply the cached result of block %v: %v", block.Hash().Hex(), res.Message)
+		ledger.blockResults.Remove(key)
+		ledger.resetState(parentBlock)
+		return result.OK, false
+	}
+
+	if ledger.stateDiffs != nil {
+		ledger.stateDiffs.Export(block, cached.stateDiffs)
+	}
+
+	if len(cached.events) > 0 {
+		ledger.chain.AddBlockEvents(block.Hash(), cached.events)
+	}
+	if len(cached.receipts) > 0 {
+		ledger.chain.AddCanonicalTxReceipts(cached.receipts)
+	}
+
+	blockRawTxs := block.Txs
+	go func() {
+		ledger.mempool.Lock()
+		defer ledger.mempool.Unlock()
+
+		ledger.mempool.UpdateUnsafe(blockRawTxs) // clear txs from the mempool
+	}()
+
+	logger.Debugf("ApplyBlockTxs: Applied the cached result, block.height = %v", block.Height)
+
+	return result.OKWith(result.Info{"hasValidatorUpdate": cached.hasValidatorUpdate}), true
+}
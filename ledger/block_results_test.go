@@ -0,0 +1,108 @@
+package ledger
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/statediff"
+)
+
+func TestApplyCachedBlockResult(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	_, ledger, _ := newTestLedger()
+	require.NotNil(ledger.blockResults)
+
+	// Commits the state after the block
+	view := ledger.state.Delivered()
+	parentStateRoot := view.Hash()
+	view.Set(common.Bytes("key"), common.Bytes("value"))
+	stateRoot := view.Save()
+
+	parent := &core.Block{BlockHeader: &core.BlockHeader{Height: 1, StateHash: parentStateRoot}}
+	block := &core.Block{BlockHeader: &core.BlockHeader{Height: 2, StateHash: stateRoot, Parent: parent.Hash()}}
+	ledger.resetState(parent)
+
+	_, ok := ledger.applyCachedBlockResult(block, parent, parentStateRoot)
+	assert.False(ok)
+
+	ledger.cacheBlockResult(block, parentStateRoot, &blockResult{hasValidatorUpdate: true})
+	_, ok = ledger.applyCachedBlockResult(block, parent, common.Hash{1})
+	assert.False(ok)
+
+	res, ok := ledger.applyCachedBlockResult(block, parent, parentStateRoot)
+	require.True(ok)
+	assert.True(res.IsOK())
+	assert.Equal(true, res.Info["hasValidatorUpdate"])
+	assert.Equal(stateRoot, ledger.state.Delivered().Hash())
+	assert.Equal(uint64(2), ledger.state.Height())
+	assert.Equal(common.Bytes("value"), ledger.state.Delivered().Get(common.Bytes("key")))
+
+	// The block is executed if the state after it is not available
+	missing := &core.Block{BlockHeader: &core.BlockHeader{Height: 2, StateHash: common.Hash{2}, Parent: parent.Hash()}}
+	ledger.resetState(parent)
+	ledger.cacheBlockResult(missing, parentStateRoot, &blockResult{})
+	_, ok = ledger.applyCachedBlockResult(missing, parent, parentStateRoot)
+	assert.False(ok)
+	assert.Equal(parentStateRoot, ledger.state.Delivered().Hash())
+	assert.Equal(1, ledger.blockResults.Len())
+}
+
+type recordingSink struct {
+	mu    sync.Mutex
+	diffs []*statediff.BlockStateDiff
+}
+
+func (sink *recordingSink) Publish(diff *statediff.BlockStateDiff) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.diffs = append(sink.diffs, diff)
+	return nil
+}
+
+func (sink *recordingSink) Close() error {
+	return nil
+}
+
+func TestApplyCachedBlockResultExportsStateDiffs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	_, ledger, _ := newTestLedger()
+	sink := &recordingSink{}
+	exporter := statediff.NewExporter(sink)
+	ledger.SetStateDiffExporter(exporter)
+
+	view := ledger.state.Delivered()
+	parentStateRoot := view.Hash()
+	view.Set(common.Bytes("key"), common.Bytes("value"))
+	stateRoot := view.Save()
+
+	parent := &core.Block{BlockHeader: &core.BlockHeader{Height: 1, StateHash: parentStateRoot}}
+	block := &core.Block{BlockHeader: &core.BlockHeader{Height: 2, StateHash: stateRoot, Parent: parent.Hash()}}
+	ledger.resetState(parent)
+
+	// The block is executed if its state diffs were not tracked when it was applied
+	ledger.cacheBlockResult(block, parentStateRoot, &blockResult{})
+	_, ok := ledger.applyCachedBlockResult(block, parent, parentStateRoot)
+	assert.False(ok)
+	assert.Equal(parentStateRoot, ledger.state.Delivered().Hash())
+
+	stateDiffs := []st.StateDiff{{Key: common.Bytes("key"), After: common.Bytes("value")}}
+	ledger.cacheBlockResult(block, parentStateRoot, &blockResult{stateDiffs: stateDiffs, stateDiffTracked: true})
+	_, ok = ledger.applyCachedBlockResult(block, parent, parentStateRoot)
+	require.True(ok)
+	assert.Equal(stateRoot, ledger.state.Delivered().Hash())
+
+	exporter.Stop()
+	require.Equal(1, len(sink.diffs))
+	assert.Equal(common.JSONUint64(2), sink.diffs[0].Height)
+	assert.Equal(block.Hash(), sink.diffs[0].BlockHash)
+	assert.Equal(stateDiffs, sink.diffs[0].Raw)
+}
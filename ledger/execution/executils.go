@@ -7,6 +7,7 @@ import (
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
 	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
 )
@@ -162,14 +163,14 @@ func validateInputsBasic(ins []types.TxInput) result.Result {
 }
 
 // Validate inputs and compute total amount of coins
-func validateInputsAdvanced(accounts map[string]*types.Account, signBytes []byte, ins []types.TxInput, blockHeight uint64) (total types.Coins, res result.Result) {
+func validateInputsAdvanced(view *state.StoreView, accounts map[string]*types.Account, signBytes []byte, ins []types.TxInput) (total types.Coins, res result.Result) {
 	total = types.NewCoins(0, 0)
 	for _, in := range ins {
 		acc := accounts[string(in.Address[:])]
 		if acc == nil {
 			panic("validateInputsAdvanced() expects account in accounts")
 		}
-		res = validateInputAdvanced(acc, signBytes, in, blockHeight)
+		res = validateInputAdvanced(view, acc, signBytes, in)
 		if res.IsError() {
 			return
 		}
@@ -179,7 +180,7 @@ func validateInputsAdvanced(accounts map[string]*types.Account, signBytes []byte
 	return total, result.OK
 }
 
-func validateInputAdvanced(acc *types.Account, signBytes []byte, in types.TxInput, blockHeight uint64) result.Result {
+func validateInputAdvanced(view *state.StoreView, acc *types.Account, signBytes []byte, in types.TxInput) result.Result {
 	// Check sequence/coins
 	seq, balance := acc.Sequence, acc.Balance
 	if seq+1 != in.Sequence {
@@ -194,13 +195,7 @@ func validateInputAdvanced(acc *types.Account, signBytes []byte, in types.TxInpu
 	}
 
 	// Check signatures
-	signatureValid := in.Signature.Verify(signBytes, acc.Address)
-	if blockHeight >= common.HeightTxWrapperExtension {
-		signBytesV2 := types.ChangeEthereumTxWrapper(signBytes, 2)
-		signatureValid = signatureValid || in.Signature.Verify(signBytesV2, acc.Address)
-	}
-
-	if !signatureValid {
+	if !verifyTxSignature(view, in.Signature, acc.Address, signBytes) {
 		return result.Error("Signature verification failed, SignBytes: %v",
 			hex.EncodeToString(signBytes)).WithErrorCode(result.CodeInvalidSignature)
 	}
@@ -208,6 +203,31 @@ func validateInputAdvanced(acc *types.Account, signBytes []byte, in types.TxInpu
 	return result.OK
 }
 
+// acceptedSignBytes returns the sign bytes the signature of a transaction may be over in the block on
+// top of the view. The sign bytes in the v2 Ethereum tx wrapper are also accepted from HeightTxWrapperExtension,
+// and only the sign bytes in the v3 wrapper, which commit to the numeric chain ID of the network, are accepted
+// from HeightEnableChainIDReplayProtection.
+func acceptedSignBytes(view *state.StoreView, signBytes []byte) []common.Bytes {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	if blockHeight >= common.HeightEnableChainIDReplayProtection {
+		return []common.Bytes{types.ReplayProtectedSignBytes(signBytes, view.GetNumericChainID())}
+	}
+	if blockHeight >= common.HeightTxWrapperExtension {
+		return []common.Bytes{signBytes, types.ChangeEthereumTxWrapper(signBytes, 2)}
+	}
+	return []common.Bytes{signBytes}
+}
+
+// verifyTxSignature checks the signature of the signer against the sign bytes accepted in the block on top of the view
+func verifyTxSignature(view *state.StoreView, sig *crypto.Signature, signer common.Address, signBytes []byte) bool {
+	for _, accepted := range acceptedSignBytes(view, signBytes) {
+		if sig.Verify(accepted, signer) {
+			return true
+		}
+	}
+	return false
+}
+
 func validateOutputsBasic(outs []types.TxOutput) result.Result {
 	for _, out := range outs {
 		// Check TxOutput basic
@@ -267,22 +287,107 @@ func sanityCheckForGasPrice(gasPrice *big.Int, blockHeight uint64) bool {
 	return true
 }
 
-func sanityCheckForFee(fee types.Coins, blockHeight uint64) (minimumFee *big.Int, success bool) {
+// getScheduledMinimumTxFee returns the minimum fee of the transaction with the given size as specified
+// by the fee schedule stored in the ledger state
+func getScheduledMinimumTxFee(view *state.StoreView, tx types.Tx, size uint64) *big.Int {
+	txType, err := types.GetTxType(tx)
+	if err != nil {
+		// Should never happen, the executors only handle the supported tx types
+		logger.Panic(err)
+	}
+	return view.GetFeeSchedule().MinimumFee(txType, size)
+}
+
+func sanityCheckForFee(view *state.StoreView, tx types.Tx, fee types.Coins, blockHeight uint64) (minimumFee *big.Int, success bool) {
 	fee = fee.NoNil()
-	minimumFee = types.GetMinimumTransactionFeeTFuelWei(blockHeight)
+	if blockHeight < common.HeightEnableFeeSchedule {
+		minimumFee = types.GetMinimumTransactionFeeTFuelWei(blockHeight)
+	} else {
+		minimumFee = getScheduledMinimumTxFee(view, tx, 0)
+	}
+	success = (fee.ThetaWei.Cmp(types.Zero) == 0 && fee.TFuelWei.Cmp(minimumFee) >= 0)
+
+	return minimumFee, success
+}
+
+func sanityCheckForSendTxFee(view *state.StoreView, tx types.Tx, fee types.Coins, numAccountsAffected uint64, blockHeight uint64) (minimumFee *big.Int, success bool) {
+	fee = fee.NoNil()
+	if blockHeight < common.HeightEnableFeeSchedule {
+		minimumFee = types.GetSendTxMinimumTransactionFeeTFuelWei(numAccountsAffected, blockHeight)
+	} else {
+		minimumFee = getScheduledMinimumTxFee(view, tx, numAccountsAffected)
+	}
 	success = (fee.ThetaWei.Cmp(types.Zero) == 0 && fee.TFuelWei.Cmp(minimumFee) >= 0)
 
 	return minimumFee, success
 }
 
-func sanityCheckForSendTxFee(fee types.Coins, numAccountsAffected uint64, blockHeight uint64) (minimumFee *big.Int, success bool) {
+func sanityCheckForBatchSendTxFee(view *state.StoreView, tx types.Tx, fee types.Coins, numOutputs uint64, blockHeight uint64) (minimumFee *big.Int, success bool) {
 	fee = fee.NoNil()
-	minimumFee = types.GetSendTxMinimumTransactionFeeTFuelWei(numAccountsAffected, blockHeight)
+	if blockHeight < common.HeightEnableFeeSchedule {
+		minimumFee = types.GetBatchSendTxMinimumTransactionFeeTFuelWei(numOutputs, blockHeight)
+	} else {
+		minimumFee = getScheduledMinimumTxFee(view, tx, numOutputs)
+	}
 	success = (fee.ThetaWei.Cmp(types.Zero) == 0 && fee.TFuelWei.Cmp(minimumFee) >= 0)
 
 	return minimumFee, success
 }
 
+// validateFeePayer validates the optional fee payer of a sponsored transaction. The fee payer
+// should differ from the senders, sign the transaction, and agree to pay up to maxFee TFuelWei.
+// It returns whether the transaction is sponsored.
+func validateFeePayer(view *state.StoreView, feePayers []types.TxInput, signBytes []byte, maxFee *big.Int,
+	senders []common.Address) (sponsored bool, res result.Result) {
+	if len(feePayers) == 0 {
+		return false, result.OK
+	}
+	if len(feePayers) > 1 {
+		return false, result.Error("At most one fee payer is allowed").WithErrorCode(result.CodeInvalidFeePayer)
+	}
+
+	feePayer := feePayers[0]
+	if res := feePayer.ValidateBasic(); res.IsError() {
+		return false, res
+	}
+	for _, sender := range senders {
+		if sender == feePayer.Address {
+			return false, result.Error("The fee payer %v cannot be a sender of the transaction",
+				feePayer.Address).WithErrorCode(result.CodeInvalidFeePayer)
+		}
+	}
+
+	coins := feePayer.Coins.NoNil()
+	if coins.ThetaWei.Cmp(types.Zero) != 0 || coins.TFuelWei.Cmp(maxFee) < 0 {
+		return false, result.Error("The fee payer agrees to pay up to %v, but the fee can reach %v TFuelWei",
+			coins, maxFee).WithErrorCode(result.CodeInvalidFeePayer)
+	}
+
+	feePayerAccount, res := getInput(view, feePayer)
+	if res.IsError() {
+		return false, result.Error("Failed to get the fee payer account: %v", res.Message)
+	}
+	if res := validateInputAdvanced(view, feePayerAccount, signBytes, feePayer); res.IsError() {
+		return false, res
+	}
+
+	return true, result.OK
+}
+
+// chargeFeePayer charges the transaction fee from the fee payer of a sponsored transaction
+func chargeFeePayer(view *state.StoreView, feePayer types.TxInput, fee types.Coins) result.Result {
+	feePayerAccount, res := getInput(view, feePayer)
+	if res.IsError() {
+		return result.Error("Failed to get the fee payer account: %v", res.Message)
+	}
+	if !chargeFee(feePayerAccount, fee) {
+		return result.Error("Failed to charge transaction fee from the fee payer %v", feePayer.Address)
+	}
+	feePayerAccount.Sequence++
+	view.SetAccount(feePayer.Address, feePayerAccount)
+	return result.OK
+}
+
 func chargeFee(account *types.Account, fee types.Coins) bool {
 	if !account.Balance.IsGTE(fee) {
 		return false
@@ -297,6 +402,16 @@ func getBlockHeight(ledgerState *state.LedgerState) uint64 {
 	return blockHeight
 }
 
+// getBlockTime returns the timestamp of the parent block, which is used as the
+// current time for the time-locked transactions so that the execution is deterministic
+func getBlockTime(ledgerState *state.LedgerState) uint64 {
+	parentBlock := ledgerState.ParentBlock()
+	if parentBlock == nil || parentBlock.Timestamp == nil {
+		return 0
+	}
+	return parentBlock.Timestamp.Uint64()
+}
+
 func getRegularTxGas(ledgerState *state.LedgerState) uint64 {
 	blockHeight := getBlockHeight(ledgerState)
 	if blockHeight < common.HeightJune2021FeeAdjustment {
@@ -1,18 +1,22 @@
 package execution
 
 import (
-	log "github.com/sirupsen/logrus"
+	"math/big"
 
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
 	st "github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/upgrade"
+	"github.com/thetatoken/theta/ledger/vm"
 	"github.com/thetatoken/theta/store/database"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "ledger"})
+var logger = util.GetLoggerForModule("ledger")
 
 //
 // TxExecutor defines the interface of the transaction executors
@@ -32,6 +36,7 @@ type Executor struct {
 	state     *st.LedgerState
 	consensus core.ConsensusEngine
 	valMgr    core.ValidatorManager
+	upgrades  *upgrade.Manager
 
 	coinbaseTxExec *CoinbaseTxExecutor
 	// slashTxExec          *SlashTxExecutor
@@ -44,18 +49,31 @@ type Executor struct {
 	depositStakeTxExec            *DepositStakeExecutor
 	withdrawStakeTxExec           *WithdrawStakeExecutor
 	stakeRewardDistributionTxExec *StakeRewardDistributionTxExecutor
+	multiSigSendTxExec            *MultiSigSendTxExecutor
+	vestingTransferTxExec         *VestingTransferTxExecutor
+	vestingClaimTxExec            *VestingClaimTxExecutor
+	batchSendTxExec               *BatchSendTxExecutor
+	splitRuleRenewalTxExec        *SplitRuleRenewalTxExecutor
+	tokenRegistryTxExec           *TokenRegistryTxExecutor
+	validatorKeyRotationTxExec    *ValidatorKeyRotationTxExecutor
+	ibcTxExec                     *IBCTxExecutor
+	bandwidthProofTxExec          *BandwidthProofTxExecutor
+	govProposalTxExec             *GovProposalTxExecutor
+	govVoteTxExec                 *GovVoteTxExecutor
 
 	skipSanityCheck bool
 }
 
 // NewExecutor creates a new instance of Executor
 func NewExecutor(db database.Database, chain *blockchain.Chain, state *st.LedgerState, consensus core.ConsensusEngine, valMgr core.ValidatorManager) *Executor {
+	upgrades := upgrade.NewManager()
 	executor := &Executor{
 		db:             db,
 		chain:          chain,
 		state:          state,
 		consensus:      consensus,
 		valMgr:         valMgr,
+		upgrades:       upgrades,
 		coinbaseTxExec: NewCoinbaseTxExecutor(db, chain, state, consensus, valMgr),
 		// slashTxExec:          NewSlashTxExecutor(consensus, valMgr),
 		sendTxExec:                    NewSendTxExecutor(state),
@@ -67,6 +85,17 @@ func NewExecutor(db database.Database, chain *blockchain.Chain, state *st.Ledger
 		depositStakeTxExec:            NewDepositStakeExecutor(state),
 		withdrawStakeTxExec:           NewWithdrawStakeExecutor(state),
 		stakeRewardDistributionTxExec: NewStakeRewardDistributionTxExecutor(state),
+		multiSigSendTxExec:            NewMultiSigSendTxExecutor(state),
+		vestingTransferTxExec:         NewVestingTransferTxExecutor(state),
+		vestingClaimTxExec:            NewVestingClaimTxExecutor(state),
+		batchSendTxExec:               NewBatchSendTxExecutor(state),
+		splitRuleRenewalTxExec:        NewSplitRuleRenewalTxExecutor(state),
+		tokenRegistryTxExec:           NewTokenRegistryTxExecutor(state),
+		validatorKeyRotationTxExec:    NewValidatorKeyRotationTxExecutor(state, consensus),
+		ibcTxExec:                     NewIBCTxExecutor(state),
+		bandwidthProofTxExec:          NewBandwidthProofTxExecutor(state),
+		govProposalTxExec:             NewGovProposalTxExecutor(state, upgrades),
+		govVoteTxExec:                 NewGovVoteTxExecutor(state),
 		skipSanityCheck:               false,
 	}
 
@@ -79,6 +108,30 @@ func (exec *Executor) SetSkipSanityCheck(skip bool) {
 	exec.skipSanityCheck = skip
 }
 
+// SetVMConfig sets the EVM configuration used to execute smart contract transactions,
+// e.g. to attach a tracer.
+func (exec *Executor) SetVMConfig(config vm.Config) {
+	exec.smartContractTxExec.vmConfig = config
+}
+
+// SetSkipTxReceipt sets whether to skip persisting the smart contract tx receipts.
+// Skip while re-executing committed transactions, e.g. for tracing.
+func (exec *Executor) SetSkipTxReceipt(skip bool) {
+	exec.smartContractTxExec.skipTxReceipt = skip
+}
+
+// SettleServicePayments settles the pending service payments whose dispute window has passed,
+// and returns the settlement events
+func (exec *Executor) SettleServicePayments(view *st.StoreView) []*types.Event {
+	return exec.servicePaymentTxExec.settlePendingPayments(view)
+}
+
+// TallyGovernanceProposals ends the vote on the governance proposals whose voting period is over,
+// applies the approved param changes, and returns the tally events
+func (exec *Executor) TallyGovernanceProposals(view *st.StoreView) []*types.Event {
+	return exec.govProposalTxExec.tallyProposals(view)
+}
+
 // ExecuteTx executes the given transaction
 func (exec *Executor) ExecuteTx(tx types.Tx) (common.Hash, result.Result) {
 	return exec.processTx(tx, core.DeliveredView)
@@ -94,6 +147,25 @@ func (exec *Executor) ScreenTx(tx types.Tx) (common.Hash, result.Result) {
 	return exec.processTx(tx, core.ScreenedView)
 }
 
+// ScreenTxWithView checks the validity of the given transaction against the given view
+func (exec *Executor) ScreenTxWithView(tx types.Tx, view *st.StoreView) (common.Hash, result.Result) {
+	return exec.processTxWithView(tx, view)
+}
+
+// SimulateTx executes the given transaction against the delivered view, and returns the would-be
+// receipt of the transaction. Unlike ExecuteTx, the receipt is built regardless of the block height.
+// It is meant for dry runs on a private ledger state which is never committed.
+func (exec *Executor) SimulateTx(tx types.Tx) (common.Hash, result.Result, *types.TxReceipt) {
+	view := exec.state.Delivered()
+	view.StartBalanceTracking()
+	txHash, res := exec.processTxWithView(tx, view)
+	balanceChanges := view.StopBalanceTracking()
+	if res.IsError() {
+		return txHash, res, nil
+	}
+	return txHash, res, exec.buildTxReceipt(tx, res, balanceChanges)
+}
+
 // GetTxInfo extracts tx information used by mempool to sort Txs.
 func (exec *Executor) GetTxInfo(tx types.Tx) (*core.TxInfo, result.Result) {
 	txExecutor := exec.getTxExecutor(tx)
@@ -102,12 +174,24 @@ func (exec *Executor) GetTxInfo(tx types.Tx) (*core.TxInfo, result.Result) {
 	}
 
 	txInfo := txExecutor.getTxInfo(tx)
+	switch tx.(type) {
+	case *types.SmartContractTx, *types.SmartContractTxV2:
+		// The actual fee depends on the gas used, ranking by the fee for the full gas limit would
+		// favor the transactions with inflated limits. Rank by the gas price instead, as the fee
+		// of a regular transaction at that price.
+		txInfo.Fee = big.NewInt(0)
+		if txInfo.EffectiveGasPrice != nil {
+			gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+			txInfo.Fee.Mul(txInfo.EffectiveGasPrice, gas)
+		}
+	default:
+		txInfo.Fee = types.GetMaxTxFee(tx).TFuelWei
+	}
 	return txInfo, result.OK
 }
 
 // processTx contains the main logic to process the transaction. If the tx is invalid, a TMSP error will be returned.
 func (exec *Executor) processTx(tx types.Tx, viewSel core.ViewSelector) (common.Hash, result.Result) {
-	chainID := exec.state.GetChainID()
 	var view *st.StoreView
 	switch viewSel {
 	case core.DeliveredView:
@@ -118,6 +202,75 @@ func (exec *Executor) processTx(tx types.Tx, viewSel core.ViewSelector) (common.
 		view = exec.state.Screened()
 	}
 
+	if viewSel == core.ScreenedView {
+		return exec.processTxWithView(tx, view)
+	}
+	return exec.ExecuteTxWithView(tx, view)
+}
+
+// ExecuteTxWithView executes the given transaction against the given view the way ExecuteTx does
+// against the delivered view, e.g. against a speculative copy of the delivered view.
+func (exec *Executor) ExecuteTxWithView(tx types.Tx, view *st.StoreView) (common.Hash, result.Result) {
+	if !exec.upgrades.IsActive(view, upgrade.TxReceiptRoot, view.Height()+1) {
+		return exec.processTxWithView(tx, view)
+	}
+
+	view.StartBalanceTracking()
+	txHash, res := exec.processTxWithView(tx, view)
+	balanceChanges := view.StopBalanceTracking()
+	if res.IsError() {
+		return txHash, res
+	}
+
+	// Attach the receipt, so that the ledger can commit it under the receipt root hash of the block.
+	// The info is copied since the executors may return the shared result.OK.
+	res = result.OKWith(res.Info)
+	res.Info["receipt"] = exec.buildTxReceipt(tx, res, balanceChanges)
+
+	return txHash, res
+}
+
+// buildTxReceipt assembles the canonical receipt of a successfully processed transaction
+func (exec *Executor) buildTxReceipt(tx types.Tx, res result.Result, balanceChanges []types.BalanceChange) *types.TxReceipt {
+	raw, err := types.TxToBytes(tx)
+	if err != nil {
+		// Should never happen
+		logger.Panic(err)
+	}
+
+	receipt := &types.TxReceipt{
+		TxHash:         crypto.Keccak256Hash(raw),
+		Status:         types.TxReceiptStatusSuccess,
+		Logs:           []*types.Log{},
+		BalanceChanges: balanceChanges,
+	}
+
+	switch tx.(type) {
+	case *types.CoinbaseTx, *types.SlashTx:
+		receipt.GasUsed = 0 // system transactions do not consume gas
+	case *types.SmartContractTx, *types.SmartContractTxV2:
+		// The info is not set if the transaction failed before reaching the EVM
+		if gasUsed, ok := res.Info["gasUsed"].(uint64); ok {
+			receipt.GasUsed = gasUsed
+		}
+		if evmRet, ok := res.Info["evmRet"].(common.Bytes); ok {
+			receipt.ReturnData = evmRet
+		}
+		if evmErr, _ := res.Info["evmErr"].(error); evmErr != nil {
+			receipt.Status = types.TxReceiptStatusFailed
+		}
+		if logs, _ := res.Info["logs"].([]*types.Log); logs != nil {
+			receipt.Logs = logs
+		}
+	default:
+		receipt.GasUsed = getRegularTxGas(exec.state)
+	}
+
+	return receipt
+}
+
+func (exec *Executor) processTxWithView(tx types.Tx, view *st.StoreView) (common.Hash, result.Result) {
+	chainID := exec.state.GetChainID()
 	sanityCheckResult := exec.sanityCheck(chainID, view, tx)
 	if sanityCheckResult.IsError() {
 		return common.Hash{}, sanityCheckResult
@@ -136,6 +289,19 @@ func (exec *Executor) sanityCheck(chainID string, view *st.StoreView, tx types.T
 		return result.Error("tx type not supported yet")
 	}
 
+	if res := exec.checkSignatures(chainID, view, tx); res.IsError() {
+		return res
+	}
+
+	if expirableTx, ok := tx.(types.ExpirableTx); ok {
+		blockHeight := view.Height() + 1
+		notAfterHeight := expirableTx.GetNotAfterHeight()
+		if notAfterHeight != 0 && blockHeight > notAfterHeight {
+			return result.Error("Transaction expired at height %v, current block height: %v",
+				notAfterHeight, blockHeight).WithErrorCode(result.CodeTxExpired)
+		}
+	}
+
 	var sanityCheckResult result.Result
 	txExecutor := exec.getTxExecutor(tx)
 	if txExecutor != nil {
@@ -171,13 +337,70 @@ func (exec *Executor) process(chainID string, view *st.StoreView, tx types.Tx) (
 func (exec *Executor) isTxTypeSupported(view *st.StoreView, tx types.Tx) bool {
 	blockHeight := view.Height() + 1
 
-	switch tx.(type) {
+	if expirableTx, ok := tx.(types.ExpirableTx); ok {
+		if expirableTx.GetNotAfterHeight() != 0 && !exec.upgrades.IsActive(view, upgrade.TxExpiry, blockHeight) {
+			return false
+		}
+	}
+
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		if len(tx.FeePayer) > 0 && !exec.upgrades.IsActive(view, upgrade.SponsoredFee, blockHeight) {
+			return false
+		}
 	case *types.SmartContractTx:
-		if blockHeight < common.HeightEnableSmartContract {
+		if !exec.upgrades.IsActive(view, upgrade.SmartContract, blockHeight) {
+			return false
+		}
+		if len(tx.FeePayer) > 0 && !exec.upgrades.IsActive(view, upgrade.SponsoredFee, blockHeight) {
+			return false
+		}
+	case *types.SmartContractTxV2:
+		if !exec.upgrades.IsActive(view, upgrade.WasmRuntime, blockHeight) {
 			return false
 		}
 	case *types.StakeRewardDistributionTx:
-		if blockHeight < common.HeightEnableTheta3 {
+		if !exec.upgrades.IsActive(view, upgrade.Theta3, blockHeight) {
+			return false
+		}
+	case *types.MultiSigSendTx:
+		if !exec.upgrades.IsActive(view, upgrade.MultiSigTx, blockHeight) {
+			return false
+		}
+	case *types.VestingTransferTx, *types.VestingClaimTx:
+		if !exec.upgrades.IsActive(view, upgrade.VestingTx, blockHeight) {
+			return false
+		}
+	case *types.BatchSendTx:
+		if !exec.upgrades.IsActive(view, upgrade.BatchSendTx, blockHeight) {
+			return false
+		}
+	case *types.SplitRuleRenewalTx:
+		if !exec.upgrades.IsActive(view, upgrade.SplitRuleRenewal, blockHeight) {
+			return false
+		}
+	case *types.ReserveFundTxV2:
+		if !exec.upgrades.IsActive(view, upgrade.ServicePaymentDispute, blockHeight) {
+			return false
+		}
+	case *types.TokenRegistryTx:
+		if !exec.upgrades.IsActive(view, upgrade.TokenRegistryTx, blockHeight) {
+			return false
+		}
+	case *types.ValidatorKeyRotationTx:
+		if !exec.upgrades.IsActive(view, upgrade.ValidatorKeyRotation, blockHeight) {
+			return false
+		}
+	case *types.IBCTx:
+		if !exec.upgrades.IsActive(view, upgrade.IBCTx, blockHeight) {
+			return false
+		}
+	case *types.BandwidthProofTx:
+		if !exec.upgrades.IsActive(view, upgrade.BandwidthProofTx, blockHeight) {
+			return false
+		}
+	case *types.GovProposalTx, *types.GovVoteTx:
+		if !exec.upgrades.IsActive(view, upgrade.Governance, blockHeight) {
 			return false
 		}
 	default:
@@ -187,6 +410,30 @@ func (exec *Executor) isTxTypeSupported(view *st.StoreView, tx types.Tx) bool {
 	return true
 }
 
+// checkSignatures rejects the signatures of the schemes not enabled yet at the block height, and the
+// non-canonical signatures once the CanonicalSignature upgrade is active, both at the mempool admission
+// and the block validation
+func (exec *Executor) checkSignatures(chainID string, view *st.StoreView, tx types.Tx) result.Result {
+	blockHeight := view.Height() + 1
+	checkScheme := !exec.upgrades.IsActive(view, upgrade.Ed25519Signature, blockHeight)
+	checkCanonical := exec.upgrades.IsActive(view, upgrade.CanonicalSignature, blockHeight)
+	if !checkScheme && !checkCanonical {
+		return result.OK
+	}
+	res := result.OK
+	forEachTxSignature(chainID, tx, func(sig *crypto.Signature, addr common.Address, signBytes []byte) {
+		if res.IsError() {
+			return
+		}
+		if checkScheme && sig.Scheme() != crypto.SignatureSchemeSecp256k1 {
+			res = result.Error("Ed25519 signatures are not supported yet").WithErrorCode(result.CodeInvalidSignature)
+		} else if checkCanonical && !sig.IsCanonical() {
+			res = result.Error("Non-canonical signature of %v", addr.Hex()).WithErrorCode(result.CodeNonCanonicalSignature)
+		}
+	})
+	return res
+}
+
 func (exec *Executor) getTxExecutor(tx types.Tx) TxExecutor {
 	var txExecutor TxExecutor
 	switch tx.(type) {
@@ -204,7 +451,7 @@ func (exec *Executor) getTxExecutor(tx types.Tx) TxExecutor {
 		txExecutor = exec.servicePaymentTxExec
 	case *types.SplitRuleTx:
 		txExecutor = exec.splitRuleTxExec
-	case *types.SmartContractTx:
+	case *types.SmartContractTx, *types.SmartContractTxV2:
 		txExecutor = exec.smartContractTxExec
 	case *types.DepositStakeTx:
 		txExecutor = exec.depositStakeTxExec
@@ -214,6 +461,30 @@ func (exec *Executor) getTxExecutor(tx types.Tx) TxExecutor {
 		txExecutor = exec.depositStakeTxExec
 	case *types.StakeRewardDistributionTx:
 		txExecutor = exec.stakeRewardDistributionTxExec
+	case *types.MultiSigSendTx:
+		txExecutor = exec.multiSigSendTxExec
+	case *types.VestingTransferTx:
+		txExecutor = exec.vestingTransferTxExec
+	case *types.VestingClaimTx:
+		txExecutor = exec.vestingClaimTxExec
+	case *types.BatchSendTx:
+		txExecutor = exec.batchSendTxExec
+	case *types.SplitRuleRenewalTx:
+		txExecutor = exec.splitRuleRenewalTxExec
+	case *types.ReserveFundTxV2:
+		txExecutor = exec.reserveFundTxExec
+	case *types.TokenRegistryTx:
+		txExecutor = exec.tokenRegistryTxExec
+	case *types.ValidatorKeyRotationTx:
+		txExecutor = exec.validatorKeyRotationTxExec
+	case *types.IBCTx:
+		txExecutor = exec.ibcTxExec
+	case *types.BandwidthProofTx:
+		txExecutor = exec.bandwidthProofTxExec
+	case *types.GovProposalTx:
+		txExecutor = exec.govProposalTxExec
+	case *types.GovVoteTx:
+		txExecutor = exec.govVoteTxExec
 	default:
 		txExecutor = nil
 	}
@@ -7,8 +7,12 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/bridge"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	st "github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
 )
 
@@ -134,12 +138,12 @@ func TestValidateInputsAdvanced(t *testing.T) {
 	signBytes := tx.SignBytes(et.chainID)
 
 	//test bad case, unsigned
-	totalCoins, res := validateInputsAdvanced(accMap, signBytes, tx.Inputs, 1)
+	totalCoins, res := validateInputsAdvanced(et.state().Delivered(), accMap, signBytes, tx.Inputs)
 	assert.True(res.IsError(), "validateInputsAdvanced: expected an error on an unsigned tx input")
 
 	//test good case sgined
 	et.signSendTx(tx, accIn1, accIn2, accIn3, et.accOut)
-	totalCoins, res = validateInputsAdvanced(accMap, signBytes, tx.Inputs, 1)
+	totalCoins, res = validateInputsAdvanced(et.state().Delivered(), accMap, signBytes, tx.Inputs)
 	assert.True(res.IsOK(), "validateInputsAdvanced: expected no error on good tx input. Error: %v", res.Message)
 
 	txTotalCoins := tx.Inputs[0].Coins.
@@ -161,25 +165,25 @@ func TestValidateInputAdvanced(t *testing.T) {
 	signBytes := tx.SignBytes(et.chainID)
 
 	//unsigned case
-	res := validateInputAdvanced(&et.accIn.Account, signBytes, tx.Inputs[0], 1)
+	res := validateInputAdvanced(et.state().Delivered(), &et.accIn.Account, signBytes, tx.Inputs[0])
 	assert.True(res.IsError(), "validateInputAdvanced: expected error on tx input without signature")
 
 	//good signed case
 	et.signSendTx(tx, et.accIn, et.accOut)
-	res = validateInputAdvanced(&et.accIn.Account, signBytes, tx.Inputs[0], 1)
+	res = validateInputAdvanced(et.state().Delivered(), &et.accIn.Account, signBytes, tx.Inputs[0])
 	assert.True(res.IsOK(), "validateInputAdvanced: expected no error on good tx input. Error: %v", res.Message)
 
 	//bad sequence case
 	et.accIn.Sequence = 1
 	et.signSendTx(tx, et.accIn, et.accOut)
-	res = validateInputAdvanced(&et.accIn.Account, signBytes, tx.Inputs[0], 1)
+	res = validateInputAdvanced(et.state().Delivered(), &et.accIn.Account, signBytes, tx.Inputs[0])
 	assert.Equal(result.CodeInvalidSequence, res.Code, "validateInputAdvanced: expected error on tx input with bad sequence")
 	et.accIn.Sequence = 0 //restore sequence
 
 	//bad balance case
 	et.accIn.Balance = types.NewCoins(2, 0)
 	et.signSendTx(tx, et.accIn, et.accOut)
-	res = validateInputAdvanced(&et.accIn.Account, signBytes, tx.Inputs[0], 1)
+	res = validateInputAdvanced(et.state().Delivered(), &et.accIn.Account, signBytes, tx.Inputs[0])
 	assert.Equal(result.CodeInsufficientFund, res.Code,
 		"validateInputAdvanced: expected error on tx input with insufficient funds %v", et.accIn.Sequence)
 }
@@ -281,6 +285,771 @@ func TestSendTx(t *testing.T) {
 		"ExecTx/good DeliverTx: unexpected change in output balance, got: %v, expected: %v", balOut, balOutExp)
 }
 
+func TestSendTxWithEd25519Signature(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	edKey, err := crypto.Ed25519PrivateKeyFromSeed(crypto.Keccak256([]byte("ed25519")))
+	assert.Nil(err)
+	edAcc := et.accIn
+	edAcc.Account.Address = edKey.PublicKey().Address()
+	et.acc2State(edAcc, et.accOut)
+
+	tx := types.MakeSendTx(1, et.accOut, edAcc)
+	tx.Inputs[0].Signature, err = edKey.Sign(tx.SignBytes(et.chainID))
+	assert.Nil(err)
+
+	res := et.executor.sendTxExec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.True(res.IsOK(), res.Message)
+
+	// The Ed25519 signatures are rejected before HeightEnableEd25519Signature
+	res = et.executor.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	// The signature needs to match the address of the input
+	tx = types.MakeSendTx(1, et.accOut, edAcc)
+	tx.Inputs[0].Signature, err = edKey.Sign(tx.SignBytes("other_chain"))
+	assert.Nil(err)
+	res = et.executor.sendTxExec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+}
+
+func TestChainIDReplayProtection(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	// viewAt returns a view whose next block is at the given height
+	viewAt := func(blockHeight uint64) *st.StoreView {
+		return st.NewStoreView(blockHeight-1, common.Hash{}, et.state().DB())
+	}
+
+	tx := types.MakeSendTx(1, et.accOut, et.accIn)
+	signBytes := tx.SignBytes(et.chainID)
+	legacySig := et.accIn.Sign(signBytes)
+	wrapperV2Sig := et.accIn.Sign(types.ChangeEthereumTxWrapper(signBytes, 2))
+	protectedSig := et.accIn.Sign(types.ReplayProtectedSignBytes(signBytes, types.NumericChainID(et.chainID)))
+	address := et.accIn.Account.Address
+
+	// Before the replay protection, the legacy sign bytes are accepted
+	view := viewAt(common.HeightEnableChainIDReplayProtection - 1)
+	assert.True(verifyTxSignature(view, legacySig, address, signBytes))
+	assert.True(verifyTxSignature(view, wrapperV2Sig, address, signBytes))
+	assert.False(verifyTxSignature(view, protectedSig, address, signBytes))
+
+	// From the replay protection on, only the sign bytes committing to the numeric chain ID are accepted
+	view = viewAt(common.HeightEnableChainIDReplayProtection)
+	assert.False(verifyTxSignature(view, legacySig, address, signBytes))
+	assert.False(verifyTxSignature(view, wrapperV2Sig, address, signBytes))
+	assert.True(verifyTxSignature(view, protectedSig, address, signBytes))
+
+	// The signature cannot be replayed on another network
+	otherSignBytes := tx.SignBytes("other_chain")
+	assert.False(verifyTxSignature(view, protectedSig, address, otherSignBytes))
+}
+
+func TestChainIDReplayProtectionWithGenesisNumericChainID(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	// Two networks with the same chain ID, whose genesis sets different numeric chain IDs
+	blockHeight := common.HeightEnableChainIDReplayProtection
+	network1 := st.NewStoreView(blockHeight-1, common.Hash{}, et.state().DB())
+	network1.SetNumericChainID(big.NewInt(1001))
+	network2 := st.NewStoreView(blockHeight-1, common.Hash{}, et.state().DB())
+	network2.SetNumericChainID(big.NewInt(1002))
+	assert.Equal(big.NewInt(1001), network1.GetNumericChainID())
+
+	tx := types.MakeSendTx(1, et.accOut, et.accIn)
+	signBytes := tx.SignBytes(et.chainID)
+	sign := func(numericChainID *big.Int) *types.SendTx {
+		signedTx := types.MakeSendTx(1, et.accOut, et.accIn)
+		signedTx.Inputs[0].Signature = et.accIn.Sign(types.ReplayProtectedSignBytes(signBytes, numericChainID))
+		return signedTx
+	}
+	tx1, tx2 := sign(big.NewInt(1001)), sign(big.NewInt(1002))
+
+	// Each network only accepts the transactions signed for its own numeric chain ID
+	res := validateInputAdvanced(network1, &et.accIn.Account, signBytes, tx1.Inputs[0])
+	assert.True(res.IsOK(), res.Message)
+	res = validateInputAdvanced(network2, &et.accIn.Account, signBytes, tx1.Inputs[0])
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+	res = validateInputAdvanced(network2, &et.accIn.Account, signBytes, tx2.Inputs[0])
+	assert.True(res.IsOK(), res.Message)
+	res = validateInputAdvanced(network1, &et.accIn.Account, signBytes, tx2.Inputs[0])
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	// Nor the transactions signed for the numeric chain ID derived from the chain ID
+	derivedSig := et.accIn.Sign(types.ReplayProtectedSignBytes(signBytes, types.NumericChainID(et.chainID)))
+	assert.False(verifyTxSignature(network1, derivedSig, et.accIn.Account.Address, signBytes))
+}
+
+func TestBatchSendTx(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	// The input and outputs need to be regular accounts, i.e. with the empty code hash
+	et.accIn.CodeHash = types.EmptyCodeHash
+	et.accOut.CodeHash = types.EmptyCodeHash
+	et.accIn.Balance = types.NewCoins(700000, 1e18) // covers the per output fee
+	accOut2 := types.MakeAccWithInitBalance("baz", types.NewCoins(0, 0))
+	et.acc2State(et.accIn, et.accOut)
+
+	blockHeight := et.state().Height() + 1
+	fee := types.Coins{
+		ThetaWei: big.NewInt(0),
+		TFuelWei: types.GetBatchSendTxMinimumTransactionFeeTFuelWei(2, blockHeight),
+	}
+	c1 := types.NewCoins(20000, 100)
+	c2 := types.NewCoins(50000, 300)
+	batchSendTx := &types.BatchSendTx{
+		Fee: fee,
+		Input: types.TxInput{
+			Address:  et.accIn.Address,
+			Coins:    c1.Plus(c2).Plus(fee),
+			Sequence: et.accIn.Sequence + 1,
+		},
+		Outputs: []types.TxOutput{
+			{Address: et.accOut.Address, Coins: c1},
+			{Address: accOut2.Address, Coins: c2},
+		},
+	}
+	batchSendTx.Input.Signature = et.accIn.Sign(batchSendTx.SignBytes(et.chainID))
+
+	exec := et.executor.batchSendTxExec
+	view := et.state().Delivered()
+	res := exec.sanityCheck(et.chainID, view, batchSendTx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = exec.process(et.chainID, view, batchSendTx)
+	assert.True(res.IsOK(), res.Message)
+
+	accIn := view.GetAccount(et.accIn.Address)
+	assert.Equal(et.accIn.Sequence+1, accIn.Sequence)
+	assert.True(et.accIn.Balance.Minus(batchSendTx.Input.Coins).IsEqual(accIn.Balance))
+	assert.True(et.accOut.Balance.Plus(c1).IsEqual(view.GetAccount(et.accOut.Address).Balance))
+	assert.True(c2.IsEqual(view.GetAccount(accOut2.Address).Balance))
+
+	// Replaying the same tx fails due to the sequence
+	res = exec.sanityCheck(et.chainID, view, batchSendTx)
+	assert.Equal(result.CodeInvalidSequence, res.Code)
+
+	// Duplicated outputs are not allowed
+	et.reset()
+	et.accIn.CodeHash = types.EmptyCodeHash
+	et.accOut.CodeHash = types.EmptyCodeHash
+	et.accIn.Balance = types.NewCoins(700000, 1e18)
+	et.acc2State(et.accIn, et.accOut)
+	batchSendTx.Outputs[1].Address = et.accOut.Address
+	batchSendTx.Input.Signature = et.accIn.Sign(batchSendTx.SignBytes(et.chainID))
+	res = et.executor.batchSendTxExec.sanityCheck(et.chainID, et.state().Delivered(), batchSendTx)
+	assert.True(res.IsError())
+
+	// The fee needs to cover all the outputs
+	batchSendTx.Outputs[1].Address = accOut2.Address
+	batchSendTx.Fee = types.Coins{
+		ThetaWei: big.NewInt(0),
+		TFuelWei: types.GetBatchSendTxMinimumTransactionFeeTFuelWei(1, blockHeight),
+	}
+	batchSendTx.Input.Coins = c1.Plus(c2).Plus(batchSendTx.Fee)
+	batchSendTx.Input.Signature = et.accIn.Sign(batchSendTx.SignBytes(et.chainID))
+	res = et.executor.batchSendTxExec.sanityCheck(et.chainID, et.state().Delivered(), batchSendTx)
+	assert.Equal(result.CodeInvalidFee, res.Code)
+}
+
+func TestSponsoredSendTx(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	// The user has no TFuel, its fee is paid by accIn
+	user := types.MakeAccWithInitBalance("sponsored_user", types.NewCoins(1000, 0))
+	user.CodeHash = types.EmptyCodeHash
+	et.accIn.CodeHash = types.EmptyCodeHash
+	et.accOut.CodeHash = types.EmptyCodeHash
+	et.acc2State(et.accIn, et.accOut, user)
+
+	blockHeight := et.state().Height() + 1
+	fee := types.Coins{
+		ThetaWei: big.NewInt(0),
+		TFuelWei: types.GetSendTxMinimumTransactionFeeTFuelWei(3, blockHeight),
+	}
+	coins := types.NewCoins(400, 0)
+	createSponsoredSendTx := func(maxFee types.Coins) *types.SendTx {
+		tx := &types.SendTx{
+			Fee: fee,
+			Inputs: []types.TxInput{{
+				Address:  user.Address,
+				Coins:    coins,
+				Sequence: user.Sequence + 1,
+			}},
+			Outputs: []types.TxOutput{{
+				Address: et.accOut.Address,
+				Coins:   coins,
+			}},
+			FeePayer: []types.TxInput{{
+				Address:  et.accIn.Address,
+				Coins:    maxFee,
+				Sequence: et.accIn.Sequence + 1,
+			}},
+		}
+		signBytes := tx.SignBytes(et.chainID)
+		tx.SetSignature(user.Address, user.Sign(signBytes))
+		tx.SetSignature(et.accIn.Address, et.accIn.Sign(signBytes))
+		return tx
+	}
+
+	exec := et.executor.sendTxExec
+
+	// The fee payer needs to agree to pay the fee
+	tx := createSponsoredSendTx(types.NewCoins(0, 1))
+	res := exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidFeePayer, res.Code)
+
+	// The fee payer needs to sign the tx
+	tx = createSponsoredSendTx(fee)
+	tx.FeePayer[0].Signature = user.Sign(tx.SignBytes(et.chainID))
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	// The fee is charged from the fee payer
+	tx = createSponsoredSendTx(fee)
+	view := et.state().Delivered()
+	res = exec.sanityCheck(et.chainID, view, tx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = exec.process(et.chainID, view, tx)
+	assert.True(res.IsOK(), res.Message)
+
+	userAcc := view.GetAccount(user.Address)
+	assert.Equal(user.Sequence+1, userAcc.Sequence)
+	assert.True(user.Balance.Minus(coins).IsEqual(userAcc.Balance))
+	assert.True(et.accOut.Balance.Plus(coins).IsEqual(view.GetAccount(et.accOut.Address).Balance))
+	feePayerAcc := view.GetAccount(et.accIn.Address)
+	assert.Equal(et.accIn.Sequence+1, feePayerAcc.Sequence)
+	assert.True(et.accIn.Balance.Minus(fee).IsEqual(feePayerAcc.Balance))
+
+	// The fee payer cannot be a sender
+	et.reset()
+	et.accIn.CodeHash = types.EmptyCodeHash
+	et.accOut.CodeHash = types.EmptyCodeHash
+	et.acc2State(et.accIn, et.accOut, user)
+	tx = createSponsoredSendTx(fee)
+	tx.FeePayer[0].Address = user.Address
+	signBytes := tx.SignBytes(et.chainID)
+	tx.Inputs[0].Signature = user.Sign(signBytes)
+	tx.FeePayer[0].Signature = user.Sign(signBytes)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidFeePayer, res.Code)
+}
+
+func TestTokenRegistryTx(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	et.accOut.CodeHash = types.EmptyCodeHash
+	contract := types.MakeAccWithInitBalance("token_contract", types.NewCoins(0, 0))
+	contract.CodeHash = common.BytesToHash([]byte("token_contract_code"))
+	et.acc2State(et.accIn, et.accOut, contract)
+
+	txFee := getMinimumTxFee()
+	createTokenRegistryTx := func(registrar types.PrivAccount, seq uint64, symbol string, contractAddress common.Address) *types.TokenRegistryTx {
+		tx := &types.TokenRegistryTx{
+			Fee: types.NewCoins(0, txFee),
+			Registrar: types.TxInput{
+				Address:  registrar.Address,
+				Sequence: seq,
+			},
+			Namespace:       "theta",
+			Symbol:          symbol,
+			Name:            "Test Token",
+			Decimals:        18,
+			ContractAddress: contractAddress,
+		}
+		tx.Registrar.Signature = registrar.Sign(tx.SignBytes(et.chainID))
+		return tx
+	}
+
+	exec := et.executor.tokenRegistryTxExec
+
+	// Only smart contracts can be registered
+	tx := createTokenRegistryTx(et.accIn, et.accIn.Sequence+1, "TKN", et.accOut.Address)
+	res := exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidTokenMetadata, res.Code)
+
+	tx = createTokenRegistryTx(et.accIn, et.accIn.Sequence+1, "tkn", contract.Address)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidTokenMetadata, res.Code)
+
+	// The first registration claims the namespace
+	tx = createTokenRegistryTx(et.accIn, et.accIn.Sequence+1, "TKN", contract.Address)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = exec.process(et.chainID, et.state().Delivered(), tx)
+	assert.True(res.IsOK(), res.Message)
+	et.state().Commit()
+
+	namespace := et.state().Delivered().GetTokenNamespace("theta")
+	assert.NotNil(namespace)
+	assert.Equal(et.accIn.Address, namespace.Owner)
+	token := et.state().Delivered().GetTokenInfo("theta", "TKN")
+	assert.NotNil(token)
+	assert.Equal(contract.Address, token.ContractAddress)
+	assert.Equal(uint8(18), token.Decimals)
+
+	// Other addresses cannot register tokens under the namespace
+	tx = createTokenRegistryTx(et.accOut, et.accOut.Sequence+1, "TKN2", contract.Address)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeUnauthorizedToUpdateTokenNamespace, res.Code)
+
+	// The namespace owner can register more tokens
+	tx = createTokenRegistryTx(et.accIn, et.accIn.Sequence+2, "TKN2", contract.Address)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = exec.process(et.chainID, et.state().Delivered(), tx)
+	assert.True(res.IsOK(), res.Message)
+	et.state().Commit()
+
+	assert.Equal(2, len(et.state().Delivered().GetTokenInfos("theta")))
+	assert.Equal(2, len(et.state().Delivered().GetTokenInfos("")))
+	assert.Equal(0, len(et.state().Delivered().GetTokenInfos("other")))
+}
+
+func TestValidatorKeyRotationTx(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	et.acc2State(et.accIn, et.accOut)
+	vcp := &core.ValidatorCandidatePool{}
+	assert.Nil(vcp.DepositStake(et.accIn.Address, et.accIn.Address, core.MinValidatorStakeDeposit))
+	et.state().Delivered().UpdateValidatorCandidatePool(vcp)
+	et.state().Commit()
+
+	signingKey := types.MakeAcc("validator_signing_key")
+	txFee := getMinimumTxFee()
+	createValidatorKeyRotationTx := func(validator types.PrivAccount, seq uint64, activationEpoch uint64) *types.ValidatorKeyRotationTx {
+		tx := &types.ValidatorKeyRotationTx{
+			Fee: types.NewCoins(0, txFee),
+			Validator: types.TxInput{
+				Address:  validator.Address,
+				Sequence: seq,
+			},
+			SigningAddress:  signingKey.Address,
+			ActivationEpoch: activationEpoch,
+		}
+		signBytes := tx.SignBytes(et.chainID)
+		tx.Validator.Signature = validator.Sign(signBytes)
+		tx.SigningKeySignature = signingKey.Sign(signBytes)
+		return tx
+	}
+
+	exec := et.executor.validatorKeyRotationTxExec
+	epoch := et.executor.consensus.GetEpoch()
+	activationEpoch := epoch + types.MinimumValidatorKeyActivationDelay
+
+	// Only the validator candidates can rotate the signing key
+	tx := createValidatorKeyRotationTx(et.accOut, et.accOut.Sequence+1, activationEpoch)
+	res := exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeNotAValidatorCandidate, res.Code)
+
+	// The new key needs to activate at least MinimumValidatorKeyActivationDelay epochs later
+	tx = createValidatorKeyRotationTx(et.accIn, et.accIn.Sequence+1, activationEpoch-1)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidKeyActivationEpoch, res.Code)
+
+	// The possession of the new key needs to be proven
+	tx = createValidatorKeyRotationTx(et.accIn, et.accIn.Sequence+1, activationEpoch)
+	tx.SigningKeySignature = et.accIn.Sign(tx.SignBytes(et.chainID))
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	tx = createValidatorKeyRotationTx(et.accIn, et.accIn.Sequence+1, activationEpoch)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = exec.process(et.chainID, et.state().Delivered(), tx)
+	assert.True(res.IsOK(), res.Message)
+	et.state().Commit()
+
+	view := et.state().Delivered()
+	assert.Equal(et.accIn.Address, view.GetValidatorSigningAddress(et.accIn.Address, activationEpoch-1))
+	assert.Equal(signingKey.Address, view.GetValidatorSigningAddress(et.accIn.Address, activationEpoch))
+	assert.Equal(et.accOut.Address, view.GetValidatorSigningAddress(et.accOut.Address, activationEpoch))
+}
+
+func TestIBCTx(t *testing.T) {
+	assert := assert.New(t)
+	chainA, chainB := NewExecTest(), NewExecTest()
+	chainA.acc2State(chainA.accIn, chainA.accOut)
+	chainB.acc2State(chainB.accIn, chainB.accOut)
+
+	txFee := getMinimumTxFee()
+	execIBCMsg := func(et *execTest, relayer types.PrivAccount, msg types.IBCMsg) result.Result {
+		view := et.state().Delivered()
+		tx := &types.IBCTx{
+			Fee: types.NewCoins(0, txFee),
+			Relayer: types.TxInput{
+				Address:  relayer.Address,
+				Sequence: view.GetAccount(relayer.Address).Sequence + 1,
+			},
+		}
+		assert.Nil(tx.SetMsg(msg))
+		tx.Relayer.Signature = relayer.Sign(tx.SignBytes(et.chainID))
+
+		exec := et.executor.ibcTxExec
+		res := exec.sanityCheck(et.chainID, view, tx)
+		if res.IsError() {
+			return res
+		}
+		_, res = exec.process(et.chainID, view, tx)
+		et.state().Commit()
+		return res
+	}
+
+	// prove lets the client track the latest counterparty state, as a client update would, and
+	// proves the counterparty value at the key against it
+	prove := func(et *execTest, clientID string, counterparty *execTest, key common.Bytes) types.IBCProof {
+		cpView := counterparty.state().Delivered()
+		et.state().Delivered().SetIBCConsensusState(clientID, &types.IBCConsensusState{
+			Height:    cpView.Height(),
+			StateHash: cpView.Hash(),
+			Timestamp: big.NewInt(0),
+		})
+		et.state().Commit()
+
+		proof := bridge.MerkleProof{}
+		assert.Nil(cpView.Prove(key, &proof))
+		return types.IBCProof{Height: cpView.Height(), Nodes: proof}
+	}
+
+	// Clients
+	createClient := func(et *execTest, counterparty *execTest) {
+		cpView := counterparty.state().Delivered()
+		res := execIBCMsg(et, et.accIn, &types.MsgCreateClient{
+			CounterpartyChainID: counterparty.chainID,
+			Validators:          []core.Validator{{Address: counterparty.accProposer.Address, Stake: big.NewInt(1)}},
+			Height:              cpView.Height(),
+			StateHash:           cpView.Hash(),
+			Timestamp:           big.NewInt(0),
+		})
+		assert.True(res.IsOK(), res.Message)
+	}
+	createClient(chainA, chainB)
+	createClient(chainB, chainA)
+	clientID := "client-0"
+	assert.NotNil(chainA.state().Delivered().GetIBCClient(clientID))
+
+	// Connection handshake
+	connID := "connection-0"
+	res := execIBCMsg(chainA, chainA.accIn, &types.MsgConnectionOpenInit{ClientID: clientID, CounterpartyClientID: clientID})
+	assert.True(res.IsOK(), res.Message)
+
+	proof := prove(chainB, clientID, chainA, st.IBCConnectionKey(connID))
+	res = execIBCMsg(chainB, chainB.accIn, &types.MsgConnectionOpenTry{
+		ClientID: clientID, CounterpartyClientID: clientID, CounterpartyConnectionID: "connection-1", Proof: proof})
+	assert.Equal(result.CodeIBCInvalidProof, res.Code)
+	res = execIBCMsg(chainB, chainB.accIn, &types.MsgConnectionOpenTry{
+		ClientID: clientID, CounterpartyClientID: clientID, CounterpartyConnectionID: connID, Proof: proof})
+	assert.True(res.IsOK(), res.Message)
+
+	proof = prove(chainA, clientID, chainB, st.IBCConnectionKey(connID))
+	res = execIBCMsg(chainA, chainA.accIn, &types.MsgConnectionOpenAck{ConnectionID: connID, CounterpartyConnectionID: connID, Proof: proof})
+	assert.True(res.IsOK(), res.Message)
+
+	proof = prove(chainB, clientID, chainA, st.IBCConnectionKey(connID))
+	res = execIBCMsg(chainB, chainB.accIn, &types.MsgConnectionOpenConfirm{ConnectionID: connID, Proof: proof})
+	assert.True(res.IsOK(), res.Message)
+	assert.Equal(types.IBCStateOpen, chainA.state().Delivered().GetIBCConnection(connID).State)
+	assert.Equal(types.IBCStateOpen, chainB.state().Delivered().GetIBCConnection(connID).State)
+
+	// Channel handshake
+	portID, channelID := "transfer", "channel-0"
+	res = execIBCMsg(chainA, chainA.accIn, &types.MsgChannelOpenInit{PortID: portID, ConnectionID: connID, CounterpartyPortID: portID})
+	assert.True(res.IsOK(), res.Message)
+
+	proof = prove(chainB, clientID, chainA, st.IBCChannelKey(portID, channelID))
+	res = execIBCMsg(chainB, chainB.accIn, &types.MsgChannelOpenTry{
+		PortID: portID, ConnectionID: connID, CounterpartyPortID: portID, CounterpartyChannelID: channelID, Proof: proof})
+	assert.True(res.IsOK(), res.Message)
+
+	proof = prove(chainA, clientID, chainB, st.IBCChannelKey(portID, channelID))
+	res = execIBCMsg(chainA, chainA.accIn, &types.MsgChannelOpenAck{PortID: portID, ChannelID: channelID, CounterpartyChannelID: channelID, Proof: proof})
+	assert.True(res.IsOK(), res.Message)
+
+	proof = prove(chainB, clientID, chainA, st.IBCChannelKey(portID, channelID))
+	res = execIBCMsg(chainB, chainB.accIn, &types.MsgChannelOpenConfirm{PortID: portID, ChannelID: channelID, Proof: proof})
+	assert.True(res.IsOK(), res.Message)
+
+	// Only the port owner can send packets
+	res = execIBCMsg(chainA, chainA.accOut, &types.MsgSendPacket{SourcePort: portID, SourceChannel: channelID, Data: common.Bytes("hello")})
+	assert.Equal(result.CodeIBCUnauthorized, res.Code)
+
+	// Send, receive and acknowledge a packet
+	res = execIBCMsg(chainA, chainA.accIn, &types.MsgSendPacket{SourcePort: portID, SourceChannel: channelID, Data: common.Bytes("hello")})
+	assert.True(res.IsOK(), res.Message)
+	packets := chainA.state().Delivered().GetIBCPackets(portID, channelID)
+	assert.Equal(1, len(packets))
+	packet := *packets[0]
+	assert.Equal(uint64(1), packet.Sequence)
+
+	proof = prove(chainB, clientID, chainA, st.IBCPacketCommitmentKey(portID, channelID, packet.Sequence))
+	forged := packet
+	forged.Data = common.Bytes("forged")
+	res = execIBCMsg(chainB, chainB.accIn, &types.MsgRecvPacket{Packet: forged, Proof: proof})
+	assert.Equal(result.CodeIBCInvalidProof, res.Code)
+	res = execIBCMsg(chainB, chainB.accIn, &types.MsgRecvPacket{Packet: packet, Proof: proof})
+	assert.True(res.IsOK(), res.Message)
+	res = execIBCMsg(chainB, chainB.accIn, &types.MsgRecvPacket{Packet: packet, Proof: proof})
+	assert.Equal(result.CodeIBCInvalidState, res.Code)
+
+	proof = prove(chainA, clientID, chainB, st.IBCPacketAckKey(portID, channelID, packet.Sequence))
+	res = execIBCMsg(chainA, chainA.accIn, &types.MsgAcknowledgePacket{Packet: packet, Acknowledgement: types.IBCAcknowledgementSuccess, Proof: proof})
+	assert.True(res.IsOK(), res.Message)
+	assert.Equal(0, len(chainA.state().Delivered().GetIBCPackets(portID, channelID)))
+
+	// Time out a packet
+	timeoutHeight := chainB.state().Delivered().Height() + 1
+	res = execIBCMsg(chainA, chainA.accIn, &types.MsgSendPacket{SourcePort: portID, SourceChannel: channelID, Data: common.Bytes("late"), TimeoutHeight: timeoutHeight})
+	assert.True(res.IsOK(), res.Message)
+	packet = *chainA.state().Delivered().GetIBCPackets(portID, channelID)[0]
+
+	proof = prove(chainB, clientID, chainA, st.IBCPacketCommitmentKey(portID, channelID, packet.Sequence))
+	res = execIBCMsg(chainB, chainB.accIn, &types.MsgRecvPacket{Packet: packet, Proof: proof})
+	assert.Equal(result.CodeIBCPacketTimeout, res.Code)
+
+	proof = prove(chainA, clientID, chainB, st.IBCPacketReceiptKey(portID, channelID, packet.Sequence))
+	assert.True(proof.Height >= timeoutHeight)
+	res = execIBCMsg(chainA, chainA.accIn, &types.MsgTimeoutPacket{Packet: packet, Proof: proof})
+	assert.True(res.IsOK(), res.Message)
+	assert.Equal(0, len(chainA.state().Delivered().GetIBCPackets(portID, channelID)))
+}
+
+func TestBandwidthProofTx(t *testing.T) {
+	assert := assert.New(t)
+	et, resourceID, alice, bob, carol, _, bobInitBalance, _ := setupForServicePayment(assert)
+	et.state().Commit()
+
+	txFee := getMinimumTxFee()
+	createBandwidthProofTx := func(edgeNode, pool types.PrivAccount, seq, nonce, epoch, relayedBytes uint64,
+		reward int64, resourceID string) *types.BandwidthProofTx {
+		tx := &types.BandwidthProofTx{
+			Fee: types.NewCoins(0, txFee),
+			EdgeNode: types.TxInput{
+				Address:  edgeNode.Address,
+				Sequence: seq,
+			},
+			Pool: types.TxInput{
+				Address: alice.Address,
+				Coins:   types.Coins{TFuelWei: big.NewInt(reward), ThetaWei: big.NewInt(0)},
+			},
+			ReserveSequence: 1,
+			ResourceID:      resourceID,
+			Epoch:           epoch,
+			RelayedBytes:    relayedBytes,
+			Nonce:           nonce,
+		}
+		tx.Pool.Signature = pool.Sign(tx.PoolSignBytes(et.chainID))
+		tx.EdgeNode.Signature = edgeNode.Sign(tx.SignBytes(et.chainID))
+		return tx
+	}
+
+	exec := et.executor.bandwidthProofTxExec
+	epoch := types.BandwidthRewardEpoch(et.state().Delivered().Height() + 1)
+	reward := int64(10 * txFee)
+
+	// The proof needs to be signed by the pool
+	tx := createBandwidthProofTx(bob, carol, 1, 1, epoch, 1e6, reward, resourceID)
+	res := exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidSignature, res.Code)
+
+	// The reserved fund needs to be reserved for the resource
+	tx = createBandwidthProofTx(bob, alice, 1, 1, epoch, 1e6, reward, "rid002")
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeChargeReservedFundFailed, res.Code)
+
+	// Only the proofs of the current and the previous epochs can be claimed
+	tx = createBandwidthProofTx(bob, alice, 1, 1, epoch+1, 1e6, reward, resourceID)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidBandwidthProof, res.Code)
+
+	tx = createBandwidthProofTx(bob, alice, 1, 1, epoch, 1e6, reward, resourceID)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = exec.process(et.chainID, et.state().Delivered(), tx)
+	assert.True(res.IsOK(), res.Message)
+	et.state().Commit()
+
+	view := et.state().Delivered()
+	assert.True(bobInitBalance.Plus(types.NewCoins(0, reward-txFee)).IsEqual(view.GetAccount(bob.Address).Balance))
+	assert.True(types.NewCoins(0, reward).IsEqual(view.GetAccount(alice.Address).ReservedFunds[0].UsedFund))
+	record := view.GetBandwidthRewardRecord(alice.Address, bob.Address)
+	assert.NotNil(record)
+	assert.Equal(uint64(1), record.Nonce)
+	assert.Equal(uint64(1e6), record.EpochRelayedBytes)
+
+	// A proof can only be claimed once
+	tx = createBandwidthProofTx(bob, alice, 2, 1, epoch, 1e6, reward, resourceID)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeBandwidthProofReplayed, res.Code)
+
+	// The bandwidth claimed per epoch is capped
+	tx = createBandwidthProofTx(bob, alice, 2, 2, epoch, types.MaxRelayedBytesPerEpoch, reward, resourceID)
+	res = exec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeBandwidthRewardExceedsCap, res.Code)
+
+	// The caps start over at the next epoch
+	epochReward, epochRelayedBytes := record.EpochTotals(epoch + 1)
+	assert.Equal(0, epochReward.Sign())
+	assert.Equal(uint64(0), epochRelayedBytes)
+}
+
+func TestGovernance(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	txFee := getMinimumTxFee()
+	deposit := new(big.Int).SetUint64(types.MinGovProposalDepositTFuelWei)
+	aliceInitBalance := types.Coins{ThetaWei: big.NewInt(0), TFuelWei: new(big.Int).Mul(deposit, big.NewInt(10))}
+	alice := types.MakeAccWithInitBalance("User Alice", aliceInitBalance)
+	bob := types.MakeAccWithInitBalance("User Bob", types.NewCoins(0, 10*txFee))
+	carol := types.MakeAccWithInitBalance("User Carol", types.NewCoins(0, 10*txFee))
+	et.acc2State(alice, bob, carol, et.accOut)
+
+	// Bob, Carol and accIn stake 3:1:1 to the validator
+	stake := core.MinValidatorStakeDeposit
+	vcp := &core.ValidatorCandidatePool{}
+	holder := et.accProposer.Address
+	assert.Nil(vcp.DepositStake(bob.Address, holder, new(big.Int).Mul(stake, big.NewInt(3))))
+	assert.Nil(vcp.DepositStake(carol.Address, holder, stake))
+	assert.Nil(vcp.DepositStake(et.accIn.Address, holder, stake))
+	et.state().Delivered().UpdateValidatorCandidatePool(vcp)
+	et.state().Commit()
+
+	createGovProposalTx := func(seq uint64, proposalType types.GovProposalType, paramChanges []types.GovParamChange,
+		deposit *big.Int) *types.GovProposalTx {
+		tx := &types.GovProposalTx{
+			Fee: types.NewCoins(0, txFee),
+			Proposer: types.TxInput{
+				Address:  alice.Address,
+				Coins:    types.Coins{ThetaWei: big.NewInt(0), TFuelWei: deposit},
+				Sequence: seq,
+			},
+			ProposalType: proposalType,
+			Title:        "Proposal",
+			ParamChanges: paramChanges,
+		}
+		tx.Proposer.Signature = alice.Sign(tx.SignBytes(et.chainID))
+		return tx
+	}
+	createGovVoteTx := func(voter types.PrivAccount, seq, proposalID uint64, option types.GovVoteOption) *types.GovVoteTx {
+		tx := &types.GovVoteTx{
+			Fee: types.NewCoins(0, txFee),
+			Voter: types.TxInput{
+				Address:  voter.Address,
+				Sequence: seq,
+			},
+			ProposalID: proposalID,
+			Option:     option,
+		}
+		tx.Voter.Signature = voter.Sign(tx.SignBytes(et.chainID))
+		return tx
+	}
+
+	proposalExec := et.executor.govProposalTxExec
+	voteExec := et.executor.govVoteTxExec
+
+	feeSchedule := types.DefaultFeeSchedule()
+	feeSchedule.DefaultFeeTFuelWei = new(big.Int).Mul(feeSchedule.DefaultFeeTFuelWei, big.NewInt(2))
+	feeScheduleChange, err := types.NewGovParamChange(types.GovParamFeeSchedule, feeSchedule)
+	assert.Nil(err)
+
+	// The deposit needs to be at least MinGovProposalDepositTFuelWei
+	tx := createGovProposalTx(1, types.GovProposalTypeParamChange, []types.GovParamChange{feeScheduleChange},
+		new(big.Int).Sub(deposit, big.NewInt(1)))
+	res := proposalExec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInsufficientGovDeposit, res.Code)
+
+	// A text proposal cannot change the chain params
+	tx = createGovProposalTx(1, types.GovProposalTypeText, []types.GovParamChange{feeScheduleChange}, deposit)
+	res = proposalExec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidGovProposal, res.Code)
+
+	// The upgrades cannot be scheduled before the end of the vote
+	upgradeScheduleChange, err := types.NewGovParamChange(types.GovParamUpgradeSchedule, &types.UpgradeSchedule{
+		Upgrades: []types.ScheduledUpgrade{{Name: "governance", Height: et.state().Height() + 10}},
+	})
+	assert.Nil(err)
+	tx = createGovProposalTx(1, types.GovProposalTypeParamChange, []types.GovParamChange{upgradeScheduleChange}, deposit)
+	res = proposalExec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+	assert.Equal(result.CodeInvalidGovProposal, res.Code)
+
+	// Proposal 1 changes the fee schedule, proposal 2 is a text proposal
+	for i, tx := range []*types.GovProposalTx{
+		createGovProposalTx(1, types.GovProposalTypeParamChange, []types.GovParamChange{feeScheduleChange}, deposit),
+		createGovProposalTx(2, types.GovProposalTypeText, nil, deposit),
+	} {
+		res = proposalExec.sanityCheck(et.chainID, et.state().Delivered(), tx)
+		assert.True(res.IsOK(), res.Message)
+		_, res = proposalExec.process(et.chainID, et.state().Delivered(), tx)
+		assert.True(res.IsOK(), res.Message)
+
+		proposal := et.state().Delivered().GetGovProposal(uint64(i + 1))
+		assert.NotNil(proposal)
+		assert.Equal(types.GovProposalStatusVoting, proposal.Status)
+	}
+	et.state().Commit()
+	assert.Equal(2, len(et.state().Delivered().GetActiveGovProposals()))
+
+	// Only the stakers can vote
+	voteTx := createGovVoteTx(et.accOut, 1, 1, types.GovVoteOptionYes)
+	res = voteExec.sanityCheck(et.chainID, et.state().Delivered(), voteTx)
+	assert.Equal(result.CodeInvalidGovVote, res.Code)
+
+	voteTx = createGovVoteTx(bob, 1, 3, types.GovVoteOptionYes)
+	res = voteExec.sanityCheck(et.chainID, et.state().Delivered(), voteTx)
+	assert.Equal(result.CodeGovProposalNotFound, res.Code)
+
+	// Bob changes his vote from no to yes, Carol votes no, 4/5 of the stake votes and 3/4 of it approves
+	for _, voteTx := range []*types.GovVoteTx{
+		createGovVoteTx(bob, 1, 1, types.GovVoteOptionNo),
+		createGovVoteTx(bob, 2, 1, types.GovVoteOptionYes),
+		createGovVoteTx(carol, 1, 1, types.GovVoteOptionNo),
+	} {
+		res = voteExec.sanityCheck(et.chainID, et.state().Delivered(), voteTx)
+		assert.True(res.IsOK(), res.Message)
+		_, res = voteExec.process(et.chainID, et.state().Delivered(), voteTx)
+		assert.True(res.IsOK(), res.Message)
+	}
+	et.state().Commit()
+	assert.Equal(2, len(et.state().Delivered().GetGovVotes(1)))
+	assert.Equal(types.GovVoteOptionYes, et.state().Delivered().GetGovVote(1, bob.Address).Option)
+
+	// The proposals are tallied at the end of the voting period
+	proposal := et.state().Delivered().GetGovProposal(1)
+	et.fastforwardTo(proposal.VotingEndHeight - 2)
+	assert.Equal(0, len(et.executor.TallyGovernanceProposals(et.state().Delivered())))
+	et.fastforwardTo(proposal.VotingEndHeight - 1)
+	events := et.executor.TallyGovernanceProposals(et.state().Delivered())
+	assert.Equal(2, len(events))
+	et.state().Commit()
+
+	view := et.state().Delivered()
+	proposal = view.GetGovProposal(1)
+	assert.Equal(types.GovProposalStatusPassed, proposal.Status)
+	assert.Equal(0, new(big.Int).Mul(stake, big.NewInt(3)).Cmp(proposal.YesStake))
+	assert.Equal(0, stake.Cmp(proposal.NoStake))
+	assert.Equal(0, new(big.Int).Mul(stake, big.NewInt(5)).Cmp(proposal.TotalStake))
+	assert.Equal(0, feeSchedule.DefaultFeeTFuelWei.Cmp(view.GetFeeSchedule().DefaultFeeTFuelWei))
+	status, _ := events[0].GetAttribute("status")
+	assert.Equal("passed", status)
+
+	// Nobody voted on proposal 2, its deposit is burned
+	assert.Equal(types.GovProposalStatusRejected, view.GetGovProposal(2).Status)
+	assert.Equal(0, len(view.GetActiveGovProposals()))
+	expectedBalance := aliceInitBalance.Minus(types.NewCoins(0, 2*txFee)).Minus(types.Coins{ThetaWei: big.NewInt(0), TFuelWei: deposit})
+	assert.True(expectedBalance.IsEqual(view.GetAccount(alice.Address).Balance))
+
+	// The vote is closed
+	voteTx = createGovVoteTx(carol, 2, 1, types.GovVoteOptionYes)
+	res = voteExec.sanityCheck(et.chainID, view, voteTx)
+	assert.Equal(result.CodeGovVotingClosed, res.Code)
+}
+
 func TestSendDuplicatedInputOutput(t *testing.T) {
 	assert := assert.New(t)
 	et := NewExecTest()
@@ -914,6 +1683,86 @@ func TestServicePaymentTxExpiration(t *testing.T) {
 // 	log.Infof("Proposer final balance: %v", retrievedProposerAccount.Balance)
 // }
 
+func TestServicePaymentTxDisputeWindow(t *testing.T) {
+	assert := assert.New(t)
+	et, _, alice, bob, _, _, bobInitBalance, _ := setupForServicePayment(assert)
+
+	txFee := getMinimumTxFee()
+	resourceID := "rid_dispute"
+	disputeWindow := uint64(10)
+	reserveFundTx := &types.ReserveFundTxV2{
+		Fee: types.NewCoins(0, txFee),
+		Source: types.TxInput{
+			Address:  alice.Address,
+			Coins:    types.Coins{TFuelWei: big.NewInt(1000 * txFee), ThetaWei: big.NewInt(0)},
+			Sequence: 2,
+		},
+		Collateral:    types.Coins{TFuelWei: big.NewInt(1001 * txFee), ThetaWei: big.NewInt(0)},
+		ResourceIDs:   []string{resourceID},
+		Duration:      1000,
+		DisputeWindow: disputeWindow,
+	}
+	reserveFundTx.Source.Signature = alice.Sign(reserveFundTx.SignBytes(et.chainID))
+	res := et.executor.getTxExecutor(reserveFundTx).sanityCheck(et.chainID, et.state().Delivered(), reserveFundTx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = et.executor.getTxExecutor(reserveFundTx).process(et.chainID, et.state().Delivered(), reserveFundTx)
+	assert.True(res.IsOK(), res.Message)
+	et.state().Commit()
+	assert.Equal(disputeWindow, et.state().Delivered().GetReservedFundDisputeWindow(alice.Address, 2))
+
+	// The payment is pending, only the fee is charged
+	payAmount1 := int64(80 * txFee)
+	servicePaymentTx1 := createServicePaymentTx(et.chainID, &alice, &bob, payAmount1, 1, 1, 1, 2, resourceID)
+	res = et.executor.getTxExecutor(servicePaymentTx1).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx1)
+	assert.True(res.IsOK(), res.Message)
+	_, res = et.executor.getTxExecutor(servicePaymentTx1).process(et.chainID, et.state().Delivered(), servicePaymentTx1)
+	assert.True(res.IsOK(), res.Message)
+	et.state().Commit()
+
+	pending := et.state().Delivered().GetPendingServicePayment(alice.Address, 2, bob.Address)
+	assert.NotNil(pending)
+	settlementHeight := pending.SettlementHeight
+	retrievedBobAcc := et.state().Delivered().GetAccount(bob.Address)
+	assert.Equal(bobInitBalance.Minus(types.NewCoins(0, txFee)), retrievedBobAcc.Balance)
+
+	// A payment with the same or lower payment sequence cannot replace the pending one
+	staleTx := createServicePaymentTx(et.chainID, &alice, &bob, 10*txFee, 1, 1, 1, 2, resourceID)
+	res = et.executor.getTxExecutor(staleTx).sanityCheck(et.chainID, et.state().Delivered(), staleTx)
+	assert.Equal(result.CodeCheckTransferReservedFundFailed, res.Code)
+
+	// A later payment replaces the pending one without extending the dispute window
+	payAmount2 := int64(120 * txFee)
+	servicePaymentTx2 := createServicePaymentTx(et.chainID, &alice, &bob, payAmount2, 1, 2, 2, 2, resourceID)
+	res = et.executor.getTxExecutor(servicePaymentTx2).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx2)
+	assert.True(res.IsOK(), res.Message)
+	_, res = et.executor.getTxExecutor(servicePaymentTx2).process(et.chainID, et.state().Delivered(), servicePaymentTx2)
+	assert.True(res.IsOK(), res.Message)
+	et.state().Commit()
+
+	pending = et.state().Delivered().GetPendingServicePayment(alice.Address, 2, bob.Address)
+	assert.Equal(uint64(2), pending.Payment.PaymentSequence)
+	assert.Equal(settlementHeight, pending.SettlementHeight)
+
+	// Nothing to settle before the dispute window passes
+	assert.Equal(0, len(et.executor.SettleServicePayments(et.state().Delivered())))
+
+	et.fastforwardTo(settlementHeight)
+	events := et.executor.SettleServicePayments(et.state().Delivered())
+	et.state().Commit()
+	assert.Equal(1, len(events))
+	assert.Equal(types.EventTypeServicePaymentSettled, events[0].Type)
+	value, ok := events[0].GetAttribute("payment_sequence")
+	assert.True(ok)
+	assert.Equal("2", value)
+
+	assert.Nil(et.state().Delivered().GetPendingServicePayment(alice.Address, 2, bob.Address))
+	retrievedBobAcc = et.state().Delivered().GetAccount(bob.Address)
+	assert.Equal(bobInitBalance.Plus(types.NewCoins(0, payAmount2-2*txFee)), retrievedBobAcc.Balance)
+	retrievedAliceAcc := et.state().Delivered().GetAccount(alice.Address)
+	assert.Equal(2, len(retrievedAliceAcc.ReservedFunds))
+	assert.Equal(types.NewCoins(0, payAmount2), retrievedAliceAcc.ReservedFunds[1].UsedFund)
+}
+
 func TestSplitRuleTxNormalExecution(t *testing.T) {
 	assert := assert.New(t)
 	et, resourceID, alice, bob, carol, _, bobInitBalance, carolInitBalance := setupForServicePayment(assert)
@@ -2182,6 +3031,85 @@ func TestSplitRuleExpiration(t *testing.T) {
 	assert.True(et.state().Delivered().GetAccount(alice.Address).ReservedFunds[0].UsedFund.IsPositive())
 }
 
+func TestSplitRuleRenewal(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	txFee := getMinimumTxFee()
+	resourceID := "rid_renewal"
+	initiator := types.MakeAcc("User David")
+	initiator.Balance = types.Coins{TFuelWei: big.NewInt(10000 * txFee), ThetaWei: big.NewInt(0)}
+	et.acc2State(initiator, et.accIn)
+
+	splitRuleTx := &types.SplitRuleTx{
+		Fee:        types.NewCoins(0, txFee),
+		ResourceID: resourceID,
+		Initiator: types.TxInput{
+			Address:  initiator.Address,
+			Sequence: 1,
+		},
+		Splits:   []types.Split{{Address: et.accOut.Address, Percentage: 30}},
+		Duration: uint64(50),
+	}
+	splitRuleTx.Initiator.Signature = initiator.Sign(splitRuleTx.SignBytes(et.chainID))
+	_, res := et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	assert.True(res.IsOK(), res.Message)
+	et.state().Commit()
+	endBlockHeight := et.state().Delivered().GetSplitRule(resourceID).EndBlockHeight
+
+	createRenewalTx := func(acc types.PrivAccount, seq int, duration uint64) *types.SplitRuleRenewalTx {
+		renewalTx := &types.SplitRuleRenewalTx{
+			Fee:        types.NewCoins(0, txFee),
+			ResourceID: resourceID,
+			Initiator: types.TxInput{
+				Address:  acc.Address,
+				Sequence: uint64(seq),
+			},
+			Duration: duration,
+		}
+		renewalTx.Initiator.Signature = acc.Sign(renewalTx.SignBytes(et.chainID))
+		return renewalTx
+	}
+
+	// Only the initiator can renew the split rule
+	renewalTx := createRenewalTx(et.accIn, 1, 100)
+	res = et.executor.splitRuleRenewalTxExec.sanityCheck(et.chainID, et.state().Delivered(), renewalTx)
+	assert.Equal(result.CodeUnauthorizedToUpdateSplitRule, res.Code)
+
+	renewalTx = createRenewalTx(initiator, 2, 100)
+	res = et.executor.splitRuleRenewalTxExec.sanityCheck(et.chainID, et.state().Delivered(), renewalTx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = et.executor.splitRuleRenewalTxExec.process(et.chainID, et.state().Delivered(), renewalTx)
+	assert.True(res.IsOK(), res.Message)
+	et.state().Commit()
+
+	splitRule := et.state().Delivered().GetSplitRule(resourceID)
+	assert.Equal(endBlockHeight+100, splitRule.EndBlockHeight)
+	assert.Equal(1, len(splitRule.Splits))
+	assert.Equal(uint64(2), et.state().Delivered().GetAccount(initiator.Address).Sequence)
+
+	// The split rule remains active past its original end block height
+	et.fastforwardBy(100)
+	assert.Equal(0, len(et.state().Delivered().RemoveExpiredSplitRules(et.state().Height())))
+
+	// An expired split rule cannot be renewed, and gets removed
+	et.fastforwardBy(100)
+	renewalTx = createRenewalTx(initiator, 3, 100)
+	res = et.executor.splitRuleRenewalTxExec.sanityCheck(et.chainID, et.state().Delivered(), renewalTx)
+	assert.True(res.IsError())
+
+	expiredRules := et.state().Delivered().RemoveExpiredSplitRules(et.state().Height())
+	assert.Equal(1, len(expiredRules))
+	assert.Equal(resourceID, expiredRules[0].ResourceID)
+	assert.Nil(et.state().Delivered().GetSplitRule(resourceID))
+
+	event := types.NewSplitRuleExpiredEvent(expiredRules[0])
+	assert.Equal(types.EventTypeSplitRuleExpired, event.Type)
+	value, ok := event.GetAttribute("resource_id")
+	assert.True(ok)
+	assert.Equal(resourceID, value)
+}
+
 func TestSplitRuleZeroDuration(t *testing.T) {
 	assert := assert.New(t)
 	et, resourceID, alice, bob, _, _, bobInitBalance, carolInitBalance := setupForServicePayment(assert)
@@ -0,0 +1,113 @@
+package execution
+
+import (
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// PreverifyTxSignatures verifies the signatures of the transactions in parallel. The sanity checks
+// of the transactions then find the valid signatures already verified, see crypto.BatchVerifier.
+// The transactions with invalid signatures are left to the sanity checks to reject.
+func (exec *Executor) PreverifyTxSignatures(txs []types.Tx) {
+	if exec.skipSanityCheck {
+		return
+	}
+	chainID := exec.state.GetChainID()
+	view := exec.state.Delivered()
+	batch := crypto.NewBatchVerifier()
+	for _, tx := range txs {
+		forEachTxSignature(chainID, tx, func(sig *crypto.Signature, addr common.Address, signBytes []byte) {
+			batch.Add(sig, addr, acceptedSignBytes(view, signBytes)...)
+		})
+	}
+	if batch.Len() > 0 {
+		batch.Verify()
+	}
+}
+
+// forEachTxSignature calls the visitor with each non-empty signature of the transaction, along with
+// the expected signer and the sign bytes. The PreverifyTxSignatures adds them to the batch with the
+// sign bytes in the tx wrappers accepted at the height of the next block, see acceptedSignBytes.
+func forEachTxSignature(chainID string, tx types.Tx, visit func(sig *crypto.Signature, addr common.Address, signBytes []byte)) {
+	add := func(sig *crypto.Signature, addr common.Address, signBytes []byte) {
+		if sig == nil || sig.IsEmpty() {
+			return
+		}
+		visit(sig, addr, signBytes)
+	}
+	addInput := func(in types.TxInput) {
+		add(in.Signature, in.Address, tx.SignBytes(chainID))
+	}
+
+	switch tx := tx.(type) {
+	case *types.CoinbaseTx:
+		addInput(tx.Proposer)
+	case *types.SlashTx:
+		addInput(tx.Proposer)
+	case *types.SendTx:
+		for _, in := range tx.Inputs {
+			addInput(in)
+		}
+		for _, in := range tx.FeePayer {
+			addInput(in)
+		}
+	case *types.ReserveFundTx:
+		addInput(tx.Source)
+	case *types.ReserveFundTxV2:
+		addInput(tx.Source)
+	case *types.ReleaseFundTx:
+		addInput(tx.Source)
+	case *types.ServicePaymentTx:
+		add(tx.Source.Signature, tx.Source.Address, tx.SourceSignBytes(chainID))
+		add(tx.Target.Signature, tx.Target.Address, tx.TargetSignBytes(chainID))
+	case *types.SplitRuleTx:
+		addInput(tx.Initiator)
+	case *types.SplitRuleRenewalTx:
+		addInput(tx.Initiator)
+	case *types.SmartContractTx:
+		addInput(tx.From)
+		for _, in := range tx.FeePayer {
+			addInput(in)
+		}
+	case *types.SmartContractTxV2:
+		addInput(tx.From)
+		for _, in := range tx.FeePayer {
+			addInput(in)
+		}
+	case *types.DepositStakeTx:
+		addInput(tx.Source)
+	case *types.DepositStakeTxV2:
+		addInput(tx.Source)
+	case *types.WithdrawStakeTx:
+		addInput(tx.Source)
+	case *types.StakeRewardDistributionTx:
+		addInput(tx.Holder)
+	case *types.MultiSigSendTx:
+		for i, sig := range tx.Signatures {
+			if i < len(tx.MultiSig.Signers) {
+				add(sig, tx.MultiSig.Signers[i], tx.SignBytes(chainID))
+			}
+		}
+	case *types.VestingTransferTx:
+		addInput(tx.Source)
+	case *types.VestingClaimTx:
+		addInput(tx.Beneficiary)
+	case *types.BatchSendTx:
+		addInput(tx.Input)
+	case *types.TokenRegistryTx:
+		addInput(tx.Registrar)
+	case *types.ValidatorKeyRotationTx:
+		addInput(tx.Validator)
+		add(tx.SigningKeySignature, tx.SigningAddress, tx.SignBytes(chainID))
+	case *types.IBCTx:
+		addInput(tx.Relayer)
+	case *types.BandwidthProofTx:
+		addInput(tx.EdgeNode)
+		add(tx.Pool.Signature, tx.Pool.Address, tx.PoolSignBytes(chainID))
+	case *types.GovProposalTx:
+		addInput(tx.Proposer)
+	case *types.GovVoteTx:
+		addInput(tx.Voter)
+	}
+}
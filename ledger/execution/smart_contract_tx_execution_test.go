@@ -251,6 +251,30 @@ func TestCustomTokenSmartContract(t *testing.T) {
 
 // --------------------------- Test Untils --------------------------- //
 
+func TestSmartContractTxRankedByGasPrice(t *testing.T) {
+	assert := assert.New(t)
+	et, privAccounts := setupForSmartContract(assert, 1)
+
+	newTx := func(gasPrice int64, gasLimit uint64) *types.SmartContractTx {
+		return &types.SmartContractTx{
+			From:     types.TxInput{Address: privAccounts[0].Account.Address, Sequence: 1},
+			GasLimit: gasLimit,
+			GasPrice: big.NewInt(gasPrice),
+		}
+	}
+
+	txInfo, res := et.executor.GetTxInfo(newTx(1000, 50000))
+	assert.True(res.IsOK())
+	inflatedTxInfo, res := et.executor.GetTxInfo(newTx(1000, 5000000))
+	assert.True(res.IsOK())
+	higherPriceTxInfo, res := et.executor.GetTxInfo(newTx(2000, 50000))
+	assert.True(res.IsOK())
+
+	// An inflated gas limit does not raise the rank of the transaction, a higher gas price does
+	assert.Equal(0, txInfo.Fee.Cmp(inflatedTxInfo.Fee))
+	assert.True(higherPriceTxInfo.Fee.Cmp(txInfo.Fee) > 0)
+}
+
 func deploySmartContract(et *execTest, deployerPrivAcc *types.PrivAccount,
 	valueAmount int64, gasLimit uint64, deploymentCode, smartContractCode common.Bytes,
 	sequence uint64, assert *assert.Assertions) (contractAddr common.Address) {
@@ -274,7 +298,7 @@ func deploySmartContract(et *execTest, deployerPrivAcc *types.PrivAccount,
 	parentBlock := &core.Block{
 		BlockHeader: &core.BlockHeader{
 			Height:    1,
-			Timestamp: 1601599331,
+			Timestamp: big.NewInt(1601599331),
 		},
 	}
 	stateCopy, err := et.state().Delivered().Copy()
@@ -340,10 +364,10 @@ func callSmartContract(et *execTest, contractAddr common.Address, callerPrivAcc
 	parentBlock := &core.Block{
 		BlockHeader: &core.BlockHeader{
 			Height:    1,
-			Timestamp: 1601599331,
+			Timestamp: big.NewInt(1601599331),
 		},
 	}
-	vmRet, execContractAddr, gasUsed, vmErr := vm.Execute(callSCTX, stateCopy)
+	vmRet, execContractAddr, gasUsed, vmErr := vm.Execute(parentBlock, callSCTX, stateCopy)
 	assert.Equal(contractAddr, execContractAddr)
 	log.Infof("[Call      ] gas used: %v", gasUsed)
 
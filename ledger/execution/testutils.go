@@ -29,6 +29,7 @@ type TestConsensusEngine struct {
 
 func (tce *TestConsensusEngine) ID() string                        { return tce.privKey.PublicKey().Address().Hex() }
 func (tce *TestConsensusEngine) PrivateKey() *crypto.PrivateKey    { return tce.privKey }
+func (tce *TestConsensusEngine) Signer() crypto.Signer             { return tce.privKey }
 func (tce *TestConsensusEngine) GetTip(bool) *core.ExtendedBlock   { return nil }
 func (tce *TestConsensusEngine) GetEpoch() uint64                  { return 100 }
 func (tce *TestConsensusEngine) AddMessage(msg interface{})        {}
@@ -43,6 +44,11 @@ func NewTestConsensusEngine(seed string) *TestConsensusEngine {
 	return &TestConsensusEngine{privKey}
 }
 
+// TestTagger stands in for the rolling DB, which tags the committed state roots
+type TestTagger struct{}
+
+func (TestTagger) Tag(height uint64, root common.Hash) {}
+
 type TestValidatorManager struct {
 	proposer core.Validator
 	valSet   *core.ValidatorSet
@@ -109,7 +115,7 @@ func (et *execTest) reset() {
 		},
 	}
 	db := backend.NewMemDatabase()
-	ledgerState := st.NewLedgerState(chainID, db, nil)
+	ledgerState := st.NewLedgerState(chainID, db, TestTagger{})
 	//ledgerState.ResetState(initHeight, initRootHash)
 	ledgerState.ResetState(initBlock)
 
@@ -209,7 +215,7 @@ func (et *execTest) SetAcc(accs ...types.PrivAccount) {
 }
 
 func getMinimumTxFee() int64 {
-	return int64(types.MinimumTransactionFeeTFuelWeiJune2021)
+	return int64(types.MinimumTransactionFeeTFuelWei)
 }
 
 func createServicePaymentTx(chainID string, source, target *types.PrivAccount, amount int64, srcSeq, tgtSeq, paymentSeq, reserveSeq int, resourceID string) *types.ServicePaymentTx {
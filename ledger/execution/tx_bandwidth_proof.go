@@ -0,0 +1,185 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*BandwidthProofTxExecutor)(nil)
+
+// ------------------------------- BandwidthProof Transaction -----------------------------------
+
+// BandwidthProofTxExecutor implements the TxExecutor interface
+type BandwidthProofTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewBandwidthProofTxExecutor creates a new instance of BandwidthProofTxExecutor
+func NewBandwidthProofTxExecutor(state *st.LedgerState) *BandwidthProofTxExecutor {
+	return &BandwidthProofTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *BandwidthProofTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.BandwidthProofTx)
+
+	res := tx.EdgeNode.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	res = tx.Pool.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	if tx.EdgeNode.Address == tx.Pool.Address {
+		return result.Error("Edge node and pool address for the bandwidth proof cannot be identical: %v", tx.Pool.Address)
+	}
+
+	poolAccount, res := getInput(view, tx.Pool)
+	if res.IsError() {
+		return res
+	}
+
+	// The edge node might not have an account yet, the reward pays for the fee in that case
+	edgeNodeAccount, res := getOrMakeInput(view, tx.EdgeNode)
+	if res.IsError() {
+		return res
+	}
+
+	if !tx.EdgeNode.Coins.IsZero() {
+		return result.Error("The edge node cannot send coins with the bandwidth proof").WithErrorCode(result.CodeInvalidBandwidthProof)
+	}
+	reward := tx.Pool.Coins.NoNil()
+	if reward.ThetaWei.Cmp(types.Zero) != 0 || reward.TFuelWei.Cmp(types.Zero) <= 0 {
+		return result.Error("The bandwidth reward needs to be a positive amount of TFuel").WithErrorCode(result.CodeInvalidBandwidthProof)
+	}
+	if tx.RelayedBytes == 0 {
+		return result.Error("The bandwidth proof needs to prove some relayed bytes").WithErrorCode(result.CodeInvalidBandwidthProof)
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, edgeNodeAccount, signBytes, tx.EdgeNode)
+	if res.IsError() {
+		return res
+	}
+
+	if !verifyTxSignature(view, tx.Pool.Signature, tx.Pool.Address, tx.PoolSignBytes(chainID)) {
+		return result.Error("Signature verification failed for the pool %v", tx.Pool.Address.Hex()).
+			WithErrorCode(result.CodeInvalidSignature)
+	}
+
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+	if !edgeNodeAccount.Balance.Plus(reward).IsGTE(tx.Fee) {
+		return result.Error("Insufficient fund: edge node balance is %v and the reward is %v, but the transaction fee is %v",
+			edgeNodeAccount.Balance, reward, tx.Fee).WithErrorCode(result.CodeInsufficientFund)
+	}
+
+	// Only the proofs of the current and the previous epochs can be claimed
+	currentEpoch := types.BandwidthRewardEpoch(blockHeight)
+	if tx.Epoch > currentEpoch || tx.Epoch+1 < currentEpoch {
+		return result.Error("The bandwidth proof of epoch %v cannot be claimed at epoch %v", tx.Epoch, currentEpoch).
+			WithErrorCode(result.CodeInvalidBandwidthProof)
+	}
+
+	record := view.GetBandwidthRewardRecord(tx.Pool.Address, tx.EdgeNode.Address)
+	if record != nil {
+		if tx.Nonce <= record.Nonce {
+			return result.Error("Invalid bandwidth proof nonce: %v, the last claimed nonce is %v", tx.Nonce, record.Nonce).
+				WithErrorCode(result.CodeBandwidthProofReplayed)
+		}
+		if tx.Epoch < record.Epoch {
+			return result.Error("The bandwidth proof of epoch %v is older than the last claimed proof of epoch %v",
+				tx.Epoch, record.Epoch).WithErrorCode(result.CodeInvalidBandwidthProof)
+		}
+	} else {
+		record = &types.BandwidthRewardRecord{}
+	}
+
+	epochReward, epochRelayedBytes := record.EpochTotals(tx.Epoch)
+	maxEpochReward := new(big.Int).SetUint64(types.MaxBandwidthRewardPerEpochTFuelWei)
+	if new(big.Int).Add(epochReward, reward.TFuelWei).Cmp(maxEpochReward) > 0 {
+		return result.Error("The bandwidth rewards claimed from the pool for epoch %v would exceed %v TFuelWei",
+			tx.Epoch, maxEpochReward).WithErrorCode(result.CodeBandwidthRewardExceedsCap)
+	}
+	if epochRelayedBytes+tx.RelayedBytes < epochRelayedBytes || epochRelayedBytes+tx.RelayedBytes > types.MaxRelayedBytesPerEpoch {
+		return result.Error("The bandwidth relayed for the pool in epoch %v would exceed %v bytes",
+			tx.Epoch, types.MaxRelayedBytesPerEpoch).WithErrorCode(result.CodeBandwidthRewardExceedsCap)
+	}
+
+	err := poolAccount.CheckChargeReservedFund(reward, view.Height(), tx.ReserveSequence, tx.ResourceID)
+	if err != nil {
+		return result.Error(err.Error()).WithErrorCode(result.CodeChargeReservedFundFailed)
+	}
+
+	return result.OK
+}
+
+func (exec *BandwidthProofTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.BandwidthProofTx)
+
+	poolAccount, res := getInput(view, tx.Pool)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	edgeNodeAccount, res := getOrMakeInput(view, tx.EdgeNode)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	reward := tx.Pool.Coins.NoNil()
+	err := poolAccount.ChargeReservedFund(edgeNodeAccount, reward, view.Height(), tx.ReserveSequence, tx.ResourceID)
+	if err != nil {
+		return common.Hash{}, result.Error(err.Error()).WithErrorCode(result.CodeChargeReservedFundFailed)
+	}
+	if !chargeFee(edgeNodeAccount, tx.Fee) {
+		// should charge after the reward is paid, so an empty address has some fund to pay the tx fee
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	record := view.GetBandwidthRewardRecord(tx.Pool.Address, tx.EdgeNode.Address)
+	if record == nil {
+		record = &types.BandwidthRewardRecord{
+			Pool:     tx.Pool.Address,
+			EdgeNode: tx.EdgeNode.Address,
+		}
+	}
+	record.Claim(tx.Nonce, tx.Epoch, reward.TFuelWei, tx.RelayedBytes)
+	view.SetBandwidthRewardRecord(record)
+
+	edgeNodeAccount.Sequence++
+	view.SetAccount(tx.Pool.Address, poolAccount)
+	view.SetAccount(tx.EdgeNode.Address, edgeNodeAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *BandwidthProofTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.BandwidthProofTx)
+	return &core.TxInfo{
+		Address:           tx.EdgeNode.Address,
+		Sequence:          tx.EdgeNode.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *BandwidthProofTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.BandwidthProofTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
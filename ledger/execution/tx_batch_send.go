@@ -0,0 +1,134 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*BatchSendTxExecutor)(nil)
+
+// ------------------------------- BatchSend Transaction -----------------------------------
+
+// BatchSendTxExecutor implements the TxExecutor interface
+type BatchSendTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewBatchSendTxExecutor creates a new instance of BatchSendTxExecutor
+func NewBatchSendTxExecutor(state *st.LedgerState) *BatchSendTxExecutor {
+	return &BatchSendTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *BatchSendTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	tx := transaction.(*types.BatchSendTx)
+
+	// Validate input and outputs, basic
+	res := tx.Input.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+	res = validateOutputsBasic(tx.Outputs)
+	if res.IsError() {
+		return res
+	}
+
+	if len(tx.Outputs) == 0 {
+		return result.Error("Invalid batchSendTx, Outputs are empty")
+	}
+
+	numAccountsAffected := uint64(1 + len(tx.Outputs))
+	if numAccountsAffected > types.MaxAccountsAffectedPerTx {
+		return result.Error("Trasaction modifying too many accounts. At most %v accounts are allowed per transaction",
+			types.MaxAccountsAffectedPerTx)
+	}
+
+	// Get input and outputs, the outputs can neither be duplicated nor include the input
+	accounts, res := getInputs(view, []types.TxInput{tx.Input})
+	if res.IsError() {
+		return res
+	}
+	accounts, res = getOrMakeOutputs(view, accounts, tx.Outputs)
+	if res.IsError() {
+		return res
+	}
+
+	for _, outAcc := range accounts {
+		if outAcc.IsASmartContract() {
+			return result.Error(
+				fmt.Sprintf("Sending Theta/TFuel to a smart contract (%v) through a BatchSendTx transaction is not allowed", outAcc.Address))
+		}
+	}
+
+	// Validate input, advanced
+	blockHeight := view.Height() + 1
+	inputAccount := accounts[string(tx.Input.Address[:])]
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, inputAccount, signBytes, tx.Input)
+	if res.IsError() {
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForBatchSendTxFee(view, transaction, tx.Fee, uint64(len(tx.Outputs)), blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	outTotal := sumOutputs(tx.Outputs)
+	outPlusFees := outTotal.Plus(tx.Fee)
+	if !tx.Input.Coins.IsEqual(outPlusFees) {
+		return result.Error("Input total (%v) != output total + fees (%v)", tx.Input.Coins, outPlusFees)
+	}
+
+	return result.OK
+}
+
+func (exec *BatchSendTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.BatchSendTx)
+
+	inputs := []types.TxInput{tx.Input}
+	accounts, res := getInputs(view, inputs)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	accounts, res = getOrMakeOutputs(view, accounts, tx.Outputs)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	adjustByInputs(view, accounts, inputs)
+	adjustByOutputs(view, accounts, tx.Outputs)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *BatchSendTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.BatchSendTx)
+	return &core.TxInfo{
+		Address:           tx.Input.Address,
+		Sequence:          tx.Input.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *BatchSendTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.BatchSendTx)
+	fee := tx.Fee
+	numOutputs := uint64(len(tx.Outputs))
+
+	// The gas of each output is priced proportionally to its share of the minimum fee
+	regularTxGas := getRegularTxGas(exec.state)
+	gasPerOutput := regularTxGas * types.BatchSendTxFeePerOutputTFuelWei / types.MinimumTransactionFeeTFuelWeiJune2021
+	gas := new(big.Int).SetUint64(regularTxGas + gasPerOutput*numOutputs)
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
@@ -75,9 +75,12 @@ func (exec *CoinbaseTxExecutor) sanityCheck(chainID string, view *st.StoreView,
 		return res
 	}
 
-	// verify the proposer's signature
+	// verify the proposer's signature, which is made with the rotated key if the proposer has rotated
+	// its signing key. No signing keys are stored before the validator key rotation upgrade.
+	currentBlock := exec.consensus.GetLedger().GetCurrentBlock()
+	signingAddress := view.GetValidatorSigningAddress(proposerAccount.Address, currentBlock.Epoch)
 	signBytes := tx.SignBytes(chainID)
-	if !tx.Proposer.Signature.Verify(signBytes, proposerAccount.Address) {
+	if !tx.Proposer.Signature.Verify(signBytes, signingAddress) {
 		return result.Error("SignBytes: %X", signBytes)
 	}
 
@@ -94,7 +97,6 @@ func (exec *CoinbaseTxExecutor) sanityCheck(chainID string, view *st.StoreView,
 
 	// check the reward amount
 	var expectedRewards map[string]types.Coins
-	currentBlock := exec.consensus.GetLedger().GetCurrentBlock()
 	guardianVotes := currentBlock.GuardianVotes
 	eliteEdgeNodeVotes := currentBlock.EliteEdgeNodeVotes
 	guardianPool, eliteEdgeNodePool := RetrievePools(exec.consensus.GetLedger(), exec.chain, exec.db, tx.BlockHeight, guardianVotes, eliteEdgeNodeVotes)
@@ -48,13 +48,13 @@ func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView
 	}
 
 	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(sourceAccount, signBytes, tx.Source, blockHeight)
+	res = validateInputAdvanced(view, sourceAccount, signBytes, tx.Source)
 	if res.IsError() {
 		logger.Debugf(fmt.Sprintf("validateSourceAdvanced failed on %v: %v", tx.Source.Address.Hex(), res))
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -276,10 +276,11 @@ func (exec *DepositStakeExecutor) castTx(transaction types.Tx) *types.DepositSta
 	}
 	if tx, ok := transaction.(*types.DepositStakeTx); ok {
 		return &types.DepositStakeTxV2{
-			Fee:     tx.Fee,
-			Source:  tx.Source,
-			Holder:  tx.Holder,
-			Purpose: tx.Purpose,
+			Fee:            tx.Fee,
+			Source:         tx.Source,
+			Holder:         tx.Holder,
+			Purpose:        tx.Purpose,
+			NotAfterHeight: tx.NotAfterHeight,
 		}
 	}
 	panic("Unreachable code")
@@ -0,0 +1,397 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/upgrade"
+)
+
+var _ TxExecutor = (*GovProposalTxExecutor)(nil)
+var _ TxExecutor = (*GovVoteTxExecutor)(nil)
+
+// ------------------------------- GovProposal Transaction -----------------------------------
+
+// GovProposalTxExecutor implements the TxExecutor interface
+type GovProposalTxExecutor struct {
+	state    *st.LedgerState
+	upgrades *upgrade.Manager
+}
+
+// NewGovProposalTxExecutor creates a new instance of GovProposalTxExecutor
+func NewGovProposalTxExecutor(state *st.LedgerState, upgrades *upgrade.Manager) *GovProposalTxExecutor {
+	return &GovProposalTxExecutor{
+		state:    state,
+		upgrades: upgrades,
+	}
+}
+
+func (exec *GovProposalTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.GovProposalTx)
+
+	res := tx.Proposer.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	proposerAccount, res := getInput(view, tx.Proposer)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, proposerAccount, signBytes, tx.Proposer)
+	if res.IsError() {
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	deposit := tx.Proposer.Coins.NoNil()
+	minDeposit := new(big.Int).SetUint64(types.MinGovProposalDepositTFuelWei)
+	if deposit.ThetaWei.Sign() != 0 || deposit.TFuelWei.Cmp(minDeposit) < 0 {
+		return result.Error("The proposal deposit needs to be at least %v TFuelWei, and cannot include Theta", minDeposit).
+			WithErrorCode(result.CodeInsufficientGovDeposit)
+	}
+	if !proposerAccount.Balance.IsGTE(deposit.Plus(tx.Fee)) {
+		return result.Error("Insufficient fund: proposer balance is %v, but the deposit is %v and the transaction fee is %v",
+			proposerAccount.Balance, deposit, tx.Fee).WithErrorCode(result.CodeInsufficientFund)
+	}
+
+	if len(tx.Title) == 0 || len(tx.Title) > types.MaxGovProposalTitleLength {
+		return result.Error("Proposal title needs to have 1 to %v characters", types.MaxGovProposalTitleLength).
+			WithErrorCode(result.CodeInvalidGovProposal)
+	}
+	if len(tx.Description) > types.MaxGovProposalDescriptionLength {
+		return result.Error("Proposal description cannot exceed %v characters", types.MaxGovProposalDescriptionLength).
+			WithErrorCode(result.CodeInvalidGovProposal)
+	}
+
+	switch tx.ProposalType {
+	case types.GovProposalTypeText:
+		if len(tx.ParamChanges) != 0 {
+			return result.Error("A text proposal cannot change the chain params").
+				WithErrorCode(result.CodeInvalidGovProposal)
+		}
+	case types.GovProposalTypeParamChange:
+		if len(tx.ParamChanges) == 0 {
+			return result.Error("A param change proposal needs to change at least one chain param").
+				WithErrorCode(result.CodeInvalidGovProposal)
+		}
+		// The upgrades need to be scheduled after the end of the vote
+		votingEndHeight := blockHeight + types.GovVotingPeriod
+		if err := exec.checkParamChanges(view, tx.ParamChanges, votingEndHeight); err != nil {
+			return result.Error("Invalid param change: %v", err).WithErrorCode(result.CodeInvalidGovProposal)
+		}
+	default:
+		return result.Error("Unknown proposal type: %v", tx.ProposalType).WithErrorCode(result.CodeInvalidGovProposal)
+	}
+
+	return result.OK
+}
+
+func (exec *GovProposalTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1
+	tx := transaction.(*types.GovProposalTx)
+
+	proposerAccount, res := getInput(view, tx.Proposer)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	if !chargeFee(proposerAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	deposit := tx.Proposer.Coins.NoNil()
+	proposerAccount.Balance = proposerAccount.Balance.Minus(deposit)
+
+	view.SetGovProposal(&types.GovProposal{
+		ID:              view.NextGovProposalID(),
+		Proposer:        tx.Proposer.Address,
+		Type:            tx.ProposalType,
+		Title:           tx.Title,
+		Description:     tx.Description,
+		ParamChanges:    tx.ParamChanges,
+		Deposit:         deposit,
+		SubmitHeight:    blockHeight,
+		VotingEndHeight: blockHeight + types.GovVotingPeriod,
+		Status:          types.GovProposalStatusVoting,
+	})
+
+	proposerAccount.Sequence++
+	view.SetAccount(tx.Proposer.Address, proposerAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+// checkParamChanges checks that the param changes can be applied at the block height
+func (exec *GovProposalTxExecutor) checkParamChanges(view *st.StoreView, paramChanges []types.GovParamChange, blockHeight uint64) error {
+	_, err := exec.decodeParamChanges(view, paramChanges, blockHeight)
+	return err
+}
+
+// applyParamChanges applies either all or none of the param changes
+func (exec *GovProposalTxExecutor) applyParamChanges(view *st.StoreView, paramChanges []types.GovParamChange, blockHeight uint64) error {
+	values, err := exec.decodeParamChanges(view, paramChanges, blockHeight)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		switch value := value.(type) {
+		case *types.FeeSchedule:
+			view.SetFeeSchedule(value)
+		case *types.UpgradeSchedule:
+			view.SetUpgradeSchedule(value)
+		}
+	}
+	return nil
+}
+
+func (exec *GovProposalTxExecutor) decodeParamChanges(view *st.StoreView, paramChanges []types.GovParamChange, blockHeight uint64) ([]interface{}, error) {
+	params := make(map[string]bool)
+	values := []interface{}{}
+	for _, pc := range paramChanges {
+		if params[pc.Param] {
+			return nil, fmt.Errorf("chain param %v changed more than once", pc.Param)
+		}
+		params[pc.Param] = true
+
+		value, err := pc.DecodeValue()
+		if err != nil {
+			return nil, err
+		}
+		if schedule, ok := value.(*types.UpgradeSchedule); ok {
+			if err := exec.upgrades.CheckReschedule(view, schedule, blockHeight); err != nil {
+				return nil, err
+			}
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// tallyProposals ends the vote on the proposals whose voting period is over. The deposits of the
+// proposals reaching the quorum are refunded, and the param changes of the approved proposals are
+// applied. Returns an event for each tallied proposal.
+func (exec *GovProposalTxExecutor) tallyProposals(view *st.StoreView) []*types.Event {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	events := []*types.Event{}
+
+	var stakes map[common.Address]*big.Int
+	var totalStake *big.Int
+	for _, proposal := range view.GetActiveGovProposals() {
+		if proposal.VotingEndHeight > blockHeight {
+			continue
+		}
+		if stakes == nil {
+			stakes, totalStake = getGovVotingStakes(view)
+		}
+
+		yes, no, abstain := big.NewInt(0), big.NewInt(0), big.NewInt(0)
+		for _, vote := range view.GetGovVotes(proposal.ID) {
+			stake, ok := stakes[vote.Voter]
+			if !ok {
+				continue
+			}
+			switch vote.Option {
+			case types.GovVoteOptionYes:
+				yes.Add(yes, stake)
+			case types.GovVoteOptionNo:
+				no.Add(no, stake)
+			case types.GovVoteOptionAbstain:
+				abstain.Add(abstain, stake)
+			}
+		}
+
+		if proposal.Tally(yes, no, abstain, totalStake) {
+			proposerAccount := getOrMakeAccount(view, proposal.Proposer)
+			proposerAccount.Balance = proposerAccount.Balance.Plus(proposal.Deposit)
+			view.SetAccount(proposal.Proposer, proposerAccount)
+		}
+
+		if proposal.Status == types.GovProposalStatusPassed && proposal.Type == types.GovProposalTypeParamChange {
+			if err := exec.applyParamChanges(view, proposal.ParamChanges, blockHeight); err != nil {
+				logger.Warnf("Failed to apply the param changes of governance proposal %v: %v", proposal.ID, err)
+				proposal.Status = types.GovProposalStatusFailed
+			}
+		}
+
+		view.SetGovProposal(proposal)
+		events = append(events, types.NewGovProposalTalliedEvent(proposal))
+	}
+	return events
+}
+
+// getGovVotingStakes returns the Theta staked by each source address to the validator and the
+// guardian candidates, excluding the withdrawn stakes, along with the total stake
+func getGovVotingStakes(view *st.StoreView) (map[common.Address]*big.Int, *big.Int) {
+	stakes := make(map[common.Address]*big.Int)
+	totalStake := big.NewInt(0)
+	addStakes := func(holders []*core.StakeHolder) {
+		for _, holder := range holders {
+			for _, stake := range holder.Stakes {
+				if stake.Withdrawn {
+					continue
+				}
+				if _, ok := stakes[stake.Source]; !ok {
+					stakes[stake.Source] = big.NewInt(0)
+				}
+				stakes[stake.Source].Add(stakes[stake.Source], stake.Amount)
+				totalStake.Add(totalStake, stake.Amount)
+			}
+		}
+	}
+
+	if vcp := view.GetValidatorCandidatePool(); vcp != nil {
+		addStakes(vcp.SortedCandidates)
+	}
+	guardians := []*core.StakeHolder{}
+	for _, g := range view.GetGuardianCandidatePool().SortedGuardians {
+		guardians = append(guardians, g.StakeHolder)
+	}
+	addStakes(guardians)
+
+	return stakes, totalStake
+}
+
+func (exec *GovProposalTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.GovProposalTx)
+	return &core.TxInfo{
+		Address:           tx.Proposer.Address,
+		Sequence:          tx.Proposer.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *GovProposalTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.GovProposalTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
+
+// ------------------------------- GovVote Transaction -----------------------------------
+
+// GovVoteTxExecutor implements the TxExecutor interface
+type GovVoteTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewGovVoteTxExecutor creates a new instance of GovVoteTxExecutor
+func NewGovVoteTxExecutor(state *st.LedgerState) *GovVoteTxExecutor {
+	return &GovVoteTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *GovVoteTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.GovVoteTx)
+
+	res := tx.Voter.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	voterAccount, res := getInput(view, tx.Voter)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, voterAccount, signBytes, tx.Voter)
+	if res.IsError() {
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+	if !voterAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("Insufficient fund: voter balance is %v, but the transaction fee is %v",
+			voterAccount.Balance, tx.Fee).WithErrorCode(result.CodeInsufficientFund)
+	}
+
+	if !tx.Voter.Coins.IsZero() {
+		return result.Error("The voter cannot send coins with the vote").WithErrorCode(result.CodeInvalidGovVote)
+	}
+	switch tx.Option {
+	case types.GovVoteOptionYes, types.GovVoteOptionNo, types.GovVoteOptionAbstain:
+	default:
+		return result.Error("Unknown vote option: %v", tx.Option).WithErrorCode(result.CodeInvalidGovVote)
+	}
+
+	proposal := view.GetGovProposal(tx.ProposalID)
+	if proposal == nil {
+		return result.Error("Governance proposal %v not found", tx.ProposalID).WithErrorCode(result.CodeGovProposalNotFound)
+	}
+	if proposal.Status != types.GovProposalStatusVoting || blockHeight > proposal.VotingEndHeight {
+		return result.Error("The vote on governance proposal %v ended at height %v", tx.ProposalID, proposal.VotingEndHeight).
+			WithErrorCode(result.CodeGovVotingClosed)
+	}
+
+	stakes, _ := getGovVotingStakes(view)
+	if _, ok := stakes[tx.Voter.Address]; !ok {
+		return result.Error("%v does not stake any Theta to the validators or the guardians", tx.Voter.Address.Hex()).
+			WithErrorCode(result.CodeInvalidGovVote)
+	}
+
+	return result.OK
+}
+
+func (exec *GovVoteTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1
+	tx := transaction.(*types.GovVoteTx)
+
+	voterAccount, res := getInput(view, tx.Voter)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	if !chargeFee(voterAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	view.SetGovVote(&types.GovVote{
+		ProposalID: tx.ProposalID,
+		Voter:      tx.Voter.Address,
+		Option:     tx.Option,
+		Height:     blockHeight,
+	})
+
+	voterAccount.Sequence++
+	view.SetAccount(tx.Voter.Address, voterAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *GovVoteTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.GovVoteTx)
+	return &core.TxInfo{
+		Address:           tx.Voter.Address,
+		Sequence:          tx.Voter.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *GovVoteTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.GovVoteTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
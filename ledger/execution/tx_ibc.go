@@ -0,0 +1,706 @@
+package execution
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/thetatoken/theta/bridge"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store/trie"
+)
+
+var _ TxExecutor = (*IBCTxExecutor)(nil)
+
+// maxNumCounterpartyValidators is the maximum size of the counterparty validator set, same as consensus.MaxValidatorCount
+const maxNumCounterpartyValidators = 31
+
+// The kinds of the generated IBC identifiers
+const (
+	ibcClientKind     = "client"
+	ibcConnectionKind = "connection"
+	ibcChannelKind    = "channel"
+)
+
+// ------------------------------- IBC Transaction -----------------------------------
+
+// IBCTxExecutor implements the TxExecutor interface
+type IBCTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewIBCTxExecutor creates a new instance of IBCTxExecutor
+func NewIBCTxExecutor(state *st.LedgerState) *IBCTxExecutor {
+	return &IBCTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *IBCTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.IBCTx)
+
+	res := tx.Relayer.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	relayerAccount, res := getInput(view, tx.Relayer)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, relayerAccount, signBytes, tx.Relayer)
+	if res.IsError() {
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+	if !relayerAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("Insufficient fund: relayer balance is %v, but the transaction fee is %v",
+			relayerAccount.Balance, tx.Fee).WithErrorCode(result.CodeInsufficientFund)
+	}
+
+	msg, err := tx.DecodeMsg()
+	if err != nil {
+		return result.Error("Invalid IBC message: %v", err).WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	return exec.handleMsg(view, tx.Relayer.Address, msg, blockHeight, false)
+}
+
+func (exec *IBCTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1
+	tx := transaction.(*types.IBCTx)
+
+	relayerAccount, res := getInput(view, tx.Relayer)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	msg, err := tx.DecodeMsg()
+	if err != nil {
+		return common.Hash{}, result.Error("Invalid IBC message: %v", err).WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+
+	if !chargeFee(relayerAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	// The message handler checks the message in full before updating the view
+	res = exec.handleMsg(view, tx.Relayer.Address, msg, blockHeight, true)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	relayerAccount.Sequence++
+	view.SetAccount(tx.Relayer.Address, relayerAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *IBCTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.IBCTx)
+	return &core.TxInfo{
+		Address:           tx.Relayer.Address,
+		Sequence:          tx.Relayer.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *IBCTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.IBCTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
+
+// handleMsg checks the message against the view, and applies it to the view if apply is true. The
+// view is only updated once all the checks pass.
+func (exec *IBCTxExecutor) handleMsg(view *st.StoreView, relayer common.Address, msg types.IBCMsg,
+	blockHeight uint64, apply bool) result.Result {
+	switch msg := msg.(type) {
+	case *types.MsgCreateClient:
+		return exec.createClient(view, msg, apply)
+	case *types.MsgUpdateClient:
+		return exec.updateClient(view, msg, apply)
+	case *types.MsgConnectionOpenInit:
+		return exec.connectionOpenInit(view, msg, apply)
+	case *types.MsgConnectionOpenTry:
+		return exec.connectionOpenTry(view, msg, apply)
+	case *types.MsgConnectionOpenAck:
+		return exec.connectionOpenAck(view, msg, apply)
+	case *types.MsgConnectionOpenConfirm:
+		return exec.connectionOpenConfirm(view, msg, apply)
+	case *types.MsgChannelOpenInit:
+		return exec.channelOpenInit(view, relayer, msg, apply)
+	case *types.MsgChannelOpenTry:
+		return exec.channelOpenTry(view, relayer, msg, apply)
+	case *types.MsgChannelOpenAck:
+		return exec.channelOpenAck(view, msg, apply)
+	case *types.MsgChannelOpenConfirm:
+		return exec.channelOpenConfirm(view, msg, apply)
+	case *types.MsgSendPacket:
+		return exec.sendPacket(view, relayer, msg, apply)
+	case *types.MsgRecvPacket:
+		return exec.recvPacket(view, msg, blockHeight, apply)
+	case *types.MsgAcknowledgePacket:
+		return exec.acknowledgePacket(view, msg, apply)
+	case *types.MsgTimeoutPacket:
+		return exec.timeoutPacket(view, msg, apply)
+	default:
+		return result.Error("Unknown IBC message").WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+}
+
+// ---------------- Clients ---------------- //
+
+func (exec *IBCTxExecutor) createClient(view *st.StoreView, msg *types.MsgCreateClient, apply bool) result.Result {
+	if msg.CounterpartyChainID == "" {
+		return result.Error("Counterparty chain ID is not specified").WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	if msg.Height == 0 || msg.StateHash.IsEmpty() {
+		return result.Error("Trusted counterparty block is not specified").WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	if len(msg.Validators) == 0 || len(msg.Validators) > maxNumCounterpartyValidators {
+		return result.Error("The counterparty validator set needs to have 1 to %v validators",
+			maxNumCounterpartyValidators).WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	for _, v := range msg.Validators {
+		if v.Stake == nil || v.Stake.Sign() <= 0 {
+			return result.Error("Stake of the counterparty validator %v needs to be positive",
+				v.Address.Hex()).WithErrorCode(result.CodeInvalidIBCMsg)
+		}
+	}
+	if !apply {
+		return result.OK
+	}
+
+	timestamp := msg.Timestamp
+	if timestamp == nil {
+		timestamp = big.NewInt(0)
+	}
+	client := &types.IBCClientState{
+		ClientID:            view.NextIBCIdentifier(ibcClientKind),
+		CounterpartyChainID: msg.CounterpartyChainID,
+		LatestHeight:        msg.Height,
+		Validators:          msg.Validators,
+	}
+	view.SetIBCClient(client)
+	view.SetIBCConsensusState(client.ClientID, &types.IBCConsensusState{
+		Height:    msg.Height,
+		StateHash: msg.StateHash,
+		Timestamp: timestamp,
+	})
+	return result.OK
+}
+
+func (exec *IBCTxExecutor) updateClient(view *st.StoreView, msg *types.MsgUpdateClient, apply bool) result.Result {
+	client := view.GetIBCClient(msg.ClientID)
+	if client == nil {
+		return result.Error("IBC client %v not found", msg.ClientID).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	bundle, err := bridge.DecodeProofBundle(msg.Bundle)
+	if err != nil {
+		return result.Error("Invalid proof bundle: %v", err).WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	if err := bundle.Verify(client.CounterpartyChainID, client.ValidatorSet()); err != nil {
+		return result.Error("Failed to verify the counterparty block: %v", err).WithErrorCode(result.CodeIBCInvalidProof)
+	}
+	header := bundle.Header
+	if view.GetIBCConsensusState(client.ClientID, header.Height) != nil {
+		return result.Error("IBC client %v already tracks height %v", client.ClientID, header.Height).
+			WithErrorCode(result.CodeIBCInvalidState)
+	}
+
+	var validators []core.Validator
+	if len(msg.ValidatorCandidatePoolProof) > 0 {
+		vcpBytes, _, err := trie.VerifyProof(header.StateHash, st.ValidatorCandidatePoolKey(), bridge.MerkleProof(msg.ValidatorCandidatePoolProof))
+		if err != nil || vcpBytes == nil {
+			return result.Error("Invalid proof of the counterparty validator candidate pool: %v", err).
+				WithErrorCode(result.CodeIBCInvalidProof)
+		}
+		vcp := &core.ValidatorCandidatePool{}
+		if err := rlp.DecodeBytes(vcpBytes, vcp); err != nil {
+			return result.Error("Invalid counterparty validator candidate pool: %v", err).
+				WithErrorCode(result.CodeIBCInvalidProof)
+		}
+		for _, stakeHolder := range vcp.GetTopStakeHolders(maxNumCounterpartyValidators) {
+			stake := stakeHolder.TotalStake()
+			if stake.Sign() == 0 {
+				continue
+			}
+			validators = append(validators, core.Validator{Address: stakeHolder.Holder, Stake: stake})
+		}
+		if len(validators) == 0 {
+			return result.Error("Counterparty validator set is empty").WithErrorCode(result.CodeIBCInvalidProof)
+		}
+	}
+	if !apply {
+		return result.OK
+	}
+
+	view.SetIBCConsensusState(client.ClientID, &types.IBCConsensusState{
+		Height:    header.Height,
+		StateHash: header.StateHash,
+		Timestamp: header.Timestamp,
+	})
+	if header.Height > client.LatestHeight {
+		client.LatestHeight = header.Height
+		if validators != nil {
+			client.Validators = validators
+		}
+	}
+	view.SetIBCClient(client)
+	return result.OK
+}
+
+// verifyCounterpartyState checks the proof that the counterparty state, committed by the consensus
+// state tracked by the client at the proof height, holds the value at the key. A nil value checks
+// that the counterparty state holds no value at the key.
+func verifyCounterpartyState(view *st.StoreView, clientID string, proof *types.IBCProof, key, value common.Bytes) result.Result {
+	cs := view.GetIBCConsensusState(clientID, proof.Height)
+	if cs == nil {
+		return result.Error("IBC client %v does not track height %v", clientID, proof.Height).
+			WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	stored, _, err := trie.VerifyProof(cs.StateHash, key, bridge.MerkleProof(proof.Nodes))
+	if err != nil {
+		return result.Error("Invalid proof of the counterparty state: %v", err).WithErrorCode(result.CodeIBCInvalidProof)
+	}
+	if !bytes.Equal(stored, value) {
+		return result.Error("Counterparty state does not match at key %v", string(key)).
+			WithErrorCode(result.CodeIBCInvalidProof)
+	}
+	return result.OK
+}
+
+// verifyCounterpartyObject checks the proof that the counterparty state holds the object at the key
+func verifyCounterpartyObject(view *st.StoreView, clientID string, proof *types.IBCProof, key common.Bytes, obj interface{}) result.Result {
+	value, err := types.ToBytes(obj)
+	if err != nil {
+		return result.Error("Failed to encode the expected counterparty state: %v", err)
+	}
+	return verifyCounterpartyState(view, clientID, proof, key, value)
+}
+
+// ---------------- Connections ---------------- //
+
+func (exec *IBCTxExecutor) connectionOpenInit(view *st.StoreView, msg *types.MsgConnectionOpenInit, apply bool) result.Result {
+	if view.GetIBCClient(msg.ClientID) == nil {
+		return result.Error("IBC client %v not found", msg.ClientID).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	if err := types.ValidateIBCIdentifier(msg.CounterpartyClientID); err != nil {
+		return result.Error(err.Error()).WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	if !apply {
+		return result.OK
+	}
+
+	view.SetIBCConnection(&types.IBCConnection{
+		ConnectionID:         view.NextIBCIdentifier(ibcConnectionKind),
+		ClientID:             msg.ClientID,
+		CounterpartyClientID: msg.CounterpartyClientID,
+		State:                types.IBCStateInit,
+	})
+	return result.OK
+}
+
+func (exec *IBCTxExecutor) connectionOpenTry(view *st.StoreView, msg *types.MsgConnectionOpenTry, apply bool) result.Result {
+	if view.GetIBCClient(msg.ClientID) == nil {
+		return result.Error("IBC client %v not found", msg.ClientID).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	for _, id := range []string{msg.CounterpartyClientID, msg.CounterpartyConnectionID} {
+		if err := types.ValidateIBCIdentifier(id); err != nil {
+			return result.Error(err.Error()).WithErrorCode(result.CodeInvalidIBCMsg)
+		}
+	}
+	expected := &types.IBCConnection{
+		ConnectionID:         msg.CounterpartyConnectionID,
+		ClientID:             msg.CounterpartyClientID,
+		CounterpartyClientID: msg.ClientID,
+		State:                types.IBCStateInit,
+	}
+	if res := verifyCounterpartyObject(view, msg.ClientID, &msg.Proof, st.IBCConnectionKey(expected.ConnectionID), expected); res.IsError() {
+		return res
+	}
+	if !apply {
+		return result.OK
+	}
+
+	view.SetIBCConnection(&types.IBCConnection{
+		ConnectionID:             view.NextIBCIdentifier(ibcConnectionKind),
+		ClientID:                 msg.ClientID,
+		CounterpartyClientID:     msg.CounterpartyClientID,
+		CounterpartyConnectionID: msg.CounterpartyConnectionID,
+		State:                    types.IBCStateTryOpen,
+	})
+	return result.OK
+}
+
+func (exec *IBCTxExecutor) connectionOpenAck(view *st.StoreView, msg *types.MsgConnectionOpenAck, apply bool) result.Result {
+	conn := view.GetIBCConnection(msg.ConnectionID)
+	if conn == nil {
+		return result.Error("IBC connection %v not found", msg.ConnectionID).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	if conn.State != types.IBCStateInit {
+		return result.Error("IBC connection %v is in the %v state", conn.ConnectionID, conn.State).
+			WithErrorCode(result.CodeIBCInvalidState)
+	}
+	if err := types.ValidateIBCIdentifier(msg.CounterpartyConnectionID); err != nil {
+		return result.Error(err.Error()).WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	expected := &types.IBCConnection{
+		ConnectionID:             msg.CounterpartyConnectionID,
+		ClientID:                 conn.CounterpartyClientID,
+		CounterpartyClientID:     conn.ClientID,
+		CounterpartyConnectionID: conn.ConnectionID,
+		State:                    types.IBCStateTryOpen,
+	}
+	if res := verifyCounterpartyObject(view, conn.ClientID, &msg.Proof, st.IBCConnectionKey(expected.ConnectionID), expected); res.IsError() {
+		return res
+	}
+	if !apply {
+		return result.OK
+	}
+
+	conn.CounterpartyConnectionID = msg.CounterpartyConnectionID
+	conn.State = types.IBCStateOpen
+	view.SetIBCConnection(conn)
+	return result.OK
+}
+
+func (exec *IBCTxExecutor) connectionOpenConfirm(view *st.StoreView, msg *types.MsgConnectionOpenConfirm, apply bool) result.Result {
+	conn := view.GetIBCConnection(msg.ConnectionID)
+	if conn == nil {
+		return result.Error("IBC connection %v not found", msg.ConnectionID).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	if conn.State != types.IBCStateTryOpen {
+		return result.Error("IBC connection %v is in the %v state", conn.ConnectionID, conn.State).
+			WithErrorCode(result.CodeIBCInvalidState)
+	}
+	expected := &types.IBCConnection{
+		ConnectionID:             conn.CounterpartyConnectionID,
+		ClientID:                 conn.CounterpartyClientID,
+		CounterpartyClientID:     conn.ClientID,
+		CounterpartyConnectionID: conn.ConnectionID,
+		State:                    types.IBCStateOpen,
+	}
+	if res := verifyCounterpartyObject(view, conn.ClientID, &msg.Proof, st.IBCConnectionKey(expected.ConnectionID), expected); res.IsError() {
+		return res
+	}
+	if !apply {
+		return result.OK
+	}
+
+	conn.State = types.IBCStateOpen
+	view.SetIBCConnection(conn)
+	return result.OK
+}
+
+// ---------------- Channels ---------------- //
+
+// checkPortOwner checks that the port is unbound or bound to the owner
+func checkPortOwner(view *st.StoreView, portID string, owner common.Address) result.Result {
+	if err := types.ValidateIBCIdentifier(portID); err != nil {
+		return result.Error(err.Error()).WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	port := view.GetIBCPort(portID)
+	if port != nil && port.Owner != owner {
+		return result.Error("IBC port %v is owned by %v", portID, port.Owner.Hex()).WithErrorCode(result.CodeIBCUnauthorized)
+	}
+	return result.OK
+}
+
+// getOpenConnection returns the connection if it is open
+func getOpenConnection(view *st.StoreView, connectionID string) (*types.IBCConnection, result.Result) {
+	conn := view.GetIBCConnection(connectionID)
+	if conn == nil {
+		return nil, result.Error("IBC connection %v not found", connectionID).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	if conn.State != types.IBCStateOpen {
+		return nil, result.Error("IBC connection %v is not open", connectionID).WithErrorCode(result.CodeIBCInvalidState)
+	}
+	return conn, result.OK
+}
+
+func (exec *IBCTxExecutor) channelOpenInit(view *st.StoreView, relayer common.Address, msg *types.MsgChannelOpenInit, apply bool) result.Result {
+	if res := checkPortOwner(view, msg.PortID, relayer); res.IsError() {
+		return res
+	}
+	if _, res := getOpenConnection(view, msg.ConnectionID); res.IsError() {
+		return res
+	}
+	if err := types.ValidateIBCIdentifier(msg.CounterpartyPortID); err != nil {
+		return result.Error(err.Error()).WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	if !apply {
+		return result.OK
+	}
+
+	view.SetIBCPort(&types.IBCPort{PortID: msg.PortID, Owner: relayer})
+	view.SetIBCChannel(&types.IBCChannel{
+		PortID:             msg.PortID,
+		ChannelID:          view.NextIBCIdentifier(ibcChannelKind),
+		ConnectionID:       msg.ConnectionID,
+		CounterpartyPortID: msg.CounterpartyPortID,
+		State:              types.IBCStateInit,
+	})
+	return result.OK
+}
+
+func (exec *IBCTxExecutor) channelOpenTry(view *st.StoreView, relayer common.Address, msg *types.MsgChannelOpenTry, apply bool) result.Result {
+	if res := checkPortOwner(view, msg.PortID, relayer); res.IsError() {
+		return res
+	}
+	conn, res := getOpenConnection(view, msg.ConnectionID)
+	if res.IsError() {
+		return res
+	}
+	for _, id := range []string{msg.CounterpartyPortID, msg.CounterpartyChannelID} {
+		if err := types.ValidateIBCIdentifier(id); err != nil {
+			return result.Error(err.Error()).WithErrorCode(result.CodeInvalidIBCMsg)
+		}
+	}
+	expected := &types.IBCChannel{
+		PortID:             msg.CounterpartyPortID,
+		ChannelID:          msg.CounterpartyChannelID,
+		ConnectionID:       conn.CounterpartyConnectionID,
+		CounterpartyPortID: msg.PortID,
+		State:              types.IBCStateInit,
+	}
+	key := st.IBCChannelKey(expected.PortID, expected.ChannelID)
+	if res := verifyCounterpartyObject(view, conn.ClientID, &msg.Proof, key, expected); res.IsError() {
+		return res
+	}
+	if !apply {
+		return result.OK
+	}
+
+	view.SetIBCPort(&types.IBCPort{PortID: msg.PortID, Owner: relayer})
+	view.SetIBCChannel(&types.IBCChannel{
+		PortID:                msg.PortID,
+		ChannelID:             view.NextIBCIdentifier(ibcChannelKind),
+		ConnectionID:          msg.ConnectionID,
+		CounterpartyPortID:    msg.CounterpartyPortID,
+		CounterpartyChannelID: msg.CounterpartyChannelID,
+		State:                 types.IBCStateTryOpen,
+	})
+	return result.OK
+}
+
+func (exec *IBCTxExecutor) channelOpenAck(view *st.StoreView, msg *types.MsgChannelOpenAck, apply bool) result.Result {
+	channel := view.GetIBCChannel(msg.PortID, msg.ChannelID)
+	if channel == nil {
+		return result.Error("IBC channel %v/%v not found", msg.PortID, msg.ChannelID).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	if channel.State != types.IBCStateInit {
+		return result.Error("IBC channel %v/%v is in the %v state", msg.PortID, msg.ChannelID, channel.State).
+			WithErrorCode(result.CodeIBCInvalidState)
+	}
+	conn, res := getOpenConnection(view, channel.ConnectionID)
+	if res.IsError() {
+		return res
+	}
+	if err := types.ValidateIBCIdentifier(msg.CounterpartyChannelID); err != nil {
+		return result.Error(err.Error()).WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	expected := &types.IBCChannel{
+		PortID:                channel.CounterpartyPortID,
+		ChannelID:             msg.CounterpartyChannelID,
+		ConnectionID:          conn.CounterpartyConnectionID,
+		CounterpartyPortID:    channel.PortID,
+		CounterpartyChannelID: channel.ChannelID,
+		State:                 types.IBCStateTryOpen,
+	}
+	key := st.IBCChannelKey(expected.PortID, expected.ChannelID)
+	if res := verifyCounterpartyObject(view, conn.ClientID, &msg.Proof, key, expected); res.IsError() {
+		return res
+	}
+	if !apply {
+		return result.OK
+	}
+
+	channel.CounterpartyChannelID = msg.CounterpartyChannelID
+	channel.State = types.IBCStateOpen
+	view.SetIBCChannel(channel)
+	return result.OK
+}
+
+func (exec *IBCTxExecutor) channelOpenConfirm(view *st.StoreView, msg *types.MsgChannelOpenConfirm, apply bool) result.Result {
+	channel := view.GetIBCChannel(msg.PortID, msg.ChannelID)
+	if channel == nil {
+		return result.Error("IBC channel %v/%v not found", msg.PortID, msg.ChannelID).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	if channel.State != types.IBCStateTryOpen {
+		return result.Error("IBC channel %v/%v is in the %v state", msg.PortID, msg.ChannelID, channel.State).
+			WithErrorCode(result.CodeIBCInvalidState)
+	}
+	conn, res := getOpenConnection(view, channel.ConnectionID)
+	if res.IsError() {
+		return res
+	}
+	expected := &types.IBCChannel{
+		PortID:                channel.CounterpartyPortID,
+		ChannelID:             channel.CounterpartyChannelID,
+		ConnectionID:          conn.CounterpartyConnectionID,
+		CounterpartyPortID:    channel.PortID,
+		CounterpartyChannelID: channel.ChannelID,
+		State:                 types.IBCStateOpen,
+	}
+	key := st.IBCChannelKey(expected.PortID, expected.ChannelID)
+	if res := verifyCounterpartyObject(view, conn.ClientID, &msg.Proof, key, expected); res.IsError() {
+		return res
+	}
+	if !apply {
+		return result.OK
+	}
+
+	channel.State = types.IBCStateOpen
+	view.SetIBCChannel(channel)
+	return result.OK
+}
+
+// ---------------- Packets ---------------- //
+
+// getOpenChannel returns the channel and its connection if the channel is open
+func getOpenChannel(view *st.StoreView, portID, channelID string) (*types.IBCChannel, *types.IBCConnection, result.Result) {
+	channel := view.GetIBCChannel(portID, channelID)
+	if channel == nil {
+		return nil, nil, result.Error("IBC channel %v/%v not found", portID, channelID).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	if channel.State != types.IBCStateOpen {
+		return nil, nil, result.Error("IBC channel %v/%v is not open", portID, channelID).WithErrorCode(result.CodeIBCInvalidState)
+	}
+	conn, res := getOpenConnection(view, channel.ConnectionID)
+	if res.IsError() {
+		return nil, nil, res
+	}
+	return channel, conn, result.OK
+}
+
+func (exec *IBCTxExecutor) sendPacket(view *st.StoreView, relayer common.Address, msg *types.MsgSendPacket, apply bool) result.Result {
+	port := view.GetIBCPort(msg.SourcePort)
+	if port == nil || port.Owner != relayer {
+		return result.Error("Only the owner of IBC port %v can send packets", msg.SourcePort).WithErrorCode(result.CodeIBCUnauthorized)
+	}
+	channel, _, res := getOpenChannel(view, msg.SourcePort, msg.SourceChannel)
+	if res.IsError() {
+		return res
+	}
+	if len(msg.Data) == 0 || len(msg.Data) > types.MaxIBCPacketDataSize {
+		return result.Error("IBC packet data needs to have 1 to %v bytes", types.MaxIBCPacketDataSize).
+			WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	if !apply {
+		return result.OK
+	}
+
+	sequence := view.GetIBCNextSequenceSend(channel.PortID, channel.ChannelID)
+	view.SetIBCNextSequenceSend(channel.PortID, channel.ChannelID, sequence+1)
+	view.SetIBCPacket(&types.IBCPacket{
+		Sequence:           sequence,
+		SourcePort:         channel.PortID,
+		SourceChannel:      channel.ChannelID,
+		DestinationPort:    channel.CounterpartyPortID,
+		DestinationChannel: channel.CounterpartyChannelID,
+		Data:               msg.Data,
+		TimeoutHeight:      msg.TimeoutHeight,
+	})
+	return result.OK
+}
+
+func (exec *IBCTxExecutor) recvPacket(view *st.StoreView, msg *types.MsgRecvPacket, blockHeight uint64, apply bool) result.Result {
+	packet := &msg.Packet
+	channel, conn, res := getOpenChannel(view, packet.DestinationPort, packet.DestinationChannel)
+	if res.IsError() {
+		return res
+	}
+	if packet.SourcePort != channel.CounterpartyPortID || packet.SourceChannel != channel.CounterpartyChannelID {
+		return result.Error("IBC packet is not from the counterparty of channel %v/%v", channel.PortID, channel.ChannelID).
+			WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	if packet.TimeoutHeight != 0 && blockHeight >= packet.TimeoutHeight {
+		return result.Error("IBC packet timed out at height %v", packet.TimeoutHeight).WithErrorCode(result.CodeIBCPacketTimeout)
+	}
+	if view.GetIBCPacketReceipt(channel.PortID, channel.ChannelID, packet.Sequence) != nil {
+		return result.Error("IBC packet %v already received", packet.Sequence).WithErrorCode(result.CodeIBCInvalidState)
+	}
+	key := st.IBCPacketCommitmentKey(packet.SourcePort, packet.SourceChannel, packet.Sequence)
+	if res := verifyCounterpartyState(view, conn.ClientID, &msg.Proof, key, packet.Commitment()); res.IsError() {
+		return res
+	}
+	if !apply {
+		return result.OK
+	}
+
+	view.SetIBCPacketReceipt(packet, types.IBCAcknowledgementSuccess)
+	return result.OK
+}
+
+// getSentPacketConnection checks that the packet was sent over an open channel and is not yet
+// acknowledged or timed out, and returns the connection of the channel
+func getSentPacketConnection(view *st.StoreView, packet *types.IBCPacket) (*types.IBCConnection, result.Result) {
+	channel, conn, res := getOpenChannel(view, packet.SourcePort, packet.SourceChannel)
+	if res.IsError() {
+		return nil, res
+	}
+	if packet.DestinationPort != channel.CounterpartyPortID || packet.DestinationChannel != channel.CounterpartyChannelID {
+		return nil, result.Error("IBC packet is not sent to the counterparty of channel %v/%v", channel.PortID, channel.ChannelID).
+			WithErrorCode(result.CodeInvalidIBCMsg)
+	}
+	commitment := view.GetIBCPacketCommitment(packet.SourcePort, packet.SourceChannel, packet.Sequence)
+	if commitment == nil || !bytes.Equal(commitment, packet.Commitment()) {
+		return nil, result.Error("IBC packet %v is not pending", packet.Sequence).WithErrorCode(result.CodeIBCStateNotFound)
+	}
+	return conn, result.OK
+}
+
+func (exec *IBCTxExecutor) acknowledgePacket(view *st.StoreView, msg *types.MsgAcknowledgePacket, apply bool) result.Result {
+	packet := &msg.Packet
+	conn, res := getSentPacketConnection(view, packet)
+	if res.IsError() {
+		return res
+	}
+	key := st.IBCPacketAckKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence)
+	if res := verifyCounterpartyState(view, conn.ClientID, &msg.Proof, key, types.IBCAcknowledgementCommitment(msg.Acknowledgement)); res.IsError() {
+		return res
+	}
+	if !apply {
+		return result.OK
+	}
+
+	view.DeleteIBCPacket(packet)
+	return result.OK
+}
+
+func (exec *IBCTxExecutor) timeoutPacket(view *st.StoreView, msg *types.MsgTimeoutPacket, apply bool) result.Result {
+	packet := &msg.Packet
+	conn, res := getSentPacketConnection(view, packet)
+	if res.IsError() {
+		return res
+	}
+	if packet.TimeoutHeight == 0 || msg.Proof.Height < packet.TimeoutHeight {
+		return result.Error("IBC packet %v has not timed out at the counterparty height %v", packet.Sequence, msg.Proof.Height).
+			WithErrorCode(result.CodeIBCInvalidState)
+	}
+	key := st.IBCPacketReceiptKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence)
+	if res := verifyCounterpartyState(view, conn.ClientID, &msg.Proof, key, nil); res.IsError() {
+		return res
+	}
+	if !apply {
+		return result.OK
+	}
+
+	view.DeleteIBCPacket(packet)
+	return result.OK
+}
@@ -0,0 +1,185 @@
+package execution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*MultiSigSendTxExecutor)(nil)
+
+// ------------------------------- MultiSig Send Transaction -----------------------------------
+
+// MultiSigSendTxExecutor implements the TxExecutor interface
+type MultiSigSendTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewMultiSigSendTxExecutor creates a new instance of MultiSigSendTxExecutor
+func NewMultiSigSendTxExecutor(state *st.LedgerState) *MultiSigSendTxExecutor {
+	return &MultiSigSendTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *MultiSigSendTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	tx := transaction.(*types.MultiSigSendTx)
+
+	// Validate the multisig info, input and outputs, basic
+	res := tx.MultiSig.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+	res = tx.Input.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+	res = validateOutputsBasic(tx.Outputs)
+	if res.IsError() {
+		return res
+	}
+
+	if len(tx.Outputs) == 0 {
+		return result.Error("Invalid multiSigSendTx, Outputs are empty")
+	}
+
+	numAccountsAffected := uint64(1 + len(tx.Outputs))
+	if numAccountsAffected > types.MaxAccountsAffectedPerTx {
+		return result.Error("Trasaction modifying too many accounts. At most %v accounts are allowed per transaction",
+			types.MaxAccountsAffectedPerTx)
+	}
+
+	multiSigAddress := tx.MultiSig.Address()
+	if tx.Input.Address != multiSigAddress {
+		return result.Error("Input address %v does not match the multisig address %v",
+			tx.Input.Address.Hex(), multiSigAddress.Hex())
+	}
+
+	inputs := []types.TxInput{tx.Input}
+	accounts, res := getInputs(view, inputs)
+	if res.IsError() {
+		return res
+	}
+
+	accounts, res = getOrMakeOutputs(view, accounts, tx.Outputs)
+	if res.IsError() {
+		return res
+	}
+
+	blockHeight := view.Height() + 1
+	for _, outAcc := range accounts {
+		if outAcc.IsASmartContract() {
+			return result.Error(
+				fmt.Sprintf("Sending Theta/TFuel to a smart contract (%v) through a MultiSigSendTx transaction is not allowed", outAcc.Address))
+		}
+	}
+
+	// Check the sequence and the balance
+	acc := accounts[string(tx.Input.Address[:])]
+	if acc.Sequence+1 != tx.Input.Sequence {
+		return result.Error("ValidateInputAdvanced: Got %v, expected %v. (acc.seq=%v)",
+			tx.Input.Sequence, acc.Sequence+1, acc.Sequence).WithErrorCode(result.CodeInvalidSequence)
+	}
+	if !acc.Balance.IsGTE(tx.Input.Coins) {
+		return result.Error("Insufficient fund: balance is %v, tried to send %v",
+			acc.Balance, tx.Input.Coins).WithErrorCode(result.CodeInsufficientFund)
+	}
+
+	// Check the signatures against the threshold
+	signBytes := tx.SignBytes(chainID)
+	res = validateMultiSignatures(view, tx, signBytes)
+	if res.IsError() {
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForSendTxFee(view, transaction, tx.Fee, numAccountsAffected, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	outTotal := sumOutputs(tx.Outputs)
+	outPlusFees := outTotal.Plus(tx.Fee)
+	if !tx.Input.Coins.IsEqual(outPlusFees) {
+		return result.Error("Input total (%v) != output total + fees (%v)", tx.Input.Coins, outPlusFees)
+	}
+
+	return result.OK
+}
+
+func (exec *MultiSigSendTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.MultiSigSendTx)
+
+	inputs := []types.TxInput{tx.Input}
+	accounts, res := getInputs(view, inputs)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	accounts, res = getOrMakeOutputs(view, accounts, tx.Outputs)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	adjustByInputs(view, accounts, inputs)
+	adjustByOutputs(view, accounts, tx.Outputs)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *MultiSigSendTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.MultiSigSendTx)
+	return &core.TxInfo{
+		Address:           tx.Input.Address,
+		Sequence:          tx.Input.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *MultiSigSendTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.MultiSigSendTx)
+	fee := tx.Fee
+	numAccountsAffected := uint64(1 + len(tx.Outputs))
+
+	gasSendTxPerAccount := getRegularTxGas(exec.state) / 2
+	gasUint64 := gasSendTxPerAccount * numAccountsAffected
+	if gasUint64 < 2*gasSendTxPerAccount {
+		gasUint64 = 2 * gasSendTxPerAccount
+	}
+	gas := new(big.Int).SetUint64(gasUint64)
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
+
+// validateMultiSignatures checks that at least MultiSig.Threshold of the signers have signed
+// the transaction. Each signature is verified against the signer at the same index.
+func validateMultiSignatures(view *st.StoreView, tx *types.MultiSigSendTx, signBytes []byte) result.Result {
+	if len(tx.Signatures) != len(tx.MultiSig.Signers) {
+		return result.Error("Expected %v signature slots, got %v",
+			len(tx.MultiSig.Signers), len(tx.Signatures)).WithErrorCode(result.CodeInvalidSignature)
+	}
+
+	numValidSigs := uint(0)
+	for i, sig := range tx.Signatures {
+		if sig == nil || sig.IsEmpty() {
+			continue
+		}
+		signer := tx.MultiSig.Signers[i]
+		if !verifyTxSignature(view, sig, signer, signBytes) {
+			return result.Error("Signature verification failed for signer %v", signer.Hex()).WithErrorCode(result.CodeInvalidSignature)
+		}
+		numValidSigs++
+	}
+
+	if numValidSigs < tx.MultiSig.Threshold {
+		return result.Error("Insufficient signatures: got %v, the multisig threshold is %v",
+			numValidSigs, tx.MultiSig.Threshold).WithErrorCode(result.CodeInvalidSignature)
+	}
+
+	return result.OK
+}
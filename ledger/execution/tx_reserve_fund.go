@@ -29,7 +29,7 @@ func NewReserveFundTxExecutor(state *st.LedgerState) *ReserveFundTxExecutor {
 
 func (exec *ReserveFundTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
 	blockHeight := view.Height() + 1 // the view points to the parent of the current block
-	tx := transaction.(*types.ReserveFundTx)
+	tx := exec.castTx(transaction)
 
 	// Validate source, basic
 	res := tx.Source.ValidateBasic()
@@ -44,8 +44,8 @@ func (exec *ReserveFundTxExecutor) sanityCheck(chainID string, view *st.StoreVie
 	}
 
 	// Validate input, advanced
-	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(sourceAccount, signBytes, tx.Source, blockHeight)
+	signBytes := transaction.SignBytes(chainID)
+	res = validateInputAdvanced(view, sourceAccount, signBytes, tx.Source)
 	if res.IsError() {
 		logger.Debugf(fmt.Sprintf("validateSourceAdvanced failed on %v: %v", tx.Source.Address.Hex(), res))
 		return res
@@ -63,7 +63,7 @@ func (exec *ReserveFundTxExecutor) sanityCheck(chainID string, view *st.StoreVie
 			WithErrorCode(result.CodeInvalidFundToReserve)
 	}
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -85,11 +85,20 @@ func (exec *ReserveFundTxExecutor) sanityCheck(chainID string, view *st.StoreVie
 		return result.Error(err.Error()).WithErrorCode(result.CodeReserveFundCheckFailed)
 	}
 
+	if tx.DisputeWindow > types.MaximumServicePaymentDisputeWindow {
+		return result.Error("Dispute window cannot exceed %v blocks", types.MaximumServicePaymentDisputeWindow).
+			WithErrorCode(result.CodeReserveFundCheckFailed)
+	}
+	if tx.DisputeWindow >= duration {
+		return result.Error("Dispute window (%v) needs to be shorter than the reserve duration (%v)", tx.DisputeWindow, duration).
+			WithErrorCode(result.CodeReserveFundCheckFailed)
+	}
+
 	return result.OK
 }
 
 func (exec *ReserveFundTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
-	tx := transaction.(*types.ReserveFundTx)
+	tx := exec.castTx(transaction)
 
 	sourceAddress := tx.Source.Address
 	sourceAccount, success := getInput(view, tx.Source)
@@ -109,15 +118,19 @@ func (exec *ReserveFundTxExecutor) process(chainID string, view *st.StoreView, t
 		return common.Hash{}, result.Error("failed to charge transaction fee")
 	}
 
+	if tx.DisputeWindow > 0 {
+		view.SetReservedFundDisputeWindow(sourceAddress, reserveSequence, tx.DisputeWindow)
+	}
+
 	sourceAccount.Sequence++
 	view.SetAccount(sourceAddress, sourceAccount)
 
-	txHash := types.TxID(chainID, tx)
+	txHash := types.TxID(chainID, transaction)
 	return txHash, result.OK
 }
 
 func (exec *ReserveFundTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
-	tx := transaction.(*types.ReserveFundTx)
+	tx := exec.castTx(transaction)
 	return &core.TxInfo{
 		Address:           tx.Source.Address,
 		Sequence:          tx.Source.Sequence,
@@ -126,9 +139,26 @@ func (exec *ReserveFundTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo
 }
 
 func (exec *ReserveFundTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
-	tx := transaction.(*types.ReserveFundTx)
+	tx := exec.castTx(transaction)
 	fee := tx.Fee
 	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
 	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
 	return effectiveGasPrice
 }
+
+func (exec *ReserveFundTxExecutor) castTx(transaction types.Tx) *types.ReserveFundTxV2 {
+	if tx, ok := transaction.(*types.ReserveFundTxV2); ok {
+		return tx
+	}
+	if tx, ok := transaction.(*types.ReserveFundTx); ok {
+		return &types.ReserveFundTxV2{
+			Fee:            tx.Fee,
+			Source:         tx.Source,
+			Collateral:     tx.Collateral,
+			ResourceIDs:    tx.ResourceIDs,
+			Duration:       tx.Duration,
+			NotAfterHeight: tx.NotAfterHeight,
+		}
+	}
+	panic("Unreachable code")
+}
@@ -44,7 +44,7 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, tran
 		return result.Error("Invalid sendTx, Inputs and/or Outputs are empty")
 	}
 
-	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs))
+	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs) + len(tx.FeePayer))
 	if numAccountsAffected > types.MaxAccountsAffectedPerTx {
 		return result.Error("Trasaction modifying too many accounts. At most %v accounts are allowed per transaction",
 			types.MaxAccountsAffectedPerTx)
@@ -56,13 +56,17 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, tran
 		return res
 	}
 
-	// Get or make outputs.
-	accounts, res = getOrMakeOutputs(view, accounts, tx.Outputs)
+	// Get or make outputs. A cancellation tx sends to its own input account, which is already loaded
+	blockHeight := view.Height() + 1
+	outputs := tx.Outputs
+	if isCancellationTx(tx, blockHeight) {
+		outputs = []types.TxOutput{}
+	}
+	accounts, res = getOrMakeOutputs(view, accounts, outputs)
 	if res.IsError() {
 		return res
 	}
 
-	blockHeight := view.Height() + 1
 	if blockHeight >= common.HeightEnableSmartContract {
 		for _, outAcc := range accounts {
 			if outAcc.IsASmartContract() {
@@ -74,19 +78,31 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, tran
 
 	// Validate inputs and outputs, advanced
 	signBytes := tx.SignBytes(chainID)
-	inTotal, res := validateInputsAdvanced(accounts, signBytes, tx.Inputs, blockHeight)
+	inTotal, res := validateInputsAdvanced(view, accounts, signBytes, tx.Inputs)
 	if res.IsError() {
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForSendTxFee(tx.Fee, numAccountsAffected, blockHeight); !success {
+	if minTxFee, success := sanityCheckForSendTxFee(view, transaction, tx.Fee, numAccountsAffected, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
 
+	// For a sponsored tx, the fee is paid by the fee payer instead of the inputs
+	senders := []common.Address{}
+	for _, in := range tx.Inputs {
+		senders = append(senders, in.Address)
+	}
+	sponsored, res := validateFeePayer(view, tx.FeePayer, signBytes, tx.Fee.NoNil().TFuelWei, senders)
+	if res.IsError() {
+		return res
+	}
+
 	outTotal := sumOutputs(tx.Outputs)
 	outPlusFees := outTotal
-	outPlusFees = outTotal.Plus(tx.Fee)
+	if !sponsored {
+		outPlusFees = outTotal.Plus(tx.Fee)
+	}
 	if !inTotal.IsEqual(outPlusFees) {
 		return result.Error("Input total (%v) != output total + fees (%v)", inTotal, outPlusFees)
 	}
@@ -102,13 +118,23 @@ func (exec *SendTxExecutor) process(chainID string, view *st.StoreView, transact
 		return common.Hash{}, res
 	}
 
-	accounts, res = getOrMakeOutputs(view, accounts, tx.Outputs)
+	outputs := tx.Outputs
+	if isCancellationTx(tx, view.Height()+1) {
+		outputs = []types.TxOutput{}
+	}
+	accounts, res = getOrMakeOutputs(view, accounts, outputs)
 	if res.IsError() {
 		return common.Hash{}, res
 	}
 
 	adjustByInputs(view, accounts, tx.Inputs)
-	adjustByOutputs(view, accounts, tx.Outputs)
+	adjustByOutputs(view, accounts, outputs)
+
+	if len(tx.FeePayer) > 0 {
+		if res := chargeFeePayer(view, tx.FeePayer[0], tx.Fee); res.IsError() {
+			return common.Hash{}, res
+		}
+	}
 
 	txHash := types.TxID(chainID, tx)
 	return txHash, result.OK
@@ -126,7 +152,7 @@ func (exec *SendTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
 func (exec *SendTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
 	tx := transaction.(*types.SendTx)
 	fee := tx.Fee
-	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs))
+	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs) + len(tx.FeePayer))
 
 	gasSendTxPerAccount := getRegularTxGas(exec.state) / 2
 	gasUint64 := gasSendTxPerAccount * numAccountsAffected
@@ -137,3 +163,15 @@ func (exec *SendTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *bi
 	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
 	return effectiveGasPrice
 }
+
+// isCancellationTx returns whether the tx is a zero-value self-send, which only pays the fee
+// and bumps the sequence. It is used to cancel (replace) a pending tx with the same sequence.
+func isCancellationTx(tx *types.SendTx, blockHeight uint64) bool {
+	if blockHeight < common.HeightEnableTxCancellation {
+		return false
+	}
+	if len(tx.Inputs) != 1 || len(tx.Outputs) != 1 {
+		return false
+	}
+	return tx.Inputs[0].Address == tx.Outputs[0].Address && tx.Outputs[0].Coins.NoNil().IsZero()
+}
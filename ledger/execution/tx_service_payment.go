@@ -7,6 +7,7 @@ import (
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
 	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
 	st "github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
 )
@@ -63,22 +64,22 @@ func (exec *ServicePaymentTxExecutor) sanityCheck(chainID string, view *st.Store
 	}
 
 	// Verify source
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
 	sourceSignBytes := tx.SourceSignBytes(chainID)
-	if !tx.Source.Signature.Verify(sourceSignBytes, sourceAccount.Address) {
+	if !verifyServicePaymentSignature(view, tx.Source.Signature, sourceAccount.Address, sourceSignBytes) {
 		errMsg := fmt.Sprintf("sanityCheckForServicePaymentTx failed on source signature, addr: %v", sourceAddress.Hex())
 		logger.Infof(errMsg)
 		return result.Error(errMsg)
 	}
 
 	targetSignBytes := tx.TargetSignBytes(chainID)
-	if !tx.Target.Signature.Verify(targetSignBytes, targetAccount.Address) {
+	if !verifyServicePaymentSignature(view, tx.Target.Signature, targetAccount.Address, targetSignBytes) {
 		errMsg := fmt.Sprintf("sanityCheckForServicePaymentTx failed on target signature, addr: %v", targetAddress.Hex())
 		logger.Infof(errMsg)
 		return result.Error(errMsg)
 	}
 
-	blockHeight := view.Height() + 1 // the view points to the parent of the current block
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -96,6 +97,50 @@ func (exec *ServicePaymentTxExecutor) sanityCheck(chainID string, view *st.Store
 		return result.Error(err.Error()).WithErrorCode(result.CodeCheckTransferReservedFundFailed)
 	}
 
+	disputeWindow := view.GetReservedFundDisputeWindow(sourceAddress, reserveSequence)
+	if disputeWindow > 0 {
+		return exec.sanityCheckPendingPayment(view, tx, sourceAccount, targetAccount, disputeWindow)
+	}
+
+	return result.OK
+}
+
+// sanityCheckPendingPayment checks a service payment against a reserved fund with a dispute window. The
+// payment needs to supersede the pending payment to the same target if there is one, and the settlement
+// has to happen before the reserved fund can be released.
+func (exec *ServicePaymentTxExecutor) sanityCheckPendingPayment(view *st.StoreView, tx *types.ServicePaymentTx,
+	sourceAccount, targetAccount *types.Account, disputeWindow uint64) result.Result {
+	sourceAddress := tx.Source.Address
+	targetAddress := tx.Target.Address
+	reserveSequence := tx.ReserveSequence
+
+	settlementHeight := view.Height() + 1 + disputeWindow
+	pending := view.GetPendingServicePayment(sourceAddress, reserveSequence, targetAddress)
+	if pending != nil {
+		if tx.PaymentSequence <= pending.Payment.PaymentSequence {
+			return result.Error("Invalid payment sequence for address %v: %d, the pending payment has sequence %d",
+				targetAddress.Hex(), tx.PaymentSequence, pending.Payment.PaymentSequence).WithErrorCode(result.CodeCheckTransferReservedFundFailed)
+		}
+		settlementHeight = pending.SettlementHeight
+	}
+
+	for _, reservedFund := range sourceAccount.ReservedFunds {
+		if reservedFund.ReserveSequence != reserveSequence {
+			continue
+		}
+		releaseHeight := reservedFund.EndBlockHeight + types.ReservedFundFreezePeriodDuration
+		if settlementHeight >= releaseHeight {
+			return result.Error("The payment cannot be settled before the reserved fund is released at height %v", releaseHeight).
+				WithErrorCode(result.CodeCheckTransferReservedFundFailed)
+		}
+	}
+
+	// The fee is charged before the payment is settled
+	if !targetAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("Insufficient fund: target balance is %v, but the transaction fee is %v",
+			targetAccount.Balance, tx.Fee).WithErrorCode(result.CodeInsufficientFund)
+	}
+
 	return result.OK
 }
 
@@ -115,16 +160,64 @@ func (exec *ServicePaymentTxExecutor) process(chainID string, view *st.StoreView
 		return common.Hash{}, res
 	}
 
+	reserveSequence := tx.ReserveSequence
+	if disputeWindow := view.GetReservedFundDisputeWindow(sourceAddress, reserveSequence); disputeWindow > 0 {
+		// The payment is settled after the dispute window, see settlePendingPayments()
+		settlementHeight := view.Height() + 1 + disputeWindow
+		if pending := view.GetPendingServicePayment(sourceAddress, reserveSequence, targetAddress); pending != nil {
+			settlementHeight = pending.SettlementHeight // a later payment does not extend the dispute window
+		}
+		view.SetPendingServicePayment(&types.PendingServicePayment{
+			Payment:          *tx,
+			SettlementHeight: settlementHeight,
+		})
+
+		if !chargeFee(targetAccount, tx.Fee) {
+			return common.Hash{}, result.Error("failed to charge transaction fee")
+		}
+		view.SetAccount(targetAddress, targetAccount)
+
+		txHash := types.TxID(chainID, tx)
+		return txHash, result.OK
+	}
+
+	accCoinsMap, _, success := exec.transferPayment(view, tx, sourceAccount, targetAccount)
+	if !success {
+		return common.Hash{}, result.Error("Failed to split payment")
+	}
+	if !chargeFee(targetAccount, tx.Fee) {
+		// should charge after transfer the fund, so an empty address has some fund to pay the tx fee
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	view.SetAccount(sourceAddress, sourceAccount)
+	view.SetAccount(targetAddress, targetAccount)
+	for account := range accCoinsMap {
+		view.SetAccount(account.Address, account)
+	}
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+// transferPayment transfers the payment from the reserved fund of the source account to the target account
+// and the addresses in the split rule of the resource. The accounts are updated in place, and need to be
+// saved by the caller.
+func (exec *ServicePaymentTxExecutor) transferPayment(view *st.StoreView, tx *types.ServicePaymentTx,
+	sourceAccount, targetAccount *types.Account) (accCoinsMap map[*types.Account]types.Coins, shouldSlash bool, success bool) {
+	sourceAddress := tx.Source.Address
+	targetAddress := tx.Target.Address
+
 	resourceID := tx.ResourceID
 	splitRule := view.GetSplitRule(resourceID)
 
 	fullTransferAmount := tx.Source.Coins
 	splitSuccess, addrCoinsMap := exec.splitPayment(view, splitRule, resourceID, targetAddress, fullTransferAmount)
 	if !splitSuccess {
-		return common.Hash{}, result.Error("Failed to split payment")
+		return nil, false, false
 	}
 
-	accCoinsMap := map[*types.Account]types.Coins{}
+	accCoinsMap = map[*types.Account]types.Coins{}
 	for addr, coins := range addrCoinsMap {
 		var account *types.Account
 		if addr == targetAddress {
@@ -139,23 +232,50 @@ func (exec *ServicePaymentTxExecutor) process(chainID string, view *st.StoreView
 
 	currentBlockHeight := view.Height()
 	reserveSequence := tx.ReserveSequence
-	shouldSlash, _ := sourceAccount.TransferReservedFund(accCoinsMap, currentBlockHeight, reserveSequence, tx)
+	shouldSlash, _ = sourceAccount.TransferReservedFund(accCoinsMap, currentBlockHeight, reserveSequence, tx)
 	if shouldSlash {
 		//view.AddSlashIntent(slashIntent)
 	}
-	if !chargeFee(targetAccount, tx.Fee) {
-		// should charge after transfer the fund, so an empty address has some fund to pay the tx fee
-		return common.Hash{}, result.Error("failed to charge transaction fee")
-	}
 
-	view.SetAccount(sourceAddress, sourceAccount)
-	view.SetAccount(targetAddress, targetAccount)
-	for account := range accCoinsMap {
-		view.SetAccount(account.Address, account)
-	}
+	return accCoinsMap, shouldSlash, true
+}
 
-	txHash := types.TxID(chainID, tx)
-	return txHash, result.OK
+// settlePendingPayments settles the pending service payments whose dispute window has passed
+func (exec *ServicePaymentTxExecutor) settlePendingPayments(view *st.StoreView) []*types.Event {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	events := []*types.Event{}
+	for _, pending := range view.GetPendingServicePayments() {
+		if pending.SettlementHeight > blockHeight {
+			continue
+		}
+
+		tx := &pending.Payment
+		view.DeletePendingServicePayment(tx.Source.Address, tx.ReserveSequence, tx.Target.Address)
+
+		sourceAccount, res := getOrMakeAccountImpl(view, tx.Source.Address, false)
+		if res.IsError() {
+			logger.Warnf("Failed to settle service payment: %v", res.Message)
+			continue
+		}
+		targetAccount := getOrMakeAccount(view, tx.Target.Address)
+		accCoinsMap, shouldSlash, success := exec.transferPayment(view, tx, sourceAccount, targetAccount)
+		if !success {
+			logger.Warnf("Failed to split service payment: %v", tx)
+			continue
+		}
+		view.SetAccount(tx.Source.Address, sourceAccount)
+		view.SetAccount(tx.Target.Address, targetAccount)
+		for account := range accCoinsMap {
+			view.SetAccount(account.Address, account)
+		}
+		if shouldSlash {
+			logger.Warnf("Service payment overspends the reserved fund: %v", tx)
+			continue
+		}
+
+		events = append(events, types.NewServicePaymentSettledEvent(pending))
+	}
+	return events
 }
 
 func (exec *ServicePaymentTxExecutor) splitPayment(view *st.StoreView, splitRule *types.SplitRule, resourceID string,
@@ -222,3 +342,13 @@ func (exec *ServicePaymentTxExecutor) calculateEffectiveGasPrice(transaction typ
 	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
 	return effectiveGasPrice
 }
+
+// verifyServicePaymentSignature checks the source or target signature of a service payment. Unlike
+// the other transactions, the service payments have never accepted the v2 Ethereum tx wrapper.
+func verifyServicePaymentSignature(view *st.StoreView, sig *crypto.Signature, signer common.Address, signBytes []byte) bool {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	if blockHeight >= common.HeightEnableChainIDReplayProtection {
+		signBytes = types.ReplayProtectedSignBytes(signBytes, view.GetNumericChainID())
+	}
+	return sig.Verify(signBytes, signer)
+}
@@ -23,6 +23,9 @@ var _ TxExecutor = (*SmartContractTxExecutor)(nil)
 type SmartContractTxExecutor struct {
 	state *st.LedgerState
 	chain *blockchain.Chain
+
+	vmConfig      vm.Config
+	skipTxReceipt bool
 }
 
 // NewSmartContractTxExecutor creates a new instance of SmartContractTxExecutor
@@ -35,7 +38,7 @@ func NewSmartContractTxExecutor(chain *blockchain.Chain, state *st.LedgerState)
 
 func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
 	blockHeight := getBlockHeight(exec.state)
-	tx := transaction.(*types.SmartContractTx)
+	tx := exec.castTx(transaction)
 
 	// Validate from, basic
 	res := tx.From.ValidateBasic()
@@ -44,15 +47,19 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 	}
 
 	// Check signatures
-	signBytes := tx.SignBytes(chainID)
-	nativeSignatureValid := tx.From.Signature.Verify(signBytes, tx.From.Address)
-	if blockHeight >= common.HeightTxWrapperExtension {
-		signBytesV2 := types.ChangeEthereumTxWrapper(signBytes, 2)
-		nativeSignatureValid = nativeSignatureValid || tx.From.Signature.Verify(signBytesV2, tx.From.Address)
-	}
+	signBytes := transaction.SignBytes(chainID)
+	nativeSignatureValid := verifyTxSignature(view, tx.From.Signature, tx.From.Address, signBytes)
 
 	if !nativeSignatureValid {
-		if blockHeight < common.HeightRPCCompatibility {
+		if len(tx.FeePayer) > 0 {
+			// The ETH tx signature does not cover the fee payer
+			return result.Error("Sponsored transaction requires a native signature").
+				WithErrorCode(result.CodeInvalidSignature)
+		}
+
+		// Only the SmartContractTx can be signed as an ETH tx
+		ethTx, isEthCompatible := transaction.(*types.SmartContractTx)
+		if blockHeight < common.HeightRPCCompatibility || !isEthCompatible {
 			return result.Error("Signature verification failed, SignBytes: %v",
 				hex.EncodeToString(signBytes)).WithErrorCode(result.CodeInvalidSignature)
 		}
@@ -62,7 +69,7 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 			return result.Error("Sending Theta with ETH transaction is not allowed") // extra check, since ETH transaction only signs the TFuel part (i.e., value, gasPrice, gasLimit, etc)
 		}
 
-		ethSigningHash := tx.EthSigningHash(chainID, blockHeight)
+		ethSigningHash := ethTx.EthSigningHash(chainID, blockHeight)
 		err := crypto.ValidateEthSignature(tx.From.Address, ethSigningHash, tx.From.Signature)
 		if err != nil {
 			return result.Error("ETH Signature verification failed, SignBytes: %v, error: %v",
@@ -97,6 +104,17 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 			WithErrorCode(result.CodeInvalidValueToTransfer)
 	}
 
+	switch tx.Runtime {
+	case types.ContractRuntimeEVM:
+	case types.ContractRuntimeWASM:
+		if coins.ThetaWei.Sign() != 0 {
+			return result.Error("Sending Theta to a WASM contract is not allowed").
+				WithErrorCode(result.CodeInvalidValueToTransfer)
+		}
+	default:
+		return result.Error("Invalid contract runtime: %v", tx.Runtime)
+	}
+
 	if !sanityCheckForGasPrice(tx.GasPrice, blockHeight) {
 		minimumGasPrice := types.GetMinimumGasPrice(blockHeight)
 		return result.Error("Insufficient gas price. Gas price needs to be at least %v TFuelWei", minimumGasPrice).
@@ -118,6 +136,15 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 			WithErrorCode(result.CodeFeeLimitTooHigh)
 	}
 
+	// For a sponsored tx, the gas fee is paid by the fee payer instead of the caller
+	sponsored, res := validateFeePayer(view, tx.FeePayer, signBytes, feeLimit, []common.Address{tx.From.Address})
+	if res.IsError() {
+		return res
+	}
+	if sponsored {
+		feeLimit = big.NewInt(0)
+	}
+
 	var minimalBalance types.Coins
 	value := coins.TFuelWei      // NoNil() already guarantees value is NOT nil
 	thetaValue := coins.ThetaWei // NoNil() already guarantees value is NOT nil
@@ -143,14 +170,22 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 }
 
 func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
-	tx := transaction.(*types.SmartContractTx)
+	tx := exec.castTx(transaction)
 
 	view.ResetLogs()
 
 	// Note: for contract deployment, vm.Execute() might transfer coins from the fromAccount to the
 	//       deployed smart contract. Thus, we should call vm.Execute() before calling getInput().
 	//       Otherwise, the fromAccount returned by getInput() will have incorrect balance.
-	evmRet, contractAddr, gasUsed, evmErr := vm.Execute(exec.state.ParentBlock(), tx, view)
+	var evmRet common.Bytes
+	var contractAddr common.Address
+	var gasUsed uint64
+	var evmErr error
+	if tx.Runtime == types.ContractRuntimeWASM {
+		evmRet, contractAddr, gasUsed, evmErr = vm.ExecuteWasm(exec.state.ParentBlock(), tx.SmartContractTx(), view)
+	} else {
+		evmRet, contractAddr, gasUsed, evmErr = vm.ExecuteWithConfig(exec.state.ParentBlock(), tx.SmartContractTx(), view, exec.vmConfig)
+	}
 
 	fromAddress := tx.From.Address
 	fromAccount, success := getInput(view, tx.From)
@@ -163,7 +198,8 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 		ThetaWei: big.NewInt(int64(0)),
 		TFuelWei: feeAmount,
 	}
-	if !chargeFee(fromAccount, fee) {
+	sponsored := len(tx.FeePayer) > 0
+	if !sponsored && !chargeFee(fromAccount, fee) {
 		return common.Hash{}, result.Error("failed to charge transaction fee")
 	}
 
@@ -173,7 +209,13 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 	}
 	view.SetAccount(fromAddress, fromAccount)
 
-	txHash := types.TxID(chainID, tx)
+	if sponsored {
+		if res := chargeFeePayer(view, tx.FeePayer[0], fee); res.IsError() {
+			return common.Hash{}, res
+		}
+	}
+
+	txHash := types.TxID(chainID, transaction)
 
 	// TODO: Add tx receipt: status and events
 	logs := view.PopLogs()
@@ -181,13 +223,21 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 		// Do not record events if transaction is reverted
 		logs = nil
 	}
-	exec.chain.AddTxReceipt(tx, logs, evmRet, contractAddr, gasUsed, evmErr)
+	if !exec.skipTxReceipt {
+		exec.chain.AddTxReceipt(transaction, logs, evmRet, contractAddr, gasUsed, evmErr)
+	}
 
-	return txHash, result.OK
+	// The execution results are needed by the Executor to assemble the canonical tx receipt
+	return txHash, result.OKWith(result.Info{
+		"gasUsed": gasUsed,
+		"evmRet":  evmRet,
+		"evmErr":  evmErr,
+		"logs":    logs,
+	})
 }
 
 func (exec *SmartContractTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
-	tx := transaction.(*types.SmartContractTx)
+	tx := exec.castTx(transaction)
 	return &core.TxInfo{
 		Address:           tx.From.Address,
 		Sequence:          tx.From.Sequence,
@@ -196,6 +246,25 @@ func (exec *SmartContractTxExecutor) getTxInfo(transaction types.Tx) *core.TxInf
 }
 
 func (exec *SmartContractTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
-	tx := transaction.(*types.SmartContractTx)
+	tx := exec.castTx(transaction)
 	return tx.GasPrice
 }
+
+func (exec *SmartContractTxExecutor) castTx(transaction types.Tx) *types.SmartContractTxV2 {
+	if tx, ok := transaction.(*types.SmartContractTxV2); ok {
+		return tx
+	}
+	if tx, ok := transaction.(*types.SmartContractTx); ok {
+		return &types.SmartContractTxV2{
+			From:           tx.From,
+			To:             tx.To,
+			GasLimit:       tx.GasLimit,
+			GasPrice:       tx.GasPrice,
+			Data:           tx.Data,
+			Runtime:        types.ContractRuntimeEVM,
+			FeePayer:       tx.FeePayer,
+			NotAfterHeight: tx.NotAfterHeight,
+		}
+	}
+	panic("Unreachable code")
+}
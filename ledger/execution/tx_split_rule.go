@@ -12,6 +12,7 @@ import (
 )
 
 var _ TxExecutor = (*SplitRuleTxExecutor)(nil)
+var _ TxExecutor = (*SplitRuleRenewalTxExecutor)(nil)
 
 // ------------------------------- SplitRule Transaction -----------------------------------
 
@@ -44,12 +45,12 @@ func (exec *SplitRuleTxExecutor) sanityCheck(chainID string, view *st.StoreView,
 
 	// Validate inputs and outputs, advanced
 	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(initiatorAccount, signBytes, tx.Initiator, blockHeight)
+	res = validateInputAdvanced(view, initiatorAccount, signBytes, tx.Initiator)
 	if res.IsError() {
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -159,3 +160,113 @@ func (exec *SplitRuleTxExecutor) calculateEffectiveGasPrice(transaction types.Tx
 	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
 	return effectiveGasPrice
 }
+
+// ------------------------------- SplitRuleRenewal Transaction -----------------------------------
+
+// SplitRuleRenewalTxExecutor implements the TxExecutor interface
+type SplitRuleRenewalTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewSplitRuleRenewalTxExecutor creates a new instance of SplitRuleRenewalTxExecutor
+func NewSplitRuleRenewalTxExecutor(state *st.LedgerState) *SplitRuleRenewalTxExecutor {
+	return &SplitRuleRenewalTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *SplitRuleRenewalTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.SplitRuleRenewalTx)
+
+	res := tx.Initiator.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	initiatorAccount, res := getInput(view, tx.Initiator)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, initiatorAccount, signBytes, tx.Initiator)
+	if res.IsError() {
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+	if !initiatorAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("the contract initiator account balance is %v, but required minimal balance is %v", initiatorAccount.Balance, tx.Fee)
+	}
+
+	if tx.Duration == 0 {
+		return result.Error("Renewal duration needs to be positive")
+	}
+
+	splitRule := view.GetSplitRule(tx.ResourceID)
+	if splitRule == nil {
+		return result.Error("No split rule exists for resourceID %v", tx.ResourceID)
+	}
+	if splitRule.InitiatorAddress != tx.Initiator.Address {
+		return result.Error("Only the initiator can renew the split rule").
+			WithErrorCode(result.CodeUnauthorizedToUpdateSplitRule)
+	}
+	if view.Height() > splitRule.EndBlockHeight {
+		return result.Error("Split rule for resourceID %v has expired at height %v", tx.ResourceID, splitRule.EndBlockHeight)
+	}
+	if splitRule.EndBlockHeight+tx.Duration < splitRule.EndBlockHeight {
+		return result.Error("Renewal duration is too large")
+	}
+
+	return result.OK
+}
+
+func (exec *SplitRuleRenewalTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.SplitRuleRenewalTx)
+
+	initiatorAccount, res := getInput(view, tx.Initiator)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	splitRule := view.GetSplitRule(tx.ResourceID)
+	if splitRule == nil {
+		return common.Hash{}, result.Error("No split rule exists for resourceID %v", tx.ResourceID)
+	}
+
+	if !chargeFee(initiatorAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	splitRule.EndBlockHeight += tx.Duration
+	if !view.UpdateSplitRule(splitRule) {
+		return common.Hash{}, result.Error("failed to renew split rule")
+	}
+
+	initiatorAccount.Sequence++
+	view.SetAccount(tx.Initiator.Address, initiatorAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *SplitRuleRenewalTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.SplitRuleRenewalTx)
+	return &core.TxInfo{
+		Address:           tx.Initiator.Address,
+		Sequence:          tx.Initiator.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *SplitRuleRenewalTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.SplitRuleRenewalTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
@@ -0,0 +1,147 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*TokenRegistryTxExecutor)(nil)
+
+// ------------------------------- TokenRegistry Transaction -----------------------------------
+
+// TokenRegistryTxExecutor implements the TxExecutor interface
+type TokenRegistryTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewTokenRegistryTxExecutor creates a new instance of TokenRegistryTxExecutor
+func NewTokenRegistryTxExecutor(state *st.LedgerState) *TokenRegistryTxExecutor {
+	return &TokenRegistryTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *TokenRegistryTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.TokenRegistryTx)
+
+	res := tx.Registrar.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	registrarAccount, res := getInput(view, tx.Registrar)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, registrarAccount, signBytes, tx.Registrar)
+	if res.IsError() {
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+	if !registrarAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("Insufficient fund: registrar balance is %v, but the transaction fee is %v",
+			registrarAccount.Balance, tx.Fee).WithErrorCode(result.CodeInsufficientFund)
+	}
+
+	if err := types.ValidateTokenNamespace(tx.Namespace); err != nil {
+		return result.Error(err.Error()).WithErrorCode(result.CodeInvalidTokenMetadata)
+	}
+	if err := types.ValidateTokenSymbol(tx.Symbol); err != nil {
+		return result.Error(err.Error()).WithErrorCode(result.CodeInvalidTokenMetadata)
+	}
+	if len(tx.Name) == 0 || len(tx.Name) > types.MaxTokenNameLength {
+		return result.Error("Token name needs to have 1 to %v characters", types.MaxTokenNameLength).
+			WithErrorCode(result.CodeInvalidTokenMetadata)
+	}
+	if tx.Decimals > types.MaxTokenDecimals {
+		return result.Error("Token decimals cannot exceed %v", types.MaxTokenDecimals).
+			WithErrorCode(result.CodeInvalidTokenMetadata)
+	}
+
+	contractAccount := view.GetAccount(tx.ContractAddress)
+	if contractAccount == nil || !contractAccount.IsASmartContract() {
+		return result.Error("%v is not a smart contract address", tx.ContractAddress.Hex()).
+			WithErrorCode(result.CodeInvalidTokenMetadata)
+	}
+
+	namespace := view.GetTokenNamespace(tx.Namespace)
+	if namespace != nil && namespace.Owner != tx.Registrar.Address {
+		return result.Error("Token namespace %v is owned by %v", tx.Namespace, namespace.Owner.Hex()).
+			WithErrorCode(result.CodeUnauthorizedToUpdateTokenNamespace)
+	}
+
+	return result.OK
+}
+
+func (exec *TokenRegistryTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1
+	tx := transaction.(*types.TokenRegistryTx)
+
+	registrarAccount, res := getInput(view, tx.Registrar)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	namespace := view.GetTokenNamespace(tx.Namespace)
+	if namespace == nil {
+		view.SetTokenNamespace(&types.TokenNamespace{
+			Name:  tx.Namespace,
+			Owner: tx.Registrar.Address,
+		})
+	} else if namespace.Owner != tx.Registrar.Address {
+		return common.Hash{}, result.Error("Token namespace %v is owned by %v", tx.Namespace, namespace.Owner.Hex()).
+			WithErrorCode(result.CodeUnauthorizedToUpdateTokenNamespace)
+	}
+
+	if !chargeFee(registrarAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	registeredHeight := blockHeight
+	if token := view.GetTokenInfo(tx.Namespace, tx.Symbol); token != nil {
+		registeredHeight = token.RegisteredHeight
+	}
+	view.SetTokenInfo(&types.TokenInfo{
+		Namespace:        tx.Namespace,
+		Symbol:           tx.Symbol,
+		Name:             tx.Name,
+		Decimals:         tx.Decimals,
+		ContractAddress:  tx.ContractAddress,
+		RegisteredHeight: registeredHeight,
+	})
+
+	registrarAccount.Sequence++
+	view.SetAccount(tx.Registrar.Address, registrarAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *TokenRegistryTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.TokenRegistryTx)
+	return &core.TxInfo{
+		Address:           tx.Registrar.Address,
+		Sequence:          tx.Registrar.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *TokenRegistryTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.TokenRegistryTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
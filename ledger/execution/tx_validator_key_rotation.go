@@ -0,0 +1,138 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*ValidatorKeyRotationTxExecutor)(nil)
+
+// ------------------------------- ValidatorKeyRotation Transaction -----------------------------------
+
+// ValidatorKeyRotationTxExecutor implements the TxExecutor interface
+type ValidatorKeyRotationTxExecutor struct {
+	state     *st.LedgerState
+	consensus core.ConsensusEngine
+}
+
+// NewValidatorKeyRotationTxExecutor creates a new instance of ValidatorKeyRotationTxExecutor
+func NewValidatorKeyRotationTxExecutor(state *st.LedgerState, consensus core.ConsensusEngine) *ValidatorKeyRotationTxExecutor {
+	return &ValidatorKeyRotationTxExecutor{
+		state:     state,
+		consensus: consensus,
+	}
+}
+
+func (exec *ValidatorKeyRotationTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.ValidatorKeyRotationTx)
+
+	res := tx.Validator.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	validatorAccount, res := getInput(view, tx.Validator)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, validatorAccount, signBytes, tx.Validator)
+	if res.IsError() {
+		return res
+	}
+
+	if tx.SigningAddress == (common.Address{}) {
+		return result.Error("Signing address is not specified").WithErrorCode(result.CodeInvalidSignature)
+	}
+	if !verifyTxSignature(view, tx.SigningKeySignature, tx.SigningAddress, signBytes) {
+		return result.Error("Signature verification failed for the signing key %v", tx.SigningAddress.Hex()).
+			WithErrorCode(result.CodeInvalidSignature)
+	}
+
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+	if !validatorAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("Insufficient fund: validator balance is %v, but the transaction fee is %v",
+			validatorAccount.Balance, tx.Fee).WithErrorCode(result.CodeInsufficientFund)
+	}
+
+	vcp := view.GetValidatorCandidatePool()
+	if vcp == nil || vcp.FindStakeDelegate(tx.Validator.Address) == nil {
+		return result.Error("%v is not a validator candidate", tx.Validator.Address.Hex()).
+			WithErrorCode(result.CodeNotAValidatorCandidate)
+	}
+
+	minActivationEpoch := exec.currentEpoch() + types.MinimumValidatorKeyActivationDelay
+	if tx.ActivationEpoch < minActivationEpoch {
+		return result.Error("The activation epoch needs to be at least %v", minActivationEpoch).
+			WithErrorCode(result.CodeInvalidKeyActivationEpoch)
+	}
+
+	return result.OK
+}
+
+func (exec *ValidatorKeyRotationTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.ValidatorKeyRotationTx)
+
+	validatorAccount, res := getInput(view, tx.Validator)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	if !chargeFee(validatorAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	vsk := view.GetValidatorSigningKeys(tx.Validator.Address)
+	if vsk == nil {
+		vsk = &types.ValidatorSigningKeys{Validator: tx.Validator.Address}
+	}
+	vsk.Rotate(types.ValidatorSigningKey{
+		SigningAddress:  tx.SigningAddress,
+		ActivationEpoch: tx.ActivationEpoch,
+	}, exec.currentEpoch())
+	view.SetValidatorSigningKeys(vsk)
+
+	validatorAccount.Sequence++
+	view.SetAccount(tx.Validator.Address, validatorAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+// currentEpoch returns the epoch of the block being proposed or applied, or the epoch of the
+// consensus engine when the transaction is screened for the mempool
+func (exec *ValidatorKeyRotationTxExecutor) currentEpoch() uint64 {
+	if ledger := exec.consensus.GetLedger(); ledger != nil {
+		if block := ledger.GetCurrentBlock(); block != nil {
+			return block.Epoch
+		}
+	}
+	return exec.consensus.GetEpoch()
+}
+
+func (exec *ValidatorKeyRotationTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.ValidatorKeyRotationTx)
+	return &core.TxInfo{
+		Address:           tx.Validator.Address,
+		Sequence:          tx.Validator.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *ValidatorKeyRotationTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.ValidatorKeyRotationTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
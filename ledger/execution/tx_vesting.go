@@ -0,0 +1,225 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*VestingTransferTxExecutor)(nil)
+var _ TxExecutor = (*VestingClaimTxExecutor)(nil)
+
+// ------------------------------- VestingTransfer Transaction -----------------------------------
+
+// VestingTransferTxExecutor implements the TxExecutor interface
+type VestingTransferTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewVestingTransferTxExecutor creates a new instance of VestingTransferTxExecutor
+func NewVestingTransferTxExecutor(state *st.LedgerState) *VestingTransferTxExecutor {
+	return &VestingTransferTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *VestingTransferTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.VestingTransferTx)
+
+	res := tx.Source.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+	if tx.Beneficiary == (common.Address{}) {
+		return result.Error("Beneficiary address cannot be empty")
+	}
+
+	sourceAccount, res := getInput(view, tx.Source)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, sourceAccount, signBytes, tx.Source)
+	if res.IsError() {
+		return res
+	}
+
+	if tx.UnlockHeight <= blockHeight && tx.UnlockTime == 0 {
+		return result.Error("Unlock height needs to be greater than the current height %v, or an unlock time needs to be specified", blockHeight)
+	}
+	if tx.VestingEndHeight != 0 && tx.VestingEndHeight < tx.UnlockHeight {
+		return result.Error("Vesting end height cannot be less than the unlock height")
+	}
+
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	lockedCoins := tx.Source.Coins.Minus(tx.Fee)
+	if !lockedCoins.IsNonnegative() || lockedCoins.IsZero() {
+		return result.Error("Amount of coins to lock needs to be positive")
+	}
+
+	return result.OK
+}
+
+func (exec *VestingTransferTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.VestingTransferTx)
+
+	sourceAccount, res := getInput(view, tx.Source)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+	if !sourceAccount.Balance.IsGTE(tx.Source.Coins) {
+		return common.Hash{}, result.Error("Insufficient fund: balance is %v, tried to lock %v",
+			sourceAccount.Balance, tx.Source.Coins).WithErrorCode(result.CodeInsufficientFund)
+	}
+
+	txHash := types.TxID(chainID, tx)
+	fund := &types.VestingFund{
+		ID:               txHash,
+		Source:           tx.Source.Address,
+		Beneficiary:      tx.Beneficiary,
+		Total:            tx.Source.Coins.Minus(tx.Fee),
+		Claimed:          types.NewCoins(0, 0),
+		UnlockHeight:     tx.UnlockHeight,
+		UnlockTime:       tx.UnlockTime,
+		VestingEndHeight: tx.VestingEndHeight,
+	}
+	view.SetVestingFund(fund)
+
+	sourceAccount.Balance = sourceAccount.Balance.Minus(tx.Source.Coins)
+	sourceAccount.Sequence++
+	view.SetAccount(tx.Source.Address, sourceAccount)
+
+	return txHash, result.OK
+}
+
+func (exec *VestingTransferTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.VestingTransferTx)
+	return &core.TxInfo{
+		Address:           tx.Source.Address,
+		Sequence:          tx.Source.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *VestingTransferTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.VestingTransferTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
+
+// ------------------------------- VestingClaim Transaction -----------------------------------
+
+// VestingClaimTxExecutor implements the TxExecutor interface
+type VestingClaimTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewVestingClaimTxExecutor creates a new instance of VestingClaimTxExecutor
+func NewVestingClaimTxExecutor(state *st.LedgerState) *VestingClaimTxExecutor {
+	return &VestingClaimTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *VestingClaimTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.VestingClaimTx)
+
+	res := tx.Beneficiary.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	beneficiaryAccount, res := getInput(view, tx.Beneficiary)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(view, beneficiaryAccount, signBytes, tx.Beneficiary)
+	if res.IsError() {
+		return res
+	}
+
+	if minTxFee, success := sanityCheckForFee(view, transaction, tx.Fee, blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+	if !tx.Beneficiary.Coins.IsEqual(tx.Fee) {
+		return result.Error("Beneficiary coins (%v) need to be equal to the fee (%v)", tx.Beneficiary.Coins, tx.Fee)
+	}
+
+	fund := view.GetVestingFund(tx.Beneficiary.Address, tx.FundID)
+	if fund == nil {
+		return result.Error("Vesting fund %v of %v does not exist", tx.FundID.Hex(), tx.Beneficiary.Address.Hex())
+	}
+	claimable := fund.ClaimableAmount(blockHeight, getBlockTime(exec.state))
+	if claimable.IsZero() {
+		return result.Error("No vested coins to claim from vesting fund %v", tx.FundID.Hex())
+	}
+
+	return result.OK
+}
+
+func (exec *VestingClaimTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1
+	tx := transaction.(*types.VestingClaimTx)
+
+	beneficiaryAccount, res := getInput(view, tx.Beneficiary)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	fund := view.GetVestingFund(tx.Beneficiary.Address, tx.FundID)
+	if fund == nil {
+		return common.Hash{}, result.Error("Vesting fund %v of %v does not exist", tx.FundID.Hex(), tx.Beneficiary.Address.Hex())
+	}
+
+	if !chargeFee(beneficiaryAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	claimable := fund.ClaimableAmount(blockHeight, getBlockTime(exec.state))
+	beneficiaryAccount.Balance = beneficiaryAccount.Balance.Plus(claimable)
+	beneficiaryAccount.Sequence++
+	view.SetAccount(tx.Beneficiary.Address, beneficiaryAccount)
+
+	fund.Claimed = fund.Claimed.Plus(claimable)
+	if fund.IsFullyClaimed() {
+		view.DeleteVestingFund(fund.Beneficiary, fund.ID)
+	} else {
+		view.SetVestingFund(fund)
+	}
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *VestingClaimTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.VestingClaimTx)
+	return &core.TxInfo{
+		Address:           tx.Beneficiary.Address,
+		Sequence:          tx.Beneficiary.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *VestingClaimTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.VestingClaimTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
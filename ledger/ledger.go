@@ -1,13 +1,16 @@
 package ledger
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"strconv"
 	"sync"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/store"
 	"github.com/thetatoken/theta/store/kvstore"
 
@@ -16,17 +19,20 @@ import (
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/common/tracing"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
 	exec "github.com/thetatoken/theta/ledger/execution"
 	"github.com/thetatoken/theta/ledger/state"
 	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/statediff"
 	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/upgrade"
 	mp "github.com/thetatoken/theta/mempool"
 	"github.com/thetatoken/theta/store/database"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "ledger"})
+var logger = util.GetLoggerForModule("ledger")
 
 var _ core.Ledger = (*Ledger)(nil)
 
@@ -44,6 +50,12 @@ type Ledger struct {
 	mu       *sync.RWMutex // Lock for accessing ledger state.
 	state    *st.LedgerState
 	executor *exec.Executor
+	upgrades *upgrade.Manager
+
+	stateDiffs *statediff.Exporter // exports the state diff of each committed block if not nil
+
+	parallelTxWorkers int        // the number of workers executing the block transactions in parallel, see parallel.go
+	blockResults      *lru.Cache // the results of the recently applied blocks, see block_results.go
 }
 
 // NewLedger creates an instance of Ledger
@@ -59,10 +71,19 @@ func NewLedger(chainID string, db database.Database, tagger st.Tagger, chain *bl
 		mu:        &sync.RWMutex{},
 		state:     state,
 		executor:  executor,
+		upgrades:  upgrade.NewManager(),
+
+		parallelTxWorkers: viper.GetInt(common.CfgExecutionParallelTxWorkers),
+		blockResults:      newBlockResultCache(viper.GetInt(common.CfgExecutionBlockResultCacheSize)),
 	}
 	return ledger
 }
 
+// SetStateDiffExporter sets the exporter of the state diffs of the committed blocks
+func (ledger *Ledger) SetStateDiffExporter(exporter *statediff.Exporter) {
+	ledger.stateDiffs = exporter
+}
+
 // State returns the state of the ledger
 func (ledger *Ledger) State() *st.LedgerState {
 	return ledger.state
@@ -196,6 +217,29 @@ func (ledger *Ledger) GetEliteEdgeNodePoolOfLastCheckpoint(blockHash common.Hash
 	}
 }
 
+// GetValidatorSigningAddress returns the address of the key the validator signs the votes and blocks of the
+// given epoch with, as of the state of the given block.
+func (ledger *Ledger) GetValidatorSigningAddress(blockHash common.Hash, validator common.Address, epoch uint64) (common.Address, error) {
+	db := ledger.state.DB()
+	store := kvstore.NewKVStore(db)
+
+	block, err := findBlock(store, blockHash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if !block.Status.IsValid() {
+		return common.Address{}, fmt.Errorf("Block %v has no valid state", blockHash.Hex())
+	}
+	storeView := st.NewStoreView(block.Height, block.StateHash, db)
+	if storeView == nil {
+		return common.Address{}, fmt.Errorf("Failed to load state for block %v", blockHash.Hex())
+	}
+	if !ledger.upgrades.IsActive(storeView, upgrade.ValidatorKeyRotation, block.Height) {
+		return validator, nil
+	}
+	return storeView.GetValidatorSigningAddress(validator, epoch), nil
+}
+
 func findBlock(store store.Store, blockHash common.Hash) (*core.ExtendedBlock, error) {
 	var block core.ExtendedBlock
 	err := store.Get(blockHash[:], &block)
@@ -217,6 +261,18 @@ func (ledger *Ledger) ScreenTxUnsafe(rawTx common.Bytes) (res result.Result) {
 	return res
 }
 
+// ResetScreenedAccountUnsafe resets the account in the screened view to its state in the checked view
+// without locking, which rolls back the transactions of the account screened since the last block.
+func (ledger *Ledger) ResetScreenedAccountUnsafe(address common.Address) {
+	key := st.AccountKey(address)
+	screened := ledger.state.Screened()
+	if value := ledger.state.Checked().Get(key); value != nil {
+		screened.Set(key, value)
+	} else {
+		screened.Delete(key)
+	}
+}
+
 // ScreenTx screens the given transaction
 func (ledger *Ledger) ScreenTx(rawTx common.Bytes) (txInfo *core.TxInfo, res result.Result) {
 	var tx types.Tx
@@ -246,6 +302,91 @@ func (ledger *Ledger) ScreenTx(rawTx common.Bytes) (txInfo *core.TxInfo, res res
 	return txInfo, res
 }
 
+// PreverifyTxSignatures verifies the signatures of the given transactions in parallel, so that
+// screening the transactions one by one afterwards doesn't verify them again
+func (ledger *Ledger) PreverifyTxSignatures(rawTxs []common.Bytes) {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+
+	ledger.preverifyTxSignatures(rawTxs)
+}
+
+// preverifyTxSignatures verifies the signatures of the given transactions in parallel. The
+// transactions failing to decode are left to the regular checks to reject.
+func (ledger *Ledger) preverifyTxSignatures(rawTxs []common.Bytes) {
+	txs := make([]types.Tx, 0, len(rawTxs))
+	for _, rawTx := range rawTxs {
+		if tx, err := types.TxFromBytes(rawTx); err == nil {
+			txs = append(txs, tx)
+		}
+	}
+	ledger.executor.PreverifyTxSignatures(txs)
+}
+
+// GetTxInfo decodes the given transaction and extracts the information used by the mempool
+// to sort the transactions, without screening the transaction.
+func (ledger *Ledger) GetTxInfo(rawTx common.Bytes) (*core.TxInfo, result.Result) {
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, result.Error("Error decoding tx: %v", err)
+	}
+	return ledger.executor.GetTxInfo(tx)
+}
+
+// GetAccountSequence returns the sequence of the given account in the screened view, which
+// also reflects the transactions already accepted by the mempool.
+func (ledger *Ledger) GetAccountSequence(address common.Address) uint64 {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+
+	account := ledger.state.Screened().GetAccount(address)
+	if account == nil {
+		return 0
+	}
+	return account.Sequence
+}
+
+// ScreenTxReplacement screens a transaction which replaces a pending transaction of the same
+// account and sequence. Since the screened view already reflects the replaced transaction, the
+// replacement is screened on a copy of the checked view instead, after replaying the pending
+// transactions of the account with lower sequences.
+func (ledger *Ledger) ScreenTxReplacement(rawTx common.Bytes, precedingRawTxs []common.Bytes) (txInfo *core.TxInfo, res result.Result) {
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, result.Error("Error decoding tx: %v", err)
+	}
+
+	if ledger.shouldSkipCheckTx(tx) {
+		return nil, result.Error("Unauthorized transaction, should skip").
+			WithErrorCode(result.CodeUnauthorizedTx)
+	}
+
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+
+	view, err := ledger.state.Checked().Copy()
+	if err != nil {
+		return nil, result.Error("Failed to copy the checked view: %v", err)
+	}
+
+	for _, precedingRawTx := range precedingRawTxs {
+		precedingTx, err := types.TxFromBytes(precedingRawTx)
+		if err != nil {
+			return nil, result.Error("Error decoding tx: %v", err)
+		}
+		if _, res = ledger.executor.ScreenTxWithView(precedingTx, view); res.IsError() {
+			return nil, result.Error("Failed to replay the preceding pending tx: %v", res.Message)
+		}
+	}
+
+	_, res = ledger.executor.ScreenTxWithView(tx, view)
+	if res.IsError() {
+		return nil, res
+	}
+
+	return ledger.executor.GetTxInfo(tx)
+}
+
 // ProposeBlockTxs collects and executes a list of transactions, which will be used to assemble the next blockl
 // It also clears these transactions from the mempool.
 func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorUpdateTxs bool) (stateRootHash common.Hash, blockRawTxs []common.Bytes, res result.Result) {
@@ -264,6 +405,7 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorU
 	defer func() { ledger.currentBlock = nil }()
 
 	view := ledger.state.Checked()
+	ledger.haltOnUnsupportedUpgrade(view, block.Height)
 
 	logger.Debugf("ProposeBlockTxs: Start adding block transactions, block.height = %v", block.Height)
 	preparationTime := time.Since(start)
@@ -284,6 +426,7 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorU
 	start = time.Now()
 
 	blockRawTxs = []common.Bytes{}
+	receipts := []*types.TxReceipt{}
 	for _, rawTxCandidate := range rawTxCandidates {
 		tx, err := types.TxFromBytes(rawTxCandidate)
 		if err != nil {
@@ -306,6 +449,9 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorU
 			continue
 		}
 		blockRawTxs = append(blockRawTxs, rawTxCandidate)
+		if receipt, ok := res.Info["receipt"].(*types.TxReceipt); ok {
+			receipts = append(receipts, receipt)
+		}
 	}
 
 	logger.Debugf("ProposeBlockTxs: block transactions executed, block.height = %v", block.Height)
@@ -322,17 +468,34 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorU
 	logger.Debugf("ProposeBlockTxs: Done, block.height = %v, preparationTime = %v, addTxsTime = %v, execTxsTime = %v, handleDelayedUpdateTime = %v",
 		block.Height, preparationTime, addTxsTime, execTxsTime, handleDelayedUpdateTime)
 
+	if ledger.upgrades.IsActive(view, upgrade.TxReceiptRoot, view.Height()+1) {
+		receiptRootHash := calculateReceiptRootHash(receipts)
+		return stateRootHash, blockRawTxs, result.OKWith(result.Info{"receiptHash": receiptRootHash})
+	}
+
 	return stateRootHash, blockRawTxs, result.OK
 }
 
 // ApplyBlockTxs applies the given block transactions. If any of the transactions failed, it returns
 // an error immediately. If all the transactions execute successfully, it then validates the state
 // root hash. If the states root hash matches the expected value, it clears the transactions from the mempool
-func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
+func (ledger *Ledger) ApplyBlockTxs(block *core.Block) (res result.Result) {
 	// Must always acquire locks in following order to avoid deadlock: mempool, ledger.
 	// Otherwise, could cause deadlock since mempool.InsertTransaction() also first acquires the mempool, and then the ledger lock
 	logger.Debugf("ApplyBlockTxs: Apply block transactions, block.height = %v", block.Height)
 
+	ctx, span := tracing.StartBlockSpan(context.Background(), "ledger.apply_block", block.Hash())
+	if span != nil {
+		span.SetAttribute("block.height", block.Height)
+		span.SetAttribute("block.num_txs", len(block.Txs))
+		defer func() {
+			if res.IsError() {
+				span.SetError(fmt.Errorf("%v", res.Message))
+			}
+			span.Finish()
+		}()
+	}
+
 	ledger.mu.Lock()
 	defer ledger.mu.Unlock()
 
@@ -343,6 +506,7 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 	expectedStateRoot := ledger.currentBlock.StateHash
 
 	view := ledger.state.Delivered()
+	ledger.haltOnUnsupportedUpgrade(view, block.Height)
 
 	// currHeight := view.Height()
 	// currStateRoot := view.Hash()
@@ -351,12 +515,28 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 		panic(fmt.Sprintf("Failed to find the parent block: %v, err: %v", block.Parent.Hex(), err))
 	}
 	parentBlock := extParentBlock.Block
+
+	parentStateRoot := view.Hash()
+	if res, ok := ledger.applyCachedBlockResult(block, parentBlock, parentStateRoot); ok {
+		return res
+	}
+	view = ledger.state.Delivered() // in case the state was reset
+
+	// The tracking ends with the view if the block is rejected, since the state is reset
+	if ledger.stateDiffs != nil {
+		view.StartStateDiffTracking()
+	}
 	logger.Debugf("ApplyBlockTxs: Start applying block transactions, block.height = %v", block.Height)
 
+	preverifyStart := time.Now()
+	_, preverifySpan := tracing.StartSpan(ctx, "ledger.preverify_signatures")
+	ledger.preverifyTxSignatures(blockRawTxs)
+	preverifySpan.Finish()
+	logger.Debugf("ApplyBlockTxs: Preverified transaction signatures, block.height = %v, time = %v", block.Height, time.Since(preverifyStart))
+
 	hasValidatorUpdate := false
-	txProcessTime := []time.Duration{}
+	txs := make([]types.Tx, 0, len(blockRawTxs))
 	for _, rawTx := range blockRawTxs {
-		start := time.Now()
 		tx, err := types.TxFromBytes(rawTx)
 		if err != nil {
 			//ledger.resetState(currHeight, currStateRoot)
@@ -368,19 +548,30 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 		} else if wtx, ok := tx.(*types.WithdrawStakeTx); ok && wtx.Purpose == core.StakeForValidator {
 			hasValidatorUpdate = true
 		}
-		_, res := ledger.executor.ExecuteTx(tx)
+		txs = append(txs, tx)
+	}
+
+	receipts := []*types.TxReceipt{}
+	executeStart := time.Now()
+	_, executeSpan := tracing.StartSpan(ctx, "ledger.execute_txs")
+	defer executeSpan.Finish()
+	for _, res := range ledger.executeTxs(view, txs) {
 		if res.IsError() {
 			//ledger.resetState(currHeight, currStateRoot)
 			ledger.resetState(parentBlock)
 			return res
 		}
-		txProcessTime = append(txProcessTime, time.Since(start))
+		if receipt, ok := res.Info["receipt"].(*types.TxReceipt); ok {
+			receipts = append(receipts, receipt)
+		}
 	}
+	txProcessTime := time.Since(executeStart)
 
+	executeSpan.Finish()
 	logger.Debugf("ApplyBlockTxs: Finish applying block transactions, block.height=%v, txProcessTime=%v", block.Height, txProcessTime)
 
 	start := time.Now()
-	ledger.handleDelayedStateUpdates(view)
+	events := ledger.handleDelayedStateUpdates(view)
 	handleDelayedUpdateTime := time.Since(start)
 
 	newStateRoot := view.Hash()
@@ -392,12 +583,49 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 			hex.EncodeToString(expectedStateRoot[:]))
 	}
 
+	if ledger.upgrades.IsActive(view, upgrade.TxReceiptRoot, block.Height) {
+		receiptRootHash := calculateReceiptRootHash(receipts)
+		if receiptRootHash != block.ReceiptHash {
+			ledger.resetState(parentBlock)
+			return result.Error("Receipt root mismatch! root: %v, exptected: %v",
+				hex.EncodeToString(receiptRootHash[:]),
+				hex.EncodeToString(block.ReceiptHash[:]))
+		}
+	}
+
+	var stateDiffs []st.StateDiff
+	if ledger.stateDiffs != nil {
+		stateDiffs = view.StopStateDiffTracking()
+	}
+
 	start = time.Now()
+	_, commitSpan := tracing.StartSpan(ctx, "state.commit")
 	ledger.state.Commit() // commit to persistent storage
+	commitSpan.Finish()
 	commitTime := time.Since(start)
 
 	logger.Debugf("ApplyBlockTxs: Committed state change, block.height = %v", block.Height)
 
+	if ledger.stateDiffs != nil {
+		ledger.stateDiffs.Export(block, stateDiffs)
+	}
+
+	if len(events) > 0 {
+		ledger.chain.AddBlockEvents(block.Hash(), events)
+	}
+
+	if len(receipts) > 0 {
+		ledger.chain.AddCanonicalTxReceipts(receipts)
+	}
+
+	ledger.cacheBlockResult(block, parentStateRoot, &blockResult{
+		hasValidatorUpdate: hasValidatorUpdate,
+		events:             events,
+		receipts:           receipts,
+		stateDiffs:         stateDiffs,
+		stateDiffTracked:   ledger.stateDiffs != nil,
+	})
+
 	go func() {
 		ledger.mempool.Lock()
 		defer ledger.mempool.Unlock()
@@ -427,6 +655,7 @@ func (ledger *Ledger) ApplyBlockTxsForChainCorrection(block *core.Block) (common
 	blockRawTxs := ledger.currentBlock.Txs
 
 	view := ledger.state.Delivered()
+	ledger.haltOnUnsupportedUpgrade(view, block.Height)
 
 	//currHeight := view.Height()
 	//currStateRoot := view.Hash()
@@ -436,6 +665,8 @@ func (ledger *Ledger) ApplyBlockTxsForChainCorrection(block *core.Block) (common
 	}
 	parentBlock := extParentBlock.Block
 
+	ledger.preverifyTxSignatures(blockRawTxs)
+
 	hasValidatorUpdate := false
 	for _, rawTx := range blockRawTxs {
 		tx, err := types.TxFromBytes(rawTx)
@@ -626,6 +857,15 @@ func (ledger *Ledger) resetState(block *core.Block) result.Result {
 	return result.OK
 }
 
+// haltOnUnsupportedUpgrade stops the node before it processes a block at or beyond the activation
+// height of an upgrade scheduled by the chain params but not implemented by this binary. Rejecting
+// the block instead would mark a valid block as invalid.
+func (ledger *Ledger) haltOnUnsupportedUpgrade(view *st.StoreView, blockHeight uint64) {
+	if err := ledger.upgrades.CheckSupported(view, blockHeight); err != nil {
+		logger.Fatalf("Halting before block %v: %v", blockHeight, err)
+	}
+}
+
 // CheckTx() should skip all the transactions that can only be initiated by the validators
 // i.e., if a regular user submits a coinbaseTx or slashTx, it should be skipped so it will not
 // get into the mempool
@@ -640,9 +880,25 @@ func (ledger *Ledger) shouldSkipCheckTx(tx types.Tx) bool {
 	}
 }
 
+// calculateReceiptRootHash calculates the root hash of the canonical receipts of the block transactions
+func calculateReceiptRootHash(receipts []*types.TxReceipt) common.Hash {
+	receiptBytes := []common.Bytes{}
+	for _, receipt := range receipts {
+		raw, err := types.ToBytes(receipt)
+		if err != nil {
+			// Should never happen
+			logger.Panic(err)
+		}
+		receiptBytes = append(receiptBytes, raw)
+	}
+	return core.CalculateRootHash(receiptBytes)
+}
+
 // handleDelayedStateUpdates handles delayed state updates, e.g. stake return, where the stake
-// is returned only after X blocks of its corresponding StakeWithdraw transaction
-func (ledger *Ledger) handleDelayedStateUpdates(view *st.StoreView) {
+// is returned only after X blocks of its corresponding StakeWithdraw transaction. It returns
+// the events emitted by the updates.
+func (ledger *Ledger) handleDelayedStateUpdates(view *st.StoreView) []*types.Event {
+	events := []*types.Event{}
 	ledger.handleValidatorStakeReturn(view)
 	ledger.handleGuardianStakeReturn(view)
 
@@ -650,6 +906,29 @@ func (ledger *Ledger) handleDelayedStateUpdates(view *st.StoreView) {
 	if blockHeight >= common.HeightEnableTheta3 {
 		ledger.handleEliteEdgeNodeStakeReturns(view)
 	}
+	if blockHeight >= common.HeightEnableSplitRuleRenewal {
+		events = append(events, ledger.handleSplitRuleExpiry(view)...)
+	}
+	if blockHeight >= common.HeightEnableServicePaymentDispute {
+		events = append(events, ledger.executor.SettleServicePayments(view)...)
+	}
+	if ledger.upgrades.IsActive(view, upgrade.Governance, blockHeight) {
+		events = append(events, ledger.executor.TallyGovernanceProposals(view)...)
+	}
+	return events
+}
+
+// handleSplitRuleExpiry removes the expired split rules, and emits an expiry event for each of them.
+// A split rule is considered expired once the parent block height passes its end block height,
+// consistent with the expiry check of the service payments.
+func (ledger *Ledger) handleSplitRuleExpiry(view *st.StoreView) []*types.Event {
+	events := []*types.Event{}
+	expiredRules := view.RemoveExpiredSplitRules(view.Height())
+	for _, splitRule := range expiredRules {
+		logger.Debugf("Split rule expired: %v", splitRule)
+		events = append(events, types.NewSplitRuleExpiredEvent(splitRule))
+	}
+	return events
 }
 
 func (ledger *Ledger) handleValidatorStakeReturn(view *st.StoreView) {
@@ -860,7 +1139,7 @@ func (ledger *Ledger) addSlashTxs(view *st.StoreView, proposer *core.Validator,
 func (ledger *Ledger) signTransaction(tx types.Tx) (*crypto.Signature, error) {
 	chainID := ledger.state.GetChainID()
 	signBytes := tx.SignBytes(chainID)
-	signature, err := ledger.consensus.PrivateKey().Sign(signBytes)
+	signature, err := ledger.consensus.Signer().Sign(signBytes)
 	if err != nil {
 		return nil, err
 	}
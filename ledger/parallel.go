@@ -0,0 +1,189 @@
+package ledger
+
+import (
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+//
+// Parallel execution of the block transactions
+//
+// The transactions of a block are first executed speculatively by a pool of workers, each on
+// its own copy of the state before the block, tracking the keys it reads and writes. The results
+// are then committed one by one in the block order. A speculative result stands if none of the
+// keys the transaction accessed was written by the transactions before it in the block, since it
+// would have executed the same way in sequence. Otherwise the transaction is executed again on
+// the actual state. Hence the resulting state is always identical to the sequential execution,
+// regardless of how the workers are scheduled.
+//
+// The transactions bound to conflict are not speculated: the system transactions, which update
+// the view beyond the state tree, and the transactions sharing an account with an earlier
+// transaction in the block according to their inputs and outputs, e.g. the consecutive
+// transactions of the same sender.
+//
+
+// minParallelBlockTxs is the min number of transactions for a block to be executed in parallel
+const minParallelBlockTxs = 4
+
+// speculation is the result of a transaction executed on a speculative copy of the state
+type speculation struct {
+	view *st.StoreView // nil if the transaction is not speculated
+	res  result.Result
+	done bool // false if the execution did not complete, e.g. it panicked on the stale state
+}
+
+// executeTxs executes the transactions of the block against the delivered view, and returns the
+// results of the transactions up to the first failed one.
+func (ledger *Ledger) executeTxs(view *st.StoreView, txs []types.Tx) []result.Result {
+	if ledger.parallelTxWorkers > 1 && len(txs) >= minParallelBlockTxs {
+		return ledger.executeTxsInParallel(view, txs)
+	}
+
+	results := make([]result.Result, 0, len(txs))
+	for _, tx := range txs {
+		_, res := ledger.executor.ExecuteTx(tx)
+		results = append(results, res)
+		if res.IsError() {
+			break
+		}
+	}
+	return results
+}
+
+func (ledger *Ledger) executeTxsInParallel(view *st.StoreView, txs []types.Tx) []result.Result {
+	speculations := ledger.speculateTxs(view, txs)
+
+	results := make([]result.Result, 0, len(txs))
+	written := make(map[string]bool) // the keys written by the transactions committed so far
+	numReexecuted := 0
+	for i, tx := range txs {
+		spec := speculations[i]
+		if spec.done && !spec.view.Accesses().Conflicts(written) {
+			results = append(results, spec.res)
+			if spec.res.IsError() {
+				break
+			}
+			for key := range spec.view.Accesses().Writes {
+				written[key] = true
+			}
+			if err := view.MergeSpeculativeCopy(spec.view); err != nil {
+				logger.Panicf("Failed to merge the speculative execution of a transaction: %v", err)
+			}
+			continue
+		}
+
+		numReexecuted++
+		view.StartAccessTracking()
+		_, res := ledger.executor.ExecuteTx(tx)
+		accesses := view.StopAccessTracking()
+		results = append(results, res)
+		if res.IsError() {
+			break
+		}
+		for key := range accesses.Writes {
+			written[key] = true
+		}
+	}
+
+	logger.Debugf("Executed %v transactions in parallel, %v of them executed again after a conflict", len(results), numReexecuted)
+	return results
+}
+
+// speculateTxs executes the transactions speculatively in parallel
+func (ledger *Ledger) speculateTxs(view *st.StoreView, txs []types.Tx) []*speculation {
+	ledger.state.GetChainID() // caches the chain ID before the concurrent reads
+
+	speculations := make([]*speculation, len(txs))
+	jobs := make(chan int, len(txs))
+	claimed := make(map[common.Address]bool)
+	for i, tx := range txs {
+		speculations[i] = &speculation{}
+		accounts, speculative := txAccounts(tx)
+		if !speculative {
+			continue
+		}
+		conflicting := false
+		for _, addr := range accounts {
+			conflicting = conflicting || claimed[addr]
+			claimed[addr] = true
+		}
+		if conflicting {
+			continue
+		}
+		copied, err := view.SpeculativeCopy()
+		if err != nil {
+			logger.Warnf("Failed to copy the state for the speculative execution: %v", err)
+			continue
+		}
+		speculations[i].view = copied
+		jobs <- i
+	}
+	close(jobs)
+
+	wg := &sync.WaitGroup{}
+	for w := 0; w < ledger.parallelTxWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				ledger.speculateTx(txs[i], speculations[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return speculations
+}
+
+func (ledger *Ledger) speculateTx(tx types.Tx, spec *speculation) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Debugf("Speculative execution of a transaction panicked, it will be executed again: %v", r)
+		}
+	}()
+	_, spec.res = ledger.executor.ExecuteTxWithView(tx, spec.view)
+	spec.done = true
+}
+
+// txAccounts returns the accounts the transaction is known to touch from its inputs and outputs,
+// and whether the transaction can be executed speculatively at all.
+func txAccounts(tx types.Tx) ([]common.Address, bool) {
+	accounts := []common.Address{}
+	addInputs := func(inputs ...types.TxInput) {
+		for _, input := range inputs {
+			accounts = append(accounts, input.Address)
+		}
+	}
+	addOutputs := func(outputs ...types.TxOutput) {
+		for _, output := range outputs {
+			if output.Address != (common.Address{}) { // the contract to deploy
+				accounts = append(accounts, output.Address)
+			}
+		}
+	}
+
+	switch tx := tx.(type) {
+	case *types.CoinbaseTx, *types.SlashTx:
+		return nil, false
+	case *types.SendTx:
+		addInputs(tx.Inputs...)
+		addInputs(tx.FeePayer...)
+		addOutputs(tx.Outputs...)
+	case *types.BatchSendTx:
+		addInputs(tx.Input)
+		addOutputs(tx.Outputs...)
+	case *types.SmartContractTx:
+		addInputs(tx.From)
+		addInputs(tx.FeePayer...)
+		addOutputs(tx.To)
+	case *types.SmartContractTxV2:
+		addInputs(tx.From)
+		addInputs(tx.FeePayer...)
+		addOutputs(tx.To)
+	}
+	return accounts, true
+}
@@ -0,0 +1,193 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/upgrade"
+)
+
+func TestExecuteTxsInParallel(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// Executes the same transactions in sequence and in parallel
+	execute := func(workers int) common.Hash {
+		chainID, ledger, _ := newTestLedger()
+		ledger.parallelTxWorkers = workers
+		accOut, accIns := prepareInitLedgerState(ledger, 5)
+
+		rawTxs := []common.Bytes{
+			newRawSendTx(chainID, 1, true, accIns[1], accIns[0], false),
+			newRawSendTx(chainID, 1, true, accIns[3], accIns[2], false),
+			newRawSendTx(chainID, 1, true, accOut, accIns[4], false),
+			newRawSendTx(chainID, 1, true, accIns[2], accIns[1], false), // shares accounts with the earlier txs
+			newRawSendTx(chainID, 2, true, accOut, accIns[0], false),
+		}
+		txs := []types.Tx{}
+		for _, rawTx := range rawTxs {
+			tx, err := types.TxFromBytes(rawTx)
+			require.Nil(err)
+			txs = append(txs, tx)
+		}
+
+		view := ledger.state.Delivered()
+		results := ledger.executeTxs(view, txs)
+		require.Equal(len(txs), len(results))
+		for _, res := range results {
+			require.True(res.IsOK(), res.Message)
+		}
+		return view.Hash()
+	}
+
+	assert.Equal(execute(0), execute(4))
+}
+
+func TestExecuteMixedBlockInParallel(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// ASM: push 0x0, sload, push 0x1, add, push 0x0, sstore, stop
+	counterCode, _ := hex.DecodeString("60005460010160005500")
+	deploymentCode, _ := hex.DecodeString("600a600c600039600a6000f3" + "60005460010160005500")
+	counterAddr := common.HexToAddress("0x00000000000000000000000000000000000c0a7e")
+	resourceID := "rid_mixed_block"
+
+	// Executes the same block in sequence and in parallel. The block mixes the transactions
+	// which write the same contract storage, read the split rules and the stakes by range, and
+	// fail in the EVM or in the sanity check.
+	execute := func(workers int) (common.Hash, []result.Result, common.Hash) {
+		chainID, ledger, _ := newTestLedger()
+		ledger.parallelTxWorkers = workers
+		ledger.executor.SetSkipTxReceipt(true)
+
+		txFee := getMinimumTxFee()
+		balance := types.NewCoins(1000000, 10000*txFee)
+		accs := []types.PrivAccount{}
+		for _, secret := range []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi"} {
+			acc := types.MakeAccWithInitBalance(secret, balance)
+			ledger.state.Delivered().SetAccount(acc.Address, &acc.Account)
+			accs = append(accs, acc)
+		}
+		staker := types.MakeAccWithInitBalance("staker", types.Coins{
+			ThetaWei: new(big.Int).Set(core.MinValidatorStakeDeposit),
+			TFuelWei: big.NewInt(10000 * txFee),
+		})
+		ledger.state.Delivered().SetAccount(staker.Address, &staker.Account)
+		ledger.state.Delivered().SetCode(counterAddr, counterCode)
+		ledger.state.Delivered().UpdateValidatorCandidatePool(&core.ValidatorCandidatePool{})
+		ledger.state.Delivered().SetUpgradeSchedule(&types.UpgradeSchedule{Upgrades: []types.ScheduledUpgrade{
+			{Name: upgrade.SmartContract, Height: 1},
+			{Name: upgrade.TxReceiptRoot, Height: 1},
+		}})
+		ledger.state.Commit()
+
+		gasPrice := types.GetMinimumGasPrice(ledger.state.Height() + 1)
+		newContractTx := func(from types.PrivAccount, to common.Address, gasLimit uint64, data common.Bytes) types.Tx {
+			tx := &types.SmartContractTx{
+				From:     types.TxInput{Address: from.Address, Sequence: 1},
+				To:       types.TxOutput{Address: to},
+				GasLimit: gasLimit,
+				GasPrice: gasPrice,
+				Data:     data,
+			}
+			tx.From.Signature = from.Sign(tx.SignBytes(chainID))
+			return tx
+		}
+		newSplitRuleTx := func(initiator types.PrivAccount, splitTo types.PrivAccount) types.Tx {
+			tx := &types.SplitRuleTx{
+				Fee:        types.NewCoins(0, txFee),
+				ResourceID: resourceID,
+				Initiator:  types.TxInput{Address: initiator.Address, Sequence: 1},
+				Splits:     []types.Split{{Address: splitTo.Address, Percentage: 30}},
+				Duration:   uint64(1000),
+			}
+			tx.Initiator.Signature = initiator.Sign(tx.SignBytes(chainID))
+			return tx
+		}
+		depositStakeTx := &types.DepositStakeTx{
+			Fee: types.NewCoins(0, txFee),
+			Source: types.TxInput{
+				Address:  staker.Address,
+				Coins:    types.Coins{ThetaWei: core.MinValidatorStakeDeposit, TFuelWei: big.NewInt(0)},
+				Sequence: 1,
+			},
+			Holder:  types.TxOutput{Address: accs[0].Address},
+			Purpose: core.StakeForValidator,
+		}
+		depositStakeTx.Source.Signature = staker.Sign(depositStakeTx.SignBytes(chainID))
+
+		sendTx, err := types.TxFromBytes(newRawSendTx(chainID, 1, true, accs[1], accs[0], false))
+		require.Nil(err)
+		trailingSendTx, err := types.TxFromBytes(newRawSendTx(chainID, 2, true, accs[1], accs[0], false))
+		require.Nil(err)
+
+		txs := []types.Tx{
+			sendTx,
+			newContractTx(accs[2], counterAddr, 100000, nil),
+			newContractTx(accs[3], counterAddr, 100000, nil), // writes the same storage slot
+			newSplitRuleTx(accs[4], accs[1]),
+			depositStakeTx,
+			newContractTx(accs[5], counterAddr, 22000, nil),                  // runs out of gas in the EVM
+			newContractTx(accs[6], common.Address{}, 200000, deploymentCode), // deploys another counter
+			newSplitRuleTx(accs[7], accs[2]),                                 // the resource already has a split rule
+			trailingSendTx,
+		}
+
+		view := ledger.state.Delivered()
+		results := ledger.executeTxs(view, txs)
+		return view.Hash(), results, view.GetState(counterAddr, common.Hash{})
+	}
+
+	seqHash, seqResults, seqCounter := execute(0)
+	parHash, parResults, parCounter := execute(4)
+
+	// The execution stops at the conflicting split rule
+	require.Equal(8, len(seqResults))
+	for i, res := range seqResults[:7] {
+		require.True(res.IsOK(), "tx %v: %v", i, res.Message)
+	}
+	assert.True(seqResults[7].IsError())
+	assert.Equal(common.BigToHash(big.NewInt(2)), seqCounter)
+
+	assert.Equal(seqHash, parHash)
+	assert.Equal(seqCounter, parCounter)
+	require.Equal(len(seqResults), len(parResults))
+	for i := range seqResults {
+		assert.Equal(seqResults[i].Code, parResults[i].Code, "tx %v", i)
+		assert.Equal(seqResults[i].Message, parResults[i].Message, "tx %v", i)
+		seqReceipt, _ := seqResults[i].Info["receipt"].(*types.TxReceipt)
+		parReceipt, _ := parResults[i].Info["receipt"].(*types.TxReceipt)
+		assert.Equal(seqReceipt, parReceipt, "tx %v", i)
+	}
+	seqOOGReceipt := seqResults[5].Info["receipt"].(*types.TxReceipt)
+	assert.Equal(types.TxReceiptStatusFailed, seqOOGReceipt.Status)
+}
+
+func TestTxAccounts(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x0000000000000000000000000000000000000002")
+
+	accounts, speculative := txAccounts(&types.SmartContractTx{
+		From: types.TxInput{Address: from},
+		To:   types.TxOutput{Address: to},
+	})
+	assert.True(speculative)
+	assert.Equal([]common.Address{from, to}, accounts)
+
+	// Contract deployment
+	accounts, _ = txAccounts(&types.SmartContractTx{From: types.TxInput{Address: from}})
+	assert.Equal([]common.Address{from}, accounts)
+
+	_, speculative = txAccounts(&types.CoinbaseTx{})
+	assert.False(speculative)
+}
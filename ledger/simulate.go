@@ -0,0 +1,32 @@
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	exec "github.com/thetatoken/theta/ledger/execution"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// SimulateTx executes the given transaction on top of the state of the given block, as if it were
+// included in the next block. The execution runs on a private ledger state which is never committed,
+// so the node state is left untouched. It returns the would-be receipt of the transaction and the
+// changes it would make to the ledger state. The returned result reports whether the transaction is
+// valid, while the error reports the failure to set up the execution.
+func (ledger *Ledger) SimulateTx(tx types.Tx, block *core.Block) (*types.TxReceipt, []st.StateDiff, result.Result, error) {
+	simState := st.NewLedgerState(ledger.state.GetChainID(), ledger.db, nil)
+	if res := simState.ResetState(block); res.IsError() {
+		return nil, nil, res, fmt.Errorf("The state at height %v is not available, it might have been pruned", block.Height)
+	}
+	executor := exec.NewExecutor(ledger.db, ledger.chain, simState, ledger.consensus, ledger.valMgr)
+	executor.SetSkipTxReceipt(true)
+
+	view := simState.Delivered()
+	view.StartStateDiffTracking()
+	_, res, receipt := executor.SimulateTx(tx)
+	stateDiffs := view.StopStateDiffTracking()
+
+	return receipt, stateDiffs, res, nil
+}
@@ -0,0 +1,144 @@
+package state
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/store/cache"
+	"github.com/thetatoken/theta/store/trie"
+)
+
+//
+// ------------------------- State Caches -------------------------
+//
+// Reading an account from the state tree takes a lookup in the database for every trie node on
+// its path, unless the node is in the trie node cache. On top of that, the encoded accounts of the
+// committed states are cached by state root and address, so that a view reads the accounts not
+// modified since it was opened or last saved without traversing the tree at all. The accounts a
+// view reads and writes are written through to the cache under the new state root when it saves.
+//
+
+// accountCacheShare is the share of the state cache memory budget for the accounts, the rest is for the trie nodes
+const accountCacheShare = 4
+
+var (
+	accounts         = cache.New("state/accountcache", 0)
+	accountCacheSize int
+)
+
+type accountCacheKey struct {
+	root common.Hash
+	addr common.Address
+}
+
+// SetCacheSize sets the memory budget of the state caches in bytes, 0 to disable them
+func SetCacheSize(size int) {
+	accountCacheSize = size / accountCacheShare
+	accounts.Resize(accountCacheSize)
+	trie.SetNodeCacheSize(size - accountCacheSize)
+}
+
+// viewAccountCache tracks the accounts of a view that can be served by the account cache
+type viewAccountCache struct {
+	mu    sync.Mutex
+	root  common.Hash                     // the committed state root the view was opened or last saved at
+	dirty map[common.Address]bool         // the accounts written since, which are not served by the cache
+	read  map[common.Address]common.Bytes // the unmodified accounts read since, to carry over on save
+}
+
+func newViewAccountCache(root common.Hash, dirty map[common.Address]bool) *viewAccountCache {
+	if accountCacheSize <= 0 {
+		return nil
+	}
+	copiedDirty := make(map[common.Address]bool, len(dirty))
+	for addr := range dirty {
+		copiedDirty[addr] = true
+	}
+	return &viewAccountCache{
+		root:  root,
+		dirty: copiedDirty,
+		read:  make(map[common.Address]common.Bytes),
+	}
+}
+
+// copyAccountCache returns the account cache tracking for a copy of the view
+func (sv *StoreView) copyAccountCache() *viewAccountCache {
+	if sv.accountCache == nil {
+		return nil
+	}
+	sv.accountCache.mu.Lock()
+	defer sv.accountCache.mu.Unlock()
+	return newViewAccountCache(sv.accountCache.root, sv.accountCache.dirty)
+}
+
+// getAccountData returns the encoded account from the account cache if it has not been modified
+// by the view, or from the state tree otherwise.
+func (sv *StoreView) getAccountData(addr common.Address) common.Bytes {
+	key := AccountKey(addr)
+	c := sv.accountCache
+	if c == nil {
+		return sv.Get(key)
+	}
+
+	c.mu.Lock()
+	root, dirty := c.root, c.dirty[addr]
+	c.mu.Unlock()
+	if dirty {
+		return sv.Get(key)
+	}
+
+	data, ok := accounts.Get(accountCacheKey{root: root, addr: addr})
+	if ok {
+		sv.recordRead(key)
+	} else {
+		data = sv.Get(key)
+		accounts.Add(accountCacheKey{root: root, addr: addr}, data)
+	}
+
+	c.mu.Lock()
+	if !c.dirty[addr] {
+		c.read[addr] = data
+	}
+	c.mu.Unlock()
+	return data
+}
+
+// markAccountDirty excludes the account stored under the key, if it is an account key, from the
+// account cache until the view is saved.
+func (sv *StoreView) markAccountDirty(key common.Bytes) {
+	c := sv.accountCache
+	if c == nil {
+		return
+	}
+	prefix := AccountKeyPrefix()
+	if len(key) != len(prefix)+common.AddressLength || !bytes.HasPrefix(key, prefix) {
+		return
+	}
+	c.mu.Lock()
+	c.dirty[common.BytesToAddress(key[len(prefix):])] = true
+	c.mu.Unlock()
+}
+
+// cacheSavedAccounts writes the accounts read and written by the view through to the account
+// cache under the state root the view is saved at.
+func (sv *StoreView) cacheSavedAccounts(root common.Hash) {
+	c := sv.accountCache
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for addr, data := range c.read {
+		if !c.dirty[addr] {
+			accounts.Add(accountCacheKey{root: root, addr: addr}, data)
+		}
+	}
+	for addr := range c.dirty {
+		accounts.Add(accountCacheKey{root: root, addr: addr}, sv.store.Get(AccountKey(addr)))
+	}
+	c.root = root
+	c.dirty = make(map[common.Address]bool)
+	c.read = make(map[common.Address]common.Bytes)
+}
@@ -0,0 +1,63 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestAccountCache(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	SetCacheSize(4 * 1024 * 1024)
+	defer SetCacheSize(0)
+
+	addr := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	newAccount := func(theta int64) *types.Account {
+		return &types.Account{
+			Address: addr,
+			Balance: types.Coins{ThetaWei: big.NewInt(theta), TFuelWei: big.NewInt(0)},
+		}
+	}
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(0, common.Hash{}, db)
+	sv.SetAccount(addr, newAccount(100))
+	root1 := sv.Save()
+
+	// The written account is cached under the saved root
+	_, ok := accounts.Get(accountCacheKey{root: root1, addr: addr})
+	assert.True(ok)
+
+	sv1 := NewStoreView(1, root1, db)
+	require.NotNil(sv1.GetAccount(addr))
+	assert.Equal(big.NewInt(100), sv1.GetAccount(addr).Balance.ThetaWei)
+
+	// A modified account is read from the state tree, while the other views are not affected
+	sv1.SetAccount(addr, newAccount(200))
+	assert.Equal(big.NewInt(200), sv1.GetAccount(addr).Balance.ThetaWei)
+	copied, err := sv1.Copy()
+	require.Nil(err)
+	assert.Equal(big.NewInt(200), copied.GetAccount(addr).Balance.ThetaWei)
+	assert.Equal(big.NewInt(100), NewStoreView(1, root1, db).GetAccount(addr).Balance.ThetaWei)
+
+	root2 := sv1.Save()
+	data, ok := accounts.Get(accountCacheKey{root: root2, addr: addr})
+	require.True(ok)
+	acc := &types.Account{}
+	require.Nil(types.FromBytes(data, acc))
+	assert.Equal(big.NewInt(200), acc.Balance.ThetaWei)
+
+	// Deleted accounts are cached as absent
+	sv1.DeleteAccount(addr)
+	assert.Nil(sv1.GetAccount(addr))
+	root3 := sv1.Save()
+	assert.Nil(NewStoreView(2, root3, db).GetAccount(addr))
+	assert.Equal(big.NewInt(200), NewStoreView(2, root2, db).GetAccount(addr).Balance.ThetaWei)
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+//
+// ------------------------- Ledger State Iterators -------------------------
+//
+// The iterators below traverse the ledger state in ascending address order starting from
+// a given address, so that the callers (e.g. the explorers, auditors and the snapshot exporter)
+// can page through the state with the address of the next entry as the cursor, without loading
+// the entire state into memory.
+//
+
+// Iterate iterates over the key/value pairs with key having prefix in ascending key order,
+// starting from the first key not less than start, and stops once cb returns false
+func (sv *StoreView) Iterate(prefix, start common.Bytes, cb func(k, v common.Bytes) bool) {
+	sv.recordRangeRead()
+	sv.store.Iterate(prefix, start, cb)
+}
+
+// IterateAccounts iterates over the accounts starting from the given address
+func (sv *StoreView) IterateAccounts(start common.Address, cb func(addr common.Address, acc *types.Account) bool) {
+	prefix := AccountKeyPrefix()
+	sv.Iterate(prefix, AccountKey(start), func(k, v common.Bytes) bool {
+		acc := &types.Account{}
+		err := types.FromBytes(v, acc)
+		if err != nil {
+			log.Panicf("Error reading account %X error: %v", v, err.Error())
+		}
+		return cb(common.BytesToAddress(k[len(prefix):]), acc)
+	})
+}
+
+// IterateReservedFunds iterates over the reserved funds of the accounts starting from the given
+// address. The accounts without reserved funds are skipped.
+func (sv *StoreView) IterateReservedFunds(start common.Address, cb func(source common.Address, funds []types.ReservedFund) bool) {
+	sv.IterateAccounts(start, func(addr common.Address, acc *types.Account) bool {
+		if len(acc.ReservedFunds) == 0 {
+			return true
+		}
+		return cb(addr, acc.ReservedFunds)
+	})
+}
+
+// IterateStakeHolders iterates over the stake holders of the given purpose, i.e. the validator
+// candidates, guardians or elite edge nodes, starting from the given holder address
+func (sv *StoreView) IterateStakeHolders(purpose uint8, start common.Address, cb func(holder *core.StakeHolder) bool) error {
+	var holders []*core.StakeHolder
+	switch purpose {
+	case core.StakeForValidator:
+		vcp := sv.GetValidatorCandidatePool()
+		if vcp == nil {
+			return nil
+		}
+		// The validator candidates are sorted by stake, re-sort them by holder address
+		holders = append(holders, vcp.SortedCandidates...)
+		sort.Slice(holders, func(i, j int) bool {
+			return bytes.Compare(holders[i].Holder[:], holders[j].Holder[:]) < 0
+		})
+	case core.StakeForGuardian:
+		gcp := sv.GetGuardianCandidatePool()
+		for _, g := range gcp.SortedGuardians { // already sorted by holder address
+			holders = append(holders, g.StakeHolder)
+		}
+	case core.StakeForEliteEdgeNode:
+		// The elite edge nodes are stored under separate keys, so they can be iterated lazily
+		sv.Iterate(EliteEdgeNodeKeyPrefix(), EliteEdgeNodeKey(start), func(k, v common.Bytes) bool {
+			een := &core.EliteEdgeNode{}
+			err := types.FromBytes(v, een)
+			if err != nil {
+				log.Panicf("Error reading elite edge node %X, error: %v", v, err.Error())
+			}
+			return cb(een.StakeHolder)
+		})
+		return nil
+	default:
+		return fmt.Errorf("Invalid stake purpose: %v", purpose)
+	}
+
+	for _, holder := range holders {
+		if bytes.Compare(holder.Holder[:], start[:]) < 0 {
+			continue
+		}
+		if !cb(holder) {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestStoreViewIterateAccounts(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+
+	addrs := []common.Address{
+		common.HexToAddress("0x0000000000000000000000000000000000000001"),
+		common.HexToAddress("0x0000000000000000000000000000000000000002"),
+		common.HexToAddress("0x0000000000000000000000000000000000000003"),
+		common.HexToAddress("0x0000000000000000000000000000000000000004"),
+	}
+	for i, addr := range addrs {
+		acc := types.NewAccount(addr)
+		acc.Balance = types.NewCoins(int64(i), 0)
+		if i%2 == 1 {
+			acc.ReservedFunds = []types.ReservedFund{{ReserveSequence: uint64(i)}}
+		}
+		sv.SetAccount(addr, acc)
+	}
+	// Should not be iterated as accounts
+	sv.Set(SplitRuleKey("resource"), common.Bytes("rule"))
+
+	visited := []common.Address{}
+	sv.IterateAccounts(common.Address{}, func(addr common.Address, acc *types.Account) bool {
+		assert.Equal(addr, acc.Address)
+		visited = append(visited, addr)
+		return true
+	})
+	assert.Equal(addrs, visited)
+
+	// Resume from the cursor, and stop once the callback returns false
+	visited = []common.Address{}
+	sv.IterateAccounts(addrs[1], func(addr common.Address, acc *types.Account) bool {
+		visited = append(visited, addr)
+		return len(visited) < 2
+	})
+	assert.Equal(addrs[1:3], visited)
+
+	sources := []common.Address{}
+	sv.IterateReservedFunds(addrs[2], func(source common.Address, funds []types.ReservedFund) bool {
+		sources = append(sources, source)
+		assert.Equal(1, len(funds))
+		return true
+	})
+	assert.Equal([]common.Address{addrs[3]}, sources)
+}
+
+func TestStoreViewIterateStakeHolders(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+
+	holder1 := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	holder2 := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	source := common.HexToAddress("0x0000000000000000000000000000000000000010")
+
+	// The validator candidates are sorted by stake, holder2 has more stake
+	vcp := &core.ValidatorCandidatePool{}
+	assert.Nil(vcp.DepositStake(source, holder1, core.MinValidatorStakeDeposit))
+	assert.Nil(vcp.DepositStake(source, holder2, new(big.Int).Mul(core.MinValidatorStakeDeposit, big.NewInt(2))))
+	sv.UpdateValidatorCandidatePool(vcp)
+
+	holders := []common.Address{}
+	err := sv.IterateStakeHolders(core.StakeForValidator, common.Address{}, func(holder *core.StakeHolder) bool {
+		holders = append(holders, holder.Holder)
+		return true
+	})
+	assert.Nil(err)
+	assert.Equal([]common.Address{holder1, holder2}, holders)
+
+	holders = []common.Address{}
+	err = sv.IterateStakeHolders(core.StakeForValidator, holder2, func(holder *core.StakeHolder) bool {
+		holders = append(holders, holder.Holder)
+		return true
+	})
+	assert.Nil(err)
+	assert.Equal([]common.Address{holder2}, holders)
+
+	err = sv.IterateStakeHolders(uint8(9), common.Address{}, func(holder *core.StakeHolder) bool {
+		return true
+	})
+	assert.NotNil(err)
+}
@@ -15,9 +15,14 @@ func ChainIDKey() common.Bytes {
 	return common.Bytes("chainid")
 }
 
+// AccountKeyPrefix returns the prefix of the account keys
+func AccountKeyPrefix() common.Bytes {
+	return common.Bytes("ls/a/")
+}
+
 // AccountKey constructs the state key for the given address
 func AccountKey(addr common.Address) common.Bytes {
-	return append(common.Bytes("ls/a/"), addr[:]...)
+	return append(AccountKeyPrefix(), addr[:]...)
 }
 
 // SplitRuleKeyPrefix returns the prefix for the split rule key
@@ -98,3 +103,187 @@ func EliteEdgeNodeStakeReturnsKey(height uint64) common.Bytes {
 func EliteEdgeNodesTotalActiveStakeKey() common.Bytes {
 	return common.Bytes("ls/eentas")
 }
+
+// VestingFundKeyPrefix returns the prefix of the vesting fund keys of the given beneficiary
+func VestingFundKeyPrefix(beneficiary common.Address) common.Bytes {
+	return append(common.Bytes("ls/vf/"), beneficiary[:]...)
+}
+
+// VestingFundKey returns the state key of a vesting fund
+func VestingFundKey(beneficiary common.Address, fundID common.Hash) common.Bytes {
+	return append(VestingFundKeyPrefix(beneficiary), fundID[:]...)
+}
+
+// ReservedFundDisputeWindowKey returns the state key of the dispute window of a reserved fund
+func ReservedFundDisputeWindowKey(source common.Address, reserveSequence uint64) common.Bytes {
+	seqStr := strconv.FormatUint(reserveSequence, 10)
+	return append(append(common.Bytes("ls/rfdw/"), source[:]...), common.Bytes("/"+seqStr)...)
+}
+
+// PendingServicePaymentKeyPrefix returns the prefix of the pending service payment keys
+func PendingServicePaymentKeyPrefix() common.Bytes {
+	return common.Bytes("ls/psp/")
+}
+
+// PendingServicePaymentKey returns the state key of the pending service payment from the reserved fund to the target
+func PendingServicePaymentKey(source common.Address, reserveSequence uint64, target common.Address) common.Bytes {
+	seqStr := strconv.FormatUint(reserveSequence, 10)
+	key := append(PendingServicePaymentKeyPrefix(), source[:]...)
+	key = append(key, common.Bytes("/"+seqStr+"/")...)
+	return append(key, target[:]...)
+}
+
+// TokenNamespaceKey returns the state key of a token registry namespace
+func TokenNamespaceKey(namespace string) common.Bytes {
+	return common.Bytes("ls/tkns/" + namespace)
+}
+
+// FeeScheduleKey returns the state key of the minimum transaction fee schedule
+func FeeScheduleKey() common.Bytes {
+	return common.Bytes("ls/fs")
+}
+
+// UpgradeScheduleKey returns the state key of the upgrade activation heights set by the chain params
+func UpgradeScheduleKey() common.Bytes {
+	return common.Bytes("ls/ups")
+}
+
+// NumericChainIDKey returns the state key of the numeric chain ID set by the genesis
+func NumericChainIDKey() common.Bytes {
+	return common.Bytes("ls/ncid")
+}
+
+// TokenInfoKeyPrefix returns the prefix of the keys of the tokens registered under the given namespace,
+// or of all the registered tokens if the namespace is empty
+func TokenInfoKeyPrefix(namespace string) common.Bytes {
+	if namespace == "" {
+		return common.Bytes("ls/tkn/")
+	}
+	return common.Bytes("ls/tkn/" + namespace + "/")
+}
+
+// TokenInfoKey returns the state key of a registered token
+func TokenInfoKey(namespace string, symbol string) common.Bytes {
+	return common.Bytes(string(TokenInfoKeyPrefix(namespace)) + symbol)
+}
+
+// ValidatorSigningKeysKey returns the state key of the signing keys announced by a validator
+func ValidatorSigningKeysKey(validator common.Address) common.Bytes {
+	return append(common.Bytes("ls/vsk/"), validator[:]...)
+}
+
+// The IBC state keys are part of the standard shared with the counterparty chains, which verify the
+// Merkle proofs of the values under these keys
+
+// IBCIdentifierCounterKey returns the state key of the counter used to generate the client,
+// connection or channel identifiers
+func IBCIdentifierCounterKey(kind string) common.Bytes {
+	return common.Bytes("ls/ibc/cnt/" + kind)
+}
+
+// IBCClientKey returns the state key of an IBC client
+func IBCClientKey(clientID string) common.Bytes {
+	return common.Bytes("ls/ibc/cl/" + clientID)
+}
+
+// IBCConsensusStateKey returns the state key of the counterparty consensus state tracked by a client
+// at a counterparty block height
+func IBCConsensusStateKey(clientID string, height uint64) common.Bytes {
+	return common.Bytes("ls/ibc/cs/" + clientID + "/" + strconv.FormatUint(height, 10))
+}
+
+// IBCConnectionKey returns the state key of an IBC connection end
+func IBCConnectionKey(connectionID string) common.Bytes {
+	return common.Bytes("ls/ibc/conn/" + connectionID)
+}
+
+// IBCPortKey returns the state key of an IBC port binding
+func IBCPortKey(portID string) common.Bytes {
+	return common.Bytes("ls/ibc/port/" + portID)
+}
+
+// IBCChannelKey returns the state key of an IBC channel end
+func IBCChannelKey(portID, channelID string) common.Bytes {
+	return common.Bytes("ls/ibc/chan/" + portID + "/" + channelID)
+}
+
+// IBCNextSequenceSendKey returns the state key of the sequence of the next packet sent over a channel
+func IBCNextSequenceSendKey(portID, channelID string) common.Bytes {
+	return common.Bytes("ls/ibc/seqs/" + portID + "/" + channelID)
+}
+
+// IBCPacketKeyPrefix returns the prefix of the keys of the packets sent over a channel and not yet
+// acknowledged or timed out
+func IBCPacketKeyPrefix(portID, channelID string) common.Bytes {
+	return common.Bytes("ls/ibc/pkt/" + portID + "/" + channelID + "/")
+}
+
+// IBCPacketKey returns the state key of a packet sent over a channel
+func IBCPacketKey(portID, channelID string, sequence uint64) common.Bytes {
+	return common.Bytes(string(IBCPacketKeyPrefix(portID, channelID)) + strconv.FormatUint(sequence, 10))
+}
+
+// IBCPacketCommitmentKey returns the state key of the commitment of a packet sent over a channel
+func IBCPacketCommitmentKey(portID, channelID string, sequence uint64) common.Bytes {
+	return common.Bytes("ls/ibc/cmt/" + portID + "/" + channelID + "/" + strconv.FormatUint(sequence, 10))
+}
+
+// IBCPacketReceiptKeyPrefix returns the prefix of the keys of the packets received over a channel
+func IBCPacketReceiptKeyPrefix(portID, channelID string) common.Bytes {
+	return common.Bytes("ls/ibc/rcpt/" + portID + "/" + channelID + "/")
+}
+
+// IBCPacketReceiptKey returns the state key of the receipt of a packet received over a channel
+func IBCPacketReceiptKey(portID, channelID string, sequence uint64) common.Bytes {
+	return common.Bytes(string(IBCPacketReceiptKeyPrefix(portID, channelID)) + strconv.FormatUint(sequence, 10))
+}
+
+// IBCPacketAckKey returns the state key of the acknowledgement commitment of a packet received over a channel
+func IBCPacketAckKey(portID, channelID string, sequence uint64) common.Bytes {
+	return common.Bytes("ls/ibc/ack/" + portID + "/" + channelID + "/" + strconv.FormatUint(sequence, 10))
+}
+
+// BandwidthRewardRecordKeyPrefix returns the prefix of the keys of the bandwidth rewards claimed from a pool
+func BandwidthRewardRecordKeyPrefix(pool common.Address) common.Bytes {
+	return append(common.Bytes("ls/bwr/"), pool[:]...)
+}
+
+// BandwidthRewardRecordKey returns the state key of the bandwidth rewards an edge node claimed from a pool
+func BandwidthRewardRecordKey(pool, edgeNode common.Address) common.Bytes {
+	return append(BandwidthRewardRecordKeyPrefix(pool), edgeNode[:]...)
+}
+
+// GovProposalCounterKey returns the state key of the number of governance proposals submitted
+func GovProposalCounterKey() common.Bytes {
+	return common.Bytes("ls/gov/cnt")
+}
+
+// GovProposalKeyPrefix returns the prefix of the governance proposal keys
+func GovProposalKeyPrefix() common.Bytes {
+	return common.Bytes("ls/gov/p/")
+}
+
+// GovProposalKey returns the state key of a governance proposal
+func GovProposalKey(proposalID uint64) common.Bytes {
+	return append(GovProposalKeyPrefix(), common.Bytes(strconv.FormatUint(proposalID, 10))...)
+}
+
+// ActiveGovProposalKeyPrefix returns the prefix of the keys indexing the proposals open for voting
+func ActiveGovProposalKeyPrefix() common.Bytes {
+	return common.Bytes("ls/gov/a/")
+}
+
+// ActiveGovProposalKey returns the state key indexing a proposal open for voting
+func ActiveGovProposalKey(proposalID uint64) common.Bytes {
+	return append(ActiveGovProposalKeyPrefix(), common.Bytes(strconv.FormatUint(proposalID, 10))...)
+}
+
+// GovVoteKeyPrefix returns the prefix of the keys of the votes on a governance proposal
+func GovVoteKeyPrefix(proposalID uint64) common.Bytes {
+	return common.Bytes("ls/gov/v/" + strconv.FormatUint(proposalID, 10) + "/")
+}
+
+// GovVoteKey returns the state key of the vote of a voter on a governance proposal
+func GovVoteKey(proposalID uint64, voter common.Address) common.Bytes {
+	return append(GovVoteKeyPrefix(proposalID), voter[:]...)
+}
@@ -0,0 +1,324 @@
+package state
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/store"
+	"github.com/thetatoken/theta/store/database"
+)
+
+//
+// ------------------------- Speculative Execution -------------------------
+//
+// A transaction can be executed speculatively on a copy of the view, e.g. in parallel with the
+// other transactions of a block. The copy tracks the keys the transaction reads and writes, so
+// that the caller can tell whether the transaction would have executed the same way on the view
+// after the transactions preceding it, and then merge its writes into the view.
+//
+
+// AccessSet records the keys of the state tree read and written since the access tracking started
+type AccessSet struct {
+	Reads     map[string]bool
+	Writes    map[string]bool
+	RangeRead bool // whether a key range was traversed, the keys of a range are not tracked individually
+}
+
+func newAccessSet() *AccessSet {
+	return &AccessSet{
+		Reads:  make(map[string]bool),
+		Writes: make(map[string]bool),
+	}
+}
+
+// Conflicts returns whether any of the keys accessed was written by others
+func (as *AccessSet) Conflicts(written map[string]bool) bool {
+	if len(written) == 0 {
+		return false
+	}
+	if as.RangeRead {
+		return true
+	}
+	for key := range as.Reads {
+		if written[key] {
+			return true
+		}
+	}
+	for key := range as.Writes {
+		if written[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// StartAccessTracking starts recording the keys accessed by the subsequent reads and writes
+func (sv *StoreView) StartAccessTracking() {
+	sv.accesses = newAccessSet()
+}
+
+// StopAccessTracking stops the access tracking, and returns the keys accessed
+func (sv *StoreView) StopAccessTracking() *AccessSet {
+	accesses := sv.accesses
+	sv.accesses = nil
+	return accesses
+}
+
+// Accesses returns the keys accessed since the access tracking started, or nil if not tracking
+func (sv *StoreView) Accesses() *AccessSet {
+	return sv.accesses
+}
+
+func (sv *StoreView) recordRead(key common.Bytes) {
+	if sv.accesses != nil {
+		sv.accesses.Reads[string(key)] = true
+	}
+}
+
+func (sv *StoreView) recordWrite(key common.Bytes) {
+	if sv.accesses != nil {
+		sv.accesses.Writes[string(key)] = true
+	}
+}
+
+func (sv *StoreView) recordRangeRead() {
+	if sv.accesses != nil {
+		sv.accesses.RangeRead = true
+	}
+}
+
+// SpeculativeCopy returns a copy of the view to execute a transaction on speculatively. The
+// accesses of the copy are tracked, and the contract storage it writes is buffered in memory
+// instead of the database, so the copies can be executed concurrently, and the result of a copy
+// can be discarded without a trace, or merged into the view with MergeSpeculativeCopy.
+func (sv *StoreView) SpeculativeCopy() (*StoreView, error) {
+	copied, err := sv.Copy()
+	if err != nil {
+		return nil, err
+	}
+	copied.storageDB = newOverlayDatabase(sv.store.GetDB())
+	copied.StartAccessTracking()
+	return copied, nil
+}
+
+// MergeSpeculativeCopy applies the writes of a speculative copy of the view, and persists the
+// contract storage the copy has written. The copy must not be used afterwards.
+func (sv *StoreView) MergeSpeculativeCopy(copied *StoreView) error {
+	accesses := copied.StopAccessTracking()
+	if overlay, ok := copied.storageDB.(*overlayDatabase); ok {
+		if err := overlay.flush(); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(accesses.Writes))
+	for key := range accesses.Writes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := copied.store.Get(common.Bytes(key))
+		if bytes.Equal(value, sv.store.Get(common.Bytes(key))) {
+			continue // e.g. reverted
+		}
+		if len(value) == 0 {
+			sv.Delete(common.Bytes(key))
+		} else {
+			sv.Set(common.Bytes(key), value)
+		}
+	}
+	return nil
+}
+
+// overlayDatabase buffers the writes to the underlying database in memory, and reads through
+// the buffered writes. The buffered writes are applied to the underlying database by flush.
+type overlayDatabase struct {
+	base database.Database
+
+	mu     sync.RWMutex
+	values map[string][]byte // nil for the deleted keys
+	refs   map[string]int
+	ops    []overlayOp // the writes in order
+}
+
+type overlayOp struct {
+	key   []byte
+	value []byte
+	del   bool
+	ref   int // +1 for a reference, -1 for a dereference
+}
+
+var _ database.Database = (*overlayDatabase)(nil)
+
+func newOverlayDatabase(base database.Database) *overlayDatabase {
+	return &overlayDatabase{
+		base:   base,
+		values: make(map[string][]byte),
+		refs:   make(map[string]int),
+	}
+}
+
+func (db *overlayDatabase) Put(key []byte, value []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.apply(overlayOp{key: common.CopyBytes(key), value: common.CopyBytes(value)})
+	return nil
+}
+
+func (db *overlayDatabase) Delete(key []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.apply(overlayOp{key: common.CopyBytes(key), del: true})
+	return nil
+}
+
+func (db *overlayDatabase) Reference(key []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.apply(overlayOp{key: common.CopyBytes(key), ref: 1})
+	return nil
+}
+
+func (db *overlayDatabase) Dereference(key []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.apply(overlayOp{key: common.CopyBytes(key), ref: -1})
+	return nil
+}
+
+func (db *overlayDatabase) apply(op overlayOp) {
+	switch {
+	case op.ref != 0:
+		db.refs[string(op.key)] += op.ref
+	case op.del:
+		db.values[string(op.key)] = nil
+	default:
+		db.values[string(op.key)] = op.value
+	}
+	db.ops = append(db.ops, op)
+}
+
+func (db *overlayDatabase) Get(key []byte) ([]byte, error) {
+	db.mu.RLock()
+	value, buffered := db.values[string(key)]
+	db.mu.RUnlock()
+	if !buffered {
+		return db.base.Get(key)
+	}
+	if value == nil {
+		return nil, store.ErrKeyNotFound
+	}
+	return common.CopyBytes(value), nil
+}
+
+func (db *overlayDatabase) Has(key []byte) (bool, error) {
+	db.mu.RLock()
+	value, buffered := db.values[string(key)]
+	db.mu.RUnlock()
+	if !buffered {
+		return db.base.Has(key)
+	}
+	return value != nil, nil
+}
+
+func (db *overlayDatabase) CountReference(key []byte) (int, error) {
+	db.mu.RLock()
+	delta := db.refs[string(key)]
+	db.mu.RUnlock()
+	ref, err := db.base.CountReference(key)
+	if err != nil && err != store.ErrKeyNotFound {
+		return 0, err
+	}
+	return ref + delta, nil
+}
+
+func (db *overlayDatabase) Close() {}
+
+func (db *overlayDatabase) NewBatch() database.Batch {
+	return &overlayBatch{db: db}
+}
+
+// flush applies the buffered writes to the underlying database in a batch
+func (db *overlayDatabase) flush() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if len(db.ops) == 0 {
+		return nil
+	}
+	batch := db.base.NewBatch()
+	for _, op := range db.ops {
+		var err error
+		switch {
+		case op.ref > 0:
+			err = batch.Reference(op.key)
+		case op.ref < 0:
+			err = batch.Dereference(op.key)
+		case op.del:
+			err = batch.Delete(op.key)
+		default:
+			err = batch.Put(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	db.values = make(map[string][]byte)
+	db.refs = make(map[string]int)
+	db.ops = nil
+	return nil
+}
+
+type overlayBatch struct {
+	db   *overlayDatabase
+	ops  []overlayOp
+	size int
+}
+
+func (b *overlayBatch) Put(key, value []byte) error {
+	b.ops = append(b.ops, overlayOp{key: common.CopyBytes(key), value: common.CopyBytes(value)})
+	b.size += len(value)
+	return nil
+}
+
+func (b *overlayBatch) Delete(key []byte) error {
+	b.ops = append(b.ops, overlayOp{key: common.CopyBytes(key), del: true})
+	b.size++
+	return nil
+}
+
+func (b *overlayBatch) Reference(key []byte) error {
+	b.ops = append(b.ops, overlayOp{key: common.CopyBytes(key), ref: 1})
+	b.size++
+	return nil
+}
+
+func (b *overlayBatch) Dereference(key []byte) error {
+	b.ops = append(b.ops, overlayOp{key: common.CopyBytes(key), ref: -1})
+	b.size++
+	return nil
+}
+
+func (b *overlayBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *overlayBatch) Write() error {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+	for _, op := range b.ops {
+		b.db.apply(op)
+	}
+	return nil
+}
+
+func (b *overlayBatch) Reset() {
+	b.ops = nil
+	b.size = 0
+}
@@ -0,0 +1,59 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestSpeculativeCopy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(1, common.Hash{}, db)
+	contract := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	slot := common.BigToHash(big.NewInt(1))
+	sv.Set(common.Bytes("k1"), common.Bytes("v1"))
+	sv.Set(common.Bytes("k2"), common.Bytes("v2"))
+	sv.CreateAccount(contract)
+	sv.Save()
+	numKeys := db.Len()
+
+	copied, err := sv.SpeculativeCopy()
+	require.Nil(err)
+	assert.Equal(common.Bytes("v1"), copied.Get(common.Bytes("k1")))
+	copied.Set(common.Bytes("k2"), common.Bytes("v2'"))
+	copied.Delete(common.Bytes("k3"))
+	copied.SetState(contract, slot, common.BigToHash(big.NewInt(10)))
+
+	accesses := copied.Accesses()
+	assert.True(accesses.Reads["k1"])
+	assert.True(accesses.Writes["k2"])
+	assert.True(accesses.Writes[string(AccountKey(contract))])
+	assert.False(accesses.Conflicts(map[string]bool{"k4": true}))
+	assert.True(accesses.Conflicts(map[string]bool{"k1": true}))
+	assert.True(accesses.Conflicts(map[string]bool{"k3": true}))
+
+	// The copy doesn't touch the view or the database
+	assert.Equal(common.Bytes("v2"), sv.Get(common.Bytes("k2")))
+	assert.Equal(common.Hash{}, sv.GetState(contract, slot))
+	assert.Equal(numKeys, db.Len())
+
+	require.Nil(sv.MergeSpeculativeCopy(copied))
+	assert.Equal(common.Bytes("v1"), sv.Get(common.Bytes("k1")))
+	assert.Equal(common.Bytes("v2'"), sv.Get(common.Bytes("k2")))
+	assert.Equal(common.BigToHash(big.NewInt(10)), sv.GetState(contract, slot))
+	assert.Equal(copied.Hash(), sv.Hash())
+
+	// A range read conflicts with any write
+	copied, err = sv.SpeculativeCopy()
+	require.Nil(err)
+	copied.Traverse(common.Bytes("k"), func(k, v common.Bytes) bool { return true })
+	assert.True(copied.Accesses().Conflicts(map[string]bool{"x": true}))
+	assert.False(copied.Accesses().Conflicts(map[string]bool{}))
+}
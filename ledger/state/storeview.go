@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"math/big"
+	"sort"
+	"strconv"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/ledger/types"
@@ -15,7 +18,7 @@ import (
 	"github.com/thetatoken/theta/store/treestore"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "ledger"})
+var logger = util.GetLoggerForModule("ledger")
 
 //
 // ------------------------- StoreView -------------------------
@@ -27,8 +30,21 @@ type StoreView struct {
 
 	coinbaseTransactinProcessed bool
 	slashIntents                []types.SlashIntent
-	refund                      uint64       // Gas refund during smart contract execution
-	logs                        []*types.Log // Temporary store of events during smart contract execution
+	refund                      uint64                         // Gas refund during smart contract execution
+	logs                        []*types.Log                   // Temporary store of events during smart contract execution
+	balancesBefore              map[common.Address]types.Coins // Balances of the accounts touched by the current tx, used for tx receipts
+	valuesBefore                map[string]common.Bytes        // Values of the keys modified since the state diff tracking started
+	accesses                    *AccessSet                     // Keys accessed since the access tracking started
+	storageDB                   database.Database              // Database of the contract storage if not the one of the state tree
+	accountCache                *viewAccountCache              // Accounts of the view served by the account cache, nil if disabled
+}
+
+// StateDiff records the change of the value stored under a key. A nil Before value indicates
+// the key is created, and a nil After value indicates the key is deleted.
+type StateDiff struct {
+	Key    common.Bytes `json:"key"`
+	Before common.Bytes `json:"before"`
+	After  common.Bytes `json:"after"`
 }
 
 // NewStoreView creates an instance of the StoreView
@@ -43,6 +59,7 @@ func NewStoreView(height uint64, root common.Hash, db database.Database) *StoreV
 		store:        store,
 		slashIntents: []types.SlashIntent{},
 		refund:       0,
+		accountCache: newViewAccountCache(root, nil),
 	}
 	return sv
 }
@@ -58,6 +75,7 @@ func (sv *StoreView) Copy() (*StoreView, error) {
 		store:        copiedStore,
 		slashIntents: []types.SlashIntent{},
 		refund:       0,
+		accountCache: sv.copyAccountCache(),
 	}
 	return copiedStoreView, nil
 }
@@ -91,11 +109,13 @@ func (sv *StoreView) Save() common.Hash {
 	if err != nil {
 		log.Panicf("Failed to save the StoreView: %v", err)
 	}
+	sv.cacheSavedAccounts(rootHash)
 	return rootHash
 }
 
 // Get returns the value corresponding to the key
 func (sv *StoreView) Get(key common.Bytes) common.Bytes {
+	sv.recordRead(key)
 	value := sv.store.Get(key)
 	return value
 }
@@ -103,6 +123,7 @@ func (sv *StoreView) Get(key common.Bytes) common.Bytes {
 // Traverse traverses the trie and calls cb callback func on every key/value pair
 // with key having prefix
 func (sv *StoreView) Traverse(prefix common.Bytes, cb func(k, v common.Bytes) bool) bool {
+	sv.recordRangeRead()
 	return sv.store.Traverse(prefix, cb)
 }
 
@@ -110,16 +131,68 @@ func (sv *StoreView) ProveVCP(vcpKey []byte, vp *core.VCPProof) error {
 	return sv.store.ProveVCP(vcpKey, vp)
 }
 
+// Prove writes the Merkle proof of the value at the key against the state root hash into proofDb
+func (sv *StoreView) Prove(key []byte, proofDb database.Putter) error {
+	return sv.store.Prove(key, proofDb)
+}
+
 // Delete removes the value corresponding to the key
 func (sv *StoreView) Delete(key common.Bytes) {
+	sv.recordWrite(key)
+	sv.recordValueBefore(key)
+	sv.markAccountDirty(key)
 	sv.store.Delete(key)
 }
 
 // Set returns the value corresponding to the key
 func (sv *StoreView) Set(key common.Bytes, value common.Bytes) {
+	sv.recordWrite(key)
+	sv.recordValueBefore(key)
+	sv.markAccountDirty(key)
 	sv.store.Set(key, value)
 }
 
+// StartStateDiffTracking starts recording the original values of the keys modified by the
+// subsequent updates
+func (sv *StoreView) StartStateDiffTracking() {
+	sv.valuesBefore = make(map[string]common.Bytes)
+}
+
+// StopStateDiffTracking stops the state diff tracking, and returns the changes of the modified
+// keys sorted by key. Keys whose values are restored, e.g. by a revert, are not included.
+func (sv *StoreView) StopStateDiffTracking() []StateDiff {
+	valuesBefore := sv.valuesBefore
+	sv.valuesBefore = nil
+
+	stateDiffs := []StateDiff{}
+	for key, before := range valuesBefore {
+		after := sv.store.Get(common.Bytes(key))
+		if bytes.Equal(before, after) {
+			continue
+		}
+		stateDiffs = append(stateDiffs, StateDiff{
+			Key:    common.Bytes(key),
+			Before: before,
+			After:  after,
+		})
+	}
+	sort.Slice(stateDiffs, func(i, j int) bool {
+		return bytes.Compare(stateDiffs[i].Key, stateDiffs[j].Key) < 0
+	})
+
+	return stateDiffs
+}
+
+func (sv *StoreView) recordValueBefore(key common.Bytes) {
+	if sv.valuesBefore == nil {
+		return
+	}
+	if _, recorded := sv.valuesBefore[string(key)]; recorded {
+		return
+	}
+	sv.valuesBefore[string(key)] = sv.store.Get(key)
+}
+
 // AddSlashIntent adds slashIntent
 func (sv *StoreView) AddSlashIntent(slashIntent types.SlashIntent) {
 	sv.slashIntents = append(sv.slashIntents, slashIntent)
@@ -147,7 +220,7 @@ func (sv *StoreView) SetCoinbaseTransactionProcessed(processed bool) {
 
 // GetAccount returns an account.
 func (sv *StoreView) GetAccount(addr common.Address) *types.Account {
-	data := sv.Get(AccountKey(addr))
+	data := sv.getAccountData(addr)
 	if data == nil || len(data) == 0 {
 		return nil
 	}
@@ -185,6 +258,7 @@ func (sv *StoreView) setAccount(addr common.Address, acc *types.Account, updateR
 		log.Panicf("Error writing account %v error: %v",
 			acc, err.Error())
 	}
+	sv.recordBalanceBefore(addr)
 	sv.Set(AccountKey(addr), accBytes)
 
 	if !updateRefCountForAccountStateTree {
@@ -204,9 +278,58 @@ func (sv *StoreView) setAccount(addr common.Address, acc *types.Account, updateR
 
 // DeleteAccount deletes an account.
 func (sv *StoreView) DeleteAccount(addr common.Address) {
+	sv.recordBalanceBefore(addr)
 	sv.Delete(AccountKey(addr))
 }
 
+// StartBalanceTracking starts recording the balances of the accounts modified by the
+// subsequent account updates
+func (sv *StoreView) StartBalanceTracking() {
+	sv.balancesBefore = make(map[common.Address]types.Coins)
+}
+
+// StopBalanceTracking stops the balance tracking, and returns the balance changes of
+// the modified accounts sorted by address
+func (sv *StoreView) StopBalanceTracking() []types.BalanceChange {
+	balancesBefore := sv.balancesBefore
+	sv.balancesBefore = nil
+
+	balanceChanges := []types.BalanceChange{}
+	for addr, before := range balancesBefore {
+		after := types.NewCoins(0, 0)
+		if acc := sv.GetAccount(addr); acc != nil {
+			after = acc.Balance.NoNil()
+		}
+		if after.IsEqual(before) {
+			continue
+		}
+		balanceChanges = append(balanceChanges, types.BalanceChange{
+			Address: addr,
+			Before:  before,
+			After:   after,
+		})
+	}
+	sort.Slice(balanceChanges, func(i, j int) bool {
+		return bytes.Compare(balanceChanges[i].Address[:], balanceChanges[j].Address[:]) < 0
+	})
+
+	return balanceChanges
+}
+
+func (sv *StoreView) recordBalanceBefore(addr common.Address) {
+	if sv.balancesBefore == nil {
+		return
+	}
+	if _, recorded := sv.balancesBefore[addr]; recorded {
+		return
+	}
+	before := types.NewCoins(0, 0)
+	if acc := sv.GetAccount(addr); acc != nil {
+		before = acc.Balance.NoNil()
+	}
+	sv.balancesBefore[addr] = before
+}
+
 // SplitRuleExists checks if a split rule associated with the given resourceID already exists
 func (sv *StoreView) SplitRuleExists(resourceID string) bool {
 	return sv.GetSplitRule(resourceID) != nil
@@ -260,6 +383,8 @@ func (sv *StoreView) SetSplitRule(resourceID string, splitRule *types.SplitRule)
 // DeleteSplitRule deletes a split rule.
 func (sv *StoreView) DeleteSplitRule(resourceID string) bool {
 	key := SplitRuleKey(resourceID)
+	sv.recordWrite(key)
+	sv.recordValueBefore(key)
 	deleted := sv.store.Delete(key)
 	return deleted
 }
@@ -269,6 +394,7 @@ func (sv *StoreView) DeleteExpiredSplitRules(currentBlockHeight uint64) bool {
 	prefix := SplitRuleKeyPrefix()
 
 	expiredKeys := []common.Bytes{}
+	sv.recordRangeRead()
 	sv.store.Traverse(prefix, func(key, value common.Bytes) bool {
 		var splitRule types.SplitRule
 		err := types.FromBytes(value, &splitRule)
@@ -284,6 +410,8 @@ func (sv *StoreView) DeleteExpiredSplitRules(currentBlockHeight uint64) bool {
 	})
 
 	for _, key := range expiredKeys {
+		sv.recordWrite(key)
+		sv.recordValueBefore(key)
 		deleted := sv.store.Delete(key)
 		if !deleted {
 			logger.Errorf("Failed to delete expired split rules")
@@ -294,6 +422,29 @@ func (sv *StoreView) DeleteExpiredSplitRules(currentBlockHeight uint64) bool {
 	return true
 }
 
+// RemoveExpiredSplitRules deletes the split rules expired before the given block height, and
+// returns the deleted split rules.
+func (sv *StoreView) RemoveExpiredSplitRules(currentBlockHeight uint64) []*types.SplitRule {
+	expiredRules := []*types.SplitRule{}
+	sv.recordRangeRead()
+	sv.store.Traverse(SplitRuleKeyPrefix(), func(key, value common.Bytes) bool {
+		splitRule := &types.SplitRule{}
+		err := types.FromBytes(value, splitRule)
+		if err != nil {
+			log.Panicf("Error reading splitRule %X error: %v", value, err.Error())
+		}
+		if splitRule.EndBlockHeight < currentBlockHeight {
+			expiredRules = append(expiredRules, splitRule)
+		}
+		return true
+	})
+
+	for _, splitRule := range expiredRules {
+		sv.DeleteSplitRule(splitRule.ResourceID)
+	}
+	return expiredRules
+}
+
 // GetValidatorCandidatePool gets the validator candidate pool.
 func (sv *StoreView) GetValidatorCandidatePool() *core.ValidatorCandidatePool {
 	data := sv.Get(ValidatorCandidatePoolKey())
@@ -406,6 +557,656 @@ func (sv *StoreView) RemoveEliteEdgeNodeStakeReturns(height uint64) {
 	sv.Delete(EliteEdgeNodeStakeReturnsKey(height))
 }
 
+// GetVestingFund gets the vesting fund with the given ID of the beneficiary
+func (sv *StoreView) GetVestingFund(beneficiary common.Address, fundID common.Hash) *types.VestingFund {
+	data := sv.Get(VestingFundKey(beneficiary, fundID))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	fund := &types.VestingFund{}
+	err := types.FromBytes(data, fund)
+	if err != nil {
+		log.Panicf("Error reading vesting fund %X, error: %v", data, err.Error())
+	}
+	return fund
+}
+
+// SetVestingFund saves the vesting fund
+func (sv *StoreView) SetVestingFund(fund *types.VestingFund) {
+	fundBytes, err := types.ToBytes(fund)
+	if err != nil {
+		log.Panicf("Error writing vesting fund %v, error: %v", fund, err.Error())
+	}
+	sv.Set(VestingFundKey(fund.Beneficiary, fund.ID), fundBytes)
+}
+
+// DeleteVestingFund deletes the vesting fund
+func (sv *StoreView) DeleteVestingFund(beneficiary common.Address, fundID common.Hash) {
+	sv.Delete(VestingFundKey(beneficiary, fundID))
+}
+
+// GetVestingFunds gets all the vesting funds of the beneficiary
+func (sv *StoreView) GetVestingFunds(beneficiary common.Address) []*types.VestingFund {
+	funds := []*types.VestingFund{}
+	sv.Traverse(VestingFundKeyPrefix(beneficiary), func(key, value common.Bytes) bool {
+		fund := &types.VestingFund{}
+		err := types.FromBytes(value, fund)
+		if err != nil {
+			log.Panicf("Error reading vesting fund %X, error: %v", value, err.Error())
+		}
+		funds = append(funds, fund)
+		return true
+	})
+	return funds
+}
+
+// GetReservedFundDisputeWindow gets the dispute window of the reserved fund, zero if the fund has no dispute window
+func (sv *StoreView) GetReservedFundDisputeWindow(source common.Address, reserveSequence uint64) uint64 {
+	data := sv.Get(ReservedFundDisputeWindowKey(source, reserveSequence))
+	if data == nil || len(data) == 0 {
+		return 0
+	}
+	var window uint64
+	err := types.FromBytes(data, &window)
+	if err != nil {
+		log.Panicf("Error reading reserved fund dispute window %X, error: %v", data, err.Error())
+	}
+	return window
+}
+
+// SetReservedFundDisputeWindow sets the dispute window of the reserved fund
+func (sv *StoreView) SetReservedFundDisputeWindow(source common.Address, reserveSequence uint64, window uint64) {
+	windowBytes, err := types.ToBytes(window)
+	if err != nil {
+		log.Panicf("Error writing reserved fund dispute window %v, error: %v", window, err.Error())
+	}
+	sv.Set(ReservedFundDisputeWindowKey(source, reserveSequence), windowBytes)
+}
+
+// GetPendingServicePayment gets the pending service payment from the reserved fund to the target
+func (sv *StoreView) GetPendingServicePayment(source common.Address, reserveSequence uint64, target common.Address) *types.PendingServicePayment {
+	data := sv.Get(PendingServicePaymentKey(source, reserveSequence, target))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	payment := &types.PendingServicePayment{}
+	err := types.FromBytes(data, payment)
+	if err != nil {
+		log.Panicf("Error reading pending service payment %X, error: %v", data, err.Error())
+	}
+	return payment
+}
+
+// SetPendingServicePayment saves the pending service payment
+func (sv *StoreView) SetPendingServicePayment(payment *types.PendingServicePayment) {
+	paymentBytes, err := types.ToBytes(payment)
+	if err != nil {
+		log.Panicf("Error writing pending service payment %v, error: %v", payment, err.Error())
+	}
+	tx := payment.Payment
+	sv.Set(PendingServicePaymentKey(tx.Source.Address, tx.ReserveSequence, tx.Target.Address), paymentBytes)
+}
+
+// DeletePendingServicePayment deletes the pending service payment
+func (sv *StoreView) DeletePendingServicePayment(source common.Address, reserveSequence uint64, target common.Address) {
+	sv.Delete(PendingServicePaymentKey(source, reserveSequence, target))
+}
+
+// GetPendingServicePayments gets all the pending service payments
+func (sv *StoreView) GetPendingServicePayments() []*types.PendingServicePayment {
+	payments := []*types.PendingServicePayment{}
+	sv.Traverse(PendingServicePaymentKeyPrefix(), func(key, value common.Bytes) bool {
+		payment := &types.PendingServicePayment{}
+		err := types.FromBytes(value, payment)
+		if err != nil {
+			log.Panicf("Error reading pending service payment %X, error: %v", value, err.Error())
+		}
+		payments = append(payments, payment)
+		return true
+	})
+	return payments
+}
+
+// GetTokenNamespace gets the token registry namespace
+func (sv *StoreView) GetTokenNamespace(namespace string) *types.TokenNamespace {
+	data := sv.Get(TokenNamespaceKey(namespace))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	ns := &types.TokenNamespace{}
+	err := types.FromBytes(data, ns)
+	if err != nil {
+		log.Panicf("Error reading token namespace %X, error: %v", data, err.Error())
+	}
+	return ns
+}
+
+// SetTokenNamespace saves the token registry namespace
+func (sv *StoreView) SetTokenNamespace(ns *types.TokenNamespace) {
+	nsBytes, err := types.ToBytes(ns)
+	if err != nil {
+		log.Panicf("Error writing token namespace %v, error: %v", ns, err.Error())
+	}
+	sv.Set(TokenNamespaceKey(ns.Name), nsBytes)
+}
+
+// GetTokenInfo gets the metadata of the token registered under the namespace
+func (sv *StoreView) GetTokenInfo(namespace string, symbol string) *types.TokenInfo {
+	data := sv.Get(TokenInfoKey(namespace, symbol))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	token := &types.TokenInfo{}
+	err := types.FromBytes(data, token)
+	if err != nil {
+		log.Panicf("Error reading token info %X, error: %v", data, err.Error())
+	}
+	return token
+}
+
+// SetTokenInfo saves the metadata of a registered token
+func (sv *StoreView) SetTokenInfo(token *types.TokenInfo) {
+	tokenBytes, err := types.ToBytes(token)
+	if err != nil {
+		log.Panicf("Error writing token info %v, error: %v", token, err.Error())
+	}
+	sv.Set(TokenInfoKey(token.Namespace, token.Symbol), tokenBytes)
+}
+
+// GetTokenInfos gets the metadata of the tokens registered under the namespace, or of all the
+// registered tokens if the namespace is empty
+func (sv *StoreView) GetTokenInfos(namespace string) []*types.TokenInfo {
+	tokens := []*types.TokenInfo{}
+	sv.Traverse(TokenInfoKeyPrefix(namespace), func(key, value common.Bytes) bool {
+		token := &types.TokenInfo{}
+		err := types.FromBytes(value, token)
+		if err != nil {
+			log.Panicf("Error reading token info %X, error: %v", value, err.Error())
+		}
+		tokens = append(tokens, token)
+		return true
+	})
+	return tokens
+}
+
+// GetFeeSchedule gets the minimum transaction fee schedule. The default schedule is returned
+// if the schedule has not been adjusted yet.
+func (sv *StoreView) GetFeeSchedule() *types.FeeSchedule {
+	data := sv.Get(FeeScheduleKey())
+	if data == nil || len(data) == 0 {
+		return types.DefaultFeeSchedule()
+	}
+	fs := &types.FeeSchedule{}
+	err := types.FromBytes(data, fs)
+	if err != nil {
+		log.Panicf("Error reading fee schedule %X, error: %v", data, err.Error())
+	}
+	return fs
+}
+
+// SetFeeSchedule saves the minimum transaction fee schedule
+func (sv *StoreView) SetFeeSchedule(fs *types.FeeSchedule) {
+	fsBytes, err := types.ToBytes(fs)
+	if err != nil {
+		log.Panicf("Error writing fee schedule %v, error: %v", fs, err.Error())
+	}
+	sv.Set(FeeScheduleKey(), fsBytes)
+}
+
+// GetUpgradeSchedule gets the upgrade activation heights set by the chain params. An empty schedule
+// is returned if the chain params do not schedule any upgrade.
+func (sv *StoreView) GetUpgradeSchedule() *types.UpgradeSchedule {
+	data := sv.Get(UpgradeScheduleKey())
+	if data == nil || len(data) == 0 {
+		return &types.UpgradeSchedule{}
+	}
+	us := &types.UpgradeSchedule{}
+	err := types.FromBytes(data, us)
+	if err != nil {
+		log.Panicf("Error reading upgrade schedule %X, error: %v", data, err.Error())
+	}
+	return us
+}
+
+// SetUpgradeSchedule saves the upgrade activation heights set by the chain params
+func (sv *StoreView) SetUpgradeSchedule(us *types.UpgradeSchedule) {
+	usBytes, err := types.ToBytes(us)
+	if err != nil {
+		log.Panicf("Error writing upgrade schedule %v, error: %v", us, err.Error())
+	}
+	sv.Set(UpgradeScheduleKey(), usBytes)
+}
+
+// GetNumericChainID gets the numeric chain ID the transaction signatures commit to, see
+// types.ReplayProtectedSignBytes. It returns nil if the genesis does not set the numeric chain ID,
+// which is then derived from the chain ID, see types.NumericChainID.
+func (sv *StoreView) GetNumericChainID() *big.Int {
+	data := sv.Get(NumericChainIDKey())
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	return new(big.Int).SetBytes(data)
+}
+
+// SetNumericChainID saves the numeric chain ID
+func (sv *StoreView) SetNumericChainID(numericChainID *big.Int) {
+	sv.Set(NumericChainIDKey(), numericChainID.Bytes())
+}
+
+// GetValidatorSigningKeys gets the signing keys announced by the validator
+func (sv *StoreView) GetValidatorSigningKeys(validator common.Address) *types.ValidatorSigningKeys {
+	data := sv.Get(ValidatorSigningKeysKey(validator))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	vsk := &types.ValidatorSigningKeys{}
+	err := types.FromBytes(data, vsk)
+	if err != nil {
+		log.Panicf("Error reading validator signing keys %X, error: %v", data, err.Error())
+	}
+	return vsk
+}
+
+// SetValidatorSigningKeys saves the signing keys announced by the validator
+func (sv *StoreView) SetValidatorSigningKeys(vsk *types.ValidatorSigningKeys) {
+	vskBytes, err := types.ToBytes(vsk)
+	if err != nil {
+		log.Panicf("Error writing validator signing keys %v, error: %v", vsk, err.Error())
+	}
+	sv.Set(ValidatorSigningKeysKey(vsk.Validator), vskBytes)
+}
+
+// GetValidatorSigningAddress returns the address of the signing key of the validator active at the
+// given epoch, which is the validator address itself if the validator never rotated its signing key
+func (sv *StoreView) GetValidatorSigningAddress(validator common.Address, epoch uint64) common.Address {
+	vsk := sv.GetValidatorSigningKeys(validator)
+	if vsk == nil {
+		return validator
+	}
+	return vsk.SigningAddress(epoch)
+}
+
+// GetBandwidthRewardRecord gets the bandwidth rewards the edge node claimed from the pool
+func (sv *StoreView) GetBandwidthRewardRecord(pool, edgeNode common.Address) *types.BandwidthRewardRecord {
+	data := sv.Get(BandwidthRewardRecordKey(pool, edgeNode))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	record := &types.BandwidthRewardRecord{}
+	err := types.FromBytes(data, record)
+	if err != nil {
+		log.Panicf("Error reading bandwidth reward record %X, error: %v", data, err.Error())
+	}
+	return record
+}
+
+// GetBandwidthRewardRecords gets the bandwidth rewards claimed from the pool
+func (sv *StoreView) GetBandwidthRewardRecords(pool common.Address) []*types.BandwidthRewardRecord {
+	records := []*types.BandwidthRewardRecord{}
+	sv.Traverse(BandwidthRewardRecordKeyPrefix(pool), func(key, val common.Bytes) bool {
+		record := &types.BandwidthRewardRecord{}
+		err := types.FromBytes(val, record)
+		if err != nil {
+			log.Panicf("Error reading bandwidth reward record %X, error: %v", val, err.Error())
+		}
+		records = append(records, record)
+		return true
+	})
+	return records
+}
+
+// SetBandwidthRewardRecord saves the bandwidth rewards the edge node claimed from the pool
+func (sv *StoreView) SetBandwidthRewardRecord(record *types.BandwidthRewardRecord) {
+	recordBytes, err := types.ToBytes(record)
+	if err != nil {
+		log.Panicf("Error writing bandwidth reward record %v, error: %v", record, err.Error())
+	}
+	sv.Set(BandwidthRewardRecordKey(record.Pool, record.EdgeNode), recordBytes)
+}
+
+// NextGovProposalID returns the ID of the next governance proposal, starting from 1
+func (sv *StoreView) NextGovProposalID() uint64 {
+	key := GovProposalCounterKey()
+	counter := new(big.Int).SetBytes(sv.Get(key)).Uint64()
+	sv.Set(key, new(big.Int).SetUint64(counter+1).Bytes())
+	return counter + 1
+}
+
+// GetGovProposal gets a governance proposal
+func (sv *StoreView) GetGovProposal(proposalID uint64) *types.GovProposal {
+	data := sv.Get(GovProposalKey(proposalID))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	proposal := &types.GovProposal{}
+	err := types.FromBytes(data, proposal)
+	if err != nil {
+		log.Panicf("Error reading governance proposal %X, error: %v", data, err.Error())
+	}
+	return proposal
+}
+
+// GetGovProposals gets all the governance proposals, ordered by their IDs
+func (sv *StoreView) GetGovProposals() []*types.GovProposal {
+	proposals := []*types.GovProposal{}
+	sv.Traverse(GovProposalKeyPrefix(), func(key, value common.Bytes) bool {
+		proposal := &types.GovProposal{}
+		err := types.FromBytes(value, proposal)
+		if err != nil {
+			log.Panicf("Error reading governance proposal %X, error: %v", value, err.Error())
+		}
+		proposals = append(proposals, proposal)
+		return true
+	})
+	sort.Slice(proposals, func(i, j int) bool {
+		return proposals[i].ID < proposals[j].ID
+	})
+	return proposals
+}
+
+// SetGovProposal saves a governance proposal. The proposal is indexed as active while it is open for voting.
+func (sv *StoreView) SetGovProposal(proposal *types.GovProposal) {
+	proposalBytes, err := types.ToBytes(proposal)
+	if err != nil {
+		log.Panicf("Error writing governance proposal %v, error: %v", proposal, err.Error())
+	}
+	sv.Set(GovProposalKey(proposal.ID), proposalBytes)
+	if proposal.Status == types.GovProposalStatusVoting {
+		sv.Set(ActiveGovProposalKey(proposal.ID), common.Bytes{0x01})
+	} else {
+		sv.Delete(ActiveGovProposalKey(proposal.ID))
+	}
+}
+
+// GetActiveGovProposals gets the governance proposals open for voting, ordered by their IDs
+func (sv *StoreView) GetActiveGovProposals() []*types.GovProposal {
+	proposals := []*types.GovProposal{}
+	prefix := ActiveGovProposalKeyPrefix()
+	sv.Traverse(prefix, func(key, value common.Bytes) bool {
+		proposalID, err := strconv.ParseUint(string(key[len(prefix):]), 10, 64)
+		if err != nil {
+			log.Panicf("Error reading active governance proposal key %X, error: %v", key, err.Error())
+		}
+		proposal := sv.GetGovProposal(proposalID)
+		if proposal == nil {
+			log.Panicf("Active governance proposal %v not found", proposalID)
+		}
+		proposals = append(proposals, proposal)
+		return true
+	})
+	sort.Slice(proposals, func(i, j int) bool {
+		return proposals[i].ID < proposals[j].ID
+	})
+	return proposals
+}
+
+// GetGovVote gets the vote of the voter on a governance proposal
+func (sv *StoreView) GetGovVote(proposalID uint64, voter common.Address) *types.GovVote {
+	data := sv.Get(GovVoteKey(proposalID, voter))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	vote := &types.GovVote{}
+	err := types.FromBytes(data, vote)
+	if err != nil {
+		log.Panicf("Error reading governance vote %X, error: %v", data, err.Error())
+	}
+	return vote
+}
+
+// GetGovVotes gets all the votes on a governance proposal
+func (sv *StoreView) GetGovVotes(proposalID uint64) []*types.GovVote {
+	votes := []*types.GovVote{}
+	sv.Traverse(GovVoteKeyPrefix(proposalID), func(key, value common.Bytes) bool {
+		vote := &types.GovVote{}
+		err := types.FromBytes(value, vote)
+		if err != nil {
+			log.Panicf("Error reading governance vote %X, error: %v", value, err.Error())
+		}
+		votes = append(votes, vote)
+		return true
+	})
+	return votes
+}
+
+// SetGovVote saves the vote of the voter on a governance proposal
+func (sv *StoreView) SetGovVote(vote *types.GovVote) {
+	voteBytes, err := types.ToBytes(vote)
+	if err != nil {
+		log.Panicf("Error writing governance vote %v, error: %v", vote, err.Error())
+	}
+	sv.Set(GovVoteKey(vote.ProposalID, vote.Voter), voteBytes)
+}
+
+// NextIBCIdentifier returns the next client, connection or channel identifier of the given kind,
+// e.g. "client-0", "client-1", and so on
+func (sv *StoreView) NextIBCIdentifier(kind string) string {
+	key := IBCIdentifierCounterKey(kind)
+	counter := new(big.Int).SetBytes(sv.Get(key)).Uint64()
+	sv.Set(key, new(big.Int).SetUint64(counter+1).Bytes())
+	return kind + "-" + strconv.FormatUint(counter, 10)
+}
+
+// GetIBCClient gets an IBC client
+func (sv *StoreView) GetIBCClient(clientID string) *types.IBCClientState {
+	data := sv.Get(IBCClientKey(clientID))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	client := &types.IBCClientState{}
+	err := types.FromBytes(data, client)
+	if err != nil {
+		log.Panicf("Error reading IBC client %X, error: %v", data, err.Error())
+	}
+	return client
+}
+
+// SetIBCClient saves an IBC client
+func (sv *StoreView) SetIBCClient(client *types.IBCClientState) {
+	clientBytes, err := types.ToBytes(client)
+	if err != nil {
+		log.Panicf("Error writing IBC client %v, error: %v", client, err.Error())
+	}
+	sv.Set(IBCClientKey(client.ClientID), clientBytes)
+}
+
+// GetIBCConsensusState gets the counterparty consensus state tracked by the client at the height
+func (sv *StoreView) GetIBCConsensusState(clientID string, height uint64) *types.IBCConsensusState {
+	data := sv.Get(IBCConsensusStateKey(clientID, height))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	cs := &types.IBCConsensusState{}
+	err := types.FromBytes(data, cs)
+	if err != nil {
+		log.Panicf("Error reading IBC consensus state %X, error: %v", data, err.Error())
+	}
+	return cs
+}
+
+// SetIBCConsensusState saves a counterparty consensus state tracked by the client
+func (sv *StoreView) SetIBCConsensusState(clientID string, cs *types.IBCConsensusState) {
+	csBytes, err := types.ToBytes(cs)
+	if err != nil {
+		log.Panicf("Error writing IBC consensus state %v, error: %v", cs, err.Error())
+	}
+	sv.Set(IBCConsensusStateKey(clientID, cs.Height), csBytes)
+}
+
+// GetIBCConnection gets an IBC connection end
+func (sv *StoreView) GetIBCConnection(connectionID string) *types.IBCConnection {
+	data := sv.Get(IBCConnectionKey(connectionID))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	conn := &types.IBCConnection{}
+	err := types.FromBytes(data, conn)
+	if err != nil {
+		log.Panicf("Error reading IBC connection %X, error: %v", data, err.Error())
+	}
+	return conn
+}
+
+// SetIBCConnection saves an IBC connection end
+func (sv *StoreView) SetIBCConnection(conn *types.IBCConnection) {
+	connBytes, err := types.ToBytes(conn)
+	if err != nil {
+		log.Panicf("Error writing IBC connection %v, error: %v", conn, err.Error())
+	}
+	sv.Set(IBCConnectionKey(conn.ConnectionID), connBytes)
+}
+
+// GetIBCPort gets an IBC port binding
+func (sv *StoreView) GetIBCPort(portID string) *types.IBCPort {
+	data := sv.Get(IBCPortKey(portID))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	port := &types.IBCPort{}
+	err := types.FromBytes(data, port)
+	if err != nil {
+		log.Panicf("Error reading IBC port %X, error: %v", data, err.Error())
+	}
+	return port
+}
+
+// SetIBCPort saves an IBC port binding
+func (sv *StoreView) SetIBCPort(port *types.IBCPort) {
+	portBytes, err := types.ToBytes(port)
+	if err != nil {
+		log.Panicf("Error writing IBC port %v, error: %v", port, err.Error())
+	}
+	sv.Set(IBCPortKey(port.PortID), portBytes)
+}
+
+// GetIBCChannel gets an IBC channel end
+func (sv *StoreView) GetIBCChannel(portID, channelID string) *types.IBCChannel {
+	data := sv.Get(IBCChannelKey(portID, channelID))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	channel := &types.IBCChannel{}
+	err := types.FromBytes(data, channel)
+	if err != nil {
+		log.Panicf("Error reading IBC channel %X, error: %v", data, err.Error())
+	}
+	return channel
+}
+
+// SetIBCChannel saves an IBC channel end
+func (sv *StoreView) SetIBCChannel(channel *types.IBCChannel) {
+	channelBytes, err := types.ToBytes(channel)
+	if err != nil {
+		log.Panicf("Error writing IBC channel %v, error: %v", channel, err.Error())
+	}
+	sv.Set(IBCChannelKey(channel.PortID, channel.ChannelID), channelBytes)
+}
+
+// GetIBCNextSequenceSend gets the sequence of the next packet sent over the channel, starting from 1
+func (sv *StoreView) GetIBCNextSequenceSend(portID, channelID string) uint64 {
+	data := sv.Get(IBCNextSequenceSendKey(portID, channelID))
+	if data == nil || len(data) == 0 {
+		return 1
+	}
+	return new(big.Int).SetBytes(data).Uint64()
+}
+
+// SetIBCNextSequenceSend saves the sequence of the next packet sent over the channel
+func (sv *StoreView) SetIBCNextSequenceSend(portID, channelID string, sequence uint64) {
+	sv.Set(IBCNextSequenceSendKey(portID, channelID), new(big.Int).SetUint64(sequence).Bytes())
+}
+
+// GetIBCPacket gets a packet sent over the channel and not yet acknowledged or timed out
+func (sv *StoreView) GetIBCPacket(portID, channelID string, sequence uint64) *types.IBCPacket {
+	data := sv.Get(IBCPacketKey(portID, channelID, sequence))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	packet := &types.IBCPacket{}
+	err := types.FromBytes(data, packet)
+	if err != nil {
+		log.Panicf("Error reading IBC packet %X, error: %v", data, err.Error())
+	}
+	return packet
+}
+
+// GetIBCPackets gets the packets sent over the channel and not yet acknowledged or timed out
+func (sv *StoreView) GetIBCPackets(portID, channelID string) []*types.IBCPacket {
+	packets := []*types.IBCPacket{}
+	sv.Traverse(IBCPacketKeyPrefix(portID, channelID), func(key, value common.Bytes) bool {
+		packet := &types.IBCPacket{}
+		err := types.FromBytes(value, packet)
+		if err != nil {
+			log.Panicf("Error reading IBC packet %X, error: %v", value, err.Error())
+		}
+		packets = append(packets, packet)
+		return true
+	})
+	return packets
+}
+
+// SetIBCPacket saves a packet sent over the channel along with its commitment
+func (sv *StoreView) SetIBCPacket(packet *types.IBCPacket) {
+	packetBytes, err := types.ToBytes(packet)
+	if err != nil {
+		log.Panicf("Error writing IBC packet %v, error: %v", packet, err.Error())
+	}
+	sv.Set(IBCPacketKey(packet.SourcePort, packet.SourceChannel, packet.Sequence), packetBytes)
+	sv.Set(IBCPacketCommitmentKey(packet.SourcePort, packet.SourceChannel, packet.Sequence), packet.Commitment())
+}
+
+// DeleteIBCPacket deletes a packet sent over the channel along with its commitment, once the packet
+// is acknowledged or timed out
+func (sv *StoreView) DeleteIBCPacket(packet *types.IBCPacket) {
+	sv.Delete(IBCPacketKey(packet.SourcePort, packet.SourceChannel, packet.Sequence))
+	sv.Delete(IBCPacketCommitmentKey(packet.SourcePort, packet.SourceChannel, packet.Sequence))
+}
+
+// GetIBCPacketCommitment gets the commitment of a packet sent over the channel
+func (sv *StoreView) GetIBCPacketCommitment(portID, channelID string, sequence uint64) common.Bytes {
+	return sv.Get(IBCPacketCommitmentKey(portID, channelID, sequence))
+}
+
+// GetIBCPacketReceipt gets a packet received over the channel
+func (sv *StoreView) GetIBCPacketReceipt(portID, channelID string, sequence uint64) *types.IBCPacket {
+	data := sv.Get(IBCPacketReceiptKey(portID, channelID, sequence))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	packet := &types.IBCPacket{}
+	err := types.FromBytes(data, packet)
+	if err != nil {
+		log.Panicf("Error reading IBC packet receipt %X, error: %v", data, err.Error())
+	}
+	return packet
+}
+
+// GetIBCPacketReceipts gets the packets received over the channel
+func (sv *StoreView) GetIBCPacketReceipts(portID, channelID string) []*types.IBCPacket {
+	packets := []*types.IBCPacket{}
+	sv.Traverse(IBCPacketReceiptKeyPrefix(portID, channelID), func(key, value common.Bytes) bool {
+		packet := &types.IBCPacket{}
+		err := types.FromBytes(value, packet)
+		if err != nil {
+			log.Panicf("Error reading IBC packet receipt %X, error: %v", value, err.Error())
+		}
+		packets = append(packets, packet)
+		return true
+	})
+	return packets
+}
+
+// SetIBCPacketReceipt saves a packet received over the channel, along with the commitment of its
+// acknowledgement
+func (sv *StoreView) SetIBCPacketReceipt(packet *types.IBCPacket, ack common.Bytes) {
+	packetBytes, err := types.ToBytes(packet)
+	if err != nil {
+		log.Panicf("Error writing IBC packet receipt %v, error: %v", packet, err.Error())
+	}
+	sv.Set(IBCPacketReceiptKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence), packetBytes)
+	sv.Set(IBCPacketAckKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence), types.IBCAcknowledgementCommitment(ack))
+}
+
 // GetTotalEENStake retrives the total active EEN stakes
 func (sv *StoreView) GetTotalEENStake() *big.Int {
 	raw := sv.Get(EliteEdgeNodesTotalActiveStakeKey())
@@ -623,6 +1424,9 @@ func (sv *StoreView) GetCommittedState(addr common.Address, key common.Hash) com
 }
 
 func (sv *StoreView) getAccountStorage(account *types.Account) *treestore.TreeStore {
+	if sv.storageDB != nil {
+		return treestore.NewTreeStore(account.Root, sv.storageDB)
+	}
 	return treestore.NewTreeStore(account.Root, sv.store.GetDB())
 }
 
@@ -109,6 +109,89 @@ func TestStoreViewAccountAccess(t *testing.T) {
 	log.Infof("Balance: %v\n", accRetrieved.Balance)
 }
 
+func TestStoreViewBalanceTracking(t *testing.T) {
+	assert := assert.New(t)
+
+	addr1 := common.HexToAddress("0x2000000000000000000000000000000000000000")
+	addr2 := common.HexToAddress("0x1000000000000000000000000000000000000000")
+	addr3 := common.HexToAddress("0x3000000000000000000000000000000000000000")
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+	sv.SetAccount(addr1, &types.Account{Address: addr1, Balance: types.NewCoins(100, 1000)})
+	sv.SetAccount(addr3, &types.Account{Address: addr3, Balance: types.NewCoins(5, 5)})
+
+	sv.StartBalanceTracking()
+
+	acc1 := sv.GetAccount(addr1)
+	acc1.Balance = types.NewCoins(90, 900)
+	sv.SetAccount(addr1, acc1)
+	acc1.Balance = types.NewCoins(80, 800)
+	sv.SetAccount(addr1, acc1)
+
+	sv.SetAccount(addr2, &types.Account{Address: addr2, Balance: types.NewCoins(20, 200)})
+
+	acc3 := sv.GetAccount(addr3)
+	acc3.Sequence++
+	sv.SetAccount(addr3, acc3) // balance unchanged, should not be reported
+
+	balanceChanges := sv.StopBalanceTracking()
+	assert.Equal(2, len(balanceChanges))
+
+	// Sorted by address
+	assert.Equal(addr2, balanceChanges[0].Address)
+	assert.True(balanceChanges[0].Before.IsEqual(types.NewCoins(0, 0)))
+	assert.True(balanceChanges[0].After.IsEqual(types.NewCoins(20, 200)))
+
+	assert.Equal(addr1, balanceChanges[1].Address)
+	assert.True(balanceChanges[1].Before.IsEqual(types.NewCoins(100, 1000)))
+	assert.True(balanceChanges[1].After.IsEqual(types.NewCoins(80, 800)))
+
+	// Account updates are no longer tracked once stopped
+	acc1.Balance = types.NewCoins(0, 0)
+	sv.SetAccount(addr1, acc1)
+	assert.Equal(0, len(sv.StopBalanceTracking()))
+}
+
+func TestStoreViewStateDiffTracking(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+	sv.Set(common.Bytes("k1"), common.Bytes("v1"))
+	sv.Set(common.Bytes("k2"), common.Bytes("v2"))
+	sv.Set(common.Bytes("k3"), common.Bytes("v3"))
+
+	sv.StartStateDiffTracking()
+
+	sv.Set(common.Bytes("k3"), common.Bytes("v3.1"))
+	sv.Set(common.Bytes("k3"), common.Bytes("v3.2"))
+	sv.Delete(common.Bytes("k1"))
+	sv.Set(common.Bytes("k0"), common.Bytes("v0"))
+	sv.Set(common.Bytes("k2"), common.Bytes("v2.1"))
+	sv.Set(common.Bytes("k2"), common.Bytes("v2")) // value restored, should not be reported
+
+	stateDiffs := sv.StopStateDiffTracking()
+	assert.Equal(3, len(stateDiffs))
+
+	// Sorted by key
+	assert.Equal(common.Bytes("k0"), stateDiffs[0].Key)
+	assert.Nil(stateDiffs[0].Before)
+	assert.Equal(common.Bytes("v0"), stateDiffs[0].After)
+
+	assert.Equal(common.Bytes("k1"), stateDiffs[1].Key)
+	assert.Equal(common.Bytes("v1"), stateDiffs[1].Before)
+	assert.Nil(stateDiffs[1].After)
+
+	assert.Equal(common.Bytes("k3"), stateDiffs[2].Key)
+	assert.Equal(common.Bytes("v3"), stateDiffs[2].Before)
+	assert.Equal(common.Bytes("v3.2"), stateDiffs[2].After)
+
+	// Updates are no longer tracked once stopped
+	sv.Set(common.Bytes("k1"), common.Bytes("v1"))
+	assert.Equal(0, len(sv.StopStateDiffTracking()))
+}
+
 func TestStoreViewSplitRuleAccess(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,263 @@
+package statediff
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// The changes of an account
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// The stake pools
+const (
+	PoolValidator     = "validator"
+	PoolGuardian      = "guardian"
+	PoolEliteEdgeNode = "elite_edge_node"
+)
+
+// BlockStateDiff is the change of the ledger state made by a committed block. The diff is
+// deterministic, i.e. all the nodes export the same diff for the same block. The block might not
+// be finalized yet, an indexer following the chain detects the forks with the parent hash.
+type BlockStateDiff struct {
+	Height     common.JSONUint64 `json:"height"`
+	BlockHash  common.Hash       `json:"block_hash"`
+	ParentHash common.Hash       `json:"parent_hash"`
+	StateRoot  common.Hash       `json:"state_root"`
+
+	Accounts []AccountDiff `json:"accounts"` // sorted by address
+	Stakes   []StakeDiff   `json:"stakes"`   // sorted by pool, holder and source
+
+	// The changes of all the keys, including the smart contract storage, sorted by key
+	Raw []state.StateDiff `json:"raw"`
+}
+
+// AccountDiff is the change of an account. The balance delta is negative if the balance decreased.
+type AccountDiff struct {
+	Address       common.Address    `json:"address"`
+	Change        string            `json:"change"`
+	Sequence      common.JSONUint64 `json:"sequence"`
+	BalanceBefore types.Coins       `json:"balance_before"`
+	BalanceAfter  types.Coins       `json:"balance_after"`
+	BalanceDelta  types.Coins       `json:"balance_delta"`
+}
+
+// StakeDiff is the change of a stake deposited by a source to a stake holder, e.g. a validator
+type StakeDiff struct {
+	Pool         string            `json:"pool"`
+	Holder       common.Address    `json:"holder"`
+	Source       common.Address    `json:"source"`
+	AmountBefore *common.JSONBig   `json:"amount_before"`
+	AmountAfter  *common.JSONBig   `json:"amount_after"`
+	Withdrawn    bool              `json:"withdrawn"`
+	ReturnHeight common.JSONUint64 `json:"return_height"`
+}
+
+// NewBlockStateDiff decodes the account and stake changes from the raw state diffs of the block
+func NewBlockStateDiff(block *core.Block, stateDiffs []state.StateDiff) (*BlockStateDiff, error) {
+	diff := &BlockStateDiff{
+		Height:     common.JSONUint64(block.Height),
+		BlockHash:  block.Hash(),
+		ParentHash: block.Parent,
+		StateRoot:  block.StateHash,
+		Accounts:   []AccountDiff{},
+		Stakes:     []StakeDiff{},
+		Raw:        stateDiffs,
+	}
+
+	accountPrefix := state.AccountKeyPrefix()
+	eenPrefix := state.EliteEdgeNodeKeyPrefix()
+	for _, sd := range stateDiffs {
+		var err error
+		switch {
+		case bytes.HasPrefix(sd.Key, accountPrefix) && len(sd.Key) == len(accountPrefix)+common.AddressLength:
+			err = diff.addAccountDiff(sd)
+		case bytes.Equal(sd.Key, state.ValidatorCandidatePoolKey()):
+			err = diff.addStakeDiffs(PoolValidator, sd, decodeValidatorCandidatePool)
+		case bytes.Equal(sd.Key, state.GuardianCandidatePoolKey()):
+			err = diff.addStakeDiffs(PoolGuardian, sd, decodeGuardianCandidatePool)
+		case bytes.HasPrefix(sd.Key, eenPrefix) && len(sd.Key) == len(eenPrefix)+common.AddressLength:
+			err = diff.addStakeDiffs(PoolEliteEdgeNode, sd, decodeEliteEdgeNode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode the state diff of key %v: %v", sd.Key, err)
+		}
+	}
+
+	sort.SliceStable(diff.Stakes, func(i, j int) bool {
+		si, sj := diff.Stakes[i], diff.Stakes[j]
+		if si.Pool != sj.Pool {
+			return si.Pool < sj.Pool
+		}
+		if c := bytes.Compare(si.Holder[:], sj.Holder[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(si.Source[:], sj.Source[:]) < 0
+	})
+
+	return diff, nil
+}
+
+func (diff *BlockStateDiff) addAccountDiff(sd state.StateDiff) error {
+	before, err := decodeAccount(sd.Before)
+	if err != nil {
+		return err
+	}
+	after, err := decodeAccount(sd.After)
+	if err != nil {
+		return err
+	}
+
+	ad := AccountDiff{
+		Address:       common.BytesToAddress(sd.Key[len(state.AccountKeyPrefix()):]),
+		BalanceBefore: types.NewCoins(0, 0),
+		BalanceAfter:  types.NewCoins(0, 0),
+	}
+	switch {
+	case before == nil:
+		ad.Change = ChangeCreated
+	case after == nil:
+		ad.Change = ChangeDeleted
+	default:
+		ad.Change = ChangeUpdated
+	}
+	if before != nil {
+		ad.BalanceBefore = before.Balance.NoNil()
+		ad.Sequence = common.JSONUint64(before.Sequence)
+	}
+	if after != nil {
+		ad.BalanceAfter = after.Balance.NoNil()
+		ad.Sequence = common.JSONUint64(after.Sequence)
+	}
+	ad.BalanceDelta = types.Coins{
+		ThetaWei: new(big.Int).Sub(ad.BalanceAfter.ThetaWei, ad.BalanceBefore.ThetaWei),
+		TFuelWei: new(big.Int).Sub(ad.BalanceAfter.TFuelWei, ad.BalanceBefore.TFuelWei),
+	}
+	diff.Accounts = append(diff.Accounts, ad)
+	return nil
+}
+
+func decodeAccount(raw common.Bytes) (*types.Account, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	acc := &types.Account{}
+	if err := types.FromBytes(raw, acc); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+// stakeKey identifies a stake within a pool
+type stakeKey struct {
+	holder common.Address
+	source common.Address
+}
+
+// decodeStakesFunc decodes the stakes stored under a key
+type decodeStakesFunc func(raw common.Bytes) (map[stakeKey]*core.Stake, error)
+
+func (diff *BlockStateDiff) addStakeDiffs(pool string, sd state.StateDiff, decode decodeStakesFunc) error {
+	before, err := decode(sd.Before)
+	if err != nil {
+		return err
+	}
+	after, err := decode(sd.After)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[stakeKey]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	for key := range keys {
+		sb, sa := before[key], after[key]
+		if sb != nil && sa != nil && sb.Amount.Cmp(sa.Amount) == 0 &&
+			sb.Withdrawn == sa.Withdrawn && sb.ReturnHeight == sa.ReturnHeight {
+			continue
+		}
+		stakeDiff := StakeDiff{
+			Pool:         pool,
+			Holder:       key.holder,
+			Source:       key.source,
+			AmountBefore: (*common.JSONBig)(big.NewInt(0)),
+			AmountAfter:  (*common.JSONBig)(big.NewInt(0)),
+		}
+		if sb != nil {
+			stakeDiff.AmountBefore = (*common.JSONBig)(sb.Amount)
+		}
+		if sa != nil {
+			stakeDiff.AmountAfter = (*common.JSONBig)(sa.Amount)
+			stakeDiff.Withdrawn = sa.Withdrawn
+			stakeDiff.ReturnHeight = common.JSONUint64(sa.ReturnHeight)
+		}
+		diff.Stakes = append(diff.Stakes, stakeDiff)
+	}
+	return nil
+}
+
+func addStakes(stakes map[stakeKey]*core.Stake, holder *core.StakeHolder) {
+	if holder == nil {
+		return
+	}
+	for _, stake := range holder.Stakes {
+		stakes[stakeKey{holder: holder.Holder, source: stake.Source}] = stake
+	}
+}
+
+func decodeValidatorCandidatePool(raw common.Bytes) (map[stakeKey]*core.Stake, error) {
+	stakes := make(map[stakeKey]*core.Stake)
+	if len(raw) == 0 {
+		return stakes, nil
+	}
+	vcp := &core.ValidatorCandidatePool{}
+	if err := types.FromBytes(raw, vcp); err != nil {
+		return nil, err
+	}
+	for _, candidate := range vcp.SortedCandidates {
+		addStakes(stakes, candidate)
+	}
+	return stakes, nil
+}
+
+func decodeGuardianCandidatePool(raw common.Bytes) (map[stakeKey]*core.Stake, error) {
+	stakes := make(map[stakeKey]*core.Stake)
+	if len(raw) == 0 {
+		return stakes, nil
+	}
+	gcp := &core.GuardianCandidatePool{}
+	if err := types.FromBytes(raw, gcp); err != nil {
+		return nil, err
+	}
+	for _, guardian := range gcp.SortedGuardians {
+		addStakes(stakes, guardian.StakeHolder)
+	}
+	return stakes, nil
+}
+
+func decodeEliteEdgeNode(raw common.Bytes) (map[stakeKey]*core.Stake, error) {
+	stakes := make(map[stakeKey]*core.Stake)
+	if len(raw) == 0 {
+		return stakes, nil
+	}
+	een := &core.EliteEdgeNode{}
+	if err := types.FromBytes(raw, een); err != nil {
+		return nil, err
+	}
+	addStakes(stakes, een.StakeHolder)
+	return stakes, nil
+}
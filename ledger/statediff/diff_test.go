@@ -0,0 +1,107 @@
+package statediff
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestBlockStateDiff(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	bob := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	carol := common.HexToAddress("0x0000000000000000000000000000000000000003")
+	validator := common.HexToAddress("0x0000000000000000000000000000000000000004")
+
+	sv := state.NewStoreView(uint64(1), common.Hash{}, backend.NewMemDatabase())
+	aliceAcc := types.NewAccount(alice)
+	aliceAcc.Balance = types.NewCoins(1000, 500)
+	sv.SetAccount(alice, aliceAcc)
+	sv.SetAccount(carol, types.NewAccount(carol))
+	minStake := core.MinValidatorStakeDeposit
+	vcp := &core.ValidatorCandidatePool{}
+	require.Nil(vcp.DepositStake(alice, validator, minStake))
+	sv.UpdateValidatorCandidatePool(vcp)
+
+	sv.StartStateDiffTracking()
+	aliceAcc.Balance = types.NewCoins(800, 490)
+	aliceAcc.Sequence = 1
+	sv.SetAccount(alice, aliceAcc)
+	bobAcc := types.NewAccount(bob)
+	bobAcc.Balance = types.NewCoins(100, 0)
+	sv.SetAccount(bob, bobAcc)
+	sv.DeleteAccount(carol)
+	require.Nil(vcp.DepositStake(alice, validator, minStake))
+	require.Nil(vcp.DepositStake(bob, validator, minStake))
+	sv.UpdateValidatorCandidatePool(vcp)
+	stateDiffs := sv.StopStateDiffTracking()
+
+	block := &core.Block{BlockHeader: &core.BlockHeader{Height: 2, Parent: common.BytesToHash([]byte{1})}}
+	diff, err := NewBlockStateDiff(block, stateDiffs)
+	require.Nil(err)
+	assert.Equal(common.JSONUint64(2), diff.Height)
+	assert.Equal(block.Hash(), diff.BlockHash)
+	assert.Equal(block.Parent, diff.ParentHash)
+	assert.Equal(len(stateDiffs), len(diff.Raw))
+
+	require.Equal(3, len(diff.Accounts))
+	assert.Equal(alice, diff.Accounts[0].Address)
+	assert.Equal(ChangeUpdated, diff.Accounts[0].Change)
+	assert.Equal(common.JSONUint64(1), diff.Accounts[0].Sequence)
+	assert.Equal(int64(-200), diff.Accounts[0].BalanceDelta.ThetaWei.Int64())
+	assert.Equal(int64(-10), diff.Accounts[0].BalanceDelta.TFuelWei.Int64())
+	assert.Equal(bob, diff.Accounts[1].Address)
+	assert.Equal(ChangeCreated, diff.Accounts[1].Change)
+	assert.Equal(int64(100), diff.Accounts[1].BalanceDelta.ThetaWei.Int64())
+	assert.Equal(carol, diff.Accounts[2].Address)
+	assert.Equal(ChangeDeleted, diff.Accounts[2].Change)
+
+	require.Equal(2, len(diff.Stakes))
+	assert.Equal(PoolValidator, diff.Stakes[0].Pool)
+	assert.Equal(validator, diff.Stakes[0].Holder)
+	assert.Equal(alice, diff.Stakes[0].Source)
+	assert.Equal(0, minStake.Cmp(diff.Stakes[0].AmountBefore.ToInt()))
+	assert.Equal(0, new(big.Int).Mul(minStake, big.NewInt(2)).Cmp(diff.Stakes[0].AmountAfter.ToInt()))
+	assert.Equal(bob, diff.Stakes[1].Source)
+	assert.Equal(0, diff.Stakes[1].AmountBefore.ToInt().Sign())
+	assert.Equal(0, minStake.Cmp(diff.Stakes[1].AmountAfter.ToInt()))
+
+	// The diffs are appended to the file as JSON lines
+	dir, err := ioutil.TempDir("", "statediff")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	sink, err := NewFileSink(path.Join(dir, "statediff.jsonl"))
+	require.Nil(err)
+	require.Nil(sink.Publish(diff))
+	require.Nil(sink.Publish(diff))
+	require.Nil(sink.Close())
+
+	file, err := os.Open(path.Join(dir, "statediff.jsonl"))
+	require.Nil(err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	lines := 0
+	for scanner.Scan() {
+		decoded := &BlockStateDiff{}
+		require.Nil(json.Unmarshal(scanner.Bytes(), decoded))
+		assert.Equal(diff.BlockHash, decoded.BlockHash)
+		assert.Equal(len(diff.Accounts), len(decoded.Accounts))
+		lines++
+	}
+	assert.Equal(2, lines)
+}
@@ -0,0 +1,107 @@
+package statediff
+
+import (
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/common/util"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+)
+
+var logger = util.GetLoggerForModule("statediff")
+
+const (
+	exportQueueSize  = 1024
+	maxPublishTries  = 5
+	publishRetryWait = time.Second
+)
+
+type exportItem struct {
+	block      *core.Block
+	stateDiffs []state.StateDiff
+}
+
+// Exporter decodes the state diffs of the committed blocks and publishes them to the sink in a
+// background goroutine, so that a slow sink does not hold up the block processing. The diffs are
+// published in the order of the blocks. A diff is dropped if the sink fails repeatedly or cannot
+// keep up, an indexer detects the gap from the heights and re-syncs the missing blocks.
+type Exporter struct {
+	sink Sink
+
+	queue chan *exportItem
+	quit  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewExporter creates an exporter publishing to the given sink
+func NewExporter(sink Sink) *Exporter {
+	exp := &Exporter{
+		sink:  sink,
+		queue: make(chan *exportItem, exportQueueSize),
+		quit:  make(chan struct{}),
+	}
+
+	exp.wg.Add(1)
+	go exp.mainLoop()
+
+	return exp
+}
+
+// Export queues the state diffs of a committed block
+func (exp *Exporter) Export(block *core.Block, stateDiffs []state.StateDiff) {
+	select {
+	case exp.queue <- &exportItem{block: block, stateDiffs: stateDiffs}:
+	default:
+		logger.Errorf("State diff queue is full, dropped the state diff of block %v at height %v", block.Hash().Hex(), block.Height)
+	}
+}
+
+// Stop publishes the queued diffs, stops the exporter and closes the sink.
+func (exp *Exporter) Stop() {
+	close(exp.quit)
+	exp.wg.Wait()
+	if err := exp.sink.Close(); err != nil {
+		logger.Warnf("Failed to close the state diff sink: %v", err)
+	}
+}
+
+func (exp *Exporter) mainLoop() {
+	defer exp.wg.Done()
+
+	for {
+		select {
+		case item := <-exp.queue:
+			exp.publish(item)
+		case <-exp.quit:
+			for len(exp.queue) > 0 {
+				exp.publish(<-exp.queue)
+			}
+			return
+		}
+	}
+}
+
+func (exp *Exporter) publish(item *exportItem) {
+	diff, err := NewBlockStateDiff(item.block, item.stateDiffs)
+	if err != nil {
+		logger.Errorf("Failed to decode the state diff of block %v: %v", item.block.Hash().Hex(), err)
+		return
+	}
+
+	for i := 0; i < maxPublishTries; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(publishRetryWait):
+			case <-exp.quit:
+				logger.Errorf("Dropped the state diff of block %v at height %v on shutdown", item.block.Hash().Hex(), item.block.Height)
+				return
+			}
+		}
+		if err = exp.sink.Publish(diff); err == nil {
+			return
+		}
+		logger.Warnf("Failed to publish the state diff of block %v at height %v: %v", item.block.Hash().Hex(), item.block.Height, err)
+	}
+	logger.Errorf("Dropped the state diff of block %v at height %v after %v tries", item.block.Hash().Hex(), item.block.Height, maxPublishTries)
+}
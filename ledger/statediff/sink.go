@@ -0,0 +1,213 @@
+package statediff
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// The sink types
+const (
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+	SinkKafka   = "kafka"
+)
+
+const sinkTimeout = 10 * time.Second
+
+// Sink receives the state diffs of the committed blocks in the order of the blocks
+type Sink interface {
+	Publish(diff *BlockStateDiff) error
+	Close() error
+}
+
+// SinkConfig configures the sink of the state diffs
+type SinkConfig struct {
+	Type           string
+	FilePath       string   // for the file sink
+	WebhookURL     string   // for the webhook sink
+	KafkaRESTProxy string   // for the Kafka sink, the URL of the Kafka REST proxy
+	KafkaTopic     string   // for the Kafka sink
+	Headers        []string // extra HTTP headers as "<name>: <value>", e.g. for the authentication
+}
+
+// NewSink creates the sink of the given type
+func NewSink(config SinkConfig) (Sink, error) {
+	switch config.Type {
+	case SinkFile:
+		return NewFileSink(config.FilePath)
+	case SinkWebhook:
+		return NewWebhookSink(config.WebhookURL, config.Headers)
+	case SinkKafka:
+		return NewKafkaSink(config.KafkaRESTProxy, config.KafkaTopic, config.Headers)
+	default:
+		return nil, fmt.Errorf("Unknown state diff sink: %v", config.Type)
+	}
+}
+
+// ------------------------------ File ------------------------------
+
+// FileSink appends the state diffs to a file as JSON lines
+type FileSink struct {
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// NewFileSink opens the file, which is created if it does not exist
+func NewFileSink(filePath string) (*FileSink, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("The file path of the state diff sink is not set")
+	}
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// Publish writes the diff as a line, and flushes it to the file
+func (s *FileSink) Publish(diff *BlockStateDiff) error {
+	line, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	if _, err := s.writer.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.writer.Flush()
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.writer.Flush()
+	return s.file.Close()
+}
+
+// ------------------------------ Webhook ------------------------------
+
+// WebhookSink posts each state diff as JSON to a URL
+type WebhookSink struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+// NewWebhookSink creates a sink posting the diffs to the given URL
+func NewWebhookSink(url string, headers []string) (*WebhookSink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("The URL of the state diff webhook is not set")
+	}
+	hdr, err := parseHeaders(headers)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookSink{url: url, headers: hdr, client: &http.Client{Timeout: sinkTimeout}}, nil
+}
+
+// Publish posts the diff, any status other than 2xx is an error
+func (s *WebhookSink) Publish(diff *BlockStateDiff) error {
+	body, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	return post(s.client, s.url, "application/json", s.headers, body)
+}
+
+// Close is a no-op
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// ------------------------------ Kafka ------------------------------
+
+// KafkaSink produces each state diff as a record of a Kafka topic through a Kafka REST proxy
+// (the v2 API of the Confluent REST proxy). The records are keyed by the block height, so that
+// a topic with multiple partitions can be consumed in order per height.
+type KafkaSink struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value *BlockStateDiff `json:"value"`
+}
+
+type kafkaRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+// NewKafkaSink creates a sink producing to the topic through the REST proxy, e.g. "http://127.0.0.1:8082"
+func NewKafkaSink(proxyURL string, topic string, headers []string) (*KafkaSink, error) {
+	if proxyURL == "" || topic == "" {
+		return nil, fmt.Errorf("The REST proxy URL and the topic of the Kafka state diff sink must be set")
+	}
+	hdr, err := parseHeaders(headers)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaSink{
+		url:     strings.TrimRight(proxyURL, "/") + "/topics/" + topic,
+		headers: hdr,
+		client:  &http.Client{Timeout: sinkTimeout},
+	}, nil
+}
+
+// Publish produces the diff to the topic
+func (s *KafkaSink) Publish(diff *BlockStateDiff) error {
+	body, err := json.Marshal(kafkaRequest{
+		Records: []kafkaRecord{{Key: fmt.Sprintf("%v", uint64(diff.Height)), Value: diff}},
+	})
+	if err != nil {
+		return err
+	}
+	return post(s.client, s.url, "application/vnd.kafka.json.v2+json", s.headers, body)
+}
+
+// Close is a no-op
+func (s *KafkaSink) Close() error {
+	return nil
+}
+
+// ------------------------------ Utils ------------------------------
+
+func parseHeaders(headers []string) (http.Header, error) {
+	hdr := http.Header{}
+	for _, h := range headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("HTTP header needs to be given as <name>: <value>: %v", h)
+		}
+		hdr.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	return hdr, nil
+}
+
+func post(client *http.Client, url string, contentType string, headers http.Header, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Failed to post the state diff to %v: %v", url, resp.Status)
+	}
+	return nil
+}
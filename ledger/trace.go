@@ -0,0 +1,71 @@
+package ledger
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	exec "github.com/thetatoken/theta/ledger/execution"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm"
+)
+
+// TraceTransaction re-executes the committed smart contract transaction with the given hash
+// on top of the state of its parent block, and feeds the EVM execution to the given tracer.
+// The transactions preceding it in the same block are replayed first so that the traced
+// execution observes the same state as the original one. The re-execution does not modify
+// the ledger state.
+func (ledger *Ledger) TraceTransaction(txHash common.Hash, tracer vm.Tracer) (*types.SmartContractTx, *core.ExtendedBlock, error) {
+	rawTx, block, found := ledger.chain.FindTxByHash(txHash)
+	if !found {
+		return nil, nil, fmt.Errorf("Transaction %v is not found", txHash.Hex())
+	}
+
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to parse transaction %v: %v", txHash.Hex(), err)
+	}
+	sctx, ok := tx.(*types.SmartContractTx)
+	if !ok {
+		return nil, nil, fmt.Errorf("Transaction %v is not a smart contract transaction", txHash.Hex())
+	}
+
+	parentBlock, err := ledger.chain.FindBlock(block.Parent)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to find the parent block %v: %v", block.Parent.Hex(), err)
+	}
+
+	// Re-execute on a private ledger state so that the node state is left untouched
+	traceState := st.NewLedgerState(ledger.state.GetChainID(), ledger.db, nil)
+	if res := traceState.ResetState(parentBlock.Block); res.IsError() {
+		return nil, nil, fmt.Errorf("The state at height %v is not available, it might have been pruned", parentBlock.Height)
+	}
+	executor := exec.NewExecutor(ledger.db, ledger.chain, traceState, ledger.consensus, ledger.valMgr)
+	executor.SetSkipSanityCheck(true)
+	executor.SetSkipTxReceipt(true)
+
+	for _, raw := range block.Txs {
+		if bytes.Equal(raw, rawTx) {
+			executor.SetVMConfig(vm.Config{
+				Debug:  true,
+				Tracer: tracer,
+			})
+			if _, res := executor.ExecuteTx(sctx); res.IsError() {
+				return nil, nil, fmt.Errorf("Failed to trace transaction %v: %v", txHash.Hex(), res.Message)
+			}
+			return sctx, block, nil
+		}
+
+		precedingTx, err := types.TxFromBytes(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to parse transaction in block %v: %v", block.Hash().Hex(), err)
+		}
+		if _, res := executor.ExecuteTx(precedingTx); res.IsError() {
+			return nil, nil, fmt.Errorf("Failed to replay transaction in block %v: %v", block.Hash().Hex(), res.Message)
+		}
+	}
+
+	return nil, nil, fmt.Errorf("Transaction %v is not found in block %v", txHash.Hex(), block.Hash().Hex())
+}
@@ -264,6 +264,46 @@ func (acc *Account) TransferReservedFund(splittedCoinsMap map[*Account]Coins, cu
 	return false, SlashIntent{}
 }
 
+// CheckChargeReservedFund verifies that the reserved fund can pay the bandwidth reward for the resource
+func (acc *Account) CheckChargeReservedFund(reward Coins, currentBlockHeight uint64, reserveSequence uint64, resourceID string) error {
+	for _, reservedFund := range acc.ReservedFunds {
+		if reservedFund.ReserveSequence != reserveSequence {
+			continue
+		}
+
+		if reservedFund.EndBlockHeight < currentBlockHeight {
+			return errors.New("Already expired")
+		}
+		if !reservedFund.HasResourceID(resourceID) {
+			return errors.Errorf("The reserved fund is not reserved for resource %v", resourceID)
+		}
+		remainingFund := reservedFund.InitialFund.Minus(reservedFund.UsedFund)
+		if !remainingFund.IsGTE(reward) {
+			return errors.Errorf("The remaining reserved fund %v is not enough for %v", remainingFund, reward)
+		}
+
+		return nil // at most one matching reserveSequence
+	}
+	return errors.Errorf("No matching ReservedFund with reserveSequence %d", reserveSequence)
+}
+
+// ChargeReservedFund pays the bandwidth reward for the resource from the reserved fund to the edge node account
+func (acc *Account) ChargeReservedFund(edgeNodeAcc *Account, reward Coins, currentBlockHeight uint64, reserveSequence uint64, resourceID string) error {
+	if err := acc.CheckChargeReservedFund(reward, currentBlockHeight, reserveSequence, resourceID); err != nil {
+		return err
+	}
+	for idx := range acc.ReservedFunds {
+		reservedFund := &acc.ReservedFunds[idx]
+		if reservedFund.ReserveSequence != reserveSequence {
+			continue
+		}
+		reservedFund.UsedFund = reservedFund.UsedFund.Plus(reward)
+		edgeNodeAcc.Balance = edgeNodeAcc.Balance.Plus(reward)
+		break
+	}
+	return nil
+}
+
 func (acc *Account) generateSlashIntent(reservedFund *ReservedFund, currentServicePaymentTx *ServicePaymentTx) SlashIntent {
 	overspendingProof := constructOverspendingProof(reservedFund, currentServicePaymentTx)
 
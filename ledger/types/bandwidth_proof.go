@@ -0,0 +1,46 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// ** Bandwidth Rewards: the rewards claimed by the edge nodes through the BandwidthProofTx **
+//
+
+// BandwidthRewardEpoch returns the bandwidth reward epoch of the block height
+func BandwidthRewardEpoch(blockHeight uint64) uint64 {
+	return blockHeight / BandwidthRewardEpochLength
+}
+
+// BandwidthRewardRecord tracks the bandwidth proofs signed by a pool for an edge node which have
+// been claimed, for the anti-replay nonce and the per-epoch caps
+type BandwidthRewardRecord struct {
+	Pool              common.Address `json:"pool"`
+	EdgeNode          common.Address `json:"edge_node"`
+	Nonce             uint64         `json:"nonce"`               // the nonce of the last claimed proof
+	Epoch             uint64         `json:"epoch"`               // the epoch of the last claimed proof
+	EpochReward       *big.Int       `json:"epoch_reward"`        // the TFuelWei claimed for the epoch
+	EpochRelayedBytes uint64         `json:"epoch_relayed_bytes"` // the bandwidth claimed for the epoch
+}
+
+// Claim records the claim of the proof, and starts over the per-epoch totals at a new epoch
+func (record *BandwidthRewardRecord) Claim(nonce, epoch uint64, reward *big.Int, relayedBytes uint64) {
+	if epoch != record.Epoch || record.EpochReward == nil {
+		record.Epoch = epoch
+		record.EpochReward = big.NewInt(0)
+		record.EpochRelayedBytes = 0
+	}
+	record.Nonce = nonce
+	record.EpochReward = new(big.Int).Add(record.EpochReward, reward)
+	record.EpochRelayedBytes += relayedBytes
+}
+
+// EpochTotals returns the reward and the bandwidth already claimed for the epoch
+func (record *BandwidthRewardRecord) EpochTotals(epoch uint64) (*big.Int, uint64) {
+	if epoch != record.Epoch || record.EpochReward == nil {
+		return big.NewInt(0), 0
+	}
+	return record.EpochReward, record.EpochRelayedBytes
+}
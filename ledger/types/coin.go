@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/thetatoken/theta/common"
@@ -171,24 +172,90 @@ func (coins Coins) IsNonnegative() bool {
 	return c.ThetaWei.Cmp(Zero) >= 0 && c.TFuelWei.Cmp(Zero) >= 0
 }
 
-// ParseCoinAmount parses a string representation of coin amount.
+// maxCoinAmountExponent bounds the exponent of the parsed coin amounts, so that parsing
+// an input like 1e999999999 doesn't exhaust the memory
+const maxCoinAmountExponent = 1000
+
+var weiPerCoin = new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+
+// ParseCoinAmount parses a string representation of coin amount, e.g. 1000, 1e3wei, 12.5theta
+// or 0.3tfuel. Amounts without the wei suffix are in units of 10^18 wei. The amount is converted
+// to wei exactly, amounts with a fraction of a wei are rejected.
 func ParseCoinAmount(in string) (*big.Int, bool) {
+	_, amount, ok := parseCoinAmount(in)
+	return amount, ok
+}
+
+// ParseCoinAmountOf parses the amount of the given coin (DenomTheta or DenomTFuel). The amount
+// is rejected if it carries the denomination of the other coin.
+func ParseCoinAmountOf(in string, denom string) (*big.Int, bool) {
+	amountDenom, amount, ok := parseCoinAmount(in)
+	if !ok || (amountDenom != "" && !strings.EqualFold(amountDenom, denom)) {
+		return nil, false
+	}
+	return amount, true
+}
+
+// ParseCoins parses a comma separated list of coin amounts, each carrying its denomination,
+// e.g. "12.5theta,0.3tfuel" or "1000tfuelwei"
+func ParseCoins(in string) (Coins, bool) {
+	coins := NewCoins(0, 0)
+	for _, s := range strings.Split(in, ",") {
+		denom, amount, ok := parseCoinAmount(strings.TrimSpace(s))
+		if !ok {
+			return Coins{}, false
+		}
+		switch denom {
+		case DenomTheta:
+			coins.ThetaWei.Add(coins.ThetaWei, amount)
+		case DenomTFuel:
+			coins.TFuelWei.Add(coins.TFuelWei, amount)
+		default:
+			return Coins{}, false
+		}
+	}
+	return coins, true
+}
+
+// parseCoinAmount returns the denomination of the amount (DenomTheta, DenomTFuel, or empty if
+// not specified) and the amount in wei
+func parseCoinAmount(in string) (string, *big.Int, bool) {
 	inWei := false
 	if len(in) > 3 && strings.EqualFold("wei", in[len(in)-3:]) {
 		inWei = true
 		in = in[:len(in)-3]
 	}
+	denom := ""
+	for _, d := range []string{DenomTheta, DenomTFuel} {
+		if len(in) > len(d) && strings.EqualFold(d, in[len(in)-len(d):]) {
+			denom = d
+			in = in[:len(in)-len(d)]
+			break
+		}
+	}
 
-	f, ok := new(big.Float).SetPrec(1024).SetString(in)
-	if !ok || f.Sign() < 0 {
-		return nil, false
+	// Only decimal numbers with an optional exponent are accepted, not fractions like 1/3
+	mantissa, exponent := in, ""
+	if idx := strings.IndexAny(in, "eE"); idx >= 0 {
+		mantissa, exponent = in[:idx], in[idx+1:]
+	}
+	if len(mantissa) == 0 || strings.Trim(mantissa, "0123456789.") != "" || strings.Count(mantissa, ".") > 1 {
+		return "", nil, false
+	}
+	if exp, err := strconv.Atoi(exponent); exponent != "" && (err != nil || exp > maxCoinAmountExponent || exp < -maxCoinAmountExponent) {
+		return "", nil, false
 	}
 
+	r, ok := new(big.Rat).SetString(in)
+	if !ok {
+		return "", nil, false
+	}
 	if !inWei {
-		f = f.Mul(f, new(big.Float).SetPrec(1024).SetUint64(1e18))
+		r.Mul(r, weiPerCoin)
+	}
+	if !r.IsInt() {
+		return "", nil, false
 	}
 
-	ret, _ := f.Int(nil)
-
-	return ret, true
+	return denom, new(big.Int).Set(r.Num()), true
 }
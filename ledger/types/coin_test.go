@@ -183,6 +183,78 @@ func TestParseCoinAmount(t *testing.T) {
 	ret, ok = ParseCoinAmount("1e3Wei")
 	assert.True(ok)
 	assert.True(big.NewInt(1000).Cmp(ret) == 0)
+
+	// Decimal amounts are converted exactly.
+	ret, ok = ParseCoinAmount("0.3")
+	assert.True(ok)
+	assert.True(big.NewInt(3e17).Cmp(ret) == 0)
+
+	ret, ok = ParseCoinAmount("2.5theta")
+	assert.True(ok)
+	assert.True(big.NewInt(25e17).Cmp(ret) == 0)
+
+	ret, ok = ParseCoinAmount("0.3TFuel")
+	assert.True(ok)
+	assert.True(big.NewInt(3e17).Cmp(ret) == 0)
+
+	ret, ok = ParseCoinAmount("1000tfuelwei")
+	assert.True(ok)
+	assert.True(big.NewInt(1000).Cmp(ret) == 0)
+
+	ret, ok = ParseCoinAmount("123456789.123456789123456789")
+	assert.True(ok)
+	assert.Equal("123456789123456789123456789", ret.String())
+
+	// Fractions of a wei, fractions and huge exponents are rejected.
+	_, ok = ParseCoinAmount("1.5wei")
+	assert.False(ok)
+	_, ok = ParseCoinAmount("0.0000000000000000001")
+	assert.False(ok)
+	_, ok = ParseCoinAmount("1/3")
+	assert.False(ok)
+	_, ok = ParseCoinAmount("1e999999999")
+	assert.False(ok)
+	_, ok = ParseCoinAmount("theta")
+	assert.False(ok)
+}
+
+func TestParseCoinAmountOf(t *testing.T) {
+	assert := assert.New(t)
+
+	ret, ok := ParseCoinAmountOf("2.5theta", DenomTheta)
+	assert.True(ok)
+	assert.True(big.NewInt(25e17).Cmp(ret) == 0)
+
+	ret, ok = ParseCoinAmountOf("2.5", DenomTheta)
+	assert.True(ok)
+	assert.True(big.NewInt(25e17).Cmp(ret) == 0)
+
+	ret, ok = ParseCoinAmountOf("100wei", DenomTFuel)
+	assert.True(ok)
+	assert.True(big.NewInt(100).Cmp(ret) == 0)
+
+	_, ok = ParseCoinAmountOf("0.3tfuel", DenomTheta)
+	assert.False(ok)
+}
+
+func TestParseCoins(t *testing.T) {
+	assert := assert.New(t)
+
+	coins, ok := ParseCoins("2.5theta, 0.3tfuel,1tfuel")
+	assert.True(ok)
+	assert.True(big.NewInt(25e17).Cmp(coins.ThetaWei) == 0)
+	assert.True(big.NewInt(13e17).Cmp(coins.TFuelWei) == 0)
+
+	coins, ok = ParseCoins("1000thetawei")
+	assert.True(ok)
+	assert.True(big.NewInt(1000).Cmp(coins.ThetaWei) == 0)
+	assert.True(big.NewInt(0).Cmp(coins.TFuelWei) == 0)
+
+	// The denomination is required.
+	_, ok = ParseCoins("2.5")
+	assert.False(ok)
+	_, ok = ParseCoins("2.5theta,1000wei")
+	assert.False(ok)
 }
 
 func TestJSON(t *testing.T) {
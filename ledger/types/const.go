@@ -13,6 +13,12 @@ const (
 	// DenomTFuelWei is the basic unit of theta, 1 Theta = 10^18 ThetaWei
 	DenomTFuelWei string = "TFuelWei"
 
+	// DenomTheta is the unit of theta amounts, 1 Theta = 10^18 ThetaWei
+	DenomTheta string = "Theta"
+
+	// DenomTFuel is the unit of tfuel amounts, 1 TFuel = 10^18 TFuelWei
+	DenomTFuel string = "TFuel"
+
 	// Initial gas parameters
 
 	// MinimumGasPrice is the minimum gas price for a smart contract transaction
@@ -38,6 +44,10 @@ const (
 
 	// MaxAccountsAffectedPerTx specifies the max number of accounts one transaction is allowed to modify to avoid spamming
 	MaxAccountsAffectedPerTx = 512
+
+	// BatchSendTxFeePerOutputTFuelWei specifies the minimum fee for each output of a batch send transaction,
+	// in addition to the minimum fee for a regular transaction
+	BatchSendTxFeePerOutputTFuelWei uint64 = 3e16
 )
 
 const (
@@ -90,6 +100,57 @@ const (
 
 	// ReservedFundFreezePeriodDuration indicates the freeze duration (in terms of number of blocks) of the reserved fund
 	ReservedFundFreezePeriodDuration uint64 = 5
+
+	// MaximumServicePaymentDisputeWindow indicates the maximum dispute window (in terms of number of blocks) of a reserved fund
+	MaximumServicePaymentDisputeWindow uint64 = 600
+
+	// MaxTokenNamespaceLength is the maximum length of a token registry namespace
+	MaxTokenNamespaceLength int = 32
+
+	// MaxTokenSymbolLength is the maximum length of a registered token symbol
+	MaxTokenSymbolLength int = 16
+
+	// MaxTokenNameLength is the maximum length of a registered token name
+	MaxTokenNameLength int = 64
+
+	// MaxTokenDecimals is the maximum number of decimals of a registered token
+	MaxTokenDecimals uint8 = 36
+
+	// MinimumValidatorKeyActivationDelay is the minimum number of epochs between the announcement of a
+	// new validator signing key and its activation, so that the validator nodes can switch the key in time
+	MinimumValidatorKeyActivationDelay uint64 = 300
+
+	// BandwidthRewardEpochLength is the number of blocks of a bandwidth reward epoch, roughly a day
+	BandwidthRewardEpochLength uint64 = 14400
+
+	// MaxBandwidthRewardPerEpochTFuelWei caps the rewards an edge node can claim from a pool per epoch
+	MaxBandwidthRewardPerEpochTFuelWei uint64 = 5 * 1000000000000000000
+
+	// MaxRelayedBytesPerEpoch caps the bandwidth an edge node can claim to have relayed for a pool per epoch
+	MaxRelayedBytesPerEpoch uint64 = 10 * 1024 * 1024 * 1024 * 1024
+
+	// GovVotingPeriod is the number of blocks a governance proposal is open for voting, roughly a week
+	GovVotingPeriod uint64 = 100800
+
+	// MinGovProposalDepositTFuelWei is the minimum deposit of a governance proposal, which is burned
+	// if the vote does not reach the quorum
+	MinGovProposalDepositTFuelWei uint64 = 10 * 1000000000000000000
+
+	// MaxGovProposalTitleLength is the maximum length of the title of a governance proposal
+	MaxGovProposalTitleLength int = 140
+
+	// MaxGovProposalDescriptionLength is the maximum length of the description of a governance proposal
+	MaxGovProposalDescriptionLength int = 10000
+
+	// GovQuorumNumerator / GovQuorumDenominator is the minimum share of the total stake that needs
+	// to vote on a governance proposal
+	GovQuorumNumerator   int64 = 1
+	GovQuorumDenominator int64 = 3
+
+	// GovThresholdNumerator / GovThresholdDenominator is the share of the stake voting yes or no
+	// that a governance proposal needs to exceed to pass
+	GovThresholdNumerator   int64 = 2
+	GovThresholdDenominator int64 = 3
 )
 
 func GetMinimumGasPrice(blockHeight uint64) *big.Int {
@@ -132,3 +193,11 @@ func GetSendTxMinimumTransactionFeeTFuelWei(numAccountsAffected uint64, blockHei
 
 	return minSendTxFee
 }
+
+// GetBatchSendTxMinimumTransactionFeeTFuelWei returns the minimum fee of a BatchSendTx with the given number of outputs,
+// which is much lower than that of a SendTx modifying the same number of accounts
+func GetBatchSendTxMinimumTransactionFeeTFuelWei(numOutputs uint64, blockHeight uint64) *big.Int {
+	minBatchSendTxFee := GetMinimumTransactionFeeTFuelWei(blockHeight)
+	perOutputFee := new(big.Int).SetUint64(BatchSendTxFeePerOutputTFuelWei)
+	return minBatchSendTxFee.Add(minBatchSendTxFee, perOutputFee.Mul(perOutputFee, new(big.Int).SetUint64(numOutputs)))
+}
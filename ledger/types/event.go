@@ -0,0 +1,92 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ** Event: Notification emitted by the ledger when the state changes outside of a transaction **
+//
+
+const (
+	// EventTypeSplitRuleExpired is emitted when an expired split rule is removed from the ledger state
+	EventTypeSplitRuleExpired string = "split_rule_expired"
+
+	// EventTypeServicePaymentSettled is emitted when a pending service payment is settled after its dispute window
+	EventTypeServicePaymentSettled string = "service_payment_settled"
+
+	// EventTypeGovProposalTallied is emitted when the vote on a governance proposal ends
+	EventTypeGovProposalTallied string = "gov_proposal_tallied"
+)
+
+// EventAttribute is a key/value pair describing an event
+type EventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Event is emitted by the ledger during block execution. Similar to the ABCI events, each event
+// has a type and a list of attributes, so that clients can subscribe to the events of interest
+// without parsing the ledger state.
+type Event struct {
+	Type       string           `json:"type"`
+	Attributes []EventAttribute `json:"attributes"`
+}
+
+// NewSplitRuleExpiredEvent creates the event for the expiry of the given split rule
+func NewSplitRuleExpiredEvent(splitRule *SplitRule) *Event {
+	return &Event{
+		Type: EventTypeSplitRuleExpired,
+		Attributes: []EventAttribute{
+			{Key: "resource_id", Value: splitRule.ResourceID},
+			{Key: "initiator_address", Value: splitRule.InitiatorAddress.Hex()},
+			{Key: "end_block_height", Value: strconv.FormatUint(splitRule.EndBlockHeight, 10)},
+		},
+	}
+}
+
+// NewServicePaymentSettledEvent creates the event for the settlement of the given pending service payment
+func NewServicePaymentSettledEvent(payment *PendingServicePayment) *Event {
+	tx := payment.Payment
+	return &Event{
+		Type: EventTypeServicePaymentSettled,
+		Attributes: []EventAttribute{
+			{Key: "source_address", Value: tx.Source.Address.Hex()},
+			{Key: "target_address", Value: tx.Target.Address.Hex()},
+			{Key: "resource_id", Value: tx.ResourceID},
+			{Key: "reserve_sequence", Value: strconv.FormatUint(tx.ReserveSequence, 10)},
+			{Key: "payment_sequence", Value: strconv.FormatUint(tx.PaymentSequence, 10)},
+			{Key: "amount", Value: tx.Source.Coins.NoNil().TFuelWei.String()},
+		},
+	}
+}
+
+// NewGovProposalTalliedEvent creates the event for the end of the vote on the given governance proposal
+func NewGovProposalTalliedEvent(proposal *GovProposal) *Event {
+	return &Event{
+		Type: EventTypeGovProposalTallied,
+		Attributes: []EventAttribute{
+			{Key: "proposal_id", Value: strconv.FormatUint(proposal.ID, 10)},
+			{Key: "proposal_type", Value: proposal.Type.String()},
+			{Key: "status", Value: proposal.Status.String()},
+			{Key: "yes_stake", Value: proposal.YesStake.String()},
+			{Key: "no_stake", Value: proposal.NoStake.String()},
+			{Key: "abstain_stake", Value: proposal.AbstainStake.String()},
+			{Key: "total_stake", Value: proposal.TotalStake.String()},
+		},
+	}
+}
+
+// GetAttribute returns the value of the attribute with the given key
+func (e *Event) GetAttribute(key string) (string, bool) {
+	for _, attr := range e.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+func (e *Event) String() string {
+	return fmt.Sprintf("Event{%v, %v}", e.Type, e.Attributes)
+}
@@ -0,0 +1,187 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/thetatoken/theta/common"
+)
+
+// ** Fee Schedule: The chain-wide minimum transaction fees, stored in the ledger state so that **
+// ** they can be adjusted by governance in response to TFuel price changes                       **
+//
+
+// TxFeeRule specifies the minimum fee of a transaction type. The minimum fee of a transaction is
+//
+//	BaseFeeTFuelWei + PerUnitFeeTFuelWei * max(size, MinUnits)
+//
+// where the size of a transaction is the number of accounts affected by a SendTx or MultiSigSendTx,
+// the number of outputs of a BatchSendTx, and zero for the other transaction types.
+type TxFeeRule struct {
+	TxType             TxType
+	BaseFeeTFuelWei    *big.Int
+	PerUnitFeeTFuelWei *big.Int
+	MinUnits           uint64
+}
+
+type TxFeeRuleJSON struct {
+	TxType             TxType            `json:"tx_type"`
+	BaseFeeTFuelWei    *common.JSONBig   `json:"base_fee_tfuel_wei"`
+	PerUnitFeeTFuelWei *common.JSONBig   `json:"per_unit_fee_tfuel_wei"`
+	MinUnits           common.JSONUint64 `json:"min_units"`
+}
+
+func NewTxFeeRuleJSON(a TxFeeRule) TxFeeRuleJSON {
+	return TxFeeRuleJSON{
+		TxType:             a.TxType,
+		BaseFeeTFuelWei:    (*common.JSONBig)(a.BaseFeeTFuelWei),
+		PerUnitFeeTFuelWei: (*common.JSONBig)(a.PerUnitFeeTFuelWei),
+		MinUnits:           common.JSONUint64(a.MinUnits),
+	}
+}
+
+func (a TxFeeRuleJSON) TxFeeRule() TxFeeRule {
+	return TxFeeRule{
+		TxType:             a.TxType,
+		BaseFeeTFuelWei:    (*big.Int)(a.BaseFeeTFuelWei),
+		PerUnitFeeTFuelWei: (*big.Int)(a.PerUnitFeeTFuelWei),
+		MinUnits:           uint64(a.MinUnits),
+	}
+}
+
+func (a TxFeeRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewTxFeeRuleJSON(a))
+}
+
+func (a *TxFeeRule) UnmarshalJSON(data []byte) error {
+	var b TxFeeRuleJSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.TxFeeRule()
+	return nil
+}
+
+// MinimumFee returns the minimum fee of a transaction with the given size
+func (r *TxFeeRule) MinimumFee(size uint64) *big.Int {
+	if size < r.MinUnits {
+		size = r.MinUnits
+	}
+	minimumFee := new(big.Int).Mul(r.PerUnitFeeTFuelWei, new(big.Int).SetUint64(size))
+	return minimumFee.Add(minimumFee, r.BaseFeeTFuelWei)
+}
+
+func (r *TxFeeRule) String() string {
+	return fmt.Sprintf("TxFeeRule{tx_type: %v, base_fee: %v, per_unit_fee: %v, min_units: %v}",
+		r.TxType, r.BaseFeeTFuelWei, r.PerUnitFeeTFuelWei, r.MinUnits)
+}
+
+// FeeSchedule specifies the minimum fees of the transactions. The transaction types without
+// a rule in the schedule are charged DefaultFeeTFuelWei.
+type FeeSchedule struct {
+	DefaultFeeTFuelWei *big.Int
+	Rules              []TxFeeRule
+}
+
+type FeeScheduleJSON struct {
+	DefaultFeeTFuelWei *common.JSONBig `json:"default_fee_tfuel_wei"`
+	Rules              []TxFeeRule     `json:"rules"`
+}
+
+func NewFeeScheduleJSON(a FeeSchedule) FeeScheduleJSON {
+	return FeeScheduleJSON{
+		DefaultFeeTFuelWei: (*common.JSONBig)(a.DefaultFeeTFuelWei),
+		Rules:              a.Rules,
+	}
+}
+
+func (a FeeScheduleJSON) FeeSchedule() FeeSchedule {
+	return FeeSchedule{
+		DefaultFeeTFuelWei: (*big.Int)(a.DefaultFeeTFuelWei),
+		Rules:              a.Rules,
+	}
+}
+
+func (a FeeSchedule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewFeeScheduleJSON(a))
+}
+
+func (a *FeeSchedule) UnmarshalJSON(data []byte) error {
+	var b FeeScheduleJSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.FeeSchedule()
+	return nil
+}
+
+// DefaultFeeSchedule returns the fee schedule matching the minimum fees compiled into the ledger,
+// which is in effect until the schedule is first adjusted
+func DefaultFeeSchedule() *FeeSchedule {
+	minTxFee := new(big.Int).SetUint64(MinimumTransactionFeeTFuelWeiJune2021)
+	halfMinTxFee := new(big.Int).Div(minTxFee, big.NewInt(2))
+	sendTxRule := func(txType TxType) TxFeeRule {
+		return TxFeeRule{
+			TxType:             txType,
+			BaseFeeTFuelWei:    big.NewInt(0),
+			PerUnitFeeTFuelWei: halfMinTxFee,
+			MinUnits:           2,
+		}
+	}
+
+	return &FeeSchedule{
+		DefaultFeeTFuelWei: minTxFee,
+		Rules: []TxFeeRule{
+			sendTxRule(TxSend),
+			sendTxRule(TxMultiSigSend),
+			{
+				TxType:             TxBatchSend,
+				BaseFeeTFuelWei:    minTxFee,
+				PerUnitFeeTFuelWei: new(big.Int).SetUint64(BatchSendTxFeePerOutputTFuelWei),
+			},
+		},
+	}
+}
+
+// GetRule returns the fee rule of the given transaction type, or nil if there is none
+func (fs *FeeSchedule) GetRule(txType TxType) *TxFeeRule {
+	for i := range fs.Rules {
+		if fs.Rules[i].TxType == txType {
+			return &fs.Rules[i]
+		}
+	}
+	return nil
+}
+
+// MinimumFee returns the minimum fee of a transaction with the given type and size
+func (fs *FeeSchedule) MinimumFee(txType TxType, size uint64) *big.Int {
+	rule := fs.GetRule(txType)
+	if rule == nil {
+		return new(big.Int).Set(fs.DefaultFeeTFuelWei)
+	}
+	return rule.MinimumFee(size)
+}
+
+// Validate checks that all the fees are specified and non-negative, and that each transaction
+// type has at most one rule
+func (fs *FeeSchedule) Validate() error {
+	if fs.DefaultFeeTFuelWei == nil || fs.DefaultFeeTFuelWei.Sign() < 0 {
+		return errors.New("Default fee needs to be non-negative")
+	}
+	txTypes := make(map[TxType]bool)
+	for _, rule := range fs.Rules {
+		if txTypes[rule.TxType] {
+			return errors.Errorf("Duplicated fee rule for tx type %v", rule.TxType)
+		}
+		txTypes[rule.TxType] = true
+		if rule.BaseFeeTFuelWei == nil || rule.BaseFeeTFuelWei.Sign() < 0 {
+			return errors.Errorf("Base fee for tx type %v needs to be non-negative", rule.TxType)
+		}
+		if rule.PerUnitFeeTFuelWei == nil || rule.PerUnitFeeTFuelWei.Sign() < 0 {
+			return errors.Errorf("Per unit fee for tx type %v needs to be non-negative", rule.TxType)
+		}
+	}
+	return nil
+}
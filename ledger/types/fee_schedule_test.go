@@ -0,0 +1,75 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestDefaultFeeSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	// The default schedule should match the minimum fees compiled into the ledger
+	fs := DefaultFeeSchedule()
+	assert.Nil(fs.Validate())
+	height := common.HeightJune2021FeeAdjustment
+	for _, numAccounts := range []uint64{0, 1, 2, 3, 10} {
+		assert.Equal(GetSendTxMinimumTransactionFeeTFuelWei(numAccounts, height), fs.MinimumFee(TxSend, numAccounts))
+		assert.Equal(GetSendTxMinimumTransactionFeeTFuelWei(numAccounts, height), fs.MinimumFee(TxMultiSigSend, numAccounts))
+	}
+	for _, numOutputs := range []uint64{1, 5, 100} {
+		assert.Equal(GetBatchSendTxMinimumTransactionFeeTFuelWei(numOutputs, height), fs.MinimumFee(TxBatchSend, numOutputs))
+	}
+	assert.Equal(GetMinimumTransactionFeeTFuelWei(height), fs.MinimumFee(TxReserveFund, 0))
+	assert.Equal(GetMinimumTransactionFeeTFuelWei(height), fs.MinimumFee(TxDepositStakeV2, 0))
+}
+
+func TestFeeScheduleValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	fs := DefaultFeeSchedule()
+	fs.Rules = append(fs.Rules, TxFeeRule{
+		TxType:             TxSend,
+		BaseFeeTFuelWei:    big.NewInt(1),
+		PerUnitFeeTFuelWei: big.NewInt(1),
+	})
+	assert.NotNil(fs.Validate())
+
+	fs = DefaultFeeSchedule()
+	fs.Rules[0].PerUnitFeeTFuelWei = big.NewInt(-1)
+	assert.NotNil(fs.Validate())
+
+	fs = DefaultFeeSchedule()
+	fs.DefaultFeeTFuelWei = nil
+	assert.NotNil(fs.Validate())
+}
+
+func TestFeeScheduleRLPAndJSON(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	fs := DefaultFeeSchedule()
+	fs.Rules = append(fs.Rules, TxFeeRule{
+		TxType:             TxSmartContract,
+		BaseFeeTFuelWei:    big.NewInt(123),
+		PerUnitFeeTFuelWei: big.NewInt(0),
+		MinUnits:           1,
+	})
+
+	raw, err := ToBytes(fs)
+	require.Nil(err)
+	fs2 := &FeeSchedule{}
+	require.Nil(FromBytes(raw, fs2))
+	assert.Equal(fs.MinimumFee(TxSend, 5), fs2.MinimumFee(TxSend, 5))
+	assert.Equal(big.NewInt(123), fs2.MinimumFee(TxSmartContract, 0))
+
+	s, err := json.Marshal(fs)
+	require.Nil(err)
+	fs3 := &FeeSchedule{}
+	require.Nil(json.Unmarshal(s, fs3))
+	assert.Equal(fs.DefaultFeeTFuelWei, fs3.DefaultFeeTFuelWei)
+	assert.Equal(fs.MinimumFee(TxBatchSend, 7), fs3.MinimumFee(TxBatchSend, 7))
+}
@@ -0,0 +1,214 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/thetatoken/theta/common"
+)
+
+// ** Governance: the proposals voted on by the stakers through the GovProposalTx and the GovVoteTx **
+//
+
+// GovProposalType is the type of a governance proposal
+type GovProposalType uint8
+
+const (
+	// GovProposalTypeText is a proposal without any effect on the ledger state, e.g. a signaling proposal
+	GovProposalTypeText GovProposalType = 1
+
+	// GovProposalTypeParamChange is a proposal to change the chain params, which are changed once it passes
+	GovProposalTypeParamChange GovProposalType = 2
+)
+
+func (t GovProposalType) String() string {
+	switch t {
+	case GovProposalTypeText:
+		return "text"
+	case GovProposalTypeParamChange:
+		return "param_change"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+// ParseGovProposalType parses the name of a governance proposal type
+func ParseGovProposalType(name string) (GovProposalType, error) {
+	for _, t := range []GovProposalType{GovProposalTypeText, GovProposalTypeParamChange} {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, errors.Errorf("Unknown proposal type: %v", name)
+}
+
+// GovProposalStatus is the status of a governance proposal
+type GovProposalStatus uint8
+
+const (
+	// GovProposalStatusVoting means the proposal is open for voting
+	GovProposalStatusVoting GovProposalStatus = 1
+
+	// GovProposalStatusPassed means the proposal was approved, and its param changes, if any, were applied
+	GovProposalStatusPassed GovProposalStatus = 2
+
+	// GovProposalStatusRejected means the proposal did not reach the quorum or the approval threshold
+	GovProposalStatusRejected GovProposalStatus = 3
+
+	// GovProposalStatusFailed means the proposal was approved, but its param changes could not be applied
+	GovProposalStatusFailed GovProposalStatus = 4
+)
+
+func (s GovProposalStatus) String() string {
+	switch s {
+	case GovProposalStatusVoting:
+		return "voting"
+	case GovProposalStatusPassed:
+		return "passed"
+	case GovProposalStatusRejected:
+		return "rejected"
+	case GovProposalStatusFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(s))
+	}
+}
+
+// GovVoteOption is the option chosen by a voter
+type GovVoteOption uint8
+
+const (
+	GovVoteOptionYes     GovVoteOption = 1
+	GovVoteOptionNo      GovVoteOption = 2
+	GovVoteOptionAbstain GovVoteOption = 3
+)
+
+func (o GovVoteOption) String() string {
+	switch o {
+	case GovVoteOptionYes:
+		return "yes"
+	case GovVoteOptionNo:
+		return "no"
+	case GovVoteOptionAbstain:
+		return "abstain"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(o))
+	}
+}
+
+// ParseGovVoteOption parses the name of a vote option
+func ParseGovVoteOption(name string) (GovVoteOption, error) {
+	for _, o := range []GovVoteOption{GovVoteOptionYes, GovVoteOptionNo, GovVoteOptionAbstain} {
+		if o.String() == name {
+			return o, nil
+		}
+	}
+	return 0, errors.Errorf("Unknown vote option: %v", name)
+}
+
+// The chain params which the governance proposals can change
+const (
+	GovParamFeeSchedule     = "fee_schedule"
+	GovParamUpgradeSchedule = "upgrade_schedule"
+)
+
+// GovParamChange replaces a chain param with a new value
+type GovParamChange struct {
+	Param string       `json:"param"`
+	Value common.Bytes `json:"value"` // the RLP encoded new value, e.g. a FeeSchedule
+}
+
+// NewGovParamChange encodes the new value of the chain param, i.e. a *FeeSchedule or an *UpgradeSchedule
+func NewGovParamChange(param string, value interface{}) (GovParamChange, error) {
+	raw, err := ToBytes(value)
+	if err != nil {
+		return GovParamChange{}, err
+	}
+	pc := GovParamChange{Param: param, Value: raw}
+	if _, err := pc.DecodeValue(); err != nil {
+		return GovParamChange{}, err
+	}
+	return pc, nil
+}
+
+// DecodeValue decodes and validates the new value of the chain param
+func (pc GovParamChange) DecodeValue() (interface{}, error) {
+	switch pc.Param {
+	case GovParamFeeSchedule:
+		fs := &FeeSchedule{}
+		if err := FromBytes(pc.Value, fs); err != nil {
+			return nil, errors.Wrap(err, "Failed to decode the fee schedule")
+		}
+		return fs, fs.Validate()
+	case GovParamUpgradeSchedule:
+		us := &UpgradeSchedule{}
+		if err := FromBytes(pc.Value, us); err != nil {
+			return nil, errors.Wrap(err, "Failed to decode the upgrade schedule")
+		}
+		return us, us.Validate()
+	default:
+		return nil, errors.Errorf("Unknown chain param: %v", pc.Param)
+	}
+}
+
+func (pc GovParamChange) String() string {
+	return fmt.Sprintf("GovParamChange{param: %v, value: %v}", pc.Param, pc.Value)
+}
+
+// GovProposal is a governance proposal along with the outcome of the vote
+type GovProposal struct {
+	ID              uint64            `json:"id"`
+	Proposer        common.Address    `json:"proposer"`
+	Type            GovProposalType   `json:"type"`
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	ParamChanges    []GovParamChange  `json:"param_changes"`
+	Deposit         Coins             `json:"deposit"`
+	SubmitHeight    uint64            `json:"submit_height"`
+	VotingEndHeight uint64            `json:"voting_end_height"`
+	Status          GovProposalStatus `json:"status"`
+
+	// The tally of the vote at the end of the voting period, in ThetaWei staked
+	YesStake     *big.Int `json:"yes_stake"`
+	NoStake      *big.Int `json:"no_stake"`
+	AbstainStake *big.Int `json:"abstain_stake"`
+	TotalStake   *big.Int `json:"total_stake"`
+}
+
+// Tally records the stake voting for each option, and decides whether the proposal passed. The vote
+// reaches the quorum if at least GovQuorumNumerator/GovQuorumDenominator of the total stake voted,
+// and the proposal passes if more than GovThresholdNumerator/GovThresholdDenominator of the stake
+// voting yes or no voted yes. Tally returns whether the vote reached the quorum.
+func (p *GovProposal) Tally(yes, no, abstain, total *big.Int) bool {
+	p.YesStake, p.NoStake, p.AbstainStake, p.TotalStake = yes, no, abstain, total
+
+	voted := new(big.Int).Add(yes, no)
+	voted.Add(voted, abstain)
+	quorum := new(big.Int).Mul(total, big.NewInt(GovQuorumNumerator))
+	if total.Sign() <= 0 || new(big.Int).Mul(voted, big.NewInt(GovQuorumDenominator)).Cmp(quorum) < 0 {
+		p.Status = GovProposalStatusRejected
+		return false
+	}
+
+	threshold := new(big.Int).Mul(new(big.Int).Add(yes, no), big.NewInt(GovThresholdNumerator))
+	if new(big.Int).Mul(yes, big.NewInt(GovThresholdDenominator)).Cmp(threshold) > 0 {
+		p.Status = GovProposalStatusPassed
+	} else {
+		p.Status = GovProposalStatusRejected
+	}
+	return true
+}
+
+func (p *GovProposal) String() string {
+	return fmt.Sprintf("GovProposal{id: %v, proposer: %v, type: %v, title: %v, param_changes: %v, deposit: %v, voting_end_height: %v, status: %v}",
+		p.ID, p.Proposer.Hex(), p.Type, p.Title, p.ParamChanges, p.Deposit, p.VotingEndHeight, p.Status)
+}
+
+// GovVote is the latest vote of a voter on a proposal
+type GovVote struct {
+	ProposalID uint64         `json:"proposal_id"`
+	Voter      common.Address `json:"voter"`
+	Option     GovVoteOption  `json:"option"`
+	Height     uint64         `json:"height"` // the block height of the vote
+}
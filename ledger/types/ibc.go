@@ -0,0 +1,328 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rlp"
+)
+
+// ** IBC: Authenticated packet channels with the counterparty chains implementing the same standard **
+//
+// A client tracks the finalized blocks of a counterparty chain, verified against the validator set of
+// the counterparty. On top of a client, a connection and then channels are established with the
+// counterparty through four-way handshakes, where each step is proven by the Merkle proof of the
+// counterparty state under a block tracked by the client. The packets sent over an open channel are
+// committed in the state of the sending chain, and received, acknowledged or timed out on the proofs
+// of the counterparty state. The proofs are relayed by off-chain relayers, which need not be trusted.
+//
+
+var ibcIdentifierPattern = regexp.MustCompile("^[a-zA-Z0-9._-]{2,64}$")
+
+// ValidateIBCIdentifier checks that the client, connection, port or channel identifier is 2 to 64
+// characters long, and does not contain the separator of the state keys
+func ValidateIBCIdentifier(id string) error {
+	if !ibcIdentifierPattern.MatchString(id) {
+		return errors.Errorf("Invalid IBC identifier %q, it needs to have 2 to 64 alphanumeric, '.', '_' or '-' characters", id)
+	}
+	return nil
+}
+
+// IBCHandshakeState is the state of a connection or a channel
+type IBCHandshakeState uint8
+
+const (
+	IBCStateInit    IBCHandshakeState = 1
+	IBCStateTryOpen IBCHandshakeState = 2
+	IBCStateOpen    IBCHandshakeState = 3
+)
+
+func (s IBCHandshakeState) String() string {
+	switch s {
+	case IBCStateInit:
+		return "INIT"
+	case IBCStateTryOpen:
+		return "TRYOPEN"
+	case IBCStateOpen:
+		return "OPEN"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", uint8(s))
+	}
+}
+
+// IBCClientState is the light client of a counterparty chain
+type IBCClientState struct {
+	ClientID            string           `json:"client_id"`
+	CounterpartyChainID string           `json:"counterparty_chain_id"`
+	LatestHeight        uint64           `json:"latest_height"`
+	Validators          []core.Validator `json:"validators"` // the validator set which finalizes the counterparty blocks
+}
+
+// ValidatorSet returns the counterparty validator set tracked by the client
+func (cs *IBCClientState) ValidatorSet() *core.ValidatorSet {
+	vs := core.NewValidatorSet()
+	for _, v := range cs.Validators {
+		vs.AddValidator(v)
+	}
+	return vs
+}
+
+// IBCConsensusState is the commitment to the counterparty state at a finalized counterparty block
+type IBCConsensusState struct {
+	Height    uint64      `json:"height"`
+	StateHash common.Hash `json:"state_hash"`
+	Timestamp *big.Int    `json:"timestamp"`
+}
+
+// IBCConnection is one end of a connection between two clients tracking each other's chain
+type IBCConnection struct {
+	ConnectionID             string            `json:"connection_id"`
+	ClientID                 string            `json:"client_id"`
+	CounterpartyClientID     string            `json:"counterparty_client_id"`
+	CounterpartyConnectionID string            `json:"counterparty_connection_id"` // empty until the counterparty end is created
+	State                    IBCHandshakeState `json:"state"`
+}
+
+// IBCPort binds a port to the address which owns the channels on the port. A port is claimed by the
+// address which opens the first channel on it, and only the owner can send packets from the port.
+type IBCPort struct {
+	PortID string         `json:"port_id"`
+	Owner  common.Address `json:"owner"`
+}
+
+// IBCChannel is one end of an unordered packet channel over a connection
+type IBCChannel struct {
+	PortID                string            `json:"port_id"`
+	ChannelID             string            `json:"channel_id"`
+	ConnectionID          string            `json:"connection_id"`
+	CounterpartyPortID    string            `json:"counterparty_port_id"`
+	CounterpartyChannelID string            `json:"counterparty_channel_id"` // empty until the counterparty end is created
+	State                 IBCHandshakeState `json:"state"`
+}
+
+// MaxIBCPacketDataSize is the maximum size of the data carried by a packet
+const MaxIBCPacketDataSize = 32 * 1024
+
+// IBCPacket is the unit of the data exchanged over a channel
+type IBCPacket struct {
+	Sequence           uint64       `json:"sequence"`
+	SourcePort         string       `json:"source_port"`
+	SourceChannel      string       `json:"source_channel"`
+	DestinationPort    string       `json:"destination_port"`
+	DestinationChannel string       `json:"destination_channel"`
+	Data               common.Bytes `json:"data"`
+	TimeoutHeight      uint64       `json:"timeout_height"` // block height of the destination chain, 0 for no timeout
+}
+
+// Commitment returns the hash of the packet committed in the state of the source chain
+func (p *IBCPacket) Commitment() common.Bytes {
+	raw, err := rlp.EncodeToBytes(p)
+	if err != nil {
+		// Should never happen
+		panic(err)
+	}
+	return crypto.Keccak256(raw)
+}
+
+// IBCAcknowledgementSuccess is the acknowledgement written for the packets received by this chain
+var IBCAcknowledgementSuccess = common.Bytes{0x01}
+
+// IBCAcknowledgementCommitment returns the hash of the acknowledgement committed in the state of
+// the destination chain
+func IBCAcknowledgementCommitment(ack common.Bytes) common.Bytes {
+	return crypto.Keccak256(ack)
+}
+
+//
+// ------- IBC Messages ------- //
+//
+
+// IBCMsgType identifies the message carried by an IBCTx
+type IBCMsgType uint8
+
+const (
+	IBCMsgCreateClient IBCMsgType = iota + 1
+	IBCMsgUpdateClient
+	IBCMsgConnectionOpenInit
+	IBCMsgConnectionOpenTry
+	IBCMsgConnectionOpenAck
+	IBCMsgConnectionOpenConfirm
+	IBCMsgChannelOpenInit
+	IBCMsgChannelOpenTry
+	IBCMsgChannelOpenAck
+	IBCMsgChannelOpenConfirm
+	IBCMsgSendPacket
+	IBCMsgRecvPacket
+	IBCMsgAcknowledgePacket
+	IBCMsgTimeoutPacket
+)
+
+// IBCMsg is a message of the IBC client, connection, channel or packet state machines
+type IBCMsg interface {
+	MsgType() IBCMsgType
+}
+
+// IBCProof is the Merkle proof of a value in the counterparty state, against the state hash of the
+// counterparty block at the height tracked by the client
+type IBCProof struct {
+	Height uint64
+	Nodes  []common.Bytes
+}
+
+// MsgCreateClient creates a client trusting the given counterparty validator set and block
+type MsgCreateClient struct {
+	CounterpartyChainID string
+	Validators          []core.Validator
+	Height              uint64
+	StateHash           common.Hash
+	Timestamp           *big.Int
+}
+
+// MsgUpdateClient adds a finalized counterparty block to the client. The block is proven by an
+// encoded bridge.ProofBundle. If the validator set of the counterparty changes at the block, the
+// client switches to the validators selected from the counterparty validator candidate pool, proven
+// against the state hash of the block.
+type MsgUpdateClient struct {
+	ClientID                    string
+	Bundle                      common.Bytes
+	ValidatorCandidatePoolProof []common.Bytes // optional
+}
+
+type MsgConnectionOpenInit struct {
+	ClientID             string
+	CounterpartyClientID string
+}
+
+type MsgConnectionOpenTry struct {
+	ClientID                 string
+	CounterpartyClientID     string
+	CounterpartyConnectionID string
+	Proof                    IBCProof // of the counterparty connection in the INIT state
+}
+
+type MsgConnectionOpenAck struct {
+	ConnectionID             string
+	CounterpartyConnectionID string
+	Proof                    IBCProof // of the counterparty connection in the TRYOPEN state
+}
+
+type MsgConnectionOpenConfirm struct {
+	ConnectionID string
+	Proof        IBCProof // of the counterparty connection in the OPEN state
+}
+
+type MsgChannelOpenInit struct {
+	PortID             string
+	ConnectionID       string
+	CounterpartyPortID string
+}
+
+type MsgChannelOpenTry struct {
+	PortID                string
+	ConnectionID          string
+	CounterpartyPortID    string
+	CounterpartyChannelID string
+	Proof                 IBCProof // of the counterparty channel in the INIT state
+}
+
+type MsgChannelOpenAck struct {
+	PortID                string
+	ChannelID             string
+	CounterpartyChannelID string
+	Proof                 IBCProof // of the counterparty channel in the TRYOPEN state
+}
+
+type MsgChannelOpenConfirm struct {
+	PortID    string
+	ChannelID string
+	Proof     IBCProof // of the counterparty channel in the OPEN state
+}
+
+type MsgSendPacket struct {
+	SourcePort    string
+	SourceChannel string
+	Data          common.Bytes
+	TimeoutHeight uint64
+}
+
+type MsgRecvPacket struct {
+	Packet IBCPacket
+	Proof  IBCProof // of the packet commitment on the source chain
+}
+
+type MsgAcknowledgePacket struct {
+	Packet          IBCPacket
+	Acknowledgement common.Bytes
+	Proof           IBCProof // of the acknowledgement commitment on the destination chain
+}
+
+type MsgTimeoutPacket struct {
+	Packet IBCPacket
+	Proof  IBCProof // of the absence of the packet receipt on the destination chain
+}
+
+func (MsgCreateClient) MsgType() IBCMsgType          { return IBCMsgCreateClient }
+func (MsgUpdateClient) MsgType() IBCMsgType          { return IBCMsgUpdateClient }
+func (MsgConnectionOpenInit) MsgType() IBCMsgType    { return IBCMsgConnectionOpenInit }
+func (MsgConnectionOpenTry) MsgType() IBCMsgType     { return IBCMsgConnectionOpenTry }
+func (MsgConnectionOpenAck) MsgType() IBCMsgType     { return IBCMsgConnectionOpenAck }
+func (MsgConnectionOpenConfirm) MsgType() IBCMsgType { return IBCMsgConnectionOpenConfirm }
+func (MsgChannelOpenInit) MsgType() IBCMsgType       { return IBCMsgChannelOpenInit }
+func (MsgChannelOpenTry) MsgType() IBCMsgType        { return IBCMsgChannelOpenTry }
+func (MsgChannelOpenAck) MsgType() IBCMsgType        { return IBCMsgChannelOpenAck }
+func (MsgChannelOpenConfirm) MsgType() IBCMsgType    { return IBCMsgChannelOpenConfirm }
+func (MsgSendPacket) MsgType() IBCMsgType            { return IBCMsgSendPacket }
+func (MsgRecvPacket) MsgType() IBCMsgType            { return IBCMsgRecvPacket }
+func (MsgAcknowledgePacket) MsgType() IBCMsgType     { return IBCMsgAcknowledgePacket }
+func (MsgTimeoutPacket) MsgType() IBCMsgType         { return IBCMsgTimeoutPacket }
+
+// EncodeIBCMsg encodes the message to be carried by an IBCTx
+func EncodeIBCMsg(msg IBCMsg) (common.Bytes, error) {
+	return rlp.EncodeToBytes(msg)
+}
+
+// DecodeIBCMsg decodes the message of the given type
+func DecodeIBCMsg(msgType IBCMsgType, raw common.Bytes) (IBCMsg, error) {
+	var msg IBCMsg
+	switch msgType {
+	case IBCMsgCreateClient:
+		msg = &MsgCreateClient{}
+	case IBCMsgUpdateClient:
+		msg = &MsgUpdateClient{}
+	case IBCMsgConnectionOpenInit:
+		msg = &MsgConnectionOpenInit{}
+	case IBCMsgConnectionOpenTry:
+		msg = &MsgConnectionOpenTry{}
+	case IBCMsgConnectionOpenAck:
+		msg = &MsgConnectionOpenAck{}
+	case IBCMsgConnectionOpenConfirm:
+		msg = &MsgConnectionOpenConfirm{}
+	case IBCMsgChannelOpenInit:
+		msg = &MsgChannelOpenInit{}
+	case IBCMsgChannelOpenTry:
+		msg = &MsgChannelOpenTry{}
+	case IBCMsgChannelOpenAck:
+		msg = &MsgChannelOpenAck{}
+	case IBCMsgChannelOpenConfirm:
+		msg = &MsgChannelOpenConfirm{}
+	case IBCMsgSendPacket:
+		msg = &MsgSendPacket{}
+	case IBCMsgRecvPacket:
+		msg = &MsgRecvPacket{}
+	case IBCMsgAcknowledgePacket:
+		msg = &MsgAcknowledgePacket{}
+	case IBCMsgTimeoutPacket:
+		msg = &MsgTimeoutPacket{}
+	default:
+		return nil, errors.Errorf("Unknown IBC message type: %v", msgType)
+	}
+	if err := rlp.DecodeBytes(raw, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
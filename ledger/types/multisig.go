@@ -0,0 +1,89 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rlp"
+)
+
+// ** MultiSig: Specifies an M-of-N multisignature account **
+//
+
+const (
+	// MaxMultiSigSigners specifies the max number of signers of a multisig account
+	MaxMultiSigSigners = 16
+
+	multiSigAddressPrefix = "multisig"
+)
+
+// MultiSigInfo specifies the signers of a multisig account, and the minimal number
+// of signatures (i.e. the threshold) required to authorize a transaction on its behalf.
+// The address of the multisig account is derived from the MultiSigInfo, hence the
+// account can receive coins like any other account before its signers are revealed.
+type MultiSigInfo struct {
+	Threshold uint             `json:"threshold"` // An integer between 1 and the number of signers
+	Signers   []common.Address `json:"signers"`   // Signer addresses, in ascending order
+}
+
+// NewMultiSigInfo creates a MultiSigInfo with the signers sorted in the canonical order.
+func NewMultiSigInfo(threshold uint, signers []common.Address) *MultiSigInfo {
+	sorted := make([]common.Address, len(signers))
+	copy(sorted, signers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+	return &MultiSigInfo{
+		Threshold: threshold,
+		Signers:   sorted,
+	}
+}
+
+// Address returns the address of the multisig account.
+func (ms *MultiSigInfo) Address() common.Address {
+	encoded, err := rlp.EncodeToBytes(ms)
+	if err != nil {
+		logger.Panicf("Failed to encode multisig info: %v", err)
+	}
+	hash := crypto.Keccak256Hash(append([]byte(multiSigAddressPrefix), encoded...))
+	return common.BytesToAddress(hash[12:])
+}
+
+// SignerIndex returns the index of the given signer, or -1 if the address is not a signer.
+func (ms *MultiSigInfo) SignerIndex(addr common.Address) int {
+	for i, signer := range ms.Signers {
+		if signer == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+// ValidateBasic checks the threshold and the signers. The signers must be distinct and in
+// ascending order so that each set of signers maps to exactly one multisig address.
+func (ms *MultiSigInfo) ValidateBasic() result.Result {
+	numSigners := len(ms.Signers)
+	if numSigners == 0 {
+		return result.Error("Multisig account needs at least one signer")
+	}
+	if numSigners > MaxMultiSigSigners {
+		return result.Error("Multisig account can have at most %v signers", MaxMultiSigSigners)
+	}
+	if ms.Threshold == 0 || ms.Threshold > uint(numSigners) {
+		return result.Error("Invalid multisig threshold %v, should be between 1 and %v", ms.Threshold, numSigners)
+	}
+	for i := 1; i < numSigners; i++ {
+		if bytes.Compare(ms.Signers[i-1][:], ms.Signers[i][:]) >= 0 {
+			return result.Error("Multisig signers must be distinct and sorted in ascending order")
+		}
+	}
+	return result.OK
+}
+
+func (ms *MultiSigInfo) String() string {
+	return fmt.Sprintf("MultiSigInfo{%v-of-%v, signers: %v}", ms.Threshold, len(ms.Signers), ms.Signers)
+}
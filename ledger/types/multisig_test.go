@@ -0,0 +1,88 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/thetatoken/theta/common"
+)
+
+func TestMultiSigInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	alice := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	bob := common.HexToAddress("0x9F1233798E905E173560071255140b4A8aBd3Ec6")
+	carol := common.HexToAddress("0x0d2fD67d573c8ecB4161510fc00754d64B401F86")
+
+	ms1 := NewMultiSigInfo(2, []common.Address{alice, bob, carol})
+	ms2 := NewMultiSigInfo(2, []common.Address{carol, alice, bob})
+	assert.True(ms1.ValidateBasic().IsOK())
+	assert.Equal(ms1.Address(), ms2.Address())
+	assert.Equal(carol, ms1.Signers[0])
+	assert.Equal(1, ms1.SignerIndex(alice))
+	assert.Equal(-1, ms1.SignerIndex(common.Address{}))
+
+	ms3 := NewMultiSigInfo(3, []common.Address{alice, bob, carol})
+	assert.NotEqual(ms1.Address(), ms3.Address())
+
+	assert.True(NewMultiSigInfo(0, []common.Address{alice, bob}).ValidateBasic().IsError())
+	assert.True(NewMultiSigInfo(3, []common.Address{alice, bob}).ValidateBasic().IsError())
+	assert.True(NewMultiSigInfo(1, []common.Address{alice, alice}).ValidateBasic().IsError())
+	assert.True((&MultiSigInfo{Threshold: 1, Signers: []common.Address{bob, alice}}).ValidateBasic().IsError())
+}
+
+func TestMultiSigSendTxSignatures(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID := "test_chain_id"
+	va1 := PrivAccountFromSecret("multisig_signer1")
+	va2 := PrivAccountFromSecret("multisig_signer2")
+	va3 := PrivAccountFromSecret("multisig_signer3")
+	ms := NewMultiSigInfo(2, []common.Address{va1.Address, va2.Address, va3.Address})
+
+	tx := &MultiSigSendTx{
+		Fee:      NewCoins(0, int64(MinimumTransactionFeeTFuelWeiJune2021)),
+		MultiSig: *ms,
+		Input:    NewTxInput(ms.Address(), NewCoins(10, int64(MinimumTransactionFeeTFuelWeiJune2021)), 1),
+		Outputs:  Accs2TxOutputs(MakeAcc("receiver")),
+	}
+	signBytes := tx.SignBytes(chainID)
+
+	// Each signer signs its own copy of the transaction
+	txBytes, err := TxToBytes(tx)
+	require.Nil(err)
+	partials := []*MultiSigSendTx{}
+	for _, va := range []PrivAccount{va1, va3} {
+		decoded, err := TxFromBytes(txBytes)
+		require.Nil(err)
+		partial := decoded.(*MultiSigSendTx)
+		assert.True(partial.SetSignature(va.Address, va.Sign(signBytes)))
+		assert.Equal(signBytes, partial.SignBytes(chainID))
+		partials = append(partials, partial)
+	}
+	assert.False(tx.SetSignature(MakeAcc("outsider").Address, va1.Sign(signBytes)))
+
+	// Assemble the signatures
+	for _, partial := range partials {
+		assert.True(tx.MergeSignatures(partial))
+	}
+	require.Equal(3, len(tx.Signatures))
+	assert.True(tx.Signatures[ms.SignerIndex(va1.Address)].Verify(signBytes, va1.Address))
+	assert.True(tx.Signatures[ms.SignerIndex(va3.Address)].Verify(signBytes, va3.Address))
+	assert.Nil(tx.Signatures[ms.SignerIndex(va2.Address)])
+
+	// Serialization round trip
+	txBytes, err = TxToBytes(tx)
+	require.Nil(err)
+	decoded, err := TxFromBytes(txBytes)
+	require.Nil(err)
+	tx2 := decoded.(*MultiSigSendTx)
+	assert.Equal(ms.Address(), tx2.MultiSig.Address())
+	assert.Equal(signBytes, tx2.SignBytes(chainID))
+	assert.True(tx2.Signatures[ms.SignerIndex(va1.Address)].Verify(signBytes, va1.Address))
+	missing := tx2.Signatures[ms.SignerIndex(va2.Address)]
+	assert.True(missing == nil || missing.IsEmpty())
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/thetatoken/theta/common"
+)
+
+const (
+	// TxReceiptStatusFailed indicates the transaction was included in the block, but its
+	// execution was reverted (e.g. a smart contract call that ran out of gas)
+	TxReceiptStatusFailed uint8 = 0
+
+	// TxReceiptStatusSuccess indicates the transaction was executed successfully
+	TxReceiptStatusSuccess uint8 = 1
+)
+
+// BalanceChange records the balance of an account before and after a transaction
+type BalanceChange struct {
+	Address common.Address `json:"address"`
+	Before  Coins          `json:"before"`
+	After   Coins          `json:"after"`
+}
+
+// TxReceipt is the canonical execution result of a transaction. The receipts of all the
+// transactions in a block are committed under the ReceiptHash of the block header, hence
+// all the fields need to be deterministic.
+type TxReceipt struct {
+	TxHash         common.Hash
+	Status         uint8
+	GasUsed        uint64
+	ReturnData     common.Bytes
+	Logs           []*Log
+	BalanceChanges []BalanceChange
+}
+
+type TxReceiptJSON struct {
+	TxHash         common.Hash       `json:"tx_hash"`
+	Status         uint8             `json:"status"`
+	GasUsed        common.JSONUint64 `json:"gas_used"`
+	ReturnData     common.Bytes      `json:"return_data"`
+	Logs           []*Log            `json:"logs"`
+	BalanceChanges []BalanceChange   `json:"balance_changes"`
+}
+
+func NewTxReceiptJSON(r TxReceipt) TxReceiptJSON {
+	return TxReceiptJSON{
+		TxHash:         r.TxHash,
+		Status:         r.Status,
+		GasUsed:        common.JSONUint64(r.GasUsed),
+		ReturnData:     r.ReturnData,
+		Logs:           r.Logs,
+		BalanceChanges: r.BalanceChanges,
+	}
+}
+
+func (r TxReceiptJSON) TxReceipt() TxReceipt {
+	return TxReceipt{
+		TxHash:         r.TxHash,
+		Status:         r.Status,
+		GasUsed:        uint64(r.GasUsed),
+		ReturnData:     r.ReturnData,
+		Logs:           r.Logs,
+		BalanceChanges: r.BalanceChanges,
+	}
+}
+
+func (r TxReceipt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewTxReceiptJSON(r))
+}
+
+func (r *TxReceipt) UnmarshalJSON(data []byte) error {
+	var a TxReceiptJSON
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	*r = a.TxReceipt()
+	return nil
+}
@@ -103,3 +103,43 @@ func (reservedFund *ReservedFund) HasResourceID(resourceID string) bool {
 	}
 	return false
 }
+
+// PendingServicePayment is a service payment against a reserved fund with a dispute window. It is
+// settled once the block height reaches SettlementHeight. Before that, it can be replaced by a
+// payment with a higher payment sequence, which keeps the original settlement height.
+type PendingServicePayment struct {
+	Payment          ServicePaymentTx
+	SettlementHeight uint64
+}
+
+type PendingServicePaymentJSON struct {
+	Payment          ServicePaymentTx  `json:"payment"`
+	SettlementHeight common.JSONUint64 `json:"settlement_height"`
+}
+
+func NewPendingServicePaymentJSON(p PendingServicePayment) PendingServicePaymentJSON {
+	return PendingServicePaymentJSON{
+		Payment:          p.Payment,
+		SettlementHeight: common.JSONUint64(p.SettlementHeight),
+	}
+}
+
+func (p PendingServicePaymentJSON) PendingServicePayment() PendingServicePayment {
+	return PendingServicePayment{
+		Payment:          p.Payment,
+		SettlementHeight: uint64(p.SettlementHeight),
+	}
+}
+
+func (p PendingServicePayment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewPendingServicePaymentJSON(p))
+}
+
+func (p *PendingServicePayment) UnmarshalJSON(data []byte) error {
+	var a PendingServicePaymentJSON
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	*p = a.PendingServicePayment()
+	return nil
+}
@@ -37,6 +37,19 @@ const (
 	TxWithdrawStake
 	TxDepositStakeV2
 	TxStakeRewardDistribution
+	TxMultiSigSend
+	TxVestingTransfer
+	TxVestingClaim
+	TxBatchSend
+	TxSplitRuleRenewal
+	TxReserveFundV2
+	TxTokenRegistry
+	TxSmartContractV2
+	TxValidatorKeyRotation
+	TxIBC
+	TxBandwidthProof
+	TxGovProposal
+	TxGovVote
 )
 
 func Fuzz(data []byte) int {
@@ -114,13 +127,65 @@ func TxFromBytes(raw []byte) (Tx, error) {
 		data := &StakeRewardDistributionTx{}
 		err = s.Decode(data)
 		return data, err
+	} else if txType == TxMultiSigSend {
+		data := &MultiSigSendTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxVestingTransfer {
+		data := &VestingTransferTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxVestingClaim {
+		data := &VestingClaimTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxBatchSend {
+		data := &BatchSendTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxSplitRuleRenewal {
+		data := &SplitRuleRenewalTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxReserveFundV2 {
+		data := &ReserveFundTxV2{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxTokenRegistry {
+		data := &TokenRegistryTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxSmartContractV2 {
+		data := &SmartContractTxV2{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxValidatorKeyRotation {
+		data := &ValidatorKeyRotationTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxIBC {
+		data := &IBCTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxBandwidthProof {
+		data := &BandwidthProofTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxGovProposal {
+		data := &GovProposalTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxGovVote {
+		data := &GovVoteTx{}
+		err = s.Decode(data)
+		return data, err
 	} else {
 		return nil, fmt.Errorf("Unknown TX type: %v", txType)
 	}
 }
 
-func TxToBytes(t Tx) ([]byte, error) {
-	var buf bytes.Buffer
+// GetTxType returns the type of the given transaction
+func GetTxType(t Tx) (TxType, error) {
 	var txType TxType
 	switch t.(type) {
 	case *CoinbaseTx:
@@ -147,10 +212,45 @@ func TxToBytes(t Tx) ([]byte, error) {
 		txType = TxDepositStakeV2
 	case *StakeRewardDistributionTx:
 		txType = TxStakeRewardDistribution
+	case *MultiSigSendTx:
+		txType = TxMultiSigSend
+	case *VestingTransferTx:
+		txType = TxVestingTransfer
+	case *VestingClaimTx:
+		txType = TxVestingClaim
+	case *BatchSendTx:
+		txType = TxBatchSend
+	case *SplitRuleRenewalTx:
+		txType = TxSplitRuleRenewal
+	case *ReserveFundTxV2:
+		txType = TxReserveFundV2
+	case *TokenRegistryTx:
+		txType = TxTokenRegistry
+	case *SmartContractTxV2:
+		txType = TxSmartContractV2
+	case *ValidatorKeyRotationTx:
+		txType = TxValidatorKeyRotation
+	case *IBCTx:
+		txType = TxIBC
+	case *BandwidthProofTx:
+		txType = TxBandwidthProof
+	case *GovProposalTx:
+		txType = TxGovProposal
+	case *GovVoteTx:
+		txType = TxGovVote
 	default:
-		return nil, errors.New("Unsupported message type")
+		return txType, errors.New("Unsupported message type")
+	}
+	return txType, nil
+}
+
+func TxToBytes(t Tx) ([]byte, error) {
+	var buf bytes.Buffer
+	txType, err := GetTxType(t)
+	if err != nil {
+		return nil, err
 	}
-	err := rlp.Encode(&buf, txType)
+	err = rlp.Encode(&buf, txType)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/thetatoken/theta/common"
+)
+
+// ** Token Registry: Metadata of the fungible token contracts (e.g. TNT-20) registered on chain **
+//
+
+var (
+	tokenNamespacePattern = regexp.MustCompile("^[a-z0-9_]+$")
+	tokenSymbolPattern    = regexp.MustCompile("^[A-Z0-9]+$")
+)
+
+// TokenNamespace groups the registered tokens. A namespace is claimed by the address that registers
+// the first token under it. After that, the namespace is governed by its owner, i.e. only the owner
+// can register new tokens or update the registered tokens under the namespace.
+type TokenNamespace struct {
+	Name  string         `json:"name"`
+	Owner common.Address `json:"owner"`
+}
+
+// TokenInfo is the metadata of a registered fungible token
+type TokenInfo struct {
+	Namespace        string         // Namespace the token is registered under
+	Symbol           string         // Symbol of the token, unique within the namespace
+	Name             string         // Display name of the token
+	Decimals         uint8          // Number of decimals of the token amounts
+	ContractAddress  common.Address // Address of the token contract
+	RegisteredHeight uint64         // Block height at which the token was first registered
+}
+
+type TokenInfoJSON struct {
+	Namespace        string            `json:"namespace"`
+	Symbol           string            `json:"symbol"`
+	Name             string            `json:"name"`
+	Decimals         uint8             `json:"decimals"`
+	ContractAddress  common.Address    `json:"contract_address"`
+	RegisteredHeight common.JSONUint64 `json:"registered_height"`
+}
+
+func NewTokenInfoJSON(a TokenInfo) TokenInfoJSON {
+	return TokenInfoJSON{
+		Namespace:        a.Namespace,
+		Symbol:           a.Symbol,
+		Name:             a.Name,
+		Decimals:         a.Decimals,
+		ContractAddress:  a.ContractAddress,
+		RegisteredHeight: common.JSONUint64(a.RegisteredHeight),
+	}
+}
+
+func (a TokenInfoJSON) TokenInfo() TokenInfo {
+	return TokenInfo{
+		Namespace:        a.Namespace,
+		Symbol:           a.Symbol,
+		Name:             a.Name,
+		Decimals:         a.Decimals,
+		ContractAddress:  a.ContractAddress,
+		RegisteredHeight: uint64(a.RegisteredHeight),
+	}
+}
+
+func (a TokenInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewTokenInfoJSON(a))
+}
+
+func (a *TokenInfo) UnmarshalJSON(data []byte) error {
+	var b TokenInfoJSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.TokenInfo()
+	return nil
+}
+
+func (a *TokenInfo) String() string {
+	return fmt.Sprintf("TokenInfo{%v/%v, name: %v, decimals: %v, contract: %v, registered_height: %v}",
+		a.Namespace, a.Symbol, a.Name, a.Decimals, a.ContractAddress.Hex(), a.RegisteredHeight)
+}
+
+// ValidateTokenNamespace checks that the namespace consists of lower case letters, digits and underscores
+func ValidateTokenNamespace(namespace string) error {
+	if len(namespace) == 0 || len(namespace) > MaxTokenNamespaceLength {
+		return errors.Errorf("Token namespace needs to have 1 to %v characters", MaxTokenNamespaceLength)
+	}
+	if !tokenNamespacePattern.MatchString(namespace) {
+		return errors.Errorf("Token namespace can only contain lower case letters, digits and underscores: %v", namespace)
+	}
+	return nil
+}
+
+// ValidateTokenSymbol checks that the symbol consists of upper case letters and digits
+func ValidateTokenSymbol(symbol string) error {
+	if len(symbol) == 0 || len(symbol) > MaxTokenSymbolLength {
+		return errors.Errorf("Token symbol needs to have 1 to %v characters", MaxTokenSymbolLength)
+	}
+	if !tokenSymbolPattern.MatchString(symbol) {
+		return errors.Errorf("Token symbol can only contain upper case letters and digits: %v", symbol)
+	}
+	return nil
+}
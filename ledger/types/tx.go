@@ -11,6 +11,7 @@ import (
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/crypto/bls"
@@ -18,7 +19,7 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "ledger"})
+var logger = util.GetLoggerForModule("ledger")
 
 /*
 Tx (Transaction) is an atomic operation on the ledger state.
@@ -28,13 +29,26 @@ Transaction Types:
  - SlashTx     			   Transaction for slashing dishonest user
  - SendTx                  Send coins to address
  - ReserveFundTx           Reserve fund for subsequence service payments
+ - ReserveFundTxV2         Reserve fund with a dispute window for the service payment settlement
+ - TokenRegistryTx         Register the metadata of a fungible token contract
  - ReleaseFundTx           Release fund reserved for service payments
  - ServicePaymentTx        Payments for service
  - SplitRuleTx             Payment split rule
  - DepositStakeTx          Deposit stake to a target address (e.g. a validator)
  - WithdrawStakeTx         Withdraw stake from a target address (e.g. a validator)
  - SmartContractTx         Execute smart contract
+ - SmartContractTxV2       Execute smart contract with the specified runtime, e.g. the EVM or the WASM runtime
  - StakeRewardDistribution Defines how stake reward is distributed
+ - MultiSigSendTx          Send coins from a multisig account
+ - VestingTransferTx       Lock coins for a beneficiary until a block height/time, with optional linear vesting
+ - VestingClaimTx          Claim the vested coins of a vesting fund
+ - BatchSendTx             Send coins from one address to multiple addresses atomically
+ - SplitRuleRenewalTx      Extend the end block height of an existing split rule
+ - ValidatorKeyRotationTx  Announce a new validator signing key which activates at a future epoch
+ - IBCTx                   Drive the IBC client, connection, channel and packet state machines
+ - BandwidthProofTx        Claim the reward of an edge node for the bandwidth relayed for a pool
+ - GovProposalTx           Submit a governance proposal, e.g. to change the fee or the upgrade schedule
+ - GovVoteTx               Vote on a governance proposal with the Theta staked by the voter
 */
 
 // Gas of regular transactions
@@ -59,6 +73,16 @@ type Tx interface {
 	SignBytes(chainID string) []byte
 }
 
+// ExpirableTx is implemented by the user signed transactions. A transaction with a non-zero
+// NotAfterHeight can no longer be included in a block once the chain passes that height, so
+// that a stale signed transaction cannot be replayed by others much later. The field is RLP
+// encoded as an optional field, so that transactions without an expiry keep their original
+// encoding.
+type ExpirableTx interface {
+	Tx
+	GetNotAfterHeight() uint64
+}
+
 //-----------------------------------------------------------------------------
 
 func TxID(chainID string, tx Tx) common.Hash {
@@ -73,6 +97,69 @@ func TxID(chainID string, tx Tx) common.Hash {
 	return crypto.Keccak256Hash(signBytes)
 }
 
+// GetMaxTxFee returns the maximum fee the transaction pays. For the smart contract transactions,
+// it is the fee for the full gas limit, while the actual fee depends on the gas used.
+func GetMaxTxFee(tx Tx) Coins {
+	switch tx := tx.(type) {
+	case *SmartContractTx:
+		return smartContractMaxFee(tx.GasPrice, tx.GasLimit)
+	case *SmartContractTxV2:
+		return smartContractMaxFee(tx.GasPrice, tx.GasLimit)
+	case *SendTx:
+		return tx.Fee.NoNil()
+	case *ReserveFundTx:
+		return tx.Fee.NoNil()
+	case *ReserveFundTxV2:
+		return tx.Fee.NoNil()
+	case *ReleaseFundTx:
+		return tx.Fee.NoNil()
+	case *ServicePaymentTx:
+		return tx.Fee.NoNil()
+	case *SplitRuleTx:
+		return tx.Fee.NoNil()
+	case *SplitRuleRenewalTx:
+		return tx.Fee.NoNil()
+	case *DepositStakeTx:
+		return tx.Fee.NoNil()
+	case *DepositStakeTxV2:
+		return tx.Fee.NoNil()
+	case *WithdrawStakeTx:
+		return tx.Fee.NoNil()
+	case *StakeRewardDistributionTx:
+		return tx.Fee.NoNil()
+	case *MultiSigSendTx:
+		return tx.Fee.NoNil()
+	case *VestingTransferTx:
+		return tx.Fee.NoNil()
+	case *VestingClaimTx:
+		return tx.Fee.NoNil()
+	case *BatchSendTx:
+		return tx.Fee.NoNil()
+	case *TokenRegistryTx:
+		return tx.Fee.NoNil()
+	case *ValidatorKeyRotationTx:
+		return tx.Fee.NoNil()
+	case *IBCTx:
+		return tx.Fee.NoNil()
+	case *BandwidthProofTx:
+		return tx.Fee.NoNil()
+	case *GovProposalTx:
+		return tx.Fee.NoNil()
+	case *GovVoteTx:
+		return tx.Fee.NoNil()
+	default: // the coinbase and slash transactions do not pay fees
+		return NewCoins(0, 0)
+	}
+}
+
+func smartContractMaxFee(gasPrice *big.Int, gasLimit uint64) Coins {
+	fee := NewCoins(0, 0)
+	if gasPrice != nil {
+		fee.TFuelWei = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+	}
+	return fee
+}
+
 //--------------------------------------------------------------------------------
 
 // Contract: This function is deterministic and completely reversible.
@@ -344,14 +431,27 @@ func (tx *SlashTx) String() string {
 
 //-----------------------------------------------------------------------------
 
+//
+// SendTx transfers tokens from the inputs to the outputs. Optionally, a third party can pay the
+// fee on behalf of the inputs as the FeePayer, in which case the input total only needs to cover
+// the output total. FeePayer holds at most one fee payer, whose Coins specify the maximum fee it
+// agrees to pay. It is RLP encoded as an optional field, so that transactions without a fee payer
+// keep their original encoding.
+//
 type SendTx struct {
-	Fee     Coins      `json:"fee"` // Fee
-	Inputs  []TxInput  `json:"inputs"`
-	Outputs []TxOutput `json:"outputs"`
+	Fee            Coins      `json:"fee"` // Fee
+	Inputs         []TxInput  `json:"inputs"`
+	Outputs        []TxOutput `json:"outputs"`
+	FeePayer       []TxInput  `json:"fee_payer,omitempty" rlp:"optional"`
+	NotAfterHeight uint64     `json:"not_after_height,omitempty" rlp:"optional"`
 }
 
 func (_ *SendTx) AssertIsTx() {}
 
+func (tx *SendTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *SendTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sigz := make([]*crypto.Signature, len(tx.Inputs))
@@ -359,6 +459,11 @@ func (tx *SendTx) SignBytes(chainID string) []byte {
 		sigz[i] = tx.Inputs[i].Signature
 		tx.Inputs[i].Signature = nil
 	}
+	feePayerSigz := make([]*crypto.Signature, len(tx.FeePayer))
+	for i := range tx.FeePayer {
+		feePayerSigz[i] = tx.FeePayer[i].Signature
+		tx.FeePayer[i].Signature = nil
+	}
 	txBytes, _ := TxToBytes(tx)
 	signBytes = append(signBytes, txBytes...)
 	signBytes = addPrefixForSignBytes(signBytes)
@@ -366,6 +471,9 @@ func (tx *SendTx) SignBytes(chainID string) []byte {
 	for i := range tx.Inputs {
 		tx.Inputs[i].Signature = sigz[i]
 	}
+	for i := range tx.FeePayer {
+		tx.FeePayer[i].Signature = feePayerSigz[i]
+	}
 	return signBytes
 }
 
@@ -376,6 +484,12 @@ func (tx *SendTx) SetSignature(addr common.Address, sig *crypto.Signature) bool
 			return true
 		}
 	}
+	for i, feePayer := range tx.FeePayer {
+		if feePayer.Address == addr {
+			tx.FeePayer[i].Signature = sig
+			return true
+		}
+	}
 	return false
 }
 
@@ -386,38 +500,42 @@ func (tx *SendTx) String() string {
 //-----------------------------------------------------------------------------
 
 type ReserveFundTx struct {
-	Fee         Coins    // Fee
-	Source      TxInput  // Source account
-	Collateral  Coins    // Collateral for the micropayment pool
-	ResourceIDs []string // List of resource ID
-	Duration    uint64
+	Fee            Coins    // Fee
+	Source         TxInput  // Source account
+	Collateral     Coins    // Collateral for the micropayment pool
+	ResourceIDs    []string // List of resource ID
+	Duration       uint64
+	NotAfterHeight uint64 `rlp:"optional"`
 }
 
 type ReserveFundTxJSON struct {
-	Fee         Coins             `json:"fee"`          // Fee
-	Source      TxInput           `json:"source"`       // Source account
-	Collateral  Coins             `json:"collateral"`   // Collateral for the micropayment pool
-	ResourceIDs []string          `json:"resource_ids"` // List of resource ID
-	Duration    common.JSONUint64 `json:"duration"`
+	Fee            Coins             `json:"fee"`          // Fee
+	Source         TxInput           `json:"source"`       // Source account
+	Collateral     Coins             `json:"collateral"`   // Collateral for the micropayment pool
+	ResourceIDs    []string          `json:"resource_ids"` // List of resource ID
+	Duration       common.JSONUint64 `json:"duration"`
+	NotAfterHeight common.JSONUint64 `json:"not_after_height,omitempty"`
 }
 
 func NewReserveFundTxJSON(a ReserveFundTx) ReserveFundTxJSON {
 	return ReserveFundTxJSON{
-		Fee:         a.Fee,
-		Source:      a.Source,
-		Collateral:  a.Collateral,
-		ResourceIDs: a.ResourceIDs,
-		Duration:    common.JSONUint64(a.Duration),
+		Fee:            a.Fee,
+		Source:         a.Source,
+		Collateral:     a.Collateral,
+		ResourceIDs:    a.ResourceIDs,
+		Duration:       common.JSONUint64(a.Duration),
+		NotAfterHeight: common.JSONUint64(a.NotAfterHeight),
 	}
 }
 
 func (a ReserveFundTxJSON) ReserveFundTx() ReserveFundTx {
 	return ReserveFundTx{
-		Fee:         a.Fee,
-		Source:      a.Source,
-		Collateral:  a.Collateral,
-		ResourceIDs: a.ResourceIDs,
-		Duration:    uint64(a.Duration),
+		Fee:            a.Fee,
+		Source:         a.Source,
+		Collateral:     a.Collateral,
+		ResourceIDs:    a.ResourceIDs,
+		Duration:       uint64(a.Duration),
+		NotAfterHeight: uint64(a.NotAfterHeight),
 	}
 }
 
@@ -436,6 +554,10 @@ func (a *ReserveFundTx) UnmarshalJSON(data []byte) error {
 
 func (_ *ReserveFundTx) AssertIsTx() {}
 
+func (tx *ReserveFundTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *ReserveFundTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sig := tx.Source.Signature
@@ -461,18 +583,112 @@ func (tx *ReserveFundTx) String() string {
 		tx.Fee, tx.Source, tx.Collateral, tx.ResourceIDs, tx.Duration)
 }
 
+// ReserveFundTxV2 is a ReserveFundTx with a dispute window. If the dispute window is non-zero,
+// the service payments against the reserved fund are not settled immediately. Instead, they stay
+// pending for DisputeWindow blocks, during which the target can submit a signed payment with a
+// higher payment sequence to replace a stale one.
+type ReserveFundTxV2 struct {
+	Fee            Coins    // Fee
+	Source         TxInput  // Source account
+	Collateral     Coins    // Collateral for the micropayment pool
+	ResourceIDs    []string // List of resource ID
+	Duration       uint64
+	DisputeWindow  uint64 // Number of blocks before a service payment is settled
+	NotAfterHeight uint64 `rlp:"optional"`
+}
+
+type ReserveFundTxV2JSON struct {
+	Fee            Coins             `json:"fee"`          // Fee
+	Source         TxInput           `json:"source"`       // Source account
+	Collateral     Coins             `json:"collateral"`   // Collateral for the micropayment pool
+	ResourceIDs    []string          `json:"resource_ids"` // List of resource ID
+	Duration       common.JSONUint64 `json:"duration"`
+	DisputeWindow  common.JSONUint64 `json:"dispute_window"`
+	NotAfterHeight common.JSONUint64 `json:"not_after_height,omitempty"`
+}
+
+func NewReserveFundTxV2JSON(a ReserveFundTxV2) ReserveFundTxV2JSON {
+	return ReserveFundTxV2JSON{
+		Fee:            a.Fee,
+		Source:         a.Source,
+		Collateral:     a.Collateral,
+		ResourceIDs:    a.ResourceIDs,
+		Duration:       common.JSONUint64(a.Duration),
+		DisputeWindow:  common.JSONUint64(a.DisputeWindow),
+		NotAfterHeight: common.JSONUint64(a.NotAfterHeight),
+	}
+}
+
+func (a ReserveFundTxV2JSON) ReserveFundTxV2() ReserveFundTxV2 {
+	return ReserveFundTxV2{
+		Fee:            a.Fee,
+		Source:         a.Source,
+		Collateral:     a.Collateral,
+		ResourceIDs:    a.ResourceIDs,
+		Duration:       uint64(a.Duration),
+		DisputeWindow:  uint64(a.DisputeWindow),
+		NotAfterHeight: uint64(a.NotAfterHeight),
+	}
+}
+
+func (a ReserveFundTxV2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewReserveFundTxV2JSON(a))
+}
+
+func (a *ReserveFundTxV2) UnmarshalJSON(data []byte) error {
+	var b ReserveFundTxV2JSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.ReserveFundTxV2()
+	return nil
+}
+
+func (_ *ReserveFundTxV2) AssertIsTx() {}
+
+func (tx *ReserveFundTxV2) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *ReserveFundTxV2) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Source.Signature
+	tx.Source.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Source.Signature = sig
+	return signBytes
+}
+
+func (tx *ReserveFundTxV2) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Source.Address == addr {
+		tx.Source.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *ReserveFundTxV2) String() string {
+	return fmt.Sprintf("ReserveFundTxV2{fee: %v, source: %v, collateral: %v, resource_ids: %v, duration: %v, dispute_window: %v}",
+		tx.Fee, tx.Source, tx.Collateral, tx.ResourceIDs, tx.Duration, tx.DisputeWindow)
+}
+
 //-----------------------------------------------------------------------------
 
 type ReleaseFundTx struct {
 	Fee             Coins   // Fee
 	Source          TxInput // source account
 	ReserveSequence uint64
+	NotAfterHeight  uint64 `rlp:"optional"`
 }
 
 type ReleaseFundTxJSON struct {
 	Fee             Coins             `json:"fee"`    // Fee
 	Source          TxInput           `json:"source"` // source account
 	ReserveSequence common.JSONUint64 `json:"reserve_sequence"`
+	NotAfterHeight  common.JSONUint64 `json:"not_after_height,omitempty"`
 }
 
 func NewReleaseFundTxJSON(a ReleaseFundTx) ReleaseFundTxJSON {
@@ -480,6 +696,7 @@ func NewReleaseFundTxJSON(a ReleaseFundTx) ReleaseFundTxJSON {
 		Fee:             a.Fee,
 		Source:          a.Source,
 		ReserveSequence: common.JSONUint64(a.ReserveSequence),
+		NotAfterHeight:  common.JSONUint64(a.NotAfterHeight),
 	}
 }
 
@@ -488,6 +705,7 @@ func (a ReleaseFundTxJSON) ReleaseFundTx() ReleaseFundTx {
 		Fee:             a.Fee,
 		Source:          a.Source,
 		ReserveSequence: uint64(a.ReserveSequence),
+		NotAfterHeight:  uint64(a.NotAfterHeight),
 	}
 }
 
@@ -506,6 +724,10 @@ func (a *ReleaseFundTx) UnmarshalJSON(data []byte) error {
 
 func (_ *ReleaseFundTx) AssertIsTx() {}
 
+func (tx *ReleaseFundTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *ReleaseFundTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sig := tx.Source.Signature
@@ -539,6 +761,7 @@ type ServicePaymentTx struct {
 	PaymentSequence uint64  // each on-chain settlement needs to increase the payment sequence by 1
 	ReserveSequence uint64  // ReserveSequence to locate the ReservedFund
 	ResourceID      string  // The corresponding resourceID
+	NotAfterHeight  uint64  `rlp:"optional"`
 }
 
 type ServicePaymentTxJSON struct {
@@ -548,6 +771,7 @@ type ServicePaymentTxJSON struct {
 	PaymentSequence common.JSONUint64 `json:"payment_sequence"` // each on-chain settlement needs to increase the payment sequence by 1
 	ReserveSequence common.JSONUint64 `json:"reserve_sequence"` // ReserveSequence to locate the ReservedFund
 	ResourceID      string            `json:"resource_id"`      // The corresponding resourceID
+	NotAfterHeight  common.JSONUint64 `json:"not_after_height,omitempty"`
 }
 
 func NewServicePaymentTxJSON(a ServicePaymentTx) ServicePaymentTxJSON {
@@ -558,6 +782,7 @@ func NewServicePaymentTxJSON(a ServicePaymentTx) ServicePaymentTxJSON {
 		PaymentSequence: common.JSONUint64(a.PaymentSequence),
 		ReserveSequence: common.JSONUint64(a.ReserveSequence),
 		ResourceID:      a.ResourceID,
+		NotAfterHeight:  common.JSONUint64(a.NotAfterHeight),
 	}
 }
 
@@ -569,6 +794,7 @@ func (a ServicePaymentTxJSON) ServicePaymentTx() ServicePaymentTx {
 		PaymentSequence: uint64(a.PaymentSequence),
 		ReserveSequence: uint64(a.ReserveSequence),
 		ResourceID:      a.ResourceID,
+		NotAfterHeight:  uint64(a.NotAfterHeight),
 	}
 }
 
@@ -587,6 +813,10 @@ func (a *ServicePaymentTx) UnmarshalJSON(data []byte) error {
 
 func (_ *ServicePaymentTx) AssertIsTx() {}
 
+func (tx *ServicePaymentTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *ServicePaymentTx) SourceSignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 
@@ -655,38 +885,42 @@ func (tx *ServicePaymentTx) TxBytes() ([]byte, error) {
 //-----------------------------------------------------------------------------
 
 type SplitRuleTx struct {
-	Fee        Coins   // Fee
-	ResourceID string  // ResourceID of the payment to be split
-	Initiator  TxInput // Initiator of the split rule
-	Splits     []Split // Agreed splits
-	Duration   uint64  // Duration of the payment split in terms of blocks
+	Fee            Coins   // Fee
+	ResourceID     string  // ResourceID of the payment to be split
+	Initiator      TxInput // Initiator of the split rule
+	Splits         []Split // Agreed splits
+	Duration       uint64  // Duration of the payment split in terms of blocks
+	NotAfterHeight uint64  `rlp:"optional"`
 }
 
 type SplitRuleTxJSON struct {
-	Fee        Coins             `json:"fee"`         // Fee
-	ResourceID string            `json:"resource_id"` // ResourceID of the payment to be split
-	Initiator  TxInput           `json:"initiator"`   // Initiator of the split rule
-	Splits     []Split           `json:"splits"`      // Agreed splits
-	Duration   common.JSONUint64 `json:"duration"`    // Duration of the payment split in terms of blocks
+	Fee            Coins             `json:"fee"`         // Fee
+	ResourceID     string            `json:"resource_id"` // ResourceID of the payment to be split
+	Initiator      TxInput           `json:"initiator"`   // Initiator of the split rule
+	Splits         []Split           `json:"splits"`      // Agreed splits
+	Duration       common.JSONUint64 `json:"duration"`    // Duration of the payment split in terms of blocks
+	NotAfterHeight common.JSONUint64 `json:"not_after_height,omitempty"`
 }
 
 func NewSplitRuleTxJSON(a SplitRuleTx) SplitRuleTxJSON {
 	return SplitRuleTxJSON{
-		Fee:        a.Fee,
-		ResourceID: a.ResourceID,
-		Initiator:  a.Initiator,
-		Splits:     a.Splits,
-		Duration:   common.JSONUint64(a.Duration),
+		Fee:            a.Fee,
+		ResourceID:     a.ResourceID,
+		Initiator:      a.Initiator,
+		Splits:         a.Splits,
+		Duration:       common.JSONUint64(a.Duration),
+		NotAfterHeight: common.JSONUint64(a.NotAfterHeight),
 	}
 }
 
 func (a SplitRuleTxJSON) SplitRuleTx() SplitRuleTx {
 	return SplitRuleTx{
-		Fee:        a.Fee,
-		ResourceID: a.ResourceID,
-		Initiator:  a.Initiator,
-		Splits:     a.Splits,
-		Duration:   uint64(a.Duration),
+		Fee:            a.Fee,
+		ResourceID:     a.ResourceID,
+		Initiator:      a.Initiator,
+		Splits:         a.Splits,
+		Duration:       uint64(a.Duration),
+		NotAfterHeight: uint64(a.NotAfterHeight),
 	}
 }
 
@@ -705,6 +939,10 @@ func (a *SplitRuleTx) UnmarshalJSON(data []byte) error {
 
 func (_ *SplitRuleTx) AssertIsTx() {}
 
+func (tx *SplitRuleTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *SplitRuleTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sig := tx.Initiator.Signature
@@ -732,39 +970,138 @@ func (tx *SplitRuleTx) String() string {
 
 //-----------------------------------------------------------------------------
 
+//
+// SplitRuleRenewalTx extends the split rule of the given resourceID by Duration blocks, without changing
+// the splits. Only the initiator of the split rule can renew it, and the split rule must not have expired.
+//
+type SplitRuleRenewalTx struct {
+	Fee            Coins   // Fee
+	ResourceID     string  // ResourceID of the split rule to be renewed
+	Initiator      TxInput // Initiator of the split rule
+	Duration       uint64  // Number of blocks to extend the split rule by
+	NotAfterHeight uint64  `rlp:"optional"`
+}
+
+type SplitRuleRenewalTxJSON struct {
+	Fee            Coins             `json:"fee"`         // Fee
+	ResourceID     string            `json:"resource_id"` // ResourceID of the split rule to be renewed
+	Initiator      TxInput           `json:"initiator"`   // Initiator of the split rule
+	Duration       common.JSONUint64 `json:"duration"`    // Number of blocks to extend the split rule by
+	NotAfterHeight common.JSONUint64 `json:"not_after_height,omitempty"`
+}
+
+func NewSplitRuleRenewalTxJSON(a SplitRuleRenewalTx) SplitRuleRenewalTxJSON {
+	return SplitRuleRenewalTxJSON{
+		Fee:            a.Fee,
+		ResourceID:     a.ResourceID,
+		Initiator:      a.Initiator,
+		Duration:       common.JSONUint64(a.Duration),
+		NotAfterHeight: common.JSONUint64(a.NotAfterHeight),
+	}
+}
+
+func (a SplitRuleRenewalTxJSON) SplitRuleRenewalTx() SplitRuleRenewalTx {
+	return SplitRuleRenewalTx{
+		Fee:            a.Fee,
+		ResourceID:     a.ResourceID,
+		Initiator:      a.Initiator,
+		Duration:       uint64(a.Duration),
+		NotAfterHeight: uint64(a.NotAfterHeight),
+	}
+}
+
+func (a SplitRuleRenewalTx) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewSplitRuleRenewalTxJSON(a))
+}
+
+func (a *SplitRuleRenewalTx) UnmarshalJSON(data []byte) error {
+	var b SplitRuleRenewalTxJSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.SplitRuleRenewalTx()
+	return nil
+}
+
+func (_ *SplitRuleRenewalTx) AssertIsTx() {}
+
+func (tx *SplitRuleRenewalTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *SplitRuleRenewalTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Initiator.Signature
+	tx.Initiator.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Initiator.Signature = sig
+	return signBytes
+}
+
+func (tx *SplitRuleRenewalTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Initiator.Address == addr {
+		tx.Initiator.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *SplitRuleRenewalTx) String() string {
+	return fmt.Sprintf("SplitRuleRenewalTx{fee: %v, resource_id: %v, initiator: %v, duration: %v}",
+		tx.Fee, tx.ResourceID, tx.Initiator, tx.Duration)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// SmartContractTx deploys or calls a smart contract. Similar to the SendTx, an optional FeePayer
+// can pay the gas fee on behalf of the caller, in which case the caller's balance only needs to
+// cover the value to transfer.
+//
 type SmartContractTx struct {
-	From     TxInput
-	To       TxOutput
-	GasLimit uint64
-	GasPrice *big.Int
-	Data     common.Bytes
+	From           TxInput
+	To             TxOutput
+	GasLimit       uint64
+	GasPrice       *big.Int
+	Data           common.Bytes
+	FeePayer       []TxInput `rlp:"optional"`
+	NotAfterHeight uint64    `rlp:"optional"`
 }
 
 type SmartContractTxJSON struct {
-	From     TxInput           `json:"from"`
-	To       TxOutput          `json:"to"`
-	GasLimit common.JSONUint64 `json:"gas_limit"`
-	GasPrice *common.JSONBig   `json:"gas_price"`
-	Data     common.Bytes      `json:"data"`
+	From           TxInput           `json:"from"`
+	To             TxOutput          `json:"to"`
+	GasLimit       common.JSONUint64 `json:"gas_limit"`
+	GasPrice       *common.JSONBig   `json:"gas_price"`
+	Data           common.Bytes      `json:"data"`
+	FeePayer       []TxInput         `json:"fee_payer,omitempty"`
+	NotAfterHeight common.JSONUint64 `json:"not_after_height,omitempty"`
 }
 
 func NewSmartContractTxJSON(a SmartContractTx) SmartContractTxJSON {
 	return SmartContractTxJSON{
-		From:     a.From,
-		To:       a.To,
-		GasLimit: common.JSONUint64(a.GasLimit),
-		GasPrice: (*common.JSONBig)(a.GasPrice),
-		Data:     a.Data,
+		From:           a.From,
+		To:             a.To,
+		GasLimit:       common.JSONUint64(a.GasLimit),
+		GasPrice:       (*common.JSONBig)(a.GasPrice),
+		Data:           a.Data,
+		FeePayer:       a.FeePayer,
+		NotAfterHeight: common.JSONUint64(a.NotAfterHeight),
 	}
 }
 
 func (a SmartContractTxJSON) SmartContractTx() SmartContractTx {
 	return SmartContractTx{
-		From:     a.From,
-		To:       a.To,
-		GasLimit: uint64(a.GasLimit),
-		GasPrice: (*big.Int)(a.GasPrice),
-		Data:     a.Data,
+		From:           a.From,
+		To:             a.To,
+		GasLimit:       uint64(a.GasLimit),
+		GasPrice:       (*big.Int)(a.GasPrice),
+		Data:           a.Data,
+		FeePayer:       a.FeePayer,
+		NotAfterHeight: uint64(a.NotAfterHeight),
 	}
 }
 
@@ -783,15 +1120,27 @@ func (a *SmartContractTx) UnmarshalJSON(data []byte) error {
 
 func (_ *SmartContractTx) AssertIsTx() {}
 
+func (tx *SmartContractTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *SmartContractTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sig := tx.From.Signature
 	tx.From.Signature = nil
+	feePayerSigz := make([]*crypto.Signature, len(tx.FeePayer))
+	for i := range tx.FeePayer {
+		feePayerSigz[i] = tx.FeePayer[i].Signature
+		tx.FeePayer[i].Signature = nil
+	}
 	txBytes, _ := TxToBytes(tx)
 	signBytes = append(signBytes, txBytes...)
 	signBytes = addPrefixForSignBytes(signBytes)
 
 	tx.From.Signature = sig
+	for i := range tx.FeePayer {
+		tx.FeePayer[i].Signature = feePayerSigz[i]
+	}
 	return signBytes
 }
 
@@ -838,6 +1187,12 @@ func (tx *SmartContractTx) SetSignature(addr common.Address, sig *crypto.Signatu
 		tx.From.Signature = sig
 		return true
 	}
+	for i, feePayer := range tx.FeePayer {
+		if feePayer.Address == addr {
+			tx.FeePayer[i].Signature = sig
+			return true
+		}
+	}
 	return false
 }
 
@@ -848,15 +1203,149 @@ func (tx *SmartContractTx) String() string {
 
 //-----------------------------------------------------------------------------
 
+// Smart contract runtimes
+const (
+	ContractRuntimeEVM  uint8 = 0
+	ContractRuntimeWASM uint8 = 1
+)
+
+// SmartContractTxV2 deploys or calls a smart contract with the specified runtime. The runtime of
+// a deployed contract is determined by its code, a call with a different runtime fails.
+type SmartContractTxV2 struct {
+	From           TxInput
+	To             TxOutput
+	GasLimit       uint64
+	GasPrice       *big.Int
+	Data           common.Bytes
+	Runtime        uint8
+	FeePayer       []TxInput `rlp:"optional"`
+	NotAfterHeight uint64    `rlp:"optional"`
+}
+
+type SmartContractTxV2JSON struct {
+	From           TxInput           `json:"from"`
+	To             TxOutput          `json:"to"`
+	GasLimit       common.JSONUint64 `json:"gas_limit"`
+	GasPrice       *common.JSONBig   `json:"gas_price"`
+	Data           common.Bytes      `json:"data"`
+	Runtime        uint8             `json:"runtime"`
+	FeePayer       []TxInput         `json:"fee_payer,omitempty"`
+	NotAfterHeight common.JSONUint64 `json:"not_after_height,omitempty"`
+}
+
+func NewSmartContractTxV2JSON(a SmartContractTxV2) SmartContractTxV2JSON {
+	return SmartContractTxV2JSON{
+		From:           a.From,
+		To:             a.To,
+		GasLimit:       common.JSONUint64(a.GasLimit),
+		GasPrice:       (*common.JSONBig)(a.GasPrice),
+		Data:           a.Data,
+		Runtime:        a.Runtime,
+		FeePayer:       a.FeePayer,
+		NotAfterHeight: common.JSONUint64(a.NotAfterHeight),
+	}
+}
+
+func (a SmartContractTxV2JSON) SmartContractTxV2() SmartContractTxV2 {
+	return SmartContractTxV2{
+		From:           a.From,
+		To:             a.To,
+		GasLimit:       uint64(a.GasLimit),
+		GasPrice:       (*big.Int)(a.GasPrice),
+		Data:           a.Data,
+		Runtime:        a.Runtime,
+		FeePayer:       a.FeePayer,
+		NotAfterHeight: uint64(a.NotAfterHeight),
+	}
+}
+
+func (a SmartContractTxV2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewSmartContractTxV2JSON(a))
+}
+
+func (a *SmartContractTxV2) UnmarshalJSON(data []byte) error {
+	var b SmartContractTxV2JSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.SmartContractTxV2()
+	return nil
+}
+
+func (_ *SmartContractTxV2) AssertIsTx() {}
+
+func (tx *SmartContractTxV2) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+// SmartContractTx returns the SmartContractTx with the same fields except the runtime
+func (tx *SmartContractTxV2) SmartContractTx() *SmartContractTx {
+	return &SmartContractTx{
+		From:           tx.From,
+		To:             tx.To,
+		GasLimit:       tx.GasLimit,
+		GasPrice:       tx.GasPrice,
+		Data:           tx.Data,
+		FeePayer:       tx.FeePayer,
+		NotAfterHeight: tx.NotAfterHeight,
+	}
+}
+
+func (tx *SmartContractTxV2) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.From.Signature
+	tx.From.Signature = nil
+	feePayerSigz := make([]*crypto.Signature, len(tx.FeePayer))
+	for i := range tx.FeePayer {
+		feePayerSigz[i] = tx.FeePayer[i].Signature
+		tx.FeePayer[i].Signature = nil
+	}
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.From.Signature = sig
+	for i := range tx.FeePayer {
+		tx.FeePayer[i].Signature = feePayerSigz[i]
+	}
+	return signBytes
+}
+
+func (tx *SmartContractTxV2) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.From.Address == addr {
+		tx.From.Signature = sig
+		return true
+	}
+	for i, feePayer := range tx.FeePayer {
+		if feePayer.Address == addr {
+			tx.FeePayer[i].Signature = sig
+			return true
+		}
+	}
+	return false
+}
+
+func (tx *SmartContractTxV2) String() string {
+	return fmt.Sprintf("SmartContractTxV2{%v -> %v, value: %v, gas_limit: %v, gas_price: %v, runtime: %v, data: %v}",
+		tx.From.Address.Hex(), tx.To.Address.Hex(), tx.From.Coins.TFuelWei, tx.GasLimit, tx.GasPrice, tx.Runtime, tx.Data)
+}
+
+//-----------------------------------------------------------------------------
+
 type DepositStakeTx struct {
-	Fee     Coins    `json:"fee"`     // Fee
-	Source  TxInput  `json:"source"`  // source staker account
-	Holder  TxOutput `json:"holder"`  // stake holder account
-	Purpose uint8    `json:"purpose"` // purpose e.g. stake for validator/guardian
+	Fee            Coins    `json:"fee"`     // Fee
+	Source         TxInput  `json:"source"`  // source staker account
+	Holder         TxOutput `json:"holder"`  // stake holder account
+	Purpose        uint8    `json:"purpose"` // purpose e.g. stake for validator/guardian
+	NotAfterHeight uint64   `json:"not_after_height,omitempty" rlp:"optional"`
 }
 
 func (_ *DepositStakeTx) AssertIsTx() {}
 
+func (tx *DepositStakeTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *DepositStakeTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sig := tx.Source.Signature
@@ -888,13 +1377,18 @@ type DepositStakeTxV2 struct {
 	Holder  TxOutput `json:"holder"`  // stake holder account
 	Purpose uint8    `json:"purpose"` // purpose e.g. stake for validator/guardian/elit edge node
 
-	BlsPubkey *bls.PublicKey    `rlp:"nil"`
-	BlsPop    *bls.Signature    `rlp:"nil"`
-	HolderSig *crypto.Signature `rlp:"nil"`
+	BlsPubkey      *bls.PublicKey    `rlp:"nil"`
+	BlsPop         *bls.Signature    `rlp:"nil"`
+	HolderSig      *crypto.Signature `rlp:"nil"`
+	NotAfterHeight uint64            `json:"not_after_height,omitempty" rlp:"optional"`
 }
 
 func (_ *DepositStakeTxV2) AssertIsTx() {}
 
+func (tx *DepositStakeTxV2) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *DepositStakeTxV2) SignBytes(chainID string) []byte {
 	var txBytes []byte
 	sig := tx.Source.Signature
@@ -937,14 +1431,19 @@ func (tx *DepositStakeTxV2) String() string {
 //-----------------------------------------------------------------------------
 
 type WithdrawStakeTx struct {
-	Fee     Coins    `json:"fee"`     // Fee
-	Source  TxInput  `json:"source"`  // source staker account
-	Holder  TxOutput `json:"holder"`  // stake holder account
-	Purpose uint8    `json:"purpose"` // purpose e.g. stake for validator/guardian/elite edge node
+	Fee            Coins    `json:"fee"`     // Fee
+	Source         TxInput  `json:"source"`  // source staker account
+	Holder         TxOutput `json:"holder"`  // stake holder account
+	Purpose        uint8    `json:"purpose"` // purpose e.g. stake for validator/guardian/elite edge node
+	NotAfterHeight uint64   `json:"not_after_height,omitempty" rlp:"optional"`
 }
 
 func (_ *WithdrawStakeTx) AssertIsTx() {}
 
+func (tx *WithdrawStakeTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *WithdrawStakeTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sig := tx.Source.Signature
@@ -989,10 +1488,15 @@ type StakeRewardDistributionTx struct {
 	Beneficiary     TxOutput `json:"beneficiary"`       // the beneficiary to split the reward as the hosting service fee
 	SplitBasisPoint uint     `json:"split_basis_point"` // An integer between 0 and 10000, representing the fraction of the reward the beneficiary should get (in terms of 1/10000), https://en.wikipedia.org/wiki/Basis_point
 	//Purpose         uint8    `json:"purpose"`           // purpose e.g. stake for guardian/elite edge node
+	NotAfterHeight uint64 `json:"not_after_height,omitempty" rlp:"optional"`
 }
 
 func (_ *StakeRewardDistributionTx) AssertIsTx() {}
 
+func (tx *StakeRewardDistributionTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
 func (tx *StakeRewardDistributionTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sig := tx.Holder.Signature
@@ -1018,6 +1522,602 @@ func (tx *StakeRewardDistributionTx) String() string {
 		tx.Holder.Address, tx.Beneficiary.Address, tx.SplitBasisPoint)
 }
 
+//-----------------------------------------------------------------------------
+
+//
+// MultiSigSendTx sends coins from an M-of-N multisig account. The Input address needs to be the address
+// derived from the MultiSig info, and the transaction needs to be signed by at least MultiSig.Threshold
+// of the signers. Signatures[i] holds the signature of MultiSig.Signers[i], or an empty signature if
+// that signer has not signed. Since all the signers sign the same SignBytes, the signatures can be
+// collected separately and then assembled into a single transaction.
+//
+type MultiSigSendTx struct {
+	Fee            Coins               `json:"fee"`
+	MultiSig       MultiSigInfo        `json:"multisig"`
+	Input          TxInput             `json:"input"`
+	Signatures     []*crypto.Signature `json:"signatures"`
+	Outputs        []TxOutput          `json:"outputs"`
+	NotAfterHeight uint64              `json:"not_after_height,omitempty" rlp:"optional"`
+}
+
+func (_ *MultiSigSendTx) AssertIsTx() {}
+
+func (tx *MultiSigSendTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *MultiSigSendTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Input.Signature
+	sigz := tx.Signatures
+	tx.Input.Signature = nil
+	tx.Signatures = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Input.Signature = sig
+	tx.Signatures = sigz
+	return signBytes
+}
+
+func (tx *MultiSigSendTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	idx := tx.MultiSig.SignerIndex(addr)
+	if idx < 0 {
+		return false
+	}
+	if len(tx.Signatures) != len(tx.MultiSig.Signers) {
+		sigz := make([]*crypto.Signature, len(tx.MultiSig.Signers))
+		copy(sigz, tx.Signatures)
+		tx.Signatures = sigz
+	}
+	tx.Signatures[idx] = sig
+	return true
+}
+
+// MergeSignatures copies the signatures collected in other, a copy of the same
+// transaction signed by a different subset of the signers, into tx.
+func (tx *MultiSigSendTx) MergeSignatures(other *MultiSigSendTx) bool {
+	if tx.MultiSig.Address() != other.MultiSig.Address() || len(other.Signatures) > len(other.MultiSig.Signers) {
+		return false
+	}
+	for i, sig := range other.Signatures {
+		if sig == nil || sig.IsEmpty() {
+			continue
+		}
+		tx.SetSignature(other.MultiSig.Signers[i], sig)
+	}
+	return true
+}
+
+func (tx *MultiSigSendTx) String() string {
+	return fmt.Sprintf("MultiSigSendTx{fee: %v, %v->%v, multisig: %v}", tx.Fee, tx.Input, tx.Outputs, tx.MultiSig.String())
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// VestingTransferTx locks Source.Coins - Fee in a vesting fund for the beneficiary. The beneficiary
+// can claim the coins with VestingClaimTx once the block height reaches UnlockHeight and the block
+// time reaches UnlockTime. If VestingEndHeight is greater than UnlockHeight, the coins vest linearly
+// between the two heights. The ID of the vesting fund is the hash of this transaction.
+//
+type VestingTransferTx struct {
+	Fee              Coins          // Fee
+	Source           TxInput        // Source account
+	Beneficiary      common.Address // Address that can claim the coins
+	UnlockHeight     uint64         // Block height before which no coins can be claimed
+	UnlockTime       uint64         // Unix timestamp (in seconds) before which no coins can be claimed, 0 means no time lock
+	VestingEndHeight uint64         // Block height at which all the coins are vested, 0 means no linear vesting
+	NotAfterHeight   uint64         `rlp:"optional"`
+}
+
+type VestingTransferTxJSON struct {
+	Fee              Coins             `json:"fee"`
+	Source           TxInput           `json:"source"`
+	Beneficiary      common.Address    `json:"beneficiary"`
+	UnlockHeight     common.JSONUint64 `json:"unlock_height"`
+	UnlockTime       common.JSONUint64 `json:"unlock_time"`
+	VestingEndHeight common.JSONUint64 `json:"vesting_end_height"`
+	NotAfterHeight   common.JSONUint64 `json:"not_after_height,omitempty"`
+}
+
+func NewVestingTransferTxJSON(a VestingTransferTx) VestingTransferTxJSON {
+	return VestingTransferTxJSON{
+		Fee:              a.Fee,
+		Source:           a.Source,
+		Beneficiary:      a.Beneficiary,
+		UnlockHeight:     common.JSONUint64(a.UnlockHeight),
+		UnlockTime:       common.JSONUint64(a.UnlockTime),
+		VestingEndHeight: common.JSONUint64(a.VestingEndHeight),
+		NotAfterHeight:   common.JSONUint64(a.NotAfterHeight),
+	}
+}
+
+func (a VestingTransferTxJSON) VestingTransferTx() VestingTransferTx {
+	return VestingTransferTx{
+		Fee:              a.Fee,
+		Source:           a.Source,
+		Beneficiary:      a.Beneficiary,
+		UnlockHeight:     uint64(a.UnlockHeight),
+		UnlockTime:       uint64(a.UnlockTime),
+		VestingEndHeight: uint64(a.VestingEndHeight),
+		NotAfterHeight:   uint64(a.NotAfterHeight),
+	}
+}
+
+func (a VestingTransferTx) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewVestingTransferTxJSON(a))
+}
+
+func (a *VestingTransferTx) UnmarshalJSON(data []byte) error {
+	var b VestingTransferTxJSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.VestingTransferTx()
+	return nil
+}
+
+func (_ *VestingTransferTx) AssertIsTx() {}
+
+func (tx *VestingTransferTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *VestingTransferTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Source.Signature
+	tx.Source.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Source.Signature = sig
+	return signBytes
+}
+
+func (tx *VestingTransferTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Source.Address == addr {
+		tx.Source.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *VestingTransferTx) String() string {
+	return fmt.Sprintf("VestingTransferTx{%v -> %v, fee: %v, coins: %v, unlock_height: %v, unlock_time: %v, vesting_end_height: %v}",
+		tx.Source.Address.Hex(), tx.Beneficiary.Hex(), tx.Fee, tx.Source.Coins, tx.UnlockHeight, tx.UnlockTime, tx.VestingEndHeight)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// VestingClaimTx transfers the vested but not yet claimed coins of a vesting fund to the beneficiary.
+// It needs to be signed by the beneficiary, who also pays the fee.
+//
+type VestingClaimTx struct {
+	Fee            Coins       `json:"fee"`         // Fee
+	Beneficiary    TxInput     `json:"beneficiary"` // Beneficiary of the vesting fund
+	FundID         common.Hash `json:"fund_id"`     // ID of the vesting fund
+	NotAfterHeight uint64      `json:"not_after_height,omitempty" rlp:"optional"`
+}
+
+func (_ *VestingClaimTx) AssertIsTx() {}
+
+func (tx *VestingClaimTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *VestingClaimTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Beneficiary.Signature
+	tx.Beneficiary.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Beneficiary.Signature = sig
+	return signBytes
+}
+
+func (tx *VestingClaimTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Beneficiary.Address == addr {
+		tx.Beneficiary.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *VestingClaimTx) String() string {
+	return fmt.Sprintf("VestingClaimTx{beneficiary: %v, fund_id: %v, fee: %v}",
+		tx.Beneficiary.Address.Hex(), tx.FundID.Hex(), tx.Fee)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// BatchSendTx sends coins from a single input to multiple outputs atomically. Compared to a SendTx
+// with the same outputs, it requires only one signature and one sequence increment, and is charged
+// a lower per-output fee, which suits payouts to many recipients, e.g. exchange withdrawals and payroll.
+//
+type BatchSendTx struct {
+	Fee            Coins      `json:"fee"` // Fee
+	Input          TxInput    `json:"input"`
+	Outputs        []TxOutput `json:"outputs"`
+	NotAfterHeight uint64     `json:"not_after_height,omitempty" rlp:"optional"`
+}
+
+func (_ *BatchSendTx) AssertIsTx() {}
+
+func (tx *BatchSendTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *BatchSendTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Input.Signature
+	tx.Input.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Input.Signature = sig
+	return signBytes
+}
+
+func (tx *BatchSendTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Input.Address == addr {
+		tx.Input.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *BatchSendTx) String() string {
+	return fmt.Sprintf("BatchSendTx{fee: %v, %v->%v}", tx.Fee, tx.Input, tx.Outputs)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// TokenRegistryTx registers the metadata of a fungible token contract (e.g. a TNT-20 token) under a
+// namespace, so that wallets can display the token without relying on an external registry. The
+// registrar claims the namespace if it has no owner yet, otherwise it needs to be the namespace owner.
+// Registering an existing symbol updates the metadata of the token.
+//
+type TokenRegistryTx struct {
+	Fee             Coins          `json:"fee"` // Fee
+	Registrar       TxInput        `json:"registrar"`
+	Namespace       string         `json:"namespace"`
+	Symbol          string         `json:"symbol"`
+	Name            string         `json:"name"`
+	Decimals        uint8          `json:"decimals"`
+	ContractAddress common.Address `json:"contract_address"`
+	NotAfterHeight  uint64         `json:"not_after_height,omitempty" rlp:"optional"`
+}
+
+func (_ *TokenRegistryTx) AssertIsTx() {}
+
+func (tx *TokenRegistryTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *TokenRegistryTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Registrar.Signature
+	tx.Registrar.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Registrar.Signature = sig
+	return signBytes
+}
+
+func (tx *TokenRegistryTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Registrar.Address == addr {
+		tx.Registrar.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *TokenRegistryTx) String() string {
+	return fmt.Sprintf("TokenRegistryTx{fee: %v, registrar: %v, token: %v/%v, name: %v, decimals: %v, contract: %v}",
+		tx.Fee, tx.Registrar, tx.Namespace, tx.Symbol, tx.Name, tx.Decimals, tx.ContractAddress.Hex())
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// ValidatorKeyRotationTx announces a new signing key of a validator, which replaces the current
+// signing key from the activation epoch on. The transaction is signed by both the validator address
+// and the new signing key, the latter proving the possession of the new key. Announcing a new key
+// while a previously announced key is still pending replaces the pending key.
+//
+type ValidatorKeyRotationTx struct {
+	Fee                 Coins             `json:"fee"` // Fee
+	Validator           TxInput           `json:"validator"`
+	SigningAddress      common.Address    `json:"signing_address"`
+	SigningKeySignature *crypto.Signature `json:"signing_key_signature"`
+	ActivationEpoch     uint64            `json:"activation_epoch"`
+	NotAfterHeight      uint64            `json:"not_after_height,omitempty" rlp:"optional"`
+}
+
+func (_ *ValidatorKeyRotationTx) AssertIsTx() {}
+
+func (tx *ValidatorKeyRotationTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *ValidatorKeyRotationTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	validatorSig, signingKeySig := tx.Validator.Signature, tx.SigningKeySignature
+	tx.Validator.Signature = nil
+	tx.SigningKeySignature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Validator.Signature = validatorSig
+	tx.SigningKeySignature = signingKeySig
+	return signBytes
+}
+
+func (tx *ValidatorKeyRotationTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Validator.Address == addr {
+		tx.Validator.Signature = sig
+		return true
+	}
+	if tx.SigningAddress == addr {
+		tx.SigningKeySignature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *ValidatorKeyRotationTx) String() string {
+	return fmt.Sprintf("ValidatorKeyRotationTx{fee: %v, validator: %v, signing_address: %v, activation_epoch: %v}",
+		tx.Fee, tx.Validator, tx.SigningAddress.Hex(), tx.ActivationEpoch)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// IBCTx carries a message of the IBC client, connection, channel or packet state machines, e.g. the
+// relay of a packet along with the proof of its commitment on the counterparty chain. The message is
+// encoded according to its type, see EncodeIBCMsg().
+//
+type IBCTx struct {
+	Fee            Coins        `json:"fee"` // Fee
+	Relayer        TxInput      `json:"relayer"`
+	MsgType        IBCMsgType   `json:"msg_type"`
+	Msg            common.Bytes `json:"msg"`
+	NotAfterHeight uint64       `json:"not_after_height,omitempty" rlp:"optional"`
+}
+
+func (_ *IBCTx) AssertIsTx() {}
+
+func (tx *IBCTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+// SetMsg encodes the message into the transaction
+func (tx *IBCTx) SetMsg(msg IBCMsg) error {
+	raw, err := EncodeIBCMsg(msg)
+	if err != nil {
+		return err
+	}
+	tx.MsgType = msg.MsgType()
+	tx.Msg = raw
+	return nil
+}
+
+// DecodeMsg decodes the message carried by the transaction
+func (tx *IBCTx) DecodeMsg() (IBCMsg, error) {
+	return DecodeIBCMsg(tx.MsgType, tx.Msg)
+}
+
+func (tx *IBCTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Relayer.Signature
+	tx.Relayer.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Relayer.Signature = sig
+	return signBytes
+}
+
+func (tx *IBCTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Relayer.Address == addr {
+		tx.Relayer.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *IBCTx) String() string {
+	return fmt.Sprintf("IBCTx{fee: %v, relayer: %v, msg_type: %v, msg: %v}",
+		tx.Fee, tx.Relayer, tx.MsgType, hex.EncodeToString(tx.Msg))
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// BandwidthProofTx claims the micro-reward of an edge node for the bandwidth it relayed for a resource.
+// The reward is paid from a reserved fund of the pool, which the pool designates for the bandwidth
+// rewards by reserving it for the resource. The bandwidth proof is signed by the pool and submitted by
+// the edge node. The nonces of the proofs a pool signs for an edge node strictly increase, so that each
+// proof can only be claimed once.
+//
+type BandwidthProofTx struct {
+	Fee             Coins   `json:"fee"`       // Fee
+	EdgeNode        TxInput `json:"edge_node"` // the edge node which relayed the bandwidth, receives the reward
+	Pool            TxInput `json:"pool"`      // the owner of the reserved fund, Pool.Coins is the reward
+	ReserveSequence uint64  `json:"reserve_sequence"`
+	ResourceID      string  `json:"resource_id"`
+	Epoch           uint64  `json:"epoch"` // the bandwidth reward epoch in which the bandwidth was relayed
+	RelayedBytes    uint64  `json:"relayed_bytes"`
+	Nonce           uint64  `json:"nonce"`
+	NotAfterHeight  uint64  `json:"not_after_height,omitempty" rlp:"optional"`
+}
+
+func (_ *BandwidthProofTx) AssertIsTx() {}
+
+func (tx *BandwidthProofTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+// PoolSignBytes returns the bytes of the bandwidth proof signed by the pool, which do not cover the fee
+// and the signatures, so that the proof can be signed before the edge node submits it
+func (tx *BandwidthProofTx) PoolSignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+
+	edgeNode := tx.EdgeNode
+	pool := tx.Pool
+	fee := tx.Fee
+
+	tx.EdgeNode = TxInput{Address: edgeNode.Address}
+	tx.Pool = TxInput{Address: pool.Address, Coins: pool.Coins}
+	tx.Fee = NewCoins(0, 0)
+
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+
+	tx.EdgeNode = edgeNode
+	tx.Pool = pool
+	tx.Fee = fee
+
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	return signBytes
+}
+
+// SignBytes returns the bytes signed by the edge node
+func (tx *BandwidthProofTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.EdgeNode.Signature
+	tx.EdgeNode.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.EdgeNode.Signature = sig
+	return signBytes
+}
+
+func (tx *BandwidthProofTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.EdgeNode.Address == addr {
+		tx.EdgeNode.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *BandwidthProofTx) String() string {
+	return fmt.Sprintf("BandwidthProofTx{fee: %v, edge_node: %v, pool: %v, reserve_sequence: %v, resource_id: %v, epoch: %v, relayed_bytes: %v, nonce: %v}",
+		tx.Fee, tx.EdgeNode, tx.Pool, tx.ReserveSequence, tx.ResourceID, tx.Epoch, tx.RelayedBytes, tx.Nonce)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// GovProposalTx submits a governance proposal, which the stakers vote on until GovVotingPeriod blocks
+// after the submission. A text proposal only records the outcome of the vote, while the parameter
+// changes of an approved parameter change proposal are applied to the ledger state at the end of the
+// voting period. The deposit, i.e. Proposer.Coins, is refunded if the vote reaches the quorum, and
+// burned otherwise.
+//
+type GovProposalTx struct {
+	Fee            Coins            `json:"fee"` // Fee
+	Proposer       TxInput          `json:"proposer"`
+	ProposalType   GovProposalType  `json:"proposal_type"`
+	Title          string           `json:"title"`
+	Description    string           `json:"description"`
+	ParamChanges   []GovParamChange `json:"param_changes"`
+	NotAfterHeight uint64           `json:"not_after_height,omitempty" rlp:"optional"`
+}
+
+func (_ *GovProposalTx) AssertIsTx() {}
+
+func (tx *GovProposalTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *GovProposalTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Proposer.Signature
+	tx.Proposer.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Proposer.Signature = sig
+	return signBytes
+}
+
+func (tx *GovProposalTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Proposer.Address == addr {
+		tx.Proposer.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *GovProposalTx) String() string {
+	return fmt.Sprintf("GovProposalTx{fee: %v, proposer: %v, proposal_type: %v, title: %v, param_changes: %v}",
+		tx.Fee, tx.Proposer, tx.ProposalType, tx.Title, tx.ParamChanges)
+}
+
+//-----------------------------------------------------------------------------
+
+//
+// GovVoteTx casts the vote of a staker on a governance proposal. The vote is weighted by the Theta
+// the voter stakes to the validators and the guardians at the end of the voting period. A voter can
+// change its vote until the end of the voting period.
+//
+type GovVoteTx struct {
+	Fee            Coins         `json:"fee"` // Fee
+	Voter          TxInput       `json:"voter"`
+	ProposalID     uint64        `json:"proposal_id"`
+	Option         GovVoteOption `json:"option"`
+	NotAfterHeight uint64        `json:"not_after_height,omitempty" rlp:"optional"`
+}
+
+func (_ *GovVoteTx) AssertIsTx() {}
+
+func (tx *GovVoteTx) GetNotAfterHeight() uint64 {
+	return tx.NotAfterHeight
+}
+
+func (tx *GovVoteTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Voter.Signature
+	tx.Voter.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Voter.Signature = sig
+	return signBytes
+}
+
+func (tx *GovVoteTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Voter.Address == addr {
+		tx.Voter.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *GovVoteTx) String() string {
+	return fmt.Sprintf("GovVoteTx{fee: %v, voter: %v, proposal_id: %v, option: %v}",
+		tx.Fee, tx.Voter, tx.ProposalID, tx.Option)
+}
+
 // --------------- Utils --------------- //
 
 type EthereumTxWrapper struct {
@@ -1047,6 +2147,32 @@ func addPrefixForSignBytes(signBytes common.Bytes) common.Bytes {
 	return signBytes
 }
 
+// TxFromSignBytes recovers the chain ID and the unsigned transaction from the sign bytes
+// returned by the SignBytes method of the transaction, or from the sign bytes in the v3
+// Ethereum tx wrapper.
+func TxFromSignBytes(signBytes common.Bytes) (string, Tx, error) {
+	var payload []byte
+	ethTx := EthereumTxWrapper{}
+	if err := rlp.DecodeBytes(signBytes, &ethTx); err == nil {
+		payload = ethTx.Payload
+	} else {
+		ethTxV3 := EthereumTxWrapperV3{}
+		if err := rlp.DecodeBytes(signBytes, &ethTxV3); err != nil {
+			return "", nil, err
+		}
+		payload = ethTxV3.Payload
+	}
+	chainID, txBytes, err := rlp.SplitString(payload)
+	if err != nil {
+		return "", nil, err
+	}
+	tx, err := TxFromBytes(txBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(chainID), tx, nil
+}
+
 type EthereumTxWrapperV2 struct {
 	AccountNonce uint64          `json:"nonce"    gencodec:"required"`
 	Price        *big.Int        `json:"gasPrice" gencodec:"required"`
@@ -1059,6 +2185,24 @@ type EthereumTxWrapperV2 struct {
 	EIP155Field2 uint
 }
 
+// EthereumTxWrapperV3 is the EIP-155 style wrapper of the sign bytes accepted from
+// HeightEnableChainIDReplayProtection. Its chain ID field holds the numeric chain ID of the network,
+// so that the signatures cannot be replayed on another network.
+type EthereumTxWrapperV3 struct {
+	AccountNonce uint64          `json:"nonce"    gencodec:"required"`
+	Price        *big.Int        `json:"gasPrice" gencodec:"required"`
+	GasLimit     uint64          `json:"gas"      gencodec:"required"`
+	Recipient    *common.Address `json:"to"       rlp:"nil"` // nil means contract creation
+	Amount       *big.Int        `json:"value"    gencodec:"required"`
+	Payload      []byte          `json:"input"    gencodec:"required"`
+	ChainID      *big.Int        `json:"chainId"  gencodec:"required"`
+	EIP155Field1 uint
+	EIP155Field2 uint
+}
+
+// ChangeEthereumTxWrapper re-wraps the sign bytes returned by the SignBytes method of a transaction
+// in the given version of the Ethereum tx wrapper. The v2 wrapper has the chain ID field set to 1,
+// see ReplayProtectedSignBytes for the v3 wrapper.
 func ChangeEthereumTxWrapper(origSignBytes common.Bytes, wrapperVersion uint) common.Bytes {
 	wrappedTx := &EthereumTxWrapper{}
 	err := rlp.DecodeBytes(origSignBytes, wrappedTx)
@@ -1089,18 +2233,60 @@ func ChangeEthereumTxWrapper(origSignBytes common.Bytes, wrapperVersion uint) co
 	return common.Bytes{}
 }
 
+// ReplayProtectedSignBytes re-wraps the sign bytes returned by the SignBytes method of a transaction
+// in the v3 Ethereum tx wrapper, whose chain ID field holds the numeric chain ID of the network. The
+// numeric chain ID is set by the genesis independently of the chain ID, so that two networks sharing
+// the same chain ID do not accept the signatures of each other. A nil numeric chain ID stands for the
+// one derived from the chain ID in the sign bytes, for the networks whose genesis does not set it.
+func ReplayProtectedSignBytes(origSignBytes common.Bytes, numericChainID *big.Int) common.Bytes {
+	wrappedTx := &EthereumTxWrapper{}
+	err := rlp.DecodeBytes(origSignBytes, wrappedTx)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	if numericChainID == nil {
+		chainID, _, err := rlp.SplitString(wrappedTx.Payload)
+		if err != nil {
+			log.Panic(err)
+		}
+		numericChainID = NumericChainID(string(chainID))
+	}
+	wrappedTxV3 := EthereumTxWrapperV3{
+		AccountNonce: wrappedTx.AccountNonce,
+		Price:        wrappedTx.Price,
+		GasLimit:     wrappedTx.GasLimit,
+		Recipient:    wrappedTx.Recipient,
+		Amount:       wrappedTx.Amount,
+		Payload:      wrappedTx.Payload,
+		ChainID:      numericChainID,
+		EIP155Field1: uint(0),
+		EIP155Field2: uint(0),
+	}
+	signBytes, err := rlp.EncodeToBytes(wrappedTxV3)
+	if err != nil {
+		log.Panic(err)
+	}
+	return signBytes
+}
+
 // For replay attack protection
 // https://chainid.network/
 const CHAIN_ID_OFFSET int64 = 360
 
 func MapChainID(chainIDStr string, blockHeight uint64) *big.Int {
-	chainIDWithoutOffset := mapChainIDWithoutOffset(chainIDStr)
 	if blockHeight < common.HeightRPCCompatibility {
-		return chainIDWithoutOffset
+		return mapChainIDWithoutOffset(chainIDStr)
 	}
+	return NumericChainID(chainIDStr)
+}
 
+// NumericChainID returns the numeric chain ID derived from the chain ID, i.e. the Ethereum chain ID
+// returned by eth_chainId. The transaction signatures commit to it from HeightEnableChainIDReplayProtection
+// unless the genesis sets another numeric chain ID.
+func NumericChainID(chainIDStr string) *big.Int {
 	// For replay attack protection, should NOT use the same chainID as Ethereum
-	chainID := big.NewInt(1).Add(big.NewInt(CHAIN_ID_OFFSET), chainIDWithoutOffset)
+	chainID := big.NewInt(1).Add(big.NewInt(CHAIN_ID_OFFSET), mapChainIDWithoutOffset(chainIDStr))
 	return chainID
 }
 
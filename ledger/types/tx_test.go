@@ -10,7 +10,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/rlp"
@@ -325,7 +324,7 @@ func TestSendTxSignable2(t *testing.T) {
 
 	raw, err := TxToBytes(sendTx)
 	if err != nil {
-		utils.Error("Failed to encode transaction: %v\n", err)
+		t.Fatalf("Failed to encode transaction: %v", err)
 	}
 	t.Logf("sendTx.Inputs[0].Signature : %v", hex.EncodeToString(senderSignature.ToBytes()))
 
@@ -388,6 +387,118 @@ func TestSendTxProto(t *testing.T) {
 	assert.False(tx2.Inputs[0].Signature.IsEmpty())
 }
 
+func TestSendTxNotAfterHeight(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	test1PrivAcc := PrivAccountFromSecret("sendtx1")
+	test2PrivAcc := PrivAccountFromSecret("sendtx2")
+	tx := &SendTx{
+		Fee: Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(2)},
+		Inputs: []TxInput{
+			NewTxInput(test1PrivAcc.Address, Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(10)}, 1),
+		},
+		Outputs: []TxOutput{
+			TxOutput{
+				Address: test2PrivAcc.Address,
+				Coins:   Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(8)},
+			},
+		},
+	}
+	noExpiryBytes, err := TxToBytes(tx)
+	require.Nil(err)
+
+	// The expiry height changes the encoding and hence the sign bytes
+	tx.NotAfterHeight = 1000
+	b, err := TxToBytes(tx)
+	require.Nil(err)
+	assert.NotEqual(noExpiryBytes, b)
+
+	txs, err := TxFromBytes(b)
+	require.Nil(err)
+	tx2 := txs.(*SendTx)
+	assert.Equal(uint64(1000), tx2.GetNotAfterHeight())
+	assert.Equal(0, len(tx2.FeePayer))
+	assert.Equal(tx.SignBytes(chainID), tx2.SignBytes(chainID))
+
+	// Transactions without an expiry height keep their original encoding
+	tx.NotAfterHeight = 0
+	b, err = TxToBytes(tx)
+	require.Nil(err)
+	assert.Equal(noExpiryBytes, b)
+
+	txs, err = TxFromBytes(noExpiryBytes)
+	require.Nil(err)
+	assert.Equal(uint64(0), txs.(ExpirableTx).GetNotAfterHeight())
+}
+
+func TestTxFromSignBytes(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	test1PrivAcc := PrivAccountFromSecret("sendtx1")
+	test2PrivAcc := PrivAccountFromSecret("sendtx2")
+	tx := &SendTx{
+		Fee: Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(2)},
+		Inputs: []TxInput{
+			NewTxInput(test1PrivAcc.Address, Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(10)}, 1),
+		},
+		Outputs: []TxOutput{
+			TxOutput{
+				Address: test2PrivAcc.Address,
+				Coins:   Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(8)},
+			},
+		},
+		NotAfterHeight: 1000,
+	}
+	signBytes := tx.SignBytes(chainID)
+
+	decodedChainID, decoded, err := TxFromSignBytes(signBytes)
+	require.Nil(err)
+	assert.Equal(chainID, decodedChainID)
+	assert.Equal(signBytes, decoded.SignBytes(chainID))
+
+	_, _, err = TxFromSignBytes(signBytes[:len(signBytes)-1])
+	assert.NotNil(err)
+}
+
+func TestReplayProtectedSignBytes(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	test1PrivAcc := PrivAccountFromSecret("sendtx1")
+	test2PrivAcc := PrivAccountFromSecret("sendtx2")
+	tx := &SendTx{
+		Fee: Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(2)},
+		Inputs: []TxInput{
+			NewTxInput(test1PrivAcc.Address, Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(10)}, 1),
+		},
+		Outputs: []TxOutput{
+			TxOutput{
+				Address: test2PrivAcc.Address,
+				Coins:   Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(8)},
+			},
+		},
+	}
+	signBytes := tx.SignBytes(chainID)
+	signBytesV3 := ReplayProtectedSignBytes(signBytes, nil)
+
+	// The v3 wrapper commits to the numeric chain ID, derived from the chain ID unless set otherwise
+	wrapped := EthereumTxWrapperV3{}
+	require.Nil(rlp.DecodeBytes(signBytesV3, &wrapped))
+	assert.Equal(NumericChainID(chainID), wrapped.ChainID)
+	assert.Equal(MapChainID(chainID, common.HeightRPCCompatibility), wrapped.ChainID)
+	assert.Equal(big.NewInt(361), NumericChainID("mainnet"))
+	assert.Equal(signBytesV3, ReplayProtectedSignBytes(signBytes, NumericChainID(chainID)))
+
+	// The same transaction has different sign bytes on another network, or in another wrapper
+	assert.NotEqual(signBytesV3, ReplayProtectedSignBytes(tx.SignBytes("other_chain"), nil))
+	assert.NotEqual(signBytesV3, ReplayProtectedSignBytes(signBytes, big.NewInt(1001)))
+	assert.NotEqual(signBytesV3, ChangeEthereumTxWrapper(signBytes, 2))
+
+	decodedChainID, decoded, err := TxFromSignBytes(signBytesV3)
+	require.Nil(err)
+	assert.Equal(chainID, decodedChainID)
+	assert.Equal(signBytes, decoded.SignBytes(chainID))
+}
+
 func TestReserveFundTxSignable(t *testing.T) {
 	reserveFundTx := &ReserveFundTx{
 		Fee: Coins{ThetaWei: Zero, TFuelWei: big.NewInt(111)},
@@ -787,3 +898,36 @@ func TestSmartContractTxJSON(t *testing.T) {
 	assert.Equal(uint64(math.MaxUint64), d.GasLimit)
 	assert.Equal(0, gasPrice.Cmp(d.GasPrice))
 }
+
+func TestSmartContractTxV2(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	test1PrivAcc := PrivAccountFromSecret("sctx1")
+	tx := &SmartContractTxV2{
+		From:     NewTxInput(test1PrivAcc.Address, Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(10)}, 1),
+		GasLimit: 100000,
+		GasPrice: big.NewInt(4000000000000),
+		Data:     common.Hex2Bytes("0061736d01000000"),
+		Runtime:  ContractRuntimeWASM,
+	}
+	b, err := TxToBytes(tx)
+	require.Nil(err)
+	txs, err := TxFromBytes(b)
+	require.Nil(err)
+	tx2 := txs.(*SmartContractTxV2)
+	assert.Equal(ContractRuntimeWASM, tx2.Runtime)
+	assert.Equal(tx.SignBytes(chainID), tx2.SignBytes(chainID))
+
+	// The runtime is covered by the signature
+	sctx := tx.SmartContractTx()
+	assert.Equal(tx.Data, sctx.Data)
+	assert.NotEqual(tx.SignBytes(chainID), sctx.SignBytes(chainID))
+
+	s, err := json.Marshal(tx)
+	require.Nil(err)
+	var d SmartContractTxV2
+	require.Nil(json.Unmarshal(s, &d))
+	assert.Equal(ContractRuntimeWASM, d.Runtime)
+	assert.Equal(uint64(100000), d.GasLimit)
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/thetatoken/theta/common"
+)
+
+// ** Upgrade Schedule: The activation heights of the protocol upgrades, stored in the ledger state **
+// ** as part of the chain params so that all the nodes of a network activate an upgrade together   **
+//
+
+// ScheduledUpgrade specifies the block height from which an upgrade is active
+type ScheduledUpgrade struct {
+	Name   string
+	Height uint64
+}
+
+type ScheduledUpgradeJSON struct {
+	Name   string            `json:"name"`
+	Height common.JSONUint64 `json:"height"`
+}
+
+func NewScheduledUpgradeJSON(a ScheduledUpgrade) ScheduledUpgradeJSON {
+	return ScheduledUpgradeJSON{
+		Name:   a.Name,
+		Height: common.JSONUint64(a.Height),
+	}
+}
+
+func (a ScheduledUpgradeJSON) ScheduledUpgrade() ScheduledUpgrade {
+	return ScheduledUpgrade{
+		Name:   a.Name,
+		Height: uint64(a.Height),
+	}
+}
+
+func (a ScheduledUpgrade) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewScheduledUpgradeJSON(a))
+}
+
+func (a *ScheduledUpgrade) UnmarshalJSON(data []byte) error {
+	var b ScheduledUpgradeJSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.ScheduledUpgrade()
+	return nil
+}
+
+// UpgradeSchedule specifies the activation heights of the upgrades scheduled by the chain params.
+// The upgrades not in the schedule activate at the default heights compiled into the ledger.
+type UpgradeSchedule struct {
+	Upgrades []ScheduledUpgrade `json:"upgrades"`
+}
+
+// Get returns the scheduled upgrade with the given name, or nil if it is not scheduled
+func (us *UpgradeSchedule) Get(name string) *ScheduledUpgrade {
+	for i := range us.Upgrades {
+		if us.Upgrades[i].Name == name {
+			return &us.Upgrades[i]
+		}
+	}
+	return nil
+}
+
+// Validate checks that each upgrade is named, scheduled at a positive height, and scheduled once
+func (us *UpgradeSchedule) Validate() error {
+	names := make(map[string]bool)
+	for _, upgrade := range us.Upgrades {
+		if upgrade.Name == "" {
+			return errors.New("Upgrade name cannot be empty")
+		}
+		if names[upgrade.Name] {
+			return errors.Errorf("Duplicated upgrade %v", upgrade.Name)
+		}
+		names[upgrade.Name] = true
+		if upgrade.Height == 0 {
+			return errors.Errorf("Activation height of upgrade %v needs to be positive", upgrade.Name)
+		}
+	}
+	return nil
+}
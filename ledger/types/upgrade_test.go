@@ -0,0 +1,41 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeScheduleValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	us := &UpgradeSchedule{Upgrades: []ScheduledUpgrade{{Name: "theta3", Height: 1}, {Name: "future", Height: 100}}}
+	assert.Nil(us.Validate())
+	assert.Equal(uint64(100), us.Get("future").Height)
+	assert.Nil(us.Get("unknown"))
+
+	assert.NotNil((&UpgradeSchedule{Upgrades: []ScheduledUpgrade{{Name: "", Height: 1}}}).Validate())
+	assert.NotNil((&UpgradeSchedule{Upgrades: []ScheduledUpgrade{{Name: "future", Height: 0}}}).Validate())
+	assert.NotNil((&UpgradeSchedule{Upgrades: []ScheduledUpgrade{{Name: "future", Height: 1}, {Name: "future", Height: 2}}}).Validate())
+}
+
+func TestUpgradeScheduleEncoding(t *testing.T) {
+	require := require.New(t)
+
+	us := &UpgradeSchedule{Upgrades: []ScheduledUpgrade{{Name: "theta3", Height: 1}, {Name: "future", Height: 100}}}
+
+	raw, err := ToBytes(us)
+	require.Nil(err)
+	decoded := &UpgradeSchedule{}
+	require.Nil(FromBytes(raw, decoded))
+	require.Equal(us, decoded)
+
+	js, err := json.Marshal(us)
+	require.Nil(err)
+	require.Equal(`{"upgrades":[{"name":"theta3","height":"1"},{"name":"future","height":"100"}]}`, string(js))
+	decoded = &UpgradeSchedule{}
+	require.Nil(json.Unmarshal(js, decoded))
+	require.Equal(us, decoded)
+}
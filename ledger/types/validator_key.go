@@ -0,0 +1,62 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// ** Validator Signing Keys: the signing keys announced by the validators through the ValidatorKeyRotationTx **
+//
+
+// ValidatorSigningKey is a signing key of a validator, which replaces the previous signing key from
+// the activation epoch on
+type ValidatorSigningKey struct {
+	SigningAddress  common.Address `json:"signing_address"`
+	ActivationEpoch uint64         `json:"activation_epoch"`
+}
+
+func (key ValidatorSigningKey) String() string {
+	return fmt.Sprintf("{%v activates at epoch %v}", key.SigningAddress.Hex(), key.ActivationEpoch)
+}
+
+// ValidatorSigningKeys lists the signing keys of a validator in ascending order of the activation
+// epochs. Until the activation of the first signing key, the validator signs with the key of the
+// validator address itself.
+type ValidatorSigningKeys struct {
+	Validator common.Address        `json:"validator"`
+	Keys      []ValidatorSigningKey `json:"keys"`
+}
+
+// SigningAddress returns the address of the signing key active at the given epoch
+func (vsk *ValidatorSigningKeys) SigningAddress(epoch uint64) common.Address {
+	signingAddress := vsk.Validator
+	for _, key := range vsk.Keys {
+		if key.ActivationEpoch > epoch {
+			break
+		}
+		signingAddress = key.SigningAddress
+	}
+	return signingAddress
+}
+
+// Rotate schedules the activation of the new signing key at the activation epoch. The keys which are
+// not active at the current epoch yet are replaced by the new key, and the keys superseded before the
+// current epoch are dropped.
+func (vsk *ValidatorSigningKeys) Rotate(key ValidatorSigningKey, currentEpoch uint64) {
+	keys := []ValidatorSigningKey{}
+	for i, existing := range vsk.Keys {
+		if existing.ActivationEpoch > currentEpoch {
+			break
+		}
+		if i+1 < len(vsk.Keys) && vsk.Keys[i+1].ActivationEpoch <= currentEpoch {
+			continue // superseded
+		}
+		keys = append(keys, existing)
+	}
+	vsk.Keys = append(keys, key)
+}
+
+func (vsk *ValidatorSigningKeys) String() string {
+	return fmt.Sprintf("ValidatorSigningKeys{validator: %v, keys: %v}", vsk.Validator.Hex(), vsk.Keys)
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestValidatorSigningKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	validator := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	key1 := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	key2 := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	key3 := common.HexToAddress("0x1000000000000000000000000000000000000003")
+
+	vsk := &ValidatorSigningKeys{Validator: validator}
+	assert.Equal(validator, vsk.SigningAddress(100))
+
+	vsk.Rotate(ValidatorSigningKey{SigningAddress: key1, ActivationEpoch: 200}, 100)
+	assert.Equal(validator, vsk.SigningAddress(199))
+	assert.Equal(key1, vsk.SigningAddress(200))
+
+	// A pending key is replaced by the new key
+	vsk.Rotate(ValidatorSigningKey{SigningAddress: key2, ActivationEpoch: 300}, 150)
+	assert.Equal(1, len(vsk.Keys))
+	assert.Equal(validator, vsk.SigningAddress(299))
+	assert.Equal(key2, vsk.SigningAddress(300))
+
+	// The active key is kept until the new key activates
+	vsk.Rotate(ValidatorSigningKey{SigningAddress: key3, ActivationEpoch: 500}, 400)
+	assert.Equal(2, len(vsk.Keys))
+	assert.Equal(key2, vsk.SigningAddress(499))
+	assert.Equal(key3, vsk.SigningAddress(500))
+
+	// The superseded keys are dropped
+	vsk.Rotate(ValidatorSigningKey{SigningAddress: key1, ActivationEpoch: 700}, 600)
+	assert.Equal([]ValidatorSigningKey{{key3, 500}, {key1, 700}}, vsk.Keys)
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// ** Vesting Fund: Theta/TFuel locked for a beneficiary, released by height/time with optional linear vesting **
+//
+
+// VestingFund holds the coins transferred through a VestingTransferTx. None of the coins can be
+// claimed by the beneficiary before both UnlockHeight and UnlockTime are reached. After that, if
+// VestingEndHeight is greater than UnlockHeight, the coins vest linearly by block height until
+// VestingEndHeight, otherwise all the coins are released at once.
+type VestingFund struct {
+	ID               common.Hash    // ID of the fund, i.e. the hash of the VestingTransferTx
+	Source           common.Address // Address that locked the coins
+	Beneficiary      common.Address // Address that can claim the coins
+	Total            Coins          // Total amount of coins locked
+	Claimed          Coins          // Amount of coins already claimed by the beneficiary
+	UnlockHeight     uint64         // Block height before which no coins can be claimed
+	UnlockTime       uint64         // Unix timestamp (in seconds) before which no coins can be claimed, 0 means no time lock
+	VestingEndHeight uint64         // Block height at which all the coins are vested
+}
+
+type VestingFundJSON struct {
+	ID               common.Hash       `json:"id"`
+	Source           common.Address    `json:"source"`
+	Beneficiary      common.Address    `json:"beneficiary"`
+	Total            Coins             `json:"total"`
+	Claimed          Coins             `json:"claimed"`
+	UnlockHeight     common.JSONUint64 `json:"unlock_height"`
+	UnlockTime       common.JSONUint64 `json:"unlock_time"`
+	VestingEndHeight common.JSONUint64 `json:"vesting_end_height"`
+}
+
+func NewVestingFundJSON(a VestingFund) VestingFundJSON {
+	return VestingFundJSON{
+		ID:               a.ID,
+		Source:           a.Source,
+		Beneficiary:      a.Beneficiary,
+		Total:            a.Total,
+		Claimed:          a.Claimed,
+		UnlockHeight:     common.JSONUint64(a.UnlockHeight),
+		UnlockTime:       common.JSONUint64(a.UnlockTime),
+		VestingEndHeight: common.JSONUint64(a.VestingEndHeight),
+	}
+}
+
+func (a VestingFundJSON) VestingFund() VestingFund {
+	return VestingFund{
+		ID:               a.ID,
+		Source:           a.Source,
+		Beneficiary:      a.Beneficiary,
+		Total:            a.Total,
+		Claimed:          a.Claimed,
+		UnlockHeight:     uint64(a.UnlockHeight),
+		UnlockTime:       uint64(a.UnlockTime),
+		VestingEndHeight: uint64(a.VestingEndHeight),
+	}
+}
+
+func (a VestingFund) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewVestingFundJSON(a))
+}
+
+func (a *VestingFund) UnmarshalJSON(data []byte) error {
+	var b VestingFundJSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.VestingFund()
+	return nil
+}
+
+// IsUnlocked returns whether the fund has reached the unlock height and time.
+func (vf *VestingFund) IsUnlocked(blockHeight uint64, blockTime uint64) bool {
+	return blockHeight >= vf.UnlockHeight && blockTime >= vf.UnlockTime
+}
+
+// VestedAmount returns the amount of coins vested at the given block height and time,
+// including the coins already claimed.
+func (vf *VestingFund) VestedAmount(blockHeight uint64, blockTime uint64) Coins {
+	total := vf.Total.NoNil()
+	if !vf.IsUnlocked(blockHeight, blockTime) {
+		return NewCoins(0, 0)
+	}
+	if vf.VestingEndHeight <= vf.UnlockHeight || blockHeight >= vf.VestingEndHeight {
+		return total
+	}
+
+	elapsed := new(big.Int).SetUint64(blockHeight - vf.UnlockHeight)
+	duration := new(big.Int).SetUint64(vf.VestingEndHeight - vf.UnlockHeight)
+	return Coins{
+		ThetaWei: new(big.Int).Div(new(big.Int).Mul(total.ThetaWei, elapsed), duration),
+		TFuelWei: new(big.Int).Div(new(big.Int).Mul(total.TFuelWei, elapsed), duration),
+	}
+}
+
+// ClaimableAmount returns the amount of vested coins not yet claimed.
+func (vf *VestingFund) ClaimableAmount(blockHeight uint64, blockTime uint64) Coins {
+	return vf.VestedAmount(blockHeight, blockTime).Minus(vf.Claimed.NoNil())
+}
+
+// IsFullyClaimed returns whether all the coins have been claimed.
+func (vf *VestingFund) IsFullyClaimed() bool {
+	return vf.Claimed.NoNil().IsGTE(vf.Total.NoNil())
+}
+
+func (vf *VestingFund) String() string {
+	return fmt.Sprintf("VestingFund{%v, %v->%v, total: %v, claimed: %v, unlock_height: %v, unlock_time: %v, vesting_end_height: %v}",
+		vf.ID.Hex(), vf.Source.Hex(), vf.Beneficiary.Hex(), vf.Total, vf.Claimed, vf.UnlockHeight, vf.UnlockTime, vf.VestingEndHeight)
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/thetatoken/theta/common"
+)
+
+func TestVestingFundCliff(t *testing.T) {
+	assert := assert.New(t)
+
+	fund := &VestingFund{
+		Total:        NewCoins(1000, 500),
+		Claimed:      NewCoins(0, 0),
+		UnlockHeight: 100,
+		UnlockTime:   1600000000,
+	}
+
+	assert.True(fund.VestedAmount(99, 1600000000).IsZero())
+	assert.True(fund.VestedAmount(100, 1599999999).IsZero())
+	assert.True(fund.VestedAmount(100, 1600000000).IsEqual(NewCoins(1000, 500)))
+	assert.True(fund.ClaimableAmount(200, 1700000000).IsEqual(NewCoins(1000, 500)))
+}
+
+func TestVestingFundLinear(t *testing.T) {
+	assert := assert.New(t)
+
+	fund := &VestingFund{
+		Total:            NewCoins(1000, 500),
+		Claimed:          NewCoins(0, 0),
+		UnlockHeight:     100,
+		VestingEndHeight: 200,
+	}
+
+	assert.True(fund.VestedAmount(50, 0).IsZero())
+	assert.True(fund.VestedAmount(100, 0).IsZero())
+	assert.True(fund.VestedAmount(125, 0).IsEqual(NewCoins(250, 125)))
+	assert.True(fund.VestedAmount(200, 0).IsEqual(NewCoins(1000, 500)))
+	assert.True(fund.VestedAmount(300, 0).IsEqual(NewCoins(1000, 500)))
+
+	fund.Claimed = fund.ClaimableAmount(150, 0)
+	assert.True(fund.Claimed.IsEqual(NewCoins(500, 250)))
+	assert.True(fund.ClaimableAmount(175, 0).IsEqual(NewCoins(250, 125)))
+	assert.False(fund.IsFullyClaimed())
+
+	fund.Claimed = fund.Claimed.Plus(fund.ClaimableAmount(200, 0))
+	assert.True(fund.IsFullyClaimed())
+}
+
+func TestVestingFundJSON(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fund := VestingFund{
+		ID:               common.HexToHash("0x2fe41732b40ca852e9c36f52b278dde78f0fe34f28f9c94083112aa6a0624b8c"),
+		Beneficiary:      common.HexToAddress("0x9F1233798E905E173560071255140b4A8aBd3Ec6"),
+		Total:            NewCoins(1000, 500),
+		Claimed:          NewCoins(0, 0),
+		UnlockHeight:     100,
+		VestingEndHeight: 200,
+	}
+
+	s, err := json.Marshal(fund)
+	require.Nil(err)
+
+	var d VestingFund
+	err = json.Unmarshal(s, &d)
+	require.Nil(err)
+	assert.Equal(fund.ID, d.ID)
+	assert.Equal(fund.Beneficiary, d.Beneficiary)
+	assert.Equal(uint64(200), d.VestingEndHeight)
+	assert.True(d.Total.IsEqual(fund.Total))
+}
@@ -0,0 +1,193 @@
+// Package upgrade coordinates the protocol upgrades. Each upgrade implemented by this binary has a
+// name and a default activation height, which the chain params stored in the ledger state may
+// override, e.g. to activate the upgrades from the genesis on a private network. The chain params
+// may also schedule upgrades which this binary does not implement; the node then refuses to process
+// the blocks from their activation heights on, rather than forking off the network.
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/thetatoken/theta/common"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// The upgrades implemented by this binary
+const (
+	TxExpiry                = "tx_expiry"
+	SponsoredFee            = "sponsored_fee"
+	SmartContract           = "smart_contract"
+	WasmRuntime             = "wasm_runtime"
+	Theta3                  = "theta3"
+	MultiSigTx              = "multisig_tx"
+	VestingTx               = "vesting_tx"
+	BatchSendTx             = "batch_send_tx"
+	SplitRuleRenewal        = "split_rule_renewal"
+	ServicePaymentDispute   = "service_payment_dispute"
+	TokenRegistryTx         = "token_registry_tx"
+	ValidatorKeyRotation    = "validator_key_rotation"
+	IBCTx                   = "ibc_tx"
+	BandwidthProofTx        = "bandwidth_proof_tx"
+	Governance              = "governance"
+	Ed25519Signature        = "ed25519_signature"
+	CanonicalSignature      = "canonical_signature"
+	TxReceiptRoot           = "tx_receipt_root"
+	FeeSchedule             = "fee_schedule"
+	NativePrecompiles       = "native_precompiles"
+	ChainIDReplayProtection = "chain_id_replay_protection"
+)
+
+// defaultHeights are the activation heights of the upgrades on the mainnet, which apply unless
+// the chain params schedule the upgrades otherwise
+var defaultHeights = map[string]uint64{
+	TxExpiry:                common.HeightEnableTxExpiry,
+	SponsoredFee:            common.HeightEnableSponsoredFee,
+	SmartContract:           common.HeightEnableSmartContract,
+	WasmRuntime:             common.HeightEnableWasmRuntime,
+	Theta3:                  common.HeightEnableTheta3,
+	MultiSigTx:              common.HeightEnableMultiSigTx,
+	VestingTx:               common.HeightEnableVestingTx,
+	BatchSendTx:             common.HeightEnableBatchSendTx,
+	SplitRuleRenewal:        common.HeightEnableSplitRuleRenewal,
+	ServicePaymentDispute:   common.HeightEnableServicePaymentDispute,
+	TokenRegistryTx:         common.HeightEnableTokenRegistryTx,
+	ValidatorKeyRotation:    common.HeightEnableValidatorKeyRotationTx,
+	IBCTx:                   common.HeightEnableIBCTx,
+	BandwidthProofTx:        common.HeightEnableBandwidthProofTx,
+	Governance:              common.HeightEnableGovernance,
+	Ed25519Signature:        common.HeightEnableEd25519Signature,
+	CanonicalSignature:      common.HeightEnableCanonicalSignature,
+	TxReceiptRoot:           common.HeightEnableTxReceiptRoot,
+	FeeSchedule:             common.HeightEnableFeeSchedule,
+	NativePrecompiles:       common.HeightEnableNativePrecompiles,
+	ChainIDReplayProtection: common.HeightEnableChainIDReplayProtection,
+}
+
+// Status is the state of an upgrade at a block height
+type Status struct {
+	Name      string            `json:"name"`
+	Height    common.JSONUint64 `json:"height"`
+	Active    bool              `json:"active"`
+	Supported bool              `json:"supported"` // false if this binary does not implement the upgrade
+	Scheduled bool              `json:"scheduled"` // true if the height is set by the chain params
+}
+
+// Manager resolves the activation heights of the upgrades
+type Manager struct {
+	defaultHeights map[string]uint64
+}
+
+// NewManager creates the manager of the upgrades implemented by this binary
+func NewManager() *Manager {
+	return &Manager{
+		defaultHeights: defaultHeights,
+	}
+}
+
+// ActivationHeight returns the height from which the upgrade is active, and false if the upgrade
+// is neither scheduled by the chain params nor implemented by this binary.
+func (m *Manager) ActivationHeight(view *st.StoreView, name string) (uint64, bool) {
+	if scheduled := view.GetUpgradeSchedule().Get(name); scheduled != nil {
+		return scheduled.Height, true
+	}
+	height, ok := m.defaultHeights[name]
+	return height, ok
+}
+
+// IsActive returns whether the upgrade is active at the block height
+func (m *Manager) IsActive(view *st.StoreView, name string, blockHeight uint64) bool {
+	height, ok := m.ActivationHeight(view, name)
+	return ok && blockHeight >= height
+}
+
+// CheckSupported returns an error if the chain params schedule an upgrade which this binary does
+// not implement at or below the block height. The block must not be processed in that case.
+func (m *Manager) CheckSupported(view *st.StoreView, blockHeight uint64) error {
+	for _, scheduled := range view.GetUpgradeSchedule().Upgrades {
+		if _, ok := m.defaultHeights[scheduled.Name]; ok {
+			continue
+		}
+		if blockHeight >= scheduled.Height {
+			return fmt.Errorf("upgrade %v activated at height %v is not supported by this binary, "+
+				"please upgrade the node software", scheduled.Name, scheduled.Height)
+		}
+	}
+	return nil
+}
+
+// CheckReschedule returns an error if replacing the upgrade schedule of the chain params with the
+// given schedule at the block height would change the activation height of an upgrade which is
+// already active, or activate an upgrade at or below the block height.
+func (m *Manager) CheckReschedule(view *st.StoreView, schedule *types.UpgradeSchedule, blockHeight uint64) error {
+	names := []string{}
+	for name := range m.defaultHeights {
+		names = append(names, name)
+	}
+	for _, scheduled := range view.GetUpgradeSchedule().Upgrades {
+		names = append(names, scheduled.Name)
+	}
+	for _, scheduled := range schedule.Upgrades {
+		names = append(names, scheduled.Name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oldHeight, oldOk := m.ActivationHeight(view, name)
+		newHeight, newOk := m.defaultHeights[name]
+		if scheduled := schedule.Get(name); scheduled != nil {
+			newHeight, newOk = scheduled.Height, true
+		}
+		if oldOk == newOk && oldHeight == newHeight {
+			continue
+		}
+		if oldOk && blockHeight >= oldHeight {
+			return fmt.Errorf("upgrade %v is already active since height %v", name, oldHeight)
+		}
+		if newOk && blockHeight >= newHeight {
+			return fmt.Errorf("upgrade %v cannot be activated at height %v, which is not above the current height %v",
+				name, newHeight, blockHeight)
+		}
+	}
+	return nil
+}
+
+// Status returns the state of all the known and scheduled upgrades at the block height, ordered
+// by their activation heights.
+func (m *Manager) Status(view *st.StoreView, blockHeight uint64) []Status {
+	schedule := view.GetUpgradeSchedule()
+	statuses := []Status{}
+	for name, height := range m.defaultHeights {
+		scheduled := schedule.Get(name)
+		if scheduled != nil {
+			height = scheduled.Height
+		}
+		statuses = append(statuses, Status{
+			Name:      name,
+			Height:    common.JSONUint64(height),
+			Active:    blockHeight >= height,
+			Supported: true,
+			Scheduled: scheduled != nil,
+		})
+	}
+	for _, scheduled := range schedule.Upgrades {
+		if _, ok := m.defaultHeights[scheduled.Name]; ok {
+			continue
+		}
+		statuses = append(statuses, Status{
+			Name:      scheduled.Name,
+			Height:    common.JSONUint64(scheduled.Height),
+			Active:    blockHeight >= scheduled.Height,
+			Supported: false,
+			Scheduled: true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Height != statuses[j].Height {
+			return statuses[i].Height < statuses[j].Height
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
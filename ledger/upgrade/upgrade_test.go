@@ -0,0 +1,94 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestDefaultActivationHeights(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewManager()
+	view := st.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+
+	height, ok := m.ActivationHeight(view, Theta3)
+	assert.True(ok)
+	assert.Equal(common.HeightEnableTheta3, height)
+	assert.False(m.IsActive(view, Theta3, common.HeightEnableTheta3-1))
+	assert.True(m.IsActive(view, Theta3, common.HeightEnableTheta3))
+
+	_, ok = m.ActivationHeight(view, "unknown")
+	assert.False(ok)
+	assert.False(m.IsActive(view, "unknown", 1<<62))
+	assert.Nil(m.CheckSupported(view, 1<<62))
+}
+
+func TestScheduledUpgrades(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewManager()
+	view := st.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	view.SetUpgradeSchedule(&types.UpgradeSchedule{
+		Upgrades: []types.ScheduledUpgrade{
+			{Name: Theta3, Height: 10},
+			{Name: "future", Height: 100},
+		},
+	})
+
+	// The chain params override the default height
+	assert.False(m.IsActive(view, Theta3, 9))
+	assert.True(m.IsActive(view, Theta3, 10))
+
+	// The blocks from the activation height of an unknown upgrade are not supported
+	assert.Nil(m.CheckSupported(view, 99))
+	assert.NotNil(m.CheckSupported(view, 100))
+	assert.NotNil(m.CheckSupported(view, 101))
+
+	statuses := m.Status(view, 50)
+	assert.Equal(len(defaultHeights)+1, len(statuses))
+	for i := 1; i < len(statuses); i++ {
+		assert.True(statuses[i-1].Height <= statuses[i].Height)
+	}
+	assert.Equal(Status{Name: Theta3, Height: 10, Active: true, Supported: true, Scheduled: true}, statuses[0])
+	assert.Equal(Status{Name: "future", Height: 100, Active: false, Supported: false, Scheduled: true}, statuses[1])
+}
+
+func TestCheckReschedule(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewManager()
+	view := st.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	schedule := &types.UpgradeSchedule{
+		Upgrades: []types.ScheduledUpgrade{
+			{Name: Theta3, Height: 10},
+			{Name: "future", Height: 100},
+		},
+	}
+	view.SetUpgradeSchedule(schedule)
+
+	assert.Nil(m.CheckReschedule(view, schedule, 50))
+
+	// The pending upgrades can be rescheduled above the current height, or unscheduled
+	assert.Nil(m.CheckReschedule(view, &types.UpgradeSchedule{
+		Upgrades: []types.ScheduledUpgrade{{Name: Theta3, Height: 10}, {Name: "future", Height: 60}},
+	}, 50))
+	assert.Nil(m.CheckReschedule(view, &types.UpgradeSchedule{
+		Upgrades: []types.ScheduledUpgrade{{Name: Theta3, Height: 10}},
+	}, 50))
+	assert.NotNil(m.CheckReschedule(view, &types.UpgradeSchedule{
+		Upgrades: []types.ScheduledUpgrade{{Name: Theta3, Height: 10}, {Name: "future", Height: 50}},
+	}, 50))
+
+	// The active upgrades cannot be rescheduled
+	assert.NotNil(m.CheckReschedule(view, &types.UpgradeSchedule{
+		Upgrades: []types.ScheduledUpgrade{{Name: Theta3, Height: 20}, {Name: "future", Height: 100}},
+	}, 50))
+	assert.NotNil(m.CheckReschedule(view, &types.UpgradeSchedule{
+		Upgrades: []types.ScheduledUpgrade{{Name: "future", Height: 100}},
+	}, 50))
+}
@@ -0,0 +1,251 @@
+// Package abi implements the Ethereum contract ABI, to encode the calls of the smart contract
+// methods and decode their return values and events.
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+// Argument is an input or output of a method or event
+type Argument struct {
+	Name    string
+	Type    Type
+	Indexed bool // for the event inputs only
+}
+
+func (a *Argument) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name       string     `json:"name"`
+		Type       string     `json:"type"`
+		Indexed    bool       `json:"indexed"`
+		Components []Argument `json:"components"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	t, err := NewType(raw.Type, raw.Components)
+	if err != nil {
+		return err
+	}
+	a.Name, a.Type, a.Indexed = raw.Name, t, raw.Indexed
+	return nil
+}
+
+// Arguments are the inputs or outputs of a method or event
+type Arguments []Argument
+
+func (args Arguments) types() []Type {
+	types := make([]Type, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type
+	}
+	return types
+}
+
+func (args Arguments) signature() string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type.String()
+	}
+	return "(" + strings.Join(types, ",") + ")"
+}
+
+// Method is a method of a contract, or its constructor
+type Method struct {
+	Name            string
+	Inputs          Arguments
+	Outputs         Arguments
+	StateMutability string
+	Constant        bool
+}
+
+// Sig returns the signature of the method, e.g. transfer(address,uint256)
+func (m *Method) Sig() string {
+	return m.Name + m.Inputs.signature()
+}
+
+// ID returns the selector of the method, the first 4 bytes of the hash of its signature
+func (m *Method) ID() []byte {
+	return crypto.Keccak256([]byte(m.Sig()))[:4]
+}
+
+// IsReadOnly returns whether the method doesn't modify the state
+func (m *Method) IsReadOnly() bool {
+	return m.Constant || m.StateMutability == "view" || m.StateMutability == "pure"
+}
+
+// Pack encodes the call of the method with the arguments
+func (m *Method) Pack(values ...interface{}) ([]byte, error) {
+	data, err := m.Inputs.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+	return append(m.ID(), data...), nil
+}
+
+// Event is an event emitted by a contract
+type Event struct {
+	Name      string
+	Inputs    Arguments
+	Anonymous bool
+}
+
+// Sig returns the signature of the event, e.g. Transfer(address,address,uint256)
+func (e *Event) Sig() string {
+	return e.Name + e.Inputs.signature()
+}
+
+// ID returns the hash of the signature of the event, which is the first topic of its logs
+func (e *Event) ID() common.Hash {
+	return crypto.Keccak256Hash([]byte(e.Sig()))
+}
+
+// ABI is the interface of a contract
+type ABI struct {
+	Constructor *Method
+	Methods     []*Method
+	Events      []*Event
+}
+
+// JSON parses the ABI of a contract in the JSON format generated by the Solidity compiler
+func JSON(data []byte) (*ABI, error) {
+	var entries []struct {
+		Type            string    `json:"type"`
+		Name            string    `json:"name"`
+		Inputs          Arguments `json:"inputs"`
+		Outputs         Arguments `json:"outputs"`
+		StateMutability string    `json:"stateMutability"`
+		Constant        bool      `json:"constant"`
+		Anonymous       bool      `json:"anonymous"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	abi := &ABI{}
+	for _, entry := range entries {
+		switch entry.Type {
+		case "constructor":
+			abi.Constructor = &Method{Inputs: entry.Inputs, StateMutability: entry.StateMutability}
+		case "function", "":
+			abi.Methods = append(abi.Methods, &Method{
+				Name:            entry.Name,
+				Inputs:          entry.Inputs,
+				Outputs:         entry.Outputs,
+				StateMutability: entry.StateMutability,
+				Constant:        entry.Constant,
+			})
+		case "event":
+			abi.Events = append(abi.Events, &Event{
+				Name:      entry.Name,
+				Inputs:    entry.Inputs,
+				Anonymous: entry.Anonymous,
+			})
+		}
+	}
+	if abi.Constructor == nil {
+		abi.Constructor = &Method{}
+	}
+	return abi, nil
+}
+
+// Method returns the method with the name, or with the signature if the method is overloaded,
+// e.g. safeTransferFrom(address,address,uint256)
+func (abi *ABI) Method(nameOrSig string) (*Method, error) {
+	var found []*Method
+	for _, m := range abi.Methods {
+		if m.Sig() == nameOrSig {
+			return m, nil
+		}
+		if m.Name == nameOrSig {
+			found = append(found, m)
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("Method %v not found", nameOrSig)
+	}
+	if len(found) > 1 {
+		sigs := make([]string, len(found))
+		for i, m := range found {
+			sigs[i] = m.Sig()
+		}
+		return nil, fmt.Errorf("Method %v is overloaded, specify one of %v", nameOrSig, strings.Join(sigs, ", "))
+	}
+	return found[0], nil
+}
+
+// EventByID returns the event with the ID, i.e. the first topic of its logs
+func (abi *ABI) EventByID(id common.Hash) (*Event, bool) {
+	for _, e := range abi.Events {
+		if !e.Anonymous && e.ID() == id {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// UnpackLog decodes the inputs of the event from the topics and data of a log. The indexed inputs
+// of the dynamic types are only stored as hashes, which are returned as common.Hash.
+func (e *Event) UnpackLog(topics []common.Hash, data []byte) ([]interface{}, error) {
+	if !e.Anonymous {
+		if len(topics) == 0 || topics[0] != e.ID() {
+			return nil, fmt.Errorf("Log is not an event %v", e.Sig())
+		}
+		topics = topics[1:]
+	}
+
+	nonIndexed := Arguments{}
+	for _, input := range e.Inputs {
+		if !input.Indexed {
+			nonIndexed = append(nonIndexed, input)
+		}
+	}
+	dataValues, err := nonIndexed.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0, len(e.Inputs))
+	for _, input := range e.Inputs {
+		if !input.Indexed {
+			values = append(values, dataValues[0])
+			dataValues = dataValues[1:]
+			continue
+		}
+		if len(topics) == 0 {
+			return nil, fmt.Errorf("Missing topic of the indexed input %v", input.Name)
+		}
+		topic := topics[0]
+		topics = topics[1:]
+		if input.Type.IsDynamic() || input.Type.Kind == ArrayKind || input.Type.Kind == TupleKind {
+			values = append(values, topic)
+			continue
+		}
+		value, err := unpack(input.Type, topic[:])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// errorSelector is the selector of Error(string), with which the contracts revert with a reason
+var errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+// UnpackRevertReason decodes the reason of a reverted call from its return data
+func UnpackRevertReason(data []byte) (string, bool) {
+	if len(data) < 4 || string(data[:4]) != string(errorSelector) {
+		return "", false
+	}
+	values, err := Arguments{{Type: Type{Kind: StringKind}}}.Unpack(data[4:])
+	if err != nil {
+		return "", false
+	}
+	return values[0].(string), true
+}
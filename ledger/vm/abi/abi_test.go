@@ -0,0 +1,172 @@
+package abi
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+const testABI = `[
+	{"type":"constructor","inputs":[{"name":"supply","type":"uint256"}]},
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]},
+	{"type":"function","name":"sam","inputs":[{"name":"","type":"bytes"},{"name":"","type":"bool"},{"name":"","type":"uint256[]"}],"outputs":[]},
+	{"type":"function","name":"pair","inputs":[{"name":"p","type":"tuple","components":[{"name":"a","type":"int8"},{"name":"b","type":"string"}]}],"outputs":[{"name":"","type":"int8[2]"}]},
+	{"type":"function","name":"safeTransferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"safeTransferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"data","type":"bytes"}],"outputs":[]},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestMethodSelectors(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	abi, err := JSON([]byte(testABI))
+	require.Nil(err)
+
+	transfer, err := abi.Method("transfer")
+	require.Nil(err)
+	assert.Equal("transfer(address,uint256)", transfer.Sig())
+	assert.Equal("a9059cbb", hex.EncodeToString(transfer.ID()))
+	assert.False(transfer.IsReadOnly())
+
+	balanceOf, err := abi.Method("balanceOf")
+	require.Nil(err)
+	assert.True(balanceOf.IsReadOnly())
+
+	pair, err := abi.Method("pair")
+	require.Nil(err)
+	assert.Equal("pair((int8,string))", pair.Sig())
+
+	_, err = abi.Method("safeTransferFrom")
+	assert.NotNil(err)
+	safeTransferFrom, err := abi.Method("safeTransferFrom(address,address,uint256,bytes)")
+	require.Nil(err)
+	assert.Equal(4, len(safeTransferFrom.Inputs))
+
+	_, err = abi.Method("approve")
+	assert.NotNil(err)
+
+	transferEvent, ok := abi.EventByID(common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"))
+	require.True(ok)
+	assert.Equal("Transfer", transferEvent.Name)
+}
+
+func TestPackUnpack(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	abi, err := JSON([]byte(testABI))
+	require.Nil(err)
+
+	// The example in the Solidity ABI specification
+	sam, err := abi.Method("sam")
+	require.Nil(err)
+	values, err := sam.Inputs.ParseValues([]string{"0x64617665", "true", "[1,2,3]"})
+	require.Nil(err)
+	data, err := sam.Pack(values...)
+	require.Nil(err)
+	expected := decodeHex(`a5643bf2
+		0000000000000000000000000000000000000000000000000000000000000060
+		0000000000000000000000000000000000000000000000000000000000000001
+		00000000000000000000000000000000000000000000000000000000000000a0
+		0000000000000000000000000000000000000000000000000000000000000004
+		6461766500000000000000000000000000000000000000000000000000000000
+		0000000000000000000000000000000000000000000000000000000000000003
+		0000000000000000000000000000000000000000000000000000000000000001
+		0000000000000000000000000000000000000000000000000000000000000002
+		0000000000000000000000000000000000000000000000000000000000000003`)
+	assert.Equal(expected, data)
+
+	unpacked, err := sam.Inputs.Unpack(data[4:])
+	require.Nil(err)
+	assert.Equal([]byte("dave"), unpacked[0])
+	assert.Equal(true, unpacked[1])
+	assert.Equal([]interface{}{"1", "2", "3"}, FormatValue(sam.Inputs[2].Type, unpacked[2]))
+
+	// Tuples with dynamic components, and negative integers
+	pair, err := abi.Method("pair")
+	require.Nil(err)
+	values, err = pair.Inputs.ParseValues([]string{`[-1, "theta"]`})
+	require.Nil(err)
+	data, err = pair.Pack(values...)
+	require.Nil(err)
+	unpacked, err = pair.Inputs.Unpack(data[4:])
+	require.Nil(err)
+	assert.Equal(map[string]interface{}{"p": map[string]interface{}{"a": "-1", "b": "theta"}}, FormatValues(pair.Inputs, unpacked))
+
+	ret := decodeHex(`
+		ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff80
+		000000000000000000000000000000000000000000000000000000000000007f`)
+	unpacked, err = pair.Outputs.Unpack(ret)
+	require.Nil(err)
+	assert.Equal([]interface{}{"-128", "127"}, FormatValue(pair.Outputs[0].Type, unpacked[0]))
+
+	// The values out of range are rejected
+	_, err = pair.Inputs.ParseValues([]string{`[128, "theta"]`})
+	assert.NotNil(err)
+	_, err = abi.Constructor.Inputs.ParseValues([]string{"-1"})
+	assert.NotNil(err)
+
+	// Truncated data is rejected
+	_, err = sam.Inputs.Unpack(expected[4 : len(expected)-32])
+	assert.NotNil(err)
+}
+
+func TestUnpackLog(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	abi, err := JSON([]byte(testABI))
+	require.Nil(err)
+
+	from := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	to := common.HexToAddress("0x9F1233798E905E173560071255140b4A8aBd3Ec6")
+	topics := []common.Hash{
+		common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"),
+		common.BytesToHash(from[:]),
+		common.BytesToHash(to[:]),
+	}
+	data := common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)
+
+	event, ok := abi.EventByID(topics[0])
+	require.True(ok)
+	values, err := event.UnpackLog(topics, data)
+	require.Nil(err)
+	assert.Equal(map[string]interface{}{
+		"from":  from.Hex(),
+		"to":    to.Hex(),
+		"value": "1000",
+	}, FormatValues(event.Inputs, values))
+
+	_, err = event.UnpackLog(topics[:2], data)
+	assert.NotNil(err)
+}
+
+func TestUnpackRevertReason(t *testing.T) {
+	assert := assert.New(t)
+
+	data := decodeHex(`08c379a0
+		0000000000000000000000000000000000000000000000000000000000000020
+		000000000000000000000000000000000000000000000000000000000000001a
+		4e6f7420656e6f7567682045746865722070726f76696465642e000000000000`)
+	reason, ok := UnpackRevertReason(data)
+	assert.True(ok)
+	assert.Equal("Not enough Ether provided.", reason)
+
+	_, ok = UnpackRevertReason([]byte{1, 2, 3, 4})
+	assert.False(ok)
+}
@@ -0,0 +1,251 @@
+package abi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// The values of the ABI types are represented as:
+//   uint<N>, int<N>          *big.Int
+//   address                  common.Address
+//   bool                     bool
+//   bytes<N>, bytes          []byte
+//   string                   string
+//   T[], T[k], tuples        []interface{}
+
+var (
+	tt256   = new(big.Int).Lsh(big.NewInt(1), 256)
+	tt255   = new(big.Int).Lsh(big.NewInt(1), 255)
+	maxSize = big.NewInt(1 << 32) // the maximum length and offset accepted when decoding
+)
+
+// Pack encodes the values of the arguments
+func (args Arguments) Pack(values ...interface{}) ([]byte, error) {
+	if len(values) != len(args) {
+		return nil, fmt.Errorf("Expected %v arguments, got %v", len(args), len(values))
+	}
+	return packTuple(args.types(), values)
+}
+
+func packTuple(types []Type, values []interface{}) ([]byte, error) {
+	if len(values) != len(types) {
+		return nil, fmt.Errorf("Expected %v values, got %v", len(types), len(values))
+	}
+	offset := 0
+	for _, t := range types {
+		offset += t.headSize()
+	}
+
+	var head, tail []byte
+	for i, t := range types {
+		encoded, err := pack(t, values[i])
+		if err != nil {
+			return nil, err
+		}
+		if t.IsDynamic() {
+			head = append(head, packUint(big.NewInt(int64(offset)))...)
+			tail = append(tail, encoded...)
+			offset += len(encoded)
+		} else {
+			head = append(head, encoded...)
+		}
+	}
+	return append(head, tail...), nil
+}
+
+func pack(t Type, value interface{}) ([]byte, error) {
+	switch t.Kind {
+	case UintKind, IntKind:
+		v, ok := value.(*big.Int)
+		if !ok {
+			return nil, fmt.Errorf("Expected *big.Int for %v, got %T", t, value)
+		}
+		if !fitsInt(t, v) {
+			return nil, fmt.Errorf("Value %v out of range of %v", v, t)
+		}
+		return packUint(v), nil
+	case AddressKind:
+		v, ok := value.(common.Address)
+		if !ok {
+			return nil, fmt.Errorf("Expected common.Address for %v, got %T", t, value)
+		}
+		return common.LeftPadBytes(v[:], 32), nil
+	case BoolKind:
+		v, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("Expected bool for %v, got %T", t, value)
+		}
+		if v {
+			return packUint(big.NewInt(1)), nil
+		}
+		return packUint(big.NewInt(0)), nil
+	case FixedBytesKind:
+		v, ok := value.([]byte)
+		if !ok || len(v) != t.Size {
+			return nil, fmt.Errorf("Expected %v bytes for %v", t.Size, t)
+		}
+		return common.RightPadBytes(v, 32), nil
+	case BytesKind, StringKind:
+		var v []byte
+		switch value := value.(type) {
+		case []byte:
+			v = value
+		case string:
+			v = []byte(value)
+		default:
+			return nil, fmt.Errorf("Expected []byte or string for %v, got %T", t, value)
+		}
+		encoded := packUint(big.NewInt(int64(len(v))))
+		return append(encoded, common.RightPadBytes(v, (len(v)+31)/32*32)...), nil
+	case SliceKind, ArrayKind, TupleKind:
+		v, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected []interface{} for %v, got %T", t, value)
+		}
+		if t.Kind == ArrayKind && len(v) != t.Size {
+			return nil, fmt.Errorf("Expected %v elements for %v, got %v", t.Size, t, len(v))
+		}
+		encoded, err := packTuple(t.elemTypes(len(v)), v)
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind == SliceKind {
+			encoded = append(packUint(big.NewInt(int64(len(v)))), encoded...)
+		}
+		return encoded, nil
+	}
+	return nil, fmt.Errorf("Unsupported type %v", t)
+}
+
+// packUint encodes the integer as 32 bytes in two's complement
+func packUint(v *big.Int) []byte {
+	if v.Sign() < 0 {
+		v = new(big.Int).Add(v, tt256)
+	}
+	return common.LeftPadBytes(v.Bytes(), 32)
+}
+
+func fitsInt(t Type, v *big.Int) bool {
+	if t.Kind == UintKind {
+		return v.Sign() >= 0 && v.BitLen() <= t.Size
+	}
+	// -2^(N-1) <= v < 2^(N-1)
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+	return v.Cmp(limit) < 0 && v.Cmp(new(big.Int).Neg(limit)) >= 0
+}
+
+// ParseValues converts the arguments given as strings, e.g. on the command line, into the
+// values of their types. The integers are decimal or 0x prefixed hex, the bytes are hex, and the
+// arrays and tuples are JSON arrays, e.g. ["0x2E83...","100"].
+func (args Arguments) ParseValues(strs []string) ([]interface{}, error) {
+	if len(strs) != len(args) {
+		return nil, fmt.Errorf("Expected %v arguments, got %v", len(args), len(strs))
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		value, err := ParseValue(arg.Type, strs[i])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid argument %v: %v", argName(arg, i), err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// ParseValue converts a string, or an element decoded from a JSON array, into a value of the type
+func ParseValue(t Type, input interface{}) (interface{}, error) {
+	if t.Kind == SliceKind || t.Kind == ArrayKind || t.Kind == TupleKind {
+		elems, ok := input.([]interface{})
+		if !ok {
+			str, ok := input.(string)
+			if !ok {
+				return nil, fmt.Errorf("Expected a JSON array for %v", t)
+			}
+			decoder := json.NewDecoder(strings.NewReader(str))
+			decoder.UseNumber()
+			if err := decoder.Decode(&elems); err != nil {
+				return nil, fmt.Errorf("Expected a JSON array for %v: %v", t, err)
+			}
+		}
+		if t.Kind == ArrayKind && len(elems) != t.Size {
+			return nil, fmt.Errorf("Expected %v elements for %v, got %v", t.Size, t, len(elems))
+		}
+		types := t.elemTypes(len(elems))
+		if len(types) != len(elems) {
+			return nil, fmt.Errorf("Expected %v elements for %v, got %v", len(types), t, len(elems))
+		}
+		values := make([]interface{}, len(elems))
+		for i, elem := range elems {
+			value, err := ParseValue(types[i], elem)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+
+	var str string
+	switch input := input.(type) {
+	case string:
+		str = input
+	case json.Number:
+		str = input.String()
+	case bool:
+		str = fmt.Sprintf("%v", input)
+	default:
+		return nil, fmt.Errorf("Invalid value %v for %v", input, t)
+	}
+	if t.Kind != StringKind {
+		str = strings.TrimSpace(str)
+	}
+
+	switch t.Kind {
+	case UintKind, IntKind:
+		v, ok := new(big.Int).SetString(str, 0)
+		if !ok {
+			return nil, fmt.Errorf("Invalid integer %v", str)
+		}
+		if !fitsInt(t, v) {
+			return nil, fmt.Errorf("Value %v out of range of %v", v, t)
+		}
+		return v, nil
+	case AddressKind:
+		if !common.IsHexAddress(str) {
+			return nil, fmt.Errorf("Invalid address %v", str)
+		}
+		return common.HexToAddress(str), nil
+	case BoolKind:
+		switch strings.ToLower(str) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("Invalid bool %v", str)
+	case FixedBytesKind, BytesKind:
+		v, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(str, "0x"), "0X"))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid hex %v", str)
+		}
+		if t.Kind == FixedBytesKind && len(v) != t.Size {
+			return nil, fmt.Errorf("Expected %v bytes for %v, got %v", t.Size, t, len(v))
+		}
+		return v, nil
+	case StringKind:
+		return str, nil
+	}
+	return nil, fmt.Errorf("Unsupported type %v", t)
+}
+
+func argName(arg Argument, i int) string {
+	if arg.Name != "" {
+		return arg.Name
+	}
+	return fmt.Sprintf("#%v", i)
+}
@@ -0,0 +1,164 @@
+package abi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind is the kind of an ABI type
+type Kind int
+
+const (
+	UintKind Kind = iota
+	IntKind
+	AddressKind
+	BoolKind
+	FixedBytesKind
+	BytesKind
+	StringKind
+	SliceKind
+	ArrayKind
+	TupleKind
+)
+
+// Type is an ABI type, e.g. uint256, bytes32[] or (address,uint256)
+type Type struct {
+	Kind       Kind
+	Size       int        // number of bits of the integers, bytes of the fixed bytes, or elements of the arrays
+	Elem       *Type      // element type of the slices and arrays
+	Components []Argument // components of the tuples
+}
+
+// NewType parses an ABI type string, with the components of the tuple types
+func NewType(t string, components []Argument) (Type, error) {
+	if strings.HasSuffix(t, "]") {
+		idx := strings.LastIndex(t, "[")
+		if idx <= 0 {
+			return Type{}, fmt.Errorf("Invalid type %v", t)
+		}
+		elem, err := NewType(t[:idx], components)
+		if err != nil {
+			return Type{}, err
+		}
+		dims := t[idx+1 : len(t)-1]
+		if dims == "" {
+			return Type{Kind: SliceKind, Elem: &elem}, nil
+		}
+		size, err := strconv.Atoi(dims)
+		if err != nil || size <= 0 {
+			return Type{}, fmt.Errorf("Invalid array length of type %v", t)
+		}
+		return Type{Kind: ArrayKind, Size: size, Elem: &elem}, nil
+	}
+
+	switch {
+	case t == "address":
+		return Type{Kind: AddressKind}, nil
+	case t == "bool":
+		return Type{Kind: BoolKind}, nil
+	case t == "string":
+		return Type{Kind: StringKind}, nil
+	case t == "bytes":
+		return Type{Kind: BytesKind}, nil
+	case t == "tuple":
+		if len(components) == 0 {
+			return Type{}, fmt.Errorf("Tuple type without components")
+		}
+		return Type{Kind: TupleKind, Components: components}, nil
+	case strings.HasPrefix(t, "bytes"):
+		size, err := strconv.Atoi(t[len("bytes"):])
+		if err != nil || size < 1 || size > 32 {
+			return Type{}, fmt.Errorf("Invalid type %v", t)
+		}
+		return Type{Kind: FixedBytesKind, Size: size}, nil
+	case strings.HasPrefix(t, "uint"), strings.HasPrefix(t, "int"):
+		kind, bits := UintKind, strings.TrimPrefix(t, "uint")
+		if strings.HasPrefix(t, "int") {
+			kind, bits = IntKind, strings.TrimPrefix(t, "int")
+		}
+		if bits == "" {
+			return Type{Kind: kind, Size: 256}, nil
+		}
+		size, err := strconv.Atoi(bits)
+		if err != nil || size < 8 || size > 256 || size%8 != 0 {
+			return Type{}, fmt.Errorf("Invalid type %v", t)
+		}
+		return Type{Kind: kind, Size: size}, nil
+	}
+	return Type{}, fmt.Errorf("Unsupported type %v", t)
+}
+
+// String returns the canonical type string used in the method and event signatures
+func (t Type) String() string {
+	switch t.Kind {
+	case UintKind:
+		return fmt.Sprintf("uint%v", t.Size)
+	case IntKind:
+		return fmt.Sprintf("int%v", t.Size)
+	case AddressKind:
+		return "address"
+	case BoolKind:
+		return "bool"
+	case FixedBytesKind:
+		return fmt.Sprintf("bytes%v", t.Size)
+	case BytesKind:
+		return "bytes"
+	case StringKind:
+		return "string"
+	case SliceKind:
+		return t.Elem.String() + "[]"
+	case ArrayKind:
+		return fmt.Sprintf("%v[%v]", t.Elem.String(), t.Size)
+	case TupleKind:
+		return Arguments(t.Components).signature()
+	}
+	return ""
+}
+
+// IsDynamic returns whether the encoding of the type has a variable length
+func (t Type) IsDynamic() bool {
+	switch t.Kind {
+	case BytesKind, StringKind, SliceKind:
+		return true
+	case ArrayKind:
+		return t.Elem.IsDynamic()
+	case TupleKind:
+		for _, c := range t.Components {
+			if c.Type.IsDynamic() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headSize returns the size of the type in the head of an encoded tuple
+func (t Type) headSize() int {
+	if t.IsDynamic() {
+		return 32
+	}
+	switch t.Kind {
+	case ArrayKind:
+		return t.Size * t.Elem.headSize()
+	case TupleKind:
+		size := 0
+		for _, c := range t.Components {
+			size += c.Type.headSize()
+		}
+		return size
+	}
+	return 32
+}
+
+// elemTypes returns the types of the elements of a slice or array, or the components of a tuple
+func (t Type) elemTypes(n int) []Type {
+	if t.Kind == TupleKind {
+		return Arguments(t.Components).types()
+	}
+	types := make([]Type, n)
+	for i := range types {
+		types[i] = *t.Elem
+	}
+	return types
+}
@@ -0,0 +1,147 @@
+package abi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// Unpack decodes the values of the arguments, e.g. the return values of a method
+func (args Arguments) Unpack(data []byte) ([]interface{}, error) {
+	if len(args) == 0 {
+		return []interface{}{}, nil
+	}
+	return unpackTuple(args.types(), data)
+}
+
+func unpackTuple(types []Type, data []byte) ([]interface{}, error) {
+	values := make([]interface{}, len(types))
+	pos := 0
+	for i, t := range types {
+		size := t.headSize()
+		if pos+size > len(data) {
+			return nil, fmt.Errorf("Insufficient data to decode %v", t)
+		}
+		var err error
+		if t.IsDynamic() {
+			offset, err := readSize(data[pos : pos+32])
+			if err != nil {
+				return nil, err
+			}
+			if offset > len(data) {
+				return nil, fmt.Errorf("Offset %v out of range to decode %v", offset, t)
+			}
+			values[i], err = unpack(t, data[offset:])
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			values[i], err = unpack(t, data[pos:pos+size])
+			if err != nil {
+				return nil, err
+			}
+		}
+		pos += size
+	}
+	return values, nil
+}
+
+func unpack(t Type, data []byte) (interface{}, error) {
+	if t.Kind != SliceKind && t.Kind != ArrayKind && t.Kind != TupleKind && len(data) < 32 {
+		return nil, fmt.Errorf("Insufficient data to decode %v", t)
+	}
+
+	switch t.Kind {
+	case UintKind, IntKind:
+		v := new(big.Int).SetBytes(data[:32])
+		if t.Kind == IntKind && v.Cmp(tt255) >= 0 {
+			v.Sub(v, tt256)
+		}
+		if !fitsInt(t, v) {
+			return nil, fmt.Errorf("Value %v out of range of %v", v, t)
+		}
+		return v, nil
+	case AddressKind:
+		return common.BytesToAddress(data[12:32]), nil
+	case BoolKind:
+		return data[31] == 1, nil
+	case FixedBytesKind:
+		return common.CopyBytes(data[:t.Size]), nil
+	case BytesKind, StringKind:
+		size, err := readSize(data[:32])
+		if err != nil {
+			return nil, err
+		}
+		if 32+size > len(data) {
+			return nil, fmt.Errorf("Insufficient data to decode %v", t)
+		}
+		if t.Kind == StringKind {
+			return string(data[32 : 32+size]), nil
+		}
+		return common.CopyBytes(data[32 : 32+size]), nil
+	case SliceKind:
+		if len(data) < 32 {
+			return nil, fmt.Errorf("Insufficient data to decode %v", t)
+		}
+		size, err := readSize(data[:32])
+		if err != nil {
+			return nil, err
+		}
+		// Each element takes at least 32 bytes in the head
+		if size > (len(data)-32)/32 {
+			return nil, fmt.Errorf("Insufficient data to decode %v", t)
+		}
+		return unpackTuple(t.elemTypes(size), data[32:])
+	case ArrayKind, TupleKind:
+		return unpackTuple(t.elemTypes(t.Size), data)
+	}
+	return nil, fmt.Errorf("Unsupported type %v", t)
+}
+
+// readSize reads a length or offset
+func readSize(word []byte) (int, error) {
+	v := new(big.Int).SetBytes(word)
+	if v.Cmp(maxSize) > 0 {
+		return 0, fmt.Errorf("Length or offset %v out of range", v)
+	}
+	return int(v.Int64()), nil
+}
+
+// FormatValue converts a value of the type into its JSON representation, with the integers as
+// decimal strings, the bytes as hex strings and the tuples as objects keyed by the component names
+func FormatValue(t Type, value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case common.Address:
+		return v.Hex()
+	case common.Hash:
+		return v.Hex()
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	case []interface{}:
+		if t.Kind == TupleKind {
+			return FormatValues(t.Components, v)
+		}
+		formatted := make([]interface{}, len(v))
+		for i, elem := range v {
+			formatted[i] = FormatValue(*t.Elem, elem)
+		}
+		return formatted
+	}
+	return value
+}
+
+// FormatValues converts the values of the arguments into a JSON object keyed by the argument
+// names, or by their indexes for the unnamed arguments
+func FormatValues(args Arguments, values []interface{}) map[string]interface{} {
+	formatted := make(map[string]interface{})
+	for i, arg := range args {
+		if i < len(values) {
+			formatted[argName(arg, i)] = FormatValue(arg.Type, values[i])
+		}
+	}
+	return formatted
+}
@@ -0,0 +1,164 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/hexutil"
+)
+
+var _ Tracer = (*CallTracer)(nil)
+
+// CallFrame records a single message call (or contract creation) made during the
+// execution of a transaction, along with the nested calls it made.
+type CallFrame struct {
+	Type    string            `json:"type"`
+	From    common.Address    `json:"from"`
+	To      common.Address    `json:"to"`
+	Value   *common.JSONBig   `json:"value"`
+	Gas     common.JSONUint64 `json:"gas"`
+	GasUsed common.JSONUint64 `json:"gas_used"`
+	Input   hexutil.Bytes     `json:"input"`
+	Output  hexutil.Bytes     `json:"output,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Calls   []*CallFrame      `json:"calls,omitempty"`
+
+	depth int
+}
+
+// CallTracer is an EVM tracer that captures the call tree of a transaction rather
+// than the individual opcodes executed. It implements Tracer.
+type CallTracer struct {
+	root  *CallFrame
+	stack []*CallFrame
+}
+
+// NewCallTracer returns a new call tracer
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+// CaptureStart implements the Tracer interface to initialize the top level call frame.
+func (t *CallTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	callType := CALL.String()
+	if create {
+		callType = CREATE.String()
+	}
+	t.root = &CallFrame{
+		Type:  callType,
+		From:  from,
+		To:    to,
+		Value: (*common.JSONBig)(new(big.Int).Set(value)),
+		Gas:   common.JSONUint64(gas),
+		Input: common.CopyBytes(input),
+		depth: 1,
+	}
+	t.stack = []*CallFrame{t.root}
+	return nil
+}
+
+// CaptureState implements the Tracer interface. It opens a new call frame whenever a
+// call or create opcode is about to be executed, and closes the frames that have returned.
+func (t *CallTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	if t.root == nil || err != nil {
+		return nil
+	}
+
+	// The first step at a shallower depth follows the return of the nested calls. The
+	// result of the call (or the address of the created contract) sits on top of the stack.
+	for len(t.stack) > 1 && t.stack[len(t.stack)-1].depth > depth {
+		frame := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		if stack.len() == 0 {
+			continue
+		}
+		res := stack.peek()
+		if res.Sign() == 0 {
+			if frame.Error == "" {
+				frame.Error = "execution failed"
+			}
+		} else if frame.Type == CREATE.String() || frame.Type == CREATE2.String() {
+			frame.To = common.BigToAddress(res)
+		}
+	}
+
+	var frame *CallFrame
+	switch op {
+	case CALL, CALLCODE:
+		if stack.len() < 5 {
+			return nil
+		}
+		frame = &CallFrame{
+			To:    common.BigToAddress(stack.Back(1)),
+			Value: (*common.JSONBig)(new(big.Int).Set(stack.Back(2))),
+			Gas:   common.JSONUint64(stack.Back(0).Uint64()),
+			Input: memory.Get(stack.Back(3).Int64(), stack.Back(4).Int64()),
+		}
+	case DELEGATECALL, STATICCALL:
+		if stack.len() < 4 {
+			return nil
+		}
+		frame = &CallFrame{
+			To:    common.BigToAddress(stack.Back(1)),
+			Value: (*common.JSONBig)(new(big.Int)),
+			Gas:   common.JSONUint64(stack.Back(0).Uint64()),
+			Input: memory.Get(stack.Back(2).Int64(), stack.Back(3).Int64()),
+		}
+	case CREATE, CREATE2:
+		if stack.len() < 3 {
+			return nil
+		}
+		frame = &CallFrame{
+			Value: (*common.JSONBig)(new(big.Int).Set(stack.Back(0))),
+			Gas:   common.JSONUint64(gas),
+			Input: memory.Get(stack.Back(1).Int64(), stack.Back(2).Int64()),
+		}
+	default:
+		return nil
+	}
+
+	frame.Type = op.String()
+	frame.From = contract.Address()
+	frame.depth = depth + 1
+
+	parent := t.stack[len(t.stack)-1]
+	parent.Calls = append(parent.Calls, frame)
+	t.stack = append(t.stack, frame)
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to record the error of the innermost open frame.
+func (t *CallTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	if len(t.stack) == 0 || err == nil {
+		return nil
+	}
+	frame := t.stack[len(t.stack)-1]
+	if frame.depth == depth && frame.Error == "" {
+		frame.Error = err.Error()
+	}
+	return nil
+}
+
+// CaptureEnd is called after the top level call finishes to finalize the tracing.
+func (t *CallTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	if t.root == nil {
+		return nil
+	}
+	t.root.Output = common.CopyBytes(output)
+	t.root.GasUsed = common.JSONUint64(gasUsed)
+	if err != nil {
+		t.root.Error = err.Error()
+	}
+	t.stack = nil
+	return nil
+}
+
+// Result returns the captured call tree.
+func (t *CallTracer) Result() (*CallFrame, error) {
+	if t.root == nil {
+		return nil, errors.New("no call has been traced")
+	}
+	return t.root, nil
+}
@@ -0,0 +1,75 @@
+package vm
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestCallTracer(t *testing.T) {
+	assert := assert.New(t)
+
+	callerAddr := common.HexToAddress("1133")
+	contractAddr := common.HexToAddress("2266")
+	calleeAddr := common.HexToAddress("3399")
+
+	// ASM:
+	// push 0x3
+	// push 0x13
+	// mstore8
+	// push 0x1
+	// push 0x13
+	// return
+	calleeCode, _ := hex.DecodeString("600360135360016013f3")
+
+	// ASM:
+	// push 0x1
+	// push 0x0
+	// push 0x0
+	// push 0x0
+	// push 0x0
+	// push20 0x3399
+	// push2 0xffff
+	// call
+	// push 0x1
+	// push 0x0
+	// return
+	contractCode, _ := hex.DecodeString("6001600060006000600073" + hex.EncodeToString(calleeAddr[:]) + "61fffff160016000f3")
+
+	store := state.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	account := store.GetOrCreateAccount(callerAddr)
+	account.Balance = types.NewCoins(1000, 2000)
+	store.SetAccount(callerAddr, account)
+	store.CreateAccount(contractAddr)
+	store.SetCode(contractAddr, contractCode)
+	store.CreateAccount(calleeAddr)
+	store.SetCode(calleeAddr, calleeCode)
+
+	tracer := NewCallTracer()
+	evm := NewEVM(Context{}, store, nil, Config{Debug: true, Tracer: tracer})
+	ret, _, err := evm.Call(AccountRef(callerAddr), contractAddr, nil, 100000, big.NewInt(0), big.NewInt(0))
+	assert.Nil(err)
+	assert.Equal([]byte{0x3}, ret)
+
+	root, err := tracer.Result()
+	assert.Nil(err)
+	assert.Equal("CALL", root.Type)
+	assert.Equal(callerAddr, root.From)
+	assert.Equal(contractAddr, root.To)
+	assert.Equal("", root.Error)
+	assert.True(root.GasUsed > 0)
+
+	assert.Equal(1, len(root.Calls))
+	call := root.Calls[0]
+	assert.Equal("CALL", call.Type)
+	assert.Equal(contractAddr, call.From)
+	assert.Equal(calleeAddr, call.To)
+	assert.Equal("", call.Error)
+}
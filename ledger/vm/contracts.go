@@ -19,13 +19,17 @@ package vm
 import (
 	"crypto/sha256"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/math"
+	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/crypto/bn256"
+	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/ledger/vm/params"
+	"github.com/thetatoken/theta/rlp"
 	"golang.org/x/crypto/ripemd160"
 )
 
@@ -77,6 +81,30 @@ var PrecompiledContractsThetaSupport = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{203}): &transferTheta{},
 }
 
+// PrecompiledContractsThetaNative contains the pre-compiled contracts exposing the native ledger
+// operations, e.g. the validator set, guardian votes and reserve funds, to the smart contracts
+var PrecompiledContractsThetaNative = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{1}): &ecrecover{},
+	common.BytesToAddress([]byte{2}): &sha256hash{},
+	common.BytesToAddress([]byte{3}): &ripemd160hash{},
+	common.BytesToAddress([]byte{4}): &dataCopy{},
+	common.BytesToAddress([]byte{5}): &bigModExp{},
+	common.BytesToAddress([]byte{6}): &bn256Add{},
+	common.BytesToAddress([]byte{7}): &bn256ScalarMul{},
+	common.BytesToAddress([]byte{8}): &bn256Pairing{},
+
+	common.BytesToAddress([]byte{201}): &thetaBalance{},
+	common.BytesToAddress([]byte{202}): &thetaStake{},
+	common.BytesToAddress([]byte{203}): &transferTheta{},
+	common.BytesToAddress([]byte{204}): &nativeBalances{},
+	common.BytesToAddress([]byte{205}): &verifyGuardianVotes{},
+	common.BytesToAddress([]byte{206}): &validatorSet{},
+	common.BytesToAddress([]byte{207}): &holderStake{},
+	common.BytesToAddress([]byte{208}): &reservedFunds{},
+	common.BytesToAddress([]byte{209}): &reserveFund{},
+	common.BytesToAddress([]byte{210}): &releaseFund{},
+}
+
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
 func RunPrecompiledContract(evm *EVM, p PrecompiledContract, input []byte, contract *Contract) (ret []byte, err error) {
 	blockHeight := evm.StateDB.GetBlockHeight()
@@ -445,3 +473,243 @@ func (c *transferTheta) Run(evm *EVM, input []byte, callerAddr common.Address) (
 
 	return common.Bytes{}, nil
 }
+
+// maxNumValidators is the maximum size of the validator set, same as consensus.MaxValidatorCount
+const maxNumValidators = 31
+
+var (
+	errNotSmartContract   = errors.New("caller is not a smart contract")
+	errInvalidResourceID  = errors.New("resource ID cannot be empty")
+	errInvalidReserveFund = errors.New("invalid reserve fund input")
+)
+
+func uint64To32Bytes(v uint64) []byte {
+	return common.LeftPadBytes(new(big.Int).SetUint64(v).Bytes(), 32)
+}
+
+func bigTo32Bytes(v *big.Int) []byte {
+	if v == nil {
+		return make([]byte, 32)
+	}
+	return common.LeftPadBytes(v.Bytes(), 32)
+}
+
+// nativeBalances retrieves both the ThetaWei and TFuelWei balances of the given address
+type nativeBalances struct {
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *nativeBalances) RequiredGas(input []byte, blockHeight uint64) uint64 {
+	return params.NativeBalancesGas
+}
+
+func (c *nativeBalances) Run(evm *EVM, input []byte, callerAddr common.Address) ([]byte, error) {
+	address := common.BytesToAddress(getData(input, 0, 20))
+	ret := bigTo32Bytes(evm.StateDB.GetThetaBalance(address))
+	ret = append(ret, bigTo32Bytes(evm.StateDB.GetBalance(address))...)
+	return ret, nil
+}
+
+// verifyGuardianVotes verifies the RLP encoded guardian aggregated votes against the current
+// guardian candidate pool. It returns 1 if the aggregated signature is valid, and 0 otherwise.
+type verifyGuardianVotes struct {
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract. The size of the
+// votes grows linearly with the number of guardians, so is the cost of the verification.
+func (c *verifyGuardianVotes) RequiredGas(input []byte, blockHeight uint64) uint64 {
+	return params.GuardianVotesBaseGas + uint64(len(input))*params.GuardianVotesPerByteGas
+}
+
+func (c *verifyGuardianVotes) Run(evm *EVM, input []byte, callerAddr common.Address) ([]byte, error) {
+	votes := &core.AggregatedVotes{}
+	if err := rlp.DecodeBytes(input, votes); err != nil {
+		return false32Byte, nil
+	}
+	gcp := evm.StateDB.GetGuardianCandidatePool()
+	if res := votes.Validate(gcp); res.IsError() {
+		return false32Byte, nil
+	}
+	return true32Byte, nil
+}
+
+// validatorSet retrieves the validator set, i.e. the top validator candidates by stake. The output
+// is the number of validators, followed by the address and the total stake of each validator.
+type validatorSet struct {
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *validatorSet) RequiredGas(input []byte, blockHeight uint64) uint64 {
+	return params.ValidatorSetGas
+}
+
+func (c *validatorSet) Run(evm *EVM, input []byte, callerAddr common.Address) ([]byte, error) {
+	var validators []*core.StakeHolder
+	vcp := evm.StateDB.GetValidatorCandidatePool()
+	if vcp != nil {
+		for _, candidate := range vcp.GetTopStakeHolders(maxNumValidators) {
+			if candidate.TotalStake().Sign() == 0 {
+				continue
+			}
+			validators = append(validators, candidate)
+		}
+	}
+
+	ret := uint64To32Bytes(uint64(len(validators)))
+	for _, v := range validators {
+		ret = append(ret, common.LeftPadBytes(v.Holder.Bytes(), 32)...)
+		ret = append(ret, bigTo32Bytes(v.TotalStake())...)
+	}
+	return ret, nil
+}
+
+// holderStake retrieves the total stake of a validator candidate or a guardian. The input is the
+// 20-byte holder address, followed by an optional 1-byte stake purpose (validator by default).
+type holderStake struct {
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *holderStake) RequiredGas(input []byte, blockHeight uint64) uint64 {
+	return params.HolderStakeGas
+}
+
+func (c *holderStake) Run(evm *EVM, input []byte, callerAddr common.Address) ([]byte, error) {
+	holder := common.BytesToAddress(getData(input, 0, 20))
+	purpose := getData(input, 20, 1)[0]
+
+	var stakeHolder *core.StakeHolder
+	switch purpose {
+	case core.StakeForValidator:
+		if vcp := evm.StateDB.GetValidatorCandidatePool(); vcp != nil {
+			stakeHolder = vcp.FindStakeDelegate(holder)
+		}
+	case core.StakeForGuardian:
+		if g := evm.StateDB.GetGuardianCandidatePool().GetWithHolderAddress(holder); g != nil {
+			stakeHolder = g.StakeHolder
+		}
+	default:
+		return nil, fmt.Errorf("unsupported stake purpose: %v", purpose)
+	}
+
+	if stakeHolder == nil {
+		return make([]byte, 32), nil
+	}
+	return bigTo32Bytes(stakeHolder.TotalStake()), nil
+}
+
+// reservedFunds retrieves the reserved funds of the given address. The output is the number of
+// reserved funds, followed by the reserve sequence, initial TFuelWei fund, used TFuelWei fund,
+// TFuelWei collateral and end block height of each reserved fund.
+type reservedFunds struct {
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *reservedFunds) RequiredGas(input []byte, blockHeight uint64) uint64 {
+	return params.ReservedFundsGas
+}
+
+func (c *reservedFunds) Run(evm *EVM, input []byte, callerAddr common.Address) ([]byte, error) {
+	address := common.BytesToAddress(getData(input, 0, 20))
+	account := evm.StateDB.GetAccount(address)
+	if account == nil {
+		return make([]byte, 32), nil
+	}
+
+	ret := uint64To32Bytes(uint64(len(account.ReservedFunds)))
+	for _, fund := range account.ReservedFunds {
+		ret = append(ret, uint64To32Bytes(fund.ReserveSequence)...)
+		ret = append(ret, bigTo32Bytes(fund.InitialFund.TFuelWei)...)
+		ret = append(ret, bigTo32Bytes(fund.UsedFund.TFuelWei)...)
+		ret = append(ret, bigTo32Bytes(fund.Collateral.TFuelWei)...)
+		ret = append(ret, uint64To32Bytes(fund.EndBlockHeight)...)
+	}
+	return ret, nil
+}
+
+// reserveFund reserves TFuel from the balance of the calling contract for service payments, similar
+// to the ReserveFundTx. The input is the 32-byte TFuelWei fund, the 32-byte TFuelWei collateral, the
+// 32-byte duration in blocks, followed by the resource ID. The reserve sequence is assigned
+// incrementally, and returned as a 32-byte word.
+type reserveFund struct {
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *reserveFund) RequiredGas(input []byte, blockHeight uint64) uint64 {
+	return params.ReserveFundGas
+}
+
+func (c *reserveFund) Run(evm *EVM, input []byte, callerAddr common.Address) ([]byte, error) {
+	if len(input) < 96 {
+		return nil, errInvalidReserveFund
+	}
+	fund := types.Coins{
+		ThetaWei: big.NewInt(0),
+		TFuelWei: new(big.Int).SetBytes(input[0:32]),
+	}
+	collateral := types.Coins{
+		ThetaWei: big.NewInt(0),
+		TFuelWei: new(big.Int).SetBytes(input[32:64]),
+	}
+	durationBig := new(big.Int).SetBytes(input[64:96])
+	if !durationBig.IsUint64() {
+		return nil, errInvalidReserveFund
+	}
+	duration := durationBig.Uint64()
+	resourceID := string(input[96:])
+	if len(resourceID) == 0 {
+		return nil, errInvalidResourceID
+	}
+
+	account := evm.StateDB.GetAccount(callerAddr)
+	if account == nil || !account.IsASmartContract() {
+		return nil, errNotSmartContract
+	}
+
+	// The reserve sequence of an account needs to be strictly increasing
+	reserveSequence := uint64(1)
+	for _, reservedFund := range account.ReservedFunds {
+		if reservedFund.ReserveSequence >= reserveSequence {
+			reserveSequence = reservedFund.ReserveSequence + 1
+		}
+	}
+	if err := account.CheckReserveFund(collateral, fund, duration, reserveSequence); err != nil {
+		return nil, err
+	}
+
+	endBlockHeight := evm.StateDB.GetBlockHeight() + duration
+	account.ReserveFund(collateral, fund, []string{resourceID}, endBlockHeight, reserveSequence)
+	evm.StateDB.SetAccount(callerAddr, account)
+
+	return uint64To32Bytes(reserveSequence), nil
+}
+
+// releaseFund releases the reserved fund of the calling contract with the given 32-byte reserve sequence
+type releaseFund struct {
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *releaseFund) RequiredGas(input []byte, blockHeight uint64) uint64 {
+	return params.ReleaseFundGas
+}
+
+func (c *releaseFund) Run(evm *EVM, input []byte, callerAddr common.Address) ([]byte, error) {
+	reserveSequenceBig := new(big.Int).SetBytes(getData(input, 0, 32))
+	if !reserveSequenceBig.IsUint64() {
+		return nil, errInvalidReserveFund
+	}
+	reserveSequence := reserveSequenceBig.Uint64()
+
+	account := evm.StateDB.GetAccount(callerAddr)
+	if account == nil || !account.IsASmartContract() {
+		return nil, errNotSmartContract
+	}
+
+	currentBlockHeight := evm.StateDB.GetBlockHeight()
+	if err := account.CheckReleaseFund(currentBlockHeight, reserveSequence); err != nil {
+		return nil, err
+	}
+	account.ReleaseFund(currentBlockHeight, reserveSequence)
+	evm.StateDB.SetAccount(callerAddr, account)
+
+	return common.Bytes{}, nil
+}
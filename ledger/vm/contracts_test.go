@@ -21,7 +21,12 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -481,3 +486,57 @@ func BenchmarkPrecompiledBn256Pairing(bench *testing.B) {
 		benchmarkPrecompiled("08", test, bench)
 	}
 }
+
+func TestPrecompiledThetaNative(t *testing.T) {
+	assert := assert.New(t)
+
+	store := state.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	userAddr := common.HexToAddress("0x1001")
+	contractAddr := common.HexToAddress("0x2002")
+
+	userAcc := types.NewAccount(userAddr)
+	userAcc.Balance = types.NewCoins(1000, 2000)
+	store.SetAccount(userAddr, userAcc)
+	contractAcc := types.NewAccount(contractAddr)
+	contractAcc.Balance = types.NewCoins(0, 10000)
+	store.SetAccount(contractAddr, contractAcc)
+	store.SetCode(contractAddr, common.Hex2Bytes("6080"))
+
+	evm := NewEVM(Context{}, store, nil, Config{})
+
+	// Theta and TFuel balances
+	ret, err := (&nativeBalances{}).Run(evm, userAddr.Bytes(), userAddr)
+	assert.Nil(err)
+	assert.Equal(64, len(ret))
+	assert.Equal(int64(1000), new(big.Int).SetBytes(ret[:32]).Int64())
+	assert.Equal(int64(2000), new(big.Int).SetBytes(ret[32:]).Int64())
+
+	// Empty validator set
+	ret, err = (&validatorSet{}).Run(evm, nil, userAddr)
+	assert.Nil(err)
+	assert.Equal(make([]byte, 32), ret)
+
+	// Only smart contracts can reserve funds
+	input := append(uint64To32Bytes(100), uint64To32Bytes(200)...)
+	input = append(input, uint64To32Bytes(types.MinimumFundReserveDuration)...)
+	input = append(input, []byte("rid001")...)
+	_, err = (&reserveFund{}).Run(evm, input, userAddr)
+	assert.Equal(errNotSmartContract, err)
+
+	ret, err = (&reserveFund{}).Run(evm, input, contractAddr)
+	assert.Nil(err)
+	assert.Equal(uint64To32Bytes(1), ret)
+	ret, err = (&reserveFund{}).Run(evm, input, contractAddr)
+	assert.Nil(err)
+	assert.Equal(uint64To32Bytes(2), ret)
+	assert.Equal(int64(10000-2*300), store.GetBalance(contractAddr).Int64())
+
+	ret, err = (&reservedFunds{}).Run(evm, contractAddr.Bytes(), contractAddr)
+	assert.Nil(err)
+	assert.Equal(32+2*5*32, len(ret))
+	assert.Equal(uint64To32Bytes(2), ret[:32])
+
+	// The reserved fund cannot be released before it expires
+	_, err = (&releaseFund{}).Run(evm, uint64To32Bytes(1), contractAddr)
+	assert.NotNil(err)
+}
@@ -29,4 +29,6 @@ var (
 	ErrNoCompatibleInterpreter  = errors.New("no compatible interpreter")
 	ErrInvalidGasLimit          = errors.New("invalid gas limit")
 	ErrInsufficientThetaBlance  = errors.New("insufficient Theta balance for transfer")
+	ErrWasmContract             = errors.New("wasm contract cannot be executed by the EVM")
+	ErrNotWasmContract          = errors.New("not a wasm contract")
 )
@@ -13,6 +13,13 @@ import (
 
 // Execute executes the given smart contract
 func Execute(parentBlock *core.Block, tx *types.SmartContractTx, storeView *state.StoreView) (evmRet common.Bytes,
+	contractAddr common.Address, gasUsed uint64, evmErr error) {
+	return ExecuteWithConfig(parentBlock, tx, storeView, Config{})
+}
+
+// ExecuteWithConfig executes the given smart contract with the given EVM configuration,
+// e.g. to attach a tracer to the execution
+func ExecuteWithConfig(parentBlock *core.Block, tx *types.SmartContractTx, storeView *state.StoreView, config Config) (evmRet common.Bytes,
 	contractAddr common.Address, gasUsed uint64, evmErr error) {
 	context := Context{
 		CanTransfer: CanTransfer,
@@ -28,7 +35,6 @@ func Execute(parentBlock *core.Block, tx *types.SmartContractTx, storeView *stat
 	chainConfig := &params.ChainConfig{
 		ChainID: chainIDBigInt,
 	}
-	config := Config{}
 	evm := NewEVM(context, storeView, chainConfig, config)
 
 	value := tx.From.Coins.TFuelWei
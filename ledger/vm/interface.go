@@ -20,6 +20,7 @@ import (
 	"math/big"
 
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/ledger/types"
 )
 
@@ -27,6 +28,7 @@ import (
 type StateDB interface {
 	CreateAccount(common.Address)
 	GetAccount(common.Address) *types.Account
+	SetAccount(common.Address, *types.Account)
 	CreateAccountWithPreviousBalance(addr common.Address)
 
 	SubBalance(common.Address, *big.Int)
@@ -38,6 +40,9 @@ type StateDB interface {
 	GetThetaBalance(common.Address) *big.Int // GetThetaBalance returns the ThetaWei balance of the given address
 	GetThetaStake(common.Address) *big.Int   // GetThetaStake returns the total amount of ThetaWei the address staked to validators and/or guardians
 
+	GetValidatorCandidatePool() *core.ValidatorCandidatePool
+	GetGuardianCandidatePool() *core.GuardianCandidatePool
+
 	GetNonce(common.Address) uint64
 	SetNonce(common.Address, uint64)
 
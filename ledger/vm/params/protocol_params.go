@@ -97,6 +97,15 @@ const (
 	ThetaBalanceGas  uint64 = 4     // Retrieve the Theta balance for an address
 	ThetaStakeGas    uint64 = 200   // Retrieve the total amount of staked Theta for an address
 	ThetaTransferGas uint64 = 21000 // Transfer Theta balance
+
+	NativeBalancesGas       uint64 = 400    // Retrieve the Theta and TFuel balances for an address
+	GuardianVotesBaseGas    uint64 = 100000 // Base price for verifying the guardian aggregated votes
+	GuardianVotesPerByteGas uint64 = 100    // Per-byte price for verifying the guardian aggregated votes
+	ValidatorSetGas         uint64 = 5000   // Retrieve the validator set
+	HolderStakeGas          uint64 = 800    // Retrieve the total stake of a validator candidate or guardian
+	ReservedFundsGas        uint64 = 800    // Retrieve the reserved funds of an address
+	ReserveFundGas          uint64 = 20000  // Reserve fund for service payments
+	ReleaseFundGas          uint64 = 10000  // Release a reserved fund
 )
 
 var (
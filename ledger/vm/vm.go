@@ -25,6 +25,7 @@ import (
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/ledger/vm/params"
+	"github.com/thetatoken/theta/ledger/vm/wasm"
 )
 
 type (
@@ -68,8 +69,10 @@ func getPrecompiledContracts(blockHeight uint64) map[common.Address]PrecompiledC
 	var precompiles map[common.Address]PrecompiledContract
 	if blockHeight < common.HeightSupportThetaTokenInSmartContract {
 		precompiles = PrecompiledContractsByzantium
-	} else {
+	} else if blockHeight < common.HeightEnableNativePrecompiles {
 		precompiles = PrecompiledContractsThetaSupport
+	} else {
+		precompiles = PrecompiledContractsThetaNative
 	}
 	return precompiles
 }
@@ -83,6 +86,9 @@ func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, err
 			return RunPrecompiledContract(evm, p, input, contract)
 		}
 	}
+	if wasm.IsWasmCode(contract.Code) && evm.StateDB.GetBlockHeight() >= common.HeightEnableWasmRuntime {
+		return nil, ErrWasmContract // WASM contracts are executed by the WASM runtime
+	}
 	for _, interpreter := range evm.interpreters {
 		if interpreter.CanRun(contract.Code) {
 			if evm.interpreter != interpreter {
@@ -248,6 +254,15 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	contract := NewContract(caller, to, value, gas)
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	// Capture the tracer start/end events in debug mode
+	if evm.vmConfig.Debug && evm.depth == 0 {
+		evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
+
+		defer func(startTime time.Time) { // Lazy evaluation of the parameters
+			evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(startTime), err)
+		}(time.Now())
+	}
+
 	ret, err = run(evm, contract, input, false)
 
 	// When an error was returned by the EVM or when setting the creation code
@@ -438,6 +453,11 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 
 	ret, err := run(evm, contract, nil, false)
 
+	// the EVM cannot deploy code which would be taken as a WASM contract
+	if err == nil && wasm.IsWasmCode(ret) && blockHeight >= common.HeightEnableWasmRuntime {
+		err = ErrWasmContract
+	}
+
 	// check whether the max code size has been exceeded
 	maxCodeSizeExceeded := len(ret) > params.MaxCodeSize
 	// if the contract creation ran successfully and no errors were returned
@@ -0,0 +1,32 @@
+/*
+Package wasm implements a WebAssembly (WASM) smart contract runtime which runs alongside the EVM.
+
+The runtime is a deterministic interpreter for the integer subset of the WebAssembly MVP, plus the
+sign extension operators and the memory.copy/memory.fill bulk memory operators. Floating point
+types and operators, tables, and imports other than the host functions are rejected when the
+module is decoded, so a contract cannot observe non-deterministic behavior.
+
+A WASM contract is a module with a linear memory which exports a "call" function of type [] -> [].
+The "call" function is invoked for each call to the contract. If the module also exports a "deploy"
+function of the same type, it is invoked once when the contract is deployed. The contract
+interacts with the ledger through the host functions imported from the "env" module:
+
+	input_size() -> i32                      size of the call data
+	input_copy(dst i32)                      copies the call data into memory
+	finish(ptr i32, len i32)                 stops the execution and returns the data
+	revert(ptr i32, len i32)                 stops the execution, reverts the state changes and returns the data
+	storage_read(key i32, dst i32)           reads a 32-byte value from the contract storage
+	storage_write(key i32, value i32)        writes a 32-byte value to the contract storage
+	caller(dst i32)                          20-byte address of the caller
+	address(dst i32)                         20-byte address of the contract
+	call_value(dst i32)                      32-byte big endian TFuelWei value sent with the call
+	balance(addr i32, dst i32)               32-byte big endian TFuelWei balance of an address
+	transfer(addr i32, value i32) -> i32     transfers TFuelWei from the contract, returns 0 on success
+	block_height() -> i64                    height of the block being executed
+	emit_log(topics i32, n i32, data i32, len i32)  emits a log with n 32-byte topics
+
+The contract storage and balances are the same as those of the EVM contracts, i.e. the storage
+lives in the storage trie of the contract account. Each executed instruction, memory page, and
+host function call consumes gas.
+*/
+package wasm
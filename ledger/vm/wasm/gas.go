@@ -0,0 +1,25 @@
+package wasm
+
+// Gas costs of the WASM runtime. The costs of the host functions which access the ledger state
+// are the same as the costs of the corresponding EVM instructions.
+const (
+	GasInstruction  uint64 = 1     // Once per executed instruction
+	GasMemoryPage   uint64 = 512   // Once per allocated 64KiB memory page
+	GasCopyWord     uint64 = 3     // Once per 32-byte word copied between the memory and the host
+	GasHostCall     uint64 = 20    // Once per host function call
+	GasStorageRead  uint64 = 800   // Same as SLOAD
+	GasStorageSet   uint64 = 20000 // Same as SSTORE from zero to non-zero
+	GasStorageReset uint64 = 5000  // Same as SSTORE otherwise
+	GasBalance      uint64 = 700   // Same as BALANCE
+	GasTransfer     uint64 = 9000  // Same as the value transfer of CALL
+	GasLog          uint64 = 375   // Same as LOG
+	GasLogTopic     uint64 = 375   // Per topic of LOG
+	GasLogData      uint64 = 8     // Per byte of the LOG data
+
+	// MaxLogTopics is the maximum number of topics of a log
+	MaxLogTopics = 4
+)
+
+func copyGas(size uint64) uint64 {
+	return (size + 31) / 32 * GasCopyWord
+}
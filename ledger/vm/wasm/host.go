@@ -0,0 +1,209 @@
+package wasm
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// HostModule is the name of the module the host functions are imported from
+const HostModule = "env"
+
+// Context is the context of a contract execution
+type Context struct {
+	Caller      [20]byte // Address of the caller
+	Address     [20]byte // Address of the contract
+	Value       *big.Int // TFuelWei sent with the call
+	BlockHeight uint64   // Height of the block being executed
+	Input       []byte   // Call data
+}
+
+// StateDB provides the ledger state to the host functions
+type StateDB interface {
+	// GetStorage returns the value stored under key in the contract storage
+	GetStorage(key [32]byte) [32]byte
+	// SetStorage stores value under key in the contract storage
+	SetStorage(key, value [32]byte)
+	// GetBalance returns the TFuelWei balance of the address
+	GetBalance(addr [20]byte) *big.Int
+	// Transfer transfers TFuelWei from the contract to the address, returns false if the
+	// contract does not have sufficient balance
+	Transfer(to [20]byte, amount *big.Int) bool
+	// AddLog adds a log emitted by the contract
+	AddLog(topics [][32]byte, data []byte)
+}
+
+type hostFunc struct {
+	params  []byte
+	results []byte
+	fn      func(inst *Instance, args []uint64) uint64
+}
+
+var (
+	i32 = valueTypeI32
+	i64 = valueTypeI64
+
+	hostFuncs = map[string]hostFunc{
+		"input_size":    {nil, []byte{i32}, hostInputSize},
+		"input_copy":    {[]byte{i32}, nil, hostInputCopy},
+		"finish":        {[]byte{i32, i32}, nil, hostFinish},
+		"revert":        {[]byte{i32, i32}, nil, hostRevert},
+		"storage_read":  {[]byte{i32, i32}, nil, hostStorageRead},
+		"storage_write": {[]byte{i32, i32}, nil, hostStorageWrite},
+		"caller":        {[]byte{i32}, nil, hostCaller},
+		"address":       {[]byte{i32}, nil, hostAddress},
+		"call_value":    {[]byte{i32}, nil, hostCallValue},
+		"balance":       {[]byte{i32, i32}, nil, hostBalance},
+		"transfer":      {[]byte{i32, i32}, []byte{i32}, hostTransfer},
+		"block_height":  {nil, []byte{i64}, hostBlockHeight},
+		"emit_log":      {[]byte{i32, i32, i32, i32}, nil, hostEmitLog},
+	}
+)
+
+// resolveImports resolves the imports of the module to the host functions, and checks their signatures
+func resolveImports(module *Module) ([]hostFunc, error) {
+	hosts := []hostFunc{}
+	for _, imp := range module.Imports {
+		host, ok := hostFuncs[imp.Name]
+		if imp.Module != HostModule || !ok {
+			return nil, fmt.Errorf("%v: unknown import %v.%v", ErrInvalidModule, imp.Module, imp.Name)
+		}
+		ft := FuncType{Params: host.params, Results: host.results}
+		if !ft.equals(&module.Types[imp.TypeIndex]) {
+			return nil, fmt.Errorf("%v: invalid signature of import %v.%v", ErrInvalidModule, imp.Module, imp.Name)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// ValidateContract checks that the module can be deployed as a contract
+func ValidateContract(module *Module) error {
+	if _, err := resolveImports(module); err != nil {
+		return err
+	}
+	if !module.HasMemory {
+		return fmt.Errorf("%v: contract must have a memory", ErrInvalidModule)
+	}
+	for _, name := range []string{"call", "deploy"} {
+		funcIndex, ok := module.ExportedFunction(name)
+		if !ok {
+			if name == "call" {
+				return fmt.Errorf("%v: contract must export the call function", ErrInvalidModule)
+			}
+			continue
+		}
+		ft := module.GetFuncType(funcIndex)
+		if len(ft.Params) != 0 || len(ft.Results) != 0 {
+			return fmt.Errorf("%v: exported function %v must be of type [] -> []", ErrInvalidModule, name)
+		}
+	}
+	return nil
+}
+
+func hostInputSize(inst *Instance, args []uint64) uint64 {
+	return uint64(len(inst.ctx.Input))
+}
+
+func hostInputCopy(inst *Instance, args []uint64) uint64 {
+	inst.writeMemory(uint32(args[0]), inst.ctx.Input)
+	return 0
+}
+
+func hostFinish(inst *Instance, args []uint64) uint64 {
+	panic(haltError{ret: inst.readMemory(uint32(args[0]), uint32(args[1]))})
+}
+
+func hostRevert(inst *Instance, args []uint64) uint64 {
+	panic(haltError{ret: inst.readMemory(uint32(args[0]), uint32(args[1])), err: ErrExecutionReverted})
+}
+
+func hostStorageRead(inst *Instance, args []uint64) uint64 {
+	inst.useGas(GasStorageRead)
+	var key [32]byte
+	copy(key[:], inst.readMemory(uint32(args[0]), 32))
+	value := inst.state.GetStorage(key)
+	inst.writeMemory(uint32(args[1]), value[:])
+	return 0
+}
+
+func hostStorageWrite(inst *Instance, args []uint64) uint64 {
+	var key, value [32]byte
+	copy(key[:], inst.readMemory(uint32(args[0]), 32))
+	copy(value[:], inst.readMemory(uint32(args[1]), 32))
+	if inst.state.GetStorage(key) == ([32]byte{}) && value != ([32]byte{}) {
+		inst.useGas(GasStorageSet)
+	} else {
+		inst.useGas(GasStorageReset)
+	}
+	inst.state.SetStorage(key, value)
+	return 0
+}
+
+func hostCaller(inst *Instance, args []uint64) uint64 {
+	inst.writeMemory(uint32(args[0]), inst.ctx.Caller[:])
+	return 0
+}
+
+func hostAddress(inst *Instance, args []uint64) uint64 {
+	inst.writeMemory(uint32(args[0]), inst.ctx.Address[:])
+	return 0
+}
+
+func hostCallValue(inst *Instance, args []uint64) uint64 {
+	value := inst.ctx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	inst.writeMemory(uint32(args[0]), toWord(value))
+	return 0
+}
+
+func hostBalance(inst *Instance, args []uint64) uint64 {
+	inst.useGas(GasBalance)
+	var addr [20]byte
+	copy(addr[:], inst.readMemory(uint32(args[0]), 20))
+	inst.writeMemory(uint32(args[1]), toWord(inst.state.GetBalance(addr)))
+	return 0
+}
+
+func hostTransfer(inst *Instance, args []uint64) uint64 {
+	inst.useGas(GasTransfer)
+	var addr [20]byte
+	copy(addr[:], inst.readMemory(uint32(args[0]), 20))
+	amount := new(big.Int).SetBytes(inst.readMemory(uint32(args[1]), 32))
+	if !inst.state.Transfer(addr, amount) {
+		return 1
+	}
+	return 0
+}
+
+func hostBlockHeight(inst *Instance, args []uint64) uint64 {
+	return inst.ctx.BlockHeight
+}
+
+func hostEmitLog(inst *Instance, args []uint64) uint64 {
+	numTopics := uint32(args[1])
+	if numTopics > MaxLogTopics {
+		inst.trap(fmt.Errorf("too many log topics: %v", numTopics))
+	}
+	dataLen := uint32(args[3])
+	inst.useGas(GasLog + uint64(numTopics)*GasLogTopic + uint64(dataLen)*GasLogData)
+	raw := inst.readMemory(uint32(args[0]), numTopics*32)
+	topics := make([][32]byte, numTopics)
+	for i := range topics {
+		copy(topics[i][:], raw[i*32:])
+	}
+	inst.state.AddLog(topics, inst.readMemory(uint32(args[2]), dataLen))
+	return 0
+}
+
+// toWord encodes a non-negative integer as a 32-byte big endian word, truncating the higher bits
+func toWord(v *big.Int) []byte {
+	word := make([]byte, 32)
+	b := v.Bytes()
+	if len(b) > 32 {
+		b = b[len(b)-32:]
+	}
+	copy(word[32-len(b):], b)
+	return word
+}
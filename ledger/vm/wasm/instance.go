@@ -0,0 +1,656 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+const (
+	maxCallDepth  = 256
+	maxStackSize  = 65536
+	maxLabelDepth = 1024
+)
+
+var (
+	ErrOutOfGas            = errors.New("out of gas")
+	ErrExecutionReverted   = errors.New("execution reverted")
+	ErrUnreachable         = errors.New("unreachable executed")
+	ErrMemoryOutOfBounds   = errors.New("memory access out of bounds")
+	ErrIntegerDivideByZero = errors.New("integer divide by zero")
+	ErrIntegerOverflow     = errors.New("integer overflow")
+	ErrStackOverflow       = errors.New("stack overflow")
+	ErrCallDepthExceeded   = errors.New("max call depth exceeded")
+	ErrInvalidStack        = errors.New("invalid operand stack")
+	ErrFunctionNotFound    = errors.New("exported function not found")
+)
+
+// haltError stops the execution of an instance. It carries a trap error, or the data returned
+// by the finish/revert host functions.
+type haltError struct {
+	ret []byte
+	err error
+}
+
+// label is the target of a branch
+type label struct {
+	pc      int  // Offset of the instruction to continue with after a branch
+	height  int  // Height of the operand stack when the control structure was entered
+	arity   int  // Number of values carried by a branch
+	results int  // Number of values left on the operand stack by the control structure
+	loop    bool // Whether a branch restarts the control structure
+}
+
+// Instance is an instantiated WASM module
+type Instance struct {
+	module  *Module
+	hosts   []hostFunc
+	ctx     *Context
+	state   StateDB
+	memory  []byte
+	globals []uint64
+	stack   []uint64
+	floor   int // Height of the operand stack below which the executing function cannot pop
+	gas     uint64
+	depth   int
+}
+
+// NewInstance instantiates the module with the given context and state, and runs its start
+// function if there is any
+func NewInstance(module *Module, ctx *Context, state StateDB, gas uint64) (*Instance, error) {
+	hosts, err := resolveImports(module)
+	if err != nil {
+		return nil, err
+	}
+	inst := &Instance{
+		module: module,
+		hosts:  hosts,
+		ctx:    ctx,
+		state:  state,
+		gas:    gas,
+	}
+	for _, global := range module.Globals {
+		inst.globals = append(inst.globals, global.Init)
+	}
+
+	_, err = inst.run(func() {
+		inst.useGas(uint64(module.MinPages) * GasMemoryPage)
+		inst.memory = make([]byte, int(module.MinPages)*PageSize)
+		for _, segment := range module.Data {
+			inst.useGas(copyGas(uint64(len(segment.Data))))
+			copy(inst.memoryRange(uint64(segment.Offset), uint64(len(segment.Data))), segment.Data)
+		}
+		if module.Start != nil {
+			inst.call(*module.Start)
+		}
+	})
+	if err != nil {
+		return inst, err
+	}
+	return inst, nil
+}
+
+// Invoke calls the exported function with the given name, which must be of type [] -> []
+func (inst *Instance) Invoke(name string) (ret []byte, err error) {
+	funcIndex, ok := inst.module.ExportedFunction(name)
+	if !ok {
+		return nil, fmt.Errorf("%v: %v", ErrFunctionNotFound, name)
+	}
+	ft := inst.module.GetFuncType(funcIndex)
+	if len(ft.Params) != 0 || len(ft.Results) != 0 {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidModule, "exported function "+name+" must be of type [] -> []")
+	}
+	return inst.run(func() {
+		inst.call(funcIndex)
+	})
+}
+
+// Gas returns the remaining gas
+func (inst *Instance) Gas() uint64 {
+	return inst.gas
+}
+
+// Memory returns the linear memory of the instance
+func (inst *Instance) Memory() []byte {
+	return inst.memory
+}
+
+// run executes f, and converts the halt of the execution to the returned data and error
+func (inst *Instance) run(f func()) (ret []byte, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			halt, ok := rec.(haltError)
+			if !ok {
+				panic(rec)
+			}
+			ret, err = halt.ret, halt.err
+		}
+		inst.stack = inst.stack[:0]
+		inst.floor = 0
+		inst.depth = 0
+	}()
+	f()
+	return nil, nil
+}
+
+func (inst *Instance) trap(err error) {
+	panic(haltError{err: err})
+}
+
+func (inst *Instance) useGas(gas uint64) {
+	if inst.gas < gas {
+		inst.gas = 0
+		inst.trap(ErrOutOfGas)
+	}
+	inst.gas -= gas
+}
+
+func (inst *Instance) push(v uint64) {
+	if len(inst.stack) >= maxStackSize {
+		inst.trap(ErrStackOverflow)
+	}
+	inst.stack = append(inst.stack, v)
+}
+
+func (inst *Instance) pop() uint64 {
+	n := len(inst.stack)
+	if n <= inst.floor {
+		inst.trap(ErrInvalidStack)
+	}
+	v := inst.stack[n-1]
+	inst.stack = inst.stack[:n-1]
+	return v
+}
+
+// memoryRange returns the slice of the memory at [offset, offset+size)
+func (inst *Instance) memoryRange(offset, size uint64) []byte {
+	if offset > uint64(len(inst.memory)) || size > uint64(len(inst.memory))-offset {
+		inst.trap(ErrMemoryOutOfBounds)
+	}
+	return inst.memory[offset : offset+size]
+}
+
+// readMemory copies size bytes at offset out of the memory, charging the copy gas
+func (inst *Instance) readMemory(offset, size uint32) []byte {
+	inst.useGas(copyGas(uint64(size)))
+	return append([]byte{}, inst.memoryRange(uint64(offset), uint64(size))...)
+}
+
+// writeMemory copies data into the memory at offset, charging the copy gas
+func (inst *Instance) writeMemory(offset uint32, data []byte) {
+	inst.useGas(copyGas(uint64(len(data))))
+	copy(inst.memoryRange(uint64(offset), uint64(len(data))), data)
+}
+
+func (inst *Instance) call(funcIndex uint32) {
+	module := inst.module
+	numImports := uint32(len(module.Imports))
+	ft := module.GetFuncType(funcIndex)
+	if funcIndex < numImports {
+		args := make([]uint64, len(ft.Params))
+		for i := len(args) - 1; i >= 0; i-- {
+			args[i] = inst.pop()
+		}
+		inst.useGas(GasHostCall)
+		result := inst.hosts[funcIndex].fn(inst, args)
+		if len(ft.Results) > 0 {
+			inst.push(result)
+		}
+		return
+	}
+
+	if inst.depth >= maxCallDepth {
+		inst.trap(ErrCallDepthExceeded)
+	}
+	inst.depth++
+	defer func() { inst.depth-- }()
+
+	fn := &module.Functions[funcIndex-numImports]
+	locals := make([]uint64, len(ft.Params)+len(fn.Locals))
+	for i := len(ft.Params) - 1; i >= 0; i-- {
+		locals[i] = inst.pop()
+	}
+	inst.execute(fn, locals, len(ft.Results))
+}
+
+// execute interprets the function body. The results are left on the operand stack.
+func (inst *Instance) execute(fn *Function, locals []uint64, numResults int) {
+	code := fn.Code
+	base := len(inst.stack)
+	callerFloor := inst.floor
+	inst.floor = base
+	defer func() { inst.floor = callerFloor }()
+	labels := []label{{pc: len(code), height: base, arity: numResults, results: numResults}}
+	pc := 0
+
+	// branch unwinds the operand stack to the target label and continues after it, returns
+	// true if the branch leaves the function
+	branch := func(depth uint32) bool {
+		target := labels[len(labels)-1-int(depth)]
+		inst.unwind(target.height, target.arity)
+		if int(depth) == len(labels)-1 {
+			return true
+		}
+		if target.loop {
+			labels = labels[:len(labels)-int(depth)]
+		} else {
+			labels = labels[:len(labels)-1-int(depth)]
+		}
+		pc = target.pc
+		return false
+	}
+	readU32 := func() uint32 {
+		v, _ := readULEB128(code, &pc, 32)
+		return uint32(v)
+	}
+	enter := func(start int, b *block) {
+		if len(labels) >= maxLabelDepth {
+			inst.trap(ErrStackOverflow)
+		}
+		l := label{pc: b.endPC + 1, height: len(inst.stack)}
+		if code[start+1] != blockTypeEmpty {
+			l.results = 1
+		}
+		l.arity = l.results
+		if code[start] == opLoop {
+			// branching to a loop restarts it without carrying any value
+			l.pc, l.arity, l.loop = start+2, 0, true
+		}
+		labels = append(labels, l)
+	}
+
+	for {
+		inst.useGas(GasInstruction)
+		start := pc
+		op := code[pc]
+		pc++
+		switch op {
+		case opUnreachable:
+			inst.trap(ErrUnreachable)
+		case opNop:
+		case opBlock, opLoop:
+			pc++
+			enter(start, fn.blocks[start])
+		case opIf:
+			pc++
+			b := fn.blocks[start]
+			if uint32(inst.pop()) != 0 {
+				enter(start, b)
+			} else if b.elsePC >= 0 {
+				enter(start, b)
+				pc = b.elsePC + 1
+			} else {
+				pc = b.endPC + 1
+			}
+		case opElse:
+			// end of the then branch, skip the else branch
+			if branch(0) {
+				return
+			}
+		case opEnd:
+			if len(labels) == 1 {
+				inst.unwind(base, numResults)
+				return
+			}
+			top := labels[len(labels)-1]
+			inst.unwind(top.height, top.results)
+			labels = labels[:len(labels)-1]
+		case opBr:
+			if branch(readU32()) {
+				return
+			}
+		case opBrIf:
+			depth := readU32()
+			if uint32(inst.pop()) != 0 {
+				if branch(depth) {
+					return
+				}
+			}
+		case opBrTable:
+			n := readU32()
+			index := uint32(inst.pop())
+			var depth uint32
+			for i := uint32(0); i <= n; i++ {
+				d := readU32()
+				if i == index || i == n {
+					depth = d
+					break
+				}
+			}
+			if branch(depth) {
+				return
+			}
+		case opReturn:
+			inst.unwind(base, numResults)
+			return
+		case opCall:
+			inst.call(readU32())
+
+		case opDrop:
+			inst.pop()
+		case opSelect:
+			c := uint32(inst.pop())
+			v2 := inst.pop()
+			v1 := inst.pop()
+			if c != 0 {
+				inst.push(v1)
+			} else {
+				inst.push(v2)
+			}
+
+		case opLocalGet:
+			inst.push(locals[readU32()])
+		case opLocalSet:
+			locals[readU32()] = inst.pop()
+		case opLocalTee:
+			v := inst.pop()
+			locals[readU32()] = v
+			inst.push(v)
+		case opGlobalGet:
+			inst.push(inst.globals[readU32()])
+		case opGlobalSet:
+			inst.globals[readU32()] = inst.pop()
+
+		case opMemorySize:
+			pc++
+			inst.push(uint64(len(inst.memory) / PageSize))
+		case opMemoryGrow:
+			pc++
+			delta := uint32(inst.pop())
+			pages := uint32(len(inst.memory) / PageSize)
+			if uint64(pages)+uint64(delta) > uint64(inst.module.MaxPages) {
+				inst.push(uint64(math.MaxUint32))
+				break
+			}
+			inst.useGas(uint64(delta) * GasMemoryPage)
+			inst.memory = append(inst.memory, make([]byte, int(delta)*PageSize)...)
+			inst.push(uint64(pages))
+
+		case opI32Const:
+			v, _ := readSLEB128(code, &pc, 32)
+			inst.push(uint64(uint32(v)))
+		case opI64Const:
+			v, _ := readSLEB128(code, &pc, 64)
+			inst.push(uint64(v))
+
+		case opMiscPrefix:
+			switch readU32() {
+			case opMemoryCopy:
+				pc += 2
+				n, src, dst := uint32(inst.pop()), uint32(inst.pop()), uint32(inst.pop())
+				inst.useGas(copyGas(uint64(n)))
+				copy(inst.memoryRange(uint64(dst), uint64(n)), inst.memoryRange(uint64(src), uint64(n)))
+			case opMemoryFill:
+				pc++
+				n, v, dst := uint32(inst.pop()), byte(inst.pop()), uint32(inst.pop())
+				inst.useGas(copyGas(uint64(n)))
+				mem := inst.memoryRange(uint64(dst), uint64(n))
+				for i := range mem {
+					mem[i] = v
+				}
+			}
+
+		default:
+			if op >= opI32Load && op <= opI64Store32 {
+				readU32() // alignment
+				offset := readU32()
+				inst.memoryOp(op, offset)
+			} else {
+				inst.numericOp(op)
+			}
+		}
+	}
+}
+
+// unwind keeps the top arity values of the operand stack, and drops the other values above height
+func (inst *Instance) unwind(height, arity int) {
+	n := len(inst.stack)
+	if n-height < arity {
+		inst.trap(ErrInvalidStack)
+	}
+	copy(inst.stack[height:], inst.stack[n-arity:])
+	inst.stack = inst.stack[:height+arity]
+}
+
+func (inst *Instance) memoryOp(op byte, offset uint32) {
+	var size uint64
+	switch op {
+	case opI32Load8S, opI32Load8U, opI64Load8S, opI64Load8U, opI32Store8, opI64Store8:
+		size = 1
+	case opI32Load16S, opI32Load16U, opI64Load16S, opI64Load16U, opI32Store16, opI64Store16:
+		size = 2
+	case opI32Load, opI64Load32S, opI64Load32U, opI32Store, opI64Store32:
+		size = 4
+	default:
+		size = 8
+	}
+
+	if op >= opI32Store {
+		v := inst.pop()
+		mem := inst.memoryRange(uint64(uint32(inst.pop()))+uint64(offset), size)
+		for i := range mem {
+			mem[i] = byte(v >> (8 * uint(i)))
+		}
+		return
+	}
+
+	mem := inst.memoryRange(uint64(uint32(inst.pop()))+uint64(offset), size)
+	var v uint64
+	for i := range mem {
+		v |= uint64(mem[i]) << (8 * uint(i))
+	}
+	switch op {
+	case opI32Load8S:
+		v = uint64(uint32(int32(int8(v))))
+	case opI32Load16S:
+		v = uint64(uint32(int32(int16(v))))
+	case opI64Load8S:
+		v = uint64(int64(int8(v)))
+	case opI64Load16S:
+		v = uint64(int64(int16(v)))
+	case opI64Load32S:
+		v = uint64(int64(int32(v)))
+	}
+	inst.push(v)
+}
+
+func b2u(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (inst *Instance) numericOp(op byte) {
+	switch op {
+	case opI32Eqz:
+		inst.push(b2u(uint32(inst.pop()) == 0))
+	case opI64Eqz:
+		inst.push(b2u(inst.pop() == 0))
+	case opI32Clz:
+		inst.push(uint64(bits.LeadingZeros32(uint32(inst.pop()))))
+	case opI32Ctz:
+		inst.push(uint64(bits.TrailingZeros32(uint32(inst.pop()))))
+	case opI32Popcnt:
+		inst.push(uint64(bits.OnesCount32(uint32(inst.pop()))))
+	case opI64Clz:
+		inst.push(uint64(bits.LeadingZeros64(inst.pop())))
+	case opI64Ctz:
+		inst.push(uint64(bits.TrailingZeros64(inst.pop())))
+	case opI64Popcnt:
+		inst.push(uint64(bits.OnesCount64(inst.pop())))
+	case opI32WrapI64:
+		inst.push(uint64(uint32(inst.pop())))
+	case opI64ExtendI32S:
+		inst.push(uint64(int64(int32(inst.pop()))))
+	case opI64ExtendI32U:
+		inst.push(uint64(uint32(inst.pop())))
+	case opI32Extend8S:
+		inst.push(uint64(uint32(int32(int8(inst.pop())))))
+	case opI32Extend16S:
+		inst.push(uint64(uint32(int32(int16(inst.pop())))))
+	case opI64Extend8S:
+		inst.push(uint64(int64(int8(inst.pop()))))
+	case opI64Extend16S:
+		inst.push(uint64(int64(int16(inst.pop()))))
+	case opI64Extend32S:
+		inst.push(uint64(int64(int32(inst.pop()))))
+	default:
+		b := inst.pop()
+		a := inst.pop()
+		if (op >= opI32Eq && op <= opI32GeU) || (op >= opI32Clz && op <= opI32Rotr) {
+			inst.push(uint64(i32BinaryOp(inst, op, uint32(a), uint32(b))))
+		} else {
+			inst.push(i64BinaryOp(inst, op, a, b))
+		}
+	}
+}
+
+func i32BinaryOp(inst *Instance, op byte, a, b uint32) uint32 {
+	switch op {
+	case opI32Eq:
+		return uint32(b2u(a == b))
+	case opI32Ne:
+		return uint32(b2u(a != b))
+	case opI32LtS:
+		return uint32(b2u(int32(a) < int32(b)))
+	case opI32LtU:
+		return uint32(b2u(a < b))
+	case opI32GtS:
+		return uint32(b2u(int32(a) > int32(b)))
+	case opI32GtU:
+		return uint32(b2u(a > b))
+	case opI32LeS:
+		return uint32(b2u(int32(a) <= int32(b)))
+	case opI32LeU:
+		return uint32(b2u(a <= b))
+	case opI32GeS:
+		return uint32(b2u(int32(a) >= int32(b)))
+	case opI32GeU:
+		return uint32(b2u(a >= b))
+	case opI32Add:
+		return a + b
+	case opI32Sub:
+		return a - b
+	case opI32Mul:
+		return a * b
+	case opI32DivS:
+		if b == 0 {
+			inst.trap(ErrIntegerDivideByZero)
+		}
+		if int32(a) == math.MinInt32 && int32(b) == -1 {
+			inst.trap(ErrIntegerOverflow)
+		}
+		return uint32(int32(a) / int32(b))
+	case opI32DivU:
+		if b == 0 {
+			inst.trap(ErrIntegerDivideByZero)
+		}
+		return a / b
+	case opI32RemS:
+		if b == 0 {
+			inst.trap(ErrIntegerDivideByZero)
+		}
+		if int32(b) == -1 {
+			return 0
+		}
+		return uint32(int32(a) % int32(b))
+	case opI32RemU:
+		if b == 0 {
+			inst.trap(ErrIntegerDivideByZero)
+		}
+		return a % b
+	case opI32And:
+		return a & b
+	case opI32Or:
+		return a | b
+	case opI32Xor:
+		return a ^ b
+	case opI32Shl:
+		return a << (b & 31)
+	case opI32ShrS:
+		return uint32(int32(a) >> (b & 31))
+	case opI32ShrU:
+		return a >> (b & 31)
+	case opI32Rotl:
+		return bits.RotateLeft32(a, int(b&31))
+	case opI32Rotr:
+		return bits.RotateLeft32(a, -int(b&31))
+	}
+	panic(fmt.Sprintf("unexpected i32 instruction 0x%x", op))
+}
+
+func i64BinaryOp(inst *Instance, op byte, a, b uint64) uint64 {
+	switch op {
+	case opI64Eq:
+		return b2u(a == b)
+	case opI64Ne:
+		return b2u(a != b)
+	case opI64LtS:
+		return b2u(int64(a) < int64(b))
+	case opI64LtU:
+		return b2u(a < b)
+	case opI64GtS:
+		return b2u(int64(a) > int64(b))
+	case opI64GtU:
+		return b2u(a > b)
+	case opI64LeS:
+		return b2u(int64(a) <= int64(b))
+	case opI64LeU:
+		return b2u(a <= b)
+	case opI64GeS:
+		return b2u(int64(a) >= int64(b))
+	case opI64GeU:
+		return b2u(a >= b)
+	case opI64Add:
+		return a + b
+	case opI64Sub:
+		return a - b
+	case opI64Mul:
+		return a * b
+	case opI64DivS:
+		if b == 0 {
+			inst.trap(ErrIntegerDivideByZero)
+		}
+		if int64(a) == math.MinInt64 && int64(b) == -1 {
+			inst.trap(ErrIntegerOverflow)
+		}
+		return uint64(int64(a) / int64(b))
+	case opI64DivU:
+		if b == 0 {
+			inst.trap(ErrIntegerDivideByZero)
+		}
+		return a / b
+	case opI64RemS:
+		if b == 0 {
+			inst.trap(ErrIntegerDivideByZero)
+		}
+		if int64(b) == -1 {
+			return 0
+		}
+		return uint64(int64(a) % int64(b))
+	case opI64RemU:
+		if b == 0 {
+			inst.trap(ErrIntegerDivideByZero)
+		}
+		return a % b
+	case opI64And:
+		return a & b
+	case opI64Or:
+		return a | b
+	case opI64Xor:
+		return a ^ b
+	case opI64Shl:
+		return a << (b & 63)
+	case opI64ShrS:
+		return uint64(int64(a) >> (b & 63))
+	case opI64ShrU:
+		return a >> (b & 63)
+	case opI64Rotl:
+		return bits.RotateLeft64(a, int(b&63))
+	case opI64Rotr:
+		return bits.RotateLeft64(a, -int(b&63))
+	}
+	panic(fmt.Sprintf("unexpected i64 instruction 0x%x", op))
+}
@@ -0,0 +1,384 @@
+package wasm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------- Test Utilities ----------------------------------
+
+func uleb(v uint64) []byte {
+	out := []byte{}
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			out = append(out, b|0x80)
+			continue
+		}
+		return append(out, b)
+	}
+}
+
+func sleb(v int64) []byte {
+	out := []byte{}
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func vec(items ...[]byte) []byte {
+	out := uleb(uint64(len(items)))
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+func name(s string) []byte {
+	return append(uleb(uint64(len(s))), s...)
+}
+
+func cat(parts ...[]byte) []byte {
+	out := []byte{}
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+func section(id byte, content []byte) []byte {
+	return cat([]byte{id}, uleb(uint64(len(content))), content)
+}
+
+func funcType(params, results []byte) []byte {
+	return cat([]byte{0x60}, uleb(uint64(len(params))), params, uleb(uint64(len(results))), results)
+}
+
+func i32Const(v int32) []byte {
+	return cat([]byte{opI32Const}, sleb(int64(v)))
+}
+
+func i64Const(v int64) []byte {
+	return cat([]byte{opI64Const}, sleb(v))
+}
+
+func localOp(op byte, idx uint32) []byte {
+	return cat([]byte{op}, uleb(uint64(idx)))
+}
+
+func callOp(idx uint32) []byte {
+	return cat([]byte{opCall}, uleb(uint64(idx)))
+}
+
+// testFunc is a function of the test module
+type testFunc struct {
+	params  []byte
+	results []byte
+	locals  []byte
+	body    []byte
+	export  string
+}
+
+type testImport struct {
+	name    string
+	params  []byte
+	results []byte
+}
+
+// buildModule assembles a module with a single page memory, the given imports and functions
+func buildModule(imports []testImport, funcs []testFunc, data []byte) []byte {
+	types := [][]byte{}
+	importEntries := [][]byte{}
+	for i, imp := range imports {
+		types = append(types, funcType(imp.params, imp.results))
+		importEntries = append(importEntries, cat(name(HostModule), name(imp.name), []byte{externalFunction}, uleb(uint64(i))))
+	}
+	funcEntries := [][]byte{}
+	codeEntries := [][]byte{}
+	exports := [][]byte{cat(name("memory"), []byte{externalMemory, 0x00})}
+	for i, fn := range funcs {
+		funcEntries = append(funcEntries, uleb(uint64(len(types))))
+		types = append(types, funcType(fn.params, fn.results))
+		localDecls := [][]byte{}
+		for _, l := range fn.locals {
+			localDecls = append(localDecls, []byte{0x01, l})
+		}
+		body := cat(vec(localDecls...), fn.body, []byte{opEnd})
+		codeEntries = append(codeEntries, cat(uleb(uint64(len(body))), body))
+		if fn.export != "" {
+			exports = append(exports, cat(name(fn.export), []byte{externalFunction}, uleb(uint64(len(imports)+i))))
+		}
+	}
+
+	module := cat(magic, version,
+		section(sectionType, vec(types...)),
+		section(sectionImport, vec(importEntries...)),
+		section(sectionFunction, vec(funcEntries...)),
+		section(sectionMemory, []byte{0x01, 0x01, 0x01, 0x01}),
+		section(sectionExport, vec(exports...)),
+		section(sectionCode, vec(codeEntries...)))
+	if data != nil {
+		module = append(module, section(sectionData, vec(cat([]byte{0x00}, i32Const(0), []byte{opEnd}, uleb(uint64(len(data))), data)))...)
+	}
+	return module
+}
+
+type testState struct {
+	contract [20]byte
+	storage  map[[32]byte][32]byte
+	balances map[[20]byte]*big.Int
+	logs     [][]byte
+}
+
+func newTestState() *testState {
+	return &testState{
+		contract: [20]byte{0xff},
+		storage:  make(map[[32]byte][32]byte),
+		balances: make(map[[20]byte]*big.Int),
+	}
+}
+
+func (s *testState) GetStorage(key [32]byte) [32]byte {
+	return s.storage[key]
+}
+
+func (s *testState) SetStorage(key, value [32]byte) {
+	s.storage[key] = value
+}
+
+func (s *testState) GetBalance(addr [20]byte) *big.Int {
+	if b, ok := s.balances[addr]; ok {
+		return b
+	}
+	return new(big.Int)
+}
+
+func (s *testState) Transfer(to [20]byte, amount *big.Int) bool {
+	if s.GetBalance(s.contract).Cmp(amount) < 0 {
+		return false
+	}
+	s.balances[s.contract] = new(big.Int).Sub(s.GetBalance(s.contract), amount)
+	s.balances[to] = new(big.Int).Add(s.GetBalance(to), amount)
+	return true
+}
+
+func (s *testState) AddLog(topics [][32]byte, data []byte) {
+	s.logs = append(s.logs, data)
+}
+
+func instantiate(t *testing.T, code []byte, ctx *Context, state StateDB, gas uint64) *Instance {
+	module, err := DecodeModule(code)
+	require.Nil(t, err)
+	inst, err := NewInstance(module, ctx, state, gas)
+	require.Nil(t, err)
+	return inst
+}
+
+var (
+	finishImport = testImport{"finish", []byte{i32, i32}, nil}
+	revertImport = testImport{"revert", []byte{i32, i32}, nil}
+)
+
+// finishI64 stores the i64 on top of the stack at address 0 and returns it with finish
+func finishI64() []byte {
+	return cat(localOp(opLocalSet, 0), i32Const(0), localOp(opLocalGet, 0), []byte{opI64Store, 0x03, 0x00},
+		i32Const(0), i32Const(8), callOp(0))
+}
+
+func decodeI64(ret []byte) int64 {
+	var v uint64
+	for i := 7; i >= 0; i-- {
+		v = v<<8 | uint64(ret[i])
+	}
+	return int64(v)
+}
+
+// ---------------------------------- Tests ----------------------------------
+
+func TestDecodeModule(t *testing.T) {
+	assert := assert.New(t)
+
+	code := buildModule([]testImport{finishImport}, []testFunc{
+		{body: nil, export: "call"},
+	}, []byte("hello"))
+	module, err := DecodeModule(code)
+	assert.Nil(err)
+	assert.True(IsWasmCode(code))
+	assert.Equal(1, len(module.Imports))
+	assert.Equal(1, len(module.Functions))
+	assert.True(module.HasMemory)
+	assert.Nil(ValidateContract(module))
+
+	// Floating point instructions are not supported
+	code = buildModule(nil, []testFunc{
+		{body: []byte{0x43, 0x00, 0x00, 0x00, 0x00, opDrop}, export: "call"},
+	}, nil)
+	_, err = DecodeModule(code)
+	assert.NotNil(err)
+
+	// Unknown host functions cannot be imported
+	code = buildModule([]testImport{{"random", nil, []byte{i32}}}, []testFunc{{export: "call"}}, nil)
+	module, err = DecodeModule(code)
+	assert.Nil(err)
+	assert.NotNil(ValidateContract(module))
+
+	// The call function is required
+	code = buildModule(nil, []testFunc{{export: "deploy"}}, nil)
+	module, err = DecodeModule(code)
+	assert.Nil(err)
+	assert.NotNil(ValidateContract(module))
+
+	_, err = DecodeModule([]byte{0x00, 0x61, 0x73, 0x6d, 0x01})
+	assert.NotNil(err)
+	assert.False(IsWasmCode([]byte{0x60, 0x80}))
+}
+
+func TestInstanceFactorial(t *testing.T) {
+	assert := assert.New(t)
+
+	// fac(n) = n <= 1 ? 1 : n * fac(n-1), computed recursively
+	fac := testFunc{
+		params:  []byte{i64},
+		results: []byte{i64},
+		body: cat(localOp(opLocalGet, 0), i64Const(1), []byte{opI64LeS, opIf, i64},
+			i64Const(1),
+			[]byte{opElse}, localOp(opLocalGet, 0), localOp(opLocalGet, 0), i64Const(1), []byte{opI64Sub}, callOp(2), []byte{opI64Mul},
+			[]byte{opEnd}),
+	}
+	// sum of 1..n computed with a loop
+	sum := testFunc{
+		params:  []byte{i64},
+		results: []byte{i64},
+		locals:  []byte{i64},
+		body: cat([]byte{opBlock, blockTypeEmpty, opLoop, blockTypeEmpty},
+			localOp(opLocalGet, 0), []byte{opI64Eqz}, []byte{opBrIf, 0x01},
+			localOp(opLocalGet, 1), localOp(opLocalGet, 0), []byte{opI64Add}, localOp(opLocalSet, 1),
+			localOp(opLocalGet, 0), i64Const(1), []byte{opI64Sub}, localOp(opLocalSet, 0),
+			[]byte{opBr, 0x00, opEnd, opEnd},
+			localOp(opLocalGet, 1)),
+	}
+	call := testFunc{
+		locals: []byte{i64},
+		body:   cat(i64Const(20), callOp(2), i64Const(100), callOp(3), []byte{opI64Add}, finishI64()),
+		export: "call",
+	}
+	code := buildModule([]testImport{finishImport, revertImport}, []testFunc{fac, sum, call}, nil)
+
+	inst := instantiate(t, code, &Context{}, newTestState(), 1000000)
+	ret, err := inst.Invoke("call")
+	assert.Nil(err)
+	assert.Equal(int64(2432902008176640000+5050), decodeI64(ret))
+	gasUsed := 1000000 - inst.Gas()
+	assert.True(gasUsed > 0)
+
+	// Running out of gas
+	inst = instantiate(t, code, &Context{}, newTestState(), 1000)
+	_, err = inst.Invoke("call")
+	assert.Equal(ErrOutOfGas, err)
+	assert.Equal(uint64(0), inst.Gas())
+}
+
+func TestInstanceTraps(t *testing.T) {
+	assert := assert.New(t)
+
+	code := buildModule([]testImport{finishImport}, []testFunc{
+		{body: cat(i32Const(1), i32Const(0), []byte{opI32DivU, opDrop}), export: "div"},
+		{body: cat(i32Const(65536), []byte{opI32Load, 0x02, 0x00, opDrop}), export: "load"},
+		{body: []byte{opUnreachable}, export: "unreachable"},
+		{body: callOp(4), export: "recurse"},
+		{body: []byte{opDrop}, export: "underflow"},
+		{body: cat(i32Const(1), []byte{opMemoryGrow, 0x00}, i32Const(-1), []byte{opI32Eq, opIf, blockTypeEmpty, opUnreachable, opEnd}), export: "grow"},
+	}, nil)
+
+	for fn, expected := range map[string]error{
+		"div":         ErrIntegerDivideByZero,
+		"load":        ErrMemoryOutOfBounds,
+		"unreachable": ErrUnreachable,
+		"recurse":     ErrCallDepthExceeded,
+		"underflow":   ErrInvalidStack,
+	} {
+		inst := instantiate(t, code, &Context{}, newTestState(), 1000000)
+		_, err := inst.Invoke(fn)
+		assert.Equal(expected, err, fn)
+	}
+
+	// The memory is limited to one page by the module
+	inst := instantiate(t, code, &Context{}, newTestState(), 1000000)
+	_, err := inst.Invoke("grow")
+	assert.Equal(ErrUnreachable, err)
+}
+
+func TestInstanceHostFunctions(t *testing.T) {
+	assert := assert.New(t)
+
+	imports := []testImport{
+		finishImport,
+		revertImport,
+		{"input_size", nil, []byte{i32}},
+		{"input_copy", []byte{i32}, nil},
+		{"storage_read", []byte{i32, i32}, nil},
+		{"storage_write", []byte{i32, i32}, nil},
+		{"block_height", nil, []byte{i64}},
+		{"transfer", []byte{i32, i32}, []byte{i32}},
+		{"emit_log", []byte{i32, i32, i32, i32}, nil},
+	}
+	// Stores the 32-byte input under the key at 0..32, reads it back to 64..96 and returns it
+	store := testFunc{
+		body: cat(i32Const(32), callOp(3),
+			i32Const(0), i32Const(32), callOp(5),
+			i32Const(0), i32Const(64), callOp(4),
+			i32Const(0), i32Const(0), i32Const(64), i32Const(32), callOp(8),
+			i32Const(64), callOp(2), callOp(0)),
+		export: "call",
+	}
+	// Transfers the amount at 32..64 to the address at 0..20, and reverts if the transfer fails
+	transfer := testFunc{
+		body: cat(i32Const(32), callOp(3),
+			i32Const(0), i32Const(32), callOp(7),
+			[]byte{opIf, blockTypeEmpty}, i32Const(0), i32Const(0), callOp(1), []byte{opEnd}),
+		export: "transfer",
+	}
+	height := testFunc{
+		locals: []byte{i64},
+		body:   cat(callOp(6), finishI64()),
+		export: "height",
+	}
+	code := buildModule(imports, []testFunc{store, transfer, height}, nil)
+
+	input := make([]byte, 32)
+	input[31] = 0x2a
+	state := newTestState()
+	inst := instantiate(t, code, &Context{Input: input, BlockHeight: 123}, state, 100000)
+	ret, err := inst.Invoke("call")
+	assert.Nil(err)
+	assert.Equal(input, ret)
+	var key, value [32]byte
+	value[31] = 0x2a
+	assert.Equal(value, state.storage[key])
+	assert.Equal([][]byte{input}, state.logs)
+	assert.True(100000-inst.Gas() > GasStorageSet)
+
+	ret, err = inst.Invoke("height")
+	assert.Nil(err)
+	assert.Equal(int64(123), decodeI64(ret))
+
+	// The contract has no balance, so the transfer fails and the call reverts
+	inst = instantiate(t, code, &Context{Input: input}, state, 100000)
+	_, err = inst.Invoke("transfer")
+	assert.Equal(ErrExecutionReverted, err)
+
+	state.balances[state.contract] = big.NewInt(100)
+	_, err = inst.Invoke("transfer")
+	assert.Nil(err)
+	assert.Equal(big.NewInt(100-0x2a), state.balances[state.contract])
+	assert.Equal(big.NewInt(0x2a), state.balances[[20]byte{}])
+}
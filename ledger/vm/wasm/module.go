@@ -0,0 +1,611 @@
+package wasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	// PageSize is the size of a WASM linear memory page
+	PageSize = 65536
+
+	// MaxMemoryPages is the maximum number of linear memory pages of a contract
+	MaxMemoryPages uint32 = 64
+
+	maxFunctionLocals = 1024
+	maxBrTableSize    = 1024
+
+	valueTypeI32 byte = 0x7f
+	valueTypeI64 byte = 0x7e
+
+	blockTypeEmpty byte = 0x40
+
+	externalFunction byte = 0x00
+	externalMemory   byte = 0x02
+	externalGlobal   byte = 0x03
+)
+
+const (
+	sectionCustom   byte = 0
+	sectionType     byte = 1
+	sectionImport   byte = 2
+	sectionFunction byte = 3
+	sectionTable    byte = 4
+	sectionMemory   byte = 5
+	sectionGlobal   byte = 6
+	sectionExport   byte = 7
+	sectionStart    byte = 8
+	sectionElement  byte = 9
+	sectionCode     byte = 10
+	sectionData     byte = 11
+	sectionDataCnt  byte = 12
+)
+
+var (
+	magic   = []byte{0x00, 0x61, 0x73, 0x6d} // "\0asm"
+	version = []byte{0x01, 0x00, 0x00, 0x00}
+
+	// ErrInvalidModule is returned when the code is not a valid WASM module supported by the runtime
+	ErrInvalidModule = errors.New("invalid wasm module")
+
+	errUnexpectedEnd = errors.New("unexpected end of module")
+)
+
+// IsWasmCode returns whether the code is a WASM module
+func IsWasmCode(code []byte) bool {
+	return len(code) >= len(magic) && bytes.Equal(code[:len(magic)], magic)
+}
+
+// FuncType is the signature of a function
+type FuncType struct {
+	Params  []byte
+	Results []byte
+}
+
+func (ft *FuncType) equals(other *FuncType) bool {
+	return bytes.Equal(ft.Params, other.Params) && bytes.Equal(ft.Results, other.Results)
+}
+
+// Import is a function imported from the host
+type Import struct {
+	Module    string
+	Name      string
+	TypeIndex uint32
+}
+
+// Function is a function defined in the module
+type Function struct {
+	TypeIndex uint32
+	Locals    []byte // Types of the declared locals, excluding the parameters
+	Code      []byte // Instructions of the function body, including the final end
+
+	blocks map[int]*block // Control structures keyed by the offset of their block/loop/if instruction
+}
+
+// block records the positions of the else and end instructions of a control structure
+type block struct {
+	elsePC int // Offset of the else instruction, -1 if there is none
+	endPC  int // Offset of the end instruction
+}
+
+// Global is a global variable
+type Global struct {
+	Type    byte
+	Mutable bool
+	Init    uint64
+}
+
+// Export is an exported function, memory or global
+type Export struct {
+	Kind  byte
+	Index uint32
+}
+
+// DataSegment initializes a range of the linear memory
+type DataSegment struct {
+	Offset uint32
+	Data   []byte
+}
+
+// Module is a decoded WASM module
+type Module struct {
+	Types     []FuncType
+	Imports   []Import
+	Functions []Function
+	HasMemory bool
+	MinPages  uint32
+	MaxPages  uint32
+	Globals   []Global
+	Exports   map[string]Export
+	Start     *uint32
+	Data      []DataSegment
+}
+
+// NumFunctions returns the number of functions in the function index space, including the imports
+func (m *Module) NumFunctions() uint32 {
+	return uint32(len(m.Imports) + len(m.Functions))
+}
+
+// GetFuncType returns the signature of the function with the given index
+func (m *Module) GetFuncType(funcIndex uint32) *FuncType {
+	if funcIndex < uint32(len(m.Imports)) {
+		return &m.Types[m.Imports[funcIndex].TypeIndex]
+	}
+	return &m.Types[m.Functions[funcIndex-uint32(len(m.Imports))].TypeIndex]
+}
+
+// ExportedFunction returns the index of the exported function with the given name
+func (m *Module) ExportedFunction(name string) (uint32, bool) {
+	export, ok := m.Exports[name]
+	if !ok || export.Kind != externalFunction {
+		return 0, false
+	}
+	return export.Index, true
+}
+
+// DecodeModule decodes and validates a WASM module in the binary format
+func DecodeModule(code []byte) (module *Module, err error) {
+	r := &reader{data: code}
+	defer func() {
+		if rec := recover(); rec != nil {
+			if decodeErr, ok := rec.(decodeError); ok {
+				module, err = nil, fmt.Errorf("%v: %v", ErrInvalidModule, decodeErr.err)
+				return
+			}
+			panic(rec)
+		}
+	}()
+
+	if !bytes.Equal(r.readBytes(4), magic) {
+		r.fail("invalid magic number")
+	}
+	if !bytes.Equal(r.readBytes(4), version) {
+		r.fail("unsupported version")
+	}
+
+	module = &Module{Exports: make(map[string]Export)}
+	var funcTypeIndices []uint32
+	lastSection := byte(0)
+	for !r.eof() {
+		id := r.readByte()
+		size := r.readU32()
+		sr := &reader{data: r.readBytes(int(size))}
+		if id != sectionCustom {
+			if id <= lastSection || id == sectionDataCnt {
+				if id != sectionDataCnt || lastSection >= sectionCode {
+					r.fail("unexpected section %v", id)
+				}
+			}
+			lastSection = id
+		}
+
+		switch id {
+		case sectionCustom:
+			// Custom sections, e.g. names, do not affect the execution
+		case sectionType:
+			for n := sr.readU32(); n > 0; n-- {
+				if sr.readByte() != 0x60 {
+					sr.fail("invalid function type")
+				}
+				ft := FuncType{Params: sr.readValueTypes(), Results: sr.readValueTypes()}
+				if len(ft.Results) > 1 {
+					sr.fail("multiple return values are not supported")
+				}
+				module.Types = append(module.Types, ft)
+			}
+		case sectionImport:
+			for n := sr.readU32(); n > 0; n-- {
+				imp := Import{Module: sr.readName(), Name: sr.readName()}
+				if sr.readByte() != externalFunction {
+					sr.fail("only function imports are supported: %v.%v", imp.Module, imp.Name)
+				}
+				imp.TypeIndex = sr.readU32()
+				if imp.TypeIndex >= uint32(len(module.Types)) {
+					sr.fail("invalid type index %v", imp.TypeIndex)
+				}
+				module.Imports = append(module.Imports, imp)
+			}
+		case sectionFunction:
+			for n := sr.readU32(); n > 0; n-- {
+				typeIndex := sr.readU32()
+				if typeIndex >= uint32(len(module.Types)) {
+					sr.fail("invalid type index %v", typeIndex)
+				}
+				funcTypeIndices = append(funcTypeIndices, typeIndex)
+			}
+		case sectionTable, sectionElement:
+			sr.fail("tables are not supported")
+		case sectionMemory:
+			n := sr.readU32()
+			if n > 1 {
+				sr.fail("at most one memory is allowed")
+			}
+			if n == 1 {
+				module.HasMemory = true
+				module.MinPages, module.MaxPages = sr.readLimits()
+			}
+		case sectionGlobal:
+			for n := sr.readU32(); n > 0; n-- {
+				global := Global{Type: sr.readValueType()}
+				switch sr.readByte() {
+				case 0x00:
+				case 0x01:
+					global.Mutable = true
+				default:
+					sr.fail("invalid global mutability")
+				}
+				global.Init = sr.readConstExpr(global.Type)
+				module.Globals = append(module.Globals, global)
+			}
+		case sectionExport:
+			for n := sr.readU32(); n > 0; n-- {
+				name := sr.readName()
+				if _, ok := module.Exports[name]; ok {
+					sr.fail("duplicated export %v", name)
+				}
+				export := Export{Kind: sr.readByte(), Index: sr.readU32()}
+				module.Exports[name] = export
+			}
+		case sectionStart:
+			start := sr.readU32()
+			module.Start = &start
+		case sectionCode:
+			n := sr.readU32()
+			if n != uint32(len(funcTypeIndices)) {
+				sr.fail("function and code section sizes do not match")
+			}
+			for i := uint32(0); i < n; i++ {
+				body := &reader{data: sr.readBytes(int(sr.readU32()))}
+				fn := Function{TypeIndex: funcTypeIndices[i]}
+				for numDecls := body.readU32(); numDecls > 0; numDecls-- {
+					count := body.readU32()
+					valueType := body.readValueType()
+					if uint64(len(fn.Locals))+uint64(count) > maxFunctionLocals {
+						body.fail("too many locals")
+					}
+					for j := uint32(0); j < count; j++ {
+						fn.Locals = append(fn.Locals, valueType)
+					}
+				}
+				fn.Code = body.data[body.pos:]
+				module.Functions = append(module.Functions, fn)
+			}
+		case sectionData:
+			for n := sr.readU32(); n > 0; n-- {
+				if sr.readU32() != 0 {
+					sr.fail("only active data segments of memory 0 are supported")
+				}
+				offset := uint32(sr.readConstExpr(valueTypeI32))
+				size := sr.readU32()
+				module.Data = append(module.Data, DataSegment{Offset: offset, Data: sr.readBytes(int(size))})
+			}
+		case sectionDataCnt:
+			sr.readU32()
+		default:
+			r.fail("unknown section %v", id)
+		}
+		if !sr.eof() {
+			r.fail("section %v size mismatch", id)
+		}
+	}
+
+	if len(funcTypeIndices) != len(module.Functions) {
+		r.fail("function and code section sizes do not match")
+	}
+	if len(module.Data) > 0 && !module.HasMemory {
+		r.fail("data segments require a memory")
+	}
+	for name, export := range module.Exports {
+		switch export.Kind {
+		case externalFunction:
+			if export.Index >= module.NumFunctions() {
+				r.fail("invalid exported function %v", name)
+			}
+		case externalMemory:
+			if export.Index != 0 || !module.HasMemory {
+				r.fail("invalid exported memory %v", name)
+			}
+		case externalGlobal:
+			if export.Index >= uint32(len(module.Globals)) {
+				r.fail("invalid exported global %v", name)
+			}
+		default:
+			r.fail("unsupported export kind of %v", name)
+		}
+	}
+	if module.Start != nil {
+		if *module.Start >= module.NumFunctions() {
+			r.fail("invalid start function")
+		}
+		ft := module.GetFuncType(*module.Start)
+		if len(ft.Params) != 0 || len(ft.Results) != 0 {
+			r.fail("invalid start function type")
+		}
+	}
+	for i := range module.Functions {
+		analyzeFunction(module, &module.Functions[i])
+	}
+
+	return module, nil
+}
+
+// analyzeFunction checks that the function body only contains the supported instructions with valid
+// immediates, and records the positions of the control structures for the interpreter
+func analyzeFunction(module *Module, fn *Function) {
+	ft := &module.Types[fn.TypeIndex]
+	numLocals := uint32(len(ft.Params) + len(fn.Locals))
+	fn.blocks = make(map[int]*block)
+
+	r := &reader{data: fn.Code}
+	var openBlocks []int // offsets of the block/loop/if instructions which are not closed yet
+	for !r.eof() {
+		pc := r.pos
+		op := r.readByte()
+		switch {
+		case op == opBlock || op == opLoop || op == opIf:
+			blockType := r.readByte()
+			if blockType != blockTypeEmpty && blockType != valueTypeI32 && blockType != valueTypeI64 {
+				r.fail("unsupported block type 0x%x", blockType)
+			}
+			fn.blocks[pc] = &block{elsePC: -1}
+			openBlocks = append(openBlocks, pc)
+		case op == opElse:
+			if len(openBlocks) == 0 || fn.Code[openBlocks[len(openBlocks)-1]] != opIf {
+				r.fail("else without if")
+			}
+			b := fn.blocks[openBlocks[len(openBlocks)-1]]
+			if b.elsePC >= 0 {
+				r.fail("duplicated else")
+			}
+			b.elsePC = pc
+		case op == opEnd:
+			if len(openBlocks) == 0 {
+				if !r.eof() {
+					r.fail("instructions after the end of the function")
+				}
+				return
+			}
+			start := openBlocks[len(openBlocks)-1]
+			openBlocks = openBlocks[:len(openBlocks)-1]
+			b := fn.blocks[start]
+			b.endPC = pc
+			if fn.Code[start] == opIf && b.elsePC < 0 && fn.Code[start+1] != blockTypeEmpty {
+				r.fail("if with a result requires an else")
+			}
+		case op == opBr || op == opBrIf:
+			if r.readU32() > uint32(len(openBlocks)) {
+				r.fail("invalid branch depth")
+			}
+		case op == opBrTable:
+			n := r.readU32()
+			if n > maxBrTableSize {
+				r.fail("br_table too large")
+			}
+			for i := uint32(0); i <= n; i++ {
+				if r.readU32() > uint32(len(openBlocks)) {
+					r.fail("invalid branch depth")
+				}
+			}
+		case op == opCall:
+			if r.readU32() >= module.NumFunctions() {
+				r.fail("invalid function index")
+			}
+		case op == opLocalGet || op == opLocalSet || op == opLocalTee:
+			if r.readU32() >= numLocals {
+				r.fail("invalid local index")
+			}
+		case op == opGlobalGet || op == opGlobalSet:
+			idx := r.readU32()
+			if idx >= uint32(len(module.Globals)) {
+				r.fail("invalid global index")
+			}
+			if op == opGlobalSet && !module.Globals[idx].Mutable {
+				r.fail("global %v is immutable", idx)
+			}
+		case op >= opI32Load && op <= opI64Store32:
+			if !isSupportedMemoryOp(op) {
+				r.fail("unsupported instruction 0x%x", op)
+			}
+			if !module.HasMemory {
+				r.fail("memory instruction without a memory")
+			}
+			r.readU32() // alignment
+			r.readU32() // offset
+		case op == opMemorySize || op == opMemoryGrow:
+			if !module.HasMemory || r.readByte() != 0x00 {
+				r.fail("invalid memory index")
+			}
+		case op == opI32Const:
+			r.readS32()
+		case op == opI64Const:
+			r.readS64()
+		case op == opMiscPrefix:
+			switch r.readU32() {
+			case opMemoryCopy:
+				if !module.HasMemory || r.readByte() != 0x00 || r.readByte() != 0x00 {
+					r.fail("invalid memory index")
+				}
+			case opMemoryFill:
+				if !module.HasMemory || r.readByte() != 0x00 {
+					r.fail("invalid memory index")
+				}
+			default:
+				r.fail("unsupported instruction 0x%x", op)
+			}
+		case isSupportedSimpleOp(op):
+		default:
+			r.fail("unsupported instruction 0x%x", op)
+		}
+	}
+	r.fail("missing end of function")
+}
+
+// ---------------------------------- Binary Reader ----------------------------------
+
+type decodeError struct {
+	err error
+}
+
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) fail(format string, args ...interface{}) {
+	panic(decodeError{fmt.Errorf(format, args...)})
+}
+
+func (r *reader) eof() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *reader) readByte() byte {
+	if r.eof() {
+		panic(decodeError{errUnexpectedEnd})
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) readBytes(n int) []byte {
+	if n < 0 || n > len(r.data)-r.pos {
+		panic(decodeError{errUnexpectedEnd})
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) readU32() uint32 {
+	v, err := readULEB128(r.data, &r.pos, 32)
+	if err != nil {
+		panic(decodeError{err})
+	}
+	return uint32(v)
+}
+
+func (r *reader) readS32() int32 {
+	v, err := readSLEB128(r.data, &r.pos, 32)
+	if err != nil {
+		panic(decodeError{err})
+	}
+	return int32(v)
+}
+
+func (r *reader) readS64() int64 {
+	v, err := readSLEB128(r.data, &r.pos, 64)
+	if err != nil {
+		panic(decodeError{err})
+	}
+	return v
+}
+
+func (r *reader) readName() string {
+	return string(r.readBytes(int(r.readU32())))
+}
+
+func (r *reader) readValueType() byte {
+	t := r.readByte()
+	if t != valueTypeI32 && t != valueTypeI64 {
+		r.fail("unsupported value type 0x%x", t)
+	}
+	return t
+}
+
+func (r *reader) readValueTypes() []byte {
+	n := r.readU32()
+	types := []byte{}
+	for i := uint32(0); i < n; i++ {
+		types = append(types, r.readValueType())
+	}
+	return types
+}
+
+func (r *reader) readLimits() (min, max uint32) {
+	switch r.readByte() {
+	case 0x00:
+		min, max = r.readU32(), MaxMemoryPages
+	case 0x01:
+		min, max = r.readU32(), r.readU32()
+		if max < min {
+			r.fail("invalid memory limits")
+		}
+	default:
+		r.fail("invalid memory limits")
+	}
+	if min > MaxMemoryPages {
+		r.fail("memory exceeds %v pages", MaxMemoryPages)
+	}
+	if max > MaxMemoryPages {
+		max = MaxMemoryPages
+	}
+	return min, max
+}
+
+// readConstExpr reads a constant initializer expression, only i32.const and i64.const are supported
+func (r *reader) readConstExpr(valueType byte) uint64 {
+	var v uint64
+	switch op := r.readByte(); {
+	case op == opI32Const && valueType == valueTypeI32:
+		v = uint64(uint32(r.readS32()))
+	case op == opI64Const && valueType == valueTypeI64:
+		v = uint64(r.readS64())
+	default:
+		r.fail("unsupported constant expression")
+	}
+	if r.readByte() != opEnd {
+		r.fail("unsupported constant expression")
+	}
+	return v
+}
+
+func readULEB128(data []byte, pos *int, bits uint) (uint64, error) {
+	var result uint64
+	var shift uint
+	for {
+		if *pos >= len(data) {
+			return 0, errUnexpectedEnd
+		}
+		b := data[*pos]
+		*pos++
+		if shift >= bits || (shift+7 > bits && uint64(b&0x7f)>>(bits-shift) != 0) {
+			return 0, errors.New("integer too large")
+		}
+		result |= uint64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			return result, nil
+		}
+	}
+}
+
+func readSLEB128(data []byte, pos *int, bits uint) (int64, error) {
+	var result int64
+	var shift uint
+	var b byte
+	for {
+		if *pos >= len(data) {
+			return 0, errUnexpectedEnd
+		}
+		if shift >= bits {
+			return 0, errors.New("integer too large")
+		}
+		b = data[*pos]
+		*pos++
+		result |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if shift < 64 && b&0x40 != 0 {
+		result |= -1 << shift
+	}
+	if bits < 64 && (result < math.MinInt32 || result > math.MaxInt32) {
+		return 0, errors.New("integer too large")
+	}
+	return result, nil
+}
@@ -0,0 +1,205 @@
+package vm
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm/params"
+	"github.com/thetatoken/theta/ledger/vm/wasm"
+)
+
+// ExecuteWasm executes the given smart contract with the WASM runtime. The WASM contracts share the
+// account, balance and storage model with the EVM contracts, and are charged the same intrinsic gas.
+func ExecuteWasm(parentBlock *core.Block, tx *types.SmartContractTx, storeView *state.StoreView) (ret common.Bytes,
+	contractAddr common.Address, gasUsed uint64, err error) {
+	value := tx.From.Coins.TFuelWei
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	gasLimit := tx.GasLimit
+	fromAddr := tx.From.Address
+	contractAddr = tx.To.Address
+	createContract := (contractAddr == common.Address{})
+
+	blockHeight := storeView.Height() + 1
+	maxGasLimit := types.GetMaxGasLimit(blockHeight)
+	if new(big.Int).SetUint64(gasLimit).Cmp(maxGasLimit) > 0 {
+		return common.Bytes{}, common.Address{}, 0, ErrInvalidGasLimit
+	}
+
+	intrinsicGas, err := calculateIntrinsicGas(tx.Data, createContract)
+	if err != nil {
+		return common.Bytes{}, common.Address{}, 0, err
+	}
+	if intrinsicGas > gasLimit {
+		return common.Bytes{}, common.Address{}, 0, ErrOutOfGas
+	}
+
+	ctx := &wasm.Context{
+		Caller:      fromAddr,
+		Value:       value,
+		BlockHeight: parentBlock.Height + 1,
+	}
+
+	var leftOverGas uint64
+	remainingGas := gasLimit - intrinsicGas
+	if createContract {
+		ret, contractAddr, leftOverGas, err = createWasm(storeView, ctx, tx.Data, remainingGas)
+	} else {
+		ctx.Address = contractAddr
+		ctx.Input = tx.Data
+		ret, leftOverGas, err = callWasm(storeView, ctx, remainingGas)
+	}
+
+	if leftOverGas > gasLimit { // should not happen
+		gasUsed = uint64(0)
+	} else {
+		gasUsed = gasLimit - leftOverGas
+	}
+
+	return ret, contractAddr, gasUsed, err
+}
+
+// createWasm deploys the WASM module as a contract, and runs its deploy function if exported
+func createWasm(storeView *state.StoreView, ctx *wasm.Context, code []byte, gas uint64) ([]byte, common.Address, uint64, error) {
+	caller := common.Address(ctx.Caller)
+	if !CanTransfer(storeView, caller, ctx.Value) {
+		return nil, common.Address{}, gas, ErrInsufficientBalance
+	}
+	nonce := storeView.GetNonce(caller)
+	storeView.SetNonce(caller, nonce+1)
+
+	// Ensure there's no existing contract already at the designated address
+	address := crypto.CreateAddress(caller, nonce)
+	contractHash := storeView.GetCodeHash(address)
+	if storeView.GetNonce(address) != 0 || (contractHash != (common.Hash{}) && contractHash != types.EmptyCodeHash) {
+		return nil, common.Address{}, 0, ErrContractAddressCollision
+	}
+
+	if len(code) > params.MaxCodeSize {
+		return nil, common.Address{}, 0, errMaxCodeSizeExceeded
+	}
+	module, err := wasm.DecodeModule(code)
+	if err == nil {
+		err = wasm.ValidateContract(module)
+	}
+	if err != nil {
+		return nil, common.Address{}, 0, err
+	}
+
+	snapshot := storeView.Snapshot()
+
+	// should not wipe out the Theta/TFuel balance sent to the contract address prior to contract creation
+	storeView.CreateAccountWithPreviousBalance(address)
+	Transfer(storeView, caller, address, ctx.Value)
+
+	ctx.Address = address
+	var ret []byte
+	leftOverGas := gas
+	if _, ok := module.ExportedFunction("deploy"); ok {
+		ret, leftOverGas, err = invokeWasm(storeView, module, ctx, "deploy", gas)
+	}
+	if err == nil {
+		createDataGas := uint64(len(code)) * params.CreateDataGas
+		if leftOverGas >= createDataGas {
+			leftOverGas -= createDataGas
+			storeView.SetCode(address, code)
+		} else {
+			err = ErrCodeStoreOutOfGas
+		}
+	}
+
+	if err != nil {
+		storeView.RevertToSnapshot(snapshot)
+		if err != wasm.ErrExecutionReverted {
+			leftOverGas = 0
+		}
+	}
+	return ret, address, leftOverGas, err
+}
+
+// callWasm calls the WASM contract at the context address
+func callWasm(storeView *state.StoreView, ctx *wasm.Context, gas uint64) ([]byte, uint64, error) {
+	caller, address := common.Address(ctx.Caller), common.Address(ctx.Address)
+	if !CanTransfer(storeView, caller, ctx.Value) {
+		return nil, gas, ErrInsufficientBalance
+	}
+	code := storeView.GetCode(address)
+	if !wasm.IsWasmCode(code) {
+		return nil, gas, ErrNotWasmContract
+	}
+	module, err := wasm.DecodeModule(code)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	snapshot := storeView.Snapshot()
+	Transfer(storeView, caller, address, ctx.Value)
+
+	ret, leftOverGas, err := invokeWasm(storeView, module, ctx, "call", gas)
+	if err != nil {
+		storeView.RevertToSnapshot(snapshot)
+		if err != wasm.ErrExecutionReverted {
+			leftOverGas = 0
+		}
+	}
+	return ret, leftOverGas, err
+}
+
+func invokeWasm(storeView *state.StoreView, module *wasm.Module, ctx *wasm.Context, function string, gas uint64) ([]byte, uint64, error) {
+	inst, err := wasm.NewInstance(module, ctx, &wasmStateDB{storeView: storeView, contract: ctx.Address}, gas)
+	if inst == nil {
+		return nil, 0, err
+	}
+	if err != nil {
+		return nil, inst.Gas(), err
+	}
+	ret, err := inst.Invoke(function)
+	return ret, inst.Gas(), err
+}
+
+// wasmStateDB exposes the ledger state to the WASM runtime, the storage of a WASM contract is
+// stored in the same way as the storage of an EVM contract
+type wasmStateDB struct {
+	storeView *state.StoreView
+	contract  common.Address
+}
+
+var _ wasm.StateDB = (*wasmStateDB)(nil)
+
+func (db *wasmStateDB) GetStorage(key [32]byte) [32]byte {
+	return db.storeView.GetState(db.contract, common.Hash(key))
+}
+
+func (db *wasmStateDB) SetStorage(key, value [32]byte) {
+	db.storeView.SetState(db.contract, common.Hash(key), common.Hash(value))
+}
+
+func (db *wasmStateDB) GetBalance(addr [20]byte) *big.Int {
+	return db.storeView.GetBalance(common.Address(addr))
+}
+
+func (db *wasmStateDB) Transfer(to [20]byte, amount *big.Int) bool {
+	if !CanTransfer(db.storeView, db.contract, amount) {
+		return false
+	}
+	Transfer(db.storeView, db.contract, common.Address(to), amount)
+	return true
+}
+
+func (db *wasmStateDB) AddLog(topics [][32]byte, data []byte) {
+	log := &types.Log{
+		Address: db.contract,
+		Topics:  make([]common.Hash, len(topics)),
+		Data:    data,
+	}
+	for i, topic := range topics {
+		log.Topics[i] = common.Hash(topic)
+	}
+	db.storeView.AddLog(log)
+}
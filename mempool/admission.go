@@ -0,0 +1,92 @@
+package mempool
+
+import (
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+)
+
+const EmptyTxError = MempoolError("Empty transaction")
+const TxTooLargeError = MempoolError("Transaction exceeds the maximum size")
+const PeerRateLimitedError = MempoolError("Too many transactions gossiped by the peer")
+const RecentlyRejectedTxError = MempoolError("Transaction recently rejected")
+
+// rejectedTxCacheSize is the number of the recently rejected transaction hashes to remember
+const rejectedTxCacheSize = 16384
+
+// peerRateLimitWindow is the time window over which the transactions gossiped by each peer are counted
+const peerRateLimitWindow = 1 * time.Second
+
+// txAdmission runs the cheap checks on the gossiped transactions before they are screened against
+// the ledger state, so that a flood of transactions cannot keep the node busy verifying signatures.
+type txAdmission struct {
+	mutex *sync.Mutex
+
+	maxTxSize     int // maximum size of an encoded transaction, non-positive means uncapped
+	maxTxsPerPeer int // maximum number of transactions a peer can gossip in each window, non-positive means uncapped
+	windowStart   time.Time
+	peerTxCounts  map[string]int
+	rejectedTxs   *lru.Cache // hashes of the transactions which can never become valid
+}
+
+func createTxAdmission(maxTxSize int, maxTxsPerPeer int) *txAdmission {
+	rejectedTxs, _ := lru.New(rejectedTxCacheSize)
+	return &txAdmission{
+		mutex:         &sync.Mutex{},
+		maxTxSize:     maxTxSize,
+		maxTxsPerPeer: maxTxsPerPeer,
+		windowStart:   time.Now(),
+		peerTxCounts:  make(map[string]int),
+		rejectedTxs:   rejectedTxs,
+	}
+}
+
+// checkSize rejects the empty and the oversized transactions
+func (ta *txAdmission) checkSize(rawTx common.Bytes) error {
+	if len(rawTx) == 0 {
+		return EmptyTxError
+	}
+	if ta.maxTxSize > 0 && len(rawTx) > ta.maxTxSize {
+		return TxTooLargeError
+	}
+	return nil
+}
+
+// admit runs the admission checks on the transaction gossiped by the given peer
+func (ta *txAdmission) admit(peerID string, rawTx common.Bytes) error {
+	if err := ta.checkSize(rawTx); err != nil {
+		return err
+	}
+
+	ta.mutex.Lock()
+	defer ta.mutex.Unlock()
+
+	if ta.maxTxsPerPeer > 0 {
+		if time.Since(ta.windowStart) > peerRateLimitWindow {
+			ta.windowStart = time.Now()
+			ta.peerTxCounts = make(map[string]int)
+		}
+		if ta.peerTxCounts[peerID] >= ta.maxTxsPerPeer {
+			return PeerRateLimitedError
+		}
+		ta.peerTxCounts[peerID]++
+	}
+
+	if ta.rejectedTxs.Contains(getTransactionHash(rawTx)) {
+		return RecentlyRejectedTxError
+	}
+	return nil
+}
+
+// recordRejection remembers the transaction if it was rejected for a reason that does not depend
+// on the ledger state, so that the transaction is not screened again when it is gossiped again
+func (ta *txAdmission) recordRejection(rawTx common.Bytes, res result.Result) {
+	switch res.Code {
+	case result.CodeInvalidSignature, result.CodeUnauthorizedTx, result.CodeTxExpired:
+		ta.rejectedTxs.Add(getTransactionHash(rawTx), struct{}{})
+	}
+}
@@ -0,0 +1,38 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+)
+
+func TestTxAdmission(t *testing.T) {
+	assert := assert.New(t)
+
+	ta := createTxAdmission(8, 2)
+
+	assert.Equal(EmptyTxError, ta.admit("peer1", common.Bytes{}))
+	assert.Equal(TxTooLargeError, ta.admit("peer1", createTestRawTx("123456789")))
+
+	tx1 := createTestRawTx("tx1")
+	tx2 := createTestRawTx("tx2")
+	tx3 := createTestRawTx("tx3")
+
+	// Each peer can gossip at most 2 transactions per window
+	assert.Nil(ta.admit("peer1", tx1))
+	assert.Nil(ta.admit("peer1", tx2))
+	assert.Equal(PeerRateLimitedError, ta.admit("peer1", tx3))
+	assert.Nil(ta.admit("peer2", tx3))
+
+	time.Sleep(peerRateLimitWindow + 100*time.Millisecond)
+	assert.Nil(ta.admit("peer1", tx3))
+
+	// Only the rejections independent of the ledger state are remembered
+	ta.recordRejection(tx1, result.Error("Bad sequence").WithErrorCode(result.CodeInvalidSequence))
+	ta.recordRejection(tx2, result.Error("Bad signature").WithErrorCode(result.CodeInvalidSignature))
+	assert.Nil(ta.admit("peer2", tx1))
+	assert.Equal(RecentlyRejectedTxError, ta.admit("peer3", tx2))
+}
@@ -0,0 +1,137 @@
+package mempool
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rlp"
+)
+
+// minJournalCompactionSize is the minimal number of journaled transactions before the journal
+// gets compacted, to avoid rewriting the journal too often when the mempool is almost empty
+const minJournalCompactionSize = 1024
+
+var errNoActiveJournal = errors.New("no active journal")
+
+// txJournal is an append-only journal of the pending transactions, which allows the node to
+// reload the transactions that are not yet committed after restarts. Each entry is an RLP
+// encoded raw transaction. The entries of the transactions that are no longer pending are
+// discarded when the journal is compacted.
+type txJournal struct {
+	path   string
+	writer *os.File
+	numTxs int // number of the transactions in the journal, including the ones no longer pending
+}
+
+func newTxJournal(path string) *txJournal {
+	return &txJournal{
+		path: path,
+	}
+}
+
+// load reads the raw transactions from the journal, and opens the journal for appending
+func (journal *txJournal) load() ([]common.Bytes, error) {
+	if err := os.MkdirAll(filepath.Dir(journal.path), 0700); err != nil {
+		return nil, err
+	}
+
+	rawTxs := []common.Bytes{}
+	input, err := os.Open(journal.path)
+	if err == nil {
+		defer input.Close()
+
+		stream := rlp.NewStream(input, 0)
+		for {
+			var rawTx common.Bytes
+			if err = stream.Decode(&rawTx); err != nil {
+				break
+			}
+			rawTxs = append(rawTxs, rawTx)
+		}
+		if err != io.EOF {
+			// A truncated entry is expected if the node crashed while writing the journal,
+			// the entries before it are still usable
+			logger.Warnf("Failed to read the mempool journal %v, loaded %v transactions: %v", journal.path, len(rawTxs), err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	writer, err := os.OpenFile(journal.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	journal.writer = writer
+	journal.numTxs = len(rawTxs)
+
+	return rawTxs, nil
+}
+
+// insert appends the raw transaction to the journal
+func (journal *txJournal) insert(rawTx common.Bytes) error {
+	if journal.writer == nil {
+		return errNoActiveJournal
+	}
+	if err := rlp.Encode(journal.writer, rawTx); err != nil {
+		return err
+	}
+	journal.numTxs++
+	return nil
+}
+
+// needsCompaction returns whether the journal holds too many transactions which are no longer pending
+func (journal *txJournal) needsCompaction(numPendingTxs int) bool {
+	return journal.numTxs > minJournalCompactionSize && journal.numTxs > 2*numPendingTxs
+}
+
+// rotate replaces the journal with the given pending raw transactions
+func (journal *txJournal) rotate(rawTxs []common.Bytes) error {
+	if journal.writer != nil {
+		if err := journal.writer.Close(); err != nil {
+			return err
+		}
+		journal.writer = nil
+	}
+
+	tmpPath := journal.path + ".new"
+	replacement, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	for _, rawTx := range rawTxs {
+		if err = rlp.Encode(replacement, rawTx); err != nil {
+			replacement.Close()
+			return err
+		}
+	}
+	if err = replacement.Sync(); err != nil {
+		replacement.Close()
+		return err
+	}
+	replacement.Close()
+
+	if err = os.Rename(tmpPath, journal.path); err != nil {
+		return err
+	}
+	writer, err := os.OpenFile(journal.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	journal.writer = writer
+	journal.numTxs = len(rawTxs)
+
+	return nil
+}
+
+// close closes the journal
+func (journal *txJournal) close() error {
+	if journal.writer == nil {
+		return nil
+	}
+	err := journal.writer.Close()
+	journal.writer = nil
+	return err
+}
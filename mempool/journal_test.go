@@ -0,0 +1,75 @@
+package mempool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestTxJournal(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "mempool_journal")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "mempool", "journal")
+
+	// Empty journal
+	journal := newTxJournal(path)
+	rawTxs, err := journal.load()
+	assert.Nil(err)
+	assert.Equal(0, len(rawTxs))
+
+	assert.Nil(journal.insert(common.Bytes("tx1")))
+	assert.Nil(journal.insert(common.Bytes("tx2")))
+	assert.Nil(journal.insert(common.Bytes("tx3")))
+	assert.Nil(journal.close())
+
+	// Reload after restart
+	journal = newTxJournal(path)
+	rawTxs, err = journal.load()
+	assert.Nil(err)
+	assert.Equal([]common.Bytes{common.Bytes("tx1"), common.Bytes("tx2"), common.Bytes("tx3")}, rawTxs)
+	assert.Equal(3, journal.numTxs)
+
+	// Compaction only retains the given transactions, and new transactions are appended afterwards
+	assert.Nil(journal.rotate([]common.Bytes{common.Bytes("tx2")}))
+	assert.Equal(1, journal.numTxs)
+	assert.Nil(journal.insert(common.Bytes("tx4")))
+	assert.Nil(journal.close())
+
+	journal = newTxJournal(path)
+	rawTxs, err = journal.load()
+	assert.Nil(err)
+	assert.Equal([]common.Bytes{common.Bytes("tx2"), common.Bytes("tx4")}, rawTxs)
+
+	// A truncated entry is skipped
+	assert.Nil(journal.close())
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	assert.Nil(err)
+	_, err = f.Write([]byte{0x85, 't', 'x'})
+	assert.Nil(err)
+	f.Close()
+
+	journal = newTxJournal(path)
+	rawTxs, err = journal.load()
+	assert.Nil(err)
+	assert.Equal([]common.Bytes{common.Bytes("tx2"), common.Bytes("tx4")}, rawTxs)
+	assert.Nil(journal.close())
+}
+
+func TestTxJournalCompaction(t *testing.T) {
+	assert := assert.New(t)
+
+	journal := newTxJournal("")
+	journal.numTxs = minJournalCompactionSize
+	assert.False(journal.needsCompaction(0))
+
+	journal.numTxs = 3 * minJournalCompactionSize
+	assert.True(journal.needsCompaction(minJournalCompactionSize))
+	assert.False(journal.needsCompaction(2 * minJournalCompactionSize))
+}
@@ -1,26 +1,29 @@
 package mempool
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
-	"errors"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/clist"
 	"github.com/thetatoken/theta/common/math"
 	"github.com/thetatoken/theta/common/pqueue"
 	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/common/tracing"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/consensus"
 	"github.com/thetatoken/theta/core"
 	dp "github.com/thetatoken/theta/dispatcher"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "mempool"})
+var logger = util.GetLoggerForModule("mempool")
 
 type MempoolError string
 
@@ -30,9 +33,44 @@ func (m MempoolError) Error() string {
 
 const DuplicateTxError = MempoolError("Transaction already seen")
 const FastsyncSkipTxError = MempoolError("Skip tx during fastsync")
+const ReplacementUnderpricedError = MempoolError("Replacement transaction underpriced")
+const MempoolFullError = MempoolError("Mempool is full, and the transaction fee is too low to evict a pending transaction")
+const AccountTxLimitError = MempoolError("Too many pending transactions from the account")
+const FutureTxQueueFullError = MempoolError("Too many transactions waiting for preceding sequences")
+const PriorityTxDisabledError = MempoolError("Priority local transactions are disabled")
+
+// ScreeningError is returned when a transaction fails the screening against the ledger state. It carries
+// the screening result, so that the callers can tell the cause from the result code.
+type ScreeningError struct {
+	Result result.Result
+}
+
+func (e *ScreeningError) Error() string {
+	return e.Result.Message
+}
 
 const MaxMempoolTxCount int = 25600
 
+// acceptedTxsBufferSize is the number of accepted transactions buffered for the subscribers, the
+// notifications are dropped when the buffer is full
+const acceptedTxsBufferSize = 1024
+
+// revalidationBatchSize is the number of accounts whose pending transactions are revalidated each time the
+// background revalidation acquires the Mempool lock
+const revalidationBatchSize = 128
+
+// MinReplacementGasPriceBumpPercent is the minimal increase (in percentage) of the effective gas price
+// required for a transaction to replace a pending transaction of the same account and sequence
+const MinReplacementGasPriceBumpPercent int64 = 10
+
+// MaxFutureSequenceGap is the maximum distance between the sequence of a transaction and the next
+// expected sequence of its account, for the transaction to be held until the gap is filled
+const MaxFutureSequenceGap uint64 = 16
+
+// defaultRebroadcastInterval is the interval to rebroadcast the priority local transactions if the
+// rebroadcast of the local transactions is otherwise disabled
+const defaultRebroadcastInterval = 15 * time.Second
+
 //
 // mempoolTransaction implements the pqueue.Element interface
 //
@@ -40,6 +78,8 @@ type mempoolTransaction struct {
 	index          int
 	rawTransaction common.Bytes
 	txInfo         *core.TxInfo
+	feePerByte     *big.Int
+	receivedAt     time.Time
 }
 
 var _ pqueue.Element = (*mempoolTransaction)(nil)
@@ -61,12 +101,22 @@ func createMempoolTransaction(rawTransaction common.Bytes, txInfo *core.TxInfo)
 	return &mempoolTransaction{
 		rawTransaction: rawTransaction,
 		txInfo:         txInfo,
+		feePerByte:     calculateFeePerByte(rawTransaction, txInfo),
+		receivedAt:     time.Now(),
 	}
 }
 
+// calculateFeePerByte returns the TFuelWei fee the transaction pays per byte of its encoding
+func calculateFeePerByte(rawTx common.Bytes, txInfo *core.TxInfo) *big.Int {
+	if txInfo.Fee == nil || len(rawTx) == 0 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Div(txInfo.Fee, big.NewInt(int64(len(rawTx))))
+}
+
 //
-// mempoolTransactionGroup holds a sequenece of transactions from one account. We sort transaction groups by the priority of
-// their lowest sequence transaction.
+// mempoolTransactionGroup holds a sequenece of transactions from one account. We sort transaction groups by the fee
+// per byte of their lowest sequence transaction, so that the sequence constraints of each account are preserved.
 //
 type mempoolTransactionGroup struct {
 	address common.Address
@@ -80,7 +130,7 @@ func (mtg *mempoolTransactionGroup) Priority() *big.Int {
 	if mtg.IsEmpty() {
 		return new(big.Int).SetInt64(-1)
 	}
-	return mtg.txs.Peek().(*mempoolTransaction).txInfo.EffectiveGasPrice
+	return mtg.txs.Peek().(*mempoolTransaction).feePerByte
 }
 
 func (mtg *mempoolTransactionGroup) SetIndex(index int) {
@@ -101,6 +151,61 @@ func (mtg *mempoolTransactionGroup) PopTx() (common.Bytes, *core.TxInfo) {
 	return mptx.rawTransaction, mptx.txInfo
 }
 
+// FindTx returns the pending transaction with the given sequence, or nil if not found.
+func (mtg *mempoolTransactionGroup) FindTx(sequence uint64) *mempoolTransaction {
+	for _, elem := range *mtg.txs.ElementList() {
+		mptx := elem.(*mempoolTransaction)
+		if mptx.txInfo.Sequence == sequence {
+			return mptx
+		}
+	}
+	return nil
+}
+
+// LastTx returns the pending transaction with the highest sequence, or nil if the group is empty.
+func (mtg *mempoolTransactionGroup) LastTx() *mempoolTransaction {
+	var last *mempoolTransaction
+	for _, elem := range *mtg.txs.ElementList() {
+		mptx := elem.(*mempoolTransaction)
+		if last == nil || mptx.txInfo.Sequence > last.txInfo.Sequence {
+			last = mptx
+		}
+	}
+	return last
+}
+
+// SortedTxs returns the pending transactions in ascending order of sequences.
+func (mtg *mempoolTransactionGroup) SortedTxs() []*mempoolTransaction {
+	sorted := []*mempoolTransaction{}
+	for _, elem := range *mtg.txs.ElementList() {
+		sorted = append(sorted, elem.(*mempoolTransaction))
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].txInfo.Sequence < sorted[j].txInfo.Sequence
+	})
+	return sorted
+}
+
+// PrecedingTxs returns the pending transactions with sequences lower than the given one, in ascending order.
+func (mtg *mempoolTransactionGroup) PrecedingTxs(sequence uint64) []common.Bytes {
+	preceding := []*mempoolTransaction{}
+	for _, elem := range *mtg.txs.ElementList() {
+		mptx := elem.(*mempoolTransaction)
+		if mptx.txInfo.Sequence < sequence {
+			preceding = append(preceding, mptx)
+		}
+	}
+	sort.Slice(preceding, func(i, j int) bool {
+		return preceding[i].txInfo.Sequence < preceding[j].txInfo.Sequence
+	})
+
+	rawTxs := []common.Bytes{}
+	for _, mptx := range preceding {
+		rawTxs = append(rawTxs, mptx.rawTransaction)
+	}
+	return rawTxs
+}
+
 func (mtg *mempoolTransactionGroup) IsEmpty() bool {
 	return mtg.txs.IsEmpty()
 }
@@ -145,10 +250,30 @@ type Mempool struct {
 	dispatcher *dp.Dispatcher
 
 	newTxs           *clist.CList          // new transactions, to be gossiped to other nodes
-	candidateTxs     *pqueue.PriorityQueue // candidate transactions for new block assembly, ordered by the transaction fee (high to low)
+	candidateTxs     *pqueue.PriorityQueue // candidate transactions for new block assembly, ordered by the transaction fee per byte (high to low)
 	txBookeepper     transactionBookkeeper
 	addressToTxGroup map[common.Address]*mempoolTransactionGroup
 	size             int
+	maxSize          int // maximum number of transactions, non-positive means uncapped
+
+	maxNumTxsPerAccount int         // maximum number of pending transactions per account, non-positive means uncapped
+	orphans             *orphanPool // transactions waiting for their sequence gaps to be filled
+
+	localTxs            map[string]common.Bytes // transactions submitted through the local RPC, by transaction hash
+	rebroadcastInterval time.Duration           // interval to rebroadcast the pending local transactions, non-positive means disabled
+	knownPeers          map[string]bool         // peers connected at the last rebroadcast
+	priorityLocalTxs    bool                    // whether the local transactions can be submitted as priority transactions
+	priorityTxs         map[string]bool         // priority local transactions, which are never evicted, by transaction hash
+	maxNumPriorityTxs   int                     // maximum number of priority transactions admitted in excess of maxSize
+
+	acceptedTxs chan *PendingTransaction // newly accepted candidate transactions, for the subscribers
+	admission   *txAdmission
+
+	staleAddresses map[common.Address]bool // accounts whose transactions have not been revalidated since the last committed block
+	revalidateC    chan struct{}
+
+	journal      *txJournal     // journal of the pending transactions, nil if disabled
+	journaledTxs []common.Bytes // transactions loaded from the journal, to be reinserted once synced
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -167,8 +292,25 @@ func CreateMempool(dispatcher *dp.Dispatcher, engine *consensus.ConsensusEngine)
 		newTxs:           clist.New(),
 		candidateTxs:     pqueue.CreatePriorityQueue(),
 		addressToTxGroup: make(map[common.Address]*mempoolTransactionGroup),
-		txBookeepper:     createTransactionBookkeeper(defaultMaxNumTxs),
+		txBookeepper:     createTransactionBookkeeper(defaultMaxNumTxs, time.Duration(viper.GetInt(common.CfgMempoolTxLifetimeSecs))*time.Second),
+		maxSize:          viper.GetInt(common.CfgMempoolMaxNumTxs),
 		wg:               &sync.WaitGroup{},
+
+		maxNumTxsPerAccount: viper.GetInt(common.CfgMempoolMaxNumTxsPerAccount),
+		orphans:             newOrphanPool(viper.GetInt(common.CfgMempoolMaxNumFutureTxs)),
+
+		localTxs:            make(map[string]common.Bytes),
+		rebroadcastInterval: time.Duration(viper.GetInt(common.CfgMempoolRebroadcastIntervalSecs)) * time.Second,
+		knownPeers:          make(map[string]bool),
+		priorityLocalTxs:    viper.GetBool(common.CfgMempoolPriorityLocalTxs),
+		priorityTxs:         make(map[string]bool),
+		maxNumPriorityTxs:   viper.GetInt(common.CfgMempoolMaxNumPriorityTxs),
+
+		acceptedTxs: make(chan *PendingTransaction, acceptedTxsBufferSize),
+		admission:   createTxAdmission(viper.GetInt(common.CfgMempoolMaxTxSize), viper.GetInt(common.CfgMempoolMaxTxsPerPeerPerSecond)),
+
+		staleAddresses: make(map[common.Address]bool),
+		revalidateC:    make(chan struct{}, 1),
 	}
 }
 
@@ -177,8 +319,45 @@ func (mp *Mempool) SetLedger(ledger core.Ledger) {
 	mp.ledger = ledger
 }
 
+// hasSynced returns whether the node has synced with the network. Without a consensus engine, e.g. in
+// tests, the node is regarded as synced.
+func (mp *Mempool) hasSynced() bool {
+	return mp.consensus == nil || mp.consensus.HasSynced()
+}
+
+// EnableJournal persists the pending transactions to the journal at the given path. The transactions
+// already in the journal are reinserted into the Mempool once the node has synced with the network.
+func (mp *Mempool) EnableJournal(path string) error {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	journal := newTxJournal(path)
+	rawTxs, err := journal.load()
+	if err != nil {
+		return err
+	}
+	mp.journal = journal
+	mp.journaledTxs = rawTxs
+	logger.Infof("Loaded %v transactions from the mempool journal %v", len(rawTxs), path)
+
+	return nil
+}
+
 // InsertTransaction inserts the incoming transaction to mempool (submitted by the clients or relayed from peers)
-func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
+func (mp *Mempool) InsertTransaction(rawTx common.Bytes) (err error) {
+	_, span := tracing.StartSpan(context.Background(), "mempool.insert_tx")
+	if span != nil {
+		span.SetAttribute("tx.hash", "0x"+getTransactionHash(rawTx))
+		defer func() {
+			span.SetError(err)
+			span.Finish()
+		}()
+	}
+
+	if err := mp.admission.checkSize(rawTx); err != nil {
+		return err
+	}
+
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
 
@@ -188,41 +367,51 @@ func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
 		return DuplicateTxError
 	}
 
-	// if mp.size >= MaxMempoolTxCount {
-	// 	logger.Debugf("Mempool is full")
-	// 	return errors.New("mempool is full, please submit your transaction again later")
-	// }
-
 	var txInfo *core.TxInfo
 	var checkTxRes result.Result
 
 	// Delay tx verification when in fast sync
-	if mp.consensus.HasSynced() {
+	if mp.hasSynced() {
+		// The pending transactions of the account must be screened on top of the last committed block first
+		if len(mp.staleAddresses) > 0 {
+			if txInfo, res := mp.ledger.GetTxInfo(rawTx); res.IsOK() {
+				mp.revalidateAccount(txInfo.Address)
+			}
+		}
+
+		if replaced, err := mp.replaceTransaction(rawTx); replaced || err != nil {
+			return err
+		}
+
+		// Check the limit before screening, since a screened transaction is already reflected in the screened view
+		if err := mp.checkAccountTxLimit(rawTx); err != nil {
+			return err
+		}
+
 		txInfo, checkTxRes = mp.ledger.ScreenTx(rawTx)
 		if !checkTxRes.IsOK() {
+			if checkTxRes.Code == result.CodeInvalidSequence {
+				if queued, err := mp.queueFutureTransaction(rawTx); queued || err != nil {
+					return err
+				}
+			}
 			logger.Debugf("Transaction screening failed, tx: %v, error: %v", hex.EncodeToString(rawTx), checkTxRes.Message)
-			return errors.New(checkTxRes.Message)
+			mp.admission.recordRejection(rawTx, checkTxRes)
+			return &ScreeningError{Result: checkTxRes}
 		}
 
-		// only record the transactions that passed the screening. This is because that
-		// an invalid transaction could becoume valid later on. For example, assume expected
-		// sequence for an account is 6. The account accidentally submits txA (seq = 7), got rejected.
-		// He then submit txB(seq = 6), and then txA(seq = 7) again. For the second submission, txA
-		// should not be rejected even though it has been submitted earlier.
-		mp.txBookeepper.record(rawTx)
-
-		txGroup, ok := mp.addressToTxGroup[txInfo.Address]
-		if ok {
-			txGroup.AddTx(rawTx, txInfo)
-			mp.candidateTxs.Remove(txGroup.index) // Need to re-insert txGroup into queue since its priority could change.
-		} else {
-			txGroup = createMempoolTransactionGroup(rawTx, txInfo)
-			mp.addressToTxGroup[txInfo.Address] = txGroup
+		if mp.maxSize > 0 && mp.size >= mp.maxSize {
+			evicted := mp.evictLowestFeeTransaction(txInfo.Address, calculateFeePerByte(rawTx, txInfo))
+			// The priority transactions can only exceed the size limit by the capacity of the priority lane
+			if !evicted && (!mp.priorityTxs[getTransactionHash(rawTx)] || mp.size >= mp.maxSize+mp.maxNumPriorityTxs) {
+				logger.Debugf("Mempool is full, tx: %v", hex.EncodeToString(rawTx))
+				mp.rollBackScreenedAccount(txInfo.Address) // the transaction has been screened already
+				return MempoolFullError
+			}
 		}
-		mp.candidateTxs.Push(txGroup)
-		logger.Debugf("rawTx: %v, txInfo: %v", hex.EncodeToString(rawTx), txInfo)
-		logger.Infof("Insert tx, tx.hash: 0x%v", getTransactionHash(rawTx))
-		mp.size++
+
+		mp.addTransaction(rawTx, txInfo)
+		mp.promoteFutureTransactions(txInfo.Address)
 
 		return nil
 	}
@@ -230,18 +419,477 @@ func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
 	return FastsyncSkipTxError
 }
 
+// addTransaction adds the screened transaction to the candidate transactions
+func (mp *Mempool) addTransaction(rawTx common.Bytes, txInfo *core.TxInfo) {
+	// only record the transactions that passed the screening. This is because that
+	// an invalid transaction could becoume valid later on. For example, assume expected
+	// sequence for an account is 6. The account accidentally submits txA (seq = 7), got rejected.
+	// He then submit txB(seq = 6), and then txA(seq = 7) again. For the second submission, txA
+	// should not be rejected even though it has been submitted earlier.
+	mp.txBookeepper.record(rawTx)
+
+	txGroup, ok := mp.addressToTxGroup[txInfo.Address]
+	if ok {
+		txGroup.AddTx(rawTx, txInfo)
+		mp.candidateTxs.Remove(txGroup.index) // Need to re-insert txGroup into queue since its priority could change.
+	} else {
+		txGroup = createMempoolTransactionGroup(rawTx, txInfo)
+		mp.addressToTxGroup[txInfo.Address] = txGroup
+	}
+	mp.candidateTxs.Push(txGroup)
+	logger.Debugf("rawTx: %v, txInfo: %v", hex.EncodeToString(rawTx), txInfo)
+	logger.Infof("Insert tx, tx.hash: 0x%v", getTransactionHash(rawTx))
+	mp.size++
+	mp.journalTransaction(rawTx)
+	mp.notifyAcceptedTransaction(txGroup.FindTx(txInfo.Sequence))
+}
+
+// notifyAcceptedTransaction publishes the newly accepted candidate transaction to the subscribers
+func (mp *Mempool) notifyAcceptedTransaction(mptx *mempoolTransaction) {
+	select {
+	case mp.acceptedTxs <- newPendingTransaction(mptx):
+	default:
+		logger.Debugf("Failed to notify accepted tx, tx.hash: 0x%v", getTransactionHash(mptx.rawTransaction))
+	}
+}
+
+// hasReachedAccountTxLimit returns whether the account already has the maximum number of pending transactions
+func (mp *Mempool) hasReachedAccountTxLimit(address common.Address) bool {
+	if mp.maxNumTxsPerAccount <= 0 {
+		return false
+	}
+	txGroup, ok := mp.addressToTxGroup[address]
+	return ok && txGroup.txs.NumElements() >= mp.maxNumTxsPerAccount
+}
+
+// checkAccountTxLimit rejects the transaction if its account already has the maximum number of pending transactions,
+// so that a single account cannot fill up the Mempool
+func (mp *Mempool) checkAccountTxLimit(rawTx common.Bytes) error {
+	txInfo, res := mp.ledger.GetTxInfo(rawTx)
+	if res.IsError() {
+		return nil // the regular screening will reject the transaction
+	}
+	if mp.hasReachedAccountTxLimit(txInfo.Address) {
+		logger.Debugf("Too many pending transactions from account %v, tx: %v", txInfo.Address, hex.EncodeToString(rawTx))
+		return AccountTxLimitError
+	}
+	return nil
+}
+
+// queueFutureTransaction holds the transaction which failed the screening due to its sequence, if the sequence
+// is ahead of the next expected sequence of the account by no more than MaxFutureSequenceGap. The transaction
+// is promoted to the candidate transactions once the sequence gap is filled. It returns false if the transaction
+// is not a future transaction.
+func (mp *Mempool) queueFutureTransaction(rawTx common.Bytes) (bool, error) {
+	txInfo, res := mp.ledger.GetTxInfo(rawTx)
+	if res.IsError() {
+		return false, nil
+	}
+	nextSequence := mp.ledger.GetAccountSequence(txInfo.Address) + 1
+	if txInfo.Sequence <= nextSequence || txInfo.Sequence > nextSequence+MaxFutureSequenceGap {
+		return false, nil
+	}
+
+	if err := mp.addFutureTransaction(rawTx, txInfo, time.Now()); err != nil {
+		return true, err
+	}
+	mp.journalTransaction(rawTx)
+
+	return true, nil
+}
+
+// addFutureTransaction holds the transaction in the orphan pool until the preceding sequences of the account
+// are filled.
+func (mp *Mempool) addFutureTransaction(rawTx common.Bytes, txInfo *core.TxInfo, receivedAt time.Time) error {
+	err := mp.orphans.add(&futureTransaction{
+		rawTransaction: rawTx,
+		txInfo:         txInfo,
+		receivedAt:     receivedAt,
+	})
+	if err == FutureTxQueueFullError {
+		logger.Debugf("Future transaction queue is full, tx: %v", hex.EncodeToString(rawTx))
+	}
+	return err
+}
+
+// promoteFutureTransactions moves the future transactions of the account whose sequence gaps have been
+// filled to the candidate transactions. The promotion stops at the first transaction that cannot be
+// added yet, because the account or the Mempool is full.
+func (mp *Mempool) promoteFutureTransactions(address common.Address) {
+	for {
+		nextSequence := mp.ledger.GetAccountSequence(address) + 1
+		futureTx, ok := mp.orphans.get(address, nextSequence)
+		if !ok {
+			return
+		}
+
+		if mp.hasReachedAccountTxLimit(address) {
+			return
+		}
+		feePerByte := calculateFeePerByte(futureTx.rawTransaction, futureTx.txInfo)
+		if mp.maxSize > 0 && mp.size >= mp.maxSize {
+			if _, victim := mp.findEvictionCandidate(address, feePerByte); victim == nil {
+				return
+			}
+		}
+
+		mp.orphans.remove(address, nextSequence)
+		txInfo, checkTxRes := mp.ledger.ScreenTx(futureTx.rawTransaction)
+		if !checkTxRes.IsOK() {
+			logger.Debugf("Future transaction screening failed, tx: %v, error: %v",
+				hex.EncodeToString(futureTx.rawTransaction), checkTxRes.Message)
+			continue
+		}
+		if mp.maxSize > 0 && mp.size >= mp.maxSize {
+			mp.evictLowestFeeTransaction(address, feePerByte)
+		}
+		mp.addTransaction(futureTx.rawTransaction, txInfo)
+		logger.Infof("Promote future tx, tx.hash: 0x%v", getTransactionHash(futureTx.rawTransaction))
+	}
+}
+
+// PreverifyTransactions verifies the signatures of the transactions in parallel ahead of their
+// insertion, which then skips the signatures already verified. It's a no-op until the node has
+// synced, since the transactions are not screened during fast sync.
+func (mp *Mempool) PreverifyTransactions(rawTxs []common.Bytes) {
+	if mp.ledger == nil || !mp.hasSynced() {
+		return
+	}
+	mp.ledger.PreverifyTxSignatures(rawTxs)
+}
+
+// AdmitGossipedTransaction runs the cheap checks on the transaction gossiped by the given peer, i.e. the size
+// limit, the per-peer rate limit and whether the transaction was recently rejected, before the transaction is
+// screened against the ledger state.
+func (mp *Mempool) AdmitGossipedTransaction(peerID string, rawTx common.Bytes) error {
+	return mp.admission.admit(peerID, rawTx)
+}
+
+// InsertLocalTransaction inserts the transaction submitted through the local RPC. Unlike the transactions relayed
+// from peers, the local transactions are rebroadcast periodically while they are pending, so that they still reach
+// the validators if the initial gossip misses them. A priority transaction is admitted even if the Mempool is full,
+// as long as the Mempool exceeds its size limit by less than maxNumPriorityTxs, is never evicted by the transactions
+// paying higher fees, and is rebroadcast even if the rebroadcast of the local transactions is disabled.
+func (mp *Mempool) InsertLocalTransaction(rawTx common.Bytes, priority bool) error {
+	if priority && !mp.priorityLocalTxs {
+		return PriorityTxDisabledError
+	}
+
+	txHash := getTransactionHash(rawTx)
+	newPriorityTx := false
+	if priority {
+		mp.mutex.Lock()
+		newPriorityTx = !mp.priorityTxs[txHash]
+		mp.priorityTxs[txHash] = true
+		mp.mutex.Unlock()
+	}
+
+	err := mp.InsertTransaction(rawTx)
+
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	if err != nil {
+		if newPriorityTx {
+			delete(mp.priorityTxs, txHash)
+		}
+		return err
+	}
+	if priority || mp.rebroadcastInterval > 0 {
+		mp.localTxs[txHash] = rawTx
+	}
+
+	return nil
+}
+
+// replaceTransaction replaces the pending transaction of the same account and sequence with the
+// given transaction, provided that the given transaction pays a sufficiently higher effective gas
+// price. It returns false if there is no pending transaction to be replaced.
+func (mp *Mempool) replaceTransaction(rawTx common.Bytes) (bool, error) {
+	txInfo, res := mp.ledger.GetTxInfo(rawTx)
+	if res.IsError() {
+		return false, nil // the regular screening will reject the transaction
+	}
+	txGroup, ok := mp.addressToTxGroup[txInfo.Address]
+	if !ok {
+		return false, nil
+	}
+	pendingTx := txGroup.FindTx(txInfo.Sequence)
+	if pendingTx == nil {
+		return false, nil
+	}
+
+	minGasPrice := minReplacementGasPrice(pendingTx.txInfo.EffectiveGasPrice)
+	if txInfo.EffectiveGasPrice.Cmp(minGasPrice) < 0 {
+		logger.Debugf("Replacement transaction underpriced, tx: %v, effective gas price: %v, required: %v",
+			hex.EncodeToString(rawTx), txInfo.EffectiveGasPrice, minGasPrice)
+		return true, ReplacementUnderpricedError
+	}
+
+	txInfo, checkTxRes := mp.ledger.ScreenTxReplacement(rawTx, txGroup.PrecedingTxs(txInfo.Sequence))
+	if !checkTxRes.IsOK() {
+		logger.Debugf("Replacement transaction screening failed, tx: %v, error: %v", hex.EncodeToString(rawTx), checkTxRes.Message)
+		return true, &ScreeningError{Result: checkTxRes}
+	}
+
+	mp.txBookeepper.record(rawTx)
+	mp.txBookeepper.markAbandoned(pendingTx.rawTransaction)
+
+	txGroup.txs.Remove(pendingTx.GetIndex())
+	txGroup.AddTx(rawTx, txInfo)
+	mp.candidateTxs.Remove(txGroup.index) // Need to re-insert txGroup into queue since its priority could change.
+	mp.candidateTxs.Push(txGroup)
+	logger.Infof("Replace tx, tx.hash: 0x%v, replaced tx.hash: 0x%v",
+		getTransactionHash(rawTx), getTransactionHash(pendingTx.rawTransaction))
+	mp.journalTransaction(rawTx)
+	mp.notifyAcceptedTransaction(txGroup.FindTx(txInfo.Sequence))
+
+	return true, nil
+}
+
+// minReplacementGasPrice returns the minimal effective gas price for a transaction to replace
+// another transaction of the same account and sequence
+func minReplacementGasPrice(effectiveGasPrice *big.Int) *big.Int {
+	minGasPrice := new(big.Int).Mul(effectiveGasPrice, big.NewInt(100+MinReplacementGasPriceBumpPercent))
+	return minGasPrice.Div(minGasPrice, big.NewInt(100))
+}
+
+// evictLowestFeeTransaction evicts the pending transaction with the lowest fee per byte to make room for
+// a new transaction, provided that the new transaction pays a higher fee per byte. Only the highest sequence
+// transaction of each account can be evicted, so that the remaining transactions of the account can still be
+// included in order. The transactions of the account which sends the new transaction are not evicted, since
+// the new transaction may depend on them. It returns false if no transaction can be evicted.
+func (mp *Mempool) evictLowestFeeTransaction(address common.Address, feePerByte *big.Int) bool {
+	victimGroup, victim := mp.findEvictionCandidate(address, feePerByte)
+	if victim == nil {
+		return false
+	}
+
+	victimGroup.txs.Remove(victim.GetIndex())
+	mp.txBookeepper.markAbandoned(victim.rawTransaction)
+	if victimGroup.IsEmpty() {
+		delete(mp.addressToTxGroup, victimGroup.address)
+		mp.candidateTxs.Remove(victimGroup.GetIndex())
+	}
+	mp.size--
+	mp.rollBackScreenedAccount(victimGroup.address)
+	logger.Infof("Evict tx, tx.hash: 0x%v, fee per byte: %v", getTransactionHash(victim.rawTransaction), victim.feePerByte)
+
+	return true
+}
+
+// rollBackScreenedAccount rolls back the screened view of the account after the removal of a screened transaction,
+// e.g. an evicted one, which would otherwise still count towards the sequence and balance of the account. The
+// account is reset to the last committed block, and marked stale so that its remaining transactions are screened
+// again before the account is used, see revalidateAccount.
+func (mp *Mempool) rollBackScreenedAccount(address common.Address) {
+	mp.ledger.ResetScreenedAccountUnsafe(address)
+	mp.staleAddresses[address] = true
+	select {
+	case mp.revalidateC <- struct{}{}:
+	default:
+	}
+}
+
+// findEvictionCandidate returns the transaction to be evicted for a new transaction of the given account
+// and fee per byte, or nil if no transaction can be evicted.
+func (mp *Mempool) findEvictionCandidate(address common.Address, feePerByte *big.Int) (*mempoolTransactionGroup, *mempoolTransaction) {
+	var victimGroup *mempoolTransactionGroup
+	var victim *mempoolTransaction
+	for _, elem := range *mp.candidateTxs.ElementList() {
+		txGroup := elem.(*mempoolTransactionGroup)
+		if txGroup.address == address {
+			continue
+		}
+		lastTx := txGroup.LastTx()
+		if lastTx == nil || mp.priorityTxs[getTransactionHash(lastTx.rawTransaction)] {
+			continue
+		}
+		if victim == nil || lastTx.feePerByte.Cmp(victim.feePerByte) < 0 {
+			victimGroup = txGroup
+			victim = lastTx
+		}
+	}
+	if victim == nil || victim.feePerByte.Cmp(feePerByte) >= 0 {
+		return nil, nil
+	}
+	return victimGroup, victim
+}
+
+// journalTransaction appends the inserted transaction to the journal if enabled
+func (mp *Mempool) journalTransaction(rawTx common.Bytes) {
+	if mp.journal == nil {
+		return
+	}
+	if err := mp.journal.insert(rawTx); err != nil {
+		logger.Warnf("Failed to journal tx, tx.hash: 0x%v, error: %v", getTransactionHash(rawTx), err)
+	}
+}
+
+// compactJournal rewrites the journal with the pending transactions, including the future transactions. The
+// transactions of each account are written in the order of their sequences, so that they can be reinserted in order.
+func (mp *Mempool) compactJournal() {
+	rawTxs := []common.Bytes{}
+	for _, elem := range *mp.candidateTxs.ElementList() {
+		for _, mptx := range elem.(*mempoolTransactionGroup).SortedTxs() {
+			rawTxs = append(rawTxs, mptx.rawTransaction)
+		}
+	}
+	for _, futureTx := range mp.orphans.all() {
+		rawTxs = append(rawTxs, futureTx.rawTransaction)
+	}
+	if err := mp.journal.rotate(rawTxs); err != nil {
+		logger.Warnf("Failed to compact the mempool journal: %v", err)
+		return
+	}
+	logger.Debugf("Compacted the mempool journal, %v transactions retained", len(rawTxs))
+}
+
+// reinsertJournaledTransactions waits until the node has synced with the network, and then reinserts
+// the transactions loaded from the journal. The transactions are revalidated against the latest
+// ledger state, and the ones accepted are gossiped to the peers again.
+func (mp *Mempool) reinsertJournaledTransactions() {
+	defer mp.wg.Done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for !mp.hasSynced() {
+		select {
+		case <-mp.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	mp.mutex.Lock()
+	rawTxs := mp.journaledTxs
+	mp.journaledTxs = nil
+	mp.mutex.Unlock()
+
+	mp.PreverifyTransactions(rawTxs)
+
+	numReinserted := 0
+	for _, rawTx := range rawTxs {
+		if err := mp.InsertTransaction(rawTx); err != nil {
+			logger.Debugf("Skip journaled tx, tx.hash: 0x%v, error: %v", getTransactionHash(rawTx), err)
+			continue
+		}
+		mp.BroadcastTx(rawTx)
+		numReinserted++
+	}
+	logger.Infof("Reinserted %v out of %v journaled transactions", numReinserted, len(rawTxs))
+
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	if mp.journal != nil {
+		mp.compactJournal()
+	}
+}
+
+// rebroadcastLocalTransactions periodically rebroadcasts the pending local transactions until the Mempool stops
+func (mp *Mempool) rebroadcastLocalTransactions() {
+	defer mp.wg.Done()
+
+	interval := mp.rebroadcastInterval
+	if interval <= 0 {
+		interval = defaultRebroadcastInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-mp.ctx.Done():
+			return
+		case <-ticker.C:
+			mp.rebroadcast()
+		}
+	}
+}
+
+// rebroadcast gossips the local transactions which are still pending, and sends them directly to the peers
+// connected since the last rebroadcast. The local transactions no longer pending are forgotten.
+func (mp *Mempool) rebroadcast() {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	pendingTxHashes := make(map[string]bool)
+	for _, txGroupEl := range *mp.candidateTxs.ElementList() {
+		for _, txEl := range *txGroupEl.(*mempoolTransactionGroup).txs.ElementList() {
+			pendingTxHashes[getTransactionHash(txEl.(*mempoolTransaction).rawTransaction)] = true
+		}
+	}
+	for _, futureTx := range mp.orphans.all() {
+		pendingTxHashes[getTransactionHash(futureTx.rawTransaction)] = true
+	}
+
+	rawTxs := []common.Bytes{}
+	for txHash, rawTx := range mp.localTxs {
+		if !pendingTxHashes[txHash] {
+			delete(mp.localTxs, txHash)
+			delete(mp.priorityTxs, txHash)
+			continue
+		}
+		rawTxs = append(rawTxs, rawTx)
+	}
+
+	peers := mp.dispatcher.Peers(true) // transactions are only gossiped among the blockchain nodes
+	newPeers := []string{}
+	knownPeers := make(map[string]bool)
+	for _, peer := range peers {
+		if !mp.knownPeers[peer] {
+			newPeers = append(newPeers, peer)
+		}
+		knownPeers[peer] = true
+	}
+	mp.knownPeers = knownPeers
+
+	if len(rawTxs) == 0 {
+		return
+	}
+	for _, rawTx := range rawTxs {
+		mp.BroadcastTxUnsafe(rawTx)
+		if len(newPeers) > 0 {
+			data := dp.DataResponse{
+				ChannelID: common.ChannelIDTransaction,
+				Payload:   rawTx,
+			}
+			mp.dispatcher.SendData(newPeers, data)
+		}
+	}
+	logger.Debugf("Rebroadcasted %v local transactions, sent to %v new peers", len(rawTxs), len(newPeers))
+}
+
 // Start needs to be called when the Mempool starts
 func (mp *Mempool) Start(ctx context.Context) error {
 	c, cancel := context.WithCancel(ctx)
 	mp.ctx = c
 	mp.cancel = cancel
 
+	if len(mp.journaledTxs) > 0 {
+		mp.wg.Add(1)
+		go mp.reinsertJournaledTransactions()
+	}
+	if mp.rebroadcastInterval > 0 || mp.priorityLocalTxs {
+		mp.wg.Add(1)
+		go mp.rebroadcastLocalTransactions()
+	}
+	mp.wg.Add(1)
+	go mp.revalidateTransactionsInBackground()
+
 	return nil
 }
 
 // Stop needs to be called when the Mempool stops
 func (mp *Mempool) Stop() {
 	mp.cancel()
+
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	if mp.journal != nil {
+		if err := mp.journal.close(); err != nil {
+			logger.Warnf("Failed to close the mempool journal: %v", err)
+		}
+		mp.journal = nil
+	}
 }
 
 // Wait suspends the caller goroutine
@@ -293,7 +941,14 @@ func (mp *Mempool) ReapUnsafe(maxNumTxs int) []common.Bytes {
 		if mp.candidateTxs.IsEmpty() {
 			break
 		}
-		txGroup := mp.candidateTxs.Pop().(*mempoolTransactionGroup)
+		txGroup := mp.candidateTxs.Peek().(*mempoolTransactionGroup)
+		if mp.staleAddresses[txGroup.address] {
+			// The background revalidation has not reached the account yet
+			mp.revalidateAccount(txGroup.address)
+			i--
+			continue
+		}
+		mp.candidateTxs.Pop()
 		rawTx, txInfo := txGroup.PopTx()
 
 		// Check for outdated txs
@@ -319,58 +974,158 @@ func (mp *Mempool) ReapUnsafe(maxNumTxs int) []common.Bytes {
 	return txs
 }
 
-// Update removes the committed transactions from the transaction candidate list
+// Update removes the committed transactions from the transaction candidate list, and revalidates
+// the remaining transactions before returning
 // RUNTIME COMPLEXITY: O(k + n), where k is the number committed raw transactions,
 // and n is the number of transactions in the candidate pool.
 func (mp *Mempool) Update(committedRawTxs []common.Bytes) {
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
 	mp.UpdateUnsafe(committedRawTxs)
+	for address := range mp.staleAddresses {
+		mp.revalidateAccount(address)
+	}
 }
 
 // UpdateUnsafe is the non-locking version of Update. Caller must call Mempool.Lock() before
-// calling this method.
+// calling this method. Unlike Update, it leaves the revalidation of the remaining transactions
+// to the background, so that the Mempool is not locked for a full pass over the pending transactions.
 func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
 	start := time.Now()
 	mp.removeTxs(committedRawTxs)
 	removeCommittedTxTime := time.Since(start)
 
-	// Remove Txs that have become obsolete.
-	start = time.Now()
+	for address := range mp.addressToTxGroup {
+		mp.staleAddresses[address] = true
+	}
+	for _, address := range mp.orphans.addresses() {
+		mp.staleAddresses[address] = true
+	}
+	select {
+	case mp.revalidateC <- struct{}{}:
+	default:
+	}
+
+	if mp.journal != nil && mp.journal.needsCompaction(mp.size+mp.orphans.len()) {
+		mp.compactJournal()
+	}
+
+	logger.Debugf("UpdateUnsafe: removeCommittedTxTime = %v, %d accounts to be revalidated", removeCommittedTxTime, len(mp.staleAddresses))
+}
+
+// revalidateTransactionsInBackground revalidates the transactions of the accounts marked stale by UpdateUnsafe.
+// It releases the Mempool lock after each batch of accounts, so that the insertions and the block proposals are
+// not held up by the revalidation. The insertions and the reaping revalidate the accounts they touch first.
+func (mp *Mempool) revalidateTransactionsInBackground() {
+	defer mp.wg.Done()
+
+	for {
+		select {
+		case <-mp.ctx.Done():
+			return
+		case <-mp.revalidateC:
+		}
+
+		start := time.Now()
+		for mp.revalidateBatch() {
+			select {
+			case <-mp.ctx.Done():
+				return
+			default:
+			}
+		}
+		logger.Debugf("Revalidated the pending transactions in %v", time.Since(start))
+	}
+}
+
+// revalidateBatch revalidates the transactions of up to revalidationBatchSize stale accounts. It returns
+// false once no stale account is left.
+func (mp *Mempool) revalidateBatch() bool {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
 	count := 0
-	invalidTxs := []common.Bytes{}
-	txGroups := mp.candidateTxs.ElementList()
-	for _, txGroupEl := range *txGroups {
-		txGroup := txGroupEl.(*mempoolTransactionGroup)
-		txs := txGroup.txs.ElementList()
-		for _, txEl := range *txs {
-			count++
-
-			mempoolTx := txEl.(*mempoolTransaction)
-
-			// Check for outdated txs
-			txHash := getTransactionHash(mempoolTx.rawTransaction)
-			_, exists := mp.txBookeepper.getStatus(txHash)
-			if !exists {
-				// Tx has been removed from bookkeeper due to timeout
-				invalidTxs = append(invalidTxs, mempoolTx.rawTransaction)
-				continue
+	for address := range mp.staleAddresses {
+		if count >= revalidationBatchSize {
+			return true
+		}
+		mp.revalidateAccount(address)
+		count++
+	}
+	return false
+}
+
+// revalidateAccount revalidates the pending transactions of the account if it is stale, see revalidateTxGroup,
+// then drops the future transactions that are outdated or superseded by the committed transactions, and
+// promotes the ones whose sequence gaps have been filled.
+func (mp *Mempool) revalidateAccount(address common.Address) {
+	if !mp.staleAddresses[address] {
+		return
+	}
+	delete(mp.staleAddresses, address)
+
+	if txGroup, ok := mp.addressToTxGroup[address]; ok {
+		removedTxs := make(map[string]bool)
+		for _, rawTx := range mp.revalidateTxGroup(txGroup) {
+			removedTxs[string(rawTx)] = true
+		}
+		if len(removedTxs) > 0 {
+			mp.size -= txGroup.RemoveTxs(removedTxs)
+			mp.candidateTxs.Remove(txGroup.GetIndex()) // the priority changes with the lowest sequence transaction
+			if txGroup.IsEmpty() {
+				delete(mp.addressToTxGroup, address)
+			} else {
+				mp.candidateTxs.Push(txGroup)
 			}
+		}
+	}
+
+	mp.orphans.prune(address, mp.ledger.GetAccountSequence(address)+1, mp.txBookeepper.txLife)
+	mp.promoteFutureTransactions(address)
+}
+
+// revalidateTxGroup rescreens the pending transactions of the account against the screened view, which has been
+// reset to the state of the newly committed block. The transactions are screened in the order of their sequences,
+// so that each transaction is checked on top of its predecessors. The transactions that are no longer valid, e.g.
+// with stale sequences or insufficient balances, are abandoned. The transactions following an abandoned one are
+// moved to the future transactions, since they could become valid again once the account fills the sequence gap.
+// It returns the transactions to be removed from the candidate transactions.
+func (mp *Mempool) revalidateTxGroup(txGroup *mempoolTransactionGroup) []common.Bytes {
+	removedTxs := []common.Bytes{}
+	hasGap := false
+	for _, mempoolTx := range txGroup.SortedTxs() {
+		// Check for outdated txs
+		txHash := getTransactionHash(mempoolTx.rawTransaction)
+		_, exists := mp.txBookeepper.getStatus(txHash)
+		if !exists {
+			// Tx has been removed from bookkeeper due to timeout
+			removedTxs = append(removedTxs, mempoolTx.rawTransaction)
+			hasGap = true
+			continue
+		}
 
+		if !hasGap {
 			checkTxRes := mp.ledger.ScreenTxUnsafe(mempoolTx.rawTransaction)
-			if !checkTxRes.IsOK() {
-				invalidTxs = append(invalidTxs, mempoolTx.rawTransaction)
+			if checkTxRes.IsOK() {
+				continue
+			}
+			hasGap = checkTxRes.Code != result.CodeInvalidSequence ||
+				mempoolTx.txInfo.Sequence > mp.ledger.GetAccountSequence(txGroup.address)
+			if checkTxRes.Code != result.CodeInvalidSequence || !hasGap {
+				// A stale sequence does not leave a gap, since the sequence has been taken by a committed transaction
+				logger.Debugf("Abandon tx, tx.hash: 0x%v, error: %v", txHash, checkTxRes.Message)
+				removedTxs = append(removedTxs, mempoolTx.rawTransaction)
 				mp.txBookeepper.markAbandoned(mempoolTx.rawTransaction)
+				continue
 			}
 		}
-	}
-	screenTxTime := time.Since(start)
-
-	start = time.Now()
-	mp.removeTxs(invalidTxs)
-	removeInvalidTxTime := time.Since(start)
 
-	logger.Debugf("UpdateUnsafe: %d tx screened in %v, removeCommittedTxTime = %v, removed %d obsolete Txs in %v: %v,", count, screenTxTime, removeCommittedTxTime, len(invalidTxs), removeInvalidTxTime, invalidTxs)
+		removedTxs = append(removedTxs, mempoolTx.rawTransaction)
+		if err := mp.addFutureTransaction(mempoolTx.rawTransaction, mempoolTx.txInfo, mempoolTx.receivedAt); err != nil {
+			mp.txBookeepper.markAbandoned(mempoolTx.rawTransaction)
+		}
+	}
+	return removedTxs
 }
 
 func (mp *Mempool) removeTxs(committedRawTxs []common.Bytes) {
@@ -423,6 +1178,120 @@ func (mp *Mempool) GetCandidateTransactionHashes() []string {
 	return txHashes
 }
 
+// PendingTransaction describes a transaction held in the Mempool
+type PendingTransaction struct {
+	Hash       string // hex encoded hash, without the 0x prefix
+	RawTx      common.Bytes
+	TxInfo     *core.TxInfo
+	FeePerByte *big.Int
+	Queued     bool // whether the transaction is waiting for the transactions with lower sequences
+	ReceivedAt time.Time
+
+	PrecedingRawTxs []common.Bytes // pending transactions of the same account with lower sequences
+}
+
+// GetStats returns the number of the candidate transactions, the number of the transactions waiting for
+// the transactions with lower sequences, and the total size in bytes of both.
+func (mp *Mempool) GetStats() (numTxs int, numQueuedTxs int, totalBytes int) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	for _, txGroupEl := range *mp.candidateTxs.ElementList() {
+		for _, txEl := range *txGroupEl.(*mempoolTransactionGroup).txs.ElementList() {
+			totalBytes += len(txEl.(*mempoolTransaction).rawTransaction)
+		}
+	}
+	for _, futureTx := range mp.orphans.all() {
+		totalBytes += len(futureTx.rawTransaction)
+	}
+	return mp.size, mp.orphans.len(), totalBytes
+}
+
+// GetPendingTransactions returns all the transactions held in the Mempool, ordered by the fee per byte (high to low).
+// The transactions with the same fee per byte are ordered by account and sequence.
+func (mp *Mempool) GetPendingTransactions() []*PendingTransaction {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	pendingTxs := []*PendingTransaction{}
+	for _, txGroupEl := range *mp.candidateTxs.ElementList() {
+		for _, txEl := range *txGroupEl.(*mempoolTransactionGroup).txs.ElementList() {
+			pendingTxs = append(pendingTxs, newPendingTransaction(txEl.(*mempoolTransaction)))
+		}
+	}
+	for _, futureTx := range mp.orphans.all() {
+		pendingTxs = append(pendingTxs, newQueuedTransaction(futureTx))
+	}
+
+	sort.Slice(pendingTxs, func(i, j int) bool {
+		a, b := pendingTxs[i], pendingTxs[j]
+		if cmp := a.FeePerByte.Cmp(b.FeePerByte); cmp != 0 {
+			return cmp > 0
+		}
+		if cmp := bytes.Compare(a.TxInfo.Address[:], b.TxInfo.Address[:]); cmp != 0 {
+			return cmp < 0
+		}
+		return a.TxInfo.Sequence < b.TxInfo.Sequence
+	})
+	return pendingTxs
+}
+
+// GetPendingTransaction returns the transaction with the given hash held in the Mempool, along with the pending
+// transactions of the same account with lower sequences. It returns false if the transaction is not found.
+func (mp *Mempool) GetPendingTransaction(hash string) (*PendingTransaction, bool) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	for _, txGroupEl := range *mp.candidateTxs.ElementList() {
+		txGroup := txGroupEl.(*mempoolTransactionGroup)
+		for _, txEl := range *txGroup.txs.ElementList() {
+			mptx := txEl.(*mempoolTransaction)
+			if getTransactionHash(mptx.rawTransaction) == hash {
+				pendingTx := newPendingTransaction(mptx)
+				pendingTx.PrecedingRawTxs = txGroup.PrecedingTxs(mptx.txInfo.Sequence)
+				return pendingTx, true
+			}
+		}
+	}
+	for _, futureTx := range mp.orphans.all() {
+		if getTransactionHash(futureTx.rawTransaction) == hash {
+			return newQueuedTransaction(futureTx), true
+		}
+	}
+	return nil, false
+}
+
+func newPendingTransaction(mptx *mempoolTransaction) *PendingTransaction {
+	return &PendingTransaction{
+		Hash:       getTransactionHash(mptx.rawTransaction),
+		RawTx:      mptx.rawTransaction,
+		TxInfo:     mptx.txInfo,
+		FeePerByte: mptx.feePerByte,
+		ReceivedAt: mptx.receivedAt,
+	}
+}
+
+func newQueuedTransaction(futureTx *futureTransaction) *PendingTransaction {
+	return &PendingTransaction{
+		Hash:       getTransactionHash(futureTx.rawTransaction),
+		RawTx:      futureTx.rawTransaction,
+		TxInfo:     futureTx.txInfo,
+		FeePerByte: calculateFeePerByte(futureTx.rawTransaction, futureTx.txInfo),
+		Queued:     true,
+		ReceivedAt: futureTx.receivedAt,
+	}
+}
+
+// AcceptedTransactions returns a channel that will be published with the transactions newly accepted as candidates
+func (mp *Mempool) AcceptedTransactions() chan *PendingTransaction {
+	return mp.acceptedTxs
+}
+
+// MaxSize returns the maximum number of transactions in the Mempool, non-positive means uncapped
+func (mp *Mempool) MaxSize() int {
+	return mp.maxSize
+}
+
 // Flush removes all transactions from the Mempool and the transactionBookkeeper
 func (mp *Mempool) Flush() {
 	mp.mutex.Lock()
@@ -434,6 +1303,14 @@ func (mp *Mempool) Flush() {
 		mp.candidateTxs.Pop()
 	}
 	mp.size = 0
+	mp.orphans.reset()
+	mp.localTxs = make(map[string]common.Bytes)
+	mp.priorityTxs = make(map[string]bool)
+	mp.staleAddresses = make(map[common.Address]bool)
+
+	if mp.journal != nil {
+		mp.compactJournal()
+	}
 }
 
 // BroadcastTx broadcast given raw transaction to the network
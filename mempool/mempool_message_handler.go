@@ -69,6 +69,15 @@ func (mmh *MempoolMessageHandler) HandleMessage(message types.Message) error {
 	rawTx := message.Content.(common.Bytes)
 	logger.Debugf("Received gossiped transaction: %v", hex.EncodeToString(rawTx))
 
+	if err := mmh.mempool.AdmitGossipedTransaction(message.PeerID, rawTx); err != nil {
+		logger.Debugf("Dropped gossiped transaction from peer %v: %v", message.PeerID, err)
+		return nil
+	}
+
+	// Verified before the Mempool is locked, so that the transactions from different peers are
+	// verified concurrently rather than one by one while screened
+	mmh.mempool.PreverifyTransactions([]common.Bytes{rawTx})
+
 	err := mmh.mempool.InsertTransaction(rawTx)
 	if err == DuplicateTxError {
 		return nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/big"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -16,6 +17,7 @@ import (
 	dp "github.com/thetatoken/theta/dispatcher"
 	p2psim "github.com/thetatoken/theta/p2p/simulation"
 	p2ptypes "github.com/thetatoken/theta/p2p/types"
+	p2plmsg "github.com/thetatoken/theta/p2pl/messenger"
 	"github.com/thetatoken/theta/rlp"
 )
 
@@ -391,14 +393,283 @@ func TestMempoolTransactionGossip(t *testing.T) {
 	}
 }
 
+func TestMempoolEviction(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+	mempool.maxSize = 4
+
+	tx1 := createTestRawTx("tx1") // fee per byte: 78, address: A1, seq: 1023
+	tx2 := createTestRawTx("tx2") // fee per byte: 234234, address: A2, seq: 1011
+	tx3 := createTestRawTx("tx3") // fee per byte: 32, address: A3, seq: 2012
+	tx4 := createTestRawTx("tx4") // fee per byte: 525, address: A1, seq: 1000
+	tx5 := createTestRawTx("tx5") // fee per byte: 2392992, address: B1, seq: 1033
+	tx6 := createTestRawTx("tx6") // fee per byte: 32, address: B2, seq: 3023
+
+	assert.Nil(mempool.InsertTransaction(tx1))
+	assert.Nil(mempool.InsertTransaction(tx2))
+	assert.Nil(mempool.InsertTransaction(tx3))
+	assert.Nil(mempool.InsertTransaction(tx4))
+	assert.Equal(4, mempool.Size())
+
+	// tx3 pays the lowest fee per byte, and is evicted to make room for tx5
+	assert.Nil(mempool.InsertTransaction(tx5))
+	assert.Equal(4, mempool.Size())
+
+	// tx6 does not pay more than any of the evictable transactions, i.e. tx1, tx2 and tx5
+	assert.Equal(MempoolFullError, mempool.InsertTransaction(tx6))
+	assert.Equal(4, mempool.Size())
+
+	reapedRawTxs := mempool.Reap(-1)
+	assert.Equal(4, len(reapedRawTxs))
+	assert.Equal("tx5", string(reapedRawTxs[0][:]))
+	assert.Equal("tx2", string(reapedRawTxs[1][:]))
+	assert.Equal("tx4", string(reapedRawTxs[2][:]))
+	assert.Equal("tx1", string(reapedRawTxs[3][:]))
+}
+
+func TestMempoolEvictionRollback(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+	ledger := newSequenceTestLedger().(*SequenceTestLedger)
+	mempool.SetLedger(ledger)
+	mempool.maxSize = 2
+
+	a1 := common.HexToAddress("A1")
+	a4 := common.HexToAddress("A4")
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:1:100")))
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A2:1:150")))
+	assert.Equal(uint64(1), ledger.GetAccountSequence(a1))
+
+	// A1:1 is evicted for A3:1, and no longer counts towards the sequence of A1
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A3:1:200")))
+	assert.Equal(2, mempool.Size())
+	assert.Equal(uint64(0), ledger.GetAccountSequence(a1))
+
+	// The transaction rejected for the lack of room does not count towards the sequence of A4 either
+	assert.Equal(MempoolFullError, mempool.InsertTransaction(createTestRawTx("A4:1:10")))
+	assert.Equal(uint64(0), ledger.GetAccountSequence(a4))
+
+	// A1 can send a transaction with sequence 1 again
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:1:300")))
+	assert.Equal(2, mempool.Size())
+	assert.Equal(uint64(1), ledger.GetAccountSequence(a1))
+
+	reapedRawTxs := mempool.Reap(-1)
+	assert.Equal(2, len(reapedRawTxs))
+	assert.Equal("A1:1:300", string(reapedRawTxs[0][:]))
+	assert.Equal("A3:1:200", string(reapedRawTxs[1][:]))
+}
+
+func TestMempoolFutureTransactions(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+	mempool.SetLedger(newSequenceTestLedger())
+	mempool.maxNumTxsPerAccount = 3
+
+	// A1 expects sequence 1, the transactions with higher sequences are held until the gap is filled
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:3:100")))
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:2:100")))
+	assert.Equal(0, mempool.Size())
+	assert.Equal(2, mempool.orphans.len())
+	assert.Equal(DuplicateTxError, mempool.InsertTransaction(createTestRawTx("A1:3:100")))
+	assert.Equal(ReplacementUnderpricedError, mempool.InsertTransaction(createTestRawTx("A1:3:105")))
+
+	// The sequence too far ahead is rejected
+	assert.NotNil(mempool.InsertTransaction(createTestRawTx("A1:100:100")))
+	assert.Equal(2, mempool.orphans.len())
+
+	// Filling the gap promotes the future transactions
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:1:100")))
+	assert.Equal(3, mempool.Size())
+	assert.Equal(0, mempool.orphans.len())
+
+	// A1 has reached the per-account limit
+	assert.Equal(AccountTxLimitError, mempool.InsertTransaction(createTestRawTx("A1:4:100")))
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A2:1:100")))
+	assert.Equal(4, mempool.Size())
+
+	reapedRawTxs := mempool.Reap(-1)
+	assert.Equal(4, len(reapedRawTxs))
+}
+
+func TestMempoolOrphanPoolEviction(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+	mempool.SetLedger(newSequenceTestLedger())
+	mempool.orphans = newOrphanPool(3)
+
+	// A1 takes up the orphan pool
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:2:100")))
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:3:100")))
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:4:100")))
+	assert.Equal(3, mempool.orphans.len())
+
+	// The highest sequence orphan of A1 is evicted for the orphan of A2
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A2:2:100")))
+	assert.Equal(3, mempool.orphans.len())
+	_, ok := mempool.orphans.get(common.HexToAddress("A1"), 4)
+	assert.False(ok)
+
+	// Nothing is evicted once A1 holds no more orphans than A2 would
+	assert.Equal(FutureTxQueueFullError, mempool.InsertTransaction(createTestRawTx("A2:3:100")))
+	assert.Equal(3, mempool.orphans.len())
+
+	// The orphans are promoted in order as the gaps are filled
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:1:100")))
+	assert.Equal(3, mempool.Size())
+	assert.Equal(1, mempool.orphans.len())
+}
+
+func TestMempoolRevalidation(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+	ledger := newSequenceTestLedger().(*SequenceTestLedger)
+	mempool.SetLedger(ledger)
+
+	tx1 := createTestRawTx("A1:1:100")
+	tx2 := createTestRawTx("A1:2:100")
+	tx3 := createTestRawTx("A1:3:100")
+	assert.Nil(mempool.InsertTransaction(tx3))
+	assert.Nil(mempool.InsertTransaction(tx2))
+	assert.Nil(mempool.InsertTransaction(tx1))
+	assert.Equal(3, mempool.Size())
+
+	// tx1 is committed, tx2 and tx3 are rescreened in the order of their sequences
+	ledger.sequences[common.HexToAddress("A1")] = 1
+	mempool.Update([]common.Bytes{tx1})
+	assert.Equal(2, mempool.Size())
+	assert.Equal(0, mempool.orphans.len())
+
+	// tx2 becomes invalid, tx3 waits for another transaction with sequence 2
+	ledger.sequences[common.HexToAddress("A1")] = 1
+	ledger.invalidTxs[string(tx2)] = true
+	mempool.Update([]common.Bytes{})
+	assert.Equal(0, mempool.Size())
+	assert.Equal(1, mempool.orphans.len())
+	status, _ := mempool.GetTransactionStatus(getTransactionHash(tx2))
+	assert.Equal(TxStatusAbandoned, status)
+
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:2:200")))
+	assert.Equal(2, mempool.Size())
+	assert.Equal(0, mempool.orphans.len())
+}
+
+func TestMempoolBackgroundRevalidation(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+	ledger := newSequenceTestLedger().(*SequenceTestLedger)
+	mempool.SetLedger(ledger)
+
+	a1 := common.HexToAddress("A1")
+	a2 := common.HexToAddress("A2")
+	txA11 := createTestRawTx("A1:1:100")
+	txA21 := createTestRawTx("A2:1:100")
+	assert.Nil(mempool.InsertTransaction(txA11))
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:2:100")))
+	assert.Nil(mempool.InsertTransaction(txA21))
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A2:2:100")))
+
+	// The screened view is reset to the committed block, the remaining transactions are not revalidated right away
+	ledger.sequences[a1] = 1
+	ledger.sequences[a2] = 1
+	mempool.Lock()
+	mempool.UpdateUnsafe([]common.Bytes{txA11, txA21})
+	mempool.Unlock()
+	assert.Equal(2, mempool.Size())
+	assert.True(mempool.staleAddresses[a1])
+	assert.True(mempool.staleAddresses[a2])
+
+	// Inserting a transaction of A1 revalidates the pending transactions of A1 first
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A1:3:100")))
+	assert.Equal(3, mempool.Size())
+	assert.Equal(0, mempool.orphans.len())
+	assert.False(mempool.staleAddresses[a1])
+	assert.True(mempool.staleAddresses[a2])
+
+	// The rest is revalidated in the background
+	ctx, cancel := context.WithCancel(context.Background())
+	mempool.Start(ctx)
+	defer func() {
+		cancel()
+		mempool.Wait()
+	}()
+	ledger.sequences[a1] = 1
+	mempool.Lock()
+	mempool.UpdateUnsafe([]common.Bytes{})
+	mempool.Unlock()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		mempool.Lock()
+		numStale := len(mempool.staleAddresses)
+		mempool.Unlock()
+		if numStale == 0 {
+			break
+		}
+	}
+	mempool.Lock()
+	defer mempool.Unlock()
+	assert.Equal(0, len(mempool.staleAddresses))
+	assert.Equal(3, mempool.Size())
+	assert.Equal(uint64(3), ledger.sequences[a1])
+	assert.Equal(uint64(2), ledger.sequences[a2])
+}
+
+func TestMempoolPriorityLocalTransactions(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+	mempool.SetLedger(newSequenceTestLedger())
+	mempool.maxSize = 2
+	mempool.maxNumPriorityTxs = 1
+
+	assert.Equal(PriorityTxDisabledError, mempool.InsertLocalTransaction(createTestRawTx("A1:1:100"), true))
+	mempool.priorityLocalTxs = true
+
+	assert.Nil(mempool.InsertLocalTransaction(createTestRawTx("A1:1:100"), true))
+	assert.Nil(mempool.InsertLocalTransaction(createTestRawTx("A2:1:50"), false))
+
+	// The priority transaction is never evicted, even if it pays a lower fee
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A3:1:1000")))
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("A4:1:2000")))
+	assert.Equal(2, mempool.Size())
+
+	// The priority transaction is admitted even if no transaction can be evicted
+	assert.Nil(mempool.InsertLocalTransaction(createTestRawTx("A5:1:10"), true))
+	assert.Equal(3, mempool.Size())
+	assert.Equal(MempoolFullError, mempool.InsertTransaction(createTestRawTx("A6:1:1")))
+
+	// The priority lane is full
+	assert.Equal(MempoolFullError, mempool.InsertLocalTransaction(createTestRawTx("A7:1:10"), true))
+	assert.Equal(3, mempool.Size())
+
+	reapedRawTxs := mempool.Reap(-1)
+	assert.Equal(3, len(reapedRawTxs))
+	assert.Equal("A4:1:2000", string(reapedRawTxs[0][:]))
+	assert.Equal("A1:1:100", string(reapedRawTxs[1][:]))
+	assert.Equal("A5:1:10", string(reapedRawTxs[2][:]))
+}
+
 // --------------- Test Utilities --------------- //
 
 func newTestMempool(peerID string, simnet *p2psim.Simnet) (*Mempool, context.Context) {
 	ctx := context.Background()
 
 	messenger := simnet.AddEndpoint(peerID)
-	dispatcher := dp.NewDispatcher(messenger, nil)
-	mempool := CreateMempool(dispatcher)
+	// The dispatcher skips the libp2p network only if it is a typed nil
+	dispatcher := dp.NewDispatcher(messenger, (*p2plmsg.Messenger)(nil))
+	mempool := CreateMempool(dispatcher, nil)
 	mempool.SetLedger(newTestLedger())
 	txMsgHandler := CreateMempoolMessageHandler(mempool)
 	messenger.RegisterMessageHandler(txMsgHandler)
@@ -460,9 +731,14 @@ func (tl *TestLedger) ScreenTxUnsafe(rawTx common.Bytes) result.Result {
 	return res
 }
 
+func (tl *TestLedger) ResetScreenedAccountUnsafe(address common.Address) {
+}
+
 func (tl *TestLedger) ScreenTx(rawTx common.Bytes) (*core.TxInfo, result.Result) {
+	effectiveGasPrice := new(big.Int).SetUint64(tl.effectiveGasPriceList[tl.counter])
 	txInfo := &core.TxInfo{
-		EffectiveGasPrice: new(big.Int).SetUint64(tl.effectiveGasPriceList[tl.counter]),
+		EffectiveGasPrice: effectiveGasPrice,
+		Fee:               new(big.Int).Mul(effectiveGasPrice, big.NewInt(int64(len(rawTx)))), // fee per byte equals the gas price
 		Address:           common.HexToAddress(tl.addressList[tl.counter]),
 		Sequence:          tl.sequenceList[tl.counter],
 	}
@@ -470,6 +746,21 @@ func (tl *TestLedger) ScreenTx(rawTx common.Bytes) (*core.TxInfo, result.Result)
 	return txInfo, result.OK
 }
 
+func (tl *TestLedger) ScreenTxReplacement(rawTx common.Bytes, precedingRawTxs []common.Bytes) (*core.TxInfo, result.Result) {
+	return tl.ScreenTx(rawTx)
+}
+
+func (tl *TestLedger) GetTxInfo(rawTx common.Bytes) (*core.TxInfo, result.Result) {
+	return nil, result.Error("Not supported")
+}
+
+func (tl *TestLedger) PreverifyTxSignatures(rawTxs []common.Bytes) {
+}
+
+func (tl *TestLedger) GetAccountSequence(address common.Address) uint64 {
+	return 0
+}
+
 func (tl *TestLedger) GetCurrentBlock() *core.Block {
 	return nil
 }
@@ -482,7 +773,7 @@ func (tl *TestLedger) ApplyBlockTxs(block *core.Block) result.Result {
 	return result.OK
 }
 
-func (tl *TestLedger) ResetState(height uint64, rootHash common.Hash) result.Result {
+func (tl *TestLedger) ResetState(block *core.Block) result.Result {
 	return result.OK
 }
 
@@ -498,6 +789,14 @@ func (tl *TestLedger) GetGuardianCandidatePool(blockHash common.Hash) (*core.Gua
 	return nil, nil
 }
 
+func (tl *TestLedger) GetEliteEdgeNodePoolOfLastCheckpoint(blockHash common.Hash) (core.EliteEdgeNodePool, error) {
+	return nil, nil
+}
+
+func (tl *TestLedger) GetValidatorSigningAddress(blockHash common.Hash, validator common.Address, epoch uint64) (common.Address, error) {
+	return validator, nil
+}
+
 func (tl *TestLedger) PruneState(endHeight uint64) error {
 	return nil
 }
@@ -506,6 +805,61 @@ func (tl *TestLedger) ApplyBlockTxsForChainCorrection(block *core.Block) (common
 	return common.Hash{}, result.Result{}
 }
 
+// SequenceTestLedger screens the raw transactions of the form "address:sequence:gasPrice" against the
+// account sequences, which are advanced by the transactions that passed the screening
+type SequenceTestLedger struct {
+	TestLedger
+	sequences          map[common.Address]uint64
+	committedSequences map[common.Address]uint64
+	invalidTxs         map[string]bool
+}
+
+func newSequenceTestLedger() core.Ledger {
+	return &SequenceTestLedger{
+		sequences:          make(map[common.Address]uint64),
+		committedSequences: make(map[common.Address]uint64),
+		invalidTxs:         make(map[string]bool),
+	}
+}
+
+func (tl *SequenceTestLedger) ResetScreenedAccountUnsafe(address common.Address) {
+	tl.sequences[address] = tl.committedSequences[address]
+}
+
+func (tl *SequenceTestLedger) GetTxInfo(rawTx common.Bytes) (*core.TxInfo, result.Result) {
+	fields := strings.Split(string(rawTx), ":")
+	sequence, _ := strconv.ParseUint(fields[1], 10, 64)
+	gasPrice, _ := strconv.ParseInt(fields[2], 10, 64)
+	return &core.TxInfo{
+		EffectiveGasPrice: big.NewInt(gasPrice),
+		Fee:               big.NewInt(gasPrice * int64(len(rawTx))),
+		Address:           common.HexToAddress(fields[0]),
+		Sequence:          sequence,
+	}, result.OK
+}
+
+func (tl *SequenceTestLedger) ScreenTx(rawTx common.Bytes) (*core.TxInfo, result.Result) {
+	txInfo, _ := tl.GetTxInfo(rawTx)
+	if tl.invalidTxs[string(rawTx)] {
+		return nil, result.Error("Insufficient fund").WithErrorCode(result.CodeInsufficientFund)
+	}
+	if txInfo.Sequence != tl.sequences[txInfo.Address]+1 {
+		return nil, result.Error("Got %v, expected %v", txInfo.Sequence, tl.sequences[txInfo.Address]+1).
+			WithErrorCode(result.CodeInvalidSequence)
+	}
+	tl.sequences[txInfo.Address] = txInfo.Sequence
+	return txInfo, result.OK
+}
+
+func (tl *SequenceTestLedger) ScreenTxUnsafe(rawTx common.Bytes) result.Result {
+	_, res := tl.ScreenTx(rawTx)
+	return res
+}
+
+func (tl *SequenceTestLedger) GetAccountSequence(address common.Address) uint64 {
+	return tl.sequences[address]
+}
+
 type TestNetworkMessageInterceptor struct {
 	lock             *sync.Mutex
 	ReceivedMessages chan p2ptypes.Message
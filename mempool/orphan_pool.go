@@ -0,0 +1,168 @@
+package mempool
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+// futureTransaction is a transaction whose sequence is ahead of the next expected sequence of its
+// account. It is held in the orphan pool until the transactions filling the sequence gap arrive.
+type futureTransaction struct {
+	rawTransaction common.Bytes
+	txInfo         *core.TxInfo
+	receivedAt     time.Time
+}
+
+func (ft *futureTransaction) IsOutdated(txLife time.Duration) bool {
+	return time.Since(ft.receivedAt) > txLife
+}
+
+//
+// orphanPool holds the orphan transactions, i.e. the transactions which depend on transactions of the same
+// account that have neither been received nor committed yet, since their sequences are ahead of the next
+// expected sequences of their accounts. The orphans are keyed by account and sequence, so that the Mempool
+// can promote them in the order of their sequences as the gaps are filled. The pool is bounded, and when it
+// is full an orphan of the account holding the most orphans is evicted, so that a single account cannot take
+// up the pool.
+//
+type orphanPool struct {
+	txs     map[common.Address]map[uint64]*futureTransaction
+	size    int
+	maxSize int // non-positive means uncapped
+}
+
+func newOrphanPool(maxSize int) *orphanPool {
+	return &orphanPool{
+		txs:     make(map[common.Address]map[uint64]*futureTransaction),
+		maxSize: maxSize,
+	}
+}
+
+// add holds the orphan transaction. An orphan of the same account and sequence is replaced if the new one
+// pays a sufficiently higher gas price.
+func (op *orphanPool) add(tx *futureTransaction) error {
+	address, sequence := tx.txInfo.Address, tx.txInfo.Sequence
+	orphans := op.txs[address]
+	if queuedTx, ok := orphans[sequence]; ok {
+		if bytes.Equal(queuedTx.rawTransaction, tx.rawTransaction) {
+			return DuplicateTxError
+		}
+		if tx.txInfo.EffectiveGasPrice.Cmp(minReplacementGasPrice(queuedTx.txInfo.EffectiveGasPrice)) < 0 {
+			return ReplacementUnderpricedError
+		}
+		orphans[sequence] = tx
+		logger.Infof("Replace future tx, tx.hash: 0x%v, replaced tx.hash: 0x%v",
+			getTransactionHash(tx.rawTransaction), getTransactionHash(queuedTx.rawTransaction))
+		return nil
+	}
+
+	if op.maxSize > 0 && op.size >= op.maxSize && !op.evictFor(address) {
+		return FutureTxQueueFullError
+	}
+	if orphans == nil {
+		orphans = make(map[uint64]*futureTransaction)
+		op.txs[address] = orphans
+	}
+	orphans[sequence] = tx
+	op.size++
+	logger.Infof("Queue future tx, tx.hash: 0x%v, sequence: %v", getTransactionHash(tx.rawTransaction), sequence)
+
+	return nil
+}
+
+// evictFor evicts the highest sequence orphan of the account holding the most orphans, to make room for an
+// orphan of the given account. Nothing is evicted unless the other account would still hold at least as many
+// orphans as the given account afterwards. It returns false if no orphan is evicted.
+func (op *orphanPool) evictFor(address common.Address) bool {
+	var victim common.Address
+	most := len(op.txs[address]) + 1
+	for account, orphans := range op.txs {
+		if len(orphans) > most {
+			victim = account
+			most = len(orphans)
+		}
+	}
+	if most == len(op.txs[address])+1 {
+		return false
+	}
+
+	sorted := op.sortedTxs(victim)
+	evicted := sorted[len(sorted)-1]
+	op.remove(victim, evicted.txInfo.Sequence)
+	logger.Infof("Evict future tx, tx.hash: 0x%v", getTransactionHash(evicted.rawTransaction))
+	return true
+}
+
+// get returns the orphan of the given account and sequence
+func (op *orphanPool) get(address common.Address, sequence uint64) (*futureTransaction, bool) {
+	tx, ok := op.txs[address][sequence]
+	return tx, ok
+}
+
+// remove removes the orphan of the given account and sequence
+func (op *orphanPool) remove(address common.Address, sequence uint64) {
+	orphans := op.txs[address]
+	if _, ok := orphans[sequence]; !ok {
+		return
+	}
+	delete(orphans, sequence)
+	op.size--
+	if len(orphans) == 0 {
+		delete(op.txs, address)
+	}
+}
+
+// prune drops the orphans of the account superseded by the committed transactions, i.e. with sequences
+// lower than nextSequence, and the ones held for longer than txLife
+func (op *orphanPool) prune(address common.Address, nextSequence uint64, txLife time.Duration) {
+	for sequence, tx := range op.txs[address] {
+		if sequence < nextSequence || tx.IsOutdated(txLife) {
+			op.remove(address, sequence)
+		}
+	}
+}
+
+// addresses returns the accounts holding orphans
+func (op *orphanPool) addresses() []common.Address {
+	addresses := make([]common.Address, 0, len(op.txs))
+	for address := range op.txs {
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// sortedTxs returns the orphans of the account in ascending order of sequences
+func (op *orphanPool) sortedTxs(address common.Address) []*futureTransaction {
+	sorted := make([]*futureTransaction, 0, len(op.txs[address]))
+	for _, tx := range op.txs[address] {
+		sorted = append(sorted, tx)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].txInfo.Sequence < sorted[j].txInfo.Sequence
+	})
+	return sorted
+}
+
+// all returns all the orphans, the orphans of each account in ascending order of sequences
+func (op *orphanPool) all() []*futureTransaction {
+	txs := make([]*futureTransaction, 0, op.size)
+	for address := range op.txs {
+		txs = append(txs, op.sortedTxs(address)...)
+	}
+	return txs
+}
+
+// len returns the number of orphans
+func (op *orphanPool) len() int {
+	return op.size
+}
+
+// reset drops all the orphans
+func (op *orphanPool) reset() {
+	op.txs = make(map[common.Address]map[uint64]*futureTransaction)
+	op.size = 0
+}
@@ -12,7 +12,8 @@ import (
 
 const defaultMaxNumTxs = uint(200000)
 
-const maxTxLife = 1 * time.Minute
+// defaultTxLife is the time a transaction record is kept if the lifetime is not configured
+const defaultTxLife = 1 * time.Minute
 
 //
 // transactionBookkeeper keeps tracks of recently seen transactions
@@ -24,6 +25,7 @@ type transactionBookkeeper struct {
 	txList list.List            // FIFO list of transaction hashes
 
 	maxNumTxs uint
+	txLife    time.Duration // time after which a transaction record is removed
 }
 
 type TxRecord struct {
@@ -32,8 +34,8 @@ type TxRecord struct {
 	CreatedAt time.Time
 }
 
-func (r *TxRecord) IsOutdated() bool {
-	return time.Since(r.CreatedAt) > maxTxLife
+func (r *TxRecord) IsOutdated(txLife time.Duration) bool {
+	return time.Since(r.CreatedAt) > txLife
 }
 
 type TxStatus int
@@ -43,11 +45,15 @@ const (
 	TxStatusAbandoned
 )
 
-func createTransactionBookkeeper(maxNumTxs uint) transactionBookkeeper {
+func createTransactionBookkeeper(maxNumTxs uint, txLife time.Duration) transactionBookkeeper {
+	if txLife <= 0 {
+		txLife = defaultTxLife
+	}
 	return transactionBookkeeper{
 		mutex:     &sync.Mutex{},
 		txMap:     make(map[string]*TxRecord),
 		maxNumTxs: maxNumTxs,
+		txLife:    txLife,
 	}
 }
 
@@ -94,7 +100,7 @@ func (tb *transactionBookkeeper) removeOutdatedTxsUnsafe() {
 			return
 		}
 		txRecord := el.Value.(*TxRecord)
-		if !txRecord.IsOutdated() {
+		if !txRecord.IsOutdated(tb.txLife) {
 			return
 		}
 
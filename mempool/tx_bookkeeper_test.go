@@ -2,6 +2,7 @@ package mempool
 
 import (
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/thetatoken/theta/common"
@@ -25,7 +26,7 @@ func TestTxBookkeeper(t *testing.T) {
 	log.Infof("tx5 hash: %v", getTransactionHash(tx5))
 
 	maxNumTxs := uint(3)
-	txb := createTransactionBookkeeper(maxNumTxs)
+	txb := createTransactionBookkeeper(maxNumTxs, defaultTxLife)
 	assert.False(txb.hasSeen(tx1))
 	assert.False(txb.hasSeen(tx3))
 	assert.False(txb.hasSeen(tx5))
@@ -54,6 +55,20 @@ func TestTxBookkeeper(t *testing.T) {
 	assert.False(txb.hasSeen(tx5))
 }
 
+func TestTxBookkeeperTxLife(t *testing.T) {
+	assert := assert.New(t)
+
+	tx1 := createTestRawTx("1")
+	txb := createTransactionBookkeeper(uint(3), 50*time.Millisecond)
+	assert.True(txb.record(tx1))
+	assert.True(txb.hasSeen(tx1))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.False(txb.hasSeen(tx1)) // tx1 should have expired
+	_, exists := txb.getStatus(getTransactionHash(tx1))
+	assert.False(exists)
+}
+
 // --------------- Test Utilities --------------- //
 
 func createTestRawTx(rawTxStr string) common.Bytes {
@@ -13,6 +13,8 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/diagnostic"
+	"github.com/thetatoken/theta/common/metrics"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/dispatcher"
@@ -141,6 +143,27 @@ type RequestManager struct {
 	aplock         *sync.RWMutex
 
 	reporter *rp.Reporter
+
+	metrics requestMetrics
+}
+
+// requestMetrics are the sync statistics, registered as "sync/..."
+type requestMetrics struct {
+	pendingBlocks  metrics.Gauge
+	pendingHeaders metrics.Gauge
+	activePeers    metrics.Gauge
+	blocksReq      metrics.Counter
+	blocksRecv     metrics.Counter
+}
+
+func newRequestMetrics(reg metrics.Registry) requestMetrics {
+	return requestMetrics{
+		pendingBlocks:  reg.GetOrRegister("pending_blocks", &metrics.StandardGauge{}).(metrics.Gauge),
+		pendingHeaders: reg.GetOrRegister("pending_headers", &metrics.StandardGauge{}).(metrics.Gauge),
+		activePeers:    reg.GetOrRegister("active_peers", &metrics.StandardGauge{}).(metrics.Gauge),
+		blocksReq:      reg.GetOrRegister("blocks_requested", &metrics.StandardCounter{}).(metrics.Counter),
+		blocksRecv:     reg.GetOrRegister("blocks_received", &metrics.StandardCounter{}).(metrics.Counter),
+	}
 }
 
 func NewRequestManager(syncMgr *SyncManager, reporter *rp.Reporter) *RequestManager {
@@ -175,6 +198,8 @@ func NewRequestManager(syncMgr *SyncManager, reporter *rp.Reporter) *RequestMana
 		aplock:         &sync.RWMutex{},
 
 		reporter: reporter,
+
+		metrics: newRequestMetrics(metrics.ModuleRegistry("sync")),
 	}
 
 	logger := util.GetLoggerForModule("request")
@@ -188,6 +213,7 @@ func NewRequestManager(syncMgr *SyncManager, reporter *rp.Reporter) *RequestMana
 
 func (rm *RequestManager) mainLoop() {
 	defer rm.wg.Done()
+	defer diagnostic.Recover()
 
 	for {
 		select {
@@ -248,6 +274,7 @@ func (rm *RequestManager) AddActivePeer(activePeerID string) {
 	}
 
 	rm.activePeers[activePeerID] = MaxPeerActiveScore
+	rm.metrics.activePeers.Update(int64(len(rm.activePeers)))
 	rm.logger.Debugf("Active peer added: %v", activePeerID)
 }
 
@@ -298,6 +325,10 @@ func (rm *RequestManager) tryToDownload() {
 
 	rm.gossipQuota = GossipRequestQuotaPerSecond
 	rm.fastsyncQuota = FastsyncRequestQuota
+	if rm.syncMgr.resources.UnderPressure() {
+		// Only request one block at a time, to keep up with the chain without prefetching
+		rm.fastsyncQuota = 1
+	}
 
 	hasUndownloadedBlocks := rm.pendingBlocks.Len() > 0 || len(rm.pendingBlocksByHash) > 0 || rm.pendingBlocksWithHeader.Len() > 0
 
@@ -337,6 +368,9 @@ func (rm *RequestManager) tryToDownload() {
 		}
 	}
 	rm.pendingBlocksWithHeader = newQ
+
+	rm.metrics.pendingBlocks.Update(int64(rm.pendingBlocks.Len()))
+	rm.metrics.pendingHeaders.Update(int64(rm.pendingBlocksWithHeader.Len()))
 }
 
 //compatible with older version, download block from hash
@@ -383,6 +417,7 @@ func (rm *RequestManager) downloadBlockFromHash() {
 				"peer":            randomPeerID,
 			}).Debug("Sending data request from hash")
 			rm.syncMgr.dispatcher.GetData([]string{randomPeerID}, request)
+			rm.metrics.blocksReq.Inc(1)
 			pendingBlock.UpdateTimestamp()
 			pendingBlock.status = RequestWaitingDataResp
 
@@ -604,6 +639,7 @@ func (rm *RequestManager) sendBlocksRequest(peerID string, entries []string) {
 		"peer":            peerID,
 	}).Debug("Sending data request from header")
 	rm.syncMgr.dispatcher.GetData([]string{peerID}, request)
+	rm.metrics.blocksReq.Inc(int64(len(entries)))
 }
 
 func (rm *RequestManager) removeEl(el *list.Element) {
@@ -713,6 +749,8 @@ func (rm *RequestManager) AddBlock(block *core.Block) {
 		return
 	}
 
+	rm.metrics.blocksRecv.Inc(1)
+
 	hash := block.Hash().String()
 
 	if pendingBlockEl, ok := rm.pendingBlocksByHash[hash]; ok {
@@ -11,6 +11,9 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/diagnostic"
+	"github.com/thetatoken/theta/common/resource"
+	"github.com/thetatoken/theta/common/tracing"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/dispatcher"
@@ -23,7 +26,7 @@ import (
 
 const voteCacheLimit = 512
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "netsync"})
+var logger = util.GetLoggerForModule("netsync")
 
 type MessageConsumer interface {
 	AddMessage(interface{})
@@ -59,6 +62,8 @@ type SyncManager struct {
 	logger *log.Entry
 
 	voteCache *lru.Cache // Cache for votes
+
+	resources *resource.Monitor // the block prefetching is throttled when the resources are low
 }
 
 func NewSyncManager(chain *blockchain.Chain, cons core.ConsensusEngine, networkOld p2p.Network, network p2pl.Network, disp *dispatcher.Dispatcher, consumer MessageConsumer, reporter *rp.Reporter) *SyncManager {
@@ -95,6 +100,11 @@ func NewSyncManager(chain *blockchain.Chain, cons core.ConsensusEngine, networkO
 	return sm
 }
 
+// SetResourceMonitor sets the monitor which throttles the block prefetching under resource pressure.
+func (sm *SyncManager) SetResourceMonitor(resources *resource.Monitor) {
+	sm.resources = resources
+}
+
 func (sm *SyncManager) Start(ctx context.Context) {
 	c, cancel := context.WithCancel(ctx)
 	sm.ctx = c
@@ -117,6 +127,7 @@ func (sm *SyncManager) Wait() {
 
 func (sm *SyncManager) mainLoop() {
 	defer sm.wg.Done()
+	defer diagnostic.Recover()
 
 	for {
 		select {
@@ -723,6 +734,10 @@ func (sm *SyncManager) handleHeader(header *core.BlockHeader, peerID []string) {
 }
 
 func (sm *SyncManager) handleBlock(block *core.Block) {
+	_, span := tracing.StartBlockSpan(context.Background(), "sync.receive_block", block.Hash())
+	span.SetAttribute("block.height", block.Height)
+	defer span.Finish()
+
 	if eb, err := sm.chain.FindBlock(block.Hash()); err == nil && !eb.Status.IsPending() {
 		sm.logger.WithFields(log.Fields{
 			"block hash":   block.Hash().String(),
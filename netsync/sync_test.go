@@ -186,6 +186,7 @@ func NewMockConsensus(chain *blockchain.Chain, lfb *core.ExtendedBlock) *MockCon
 
 // ID() string
 // PrivateKey() *crypto.PrivateKey
+// Signer() crypto.Signer
 // GetTip(includePendingBlockingLeaf bool) *ExtendedBlock
 // GetEpoch() uint64
 // GetLedger() Ledger
@@ -201,6 +202,10 @@ func (c *MockConsensus) PrivateKey() *crypto.PrivateKey {
 	return nil
 }
 
+func (c *MockConsensus) Signer() crypto.Signer {
+	return nil
+}
+
 func (c *MockConsensus) GetTip(includePendingBlockingLeaf bool) *core.ExtendedBlock {
 	return nil
 }
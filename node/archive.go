@@ -0,0 +1,104 @@
+package node
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/store"
+	"github.com/thetatoken/theta/store/database"
+)
+
+// archiveProgressInterval is how often the progress of the archive check is logged, in blocks.
+const archiveProgressInterval = 100000
+
+func archiveMetaKey() common.Bytes {
+	return common.Bytes("/archive")
+}
+
+// archiveMeta records the history verified to be complete in the data directory
+type archiveMeta struct {
+	GenesisHash    common.Hash
+	VerifiedHeight uint64
+}
+
+// checkArchive verifies that the data directory holds the full history of the chain, i.e. the
+// finalized block and its state at every height since the genesis, and that the search index
+// covers all these blocks. The heights verified by a previous start are skipped, so only the
+// first start in archive mode scans the whole chain. The search index is backfilled if it lags
+// behind the blocks, e.g. if it was disabled before.
+func checkArchive(kvstore store.Store, chain *blockchain.Chain, db database.Database, root *core.Block,
+	lastFinalized *core.ExtendedBlock, readOnly bool) error {
+	if root.Height != core.GenesisBlockHeight {
+		return fmt.Errorf("The data directory was bootstrapped from the snapshot at height %v, an archive node needs to be synced from the genesis", root.Height)
+	}
+	if state.NewStoreView(root.Height, root.StateHash, db) == nil {
+		return fmt.Errorf("The genesis state is missing")
+	}
+
+	meta := &archiveMeta{}
+	err := kvstore.Get(archiveMetaKey(), meta)
+	if err == store.ErrKeyNotFound {
+		meta = &archiveMeta{GenesisHash: root.Hash(), VerifiedHeight: root.Height}
+		log.Infof("Verifying the history of the data directory up to height %v for the archive mode, it might take a while", lastFinalized.Height)
+	} else if err != nil {
+		return err
+	}
+	if meta.GenesisHash != root.Hash() {
+		return fmt.Errorf("The archive was built from the genesis %v, but the node starts from %v", meta.GenesisHash.Hex(), root.Hash().Hex())
+	}
+
+	firstIndexed, lastIndexed, indexed := chain.SearchIndexRange()
+	if !indexed {
+		lastIndexed = root.Height
+	} else if firstIndexed > root.Height+1 {
+		return fmt.Errorf("The search index starts at height %v, it needs to be rebuilt from the genesis", firstIndexed)
+	}
+
+	start := meta.VerifiedHeight + 1
+	if lastIndexed+1 < start {
+		start = lastIndexed + 1
+	}
+	for height := start; height <= lastFinalized.Height; height++ {
+		block := findFinalizedBlock(chain, height)
+		if block == nil {
+			return fmt.Errorf("The finalized block at height %v is missing", height)
+		}
+		if height > meta.VerifiedHeight && state.NewStoreView(height, block.StateHash, db) == nil {
+			return fmt.Errorf("The state at height %v is missing, it might have been pruned", height)
+		}
+		if height > lastIndexed {
+			if readOnly {
+				return fmt.Errorf("The search index ends at height %v, it needs to be backfilled by starting the node in archive mode without --read_only", lastIndexed)
+			}
+			chain.AddBlockToSearchIndex(block)
+		}
+		if height%archiveProgressInterval == 0 {
+			log.Infof("Verified the history up to height %v", height)
+		}
+	}
+
+	if lastFinalized.Height > meta.VerifiedHeight {
+		meta.VerifiedHeight = lastFinalized.Height
+	}
+	if !readOnly {
+		if err := kvstore.Put(archiveMetaKey(), *meta); err != nil {
+			return err
+		}
+	}
+	log.Infof("Verified the full history of the chain up to height %v", meta.VerifiedHeight)
+	return nil
+}
+
+// findFinalizedBlock returns the finalized block at the given height, or nil if not found
+func findFinalizedBlock(chain *blockchain.Chain, height uint64) *core.ExtendedBlock {
+	for _, block := range chain.FindBlocksByHeight(height) {
+		if block.Status.IsFinalized() {
+			return block
+		}
+	}
+	return nil
+}
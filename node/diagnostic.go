@@ -0,0 +1,35 @@
+package node
+
+import (
+	"github.com/thetatoken/theta/common"
+)
+
+// diagnosticStatus is the state of the chain included in the diagnostic bundles.
+type diagnosticStatus struct {
+	ChainID             string      `json:"chain_id"`
+	ReadOnly            bool        `json:"read_only"`
+	LastFinalizedHeight uint64      `json:"last_finalized_height"`
+	LastFinalizedHash   common.Hash `json:"last_finalized_hash"`
+	HighestCCHeight     uint64      `json:"highest_cc_height"`
+	HighestCCHash       common.Hash `json:"highest_cc_hash"`
+	Epoch               uint64      `json:"epoch"`
+	NumPeers            int         `json:"num_peers"`
+	UnderPressure       bool        `json:"under_resource_pressure"`
+}
+
+// DiagnosticStatus returns the state of the chain for the diagnostic bundles.
+func (n *Node) DiagnosticStatus() interface{} {
+	lastFinalized := n.Consensus.GetLastFinalizedBlock()
+	highestCC := n.Consensus.State().GetHighestCCBlock()
+	return &diagnosticStatus{
+		ChainID:             n.Chain.ChainID,
+		ReadOnly:            n.readOnly,
+		LastFinalizedHeight: lastFinalized.Height,
+		LastFinalizedHash:   lastFinalized.Hash(),
+		HighestCCHeight:     highestCC.Height,
+		HighestCCHash:       highestCC.Hash(),
+		Epoch:               n.Consensus.GetEpoch(),
+		NumPeers:            len(n.Dispatcher.Peers(false)),
+		UnderPressure:       n.resources.UnderPressure(),
+	}
+}
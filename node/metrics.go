@@ -0,0 +1,129 @@
+package node
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/common/metrics/prometheus"
+)
+
+// metricsSampleInterval is how often the node-wide gauges are refreshed.
+const metricsSampleInterval = 10 * time.Second
+
+// nodeMetrics samples the node-wide gauges that are not updated by the modules themselves,
+// and serves all the metrics of the DefaultRegistry in the Prometheus format if configured.
+type nodeMetrics struct {
+	node   *Node
+	dbPath string // empty if the database size is not reported
+
+	height          metrics.Gauge
+	finalizedHeight metrics.Gauge
+	finalizationLag metrics.Gauge
+	peers           metrics.Gauge
+	mempoolSize     metrics.Gauge
+	mempoolQueued   metrics.Gauge
+	mempoolBytes    metrics.Gauge
+	dbSize          metrics.Gauge
+}
+
+func newNodeMetrics(node *Node, dbPath string) *nodeMetrics {
+	gauge := func(module string, name string) metrics.Gauge {
+		return metrics.ModuleRegistry(module).GetOrRegister(name, &metrics.StandardGauge{}).(metrics.Gauge)
+	}
+
+	return &nodeMetrics{
+		node:   node,
+		dbPath: dbPath,
+
+		height:          gauge("chain", "height"),
+		finalizedHeight: gauge("chain", "finalized_height"),
+		finalizationLag: gauge("chain", "finalization_lag"),
+		peers:           gauge("p2p", "peers"),
+		mempoolSize:     gauge("mempool", "size"),
+		mempoolQueued:   gauge("mempool", "queued"),
+		mempoolBytes:    gauge("mempool", "bytes"),
+		dbSize:          gauge("store", "db_size_bytes"),
+	}
+}
+
+// Start starts the sampling loop and the Prometheus listener, both stop when ctx is done.
+func (m *nodeMetrics) Start(ctx context.Context) {
+	go m.mainLoop(ctx)
+
+	address := viper.GetString(common.CfgMetricsPrometheusAddress)
+	if address == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prometheus.Handler(metrics.DefaultRegistry))
+	server := &http.Server{Addr: address, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Failed to serve the metrics at %v: %v", address, err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+}
+
+func (m *nodeMetrics) mainLoop(ctx context.Context) {
+	ticker := time.NewTicker(metricsSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		m.sample()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *nodeMetrics) sample() {
+	tip := m.node.Consensus.GetTip(true)
+	lfb := m.node.Consensus.GetLastFinalizedBlock()
+	m.height.Update(int64(tip.Height))
+	m.finalizedHeight.Update(int64(lfb.Height))
+	if tip.Height > lfb.Height {
+		m.finalizationLag.Update(int64(tip.Height - lfb.Height))
+	} else {
+		m.finalizationLag.Update(0)
+	}
+
+	m.peers.Update(int64(len(m.node.Dispatcher.Peers(false))))
+
+	numTxs, numQueuedTxs, totalBytes := m.node.Mempool.GetStats()
+	m.mempoolSize.Update(int64(numTxs))
+	m.mempoolQueued.Update(int64(numQueuedTxs))
+	m.mempoolBytes.Update(int64(totalBytes))
+
+	if m.dbPath != "" {
+		m.dbSize.Update(dirSize(m.dbPath))
+	}
+}
+
+// dirSize returns the total size of the files under the directory. The files removed
+// during the walk, e.g. by a database compaction, are skipped.
+func dirSize(dir string) int64 {
+	size := int64(0)
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,27 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirSize(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "node_metrics")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	require.Nil(os.MkdirAll(filepath.Join(dir, "main"), 0700))
+	require.Nil(ioutil.WriteFile(filepath.Join(dir, "CURRENT"), make([]byte, 16), 0600))
+	require.Nil(ioutil.WriteFile(filepath.Join(dir, "main", "000001.ldb"), make([]byte, 1000), 0600))
+
+	assert.Equal(int64(1016), dirSize(dir))
+	assert.Equal(int64(0), dirSize(filepath.Join(dir, "missing")))
+}
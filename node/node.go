@@ -2,18 +2,22 @@ package node
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"path"
 	"reflect"
 	"sync"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/resource"
 	"github.com/thetatoken/theta/consensus"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
 	dp "github.com/thetatoken/theta/dispatcher"
 	ld "github.com/thetatoken/theta/ledger"
+	"github.com/thetatoken/theta/ledger/statediff"
 	mp "github.com/thetatoken/theta/mempool"
 	"github.com/thetatoken/theta/netsync"
 	"github.com/thetatoken/theta/p2p"
@@ -23,6 +27,7 @@ import (
 	"github.com/thetatoken/theta/snapshot"
 	"github.com/thetatoken/theta/store"
 	"github.com/thetatoken/theta/store/database"
+	"github.com/thetatoken/theta/store/database/backend"
 	"github.com/thetatoken/theta/store/kvstore"
 	"github.com/thetatoken/theta/store/rollingdb"
 )
@@ -38,6 +43,15 @@ type Node struct {
 	Mempool          *mp.Mempool
 	RPC              *rpc.ThetaRPCServer
 	reporter         *rp.Reporter
+	metrics          *nodeMetrics
+	rollingDB        *rollingdb.RollingDB
+	resources        *resource.Monitor
+	stateDiffs       *statediff.Exporter
+	webhooks         *webhookWatcher
+
+	// In read-only mode the node only serves RPC queries from checkpoints of the data directory of another node
+	readOnly     bool
+	checkpointDB *backend.CheckpointDatabase
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -49,7 +63,9 @@ type Node struct {
 
 type Params struct {
 	ChainID             string
+	DataPath            string // the data directory, used to report the size of the databases
 	PrivateKey          *crypto.PrivateKey
+	Signer              crypto.Signer // signs the votes and blocks, the PrivateKey is used if nil
 	Root                *core.Block
 	NetworkOld          p2p.Network
 	Network             p2pl.Network
@@ -58,26 +74,45 @@ type Params struct {
 	SnapshotPath        string
 	ChainImportDirPath  string
 	ChainCorrectionPath string
+	MempoolJournalPath  string // empty means the mempool journal is disabled
+	ReadOnly            bool   // requires DB to be a *backend.CheckpointDatabase
 }
 
 func NewNode(params *Params) *Node {
 	store := kvstore.NewKVStore(params.DB)
 	chain := blockchain.NewChain(params.ChainID, store, params.Root)
+	if viper.GetBool(common.CfgStorageSearchIndexEnabled) {
+		chain.EnableSearchIndex()
+	}
 	params.RollingDB.SetChain(chain)
 
 	validatorManager := consensus.NewRotatingValidatorManager()
 	dispatcher := dp.NewDispatcher(params.NetworkOld, params.Network)
-	consensus := consensus.NewConsensusEngine(params.PrivateKey, store, chain, dispatcher, validatorManager)
+	var signer crypto.Signer = params.PrivateKey
+	if params.Signer != nil {
+		signer = params.Signer
+	}
+	consensus := consensus.NewConsensusEngine(signer, store, chain, dispatcher, validatorManager)
 	reporter := rp.NewReporter(dispatcher, consensus, chain)
 
 	// TODO: check if this is a guardian node
 	syncMgr := netsync.NewSyncManager(chain, consensus, params.NetworkOld, params.Network, dispatcher, consensus, reporter)
 	mempool := mp.CreateMempool(dispatcher, consensus)
 	ledger := ld.NewLedger(params.ChainID, params.RollingDB, params.RollingDB, chain, consensus, validatorManager, mempool)
+	var stateDiffs *statediff.Exporter
+	if viper.GetBool(common.CfgStateDiffEnabled) && !params.ReadOnly {
+		stateDiffs = newStateDiffExporter(params.DataPath)
+		ledger.SetStateDiffExporter(stateDiffs)
+	}
 
 	validatorManager.SetConsensusEngine(consensus)
 	consensus.SetLedger(ledger)
 	mempool.SetLedger(ledger)
+	if params.MempoolJournalPath != "" && !params.ReadOnly {
+		if err := mempool.EnableJournal(params.MempoolJournalPath); err != nil {
+			log.Errorf("Failed to load the mempool journal %v: %v", params.MempoolJournalPath, err)
+		}
+	}
 	txMsgHandler := mp.CreateMempoolMessageHandler(mempool)
 
 	if !reflect.ValueOf(params.Network).IsNil() {
@@ -88,7 +123,7 @@ func NewNode(params *Params) *Node {
 	}
 
 	currentHeight := consensus.GetLastFinalizedBlock().Height
-	if currentHeight <= params.Root.Height {
+	if currentHeight <= params.Root.Height && !params.ReadOnly {
 		snapshotPath := params.SnapshotPath
 		chainImportDirPath := params.ChainImportDirPath
 		chainCorrectionPath := params.ChainCorrectionPath
@@ -106,6 +141,12 @@ func NewNode(params *Params) *Node {
 		}
 	}
 
+	if viper.GetBool(common.CfgStorageArchive) {
+		if err := checkArchive(store, chain, params.RollingDB, params.Root, consensus.GetLastFinalizedBlock(), params.ReadOnly); err != nil {
+			log.Fatalf("The data directory does not hold the full history required by the archive mode: %v", err)
+		}
+	}
+
 	node := &Node{
 		Store:            store,
 		Chain:            chain,
@@ -116,25 +157,85 @@ func NewNode(params *Params) *Node {
 		Ledger:           ledger,
 		Mempool:          mempool,
 		reporter:         reporter,
+		rollingDB:        params.RollingDB,
+		stateDiffs:       stateDiffs,
+		readOnly:         params.ReadOnly,
+		wg:               &sync.WaitGroup{},
+	}
+	if params.ReadOnly {
+		checkpointDB, ok := params.DB.(*backend.CheckpointDatabase)
+		if !ok {
+			log.Fatalf("The read-only mode requires a checkpoint database")
+		}
+		node.checkpointDB = checkpointDB
+	}
+	dbPath := ""
+	if params.DataPath != "" {
+		dbPath = path.Join(params.DataPath, "db")
+	}
+	node.metrics = newNodeMetrics(node, dbPath)
+	node.resources = resource.NewMonitor(dbPath)
+	syncMgr.SetResourceMonitor(node.resources)
+
+	if viper.GetBool(common.CfgWebhookEnabled) && !params.ReadOnly {
+		webhooks, err := newWebhookWatcher(node)
+		if err != nil {
+			log.Fatalf("Failed to set up the webhooks: %v", err)
+		}
+		node.webhooks = webhooks
 	}
 
 	if viper.GetBool(common.CfgRPCEnabled) {
 		node.RPC = rpc.NewThetaRPCServer(mempool, ledger, dispatcher, chain, consensus)
+		node.RPC.SetReadOnly(params.ReadOnly)
+		node.RPC.SetResourceMonitor(node.resources)
 	}
 	return node
 }
 
+// newStateDiffExporter creates the exporter of the state diffs with the sink set in the config
+func newStateDiffExporter(dataPath string) *statediff.Exporter {
+	filePath := viper.GetString(common.CfgStateDiffFilePath)
+	if filePath == "" && dataPath != "" {
+		filePath = path.Join(dataPath, "statediff.jsonl")
+	}
+	sink, err := statediff.NewSink(statediff.SinkConfig{
+		Type:           viper.GetString(common.CfgStateDiffSink),
+		FilePath:       filePath,
+		WebhookURL:     viper.GetString(common.CfgStateDiffWebhookURL),
+		KafkaRESTProxy: viper.GetString(common.CfgStateDiffKafkaRESTProxy),
+		KafkaTopic:     viper.GetString(common.CfgStateDiffKafkaTopic),
+		Headers:        viper.GetStringSlice(common.CfgStateDiffHTTPHeaders),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create the state diff sink: %v", err)
+	}
+	log.Infof("Exporting the state diffs of the committed blocks to the %v sink", viper.GetString(common.CfgStateDiffSink))
+	return statediff.NewExporter(sink)
+}
+
 // Start starts sub components and kick off the main loop.
 func (n *Node) Start(ctx context.Context) {
 	c, cancel := context.WithCancel(ctx)
 	n.ctx = c
 	n.cancel = cancel
 
+	n.metrics.Start(n.ctx)
+	n.resources.Start(n.ctx)
+
+	if n.readOnly {
+		n.startReadOnly()
+		return
+	}
+
 	n.Consensus.Start(n.ctx)
 	n.SyncManager.Start(n.ctx)
 	n.Dispatcher.Start(n.ctx)
 	n.Mempool.Start(n.ctx)
 	n.reporter.Start(n.ctx)
+	if n.webhooks != nil {
+		n.webhooks.Start(n.ctx)
+	}
 
 	if viper.GetBool(common.CfgRPCEnabled) {
 		n.RPC.Start(n.ctx)
@@ -146,8 +247,82 @@ func (n *Node) Stop() {
 	n.cancel()
 }
 
+// Shutdown stops the sub components in an order which leaves the data directory consistent. It
+// stops accepting RPC requests and drains the in-flight ones, stops importing blocks and lets the
+// block being processed be committed, closes the mempool journal and disconnects the peers. It
+// returns an error if the components have not stopped when ctx is done, in which case the
+// databases must not be closed.
+func (n *Node) Shutdown(ctx context.Context) error {
+	if n.RPC != nil {
+		if err := n.RPC.Shutdown(ctx); err != nil {
+			log.Warnf("Failed to drain the RPC requests: %v", err)
+		}
+	}
+	if n.readOnly {
+		n.cancel()
+		return waitUntil(ctx, "RPC server", n.Wait)
+	}
+
+	// Stop receiving blocks first, so that the consensus engine stops after the block being processed
+	n.SyncManager.Stop()
+	if err := waitUntil(ctx, "sync manager", n.SyncManager.Wait); err != nil {
+		return err
+	}
+	n.Consensus.Stop()
+	if err := waitUntil(ctx, "consensus engine", n.Consensus.Wait); err != nil {
+		return err
+	}
+	if !n.rollingDB.StopCompaction(ctx) {
+		return fmt.Errorf("Timed out waiting for the state compaction: %v", ctx.Err())
+	}
+
+	if n.stateDiffs != nil {
+		n.stateDiffs.Stop()
+	}
+
+	if n.webhooks != nil {
+		n.webhooks.Stop()
+	}
+
+	n.Mempool.Stop()
+	n.reporter.Stop()
+	n.Dispatcher.Stop()
+	n.cancel()
+	if err := waitUntil(ctx, "mempool", n.Mempool.Wait); err != nil {
+		return err
+	}
+	if err := waitUntil(ctx, "dispatcher", n.Dispatcher.Wait); err != nil {
+		return err
+	}
+	return waitUntil(ctx, "RPC server", n.Wait)
+}
+
+// waitUntil calls wait and returns an error if it has not returned when ctx is done.
+func waitUntil(ctx context.Context, component string, wait func()) error {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("Timed out waiting for the %v to stop: %v", component, ctx.Err())
+	}
+}
+
 // Wait blocks until all sub components stop.
 func (n *Node) Wait() {
+	if n.readOnly {
+		n.wg.Wait()
+		if n.RPC != nil {
+			n.RPC.Wait()
+		}
+		return
+	}
+
 	n.Consensus.Wait()
 	n.SyncManager.Wait()
 	if n.RPC != nil {
@@ -1 +1,23 @@
 package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitUntil(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(waitUntil(context.Background(), "component", func() {}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	stopped := make(chan struct{})
+	defer close(stopped)
+	err := waitUntil(ctx, "consensus engine", func() { <-stopped })
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "consensus engine")
+}
@@ -0,0 +1,72 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+)
+
+// startReadOnly points the ledger to the last finalized state of the current checkpoint and
+// starts the RPC server only. Consensus, sync and mempool are not started since they write to
+// the databases. The node then takes a new checkpoint periodically to follow the finalized blocks
+// of the node writing to the data directory.
+func (n *Node) startReadOnly() {
+	lastFinalizedBlock := n.Consensus.GetLastFinalizedBlock()
+	if res := n.Ledger.ResetState(lastFinalizedBlock.Block); res.IsError() {
+		log.Fatalf("Failed to load the ledger state at height %v: %v", lastFinalizedBlock.Height, res.Message)
+	}
+
+	if n.RPC != nil {
+		n.RPC.Start(n.ctx)
+	} else {
+		log.Printf("RPC is disabled, the read-only node has nothing to serve")
+	}
+
+	interval := time.Duration(viper.GetInt(common.CfgStorageReadOnlyRefreshInterval)) * time.Second
+	if interval <= 0 {
+		return
+	}
+	n.wg.Add(1)
+	go n.followCheckpoints(interval)
+}
+
+func (n *Node) followCheckpoints(interval time.Duration) {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := n.refreshCheckpoint(); err != nil {
+				log.Warnf("Failed to refresh the checkpoint of the data directory: %v", err)
+			}
+		}
+	}
+}
+
+// refreshCheckpoint switches the databases to a new checkpoint and moves the ledger to the last
+// finalized block recorded in it.
+func (n *Node) refreshCheckpoint() error {
+	release, err := n.checkpointDB.Refresh()
+	if err != nil {
+		return err
+	}
+	n.rollingDB.Reopen(n.checkpointDB.Path())
+	release()
+
+	if err := n.Consensus.State().Reload(); err != nil {
+		return err
+	}
+	lastFinalizedBlock := n.Consensus.GetLastFinalizedBlock()
+	if res := n.Ledger.ResetState(lastFinalizedBlock.Block); res.IsError() {
+		return fmt.Errorf("Failed to load the ledger state at height %v: %v", lastFinalizedBlock.Height, res.Message)
+	}
+	log.Debugf("Switched to a new checkpoint, last finalized height: %v", lastFinalizedBlock.Height)
+	return nil
+}
@@ -0,0 +1,163 @@
+package node
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/util"
+)
+
+// reloadableConfigs are the settings applied by ReloadConfig without a restart. The changes of
+// the other settings are reported, and only take effect after the node restarts.
+var reloadableConfigs = map[string]bool{
+	common.CfgLogLevels: true,
+	common.CfgLogFormat: true,
+
+	common.CfgRPCRateLimitRequestsPerSec: true,
+	common.CfgRPCRateLimitBurst:          true,
+	common.CfgRPCRateLimitMaxConcurrent:  true,
+
+	// Read for each new connection or request
+	common.CfgRPCWSMaxSubscriptionsPerConn: true,
+	common.CfgRPCWSHeartbeatIntervalSecs:   true,
+	common.CfgRPCHealthMaxBlocksBehind:     true,
+	common.CfgRPCHealthMinPeers:            true,
+
+	// The node connects to the added seeds, the removed seeds stay connected until they disconnect
+	common.CfgP2PSeeds:    true,
+	common.CfgLibP2PSeeds: true,
+}
+
+var reloadMu sync.Mutex
+
+// ReloadConfig re-reads the config file and applies the changes of the reloadable settings. It
+// returns the changed settings which have been applied, and those which require a restart.
+func (n *Node) ReloadConfig() (applied []string, restartRequired []string, err error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	before := configSnapshot()
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, nil, fmt.Errorf("Failed to read the config file: %v", err)
+	}
+	changed := changedConfigs(before, configSnapshot())
+	applied, restartRequired = classifyConfigs(changed)
+
+	for _, key := range restartRequired {
+		log.Warnf("Config %v changed to %v, the change takes effect after a restart", key, viper.Get(key))
+	}
+	if len(applied) == 0 {
+		return applied, restartRequired, nil
+	}
+
+	if contains(applied, common.CfgLogLevels) || contains(applied, common.CfgLogFormat) {
+		if err := util.ReloadLog(); err != nil {
+			return nil, restartRequired, err
+		}
+	}
+	if n.RPC != nil {
+		n.RPC.ReloadConfig()
+	}
+	for _, key := range []string{common.CfgP2PSeeds, common.CfgLibP2PSeeds} {
+		if !contains(applied, key) || n.readOnly {
+			continue
+		}
+		previous, _ := before[strings.ToLower(key)].(string)
+		for _, seed := range addedSeeds(previous, viper.GetString(key)) {
+			if err := n.Dispatcher.ConnectToPeer(seed); err != nil {
+				log.Warnf("Failed to connect to the added seed %v: %v", seed, err)
+			}
+		}
+	}
+
+	for _, key := range applied {
+		log.Infof("Config %v changed to %v", key, viper.Get(key))
+	}
+	return applied, restartRequired, nil
+}
+
+// configSnapshot returns the current value of every setting.
+func configSnapshot() map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, key := range viper.AllKeys() {
+		values[key] = viper.Get(key)
+	}
+	return values
+}
+
+// changedConfigs returns the sorted keys of the settings whose values differ, including the
+// settings added or removed.
+func changedConfigs(before, after map[string]interface{}) []string {
+	changed := []string{}
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func classifyConfigs(keys []string) (reloadable []string, restartRequired []string) {
+	reloadable = []string{}
+	restartRequired = []string{}
+	for _, key := range keys {
+		if isReloadable(key) {
+			reloadable = append(reloadable, key)
+		} else {
+			restartRequired = append(restartRequired, key)
+		}
+	}
+	return reloadable, restartRequired
+}
+
+// isReloadable compares the keys case insensitively, as viper returns them in lower case.
+func isReloadable(key string) bool {
+	for cfg := range reloadableConfigs {
+		if strings.EqualFold(cfg, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// addedSeeds returns the seeds of the comma separated list after which are not in before.
+func addedSeeds(before string, after string) []string {
+	existing := make(map[string]bool)
+	for _, seed := range splitSeeds(before) {
+		existing[seed] = true
+	}
+	added := []string{}
+	for _, seed := range splitSeeds(after) {
+		if !existing[seed] {
+			added = append(added, seed)
+		}
+	}
+	return added
+}
+
+func splitSeeds(seeds string) []string {
+	return strings.FieldsFunc(seeds, func(c rune) bool {
+		return c == ',' || c == ' '
+	})
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
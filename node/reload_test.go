@@ -0,0 +1,42 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedConfigs(t *testing.T) {
+	assert := assert.New(t)
+
+	before := map[string]interface{}{
+		"log.levels":                  "*:info",
+		"p2p.port":                    12000,
+		"rpc.ratelimitrequestspersec": 10.0,
+		"rpc.authapikeys":             []string{"key1=theta.*"},
+	}
+	after := map[string]interface{}{
+		"log.levels":                  "*:debug",
+		"p2p.port":                    12000,
+		"rpc.ratelimitrequestspersec": 10.0,
+		"rpc.authapikeys":             []string{"key1=theta.*", "key2=admin.*"},
+		"p2p.libp2pseeds":             "/ip4/127.0.0.1/tcp/15000/p2p/QmPeer",
+	}
+
+	changed := changedConfigs(before, after)
+	assert.Equal([]string{"log.levels", "p2p.libp2pseeds", "rpc.authapikeys"}, changed)
+
+	applied, restartRequired := classifyConfigs(changed)
+	assert.Equal([]string{"log.levels", "p2p.libp2pseeds"}, applied)
+	assert.Equal([]string{"rpc.authapikeys"}, restartRequired)
+
+	assert.Equal([]string{}, changedConfigs(before, before))
+}
+
+func TestAddedSeeds(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"10.0.0.3:12000"}, addedSeeds("10.0.0.1:12000,10.0.0.2:12000", "10.0.0.2:12000, 10.0.0.3:12000"))
+	assert.Equal([]string{"10.0.0.1:12000"}, addedSeeds("", "10.0.0.1:12000"))
+	assert.Equal([]string{}, addedSeeds("10.0.0.1:12000", ""))
+}
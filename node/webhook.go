@@ -0,0 +1,248 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/webhook"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// The webhook events
+const (
+	WebhookEventBlockFinalized           = "block_finalized"
+	WebhookEventFundsReceived            = "funds_received"
+	WebhookEventValidatorMissedProposals = "validator_missed_proposals"
+	WebhookEventNodeFellBehind           = "node_fell_behind"
+	WebhookEventNodeCaughtUp             = "node_caught_up"
+)
+
+const (
+	webhookPollInterval  = 1 * time.Second
+	maxWebhookBlocksPoll = 100  // the max number of finalized blocks processed per poll
+	maxWebhookEpochGap   = 1000 // the max number of epochs without a block checked for missed proposals
+)
+
+// webhookWatcher follows the finalized blocks and the sync status of the node, and notifies the
+// webhook endpoints of the events the operator is interested in.
+type webhookWatcher struct {
+	node       *Node
+	dispatcher *webhook.Dispatcher
+
+	addresses       map[common.Address]bool
+	validators      map[common.Address]bool
+	missedThreshold uint64
+	maxBlocksBehind uint64
+
+	lastHeight uint64                    // the height of the last finalized block processed
+	missed     map[common.Address]uint64 // the number of consecutive proposals missed by the validators
+	behind     bool
+}
+
+func newWebhookWatcher(node *Node) (*webhookWatcher, error) {
+	endpoints := []webhook.Endpoint{}
+	for _, entry := range viper.GetStringSlice(common.CfgWebhookEndpoints) {
+		endpoint, err := webhook.ParseEndpoint(entry)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("No webhook endpoints configured")
+	}
+
+	addresses, err := parseAddresses(viper.GetStringSlice(common.CfgWebhookWatchAddresses))
+	if err != nil {
+		return nil, err
+	}
+	validators, err := parseAddresses(viper.GetStringSlice(common.CfgWebhookWatchValidators))
+	if err != nil {
+		return nil, err
+	}
+	if len(validators) == 0 {
+		validators[common.HexToAddress(node.Consensus.ID())] = true
+	}
+
+	return &webhookWatcher{
+		node:            node,
+		dispatcher:      webhook.NewDispatcher(endpoints, viper.GetString(common.CfgWebhookSecret), viper.GetInt(common.CfgWebhookMaxRetries)),
+		addresses:       addresses,
+		validators:      validators,
+		missedThreshold: viper.GetUint64(common.CfgWebhookMissedProposals),
+		maxBlocksBehind: viper.GetUint64(common.CfgWebhookMaxBlocksBehind),
+		missed:          make(map[common.Address]uint64),
+	}, nil
+}
+
+func parseAddresses(hexAddrs []string) (map[common.Address]bool, error) {
+	addresses := make(map[common.Address]bool)
+	for _, hexAddr := range hexAddrs {
+		if !common.IsHexAddress(hexAddr) {
+			return nil, fmt.Errorf("Invalid address: %v", hexAddr)
+		}
+		addresses[common.HexToAddress(hexAddr)] = true
+	}
+	return addresses, nil
+}
+
+// Start starts following the blocks finalized from now on, it stops when ctx is done.
+func (w *webhookWatcher) Start(ctx context.Context) {
+	w.lastHeight = w.node.Consensus.GetLastFinalizedBlock().Height
+	go w.mainLoop(ctx)
+}
+
+// Stop delivers the queued events and stops the dispatcher.
+func (w *webhookWatcher) Stop() {
+	w.dispatcher.Stop()
+}
+
+func (w *webhookWatcher) mainLoop(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+func (w *webhookWatcher) poll() {
+	lfb := w.node.Consensus.GetLastFinalizedBlock()
+	for i := 0; i < maxWebhookBlocksPoll && w.lastHeight < lfb.Height; i++ {
+		block := findFinalizedBlock(w.node.Chain, w.lastHeight+1)
+		if block == nil {
+			break
+		}
+		w.processBlock(block)
+		w.lastHeight = block.Height
+	}
+
+	w.checkSyncStatus(lfb.Height, w.node.Consensus.GetNetworkHeight())
+}
+
+func (w *webhookWatcher) processBlock(block *core.ExtendedBlock) {
+	w.dispatcher.Notify(WebhookEventBlockFinalized, map[string]interface{}{
+		"height":    common.JSONUint64(block.Height),
+		"hash":      block.Hash(),
+		"parent":    block.Parent,
+		"epoch":     common.JSONUint64(block.Epoch),
+		"timestamp": (*common.JSONBig)(block.Timestamp),
+		"proposer":  block.Proposer,
+		"num_txs":   len(block.Txs),
+	})
+
+	if len(w.addresses) > 0 {
+		w.checkFundsReceived(block)
+	}
+	if len(w.validators) > 0 && w.missedThreshold > 0 {
+		w.checkMissedProposals(block)
+	}
+}
+
+// checkFundsReceived notifies the funds sent to the watched addresses by the send transactions,
+// and the block rewards paid to them by the coinbase transactions.
+func (w *webhookWatcher) checkFundsReceived(block *core.ExtendedBlock) {
+	for _, rawTx := range block.Txs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			continue
+		}
+		var txType string
+		var outputs []types.TxOutput
+		var senders []common.Address
+		switch tx := tx.(type) {
+		case *types.SendTx:
+			txType = "send"
+			outputs = tx.Outputs
+			for _, input := range tx.Inputs {
+				senders = append(senders, input.Address)
+			}
+		case *types.CoinbaseTx:
+			txType = "coinbase"
+			outputs = tx.Outputs
+		default:
+			continue
+		}
+		for _, output := range outputs {
+			if !w.addresses[output.Address] {
+				continue
+			}
+			w.dispatcher.Notify(WebhookEventFundsReceived, map[string]interface{}{
+				"address":      output.Address,
+				"coins":        output.Coins,
+				"senders":      senders,
+				"tx_hash":      crypto.Keccak256Hash(rawTx),
+				"tx_type":      txType,
+				"block_hash":   block.Hash(),
+				"block_height": common.JSONUint64(block.Height),
+			})
+		}
+	}
+}
+
+// checkMissedProposals counts the epochs without a block between the block and its parent against
+// the validators expected to propose in these epochs. A validator is notified when it misses the
+// configured number of consecutive proposals, and its count is reset once it proposes a block.
+func (w *webhookWatcher) checkMissedProposals(block *core.ExtendedBlock) {
+	parent, err := w.node.Chain.FindBlock(block.Parent)
+	if err != nil || parent == nil {
+		return
+	}
+	startEpoch := parent.Epoch + 1
+	if block.Epoch > startEpoch+maxWebhookEpochGap {
+		startEpoch = block.Epoch - maxWebhookEpochGap
+	}
+	for epoch := startEpoch; epoch < block.Epoch; epoch++ {
+		proposer := w.node.ValidatorManager.GetNextProposer(parent.Hash(), epoch).Address
+		if !w.validators[proposer] {
+			continue
+		}
+		w.missed[proposer]++
+		if w.missed[proposer]%w.missedThreshold == 0 {
+			w.dispatcher.Notify(WebhookEventValidatorMissedProposals, map[string]interface{}{
+				"validator":     proposer,
+				"missed":        w.missed[proposer],
+				"epoch":         common.JSONUint64(epoch),
+				"parent_hash":   parent.Hash(),
+				"parent_height": common.JSONUint64(parent.Height),
+			})
+		}
+	}
+	if w.validators[block.Proposer] {
+		w.missed[block.Proposer] = 0
+	}
+}
+
+// checkSyncStatus notifies when the node falls behind the network by more than the configured
+// number of blocks, and when it catches up again.
+func (w *webhookWatcher) checkSyncStatus(finalizedHeight uint64, networkHeight uint64) {
+	if networkHeight == 0 {
+		return // no votes received from the network yet
+	}
+	behind := networkHeight > finalizedHeight && networkHeight-finalizedHeight > w.maxBlocksBehind
+	if behind == w.behind {
+		return
+	}
+	w.behind = behind
+
+	event := WebhookEventNodeCaughtUp
+	if behind {
+		event = WebhookEventNodeFellBehind
+	}
+	log.Infof("Node sync status changed: %v, finalized height: %v, network height: %v", event, finalizedHeight, networkHeight)
+	w.dispatcher.Notify(event, map[string]interface{}{
+		"finalized_height": common.JSONUint64(finalizedHeight),
+		"network_height":   common.JSONUint64(networkHeight),
+	})
+}
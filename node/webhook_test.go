@@ -0,0 +1,65 @@
+package node
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/webhook"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestWebhookWatcher(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mu := &sync.Mutex{}
+	events := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, r.Header.Get(webhook.HeaderEvent))
+	}))
+	defer server.Close()
+
+	watched := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	other := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	w := &webhookWatcher{
+		dispatcher:      webhook.NewDispatcher([]webhook.Endpoint{{URL: server.URL}}, "", 0),
+		addresses:       map[common.Address]bool{watched: true},
+		maxBlocksBehind: 10,
+		missed:          make(map[common.Address]uint64),
+	}
+
+	// Only the outputs to the watched addresses are notified
+	sendTx := &types.SendTx{
+		Fee:     types.NewCoins(0, 1),
+		Inputs:  []types.TxInput{{Address: other, Coins: types.NewCoins(0, 3)}},
+		Outputs: []types.TxOutput{{Address: watched, Coins: types.NewCoins(0, 1)}, {Address: other, Coins: types.NewCoins(0, 1)}},
+	}
+	rawTx, err := types.TxToBytes(sendTx)
+	require.Nil(err)
+	block := &core.ExtendedBlock{Block: &core.Block{
+		BlockHeader: &core.BlockHeader{Height: 1, Timestamp: big.NewInt(0)},
+		Txs:         []common.Bytes{rawTx},
+	}}
+	w.checkFundsReceived(block)
+
+	// Notified once when falling behind, and once when catching up
+	w.checkSyncStatus(100, 0)
+	w.checkSyncStatus(100, 105)
+	w.checkSyncStatus(100, 111)
+	w.checkSyncStatus(100, 120)
+	w.checkSyncStatus(115, 120)
+	w.dispatcher.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal([]string{WebhookEventFundsReceived, WebhookEventNodeFellBehind, WebhookEventNodeCaughtUp}, events)
+}
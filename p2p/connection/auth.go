@@ -88,7 +88,8 @@ func (conn *Connection) DoEncHandshake(prv *ecdsa.PrivateKey, dial *ecdsa.Public
 		sec secrets
 		err error
 	)
-	if dial.X.Cmp(prv.PublicKey.X) < 0 {
+	initiator := dial.X.Cmp(prv.PublicKey.X) >= 0
+	if !initiator {
 		sec, err = receiverEncHandshake(conn.bufConn, prv)
 	} else {
 		sec, err = initiatorEncHandshake(conn.bufConn, prv, dial)
@@ -96,8 +97,13 @@ func (conn *Connection) DoEncHandshake(prv *ecdsa.PrivateKey, dial *ecdsa.Public
 	if err != nil {
 		return nil, err
 	}
+	rekey, err := newSessionRekey(initiator, sec)
+	if err != nil {
+		return nil, err
+	}
 	conn.wmu.Lock()
 	conn.rw = newRLPXFrameRW(conn.bufConn, sec)
+	conn.rekey = rekey
 	conn.wmu.Unlock()
 	return crypto.ECDSAToPubKey(sec.Remote.ExportECDSA()), nil
 }
@@ -468,9 +474,10 @@ type rlpxFrameRW struct {
 	enc  cipher.Stream
 	dec  cipher.Stream
 
-	macCipher  cipher.Block
-	egressMAC  hash.Hash
-	ingressMAC hash.Hash
+	egressMACCipher  cipher.Block
+	ingressMACCipher cipher.Block
+	egressMAC        hash.Hash
+	ingressMAC       hash.Hash
 
 	snappy bool
 }
@@ -488,12 +495,13 @@ func newRLPXFrameRW(conn io.ReadWriter, s secrets) *rlpxFrameRW {
 	// for encryption is ephemeral.
 	iv := make([]byte, encc.BlockSize())
 	return &rlpxFrameRW{
-		conn:       conn,
-		enc:        cipher.NewCTR(encc, iv),
-		dec:        cipher.NewCTR(encc, iv),
-		macCipher:  macc,
-		egressMAC:  s.EgressMAC,
-		ingressMAC: s.IngressMAC,
+		conn:             conn,
+		enc:              cipher.NewCTR(encc, iv),
+		dec:              cipher.NewCTR(encc, iv),
+		egressMACCipher:  macc,
+		ingressMACCipher: macc,
+		egressMAC:        s.EgressMAC,
+		ingressMAC:       s.IngressMAC,
 	}
 }
 
@@ -518,7 +526,7 @@ func (rw *rlpxFrameRW) WritePacket(packet *Packet) error {
 	rw.enc.XORKeyStream(headbuf[:16], headbuf[:16]) // first half is now encrypted
 
 	// write header MAC
-	copy(headbuf[16:], updateMAC(rw.egressMAC, rw.macCipher, headbuf[:16]))
+	copy(headbuf[16:], updateMAC(rw.egressMAC, rw.egressMACCipher, headbuf[:16]))
 	if _, err := rw.conn.Write(headbuf); err != nil {
 		return err
 	}
@@ -538,7 +546,7 @@ func (rw *rlpxFrameRW) WritePacket(packet *Packet) error {
 	// write frame MAC. egress MAC hash is up to date because
 	// frame content was written to it as well.
 	fmacseed := rw.egressMAC.Sum(nil)
-	mac := updateMAC(rw.egressMAC, rw.macCipher, fmacseed)
+	mac := updateMAC(rw.egressMAC, rw.egressMACCipher, fmacseed)
 	_, err := rw.conn.Write(mac)
 	return err
 }
@@ -550,7 +558,7 @@ func (rw *rlpxFrameRW) ReadPacket() (*Packet, error) {
 		return nil, err
 	}
 	// verify header mac
-	shouldMAC := updateMAC(rw.ingressMAC, rw.macCipher, headbuf[:16])
+	shouldMAC := updateMAC(rw.ingressMAC, rw.ingressMACCipher, headbuf[:16])
 	if !hmac.Equal(shouldMAC, headbuf[16:]) {
 		return nil, errors.New("bad header MAC")
 	}
@@ -574,7 +582,7 @@ func (rw *rlpxFrameRW) ReadPacket() (*Packet, error) {
 	if _, err := io.ReadFull(rw.conn, headbuf[:16]); err != nil {
 		return nil, err
 	}
-	shouldMAC = updateMAC(rw.ingressMAC, rw.macCipher, fmacseed)
+	shouldMAC = updateMAC(rw.ingressMAC, rw.ingressMACCipher, fmacseed)
 	if !hmac.Equal(shouldMAC, headbuf[:16]) {
 		return nil, errors.New("bad frame MAC")
 	}
@@ -11,16 +11,16 @@ import (
 	"sync/atomic"
 	"time"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/timer"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/p2p/connection/flowrate"
 	"github.com/thetatoken/theta/p2p/types"
 	p2ptypes "github.com/thetatoken/theta/p2p/types"
 	"github.com/thetatoken/theta/rlp"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "p2p"})
+var logger = util.GetLoggerForModule("p2p")
 
 //
 // Connection models the connection between the current node and a peer node.
@@ -44,12 +44,14 @@ type Connection struct {
 	onError      ErrorHandler
 	errored      uint32
 
-	sendPulse chan bool
-	pongPulse chan bool
-	quitPulse chan bool
+	sendPulse  chan bool
+	pongPulse  chan bool
+	rekeyPulse chan bool
+	quitPulse  chan bool
 
 	flushTimer *timer.ThrottleTimer // flush writes as necessary but throttled
 	pingTimer  *timer.RepeatTimer   // send pings periodically
+	rekeyTimer *timer.RepeatTimer   // rotate the session keys periodically, nil if disabled
 
 	pendingPings uint32
 
@@ -57,6 +59,7 @@ type Connection struct {
 
 	rmu, wmu sync.Mutex
 	rw       *rlpxFrameRW
+	rekey    *sessionRekey
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -76,6 +79,10 @@ type ConnectionConfig struct {
 	FlushThrottle   time.Duration
 	PingTimeout     time.Duration
 	MaxPendingPings uint32
+
+	// SessionKeyRotationInterval is the interval to rotate the session keys of the encrypted
+	// transport, zero disables the rotation
+	SessionKeyRotationInterval time.Duration
 }
 
 // MessageParser parses the raw message bytes to type p2ptypes.Message
@@ -141,6 +148,7 @@ func CreateConnection(netconn net.Conn, config ConnectionConfig) *Connection {
 		channelGroup: channelGroup,
 		sendPulse:    make(chan bool, 1),
 		pongPulse:    make(chan bool, 1),
+		rekeyPulse:   make(chan bool, 1),
 		quitPulse:    make(chan bool, 1),
 		flushTimer:   timer.NewThrottleTimer("flush", config.FlushThrottle),
 		pingTimer:    timer.NewRepeatTimer("ping", config.PingTimeout),
@@ -165,6 +173,8 @@ func GetDefaultConnectionConfig() ConnectionConfig {
 		FlushThrottle:   100 * time.Millisecond,
 		PingTimeout:     40 * time.Second,
 		MaxPendingPings: 3,
+
+		SessionKeyRotationInterval: 30 * time.Minute,
 	}
 }
 
@@ -184,6 +194,10 @@ func (conn *Connection) Start(ctx context.Context) bool {
 	//       the wg since rlp.Decode() is a blocking call
 	conn.wg.Add(1)
 
+	if conn.rekey != nil && conn.rekey.requester && conn.config.SessionKeyRotationInterval > 0 {
+		conn.rekeyTimer = timer.NewRepeatTimer("rekey", conn.config.SessionKeyRotationInterval)
+	}
+
 	go conn.sendRoutine()
 	go conn.recvRoutine()
 	return true
@@ -303,6 +317,12 @@ func (conn *Connection) sendRoutine() {
 		_ = conn.netconn.Close()
 	}()
 
+	var rekeyCh chan time.Time
+	if conn.rekeyTimer != nil {
+		rekeyCh = conn.rekeyTimer.Ch
+		defer conn.rekeyTimer.Stop()
+	}
+
 	for {
 		var err error
 		select {
@@ -317,6 +337,10 @@ func (conn *Connection) sendRoutine() {
 			err = conn.sendPingSignal()
 		case <-conn.pongPulse:
 			err = conn.sendPongSignal()
+		case <-rekeyCh:
+			err = conn.rotateSessionKeys()
+		case <-conn.rekeyPulse:
+			err = conn.sendPendingRekeyPacket()
 		case <-conn.sendPulse:
 			conn.sendPacketBatchAndScheduleSendPulse()
 		case <-conn.quitPulse:
@@ -426,6 +450,15 @@ func (conn *Connection) recvRoutine() {
 		conn.recvMonitor.Update(int(1))
 		switch packet.ChannelID {
 		case common.ChannelIDPing:
+			if isRekeyPacket(packet) {
+				if err := conn.handleRekeyPacket(packet); err != nil {
+					// The keys of the two nodes may no longer match
+					logger.Warnf("recvRoutine: failed to rotate the session keys: %v", err)
+					conn.stopForError(err)
+					return
+				}
+				break
+			}
 			conn.handlePingPong(packet)
 		default:
 			conn.handleReceivedPacket(packet)
@@ -0,0 +1,312 @@
+package connection
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"hash"
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/sha3"
+	p2ptypes "github.com/thetatoken/theta/p2p/types"
+)
+
+//
+// Session key rotation
+//
+// The session keys negotiated by the encryption handshake are replaced periodically on the long
+// lived connections. The node that initiated the encryption handshake starts each rotation:
+//
+//   1. The requester sends RekeyRequest with a new ephemeral public key
+//   2. The responder generates its ephemeral key, derives the new keys, sends RekeyResponse with
+//      its ephemeral public key, and encrypts everything it sends afterwards with the new keys
+//   3. The requester derives the new keys, decrypts everything it receives afterwards with the
+//      new keys, sends RekeyDone, and encrypts everything it sends afterwards with the new keys
+//   4. The responder decrypts everything it receives after RekeyDone with the new keys
+//
+// The new keys are derived from the ECDH secret of the ephemeral keys and a chain key, which is
+// replaced by each rotation. The ephemeral keys and the previous keys are discarded, so a key
+// captured from a node only decrypts the traffic of the current rotation period. The rotation
+// messages are sent on the ping channel, through the encrypted transport.
+//
+
+// SessionKeyRotationCapability is advertised in the handshake by the nodes supporting the session
+// key rotation
+const SessionKeyRotationCapability = "cap:rekey"
+
+const (
+	rekeyKeyLen = 32
+	rekeyInfo   = "theta p2p session keys"
+	chainInfo   = "theta p2p session chain key"
+)
+
+var (
+	errRekeyNotEnabled = errors.New("session key rotation not enabled")
+	errUnexpectedRekey = errors.New("unexpected session key rotation message")
+)
+
+// frameKeys are the keys encrypting and authenticating the frames in one direction
+type frameKeys struct {
+	aes []byte
+	mac []byte
+}
+
+func (keys frameKeys) ciphers() (stream cipher.Stream, macCipher cipher.Block, mac hash.Hash, err error) {
+	encc, err := aes.NewCipher(keys.aes)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	macCipher, err = aes.NewCipher(keys.mac)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// the keys are only used once, so the IV can be all zeroes
+	stream = cipher.NewCTR(encc, make([]byte, encc.BlockSize()))
+	mac = sha3.NewKeccak256()
+	mac.Write(keys.mac)
+	return stream, macCipher, mac, nil
+}
+
+func (rw *rlpxFrameRW) setEgressKeys(keys frameKeys) error {
+	stream, macCipher, mac, err := keys.ciphers()
+	if err != nil {
+		return err
+	}
+	rw.enc, rw.egressMACCipher, rw.egressMAC = stream, macCipher, mac
+	return nil
+}
+
+func (rw *rlpxFrameRW) setIngressKeys(keys frameKeys) error {
+	stream, macCipher, mac, err := keys.ciphers()
+	if err != nil {
+		return err
+	}
+	rw.dec, rw.ingressMACCipher, rw.ingressMAC = stream, macCipher, mac
+	return nil
+}
+
+// sessionRekey is the state of the session key rotation of a connection
+type sessionRekey struct {
+	mu sync.Mutex
+
+	enabled   bool   // whether the remote node supports the rotation
+	requester bool   // whether the local node starts the rotations
+	chainKey  []byte // replaced by each rotation
+	rotations uint64
+
+	ephemeralKey  *crypto.EphemeralKey // requester: waiting for the response
+	pendingPacket *Packet              // the last packet to send with the current egress keys
+	nextEgress    *frameKeys
+	nextIngress   *frameKeys // responder: waiting for RekeyDone
+}
+
+func newSessionRekey(requester bool, s secrets) (*sessionRekey, error) {
+	chainKey, err := crypto.DeriveKeys(s.AES, s.MAC, chainInfo, rekeyKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	return &sessionRekey{requester: requester, chainKey: chainKey}, nil
+}
+
+// ratchet derives the keys of both directions from the ECDH secret, and replaces the chain key
+func (r *sessionRekey) ratchet(secret []byte) (requesterKeys, responderKeys frameKeys, err error) {
+	defer zero(secret)
+	material, err := crypto.DeriveKeys(secret, r.chainKey, rekeyInfo, 5*rekeyKeyLen)
+	if err != nil {
+		return frameKeys{}, frameKeys{}, err
+	}
+	zero(r.chainKey)
+	r.chainKey = material[:rekeyKeyLen]
+	requesterKeys = frameKeys{aes: material[rekeyKeyLen : 2*rekeyKeyLen], mac: material[2*rekeyKeyLen : 3*rekeyKeyLen]}
+	responderKeys = frameKeys{aes: material[3*rekeyKeyLen : 4*rekeyKeyLen], mac: material[4*rekeyKeyLen:]}
+	return requesterKeys, responderKeys, nil
+}
+
+func rekeyPacket(signal byte, pubKey common.Bytes) *Packet {
+	return &Packet{
+		ChannelID: common.ChannelIDPing,
+		Bytes:     append([]byte{signal}, pubKey...),
+		IsEOF:     byte(0x01),
+	}
+}
+
+func isRekeyPacket(packet *Packet) bool {
+	if len(packet.Bytes) == 0 {
+		return false
+	}
+	switch packet.Bytes[0] {
+	case p2ptypes.RekeyRequestSignal, p2ptypes.RekeyResponseSignal, p2ptypes.RekeyDoneSignal:
+		return true
+	}
+	return false
+}
+
+// EnableSessionKeyRotation enables the session key rotation, once the encryption handshake is done
+// and the remote node advertised the support for it
+func (conn *Connection) EnableSessionKeyRotation() error {
+	if conn.rekey == nil {
+		return errRekeyNotEnabled
+	}
+	conn.rekey.mu.Lock()
+	defer conn.rekey.mu.Unlock()
+	conn.rekey.enabled = true
+	return nil
+}
+
+// SessionKeyRotations returns the number of session key rotations completed on the connection
+func (conn *Connection) SessionKeyRotations() uint64 {
+	if conn.rekey == nil {
+		return 0
+	}
+	conn.rekey.mu.Lock()
+	defer conn.rekey.mu.Unlock()
+	return conn.rekey.rotations
+}
+
+// rotateSessionKeys starts a rotation, called by the sendRoutine
+func (conn *Connection) rotateSessionKeys() error {
+	r := conn.rekey
+	r.mu.Lock()
+	if !r.enabled || !r.requester {
+		r.mu.Unlock()
+		return nil
+	}
+	if r.ephemeralKey != nil || r.pendingPacket != nil {
+		r.mu.Unlock()
+		logger.Warnf("Previous session key rotation not completed, remote: %v", conn.netconn.RemoteAddr())
+		return nil
+	}
+	ephemeralKey, err := crypto.GenerateEphemeralKey()
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	r.ephemeralKey = ephemeralKey
+	r.mu.Unlock()
+
+	err = conn.writePacket(rekeyPacket(p2ptypes.RekeyRequestSignal, ephemeralKey.PublicKeyBytes()))
+	if err != nil {
+		return err
+	}
+	conn.sendMonitor.Update(int(1))
+	conn.flush()
+	return nil
+}
+
+// sendPendingRekeyPacket sends the last packet with the current egress keys and switches to the
+// new ones, called by the sendRoutine
+func (conn *Connection) sendPendingRekeyPacket() error {
+	r := conn.rekey
+	r.mu.Lock()
+	packet, keys := r.pendingPacket, r.nextEgress
+	r.pendingPacket, r.nextEgress = nil, nil
+	if packet != nil && r.requester {
+		r.rotations++
+	}
+	r.mu.Unlock()
+	if packet == nil {
+		return nil
+	}
+
+	conn.wmu.Lock()
+	err := conn.rw.WritePacket(packet)
+	if err == nil {
+		err = conn.rw.setEgressKeys(*keys)
+	}
+	conn.wmu.Unlock()
+	if err != nil {
+		return err
+	}
+	conn.sendMonitor.Update(int(1))
+	conn.flush()
+	return nil
+}
+
+// handleRekeyPacket handles the rotation messages, called by the recvRoutine after the packet
+// is read, so the ingress keys can be switched right away
+func (conn *Connection) handleRekeyPacket(packet *Packet) error {
+	r := conn.rekey
+	if r == nil || conn.rw == nil {
+		return errRekeyNotEnabled
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.enabled {
+		return errRekeyNotEnabled
+	}
+
+	signal, payload := packet.Bytes[0], packet.Bytes[1:]
+	switch {
+	case signal == p2ptypes.RekeyRequestSignal && !r.requester:
+		if r.nextIngress != nil || r.pendingPacket != nil {
+			return errUnexpectedRekey
+		}
+		ephemeralKey, err := crypto.GenerateEphemeralKey()
+		if err != nil {
+			return err
+		}
+		pubKey := ephemeralKey.PublicKeyBytes()
+		secret, err := ephemeralKey.SharedSecret(payload)
+		if err != nil {
+			return err
+		}
+		requesterKeys, responderKeys, err := r.ratchet(secret)
+		if err != nil {
+			return err
+		}
+		r.nextIngress = &requesterKeys
+		r.nextEgress = &responderKeys
+		r.pendingPacket = rekeyPacket(p2ptypes.RekeyResponseSignal, pubKey)
+		conn.scheduleRekeyPulse()
+
+	case signal == p2ptypes.RekeyResponseSignal && r.requester:
+		if r.ephemeralKey == nil {
+			return errUnexpectedRekey
+		}
+		secret, err := r.ephemeralKey.SharedSecret(payload)
+		r.ephemeralKey = nil
+		if err != nil {
+			return err
+		}
+		requesterKeys, responderKeys, err := r.ratchet(secret)
+		if err != nil {
+			return err
+		}
+		if err := conn.rw.setIngressKeys(responderKeys); err != nil {
+			return err
+		}
+		r.nextEgress = &requesterKeys
+		r.pendingPacket = rekeyPacket(p2ptypes.RekeyDoneSignal, nil)
+		conn.scheduleRekeyPulse()
+
+	case signal == p2ptypes.RekeyDoneSignal && !r.requester:
+		if r.nextIngress == nil {
+			return errUnexpectedRekey
+		}
+		if err := conn.rw.setIngressKeys(*r.nextIngress); err != nil {
+			return err
+		}
+		r.nextIngress = nil
+		r.rotations++
+		logger.Debugf("Rotated session keys, remote: %v, rotations: %v", conn.netconn.RemoteAddr(), r.rotations)
+
+	default:
+		return errUnexpectedRekey
+	}
+	return nil
+}
+
+func (conn *Connection) scheduleRekeyPulse() {
+	select {
+	case conn.rekeyPulse <- true:
+	default:
+	}
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,126 @@
+package connection
+
+import (
+	"bufio"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/p2p/connection/flowrate"
+	p2ptypes "github.com/thetatoken/theta/p2p/types"
+)
+
+func TestSessionKeyRotation(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	requester, responder := newTestEncryptedConnections(t)
+	require.Nil(requester.EnableSessionKeyRotation())
+	require.Nil(responder.EnableSessionKeyRotation())
+
+	exchangeTestPackets(t, requester, responder)
+	for i := 0; i < 3; i++ {
+		// RekeyRequest
+		done := inBackground(requester.rotateSessionKeys)
+		packet, err := responder.readPacket()
+		require.Nil(err)
+		require.Nil(<-done)
+		require.True(isRekeyPacket(packet))
+		require.Nil(responder.handleRekeyPacket(packet))
+
+		// RekeyResponse, the responder switches the egress keys
+		done = inBackground(responder.sendPendingRekeyPacket)
+		packet, err = requester.readPacket()
+		require.Nil(err)
+		require.Nil(<-done)
+		require.Nil(requester.handleRekeyPacket(packet))
+
+		// RekeyDone, the requester switches the egress keys
+		done = inBackground(requester.sendPendingRekeyPacket)
+		packet, err = responder.readPacket()
+		require.Nil(err)
+		require.Nil(<-done)
+		require.Nil(responder.handleRekeyPacket(packet))
+
+		exchangeTestPackets(t, requester, responder)
+		assert.Equal(requester.rekey.chainKey, responder.rekey.chainKey)
+	}
+	assert.Equal(uint64(3), requester.SessionKeyRotations())
+	assert.Equal(uint64(3), responder.SessionKeyRotations())
+
+	// Out of order messages are rejected
+	assert.Equal(errUnexpectedRekey, responder.handleRekeyPacket(rekeyPacket(p2ptypes.RekeyDoneSignal, nil)))
+	assert.Equal(errUnexpectedRekey, requester.handleRekeyPacket(rekeyPacket(p2ptypes.RekeyResponseSignal, nil)))
+	assert.Equal(errUnexpectedRekey, requester.handleRekeyPacket(rekeyPacket(p2ptypes.RekeyRequestSignal, nil)))
+}
+
+func TestSessionKeyRotationNotEnabled(t *testing.T) {
+	assert := assert.New(t)
+
+	_, responder := newTestEncryptedConnections(t)
+	ephemeralKey, err := crypto.GenerateEphemeralKey()
+	assert.Nil(err)
+	packet := rekeyPacket(p2ptypes.RekeyRequestSignal, ephemeralKey.PublicKeyBytes())
+	assert.Equal(errRekeyNotEnabled, responder.handleRekeyPacket(packet))
+
+	plaintext := CreateConnection(nil, GetDefaultConnectionConfig())
+	assert.Equal(errRekeyNotEnabled, plaintext.EnableSessionKeyRotation())
+}
+
+func newTestEncryptedConnections(t *testing.T) (requester, responder *Connection) {
+	requesterKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(t, err)
+	responderKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(t, err)
+
+	requesterConn, responderConn := net.Pipe()
+	var requesterSecrets, responderSecrets secrets
+	var requesterErr, responderErr error
+	common.Parallel(
+		func() {
+			requesterSecrets, requesterErr = initiatorEncHandshake(requesterConn, crypto.PrivKeyToECDSA(requesterKey),
+				&crypto.PrivKeyToECDSA(responderKey).PublicKey)
+		},
+		func() {
+			responderSecrets, responderErr = receiverEncHandshake(responderConn, crypto.PrivKeyToECDSA(responderKey))
+		},
+	)
+	require.Nil(t, requesterErr)
+	require.Nil(t, responderErr)
+
+	newConn := func(netconn net.Conn, sec secrets, isRequester bool) *Connection {
+		rekey, err := newSessionRekey(isRequester, sec)
+		require.Nil(t, err)
+		return &Connection{
+			netconn:     netconn,
+			bufWriter:   bufio.NewWriter(netconn),
+			sendMonitor: flowrate.New(0, 0),
+			rekeyPulse:  make(chan bool, 1),
+			rw:          newRLPXFrameRW(netconn, sec),
+			rekey:       rekey,
+		}
+	}
+	return newConn(requesterConn, requesterSecrets, true), newConn(responderConn, responderSecrets, false)
+}
+
+func exchangeTestPackets(t *testing.T, a, b *Connection) {
+	for _, pair := range [][2]*Connection{{a, b}, {b, a}} {
+		sent := &Packet{ChannelID: common.ChannelIDTransaction, Bytes: []byte("Hello world"), IsEOF: byte(0x01)}
+		done := inBackground(func() error { return pair[0].writePacket(sent) })
+		received, err := pair[1].readPacket()
+		require.Nil(t, err)
+		require.Nil(t, <-done)
+		assert.Equal(t, sent.Bytes, received.Bytes)
+	}
+}
+
+func inBackground(f func() error) chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+	return done
+}
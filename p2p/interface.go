@@ -57,6 +57,12 @@ type Network interface {
 	// PeerExists indicates if the given peerID is a neighboring peer
 	PeerExists(peerID string) bool
 
+	// ConnectToPeer connects to the peer at the given address
+	ConnectToPeer(address string) error
+
+	// DisconnectPeer disconnects from the given peer
+	DisconnectPeer(peerID string) error
+
 	// RegisterMessageHandler registers message handler
 	RegisterMessageHandler(messageHandler MessageHandler)
 
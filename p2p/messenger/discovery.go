@@ -204,7 +204,7 @@ func (discMgr *PeerDiscoveryManager) HandlePeerWithErrors(peer *pr.Peer) {
 func (discMgr *PeerDiscoveryManager) connectToOutboundPeer(peerNetAddress *netutil.NetAddress, persistent bool) (*pr.Peer, error) {
 	logger.Debugf("Connecting to outbound peer: %v...", peerNetAddress)
 	peerConfig := pr.GetDefaultPeerConfig()
-	connConfig := cn.GetDefaultConnectionConfig()
+	connConfig := getConnectionConfig()
 	peer, err := pr.CreateOutboundPeer(peerNetAddress, peerConfig, connConfig)
 	if err != nil {
 		logger.Debugf("Failed to create outbound peer: %v", peerNetAddress)
@@ -218,7 +218,7 @@ func (discMgr *PeerDiscoveryManager) connectToOutboundPeer(peerNetAddress *netut
 func (discMgr *PeerDiscoveryManager) connectWithInboundPeer(netconn net.Conn, persistent bool) (*pr.Peer, error) {
 	logger.Infof("Connecting with inbound peer: %v...", netconn.RemoteAddr())
 	peerConfig := pr.GetDefaultPeerConfig()
-	connConfig := cn.GetDefaultConnectionConfig()
+	connConfig := getConnectionConfig()
 	peer, err := pr.CreateInboundPeer(netconn, peerConfig, connConfig)
 	if err != nil {
 		logger.Warnf("Failed to create inbound peer: %v", netconn.RemoteAddr())
@@ -229,6 +229,12 @@ func (discMgr *PeerDiscoveryManager) connectWithInboundPeer(netconn net.Conn, pe
 	return peer, err
 }
 
+func getConnectionConfig() cn.ConnectionConfig {
+	connConfig := cn.GetDefaultConnectionConfig()
+	connConfig.SessionKeyRotationInterval = time.Duration(viper.GetInt(common.CfgP2PSessionKeyRotationInterval)) * time.Second
+	return connConfig
+}
+
 // handshakeAndAddPeer performs handshake with a peer. Upon successful handshake,
 // it save the peer to the peer table
 func (discMgr *PeerDiscoveryManager) handshakeAndAddPeer(peer *pr.Peer) error {
@@ -2,6 +2,7 @@ package messenger
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"sync"
 
@@ -9,17 +10,17 @@ import (
 
 	//nat "github.com/fd/go-nat"
 	//nat "github.com/libp2p/go-nat"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/p2p"
+	"github.com/thetatoken/theta/p2p/netutil"
 	pr "github.com/thetatoken/theta/p2p/peer"
 	p2ptypes "github.com/thetatoken/theta/p2p/types"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "p2p"})
+var logger = util.GetLoggerForModule("p2p")
 
 //
 // Messenger implements the Network interface
@@ -137,8 +138,11 @@ func (msgr *Messenger) Start(ctx context.Context) error {
 	return err
 }
 
-// Stop is called when the Messenger stops
+// Stop is called when the Messenger stops, it closes the connections to the peers
 func (msgr *Messenger) Stop() {
+	for _, peer := range *msgr.peerTable.GetAllPeers(false) {
+		peer.Stop()
+	}
 	msgr.cancel()
 }
 
@@ -255,6 +259,30 @@ func (msgr *Messenger) PeerExists(peerID string) bool {
 	return msgr.peerTable.PeerExists(peerID)
 }
 
+// ConnectToPeer connects to the peer at the given address, e.g. "1.2.3.4:30001"
+func (msgr *Messenger) ConnectToPeer(address string) error {
+	netAddr, err := netutil.NewNetAddressString(address)
+	if err != nil {
+		return err
+	}
+	if msgr.peerTable.PeerAddrExists(netAddr) {
+		return fmt.Errorf("Already connected to peer %v", address)
+	}
+	_, err = msgr.discMgr.connectToOutboundPeer(netAddr, true)
+	return err
+}
+
+// DisconnectPeer disconnects from the given peer
+func (msgr *Messenger) DisconnectPeer(peerID string) error {
+	peer := msgr.peerTable.GetPeer(peerID)
+	if peer == nil {
+		return fmt.Errorf("Peer %v not found", peerID)
+	}
+	msgr.peerTable.DeletePeer(peerID)
+	peer.Stop()
+	return nil
+}
+
 // RegisterMessageHandler registers the message handler
 func (msgr *Messenger) RegisterMessageHandler(msgHandler p2p.MessageHandler) {
 	channelIDs := msgHandler.GetChannelIDs()
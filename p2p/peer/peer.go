@@ -10,10 +10,10 @@ import (
 	"sync"
 	"time"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/common"
 	cmn "github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/crypto"
 	cn "github.com/thetatoken/theta/p2p/connection"
 	nu "github.com/thetatoken/theta/p2p/netutil"
@@ -21,7 +21,7 @@ import (
 	"github.com/thetatoken/theta/rlp"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "p2p"})
+var logger = util.GetLoggerForModule("p2p")
 
 const maxExtraHandshakeInfo = 4096
 
@@ -155,6 +155,7 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 	localChainID := viper.GetString(cmn.CfgGenesisChainID)
 	selfNodeType := viper.GetInt(cmn.CfgNodeType)
 	var peerType int
+	var peerRekey bool
 	cmn.Parallel(
 		func() {
 			sendError = rlp.Encode(peer.connection.GetBufNetconn(), localChainID)
@@ -165,6 +166,10 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 			if sendError != nil {
 				return
 			}
+			sendError = rlp.Encode(peer.connection.GetBufNetconn(), cn.SessionKeyRotationCapability)
+			if sendError != nil {
+				return
+			}
 			sendError = rlp.Encode(peer.connection.GetBufNetconn(), "EOH")
 		},
 		func() {
@@ -205,6 +210,9 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 				if msg == "EOH" {
 					return
 				}
+				if msg == cn.SessionKeyRotationCapability {
+					peerRekey = true
+				}
 			}
 		},
 	)
@@ -233,6 +241,13 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 	}
 	logger.Infof("Using encrypted transport for peer: %v", targetNodePubKey.Address())
 
+	if peerRekey {
+		if err := peer.connection.EnableSessionKeyRotation(); err != nil {
+			logger.Warnf("Error during handshake/key exchange: %v", err)
+			return err
+		}
+	}
+
 	if !peer.isOutbound {
 		peer.SetNetAddress(nu.NewNetAddressWithEnforcedPort(netconn.RemoteAddr(), int(peer.nodeInfo.Port)))
 	}
@@ -2,6 +2,7 @@ package simulation
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -219,6 +220,16 @@ func (se *SimnetEndpoint) PeerExists(peerID string) bool {
 	return false
 }
 
+// ConnectToPeer implements the Network interface.
+func (se *SimnetEndpoint) ConnectToPeer(address string) error {
+	return errors.New("ConnectToPeer is not supported by the simulated network")
+}
+
+// DisconnectPeer implements the Network interface.
+func (se *SimnetEndpoint) DisconnectPeer(peerID string) error {
+	return errors.New("DisconnectPeer is not supported by the simulated network")
+}
+
 // RegisterMessageHandler implements the Network interface.
 func (se *SimnetEndpoint) RegisterMessageHandler(handler p2p.MessageHandler) {
 	se.handlers = append(se.handlers, handler)
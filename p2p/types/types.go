@@ -54,6 +54,15 @@ const (
 
 	// PongSignal represents a pong respond to a peer
 	PongSignal = byte(0x1)
+
+	// RekeyRequestSignal starts a session key rotation with a peer
+	RekeyRequestSignal = byte(0x2)
+
+	// RekeyResponseSignal responds to a session key rotation request
+	RekeyResponseSignal = byte(0x3)
+
+	// RekeyDoneSignal marks the last packet sent with the previous session keys
+	RekeyDoneSignal = byte(0x4)
 )
 
 type StackError struct {
@@ -46,7 +46,7 @@ import (
 	ma "github.com/multiformats/go-multiaddr"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "p2pl"})
+var logger = util.GetLoggerForModule("p2pl")
 
 //
 // Messenger implements the Network interface
@@ -676,6 +676,35 @@ func (msgr *Messenger) PeerExists(peerID string) bool {
 	return msgr.peerTable.PeerExists(prID)
 }
 
+// ConnectToPeer connects to the peer at the given multiaddress, e.g. "/ip4/1.2.3.4/tcp/30001/p2p/<peer ID>"
+func (msgr *Messenger) ConnectToPeer(address string) error {
+	addr, err := ma.NewMultiaddr(address)
+	if err != nil {
+		return err
+	}
+	peerInfo, err := peerstore.InfoFromP2pAddr(addr)
+	if err != nil {
+		return err
+	}
+	if msgr.peerTable.PeerExists(peerInfo.ID) {
+		return fmt.Errorf("Already connected to peer %v", peerInfo.ID.Pretty())
+	}
+	return msgr.host.Connect(msgr.ctx, *peerInfo)
+}
+
+// DisconnectPeer disconnects from the given peer
+func (msgr *Messenger) DisconnectPeer(peerID string) error {
+	prID, err := pr.IDB58Decode(peerID)
+	if err != nil {
+		return err
+	}
+	if !msgr.peerTable.PeerExists(prID) {
+		return fmt.Errorf("Peer %v not found", peerID)
+	}
+	msgr.peerTable.DeletePeer(prID)
+	return msgr.host.Network().ClosePeer(prID)
+}
+
 func (msgr *Messenger) recordReceivedBytes(cid common.ChannelIDEnum, size int) {
 	if !msgr.statsEnabled {
 		return
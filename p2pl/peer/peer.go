@@ -5,10 +5,10 @@ import (
 	"sync"
 	"time"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	cmn "github.com/thetatoken/theta/common"
 	p2ptypes "github.com/thetatoken/theta/p2p/types"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/p2pl/transport"
 
 	"github.com/libp2p/go-libp2p-core/network"
@@ -18,7 +18,7 @@ import (
 	ma "github.com/multiformats/go-multiaddr"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "p2pl"})
+var logger = util.GetLoggerForModule("p2pl")
 
 var Channels = []cmn.ChannelIDEnum{
 	cmn.ChannelIDCheckpoint,
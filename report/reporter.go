@@ -24,7 +24,7 @@ import (
 	"github.com/thetatoken/theta/version"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "reporter"})
+var logger = util.GetLoggerForModule("reporter")
 var reportPeersPort string = ":9000"
 var setPeersSuffix string = "/peers/set"
 var peerUrl string
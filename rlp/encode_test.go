@@ -219,6 +219,12 @@ var encTests = []encTest{
 	{val: &tailRaw{A: 1, Tail: []RawValue{}}, output: "C101"},
 	{val: &tailRaw{A: 1, Tail: nil}, output: "C101"},
 	{val: &hasIgnoredField{A: 1, B: 2, C: 3}, output: "C20103"},
+	{val: &optionalFields{A: 1}, output: "C101"},
+	{val: &optionalFields{A: 1, B: 2}, output: "C20102"},
+	{val: &optionalFields{A: 1, C: 3}, output: "C3018003"},
+	{val: &optionalAndTailField{A: 1}, output: "C101"},
+	{val: &optionalAndTailField{A: 1, Tail: []uint{3, 4}}, output: "C401800304"},
+	{val: &invalidOptional{A: 1}, error: "rlp: struct field rlp.invalidOptional.B needs \"optional\" tag because a preceding field is optional"},
 
 	// nil
 	{val: (*uint)(nil), output: "80"},
@@ -0,0 +1,361 @@
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/diagnostic"
+	"github.com/thetatoken/theta/common/resource"
+	"github.com/thetatoken/theta/common/util"
+)
+
+//
+// ThetaAdminService serves the "admin" namespace, which lets the operators manage the node without
+// SSH access or restarts. The admin methods are only served to the callers authenticated with the
+// credentials that allow them, or by the admin listener.
+//
+
+// adminNamespace matches the methods of the admin namespace
+var adminNamespace = methodPatterns{"admin.*"}
+
+type ThetaAdminService struct {
+	service   *ThetaRPCService
+	startTime time.Time
+
+	shutdownHandler func()        // nil if shutdown is not supported
+	reloadHandler   ReloadHandler // nil if config reload is not supported
+
+	profiler   *profiler
+	profileDir string // empty if capturing profiles is not supported
+
+	exportMu     sync.Mutex
+	exportStatus SnapshotExportStatus
+}
+
+func newThetaAdminService(service *ThetaRPCService) *ThetaAdminService {
+	return &ThetaAdminService{
+		service:   service,
+		startTime: time.Now(),
+	}
+}
+
+// ------------------------------ ListPeers -----------------------------------
+
+type ListPeersArgs struct {
+	SkipEdgeNode bool `json:"skip_edge_node"`
+}
+
+type ListPeersResult struct {
+	NumPeers common.JSONUint64 `json:"num_peers"`
+	Peers    []string          `json:"peers"`
+	PeerURLs []string          `json:"peer_urls"`
+}
+
+func (a *ThetaAdminService) ListPeers(args *ListPeersArgs, result *ListPeersResult) (err error) {
+	result.Peers = a.service.dispatcher.Peers(args.SkipEdgeNode)
+	result.PeerURLs = a.service.dispatcher.PeerURLs(args.SkipEdgeNode)
+	result.NumPeers = common.JSONUint64(len(result.Peers))
+	return nil
+}
+
+// ------------------------------ AddPeer -----------------------------------
+
+type AddPeerArgs struct {
+	Address string `json:"address"` // "<ip>:<port>", or the multiaddress for the libp2p network
+}
+
+type AddPeerResult struct {
+}
+
+func (a *ThetaAdminService) AddPeer(args *AddPeerArgs, result *AddPeerResult) (err error) {
+	if args.Address == "" {
+		return errInvalidParams("Peer address is required")
+	}
+	if err := a.service.dispatcher.ConnectToPeer(args.Address); err != nil {
+		return newRPCError(ErrCodeServer, ReasonPeerConnectionFailed, "Failed to connect to peer %v: %v", args.Address, err)
+	}
+	logger.Infof("Connected to peer %v on admin request", args.Address)
+	return nil
+}
+
+// ------------------------------ RemovePeer -----------------------------------
+
+type RemovePeerArgs struct {
+	PeerID string `json:"peer_id"`
+}
+
+type RemovePeerResult struct {
+}
+
+func (a *ThetaAdminService) RemovePeer(args *RemovePeerArgs, result *RemovePeerResult) (err error) {
+	if !a.service.dispatcher.PeerExists(args.PeerID) {
+		return errNotFound("Peer %v not found", args.PeerID)
+	}
+	if err := a.service.dispatcher.DisconnectPeer(args.PeerID); err != nil {
+		return newRPCError(ErrCodeServer, ReasonPeerConnectionFailed, "Failed to disconnect peer %v: %v", args.PeerID, err)
+	}
+	logger.Infof("Disconnected peer %v on admin request", args.PeerID)
+	return nil
+}
+
+// ------------------------------ GetLogLevels -----------------------------------
+
+type GetLogLevelsArgs struct {
+}
+
+type GetLogLevelsResult struct {
+	Levels map[string]string `json:"levels"` // module -> level, "*" is the level of the other modules
+}
+
+func (a *ThetaAdminService) GetLogLevels(args *GetLogLevelsArgs, result *GetLogLevelsResult) (err error) {
+	result.Levels = util.GetLogLevels()
+	return nil
+}
+
+// ------------------------------ SetLogLevel -----------------------------------
+
+type SetLogLevelArgs struct {
+	Module string `json:"module"` // e.g. "consensus", "*" for all the modules without their own levels
+	Level  string `json:"level"`  // one of "panic", "fatal", "error", "warn", "info" and "debug"
+}
+
+type SetLogLevelResult struct {
+}
+
+func (a *ThetaAdminService) SetLogLevel(args *SetLogLevelArgs, result *SetLogLevelResult) (err error) {
+	if args.Module == "" {
+		return errInvalidParams("Module is required")
+	}
+	if err := util.SetLogLevel(args.Module, args.Level); err != nil {
+		return errInvalidParams("%v", err)
+	}
+	logger.Infof("Log level of %v set to %v on admin request", args.Module, args.Level)
+	return nil
+}
+
+// ------------------------------ ReloadConfig -----------------------------------
+
+// ReloadHandler re-reads the config file and applies the reloadable settings. It returns the
+// changed settings which have been applied, and those which only take effect after a restart.
+type ReloadHandler func() (applied []string, restartRequired []string, err error)
+
+type ReloadConfigArgs struct {
+}
+
+type ReloadConfigResult struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restart_required"`
+}
+
+// ReloadConfig reloads the config file the same way as sending SIGHUP to the node.
+func (a *ThetaAdminService) ReloadConfig(args *ReloadConfigArgs, result *ReloadConfigResult) (err error) {
+	if a.reloadHandler == nil {
+		return newRPCError(ErrCodeNotSupported, ReasonFeatureNotEnabled, "Config reload is not supported by this node")
+	}
+	applied, restartRequired, err := a.reloadHandler()
+	if err != nil {
+		return errInternal("Failed to reload the config: %v", err)
+	}
+	result.Applied = applied
+	result.RestartRequired = restartRequired
+	logger.Infof("Config reloaded on admin request")
+	return nil
+}
+
+// ------------------------------ ExportSnapshot -----------------------------------
+
+type ExportSnapshotArgs struct {
+	Config  string `json:"config"` // the snapshot is written under <config>/backup/snapshot
+	Height  uint64 `json:"height"`
+	Version uint64 `json:"version"`
+}
+
+type ExportSnapshotResult struct {
+}
+
+type SnapshotExportStatus struct {
+	Running      bool              `json:"running"`
+	Height       common.JSONUint64 `json:"height"`
+	StartedAt    common.JSONUint64 `json:"started_at"`  // unix timestamp in seconds
+	FinishedAt   common.JSONUint64 `json:"finished_at"` // unix timestamp in seconds, 0 if running
+	SnapshotFile string            `json:"snapshot_file"`
+	Error        string            `json:"error"`
+}
+
+// ExportSnapshot starts exporting a snapshot in the background, since the export usually takes
+// longer than the RPC timeout. Its progress is reported by GetSnapshotExportStatus.
+func (a *ThetaAdminService) ExportSnapshot(args *ExportSnapshotArgs, result *ExportSnapshotResult) (err error) {
+	if args.Config == "" {
+		return errInvalidParams("Config path is required")
+	}
+
+	a.exportMu.Lock()
+	defer a.exportMu.Unlock()
+	if a.exportStatus.Running {
+		return newRPCError(ErrCodeLimitExceeded, ReasonAlreadyRunning, "A snapshot export is already running")
+	}
+	if err := a.service.checkResources(); err != nil {
+		return err
+	}
+	a.exportStatus = SnapshotExportStatus{
+		Running:   true,
+		Height:    common.JSONUint64(args.Height),
+		StartedAt: common.JSONUint64(time.Now().Unix()),
+	}
+
+	backupArgs := &BackupSnapshotArgs{Config: args.Config, Height: args.Height, Version: args.Version}
+	go func() {
+		backupResult := &BackupSnapshotResult{}
+		err := a.service.BackupSnapshot(backupArgs, backupResult)
+
+		a.exportMu.Lock()
+		defer a.exportMu.Unlock()
+		a.exportStatus.Running = false
+		a.exportStatus.FinishedAt = common.JSONUint64(time.Now().Unix())
+		a.exportStatus.SnapshotFile = backupResult.SnapshotFile
+		if err != nil {
+			a.exportStatus.Error = err.Error()
+			logger.Errorf("Failed to export snapshot: %v", err)
+		}
+	}()
+	return nil
+}
+
+// ------------------------------ GetSnapshotExportStatus -----------------------------------
+
+type GetSnapshotExportStatusArgs struct {
+}
+
+type GetSnapshotExportStatusResult struct {
+	SnapshotExportStatus
+}
+
+func (a *ThetaAdminService) GetSnapshotExportStatus(args *GetSnapshotExportStatusArgs, result *GetSnapshotExportStatusResult) (err error) {
+	a.exportMu.Lock()
+	defer a.exportMu.Unlock()
+	result.SnapshotExportStatus = a.exportStatus
+	return nil
+}
+
+// ------------------------------ GetNodeStats -----------------------------------
+
+type GetNodeStatsArgs struct {
+}
+
+type GetNodeStatsResult struct {
+	Sync           *GetStatusResult        `json:"sync"`
+	Mempool        *GetMempoolStatusResult `json:"mempool"`
+	NumPeers       common.JSONUint64       `json:"num_peers"`
+	NumGoroutines  common.JSONUint64       `json:"num_goroutines"`
+	HeapAllocBytes common.JSONUint64       `json:"heap_alloc_bytes"`
+	UptimeSecs     common.JSONUint64       `json:"uptime_secs"`
+	Resources      resource.Status         `json:"resources"`
+}
+
+func (a *ThetaAdminService) GetNodeStats(args *GetNodeStatsArgs, result *GetNodeStatsResult) (err error) {
+	result.Sync = &GetStatusResult{}
+	if err := a.service.GetStatus(&GetStatusArgs{}, result.Sync); err != nil {
+		return err
+	}
+	result.Mempool = &GetMempoolStatusResult{}
+	if err := a.service.GetMempoolStatus(&GetMempoolStatusArgs{}, result.Mempool); err != nil {
+		return err
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	result.NumPeers = common.JSONUint64(len(a.service.dispatcher.Peers(false)))
+	result.NumGoroutines = common.JSONUint64(runtime.NumGoroutine())
+	result.HeapAllocBytes = common.JSONUint64(memStats.HeapAlloc)
+	result.UptimeSecs = common.JSONUint64(time.Since(a.startTime) / time.Second)
+	result.Resources = a.service.resources.Status()
+	return nil
+}
+
+// ------------------------------ Shutdown -----------------------------------
+
+type ShutdownArgs struct {
+}
+
+type ShutdownResult struct {
+}
+
+// Shutdown initiates a graceful shutdown of the node. The shutdown starts shortly after the
+// response is sent.
+func (a *ThetaAdminService) Shutdown(args *ShutdownArgs, result *ShutdownResult) (err error) {
+	if a.shutdownHandler == nil {
+		return newRPCError(ErrCodeNotSupported, ReasonFeatureNotEnabled, "Shutdown is not supported by this node")
+	}
+	logger.Warnf("Shutting down the node on admin request")
+	time.AfterFunc(time.Second, a.shutdownHandler)
+	return nil
+}
+
+// ------------------------------ GetDiagnosticBundle -----------------------------------
+
+type GetDiagnosticBundleArgs struct {
+}
+
+type GetDiagnosticBundleResult struct {
+	Bundle string `json:"bundle"`
+}
+
+// GetDiagnosticBundle returns the same diagnostic bundle the node writes when it crashes, i.e.
+// the goroutine stacks, the recent logs, the state of the chain and the redacted config.
+func (a *ThetaAdminService) GetDiagnosticBundle(args *GetDiagnosticBundleArgs, result *GetDiagnosticBundleResult) (err error) {
+	var buf bytes.Buffer
+	if err := diagnostic.WriteBundle(&buf, "requested by admin.GetDiagnosticBundle"); err != nil {
+		return errInternal("Failed to produce the diagnostic bundle: %v", err)
+	}
+	result.Bundle = buf.String()
+	return nil
+}
+
+// ------------------------------ CaptureProfile -----------------------------------
+
+type CaptureProfileArgs struct {
+	Type         string `json:"type"`          // cpu, heap, allocs, goroutine, block, mutex or threadcreate
+	DurationSecs uint64 `json:"duration_secs"` // the duration of the cpu, block and mutex profiles, capped by the config
+}
+
+type CaptureProfileResult struct {
+	File string `json:"file"`
+}
+
+// CaptureProfile writes a runtime profile to a file on the node, which can be analyzed with
+// "go tool pprof". The call returns after the duration of the profile.
+func (a *ThetaAdminService) CaptureProfile(args *CaptureProfileArgs, result *CaptureProfileResult) (err error) {
+	if a.profileDir == "" {
+		return newRPCError(ErrCodeNotSupported, ReasonFeatureNotEnabled, "Capturing profiles is not supported by this node")
+	}
+	if args.Type != "cpu" && !profileTypes[args.Type] {
+		return errInvalidParams("Unknown profile type: %v", args.Type)
+	}
+
+	if err := os.MkdirAll(a.profileDir, 0700); err != nil {
+		return errInternal("Failed to create the profile directory: %v", err)
+	}
+	filePath := path.Join(a.profileDir, fmt.Sprintf("%v-%v.pprof", args.Type, time.Now().UTC().Format("20060102-150405")))
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errInternal("Failed to create the profile file: %v", err)
+	}
+	defer file.Close()
+
+	duration := time.Duration(args.DurationSecs) * time.Second
+	if err := a.profiler.capture(a.service.ctx, args.Type, duration, file); err != nil {
+		os.Remove(filePath)
+		if err == errProfilerBusy {
+			return newRPCError(ErrCodeLimitExceeded, ReasonAlreadyRunning, "Another profile is being collected")
+		}
+		return errInternal("Failed to capture the profile: %v", err)
+	}
+	result.File = filePath
+	return nil
+}
@@ -0,0 +1,191 @@
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+var (
+	errInvalidAPIKey   = errors.New("invalid API key")
+	errInvalidJWT      = errors.New("invalid JWT")
+	errJWTExpired      = errors.New("JWT has expired")
+	errJWTNotSupported = errors.New("JWT authentication is not enabled")
+)
+
+// methodPatterns is a list of method name patterns in the syntax of path.Match, e.g. "theta.Get*"
+type methodPatterns []string
+
+func (mp methodPatterns) match(method string) bool {
+	for _, pattern := range mp {
+		if matched, _ := path.Match(pattern, method); matched {
+			return true
+		}
+	}
+	return false
+}
+
+type apiKey struct {
+	key     string
+	methods methodPatterns
+}
+
+// rpcAuthenticator authenticates the RPC callers with API keys or JWTs (HS256), and determines the
+// methods each caller is allowed to call. Callers without credentials can call the public methods.
+type rpcAuthenticator struct {
+	enabled       bool
+	apiKeys       []apiKey
+	jwtSecret     []byte
+	publicMethods methodPatterns
+}
+
+// newRPCAuthenticator creates the authenticator from the config. Each API key entry is in the
+// format of "<key>=<pattern>,<pattern>,...".
+func newRPCAuthenticator() (*rpcAuthenticator, error) {
+	a := &rpcAuthenticator{
+		enabled:       viper.GetBool(common.CfgRPCAuthEnabled),
+		jwtSecret:     []byte(viper.GetString(common.CfgRPCAuthJWTSecret)),
+		publicMethods: methodPatterns(viper.GetStringSlice(common.CfgRPCAuthPublicMethods)),
+	}
+	for _, entry := range viper.GetStringSlice(common.CfgRPCAuthAPIKeys) {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("Invalid API key entry, expected <key>=<methods>")
+		}
+		a.apiKeys = append(a.apiKeys, apiKey{
+			key:     parts[0],
+			methods: methodPatterns(strings.Split(parts[1], ",")),
+		})
+	}
+	return a, nil
+}
+
+// requestToken returns the API key or JWT carried by the request, or an empty string if none.
+func requestToken(r *http.Request) string {
+	token := r.Header.Get("X-API-Key")
+	if token == "" {
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+		}
+	}
+	if token == "" {
+		// Browsers can't set the headers of the WebSocket handshakes
+		token = r.URL.Query().Get("api_key")
+	}
+	return token
+}
+
+// authenticate returns the methods the caller of the request is allowed to call.
+func (a *rpcAuthenticator) authenticate(r *http.Request) (methodPatterns, error) {
+	token := requestToken(r)
+	if token == "" {
+		return a.publicMethods, nil
+	}
+
+	for _, k := range a.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(k.key), []byte(token)) == 1 {
+			return k.methods, nil
+		}
+	}
+	if strings.Count(token, ".") == 2 {
+		return a.verifyJWT(token)
+	}
+	return nil, errInvalidAPIKey
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Exp     int64    `json:"exp"`
+	Methods []string `json:"methods"` // optional, the public methods are allowed if not set
+}
+
+// verifyJWT verifies an HS256 signed JWT and returns the methods listed in its claims.
+func (a *rpcAuthenticator) verifyJWT(token string) (methodPatterns, error) {
+	if len(a.jwtSecret) == 0 {
+		return nil, errJWTNotSupported
+	}
+
+	parts := strings.Split(token, ".")
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errInvalidJWT
+	}
+	header := jwtHeader{}
+	if err := json.Unmarshal(headerBytes, &header); err != nil || header.Alg != "HS256" {
+		return nil, errInvalidJWT
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errInvalidJWT
+	}
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errInvalidJWT
+	}
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errInvalidJWT
+	}
+	claims := jwtClaims{}
+	if err := json.Unmarshal(claimsBytes, &claims); err != nil {
+		return nil, errInvalidJWT
+	}
+	if claims.Exp != 0 && time.Now().Unix() >= claims.Exp {
+		return nil, errJWTExpired
+	}
+	if claims.Methods == nil {
+		return a.publicMethods, nil
+	}
+	return methodPatterns(claims.Methods), nil
+}
+
+// authMiddleware rejects the requests with invalid credentials, and attaches a method filter to
+// the context of the other requests. Methods matching the excluded patterns are rejected
+// regardless of the credentials, which keeps the admin methods off the public listener. Methods
+// matching the restricted patterns are only served to the callers with credentials, even if they
+// also match the public methods.
+func (a *rpcAuthenticator) authMiddleware(handler http.Handler, excluded methodPatterns, restricted methodPatterns) http.Handler {
+	if !a.enabled && len(excluded) == 0 && len(restricted) == 0 {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var allowed methodPatterns
+		authenticated := false
+		if a.enabled && r.Method != "OPTIONS" {
+			var err error
+			if allowed, err = a.authenticate(r); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			authenticated = requestToken(r) != ""
+		}
+
+		filter := func(method string) error {
+			if excluded.match(method) || a.enabled && !allowed.match(method) || !authenticated && restricted.match(method) {
+				return newRPCError(ErrCodeMethodNotAllowed, ReasonMethodNotAllowed, "method %v is not allowed", method)
+			}
+			return nil
+		}
+		handler.ServeHTTP(w, r.WithContext(jsonrpc2.WithMethodFilter(r.Context(), filter)))
+	})
+}
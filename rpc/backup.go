@@ -3,11 +3,21 @@ package rpc
 import (
 	"os"
 	"path"
+	"strings"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/snapshot"
 )
 
+// checkResources rejects the non-essential work while the resources of the node run low.
+func (t *ThetaRPCService) checkResources() error {
+	if status := t.resources.Status(); len(status.Alerts) > 0 {
+		return newRPCError(ErrCodeLimitExceeded, ReasonResourceExhausted, "Snapshot export paused, resources low: %v",
+			strings.Join(status.Alerts, "; "))
+	}
+	return nil
+}
+
 // ------------------------------- BackupSnapshot -----------------------------------
 
 type BackupSnapshotArgs struct {
@@ -21,6 +31,10 @@ type BackupSnapshotResult struct {
 }
 
 func (t *ThetaRPCService) BackupSnapshot(args *BackupSnapshotArgs, result *BackupSnapshotResult) error {
+	if err := t.checkResources(); err != nil {
+		return err
+	}
+
 	// Default to older verison
 	if args.Version == 0 {
 		args.Version = 2
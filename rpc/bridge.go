@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"encoding/hex"
+
+	"github.com/thetatoken/theta/bridge"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// ------------------------------- GetBridgeProof -----------------------------------
+
+type GetBridgeProofArgs struct {
+	Height          common.JSONUint64 `json:"height"`
+	TxHashes        []common.Hash     `json:"tx_hashes"`        // the transactions to prove, all in the block at the height; if empty, only the finality of the block is proven
+	IncludeReceipts bool              `json:"include_receipts"` // also prove the receipts, and hence the events, of the transactions
+}
+
+type GetBridgeProofResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	TxIndices   []uint64          `json:"tx_indices"`
+	Bundle      string            `json:"bundle"` // the hex encoded bridge.ProofBundle
+}
+
+// GetBridgeProof returns the bundle which proves to an external chain that the transactions, and
+// optionally their receipts, are committed by a finalized block. The bundle is verified with
+// bridge.ProofBundle.Verify() against the validator set of the block, see theta.GetValidatorSet.
+func (t *ThetaRPCService) GetBridgeProof(args *GetBridgeProofArgs, result *GetBridgeProofResult) (err error) {
+	height := uint64(args.Height)
+	if height == 0 {
+		return errInvalidParams("Block height must be specified")
+	}
+	if args.IncludeReceipts && height < common.HeightEnableTxReceiptRoot {
+		return errInvalidParams("Receipts are not committed by the blocks below height %v", common.HeightEnableTxReceiptRoot)
+	}
+
+	// The validator manager panics if the state is missing, so check that the state is available first
+	if _, err := t.getLedgerStateAtHeight(height); err != nil {
+		return err
+	}
+	block := t.findFinalizedBlockByHeight(height)
+	if block == nil {
+		return errNotFound("No finalized block found at height %v", height)
+	}
+	validators := t.consensus.GetValidatorManager().GetValidatorSet(block.Hash())
+
+	indices := []uint64{}
+	for _, txHash := range args.TxHashes {
+		index, ok := findTxIndex(block, txHash)
+		if !ok {
+			return errNotFound("Transaction %v is not in block %v", txHash.Hex(), block.Hash().Hex())
+		}
+		indices = append(indices, index)
+	}
+
+	var receipts []*types.TxReceipt
+	if args.IncludeReceipts {
+		for _, rawTx := range block.Txs {
+			receipt, found := t.chain.FindCanonicalTxReceiptByHash(crypto.Keccak256Hash(rawTx))
+			if !found {
+				return errNotFound("Receipt of transaction %v not found", crypto.Keccak256Hash(rawTx).Hex())
+			}
+			receipts = append(receipts, receipt)
+		}
+	}
+
+	child, childCC, ok := t.findFinalizingChild(block, validators)
+	if !ok {
+		return errNotFound("No commit certificate found for block %v yet", block.Hash().Hex())
+	}
+
+	bundle, err := bridge.NewProofBundle(block.Block, child.BlockHeader, childCC, receipts, indices)
+	if err != nil {
+		return errInternal("Failed to build the proof bundle: %v", err)
+	}
+	if err := bundle.Verify(block.ChainID, validators); err != nil {
+		return errInternal("Failed to verify the proof bundle: %v", err)
+	}
+	raw, err := bundle.Encode()
+	if err != nil {
+		return errInternal("Failed to encode the proof bundle: %v", err)
+	}
+
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.BlockHash = block.Hash()
+	result.TxIndices = indices
+	result.Bundle = hex.EncodeToString(raw)
+	return nil
+}
+
+func findTxIndex(block *core.ExtendedBlock, txHash common.Hash) (uint64, bool) {
+	for i, rawTx := range block.Txs {
+		if crypto.Keccak256Hash(rawTx) == txHash {
+			return uint64(i), true
+		}
+	}
+	return 0, false
+}
+
+// findFinalizingChild finds the committed child block which carries the commit certificate of the
+// block in its HCC, along with the commit certificate of the child block itself.
+func (t *ThetaRPCService) findFinalizingChild(block *core.ExtendedBlock, validators *core.ValidatorSet) (*core.ExtendedBlock, core.CommitCertificate, bool) {
+	for _, childHash := range block.Children {
+		child, err := t.chain.FindBlock(childHash)
+		if err != nil || child.Parent != block.Hash() || child.HCC.BlockHash != block.Hash() || !child.HCC.IsValid(validators) {
+			continue
+		}
+		if cc, ok := t.findCommitCertificate(child, validators); ok {
+			return child, cc, true
+		}
+	}
+	return nil, core.CommitCertificate{}, false
+}
+
+// findCommitCertificate finds the commit certificate of the block, either in the HCC of one of its
+// children, or from the votes received for the block.
+func (t *ThetaRPCService) findCommitCertificate(block *core.ExtendedBlock, validators *core.ValidatorSet) (core.CommitCertificate, bool) {
+	for _, childHash := range block.Children {
+		child, err := t.chain.FindBlock(childHash)
+		if err != nil || child.HCC.BlockHash != block.Hash() {
+			continue
+		}
+		if child.HCC.IsValid(validators) {
+			return child.HCC.Copy(), true
+		}
+	}
+
+	cc := core.CommitCertificate{
+		Votes:     t.chain.FindVotesByHash(block.Hash()).UniqueVoter(),
+		BlockHash: block.Hash(),
+	}
+	if cc.IsValid(validators) {
+		return cc, true
+	}
+	return core.CommitCertificate{}, false
+}
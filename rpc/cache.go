@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"container/list"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+)
+
+type responseCacheEntry struct {
+	key     string
+	value   interface{} // a shallow copy of the result, which must not be modified after being cached
+	size    int
+	expires time.Time
+}
+
+// responseCache is an LRU cache of the RPC results about the immutable data, e.g. the blocks and
+// the transactions finalized before the latest finalized block. The total size of the entries,
+// estimated by their JSON encoding, is kept within the memory budget, and each entry expires
+// after the TTL in case the underlying data is rolled back or pruned.
+type responseCache struct {
+	mu      *sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is the most recently used
+	size    int
+	maxSize int
+	ttl     time.Duration
+}
+
+// newResponseCache creates the cache from the config, or returns nil if the cache is disabled.
+func newResponseCache() *responseCache {
+	maxSize := viper.GetInt(common.CfgRPCCacheSizeMB) * 1024 * 1024
+	if maxSize <= 0 {
+		return nil
+	}
+	return &responseCache{
+		mu:      &sync.Mutex{},
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		maxSize: maxSize,
+		ttl:     viper.GetDuration(common.CfgRPCCacheTTLSecs) * time.Second,
+	}
+}
+
+func responseCacheKey(method string, args interface{}) (string, bool) {
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return method + ":" + string(argsBytes), true
+}
+
+// get fills the result (a pointer) with the cached result of the method, and returns false if not cached.
+func (c *responseCache) get(method string, args interface{}, result interface{}) bool {
+	if c == nil {
+		return false
+	}
+	key, ok := responseCacheKey(method, args)
+	if !ok {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	entry := elem.Value.(*responseCacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		c.remove(elem)
+		return false
+	}
+	c.lru.MoveToFront(elem)
+	reflect.ValueOf(result).Elem().Set(reflect.ValueOf(entry.value))
+	return true
+}
+
+// put caches the result (a pointer) of the method.
+func (c *responseCache) put(method string, args interface{}, result interface{}) {
+	if c == nil {
+		return
+	}
+	key, ok := responseCacheKey(method, args)
+	if !ok {
+		return
+	}
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	size := len(key) + len(resultBytes)
+	if size > c.maxSize/16 {
+		return // too large to be worth evicting other entries
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	entry := &responseCacheEntry{
+		key:     key,
+		value:   reflect.ValueOf(result).Elem().Interface(),
+		size:    size,
+		expires: time.Now().Add(c.ttl),
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.size += size
+
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *responseCache) remove(elem *list.Element) {
+	entry := elem.Value.(*responseCacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
+
+// isSettled returns whether the block at the given height can no longer change, i.e. it has a
+// finalized descendant.
+func (t *ThetaRPCService) isSettled(height uint64) bool {
+	return height < t.consensus.GetLastFinalizedBlock().Height
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"container/list"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func newTestResponseCache(maxSize int, ttl time.Duration) *responseCache {
+	return &responseCache{
+		mu:      &sync.Mutex{},
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		maxSize: maxSize,
+		ttl:     ttl,
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newTestResponseCache(1024*1024, time.Hour)
+	args := &GetBlockByHeightArgs{Height: common.JSONUint64(10)}
+	result := &GetBlockResult{GetBlockResultInner: &GetBlockResultInner{Height: common.JSONUint64(10)}}
+
+	cached := &GetBlockResult{}
+	assert.False(c.get("GetBlockByHeight", args, cached))
+
+	c.put("GetBlockByHeight", args, result)
+	assert.True(c.get("GetBlockByHeight", args, cached))
+	assert.Equal(common.JSONUint64(10), cached.Height)
+
+	// Different arguments or methods are cached separately
+	assert.False(c.get("GetBlockByHeight", &GetBlockByHeightArgs{Height: common.JSONUint64(10), IncludeEthTxHashes: true}, cached))
+	assert.False(c.get("GetBlock", args, cached))
+
+	// A nil cache is disabled
+	var disabled *responseCache
+	disabled.put("GetBlockByHeight", args, result)
+	assert.False(disabled.get("GetBlockByHeight", args, cached))
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newTestResponseCache(64*1024, time.Hour)
+	for height := 0; height < 100; height++ {
+		args := &GetBlockByHeightArgs{Height: common.JSONUint64(height)}
+		c.put("GetBlockByHeight", args, &GetBlockResult{GetBlockResultInner: &GetBlockResultInner{Height: common.JSONUint64(height)}})
+		assert.True(c.size <= c.maxSize)
+	}
+
+	// The least recently used entries are evicted first
+	cached := &GetBlockResult{}
+	assert.False(c.get("GetBlockByHeight", &GetBlockByHeightArgs{Height: common.JSONUint64(0)}, cached))
+	assert.True(c.get("GetBlockByHeight", &GetBlockByHeightArgs{Height: common.JSONUint64(99)}, cached))
+	assert.Equal(len(c.entries), c.lru.Len())
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newTestResponseCache(1024*1024, time.Millisecond)
+	args := &GetBlockByHeightArgs{Height: common.JSONUint64(1)}
+	c.put("GetBlockByHeight", args, &GetBlockResult{GetBlockResultInner: &GetBlockResultInner{}})
+
+	time.Sleep(5 * time.Millisecond)
+	assert.False(c.get("GetBlockByHeight", args, &GetBlockResult{}))
+	assert.Equal(0, c.size)
+}
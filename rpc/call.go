@@ -2,9 +2,12 @@ package rpc
 
 import (
 	"encoding/hex"
-	"fmt"
+	"math/big"
+	"strings"
 
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/ledger/vm"
@@ -13,7 +16,9 @@ import (
 // ------------------------------- CallSmartContract -----------------------------------
 
 type CallSmartContractArgs struct {
-	SctxBytes string `json:"sctx_bytes"`
+	SctxBytes      string                             `json:"sctx_bytes"`
+	Height         common.JSONUint64                  `json:"height"`          // optional, calls against the state after the finalized block at the height
+	StateOverrides map[common.Address]AccountOverride `json:"state_overrides"` // optional, the accounts replaced for the call
 }
 
 type CallSmartContractResult struct {
@@ -28,14 +33,27 @@ type CallSmartContractResult struct {
 // without actually spending gas.
 func (t *ThetaRPCService) CallSmartContract(args *CallSmartContractArgs, result *CallSmartContractResult) (err error) {
 	var ledgerState *state.StoreView
-	ledgerState, err = t.ledger.GetDeliveredSnapshot()
-	if err != nil {
+	var parentBlock *core.Block
+	if args.Height == 0 {
+		ledgerState, err = t.ledger.GetDeliveredSnapshot()
+		if err != nil {
+			return err
+		}
+		parentBlock = t.ledger.State().ParentBlock()
+	} else {
+		ledgerState, err = t.getLedgerStateAtHeight(uint64(args.Height))
+		if err != nil {
+			return err
+		}
+		parentBlock = t.findFinalizedBlockByHeight(uint64(args.Height)).Block
+	}
+	if err := applyStateOverrides(ledgerState, args.StateOverrides); err != nil {
 		return err
 	}
 
 	blockHeight := ledgerState.Height() + 1 // the view points to the parent of the current block
 	if blockHeight < common.HeightEnableSmartContract {
-		return fmt.Errorf("Smart contract feature not enabled until block height %v.", common.HeightEnableSmartContract)
+		return newRPCError(ErrCodeNotSupported, ReasonFeatureNotEnabled, "Smart contract feature not enabled until block height %v.", common.HeightEnableSmartContract)
 	}
 
 	sctxBytes, err := hex.DecodeString(args.SctxBytes)
@@ -45,15 +63,24 @@ func (t *ThetaRPCService) CallSmartContract(args *CallSmartContractArgs, result
 
 	tx, err := types.TxFromBytes(sctxBytes)
 	if err != nil {
-		return fmt.Errorf("Failed to parse SmartContractTx, error: %v", err)
+		return errInvalidParams("Failed to parse SmartContractTx, error: %v", err)
 	}
-	sctx, ok := tx.(*types.SmartContractTx)
-	if !ok {
-		return fmt.Errorf("Failed to parse SmartContractTx: %v", args.SctxBytes)
+	var vmRet common.Bytes
+	var contractAddr common.Address
+	var gasUsed uint64
+	var vmErr error
+	switch sctx := tx.(type) {
+	case *types.SmartContractTx:
+		vmRet, contractAddr, gasUsed, vmErr = vm.Execute(parentBlock, sctx, ledgerState)
+	case *types.SmartContractTxV2:
+		if sctx.Runtime == types.ContractRuntimeWASM {
+			vmRet, contractAddr, gasUsed, vmErr = vm.ExecuteWasm(parentBlock, sctx.SmartContractTx(), ledgerState)
+		} else {
+			vmRet, contractAddr, gasUsed, vmErr = vm.Execute(parentBlock, sctx.SmartContractTx(), ledgerState)
+		}
+	default:
+		return errInvalidParams("Failed to parse SmartContractTx: %v", args.SctxBytes)
 	}
-
-	parentBlock := t.ledger.State().ParentBlock()
-	vmRet, contractAddr, gasUsed, vmErr := vm.Execute(parentBlock, sctx, ledgerState)
 	ledgerState.Save()
 
 	result.VmReturn = hex.EncodeToString(vmRet)
@@ -65,3 +92,259 @@ func (t *ThetaRPCService) CallSmartContract(args *CallSmartContractArgs, result
 
 	return nil
 }
+
+// AccountOverride replaces the fields of an account for a simulated call without touching the
+// actual state, e.g. to test a call with a fake balance or a patched contract. The fields left
+// empty are kept. State replaces the whole contract storage, while StateDiff only replaces the
+// given slots, so at most one of them can be set.
+type AccountOverride struct {
+	Sequence     *common.JSONUint64          `json:"sequence"`
+	ThetaBalance *common.JSONBig             `json:"theta_balance"` // in ThetaWei
+	TFuelBalance *common.JSONBig             `json:"tfuel_balance"` // in TFuelWei
+	Code         *string                     `json:"code"`          // the contract bytecode in hex
+	State        map[common.Hash]common.Hash `json:"state"`
+	StateDiff    map[common.Hash]common.Hash `json:"state_diff"`
+}
+
+// applyStateOverrides applies the account overrides to the state view the call executes against
+func applyStateOverrides(view *state.StoreView, overrides map[common.Address]AccountOverride) error {
+	for addr, override := range overrides {
+		if override.State != nil && override.StateDiff != nil {
+			return errInvalidParams("Both the state and the state diff of account %v are overridden", addr.Hex())
+		}
+		var code []byte
+		if override.Code != nil {
+			var err error
+			if code, err = hex.DecodeString(strings.TrimPrefix(*override.Code, "0x")); err != nil {
+				return errInvalidParams("Invalid code override for account %v: %v", addr.Hex(), err)
+			}
+		}
+
+		account := view.GetOrCreateAccount(addr)
+		account.Balance = account.Balance.NoNil()
+		if override.Sequence != nil {
+			account.Sequence = uint64(*override.Sequence)
+		}
+		if override.ThetaBalance != nil {
+			if override.ThetaBalance.ToInt().Sign() < 0 {
+				return errInvalidParams("Negative theta balance override for account %v", addr.Hex())
+			}
+			account.Balance.ThetaWei = new(big.Int).Set(override.ThetaBalance.ToInt())
+		}
+		if override.TFuelBalance != nil {
+			if override.TFuelBalance.ToInt().Sign() < 0 {
+				return errInvalidParams("Negative tfuel balance override for account %v", addr.Hex())
+			}
+			account.Balance.TFuelWei = new(big.Int).Set(override.TFuelBalance.ToInt())
+		}
+		if override.State != nil {
+			account.Root = common.Hash{} // start from an empty storage
+		}
+		view.SetAccount(addr, account)
+
+		if override.Code != nil {
+			view.SetCode(addr, code)
+		}
+		for key, value := range override.State {
+			view.SetState(addr, key, value)
+		}
+		for key, value := range override.StateDiff {
+			view.SetState(addr, key, value)
+		}
+	}
+	return nil
+}
+
+// ------------------------------- EstimateGas -----------------------------------
+
+type EstimateGasArgs struct {
+	SctxBytes      string                             `json:"sctx_bytes"`
+	StateOverrides map[common.Address]AccountOverride `json:"state_overrides"` // optional, the accounts replaced for the estimation
+}
+
+type EstimateGasResult struct {
+	GasLimit common.JSONUint64 `json:"gas_limit"`
+	GasPrice *common.JSONBig   `json:"gas_price"`
+	Fee      *common.JSONBig   `json:"fee"` // in TFuelWei
+	VmReturn string            `json:"vm_return"`
+}
+
+// EstimateGas simulates the smart contract transaction against the latest finalized state and
+// binary searches for the minimal gas limit with which the transaction executes successfully.
+// It also returns the expected transaction fee in TFuelWei for that gas limit. If the gas price
+// of the transaction is not set, the minimum gas price is used.
+func (t *ThetaRPCService) EstimateGas(args *EstimateGasArgs, result *EstimateGasResult) (err error) {
+	sctxBytes, err := hex.DecodeString(args.SctxBytes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := types.TxFromBytes(sctxBytes)
+	if err != nil {
+		return errInvalidParams("Failed to parse SmartContractTx, error: %v", err)
+	}
+	sctx, ok := tx.(*types.SmartContractTx)
+	if !ok {
+		return errInvalidParams("Failed to parse SmartContractTx: %v", args.SctxBytes)
+	}
+
+	finalizedState, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+	if err := applyStateOverrides(finalizedState, args.StateOverrides); err != nil {
+		return err
+	}
+	parentBlock := t.consensus.GetLastFinalizedBlock().Block
+
+	blockHeight := finalizedState.Height() + 1
+	if blockHeight < common.HeightEnableSmartContract {
+		return newRPCError(ErrCodeNotSupported, ReasonFeatureNotEnabled, "Smart contract feature not enabled until block height %v.", common.HeightEnableSmartContract)
+	}
+
+	if sctx.GasPrice == nil || sctx.GasPrice.Sign() == 0 {
+		sctx.GasPrice = types.GetMinimumGasPrice(blockHeight)
+	}
+
+	// Each execution runs on a fresh copy of the finalized state
+	execute := func(gasLimit uint64) (common.Bytes, error) {
+		view, err := finalizedState.Copy()
+		if err != nil {
+			return nil, err
+		}
+		sctx.GasLimit = gasLimit
+		vmRet, _, _, vmErr := vm.Execute(parentBlock, sctx, view)
+		return vmRet, vmErr
+	}
+
+	hi := types.GetMaxGasLimit(blockHeight).Uint64()
+	vmRet, vmErr := execute(hi)
+	if vmErr != nil {
+		return newRPCError(ErrCodeServer, ReasonExecutionFailed, "Transaction fails even with the maximum gas limit %v: %v", hi, vmErr)
+	}
+
+	lo := uint64(0) // lo always fails, hi always succeeds
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		ret, vmErr := execute(mid)
+		if vmErr != nil {
+			lo = mid
+		} else {
+			hi = mid
+			vmRet = ret
+		}
+	}
+
+	fee := new(big.Int).Mul(sctx.GasPrice, new(big.Int).SetUint64(hi))
+
+	result.GasLimit = common.JSONUint64(hi)
+	result.GasPrice = (*common.JSONBig)(sctx.GasPrice)
+	result.Fee = (*common.JSONBig)(fee)
+	result.VmReturn = hex.EncodeToString(vmRet)
+
+	return nil
+}
+
+// ------------------------------- CallTx -----------------------------------
+
+type CallTxArgs struct {
+	TxBytes   string            `json:"tx_bytes"`
+	Height    common.JSONUint64 `json:"height"`     // height of the block to execute on top of, 0 means the last finalized block
+	StateRoot string            `json:"state_root"` // state root of the block at the height, required if there are multiple blocks at the height
+}
+
+type CallTxResult struct {
+	TxHash      common.Hash       `json:"hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"` // height of the block the transaction would be included in
+	StateRoot   common.Hash       `json:"state_root"`   // state root the transaction is executed against
+	Success     bool              `json:"success"`
+	Error       string            `json:"error"`
+	Fee         types.Coins       `json:"fee"`
+	Receipt     *types.TxReceipt  `json:"receipt"`
+	StateDiffs  []state.StateDiff `json:"state_diffs"`
+}
+
+// CallTx executes a transaction of any type against the ledger state of the specified block
+// without committing the state changes. It returns the would-be result of the transaction,
+// including its receipt, the fee it consumes, and the changes it would make to the ledger state.
+// A transaction which fails the validation is reported in the result rather than as an error.
+func (t *ThetaRPCService) CallTx(args *CallTxArgs, result *CallTxResult) (err error) {
+	txBytes, err := hex.DecodeString(args.TxBytes)
+	if err != nil {
+		return err
+	}
+	tx, err := types.TxFromBytes(txBytes)
+	if err != nil {
+		return errInvalidParams("Failed to parse transaction, error: %v", err)
+	}
+
+	block, err := t.getCallTxBlock(args)
+	if err != nil {
+		return err
+	}
+
+	receipt, stateDiffs, res, err := t.ledger.SimulateTx(tx, block)
+	if err != nil {
+		return err
+	}
+
+	result.TxHash = crypto.Keccak256Hash(txBytes)
+	result.BlockHeight = common.JSONUint64(block.Height + 1)
+	result.StateRoot = block.StateHash
+	result.Success = res.IsOK()
+	if res.IsError() {
+		result.Error = res.Message
+		return nil
+	}
+	result.Fee = getTxFee(tx, receipt.GasUsed)
+	result.Receipt = receipt
+	result.StateDiffs = stateDiffs
+
+	return nil
+}
+
+// getCallTxBlock returns the block whose state the CallTx executes against
+func (t *ThetaRPCService) getCallTxBlock(args *CallTxArgs) (*core.Block, error) {
+	height := uint64(args.Height)
+	if height == 0 {
+		if args.StateRoot != "" {
+			return nil, errInvalidParams("The height must be specified along with the state root")
+		}
+		return t.consensus.GetLastFinalizedBlock().Block, nil
+	}
+
+	blocks := t.chain.FindBlocksByHeight(height)
+	if args.StateRoot == "" {
+		if len(blocks) != 1 {
+			return nil, errInvalidParams("Found %v blocks at height %v, the state root must be specified", len(blocks), height)
+		}
+		return blocks[0].Block, nil
+	}
+
+	stateRoot := common.HexToHash(args.StateRoot)
+	for _, b := range blocks {
+		if b.StateHash == stateRoot {
+			return b.Block, nil
+		}
+	}
+	return nil, errNotFound("No block with state root %v found at height %v", stateRoot.Hex(), height)
+}
+
+// getTxFee returns the fee the transaction consumes
+func getTxFee(tx types.Tx, gasUsed uint64) types.Coins {
+	var gasPrice *big.Int
+	switch tx := tx.(type) {
+	case *types.SmartContractTx:
+		gasPrice = tx.GasPrice
+	case *types.SmartContractTxV2:
+		gasPrice = tx.GasPrice
+	default:
+		return types.GetMaxTxFee(tx)
+	}
+
+	fee := types.NewCoins(0, 0)
+	if gasPrice != nil {
+		fee.TFuelWei = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasUsed))
+	}
+	return fee
+}
@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestApplyStateOverrides(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	view := state.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	contract := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	slot1, slot2 := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))
+	view.SetState(contract, slot1, common.BigToHash(big.NewInt(10)))
+	view.SetState(contract, slot2, common.BigToHash(big.NewInt(20)))
+
+	var overrides map[common.Address]AccountOverride
+	require.Nil(json.Unmarshal([]byte(`{
+		"0x0000000000000000000000000000000000000001": {
+			"tfuel_balance": "1000",
+			"code": "0x6001",
+			"state_diff": {"0x0000000000000000000000000000000000000000000000000000000000000001": "0x000000000000000000000000000000000000000000000000000000000000000b"}
+		},
+		"0x0000000000000000000000000000000000000002": {"sequence": "7", "theta_balance": "5"}
+	}`), &overrides))
+	require.Nil(applyStateOverrides(view, overrides))
+
+	account := view.GetAccount(contract)
+	require.NotNil(account)
+	assert.Equal(big.NewInt(1000), account.Balance.TFuelWei)
+	assert.Equal([]byte{0x60, 0x01}, view.GetCode(contract))
+	assert.Equal(common.BigToHash(big.NewInt(11)), view.GetState(contract, slot1))
+	assert.Equal(common.BigToHash(big.NewInt(20)), view.GetState(contract, slot2))
+
+	other := view.GetAccount(common.HexToAddress("0x0000000000000000000000000000000000000002"))
+	require.NotNil(other)
+	assert.Equal(uint64(7), other.Sequence)
+	assert.Equal(big.NewInt(5), other.Balance.ThetaWei)
+
+	// The state override replaces the whole storage
+	require.Nil(applyStateOverrides(view, map[common.Address]AccountOverride{
+		contract: {State: map[common.Hash]common.Hash{slot2: common.BigToHash(big.NewInt(21))}},
+	}))
+	assert.Equal(common.Hash{}, view.GetState(contract, slot1))
+	assert.Equal(common.BigToHash(big.NewInt(21)), view.GetState(contract, slot2))
+	assert.Equal(big.NewInt(1000), view.GetAccount(contract).Balance.TFuelWei)
+
+	// The state and the state diff are mutually exclusive
+	empty := map[common.Hash]common.Hash{}
+	assert.NotNil(applyStateOverrides(view, map[common.Address]AccountOverride{
+		contract: {State: empty, StateDiff: empty},
+	}))
+}
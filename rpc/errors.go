@@ -0,0 +1,151 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+//
+// The RPC methods report the errors as JSON-RPC error objects. The code is one of the ErrCode*
+// constants below, which follow the JSON-RPC 2.0 spec and EIP-1474, and the data carries an
+// ErrorReason that tells the specific cause. Both the codes and the reasons are stable, so the
+// clients should branch on them rather than on the messages, which are for humans only.
+//
+
+const (
+	ErrCodeMethodNotAllowed = -32601 // the caller is not allowed to call the method
+	ErrCodeInvalidParams    = -32602 // the arguments are missing or malformed
+	ErrCodeInternal         = -32603 // the node failed to serve the request
+	ErrCodeServer           = -32000 // errors not covered by the other codes
+	ErrCodeNotFound         = -32001 // the requested block, transaction, account, etc. does not exist
+	ErrCodeStateUnavailable = -32002 // the ledger state at the requested height is pruned or not finalized yet
+	ErrCodeTxRejected       = -32003 // the transaction is rejected by the mempool or the ledger
+	ErrCodeNotSupported     = -32004 // the request is not supported by the node, e.g. in read-only mode
+	ErrCodeLimitExceeded    = -32005 // a rate limit or capacity limit is exceeded
+	ErrCodeTimeout          = -32010 // the node timed out waiting for the outcome, e.g. the finalization of a transaction
+)
+
+// ErrorReason is the machine-readable cause of an RPC error.
+type ErrorReason string
+
+const (
+	ReasonInvalidParams          ErrorReason = "INVALID_PARAMS"
+	ReasonInternal               ErrorReason = "INTERNAL"
+	ReasonNotFound               ErrorReason = "NOT_FOUND"
+	ReasonStatePruned            ErrorReason = "STATE_PRUNED"
+	ReasonHeightNotFinalized     ErrorReason = "HEIGHT_NOT_FINALIZED"
+	ReasonFeatureNotEnabled      ErrorReason = "FEATURE_NOT_ENABLED"
+	ReasonExecutionFailed        ErrorReason = "EXECUTION_FAILED"
+	ReasonReadOnly               ErrorReason = "READ_ONLY"
+	ReasonTimeout                ErrorReason = "TIMEOUT"
+	ReasonServiceStopping        ErrorReason = "SERVICE_STOPPING"
+	ReasonRateLimited            ErrorReason = "RATE_LIMITED"
+	ReasonMethodNotAllowed       ErrorReason = "METHOD_NOT_ALLOWED"
+	ReasonAlreadyRunning         ErrorReason = "ALREADY_RUNNING"
+	ReasonPeerConnectionFailed   ErrorReason = "PEER_CONNECTION_FAILED"
+	ReasonTxRejected             ErrorReason = "TX_REJECTED" // rejected for a reason not listed below
+	ReasonInvalidSignature       ErrorReason = "INVALID_SIGNATURE"
+	ReasonInvalidSequence        ErrorReason = "INVALID_SEQUENCE"
+	ReasonInsufficientFunds      ErrorReason = "INSUFFICIENT_FUNDS"
+	ReasonInvalidFee             ErrorReason = "INVALID_FEE"
+	ReasonInvalidGasLimit        ErrorReason = "INVALID_GAS_LIMIT"
+	ReasonTxExpired              ErrorReason = "TX_EXPIRED"
+	ReasonTxEmpty                ErrorReason = "TX_EMPTY"
+	ReasonTxTooLarge             ErrorReason = "TX_TOO_LARGE"
+	ReasonDuplicateTx            ErrorReason = "DUPLICATE_TX"
+	ReasonReplacementUnderpriced ErrorReason = "REPLACEMENT_UNDERPRICED"
+	ReasonMempoolFull            ErrorReason = "MEMPOOL_FULL"
+	ReasonAccountTxLimit         ErrorReason = "ACCOUNT_TX_LIMIT"
+	ReasonFutureTxQueueFull      ErrorReason = "FUTURE_TX_QUEUE_FULL"
+	ReasonPriorityTxDisabled     ErrorReason = "PRIORITY_TX_DISABLED"
+	ReasonResourceExhausted      ErrorReason = "RESOURCE_EXHAUSTED" // the disk space, file descriptors or memory of the node run low
+)
+
+// ErrorData is the data of the RPC errors.
+type ErrorData struct {
+	Reason     ErrorReason      `json:"reason"`
+	LedgerCode result.ErrorCode `json:"ledger_code,omitempty"` // the code of the failed ledger check, for the rejected transactions
+	Height     uint64           `json:"height,omitempty"`      // the height involved, for the state errors
+}
+
+func newRPCError(code int, reason ErrorReason, format string, a ...interface{}) *jsonrpc2.Error {
+	return &jsonrpc2.Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, a...),
+		Data:    &ErrorData{Reason: reason},
+	}
+}
+
+func errInvalidParams(format string, a ...interface{}) *jsonrpc2.Error {
+	return newRPCError(ErrCodeInvalidParams, ReasonInvalidParams, format, a...)
+}
+
+func errNotFound(format string, a ...interface{}) *jsonrpc2.Error {
+	return newRPCError(ErrCodeNotFound, ReasonNotFound, format, a...)
+}
+
+func errInternal(format string, a ...interface{}) *jsonrpc2.Error {
+	return newRPCError(ErrCodeInternal, ReasonInternal, format, a...)
+}
+
+// errStateUnavailable reports that the ledger state at the given height can't be queried.
+func errStateUnavailable(reason ErrorReason, height uint64, format string, a ...interface{}) *jsonrpc2.Error {
+	err := newRPCError(ErrCodeStateUnavailable, reason, format, a...)
+	err.Data.(*ErrorData).Height = height
+	return err
+}
+
+// ledgerRejectionReasons maps the codes of the failed ledger checks to the reasons
+var ledgerRejectionReasons = map[result.ErrorCode]ErrorReason{
+	result.CodeInvalidSignature:        ReasonInvalidSignature,
+	result.CodeInvalidSequence:         ReasonInvalidSequence,
+	result.CodeInsufficientFund:        ReasonInsufficientFunds,
+	result.CodeNotEnoughBalanceToStake: ReasonInsufficientFunds,
+	result.CodeInvalidFee:              ReasonInvalidFee,
+	result.CodeInvalidGasPrice:         ReasonInvalidFee,
+	result.CodeInvalidGasLimit:         ReasonInvalidGasLimit,
+	result.CodeFeeLimitTooHigh:         ReasonInvalidGasLimit,
+	result.CodeTxExpired:               ReasonTxExpired,
+}
+
+// mempoolRejectionReasons maps the mempool errors to the reasons
+var mempoolRejectionReasons = map[mempool.MempoolError]ErrorReason{
+	mempool.EmptyTxError:                ReasonTxEmpty,
+	mempool.TxTooLargeError:             ReasonTxTooLarge,
+	mempool.DuplicateTxError:            ReasonDuplicateTx,
+	mempool.ReplacementUnderpricedError: ReasonReplacementUnderpriced,
+	mempool.MempoolFullError:            ReasonMempoolFull,
+	mempool.AccountTxLimitError:         ReasonAccountTxLimit,
+	mempool.FutureTxQueueFullError:      ReasonFutureTxQueueFull,
+	mempool.PriorityTxDisabledError:     ReasonPriorityTxDisabled,
+	mempool.RecentlyRejectedTxError:     ReasonTxRejected,
+}
+
+// errTxRejected converts the error returned by the mempool for a rejected transaction.
+func errTxRejected(err error) *jsonrpc2.Error {
+	switch e := err.(type) {
+	case *mempool.ScreeningError:
+		reason, ok := ledgerRejectionReasons[e.Result.Code]
+		if !ok {
+			reason = ReasonTxRejected
+		}
+		rpcErr := newRPCError(ErrCodeTxRejected, reason, "%v", e.Result.Message)
+		rpcErr.Data.(*ErrorData).LedgerCode = e.Result.Code
+		return rpcErr
+	case mempool.MempoolError:
+		reason, ok := mempoolRejectionReasons[e]
+		if !ok {
+			reason = ReasonTxRejected
+		}
+		code := ErrCodeTxRejected
+		if e == mempool.MempoolFullError || e == mempool.AccountTxLimitError || e == mempool.FutureTxQueueFullError {
+			code = ErrCodeLimitExceeded
+		}
+		return newRPCError(code, reason, "%v", e.Error())
+	default:
+		return newRPCError(ErrCodeTxRejected, ReasonTxRejected, "%v", err.Error())
+	}
+}
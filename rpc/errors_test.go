@@ -0,0 +1,43 @@
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+func TestErrTxRejected(t *testing.T) {
+	assert := assert.New(t)
+
+	screeningErr := &mempool.ScreeningError{Result: result.Error("Insufficient fund").WithErrorCode(result.CodeInsufficientFund)}
+	err := errTxRejected(screeningErr)
+	assert.Equal(ErrCodeTxRejected, err.Code)
+	assert.Equal("Insufficient fund", err.Message)
+	assert.Equal(ReasonInsufficientFunds, err.Data.(*ErrorData).Reason)
+	assert.Equal(result.CodeInsufficientFund, err.Data.(*ErrorData).LedgerCode)
+
+	err = errTxRejected(mempool.TxTooLargeError)
+	assert.Equal(ErrCodeTxRejected, err.Code)
+	assert.Equal(ReasonTxTooLarge, err.Data.(*ErrorData).Reason)
+
+	err = errTxRejected(mempool.MempoolFullError)
+	assert.Equal(ErrCodeLimitExceeded, err.Code)
+	assert.Equal(ReasonMempoolFull, err.Data.(*ErrorData).Reason)
+}
+
+func TestRPCErrorEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	// The JSON-RPC codec sends the error as is when its message is a JSON object
+	var rpcErr error = errStateUnavailable(ReasonStatePruned, 100, "The state at height %v has been pruned", 100)
+	decoded := &jsonrpc2.Error{}
+	assert.Nil(json.Unmarshal([]byte(rpcErr.Error()), decoded))
+	assert.Equal(ErrCodeStateUnavailable, decoded.Code)
+	assert.Equal("The state at height 100 has been pruned", decoded.Message)
+	assert.Equal(map[string]interface{}{"reason": "STATE_PRUNED", "height": float64(100)}, decoded.Data)
+}
@@ -0,0 +1,853 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/hexutil"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+	"github.com/thetatoken/theta/version"
+)
+
+// JSON-RPC 2.0 error codes used by the Ethereum clients
+const (
+	ethErrCodeParse          = -32700
+	ethErrCodeInvalidRequest = -32600
+	ethErrCodeMethodNotFound = -32601
+	ethErrCodeInvalidParams  = -32602
+	ethErrCodeServer         = -32000
+	ethErrCodeReverted       = 3
+)
+
+// maxEthRequestSize caps the size of the body of a request to the Ethereum JSON-RPC endpoint
+const maxEthRequestSize = 5 * 1024 * 1024
+
+// emptyUncleHash is the Keccak256 hash of the RLP encoding of an empty list
+var emptyUncleHash = common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347")
+
+type ethRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type ethError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+func (e *ethError) Error() string {
+	return e.Message
+}
+
+type ethResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *ethError       `json:"error,omitempty"`
+}
+
+type ethMethod func(t *ThetaRPCService, params []json.RawMessage) (interface{}, error)
+
+// ethMethods maps the supported Ethereum JSON-RPC methods onto the Theta ledger and EVM
+var ethMethods = map[string]ethMethod{
+	"web3_clientVersion":        (*ThetaRPCService).ethClientVersion,
+	"net_version":               (*ThetaRPCService).ethNetVersion,
+	"net_listening":             (*ThetaRPCService).ethNetListening,
+	"eth_chainId":               (*ThetaRPCService).ethChainID,
+	"eth_syncing":               (*ThetaRPCService).ethSyncing,
+	"eth_accounts":              (*ThetaRPCService).ethAccounts,
+	"eth_blockNumber":           (*ThetaRPCService).ethBlockNumber,
+	"eth_gasPrice":              (*ThetaRPCService).ethGasPrice,
+	"eth_getBalance":            (*ThetaRPCService).ethGetBalance,
+	"eth_getTransactionCount":   (*ThetaRPCService).ethGetTransactionCount,
+	"eth_getCode":               (*ThetaRPCService).ethGetCode,
+	"eth_getStorageAt":          (*ThetaRPCService).ethGetStorageAt,
+	"eth_call":                  (*ThetaRPCService).ethCall,
+	"eth_estimateGas":           (*ThetaRPCService).ethEstimateGas,
+	"eth_sendRawTransaction":    (*ThetaRPCService).ethSendRawTransaction,
+	"eth_getBlockByNumber":      (*ThetaRPCService).ethGetBlockByNumber,
+	"eth_getBlockByHash":        (*ThetaRPCService).ethGetBlockByHash,
+	"eth_getTransactionReceipt": (*ThetaRPCService).ethGetTransactionReceipt,
+	"eth_getLogs":               (*ThetaRPCService).ethGetLogs,
+}
+
+// ethHTTPHandler serves the Ethereum JSON-RPC methods, so that the Ethereum tooling such as
+// wallets and development frameworks can talk to the node directly. Batch requests are supported.
+type ethHTTPHandler struct {
+	service *ThetaRPCService
+}
+
+func (h *ethHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxEthRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	filter := jsonrpc2.MethodFilterFromContext(r.Context())
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var reqs []json.RawMessage
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			json.NewEncoder(w).Encode(newEthErrorResponse(nil, &ethError{Code: ethErrCodeParse, Message: err.Error()}))
+			return
+		}
+		resps := make([]*ethResponse, len(reqs))
+		for i, req := range reqs {
+			resps[i] = h.handle(req, filter)
+		}
+		json.NewEncoder(w).Encode(resps)
+		return
+	}
+
+	json.NewEncoder(w).Encode(h.handle(body, filter))
+}
+
+func (h *ethHTTPHandler) handle(raw json.RawMessage, filter jsonrpc2.MethodFilter) *ethResponse {
+	req := &ethRequest{}
+	if err := json.Unmarshal(raw, req); err != nil {
+		return newEthErrorResponse(nil, &ethError{Code: ethErrCodeInvalidRequest, Message: err.Error()})
+	}
+
+	if filter != nil {
+		if err := filter(req.Method); err != nil {
+			ethErr := &ethError{Code: ethErrCodeMethodNotFound, Message: err.Error()}
+			if jsonErr, ok := err.(*jsonrpc2.Error); ok {
+				ethErr = &ethError{Code: jsonErr.Code, Message: jsonErr.Message}
+			}
+			return newEthErrorResponse(req.ID, ethErr)
+		}
+	}
+
+	method, ok := ethMethods[req.Method]
+	if !ok {
+		return newEthErrorResponse(req.ID, &ethError{
+			Code:    ethErrCodeMethodNotFound,
+			Message: fmt.Sprintf("the method %v does not exist/is not available", req.Method),
+		})
+	}
+
+	result, err := method(h.service, req.Params)
+	if err != nil {
+		ethErr, ok := err.(*ethError)
+		if !ok {
+			if rpcErr, isRPCErr := err.(*jsonrpc2.Error); isRPCErr {
+				ethErr = &ethError{Code: rpcErr.Code, Message: rpcErr.Message}
+			} else {
+				ethErr = &ethError{Code: ethErrCodeServer, Message: err.Error()}
+			}
+		}
+		return newEthErrorResponse(req.ID, ethErr)
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return newEthErrorResponse(req.ID, &ethError{Code: ethErrCodeServer, Message: err.Error()})
+	}
+	return &ethResponse{JSONRPC: "2.0", ID: req.ID, Result: resultBytes}
+}
+
+func newEthErrorResponse(id json.RawMessage, err *ethError) *ethResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &ethResponse{JSONRPC: "2.0", ID: id, Error: err}
+}
+
+// -------------------------- Params -------------------------- //
+
+func parseEthParam(params []json.RawMessage, index int, required bool, v interface{}) error {
+	if index >= len(params) || string(params[index]) == "null" {
+		if required {
+			return &ethError{Code: ethErrCodeInvalidParams, Message: fmt.Sprintf("missing value for required argument %v", index)}
+		}
+		return nil
+	}
+	if err := json.Unmarshal(params[index], v); err != nil {
+		return &ethError{Code: ethErrCodeInvalidParams, Message: fmt.Sprintf("invalid argument %v: %v", index, err)}
+	}
+	return nil
+}
+
+// parseEthBlockTag parses a block number or one of the block tags. The "pending" tag is reported
+// by the returned flag, the other tags resolve to the latest finalized block, which is reported
+// as height zero.
+func parseEthBlockTag(params []json.RawMessage, index int) (height uint64, pending bool, err error) {
+	tag := "latest"
+	if err = parseEthParam(params, index, false, &tag); err != nil {
+		return 0, false, err
+	}
+	switch tag {
+	case "latest", "safe", "finalized":
+		return 0, false, nil
+	case "pending":
+		return 0, true, nil
+	case "earliest":
+		return 0, false, &ethError{Code: ethErrCodeInvalidParams, Message: "the earliest block is not supported"}
+	}
+	height, err = hexutil.DecodeUint64(tag)
+	if err != nil {
+		return 0, false, &ethError{Code: ethErrCodeInvalidParams, Message: fmt.Sprintf("invalid block number %v: %v", tag, err)}
+	}
+	return height, false, nil
+}
+
+// getEthLedgerState returns the ledger state after the finalized block at the given height. Height zero
+// refers to the latest finalized block, and the pending flag to the screened view of the mempool.
+func (t *ThetaRPCService) getEthLedgerState(height uint64, pending bool) (*state.StoreView, error) {
+	if pending {
+		return t.ledger.GetScreenedSnapshot()
+	}
+	return t.getLedgerStateAtHeight(height)
+}
+
+func (t *ThetaRPCService) findFinalizedBlockByHeight(height uint64) *core.ExtendedBlock {
+	for _, block := range t.chain.FindBlocksByHeight(height) {
+		if block.Status.IsFinalized() {
+			return block
+		}
+	}
+	return nil
+}
+
+// EthCallArgs is the transaction call object of eth_call and eth_estimateGas.
+type EthCallArgs struct {
+	From     *common.Address `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"data"`
+	Input    *hexutil.Bytes  `json:"input"`
+}
+
+// EthAccountOverride is the account entry of the state override set of eth_call and eth_estimateGas,
+// the balance overrides the TFuel balance.
+type EthAccountOverride struct {
+	Nonce     *hexutil.Uint64             `json:"nonce"`
+	Balance   *hexutil.Big                `json:"balance"`
+	Code      *hexutil.Bytes              `json:"code"`
+	State     map[common.Hash]common.Hash `json:"state"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// parseEthStateOverrides parses the optional state override set at the given index of the params
+func parseEthStateOverrides(params []json.RawMessage, index int) (map[common.Address]AccountOverride, error) {
+	ethOverrides := make(map[common.Address]EthAccountOverride)
+	if err := parseEthParam(params, index, false, &ethOverrides); err != nil {
+		return nil, err
+	}
+	overrides := make(map[common.Address]AccountOverride)
+	for addr, eo := range ethOverrides {
+		override := AccountOverride{
+			State:     eo.State,
+			StateDiff: eo.StateDiff,
+		}
+		if eo.Nonce != nil {
+			sequence := common.JSONUint64(*eo.Nonce)
+			override.Sequence = &sequence
+		}
+		if eo.Balance != nil {
+			override.TFuelBalance = (*common.JSONBig)(eo.Balance.ToInt())
+		}
+		if eo.Code != nil {
+			code := hex.EncodeToString(*eo.Code)
+			override.Code = &code
+		}
+		overrides[addr] = override
+	}
+	return overrides, nil
+}
+
+// toSctxBytes encodes the call object as an unsigned smart contract transaction in hex.
+func (args *EthCallArgs) toSctxBytes(blockHeight uint64) (string, error) {
+	sctx := &types.SmartContractTx{
+		GasPrice: types.GetMinimumGasPrice(blockHeight),
+	}
+	if args.From != nil {
+		sctx.From.Address = *args.From
+	}
+	sctx.From.Coins = types.NewCoins(0, 0)
+	if args.Value != nil {
+		sctx.From.Coins.TFuelWei = args.Value.ToInt()
+	}
+	if args.To != nil {
+		sctx.To.Address = *args.To
+	}
+	if args.Gas != nil {
+		sctx.GasLimit = uint64(*args.Gas)
+	} else {
+		sctx.GasLimit = types.GetMaxGasLimit(blockHeight).Uint64()
+	}
+	if args.GasPrice != nil {
+		sctx.GasPrice = args.GasPrice.ToInt()
+	}
+	if args.Input != nil {
+		sctx.Data = common.Bytes(*args.Input)
+	} else if args.Data != nil {
+		sctx.Data = common.Bytes(*args.Data)
+	}
+
+	raw, err := types.TxToBytes(sctx)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// -------------------------- Methods -------------------------- //
+
+func (t *ThetaRPCService) ethClientVersion(params []json.RawMessage) (interface{}, error) {
+	return "Theta/" + version.Version, nil
+}
+
+func (t *ThetaRPCService) ethNetVersion(params []json.RawMessage) (interface{}, error) {
+	return t.getEthChainID().String(), nil
+}
+
+func (t *ThetaRPCService) ethNetListening(params []json.RawMessage) (interface{}, error) {
+	return true, nil
+}
+
+func (t *ThetaRPCService) ethChainID(params []json.RawMessage) (interface{}, error) {
+	return (*hexutil.Big)(t.getEthChainID()), nil
+}
+
+func (t *ThetaRPCService) getEthChainID() *big.Int {
+	block := t.consensus.GetLastFinalizedBlock()
+	return types.MapChainID(block.ChainID, block.Height)
+}
+
+func (t *ThetaRPCService) ethSyncing(params []json.RawMessage) (interface{}, error) {
+	return false, nil
+}
+
+func (t *ThetaRPCService) ethAccounts(params []json.RawMessage) (interface{}, error) {
+	return []common.Address{}, nil
+}
+
+func (t *ThetaRPCService) ethBlockNumber(params []json.RawMessage) (interface{}, error) {
+	return hexutil.Uint64(t.consensus.GetLastFinalizedBlock().Height), nil
+}
+
+func (t *ThetaRPCService) ethGasPrice(params []json.RawMessage) (interface{}, error) {
+	height := t.consensus.GetLastFinalizedBlock().Height + 1
+	return (*hexutil.Big)(types.GetMinimumGasPrice(height)), nil
+}
+
+func (t *ThetaRPCService) ethGetBalance(params []json.RawMessage) (interface{}, error) {
+	var address common.Address
+	if err := parseEthParam(params, 0, true, &address); err != nil {
+		return nil, err
+	}
+	height, pending, err := parseEthBlockTag(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	ledgerState, err := t.getEthLedgerState(height, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	account := ledgerState.GetAccount(address)
+	if account == nil {
+		return (*hexutil.Big)(big.NewInt(0)), nil
+	}
+	return (*hexutil.Big)(account.Balance.NoNil().TFuelWei), nil
+}
+
+// ethGetTransactionCount returns the Ethereum nonce of the account. Since the Ethereum nonce starts
+// from 0 while the Theta sequence starts from 1, the next nonce equals the current sequence.
+func (t *ThetaRPCService) ethGetTransactionCount(params []json.RawMessage) (interface{}, error) {
+	var address common.Address
+	if err := parseEthParam(params, 0, true, &address); err != nil {
+		return nil, err
+	}
+	height, pending, err := parseEthBlockTag(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	ledgerState, err := t.getEthLedgerState(height, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	account := ledgerState.GetAccount(address)
+	if account == nil {
+		return hexutil.Uint64(0), nil
+	}
+	return hexutil.Uint64(account.Sequence), nil
+}
+
+func (t *ThetaRPCService) ethGetCode(params []json.RawMessage) (interface{}, error) {
+	var address common.Address
+	if err := parseEthParam(params, 0, true, &address); err != nil {
+		return nil, err
+	}
+	height, pending, err := parseEthBlockTag(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	ledgerState, err := t.getEthLedgerState(height, pending)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(ledgerState.GetCode(address)), nil
+}
+
+func (t *ThetaRPCService) ethGetStorageAt(params []json.RawMessage) (interface{}, error) {
+	var address common.Address
+	if err := parseEthParam(params, 0, true, &address); err != nil {
+		return nil, err
+	}
+	var position hexutil.Big
+	if err := parseEthParam(params, 1, true, &position); err != nil {
+		return nil, err
+	}
+	height, pending, err := parseEthBlockTag(params, 2)
+	if err != nil {
+		return nil, err
+	}
+	ledgerState, err := t.getEthLedgerState(height, pending)
+	if err != nil {
+		return nil, err
+	}
+	key := common.BigToHash(position.ToInt())
+	return ledgerState.GetState(address, key), nil
+}
+
+// ethCall executes the call against the state after the given block, the latest state by default,
+// with the optional state override set applied. The pending tag is treated as the latest.
+func (t *ThetaRPCService) ethCall(params []json.RawMessage) (interface{}, error) {
+	args := &EthCallArgs{}
+	if err := parseEthParam(params, 0, true, args); err != nil {
+		return nil, err
+	}
+	height, _, err := parseEthBlockTag(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	blockHeight := height
+	if blockHeight == 0 {
+		blockHeight = t.consensus.GetLastFinalizedBlock().Height
+	}
+	overrides, err := parseEthStateOverrides(params, 2)
+	if err != nil {
+		return nil, err
+	}
+	sctxBytes, err := args.toSctxBytes(blockHeight + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CallSmartContractResult{}
+	callArgs := &CallSmartContractArgs{
+		SctxBytes:      sctxBytes,
+		Height:         common.JSONUint64(height),
+		StateOverrides: overrides,
+	}
+	if err := t.CallSmartContract(callArgs, result); err != nil {
+		return nil, err
+	}
+	if result.VmError != "" {
+		return nil, &ethError{Code: ethErrCodeReverted, Message: "execution reverted: " + result.VmError, Data: "0x" + result.VmReturn}
+	}
+
+	vmRet, err := hex.DecodeString(result.VmReturn)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(vmRet), nil
+}
+
+func (t *ThetaRPCService) ethEstimateGas(params []json.RawMessage) (interface{}, error) {
+	args := &EthCallArgs{}
+	if err := parseEthParam(params, 0, true, args); err != nil {
+		return nil, err
+	}
+	args.Gas = nil // EstimateGas searches for the gas limit
+	overrides, err := parseEthStateOverrides(params, 2)
+	if err != nil {
+		return nil, err
+	}
+	sctxBytes, err := args.toSctxBytes(t.consensus.GetLastFinalizedBlock().Height + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EstimateGasResult{}
+	if err := t.EstimateGas(&EstimateGasArgs{SctxBytes: sctxBytes, StateOverrides: overrides}, result); err != nil {
+		return nil, err
+	}
+	return hexutil.Uint64(result.GasLimit), nil
+}
+
+func (t *ThetaRPCService) ethSendRawTransaction(params []json.RawMessage) (interface{}, error) {
+	var rawTx hexutil.Bytes
+	if err := parseEthParam(params, 0, true, &rawTx); err != nil {
+		return nil, err
+	}
+
+	result := &BroadcastRawTransactionAsyncResult{}
+	err := t.BroadcastRawEthTransactionAsync(&BroadcastRawTransactionAsyncArgs{
+		TxBytes: hexutil.Encode(rawTx),
+	}, result)
+	if err != nil {
+		return nil, err
+	}
+	return common.HexToHash(result.TxHash), nil
+}
+
+// EthBlock is the Ethereum representation of a Theta block. The fields without a Theta
+// counterpart are filled with the empty values.
+type EthBlock struct {
+	Number           hexutil.Uint64 `json:"number"`
+	Hash             common.Hash    `json:"hash"`
+	ParentHash       common.Hash    `json:"parentHash"`
+	Nonce            hexutil.Bytes  `json:"nonce"`
+	Sha3Uncles       common.Hash    `json:"sha3Uncles"`
+	LogsBloom        hexutil.Bytes  `json:"logsBloom"`
+	TransactionsRoot common.Hash    `json:"transactionsRoot"`
+	StateRoot        common.Hash    `json:"stateRoot"`
+	ReceiptsRoot     common.Hash    `json:"receiptsRoot"`
+	Miner            common.Address `json:"miner"`
+	Difficulty       hexutil.Uint64 `json:"difficulty"`
+	TotalDifficulty  hexutil.Uint64 `json:"totalDifficulty"`
+	ExtraData        hexutil.Bytes  `json:"extraData"`
+	Size             hexutil.Uint64 `json:"size"`
+	GasLimit         hexutil.Uint64 `json:"gasLimit"`
+	GasUsed          hexutil.Uint64 `json:"gasUsed"`
+	Timestamp        *hexutil.Big   `json:"timestamp"`
+	Transactions     []common.Hash  `json:"transactions"`
+	Uncles           []common.Hash  `json:"uncles"`
+}
+
+func (t *ThetaRPCService) newEthBlock(block *core.ExtendedBlock) *EthBlock {
+	ethBlock := &EthBlock{
+		Number:           hexutil.Uint64(block.Height),
+		Hash:             block.Hash(),
+		ParentHash:       block.Parent,
+		Nonce:            make(hexutil.Bytes, 8),
+		Sha3Uncles:       emptyUncleHash,
+		LogsBloom:        hexutil.Bytes(block.Bloom[:]),
+		TransactionsRoot: block.TxHash,
+		StateRoot:        block.StateHash,
+		ReceiptsRoot:     block.ReceiptHash,
+		Miner:            block.Proposer,
+		ExtraData:        hexutil.Bytes{},
+		GasLimit:         hexutil.Uint64(types.GetMaxGasLimit(block.Height).Uint64()),
+		Timestamp:        (*hexutil.Big)(block.Timestamp),
+		Transactions:     []common.Hash{},
+		Uncles:           []common.Hash{},
+	}
+
+	gasUsed := uint64(0)
+	size := uint64(0)
+	for _, txBytes := range block.Txs {
+		txHash := crypto.Keccak256Hash(txBytes)
+		if ethTxHash, err := blockchain.CalcEthTxHash(block, txBytes); err == nil {
+			txHash = ethTxHash
+		}
+		ethBlock.Transactions = append(ethBlock.Transactions, txHash)
+		if receipt, found := t.chain.FindTxReceiptByHash(crypto.Keccak256Hash(txBytes)); found {
+			gasUsed += receipt.GasUsed
+		}
+		size += uint64(len(txBytes))
+	}
+	ethBlock.GasUsed = hexutil.Uint64(gasUsed)
+	ethBlock.Size = hexutil.Uint64(size)
+
+	return ethBlock
+}
+
+func (t *ThetaRPCService) ethGetBlockByNumber(params []json.RawMessage) (interface{}, error) {
+	height, pending, err := parseEthBlockTag(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	if pending || height == 0 {
+		return t.newEthBlock(t.consensus.GetLastFinalizedBlock()), nil
+	}
+	block := t.findFinalizedBlockByHeight(height)
+	if block == nil {
+		return nil, nil
+	}
+	return t.newEthBlock(block), nil
+}
+
+func (t *ThetaRPCService) ethGetBlockByHash(params []json.RawMessage) (interface{}, error) {
+	var hash common.Hash
+	if err := parseEthParam(params, 0, true, &hash); err != nil {
+		return nil, err
+	}
+	block, err := t.chain.FindBlock(hash)
+	if err != nil || !block.Status.IsFinalized() {
+		return nil, nil
+	}
+	return t.newEthBlock(block), nil
+}
+
+// EthLog is the Ethereum representation of a smart contract event log.
+type EthLog struct {
+	Address     common.Address `json:"address"`
+	Topics      []common.Hash  `json:"topics"`
+	Data        hexutil.Bytes  `json:"data"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	TxIndex     hexutil.Uint64 `json:"transactionIndex"`
+	LogIndex    hexutil.Uint64 `json:"logIndex"`
+	Removed     bool           `json:"removed"`
+}
+
+func newEthLog(entry *LogEntry, txHash common.Hash) *EthLog {
+	topics := entry.Topics
+	if topics == nil {
+		topics = []common.Hash{}
+	}
+	return &EthLog{
+		Address:     entry.Address,
+		Topics:      topics,
+		Data:        hexutil.Bytes(entry.Data),
+		BlockNumber: hexutil.Uint64(entry.BlockHeight),
+		BlockHash:   entry.BlockHash,
+		TxHash:      txHash,
+		TxIndex:     hexutil.Uint64(entry.TxIndex),
+		LogIndex:    hexutil.Uint64(entry.LogIndex),
+	}
+}
+
+// EthTxReceipt is the Ethereum representation of a transaction receipt.
+type EthTxReceipt struct {
+	TxHash            common.Hash     `json:"transactionHash"`
+	TxIndex           hexutil.Uint64  `json:"transactionIndex"`
+	BlockHash         common.Hash     `json:"blockHash"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	From              common.Address  `json:"from"`
+	To                *common.Address `json:"to"`
+	CumulativeGasUsed hexutil.Uint64  `json:"cumulativeGasUsed"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	Logs              []*EthLog       `json:"logs"`
+	LogsBloom         hexutil.Bytes   `json:"logsBloom"`
+	Type              hexutil.Uint64  `json:"type"`
+	Status            hexutil.Uint64  `json:"status"`
+}
+
+// ethGetTransactionReceipt looks up the receipt of a smart contract transaction by either its
+// Ethereum or its Theta transaction hash. It returns null for the transactions which are not yet
+// finalized.
+func (t *ThetaRPCService) ethGetTransactionReceipt(params []json.RawMessage) (interface{}, error) {
+	var hash common.Hash
+	if err := parseEthParam(params, 0, true, &hash); err != nil {
+		return nil, err
+	}
+
+	rawTx, block, found := t.chain.FindTxByHash(hash)
+	if !found || !block.Status.IsFinalized() {
+		return nil, nil
+	}
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	sctx, ok := tx.(*types.SmartContractTx)
+	if !ok {
+		return nil, nil
+	}
+	receipt, found := t.chain.FindTxReceiptByHash(crypto.Keccak256Hash(rawTx))
+	if !found {
+		return nil, nil
+	}
+
+	txIndex := -1
+	cumulativeGasUsed := uint64(0)
+	logIndex := uint64(0)
+	for idx, txBytes := range block.Txs {
+		r, found := t.chain.FindTxReceiptByHash(crypto.Keccak256Hash(txBytes))
+		if found {
+			cumulativeGasUsed += r.GasUsed
+		}
+		if bytes.Equal(txBytes, rawTx) {
+			txIndex = idx
+			break
+		}
+		if found {
+			logIndex += uint64(len(r.Logs))
+		}
+	}
+	if txIndex < 0 {
+		return nil, errNotFound("transaction is not found in its block")
+	}
+
+	ethReceipt := &EthTxReceipt{
+		TxHash:            hash,
+		TxIndex:           hexutil.Uint64(txIndex),
+		BlockHash:         block.Hash(),
+		BlockNumber:       hexutil.Uint64(block.Height),
+		From:              sctx.From.Address,
+		CumulativeGasUsed: hexutil.Uint64(cumulativeGasUsed),
+		GasUsed:           hexutil.Uint64(receipt.GasUsed),
+		EffectiveGasPrice: (*hexutil.Big)(sctx.GasPrice),
+		Logs:              []*EthLog{},
+		LogsBloom:         make(hexutil.Bytes, core.BloomByteLength),
+	}
+	if (sctx.To.Address == common.Address{}) {
+		contractAddress := receipt.ContractAddress
+		ethReceipt.ContractAddress = &contractAddress
+	} else {
+		to := sctx.To.Address
+		ethReceipt.To = &to
+	}
+	if receipt.EvmErr == "" {
+		ethReceipt.Status = 1
+	}
+	for _, log := range receipt.Logs {
+		entry := &LogEntry{
+			Log:         log,
+			BlockHash:   block.Hash(),
+			BlockHeight: common.JSONUint64(block.Height),
+			TxIndex:     common.JSONUint64(txIndex),
+			LogIndex:    common.JSONUint64(logIndex),
+		}
+		ethReceipt.Logs = append(ethReceipt.Logs, newEthLog(entry, hash))
+		logIndex++
+	}
+
+	return ethReceipt, nil
+}
+
+// EthLogFilter is the filter object of eth_getLogs. The address can be either a single address or
+// a list of addresses, and each topic position can be either null, a single topic or a list of topics.
+type EthLogFilter struct {
+	FromBlock *string           `json:"fromBlock"`
+	ToBlock   *string           `json:"toBlock"`
+	BlockHash *common.Hash      `json:"blockHash"`
+	Address   json.RawMessage   `json:"address"`
+	Topics    []json.RawMessage `json:"topics"`
+}
+
+func (f *EthLogFilter) toLogFilter() (*LogFilter, error) {
+	filter := &LogFilter{}
+	if len(f.Address) > 0 && string(f.Address) != "null" {
+		if err := unmarshalOneOrMany(f.Address, &filter.Addresses); err != nil {
+			return nil, err
+		}
+	}
+	for _, raw := range f.Topics {
+		candidates := []common.Hash{}
+		if len(raw) > 0 && string(raw) != "null" {
+			if err := unmarshalOneOrMany(raw, &candidates); err != nil {
+				return nil, err
+			}
+		}
+		filter.Topics = append(filter.Topics, candidates)
+	}
+	return filter, nil
+}
+
+// unmarshalOneOrMany decodes either a single JSON value or a list of values into the given slice.
+func unmarshalOneOrMany(raw json.RawMessage, v interface{}) error {
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		return json.Unmarshal(raw, v)
+	}
+	return json.Unmarshal(append(append([]byte{'['}, raw...), ']'), v)
+}
+
+func (t *ThetaRPCService) ethGetLogs(params []json.RawMessage) (interface{}, error) {
+	f := &EthLogFilter{}
+	if err := parseEthParam(params, 0, true, f); err != nil {
+		return nil, err
+	}
+	filter, err := f.toLogFilter()
+	if err != nil {
+		return nil, &ethError{Code: ethErrCodeInvalidParams, Message: err.Error()}
+	}
+
+	var entries []*LogEntry
+	if f.BlockHash != nil {
+		block, err := t.chain.FindBlock(*f.BlockHash)
+		if err != nil || !block.Status.IsFinalized() {
+			return nil, errNotFound("finalized block %v is not found", f.BlockHash.Hex())
+		}
+		entries = t.filterBlockLogs(block.Block, filter)
+	} else {
+		lastFinalizedHeight := t.consensus.GetLastFinalizedBlock().Height
+		fromBlock, err := resolveEthLogBlock(f.FromBlock, lastFinalizedHeight)
+		if err != nil {
+			return nil, err
+		}
+		toBlock, err := resolveEthLogBlock(f.ToBlock, lastFinalizedHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		args := &GetLogsArgs{
+			LogFilter: *filter,
+			FromBlock: common.JSONUint64(fromBlock),
+			ToBlock:   common.JSONUint64(toBlock),
+		}
+		result := &GetLogsResult{}
+		if err := t.GetLogs(args, result); err != nil {
+			return nil, err
+		}
+		entries = result.Logs
+	}
+
+	var block *core.ExtendedBlock
+	logs := []*EthLog{}
+	for _, entry := range entries {
+		if block == nil || block.Hash() != entry.BlockHash {
+			if block, err = t.chain.FindBlock(entry.BlockHash); err != nil {
+				return nil, err
+			}
+		}
+		txHash := entry.TxHash
+		if ethTxHash, err := blockchain.CalcEthTxHash(block, block.Txs[entry.TxIndex]); err == nil {
+			txHash = ethTxHash
+		}
+		logs = append(logs, newEthLog(entry, txHash))
+	}
+	return logs, nil
+}
+
+// resolveEthLogBlock resolves the block number or tag of the log filter to a block height, the
+// unspecified block refers to the latest finalized block.
+func resolveEthLogBlock(tag *string, lastFinalizedHeight uint64) (uint64, error) {
+	if tag == nil {
+		return lastFinalizedHeight, nil
+	}
+	switch *tag {
+	case "earliest":
+		return 0, nil
+	case "latest", "safe", "finalized", "pending":
+		return lastFinalizedHeight, nil
+	}
+	height, err := hexutil.DecodeUint64(*tag)
+	if err != nil {
+		return 0, &ethError{Code: ethErrCodeInvalidParams, Message: fmt.Sprintf("invalid block number %v: %v", *tag, err)}
+	}
+	return height, nil
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+const maxEventQueryBlockRange = 5000
+
+// EventEntry is a ledger event along with the block that emitted it.
+type EventEntry struct {
+	*types.Event
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+}
+
+// ------------------------------ GetEvents -----------------------------------
+
+type GetEventsArgs struct {
+	Types     []string          `json:"types"` // empty means all event types
+	FromBlock common.JSONUint64 `json:"from_block"`
+	ToBlock   common.JSONUint64 `json:"to_block"` // 0 means the latest finalized block
+}
+
+type GetEventsResult struct {
+	Events []*EventEntry `json:"events"`
+}
+
+// GetEvents returns the ledger events, e.g. the split rule expiries, emitted by the finalized blocks
+// within the given height range.
+func (t *ThetaRPCService) GetEvents(args *GetEventsArgs, result *GetEventsResult) (err error) {
+	toBlock := args.ToBlock
+	lastFinalizedHeight := common.JSONUint64(t.consensus.GetLastFinalizedBlock().Height)
+	if toBlock == 0 || toBlock > lastFinalizedHeight {
+		toBlock = lastFinalizedHeight
+	}
+	if args.FromBlock > toBlock {
+		return errInvalidParams("Starting block must be less than ending block")
+	}
+	if toBlock-args.FromBlock > maxEventQueryBlockRange {
+		return errInvalidParams("Can't query events of more than 5000 blocks at a time")
+	}
+
+	typeFilter := make(map[string]bool)
+	for _, eventType := range args.Types {
+		typeFilter[eventType] = true
+	}
+
+	result.Events = []*EventEntry{}
+	for height := uint64(args.FromBlock); height <= uint64(toBlock); height++ {
+		for _, block := range t.chain.FindBlocksByHeight(height) {
+			if !block.Status.IsFinalized() {
+				continue
+			}
+			events, found := t.chain.FindBlockEvents(block.Hash())
+			if !found {
+				break
+			}
+			for _, event := range events {
+				if len(typeFilter) > 0 && !typeFilter[event.Type] {
+					continue
+				}
+				result.Events = append(result.Events, &EventEntry{
+					Event:       event,
+					BlockHash:   block.Hash(),
+					BlockHeight: common.JSONUint64(block.Height),
+				})
+			}
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,706 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+const (
+	defaultGraphQLPageSize = 20
+	maxGraphQLPageSize     = 100
+)
+
+// graphqlSchema describes the chain data served at the /graphql endpoint. The blocks and the
+// accounts are linked, so that e.g. the balances of the proposers of a range of blocks can be
+// fetched in a single query. The states are taken from the finalized blocks only.
+const graphqlSchema = `
+	# Long is an unsigned 64 bit integer, it can be given as a number or a decimal string
+	scalar Long
+	# BigInt is an arbitrary precision integer, encoded as a decimal string
+	scalar BigInt
+
+	schema {
+		query: Query
+	}
+
+	type Query {
+		# the block with the given hash, or the finalized block at the given height (the latest if neither is given)
+		block(hash: String, height: Long): Block
+		# the finalized blocks from the given height (inclusive) in ascending order
+		blocks(from: Long!, to: Long, first: Int): BlockConnection!
+		# the transaction with the given native or ETH hash
+		transaction(hash: String!): Transaction
+		# the account as of the finalized block at the given height (the latest if not given)
+		account(address: String!, height: Long): Account!
+		# the validators of the finalized block at the given height (the latest if not given)
+		validators(height: Long): [Validator!]!
+		# the stake holders of the given purpose (0: validator, 1: guardian, 2: elite edge node), in address order
+		stakeHolders(purpose: Int!, height: Long, first: Int, after: String): StakeHolderConnection!
+	}
+
+	type Block {
+		hash: String!
+		height: Long!
+		epoch: Long!
+		chainID: String!
+		parentHash: String!
+		parent: Block
+		stateHash: String!
+		transactionsHash: String!
+		timestamp: BigInt!
+		proposer: Account!
+		status: Int!
+		transactionCount: Int!
+		transactions(first: Int, skip: Int): [Transaction!]!
+		validators: [Validator!]!
+	}
+
+	type BlockConnection {
+		blocks: [Block!]!
+		hasMore: Boolean!
+		nextHeight: Long
+	}
+
+	type Transaction {
+		hash: String!
+		ethHash: String
+		type: Int!
+		raw: String!
+		# the transaction decoded into JSON
+		json: String!
+		block: Block
+		receipt: Receipt
+	}
+
+	type Receipt {
+		gasUsed: Long!
+		contractAddress: String!
+		evmReturn: String!
+		evmError: String!
+		logs: [Log!]!
+	}
+
+	type Log {
+		address: String!
+		topics: [String!]!
+		data: String!
+	}
+
+	type Account {
+		address: String!
+		height: Long!
+		sequence: Long!
+		thetaBalance: BigInt!
+		tfuelBalance: BigInt!
+		reservedFunds: [ReservedFund!]!
+	}
+
+	type ReservedFund {
+		resourceIDs: [String!]!
+		collateralTFuel: BigInt!
+		initialFundTFuel: BigInt!
+		usedFundTFuel: BigInt!
+		endBlockHeight: Long!
+		reserveSequence: Long!
+	}
+
+	type Validator {
+		account: Account!
+		stake: BigInt!
+	}
+
+	type StakeHolder {
+		holder: Account!
+		totalStake: BigInt!
+		stakes: [Stake!]!
+	}
+
+	type Stake {
+		source: Account!
+		amount: BigInt!
+		withdrawn: Boolean!
+		returnHeight: Long!
+	}
+
+	type StakeHolderConnection {
+		height: Long!
+		stakeHolders: [StakeHolder!]!
+		nextCursor: String
+	}
+`
+
+// graphqlMethod is the method name checked against the method filter of the caller (see authMiddleware)
+const graphqlMethod = "graphql"
+
+type graphqlHTTPHandler struct {
+	handler *relay.Handler
+}
+
+// newGraphQLHandler creates the handler of the GraphQL endpoint
+func newGraphQLHandler(t *ThetaRPCService) (http.Handler, error) {
+	schema, err := graphql.ParseSchema(graphqlSchema, &graphqlResolver{t: t})
+	if err != nil {
+		return nil, err
+	}
+	return &graphqlHTTPHandler{handler: &relay.Handler{Schema: schema}}, nil
+}
+
+func (h *graphqlHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if filter := jsonrpc2.MethodFilterFromContext(r.Context()); filter != nil {
+		if err := filter(graphqlMethod); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
+// gqlError carries the code and reason of an RPC error in the extensions of the GraphQL error
+type gqlError struct {
+	rpcErr *jsonrpc2.Error
+}
+
+func (e gqlError) Error() string { return e.rpcErr.Message }
+
+func (e gqlError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.rpcErr.Code}
+	if data, ok := e.rpcErr.Data.(*ErrorData); ok {
+		ext["reason"] = data.Reason
+	}
+	return ext
+}
+
+func graphqlError(err error) error {
+	if rpcErr, ok := err.(*jsonrpc2.Error); ok {
+		return gqlError{rpcErr}
+	}
+	return err
+}
+
+// ------------------------------ Scalars ------------------------------
+
+type gqlLong uint64
+
+func (gqlLong) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+func (l *gqlLong) UnmarshalGraphQL(input interface{}) error {
+	switch v := input.(type) {
+	case int32:
+		if v < 0 {
+			return fmt.Errorf("negative value %v for Long", v)
+		}
+		*l = gqlLong(v)
+	case float64:
+		if v < 0 || v != float64(uint64(v)) {
+			return fmt.Errorf("invalid value %v for Long", v)
+		}
+		*l = gqlLong(v)
+	case string:
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %v for Long: %v", v, err)
+		}
+		*l = gqlLong(n)
+	default:
+		return fmt.Errorf("unexpected type %T for Long", input)
+	}
+	return nil
+}
+
+type gqlBigInt big.Int
+
+func newGQLBigInt(b *big.Int) gqlBigInt {
+	if b == nil {
+		return gqlBigInt{}
+	}
+	return gqlBigInt(*b)
+}
+
+func (gqlBigInt) ImplementsGraphQLType(name string) bool { return name == "BigInt" }
+
+func (b *gqlBigInt) UnmarshalGraphQL(input interface{}) error {
+	s, ok := input.(string)
+	if !ok {
+		return fmt.Errorf("unexpected type %T for BigInt", input)
+	}
+	if _, ok := (*big.Int)(b).SetString(s, 10); !ok {
+		return fmt.Errorf("invalid value %v for BigInt", s)
+	}
+	return nil
+}
+
+func (b gqlBigInt) MarshalJSON() ([]byte, error) {
+	i := big.Int(b)
+	return json.Marshal(i.String())
+}
+
+func graphqlPageSize(first *int32) (int, error) {
+	if first == nil {
+		return defaultGraphQLPageSize, nil
+	}
+	if *first <= 0 || *first > maxGraphQLPageSize {
+		return 0, fmt.Errorf("first must be between 1 and %v", maxGraphQLPageSize)
+	}
+	return int(*first), nil
+}
+
+// ------------------------------ Query ------------------------------
+
+type graphqlResolver struct {
+	t *ThetaRPCService
+}
+
+func (r *graphqlResolver) Block(args struct {
+	Hash   *string
+	Height *gqlLong
+}) (*gqlBlock, error) {
+	if args.Hash != nil {
+		block, err := r.t.chain.FindBlock(common.HexToHash(*args.Hash))
+		if err != nil {
+			return nil, nil
+		}
+		return &gqlBlock{t: r.t, block: block}, nil
+	}
+
+	if args.Height == nil {
+		return &gqlBlock{t: r.t, block: r.t.consensus.GetLastFinalizedBlock()}, nil
+	}
+	block := r.t.findFinalizedBlockByHeight(uint64(*args.Height))
+	if block == nil {
+		return nil, nil
+	}
+	return &gqlBlock{t: r.t, block: block}, nil
+}
+
+func (r *graphqlResolver) Blocks(args struct {
+	From  gqlLong
+	To    *gqlLong
+	First *int32
+}) (*gqlBlockConnection, error) {
+	limit, err := graphqlPageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	end := r.t.consensus.GetLastFinalizedBlock().Height
+	if args.To != nil && uint64(*args.To) < end {
+		end = uint64(*args.To)
+	}
+
+	conn := &gqlBlockConnection{blocks: []*gqlBlock{}}
+	height := uint64(args.From)
+	for ; height <= end && len(conn.blocks) < limit; height++ {
+		if block := r.t.findFinalizedBlockByHeight(height); block != nil {
+			conn.blocks = append(conn.blocks, &gqlBlock{t: r.t, block: block})
+		}
+	}
+	if height <= end {
+		next := gqlLong(height)
+		conn.nextHeight = &next
+	}
+	return conn, nil
+}
+
+func (r *graphqlResolver) Transaction(args struct{ Hash string }) (*gqlTransaction, error) {
+	raw, block, found := r.t.chain.FindTxByHash(common.HexToHash(args.Hash))
+	if !found {
+		return nil, nil
+	}
+	return &gqlTransaction{t: r.t, raw: raw, block: block}, nil
+}
+
+func (r *graphqlResolver) Account(args struct {
+	Address string
+	Height  *gqlLong
+}) (*gqlAccount, error) {
+	if !common.IsHexAddress(args.Address) {
+		return nil, fmt.Errorf("invalid address %v", args.Address)
+	}
+	height := uint64(0)
+	if args.Height != nil {
+		height = uint64(*args.Height)
+	}
+	return newGQLAccount(r.t, common.HexToAddress(args.Address), height), nil
+}
+
+func (r *graphqlResolver) Validators(args struct{ Height *gqlLong }) ([]*gqlValidator, error) {
+	block := r.t.consensus.GetLastFinalizedBlock()
+	if args.Height != nil {
+		block = r.t.findFinalizedBlockByHeight(uint64(*args.Height))
+		if block == nil {
+			return nil, fmt.Errorf("no finalized block found at height %v", *args.Height)
+		}
+	}
+	return (&gqlBlock{t: r.t, block: block}).Validators(), nil
+}
+
+func (r *graphqlResolver) StakeHolders(args struct {
+	Purpose int32
+	Height  *gqlLong
+	First   *int32
+	After   *string
+}) (*gqlStakeHolderConnection, error) {
+	limit, err := graphqlPageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	if args.Purpose < 0 || args.Purpose > 255 {
+		return nil, fmt.Errorf("invalid purpose %v", args.Purpose)
+	}
+
+	listArgs := &ListStakeHoldersArgs{Purpose: uint8(args.Purpose)}
+	listArgs.Limit = common.JSONUint64(limit)
+	if args.Height != nil {
+		listArgs.Height = common.JSONUint64(*args.Height)
+	}
+	if args.After != nil {
+		listArgs.Cursor = *args.After
+	}
+	result := &ListStakeHoldersResult{}
+	if err := r.t.ListStakeHolders(listArgs, result); err != nil {
+		return nil, graphqlError(err)
+	}
+
+	conn := &gqlStakeHolderConnection{height: gqlLong(result.BlockHeight), stakeHolders: []*gqlStakeHolder{}}
+	for _, holder := range result.StakeHolders {
+		conn.stakeHolders = append(conn.stakeHolders, &gqlStakeHolder{t: r.t, holder: holder, height: uint64(result.BlockHeight)})
+	}
+	if result.NextCursor != "" {
+		conn.nextCursor = &result.NextCursor
+	}
+	return conn, nil
+}
+
+// ------------------------------ Block ------------------------------
+
+type gqlBlock struct {
+	t     *ThetaRPCService
+	block *core.ExtendedBlock
+}
+
+func (b *gqlBlock) Hash() string             { return b.block.Hash().Hex() }
+func (b *gqlBlock) Height() gqlLong          { return gqlLong(b.block.Height) }
+func (b *gqlBlock) Epoch() gqlLong           { return gqlLong(b.block.Epoch) }
+func (b *gqlBlock) ChainID() string          { return b.block.ChainID }
+func (b *gqlBlock) ParentHash() string       { return b.block.Parent.Hex() }
+func (b *gqlBlock) StateHash() string        { return b.block.StateHash.Hex() }
+func (b *gqlBlock) TransactionsHash() string { return b.block.TxHash.Hex() }
+func (b *gqlBlock) Timestamp() gqlBigInt     { return newGQLBigInt(b.block.Timestamp) }
+func (b *gqlBlock) Status() int32            { return int32(b.block.Status) }
+func (b *gqlBlock) TransactionCount() int32  { return int32(len(b.block.Txs)) }
+
+func (b *gqlBlock) Parent() *gqlBlock {
+	parent, err := b.t.chain.FindBlock(b.block.Parent)
+	if err != nil {
+		return nil
+	}
+	return &gqlBlock{t: b.t, block: parent}
+}
+
+func (b *gqlBlock) Proposer() *gqlAccount {
+	return newGQLAccount(b.t, b.block.Proposer, b.block.Height)
+}
+
+func (b *gqlBlock) Transactions(args struct {
+	First *int32
+	Skip  *int32
+}) ([]*gqlTransaction, error) {
+	txs := b.block.Txs
+	if args.Skip != nil {
+		if *args.Skip < 0 {
+			return nil, errors.New("skip cannot be negative")
+		}
+		if int(*args.Skip) >= len(txs) {
+			return []*gqlTransaction{}, nil
+		}
+		txs = txs[*args.Skip:]
+	}
+	if args.First != nil {
+		if *args.First < 0 {
+			return nil, errors.New("first cannot be negative")
+		}
+		if int(*args.First) < len(txs) {
+			txs = txs[:*args.First]
+		}
+	}
+
+	result := make([]*gqlTransaction, 0, len(txs))
+	for _, raw := range txs {
+		result = append(result, &gqlTransaction{t: b.t, raw: raw, block: b.block})
+	}
+	return result, nil
+}
+
+func (b *gqlBlock) Validators() []*gqlValidator {
+	result := []*gqlValidator{}
+	vs := b.t.consensus.GetValidatorManager().GetValidatorSet(b.block.Hash())
+	if vs == nil {
+		return result
+	}
+	for _, v := range vs.Validators() {
+		result = append(result, &gqlValidator{
+			account: newGQLAccount(b.t, v.Address, b.block.Height),
+			stake:   newGQLBigInt(v.Stake),
+		})
+	}
+	return result
+}
+
+type gqlBlockConnection struct {
+	blocks     []*gqlBlock
+	nextHeight *gqlLong
+}
+
+func (c *gqlBlockConnection) Blocks() []*gqlBlock  { return c.blocks }
+func (c *gqlBlockConnection) HasMore() bool        { return c.nextHeight != nil }
+func (c *gqlBlockConnection) NextHeight() *gqlLong { return c.nextHeight }
+
+// ------------------------------ Transaction ------------------------------
+
+type gqlTransaction struct {
+	t     *ThetaRPCService
+	raw   []byte
+	block *core.ExtendedBlock
+}
+
+func (tx *gqlTransaction) Hash() string { return crypto.Keccak256Hash(tx.raw).Hex() }
+func (tx *gqlTransaction) Raw() string  { return "0x" + hex.EncodeToString(tx.raw) }
+
+func (tx *gqlTransaction) EthHash() *string {
+	decoded, err := types.TxFromBytes(tx.raw)
+	if err != nil || getTxType(decoded) != TxTypeSmartContract {
+		return nil
+	}
+	ethHash, err := blockchain.CalcEthTxHash(tx.block, tx.raw)
+	if err != nil {
+		return nil
+	}
+	hash := ethHash.Hex()
+	return &hash
+}
+
+func (tx *gqlTransaction) Type() (int32, error) {
+	decoded, err := types.TxFromBytes(tx.raw)
+	if err != nil {
+		return 0, err
+	}
+	return int32(getTxType(decoded)), nil
+}
+
+func (tx *gqlTransaction) Json() (string, error) {
+	decoded, err := types.TxFromBytes(tx.raw)
+	if err != nil {
+		return "", err
+	}
+	txJSON, err := json.Marshal(decoded)
+	if err != nil {
+		return "", err
+	}
+	return string(txJSON), nil
+}
+
+func (tx *gqlTransaction) Block() *gqlBlock {
+	if tx.block == nil {
+		return nil
+	}
+	return &gqlBlock{t: tx.t, block: tx.block}
+}
+
+func (tx *gqlTransaction) Receipt() *gqlReceipt {
+	receipt, found := tx.t.chain.FindTxReceiptByHash(crypto.Keccak256Hash(tx.raw))
+	if !found {
+		return nil
+	}
+	return &gqlReceipt{receipt: receipt}
+}
+
+type gqlReceipt struct {
+	receipt *blockchain.TxReceiptEntry
+}
+
+func (r *gqlReceipt) GasUsed() gqlLong        { return gqlLong(r.receipt.GasUsed) }
+func (r *gqlReceipt) ContractAddress() string { return r.receipt.ContractAddress.Hex() }
+func (r *gqlReceipt) EvmReturn() string       { return "0x" + hex.EncodeToString(r.receipt.EvmRet) }
+func (r *gqlReceipt) EvmError() string        { return r.receipt.EvmErr }
+
+func (r *gqlReceipt) Logs() []*gqlLog {
+	logs := []*gqlLog{}
+	for _, l := range r.receipt.Logs {
+		logs = append(logs, &gqlLog{log: l})
+	}
+	return logs
+}
+
+type gqlLog struct {
+	log *types.Log
+}
+
+func (l *gqlLog) Address() string { return l.log.Address.Hex() }
+func (l *gqlLog) Data() string    { return "0x" + hex.EncodeToString(l.log.Data) }
+
+func (l *gqlLog) Topics() []string {
+	topics := []string{}
+	for _, topic := range l.log.Topics {
+		topics = append(topics, topic.Hex())
+	}
+	return topics
+}
+
+// ------------------------------ Account ------------------------------
+
+// gqlAccount loads the account lazily, so that the state is only accessed if any of the account
+// fields other than the address is queried.
+type gqlAccount struct {
+	t       *ThetaRPCService
+	address common.Address
+	height  uint64 // zero for the latest finalized state
+
+	once        sync.Once
+	account     *types.Account
+	stateHeight uint64
+	err         error
+}
+
+func newGQLAccount(t *ThetaRPCService, address common.Address, height uint64) *gqlAccount {
+	return &gqlAccount{t: t, address: address, height: height}
+}
+
+func (a *gqlAccount) load() (*types.Account, error) {
+	a.once.Do(func() {
+		ledgerState, err := a.t.getLedgerStateAtHeight(a.height)
+		if err != nil {
+			a.err = graphqlError(err)
+			return
+		}
+		a.stateHeight = ledgerState.Height()
+		a.account = ledgerState.GetAccount(a.address)
+		if a.account == nil {
+			a.account = types.NewAccount(a.address)
+		}
+		a.account.UpdateToHeight(a.stateHeight)
+	})
+	return a.account, a.err
+}
+
+func (a *gqlAccount) Address() string { return a.address.Hex() }
+
+func (a *gqlAccount) Height() (gqlLong, error) {
+	_, err := a.load()
+	return gqlLong(a.stateHeight), err
+}
+
+func (a *gqlAccount) Sequence() (gqlLong, error) {
+	account, err := a.load()
+	if err != nil {
+		return 0, err
+	}
+	return gqlLong(account.Sequence), nil
+}
+
+func (a *gqlAccount) ThetaBalance() (gqlBigInt, error) {
+	account, err := a.load()
+	if err != nil {
+		return gqlBigInt{}, err
+	}
+	return newGQLBigInt(account.Balance.NoNil().ThetaWei), nil
+}
+
+func (a *gqlAccount) TfuelBalance() (gqlBigInt, error) {
+	account, err := a.load()
+	if err != nil {
+		return gqlBigInt{}, err
+	}
+	return newGQLBigInt(account.Balance.NoNil().TFuelWei), nil
+}
+
+func (a *gqlAccount) ReservedFunds() ([]*gqlReservedFund, error) {
+	account, err := a.load()
+	if err != nil {
+		return nil, err
+	}
+	funds := []*gqlReservedFund{}
+	for i := range account.ReservedFunds {
+		funds = append(funds, &gqlReservedFund{fund: &account.ReservedFunds[i]})
+	}
+	return funds, nil
+}
+
+type gqlReservedFund struct {
+	fund *types.ReservedFund
+}
+
+func (f *gqlReservedFund) ResourceIDs() []string { return f.fund.ResourceIDs }
+func (f *gqlReservedFund) CollateralTFuel() gqlBigInt {
+	return newGQLBigInt(f.fund.Collateral.NoNil().TFuelWei)
+}
+func (f *gqlReservedFund) InitialFundTFuel() gqlBigInt {
+	return newGQLBigInt(f.fund.InitialFund.NoNil().TFuelWei)
+}
+func (f *gqlReservedFund) UsedFundTFuel() gqlBigInt {
+	return newGQLBigInt(f.fund.UsedFund.NoNil().TFuelWei)
+}
+func (f *gqlReservedFund) EndBlockHeight() gqlLong  { return gqlLong(f.fund.EndBlockHeight) }
+func (f *gqlReservedFund) ReserveSequence() gqlLong { return gqlLong(f.fund.ReserveSequence) }
+
+// ------------------------------ Stakes ------------------------------
+
+type gqlValidator struct {
+	account *gqlAccount
+	stake   gqlBigInt
+}
+
+func (v *gqlValidator) Account() *gqlAccount { return v.account }
+func (v *gqlValidator) Stake() gqlBigInt     { return v.stake }
+
+type gqlStakeHolder struct {
+	t      *ThetaRPCService
+	holder *core.StakeHolder
+	height uint64
+}
+
+func (h *gqlStakeHolder) Holder() *gqlAccount   { return newGQLAccount(h.t, h.holder.Holder, h.height) }
+func (h *gqlStakeHolder) TotalStake() gqlBigInt { return newGQLBigInt(h.holder.TotalStake()) }
+
+func (h *gqlStakeHolder) Stakes() []*gqlStake {
+	stakes := []*gqlStake{}
+	for _, stake := range h.holder.Stakes {
+		stakes = append(stakes, &gqlStake{t: h.t, stake: stake, height: h.height})
+	}
+	return stakes
+}
+
+type gqlStake struct {
+	t      *ThetaRPCService
+	stake  *core.Stake
+	height uint64
+}
+
+func (s *gqlStake) Source() *gqlAccount   { return newGQLAccount(s.t, s.stake.Source, s.height) }
+func (s *gqlStake) Amount() gqlBigInt     { return newGQLBigInt(s.stake.Amount) }
+func (s *gqlStake) Withdrawn() bool       { return s.stake.Withdrawn }
+func (s *gqlStake) ReturnHeight() gqlLong { return gqlLong(s.stake.ReturnHeight) }
+
+type gqlStakeHolderConnection struct {
+	height       gqlLong
+	stakeHolders []*gqlStakeHolder
+	nextCursor   *string
+}
+
+func (c *gqlStakeHolderConnection) Height() gqlLong                 { return c.height }
+func (c *gqlStakeHolderConnection) StakeHolders() []*gqlStakeHolder { return c.stakeHolders }
+func (c *gqlStakeHolderConnection) NextCursor() *string             { return c.nextCursor }
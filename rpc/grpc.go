@@ -0,0 +1,314 @@
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"net"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+	"github.com/thetatoken/theta/rpc/pb"
+)
+
+// thetaGRPCService implements the gRPC API on top of the JSON-RPC service, so that both APIs
+// share the same semantics.
+type thetaGRPCService struct {
+	pb.UnimplementedThetaServer
+
+	t *ThetaRPCService
+}
+
+var _ pb.ThetaServer = (*thetaGRPCService)(nil)
+
+// grpcCodes maps the codes of the RPC errors to the gRPC status codes
+var grpcCodes = map[int]codes.Code{
+	ErrCodeMethodNotAllowed: codes.PermissionDenied,
+	ErrCodeInvalidParams:    codes.InvalidArgument,
+	ErrCodeInternal:         codes.Internal,
+	ErrCodeNotFound:         codes.NotFound,
+	ErrCodeStateUnavailable: codes.FailedPrecondition,
+	ErrCodeTxRejected:       codes.FailedPrecondition,
+	ErrCodeNotSupported:     codes.Unimplemented,
+	ErrCodeLimitExceeded:    codes.ResourceExhausted,
+	ErrCodeTimeout:          codes.DeadlineExceeded,
+}
+
+// grpcError converts the error returned by the JSON-RPC service to a gRPC status error, the
+// reason of the RPC error is kept in the message.
+func grpcError(err error) error {
+	rpcErr, ok := err.(*jsonrpc2.Error)
+	if !ok {
+		return err
+	}
+	code, ok := grpcCodes[rpcErr.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	if data, ok := rpcErr.Data.(*ErrorData); ok {
+		return status.Errorf(code, "%v: %v", data.Reason, rpcErr.Message)
+	}
+	return status.Error(code, rpcErr.Message)
+}
+
+// serveGRPC runs the gRPC server until the RPC service is stopped.
+func (t *ThetaRPCServer) serveGRPC() {
+	defer t.wg.Done()
+
+	address := viper.GetString(common.CfgRPCAddress)
+	port := viper.GetString(common.CfgRPCGrpcPort)
+	l, err := net.Listen("tcp", address+":"+port)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Fatal("Failed to create gRPC listener")
+	}
+	logger.WithFields(log.Fields{"address": address, "port": port}).Info("gRPC server started")
+
+	server := grpc.NewServer()
+	pb.RegisterThetaServer(server, &thetaGRPCService{t: t.ThetaRPCService})
+
+	go func() {
+		<-t.ctx.Done()
+		server.Stop()
+	}()
+
+	if err := server.Serve(l); err != nil {
+		logger.Info(err)
+	}
+}
+
+func (s *thetaGRPCService) GetVersion(ctx context.Context, req *pb.GetVersionRequest) (*pb.GetVersionResponse, error) {
+	result := &GetVersionResult{}
+	if err := s.t.GetVersion(&GetVersionArgs{}, result); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.GetVersionResponse{
+		Version:   result.Version,
+		GitHash:   result.GitHash,
+		Timestamp: result.Timestamp,
+	}, nil
+}
+
+func (s *thetaGRPCService) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.GetStatusResponse, error) {
+	result := &GetStatusResult{}
+	if err := s.t.GetStatus(&GetStatusArgs{}, result); err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.GetStatusResponse{
+		Address:                    result.Address,
+		ChainId:                    result.ChainID,
+		PeerId:                     result.PeerID,
+		LatestFinalizedBlockHash:   result.LatestFinalizedBlockHash.Bytes(),
+		LatestFinalizedBlockHeight: uint64(result.LatestFinalizedBlockHeight),
+		LatestFinalizedBlockTime:   jsonBigToUint64(result.LatestFinalizedBlockTime),
+		LatestFinalizedBlockEpoch:  uint64(result.LatestFinalizedBlockEpoch),
+		CurrentEpoch:               uint64(result.CurrentEpoch),
+		CurrentHeight:              uint64(result.CurrentHeight),
+		CurrentTime:                jsonBigToUint64(result.CurrentTime),
+		Syncing:                    result.Syncing,
+		GenesisBlockHash:           result.GenesisBlockHash.Bytes(),
+	}, nil
+}
+
+func (s *thetaGRPCService) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.Account, error) {
+	if len(req.Address) != common.AddressLength {
+		return nil, status.Error(codes.InvalidArgument, "invalid address")
+	}
+	address := common.BytesToAddress(req.Address)
+
+	result := &GetAccountResult{}
+	err := s.t.GetAccount(&GetAccountArgs{
+		Address: address.Hex(),
+		Height:  common.JSONUint64(req.Height),
+		Preview: req.Preview,
+	}, result)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if result.Account == nil {
+		return nil, status.Errorf(codes.NotFound, "account %v is not found", address.Hex())
+	}
+
+	balance := result.Account.Balance.NoNil()
+	return &pb.Account{
+		Address:  address.Bytes(),
+		Sequence: result.Account.Sequence,
+		Balance: &pb.Coins{
+			ThetaWei: balance.ThetaWei.String(),
+			TfuelWei: balance.TFuelWei.String(),
+		},
+		LastUpdatedBlockHeight: result.Account.LastUpdatedBlockHeight,
+		Root:                   result.Account.Root.Bytes(),
+		CodeHash:               result.Account.CodeHash.Bytes(),
+	}, nil
+}
+
+func (s *thetaGRPCService) GetBlock(ctx context.Context, req *pb.GetBlockRequest) (*pb.Block, error) {
+	if len(req.Hash) != common.HashLength {
+		return nil, status.Error(codes.InvalidArgument, "invalid block hash")
+	}
+	block, err := s.t.chain.FindBlock(common.BytesToHash(req.Hash))
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "block %v is not found", hex.EncodeToString(req.Hash))
+	}
+	return newPBBlock(block.Block, block.Status, true), nil
+}
+
+func (s *thetaGRPCService) GetBlockByHeight(ctx context.Context, req *pb.GetBlockByHeightRequest) (*pb.Block, error) {
+	block := s.t.findFinalizedBlockByHeight(req.Height)
+	if block == nil {
+		return nil, status.Errorf(codes.NotFound, "finalized block at height %v is not found", req.Height)
+	}
+	return newPBBlock(block.Block, block.Status, true), nil
+}
+
+func (s *thetaGRPCService) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.GetTransactionResponse, error) {
+	if len(req.Hash) != common.HashLength {
+		return nil, status.Error(codes.InvalidArgument, "invalid transaction hash")
+	}
+
+	result := &GetTransactionResult{}
+	err := s.t.GetTransaction(&GetTransactionArgs{Hash: common.BytesToHash(req.Hash).Hex()}, result)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &pb.GetTransactionResponse{
+		Status: string(result.Status),
+	}
+	if result.Tx == nil {
+		return resp, nil
+	}
+	raw, err := types.TxToBytes(result.Tx)
+	if err != nil {
+		return nil, err
+	}
+	resp.BlockHash = result.BlockHash.Bytes()
+	resp.BlockHeight = uint64(result.BlockHeight)
+	resp.Hash = result.TxHash.Bytes()
+	resp.Type = uint32(result.Type)
+	resp.Raw = raw
+	if result.Receipt != nil {
+		resp.Receipt = &pb.TxReceipt{
+			EvmRet:          result.Receipt.EvmRet,
+			ContractAddress: result.Receipt.ContractAddress.Bytes(),
+			GasUsed:         result.Receipt.GasUsed,
+			EvmErr:          result.Receipt.EvmErr,
+		}
+		for _, l := range result.Receipt.Logs {
+			pbLog := &pb.Log{
+				Address: l.Address.Bytes(),
+				Data:    l.Data,
+			}
+			for _, topic := range l.Topics {
+				pbLog.Topics = append(pbLog.Topics, topic.Bytes())
+			}
+			resp.Receipt.Logs = append(resp.Receipt.Logs, pbLog)
+		}
+	}
+	return resp, nil
+}
+
+func (s *thetaGRPCService) BroadcastRawTransaction(ctx context.Context, req *pb.BroadcastRawTransactionRequest) (*pb.BroadcastRawTransactionResponse, error) {
+	result := &BroadcastRawTransactionResult{}
+	err := s.t.BroadcastRawTransaction(&BroadcastRawTransactionArgs{
+		TxBytes:  hex.EncodeToString(req.TxBytes),
+		Priority: req.Priority,
+	}, result)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &pb.BroadcastRawTransactionResponse{
+		Hash: common.HexToHash(result.TxHash).Bytes(),
+	}
+	if result.Block != nil {
+		resp.BlockHeight = result.Block.Height
+		resp.BlockHash = result.Block.Hash().Bytes()
+	}
+	return resp, nil
+}
+
+func (s *thetaGRPCService) BroadcastRawTransactionAsync(ctx context.Context, req *pb.BroadcastRawTransactionRequest) (*pb.BroadcastRawTransactionAsyncResponse, error) {
+	result := &BroadcastRawTransactionAsyncResult{}
+	err := s.t.BroadcastRawTransactionAsync(&BroadcastRawTransactionAsyncArgs{
+		TxBytes:  hex.EncodeToString(req.TxBytes),
+		Priority: req.Priority,
+	}, result)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.BroadcastRawTransactionAsyncResponse{
+		Hash: common.HexToHash(result.TxHash).Bytes(),
+	}, nil
+}
+
+// SubscribeBlocks streams the newly finalized blocks until the client cancels the call. A client
+// that can't keep up is disconnected.
+func (s *thetaGRPCService) SubscribeBlocks(req *pb.SubscribeBlocksRequest, stream pb.Theta_SubscribeBlocksServer) error {
+	sub := s.t.blockSubscriptions.subscribe()
+	defer s.t.blockSubscriptions.unsubscribe(sub)
+
+	for {
+		select {
+		case entry, ok := <-sub.blockC:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber is too slow")
+			}
+			blockStatus := core.BlockStatusDirectlyFinalized
+			if block, err := s.t.chain.FindBlock(entry.Hash); err == nil {
+				blockStatus = block.Status
+			}
+			if err := stream.Send(newPBBlock(entry.block, blockStatus, req.FullTransactions)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		case <-s.t.ctx.Done():
+			return status.Error(codes.Unavailable, "server is stopping")
+		}
+	}
+}
+
+func newPBBlock(block *core.Block, blockStatus core.BlockStatus, fullTxs bool) *pb.Block {
+	pbBlock := &pb.Block{
+		ChainId:          block.ChainID,
+		Epoch:            block.Epoch,
+		Height:           block.Height,
+		Parent:           block.Parent.Bytes(),
+		TransactionsHash: block.TxHash.Bytes(),
+		StateHash:        block.StateHash.Bytes(),
+		Timestamp:        jsonBigToUint64((*common.JSONBig)(block.Timestamp)),
+		Proposer:         block.Proposer.Bytes(),
+		Status:           uint32(blockStatus),
+		Hash:             block.Hash().Bytes(),
+	}
+	for _, raw := range block.Txs {
+		tx := &pb.Transaction{
+			Hash: crypto.Keccak256Hash(raw).Bytes(),
+		}
+		if decoded, err := types.TxFromBytes(raw); err == nil {
+			tx.Type = uint32(getTxType(decoded))
+		}
+		if fullTxs {
+			tx.Raw = raw
+		}
+		pbBlock.Transactions = append(pbBlock.Transactions, tx)
+	}
+	return pbBlock
+}
+
+func jsonBigToUint64(b *common.JSONBig) uint64 {
+	if b == nil {
+		return 0
+	}
+	return (*big.Int)(b).Uint64()
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+)
+
+// ------------------------------ Health and Readiness ------------------------------
+
+// healthCheck is the outcome of one of the checks reported by /healthz and /readyz.
+type healthCheck struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+type healthStatus struct {
+	Status string                 `json:"status"`
+	Checks map[string]healthCheck `json:"checks"`
+}
+
+// serveHealthz reports whether the node is alive, i.e. it serves requests and its database
+// is usable. It is meant for liveness probes, a node which is still syncing is healthy.
+func (t *ThetaRPCServer) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	writeHealthStatus(w, map[string]healthCheck{
+		"db": t.checkDB(),
+	})
+}
+
+// serveReadyz reports whether the node should receive RPC traffic: it is synced within the
+// configured number of blocks of the network, has enough peers and its database is usable.
+func (t *ThetaRPCServer) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]healthCheck{
+		"db": t.checkDB(),
+	}
+	// A read-only node serves a fixed state without connecting to the network
+	if !t.readOnly {
+		checks["synced"] = checkSynced(t.consensus.GetLastFinalizedBlock().Height, t.consensus.GetNetworkHeight(),
+			viper.GetUint64(common.CfgRPCHealthMaxBlocksBehind))
+		checks["peers"] = checkPeers(len(t.dispatcher.Peers(false)), viper.GetInt(common.CfgRPCHealthMinPeers))
+	}
+	writeHealthStatus(w, checks)
+}
+
+// checkDB checks that the database is writable, or readable in read-only mode.
+func (t *ThetaRPCServer) checkDB() healthCheck {
+	if t.readOnly {
+		lfb := t.consensus.GetLastFinalizedBlock()
+		if _, err := t.chain.FindBlock(lfb.Hash()); err != nil {
+			return healthCheck{Message: fmt.Sprintf("failed to read the database: %v", err)}
+		}
+		return healthCheck{OK: true}
+	}
+	if err := t.chain.CheckWritable(); err != nil {
+		return healthCheck{Message: fmt.Sprintf("failed to write the database: %v", err)}
+	}
+	return healthCheck{OK: true}
+}
+
+func checkSynced(finalizedHeight uint64, networkHeight uint64, maxBlocksBehind uint64) healthCheck {
+	if networkHeight == 0 {
+		return healthCheck{Message: "no votes received from the network yet"}
+	}
+	if networkHeight > finalizedHeight && networkHeight-finalizedHeight > maxBlocksBehind {
+		return healthCheck{Message: fmt.Sprintf("%v blocks behind the network at height %v",
+			networkHeight-finalizedHeight, networkHeight)}
+	}
+	return healthCheck{OK: true}
+}
+
+func checkPeers(numPeers int, minPeers int) healthCheck {
+	if numPeers < minPeers {
+		return healthCheck{Message: fmt.Sprintf("%v peers connected, at least %v required", numPeers, minPeers)}
+	}
+	return healthCheck{OK: true}
+}
+
+// writeHealthStatus writes the checks with status 200 if all of them pass, or 503 otherwise.
+func writeHealthStatus(w http.ResponseWriter, checks map[string]healthCheck) {
+	status := healthStatus{Status: "ok", Checks: checks}
+	code := http.StatusOK
+	for _, check := range checks {
+		if !check.OK {
+			status.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthChecks(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(checkSynced(100, 110, 10).OK)
+	assert.True(checkSynced(120, 110, 10).OK)
+	assert.False(checkSynced(99, 110, 10).OK)
+	assert.Equal("11 blocks behind the network at height 110", checkSynced(99, 110, 10).Message)
+	assert.False(checkSynced(100, 0, 10).OK)
+
+	assert.True(checkPeers(3, 3).OK)
+	assert.False(checkPeers(2, 3).OK)
+}
+
+func TestWriteHealthStatus(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rec := httptest.NewRecorder()
+	writeHealthStatus(rec, map[string]healthCheck{"db": {OK: true}, "peers": {OK: true}})
+	assert.Equal(http.StatusOK, rec.Code)
+	status := healthStatus{}
+	require.Nil(json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal("ok", status.Status)
+
+	rec = httptest.NewRecorder()
+	writeHealthStatus(rec, map[string]healthCheck{"db": {OK: true}, "peers": checkPeers(0, 1)})
+	assert.Equal(http.StatusServiceUnavailable, rec.Code)
+	status = healthStatus{}
+	require.Nil(json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal("unavailable", status.Status)
+	assert.Equal("0 peers connected, at least 1 required", status.Checks["peers"].Message)
+}
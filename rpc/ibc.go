@@ -0,0 +1,191 @@
+package rpc
+
+import (
+	"encoding/hex"
+
+	"github.com/thetatoken/theta/bridge"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// ------------------------------- GetIBCClient -----------------------------------
+
+type GetIBCClientArgs struct {
+	ClientID string            `json:"client_id"`
+	Height   common.JSONUint64 `json:"height"` // optional, the counterparty height of the consensus state to return
+}
+
+type GetIBCClientResult struct {
+	BlockHeight    common.JSONUint64        `json:"block_height"`
+	Client         *types.IBCClientState    `json:"client"`
+	ConsensusState *types.IBCConsensusState `json:"consensus_state"` // at the given height, or else at the latest height of the client
+}
+
+// GetIBCClient returns the light client of a counterparty chain, along with one of its consensus states
+func (t *ThetaRPCService) GetIBCClient(args *GetIBCClientArgs, result *GetIBCClientResult) (err error) {
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	client := ledgerState.GetIBCClient(args.ClientID)
+	if client == nil {
+		return errNotFound("IBC client %v not found", args.ClientID)
+	}
+	height := uint64(args.Height)
+	if height == 0 {
+		height = client.LatestHeight
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Client = client
+	result.ConsensusState = ledgerState.GetIBCConsensusState(client.ClientID, height)
+	return nil
+}
+
+// ------------------------------- GetIBCConnection -----------------------------------
+
+type GetIBCConnectionArgs struct {
+	ConnectionID string `json:"connection_id"`
+}
+
+type GetIBCConnectionResult struct {
+	BlockHeight common.JSONUint64    `json:"block_height"`
+	Connection  *types.IBCConnection `json:"connection"`
+}
+
+func (t *ThetaRPCService) GetIBCConnection(args *GetIBCConnectionArgs, result *GetIBCConnectionResult) (err error) {
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	conn := ledgerState.GetIBCConnection(args.ConnectionID)
+	if conn == nil {
+		return errNotFound("IBC connection %v not found", args.ConnectionID)
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Connection = conn
+	return nil
+}
+
+// ------------------------------- GetIBCChannel -----------------------------------
+
+type GetIBCChannelArgs struct {
+	PortID    string `json:"port_id"`
+	ChannelID string `json:"channel_id"`
+}
+
+type GetIBCChannelResult struct {
+	BlockHeight      common.JSONUint64 `json:"block_height"`
+	Channel          *types.IBCChannel `json:"channel"`
+	PortOwner        common.Address    `json:"port_owner"`
+	NextSequenceSend common.JSONUint64 `json:"next_sequence_send"`
+}
+
+func (t *ThetaRPCService) GetIBCChannel(args *GetIBCChannelArgs, result *GetIBCChannelResult) (err error) {
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	channel := ledgerState.GetIBCChannel(args.PortID, args.ChannelID)
+	if channel == nil {
+		return errNotFound("IBC channel %v/%v not found", args.PortID, args.ChannelID)
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Channel = channel
+	if port := ledgerState.GetIBCPort(channel.PortID); port != nil {
+		result.PortOwner = port.Owner
+	}
+	result.NextSequenceSend = common.JSONUint64(ledgerState.GetIBCNextSequenceSend(channel.PortID, channel.ChannelID))
+	return nil
+}
+
+// ------------------------------- GetIBCPackets -----------------------------------
+
+type GetIBCPacketsArgs struct {
+	PortID    string            `json:"port_id"`
+	ChannelID string            `json:"channel_id"`
+	Height    common.JSONUint64 `json:"height"` // optional, the latest finalized height if not specified
+}
+
+type GetIBCPacketsResult struct {
+	BlockHeight     common.JSONUint64  `json:"block_height"`
+	PendingPackets  []*types.IBCPacket `json:"pending_packets"`  // sent over the channel, not yet acknowledged or timed out
+	ReceivedPackets []*types.IBCPacket `json:"received_packets"` // received over the channel, and acknowledged with types.IBCAcknowledgementSuccess
+}
+
+// GetIBCPackets returns the packets for the relayers to deliver to the counterparty chain, that is
+// the packets pending on the channel, and the acknowledgements of the packets received on it
+func (t *ThetaRPCService) GetIBCPackets(args *GetIBCPacketsArgs, result *GetIBCPacketsResult) (err error) {
+	ledgerState, err := t.getLedgerStateAtHeight(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+
+	if ledgerState.GetIBCChannel(args.PortID, args.ChannelID) == nil {
+		return errNotFound("IBC channel %v/%v not found", args.PortID, args.ChannelID)
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.PendingPackets = ledgerState.GetIBCPackets(args.PortID, args.ChannelID)
+	result.ReceivedPackets = ledgerState.GetIBCPacketReceipts(args.PortID, args.ChannelID)
+	return nil
+}
+
+// ------------------------------- GetIBCStateProof -----------------------------------
+
+type GetIBCStateProofArgs struct {
+	Height common.JSONUint64 `json:"height"`
+	Key    string            `json:"key"` // the hex encoded state key, e.g. of a connection, a channel or a packet commitment
+}
+
+type GetIBCStateProofResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	StateHash   common.Hash       `json:"state_hash"`
+	Value       string            `json:"value"` // empty if the state holds no value at the key
+	Proof       []string          `json:"proof"` // the hex encoded trie nodes, see types.IBCProof
+}
+
+// GetIBCStateProof returns the Merkle proof of the value at the key, or of its absence, against the
+// state hash of the finalized block at the height. The relayers submit the proof along with the IBC
+// messages to the counterparty chain, once the client of the counterparty tracks the block, see
+// theta.GetBridgeProof.
+func (t *ThetaRPCService) GetIBCStateProof(args *GetIBCStateProofArgs, result *GetIBCStateProofResult) (err error) {
+	height := uint64(args.Height)
+	if height == 0 {
+		return errInvalidParams("Block height must be specified")
+	}
+	key, err := hex.DecodeString(args.Key)
+	if err != nil || len(key) == 0 {
+		return errInvalidParams("Invalid state key: %v", args.Key)
+	}
+
+	ledgerState, err := t.getLedgerStateAtHeight(height)
+	if err != nil {
+		return err
+	}
+	block := t.findFinalizedBlockByHeight(height)
+	if block == nil {
+		return errNotFound("No finalized block found at height %v", height)
+	}
+
+	proof := bridge.MerkleProof{}
+	if err := ledgerState.Prove(key, &proof); err != nil {
+		return errInternal("Failed to prove the state key: %v", err)
+	}
+
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.BlockHash = block.Hash()
+	result.StateHash = block.StateHash
+	result.Value = hex.EncodeToString(ledgerState.Get(key))
+	result.Proof = []string{}
+	for _, node := range proof {
+		result.Proof = append(result.Proof, hex.EncodeToString(node))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package jsonrpc2
 
 import (
+	"bytes"
 	"encoding/json"
 	"net"
 	"net/rpc"
@@ -8,6 +9,12 @@ import (
 
 var jErrRequest = json.RawMessage(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid request"}}`)
 
+// MaxBatchSize limits the number of requests in a batch, a larger batch is
+// rejected as a whole. Zero or negative value means no limit.
+//
+// It should be set before serving any requests.
+var MaxBatchSize = 0
+
 // JSONRPC2 is an internal RPC service used to process batch requests.
 type JSONRPC2 struct{}
 
@@ -19,6 +26,9 @@ type BatchArg struct {
 }
 
 // Batch is an internal RPC method used to process batch requests.
+//
+// The requests of the batch are executed concurrently, while the replies
+// are returned in the order of the requests.
 func (JSONRPC2) Batch(arg BatchArg, replies *[]*json.RawMessage) (err error) {
 	cli, srv := net.Pipe()
 	defer cli.Close()
@@ -27,29 +37,35 @@ func (JSONRPC2) Batch(arg BatchArg, replies *[]*json.RawMessage) (err error) {
 	replyc := make(chan *json.RawMessage, len(arg.reqs))
 	donec := make(chan struct{}, 1)
 
+	var results []*json.RawMessage
 	go func() {
 		dec := json.NewDecoder(cli)
-		*replies = make([]*json.RawMessage, 0, len(arg.reqs))
+		results = make([]*json.RawMessage, 0, len(arg.reqs))
 		for reply := range replyc {
 			if reply != nil {
-				*replies = append(*replies, reply)
+				results = append(results, reply)
 			} else {
-				*replies = append(*replies, new(json.RawMessage))
-				if dec.Decode((*replies)[len(*replies)-1]) != nil {
-					(*replies)[len(*replies)-1] = &jErrRequest
+				results = append(results, new(json.RawMessage))
+				if dec.Decode(results[len(results)-1]) != nil {
+					results[len(results)-1] = &jErrRequest
 				}
 			}
 		}
 		donec <- struct{}{}
 	}()
 
+	// ids holds the ID of the request expecting each reply, or nil if the
+	// request is invalid and the reply is known in advance.
+	ids := make([]*json.RawMessage, 0, len(arg.reqs))
 	var testreq serverRequest
 	for _, req := range arg.reqs {
 		if req == nil || json.Unmarshal(*req, &testreq) != nil {
 			replyc <- &jErrRequest
+			ids = append(ids, nil)
 		} else {
 			if testreq.ID != nil {
 				replyc <- nil
+				ids = append(ids, testreq.ID)
 			}
 			if _, err = cli.Write(append(*req, '\n')); err != nil {
 				break
@@ -59,5 +75,66 @@ func (JSONRPC2) Batch(arg BatchArg, replies *[]*json.RawMessage) (err error) {
 
 	close(replyc)
 	<-donec
+	*replies = orderReplies(ids, results)
 	return err
 }
+
+// orderReplies reorders the replies, which arrive in the order the calls
+// complete, to match the order of the requests with the given IDs.
+func orderReplies(ids []*json.RawMessage, results []*json.RawMessage) []*json.RawMessage {
+	ordered := make([]*json.RawMessage, len(results))
+	var pool []*json.RawMessage
+	for i, result := range results {
+		if ids[i] == nil {
+			ordered[i] = result
+		} else {
+			pool = append(pool, result)
+		}
+	}
+
+	var res struct {
+		ID *json.RawMessage `json:"id"`
+	}
+	for i, id := range ids {
+		if id == nil {
+			continue
+		}
+		for j, reply := range pool {
+			if reply == nil {
+				continue
+			}
+			res.ID = nil
+			if json.Unmarshal(*reply, &res) != nil || res.ID == nil || !equalIDs(*res.ID, *id) {
+				continue
+			}
+			ordered[i] = reply
+			pool[j] = nil
+			break
+		}
+	}
+
+	// Replies which can't be matched, e.g. because of the duplicated request
+	// IDs, fill the remaining places in the order of arrival.
+	next := 0
+	for i := range ordered {
+		if ordered[i] != nil {
+			continue
+		}
+		for next < len(pool) && pool[next] == nil {
+			next++
+		}
+		if next < len(pool) {
+			ordered[i] = pool[next]
+			next++
+		}
+	}
+	return ordered
+}
+
+func equalIDs(a, b json.RawMessage) bool {
+	var bufA, bufB bytes.Buffer
+	if json.Compact(&bufA, a) != nil || json.Compact(&bufB, b) != nil {
+		return false
+	}
+	return bytes.Equal(bufA.Bytes(), bufB.Bytes())
+}
@@ -0,0 +1,185 @@
+package jsonrpc2
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/rpc"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// BatchSvc is an RPC service for testing batch requests.
+type BatchSvc struct{}
+
+func (*BatchSvc) Sleep(ms [1]int, res *int) error {
+	time.Sleep(time.Duration(ms[0]) * time.Millisecond)
+	*res = ms[0]
+	return nil
+}
+
+func (*BatchSvc) Echo(vals [1]int, res *int) error {
+	*res = vals[0]
+	return nil
+}
+
+func serveBatch(t *testing.T, in string) string {
+	return serveBatchContext(t, context.Background(), in)
+}
+
+func serveBatchContext(t *testing.T, ctx context.Context, in string) string {
+	server := rpc.NewServer()
+	if err := server.Register(&BatchSvc{}); err != nil {
+		t.Fatal(err)
+	}
+	cli, srv := net.Pipe()
+	defer cli.Close()
+	go server.ServeCodec(NewServerCodecContext(ctx, srv, server))
+
+	if _, err := cli.Write([]byte(in + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := bufio.NewReader(cli).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimRight(got, "\n")
+}
+
+func TestBatchRepliesInRequestOrder(t *testing.T) {
+	in := `[` +
+		`{"jsonrpc":"2.0","id":"a","method":"BatchSvc.Sleep","params":[60]},` +
+		`{"jsonrpc":"2.0","id":"b","method":"BatchSvc.Sleep","params":[30]},` +
+		`{"id":"c"},` +
+		`{"jsonrpc":"2.0","method":"BatchSvc.Sleep","params":[0]},` +
+		`{"jsonrpc":"2.0","id":"d","method":"BatchSvc.Sleep","params":[0]}]`
+	want := `[` +
+		`{"jsonrpc":"2.0","id":"a","result":60},` +
+		`{"jsonrpc":"2.0","id":"b","result":30},` +
+		jerrRequest + `,` +
+		`{"jsonrpc":"2.0","id":"d","result":0}]`
+
+	start := time.Now()
+	got := serveBatch(t, in)
+	if got != want {
+		t.Errorf("\nwant: %#q\nrecv: %#q", want, got)
+	}
+	// The calls run concurrently
+	if elapsed := time.Since(start); elapsed >= 90*time.Millisecond {
+		t.Errorf("batch took %v", elapsed)
+	}
+}
+
+func TestBatchSizeLimit(t *testing.T) {
+	defer func(size int) { MaxBatchSize = size }(MaxBatchSize)
+	MaxBatchSize = 2
+
+	in := `[` +
+		`{"jsonrpc":"2.0","id":1,"method":"BatchSvc.Sleep","params":[0]},` +
+		`{"jsonrpc":"2.0","id":2,"method":"BatchSvc.Sleep","params":[0]},` +
+		`{"jsonrpc":"2.0","id":3,"method":"BatchSvc.Sleep","params":[0]}]`
+	want := `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch size exceeds the limit of 2"}}`
+	if got := serveBatch(t, in); got != want {
+		t.Errorf("\nwant: %#q\nrecv: %#q", want, got)
+	}
+
+	in = `[` +
+		`{"jsonrpc":"2.0","id":1,"method":"BatchSvc.Sleep","params":[0]},` +
+		`{"jsonrpc":"2.0","id":2,"method":"BatchSvc.Sleep","params":[0]}]`
+	want = `[{"jsonrpc":"2.0","id":1,"result":0},{"jsonrpc":"2.0","id":2,"result":0}]`
+	if got := serveBatch(t, in); got != want {
+		t.Errorf("\nwant: %#q\nrecv: %#q", want, got)
+	}
+}
+
+func TestMethodFilter(t *testing.T) {
+	ctx := WithMethodFilter(context.Background(), func(method string) error {
+		if method != "BatchSvc.Sleep" {
+			return NewError(-32001, "method not allowed")
+		}
+		return nil
+	})
+
+	in := `{"jsonrpc":"2.0","id":1,"method":"BatchSvc.Sleep","params":[0]}`
+	want := `{"jsonrpc":"2.0","id":1,"result":0}`
+	if got := serveBatchContext(t, ctx, in); got != want {
+		t.Errorf("\nwant: %#q\nrecv: %#q", want, got)
+	}
+
+	in = `{"jsonrpc":"2.0","id":1,"method":"BatchSvc.Echo","params":[0]}`
+	want = `{"jsonrpc":"2.0","id":1,"error":{"code":-32001,"message":"method not allowed"}}`
+	if got := serveBatchContext(t, ctx, in); got != want {
+		t.Errorf("\nwant: %#q\nrecv: %#q", want, got)
+	}
+
+	// The filter also applies to the requests of a batch
+	in = `[` +
+		`{"jsonrpc":"2.0","id":1,"method":"BatchSvc.Echo","params":[0]},` +
+		`{"jsonrpc":"2.0","id":2,"method":"BatchSvc.Sleep","params":[0]}]`
+	want = `[` +
+		`{"jsonrpc":"2.0","id":1,"error":{"code":-32001,"message":"method not allowed"}},` +
+		`{"jsonrpc":"2.0","id":2,"result":0}]`
+	if got := serveBatchContext(t, ctx, in); got != want {
+		t.Errorf("\nwant: %#q\nrecv: %#q", want, got)
+	}
+}
+
+func TestParamsValidator(t *testing.T) {
+	ctx := WithParamsValidator(context.Background(), func(method string, params json.RawMessage) error {
+		if string(params) != "[0]" {
+			return NewError(-32602, method+": params must be [0]")
+		}
+		return nil
+	})
+
+	in := `{"jsonrpc":"2.0","id":1,"method":"BatchSvc.Echo","params":[0]}`
+	want := `{"jsonrpc":"2.0","id":1,"result":0}`
+	if got := serveBatchContext(t, ctx, in); got != want {
+		t.Errorf("\nwant: %#q\nrecv: %#q", want, got)
+	}
+
+	// The validator also applies to the requests of a batch
+	in = `[` +
+		`{"jsonrpc":"2.0","id":1,"method":"BatchSvc.Echo","params":[1]},` +
+		`{"jsonrpc":"2.0","id":2,"method":"BatchSvc.Echo","params":[0]}]`
+	want = `[` +
+		`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"BatchSvc.Echo: params must be [0]"}},` +
+		`{"jsonrpc":"2.0","id":2,"result":0}]`
+	if got := serveBatchContext(t, ctx, in); got != want {
+		t.Errorf("\nwant: %#q\nrecv: %#q", want, got)
+	}
+}
+
+func TestCallObserver(t *testing.T) {
+	var mu sync.Mutex
+	observed := map[string]time.Duration{}
+	ctx := WithCallObserver(context.Background(), func(method string, elapsed time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		observed[method] += elapsed
+	})
+
+	// The requests of a batch are observed individually, but not the batch itself
+	in := `[` +
+		`{"jsonrpc":"2.0","id":1,"method":"BatchSvc.Sleep","params":[20]},` +
+		`{"jsonrpc":"2.0","id":2,"method":"BatchSvc.Echo","params":[0]}]`
+	want := `[{"jsonrpc":"2.0","id":1,"result":20},{"jsonrpc":"2.0","id":2,"result":0}]`
+	if got := serveBatchContext(t, ctx, in); got != want {
+		t.Errorf("\nwant: %#q\nrecv: %#q", want, got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(observed) != 2 {
+		t.Fatalf("observed calls: %v", observed)
+	}
+	if elapsed := observed["BatchSvc.Sleep"]; elapsed < 20*time.Millisecond {
+		t.Errorf("BatchSvc.Sleep took %v", elapsed)
+	}
+	if _, ok := observed["BatchSvc.Echo"]; !ok {
+		t.Errorf("BatchSvc.Echo not observed")
+	}
+}
@@ -1,6 +1,10 @@
 package jsonrpc2
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
 
 // WithContext is an interface which should be implemented by RPC method
 // parameters type if you need access to request context in RPC method.
@@ -28,3 +32,64 @@ func (c *Ctx) Context() context.Context {
 func (c *Ctx) SetContext(ctx context.Context) {
 	c.ctx = ctx
 }
+
+type methodFilterContextKey struct{}
+
+// MethodFilter decides whether the given method may be called, a non-nil
+// error rejects the call and is returned to the client.
+type MethodFilter func(method string) error
+
+// WithMethodFilter returns a copy of ctx which makes the server codecs
+// created with it (including the ones executing batch requests) reject
+// the methods not accepted by filter.
+func WithMethodFilter(ctx context.Context, filter MethodFilter) context.Context {
+	return context.WithValue(ctx, methodFilterContextKey{}, filter)
+}
+
+// MethodFilterFromContext returns the filter set by WithMethodFilter or
+// nil otherwise.
+func MethodFilterFromContext(ctx context.Context) MethodFilter {
+	filter, _ := ctx.Value(methodFilterContextKey{}).(MethodFilter)
+	return filter
+}
+
+type paramsValidatorContextKey struct{}
+
+// ParamsValidator checks the raw params of a call to the given method
+// before they are decoded, a non-nil error rejects the call and is
+// returned to the client.
+type ParamsValidator func(method string, params json.RawMessage) error
+
+// WithParamsValidator returns a copy of ctx which makes the server codecs
+// created with it (including the ones executing batch requests) validate
+// the params of each call with validator.
+func WithParamsValidator(ctx context.Context, validator ParamsValidator) context.Context {
+	return context.WithValue(ctx, paramsValidatorContextKey{}, validator)
+}
+
+// ParamsValidatorFromContext returns the validator set by
+// WithParamsValidator or nil otherwise.
+func ParamsValidatorFromContext(ctx context.Context) ParamsValidator {
+	validator, _ := ctx.Value(paramsValidatorContextKey{}).(ParamsValidator)
+	return validator
+}
+
+type callObserverContextKey struct{}
+
+// CallObserver is notified of the duration of each call once its response
+// has been written.
+type CallObserver func(method string, elapsed time.Duration)
+
+// WithCallObserver returns a copy of ctx which makes the server codecs
+// created with it (including the ones executing batch requests) report
+// the duration of each call to observer.
+func WithCallObserver(ctx context.Context, observer CallObserver) context.Context {
+	return context.WithValue(ctx, callObserverContextKey{}, observer)
+}
+
+// CallObserverFromContext returns the observer set by WithCallObserver or
+// nil otherwise.
+func CallObserverFromContext(ctx context.Context) CallObserver {
+	observer, _ := ctx.Value(callObserverContextKey{}).(CallObserver)
+	return observer
+}
@@ -74,7 +74,17 @@ func (h *httpHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	ctx := context.WithValue(context.Background(), httpRequestContextKey, req)
+	ctx := context.Background()
+	if filter := MethodFilterFromContext(req.Context()); filter != nil {
+		ctx = WithMethodFilter(ctx, filter)
+	}
+	if validator := ParamsValidatorFromContext(req.Context()); validator != nil {
+		ctx = WithParamsValidator(ctx, validator)
+	}
+	if observer := CallObserverFromContext(req.Context()); observer != nil {
+		ctx = WithCallObserver(ctx, observer)
+	}
+	ctx = context.WithValue(ctx, httpRequestContextKey, req)
 	conn := &httpServerConn{req: req.Body, res: w}
 	_ = h.rpc.ServeRequest(NewServerCodecContext(ctx, conn, h.rpc))
 	if !conn.replied {
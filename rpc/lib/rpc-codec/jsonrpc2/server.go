@@ -9,9 +9,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/rpc"
 	"sync"
+	"time"
 )
 
 const (
@@ -28,7 +30,8 @@ type serverCodec struct {
 	ctx      context.Context
 
 	// temporary work space
-	req serverRequest
+	req    serverRequest
+	reqErr error // set if the method of req is rejected by the filter
 
 	// JSON-RPC clients can use arbitrary json values as request IDs.
 	// Package rpc expects uint64 request IDs.
@@ -36,9 +39,17 @@ type serverCodec struct {
 	// but save the original request ID in the pending map.
 	// When rpc responds, we use the sequence number in
 	// the response to find the original request ID.
-	mutex   sync.Mutex // protects seq, pending
+	mutex   sync.Mutex // protects seq, pending, calls
 	seq     uint64
 	pending map[uint64]*json.RawMessage
+
+	// The calls being served, only tracked if a CallObserver is set.
+	calls map[uint64]observedCall
+}
+
+type observedCall struct {
+	method string
+	start  time.Time
 }
 
 // NewServerCodec returns a new rpc.ServerCodec using JSON-RPC 2.0 on conn,
@@ -63,6 +74,7 @@ func NewServerCodec(conn io.ReadWriteCloser, srv *rpc.Server) rpc.ServerCodec {
 		srv:     srv,
 		ctx:     context.Background(),
 		pending: make(map[uint64]*json.RawMessage),
+		calls:   make(map[uint64]observedCall),
 	}
 }
 
@@ -171,12 +183,20 @@ func (c *serverCodec) ReadRequestHeader(r *rpc.Request) (err error) {
 
 	r.ServiceMethod = c.req.Method
 
+	c.reqErr = nil
+	if filter := MethodFilterFromContext(c.ctx); filter != nil && c.req.Method != batchMethod {
+		c.reqErr = filter(c.req.Method)
+	}
+
 	// JSON request id can be any JSON value;
 	// RPC package expects uint64.  Translate to
 	// internal uint64 and save JSON on the side.
 	c.mutex.Lock()
 	c.seq++
 	c.pending[c.seq] = c.req.ID
+	if c.req.Method != batchMethod && CallObserverFromContext(c.ctx) != nil {
+		c.calls[c.seq] = observedCall{method: c.req.Method, start: time.Now()}
+	}
 	c.req.ID = nil
 	r.Seq = c.seq
 	c.mutex.Unlock()
@@ -190,6 +210,9 @@ func (c *serverCodec) ReadRequestBody(x interface{}) error {
 	if x == nil {
 		return nil
 	}
+	if c.reqErr != nil {
+		return c.reqErr
+	}
 	if x, ok := x.(WithContext); ok {
 		x.SetContext(c.ctx)
 	}
@@ -205,9 +228,18 @@ func (c *serverCodec) ReadRequestBody(x interface{}) error {
 		if len(arg.reqs) == 0 {
 			return errRequest
 		}
+		if MaxBatchSize > 0 && len(arg.reqs) > MaxBatchSize {
+			return NewError(errRequest.Code, fmt.Sprintf("batch size exceeds the limit of %d", MaxBatchSize))
+		}
 		return nil
 	}
 
+	if validator := ParamsValidatorFromContext(c.ctx); validator != nil {
+		if err := validator(c.req.Method, *c.req.Params); err != nil {
+			return err
+		}
+	}
+
 	// Try decoding list style arguments.
 	var params [1]interface{}
 	params[0] = x
@@ -237,8 +269,14 @@ func (c *serverCodec) WriteResponse(r *rpc.Response, x interface{}) error {
 		return errors.New("invalid sequence number in response")
 	}
 	delete(c.pending, r.Seq)
+	call, observed := c.calls[r.Seq]
+	delete(c.calls, r.Seq)
 	c.mutex.Unlock()
 
+	if observed {
+		CallObserverFromContext(c.ctx)(call.method, time.Since(call.start))
+	}
+
 	if replies, ok := x.(*[]*json.RawMessage); r.ServiceMethod == batchMethod && ok {
 		if len(*replies) == 0 {
 			return nil
@@ -0,0 +1,264 @@
+package rpc
+
+import (
+	"sync"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+const (
+	maxLogQueryBlockRange     = 5000
+	logSubscriptionBufferSize = 256
+)
+
+// LogFilter selects the smart contract event logs by the emitting contract address and the topics.
+// An empty address list matches any contract. Topics are matched by position, an empty list at a
+// position matches any topic, otherwise the topic at that position needs to match one of the list.
+type LogFilter struct {
+	Addresses []common.Address `json:"addresses"`
+	Topics    [][]common.Hash  `json:"topics"`
+}
+
+// Match returns whether the given log satisfies the filter.
+func (f *LogFilter) Match(log *types.Log) bool {
+	if len(f.Addresses) > 0 {
+		found := false
+		for _, addr := range f.Addresses {
+			if addr == log.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, candidates := range f.Topics {
+		if len(candidates) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range candidates {
+			if topic == log.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// LogEntry is a smart contract event log along with its position in the chain.
+type LogEntry struct {
+	*types.Log
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	TxHash      common.Hash       `json:"transaction_hash"`
+	TxIndex     common.JSONUint64 `json:"transaction_index"`
+	LogIndex    common.JSONUint64 `json:"log_index"`
+}
+
+// filterBlockLogs collects the logs emitted by the smart contract transactions of the given block
+// which satisfy the filter.
+func (t *ThetaRPCService) filterBlockLogs(block *core.Block, filter *LogFilter) []*LogEntry {
+	entries := []*LogEntry{}
+	logIndex := uint64(0)
+	for txIndex, txBytes := range block.Txs {
+		txHash := crypto.Keccak256Hash(txBytes)
+		receipt, found := t.chain.FindTxReceiptByHash(txHash)
+		if !found {
+			continue
+		}
+		for _, log := range receipt.Logs {
+			if filter.Match(log) {
+				entries = append(entries, &LogEntry{
+					Log:         log,
+					BlockHash:   block.Hash(),
+					BlockHeight: common.JSONUint64(block.Height),
+					TxHash:      txHash,
+					TxIndex:     common.JSONUint64(txIndex),
+					LogIndex:    common.JSONUint64(logIndex),
+				})
+			}
+			logIndex++
+		}
+	}
+	return entries
+}
+
+// ------------------------------ GetLogs -----------------------------------
+
+type GetLogsArgs struct {
+	LogFilter
+	FromBlock common.JSONUint64 `json:"from_block"`
+	ToBlock   common.JSONUint64 `json:"to_block"` // 0 means the latest finalized block
+}
+
+type GetLogsResult struct {
+	Logs []*LogEntry `json:"logs"`
+}
+
+// GetLogs returns the event logs of the finalized blocks within the given height range which
+// satisfy the given filter.
+func (t *ThetaRPCService) GetLogs(args *GetLogsArgs, result *GetLogsResult) (err error) {
+	toBlock := args.ToBlock
+	lastFinalizedHeight := common.JSONUint64(t.consensus.GetLastFinalizedBlock().Height)
+	if toBlock == 0 || toBlock > lastFinalizedHeight {
+		toBlock = lastFinalizedHeight
+	}
+	if args.FromBlock > toBlock {
+		return errInvalidParams("Starting block must be less than ending block")
+	}
+	if toBlock-args.FromBlock > maxLogQueryBlockRange {
+		return errInvalidParams("Can't query logs of more than 5000 blocks at a time")
+	}
+
+	result.Logs = []*LogEntry{}
+	for height := uint64(args.FromBlock); height <= uint64(toBlock); height++ {
+		for _, block := range t.chain.FindBlocksByHeight(height) {
+			if block.Status.IsFinalized() {
+				result.Logs = append(result.Logs, t.filterBlockLogs(block.Block, &args.LogFilter)...)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// ------------------------------ Log Subscriptions -----------------------------------
+
+type logSubscription struct {
+	filter *LogFilter
+	logC   chan *LogEntry
+}
+
+// LogSubscriptionManager pushes the event logs of the newly finalized blocks to the subscribers.
+type LogSubscriptionManager struct {
+	mu            *sync.Mutex
+	subscriptions map[*logSubscription]struct{}
+}
+
+// NewLogSubscriptionManager creates a new instance of LogSubscriptionManager.
+func NewLogSubscriptionManager() *LogSubscriptionManager {
+	return &LogSubscriptionManager{
+		mu:            &sync.Mutex{},
+		subscriptions: make(map[*logSubscription]struct{}),
+	}
+}
+
+func (m *LogSubscriptionManager) subscribe(filter *LogFilter) *logSubscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &logSubscription{
+		filter: filter,
+		logC:   make(chan *LogEntry, logSubscriptionBufferSize),
+	}
+	m.subscriptions[sub] = struct{}{}
+	return sub
+}
+
+func (m *LogSubscriptionManager) unsubscribe(sub *logSubscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subscriptions[sub]; ok {
+		delete(m.subscriptions, sub)
+		close(sub.logC)
+	}
+}
+
+func (m *LogSubscriptionManager) hasSubscriptions() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.subscriptions) > 0
+}
+
+// publish delivers the matching logs of the given block to each subscriber. A subscriber that
+// can't keep up is dropped rather than blocking the publisher.
+func (m *LogSubscriptionManager) publish(collect func(filter *LogFilter) []*LogEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for sub := range m.subscriptions {
+		for _, entry := range collect(sub.filter) {
+			select {
+			case sub.logC <- entry:
+			default:
+				logger.Warnf("Log subscriber is too slow, dropping the subscription")
+				delete(m.subscriptions, sub)
+				close(sub.logC)
+			}
+			if _, ok := m.subscriptions[sub]; !ok {
+				break
+			}
+		}
+	}
+}
+
+func (t *ThetaRPCService) publishLogs(block *core.Block) {
+	if !t.logSubscriptions.hasSubscriptions() {
+		return
+	}
+	t.logSubscriptions.publish(func(filter *LogFilter) []*LogEntry {
+		return t.filterBlockLogs(block, filter)
+	})
+}
+
+// serveLogSubscription handles a WebSocket log subscription. The client first sends a LogFilter
+// in JSON, after which the event logs of the newly finalized blocks matching the filter are pushed
+// to the client until the connection is closed.
+func (t *ThetaRPCService) serveLogSubscription(ws *websocket.Conn) {
+	defer ws.Close()
+
+	filter := &LogFilter{}
+	if err := websocket.JSON.Receive(ws, filter); err != nil {
+		logger.Debugf("Failed to read log filter: %v", err)
+		return
+	}
+
+	sub := t.logSubscriptions.subscribe(filter)
+	defer t.logSubscriptions.unsubscribe(sub)
+
+	// Detect client disconnection, since the client is not expected to send anything else
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var msg interface{}
+		for {
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case entry, ok := <-sub.logC:
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(ws, entry); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package rpc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/mempool"
+)
+
+// ------------------------------- GetMempoolStatus -----------------------------------
+
+type GetMempoolStatusArgs struct {
+}
+
+type GetMempoolStatusResult struct {
+	NumTxs       common.JSONUint64 `json:"num_txs"`
+	NumQueuedTxs common.JSONUint64 `json:"num_queued_txs"` // transactions waiting for the transactions with lower sequences
+	TotalBytes   common.JSONUint64 `json:"total_bytes"`
+	MaxNumTxs    common.JSONUint64 `json:"max_num_txs"` // 0 means uncapped
+}
+
+func (t *ThetaRPCService) GetMempoolStatus(args *GetMempoolStatusArgs, result *GetMempoolStatusResult) (err error) {
+	numTxs, numQueuedTxs, totalBytes := t.mempool.GetStats()
+	result.NumTxs = common.JSONUint64(numTxs)
+	result.NumQueuedTxs = common.JSONUint64(numQueuedTxs)
+	result.TotalBytes = common.JSONUint64(totalBytes)
+	if maxSize := t.mempool.MaxSize(); maxSize > 0 {
+		result.MaxNumTxs = common.JSONUint64(maxSize)
+	}
+	return nil
+}
+
+// ------------------------------- ListMempoolTransactions -----------------------------------
+
+type ListMempoolTransactionsArgs struct {
+	Offset common.JSONUint64 `json:"offset"` // optional, the number of transactions to skip
+	Limit  common.JSONUint64 `json:"limit"`  // optional, the maximum number of transactions per page
+}
+
+type MempoolTransaction struct {
+	TxHash            common.Hash       `json:"hash"`
+	Address           common.Address    `json:"address"`
+	Sequence          common.JSONUint64 `json:"sequence"`
+	Size              common.JSONUint64 `json:"size"`
+	EffectiveGasPrice *common.JSONBig   `json:"effective_gas_price"`
+	Fee               *common.JSONBig   `json:"fee"`
+	FeePerByte        *common.JSONBig   `json:"fee_per_byte"`
+	Queued            bool              `json:"queued"`
+	ReceivedAt        common.JSONUint64 `json:"received_at"` // unix timestamp in seconds
+}
+
+type ListMempoolTransactionsResult struct {
+	Total common.JSONUint64     `json:"total"`
+	Txs   []*MempoolTransaction `json:"transactions"`
+}
+
+// ListMempoolTransactions returns a page of the transactions held in the mempool, ordered by the
+// fee per byte (high to low). Since the mempool keeps changing, the pages are not guaranteed to be
+// consistent with each other.
+func (t *ThetaRPCService) ListMempoolTransactions(args *ListMempoolTransactionsArgs, result *ListMempoolTransactionsResult) (err error) {
+	limit := int(args.Limit)
+	if limit == 0 {
+		limit = defaultListStateLimit
+	}
+	if limit > maxListStateLimit {
+		return errInvalidParams("Limit cannot exceed %v", maxListStateLimit)
+	}
+
+	pendingTxs := t.mempool.GetPendingTransactions()
+	result.Total = common.JSONUint64(len(pendingTxs))
+	result.Txs = []*MempoolTransaction{}
+	for i := uint64(args.Offset); i < uint64(len(pendingTxs)) && len(result.Txs) < limit; i++ {
+		result.Txs = append(result.Txs, newMempoolTransaction(pendingTxs[i]))
+	}
+	return nil
+}
+
+// ------------------------------- GetMempoolTransaction -----------------------------------
+
+type GetMempoolTransactionArgs struct {
+	Hash string `json:"hash"`
+}
+
+type GetMempoolTransactionResult struct {
+	MempoolTransaction
+	Type  byte     `json:"type"`
+	Tx    types.Tx `json:"transaction"`
+	Valid bool     `json:"valid"`
+	Error string   `json:"error"` // why the transaction is not valid, or is waiting in the queue
+}
+
+// GetMempoolTransaction returns the transaction with the given hash held in the mempool. The
+// transaction is revalidated against the latest committed state, after the pending transactions of
+// the same account with lower sequences.
+func (t *ThetaRPCService) GetMempoolTransaction(args *GetMempoolTransactionArgs, result *GetMempoolTransactionResult) (err error) {
+	if args.Hash == "" {
+		return errInvalidParams("Transanction hash must be specified")
+	}
+	hash := strings.TrimPrefix(strings.ToLower(args.Hash), "0x")
+
+	pendingTx, found := t.mempool.GetPendingTransaction(hash)
+	if !found {
+		return errNotFound("Transaction %v not found in the mempool", args.Hash)
+	}
+
+	tx, err := types.TxFromBytes(pendingTx.RawTx)
+	if err != nil {
+		return err
+	}
+	result.MempoolTransaction = *newMempoolTransaction(pendingTx)
+	result.Type = getTxType(tx)
+	result.Tx = tx
+
+	if pendingTx.Queued {
+		expectedSequence := t.ledger.GetAccountSequence(pendingTx.TxInfo.Address) + 1
+		result.Error = fmt.Sprintf("Waiting for the transaction with sequence %v", expectedSequence)
+		return nil
+	}
+
+	_, res := t.ledger.ScreenTxReplacement(pendingTx.RawTx, pendingTx.PrecedingRawTxs)
+	result.Valid = res.IsOK()
+	if !result.Valid {
+		result.Error = res.Message
+	}
+	return nil
+}
+
+func newMempoolTransaction(pendingTx *mempool.PendingTransaction) *MempoolTransaction {
+	return &MempoolTransaction{
+		TxHash:            common.HexToHash(pendingTx.Hash),
+		Address:           pendingTx.TxInfo.Address,
+		Sequence:          common.JSONUint64(pendingTx.TxInfo.Sequence),
+		Size:              common.JSONUint64(len(pendingTx.RawTx)),
+		EffectiveGasPrice: (*common.JSONBig)(pendingTx.TxInfo.EffectiveGasPrice),
+		Fee:               (*common.JSONBig)(pendingTx.TxInfo.Fee),
+		FeePerByte:        (*common.JSONBig)(pendingTx.FeePerByte),
+		Queued:            pendingTx.Queued,
+		ReceivedAt:        common.JSONUint64(pendingTx.ReceivedAt.Unix()),
+	}
+}
+
+// ------------------------------ Pending Transaction Subscriptions -----------------------------------
+
+const pendingTxSubscriptionBufferSize = 256
+
+// PendingTxFilter selects the newly accepted pending transactions by the addresses involved, i.e. the
+// senders and the recipients. An empty address list matches any transaction. When FullTx is set, the
+// transaction bodies are pushed along with the hashes.
+type PendingTxFilter struct {
+	Addresses []common.Address `json:"addresses"`
+	FullTx    bool             `json:"full_tx"`
+}
+
+// Match returns whether any of the given addresses satisfies the filter.
+func (f *PendingTxFilter) Match(addresses []common.Address) bool {
+	if len(f.Addresses) == 0 {
+		return true
+	}
+	for _, filterAddr := range f.Addresses {
+		for _, addr := range addresses {
+			if filterAddr == addr {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PendingTxEntry is a newly accepted pending transaction pushed to the subscribers. The type and the
+// body are only set if the subscriber asks for the full transactions.
+type PendingTxEntry struct {
+	TxHash   common.Hash       `json:"hash"`
+	Address  common.Address    `json:"address"`
+	Sequence common.JSONUint64 `json:"sequence"`
+	Type     *byte             `json:"type,omitempty"`
+	Tx       types.Tx          `json:"transaction,omitempty"`
+}
+
+type pendingTxSubscription struct {
+	filter *PendingTxFilter
+	txC    chan *PendingTxEntry
+}
+
+// PendingTxSubscriptionManager pushes the newly accepted pending transactions to the subscribers.
+type PendingTxSubscriptionManager struct {
+	mu            *sync.Mutex
+	subscriptions map[*pendingTxSubscription]struct{}
+}
+
+// NewPendingTxSubscriptionManager creates a new instance of PendingTxSubscriptionManager.
+func NewPendingTxSubscriptionManager() *PendingTxSubscriptionManager {
+	return &PendingTxSubscriptionManager{
+		mu:            &sync.Mutex{},
+		subscriptions: make(map[*pendingTxSubscription]struct{}),
+	}
+}
+
+func (m *PendingTxSubscriptionManager) subscribe(filter *PendingTxFilter) *pendingTxSubscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &pendingTxSubscription{
+		filter: filter,
+		txC:    make(chan *PendingTxEntry, pendingTxSubscriptionBufferSize),
+	}
+	m.subscriptions[sub] = struct{}{}
+	return sub
+}
+
+func (m *PendingTxSubscriptionManager) unsubscribe(sub *pendingTxSubscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subscriptions[sub]; ok {
+		delete(m.subscriptions, sub)
+		close(sub.txC)
+	}
+}
+
+func (m *PendingTxSubscriptionManager) hasSubscriptions() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.subscriptions) > 0
+}
+
+// publish delivers the pending transaction to each subscriber whose filter matches the involved
+// addresses. A subscriber that can't keep up is dropped rather than blocking the publisher.
+func (m *PendingTxSubscriptionManager) publish(tx types.Tx, pendingTx *mempool.PendingTransaction, addresses []common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	txType := getTxType(tx)
+	for sub := range m.subscriptions {
+		if !sub.filter.Match(addresses) {
+			continue
+		}
+		entry := &PendingTxEntry{
+			TxHash:   common.HexToHash(pendingTx.Hash),
+			Address:  pendingTx.TxInfo.Address,
+			Sequence: common.JSONUint64(pendingTx.TxInfo.Sequence),
+		}
+		if sub.filter.FullTx {
+			entry.Type = &txType
+			entry.Tx = tx
+		}
+		select {
+		case sub.txC <- entry:
+		default:
+			logger.Warnf("Pending transaction subscriber is too slow, dropping the subscription")
+			delete(m.subscriptions, sub)
+			close(sub.txC)
+		}
+	}
+}
+
+// pendingTxFeed forwards the transactions newly accepted by the mempool to the subscribers.
+func (t *ThetaRPCService) pendingTxFeed() {
+	defer t.wg.Done()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case pendingTx := <-t.mempool.AcceptedTransactions():
+			if !t.pendingTxSubscriptions.hasSubscriptions() {
+				continue
+			}
+			tx, err := types.TxFromBytes(pendingTx.RawTx)
+			if err != nil {
+				continue
+			}
+			addresses := append(getTxAddresses(tx), pendingTx.TxInfo.Address)
+			t.pendingTxSubscriptions.publish(tx, pendingTx, addresses)
+		}
+	}
+}
+
+// getTxAddresses returns the addresses of the senders and the recipients of the given transaction.
+func getTxAddresses(tx types.Tx) []common.Address {
+	addresses := []common.Address{}
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		for _, input := range tx.Inputs {
+			addresses = append(addresses, input.Address)
+		}
+		for _, output := range tx.Outputs {
+			addresses = append(addresses, output.Address)
+		}
+	case *types.BatchSendTx:
+		addresses = append(addresses, tx.Input.Address)
+		for _, output := range tx.Outputs {
+			addresses = append(addresses, output.Address)
+		}
+	case *types.MultiSigSendTx:
+		addresses = append(addresses, tx.Input.Address)
+		for _, output := range tx.Outputs {
+			addresses = append(addresses, output.Address)
+		}
+	case *types.SmartContractTx:
+		addresses = append(addresses, tx.From.Address, tx.To.Address)
+	case *types.SmartContractTxV2:
+		addresses = append(addresses, tx.From.Address, tx.To.Address)
+	case *types.ServicePaymentTx:
+		addresses = append(addresses, tx.Source.Address, tx.Target.Address)
+	case *types.DepositStakeTx:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.DepositStakeTxV2:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.WithdrawStakeTx:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.VestingTransferTx:
+		addresses = append(addresses, tx.Source.Address, tx.Beneficiary)
+	case *types.VestingClaimTx:
+		addresses = append(addresses, tx.Beneficiary.Address)
+	}
+	return addresses
+}
+
+// servePendingTxSubscription handles a WebSocket pending transaction subscription. The client first
+// sends a PendingTxFilter in JSON, after which the newly accepted pending transactions matching the
+// filter are pushed to the client until the connection is closed.
+func (t *ThetaRPCService) servePendingTxSubscription(ws *websocket.Conn) {
+	defer ws.Close()
+
+	filter := &PendingTxFilter{}
+	if err := websocket.JSON.Receive(ws, filter); err != nil {
+		logger.Debugf("Failed to read pending transaction filter: %v", err)
+		return
+	}
+
+	sub := t.pendingTxSubscriptions.subscribe(filter)
+	defer t.pendingTxSubscriptions.unsubscribe(sub)
+
+	// Detect client disconnection, since the client is not expected to send anything else
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var msg interface{}
+		for {
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case entry, ok := <-sub.txC:
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(ws, entry); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
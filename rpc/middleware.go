@@ -0,0 +1,197 @@
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+// ------------------------------ CORS ------------------------------
+
+// corsPolicy sets the CORS headers of the responses to the allowed origins.
+type corsPolicy struct {
+	allowAll bool
+	origins  map[string]bool
+}
+
+func newCORSPolicy(origins []string) *corsPolicy {
+	c := &corsPolicy{origins: make(map[string]bool)}
+	for _, origin := range origins {
+		if origin == "*" {
+			c.allowAll = true
+		}
+		c.origins[strings.ToLower(strings.TrimRight(origin, "/"))] = true
+	}
+	return c
+}
+
+func (c *corsPolicy) middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if c.allowAll {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Headers", "*")
+		} else if origin != "" && c.origins[strings.ToLower(origin)] {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Headers", "*")
+			w.Header().Add("Vary", "Origin")
+		} else if origin != "" && r.Method == "OPTIONS" {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// ------------------------------ Trusted Proxies ------------------------------
+
+// trustedProxies resolves the IP of the client behind the trusted reverse proxies.
+type trustedProxies []*net.IPNet
+
+// newTrustedProxies parses the proxy entries, each of which is either an IP or a CIDR.
+func newTrustedProxies(entries []string) (trustedProxies, error) {
+	proxies := trustedProxies{}
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("Invalid trusted proxy: %v", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid trusted proxy: %v", entry)
+		}
+		proxies = append(proxies, ipNet)
+	}
+	return proxies, nil
+}
+
+func (tp trustedProxies) trusted(ip net.IP) bool {
+	for _, ipNet := range tp {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the IP of the client. If the request comes from a trusted proxy, the
+// X-Forwarded-For header is walked from the right, and the first untrusted hop is the client.
+func (tp trustedProxies) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !tp.trusted(ip) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header["X-Forwarded-For"], ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		hopIP := net.ParseIP(hop)
+		if hopIP == nil {
+			break // malformed header, fall back to the last valid hop
+		}
+		host = hop
+		if !tp.trusted(hopIP) {
+			break
+		}
+	}
+	return host
+}
+
+// middleware replaces the remote address of the requests from the trusted proxies with the
+// address of the client, so that the downstream handlers, e.g. the rate limiter, see the client.
+func (tp trustedProxies) middleware(handler http.Handler) http.Handler {
+	if len(tp) == 0 {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if client := tp.clientIP(r); client != "" {
+			r.RemoteAddr = net.JoinHostPort(client, "0")
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// ------------------------------ Latency ------------------------------
+
+// rpcLatency records the latency of each RPC method in a timer of the registry, e.g.
+// "latency/theta.GetBlock". The calls to unknown methods share the "latency/unknown" timer
+// so that the clients cannot create arbitrary metrics.
+type rpcLatency struct {
+	reg    metrics.Registry
+	schema *rpcSchema
+}
+
+func newRPCLatency(reg metrics.Registry, schema *rpcSchema) *rpcLatency {
+	return &rpcLatency{reg: reg, schema: schema}
+}
+
+func (l *rpcLatency) observe(method string, elapsed time.Duration) {
+	if _, ok := l.schema.methods[method]; !ok {
+		method = "unknown"
+	}
+	metrics.GetOrRegisterTimer("latency/"+method, l.reg).Update(elapsed)
+}
+
+func (l *rpcLatency) middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(jsonrpc2.WithCallObserver(r.Context(), l.observe)))
+	})
+}
+
+// ------------------------------ Request Body Limit ------------------------------
+
+// limitRequestBody rejects the requests whose body exceeds the given size.
+func limitRequestBody(handler http.Handler, maxBytes int64) http.Handler {
+	if maxBytes <= 0 {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// newHTTPMiddleware creates the middleware shared by all the routes of the RPC listeners from the config.
+func newHTTPMiddleware() (func(http.Handler) http.Handler, error) {
+	proxies, err := newTrustedProxies(viper.GetStringSlice(common.CfgRPCTrustedProxies))
+	if err != nil {
+		return nil, err
+	}
+	maxBytes := viper.GetInt64(common.CfgRPCMaxRequestBodyBytes)
+
+	return func(handler http.Handler) http.Handler {
+		return proxies.middleware(limitRequestBody(handler, maxBytes))
+	}, nil
+}
@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+func TestTrustedProxiesClientIP(t *testing.T) {
+	assert := assert.New(t)
+
+	proxies, err := newTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.Nil(t, err)
+
+	req := httptest.NewRequest("POST", "/rpc", nil)
+
+	// Requests not from a trusted proxy can't spoof the header
+	req.RemoteAddr = "1.2.3.4:5678"
+	req.Header.Set("X-Forwarded-For", "5.6.7.8")
+	assert.Equal("1.2.3.4", proxies.clientIP(req))
+
+	// The first untrusted hop from the right is the client
+	req.RemoteAddr = "192.168.1.1:5678"
+	req.Header.Set("X-Forwarded-For", "5.6.7.8, 9.9.9.9, 10.1.2.3")
+	assert.Equal("9.9.9.9", proxies.clientIP(req))
+
+	// The walk stops at a malformed hop
+	req.Header.Set("X-Forwarded-For", "garbage, 10.1.2.3")
+	assert.Equal("10.1.2.3", proxies.clientIP(req))
+
+	req.Header.Del("X-Forwarded-For")
+	assert.Equal("192.168.1.1", proxies.clientIP(req))
+
+	_, err = newTrustedProxies([]string{"not-an-ip"})
+	assert.NotNil(err)
+}
+
+func TestCORSPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	handler := newCORSPolicy([]string{"https://explorer.thetatoken.org/"}).middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("POST", "/rpc", nil)
+	req.Header.Set("Origin", "https://explorer.thetatoken.org")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal("https://explorer.thetatoken.org", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	req = httptest.NewRequest("OPTIONS", "/rpc", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(http.StatusForbidden, rec.Code)
+	assert.Equal("", rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestLimitRequestBody(t *testing.T) {
+	assert := assert.New(t)
+
+	handler := limitRequestBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), 16)
+
+	req := httptest.NewRequest("POST", "/rpc", strings.NewReader(strings.Repeat("x", 17)))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+
+	req = httptest.NewRequest("POST", "/rpc", strings.NewReader(strings.Repeat("x", 16)))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(http.StatusOK, rec.Code)
+}
+
+func TestRPCLatency(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(enabled bool) { metrics.Enabled = enabled }(metrics.Enabled)
+	metrics.Enabled = true
+
+	reg := metrics.NewRegistry()
+	latency := newRPCLatency(reg, newRPCSchema(map[string]interface{}{"test": &testSchemaService{}}))
+	latency.observe("test.Get", 10*time.Millisecond)
+	latency.observe("test.Get", 20*time.Millisecond)
+	latency.observe("test.NoSuchMethod", time.Millisecond)
+
+	timer := reg.Get("latency/test.Get").(metrics.Timer)
+	assert.Equal(int64(2), timer.Count())
+	assert.Equal(int64(20*time.Millisecond), timer.Max())
+	assert.Nil(reg.Get("latency/test.NoSuchMethod"))
+	assert.Equal(int64(1), reg.Get("latency/unknown").(metrics.Timer).Count())
+}
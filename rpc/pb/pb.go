@@ -0,0 +1,4 @@
+// Package pb contains the protobuf definitions of the gRPC API and the code generated from them.
+package pb
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. theta.proto
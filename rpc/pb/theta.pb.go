@@ -0,0 +1,1648 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: theta.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type GetVersionRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetVersionRequest) Reset()         { *m = GetVersionRequest{} }
+func (m *GetVersionRequest) String() string { return proto.CompactTextString(m) }
+func (*GetVersionRequest) ProtoMessage()    {}
+func (*GetVersionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{0}
+}
+
+func (m *GetVersionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetVersionRequest.Unmarshal(m, b)
+}
+func (m *GetVersionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetVersionRequest.Marshal(b, m, deterministic)
+}
+func (m *GetVersionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetVersionRequest.Merge(m, src)
+}
+func (m *GetVersionRequest) XXX_Size() int {
+	return xxx_messageInfo_GetVersionRequest.Size(m)
+}
+func (m *GetVersionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetVersionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetVersionRequest proto.InternalMessageInfo
+
+type GetVersionResponse struct {
+	Version              string   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	GitHash              string   `protobuf:"bytes,2,opt,name=git_hash,json=gitHash,proto3" json:"git_hash,omitempty"`
+	Timestamp            string   `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetVersionResponse) Reset()         { *m = GetVersionResponse{} }
+func (m *GetVersionResponse) String() string { return proto.CompactTextString(m) }
+func (*GetVersionResponse) ProtoMessage()    {}
+func (*GetVersionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{1}
+}
+
+func (m *GetVersionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetVersionResponse.Unmarshal(m, b)
+}
+func (m *GetVersionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetVersionResponse.Marshal(b, m, deterministic)
+}
+func (m *GetVersionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetVersionResponse.Merge(m, src)
+}
+func (m *GetVersionResponse) XXX_Size() int {
+	return xxx_messageInfo_GetVersionResponse.Size(m)
+}
+func (m *GetVersionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetVersionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetVersionResponse proto.InternalMessageInfo
+
+func (m *GetVersionResponse) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *GetVersionResponse) GetGitHash() string {
+	if m != nil {
+		return m.GitHash
+	}
+	return ""
+}
+
+func (m *GetVersionResponse) GetTimestamp() string {
+	if m != nil {
+		return m.Timestamp
+	}
+	return ""
+}
+
+type GetStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStatusRequest) Reset()         { *m = GetStatusRequest{} }
+func (m *GetStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*GetStatusRequest) ProtoMessage()    {}
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{2}
+}
+
+func (m *GetStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStatusRequest.Unmarshal(m, b)
+}
+func (m *GetStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *GetStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStatusRequest.Merge(m, src)
+}
+func (m *GetStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_GetStatusRequest.Size(m)
+}
+func (m *GetStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStatusRequest proto.InternalMessageInfo
+
+type GetStatusResponse struct {
+	Address                    string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	ChainId                    string   `protobuf:"bytes,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	PeerId                     string   `protobuf:"bytes,3,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	LatestFinalizedBlockHash   []byte   `protobuf:"bytes,4,opt,name=latest_finalized_block_hash,json=latestFinalizedBlockHash,proto3" json:"latest_finalized_block_hash,omitempty"`
+	LatestFinalizedBlockHeight uint64   `protobuf:"varint,5,opt,name=latest_finalized_block_height,json=latestFinalizedBlockHeight,proto3" json:"latest_finalized_block_height,omitempty"`
+	LatestFinalizedBlockTime   uint64   `protobuf:"varint,6,opt,name=latest_finalized_block_time,json=latestFinalizedBlockTime,proto3" json:"latest_finalized_block_time,omitempty"`
+	LatestFinalizedBlockEpoch  uint64   `protobuf:"varint,7,opt,name=latest_finalized_block_epoch,json=latestFinalizedBlockEpoch,proto3" json:"latest_finalized_block_epoch,omitempty"`
+	CurrentEpoch               uint64   `protobuf:"varint,8,opt,name=current_epoch,json=currentEpoch,proto3" json:"current_epoch,omitempty"`
+	CurrentHeight              uint64   `protobuf:"varint,9,opt,name=current_height,json=currentHeight,proto3" json:"current_height,omitempty"`
+	CurrentTime                uint64   `protobuf:"varint,10,opt,name=current_time,json=currentTime,proto3" json:"current_time,omitempty"`
+	Syncing                    bool     `protobuf:"varint,11,opt,name=syncing,proto3" json:"syncing,omitempty"`
+	GenesisBlockHash           []byte   `protobuf:"bytes,12,opt,name=genesis_block_hash,json=genesisBlockHash,proto3" json:"genesis_block_hash,omitempty"`
+	XXX_NoUnkeyedLiteral       struct{} `json:"-"`
+	XXX_unrecognized           []byte   `json:"-"`
+	XXX_sizecache              int32    `json:"-"`
+}
+
+func (m *GetStatusResponse) Reset()         { *m = GetStatusResponse{} }
+func (m *GetStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*GetStatusResponse) ProtoMessage()    {}
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{3}
+}
+
+func (m *GetStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStatusResponse.Unmarshal(m, b)
+}
+func (m *GetStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStatusResponse.Marshal(b, m, deterministic)
+}
+func (m *GetStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStatusResponse.Merge(m, src)
+}
+func (m *GetStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_GetStatusResponse.Size(m)
+}
+func (m *GetStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStatusResponse proto.InternalMessageInfo
+
+func (m *GetStatusResponse) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *GetStatusResponse) GetChainId() string {
+	if m != nil {
+		return m.ChainId
+	}
+	return ""
+}
+
+func (m *GetStatusResponse) GetPeerId() string {
+	if m != nil {
+		return m.PeerId
+	}
+	return ""
+}
+
+func (m *GetStatusResponse) GetLatestFinalizedBlockHash() []byte {
+	if m != nil {
+		return m.LatestFinalizedBlockHash
+	}
+	return nil
+}
+
+func (m *GetStatusResponse) GetLatestFinalizedBlockHeight() uint64 {
+	if m != nil {
+		return m.LatestFinalizedBlockHeight
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetLatestFinalizedBlockTime() uint64 {
+	if m != nil {
+		return m.LatestFinalizedBlockTime
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetLatestFinalizedBlockEpoch() uint64 {
+	if m != nil {
+		return m.LatestFinalizedBlockEpoch
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetCurrentEpoch() uint64 {
+	if m != nil {
+		return m.CurrentEpoch
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetCurrentHeight() uint64 {
+	if m != nil {
+		return m.CurrentHeight
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetCurrentTime() uint64 {
+	if m != nil {
+		return m.CurrentTime
+	}
+	return 0
+}
+
+func (m *GetStatusResponse) GetSyncing() bool {
+	if m != nil {
+		return m.Syncing
+	}
+	return false
+}
+
+func (m *GetStatusResponse) GetGenesisBlockHash() []byte {
+	if m != nil {
+		return m.GenesisBlockHash
+	}
+	return nil
+}
+
+// Amounts are decimal strings in wei.
+type Coins struct {
+	ThetaWei             string   `protobuf:"bytes,1,opt,name=theta_wei,json=thetaWei,proto3" json:"theta_wei,omitempty"`
+	TfuelWei             string   `protobuf:"bytes,2,opt,name=tfuel_wei,json=tfuelWei,proto3" json:"tfuel_wei,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Coins) Reset()         { *m = Coins{} }
+func (m *Coins) String() string { return proto.CompactTextString(m) }
+func (*Coins) ProtoMessage()    {}
+func (*Coins) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{4}
+}
+
+func (m *Coins) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Coins.Unmarshal(m, b)
+}
+func (m *Coins) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Coins.Marshal(b, m, deterministic)
+}
+func (m *Coins) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Coins.Merge(m, src)
+}
+func (m *Coins) XXX_Size() int {
+	return xxx_messageInfo_Coins.Size(m)
+}
+func (m *Coins) XXX_DiscardUnknown() {
+	xxx_messageInfo_Coins.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Coins proto.InternalMessageInfo
+
+func (m *Coins) GetThetaWei() string {
+	if m != nil {
+		return m.ThetaWei
+	}
+	return ""
+}
+
+func (m *Coins) GetTfuelWei() string {
+	if m != nil {
+		return m.TfuelWei
+	}
+	return ""
+}
+
+type GetAccountRequest struct {
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// 0 means the latest finalized block
+	Height uint64 `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	// Preview the account from the screened view of the mempool
+	Preview              bool     `protobuf:"varint,3,opt,name=preview,proto3" json:"preview,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetAccountRequest) Reset()         { *m = GetAccountRequest{} }
+func (m *GetAccountRequest) String() string { return proto.CompactTextString(m) }
+func (*GetAccountRequest) ProtoMessage()    {}
+func (*GetAccountRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{5}
+}
+
+func (m *GetAccountRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAccountRequest.Unmarshal(m, b)
+}
+func (m *GetAccountRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAccountRequest.Marshal(b, m, deterministic)
+}
+func (m *GetAccountRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAccountRequest.Merge(m, src)
+}
+func (m *GetAccountRequest) XXX_Size() int {
+	return xxx_messageInfo_GetAccountRequest.Size(m)
+}
+func (m *GetAccountRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAccountRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAccountRequest proto.InternalMessageInfo
+
+func (m *GetAccountRequest) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *GetAccountRequest) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *GetAccountRequest) GetPreview() bool {
+	if m != nil {
+		return m.Preview
+	}
+	return false
+}
+
+type Account struct {
+	Address                []byte   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Sequence               uint64   `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Balance                *Coins   `protobuf:"bytes,3,opt,name=balance,proto3" json:"balance,omitempty"`
+	LastUpdatedBlockHeight uint64   `protobuf:"varint,4,opt,name=last_updated_block_height,json=lastUpdatedBlockHeight,proto3" json:"last_updated_block_height,omitempty"`
+	Root                   []byte   `protobuf:"bytes,5,opt,name=root,proto3" json:"root,omitempty"`
+	CodeHash               []byte   `protobuf:"bytes,6,opt,name=code_hash,json=codeHash,proto3" json:"code_hash,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{} `json:"-"`
+	XXX_unrecognized       []byte   `json:"-"`
+	XXX_sizecache          int32    `json:"-"`
+}
+
+func (m *Account) Reset()         { *m = Account{} }
+func (m *Account) String() string { return proto.CompactTextString(m) }
+func (*Account) ProtoMessage()    {}
+func (*Account) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{6}
+}
+
+func (m *Account) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Account.Unmarshal(m, b)
+}
+func (m *Account) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Account.Marshal(b, m, deterministic)
+}
+func (m *Account) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Account.Merge(m, src)
+}
+func (m *Account) XXX_Size() int {
+	return xxx_messageInfo_Account.Size(m)
+}
+func (m *Account) XXX_DiscardUnknown() {
+	xxx_messageInfo_Account.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Account proto.InternalMessageInfo
+
+func (m *Account) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *Account) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Account) GetBalance() *Coins {
+	if m != nil {
+		return m.Balance
+	}
+	return nil
+}
+
+func (m *Account) GetLastUpdatedBlockHeight() uint64 {
+	if m != nil {
+		return m.LastUpdatedBlockHeight
+	}
+	return 0
+}
+
+func (m *Account) GetRoot() []byte {
+	if m != nil {
+		return m.Root
+	}
+	return nil
+}
+
+func (m *Account) GetCodeHash() []byte {
+	if m != nil {
+		return m.CodeHash
+	}
+	return nil
+}
+
+type GetBlockRequest struct {
+	Hash                 []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlockRequest) Reset()         { *m = GetBlockRequest{} }
+func (m *GetBlockRequest) String() string { return proto.CompactTextString(m) }
+func (*GetBlockRequest) ProtoMessage()    {}
+func (*GetBlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{7}
+}
+
+func (m *GetBlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlockRequest.Unmarshal(m, b)
+}
+func (m *GetBlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlockRequest.Marshal(b, m, deterministic)
+}
+func (m *GetBlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlockRequest.Merge(m, src)
+}
+func (m *GetBlockRequest) XXX_Size() int {
+	return xxx_messageInfo_GetBlockRequest.Size(m)
+}
+func (m *GetBlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlockRequest proto.InternalMessageInfo
+
+func (m *GetBlockRequest) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type GetBlockByHeightRequest struct {
+	Height               uint64   `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlockByHeightRequest) Reset()         { *m = GetBlockByHeightRequest{} }
+func (m *GetBlockByHeightRequest) String() string { return proto.CompactTextString(m) }
+func (*GetBlockByHeightRequest) ProtoMessage()    {}
+func (*GetBlockByHeightRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{8}
+}
+
+func (m *GetBlockByHeightRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlockByHeightRequest.Unmarshal(m, b)
+}
+func (m *GetBlockByHeightRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlockByHeightRequest.Marshal(b, m, deterministic)
+}
+func (m *GetBlockByHeightRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlockByHeightRequest.Merge(m, src)
+}
+func (m *GetBlockByHeightRequest) XXX_Size() int {
+	return xxx_messageInfo_GetBlockByHeightRequest.Size(m)
+}
+func (m *GetBlockByHeightRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlockByHeightRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlockByHeightRequest proto.InternalMessageInfo
+
+func (m *GetBlockByHeightRequest) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+type Transaction struct {
+	Hash []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Type uint32 `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
+	// Omitted in the streamed blocks unless the full transactions are requested
+	Raw                  []byte   `protobuf:"bytes,3,opt,name=raw,proto3" json:"raw,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Transaction) Reset()         { *m = Transaction{} }
+func (m *Transaction) String() string { return proto.CompactTextString(m) }
+func (*Transaction) ProtoMessage()    {}
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{9}
+}
+
+func (m *Transaction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Transaction.Unmarshal(m, b)
+}
+func (m *Transaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Transaction.Marshal(b, m, deterministic)
+}
+func (m *Transaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Transaction.Merge(m, src)
+}
+func (m *Transaction) XXX_Size() int {
+	return xxx_messageInfo_Transaction.Size(m)
+}
+func (m *Transaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_Transaction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Transaction proto.InternalMessageInfo
+
+func (m *Transaction) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *Transaction) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *Transaction) GetRaw() []byte {
+	if m != nil {
+		return m.Raw
+	}
+	return nil
+}
+
+type Block struct {
+	ChainId              string         `protobuf:"bytes,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Epoch                uint64         `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Height               uint64         `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Parent               []byte         `protobuf:"bytes,4,opt,name=parent,proto3" json:"parent,omitempty"`
+	TransactionsHash     []byte         `protobuf:"bytes,5,opt,name=transactions_hash,json=transactionsHash,proto3" json:"transactions_hash,omitempty"`
+	StateHash            []byte         `protobuf:"bytes,6,opt,name=state_hash,json=stateHash,proto3" json:"state_hash,omitempty"`
+	Timestamp            uint64         `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Proposer             []byte         `protobuf:"bytes,8,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	Status               uint32         `protobuf:"varint,9,opt,name=status,proto3" json:"status,omitempty"`
+	Hash                 []byte         `protobuf:"bytes,10,opt,name=hash,proto3" json:"hash,omitempty"`
+	Transactions         []*Transaction `protobuf:"bytes,11,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Block) Reset()         { *m = Block{} }
+func (m *Block) String() string { return proto.CompactTextString(m) }
+func (*Block) ProtoMessage()    {}
+func (*Block) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{10}
+}
+
+func (m *Block) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Block.Unmarshal(m, b)
+}
+func (m *Block) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Block.Marshal(b, m, deterministic)
+}
+func (m *Block) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Block.Merge(m, src)
+}
+func (m *Block) XXX_Size() int {
+	return xxx_messageInfo_Block.Size(m)
+}
+func (m *Block) XXX_DiscardUnknown() {
+	xxx_messageInfo_Block.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Block proto.InternalMessageInfo
+
+func (m *Block) GetChainId() string {
+	if m != nil {
+		return m.ChainId
+	}
+	return ""
+}
+
+func (m *Block) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *Block) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *Block) GetParent() []byte {
+	if m != nil {
+		return m.Parent
+	}
+	return nil
+}
+
+func (m *Block) GetTransactionsHash() []byte {
+	if m != nil {
+		return m.TransactionsHash
+	}
+	return nil
+}
+
+func (m *Block) GetStateHash() []byte {
+	if m != nil {
+		return m.StateHash
+	}
+	return nil
+}
+
+func (m *Block) GetTimestamp() uint64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Block) GetProposer() []byte {
+	if m != nil {
+		return m.Proposer
+	}
+	return nil
+}
+
+func (m *Block) GetStatus() uint32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *Block) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *Block) GetTransactions() []*Transaction {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+type GetTransactionRequest struct {
+	// Either the Theta or the Ethereum transaction hash
+	Hash                 []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTransactionRequest) Reset()         { *m = GetTransactionRequest{} }
+func (m *GetTransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTransactionRequest) ProtoMessage()    {}
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{11}
+}
+
+func (m *GetTransactionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTransactionRequest.Unmarshal(m, b)
+}
+func (m *GetTransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTransactionRequest.Marshal(b, m, deterministic)
+}
+func (m *GetTransactionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTransactionRequest.Merge(m, src)
+}
+func (m *GetTransactionRequest) XXX_Size() int {
+	return xxx_messageInfo_GetTransactionRequest.Size(m)
+}
+func (m *GetTransactionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTransactionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTransactionRequest proto.InternalMessageInfo
+
+func (m *GetTransactionRequest) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type Log struct {
+	Address              []byte   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Topics               [][]byte `protobuf:"bytes,2,rep,name=topics,proto3" json:"topics,omitempty"`
+	Data                 []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Log) Reset()         { *m = Log{} }
+func (m *Log) String() string { return proto.CompactTextString(m) }
+func (*Log) ProtoMessage()    {}
+func (*Log) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{12}
+}
+
+func (m *Log) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Log.Unmarshal(m, b)
+}
+func (m *Log) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Log.Marshal(b, m, deterministic)
+}
+func (m *Log) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Log.Merge(m, src)
+}
+func (m *Log) XXX_Size() int {
+	return xxx_messageInfo_Log.Size(m)
+}
+func (m *Log) XXX_DiscardUnknown() {
+	xxx_messageInfo_Log.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Log proto.InternalMessageInfo
+
+func (m *Log) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *Log) GetTopics() [][]byte {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+func (m *Log) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type TxReceipt struct {
+	EvmRet               []byte   `protobuf:"bytes,1,opt,name=evm_ret,json=evmRet,proto3" json:"evm_ret,omitempty"`
+	ContractAddress      []byte   `protobuf:"bytes,2,opt,name=contract_address,json=contractAddress,proto3" json:"contract_address,omitempty"`
+	GasUsed              uint64   `protobuf:"varint,3,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`
+	EvmErr               string   `protobuf:"bytes,4,opt,name=evm_err,json=evmErr,proto3" json:"evm_err,omitempty"`
+	Logs                 []*Log   `protobuf:"bytes,5,rep,name=logs,proto3" json:"logs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TxReceipt) Reset()         { *m = TxReceipt{} }
+func (m *TxReceipt) String() string { return proto.CompactTextString(m) }
+func (*TxReceipt) ProtoMessage()    {}
+func (*TxReceipt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{13}
+}
+
+func (m *TxReceipt) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxReceipt.Unmarshal(m, b)
+}
+func (m *TxReceipt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxReceipt.Marshal(b, m, deterministic)
+}
+func (m *TxReceipt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxReceipt.Merge(m, src)
+}
+func (m *TxReceipt) XXX_Size() int {
+	return xxx_messageInfo_TxReceipt.Size(m)
+}
+func (m *TxReceipt) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxReceipt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxReceipt proto.InternalMessageInfo
+
+func (m *TxReceipt) GetEvmRet() []byte {
+	if m != nil {
+		return m.EvmRet
+	}
+	return nil
+}
+
+func (m *TxReceipt) GetContractAddress() []byte {
+	if m != nil {
+		return m.ContractAddress
+	}
+	return nil
+}
+
+func (m *TxReceipt) GetGasUsed() uint64 {
+	if m != nil {
+		return m.GasUsed
+	}
+	return 0
+}
+
+func (m *TxReceipt) GetEvmErr() string {
+	if m != nil {
+		return m.EvmErr
+	}
+	return ""
+}
+
+func (m *TxReceipt) GetLogs() []*Log {
+	if m != nil {
+		return m.Logs
+	}
+	return nil
+}
+
+type GetTransactionResponse struct {
+	BlockHash   []byte `protobuf:"bytes,1,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	BlockHeight uint64 `protobuf:"varint,2,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	// Not_found, pending, finalized or abandoned
+	Status               string     `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Hash                 []byte     `protobuf:"bytes,4,opt,name=hash,proto3" json:"hash,omitempty"`
+	Type                 uint32     `protobuf:"varint,5,opt,name=type,proto3" json:"type,omitempty"`
+	Raw                  []byte     `protobuf:"bytes,6,opt,name=raw,proto3" json:"raw,omitempty"`
+	Receipt              *TxReceipt `protobuf:"bytes,7,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *GetTransactionResponse) Reset()         { *m = GetTransactionResponse{} }
+func (m *GetTransactionResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTransactionResponse) ProtoMessage()    {}
+func (*GetTransactionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{14}
+}
+
+func (m *GetTransactionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTransactionResponse.Unmarshal(m, b)
+}
+func (m *GetTransactionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTransactionResponse.Marshal(b, m, deterministic)
+}
+func (m *GetTransactionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTransactionResponse.Merge(m, src)
+}
+func (m *GetTransactionResponse) XXX_Size() int {
+	return xxx_messageInfo_GetTransactionResponse.Size(m)
+}
+func (m *GetTransactionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTransactionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTransactionResponse proto.InternalMessageInfo
+
+func (m *GetTransactionResponse) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *GetTransactionResponse) GetBlockHeight() uint64 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
+}
+
+func (m *GetTransactionResponse) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *GetTransactionResponse) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *GetTransactionResponse) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *GetTransactionResponse) GetRaw() []byte {
+	if m != nil {
+		return m.Raw
+	}
+	return nil
+}
+
+func (m *GetTransactionResponse) GetReceipt() *TxReceipt {
+	if m != nil {
+		return m.Receipt
+	}
+	return nil
+}
+
+type BroadcastRawTransactionRequest struct {
+	TxBytes              []byte   `protobuf:"bytes,1,opt,name=tx_bytes,json=txBytes,proto3" json:"tx_bytes,omitempty"`
+	Priority             bool     `protobuf:"varint,2,opt,name=priority,proto3" json:"priority,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BroadcastRawTransactionRequest) Reset()         { *m = BroadcastRawTransactionRequest{} }
+func (m *BroadcastRawTransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*BroadcastRawTransactionRequest) ProtoMessage()    {}
+func (*BroadcastRawTransactionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{15}
+}
+
+func (m *BroadcastRawTransactionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BroadcastRawTransactionRequest.Unmarshal(m, b)
+}
+func (m *BroadcastRawTransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BroadcastRawTransactionRequest.Marshal(b, m, deterministic)
+}
+func (m *BroadcastRawTransactionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BroadcastRawTransactionRequest.Merge(m, src)
+}
+func (m *BroadcastRawTransactionRequest) XXX_Size() int {
+	return xxx_messageInfo_BroadcastRawTransactionRequest.Size(m)
+}
+func (m *BroadcastRawTransactionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BroadcastRawTransactionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BroadcastRawTransactionRequest proto.InternalMessageInfo
+
+func (m *BroadcastRawTransactionRequest) GetTxBytes() []byte {
+	if m != nil {
+		return m.TxBytes
+	}
+	return nil
+}
+
+func (m *BroadcastRawTransactionRequest) GetPriority() bool {
+	if m != nil {
+		return m.Priority
+	}
+	return false
+}
+
+type BroadcastRawTransactionResponse struct {
+	Hash []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Height of the block that includes the transaction
+	BlockHeight          uint64   `protobuf:"varint,2,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	BlockHash            []byte   `protobuf:"bytes,3,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BroadcastRawTransactionResponse) Reset()         { *m = BroadcastRawTransactionResponse{} }
+func (m *BroadcastRawTransactionResponse) String() string { return proto.CompactTextString(m) }
+func (*BroadcastRawTransactionResponse) ProtoMessage()    {}
+func (*BroadcastRawTransactionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{16}
+}
+
+func (m *BroadcastRawTransactionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BroadcastRawTransactionResponse.Unmarshal(m, b)
+}
+func (m *BroadcastRawTransactionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BroadcastRawTransactionResponse.Marshal(b, m, deterministic)
+}
+func (m *BroadcastRawTransactionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BroadcastRawTransactionResponse.Merge(m, src)
+}
+func (m *BroadcastRawTransactionResponse) XXX_Size() int {
+	return xxx_messageInfo_BroadcastRawTransactionResponse.Size(m)
+}
+func (m *BroadcastRawTransactionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BroadcastRawTransactionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BroadcastRawTransactionResponse proto.InternalMessageInfo
+
+func (m *BroadcastRawTransactionResponse) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *BroadcastRawTransactionResponse) GetBlockHeight() uint64 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
+}
+
+func (m *BroadcastRawTransactionResponse) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+type BroadcastRawTransactionAsyncResponse struct {
+	Hash                 []byte   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BroadcastRawTransactionAsyncResponse) Reset()         { *m = BroadcastRawTransactionAsyncResponse{} }
+func (m *BroadcastRawTransactionAsyncResponse) String() string { return proto.CompactTextString(m) }
+func (*BroadcastRawTransactionAsyncResponse) ProtoMessage()    {}
+func (*BroadcastRawTransactionAsyncResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{17}
+}
+
+func (m *BroadcastRawTransactionAsyncResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BroadcastRawTransactionAsyncResponse.Unmarshal(m, b)
+}
+func (m *BroadcastRawTransactionAsyncResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BroadcastRawTransactionAsyncResponse.Marshal(b, m, deterministic)
+}
+func (m *BroadcastRawTransactionAsyncResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BroadcastRawTransactionAsyncResponse.Merge(m, src)
+}
+func (m *BroadcastRawTransactionAsyncResponse) XXX_Size() int {
+	return xxx_messageInfo_BroadcastRawTransactionAsyncResponse.Size(m)
+}
+func (m *BroadcastRawTransactionAsyncResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BroadcastRawTransactionAsyncResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BroadcastRawTransactionAsyncResponse proto.InternalMessageInfo
+
+func (m *BroadcastRawTransactionAsyncResponse) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type SubscribeBlocksRequest struct {
+	// Include the raw transactions in the pushed blocks
+	FullTransactions     bool     `protobuf:"varint,1,opt,name=full_transactions,json=fullTransactions,proto3" json:"full_transactions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscribeBlocksRequest) Reset()         { *m = SubscribeBlocksRequest{} }
+func (m *SubscribeBlocksRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeBlocksRequest) ProtoMessage()    {}
+func (*SubscribeBlocksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_03b7425af283f443, []int{18}
+}
+
+func (m *SubscribeBlocksRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeBlocksRequest.Unmarshal(m, b)
+}
+func (m *SubscribeBlocksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeBlocksRequest.Marshal(b, m, deterministic)
+}
+func (m *SubscribeBlocksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeBlocksRequest.Merge(m, src)
+}
+func (m *SubscribeBlocksRequest) XXX_Size() int {
+	return xxx_messageInfo_SubscribeBlocksRequest.Size(m)
+}
+func (m *SubscribeBlocksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeBlocksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeBlocksRequest proto.InternalMessageInfo
+
+func (m *SubscribeBlocksRequest) GetFullTransactions() bool {
+	if m != nil {
+		return m.FullTransactions
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*GetVersionRequest)(nil), "theta.GetVersionRequest")
+	proto.RegisterType((*GetVersionResponse)(nil), "theta.GetVersionResponse")
+	proto.RegisterType((*GetStatusRequest)(nil), "theta.GetStatusRequest")
+	proto.RegisterType((*GetStatusResponse)(nil), "theta.GetStatusResponse")
+	proto.RegisterType((*Coins)(nil), "theta.Coins")
+	proto.RegisterType((*GetAccountRequest)(nil), "theta.GetAccountRequest")
+	proto.RegisterType((*Account)(nil), "theta.Account")
+	proto.RegisterType((*GetBlockRequest)(nil), "theta.GetBlockRequest")
+	proto.RegisterType((*GetBlockByHeightRequest)(nil), "theta.GetBlockByHeightRequest")
+	proto.RegisterType((*Transaction)(nil), "theta.Transaction")
+	proto.RegisterType((*Block)(nil), "theta.Block")
+	proto.RegisterType((*GetTransactionRequest)(nil), "theta.GetTransactionRequest")
+	proto.RegisterType((*Log)(nil), "theta.Log")
+	proto.RegisterType((*TxReceipt)(nil), "theta.TxReceipt")
+	proto.RegisterType((*GetTransactionResponse)(nil), "theta.GetTransactionResponse")
+	proto.RegisterType((*BroadcastRawTransactionRequest)(nil), "theta.BroadcastRawTransactionRequest")
+	proto.RegisterType((*BroadcastRawTransactionResponse)(nil), "theta.BroadcastRawTransactionResponse")
+	proto.RegisterType((*BroadcastRawTransactionAsyncResponse)(nil), "theta.BroadcastRawTransactionAsyncResponse")
+	proto.RegisterType((*SubscribeBlocksRequest)(nil), "theta.SubscribeBlocksRequest")
+}
+
+func init() { proto.RegisterFile("theta.proto", fileDescriptor_03b7425af283f443) }
+
+var fileDescriptor_03b7425af283f443 = []byte{
+	// 1182 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x8d, 0x57, 0x5b, 0x73, 0xe3, 0x34,
+	0x14, 0x9e, 0xdc, 0x93, 0x93, 0xb4, 0xcd, 0x0a, 0x48, 0xdd, 0x6c, 0x5b, 0xc0, 0xd0, 0xe5, 0x52,
+	0xa6, 0x5d, 0xca, 0xcc, 0xce, 0x00, 0x03, 0x6c, 0xcb, 0x94, 0x85, 0x01, 0x5e, 0xbc, 0x5d, 0x76,
+	0x86, 0x17, 0x8f, 0xed, 0xa8, 0x89, 0x67, 0x53, 0xdb, 0x48, 0x4a, 0x2f, 0xfc, 0x14, 0x5e, 0x19,
+	0xfe, 0x10, 0xbc, 0xf0, 0x73, 0x90, 0x8e, 0xa4, 0xd8, 0x4e, 0x93, 0xb0, 0x6f, 0x3e, 0x17, 0x9d,
+	0x73, 0xf4, 0x9d, 0x4f, 0x47, 0x32, 0x74, 0xc5, 0x84, 0x8a, 0xe0, 0x28, 0x63, 0xa9, 0x48, 0x49,
+	0x03, 0x05, 0xf7, 0x0d, 0x78, 0xf0, 0x8c, 0x8a, 0x5f, 0x28, 0xe3, 0x71, 0x9a, 0x78, 0xf4, 0xb7,
+	0x19, 0xe5, 0xc2, 0x1d, 0x03, 0x29, 0x2a, 0x79, 0x96, 0x26, 0x9c, 0x12, 0x07, 0x5a, 0xd7, 0x5a,
+	0xe5, 0x54, 0xde, 0xa9, 0x7c, 0xd8, 0xf1, 0xac, 0x48, 0x76, 0xa0, 0x3d, 0x8e, 0x85, 0x3f, 0x09,
+	0xf8, 0xc4, 0xa9, 0x6a, 0x93, 0x94, 0xbf, 0x97, 0x22, 0xd9, 0x85, 0x8e, 0x88, 0xaf, 0x64, 0xd0,
+	0xe0, 0x2a, 0x73, 0x6a, 0x68, 0xcb, 0x15, 0x2e, 0x81, 0xbe, 0x4c, 0xf4, 0x5c, 0x04, 0x62, 0xc6,
+	0x6d, 0xf2, 0x3f, 0xea, 0x58, 0x92, 0x55, 0xe6, 0xc9, 0x83, 0xd1, 0x88, 0x51, 0xce, 0x6d, 0x72,
+	0x23, 0xaa, 0xe4, 0xd1, 0x24, 0x88, 0x13, 0x3f, 0x1e, 0xd9, 0xe4, 0x28, 0xff, 0x30, 0x22, 0xdb,
+	0xd0, 0xca, 0x28, 0x65, 0xca, 0xa2, 0x53, 0x37, 0x95, 0x28, 0x0d, 0x5f, 0xc1, 0xc3, 0x69, 0x20,
+	0x64, 0x36, 0xff, 0x32, 0x4e, 0x82, 0x69, 0xfc, 0x3b, 0x1d, 0xf9, 0xe1, 0x34, 0x8d, 0x5e, 0xe9,
+	0x3d, 0xd4, 0xa5, 0x73, 0xcf, 0x73, 0xb4, 0xcb, 0x77, 0xd6, 0xe3, 0x4c, 0x39, 0xe0, 0xa6, 0x4e,
+	0x61, 0x6f, 0xd5, 0x72, 0x1a, 0x8f, 0x27, 0xc2, 0x69, 0xc8, 0x00, 0x75, 0x6f, 0xb8, 0x34, 0x00,
+	0x7a, 0xac, 0xa9, 0x40, 0xa1, 0xe3, 0x34, 0x31, 0xc0, 0xd2, 0x0a, 0x2e, 0xa4, 0x9d, 0x7c, 0x03,
+	0xbb, 0x2b, 0x96, 0xd3, 0x2c, 0x8d, 0x26, 0x4e, 0x0b, 0xd7, 0xef, 0x2c, 0x5b, 0x7f, 0xae, 0x1c,
+	0xc8, 0x7b, 0xb0, 0x11, 0xcd, 0x18, 0xa3, 0x89, 0x30, 0x2b, 0xda, 0xb8, 0xa2, 0x67, 0x94, 0xda,
+	0xe9, 0x00, 0x36, 0xad, 0x93, 0xd9, 0x58, 0x07, 0xbd, 0xec, 0x52, 0xb3, 0x97, 0x77, 0xc1, 0x2e,
+	0xd3, 0xc5, 0x03, 0x3a, 0x75, 0x8d, 0x0e, 0xeb, 0x95, 0xed, 0xe3, 0x77, 0x49, 0x14, 0x27, 0x63,
+	0xa7, 0x2b, 0xad, 0x6d, 0xcf, 0x8a, 0xe4, 0x13, 0x20, 0x63, 0x9a, 0x50, 0x1e, 0xf3, 0x62, 0x07,
+	0x7a, 0xd8, 0x81, 0xbe, 0xb1, 0xcc, 0x91, 0x77, 0x4f, 0xa1, 0xf1, 0x6d, 0x1a, 0x27, 0x9c, 0x3c,
+	0x94, 0xbc, 0x52, 0x04, 0xf6, 0x6f, 0x68, 0x6c, 0x18, 0xd1, 0x46, 0xc5, 0x4b, 0x1a, 0xa3, 0xf1,
+	0x72, 0x46, 0xa7, 0x68, 0xac, 0x1a, 0xa3, 0x52, 0x48, 0xa3, 0xeb, 0x23, 0xbd, 0x4e, 0xa3, 0x28,
+	0x9d, 0x25, 0xc2, 0x90, 0x6e, 0x91, 0x5e, 0xbd, 0x9c, 0x5e, 0x03, 0x68, 0x9a, 0xbd, 0x57, 0x71,
+	0x5b, 0x46, 0x52, 0x2b, 0x32, 0x46, 0xaf, 0x63, 0x7a, 0x83, 0xdc, 0x92, 0x3b, 0x32, 0xa2, 0xfb,
+	0x4f, 0x05, 0x5a, 0x26, 0xfc, 0x9a, 0xb8, 0x43, 0x68, 0x73, 0x95, 0x3c, 0x89, 0xa8, 0x89, 0x3c,
+	0x97, 0xc9, 0x23, 0x68, 0x85, 0xc1, 0x34, 0x50, 0x26, 0x15, 0xbb, 0x7b, 0xd2, 0x3b, 0xd2, 0x47,
+	0x17, 0xf7, 0xee, 0x59, 0x23, 0xf9, 0x1c, 0x64, 0x87, 0x25, 0x07, 0x66, 0xd9, 0x48, 0x36, 0x7a,
+	0x81, 0x83, 0x75, 0x0c, 0x3a, 0x50, 0x0e, 0x2f, 0xb4, 0xbd, 0xc8, 0x3f, 0x02, 0x75, 0x96, 0xa6,
+	0x9a, 0xa9, 0x3d, 0x0f, 0xbf, 0x15, 0x6c, 0x51, 0x3a, 0xa2, 0xba, 0x03, 0x4d, 0x34, 0xb4, 0x95,
+	0x02, 0x91, 0x3f, 0x80, 0x2d, 0x09, 0x1b, 0x86, 0xb0, 0xa0, 0xc9, 0x18, 0xe8, 0xaa, 0x77, 0x86,
+	0xdf, 0xee, 0xa7, 0xb0, 0x6d, 0xdd, 0xce, 0xee, 0x74, 0x2e, 0xeb, 0x9e, 0x23, 0x59, 0x29, 0x22,
+	0xe9, 0x3e, 0x83, 0xee, 0x05, 0x0b, 0x12, 0x1e, 0x44, 0x42, 0x0d, 0x93, 0x25, 0x51, 0x95, 0x4e,
+	0xdc, 0x65, 0x1a, 0xa8, 0x0d, 0x0f, 0xbf, 0x49, 0x1f, 0x6a, 0x2c, 0xd0, 0xe0, 0xf7, 0x3c, 0xf5,
+	0xe9, 0xfe, 0x5d, 0x85, 0x06, 0x66, 0x2e, 0xcd, 0x84, 0x4a, 0x79, 0x26, 0xbc, 0x09, 0x0d, 0x4d,
+	0x78, 0x0d, 0xba, 0x16, 0x0a, 0xb5, 0xd5, 0x4a, 0x5d, 0x96, 0xfa, 0x2c, 0x50, 0x2c, 0x36, 0x33,
+	0xc1, 0x48, 0xe4, 0x10, 0x1e, 0x88, 0xbc, 0x66, 0xae, 0x21, 0xd3, 0x58, 0xf6, 0x8b, 0x06, 0x1c,
+	0x17, 0x7b, 0x00, 0x72, 0xdc, 0x89, 0x12, 0xb0, 0x1d, 0xd4, 0xdc, 0x1f, 0x91, 0xfa, 0xe0, 0xe6,
+	0x0a, 0xc5, 0x13, 0x39, 0xb0, 0xb3, 0x94, 0x53, 0x86, 0x67, 0x54, 0xf6, 0xc4, 0xca, 0xaa, 0x3a,
+	0x8e, 0x63, 0x12, 0xcf, 0xe5, 0x86, 0x67, 0xa4, 0x39, 0x84, 0x50, 0x80, 0xf0, 0x09, 0xf4, 0x8a,
+	0x85, 0xc9, 0x63, 0x58, 0x93, 0xc4, 0x22, 0x86, 0x58, 0x85, 0x06, 0x78, 0x25, 0x3f, 0xf7, 0x10,
+	0xde, 0x92, 0x0d, 0x2d, 0xda, 0xd7, 0x74, 0xff, 0x47, 0xa8, 0xfd, 0x94, 0x8e, 0xd7, 0x9f, 0x26,
+	0x91, 0x66, 0x71, 0xc4, 0x25, 0xfc, 0x35, 0x85, 0xa7, 0x96, 0x54, 0x30, 0x49, 0xd1, 0xc0, 0x74,
+	0x13, 0xbf, 0xdd, 0x3f, 0x2b, 0xd0, 0xb9, 0xb8, 0xf5, 0x68, 0x44, 0xe3, 0x4c, 0xa8, 0x59, 0x4e,
+	0xaf, 0xaf, 0x7c, 0x46, 0x85, 0x89, 0xd9, 0x94, 0xa2, 0x47, 0x05, 0xf9, 0x08, 0xfa, 0x51, 0x9a,
+	0xc8, 0x9a, 0x23, 0xe1, 0xdb, 0xac, 0x55, 0xf4, 0xd8, 0xb2, 0xfa, 0xd3, 0xfc, 0xaa, 0x18, 0x07,
+	0xdc, 0x9f, 0x71, 0x3a, 0x32, 0x7d, 0x6e, 0x49, 0xf9, 0x85, 0x14, 0x6d, 0x78, 0xca, 0x18, 0x76,
+	0xba, 0x83, 0xe1, 0xcf, 0x19, 0x23, 0xfb, 0x50, 0x9f, 0xa6, 0x63, 0x2e, 0x9b, 0xab, 0xf0, 0x02,
+	0x83, 0x97, 0xdc, 0xa5, 0x87, 0x7a, 0xf7, 0xdf, 0x0a, 0x0c, 0x16, 0x01, 0x32, 0x77, 0x96, 0xec,
+	0x7b, 0x61, 0xa4, 0xe9, 0xaa, 0x3b, 0xe1, 0xfc, 0x16, 0x91, 0x63, 0xb3, 0x74, 0x60, 0x35, 0x21,
+	0xbb, 0x61, 0xe1, 0x94, 0xe6, 0x0d, 0x36, 0xf7, 0xd7, 0x42, 0x83, 0xeb, 0x4b, 0xce, 0x48, 0xe3,
+	0xfe, 0x19, 0x69, 0xce, 0xcf, 0x08, 0xf9, 0x18, 0x5a, 0x4c, 0x23, 0x8a, 0x54, 0xeb, 0x9e, 0xf4,
+	0x2d, 0x03, 0x2c, 0xd2, 0x9e, 0x75, 0x70, 0x5f, 0xc2, 0xfe, 0x19, 0x4b, 0x83, 0x51, 0x24, 0x47,
+	0x88, 0x17, 0xdc, 0x2c, 0xe1, 0x80, 0x04, 0x54, 0xdc, 0xfa, 0xe1, 0x9d, 0xbc, 0x65, 0x6c, 0xa7,
+	0xc5, 0xed, 0x99, 0x12, 0x35, 0x6f, 0xe3, 0x94, 0xc5, 0xe2, 0x0e, 0x77, 0xd6, 0xf6, 0xe6, 0xb2,
+	0x7b, 0x03, 0x6f, 0xaf, 0x0c, 0x6c, 0xb0, 0x5b, 0x36, 0x05, 0x5e, 0x03, 0xb0, 0x32, 0xe4, 0xb5,
+	0x05, 0xc8, 0xdd, 0x2f, 0xe0, 0xfd, 0x15, 0x89, 0x4f, 0xd5, 0x7d, 0xb4, 0x2e, 0xbb, 0x7b, 0x0e,
+	0x83, 0xe7, 0xb3, 0x90, 0x47, 0x2c, 0x0e, 0x29, 0x4e, 0x19, 0xfb, 0x62, 0x51, 0xc3, 0xe0, 0x72,
+	0x36, 0x9d, 0xfa, 0xa5, 0xf3, 0x55, 0xc1, 0x3d, 0xf7, 0x95, 0xa1, 0x90, 0x86, 0x9f, 0xfc, 0xd5,
+	0x80, 0xc6, 0x85, 0x42, 0x5c, 0xbe, 0x22, 0x20, 0x7f, 0x65, 0x11, 0xc7, 0xf4, 0xe1, 0xde, 0x6b,
+	0x6c, 0xb8, 0xb3, 0xc4, 0x62, 0xea, 0xfc, 0x1a, 0x3a, 0xf3, 0xa7, 0x12, 0xd9, 0xce, 0xfd, 0x4a,
+	0x2f, 0xaa, 0xa1, 0x73, 0xdf, 0x60, 0xd6, 0x3f, 0xc1, 0x12, 0xe6, 0x97, 0x55, 0xee, 0x57, 0xbe,
+	0x1e, 0x87, 0x9b, 0xc6, 0x62, 0x3d, 0x1f, 0x43, 0xdb, 0x4e, 0x79, 0x32, 0xc8, 0x57, 0x15, 0x6f,
+	0x87, 0xa1, 0xbd, 0xb3, 0xb4, 0xd7, 0x53, 0x7c, 0xe9, 0x95, 0xee, 0x05, 0xb2, 0xbf, 0xb0, 0x72,
+	0xe1, 0xc2, 0x58, 0x88, 0xf0, 0x33, 0x6c, 0x96, 0xcf, 0x19, 0xd9, 0xcd, 0xd7, 0xdf, 0xe7, 0xe6,
+	0x70, 0x6f, 0x85, 0xd5, 0x6c, 0x7d, 0x02, 0xdb, 0x2b, 0xa8, 0x40, 0x0e, 0x6c, 0xde, 0xb5, 0xe4,
+	0x1f, 0x3e, 0xfa, 0x3f, 0x37, 0x93, 0x89, 0xc1, 0xee, 0x3a, 0xd2, 0xbd, 0x6e, 0xba, 0xc3, 0xf5,
+	0x6e, 0x65, 0x02, 0x3f, 0x85, 0xad, 0x05, 0xb2, 0x12, 0x8b, 0xc7, 0x72, 0x12, 0x97, 0xc1, 0x7e,
+	0x5c, 0x39, 0xfb, 0xe0, 0xd7, 0x03, 0xf9, 0x86, 0x9f, 0xcc, 0xc2, 0xa3, 0x28, 0xbd, 0x3a, 0x46,
+	0x9b, 0x48, 0x5f, 0xd1, 0x44, 0x7f, 0x1e, 0xb3, 0x2c, 0x3a, 0xce, 0xc2, 0x2f, 0xb3, 0x30, 0x6c,
+	0xe2, 0xff, 0xc4, 0x67, 0xff, 0x01, 0x9d, 0xaf, 0xd1, 0xc1, 0x5e, 0x0c, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ThetaClient is the client API for Theta service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ThetaClient interface {
+	GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error)
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+	GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*Account, error)
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error)
+	// GetBlockByHeight returns the finalized block at the given height.
+	GetBlockByHeight(ctx context.Context, in *GetBlockByHeightRequest, opts ...grpc.CallOption) (*Block, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
+	// BroadcastRawTransaction returns after the transaction is included in a finalized block.
+	BroadcastRawTransaction(ctx context.Context, in *BroadcastRawTransactionRequest, opts ...grpc.CallOption) (*BroadcastRawTransactionResponse, error)
+	// BroadcastRawTransactionAsync returns once the transaction is accepted by the mempool.
+	BroadcastRawTransactionAsync(ctx context.Context, in *BroadcastRawTransactionRequest, opts ...grpc.CallOption) (*BroadcastRawTransactionAsyncResponse, error)
+	// SubscribeBlocks streams the newly finalized blocks.
+	SubscribeBlocks(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (Theta_SubscribeBlocksClient, error)
+}
+
+type thetaClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewThetaClient(cc *grpc.ClientConn) ThetaClient {
+	return &thetaClient{cc}
+}
+
+func (c *thetaClient) GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error) {
+	out := new(GetVersionResponse)
+	err := c.cc.Invoke(ctx, "/theta.Theta/GetVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thetaClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, "/theta.Theta/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thetaClient) GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*Account, error) {
+	out := new(Account)
+	err := c.cc.Invoke(ctx, "/theta.Theta/GetAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thetaClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, "/theta.Theta/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thetaClient) GetBlockByHeight(ctx context.Context, in *GetBlockByHeightRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, "/theta.Theta/GetBlockByHeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thetaClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error) {
+	out := new(GetTransactionResponse)
+	err := c.cc.Invoke(ctx, "/theta.Theta/GetTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thetaClient) BroadcastRawTransaction(ctx context.Context, in *BroadcastRawTransactionRequest, opts ...grpc.CallOption) (*BroadcastRawTransactionResponse, error) {
+	out := new(BroadcastRawTransactionResponse)
+	err := c.cc.Invoke(ctx, "/theta.Theta/BroadcastRawTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thetaClient) BroadcastRawTransactionAsync(ctx context.Context, in *BroadcastRawTransactionRequest, opts ...grpc.CallOption) (*BroadcastRawTransactionAsyncResponse, error) {
+	out := new(BroadcastRawTransactionAsyncResponse)
+	err := c.cc.Invoke(ctx, "/theta.Theta/BroadcastRawTransactionAsync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thetaClient) SubscribeBlocks(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (Theta_SubscribeBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Theta_serviceDesc.Streams[0], "/theta.Theta/SubscribeBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &thetaSubscribeBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Theta_SubscribeBlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type thetaSubscribeBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *thetaSubscribeBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ThetaServer is the server API for Theta service.
+type ThetaServer interface {
+	GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error)
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+	GetAccount(context.Context, *GetAccountRequest) (*Account, error)
+	GetBlock(context.Context, *GetBlockRequest) (*Block, error)
+	// GetBlockByHeight returns the finalized block at the given height.
+	GetBlockByHeight(context.Context, *GetBlockByHeightRequest) (*Block, error)
+	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
+	// BroadcastRawTransaction returns after the transaction is included in a finalized block.
+	BroadcastRawTransaction(context.Context, *BroadcastRawTransactionRequest) (*BroadcastRawTransactionResponse, error)
+	// BroadcastRawTransactionAsync returns once the transaction is accepted by the mempool.
+	BroadcastRawTransactionAsync(context.Context, *BroadcastRawTransactionRequest) (*BroadcastRawTransactionAsyncResponse, error)
+	// SubscribeBlocks streams the newly finalized blocks.
+	SubscribeBlocks(*SubscribeBlocksRequest, Theta_SubscribeBlocksServer) error
+}
+
+// UnimplementedThetaServer can be embedded to have forward compatible implementations.
+type UnimplementedThetaServer struct {
+}
+
+func (*UnimplementedThetaServer) GetVersion(ctx context.Context, req *GetVersionRequest) (*GetVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
+func (*UnimplementedThetaServer) GetStatus(ctx context.Context, req *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (*UnimplementedThetaServer) GetAccount(ctx context.Context, req *GetAccountRequest) (*Account, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccount not implemented")
+}
+func (*UnimplementedThetaServer) GetBlock(ctx context.Context, req *GetBlockRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (*UnimplementedThetaServer) GetBlockByHeight(ctx context.Context, req *GetBlockByHeightRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockByHeight not implemented")
+}
+func (*UnimplementedThetaServer) GetTransaction(ctx context.Context, req *GetTransactionRequest) (*GetTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (*UnimplementedThetaServer) BroadcastRawTransaction(ctx context.Context, req *BroadcastRawTransactionRequest) (*BroadcastRawTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastRawTransaction not implemented")
+}
+func (*UnimplementedThetaServer) BroadcastRawTransactionAsync(ctx context.Context, req *BroadcastRawTransactionRequest) (*BroadcastRawTransactionAsyncResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastRawTransactionAsync not implemented")
+}
+func (*UnimplementedThetaServer) SubscribeBlocks(req *SubscribeBlocksRequest, srv Theta_SubscribeBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeBlocks not implemented")
+}
+
+func RegisterThetaServer(s *grpc.Server, srv ThetaServer) {
+	s.RegisterService(&_Theta_serviceDesc, srv)
+}
+
+func _Theta_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThetaServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/theta.Theta/GetVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThetaServer).GetVersion(ctx, req.(*GetVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Theta_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThetaServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/theta.Theta/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThetaServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Theta_GetAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThetaServer).GetAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/theta.Theta/GetAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThetaServer).GetAccount(ctx, req.(*GetAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Theta_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThetaServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/theta.Theta/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThetaServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Theta_GetBlockByHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockByHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThetaServer).GetBlockByHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/theta.Theta/GetBlockByHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThetaServer).GetBlockByHeight(ctx, req.(*GetBlockByHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Theta_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThetaServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/theta.Theta/GetTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThetaServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Theta_BroadcastRawTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastRawTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThetaServer).BroadcastRawTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/theta.Theta/BroadcastRawTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThetaServer).BroadcastRawTransaction(ctx, req.(*BroadcastRawTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Theta_BroadcastRawTransactionAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastRawTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThetaServer).BroadcastRawTransactionAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/theta.Theta/BroadcastRawTransactionAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThetaServer).BroadcastRawTransactionAsync(ctx, req.(*BroadcastRawTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Theta_SubscribeBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ThetaServer).SubscribeBlocks(m, &thetaSubscribeBlocksServer{stream})
+}
+
+type Theta_SubscribeBlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type thetaSubscribeBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *thetaSubscribeBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Theta_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "theta.Theta",
+	HandlerType: (*ThetaServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetVersion",
+			Handler:    _Theta_GetVersion_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Theta_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetAccount",
+			Handler:    _Theta_GetAccount_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Theta_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetBlockByHeight",
+			Handler:    _Theta_GetBlockByHeight_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _Theta_GetTransaction_Handler,
+		},
+		{
+			MethodName: "BroadcastRawTransaction",
+			Handler:    _Theta_BroadcastRawTransaction_Handler,
+		},
+		{
+			MethodName: "BroadcastRawTransactionAsync",
+			Handler:    _Theta_BroadcastRawTransactionAsync_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeBlocks",
+			Handler:       _Theta_SubscribeBlocks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "theta.proto",
+}
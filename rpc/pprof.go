@@ -0,0 +1,186 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+)
+
+//
+// The runtime profiles are served at /debug/pprof/ by the admin listener when rpc.pprofEnabled is
+// set, so that the performance of the production nodes can be diagnosed without custom builds.
+// With authentication enabled, the callers must be allowed to call admin.Pprof. The requests are
+// rate limited like the RPC requests, and the profiles collected over a duration, i.e. the CPU
+// profiles and the traces, are collected one at a time for at most rpc.pprofMaxDurationSecs.
+//
+
+var errProfilerBusy = errors.New("another profile is being collected")
+
+// pprofMethod is the method the callers must be allowed to call to access the pprof endpoints
+const pprofMethod = "admin.Pprof"
+
+// The sampling rates of the block and mutex profiles captured by admin.CaptureProfile, when the
+// config does not enable the sampling
+const (
+	captureBlockProfileRate     = 10000 // one sample per 10us spent blocked
+	captureMutexProfileFraction = 5     // one in 5 contention events
+)
+
+// profileTypes are the profiles captured by admin.CaptureProfile, besides "cpu"
+var profileTypes = map[string]bool{
+	"heap":         true,
+	"allocs":       true,
+	"goroutine":    true,
+	"block":        true,
+	"mutex":        true,
+	"threadcreate": true,
+}
+
+// profiler serializes the profiles collected over a duration, which are costly and whose sampling
+// rates are process wide.
+type profiler struct {
+	busy        chan struct{}
+	maxDuration time.Duration
+
+	blockProfileRate     int
+	mutexProfileFraction int
+}
+
+func newProfiler() *profiler {
+	p := &profiler{
+		busy:        make(chan struct{}, 1),
+		maxDuration: time.Duration(viper.GetInt(common.CfgRPCPprofMaxDurationSecs)) * time.Second,
+	}
+	if viper.GetBool(common.CfgRPCPprofEnabled) {
+		p.blockProfileRate = viper.GetInt(common.CfgRPCPprofBlockProfileRate)
+		p.mutexProfileFraction = viper.GetInt(common.CfgRPCPprofMutexProfileFraction)
+		runtime.SetBlockProfileRate(p.blockProfileRate)
+		runtime.SetMutexProfileFraction(p.mutexProfileFraction)
+	}
+	return p
+}
+
+func (p *profiler) acquire() bool {
+	select {
+	case p.busy <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *profiler) release() {
+	<-p.busy
+}
+
+// timed serializes the handler of a profile collected over a duration, and caps the duration set
+// by the "seconds" query parameter. The duration is defaultSecs if not set.
+func (p *profiler) timed(handler http.Handler, defaultSecs int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !p.acquire() {
+			http.Error(w, errProfilerBusy.Error(), http.StatusTooManyRequests)
+			return
+		}
+		defer p.release()
+
+		query := r.URL.Query()
+		seconds, err := strconv.Atoi(query.Get("seconds"))
+		if err != nil || seconds <= 0 {
+			seconds = defaultSecs
+		}
+		if maxSecs := int(p.maxDuration / time.Second); seconds > maxSecs {
+			seconds = maxSecs
+		}
+		query.Set("seconds", strconv.Itoa(seconds))
+		r.URL.RawQuery = query.Encode()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// capture writes the profile of the given type to w. The CPU profile is collected over the
+// duration, and so are the block and mutex profiles when their sampling is not enabled by the
+// config. The other profiles are written immediately.
+func (p *profiler) capture(ctx context.Context, profileType string, duration time.Duration, w io.Writer) error {
+	if profileType != "cpu" && !profileTypes[profileType] {
+		return fmt.Errorf("unknown profile type %v", profileType)
+	}
+	if !p.acquire() {
+		return errProfilerBusy
+	}
+	defer p.release()
+
+	if duration <= 0 || duration > p.maxDuration {
+		duration = p.maxDuration
+	}
+	wait := func() {
+		select {
+		case <-ctx.Done():
+		case <-time.After(duration):
+		}
+	}
+
+	switch {
+	case profileType == "cpu":
+		if err := rpprof.StartCPUProfile(w); err != nil {
+			return err
+		}
+		wait()
+		rpprof.StopCPUProfile()
+		return nil
+	case profileType == "block" && p.blockProfileRate == 0:
+		runtime.SetBlockProfileRate(captureBlockProfileRate)
+		wait()
+		defer runtime.SetBlockProfileRate(0)
+	case profileType == "mutex" && p.mutexProfileFraction == 0:
+		runtime.SetMutexProfileFraction(captureMutexProfileFraction)
+		wait()
+		defer runtime.SetMutexProfileFraction(0)
+	}
+	return rpprof.Lookup(profileType).WriteTo(w, 0)
+}
+
+// pprofMiddleware rejects the callers which are not allowed to call admin.Pprof when
+// authentication is enabled.
+func (a *rpcAuthenticator) pprofMiddleware(handler http.Handler) http.Handler {
+	if !a.enabled {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, err := a.authenticate(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if !allowed.match(pprofMethod) {
+			http.Error(w, fmt.Sprintf("%v is not allowed", pprofMethod), http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// handlePprof adds the pprof endpoints to the router of the admin listener.
+func (t *ThetaRPCServer) handlePprof(router *mux.Router, auth *rpcAuthenticator) {
+	wrap := func(handler http.Handler) http.Handler {
+		return auth.pprofMiddleware(t.rateLimiter.middleware(handler))
+	}
+	router.Handle("/debug/pprof/profile", wrap(t.profiler.timed(http.HandlerFunc(pprof.Profile), 30)))
+	router.Handle("/debug/pprof/trace", wrap(t.profiler.timed(http.HandlerFunc(pprof.Trace), 1)))
+	router.Handle("/debug/pprof/cmdline", wrap(http.HandlerFunc(pprof.Cmdline)))
+	router.Handle("/debug/pprof/symbol", wrap(http.HandlerFunc(pprof.Symbol)))
+	// The index also serves the named profiles, e.g. /debug/pprof/heap
+	router.PathPrefix("/debug/pprof/").Handler(wrap(http.HandlerFunc(pprof.Index)))
+}
@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfilerTimed(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &profiler{busy: make(chan struct{}, 1), maxDuration: 10 * time.Second}
+	var seconds string
+	handler := p.timed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seconds = r.URL.Query().Get("seconds")
+	}), 5)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/debug/pprof/profile", nil))
+	assert.Equal("5", seconds)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/debug/pprof/profile?seconds=3", nil))
+	assert.Equal("3", seconds)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/debug/pprof/profile?seconds=3600", nil))
+	assert.Equal("10", seconds)
+
+	// Only one profile is collected at a time
+	assert.True(p.acquire())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pprof/profile", nil))
+	assert.Equal(http.StatusTooManyRequests, rec.Code)
+	p.release()
+}
+
+func TestProfilerCapture(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &profiler{busy: make(chan struct{}, 1), maxDuration: 100 * time.Millisecond}
+
+	var buf bytes.Buffer
+	assert.Nil(p.capture(context.Background(), "heap", 0, &buf))
+	assert.True(buf.Len() > 0)
+
+	buf.Reset()
+	assert.Nil(p.capture(context.Background(), "mutex", time.Hour, &buf))
+	assert.True(buf.Len() > 0)
+
+	assert.NotNil(p.capture(context.Background(), "unknown", 0, &buf))
+
+	assert.True(p.acquire())
+	assert.Equal(errProfilerBusy, p.capture(context.Background(), "heap", 0, &buf))
+	p.release()
+}
+
+func TestPprofMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	auth := &rpcAuthenticator{
+		enabled: true,
+		apiKeys: []apiKey{
+			{key: "ops", methods: methodPatterns{"admin.*"}},
+			{key: "reader", methods: methodPatterns{"theta.Get*"}},
+		},
+		publicMethods: methodPatterns{"theta.*"},
+	}
+	handler := auth.pprofMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(key string) int {
+		req := httptest.NewRequest("GET", "/debug/pprof/heap", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(http.StatusOK, serve("ops"))
+	assert.Equal(http.StatusForbidden, serve("reader"))
+	assert.Equal(http.StatusForbidden, serve(""))
+	assert.Equal(http.StatusUnauthorized, serve("invalid"))
+}
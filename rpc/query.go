@@ -2,8 +2,6 @@ package rpc
 
 import (
 	"encoding/hex"
-	"errors"
-	"fmt"
 	"log"
 	"math/big"
 	"math/rand"
@@ -20,6 +18,7 @@ import (
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/upgrade"
 	"github.com/thetatoken/theta/mempool"
 	"github.com/thetatoken/theta/version"
 )
@@ -58,7 +57,7 @@ type GetAccountResult struct {
 
 func (t *ThetaRPCService) GetAccount(args *GetAccountArgs, result *GetAccountResult) (err error) {
 	if args.Address == "" {
-		return errors.New("Address must be specified")
+		return errInvalidParams("Address must be specified")
 	}
 	address := common.HexToAddress(args.Address)
 	result.Address = args.Address
@@ -77,40 +76,23 @@ func (t *ThetaRPCService) GetAccount(args *GetAccountArgs, result *GetAccountRes
 
 		account := ledgerState.GetAccount(address)
 		if account == nil {
-			return fmt.Errorf("Account with address %s is not found", address.Hex())
+			return errNotFound("Account with address %s is not found", address.Hex())
 		}
 		account.UpdateToHeight(ledgerState.Height())
 
 		result.Account = account
 	} else {
-		blocks := t.chain.FindBlocksByHeight(height)
-		if len(blocks) == 0 {
-			result.Account = nil
-			return nil
-		}
-
-		deliveredView, err := t.ledger.GetDeliveredSnapshot()
+		ledgerState, err := t.getLedgerStateAtHeight(height)
 		if err != nil {
 			return err
 		}
-		db := deliveredView.GetDB()
-
-		for _, b := range blocks {
-			if b.Status.IsFinalized() {
-				stateRoot := b.StateHash
-				ledgerState := state.NewStoreView(height, stateRoot, db)
-				if ledgerState == nil { // might have been pruned
-					return fmt.Errorf("the account details for height %v is not available, it might have been pruned", height)
-				}
-				account := ledgerState.GetAccount(address)
-				if account == nil {
-					return fmt.Errorf("Account with address %v is not found", address.Hex())
-				}
-				result.Account = account
-				break
-			}
+		account := ledgerState.GetAccount(address)
+		if account == nil {
+			return errNotFound("Account with address %v is not found", address.Hex())
 		}
+		account.UpdateToHeight(ledgerState.Height())
 
+		result.Account = account
 	}
 
 	return nil
@@ -119,7 +101,8 @@ func (t *ThetaRPCService) GetAccount(args *GetAccountArgs, result *GetAccountRes
 // ------------------------------- GetSplitRule -----------------------------------
 
 type GetSplitRuleArgs struct {
-	ResourceID string `json:"resource_id"`
+	ResourceID string            `json:"resource_id"`
+	Height     common.JSONUint64 `json:"height"` // optional, the latest state is used if zero
 }
 
 type GetSplitRuleResult struct {
@@ -128,10 +111,15 @@ type GetSplitRuleResult struct {
 
 func (t *ThetaRPCService) GetSplitRule(args *GetSplitRuleArgs, result *GetSplitRuleResult) (err error) {
 	if args.ResourceID == "" {
-		return errors.New("ResourceID must be specified")
+		return errInvalidParams("ResourceID must be specified")
 	}
 	resourceID := args.ResourceID
-	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	var ledgerState *state.StoreView
+	if args.Height == 0 {
+		ledgerState, err = t.ledger.GetDeliveredSnapshot()
+	} else {
+		ledgerState, err = t.getLedgerStateAtHeight(uint64(args.Height))
+	}
 	if err != nil {
 		return err
 	}
@@ -139,6 +127,41 @@ func (t *ThetaRPCService) GetSplitRule(args *GetSplitRuleArgs, result *GetSplitR
 	return nil
 }
 
+// ------------------------------- GetActiveSplitRules -----------------------------------
+
+type GetActiveSplitRulesArgs struct {
+	ResourceIDs []string `json:"resource_ids"`
+}
+
+type GetActiveSplitRulesResult struct {
+	BlockHeight common.JSONUint64  `json:"block_height"`
+	SplitRules  []*types.SplitRule `json:"split_rules"`
+}
+
+// GetActiveSplitRules returns the split rules of the given resourceIDs which have not expired yet.
+// The resourceIDs without an active split rule are omitted from the result.
+func (t *ThetaRPCService) GetActiveSplitRules(args *GetActiveSplitRulesArgs, result *GetActiveSplitRulesResult) (err error) {
+	if len(args.ResourceIDs) == 0 {
+		return errInvalidParams("ResourceIDs must be specified")
+	}
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	currentHeight := ledgerState.Height()
+	result.BlockHeight = common.JSONUint64(currentHeight)
+	result.SplitRules = []*types.SplitRule{}
+	for _, resourceID := range args.ResourceIDs {
+		splitRule := ledgerState.GetSplitRule(resourceID)
+		if splitRule == nil || currentHeight > splitRule.EndBlockHeight {
+			continue
+		}
+		result.SplitRules = append(result.SplitRules, splitRule)
+	}
+	return nil
+}
+
 // ------------------------------ GetTransaction -----------------------------------
 
 type GetTransactionArgs struct {
@@ -165,8 +188,21 @@ const (
 )
 
 func (t *ThetaRPCService) GetTransaction(args *GetTransactionArgs, result *GetTransactionResult) (err error) {
+	if t.responseCache.get("GetTransaction", args, result) {
+		return nil
+	}
+	if err := t.getTransaction(args, result); err != nil {
+		return err
+	}
+	if result.Status == TxStatusFinalized && t.isSettled(uint64(result.BlockHeight)) {
+		t.responseCache.put("GetTransaction", args, result)
+	}
+	return nil
+}
+
+func (t *ThetaRPCService) getTransaction(args *GetTransactionArgs, result *GetTransactionResult) (err error) {
 	if args.Hash == "" {
-		return errors.New("Transanction hash must be specified")
+		return errInvalidParams("Transanction hash must be specified")
 	}
 	hash := common.HexToHash(args.Hash)
 
@@ -216,6 +252,91 @@ func (t *ThetaRPCService) GetTransaction(args *GetTransactionArgs, result *GetTr
 	return nil
 }
 
+// ------------------------------ GetTransactionByHash -----------------------------------
+
+// TxStatusCommitted indicates the transaction is included in a block which is not finalized yet
+const TxStatusCommitted = "committed"
+
+type GetTransactionByHashArgs struct {
+	Hash string `json:"hash"`
+}
+
+type GetTransactionByHashResult struct {
+	GetTransactionResult
+	BlockTimestamp      *common.JSONBig   `json:"block_timestamp"`
+	Confirmations       common.JSONUint64 `json:"confirmations"` // number of finalized blocks since the transaction's block (inclusive)
+	LastFinalizedHeight common.JSONUint64 `json:"last_finalized_height"`
+}
+
+// GetTransactionByHash returns the transaction with the given hash (either the native or the ETH hash) and
+// its status, which is one of "finalized", "committed", "pending" (in the mempool), "abandoned" or
+// "not_found". For a finalized transaction, the number of confirmations is also returned.
+func (t *ThetaRPCService) GetTransactionByHash(args *GetTransactionByHashArgs, result *GetTransactionByHashResult) (err error) {
+	if err := t.GetTransaction(&GetTransactionArgs{Hash: args.Hash}, &result.GetTransactionResult); err != nil {
+		return err
+	}
+
+	lastFinalizedBlock := t.consensus.GetLastFinalizedBlock()
+	result.LastFinalizedHeight = common.JSONUint64(lastFinalizedBlock.Height)
+	if result.Tx == nil {
+		return nil // not included in any block yet
+	}
+
+	block, err := t.chain.FindBlock(result.BlockHash)
+	if err != nil {
+		return err
+	}
+	result.BlockTimestamp = (*common.JSONBig)(block.Timestamp)
+	if !block.Status.IsFinalized() {
+		result.Status = TxStatusCommitted
+		return nil
+	}
+	if lastFinalizedBlock.Height >= block.Height {
+		result.Confirmations = common.JSONUint64(lastFinalizedBlock.Height - block.Height + 1)
+	}
+
+	return nil
+}
+
+// ------------------------------ GetTransactionReceipt -----------------------------------
+
+type GetTransactionReceiptArgs struct {
+	Hash string `json:"hash"`
+}
+
+type GetTransactionReceiptResult struct {
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	TxHash      common.Hash       `json:"hash"`
+	Receipt     *types.TxReceipt  `json:"receipt"`
+}
+
+func (t *ThetaRPCService) GetTransactionReceipt(args *GetTransactionReceiptArgs, result *GetTransactionReceiptResult) (err error) {
+	if args.Hash == "" {
+		return errInvalidParams("Transanction hash must be specified")
+	}
+	hash := common.HexToHash(args.Hash)
+
+	raw, block, found := t.chain.FindTxByHash(hash)
+	if !found {
+		return errNotFound("Transaction %v not found", args.Hash)
+	}
+
+	// args.Hash maybe an ETH tx hash, the receipt is indexed by the hash of the native Tx
+	canonicalTxHash := crypto.Keccak256Hash(raw)
+	receipt, found := t.chain.FindCanonicalTxReceiptByHash(canonicalTxHash)
+	if !found {
+		return errNotFound("Receipt of transaction %v not found", args.Hash)
+	}
+
+	result.BlockHash = block.Hash()
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.TxHash = canonicalTxHash
+	result.Receipt = receipt
+
+	return nil
+}
+
 // ------------------------------ GetPendingTransactions -----------------------------------
 
 type GetPendingTransactionsArgs struct {
@@ -294,11 +415,37 @@ const (
 	TxTypeWithdrawStake
 	TxTypeDepositStakeTxV2
 	TxTypeStakeRewardDistributionTx
+	TxTypeMultiSigSendTx
+	TxTypeVestingTransferTx
+	TxTypeVestingClaimTx
+	TxTypeBatchSendTx
+	TxTypeSplitRuleRenewalTx
+	TxTypeReserveFundTxV2
+	TxTypeTokenRegistryTx
+	TxTypeSmartContractTxV2
+	TxTypeValidatorKeyRotationTx
+	TxTypeIBCTx
+	TxTypeBandwidthProofTx
+	TxTypeGovProposalTx
+	TxTypeGovVoteTx
 )
 
 func (t *ThetaRPCService) GetBlock(args *GetBlockArgs, result *GetBlockResult) (err error) {
+	if t.responseCache.get("GetBlock", args, result) {
+		return nil
+	}
+	if err := t.getBlock(args, result); err != nil {
+		return err
+	}
+	if result.GetBlockResultInner != nil && result.Status.IsFinalized() && t.isSettled(uint64(result.Height)) {
+		t.responseCache.put("GetBlock", args, result)
+	}
+	return nil
+}
+
+func (t *ThetaRPCService) getBlock(args *GetBlockArgs, result *GetBlockResult) (err error) {
 	if args.Hash.IsEmpty() {
-		return errors.New("Block hash must be specified")
+		return errInvalidParams("Block hash must be specified")
 	}
 
 	block, err := t.chain.FindBlock(args.Hash)
@@ -335,6 +482,19 @@ type GetBlockByHeightArgs struct {
 }
 
 func (t *ThetaRPCService) GetBlockByHeight(args *GetBlockByHeightArgs, result *GetBlockResult) (err error) {
+	if t.responseCache.get("GetBlockByHeight", args, result) {
+		return nil
+	}
+	if err := t.getBlockByHeight(args, result); err != nil {
+		return err
+	}
+	if result.GetBlockResultInner != nil && t.isSettled(uint64(args.Height)) {
+		t.responseCache.put("GetBlockByHeight", args, result)
+	}
+	return nil
+}
+
+func (t *ThetaRPCService) getBlockByHeight(args *GetBlockByHeightArgs, result *GetBlockResult) (err error) {
 	// if args.Height == 0 {
 	// 	return errors.New("Block height must be specified")
 	// }
@@ -424,12 +584,12 @@ func (t *ThetaRPCService) GetBlocksByRange(args *GetBlocksByRangeArgs, result *G
 	}
 
 	if args.Start > args.End {
-		return errors.New("Starting block must be less than ending block")
+		return errInvalidParams("Starting block must be less than ending block")
 	}
 
 	maxBlockRange := common.JSONUint64(5000)
 	if args.End-args.Start > maxBlockRange {
-		return errors.New("Can't retrieve more than 100 blocks at a time")
+		return errInvalidParams("Can't retrieve more than 100 blocks at a time")
 	}
 
 	blocks := t.chain.FindBlocksByHeight(uint64(args.End))
@@ -484,6 +644,116 @@ func (t *ThetaRPCService) GetBlocksByRange(args *GetBlocksByRangeArgs, result *G
 	return
 }
 
+// ------------------------------ ListBlocks -----------------------------------
+
+// maxListBlocksTxBytes caps the total size of the transactions returned by a ListBlocks call
+const maxListBlocksTxBytes = 4 * 1024 * 1024
+
+type ListBlocksArgs struct {
+	Start              common.JSONUint64 `json:"start"`
+	End                common.JSONUint64 `json:"end"`          // optional, the latest finalized block is used if zero
+	Limit              common.JSONUint64 `json:"limit"`        // optional, the maximum number of blocks per page
+	HeadersOnly        bool              `json:"headers_only"` // omit the transactions of the blocks
+	IncludeEthTxHashes bool              `json:"include_eth_tx_hashes"`
+}
+
+type ListBlocksResult struct {
+	Blocks     []*GetBlockResultInner `json:"blocks"`
+	HasMore    bool                   `json:"has_more"`
+	NextHeight common.JSONUint64      `json:"next_height"` // start of the next page, only set if there are more blocks
+}
+
+// ListBlocks returns a page of the finalized blocks between the start and the end heights (inclusive)
+// in ascending order. A page ends when either the limit is reached, or the total size of the
+// transactions reaches maxListBlocksTxBytes, in which case the next page starts from NextHeight.
+// A page contains at least one block.
+func (t *ThetaRPCService) ListBlocks(args *ListBlocksArgs, result *ListBlocksResult) (err error) {
+	limit := int(args.Limit)
+	if limit == 0 {
+		limit = defaultListStateLimit
+	}
+	if limit > maxListStateLimit {
+		return errInvalidParams("Limit cannot exceed %v", maxListStateLimit)
+	}
+
+	end := args.End
+	lastFinalizedHeight := common.JSONUint64(t.consensus.GetLastFinalizedBlock().Height)
+	if end == 0 || end > lastFinalizedHeight {
+		end = lastFinalizedHeight
+	}
+	if args.Start > end {
+		return errInvalidParams("Starting block must be less than ending block")
+	}
+
+	result.Blocks = []*GetBlockResultInner{}
+	txBytes := 0
+	height := args.Start
+	for ; height <= end && len(result.Blocks) < limit; height++ {
+		block := t.findFinalizedBlockByHeight(uint64(height))
+		if block == nil {
+			if height == 0 { // special handling for a node starting from a non-genesis snapshot
+				result.Blocks = append(result.Blocks, t.getGenesisBlockResult())
+			}
+			continue // blocks before the snapshot the node starts from are not available
+		}
+
+		blockTxBytes := 0
+		if !args.HeadersOnly {
+			for _, tx := range block.Txs {
+				blockTxBytes += len(tx)
+			}
+			if len(result.Blocks) > 0 && txBytes+blockTxBytes > maxListBlocksTxBytes {
+				break
+			}
+		}
+		txBytes += blockTxBytes
+
+		blkInner := &GetBlockResultInner{}
+		blkInner.ChainID = block.ChainID
+		blkInner.Epoch = common.JSONUint64(block.Epoch)
+		blkInner.Height = common.JSONUint64(block.Height)
+		blkInner.Parent = block.Parent
+		blkInner.TxHash = block.TxHash
+		blkInner.StateHash = block.StateHash
+		blkInner.Timestamp = (*common.JSONBig)(block.Timestamp)
+		blkInner.Proposer = block.Proposer
+		blkInner.Children = block.Children
+		blkInner.Status = block.Status
+		blkInner.HCC = block.HCC
+		blkInner.GuardianVotes = block.GuardianVotes
+		blkInner.EliteEdgeNodeVotes = block.EliteEdgeNodeVotes
+		blkInner.Hash = block.Hash()
+		if !args.HeadersOnly {
+			if err := t.gatherTxs(block, &blkInner.Txs, args.IncludeEthTxHashes); err != nil {
+				return err
+			}
+		}
+
+		result.Blocks = append(result.Blocks, blkInner)
+	}
+
+	if height <= end {
+		result.HasMore = true
+		result.NextHeight = height
+	}
+
+	return nil
+}
+
+func (t *ThetaRPCService) getGenesisBlockResult() *GetBlockResultInner {
+	genesisBlock := &GetBlockResultInner{}
+	if t.consensus.Chain().ChainID == core.MainnetChainID {
+		genesisBlock.Hash = common.HexToHash(core.MainnetGenesisBlockHash)
+	} else {
+		genesisBlock.Hash = common.HexToHash(viper.GetString(common.CfgGenesisHash))
+	}
+	genesisBlock.ChainID = t.consensus.Chain().ChainID
+	genesisBlock.Children = []common.Hash{}
+	genesisBlock.Status = core.BlockStatusDirectlyFinalized
+	genesisBlock.Timestamp = (*common.JSONBig)(big.NewInt(0))
+	return genesisBlock
+}
+
 // ------------------------------ GetStatus -----------------------------------
 
 type GetStatusArgs struct{}
@@ -501,6 +771,7 @@ type GetStatusResult struct {
 	CurrentTime                *common.JSONBig   `json:"current_time"`
 	Syncing                    bool              `json:"syncing"`
 	GenesisBlockHash           common.Hash       `json:"genesis_block_hash"`
+	Archive                    bool              `json:"archive"` // whether the states and indices of the full chain history are retained
 }
 
 func (t *ThetaRPCService) GetStatus(args *GetStatusArgs, result *GetStatusResult) (err error) {
@@ -547,6 +818,7 @@ func (t *ThetaRPCService) GetStatus(args *GetStatusArgs, result *GetStatusResult
 		genesisHash = common.HexToHash(viper.GetString(common.CfgGenesisHash))
 	}
 	result.GenesisBlockHash = genesisHash
+	result.Archive = viper.GetBool(common.CfgStorageArchive)
 
 	return
 }
@@ -626,7 +898,7 @@ func (t *ThetaRPCService) GetVcpByHeight(args *GetVcpByHeightArgs, result *GetVc
 		stateRoot := b.StateHash
 		blockStoreView := state.NewStoreView(height, stateRoot, db)
 		if blockStoreView == nil { // might have been pruned
-			return fmt.Errorf("the VCP for height %v does not exists, it might have been pruned", height)
+			return errStateUnavailable(ReasonStatePruned, height, "the VCP for height %v does not exists, it might have been pruned", height)
 		}
 		vcp := blockStoreView.GetValidatorCandidatePool()
 		hl := blockStoreView.GetStakeTransactionHeightList()
@@ -673,7 +945,7 @@ func (t *ThetaRPCService) GetGcpByHeight(args *GetGcpByHeightArgs, result *GetGc
 		stateRoot := b.StateHash
 		blockStoreView := state.NewStoreView(height, stateRoot, db)
 		if blockStoreView == nil { // might have been pruned
-			return fmt.Errorf("the GCP for height %v does not exists, it might have been pruned", height)
+			return errStateUnavailable(ReasonStatePruned, height, "the GCP for height %v does not exists, it might have been pruned", height)
 		}
 		gcp := blockStoreView.GetGuardianCandidatePool()
 		blockHashGcpPairs = append(blockHashGcpPairs, BlockHashGcpPair{
@@ -699,10 +971,10 @@ type GetGuardianInfoResult struct {
 }
 
 func (t *ThetaRPCService) GetGuardianInfo(args *GetGuardianInfoArgs, result *GetGuardianInfoResult) (err error) {
-	privKey := t.consensus.PrivateKey()
+	privKey := t.consensus.Signer()
 	blsKey, err := bls.GenKey(strings.NewReader(common.Bytes2Hex(privKey.PublicKey().ToBytes())))
 	if err != nil {
-		return fmt.Errorf("Failed to get BLS key: %v", err.Error())
+		return errInternal("Failed to get BLS key: %v", err.Error())
 	}
 
 	result.Address = privKey.PublicKey().Address().Hex()
@@ -712,7 +984,7 @@ func (t *ThetaRPCService) GetGuardianInfo(args *GetGuardianInfoArgs, result *Get
 
 	sig, err := privKey.Sign(popBytes)
 	if err != nil {
-		return fmt.Errorf("Failed to generate signature: %v", err.Error())
+		return errInternal("Failed to generate signature: %v", err.Error())
 	}
 	result.Signature = hex.EncodeToString(sig.ToBytes())
 
@@ -750,7 +1022,7 @@ func (t *ThetaRPCService) GetEenpByHeight(args *GetEenpByHeightArgs, result *Get
 		stateRoot := b.StateHash
 		blockStoreView := state.NewStoreView(height, stateRoot, db)
 		if blockStoreView == nil { // might have been pruned
-			return fmt.Errorf("the EENP for height %v does not exists, it might have been pruned", height)
+			return errStateUnavailable(ReasonStatePruned, height, "the EENP for height %v does not exists, it might have been pruned", height)
 		}
 		eenp := state.NewEliteEdgeNodePool(blockStoreView, true)
 		eens := eenp.GetAll(false)
@@ -799,7 +1071,7 @@ func (t *ThetaRPCService) GetStakeRewardDistributionByHeight(
 		stateRoot := b.StateHash
 		blockStoreView := state.NewStoreView(height, stateRoot, db)
 		if blockStoreView == nil { // might have been pruned
-			return fmt.Errorf("the EENP for height %v does not exists, it might have been pruned", height)
+			return errStateUnavailable(ReasonStatePruned, height, "the EENP for height %v does not exists, it might have been pruned", height)
 		}
 		srdrs := state.NewStakeRewardDistributionRuleSet(blockStoreView)
 
@@ -905,7 +1177,7 @@ type GetCodeResult struct {
 
 func (t *ThetaRPCService) GetCode(args *GetCodeArgs, result *GetCodeResult) (err error) {
 	if args.Address == "" {
-		return errors.New("address must be specified")
+		return errInvalidParams("address must be specified")
 	}
 	address := common.HexToAddress(args.Address)
 	result.Address = args.Address
@@ -937,7 +1209,7 @@ func (t *ThetaRPCService) GetCode(args *GetCodeArgs, result *GetCodeResult) (err
 				stateRoot := b.StateHash
 				ledgerState := state.NewStoreView(height, stateRoot, db)
 				if ledgerState == nil { // might have been pruned
-					return fmt.Errorf("the account details for height %v is not available, it might have been pruned", height)
+					return errStateUnavailable(ReasonStatePruned, height, "the account details for height %v is not available, it might have been pruned", height)
 				}
 				codeBytes := ledgerState.GetCode(address)
 				result.Code = hex.EncodeToString(codeBytes)
@@ -964,7 +1236,7 @@ type GetStorageAtResult struct {
 
 func (t *ThetaRPCService) GetStorageAt(args *GetStorageAtArgs, result *GetStorageAtResult) (err error) {
 	if args.Address == "" || args.StoragePosition == "" {
-		return fmt.Errorf("address and storage_position must be specified, address: %v, storage_position: %v", args.Address, args.StoragePosition)
+		return errInvalidParams("address and storage_position must be specified, address: %v, storage_position: %v", args.Address, args.StoragePosition)
 	}
 	address := common.HexToAddress(args.Address)
 	key := common.HexToHash(args.StoragePosition)
@@ -996,7 +1268,7 @@ func (t *ThetaRPCService) GetStorageAt(args *GetStorageAtArgs, result *GetStorag
 				stateRoot := b.StateHash
 				ledgerState := state.NewStoreView(height, stateRoot, db)
 				if ledgerState == nil { // might have been pruned
-					return fmt.Errorf("the account details for height %v is not available, it might have been pruned", height)
+					return errStateUnavailable(ReasonStatePruned, height, "the account details for height %v is not available, it might have been pruned", height)
 				}
 				value := ledgerState.GetState(address, key)
 				result.Value = hex.EncodeToString(value.Bytes())
@@ -1008,8 +1280,532 @@ func (t *ThetaRPCService) GetStorageAt(args *GetStorageAtArgs, result *GetStorag
 	return nil
 }
 
+// ------------------------------- GetVestingFunds -----------------------------------
+
+type GetVestingFundsArgs struct {
+	Address string            `json:"address"` // address of the beneficiary
+	Height  common.JSONUint64 `json:"height"`  // optional, the latest finalized block is used if zero
+}
+
+type VestingFundWithStatus struct {
+	Fund      *types.VestingFund `json:"fund"`
+	Unlocked  bool               `json:"unlocked"`
+	Vested    types.Coins        `json:"vested"`
+	Claimable types.Coins        `json:"claimable"`
+}
+
+type GetVestingFundsResult struct {
+	Height       common.JSONUint64        `json:"height"`
+	VestingFunds []*VestingFundWithStatus `json:"vesting_funds"`
+}
+
+// GetVestingFunds returns the vesting funds of the given beneficiary as of the finalized block at the
+// given height, or the latest finalized block if the height is zero.
+func (t *ThetaRPCService) GetVestingFunds(args *GetVestingFundsArgs, result *GetVestingFundsResult) (err error) {
+	if args.Address == "" {
+		return errInvalidParams("Address must be specified")
+	}
+	beneficiary := common.HexToAddress(args.Address)
+
+	ledgerState, err := t.getLedgerStateAtHeight(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+
+	// The vesting status is evaluated as if the next block was proposed, consistent with VestingClaimTx
+	block := t.consensus.GetLastFinalizedBlock()
+	if args.Height != 0 {
+		block = t.findFinalizedBlockByHeight(uint64(args.Height))
+	}
+	blockHeight := ledgerState.Height() + 1
+	blockTime := block.Timestamp.Uint64()
+
+	result.Height = common.JSONUint64(ledgerState.Height())
+	result.VestingFunds = []*VestingFundWithStatus{}
+	for _, fund := range ledgerState.GetVestingFunds(beneficiary) {
+		result.VestingFunds = append(result.VestingFunds, &VestingFundWithStatus{
+			Fund:      fund,
+			Unlocked:  fund.IsUnlocked(blockHeight, blockTime),
+			Vested:    fund.VestedAmount(blockHeight, blockTime),
+			Claimable: fund.ClaimableAmount(blockHeight, blockTime),
+		})
+	}
+
+	return nil
+}
+
+// ------------------------------- GetRegisteredTokens -----------------------------------
+
+type GetRegisteredTokensArgs struct {
+	Namespace string `json:"namespace"` // optional, all the registered tokens are returned if empty
+}
+
+type GetRegisteredTokensResult struct {
+	BlockHeight common.JSONUint64  `json:"block_height"`
+	Tokens      []*types.TokenInfo `json:"tokens"`
+}
+
+// GetRegisteredTokens returns the metadata of the tokens registered through the TokenRegistryTx,
+// optionally filtered by namespace.
+func (t *ThetaRPCService) GetRegisteredTokens(args *GetRegisteredTokensArgs, result *GetRegisteredTokensResult) (err error) {
+	if args.Namespace != "" {
+		if err := types.ValidateTokenNamespace(args.Namespace); err != nil {
+			return err
+		}
+	}
+
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Tokens = ledgerState.GetTokenInfos(args.Namespace)
+	return nil
+}
+
+// ------------------------------- GetBandwidthRewardRecords -----------------------------------
+
+type GetBandwidthRewardRecordsArgs struct {
+	Pool     string `json:"pool"`
+	EdgeNode string `json:"edge_node"` // optional, the records of all the edge nodes are returned if empty
+}
+
+type GetBandwidthRewardRecordsResult struct {
+	BlockHeight common.JSONUint64              `json:"block_height"`
+	Epoch       common.JSONUint64              `json:"epoch"` // the bandwidth reward epoch of the next block
+	Records     []*types.BandwidthRewardRecord `json:"records"`
+}
+
+// GetBandwidthRewardRecords returns the bandwidth rewards the edge nodes claimed from the pool through
+// the BandwidthProofTx, along with the last claimed nonces
+func (t *ThetaRPCService) GetBandwidthRewardRecords(args *GetBandwidthRewardRecordsArgs, result *GetBandwidthRewardRecordsResult) (err error) {
+	if args.Pool == "" {
+		return errInvalidParams("Pool must be specified")
+	}
+	pool := common.HexToAddress(args.Pool)
+
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Epoch = common.JSONUint64(types.BandwidthRewardEpoch(ledgerState.Height() + 1))
+	if args.EdgeNode == "" {
+		result.Records = ledgerState.GetBandwidthRewardRecords(pool)
+		return nil
+	}
+	result.Records = []*types.BandwidthRewardRecord{}
+	if record := ledgerState.GetBandwidthRewardRecord(pool, common.HexToAddress(args.EdgeNode)); record != nil {
+		result.Records = append(result.Records, record)
+	}
+	return nil
+}
+
+// ------------------------------- GetGovProposal -----------------------------------
+
+type GetGovProposalArgs struct {
+	ProposalID common.JSONUint64 `json:"proposal_id"`
+}
+
+type GovParamChangeResult struct {
+	Param string      `json:"param"`
+	Value interface{} `json:"value"` // the decoded new value, e.g. a FeeSchedule
+}
+
+type GetGovProposalResult struct {
+	BlockHeight  common.JSONUint64      `json:"block_height"`
+	Proposal     *types.GovProposal     `json:"proposal"`
+	Status       string                 `json:"status"`
+	ParamChanges []GovParamChangeResult `json:"param_changes"`
+}
+
+// GetGovProposal returns a governance proposal along with its decoded param changes. The tally of
+// the vote is filled in once the voting period is over.
+func (t *ThetaRPCService) GetGovProposal(args *GetGovProposalArgs, result *GetGovProposalResult) (err error) {
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	proposal := ledgerState.GetGovProposal(uint64(args.ProposalID))
+	if proposal == nil {
+		return errNotFound("Governance proposal %v not found", args.ProposalID)
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Proposal = proposal
+	result.Status = proposal.Status.String()
+	result.ParamChanges = []GovParamChangeResult{}
+	for _, pc := range proposal.ParamChanges {
+		value, err := pc.DecodeValue()
+		if err != nil {
+			return errInternal("Failed to decode the param change: %v", err)
+		}
+		result.ParamChanges = append(result.ParamChanges, GovParamChangeResult{Param: pc.Param, Value: value})
+	}
+	return nil
+}
+
+// ------------------------------- GetGovProposals -----------------------------------
+
+type GetGovProposalsArgs struct {
+	Status string `json:"status"` // optional, e.g. "voting", all the proposals are returned if empty
+}
+
+type GetGovProposalsResult struct {
+	BlockHeight common.JSONUint64    `json:"block_height"`
+	Proposals   []*types.GovProposal `json:"proposals"`
+}
+
+// GetGovProposals returns the governance proposals with the given status, ordered by their IDs
+func (t *ThetaRPCService) GetGovProposals(args *GetGovProposalsArgs, result *GetGovProposalsResult) (err error) {
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Proposals = []*types.GovProposal{}
+	for _, proposal := range ledgerState.GetGovProposals() {
+		if args.Status == "" || proposal.Status.String() == args.Status {
+			result.Proposals = append(result.Proposals, proposal)
+		}
+	}
+	return nil
+}
+
+// ------------------------------- GetGovVotes -----------------------------------
+
+type GetGovVotesArgs struct {
+	ProposalID common.JSONUint64 `json:"proposal_id"`
+	Voter      string            `json:"voter"` // optional, the votes of all the voters are returned if empty
+}
+
+type GetGovVotesResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	Votes       []*types.GovVote  `json:"votes"`
+}
+
+// GetGovVotes returns the votes cast on a governance proposal through the GovVoteTx
+func (t *ThetaRPCService) GetGovVotes(args *GetGovVotesArgs, result *GetGovVotesResult) (err error) {
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	proposalID := uint64(args.ProposalID)
+	if ledgerState.GetGovProposal(proposalID) == nil {
+		return errNotFound("Governance proposal %v not found", proposalID)
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	if args.Voter == "" {
+		result.Votes = ledgerState.GetGovVotes(proposalID)
+		return nil
+	}
+	result.Votes = []*types.GovVote{}
+	if vote := ledgerState.GetGovVote(proposalID, common.HexToAddress(args.Voter)); vote != nil {
+		result.Votes = append(result.Votes, vote)
+	}
+	return nil
+}
+
+// ------------------------------- GetValidatorSigningKeys -----------------------------------
+
+type GetValidatorSigningKeysArgs struct {
+	Validator string `json:"validator"`
+}
+
+type GetValidatorSigningKeysResult struct {
+	BlockHeight          common.JSONUint64           `json:"block_height"`
+	Epoch                common.JSONUint64           `json:"epoch"`
+	ActiveSigningAddress common.Address              `json:"active_signing_address"`
+	AnnouncedSigningKeys []types.ValidatorSigningKey `json:"announced_signing_keys"`
+}
+
+// GetValidatorSigningKeys returns the signing key of the validator active at the current epoch, along
+// with the signing keys announced through the ValidatorKeyRotationTx
+func (t *ThetaRPCService) GetValidatorSigningKeys(args *GetValidatorSigningKeysArgs, result *GetValidatorSigningKeysResult) (err error) {
+	if args.Validator == "" {
+		return errInvalidParams("Validator must be specified")
+	}
+	validator := common.HexToAddress(args.Validator)
+
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	epoch := t.consensus.GetEpoch()
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Epoch = common.JSONUint64(epoch)
+	result.ActiveSigningAddress = validator
+	result.AnnouncedSigningKeys = []types.ValidatorSigningKey{}
+	if vsk := ledgerState.GetValidatorSigningKeys(validator); vsk != nil {
+		result.ActiveSigningAddress = vsk.SigningAddress(epoch)
+		result.AnnouncedSigningKeys = vsk.Keys
+	}
+	return nil
+}
+
+// ------------------------------- GetFeeSchedule -----------------------------------
+
+type GetFeeScheduleArgs struct{}
+
+type GetFeeScheduleResult struct {
+	BlockHeight common.JSONUint64  `json:"block_height"`
+	FeeSchedule *types.FeeSchedule `json:"fee_schedule"`
+}
+
+// GetFeeSchedule returns the minimum transaction fee schedule in effect
+func (t *ThetaRPCService) GetFeeSchedule(args *GetFeeScheduleArgs, result *GetFeeScheduleResult) (err error) {
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.FeeSchedule = ledgerState.GetFeeSchedule()
+	return nil
+}
+
+// ------------------------------- GetChainID -----------------------------------
+
+type GetChainIDArgs struct{}
+
+type GetChainIDResult struct {
+	ChainID                string            `json:"chain_id"`
+	NumericChainID         *common.JSONBig   `json:"numeric_chain_id"`
+	BlockHeight            common.JSONUint64 `json:"block_height"`
+	ReplayProtectionHeight common.JSONUint64 `json:"replay_protection_height"`
+	ReplayProtectionActive bool              `json:"replay_protection_active"` // at the next block
+}
+
+// GetChainID returns the chain ID and the numeric chain ID of the network. Once the replay protection
+// is active, the transactions need to be signed over the sign bytes committing to the numeric chain ID,
+// i.e. in the v3 Ethereum tx wrapper.
+func (t *ThetaRPCService) GetChainID(args *GetChainIDArgs, result *GetChainIDResult) (err error) {
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	chainID := t.ledger.State().GetChainID()
+	numericChainID := ledgerState.GetNumericChainID()
+	if numericChainID == nil {
+		numericChainID = types.NumericChainID(chainID)
+	}
+	result.ChainID = chainID
+	result.NumericChainID = (*common.JSONBig)(numericChainID)
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.ReplayProtectionHeight = common.JSONUint64(common.HeightEnableChainIDReplayProtection)
+	result.ReplayProtectionActive = ledgerState.Height()+1 >= common.HeightEnableChainIDReplayProtection
+	return nil
+}
+
+// ------------------------------- GetUpgradeStatus -----------------------------------
+
+type GetUpgradeStatusArgs struct{}
+
+type GetUpgradeStatusResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	Upgrades    []upgrade.Status  `json:"upgrades"`
+}
+
+// GetUpgradeStatus returns the activation heights of the protocol upgrades, and whether they are
+// active at the next block. An upgrade scheduled by the chain params but not supported by this
+// binary halts the node at its activation height.
+func (t *ThetaRPCService) GetUpgradeStatus(args *GetUpgradeStatusArgs, result *GetUpgradeStatusResult) (err error) {
+	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Upgrades = upgrade.NewManager().Status(ledgerState, ledgerState.Height()+1)
+	return nil
+}
+
+// ------------------------------- ListAccounts / ListReservedFunds / ListStakeHolders -----------------------------------
+//
+// The list queries iterate the ledger state of a finalized block in ascending address order. Each page
+// returns the cursor of the next page, which is empty after the last page. The subsequent pages should
+// be queried with the block height returned by the first page, so that all pages come from the same state.
+//
+
+const (
+	defaultListStateLimit = 100
+	maxListStateLimit     = 1000
+)
+
+type ListStateArgs struct {
+	Height common.JSONUint64 `json:"height"` // optional, the latest finalized block is used if zero
+	Cursor string            `json:"cursor"` // optional, the address to start from, empty for the first page
+	Limit  common.JSONUint64 `json:"limit"`  // optional, the maximum number of entries per page
+}
+
+type AccountWithAddress struct {
+	Address common.Address `json:"address"`
+	Account *types.Account `json:"account"`
+}
+
+type ListAccountsResult struct {
+	BlockHeight common.JSONUint64     `json:"block_height"`
+	StateRoot   common.Hash           `json:"state_root"`
+	Accounts    []*AccountWithAddress `json:"accounts"`
+	NextCursor  string                `json:"next_cursor"`
+}
+
+// ListAccounts returns a page of the accounts
+func (t *ThetaRPCService) ListAccounts(args *ListStateArgs, result *ListAccountsResult) (err error) {
+	ledgerState, start, limit, err := t.prepareListState(args)
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.StateRoot = ledgerState.Hash()
+	result.Accounts = []*AccountWithAddress{}
+	ledgerState.IterateAccounts(start, func(addr common.Address, acc *types.Account) bool {
+		if len(result.Accounts) == limit {
+			result.NextCursor = addr.Hex()
+			return false
+		}
+		result.Accounts = append(result.Accounts, &AccountWithAddress{Address: addr, Account: acc})
+		return true
+	})
+	return nil
+}
+
+type ReservedFundsOfSource struct {
+	Source        common.Address       `json:"source"`
+	ReservedFunds []types.ReservedFund `json:"reserved_funds"`
+}
+
+type ListReservedFundsResult struct {
+	BlockHeight   common.JSONUint64        `json:"block_height"`
+	StateRoot     common.Hash              `json:"state_root"`
+	ReservedFunds []*ReservedFundsOfSource `json:"reserved_funds"`
+	NextCursor    string                   `json:"next_cursor"`
+}
+
+// ListReservedFunds returns a page of the reserved funds, grouped by the source account. The limit
+// applies to the number of source accounts.
+func (t *ThetaRPCService) ListReservedFunds(args *ListStateArgs, result *ListReservedFundsResult) (err error) {
+	ledgerState, start, limit, err := t.prepareListState(args)
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.StateRoot = ledgerState.Hash()
+	result.ReservedFunds = []*ReservedFundsOfSource{}
+	ledgerState.IterateReservedFunds(start, func(source common.Address, funds []types.ReservedFund) bool {
+		if len(result.ReservedFunds) == limit {
+			result.NextCursor = source.Hex()
+			return false
+		}
+		result.ReservedFunds = append(result.ReservedFunds, &ReservedFundsOfSource{Source: source, ReservedFunds: funds})
+		return true
+	})
+	return nil
+}
+
+type ListStakeHoldersArgs struct {
+	ListStateArgs
+	Purpose uint8 `json:"purpose"` // 0: validator, 1: guardian, 2: elite edge node
+}
+
+type ListStakeHoldersResult struct {
+	BlockHeight  common.JSONUint64   `json:"block_height"`
+	StateRoot    common.Hash         `json:"state_root"`
+	StakeHolders []*core.StakeHolder `json:"stake_holders"`
+	NextCursor   string              `json:"next_cursor"`
+}
+
+// ListStakeHolders returns a page of the stake holders of the given purpose along with their stake deposits
+func (t *ThetaRPCService) ListStakeHolders(args *ListStakeHoldersArgs, result *ListStakeHoldersResult) (err error) {
+	ledgerState, start, limit, err := t.prepareListState(&args.ListStateArgs)
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.StateRoot = ledgerState.Hash()
+	result.StakeHolders = []*core.StakeHolder{}
+	return ledgerState.IterateStakeHolders(args.Purpose, start, func(holder *core.StakeHolder) bool {
+		if len(result.StakeHolders) == limit {
+			result.NextCursor = holder.Holder.Hex()
+			return false
+		}
+		result.StakeHolders = append(result.StakeHolders, holder)
+		return true
+	})
+}
+
 // ------------------------------ Utils ------------------------------
 
+// prepareListState returns the ledger state of the finalized block at the given height, along with
+// the start address and the page size of the list query
+func (t *ThetaRPCService) prepareListState(args *ListStateArgs) (ledgerState *state.StoreView, start common.Address, limit int, err error) {
+	if args.Cursor != "" {
+		if !common.IsHexAddress(args.Cursor) {
+			return nil, start, 0, errInvalidParams("Invalid cursor: %v", args.Cursor)
+		}
+		start = common.HexToAddress(args.Cursor)
+	}
+
+	limit = defaultListStateLimit
+	if args.Limit > 0 {
+		limit = int(args.Limit)
+	}
+	if limit > maxListStateLimit {
+		return nil, start, 0, errInvalidParams("The limit cannot exceed %v", maxListStateLimit)
+	}
+
+	ledgerState, err = t.getLedgerStateAtHeight(uint64(args.Height))
+	if err != nil {
+		return nil, start, 0, err
+	}
+	return ledgerState, start, limit, nil
+}
+
+// getLedgerStateAtHeight returns the ledger state as of the finalized block at the given height, or the
+// latest finalized state if the height is zero. An error is returned if the state has been pruned.
+func (t *ThetaRPCService) getLedgerStateAtHeight(height uint64) (*state.StoreView, error) {
+	if height == 0 {
+		return t.ledger.GetFinalizedSnapshot()
+	}
+
+	lastFinalizedHeight := t.consensus.GetLastFinalizedBlock().Height
+	if height > lastFinalizedHeight {
+		return nil, errStateUnavailable(ReasonHeightNotFinalized, height, "Height %v is higher than the latest finalized height %v", height, lastFinalizedHeight)
+	}
+	block := t.findFinalizedBlockByHeight(height)
+	if block == nil {
+		return nil, errNotFound("No finalized block found at height %v", height)
+	}
+
+	deliveredView, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	ledgerState := state.NewStoreView(height, block.StateHash, deliveredView.GetDB())
+	if ledgerState == nil {
+		if viper.GetBool(common.CfgStorageArchive) {
+			return nil, errStateUnavailable(ReasonStatePruned, height, "The state at height %v is missing from the archive, the data directory might be corrupted", height)
+		}
+		if viper.GetBool(common.CfgStorageStatePruningEnabled) {
+			retained := viper.GetUint64(common.CfgStorageStatePruningRetainedBlocks)
+			return nil, errStateUnavailable(ReasonStatePruned, height, "The state at height %v has been pruned, only the states of the latest %v blocks are retained", height, retained)
+		}
+		return nil, errStateUnavailable(ReasonStatePruned, height, "The state at height %v is not available", height)
+	}
+	return ledgerState, nil
+}
+
 func (t *ThetaRPCService) gatherTxs(block *core.ExtendedBlock, txs *[]interface{}, includeEthTxHashes bool) error {
 	// Parse and fulfill Txs.
 	//var tx types.Tx
@@ -1078,6 +1874,32 @@ func getTxType(tx types.Tx) byte {
 		t = TxTypeDepositStakeTxV2
 	case *types.StakeRewardDistributionTx:
 		t = TxTypeStakeRewardDistributionTx
+	case *types.MultiSigSendTx:
+		t = TxTypeMultiSigSendTx
+	case *types.VestingTransferTx:
+		t = TxTypeVestingTransferTx
+	case *types.VestingClaimTx:
+		t = TxTypeVestingClaimTx
+	case *types.BatchSendTx:
+		t = TxTypeBatchSendTx
+	case *types.SplitRuleRenewalTx:
+		t = TxTypeSplitRuleRenewalTx
+	case *types.ReserveFundTxV2:
+		t = TxTypeReserveFundTxV2
+	case *types.TokenRegistryTx:
+		t = TxTypeTokenRegistryTx
+	case *types.SmartContractTxV2:
+		t = TxTypeSmartContractTxV2
+	case *types.ValidatorKeyRotationTx:
+		t = TxTypeValidatorKeyRotationTx
+	case *types.IBCTx:
+		t = TxTypeIBCTx
+	case *types.BandwidthProofTx:
+		t = TxTypeBandwidthProofTx
+	case *types.GovProposalTx:
+		t = TxTypeGovProposalTx
+	case *types.GovVoteTx:
+		t = TxTypeGovVoteTx
 	}
 
 	return t
@@ -0,0 +1,224 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+// rateLimitPruneInterval is how often the idle clients are removed from the limiter
+const rateLimitPruneInterval = time.Minute
+
+type clientLimit struct {
+	tokens   float64
+	updated  time.Time
+	inflight int
+}
+
+// rateLimiter limits the request rate (with a token bucket) and the number of concurrent requests
+// of each client. A client is identified by its API key if it presents one, or its IP otherwise.
+type rateLimiter struct {
+	mu         *sync.Mutex
+	clients    map[string]*clientLimit
+	lastPruned time.Time
+
+	rate          float64 // tokens per second
+	burst         float64
+	maxConcurrent int
+
+	requests            metrics.Counter
+	rateRejected        metrics.Counter
+	concurrencyRejected metrics.Counter
+	inflight            metrics.Gauge
+	numClients          metrics.Gauge
+}
+
+// newRateLimiter creates the rate limiter from the config, or returns nil if rate limiting is disabled.
+func newRateLimiter(reg metrics.Registry) *rateLimiter {
+	if !viper.GetBool(common.CfgRPCRateLimitEnabled) {
+		return nil
+	}
+
+	rl := &rateLimiter{
+		mu:         &sync.Mutex{},
+		clients:    make(map[string]*clientLimit),
+		lastPruned: time.Now(),
+	}
+	rl.loadConfig()
+
+	// The metrics are always collected regardless of metrics.Enabled, as they are cheap
+	rl.requests = reg.GetOrRegister("rpc/requests", &metrics.StandardCounter{}).(metrics.Counter)
+	rl.rateRejected = reg.GetOrRegister("rpc/ratelimit/rate_rejected", &metrics.StandardCounter{}).(metrics.Counter)
+	rl.concurrencyRejected = reg.GetOrRegister("rpc/ratelimit/concurrency_rejected", &metrics.StandardCounter{}).(metrics.Counter)
+	rl.inflight = reg.GetOrRegister("rpc/ratelimit/inflight", &metrics.StandardGauge{}).(metrics.Gauge)
+	rl.numClients = reg.GetOrRegister("rpc/ratelimit/clients", &metrics.StandardGauge{}).(metrics.Gauge)
+
+	return rl
+}
+
+// reload applies the current config of the limits, the clients keep their tokens up to the new burst.
+func (rl *rateLimiter) reload() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.loadConfig()
+	for _, cl := range rl.clients {
+		if cl.tokens > rl.burst {
+			cl.tokens = rl.burst
+		}
+	}
+}
+
+// loadConfig reads the limits from the config. The caller must hold the lock once the limiter is in use.
+func (rl *rateLimiter) loadConfig() {
+	rl.rate = viper.GetFloat64(common.CfgRPCRateLimitRequestsPerSec)
+	rl.burst = float64(viper.GetInt(common.CfgRPCRateLimitBurst))
+	rl.maxConcurrent = viper.GetInt(common.CfgRPCRateLimitMaxConcurrent)
+	if rl.burst < 1 {
+		rl.burst = 1
+	}
+}
+
+// take consumes a token of the client, and returns false if the client has run out of tokens.
+func (rl *rateLimiter) take(client string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	cl := rl.getClient(client, now)
+	cl.tokens += now.Sub(cl.updated).Seconds() * rl.rate
+	if cl.tokens > rl.burst {
+		cl.tokens = rl.burst
+	}
+	cl.updated = now
+
+	if cl.tokens < 1 {
+		rl.rateRejected.Inc(1)
+		return false
+	}
+	cl.tokens--
+	rl.requests.Inc(1)
+	return true
+}
+
+// acquire reserves a concurrent request slot of the client, and returns false if there is none left.
+func (rl *rateLimiter) acquire(client string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	cl := rl.getClient(client, time.Now())
+	if rl.maxConcurrent > 0 && cl.inflight >= rl.maxConcurrent {
+		rl.concurrencyRejected.Inc(1)
+		return false
+	}
+	cl.inflight++
+	rl.inflight.Update(rl.inflight.Value() + 1)
+	return true
+}
+
+// release returns the slot reserved by acquire.
+func (rl *rateLimiter) release(client string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if cl, ok := rl.clients[client]; ok && cl.inflight > 0 {
+		cl.inflight--
+		rl.inflight.Update(rl.inflight.Value() - 1)
+	}
+}
+
+// getClient returns the limit of the client, and also removes the idle clients periodically.
+// The caller must hold the lock.
+func (rl *rateLimiter) getClient(client string, now time.Time) *clientLimit {
+	if now.Sub(rl.lastPruned) > rateLimitPruneInterval {
+		for id, cl := range rl.clients {
+			// A client is idle if its bucket would have been refilled
+			if cl.inflight == 0 && now.Sub(cl.updated).Seconds()*rl.rate >= rl.burst {
+				delete(rl.clients, id)
+			}
+		}
+		rl.lastPruned = now
+	}
+
+	cl, ok := rl.clients[client]
+	if !ok {
+		cl = &clientLimit{tokens: rl.burst, updated: now}
+		rl.clients[client] = cl
+	}
+	rl.numClients.Update(int64(len(rl.clients)))
+	return cl
+}
+
+// middleware applies the limits to the requests. A plain HTTP request consumes one token, while
+// a WebSocket connection consumes one token per call and holds a concurrent slot until it closes.
+func (rl *rateLimiter) middleware(handler http.Handler) http.Handler {
+	if rl == nil {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		client := clientID(r)
+		if !rl.acquire(client) {
+			writeRateLimitError(w, "too many concurrent requests")
+			return
+		}
+		defer rl.release(client)
+
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			prev := jsonrpc2.MethodFilterFromContext(r.Context())
+			filter := func(method string) error {
+				if prev != nil {
+					if err := prev(method); err != nil {
+						return err
+					}
+				}
+				if !rl.take(client) {
+					return newRPCError(ErrCodeLimitExceeded, ReasonRateLimited, "rate limit exceeded")
+				}
+				return nil
+			}
+			r = r.WithContext(jsonrpc2.WithMethodFilter(r.Context(), filter))
+		} else if !rl.take(client) {
+			writeRateLimitError(w, "rate limit exceeded")
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func clientID(r *http.Request) string {
+	if token := requestToken(r); token != "" {
+		return "key:" + token
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func writeRateLimitError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      interface{}     `json:"id"`
+		Error   *jsonrpc2.Error `json:"error"`
+	}{"2.0", nil, newRPCError(ErrCodeLimitExceeded, ReasonRateLimited, "%v", message)})
+}
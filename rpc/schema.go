@@ -0,0 +1,465 @@
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+	"github.com/thetatoken/theta/version"
+)
+
+//
+// The RPC schema is generated from the argument and result types of the RPC methods by reflection,
+// so it can't drift from the code. It is served as an OpenAPI document at /openapi.json, where each
+// method is a path like "/rpc#theta.GetBlock", and the same schema validates the params of the
+// calls before they are decoded, so that the malformed params are reported with the path of the
+// offending field, e.g. "params.height: expected a decimal string, got number".
+//
+
+// Schema is a JSON schema in the dialect of OpenAPI 3.0.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+const schemaRefPrefix = "#/components/schemas/"
+
+var (
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	// knownSchemas are the schemas of the types with custom JSON encodings
+	knownSchemas = map[reflect.Type]*Schema{
+		reflect.TypeOf(common.JSONUint64(0)): {Type: "string", Format: "uint64", Pattern: `^[0-9]+$`, Description: "decimal string"},
+		reflect.TypeOf(common.JSONBig{}):     {Type: "string", Format: "bigint", Pattern: `^[-+]?[0-9]+$`, Description: "decimal string"},
+		reflect.TypeOf(common.Hash{}):        {Type: "string", Format: "hash", Pattern: `^0[xX][0-9a-fA-F]{64}$`},
+		reflect.TypeOf(common.Address{}):     {Type: "string", Format: "address", Pattern: `^0[xX][0-9a-fA-F]{40}$`},
+		reflect.TypeOf(big.Int{}):            {Type: "integer", Format: "bigint"},
+	}
+)
+
+// rpcMethodSchema describes the params and the result of an RPC method.
+type rpcMethodSchema struct {
+	Args   *Schema
+	Result *Schema
+}
+
+// rpcSchema holds the schemas of the RPC methods and the named types they refer to.
+type rpcSchema struct {
+	methods    map[string]*rpcMethodSchema
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	patterns   map[string]*regexp.Regexp
+}
+
+// newRPCSchema generates the schemas of the methods of the given services, keyed by the
+// service names, following the rules of net/rpc to find the methods.
+func newRPCSchema(services map[string]interface{}) *rpcSchema {
+	s := &rpcSchema{
+		methods:    make(map[string]*rpcMethodSchema),
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+		patterns:   make(map[string]*regexp.Regexp),
+	}
+	for name, service := range services {
+		typ := reflect.TypeOf(service)
+		for i := 0; i < typ.NumMethod(); i++ {
+			method := typ.Method(i)
+			mtype := method.Type
+			if method.PkgPath != "" || mtype.NumIn() != 3 || mtype.NumOut() != 1 || mtype.Out(0) != errorType {
+				continue
+			}
+			if mtype.In(2).Kind() != reflect.Ptr {
+				continue
+			}
+			s.methods[name+"."+method.Name] = &rpcMethodSchema{
+				Args:   s.schemaOf(mtype.In(1)),
+				Result: s.schemaOf(mtype.In(2).Elem()),
+			}
+		}
+	}
+	for _, schema := range knownSchemas {
+		if schema.Pattern != "" {
+			s.patterns[schema.Pattern] = regexp.MustCompile(schema.Pattern)
+		}
+	}
+	return s
+}
+
+// schemaOf returns the schema of the given type. The structs are added to the components and
+// referred to by name, which also takes care of the recursive types.
+func (s *rpcSchema) schemaOf(typ reflect.Type) *Schema {
+	if known, ok := knownSchemas[typ]; ok {
+		copied := *known
+		return &copied
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		schema := s.schemaOf(typ.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Interface:
+		return &Schema{}
+	}
+	if typ.Implements(unmarshalerType) || reflect.PtrTo(typ).Implements(unmarshalerType) {
+		return &Schema{Description: "custom encoding"}
+	}
+	if typ.Implements(textUnmarshaler) || reflect.PtrTo(typ).Implements(textUnmarshaler) {
+		return &Schema{Type: "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer", Format: typ.Kind().String()}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: typ.Kind().String()}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 && typ.Kind() == reflect.Slice {
+			return &Schema{Type: "string", Format: "byte", Description: "base64"}
+		}
+		return &Schema{Type: "array", Items: s.schemaOf(typ.Elem())}
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return &Schema{Type: "object"}
+		}
+		return &Schema{Type: "object", AdditionalProperties: s.schemaOf(typ.Elem())}
+	case reflect.Struct:
+		return s.structSchema(typ)
+	}
+	return &Schema{}
+}
+
+func (s *rpcSchema) structSchema(typ reflect.Type) *Schema {
+	if typ.Name() == "" {
+		return s.objectSchema(typ)
+	}
+	if name, ok := s.names[typ]; ok {
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+
+	name := typ.Name()
+	if pkg := typ.PkgPath(); pkg != reflect.TypeOf(s).Elem().PkgPath() {
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	for i := 2; s.components[name] != nil; i++ {
+		name = fmt.Sprintf("%v%v", strings.TrimRight(name, "0123456789"), i)
+	}
+	s.names[typ] = name
+	s.components[name] = &Schema{} // placeholder for the recursive references
+	s.components[name] = s.objectSchema(typ)
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+// objectSchema follows the rules of encoding/json to collect the properties of a struct.
+func (s *rpcSchema) objectSchema(typ reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		ftype := field.Type
+		if field.Anonymous && name == "" {
+			if ftype.Kind() == reflect.Ptr {
+				ftype = ftype.Elem()
+			}
+			if ftype.Kind() == reflect.Struct {
+				embedded := s.objectSchema(ftype)
+				for k, v := range embedded.Properties {
+					if _, ok := schema.Properties[k]; !ok {
+						schema.Properties[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fschema := s.schemaOf(field.Type)
+		if strings.Contains(tag, ",string") && fschema.Type != "string" {
+			fschema = &Schema{Type: "string", Description: fschema.Type + " as string"}
+		}
+		schema.Properties[name] = fschema
+	}
+	return schema
+}
+
+// ------------------------------ Validation -----------------------------------
+
+// validateParams implements jsonrpc2.ParamsValidator. The params are either the args object, or
+// an array holding the args object.
+func (s *rpcSchema) validateParams(method string, params json.RawMessage) error {
+	ms, ok := s.methods[method]
+	if !ok {
+		return nil
+	}
+
+	var value interface{}
+	dec := json.NewDecoder(strings.NewReader(string(params)))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return errInvalidParams("Invalid params: %v", err)
+	}
+	path := "params"
+	if list, ok := value.([]interface{}); ok {
+		if len(list) > 1 {
+			return errInvalidParams("Invalid params: expected at most 1 element in the params array, got %v", len(list))
+		}
+		if len(list) == 0 {
+			return nil
+		}
+		value, path = list[0], "params[0]"
+	}
+	if err := s.validate(ms.Args, value, path); err != nil {
+		return errInvalidParams("Invalid params: %v", err)
+	}
+	return nil
+}
+
+func (s *rpcSchema) resolve(schema *Schema) *Schema {
+	for schema.Ref != "" {
+		schema = s.components[strings.TrimPrefix(schema.Ref, schemaRefPrefix)]
+	}
+	return schema
+}
+
+// validate checks the value decoded from JSON against the schema. The nulls are always accepted
+// since encoding/json leaves the targets untouched for them.
+func (s *rpcSchema) validate(schema *Schema, value interface{}, path string) error {
+	schema = s.resolve(schema)
+	if value == nil || schema.Type == "" {
+		return nil
+	}
+
+	switch schema.Type {
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return typeMismatch(path, "a boolean", value)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return typeMismatch(path, describeStringSchema(schema), value)
+		}
+		if schema.Pattern != "" && !s.patterns[schema.Pattern].MatchString(str) {
+			return fmt.Errorf("%v: expected %v, got %q", path, describeStringSchema(schema), str)
+		}
+	case "integer":
+		num, ok := value.(json.Number)
+		if !ok {
+			return typeMismatch(path, "an integer", value)
+		}
+		if schema.Format == "bigint" {
+			if _, ok := new(big.Int).SetString(num.String(), 10); !ok {
+				return fmt.Errorf("%v: expected an integer, got %v", path, num)
+			}
+			return nil
+		}
+		if err := checkIntegerRange(schema.Format, num.String()); err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return typeMismatch(path, "a number", value)
+		}
+	case "array":
+		list, ok := value.([]interface{})
+		if !ok {
+			return typeMismatch(path, "an array", value)
+		}
+		for i, item := range list {
+			if err := s.validate(schema.Items, item, fmt.Sprintf("%v[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return typeMismatch(path, "an object", value)
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop := lookupProperty(schema, key)
+			if prop == nil {
+				continue // unknown fields are ignored by encoding/json
+			}
+			if err := s.validate(prop, obj[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookupProperty finds the property of the key, preferring an exact match over a case-insensitive
+// match as encoding/json does.
+func lookupProperty(schema *Schema, key string) *Schema {
+	if prop, ok := schema.Properties[key]; ok {
+		return prop
+	}
+	for name, prop := range schema.Properties {
+		if strings.EqualFold(name, key) {
+			return prop
+		}
+	}
+	return schema.AdditionalProperties
+}
+
+func checkIntegerRange(format string, num string) error {
+	bits := 64
+	switch format {
+	case "int8", "uint8":
+		bits = 8
+	case "int16", "uint16":
+		bits = 16
+	case "int32", "uint32":
+		bits = 32
+	}
+	if strings.HasPrefix(format, "uint") {
+		if _, err := strconv.ParseUint(num, 10, bits); err != nil {
+			return fmt.Errorf("expected an unsigned %v-bit integer, got %v", bits, num)
+		}
+		return nil
+	}
+	if _, err := strconv.ParseInt(num, 10, bits); err != nil {
+		return fmt.Errorf("expected a signed %v-bit integer, got %v", bits, num)
+	}
+	return nil
+}
+
+func describeStringSchema(schema *Schema) string {
+	switch schema.Format {
+	case "uint64", "bigint":
+		return "a decimal string"
+	case "hash":
+		return "a 0x-prefixed 32-byte hex string"
+	case "address":
+		return "a 0x-prefixed 20-byte hex string"
+	case "byte":
+		return "a base64 string"
+	}
+	return "a string"
+}
+
+func typeMismatch(path string, expected string, value interface{}) error {
+	got := "null"
+	switch value.(type) {
+	case bool:
+		got = "boolean"
+	case string:
+		got = "string"
+	case json.Number:
+		got = "number"
+	case []interface{}:
+		got = "array"
+	case map[string]interface{}:
+		got = "object"
+	}
+	return fmt.Errorf("%v: expected %v, got %v", path, expected, got)
+}
+
+// ------------------------------ OpenAPI Document -----------------------------------
+
+// openAPIDocument renders the schema as an OpenAPI 3.0 document.
+func (s *rpcSchema) openAPIDocument() map[string]interface{} {
+	paths := make(map[string]interface{})
+	for name, ms := range s.methods {
+		request := &Schema{Type: "object", Properties: map[string]*Schema{
+			"jsonrpc": {Type: "string"},
+			"id":      {},
+			"method":  {Type: "string", Pattern: "^" + regexp.QuoteMeta(name) + "$"},
+			"params":  ms.Args,
+		}}
+		response := &Schema{Type: "object", Properties: map[string]*Schema{
+			"jsonrpc": {Type: "string"},
+			"id":      {},
+			"result":  ms.Result,
+			"error":   {Ref: schemaRefPrefix + "RPCError"},
+		}}
+		paths["/rpc#"+name] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": name,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": request}},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "JSON-RPC response",
+						"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": response}},
+					},
+				},
+			},
+		}
+	}
+
+	components := make(map[string]*Schema)
+	for name, schema := range s.components {
+		components[name] = schema
+	}
+	components["RPCError"] = &Schema{Type: "object", Properties: map[string]*Schema{
+		"code":    {Type: "integer", Format: "int"},
+		"message": {Type: "string"},
+		"data":    s.schemaOf(reflect.TypeOf(ErrorData{})),
+	}}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Theta Node RPC",
+			"version": version.Version,
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": components},
+	}
+}
+
+// ServeHTTP serves the OpenAPI document.
+func (s *rpcSchema) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.openAPIDocument())
+}
+
+// middleware makes the JSON-RPC handlers validate the params against the schema.
+func (s *rpcSchema) middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(jsonrpc2.WithParamsValidator(r.Context(), s.validateParams)))
+	})
+}
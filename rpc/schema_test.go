@@ -0,0 +1,100 @@
+package rpc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/thetatoken/theta/common"
+)
+
+type testSchemaNode struct {
+	Name     string            `json:"name"`
+	Children []*testSchemaNode `json:"children"`
+}
+
+type testSchemaArgs struct {
+	Height  common.JSONUint64 `json:"height"`
+	Hash    common.Hash       `json:"hash"`
+	Amount  *common.JSONBig   `json:"amount"`
+	Data    common.Bytes      `json:"data"`
+	Limit   uint16            `json:"limit"`
+	Root    *testSchemaNode   `json:"root"`
+	Ignored string            `json:"-"`
+}
+
+type testSchemaResult struct {
+	testSchemaNode
+	Count int `json:"count"`
+}
+
+type testSchemaService struct{}
+
+func (s *testSchemaService) Get(args *testSchemaArgs, result *testSchemaResult) error { return nil }
+
+func TestRPCSchemaGeneration(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newRPCSchema(map[string]interface{}{"test": &testSchemaService{}})
+	assert.Equal(1, len(schema.methods))
+	ms := schema.methods["test.Get"]
+	require.NotNil(t, ms)
+
+	args := schema.resolve(ms.Args)
+	assert.Equal("object", args.Type)
+	assert.Equal("uint64", args.Properties["height"].Format)
+	assert.Equal("hash", args.Properties["hash"].Format)
+	assert.Equal("byte", args.Properties["data"].Format)
+	assert.Equal(schemaRefPrefix+"testSchemaNode", args.Properties["root"].Ref)
+	assert.Nil(args.Properties["Ignored"])
+
+	node := schema.components["testSchemaNode"]
+	assert.Equal(schemaRefPrefix+"testSchemaNode", node.Properties["children"].Items.Ref)
+
+	// The embedded struct is flattened
+	result := schema.resolve(ms.Result)
+	assert.NotNil(result.Properties["name"])
+	assert.NotNil(result.Properties["count"])
+
+	doc, err := json.Marshal(schema.openAPIDocument())
+	require.Nil(t, err)
+	assert.True(json.Valid(doc))
+}
+
+func TestRPCSchemaValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := newRPCSchema(map[string]interface{}{"test": &testSchemaService{}})
+	validate := func(params string) string {
+		err := schema.validateParams("test.Get", json.RawMessage(params))
+		if err == nil {
+			return ""
+		}
+		rpcErr := &struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}{}
+		require.Nil(t, json.Unmarshal([]byte(err.Error()), rpcErr))
+		assert.Equal(ErrCodeInvalidParams, rpcErr.Code)
+		return rpcErr.Message
+	}
+
+	assert.Equal("", validate(`{"height": "100", "hash": "0x`+strings.Repeat("ab", 32)+`"}`))
+	assert.Equal("", validate(`[{"Height": "100", "amount": "-5", "limit": 65535, "unknown": 1}]`))
+	assert.Equal("", validate(`{"height": null, "root": {"children": [{"name": "a"}]}}`))
+	assert.Equal("", validate(`[]`))
+
+	assert.Equal("Invalid params: params.height: expected a decimal string, got number", validate(`{"height": 100}`))
+	assert.Equal(`Invalid params: params[0].height: expected a decimal string, got "0x10"`, validate(`[{"height": "0x10"}]`))
+	assert.Equal(`Invalid params: params.hash: expected a 0x-prefixed 32-byte hex string, got "0x12"`, validate(`{"hash": "0x12"}`))
+	assert.Equal("Invalid params: params.limit: expected an unsigned 16-bit integer, got 65536", validate(`{"limit": 65536}`))
+	assert.Equal("Invalid params: params.root.children[1].name: expected a string, got boolean", validate(`{"root": {"children": [{}, {"name": true}]}}`))
+	assert.Equal("Invalid params: expected at most 1 element in the params array, got 2", validate(`[{}, {}]`))
+	assert.Equal("Invalid params: params: expected an object, got string", validate(`"height"`))
+
+	// The methods without schemas are not validated
+	assert.Nil(schema.validateParams("test.Unknown", json.RawMessage(`"height"`)))
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// ------------------------------ Search -----------------------------------
+
+type SearchArgs struct {
+	Query     string            `json:"query"`      // e.g. "type=SendTx AND recipient=0x... AND height>1000000"
+	Cursor    common.JSONUint64 `json:"cursor"`     // the next_cursor of the previous page, 0 for the first page
+	Limit     common.JSONUint64 `json:"limit"`      // optional, 20 by default and 100 at most
+	IncludeTx bool              `json:"include_tx"` // whether to include the decoded transactions
+}
+
+type SearchResultItem struct {
+	Kind        string                 `json:"kind"` // "tx" or "event"
+	Type        string                 `json:"type"`
+	BlockHash   common.Hash            `json:"block_hash"`
+	BlockHeight common.JSONUint64      `json:"block_height"`
+	Index       common.JSONUint64      `json:"index"`
+	TxHash      *common.Hash           `json:"tx_hash,omitempty"`
+	Tx          types.Tx               `json:"tx,omitempty"`
+	Attributes  []types.EventAttribute `json:"attributes"`
+}
+
+type SearchResult struct {
+	Items      []*SearchResultItem `json:"items"`
+	NextCursor common.JSONUint64   `json:"next_cursor"` // 0 if there are no more items
+}
+
+// Search returns the transactions and ledger events of the finalized blocks that match the query,
+// in the order of the block height. The query is a conjunction of conditions joined by AND, each
+// comparing an attribute, e.g. type, height, tx.hash, sender, recipient, account or resource_id,
+// with one of the operators =, !=, <, <=, >, >=, CONTAINS and EXISTS. A page may hold fewer items
+// than the limit even if more items match, so the clients should page until next_cursor is 0.
+func (t *ThetaRPCService) Search(args *SearchArgs, result *SearchResult) (err error) {
+	if !t.chain.SearchIndexEnabled() {
+		return newRPCError(ErrCodeNotSupported, ReasonFeatureNotEnabled, "The search index is not enabled on this node")
+	}
+	query, err := blockchain.ParseQuery(args.Query)
+	if err != nil {
+		return errInvalidParams("Invalid query: %v", err)
+	}
+	limit := int(args.Limit)
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		return errInvalidParams("Can't return more than %v items at a time", maxSearchLimit)
+	}
+
+	items, nextCursor := t.chain.Search(query, uint64(args.Cursor), limit)
+	result.Items = []*SearchResultItem{}
+	for _, item := range items {
+		entry := &SearchResultItem{
+			Kind:        item.Kind,
+			Type:        item.Type,
+			BlockHash:   item.BlockHash,
+			BlockHeight: common.JSONUint64(item.Height),
+			Index:       common.JSONUint64(item.Index),
+			Attributes:  item.Attributes,
+		}
+		if item.Kind == blockchain.SearchItemTx {
+			txHash := item.TxHash
+			entry.TxHash = &txHash
+			if args.IncludeTx {
+				block, err := t.chain.FindBlock(item.BlockHash)
+				if err != nil || item.Index >= uint64(len(block.Txs)) {
+					return errInternal("Failed to load the transaction %v", item.TxHash.Hex())
+				}
+				if entry.Tx, err = types.TxFromBytes(block.Txs[item.Index]); err != nil {
+					return errInternal("Failed to decode the transaction %v: %v", item.TxHash.Hex(), err)
+				}
+			}
+		}
+		result.Items = append(result.Items, entry)
+	}
+	result.NextCursor = common.JSONUint64(nextCursor)
+	return nil
+}
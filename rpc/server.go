@@ -19,6 +19,9 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/common/metrics/prometheus"
+	"github.com/thetatoken/theta/common/resource"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/consensus"
 	"github.com/thetatoken/theta/dispatcher"
@@ -38,6 +41,17 @@ type ThetaRPCService struct {
 	chain      *blockchain.Chain
 	consensus  *consensus.ConsensusEngine
 
+	// When set, the service only answers queries and rejects transaction submissions
+	readOnly bool
+
+	// The non-essential requests, e.g. the snapshot exports, are rejected when the resources run low
+	resources *resource.Monitor
+
+	logSubscriptions       *LogSubscriptionManager
+	pendingTxSubscriptions *PendingTxSubscriptionManager
+	blockSubscriptions     *BlockSubscriptionManager
+	responseCache          *responseCache // nil if disabled
+
 	// Life cycle
 	wg      *sync.WaitGroup
 	ctx     context.Context
@@ -49,10 +63,16 @@ type ThetaRPCService struct {
 type ThetaRPCServer struct {
 	*ThetaRPCService
 
-	server   *http.Server
-	handler  *rpc.Server
-	router   *mux.Router
-	listener net.Listener
+	server      *http.Server
+	adminServer *http.Server // serves all the methods including the admin ones, nil if disabled
+	handler     *rpc.Server
+	admin       *ThetaAdminService // nil if the admin namespace is disabled
+	schema      *rpcSchema
+	rateLimiter *rateLimiter // nil if rate limiting is disabled
+	profiler    *profiler
+	latency     *rpcLatency
+	router      *mux.Router
+	listener    net.Listener
 }
 
 // NewThetaRPCServer creates a new instance of ThetaRPCServer.
@@ -60,7 +80,11 @@ func NewThetaRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatch
 	chain *blockchain.Chain, consensus *consensus.ConsensusEngine) *ThetaRPCServer {
 	t := &ThetaRPCServer{
 		ThetaRPCService: &ThetaRPCService{
-			wg: &sync.WaitGroup{},
+			wg:                     &sync.WaitGroup{},
+			logSubscriptions:       NewLogSubscriptionManager(),
+			pendingTxSubscriptions: NewPendingTxSubscriptionManager(),
+			blockSubscriptions:     NewBlockSubscriptionManager(),
+			responseCache:          newResponseCache(),
 		},
 	}
 
@@ -72,18 +96,49 @@ func NewThetaRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatch
 
 	s := rpc.NewServer()
 	s.RegisterName("theta", t.ThetaRPCService)
+	if viper.GetBool(common.CfgRPCAdminNamespaceEnabled) {
+		t.admin = newThetaAdminService(t.ThetaRPCService)
+		s.RegisterName("admin", t.admin)
+	}
+	services := map[string]interface{}{"theta": t.ThetaRPCService}
+	if t.admin != nil {
+		services["admin"] = t.admin
+	}
+	t.schema = newRPCSchema(services)
+	t.latency = newRPCLatency(metrics.ModuleRegistry("rpc"), t.schema)
 
 	t.handler = s
+	t.profiler = newProfiler()
+	if t.admin != nil {
+		t.admin.profiler = t.profiler
+	}
+	jsonrpc2.MaxBatchSize = viper.GetInt(common.CfgRPCMaxBatchSize)
 
-	t.router = mux.NewRouter()
-	t.router.Handle("/", &defaultHTTPHandler{})
-	t.router.Handle("/rpc", corsMiddleware(TimeoutHandler(jsonrpc2.HTTPHandler(s), viper.GetDuration(common.CfgRPCTimeoutSecs)*time.Second, "")))
-	t.router.Handle("/ws", websocket.Handler(func(ws *websocket.Conn) {
-		s.ServeCodec(jsonrpc2.NewServerCodec(ws, s))
-	}))
+	auth, err := newRPCAuthenticator()
+	if err != nil {
+		log.Fatalf("Failed to create RPC authenticator: %v", err)
+	}
+
+	t.rateLimiter = newRateLimiter(metrics.DefaultRegistry)
+
+	middleware, err := newHTTPMiddleware()
+	if err != nil {
+		log.Fatalf("Failed to create RPC middleware: %v", err)
+	}
+
+	// When the admin listener is enabled, the admin methods are only served by it
+	var excluded methodPatterns
+	if viper.GetString(common.CfgRPCAdminPort) != "" {
+		excluded = methodPatterns(viper.GetStringSlice(common.CfgRPCAdminMethods))
+		t.adminServer = &http.Server{
+			Handler: middleware(t.newRouter(auth, nil, nil, true)),
+		}
+	}
 
+	// The admin namespace requires credentials unless it is served by the admin listener
+	t.router = t.newRouter(auth, excluded, adminNamespace, t.adminServer == nil)
 	t.server = &http.Server{
-		Handler: t.router,
+		Handler: middleware(t.router),
 	}
 
 	logger = util.GetLoggerForModule("rpc")
@@ -91,6 +146,95 @@ func NewThetaRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatch
 	return t
 }
 
+// newRouter creates the routes of a listener. The admin listener also serves the metrics.
+func (t *ThetaRPCServer) newRouter(auth *rpcAuthenticator, excluded methodPatterns, restricted methodPatterns, admin bool) *mux.Router {
+	timeout := viper.GetDuration(common.CfgRPCTimeoutSecs) * time.Second
+	wrap := func(handler http.Handler) http.Handler {
+		return auth.authMiddleware(t.rateLimiter.middleware(handler), excluded, restricted)
+	}
+	observe := t.latency.middleware
+	validate := func(handler http.Handler) http.Handler {
+		if !viper.GetBool(common.CfgRPCValidateParams) {
+			return handler
+		}
+		return t.schema.middleware(handler)
+	}
+	cors := newCORSPolicy(viper.GetStringSlice(common.CfgRPCCORSAllowedOrigins))
+
+	router := mux.NewRouter()
+	router.Handle("/", &defaultHTTPHandler{})
+	router.Handle("/openapi.json", cors.middleware(t.schema))
+	// The probes are not authenticated or rate limited
+	router.Handle("/healthz", http.HandlerFunc(t.serveHealthz))
+	router.Handle("/readyz", http.HandlerFunc(t.serveReadyz))
+	router.Handle("/rpc", cors.middleware(wrap(observe(validate(TimeoutHandler(jsonrpc2.HTTPHandler(t.handler), timeout, ""))))))
+	router.Handle("/ws", wrap(observe(validate(websocket.Handler(func(ws *websocket.Conn) {
+		t.handler.ServeCodec(jsonrpc2.NewServerCodecContext(ws.Request().Context(), ws, t.handler))
+	})))))
+	router.Handle("/ws/logs", wrap(websocket.Handler(t.serveLogSubscription)))
+	router.Handle("/ws/pending_txs", wrap(websocket.Handler(t.servePendingTxSubscription)))
+	router.Handle("/ws/subscribe", wrap(websocket.Handler(t.serveSubscriptions)))
+	// The streaming endpoints are not wrapped by the timeout handler, which buffers the responses
+	router.Handle("/stream/blocks", cors.middleware(wrap(http.HandlerFunc(t.serveBlockStream))))
+	router.Handle("/poll/blocks", cors.middleware(wrap(http.HandlerFunc(t.servePollBlocks))))
+	if viper.GetBool(common.CfgRPCEthEnabled) {
+		router.Handle("/eth", cors.middleware(wrap(TimeoutHandler(&ethHTTPHandler{service: t.ThetaRPCService}, timeout, ""))))
+	}
+	if viper.GetBool(common.CfgRPCGraphQLEnabled) {
+		graphqlHandler, err := newGraphQLHandler(t.ThetaRPCService)
+		if err != nil {
+			log.Fatalf("Failed to create GraphQL handler: %v", err)
+		}
+		router.Handle("/graphql", cors.middleware(wrap(TimeoutHandler(graphqlHandler, timeout, ""))))
+	}
+	if admin && viper.GetBool(common.CfgRPCPrometheusEnabled) {
+		router.Handle("/metrics", prometheus.Handler(metrics.DefaultRegistry))
+	}
+	if admin && viper.GetBool(common.CfgRPCPprofEnabled) {
+		t.handlePprof(router, auth)
+	}
+	return router
+}
+
+// SetShutdownHandler sets the handler called by the admin.Shutdown method to stop the node.
+func (t *ThetaRPCServer) SetShutdownHandler(handler func()) {
+	if t.admin != nil {
+		t.admin.shutdownHandler = handler
+	}
+}
+
+// SetReloadHandler sets the handler called by the admin.ReloadConfig method to reload the config.
+func (t *ThetaRPCServer) SetReloadHandler(handler ReloadHandler) {
+	if t.admin != nil {
+		t.admin.reloadHandler = handler
+	}
+}
+
+// ReloadConfig applies the reloadable RPC settings, i.e. the rate limits, after the config is
+// reloaded. The other settings read per request or per connection need no reload.
+func (t *ThetaRPCServer) ReloadConfig() {
+	if t.rateLimiter != nil {
+		t.rateLimiter.reload()
+	}
+}
+
+// SetProfileDir sets the directory of the profiles captured by the admin.CaptureProfile method.
+func (t *ThetaRPCServer) SetProfileDir(dir string) {
+	if t.admin != nil {
+		t.admin.profileDir = dir
+	}
+}
+
+// SetReadOnly sets whether the RPC service rejects transaction submissions.
+func (t *ThetaRPCServer) SetReadOnly(readOnly bool) {
+	t.readOnly = readOnly
+}
+
+// SetResourceMonitor sets the monitor of the node resources, which pauses the snapshot exports.
+func (t *ThetaRPCServer) SetResourceMonitor(resources *resource.Monitor) {
+	t.resources = resources
+}
+
 // Start creates the main goroutine.
 func (t *ThetaRPCServer) Start(ctx context.Context) {
 	c, cancel := context.WithCancel(ctx)
@@ -102,16 +246,30 @@ func (t *ThetaRPCServer) Start(ctx context.Context) {
 
 	t.wg.Add(1)
 	go t.txCallback()
+
+	t.wg.Add(1)
+	go t.pendingTxFeed()
+
+	if viper.GetBool(common.CfgRPCGrpcEnabled) {
+		t.wg.Add(1)
+		go t.serveGRPC()
+	}
 }
 
 func (t *ThetaRPCServer) mainLoop() {
 	defer t.wg.Done()
 
 	go t.serve()
+	if t.adminServer != nil {
+		go t.serveAdmin()
+	}
 
 	<-t.ctx.Done()
 	t.stopped = true
 	t.server.Shutdown(t.ctx)
+	if t.adminServer != nil {
+		t.adminServer.Shutdown(t.ctx)
+	}
 }
 
 func (t *ThetaRPCServer) serve() {
@@ -128,22 +286,43 @@ func (t *ThetaRPCServer) serve() {
 	ll := netutil.LimitListener(l, viper.GetInt(common.CfgRPCMaxConnections))
 	t.listener = ll
 
+	certFile := viper.GetString(common.CfgRPCTLSCertFile)
+	keyFile := viper.GetString(common.CfgRPCTLSKeyFile)
+	if certFile != "" && keyFile != "" {
+		logger.Info("RPC server serving over TLS")
+		logger.Info(t.server.ServeTLS(ll, certFile, keyFile))
+		return
+	}
 	logger.Info(t.server.Serve(ll))
 }
 
-func corsMiddleware(handler http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		//Allow CORS here By * or specific origin
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "*")
+func (t *ThetaRPCServer) serveAdmin() {
+	address := viper.GetString(common.CfgRPCAdminAddress)
+	port := viper.GetString(common.CfgRPCAdminPort)
+	l, err := net.Listen("tcp", address+":"+port)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Fatal("Failed to create admin listener")
+	} else {
+		logger.WithFields(log.Fields{"address": address, "port": port}).Info("Admin RPC server started")
+	}
+	defer l.Close()
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+	logger.Info(t.adminServer.Serve(l))
+}
 
-		handler.ServeHTTP(w, r)
-	})
+// Shutdown stops accepting connections and waits for the in-flight requests to complete until
+// ctx is done, then notifies all goroutines to stop. The WebSocket connections are closed without
+// waiting for them.
+func (t *ThetaRPCServer) Shutdown(ctx context.Context) error {
+	t.stopped = true
+	err := t.server.Shutdown(ctx)
+	if t.adminServer != nil {
+		if adminErr := t.adminServer.Shutdown(ctx); err == nil {
+			err = adminErr
+		}
+	}
+	t.cancel()
+	return err
 }
 
 // Stop notifies all goroutines to stop without blocking.
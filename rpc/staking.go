@@ -0,0 +1,234 @@
+package rpc
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+// ------------------------------- GetValidatorSet -----------------------------------
+
+type GetValidatorSetArgs struct {
+	Height common.JSONUint64 `json:"height"` // optional, the latest finalized block is used if zero
+}
+
+type ValidatorInfo struct {
+	Address common.Address  `json:"address"`
+	Stake   *common.JSONBig `json:"stake"`
+}
+
+type GetValidatorSetResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	TotalStake  *common.JSONBig   `json:"total_stake"`
+	Validators  []ValidatorInfo   `json:"validators"`
+}
+
+// GetValidatorSet returns the validator set in effect for the finalized block at the given height
+func (t *ThetaRPCService) GetValidatorSet(args *GetValidatorSetArgs, result *GetValidatorSetResult) (err error) {
+	height := uint64(args.Height)
+	if height == 0 {
+		height = t.consensus.GetLastFinalizedBlock().Height
+	}
+
+	// The validator manager panics if the state is missing, so check that the state is available first
+	if _, err := t.getLedgerStateAtHeight(height); err != nil {
+		return err
+	}
+	block := t.findFinalizedBlockByHeight(height)
+	if block == nil {
+		return errNotFound("No finalized block found at height %v", height)
+	}
+
+	vs := t.consensus.GetValidatorManager().GetValidatorSet(block.Hash())
+	result.BlockHeight = common.JSONUint64(height)
+	result.BlockHash = block.Hash()
+	result.TotalStake = (*common.JSONBig)(vs.TotalStake())
+	result.Validators = []ValidatorInfo{}
+	for _, v := range vs.Validators() {
+		result.Validators = append(result.Validators, ValidatorInfo{
+			Address: v.Address,
+			Stake:   (*common.JSONBig)(v.Stake),
+		})
+	}
+	return nil
+}
+
+// ------------------------------- GetStakeHolder -----------------------------------
+
+type GetStakeHolderArgs struct {
+	Holder  string            `json:"holder"`
+	Purpose uint8             `json:"purpose"` // 0: validator, 1: guardian, 2: elite edge node
+	Height  common.JSONUint64 `json:"height"`  // optional, the latest finalized block is used if zero
+}
+
+type GetStakeHolderResult struct {
+	BlockHeight      common.JSONUint64 `json:"block_height"`
+	Holder           common.Address    `json:"holder"`
+	Purpose          uint8             `json:"purpose"`
+	TotalStake       *common.JSONBig   `json:"total_stake"`       // excluding the withdrawn stakes
+	WithdrawingStake *common.JSONBig   `json:"withdrawing_stake"` // withdrawn but not yet returned
+	Delegators       []*core.Stake     `json:"delegators"`
+}
+
+// GetStakeHolder returns the stake of a validator candidate, guardian or elite edge node, along
+// with the stakes deposited by each of its delegators
+func (t *ThetaRPCService) GetStakeHolder(args *GetStakeHolderArgs, result *GetStakeHolderResult) (err error) {
+	if !common.IsHexAddress(args.Holder) {
+		return errInvalidParams("Invalid holder address: %v", args.Holder)
+	}
+	holderAddr := common.HexToAddress(args.Holder)
+
+	ledgerState, err := t.getLedgerStateAtHeight(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+
+	var holder *core.StakeHolder
+	err = ledgerState.IterateStakeHolders(args.Purpose, holderAddr, func(sh *core.StakeHolder) bool {
+		if sh.Holder == holderAddr {
+			holder = sh
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	if holder == nil {
+		return errNotFound("Stake holder %v not found for purpose %v", holderAddr.Hex(), args.Purpose)
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.Holder = holder.Holder
+	result.Purpose = args.Purpose
+	result.TotalStake = (*common.JSONBig)(holder.TotalStake())
+	result.WithdrawingStake = (*common.JSONBig)(withdrawingStake(holder))
+	result.Delegators = holder.Stakes
+	return nil
+}
+
+// ------------------------------- GetPendingStakeWithdrawals -----------------------------------
+
+type GetPendingStakeWithdrawalsArgs struct {
+	Height common.JSONUint64 `json:"height"` // optional, the latest finalized block is used if zero
+	Source string            `json:"source"` // optional, only the withdrawals of the source are returned if set
+}
+
+type PendingStakeWithdrawal struct {
+	Purpose         uint8             `json:"purpose"`
+	Holder          common.Address    `json:"holder"`
+	Source          common.Address    `json:"source"`
+	Amount          *common.JSONBig   `json:"amount"`
+	ReturnHeight    common.JSONUint64 `json:"return_height"`
+	BlocksRemaining common.JSONUint64 `json:"blocks_remaining"`
+}
+
+type GetPendingStakeWithdrawalsResult struct {
+	BlockHeight common.JSONUint64        `json:"block_height"`
+	Withdrawals []PendingStakeWithdrawal `json:"withdrawals"`
+}
+
+// GetPendingStakeWithdrawals returns the stakes that have been withdrawn but not yet returned to
+// their sources, across the validator candidates, guardians and elite edge nodes
+func (t *ThetaRPCService) GetPendingStakeWithdrawals(args *GetPendingStakeWithdrawalsArgs, result *GetPendingStakeWithdrawalsResult) (err error) {
+	var source *common.Address
+	if args.Source != "" {
+		if !common.IsHexAddress(args.Source) {
+			return errInvalidParams("Invalid source address: %v", args.Source)
+		}
+		addr := common.HexToAddress(args.Source)
+		source = &addr
+	}
+
+	ledgerState, err := t.getLedgerStateAtHeight(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+	height := ledgerState.Height()
+
+	result.BlockHeight = common.JSONUint64(height)
+	result.Withdrawals = []PendingStakeWithdrawal{}
+	for _, purpose := range []uint8{core.StakeForValidator, core.StakeForGuardian, core.StakeForEliteEdgeNode} {
+		err = ledgerState.IterateStakeHolders(purpose, common.Address{}, func(holder *core.StakeHolder) bool {
+			for _, stake := range holder.Stakes {
+				if !stake.Withdrawn || source != nil && stake.Source != *source {
+					continue
+				}
+				remaining := uint64(0)
+				if stake.ReturnHeight > height {
+					remaining = stake.ReturnHeight - height
+				}
+				result.Withdrawals = append(result.Withdrawals, PendingStakeWithdrawal{
+					Purpose:         purpose,
+					Holder:          holder.Holder,
+					Source:          stake.Source,
+					Amount:          (*common.JSONBig)(stake.Amount),
+					ReturnHeight:    common.JSONUint64(stake.ReturnHeight),
+					BlocksRemaining: common.JSONUint64(remaining),
+				})
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ------------------------------- GetGuardianPoolSummary -----------------------------------
+
+type GetGuardianPoolSummaryArgs struct {
+	Height common.JSONUint64 `json:"height"` // optional, the latest finalized block is used if zero
+}
+
+type GetGuardianPoolSummaryResult struct {
+	BlockHeight           common.JSONUint64 `json:"block_height"`
+	NumGuardians          common.JSONUint64 `json:"num_guardians"`
+	NumGuardiansWithStake common.JSONUint64 `json:"num_guardians_with_stake"`
+	NumDelegators         common.JSONUint64 `json:"num_delegators"` // the number of distinct stake sources
+	TotalStake            *common.JSONBig   `json:"total_stake"`
+	WithdrawingStake      *common.JSONBig   `json:"withdrawing_stake"`
+	GuardianPoolHash      common.Hash       `json:"guardian_pool_hash"`
+}
+
+// GetGuardianPoolSummary returns the aggregated figures of the guardian candidate pool
+func (t *ThetaRPCService) GetGuardianPoolSummary(args *GetGuardianPoolSummaryArgs, result *GetGuardianPoolSummaryResult) (err error) {
+	ledgerState, err := t.getLedgerStateAtHeight(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+
+	gcp := ledgerState.GetGuardianCandidatePool()
+	totalStake := new(big.Int)
+	totalWithdrawing := new(big.Int)
+	sources := make(map[common.Address]bool)
+	for _, g := range gcp.SortedGuardians {
+		totalStake.Add(totalStake, g.TotalStake())
+		totalWithdrawing.Add(totalWithdrawing, withdrawingStake(g.StakeHolder))
+		for _, stake := range g.Stakes {
+			sources[stake.Source] = true
+		}
+	}
+
+	result.BlockHeight = common.JSONUint64(ledgerState.Height())
+	result.NumGuardians = common.JSONUint64(gcp.Len())
+	result.NumGuardiansWithStake = common.JSONUint64(gcp.WithStake().Len())
+	result.NumDelegators = common.JSONUint64(len(sources))
+	result.TotalStake = (*common.JSONBig)(totalStake)
+	result.WithdrawingStake = (*common.JSONBig)(totalWithdrawing)
+	result.GuardianPoolHash = gcp.Hash()
+	return nil
+}
+
+// withdrawingStake returns the total amount of the stakes withdrawn from the holder but not yet returned
+func withdrawingStake(holder *core.StakeHolder) *big.Int {
+	total := new(big.Int)
+	for _, stake := range holder.Stakes {
+		if stake.Withdrawn {
+			total.Add(total, stake.Amount)
+		}
+	}
+	return total
+}
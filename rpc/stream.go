@@ -0,0 +1,253 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+//
+// The block stream pushes the finalized blocks over plain HTTP, for the clients that can't use the
+// WebSocket endpoints. The /stream/blocks endpoint serves Server-Sent Events, and the /poll/blocks
+// endpoint serves long-polling requests. Both are resumable: the ID of an SSE event and the
+// cursor of a poll is the height of the last block delivered, so a client reconnecting with the
+// Last-Event-ID header or the cursor parameter receives the blocks finalized in the meantime.
+//
+
+const (
+	maxStreamCatchUpBlocks = 1000 // the max number of missed blocks a client can catch up with on resume
+	maxPollBlocks          = 100
+	defaultPollTimeout     = 30 * time.Second
+	maxPollTimeout         = 60 * time.Second
+)
+
+// StreamBlock is a finalized block delivered by the block stream, along with the ledger events it
+// emitted if requested.
+type StreamBlock struct {
+	*BlockEntry
+	Events []*EventEntry `json:"events,omitempty"`
+}
+
+// PollBlocksResult is the response of the long-polling endpoint.
+type PollBlocksResult struct {
+	Blocks []*StreamBlock    `json:"blocks"`
+	Cursor common.JSONUint64 `json:"cursor"` // the cursor of the next poll
+}
+
+// streamCursor returns the height after which the blocks should be delivered. A client without a
+// cursor starts from the next finalized block.
+func (t *ThetaRPCService) streamCursor(r *http.Request) (uint64, error) {
+	cursor := r.Header.Get("Last-Event-ID")
+	if cursor == "" {
+		cursor = r.URL.Query().Get("cursor")
+	}
+	lastFinalized := t.consensus.GetLastFinalizedBlock().Height
+	if cursor == "" {
+		return lastFinalized, nil
+	}
+
+	height, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor: %v", cursor)
+	}
+	if height > lastFinalized {
+		height = lastFinalized
+	}
+	if lastFinalized-height > maxStreamCatchUpBlocks {
+		return 0, fmt.Errorf("cursor is too old, at most %v blocks can be caught up with", maxStreamCatchUpBlocks)
+	}
+	return height, nil
+}
+
+func (t *ThetaRPCService) newStreamBlock(block *core.Block, withEvents bool) *StreamBlock {
+	sb := &StreamBlock{BlockEntry: newBlockEntry(block)}
+	if withEvents {
+		events, _ := t.chain.FindBlockEvents(block.Hash())
+		for _, event := range events {
+			sb.Events = append(sb.Events, &EventEntry{
+				Event:       event,
+				BlockHash:   block.Hash(),
+				BlockHeight: common.JSONUint64(block.Height),
+			})
+		}
+	}
+	return sb
+}
+
+// finalizedBlocksAfter returns up to limit finalized blocks above the given height.
+func (t *ThetaRPCService) finalizedBlocksAfter(height uint64, limit int, withEvents bool) []*StreamBlock {
+	blocks := []*StreamBlock{}
+	lastFinalized := t.consensus.GetLastFinalizedBlock().Height
+	for h := height + 1; h <= lastFinalized && len(blocks) < limit; h++ {
+		block := t.findFinalizedBlockByHeight(h)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, t.newStreamBlock(block.Block, withEvents))
+	}
+	return blocks
+}
+
+// ------------------------------ Server-Sent Events -----------------------------------
+
+// serveBlockStream streams the finalized blocks as Server-Sent Events named "block".
+func (t *ThetaRPCService) serveBlockStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	cursor, err := t.streamCursor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	withEvents := r.URL.Query().Get("events") == "true"
+
+	// Subscribe before catching up, so that no block is missed in between
+	sub := t.blockSubscriptions.subscribe()
+	defer t.blockSubscriptions.unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(sb *StreamBlock) bool {
+		data, err := json.Marshal(sb)
+		if err != nil {
+			logger.Errorf("Failed to encode block %v: %v", sb.Hash.Hex(), err)
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: block\ndata: %s\n\n", sb.Height, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		cursor = uint64(sb.Height)
+		return true
+	}
+	// catchUp delivers the finalized blocks the client hasn't received, e.g. the blocks finalized
+	// before the subscription, or the blocks skipped by the publisher
+	catchUp := func(height uint64) bool {
+		for cursor < height {
+			blocks := t.finalizedBlocksAfter(cursor, maxPollBlocks, withEvents)
+			if len(blocks) == 0 {
+				return true
+			}
+			for _, sb := range blocks {
+				if !send(sb) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	if !catchUp(t.consensus.GetLastFinalizedBlock().Height) {
+		return
+	}
+
+	heartbeatPeriod := viper.GetDuration(common.CfgRPCWSHeartbeatIntervalSecs) * time.Second
+	if heartbeatPeriod < minSubscriptionHeartbeatPeriod {
+		heartbeatPeriod = minSubscriptionHeartbeatPeriod
+	}
+	heartbeat := time.NewTicker(heartbeatPeriod)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case entry, ok := <-sub.blockC:
+			if !ok {
+				// Dropped by the subscription manager, the client can resume with the last event ID
+				fmt.Fprintf(w, "event: error\ndata: subscription dropped, the client can't keep up\n\n")
+				flusher.Flush()
+				return
+			}
+			height := uint64(entry.Height)
+			if height <= cursor {
+				continue
+			}
+			if !catchUp(height - 1) {
+				return
+			}
+			if !send(t.newStreamBlock(entry.block, withEvents)) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprintf(w, ": heartbeat %d\n\n", time.Now().Unix()); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// ------------------------------ Long Polling -----------------------------------
+
+// servePollBlocks returns the finalized blocks above the cursor. If there is none, it waits until
+// a block is finalized or the timeout, given in seconds by the timeout parameter, expires.
+func (t *ThetaRPCService) servePollBlocks(w http.ResponseWriter, r *http.Request) {
+	cursor, err := t.streamCursor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	withEvents := r.URL.Query().Get("events") == "true"
+	timeout := defaultPollTimeout
+	if s := r.URL.Query().Get("timeout"); s != "" {
+		secs, err := strconv.Atoi(s)
+		if err != nil || secs < 0 {
+			http.Error(w, "invalid timeout: "+s, http.StatusBadRequest)
+			return
+		}
+		timeout = time.Duration(secs) * time.Second
+		if timeout > maxPollTimeout {
+			timeout = maxPollTimeout
+		}
+	}
+
+	sub := t.blockSubscriptions.subscribe()
+	defer t.blockSubscriptions.unsubscribe(sub)
+
+	blocks := t.finalizedBlocksAfter(cursor, maxPollBlocks, withEvents)
+	if len(blocks) == 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+	wait:
+		for {
+			select {
+			case entry, ok := <-sub.blockC:
+				if !ok || uint64(entry.Height) > cursor {
+					break wait
+				}
+			case <-timer.C:
+				break wait
+			case <-r.Context().Done():
+				return
+			case <-t.ctx.Done():
+				return
+			}
+		}
+		blocks = t.finalizedBlocksAfter(cursor, maxPollBlocks, withEvents)
+	}
+
+	result := &PollBlocksResult{Blocks: blocks, Cursor: common.JSONUint64(cursor)}
+	if len(blocks) > 0 {
+		result.Cursor = blocks[len(blocks)-1].Height
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,388 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/websocket"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+// Topics of the WebSocket subscriptions
+const (
+	SubscriptionTopicNewBlocks  = "new_blocks"
+	SubscriptionTopicPendingTxs = "pending_txs"
+	SubscriptionTopicLogs       = "logs"
+)
+
+const (
+	blockSubscriptionBufferSize    = 64
+	subscriptionOutputBufferSize   = 256
+	subscriptionWriteTimeout       = 10 * time.Second
+	minSubscriptionHeartbeatPeriod = 1 * time.Second
+)
+
+// ------------------------------ Block Subscriptions -----------------------------------
+
+// BlockEntry is a newly finalized block pushed to the subscribers.
+type BlockEntry struct {
+	Hash      common.Hash       `json:"hash"`
+	Height    common.JSONUint64 `json:"height"`
+	Parent    common.Hash       `json:"parent"`
+	StateHash common.Hash       `json:"state_hash"`
+	Timestamp *common.JSONBig   `json:"timestamp"`
+	Proposer  common.Address    `json:"proposer"`
+	TxHashes  []common.Hash     `json:"transaction_hashes"`
+
+	block *core.Block
+}
+
+func newBlockEntry(block *core.Block) *BlockEntry {
+	entry := &BlockEntry{
+		Hash:      block.Hash(),
+		Height:    common.JSONUint64(block.Height),
+		Parent:    block.Parent,
+		StateHash: block.StateHash,
+		Timestamp: (*common.JSONBig)(block.Timestamp),
+		Proposer:  block.Proposer,
+		TxHashes:  []common.Hash{},
+		block:     block,
+	}
+	for _, tx := range block.Txs {
+		entry.TxHashes = append(entry.TxHashes, crypto.Keccak256Hash(tx))
+	}
+	return entry
+}
+
+type blockSubscription struct {
+	blockC chan *BlockEntry
+}
+
+// BlockSubscriptionManager pushes the newly finalized blocks to the subscribers.
+type BlockSubscriptionManager struct {
+	mu            *sync.Mutex
+	subscriptions map[*blockSubscription]struct{}
+}
+
+// NewBlockSubscriptionManager creates a new instance of BlockSubscriptionManager.
+func NewBlockSubscriptionManager() *BlockSubscriptionManager {
+	return &BlockSubscriptionManager{
+		mu:            &sync.Mutex{},
+		subscriptions: make(map[*blockSubscription]struct{}),
+	}
+}
+
+func (m *BlockSubscriptionManager) subscribe() *blockSubscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &blockSubscription{
+		blockC: make(chan *BlockEntry, blockSubscriptionBufferSize),
+	}
+	m.subscriptions[sub] = struct{}{}
+	return sub
+}
+
+func (m *BlockSubscriptionManager) unsubscribe(sub *blockSubscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subscriptions[sub]; ok {
+		delete(m.subscriptions, sub)
+		close(sub.blockC)
+	}
+}
+
+func (m *BlockSubscriptionManager) hasSubscriptions() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.subscriptions) > 0
+}
+
+// publish delivers the block to each subscriber. A subscriber that can't keep up is dropped
+// rather than blocking the publisher.
+func (m *BlockSubscriptionManager) publish(entry *BlockEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for sub := range m.subscriptions {
+		select {
+		case sub.blockC <- entry:
+		default:
+			logger.Warnf("Block subscriber is too slow, dropping the subscription")
+			delete(m.subscriptions, sub)
+			close(sub.blockC)
+		}
+	}
+}
+
+func (t *ThetaRPCService) publishBlock(block *core.Block) {
+	if !t.blockSubscriptions.hasSubscriptions() {
+		return
+	}
+	t.blockSubscriptions.publish(newBlockEntry(block))
+}
+
+// ------------------------------ Subscription Endpoint -----------------------------------
+
+// SubscriptionRequest is a request sent by the client over the subscription endpoint. The method
+// is one of "subscribe", "unsubscribe" and "ping". To subscribe, the client specifies the topic,
+// along with a LogFilter or a PendingTxFilter for the logs and the pending transactions topics
+// respectively. To unsubscribe, the client specifies the ID of the subscription.
+type SubscriptionRequest struct {
+	ID           json.RawMessage   `json:"id"`
+	Method       string            `json:"method"`
+	Topic        string            `json:"topic"`
+	Filter       json.RawMessage   `json:"filter"`
+	Subscription common.JSONUint64 `json:"subscription"`
+}
+
+// SubscriptionResponse is the response to a SubscriptionRequest.
+type SubscriptionResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// SubscriptionNotification carries an item published to a subscription. If the subscription is
+// dropped by the node, e.g. because the client can't keep up, a final notification with the error
+// set is sent.
+type SubscriptionNotification struct {
+	Subscription common.JSONUint64 `json:"subscription"`
+	Topic        string            `json:"topic"`
+	Result       interface{}       `json:"result,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// SubscriptionHeartbeat is sent periodically to keep the idle connections alive.
+type SubscriptionHeartbeat struct {
+	Heartbeat common.JSONUint64 `json:"heartbeat"` // Unix time of the node
+}
+
+// subscriptionConn multiplexes the subscriptions of a single WebSocket connection. All the writes
+// to the connection go through the output channel, so that they are serialized in one goroutine.
+type subscriptionConn struct {
+	t  *ThetaRPCService
+	ws *websocket.Conn
+
+	mu               *sync.Mutex
+	nextID           uint64
+	subscriptions    map[uint64]func() // subscription ID -> cancel function
+	maxSubscriptions int
+
+	outC chan interface{}
+	done chan struct{}
+}
+
+// serveSubscriptions handles the WebSocket subscription endpoint. Unlike the single purpose log and
+// pending transaction endpoints, a client can hold multiple subscriptions of different topics on
+// one connection, up to the configured limit.
+func (t *ThetaRPCService) serveSubscriptions(ws *websocket.Conn) {
+	defer ws.Close()
+
+	c := &subscriptionConn{
+		t:                t,
+		ws:               ws,
+		mu:               &sync.Mutex{},
+		subscriptions:    make(map[uint64]func()),
+		maxSubscriptions: viper.GetInt(common.CfgRPCWSMaxSubscriptionsPerConn),
+		outC:             make(chan interface{}, subscriptionOutputBufferSize),
+		done:             make(chan struct{}),
+	}
+	defer c.cancelAll()
+	defer close(c.done)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		c.readLoop()
+	}()
+
+	heartbeatPeriod := viper.GetDuration(common.CfgRPCWSHeartbeatIntervalSecs) * time.Second
+	if heartbeatPeriod < minSubscriptionHeartbeatPeriod {
+		heartbeatPeriod = minSubscriptionHeartbeatPeriod
+	}
+	heartbeat := time.NewTicker(heartbeatPeriod)
+	defer heartbeat.Stop()
+
+	for {
+		var msg interface{}
+		select {
+		case msg = <-c.outC:
+		case now := <-heartbeat.C:
+			msg = &SubscriptionHeartbeat{Heartbeat: common.JSONUint64(now.Unix())}
+		case <-closed:
+			return
+		case <-t.ctx.Done():
+			return
+		}
+		ws.SetWriteDeadline(time.Now().Add(subscriptionWriteTimeout))
+		if err := websocket.JSON.Send(ws, msg); err != nil {
+			logger.Debugf("Failed to write to subscriber: %v", err)
+			return
+		}
+	}
+}
+
+func (c *subscriptionConn) readLoop() {
+	for {
+		req := &SubscriptionRequest{}
+		if err := websocket.JSON.Receive(c.ws, req); err != nil {
+			return
+		}
+
+		resp := &SubscriptionResponse{ID: req.ID}
+		switch req.Method {
+		case "subscribe":
+			id, err := c.subscribe(req.Topic, req.Filter)
+			if err != nil {
+				resp.Error = err.Error()
+			} else {
+				resp.Result = common.JSONUint64(id)
+			}
+		case "unsubscribe":
+			resp.Result = c.unsubscribe(uint64(req.Subscription))
+		case "ping":
+			resp.Result = "pong"
+		default:
+			resp.Error = fmt.Sprintf("unknown method %v", req.Method)
+		}
+		if !c.send(resp) {
+			return
+		}
+	}
+}
+
+// send queues the message for writing, it returns false if the connection is closed.
+func (c *subscriptionConn) send(msg interface{}) bool {
+	select {
+	case c.outC <- msg:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+func (c *subscriptionConn) subscribe(topic string, rawFilter json.RawMessage) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxSubscriptions > 0 && len(c.subscriptions) >= c.maxSubscriptions {
+		return 0, fmt.Errorf("too many subscriptions, at most %v are allowed per connection", c.maxSubscriptions)
+	}
+
+	t := c.t
+	var cancel func()
+	var next func() (interface{}, bool)
+	switch topic {
+	case SubscriptionTopicNewBlocks:
+		sub := t.blockSubscriptions.subscribe()
+		cancel = func() { t.blockSubscriptions.unsubscribe(sub) }
+		next = func() (interface{}, bool) {
+			entry, ok := <-sub.blockC
+			return entry, ok
+		}
+	case SubscriptionTopicPendingTxs:
+		filter := &PendingTxFilter{}
+		if err := unmarshalSubscriptionFilter(rawFilter, filter); err != nil {
+			return 0, err
+		}
+		sub := t.pendingTxSubscriptions.subscribe(filter)
+		cancel = func() { t.pendingTxSubscriptions.unsubscribe(sub) }
+		next = func() (interface{}, bool) {
+			entry, ok := <-sub.txC
+			return entry, ok
+		}
+	case SubscriptionTopicLogs:
+		filter := &LogFilter{}
+		if err := unmarshalSubscriptionFilter(rawFilter, filter); err != nil {
+			return 0, err
+		}
+		sub := t.logSubscriptions.subscribe(filter)
+		cancel = func() { t.logSubscriptions.unsubscribe(sub) }
+		next = func() (interface{}, bool) {
+			entry, ok := <-sub.logC
+			return entry, ok
+		}
+	default:
+		return 0, fmt.Errorf("unknown topic %v", topic)
+	}
+
+	c.nextID++
+	id := c.nextID
+	c.subscriptions[id] = cancel
+	go c.forward(id, topic, next)
+
+	return id, nil
+}
+
+func unmarshalSubscriptionFilter(rawFilter json.RawMessage, filter interface{}) error {
+	if len(rawFilter) == 0 || string(rawFilter) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(rawFilter, filter); err != nil {
+		return fmt.Errorf("invalid filter: %v", err)
+	}
+	return nil
+}
+
+// forward pushes the items of the subscription to the client until the subscription is cancelled
+// by the client, or dropped by the subscription manager.
+func (c *subscriptionConn) forward(id uint64, topic string, next func() (interface{}, bool)) {
+	for {
+		item, ok := next()
+		if !ok {
+			break
+		}
+		if !c.send(&SubscriptionNotification{Subscription: common.JSONUint64(id), Topic: topic, Result: item}) {
+			c.unsubscribe(id)
+			return
+		}
+	}
+
+	// The channel is also closed when the client unsubscribes, only report the dropped subscriptions
+	c.mu.Lock()
+	_, dropped := c.subscriptions[id]
+	delete(c.subscriptions, id)
+	c.mu.Unlock()
+	if dropped {
+		c.send(&SubscriptionNotification{
+			Subscription: common.JSONUint64(id),
+			Topic:        topic,
+			Error:        "subscription dropped, the client can't keep up",
+		})
+	}
+}
+
+func (c *subscriptionConn) unsubscribe(id uint64) bool {
+	c.mu.Lock()
+	cancel, ok := c.subscriptions[id]
+	delete(c.subscriptions, id)
+	c.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func (c *subscriptionConn) cancelAll() {
+	c.mu.Lock()
+	cancels := make([]func(), 0, len(c.subscriptions))
+	for id, cancel := range c.subscriptions {
+		cancels = append(cancels, cancel)
+		delete(c.subscriptions, id)
+	}
+	c.mu.Unlock()
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
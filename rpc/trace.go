@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"encoding/hex"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/vm"
+)
+
+const (
+	// TracerStructLogger traces the execution opcode by opcode
+	TracerStructLogger = "structLogger"
+
+	// TracerCallTracer traces the execution call by call
+	TracerCallTracer = "callTracer"
+)
+
+// ------------------------------- TraceTransaction -----------------------------------
+
+type TraceTransactionArgs struct {
+	Hash           string            `json:"hash"`
+	Tracer         string            `json:"tracer"` // "structLogger" (default) or "callTracer"
+	DisableStack   bool              `json:"disable_stack"`
+	DisableMemory  bool              `json:"disable_memory"`
+	DisableStorage bool              `json:"disable_storage"`
+	Limit          common.JSONUint64 `json:"limit"` // maximum number of opcode-level logs, 0 means unlimited
+}
+
+type TraceTransactionResult struct {
+	TxHash      string            `json:"hash"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	Failed      bool              `json:"failed"`
+	ReturnValue string            `json:"return_value"`
+	VmError     string            `json:"vm_error"`
+	StructLogs  []vm.StructLog    `json:"struct_logs,omitempty"`
+	Calls       *vm.CallFrame     `json:"calls,omitempty"`
+}
+
+// TraceTransaction re-executes a committed smart contract transaction and returns the
+// opcode-level or call-level trace of its execution.
+func (t *ThetaRPCService) TraceTransaction(args *TraceTransactionArgs, result *TraceTransactionResult) (err error) {
+	if args.Hash == "" {
+		return errInvalidParams("Transaction hash must be specified")
+	}
+	hash := common.HexToHash(args.Hash)
+	result.TxHash = hash.Hex()
+
+	switch args.Tracer {
+	case "", TracerStructLogger:
+		structLogger := vm.NewStructLogger(&vm.LogConfig{
+			DisableStack:   args.DisableStack,
+			DisableMemory:  args.DisableMemory,
+			DisableStorage: args.DisableStorage,
+			Limit:          int(args.Limit),
+		})
+		_, block, err := t.ledger.TraceTransaction(hash, structLogger)
+		if err != nil {
+			return err
+		}
+		result.BlockHash = block.Hash()
+		result.BlockHeight = common.JSONUint64(block.Height)
+		result.ReturnValue = hex.EncodeToString(structLogger.Output())
+		if structLogger.Error() != nil {
+			result.Failed = true
+			result.VmError = structLogger.Error().Error()
+		}
+		result.StructLogs = structLogger.StructLogs()
+	case TracerCallTracer:
+		tracer := vm.NewCallTracer()
+		_, block, err := t.ledger.TraceTransaction(hash, tracer)
+		if err != nil {
+			return err
+		}
+		calls, err := tracer.Result()
+		if err != nil {
+			return err
+		}
+		result.BlockHash = block.Hash()
+		result.BlockHeight = common.JSONUint64(block.Height)
+		result.ReturnValue = hex.EncodeToString(calls.Output)
+		if calls.Error != "" {
+			result.Failed = true
+			result.VmError = calls.Error
+		}
+		result.Calls = calls
+	default:
+		return errInvalidParams("Unsupported tracer: %v", args.Tracer)
+	}
+
+	return nil
+}
@@ -2,12 +2,11 @@ package rpc
 
 import (
 	"encoding/hex"
-	"errors"
-	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/hexutil"
@@ -19,6 +18,8 @@ import (
 
 const txTimeout = 60 * time.Second
 
+var errReadOnlyNode = newRPCError(ErrCodeNotSupported, ReasonReadOnly, "The node is running in read-only mode and does not accept transactions")
+
 type Callback struct {
 	txHash   string
 	created  time.Time
@@ -112,6 +113,9 @@ func (t *ThetaRPCService) txCallback() {
 				}
 			}
 
+			t.publishBlock(block)
+			t.publishLogs(block)
+
 			logger.Infof("Done processing finalized block, height=%v", block.Height)
 		case <-timer.C:
 			logger.Debugf("txCallbackManager.Trim()")
@@ -126,7 +130,8 @@ func (t *ThetaRPCService) txCallback() {
 // ------------------------------- BroadcastRawTransaction -----------------------------------
 
 type BroadcastRawTransactionArgs struct {
-	TxBytes string `json:"tx_bytes"`
+	TxBytes  string `json:"tx_bytes"`
+	Priority bool   `json:"priority"` // optional, requires the node to enable the priority local transactions
 }
 
 type BroadcastRawTransactionResult struct {
@@ -136,9 +141,13 @@ type BroadcastRawTransactionResult struct {
 
 func (t *ThetaRPCService) BroadcastRawTransaction(
 	args *BroadcastRawTransactionArgs, result *BroadcastRawTransactionResult) (err error) {
+	if t.readOnly {
+		return errReadOnlyNode
+	}
+
 	txBytes, err := decodeTxHexBytes(args.TxBytes)
 	if err != nil {
-		return err
+		return errInvalidParams("Invalid tx_bytes: %v", err)
 	}
 
 	hash := crypto.Keccak256Hash(txBytes)
@@ -146,13 +155,13 @@ func (t *ThetaRPCService) BroadcastRawTransaction(
 
 	logger.Infof("Prepare to broadcast raw transaction (sync): %v, hash: %v", hex.EncodeToString(txBytes), hash.Hex())
 
-	err = t.mempool.InsertTransaction(txBytes)
+	err = t.mempool.InsertLocalTransaction(txBytes, args.Priority)
 	if err == nil || err == mempool.FastsyncSkipTxError {
 		t.mempool.BroadcastTx(txBytes) // still broadcast the transactions received locally during the fastsync mode
 		logger.Infof("Broadcasted raw transaction (sync): %v, hash: %v", hex.EncodeToString(txBytes), hash.Hex())
 	} else {
 		logger.Warnf("Failed to broadcast raw transaction (sync): %v, hash: %v, err: %v", hex.EncodeToString(txBytes), hash.Hex(), err)
-		return err
+		return errTxRejected(err)
 	}
 
 	finalized := make(chan *core.Block)
@@ -170,19 +179,20 @@ func (t *ThetaRPCService) BroadcastRawTransaction(
 	case block := <-finalized:
 		if block == nil {
 			logger.Infof("Tx callback returns nil, txHash=%v", result.TxHash)
-			return errors.New("Internal server error")
+			return errInternal("Internal server error")
 		}
 		result.Block = block.BlockHeader
 		return nil
 	case <-timeout.C:
-		return errors.New("Timed out waiting for transaction to be included")
+		return newRPCError(ErrCodeTimeout, ReasonTimeout, "Timed out waiting for transaction to be included")
 	}
 }
 
 // ------------------------------- BroadcastRawTransactionAsync -----------------------------------
 
 type BroadcastRawTransactionAsyncArgs struct {
-	TxBytes string `json:"tx_bytes"`
+	TxBytes  string `json:"tx_bytes"`
+	Priority bool   `json:"priority"` // optional, requires the node to enable the priority local transactions
 }
 
 type BroadcastRawTransactionAsyncResult struct {
@@ -191,9 +201,13 @@ type BroadcastRawTransactionAsyncResult struct {
 
 func (t *ThetaRPCService) BroadcastRawTransactionAsync(
 	args *BroadcastRawTransactionAsyncArgs, result *BroadcastRawTransactionAsyncResult) (err error) {
+	if t.readOnly {
+		return errReadOnlyNode
+	}
+
 	txBytes, err := decodeTxHexBytes(args.TxBytes)
 	if err != nil {
-		return err
+		return errInvalidParams("Invalid tx_bytes: %v", err)
 	}
 
 	hash := crypto.Keccak256Hash(txBytes)
@@ -201,7 +215,7 @@ func (t *ThetaRPCService) BroadcastRawTransactionAsync(
 
 	logger.Infof("Prepare to broadcast raw transaction (async): %v, hash: %v", hex.EncodeToString(txBytes), hash.Hex())
 
-	err = t.mempool.InsertTransaction(txBytes)
+	err = t.mempool.InsertLocalTransaction(txBytes, args.Priority)
 	if err == nil || err == mempool.FastsyncSkipTxError {
 		t.mempool.BroadcastTx(txBytes) // still broadcast the transactions received locally during the fastsync mode
 		logger.Infof("Broadcasted raw transaction (async): %v, hash: %v", hex.EncodeToString(txBytes), hash.Hex())
@@ -210,7 +224,118 @@ func (t *ThetaRPCService) BroadcastRawTransactionAsync(
 
 	logger.Warnf("Failed to broadcast raw transaction (async): %v, hash: %v, err: %v", hex.EncodeToString(txBytes), hash.Hex(), err)
 
-	return err
+	return errTxRejected(err)
+}
+
+// ------------------------------- BroadcastRawTransactionSync -----------------------------------
+
+type BroadcastRawTransactionSyncArgs struct {
+	TxBytes  string `json:"tx_bytes"`
+	Priority bool   `json:"priority"` // optional, requires the node to enable the priority local transactions
+}
+
+type BroadcastRawTransactionSyncResult struct {
+	TxHash string   `json:"hash"`
+	Status TxStatus `json:"status"`
+}
+
+// BroadcastRawTransactionSync returns once the transaction has passed the mempool checks, along with its
+// status in the mempool. Like BroadcastRawTransactionAsync, an invalid transaction is reported as an error.
+func (t *ThetaRPCService) BroadcastRawTransactionSync(
+	args *BroadcastRawTransactionSyncArgs, result *BroadcastRawTransactionSyncResult) (err error) {
+	asyncResult := &BroadcastRawTransactionAsyncResult{}
+	err = t.BroadcastRawTransactionAsync(&BroadcastRawTransactionAsyncArgs{
+		TxBytes:  args.TxBytes,
+		Priority: args.Priority,
+	}, asyncResult)
+	if err != nil {
+		return err
+	}
+
+	result.TxHash = asyncResult.TxHash
+	result.Status = TxStatusPending
+	if txStatus, exists := t.mempool.GetTransactionStatus(asyncResult.TxHash); exists && txStatus == mempool.TxStatusAbandoned {
+		result.Status = TxStatusAbandoned
+	}
+	return nil
+}
+
+// ------------------------------- BroadcastRawTransactionCommit -----------------------------------
+
+type BroadcastRawTransactionCommitArgs struct {
+	TxBytes     string            `json:"tx_bytes"`
+	Priority    bool              `json:"priority"`     // optional, requires the node to enable the priority local transactions
+	TimeoutSecs common.JSONUint64 `json:"timeout_secs"` // optional, capped at txTimeout
+}
+
+type BroadcastRawTransactionCommitResult struct {
+	TxHash      string                     `json:"hash"`
+	BlockHash   common.Hash                `json:"block_hash"`
+	BlockHeight common.JSONUint64          `json:"block_height"`
+	Receipt     *blockchain.TxReceiptEntry `json:"receipt"` // nil for the transactions without a receipt
+}
+
+// BroadcastRawTransactionCommit blocks until the transaction is included in a finalized block, and
+// returns the block along with the receipt of the transaction.
+func (t *ThetaRPCService) BroadcastRawTransactionCommit(
+	args *BroadcastRawTransactionCommitArgs, result *BroadcastRawTransactionCommitResult) (err error) {
+	if t.readOnly {
+		return errReadOnlyNode
+	}
+
+	txBytes, err := decodeTxHexBytes(args.TxBytes)
+	if err != nil {
+		return errInvalidParams("Invalid tx_bytes: %v", err)
+	}
+	hash := crypto.Keccak256Hash(txBytes)
+	result.TxHash = hash.Hex()
+
+	timeoutDuration := txTimeout
+	if args.TimeoutSecs > 0 && time.Duration(args.TimeoutSecs)*time.Second < txTimeout {
+		timeoutDuration = time.Duration(args.TimeoutSecs) * time.Second
+	}
+
+	// Register the callback before the insertion, so that the transaction can't be finalized unnoticed
+	finalized := make(chan *core.Block, 1)
+	txCallbackManager.AddCallback(hash, func(block *core.Block) {
+		select {
+		case finalized <- block:
+		default:
+		}
+	})
+
+	logger.Infof("Prepare to broadcast raw transaction (commit): %v, hash: %v", hex.EncodeToString(txBytes), hash.Hex())
+
+	err = t.mempool.InsertLocalTransaction(txBytes, args.Priority)
+	if err != nil && err != mempool.FastsyncSkipTxError {
+		txCallbackManager.RemoveCallback(hash)
+		logger.Warnf("Failed to broadcast raw transaction (commit): %v, hash: %v, err: %v", hex.EncodeToString(txBytes), hash.Hex(), err)
+		return errTxRejected(err)
+	}
+	t.mempool.BroadcastTx(txBytes) // still broadcast the transactions received locally during the fastsync mode
+	logger.Infof("Broadcasted raw transaction (commit): %v, hash: %v", hex.EncodeToString(txBytes), hash.Hex())
+
+	timeout := time.NewTimer(timeoutDuration)
+	defer timeout.Stop()
+
+	select {
+	case block := <-finalized:
+		if block == nil {
+			logger.Infof("Tx callback returns nil, txHash=%v", result.TxHash)
+			return errInternal("Internal server error")
+		}
+		result.BlockHash = block.Hash()
+		result.BlockHeight = common.JSONUint64(block.Height)
+		if receipt, found := t.chain.FindTxReceiptByHash(hash); found {
+			result.Receipt = receipt
+		}
+		return nil
+	case <-timeout.C:
+		txCallbackManager.RemoveCallback(hash)
+		return newRPCError(ErrCodeTimeout, ReasonTimeout, "Timed out waiting for transaction %v to be finalized", hash.Hex())
+	case <-t.ctx.Done():
+		return newRPCError(ErrCodeInternal, ReasonServiceStopping, "The RPC service is stopping")
+	}
 }
 
 // ------------------------------- BroadcastRawEthTransaction -----------------------------------
@@ -221,7 +346,7 @@ func (t *ThetaRPCService) BroadcastRawEthTransaction(
 	ethTxStr := args.TxBytes
 	txStr, err := translateEthTx(ethTxStr)
 	if err != nil {
-		return err
+		return errInvalidParams("Invalid ETH transaction: %v", err)
 	}
 
 	err = t.BroadcastRawTransaction(&BroadcastRawTransactionArgs{
@@ -242,7 +367,7 @@ func (t *ThetaRPCService) BroadcastRawEthTransactionAsync(
 
 	txStr, err := translateEthTx(ethTxStr)
 	if err != nil {
-		return err
+		return errInvalidParams("Invalid ETH transaction: %v", err)
 	}
 
 	err = t.BroadcastRawTransactionAsync(&BroadcastRawTransactionAsyncArgs{
@@ -255,7 +380,7 @@ func (t *ThetaRPCService) BroadcastRawEthTransactionAsync(
 	ethTxStr = strings.TrimPrefix(ethTxStr, "0x")
 	ethTxBytes, err := hex.DecodeString(ethTxStr)
 	if err != nil {
-		return fmt.Errorf("cannot decode hex string: %v", txStr)
+		return errInvalidParams("cannot decode hex string: %v", txStr)
 	}
 	ethTxHash := common.BytesToHash(crypto.Keccak256(ethTxBytes)).Hex()
 	result.TxHash = ethTxHash
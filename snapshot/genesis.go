@@ -0,0 +1,368 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+// GenesisStakeDeposit is a stake deposited in the genesis state, the holder becomes a validator
+// candidate.
+type GenesisStakeDeposit struct {
+	Source common.Address
+	Holder common.Address
+	Amount *big.Int // ThetaWei
+}
+
+// GenerateGenesisSnapshot builds the genesis state from the initial balances and stake deposits,
+// the stakes are taken from the balances of their sources. The upgrades, if any, are scheduled by the
+// chain params of the genesis state. The numeric chain ID the transaction signatures commit to is
+// derived from the chain ID if nil. It returns the state along with the snapshot metadata which
+// holds the genesis block.
+func GenerateGenesisSnapshot(chainID string, numericChainID *big.Int, balances map[common.Address]types.Coins,
+	stakes []GenesisStakeDeposit, upgrades []types.ScheduledUpgrade, timestamp int64) (*state.StoreView, *core.SnapshotMetadata, error) {
+	genesisHeight := core.GenesisBlockHeight
+	sv := state.NewStoreView(genesisHeight, common.Hash{}, backend.NewMemDatabase())
+
+	for address, balance := range balances {
+		acc := &types.Account{
+			Address:  address,
+			Root:     common.Hash{},
+			CodeHash: types.EmptyCodeHash,
+			Balance:  balance.NoNil(),
+		}
+		sv.SetAccount(address, acc)
+	}
+
+	vcp := &core.ValidatorCandidatePool{}
+	for _, stake := range stakes {
+		sourceAccount := sv.GetAccount(stake.Source)
+		if sourceAccount == nil {
+			return nil, nil, fmt.Errorf("No initial balance for the stake source %v", stake.Source.Hex())
+		}
+		amount := types.Coins{ThetaWei: stake.Amount, TFuelWei: big.NewInt(0)}
+		if !sourceAccount.Balance.IsGTE(amount) {
+			return nil, nil, fmt.Errorf("Insufficient balance of the stake source %v: %v < %v ThetaWei",
+				stake.Source.Hex(), sourceAccount.Balance.ThetaWei, stake.Amount)
+		}
+		if err := vcp.DepositStake(stake.Source, stake.Holder, stake.Amount); err != nil {
+			return nil, nil, fmt.Errorf("Failed to deposit the stake of %v: %v", stake.Holder.Hex(), err)
+		}
+		sourceAccount.Balance = sourceAccount.Balance.Minus(amount)
+		sv.SetAccount(stake.Source, sourceAccount)
+	}
+	sv.UpdateValidatorCandidatePool(vcp)
+
+	hl := &types.HeightList{}
+	hl.Append(genesisHeight)
+	sv.UpdateStakeTransactionHeightList(hl)
+
+	if len(upgrades) > 0 {
+		schedule := &types.UpgradeSchedule{Upgrades: upgrades}
+		if err := schedule.Validate(); err != nil {
+			return nil, nil, err
+		}
+		sv.SetUpgradeSchedule(schedule)
+	}
+	if numericChainID != nil {
+		if numericChainID.Sign() <= 0 {
+			return nil, nil, fmt.Errorf("The numeric chain ID needs to be positive")
+		}
+		sv.SetNumericChainID(numericChainID)
+	}
+
+	genesisBlock := core.NewBlock()
+	genesisBlock.ChainID = chainID
+	genesisBlock.Height = genesisHeight
+	genesisBlock.Epoch = genesisBlock.Height
+	genesisBlock.Parent = common.Hash{}
+	genesisBlock.StateHash = sv.Hash()
+	genesisBlock.Timestamp = big.NewInt(timestamp)
+
+	metadata := &core.SnapshotMetadata{
+		TailTrio: core.SnapshotBlockTrio{
+			First:  core.SnapshotFirstBlock{},
+			Second: core.SnapshotSecondBlock{Header: genesisBlock.BlockHeader},
+			Third:  core.SnapshotThirdBlock{},
+		},
+	}
+	return sv, metadata, nil
+}
+
+// WriteGenesisSnapshot writes the genesis snapshot to the file, which is loaded by the nodes
+// configured with the hash of the genesis block.
+func WriteGenesisSnapshot(sv *state.StoreView, metadata *core.SnapshotMetadata, filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if err := core.WriteMetadata(writer, metadata); err != nil {
+		return err
+	}
+	writeStoreView(sv, false, writer, nil)
+	return file.Sync()
+}
+
+// ReadGenesisBlockHeader reads the header of the genesis block from a genesis snapshot file.
+func ReadGenesisBlockHeader(filePath string) (*core.BlockHeader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// The genesis snapshot starts with the metadata, without the header of the later versions
+	metadata := core.SnapshotMetadata{}
+	if _, err := core.ReadRecord(file, &metadata); err != nil {
+		return nil, fmt.Errorf("Failed to read the snapshot metadata: %v", err)
+	}
+	header := metadata.TailTrio.Second.Header
+	if header == nil || header.Height != core.GenesisBlockHeight {
+		return nil, fmt.Errorf("%v is not a genesis snapshot", filePath)
+	}
+	return header, nil
+}
+
+// GenesisSpec is the declarative description of the genesis snapshot of a private network. The
+// same spec always generates the same snapshot, and hence the same genesis block hash.
+type GenesisSpec struct {
+	ChainID    string             `json:"chain_id"`
+	Timestamp  int64              `json:"timestamp"` // the time of the genesis block, in seconds since the epoch
+	Accounts   []GenesisAccount   `json:"accounts"`
+	Validators []GenesisValidator `json:"validators"`
+
+	// NumericChainID is the numeric chain ID the transaction signatures commit to, which tells apart
+	// the networks sharing the same chain ID. It is derived from the chain ID if not set.
+	NumericChainID *common.JSONBig `json:"numeric_chain_id,omitempty"`
+
+	// Upgrades are the activation heights of the protocol upgrades, overriding the mainnet heights
+	Upgrades []types.ScheduledUpgrade `json:"upgrades,omitempty"`
+}
+
+// GenesisAccount is an initial balance of the spec.
+type GenesisAccount struct {
+	Address  common.Address  `json:"address"`
+	ThetaWei *common.JSONBig `json:"theta_wei"`
+	TFuelWei *common.JSONBig `json:"tfuel_wei"`
+}
+
+// GenesisValidator is an initial validator of the spec, whose stake is taken from the balance of
+// the stake source, or of the validator itself if no source is set.
+type GenesisValidator struct {
+	Address     common.Address  `json:"address"`
+	StakeSource *common.Address `json:"stake_source,omitempty"`
+	Stake       *common.JSONBig `json:"stake"` // ThetaWei
+}
+
+// LoadGenesisSpec reads the genesis spec from the JSON file and checks it.
+func LoadGenesisSpec(filePath string) (*GenesisSpec, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	spec := &GenesisSpec{}
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("Failed to parse the genesis spec: %v", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Validate checks the spec, the balances and the stakes themselves are checked when generating
+// the snapshot.
+func (spec *GenesisSpec) Validate() error {
+	if spec.ChainID == "" {
+		return fmt.Errorf("The chain ID is required")
+	}
+	if spec.ChainID == core.MainnetChainID {
+		return fmt.Errorf("The chain ID of a private network cannot be %v", core.MainnetChainID)
+	}
+	if spec.NumericChainID != nil && spec.NumericChainID.ToInt().Sign() <= 0 {
+		return fmt.Errorf("The numeric chain ID needs to be positive")
+	}
+	if spec.Timestamp <= 0 {
+		return fmt.Errorf("The timestamp is required for the genesis block hash to be reproducible")
+	}
+	if len(spec.Validators) == 0 {
+		return fmt.Errorf("At least one validator is required")
+	}
+
+	accounts := make(map[common.Address]bool)
+	for _, account := range spec.Accounts {
+		if accounts[account.Address] {
+			return fmt.Errorf("Duplicate account %v", account.Address.Hex())
+		}
+		accounts[account.Address] = true
+		if isNegative(account.ThetaWei) || isNegative(account.TFuelWei) {
+			return fmt.Errorf("Negative balance of account %v", account.Address.Hex())
+		}
+	}
+	validators := make(map[common.Address]bool)
+	for _, validator := range spec.Validators {
+		if validators[validator.Address] {
+			return fmt.Errorf("Duplicate validator %v", validator.Address.Hex())
+		}
+		validators[validator.Address] = true
+		if validator.Stake == nil || validator.Stake.ToInt().Cmp(core.MinValidatorStakeDeposit) < 0 {
+			return fmt.Errorf("The stake of validator %v is below the minimum of %v ThetaWei",
+				validator.Address.Hex(), core.MinValidatorStakeDeposit)
+		}
+	}
+	schedule := &types.UpgradeSchedule{Upgrades: spec.Upgrades}
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Generate builds the genesis snapshot of the spec.
+func (spec *GenesisSpec) Generate() (*state.StoreView, *core.SnapshotMetadata, error) {
+	balances := make(map[common.Address]types.Coins)
+	for _, account := range spec.Accounts {
+		balances[account.Address] = types.Coins{
+			ThetaWei: toInt(account.ThetaWei),
+			TFuelWei: toInt(account.TFuelWei),
+		}
+	}
+	stakes := []GenesisStakeDeposit{}
+	for _, validator := range spec.Validators {
+		source := validator.Address
+		if validator.StakeSource != nil {
+			source = *validator.StakeSource
+		}
+		stakes = append(stakes, GenesisStakeDeposit{Source: source, Holder: validator.Address, Amount: validator.Stake.ToInt()})
+	}
+	var numericChainID *big.Int
+	if spec.NumericChainID != nil {
+		numericChainID = spec.NumericChainID.ToInt()
+	}
+	return GenerateGenesisSnapshot(spec.ChainID, numericChainID, balances, stakes, spec.Upgrades, spec.Timestamp)
+}
+
+func toInt(value *common.JSONBig) *big.Int {
+	if value == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(value.ToInt())
+}
+
+func isNegative(value *common.JSONBig) bool {
+	return value != nil && value.ToInt().Sign() < 0
+}
+
+// GenesisSummary is the content of a genesis snapshot.
+type GenesisSummary struct {
+	ChainID    string                    `json:"chain_id"`
+	BlockHash  common.Hash               `json:"block_hash"`
+	StateHash  common.Hash               `json:"state_hash"`
+	Timestamp  int64                     `json:"timestamp"`
+	Accounts   []GenesisAccountSummary   `json:"accounts"`
+	Validators []GenesisValidatorSummary `json:"validators"`
+	Upgrades   []types.ScheduledUpgrade  `json:"upgrades"`
+	TotalTheta *big.Int                  `json:"total_theta_wei"` // including the stakes
+	TotalTFuel *big.Int                  `json:"total_tfuel_wei"`
+}
+
+type GenesisAccountSummary struct {
+	Address  common.Address `json:"address"`
+	ThetaWei *big.Int       `json:"theta_wei"`
+	TFuelWei *big.Int       `json:"tfuel_wei"`
+}
+
+type GenesisValidatorSummary struct {
+	Address common.Address `json:"address"`
+	Stake   *big.Int       `json:"stake"`
+}
+
+// ReadGenesisSnapshot loads the genesis snapshot into memory, checks that its state matches the
+// state hash of the genesis block, and returns its content.
+func ReadGenesisSnapshot(filePath string) (*GenesisSummary, error) {
+	header, err := ReadGenesisBlockHeader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	metadata := core.SnapshotMetadata{}
+	if _, err := core.ReadRecord(file, &metadata); err != nil {
+		return nil, fmt.Errorf("Failed to read the snapshot metadata: %v", err)
+	}
+	sv, _, err := loadStateV2(file, backend.NewMemDatabase(), 0, "Loading genesis snapshot")
+	if err != nil {
+		return nil, err
+	}
+	if sv == nil {
+		return nil, fmt.Errorf("%v holds no state", filePath)
+	}
+	if sv.Hash() != header.StateHash {
+		return nil, fmt.Errorf("StateHash not matching: %v vs %v", sv.Hash().Hex(), header.StateHash.Hex())
+	}
+	if header.Parent != (common.Hash{}) {
+		return nil, fmt.Errorf("The genesis block has a parent: %v", header.Parent.Hex())
+	}
+
+	summary := &GenesisSummary{
+		ChainID:    header.ChainID,
+		BlockHash:  header.Hash(),
+		StateHash:  header.StateHash,
+		Accounts:   []GenesisAccountSummary{},
+		Validators: []GenesisValidatorSummary{},
+		TotalTheta: big.NewInt(0),
+		TotalTFuel: big.NewInt(0),
+	}
+	if header.Timestamp != nil {
+		summary.Timestamp = header.Timestamp.Int64()
+	}
+
+	var traverseErr error
+	sv.Traverse(state.AccountKeyPrefix(), func(k, v common.Bytes) bool {
+		account := &types.Account{}
+		if traverseErr = types.FromBytes([]byte(v), account); traverseErr != nil {
+			return false
+		}
+		balance := account.Balance.NoNil()
+		summary.Accounts = append(summary.Accounts, GenesisAccountSummary{
+			Address:  account.Address,
+			ThetaWei: balance.ThetaWei,
+			TFuelWei: balance.TFuelWei,
+		})
+		summary.TotalTheta.Add(summary.TotalTheta, balance.ThetaWei)
+		summary.TotalTFuel.Add(summary.TotalTFuel, balance.TFuelWei)
+		return true
+	})
+	if traverseErr != nil {
+		return nil, fmt.Errorf("Failed to parse account: %v", traverseErr)
+	}
+
+	vcp := sv.GetValidatorCandidatePool()
+	if vcp != nil {
+		for _, candidate := range vcp.SortedCandidates {
+			stake := candidate.TotalStake()
+			summary.Validators = append(summary.Validators, GenesisValidatorSummary{Address: candidate.Holder, Stake: stake})
+			summary.TotalTheta.Add(summary.TotalTheta, stake)
+		}
+	}
+	summary.Upgrades = sv.GetUpgradeSchedule().Upgrades
+	return summary, nil
+}
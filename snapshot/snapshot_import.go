@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/ledger"
 
 	"github.com/pkg/errors"
@@ -30,7 +31,7 @@ import (
 	"github.com/thetatoken/theta/store/trie"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "snapshot"})
+var logger = util.GetLoggerForModule("snapshot")
 
 type SVStack []*state.StoreView
 
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+// entryOverhead approximates the memory taken by an entry in addition to its value, i.e. the
+// key, the list element and the map bucket
+const entryOverhead = 96
+
+// Cache is a thread-safe LRU cache of byte values bounded by the total size of the entries. The
+// hits and misses are counted by the "<name>/hit" and "<name>/miss" metrics.
+type Cache struct {
+	mu           sync.Mutex
+	maxSize      int
+	size         int
+	entries      map[interface{}]*list.Element
+	evictionList *list.List
+
+	hitCounter  metrics.Counter
+	missCounter metrics.Counter
+}
+
+type entry struct {
+	key   interface{}
+	value []byte
+}
+
+// New creates a cache holding up to maxSize bytes, a non-positive maxSize disables the cache
+func New(name string, maxSize int) *Cache {
+	return &Cache{
+		maxSize:      maxSize,
+		entries:      make(map[interface{}]*list.Element),
+		evictionList: list.New(),
+		hitCounter:   metrics.NewRegisteredCounter(name+"/hit", nil),
+		missCounter:  metrics.NewRegisteredCounter(name+"/miss", nil),
+	}
+}
+
+// Get returns the value cached under the key. The returned value must not be modified.
+func (c *Cache) Get(key interface{}) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxSize <= 0 {
+		return nil, false
+	}
+	elem, ok := c.entries[key]
+	if !ok {
+		c.missCounter.Inc(1)
+		return nil, false
+	}
+	c.hitCounter.Inc(1)
+	c.evictionList.MoveToFront(elem)
+	return elem.Value.(*entry).value, true
+}
+
+// Add caches the value under the key, evicting the least recently used entries if the cache
+// would exceed its size. The value must not be modified afterwards.
+func (c *Cache) Add(key interface{}, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxSize <= 0 || len(value)+entryOverhead > c.maxSize {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		ent := elem.Value.(*entry)
+		c.size += len(value) - len(ent.value)
+		ent.value = value
+		c.evictionList.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.evictionList.PushFront(&entry{key: key, value: value})
+		c.size += len(value) + entryOverhead
+	}
+	c.evict()
+}
+
+// Remove removes the value cached under the key if any
+func (c *Cache) Remove(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Resize changes the max size of the cache, evicting the least recently used entries as needed
+func (c *Cache) Resize(maxSize int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxSize = maxSize
+	c.evict()
+}
+
+// Size returns the total size of the cached entries in bytes
+func (c *Cache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Len returns the number of the cached entries
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache) evict() {
+	for c.size > c.maxSize && c.evictionList.Len() > 0 {
+		c.removeElement(c.evictionList.Back())
+	}
+}
+
+func (c *Cache) removeElement(elem *list.Element) {
+	ent := c.evictionList.Remove(elem).(*entry)
+	delete(c.entries, ent.key)
+	c.size -= len(ent.value) + entryOverhead
+}
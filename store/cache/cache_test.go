@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New("test", 3*(entryOverhead+10))
+	value := make([]byte, 10)
+	c.Add("a", value)
+	c.Add("b", value)
+	c.Add("c", value)
+	assert.Equal(3, c.Len())
+	assert.Equal(3*(entryOverhead+10), c.Size())
+
+	// The least recently used entry is evicted
+	_, ok := c.Get("a")
+	assert.True(ok)
+	c.Add("d", value)
+	_, ok = c.Get("b")
+	assert.False(ok)
+	_, ok = c.Get("a")
+	assert.True(ok)
+
+	// Replacing a value updates the size
+	c.Add("a", make([]byte, 20))
+	assert.Equal(2, c.Len())
+	assert.Equal(2*entryOverhead+30, c.Size())
+
+	c.Remove("a")
+	_, ok = c.Get("a")
+	assert.False(ok)
+	assert.Equal(entryOverhead+10, c.Size())
+
+	// Entries larger than the cache are not cached
+	c.Add("e", make([]byte, 4*(entryOverhead+10)))
+	_, ok = c.Get("e")
+	assert.False(ok)
+
+	c.Resize(0)
+	assert.Equal(0, c.Len())
+	c.Add("f", value)
+	_, ok = c.Get("f")
+	assert.False(ok)
+}
@@ -0,0 +1,277 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/store/database"
+)
+
+// maxCheckpointAttempts is the number of times a LevelDB database is copied before giving up if
+// its writer keeps changing the manifest during the copy
+const maxCheckpointAttempts = 10
+
+// CreateCheckpoint creates a consistent copy of all the LevelDB databases under srcDir in dstDir,
+// while they may be held open and written by another process. Unlike opening the databases with
+// the ReadOnly option, it does not need the file lock of the writer. The table files are
+// immutable and hard linked where possible, the manifests and the journals are copied. The copy
+// must be opened in read-write mode so that the journals are replayed.
+func CreateCheckpoint(srcDir, dstDir string) error {
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// A rolling layer may be removed by the compaction of the writer during the walk
+			if os.IsNotExist(err) && path != srcDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(path, "CURRENT")); err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		if err := checkpointLevelDB(path, filepath.Join(dstDir, rel)); err != nil {
+			return fmt.Errorf("Failed to create the checkpoint of %v: %v", path, err)
+		}
+		return filepath.SkipDir
+	})
+}
+
+// checkpointLevelDB copies a single LevelDB database. The manifest is copied first and the table
+// files it references are only deleted by the writer after it appends a new version to the
+// manifest, so the copy is consistent if the manifest has not changed once the tables are linked.
+func checkpointLevelDB(srcDir, dstDir string) error {
+	for attempt := 0; attempt < maxCheckpointAttempts; attempt++ {
+		os.RemoveAll(dstDir)
+		if err := os.MkdirAll(dstDir, 0700); err != nil {
+			return err
+		}
+
+		current, err := ioutil.ReadFile(filepath.Join(srcDir, "CURRENT"))
+		if err != nil {
+			return err
+		}
+		manifest := strings.TrimSpace(string(current))
+		manifestSize, err := copyFile(filepath.Join(srcDir, manifest), filepath.Join(dstDir, manifest))
+		if os.IsNotExist(err) {
+			continue // The manifest has just been rotated
+		}
+		if err != nil {
+			return err
+		}
+
+		files, err := ioutil.ReadDir(srcDir)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			name := file.Name()
+			src, dst := filepath.Join(srcDir, name), filepath.Join(dstDir, name)
+			switch filepath.Ext(name) {
+			case ".ldb", ".sst":
+				err = linkOrCopyFile(src, dst)
+			case ".log":
+				_, err = copyFile(src, dst)
+			default:
+				continue
+			}
+			// Obsolete tables and journals may be removed in the meantime
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(dstDir, "CURRENT"), current, 0644); err != nil {
+			return err
+		}
+
+		latest, err := ioutil.ReadFile(filepath.Join(srcDir, "CURRENT"))
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(filepath.Join(srcDir, manifest))
+		if err == nil && string(latest) == string(current) && info.Size() == manifestSize {
+			return nil
+		}
+	}
+	return fmt.Errorf("the database kept changing during %v attempts", maxCheckpointAttempts)
+}
+
+func linkOrCopyFile(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	// The checkpoint may be on another file system
+	_, err := copyFile(src, dst)
+	return err
+}
+
+func copyFile(src, dst string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	size, err := io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return size, err
+}
+
+// CheckpointDatabase serves the reads from the latest checkpoint of the LevelDB databases of
+// another process, see CreateCheckpoint. It switches to a new checkpoint on Refresh, so that a
+// secondary process can follow the writer without sharing its file lock.
+type CheckpointDatabase struct {
+	mu sync.RWMutex
+
+	srcDir  string // the "db" folder of the data directory of the writer
+	rootDir string // the folder holding the checkpoints
+	cache   int
+	handles int
+
+	dir string // the current checkpoint, the databases are in its "db" folder
+	db  *LDBDatabase
+}
+
+var _ database.Database = (*CheckpointDatabase)(nil)
+
+// NewCheckpointDatabase takes a checkpoint of the "main" and "ref" databases in srcDir under
+// rootDir and opens it.
+func NewCheckpointDatabase(srcDir, rootDir string, cache int, handles int) (*CheckpointDatabase, error) {
+	// Checkpoints left behind by a previous run are stale
+	if err := os.RemoveAll(rootDir); err != nil {
+		return nil, err
+	}
+	c := &CheckpointDatabase{
+		srcDir:  srcDir,
+		rootDir: rootDir,
+		cache:   cache,
+		handles: handles,
+	}
+	dir, db, err := c.open()
+	if err != nil {
+		return nil, err
+	}
+	c.dir = dir
+	c.db = db
+	return c, nil
+}
+
+func (c *CheckpointDatabase) open() (string, *LDBDatabase, error) {
+	dir := filepath.Join(c.rootDir, strconv.FormatInt(time.Now().UnixNano(), 10))
+	dbDir := filepath.Join(dir, "db")
+	if err := CreateCheckpoint(c.srcDir, dbDir); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	db, err := NewLDBDatabase(filepath.Join(dbDir, "main"), filepath.Join(dbDir, "ref"), c.cache, c.handles)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	return dir, db, nil
+}
+
+// Path returns the folder of the current checkpoint, the databases are in its "db" folder.
+func (c *CheckpointDatabase) Path() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dir
+}
+
+// Refresh takes a new checkpoint and switches to it. The previous checkpoint remains on disk
+// until the returned function is called, so that the databases opened by the caller on the other
+// files of the previous checkpoint can be switched first.
+func (c *CheckpointDatabase) Refresh() (func(), error) {
+	dir, db, err := c.open()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	prevDir, prevDB := c.dir, c.db
+	c.dir, c.db = dir, db
+	c.mu.Unlock()
+
+	return func() {
+		prevDB.Close()
+		os.RemoveAll(prevDir)
+	}, nil
+}
+
+// Put puts the key / value to the current checkpoint
+func (c *CheckpointDatabase) Put(key []byte, value []byte) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db.Put(key, value)
+}
+
+func (c *CheckpointDatabase) Has(key []byte) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db.Has(key)
+}
+
+// Get returns the given key from the current checkpoint if it's present.
+func (c *CheckpointDatabase) Get(key []byte) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db.Get(key)
+}
+
+func (c *CheckpointDatabase) Delete(key []byte) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db.Delete(key)
+}
+
+func (c *CheckpointDatabase) Reference(key []byte) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db.Reference(key)
+}
+
+func (c *CheckpointDatabase) Dereference(key []byte) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db.Dereference(key)
+}
+
+func (c *CheckpointDatabase) CountReference(key []byte) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db.CountReference(key)
+}
+
+// NewBatch returns a batch on the current checkpoint
+func (c *CheckpointDatabase) NewBatch() database.Batch {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db.NewBatch()
+}
+
+// Close closes and removes the current checkpoint
+func (c *CheckpointDatabase) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.db.Close()
+	os.RemoveAll(c.rootDir)
+}
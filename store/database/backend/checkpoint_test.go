@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestCheckpointDatabaseWhileWriterHoldsDB(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "checkpoint_test_")
+	if err != nil {
+		t.Fatalf("failed to create test dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	srcDir := filepath.Join(dir, "data", "db")
+	writer, err := NewLDBDatabase(filepath.Join(srcDir, "main"), filepath.Join(srcDir, "ref"), 0, 0)
+	if err != nil {
+		t.Fatalf("failed to open the writer: %v", err)
+	}
+	defer writer.Close()
+
+	key := func(i int) []byte { return []byte(fmt.Sprintf("key%08d", i)) }
+	for i := 0; i < 1000; i++ {
+		if err := writer.Put(key(i), []byte("value")); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+
+	// The writer holds the lock of the database
+	if _, err := NewLDBDatabase(filepath.Join(srcDir, "main"), filepath.Join(srcDir, "ref"), 0, 0); err == nil {
+		t.Fatalf("a second process should not be able to open the database")
+	}
+
+	// Keep writing while the checkpoints are taken
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1000; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			writer.Put(key(i), make([]byte, 1024))
+		}
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	cdb, err := NewCheckpointDatabase(srcDir, filepath.Join(dir, "checkpoint"), 0, 0)
+	if err != nil {
+		t.Fatalf("failed to open the checkpoint: %v", err)
+	}
+	defer cdb.Close()
+
+	for i := 0; i < 1000; i++ {
+		if _, err := cdb.Get(key(i)); err != nil {
+			t.Fatalf("%s is missing from the checkpoint: %v", key(i), err)
+		}
+	}
+
+	// A refresh picks up the writes made after the previous checkpoint
+	if err := writer.Put([]byte("latest"), []byte("value")); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if has, _ := cdb.Has([]byte("latest")); has {
+		t.Fatalf("the checkpoint should not change until refreshed")
+	}
+	prevPath := cdb.Path()
+	release, err := cdb.Refresh()
+	if err != nil {
+		t.Fatalf("failed to refresh the checkpoint: %v", err)
+	}
+	release()
+	if _, err := os.Stat(prevPath); !os.IsNotExist(err) {
+		t.Fatalf("the previous checkpoint should have been removed")
+	}
+	if _, err := cdb.Get([]byte("latest")); err != nil {
+		t.Fatalf("the refreshed checkpoint should hold the latest write: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if _, err := cdb.Get(key(i)); err != nil {
+			t.Fatalf("%s is missing from the refreshed checkpoint: %v", key(i), err)
+		}
+	}
+}
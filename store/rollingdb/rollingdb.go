@@ -1,6 +1,7 @@
 package rollingdb
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path"
@@ -41,7 +42,9 @@ func NewRollingDB(parentPath string, root database.Database) *RollingDB {
 	}
 
 	rollingPath := path.Join(parentPath, "db", "rolling")
-	_ = os.Mkdir(rollingPath, 0700)
+	if !viper.GetBool(common.CfgStorageReadOnly) {
+		_ = os.Mkdir(rollingPath, 0700)
+	}
 
 	rdb := &RollingDB{
 		parentPath: parentPath,
@@ -62,9 +65,34 @@ func (rdb *RollingDB) SetChain(chain *blockchain.Chain) {
 	rdb.chain = chain
 }
 
+// Reopen closes the layers and opens the ones under parentPath, keeping the root database. It is
+// used by a read-only node to switch to a new checkpoint of the data directory.
+func (rdb *RollingDB) Reopen(parentPath string) {
+	rdb.mu.Lock()
+	defer rdb.mu.Unlock()
+
+	prevLayers := append(rdb.layers, rdb.activeLayer)
+
+	rdb.parentPath = parentPath
+	rdb.rootLayer.dbPath = path.Join(parentPath, "db")
+	activeLayer, layers := rdb.loadLayers(path.Join(parentPath, "db", "rolling"))
+	rdb.activeLayer = activeLayer
+	rdb.layers = layers
+
+	for _, layer := range prevLayers {
+		if layer != rdb.rootLayer {
+			layer.db.Close()
+		}
+	}
+}
+
 func (rdb *RollingDB) loadLayers(rollingPath string) (*DBLayer, []*DBLayer) {
+	readOnly := viper.GetBool(common.CfgStorageReadOnly)
 	files, err := ioutil.ReadDir(rollingPath)
 	if err != nil {
+		if readOnly && os.IsNotExist(err) {
+			return rdb.rootLayer, nil
+		}
 		logger.Panicf("Failed to load layers", err)
 	}
 	names := []int{}
@@ -80,7 +108,8 @@ func (rdb *RollingDB) loadLayers(rollingPath string) (*DBLayer, []*DBLayer) {
 	}
 
 	if len(names) == 0 {
-		if !viper.GetBool(common.CfgStorageRollingEnabled) {
+		// A read-only node cannot create new layers, fall back to the root layer
+		if readOnly || !viper.GetBool(common.CfgStorageRollingEnabled) {
 			return rdb.rootLayer, nil
 		}
 		return NewDBLayer(rollingPath, 1), nil
@@ -287,6 +316,17 @@ func (rdb *RollingDB) Delete(key []byte) error {
 	return nil
 }
 
+// StopCompaction waits for the running compaction, if any, to finish and prevents new compactions
+// from starting, so that the layers can be closed. It returns false if ctx is done first.
+func (rdb *RollingDB) StopCompaction(ctx context.Context) bool {
+	select {
+	case rdb.compactC <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (rdb *RollingDB) Close() {
 	for _, dbLayer := range rdb.layers {
 		dbLayer.db.Close()
@@ -85,6 +85,11 @@ func (store *TreeStore) ProveVCP(vcpKey []byte, vp *core.VCPProof) error {
 	return store.Trie.Prove(vcpKey, 0, vp)
 }
 
+// Prove writes the Merkle proof of the value at the key into proofDb
+func (store *TreeStore) Prove(key []byte, proofDb database.Putter) error {
+	return store.Trie.Prove(key, 0, proofDb)
+}
+
 // Set sets value of given key.
 func (store *TreeStore) Set(key, value common.Bytes) {
 	store.Trie.Update(key, value)
@@ -105,6 +110,20 @@ func (store *TreeStore) Traverse(prefix common.Bytes, cb func(k, v common.Bytes)
 	return true
 }
 
+// Iterate iterates over the key/value pairs with key having prefix in ascending key order,
+// starting from the first key not less than start, and stops once cb returns false
+func (store *TreeStore) Iterate(prefix, start common.Bytes, cb func(k, v common.Bytes) bool) {
+	if bytes.Compare(start, prefix) < 0 {
+		start = prefix
+	}
+	it := trie.NewIterator(store.Trie.NodeIterator(start))
+	for it.Next() {
+		if !bytes.HasPrefix(it.Key, prefix) || !cb(it.Key, it.Value) {
+			break
+		}
+	}
+}
+
 // Delete deletes the key/value pair.
 func (store *TreeStore) Delete(key common.Bytes) (deleted bool) {
 	store.Trie.Delete(key)
@@ -22,15 +22,15 @@ import (
 	"sync"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store/cache"
 	"github.com/thetatoken/theta/store/database"
 )
 
-var logger *log.Entry = log.WithFields(log.Fields{"prefix": "store"})
+var logger = util.GetLoggerForModule("store")
 
 var (
 	memcacheFlushTimeTimer  = metrics.NewRegisteredResettingTimer("trie/memcache/flush/time", nil)
@@ -46,6 +46,19 @@ var (
 	memcacheCommitSizeMeter  = metrics.NewRegisteredMeter("trie/memcache/commit/size", nil)
 )
 
+// cleanNodes caches the encoded nodes persisted in the disk databases, shared by all the trie
+// databases. The nodes are keyed by their hashes, hence a cached node never changes. The nodes
+// are cached when read from the disk, and written through when committed. A cached node may
+// however outlive its copy on the disk, e.g. once deleted through another database wrapping the
+// disk or by the compaction of the rolling database, hence the existence of a root is always
+// checked against the disk, see hasNode.
+var cleanNodes = cache.New("trie/cleancache", 0)
+
+// SetNodeCacheSize sets the memory budget of the clean node cache in bytes, 0 to disable it
+func SetNodeCacheSize(size int) {
+	cleanNodes.Resize(size)
+}
+
 // secureKeyPrefix is the database key prefix used to store trie node preimages.
 var secureKeyPrefix = []byte("secure-key-")
 
@@ -346,11 +359,15 @@ func (db *Database) node(hash common.Hash, cachegen uint16) node {
 	if node != nil {
 		return node.obj(hash, cachegen)
 	}
+	if enc, ok := cleanNodes.Get(hash); ok {
+		return mustDecodeNode(hash[:], enc, cachegen)
+	}
 	// Content unavailable in memory, attempt to retrieve from disk
 	enc, err := db.diskdb.Get(hash[:])
 	if err != nil || enc == nil {
 		return nil
 	}
+	cleanNodes.Add(hash, enc)
 	return mustDecodeNode(hash[:], enc, cachegen)
 }
 
@@ -365,8 +382,29 @@ func (db *Database) Node(hash common.Hash) ([]byte, error) {
 	if node != nil {
 		return node.rlp(), nil
 	}
+	if enc, ok := cleanNodes.Get(hash); ok {
+		return enc, nil
+	}
 	// Content unavailable in memory, attempt to retrieve from disk
-	return db.diskdb.Get(hash[:])
+	enc, err := db.diskdb.Get(hash[:])
+	if err == nil && enc != nil {
+		cleanNodes.Add(hash, enc)
+	}
+	return enc, err
+}
+
+// hasNode reports whether the node is held in memory or persisted in the disk database. Unlike
+// node, it bypasses the clean node cache, so that a root pruned from the disk is reported missing
+// even while its encoding is still cached.
+func (db *Database) hasNode(hash common.Hash) bool {
+	db.lock.RLock()
+	_, ok := db.nodes[hash]
+	db.lock.RUnlock()
+	if ok {
+		return true
+	}
+	has, err := db.diskdb.Has(hash[:])
+	return err == nil && has
 }
 
 // preimage retrieves a cached trie node pre-image from memory. If it cannot be
@@ -579,6 +617,7 @@ func (db *Database) Cap(limit StorageSize) error {
 	}
 	for db.oldest != oldest {
 		node := db.nodes[db.oldest]
+		cleanNodes.Add(db.oldest, node.rlp())
 		delete(db.nodes, db.oldest)
 		db.oldest = node.flushNext
 
@@ -725,6 +764,7 @@ func (db *Database) uncache(hash common.Hash) {
 	for _, child := range node.childs() {
 		db.uncache(child)
 	}
+	cleanNodes.Add(hash, node.rlp())
 	delete(db.nodes, hash)
 	db.nodesSize -= StorageSize(common.HashLength + int(node.size))
 }
@@ -114,6 +114,9 @@ func New(root common.Hash, db *Database) (*Trie, error) {
 		mu:           &sync.RWMutex{},
 	}
 	if root != (common.Hash{}) && root != emptyRoot {
+		if !db.hasNode(root) {
+			return nil, &MissingNodeError{NodeHash: root}
+		}
 		rootnode, err := trie.resolveHash(root[:], nil)
 		if err != nil {
 			//logger.Debugf("trie.New, t.originalRoot: %v, t.root: %v, err: %v", trie.originalRoot.Hex(), rootnode, err)
@@ -545,6 +548,7 @@ func (t *Trie) pruneNode(n node, cb func(n []byte) bool) error {
 	if err != nil && err != store.ErrKeyNotFound {
 		return err
 	}
+	cleanNodes.Remove(common.BytesToHash(hash))
 	//logger.Debugf("Trie.pruneNode, delete node, hash: %v", hash)
 	return nil
 }
@@ -87,6 +87,33 @@ func TestMissingRoot(t *testing.T) {
 	}
 }
 
+func TestPrunedRootWithNodeCache(t *testing.T) {
+	SetNodeCacheSize(1 << 20)
+	defer SetNodeCacheSize(0)
+
+	diskdb := dbbackend.NewMemDatabase()
+	triedb := NewDatabase(diskdb)
+	trie, _ := New(common.Hash{}, triedb)
+	updateString(trie, "120000", "qwerqwerqwerqwerqwerqwerqwerqwer")
+	updateString(trie, "123456", "asdfasdfasdfasdfasdfasdfasdfasdf")
+	root, _ := trie.Commit(nil)
+	triedb.Commit(root, true)
+
+	// Caches the root node, then deletes it from the disk behind the cache
+	if _, err := New(root, NewDatabase(diskdb)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	diskdb.Delete(root[:])
+
+	trie, err := New(root, NewDatabase(diskdb))
+	if trie != nil {
+		t.Error("New returned non-nil trie for pruned root")
+	}
+	if _, ok := err.(*MissingNodeError); !ok {
+		t.Errorf("New returned wrong error: %v", err)
+	}
+}
+
 func TestTryDeleteDisk(t *testing.T)    { testTryDelete(t, false) }
 func TestTryDeleteMemonly(t *testing.T) { testTryDelete(t, true) }
 
@@ -0,0 +1,164 @@
+// Package hd derives the keys of the software wallet from a mnemonic, so that a single seed
+// phrase backs up all the keys. The mnemonic is converted to a seed as specified by BIP39, and
+// the keys are derived from the seed along the derivation paths as specified by BIP32/BIP44.
+package hd
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/thetatoken/theta/common/math"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/wallet/types"
+)
+
+const hardenedOffset = 0x80000000
+
+// The supported mnemonic lengths in words, with 128 and 256 bits of entropy respectively
+const (
+	MnemonicWords12 = 12
+	MnemonicWords24 = 24
+)
+
+var errInvalidMnemonic = errors.New("Invalid mnemonic")
+
+// NewMnemonic generates a random mnemonic with the given number of words.
+func NewMnemonic(words int) (string, error) {
+	var bits int
+	switch words {
+	case MnemonicWords12:
+		bits = 128
+	case MnemonicWords24:
+		bits = 256
+	default:
+		return "", fmt.Errorf("Unsupported mnemonic length %v, must be %v or %v words", words, MnemonicWords12, MnemonicWords24)
+	}
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// NormalizeMnemonic collapses the whitespaces of the mnemonic and converts it to lower case.
+func NormalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+}
+
+// ValidateMnemonic checks the words and the checksum of the mnemonic.
+func ValidateMnemonic(mnemonic string) error {
+	// Unlike bip39.IsMnemonicValid, which only checks the words against the word list, the
+	// conversion verifies the checksum, so that a mistyped mnemonic is not taken for another wallet
+	if _, err := bip39.MnemonicToByteArray(NormalizeMnemonic(mnemonic)); err != nil {
+		return errInvalidMnemonic
+	}
+	return nil
+}
+
+// DeriveKey derives the private key at the given path from the mnemonic, protected by the
+// optional BIP39 passphrase.
+func DeriveKey(mnemonic, passphrase string, path types.DerivationPath) (*crypto.PrivateKey, error) {
+	seed, err := NewSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return DeriveKeyFromSeed(seed, path)
+}
+
+// NewSeed converts the mnemonic to the BIP39 seed, protected by the optional passphrase.
+func NewSeed(mnemonic, passphrase string) ([]byte, error) {
+	mnemonic = NormalizeMnemonic(mnemonic)
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, errInvalidMnemonic
+	}
+	return seed, nil
+}
+
+// DeriveKeyFromSeed derives the private key at the given path from the BIP32 seed.
+func DeriveKeyFromSeed(seed []byte, path types.DerivationPath) (*crypto.PrivateKey, error) {
+	key, chainCode := hmacSHA512([]byte("Bitcoin seed"), seed)
+	privKey, err := crypto.PrivateKeyFromBytes(key)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid master key: %v", err)
+	}
+
+	for _, index := range path {
+		privKey, chainCode, err = deriveChild(privKey, chainCode, index)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to derive key at %v: %v", path, err)
+		}
+	}
+	return privKey, nil
+}
+
+// DeriveNodeIdentityKey derives the P2P node identity key from the master secret (the BIP32
+// seed) of a node.
+func DeriveNodeIdentityKey(seed []byte) (*crypto.PrivateKey, error) {
+	return DeriveKeyFromSeed(seed, types.NodeIdentityDerivationPath)
+}
+
+// DeriveValidatorKey derives the validator signing key of the given generation from the master
+// secret (the BIP32 seed) of a node. Each generation yields an independent key, so a validator
+// can rotate its signing key without changing its master secret or node identity.
+func DeriveValidatorKey(seed []byte, generation uint32) (*crypto.PrivateKey, error) {
+	if generation >= hardenedOffset {
+		return nil, fmt.Errorf("Invalid validator key generation %v", generation)
+	}
+	return DeriveKeyFromSeed(seed, types.ValidatorDerivationPath(generation))
+}
+
+// deriveChild derives the child private key at the index (CKDpriv in BIP32).
+func deriveChild(parent *crypto.PrivateKey, chainCode []byte, index uint32) (*crypto.PrivateKey, []byte, error) {
+	var data []byte
+	if index >= hardenedOffset {
+		data = append([]byte{0}, parent.ToBytes()...)
+	} else {
+		data = compressPublicKey(parent)
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+
+	il, childChainCode := hmacSHA512(chainCode, data)
+	n := crypto.PrivKeyToECDSA(parent).Params().N
+	delta := new(big.Int).SetBytes(il)
+	if delta.Cmp(n) >= 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %v", index)
+	}
+	child := delta.Add(delta, parent.D())
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %v", index)
+	}
+
+	childKey, err := crypto.PrivateKeyFromBytes(math.PaddedBigBytes(child, 32))
+	if err != nil {
+		return nil, nil, err
+	}
+	return childKey, childChainCode, nil
+}
+
+// compressPublicKey serializes the public key of the private key in the compressed form.
+func compressPublicKey(privKey *crypto.PrivateKey) []byte {
+	pubKey := crypto.PrivKeyToECDSA(privKey).PublicKey
+	prefix := byte(0x02)
+	if pubKey.Y.Bit(0) == 1 {
+		prefix = 0x03
+	}
+	return append([]byte{prefix}, math.PaddedBigBytes(pubKey.X, 32)...)
+}
+
+func hmacSHA512(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
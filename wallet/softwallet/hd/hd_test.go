@@ -0,0 +1,121 @@
+package hd
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/wallet/types"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// BIP32 test vector 1
+func TestDeriveKeyFromSeed(t *testing.T) {
+	assert := assert.New(t)
+
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	testCases := []struct {
+		path string
+		key  string
+	}{
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for _, tc := range testCases {
+		path, err := types.ParseDerivationPath(tc.path)
+		assert.Nil(err)
+		privKey, err := DeriveKeyFromSeed(seed, path)
+		assert.Nil(err)
+		assert.Equal(tc.key, hex.EncodeToString(privKey.ToBytes()), tc.path)
+	}
+
+	privKey, err := DeriveKeyFromSeed(seed, types.DerivationPath{})
+	assert.Nil(err)
+	assert.Equal("e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35", hex.EncodeToString(privKey.ToBytes()))
+}
+
+func TestDeriveKey(t *testing.T) {
+	assert := assert.New(t)
+
+	// Same address as the other BIP44 wallets with the Ethereum coin type
+	ethPath := types.DerivationPath{0x80000000 + 44, 0x80000000 + 60, 0x80000000 + 0, 0, 0}
+	privKey, err := DeriveKey(testMnemonic, "", ethPath)
+	assert.Nil(err)
+	assert.Equal(common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"), privKey.PublicKey().Address())
+
+	// The mnemonic is normalized
+	privKey2, err := DeriveKey("  "+strings.ToUpper(testMnemonic)+"\n", "", ethPath)
+	assert.Nil(err)
+	assert.Equal(privKey.ToBytes(), privKey2.ToBytes())
+
+	// The passphrase and the path lead to different keys
+	privKey3, err := DeriveKey(testMnemonic, "passphrase", ethPath)
+	assert.Nil(err)
+	assert.NotEqual(privKey.ToBytes(), privKey3.ToBytes())
+	privKey4, err := DeriveKey(testMnemonic, "", types.DefaultHDDerivationPath)
+	assert.Nil(err)
+	assert.NotEqual(privKey.ToBytes(), privKey4.ToBytes())
+
+	_, err = DeriveKey(strings.Replace(testMnemonic, "about", "abandon", 1), "", ethPath)
+	assert.NotNil(err)
+}
+
+func TestNewMnemonic(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, words := range []int{MnemonicWords12, MnemonicWords24} {
+		mnemonic, err := NewMnemonic(words)
+		assert.Nil(err)
+		assert.Equal(words, len(strings.Fields(mnemonic)))
+		assert.Nil(ValidateMnemonic(mnemonic))
+	}
+
+	_, err := NewMnemonic(13)
+	assert.NotNil(err)
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	assert := assert.New(t)
+
+	path, err := types.ParseDerivationPath("m/44'/500'/0'/0/0")
+	assert.Nil(err)
+	assert.Equal(types.DefaultHDDerivationPath, path)
+	assert.Equal("m/44'/500'/0'/0/0", path.String())
+
+	path, err = types.ParseDerivationPath("m/44h/60h/1")
+	assert.Nil(err)
+	assert.Equal(types.DerivationPath{0x80000000 + 44, 0x80000000 + 60, 1}, path)
+
+	for _, s := range []string{"", "m", "44'/500'", "m/x", "m/-1", "m/2147483648"} {
+		_, err = types.ParseDerivationPath(s)
+		assert.NotNil(err, s)
+	}
+}
+
+func TestDeriveNodeKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	nodeKey, err := DeriveNodeIdentityKey(seed)
+	assert.Nil(err)
+	nodeKey2, err := DeriveKeyFromSeed(seed, types.NodeIdentityDerivationPath)
+	assert.Nil(err)
+	assert.Equal(nodeKey.ToBytes(), nodeKey2.ToBytes())
+
+	validatorKey0, err := DeriveValidatorKey(seed, 0)
+	assert.Nil(err)
+	validatorKey1, err := DeriveValidatorKey(seed, 1)
+	assert.Nil(err)
+	assert.NotEqual(nodeKey.ToBytes(), validatorKey0.ToBytes())
+	assert.NotEqual(validatorKey0.ToBytes(), validatorKey1.ToBytes())
+	assert.Equal("m/44'/500'/1001'/0'/1'", types.ValidatorDerivationPath(1).String())
+
+	_, err = DeriveValidatorKey(seed, hardenedOffset)
+	assert.NotNil(err)
+}
@@ -0,0 +1,178 @@
+package keystore
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Key derivation functions, which derive the encryption keys of the keystore files from the
+// passwords. The keys are encrypted with scrypt or argon2id, the pbkdf2 keys can only be decrypted.
+const (
+	KDFScrypt   = "scrypt"
+	KDFArgon2id = "argon2id"
+	KDFPBKDF2   = "pbkdf2"
+)
+
+const (
+	// StandardArgon2idTime is the number of passes of the Argon2id algorithm, as recommended by
+	// RFC 9106 with 64MB memory
+	StandardArgon2idTime = 3
+
+	// StandardArgon2idMemory is the memory in KiB used by the Argon2id algorithm
+	StandardArgon2idMemory = 64 * 1024
+
+	// StandardArgon2idThreads is the parallelism of the Argon2id algorithm
+	StandardArgon2idThreads = 4
+
+	kdfDKLen = 32
+)
+
+// KDFParams are the parameters of the key derivation function of an encrypted key
+type KDFParams struct {
+	KDF string
+
+	// scrypt
+	N int
+	R int
+	P int
+
+	// argon2id
+	Time    uint32
+	Memory  uint32 // in KiB
+	Threads uint8
+
+	// pbkdf2
+	C int
+}
+
+// ScryptParams returns the scrypt parameters with the CPU/memory cost N and the parallelism P
+func ScryptParams(n, p int) KDFParams {
+	return KDFParams{KDF: KDFScrypt, N: n, R: scryptR, P: p}
+}
+
+// Argon2idParams returns the argon2id parameters with the number of passes, the memory in KiB
+// and the parallelism
+func Argon2idParams(time, memory uint32, threads uint8) KDFParams {
+	return KDFParams{KDF: KDFArgon2id, Time: time, Memory: memory, Threads: threads}
+}
+
+var (
+	// StandardScryptParams are the scrypt parameters of the keys, by default
+	StandardScryptParams = ScryptParams(StandardScryptN, StandardScryptP)
+
+	// StandardArgon2idParams are the recommended argon2id parameters
+	StandardArgon2idParams = Argon2idParams(StandardArgon2idTime, StandardArgon2idMemory, StandardArgon2idThreads)
+)
+
+// KDFParamsByName returns the standard parameters of the KDF, i.e. scrypt or argon2id
+func KDFParamsByName(name string) (KDFParams, error) {
+	switch name {
+	case KDFScrypt:
+		return StandardScryptParams, nil
+	case KDFArgon2id:
+		return StandardArgon2idParams, nil
+	}
+	return KDFParams{}, fmt.Errorf("Unsupported KDF: %v, expected %v or %v", name, KDFScrypt, KDFArgon2id)
+}
+
+func (p KDFParams) String() string {
+	switch p.KDF {
+	case KDFScrypt:
+		return fmt.Sprintf("scrypt (n=%v, p=%v)", p.N, p.P)
+	case KDFArgon2id:
+		return fmt.Sprintf("argon2id (t=%v, m=%vKiB, p=%v)", p.Time, p.Memory, p.Threads)
+	case KDFPBKDF2:
+		return fmt.Sprintf("pbkdf2 (c=%v)", p.C)
+	}
+	return p.KDF
+}
+
+// validate checks whether the keys can be encrypted with the parameters
+func (p KDFParams) validate() error {
+	switch p.KDF {
+	case KDFScrypt:
+		if p.N <= 1 || p.N&(p.N-1) != 0 || p.R <= 0 || p.P <= 0 {
+			return fmt.Errorf("Invalid scrypt parameters: %v", p)
+		}
+	case KDFArgon2id:
+		if p.Time == 0 || p.Memory < 8*uint32(p.Threads) || p.Threads == 0 {
+			return fmt.Errorf("Invalid argon2id parameters: %v", p)
+		}
+	default:
+		return fmt.Errorf("Unsupported KDF for encryption: %v", p.KDF)
+	}
+	return nil
+}
+
+func (p KDFParams) deriveKey(auth, salt []byte, dkLen int) ([]byte, error) {
+	switch p.KDF {
+	case KDFScrypt:
+		return scrypt.Key(auth, salt, p.N, p.R, p.P, dkLen)
+	case KDFArgon2id:
+		return argon2.IDKey(auth, salt, p.Time, p.Memory, p.Threads, uint32(dkLen)), nil
+	case KDFPBKDF2:
+		return pbkdf2.Key(auth, salt, p.C, dkLen, sha256.New), nil
+	}
+	return nil, fmt.Errorf("Unsupported KDF: %s", p.KDF)
+}
+
+// toJSON returns the kdfparams of the keystore file
+func (p KDFParams) toJSON(salt []byte, dkLen int) map[string]interface{} {
+	params := map[string]interface{}{
+		"dklen": dkLen,
+		"salt":  fmt.Sprintf("%x", salt),
+	}
+	switch p.KDF {
+	case KDFScrypt:
+		params["n"] = p.N
+		params["r"] = p.R
+		params["p"] = p.P
+	case KDFArgon2id:
+		params["t"] = p.Time
+		params["m"] = p.Memory
+		params["p"] = p.Threads
+	}
+	return params
+}
+
+// kdfParamsFromJSON parses the kdf and kdfparams of the keystore file
+func kdfParamsFromJSON(cryptoJSON cryptoJSON) (KDFParams, error) {
+	params := cryptoJSON.KDFParams
+	for _, name := range kdfParamNames[cryptoJSON.KDF] {
+		if _, ok := params[name].(float64); !ok {
+			if _, ok := params[name].(int); !ok {
+				return KDFParams{}, fmt.Errorf("Missing or invalid %v parameter %v", cryptoJSON.KDF, name)
+			}
+		}
+	}
+
+	switch cryptoJSON.KDF {
+	case KDFScrypt:
+		scryptParams := ScryptParams(ensureInt(params["n"]), ensureInt(params["p"]))
+		scryptParams.R = ensureInt(params["r"])
+		return scryptParams, nil
+	case KDFArgon2id:
+		t, m, p := ensureInt(params["t"]), ensureInt(params["m"]), ensureInt(params["p"])
+		if t <= 0 || m <= 0 || p <= 0 || p > 255 {
+			return KDFParams{}, fmt.Errorf("Invalid argon2id parameters: t=%v, m=%v, p=%v", t, m, p)
+		}
+		return Argon2idParams(uint32(t), uint32(m), uint8(p)), nil
+	case KDFPBKDF2:
+		if prf, _ := params["prf"].(string); prf != "hmac-sha256" {
+			return KDFParams{}, fmt.Errorf("Unsupported PBKDF2 PRF: %v", params["prf"])
+		}
+		return KDFParams{KDF: KDFPBKDF2, C: ensureInt(params["c"])}, nil
+	}
+	return KDFParams{}, fmt.Errorf("Unsupported KDF: %s", cryptoJSON.KDF)
+}
+
+// kdfParamNames are the numeric parameters of the KDFs in the keystore files
+var kdfParamNames = map[string][]string{
+	KDFScrypt:   {"dklen", "n", "r", "p"},
+	KDFArgon2id: {"dklen", "t", "m", "p"},
+	KDFPBKDF2:   {"dklen", "c"},
+}
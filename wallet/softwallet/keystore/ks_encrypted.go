@@ -31,7 +31,6 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -48,16 +47,11 @@ import (
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/math"
 	"github.com/thetatoken/theta/crypto"
-
-	"golang.org/x/crypto/pbkdf2"
-	"golang.org/x/crypto/scrypt"
 )
 
 const (
 	version = 3
 
-	keyHeaderKDF = "scrypt"
-
 	// StandardScryptN is the N parameter of Scrypt encryption algorithm, using 256MB
 	// memory and taking approximately 1s CPU time on a modern processor.
 	StandardScryptN = 1 << 18
@@ -74,8 +68,7 @@ const (
 	// memory and taking approximately 100ms CPU time on a modern processor.
 	LightScryptP = 6
 
-	scryptR     = 8
-	scryptDKLen = 32
+	scryptR = 8
 )
 
 var (
@@ -84,11 +77,20 @@ var (
 
 type KeystoreEncrypted struct {
 	keysDirPath string
-	scryptN     int
-	scryptP     int
+	kdfParams   KDFParams
 }
 
 func NewKeystoreEncrypted(keysDirRoot string, scryptN, scryptP int) (KeystoreEncrypted, error) {
+	return NewKeystoreEncryptedWithKDF(keysDirRoot, ScryptParams(scryptN, scryptP))
+}
+
+// NewKeystoreEncryptedWithKDF creates a keystore which encrypts the keys with the KDF parameters.
+// The keys encrypted with the other parameters can still be decrypted.
+func NewKeystoreEncryptedWithKDF(keysDirRoot string, kdfParams KDFParams) (KeystoreEncrypted, error) {
+	if err := kdfParams.validate(); err != nil {
+		return KeystoreEncrypted{}, err
+	}
+
 	keysDirPath := path.Join(keysDirRoot, "encrypted")
 	err := os.MkdirAll(keysDirPath, 0700)
 	if err != nil {
@@ -106,8 +108,7 @@ func NewKeystoreEncrypted(keysDirRoot string, scryptN, scryptP int) (KeystoreEnc
 
 	ks := KeystoreEncrypted{
 		keysDirPath: keysDirPath,
-		scryptN:     scryptN,
-		scryptP:     scryptP,
+		kdfParams:   kdfParams,
 	}
 
 	return ks, nil
@@ -130,19 +131,74 @@ func (ks KeystoreEncrypted) ListKeyAddresses() ([]common.Address, error) {
 }
 
 func (ks KeystoreEncrypted) GetKey(address common.Address, auth string) (*Key, error) {
-	var keyjson []byte
+	_, keyjson, err := ks.readKeyFile(address)
+	if err != nil {
+		return nil, err
+	}
+	return decryptAddressKey(keyjson, address, auth)
+}
+
+func (ks KeystoreEncrypted) StoreKey(key *Key, auth string) error {
+	address := key.Address
+	filePath := ks.getFilePath(address, mixedCase)
+	keyjson, err := encryptKey(key, auth, ks.kdfParams)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(filePath, keyjson)
+}
+
+// KDFParams returns the KDF parameters with which the keys are encrypted
+func (ks KeystoreEncrypted) KDFParams() KDFParams {
+	return ks.kdfParams
+}
+
+// KeyKDFParams returns the KDF parameters with which the stored key is encrypted
+func (ks KeystoreEncrypted) KeyKDFParams(address common.Address) (KDFParams, error) {
+	_, keyjson, err := ks.readKeyFile(address)
+	if err != nil {
+		return KDFParams{}, err
+	}
+	encryptedKeyJs := new(encryptedKeyJSON)
+	if err := json.Unmarshal(keyjson, encryptedKeyJs); err != nil {
+		return KDFParams{}, err
+	}
+	return kdfParamsFromJSON(encryptedKeyJs.Crypto)
+}
+
+// MigrateKey re-encrypts the stored key with the KDF parameters of the keystore, in place of the
+// key file
+func (ks KeystoreEncrypted) MigrateKey(address common.Address, auth string) error {
+	filePath, keyjson, err := ks.readKeyFile(address)
+	if err != nil {
+		return err
+	}
+	key, err := decryptAddressKey(keyjson, address, auth)
+	if err != nil {
+		return err
+	}
+	keyjson, err = encryptKey(key, auth, ks.kdfParams)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(filePath, keyjson)
+}
+
+// readKeyFile reads the key file of the address, in any of the address formats
+func (ks KeystoreEncrypted) readKeyFile(address common.Address) (string, []byte, error) {
 	var err error
 	for af := allLowerCase; af <= allUpperCase; af++ { // try all formats
 		filePath := ks.getFilePath(address, af)
+		var keyjson []byte
 		keyjson, err = ioutil.ReadFile(filePath)
 		if err == nil {
-			break
+			return filePath, keyjson, nil
 		}
 	}
-	if err != nil {
-		return nil, err
-	}
+	return "", nil, err
+}
 
+func decryptAddressKey(keyjson []byte, address common.Address, auth string) (*Key, error) {
 	key, err := decryptKey(keyjson, auth)
 	if err != nil {
 		return nil, err
@@ -154,16 +210,6 @@ func (ks KeystoreEncrypted) GetKey(address common.Address, auth string) (*Key, e
 	return key, nil
 }
 
-func (ks KeystoreEncrypted) StoreKey(key *Key, auth string) error {
-	address := key.Address
-	filePath := ks.getFilePath(address, mixedCase)
-	keyjson, err := encryptKey(key, auth, ks.scryptN, ks.scryptP)
-	if err != nil {
-		return err
-	}
-	return writeKeyFile(filePath, keyjson)
-}
-
 func (ks KeystoreEncrypted) DeleteKey(address common.Address, auth string) error {
 	_, err := ks.GetKey(address, auth)
 	if err != nil {
@@ -191,59 +237,81 @@ func (ks KeystoreEncrypted) getFilePath(address common.Address, addrFormat Addre
 	return filePath
 }
 
-// encryptKey encrypts a key using the specified scrypt parameters into a json
+// encryptKey encrypts a key using the specified KDF parameters into a json
 // blob that can be decrypted later on.
-func encryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
+func encryptKey(key *Key, auth string, kdfParams KDFParams) ([]byte, error) {
+	keyBytes := math.PaddedBigBytes(key.PrivateKey.D(), 32)
+	cryptoStruct, err := encryptBytes(keyBytes, auth, kdfParams)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKeyJSON := encryptedKeyJSON{
+		hex.EncodeToString(key.Address[:]),
+		cryptoStruct,
+		key.Id.String(),
+		version,
+	}
+	return json.Marshal(encryptedKeyJSON)
+}
+
+// EncryptData encrypts arbitrary data with the same scheme as the keys into a json
+// blob that can be decrypted with DecryptData.
+func EncryptData(data []byte, auth string, scryptN, scryptP int) ([]byte, error) {
+	cryptoStruct, err := encryptBytes(data, auth, ScryptParams(scryptN, scryptP))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedDataJSON{cryptoStruct, version})
+}
+
+// DecryptData decrypts the data encrypted by EncryptData.
+func DecryptData(datajson []byte, auth string) ([]byte, error) {
+	encryptedDataJs := new(encryptedDataJSON)
+	if err := json.Unmarshal(datajson, encryptedDataJs); err != nil {
+		return nil, err
+	}
+	if encryptedDataJs.Version != version {
+		return nil, fmt.Errorf("Version %v not supported", encryptedDataJs.Version)
+	}
+	return decryptBytes(encryptedDataJs.Crypto, auth)
+}
+
+func encryptBytes(plainText []byte, auth string, kdfParams KDFParams) (cryptoJSON, error) {
 	authArray := []byte(auth)
 
 	salt := make([]byte, 32)
 	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
 		panic("reading from crypto/rand failed: " + err.Error())
 	}
-	derivedKey, err := scrypt.Key(authArray, salt, scryptN, scryptR, scryptP, scryptDKLen)
+	derivedKey, err := kdfParams.deriveKey(authArray, salt, kdfDKLen)
 	if err != nil {
-		return nil, err
+		return cryptoJSON{}, err
 	}
 	encryptKey := derivedKey[:16]
-	keyBytes := math.PaddedBigBytes(key.PrivateKey.D(), 32)
 
 	iv := make([]byte, aes.BlockSize) // 16
 	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
 		panic("reading from crypto/rand failed: " + err.Error())
 	}
-	cipherText, err := aesCTRXOR(encryptKey, keyBytes, iv)
+	cipherText, err := aesCTRXOR(encryptKey, plainText, iv)
 	if err != nil {
-		return nil, err
+		return cryptoJSON{}, err
 	}
 	mac := crypto.Keccak256(derivedKey[16:32], cipherText)
 
-	scryptParamsJSON := make(map[string]interface{}, 5)
-	scryptParamsJSON["n"] = scryptN
-	scryptParamsJSON["r"] = scryptR
-	scryptParamsJSON["p"] = scryptP
-	scryptParamsJSON["dklen"] = scryptDKLen
-	scryptParamsJSON["salt"] = hex.EncodeToString(salt)
-
 	cipherParamsJSON := cipherparamsJSON{
 		IV: hex.EncodeToString(iv),
 	}
 
-	cryptoStruct := cryptoJSON{
+	return cryptoJSON{
 		Cipher:       "aes-128-ctr",
 		CipherText:   hex.EncodeToString(cipherText),
 		CipherParams: cipherParamsJSON,
-		KDF:          keyHeaderKDF,
-		KDFParams:    scryptParamsJSON,
+		KDF:          kdfParams.KDF,
+		KDFParams:    kdfParams.toJSON(salt, kdfDKLen),
 		MAC:          hex.EncodeToString(mac),
-	}
-
-	encryptedKeyJSON := encryptedKeyJSON{
-		hex.EncodeToString(key.Address[:]),
-		cryptoStruct,
-		key.Id.String(),
-		version,
-	}
-	return json.Marshal(encryptedKeyJSON)
+	}, nil
 }
 
 // decryptKey decrypts a key from a json blob, returning the private key itself.
@@ -257,80 +325,74 @@ func decryptKey(keyjson []byte, auth string) (*Key, error) {
 		return nil, fmt.Errorf("Version %v not supported", encryptedKeyJs.Version)
 	}
 
-	if encryptedKeyJs.Crypto.Cipher != "aes-128-ctr" {
-		return nil, fmt.Errorf("Cipher not supported: %v", encryptedKeyJs.Crypto.Cipher)
-	}
-
 	keyId := uuid.Parse(encryptedKeyJs.Id)
 
-	mac, err := hex.DecodeString(encryptedKeyJs.Crypto.MAC)
+	keyBytes, err := decryptBytes(encryptedKeyJs.Crypto, auth)
 	if err != nil {
 		return nil, err
 	}
 
-	iv, err := hex.DecodeString(encryptedKeyJs.Crypto.CipherParams.IV)
-	if err != nil {
-		return nil, err
+	// Use the "unsafe" convertor to support legacy private keys
+	// whose lengths are less than 32 bytes
+	privKey := crypto.PrivateKeyFromBytesUnsafe(keyBytes)
+
+	key := &Key{
+		Id:         keyId,
+		Address:    privKey.PublicKey().Address(),
+		PrivateKey: privKey,
 	}
 
-	cipherText, err := hex.DecodeString(encryptedKeyJs.Crypto.CipherText)
+	return key, nil
+}
+
+func decryptBytes(cryptoStruct cryptoJSON, auth string) ([]byte, error) {
+	if cryptoStruct.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("Cipher not supported: %v", cryptoStruct.Cipher)
+	}
+
+	mac, err := hex.DecodeString(cryptoStruct.MAC)
 	if err != nil {
 		return nil, err
 	}
 
-	derivedKey, err := getKDFKey(encryptedKeyJs.Crypto, auth)
+	iv, err := hex.DecodeString(cryptoStruct.CipherParams.IV)
 	if err != nil {
 		return nil, err
 	}
 
-	calculatedMAC := crypto.Keccak256(derivedKey[16:32], cipherText)
-	if !bytes.Equal(calculatedMAC, mac) {
-		return nil, ErrDecrypt
+	cipherText, err := hex.DecodeString(cryptoStruct.CipherText)
+	if err != nil {
+		return nil, err
 	}
 
-	keyBytes, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	derivedKey, err := getKDFKey(cryptoStruct, auth)
 	if err != nil {
 		return nil, err
 	}
 
-	// Use the "unsafe" convertor to support legacy private keys
-	// whose lengths are less than 32 bytes
-	privKey := crypto.PrivateKeyFromBytesUnsafe(keyBytes)
-
-	key := &Key{
-		Id:         keyId,
-		Address:    privKey.PublicKey().Address(),
-		PrivateKey: privKey,
+	calculatedMAC := crypto.Keccak256(derivedKey[16:32], cipherText)
+	if !bytes.Equal(calculatedMAC, mac) {
+		return nil, ErrDecrypt
 	}
 
-	return key, nil
+	return aesCTRXOR(derivedKey[:16], cipherText, iv)
 }
 
 func getKDFKey(cryptoJSON cryptoJSON, auth string) ([]byte, error) {
-	authArray := []byte(auth)
-	salt, err := hex.DecodeString(cryptoJSON.KDFParams["salt"].(string))
+	kdfParams, err := kdfParamsFromJSON(cryptoJSON)
+	if err != nil {
+		return nil, err
+	}
+	saltStr, _ := cryptoJSON.KDFParams["salt"].(string)
+	salt, err := hex.DecodeString(saltStr)
 	if err != nil {
 		return nil, err
 	}
 	dkLen := ensureInt(cryptoJSON.KDFParams["dklen"])
-
-	if cryptoJSON.KDF == keyHeaderKDF {
-		n := ensureInt(cryptoJSON.KDFParams["n"])
-		r := ensureInt(cryptoJSON.KDFParams["r"])
-		p := ensureInt(cryptoJSON.KDFParams["p"])
-		return scrypt.Key(authArray, salt, n, r, p, dkLen)
-
-	} else if cryptoJSON.KDF == "pbkdf2" {
-		c := ensureInt(cryptoJSON.KDFParams["c"])
-		prf := cryptoJSON.KDFParams["prf"].(string)
-		if prf != "hmac-sha256" {
-			return nil, fmt.Errorf("Unsupported PBKDF2 PRF: %s", prf)
-		}
-		key := pbkdf2.Key(authArray, salt, c, dkLen, sha256.New)
-		return key, nil
+	if dkLen < 32 {
+		return nil, fmt.Errorf("Invalid derived key length: %v", dkLen)
 	}
-
-	return nil, fmt.Errorf("Unsupported KDF: %s", cryptoJSON.KDF)
+	return kdfParams.deriveKey([]byte(auth), salt, dkLen)
 }
 
 func aesCTRXOR(key, inText, iv []byte) ([]byte, error) {
@@ -363,6 +425,11 @@ type encryptedKeyJSON struct {
 	Version int        `json:"version"`
 }
 
+type encryptedDataJSON struct {
+	Crypto  cryptoJSON `json:"crypto"`
+	Version int        `json:"version"`
+}
+
 type cryptoJSON struct {
 	Cipher       string                 `json:"cipher"`
 	CipherText   string                 `json:"ciphertext"`
@@ -111,8 +111,79 @@ func TestKeyEncryptDecrypt(t *testing.T) {
 		}
 		// Recrypt with a new password and start over
 		password += "new data appended"
-		if keyjson, err = encryptKey(key, password, veryLightScryptN, veryLightScryptP); err != nil {
+		if keyjson, err = encryptKey(key, password, ScryptParams(veryLightScryptN, veryLightScryptP)); err != nil {
 			t.Errorf("test %d: failed to recrypt key %v", i, err)
 		}
 	}
 }
+
+// Tests that arbitrary data can be encrypted and decrypted with the key encryption scheme.
+func TestDataEncryptDecrypt(t *testing.T) {
+	data := []byte(`{"alice":"0x45dea0fb0bba44f4fcf290bba71fd57d7117cbb8"}`)
+	datajson, err := EncryptData(data, "foo", veryLightScryptN, veryLightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptData(datajson, "bar"); err != ErrDecrypt {
+		t.Fatalf("wrong error for invalid password\ngot %q\nwant %q", err, ErrDecrypt)
+	}
+	decrypted, err := DecryptData(datajson, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data, decrypted) {
+		t.Fatalf("data mismatch: have %s, want %s", decrypted, data)
+	}
+}
+
+// Tests that the keys encrypted with argon2id can be decrypted, and that the keys can be migrated
+// from scrypt to argon2id.
+func TestKeyStoreEncryptedArgon2id(t *testing.T) {
+	dir, scryptKs := tmpKeyStoreIface(t, true)
+	defer os.RemoveAll(dir)
+
+	pass := "foo"
+	k1, err := storeNewKeyTest(scryptKs, rand.Reader, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	argon2idParams := Argon2idParams(1, 64, 1)
+	ks, err := NewKeystoreEncryptedWithKDF(dir, argon2idParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := ks.KeyKDFParams(k1.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params != ScryptParams(veryLightScryptN, veryLightScryptP) {
+		t.Fatalf("wrong KDF parameters before migration: %v", params)
+	}
+
+	if err := ks.MigrateKey(k1.Address, "bar"); err != ErrDecrypt {
+		t.Fatalf("wrong error for invalid password\ngot %q\nwant %q", err, ErrDecrypt)
+	}
+	if err := ks.MigrateKey(k1.Address, pass); err != nil {
+		t.Fatal(err)
+	}
+	if params, err = ks.KeyKDFParams(k1.Address); err != nil || params != argon2idParams {
+		t.Fatalf("wrong KDF parameters after migration: %v, %v", params, err)
+	}
+
+	// The migrated key can still be decrypted by the keystores with the other parameters
+	k2, err := scryptKs.GetKey(k1.Address, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(k1.PrivateKey, k2.PrivateKey) {
+		t.Fatal("key mismatch after migration")
+	}
+	if _, err := ks.GetKey(k1.Address, "bar"); err != ErrDecrypt {
+		t.Fatalf("wrong error for invalid password\ngot %q\nwant %q", err, ErrDecrypt)
+	}
+
+	if _, err := NewKeystoreEncryptedWithKDF(dir, ScryptParams(3, 1)); err == nil {
+		t.Fatal("invalid scrypt parameters accepted")
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/wallet/softwallet/hd"
 	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
 	"github.com/thetatoken/theta/wallet/types"
 )
@@ -45,14 +46,27 @@ func NewSoftWallet(keysDirPath string, kstype KeystoreType) (*SoftWallet, error)
 	if err != nil {
 		return nil, err
 	}
+	return newSoftWallet(keystore), nil
+}
+
+// NewSoftWalletWithKDF creates a wallet with the encrypted keystore, which encrypts the new keys
+// and the keys with updated passwords with the KDF parameters
+func NewSoftWalletWithKDF(keysDirPath string, kdfParams ks.KDFParams) (*SoftWallet, error) {
+	keystore, err := ks.NewKeystoreEncryptedWithKDF(keysDirPath, kdfParams)
+	if err != nil {
+		return nil, err
+	}
+	return newSoftWallet(keystore), nil
+}
 
+func newSoftWallet(keystore ks.Keystore) *SoftWallet {
 	wallet := &SoftWallet{
 		mu:             &sync.RWMutex{},
 		keystore:       keystore,
 		unlockedKeyMap: make(map[common.Address]*UnlockedKey),
 	}
 
-	return wallet, nil
+	return wallet
 }
 
 // ID returns the ID of the wallet
@@ -84,10 +98,31 @@ func (w *SoftWallet) NewKey(password string) (common.Address, error) {
 		return common.Address{}, err
 	}
 
+	return w.storeNewKey(privKey, password)
+}
+
+// NewKeyFromMnemonic derives the key at the derivation path from the mnemonic and the optional
+// BIP39 passphrase, and stores it. The same key is derived again when recovering from the mnemonic.
+func (w *SoftWallet) NewKeyFromMnemonic(mnemonic, passphrase string, path types.DerivationPath, password string) (common.Address, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	privKey, err := hd.DeriveKey(mnemonic, passphrase, path)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return w.storeNewKey(privKey, password)
+}
+
+func (w *SoftWallet) storeNewKey(privKey *crypto.PrivateKey, password string) (common.Address, error) {
 	key := ks.NewKey(privKey)
 	address := key.Address
 
-	w.keystore.StoreKey(key, password)
+	err := w.keystore.StoreKey(key, password)
+	if err != nil {
+		return common.Address{}, err
+	}
 
 	// newly created key is considerred unlocked
 	unlockedKey := &UnlockedKey{
@@ -174,6 +209,30 @@ func (w *SoftWallet) UpdatePassword(address common.Address, oldPassword, newPass
 	return err
 }
 
+// KeyKDFParams returns the KDF parameters with which the key is encrypted
+func (w *SoftWallet) KeyKDFParams(address common.Address) (ks.KDFParams, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	keystore, ok := w.keystore.(ks.KeystoreEncrypted)
+	if !ok {
+		return ks.KDFParams{}, fmt.Errorf("The keys are not encrypted")
+	}
+	return keystore.KeyKDFParams(address)
+}
+
+// MigrateKey re-encrypts the key with the KDF parameters of the wallet
+func (w *SoftWallet) MigrateKey(address common.Address, password string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	keystore, ok := w.keystore.(ks.KeystoreEncrypted)
+	if !ok {
+		return fmt.Errorf("The keys are not encrypted")
+	}
+	return keystore.MigrateKey(address, password)
+}
+
 // Derive is not supported for SoftWallet
 func (w *SoftWallet) Derive(path types.DerivationPath, pin bool) (common.Address, error) {
 	return common.Address{}, fmt.Errorf("Not supported for software wallet")
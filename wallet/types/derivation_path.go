@@ -1,5 +1,11 @@
 package types
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // DerivationPath represents the computer friendly version of a hierarchical
 // deterministic wallet account derivaion path.
 type DerivationPath []uint32
@@ -18,3 +24,58 @@ var DefaultBaseDerivationPath = DerivationPath{0x80000000 + 44, 0x80000000 + 60,
 // are incremented. As such, the first account will be at m/44'/60'/0'/0, the second
 // at m/44'/60'/0'/1, etc.
 var DefaultLedgerBaseDerivationPath = DerivationPath{0x80000000 + 44, 0x80000000 + 60, 0x80000000 + 0, 0}
+
+// DefaultHDDerivationPath is the path of the first key derived from a mnemonic by the
+// software wallet, following BIP44 with the THETA coin type 500, i.e. m/44'/500'/0'/0/0.
+var DefaultHDDerivationPath = DerivationPath{0x80000000 + 44, 0x80000000 + 500, 0x80000000 + 0, 0, 0}
+
+// NodeIdentityDerivationPath is the path of the P2P node identity key derived from the master
+// secret of a node, i.e. m/44'/500'/1000'/0'/0'. All the components are hardened, so that the
+// node identity key does not reveal anything about the validator keys, and vice versa.
+var NodeIdentityDerivationPath = DerivationPath{0x80000000 + 44, 0x80000000 + 500, 0x80000000 + 1000, 0x80000000 + 0, 0x80000000 + 0}
+
+// ValidatorDerivationPath returns the path of the validator signing key of the given generation
+// derived from the master secret of a node, i.e. m/44'/500'/1001'/0'/<generation>'. The
+// generation is incremented each time the validator rotates its signing key.
+func ValidatorDerivationPath(generation uint32) DerivationPath {
+	return DerivationPath{0x80000000 + 44, 0x80000000 + 500, 0x80000000 + 1001, 0x80000000 + 0, 0x80000000 + generation}
+}
+
+// ParseDerivationPath parses a derivation path like m/44'/500'/0'/0/0. The hardened
+// components are marked with the ' or h suffix.
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	components := strings.Split(strings.TrimSpace(path), "/")
+	if len(components) < 2 || components[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path %q, expected the form m/44'/500'/0'/0/0", path)
+	}
+
+	result := DerivationPath{}
+	for _, component := range components[1:] {
+		hardened := strings.HasSuffix(component, "'") || strings.HasSuffix(component, "h")
+		if hardened {
+			component = component[:len(component)-1]
+		}
+		value, err := strconv.ParseUint(component, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid component %q in derivation path %q", component, path)
+		}
+		if hardened {
+			value += 0x80000000
+		}
+		result = append(result, uint32(value))
+	}
+	return result, nil
+}
+
+// String returns the human readable form of the derivation path.
+func (path DerivationPath) String() string {
+	result := "m"
+	for _, component := range path {
+		if component >= 0x80000000 {
+			result = fmt.Sprintf("%s/%d'", result, component-0x80000000)
+		} else {
+			result = fmt.Sprintf("%s/%d", result, component)
+		}
+	}
+	return result
+}
@@ -9,16 +9,22 @@ import (
 	"github.com/thetatoken/theta/wallet/coldwallet"
 	cw "github.com/thetatoken/theta/wallet/coldwallet"
 	sw "github.com/thetatoken/theta/wallet/softwallet"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
 	"github.com/thetatoken/theta/wallet/types"
 )
 
 var logger *log.Entry = log.WithFields(log.Fields{"prefix": "wallet"})
 
 func OpenWallet(cfgPath string, walletType types.WalletType, encrypted bool) (types.Wallet, error) {
+	return OpenWalletWithKeysDir(path.Join(cfgPath, "keys"), walletType, encrypted)
+}
+
+// OpenWalletWithKeysDir opens the wallet, which stores the keys of the soft wallet in the given
+// directory rather than the keys directory under the config path
+func OpenWalletWithKeysDir(keysDirPath string, walletType types.WalletType, encrypted bool) (types.Wallet, error) {
 	var wallet types.Wallet
 	var err error
 
-	keysDirPath := path.Join(cfgPath, "keys")
 	if walletType == types.WalletTypeSoft {
 		if encrypted {
 			wallet, err = sw.NewSoftWallet(keysDirPath, sw.KeystoreTypeEncrypted)
@@ -54,3 +60,9 @@ func OpenWallet(cfgPath string, walletType types.WalletType, encrypted bool) (ty
 
 	return wallet, nil
 }
+
+// OpenSoftWalletWithKDF opens the encrypted soft wallet, which encrypts the new keys and the keys
+// with updated passwords with the KDF parameters. The keys are stored in the given directory.
+func OpenSoftWalletWithKDF(keysDirPath string, kdfParams ks.KDFParams) (*sw.SoftWallet, error) {
+	return sw.NewSoftWalletWithKDF(keysDirPath, kdfParams)
+}